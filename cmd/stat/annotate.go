@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/annotation"
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// openAnnotations connects to the database and returns the annotation
+// repository.
+func openAnnotations(c *cli.Context) (*annotation.PgRepository, func(), error) {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return nil, nil, configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, externalError("connecting to database: %w", err)
+	}
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("running migrations: %w", err)
+	}
+	if _, err := snapshot.NewPgRepository(pool).EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("ensuring entity: %w", err)
+	}
+	return annotation.NewPgRepository(pool), pool.Close, nil
+}
+
+// runAnnotateAdd attaches a note to a snapshot date.
+func runAnnotateAdd(c *cli.Context) error {
+	date, err := time.Parse("2006-01-02", c.String("date"))
+	if err != nil {
+		return configError("invalid --date: %w", err)
+	}
+	text := strings.Join(c.Args().Slice(), " ")
+	a := annotation.Annotation{Date: date, Text: text, Tags: c.StringSlice("tag")}
+	if err := a.Validate(); err != nil {
+		return configError("%w", err)
+	}
+
+	repo, closeDB, err := openAnnotations(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	a, err = repo.Add(c.Context, "mtlf", a)
+	if err != nil {
+		return err
+	}
+	setResult(c, result{{"id", a.ID}, {"date", c.String("date")}, {"text", a.Text}, {"tags", a.Tags}})
+	return nil
+}
+
+// runAnnotateList prints the notes on dates between --from and --to.
+func runAnnotateList(c *cli.Context) error {
+	var from, to time.Time
+	for name, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if s := c.String(name); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return configError("invalid --%s: %w", name, err)
+			}
+			*t = d
+		}
+	}
+
+	repo, closeDB, err := openAnnotations(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	list, err := repo.List(c.Context, "mtlf", from, to)
+	if err != nil {
+		return err
+	}
+	res := result{{"annotations", len(list)}}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		res = append(res, field{"items", list})
+	} else {
+		for _, a := range list {
+			line := fmt.Sprintf("%-6d %s  %s", a.ID, a.Date.Format("2006-01-02"), a.Text)
+			if len(a.Tags) > 0 {
+				line += "  [" + strings.Join(a.Tags, ", ") + "]"
+			}
+			fmt.Fprintln(c.App.Writer, line)
+		}
+		if len(list) > 0 {
+			fmt.Fprintln(c.App.Writer)
+		}
+	}
+	setResult(c, res)
+	return nil
+}
+
+// runAnnotateDelete removes one note by ID.
+func runAnnotateDelete(c *cli.Context) error {
+	id, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		return configError("expected an annotation ID, got %q", c.Args().First())
+	}
+
+	repo, closeDB, err := openAnnotations(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := repo.Delete(c.Context, "mtlf", id); err != nil {
+		if errors.Is(err, annotation.ErrNotFound) {
+			return configError("%w", err)
+		}
+		return err
+	}
+	setResult(c, result{{"deleted", id}})
+	return nil
+}
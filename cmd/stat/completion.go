@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Completion scripts from urfave/cli's autocomplete directory with PROG
+// fixed to "stat". Both ask the binary for candidates through the hidden
+// --generate-bash-completion flag enabled by App.EnableBashCompletion.
+const bashCompletion = `# bash completion for stat; source it or drop it in bash_completion.d
+_stat_bash_autocomplete() {
+  if [[ "${COMP_WORDS[0]}" != "source" ]]; then
+    local cur opts
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == "-"* ]]; then
+      opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" "${cur}" --generate-bash-completion 2>/dev/null)
+    else
+      opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null)
+    fi
+    COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+    return 0
+  fi
+}
+
+complete -o bashdefault -o default -o nospace -F _stat_bash_autocomplete stat
+`
+
+const zshCompletion = `#compdef stat
+
+_stat_zsh_autocomplete() {
+  local -a opts
+  local cur
+  cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} ${cur} --generate-bash-completion)}")
+  else
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} --generate-bash-completion)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+compdef _stat_zsh_autocomplete stat
+`
+
+func runCompletion(c *cli.Context) error {
+	var script string
+	switch shell := c.Args().First(); shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		s, err := c.App.ToFishCompletion()
+		if err != nil {
+			return fmt.Errorf("generating fish completion: %w", err)
+		}
+		script = s
+	default:
+		return configError("unsupported shell %q (want bash, zsh or fish)", shell)
+	}
+	_, err := fmt.Fprint(c.App.Writer, script)
+	return err
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/definition"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// openDefinitions connects to the database and returns the definition
+// changelog repository.
+func openDefinitions(c *cli.Context) (*definition.PgRepository, func(), error) {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return nil, nil, configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, externalError("connecting to database: %w", err)
+	}
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("running migrations: %w", err)
+	}
+	return definition.NewPgRepository(pool), pool.Close, nil
+}
+
+// runDefinitionsAdd records a new definition of an indicator.
+func runDefinitionsAdd(c *cli.Context) error {
+	date, err := time.Parse("2006-01-02", c.String("effective"))
+	if err != nil {
+		return configError("invalid --effective: %w", err)
+	}
+	ch := definition.Change{IndicatorID: c.Int("id"), EffectiveDate: date, Summary: strings.Join(c.Args().Slice(), " ")}
+	if err := ch.Validate(); err != nil {
+		return configError("%w", err)
+	}
+
+	repo, closeDB, err := openDefinitions(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ch, err = repo.Add(c.Context, ch)
+	if err != nil {
+		return err
+	}
+	setResult(c, result{{"id", ch.ID}, {"indicator", ch.IndicatorID}, {"version", ch.Version}, {"effective", c.String("effective")}, {"summary", ch.Summary}})
+	return nil
+}
+
+// runDefinitionsList prints the definition changes of --id, or of all
+// indicators.
+func runDefinitionsList(c *cli.Context) error {
+	repo, closeDB, err := openDefinitions(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	list, err := repo.List(c.Context, c.Int("id"))
+	if err != nil {
+		return err
+	}
+	res := result{{"changes", len(list)}}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		res = append(res, field{"items", list})
+	} else {
+		for _, ch := range list {
+			fmt.Fprintf(c.App.Writer, "%-6d I%-4d v%-3d %s  %s\n", ch.ID, ch.IndicatorID, ch.Version, ch.EffectiveDate.Format("2006-01-02"), ch.Summary)
+		}
+		if len(list) > 0 {
+			fmt.Fprintln(c.App.Writer)
+		}
+	}
+	setResult(c, res)
+	return nil
+}
+
+// runDefinitionsDelete removes one definition change by ID.
+func runDefinitionsDelete(c *cli.Context) error {
+	id, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		return configError("expected a definition change ID, got %q", c.Args().First())
+	}
+
+	repo, closeDB, err := openDefinitions(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := repo.Delete(c.Context, id); err != nil {
+		if errors.Is(err, definition.ErrNotFound) {
+			return configError("%w", err)
+		}
+		return err
+	}
+	setResult(c, result{{"deleted", id}})
+	return nil
+}
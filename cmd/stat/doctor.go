@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/samber/lo"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// doctorCheckTimeout bounds each doctor check so one hung service doesn't
+// stall the rest.
+const doctorCheckTimeout = 30 * time.Second
+
+// Doctor check outcomes. A skipped check covers an optional integration that
+// isn't configured, or one that depends on a check that already failed.
+const (
+	checkPass = "pass"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// checkResult is the outcome of one doctor check.
+type checkResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// doctor runs the checks in order and collects their results.
+type doctor struct {
+	ctx     context.Context
+	results []checkResult
+}
+
+// check runs fn with its own timeout and records the outcome under name.
+func (d *doctor) check(name string, fn func(ctx context.Context) (status, detail string)) string {
+	ctx, cancel := context.WithTimeout(d.ctx, doctorCheckTimeout)
+	defer cancel()
+	status, detail := fn(ctx)
+	d.results = append(d.results, checkResult{Name: name, Status: status, Detail: detail})
+	return status
+}
+
+func (d *doctor) skip(name, detail string) {
+	d.results = append(d.results, checkResult{Name: name, Status: checkSkip, Detail: detail})
+}
+
+// runDoctor checks that every service stat depends on is configured and
+// reachable: the database and its migrations, each Horizon endpoint and its
+// ledger lag, the fund accounts on-chain, CoinGecko and Google Sheets. It
+// changes nothing, so it's safe to run against production.
+func runDoctor(c *cli.Context) error {
+	cfg := config.Load()
+	d := &doctor{ctx: c.Context}
+
+	var pool *pgxpool.Pool
+	if cfg.DatabaseURL == "" {
+		d.check("database", func(context.Context) (string, string) { return checkFail, "DATABASE_URL is not set" })
+	} else {
+		d.check("database", func(ctx context.Context) (string, string) {
+			p, err := database.Connect(ctx, cfg.DatabaseURL)
+			if err != nil {
+				return checkFail, err.Error()
+			}
+			pool = p
+			return checkPass, "connected"
+		})
+	}
+	if pool != nil {
+		defer pool.Close()
+		d.check("migrations", func(ctx context.Context) (string, string) {
+			pending, err := database.PendingMigrations(ctx, pool, migrations.FS)
+			switch {
+			case err != nil:
+				return checkFail, err.Error()
+			case len(pending) > 0:
+				// Every command that uses the database applies them first.
+				return checkPass, fmt.Sprintf("%d pending from %s, applied on the next run", len(pending), pending[0])
+			}
+			return checkPass, "up to date"
+		})
+	} else {
+		d.skip("migrations", "no database connection")
+	}
+
+	reachable := 0
+	for _, u := range append([]string{cfg.HorizonURL}, cfg.HorizonFallbackURLs...) {
+		status := d.check("horizon "+u, func(ctx context.Context) (string, string) {
+			root, err := horizon.NewClient(u, 0, 0).FetchRoot(ctx)
+			if err != nil {
+				return checkFail, err.Error()
+			}
+			lag := time.Since(root.LatestLedgerClosedAt).Round(time.Second)
+			detail := fmt.Sprintf("ledger %d closed %s ago", root.LatestLedger, lag)
+			if lag > cfg.HorizonMaxLag {
+				return checkFail, detail + fmt.Sprintf(" (more than %s behind)", cfg.HorizonMaxLag)
+			}
+			return checkPass, detail
+		})
+		if status == checkPass {
+			reachable++
+		}
+	}
+
+	registry := domain.AccountRegistry()
+	if reachable == 0 {
+		d.skip("fund accounts", "no Horizon endpoint reachable")
+	} else {
+		client := newHorizonClient(cfg)
+		d.check("fund accounts", func(ctx context.Context) (string, string) {
+			var missing []string
+			for _, acc := range registry {
+				if _, err := client.FetchAccount(ctx, acc.Address); err != nil {
+					missing = append(missing, acc.Name)
+				}
+			}
+			if len(missing) > 0 {
+				return checkFail, fmt.Sprintf("%d of %d not found: %s", len(missing), len(registry), strings.Join(missing, ", "))
+			}
+			return checkPass, fmt.Sprintf("all %d found", len(registry))
+		})
+	}
+
+	d.check("coingecko", func(ctx context.Context) (string, string) {
+		if err := external.NewCoinGeckoClient(cfg.CoinGeckoURL, cfg.CoinGeckoDelay, 0, external.WithTransport(roundTripper())).Ping(ctx); err != nil {
+			return checkFail, err.Error()
+		}
+		return checkPass, "reachable"
+	})
+
+	// The fund's spreadsheet, plus every entity with its own spreadsheet or
+	// credentials.
+	slugs := lo.Uniq(append(append([]string{"mtlf"}, lo.Keys(cfg.GoogleSheetsByEntity)...), lo.Keys(cfg.GoogleCredentialsByEntity)...))
+	sort.Strings(slugs[1:])
+	for _, slug := range slugs {
+		name := "google sheets"
+		if slug != "mtlf" {
+			name += " (" + slug + ")"
+		}
+		spreadsheetID, credentialsJSON := sheetsTarget(cfg, slug)
+		switch {
+		case spreadsheetID == "" && credentialsJSON == "":
+			d.skip(name, "GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON not set")
+		case spreadsheetID == "" || credentialsJSON == "":
+			d.check(name, func(context.Context) (string, string) {
+				return checkFail, "set both GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON"
+			})
+		default:
+			d.check(name, func(ctx context.Context) (string, string) {
+				w, err := newSheetsWriter(ctx, cfg, slug)
+				if err != nil {
+					return checkFail, err.Error()
+				}
+				title, err := w.CheckAccess(ctx)
+				if err != nil {
+					return checkFail, err.Error()
+				}
+				return checkPass, fmt.Sprintf("%s can edit %q", w.Account(), title)
+			})
+		}
+	}
+
+	var passed, failed, skipped int
+	for _, r := range d.results {
+		switch r.Status {
+		case checkPass:
+			passed++
+		case checkFail:
+			failed++
+		default:
+			skipped++
+		}
+	}
+	res := result{{"passed", passed}, {"failed", failed}, {"skipped", skipped}}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		res = append(res, field{"checks", d.results})
+	} else {
+		printChecks(c.App.Writer, d.results, colorEnabled(c.App.Writer))
+	}
+	setResult(c, res)
+	return partialIf(failed, passed+failed, "checks")
+}
+
+// printChecks writes one line per check, status first.
+func printChecks(w io.Writer, results []checkResult, color bool) {
+	for _, r := range results {
+		label := strings.ToUpper(r.Status)
+		if color {
+			code := map[string]string{checkPass: "32", checkFail: "31", checkSkip: "33"}[r.Status]
+			label = "\x1b[" + code + "m" + label + "\x1b[0m"
+		}
+		fmt.Fprintf(w, "%s  %-40s %s\n", label, r.Name, r.Detail)
+	}
+	fmt.Fprintln(w)
+}
+
+// colorEnabled reports whether w is a terminal and NO_COLOR is unset.
+func colorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/asof"
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/pacing"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/property"
+	"github.com/mtlprog/stat/internal/snapdate"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/storage"
+	"github.com/mtlprog/stat/internal/valuation"
+)
+
+// openStore opens DATABASE_URL through the storage factory: PostgreSQL, or
+// SQLite for a sqlite:// URL.
+func openStore(ctx context.Context, cfg config.Config) (*storage.Store, error) {
+	store, err := storage.Open(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, externalError("opening database: %w", err)
+	}
+	return store, nil
+}
+
+// reportDate returns the snapshot date of `stat report`: --date when given,
+// today by clock otherwise.
+func reportDate(c *cli.Context, clock snapdate.Clock) (time.Time, error) {
+	date := clock.Today()
+	if v := c.String("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return time.Time{}, configError("invalid --date: %w", err)
+		}
+		if d.After(date) {
+			return time.Time{}, configError("--date %s is after today (%s)", v, date.Format("2006-01-02"))
+		}
+		date = d
+	}
+	slog.Info("snapshot date", "date", date.Format("2006-01-02"), "clock", clock.String())
+	return date, nil
+}
+
+// runReportDryRun is `stat report --dry-run`: it generates the snapshot of
+// the date and calculates its indicators, then prints them instead of
+// storing or exporting anything. The stored snapshots and quotes of
+// DATABASE_URL feed the comparisons and the external valuations, so it runs
+// against SQLite as well as PostgreSQL. Only the fund structure and the
+// calculators run: the live metrics enrichers and the holder, conversion
+// and subfond stages write as they go, so the indicators they feed are left
+// out, as are the ones that need stored indicator history on SQLite.
+func runReportDryRun(ctx context.Context, c *cli.Context, cfg config.Config) error {
+	store, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return err
+	}
+	date, err := reportDate(c, clock)
+	if err != nil {
+		return err
+	}
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	horizonClient := newHorizonClient(cfg)
+	defer logTransportStats()
+	quotes := external.NewService(newCoinGeckoClient(cfg, store.Pool), store.Quotes)
+	var properties valuation.PropertySource
+	if store.Pool != nil {
+		properties = property.NewService(property.NewPgRepository(store.Pool), "mtlf", cfg.PropertyAppraisalMaxAge)
+	}
+	fundSvc, prices, err := newFundService(cfg, horizonClient, pacing.New(cfg.HorizonRPS), quotes, properties, store.Snapshots)
+	if err != nil {
+		return err
+	}
+	setPriceReference(ctx, store.Snapshots, prices, date)
+
+	hist := &indicator.HistoricalData{Repo: store.Snapshots, Slug: "mtlf", Quotes: store.Quotes, Date: date}
+	if store.Pool != nil {
+		policies, err := price.NewPgPolicyRepository(store.Pool).Policies(ctx, "mtlf")
+		if err != nil {
+			return err
+		}
+		prices.SetPolicies(policies)
+
+		indicatorRepo := indicator.NewPgRepository(store.Pool)
+		indexCfg, err := indicatorRepo.GetIndexConfig(ctx, "mtlf")
+		if err != nil {
+			return err
+		}
+		hist.IndicatorRepo, hist.Index = indicatorRepo, &indexCfg
+	}
+
+	if date.Before(clock.Today()) {
+		ctx = asof.With(ctx, clock.Start(date.AddDate(0, 0, 1)).Add(-time.Second)) // end of the snapshot day
+	}
+	stage := startStage("snapshot_preview")
+	data, err := snapshot.NewService(fundSvc, store.Snapshots).Preview(ctx, date)
+	if err != nil {
+		return externalError("generating snapshot: %w", err)
+	}
+	stage.done("date", date.Format("2006-01-02"))
+
+	res, err := indicator.NewService(hist, indicatorOpts...).CalculatePartial(ctx, data)
+	if err != nil {
+		return fmt.Errorf("calculating indicators: %w", err)
+	}
+	for _, f := range res.Failures {
+		slog.Error("indicator calculator failed", "calculator", f.Calculator, "ids", f.IDs, "skipped", f.Skipped, "error", f.Error)
+	}
+
+	inds := slices.SortedFunc(slices.Values(res.Indicators), func(a, b indicator.Indicator) int { return a.ID - b.ID })
+	values := make(result, 0, len(inds))
+	for _, ind := range inds {
+		values = append(values, field{fmt.Sprintf("I%d", ind.ID), ind.Value.String()})
+	}
+	unavailable := res.UnavailableIDs()
+	setResult(c, result{
+		{"date", date.Format("2006-01-02")},
+		{"dryRun", true},
+		{"totalEURMTL", data.AggregatedTotals.TotalEURMTL.String()},
+		{"indicators", len(res.Indicators)},
+		{"unavailable", unavailable},
+		{"values", values},
+	})
+	if len(res.Failures) > 0 {
+		return partialError("%d indicator calculators failed (indicators %v unavailable)", len(res.Failures), unavailable)
+	}
+	return nil
+}
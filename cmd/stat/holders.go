@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/holders"
+	"github.com/mtlprog/stat/internal/payout"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// holderDistributionFlags select a holder set and how a dividend is split
+// over it; shared by holders export and holders payout.
+func holderDistributionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "asset",
+			Value: holders.AssetMTL,
+			Usage: "Holder set: MTL (MTL + MTLRECT balances) or MTLAP",
+		},
+		&cli.StringFlag{
+			Name:  "date",
+			Usage: "Record date (YYYY-MM-DD; default: today)",
+		},
+		&cli.StringFlag{
+			Name:  "pool",
+			Usage: "Dividend pool to split in proportion to the balances",
+		},
+		&cli.StringFlag{
+			Name:  "per-share",
+			Usage: "Dividend per unit of balance (instead of --pool)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "Account to leave out of the distribution (repeatable)",
+		},
+		&cli.BoolFlag{
+			Name:  "fresh",
+			Usage: "Walk the current holders on Horizon instead of using the recorded set",
+		},
+	}
+}
+
+// loadHolderDistribution reads the holder set chosen by the flags of
+// holderDistributionFlags and splits the dividend over it. The set recorded
+// by the report pipeline on the date is used; --fresh walks Horizon
+// instead, which only describes today. The second result names the source.
+func loadHolderDistribution(c *cli.Context, cfg config.Config) (holders.Distribution, string, error) {
+	ctx := c.Context
+
+	asset := strings.ToUpper(c.String("asset"))
+	if !slices.Contains(holders.Assets, asset) {
+		return holders.Distribution{}, "", configError("invalid --asset %q: want one of %s", c.String("asset"), strings.Join(holders.Assets, ", "))
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	date := today
+	if s := c.String("date"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return holders.Distribution{}, "", configError("invalid --date: %w", err)
+		}
+		date = d
+	}
+	fresh := c.Bool("fresh")
+	if fresh && !date.Equal(today) {
+		return holders.Distribution{}, "", configError("--fresh scans today's holders; it can't be combined with --date %s", date.Format("2006-01-02"))
+	}
+	if c.String("pool") != "" && c.String("per-share") != "" {
+		return holders.Distribution{}, "", configError("--pool and --per-share are mutually exclusive")
+	}
+	amounts := map[string]decimal.Decimal{}
+	for _, name := range []string{"pool", "per-share"} {
+		if s := c.String(name); s != "" {
+			v, err := decimal.NewFromString(s)
+			if err != nil || v.IsNegative() {
+				return holders.Distribution{}, "", configError("invalid --%s %q: want a non-negative amount", name, s)
+			}
+			amounts[name] = v
+		}
+	}
+
+	var set holders.Set
+	source := "holder_sets"
+	if fresh {
+		source = "horizon"
+		s, err := holders.NewService(newHorizonClient(cfg), nil, "mtlf").Scan(ctx, asset, date)
+		if err != nil {
+			return holders.Distribution{}, "", externalError("scanning %s holders: %w", asset, err)
+		}
+		set = s
+	} else {
+		if cfg.DatabaseURL == "" {
+			return holders.Distribution{}, "", configError("DATABASE_URL is required (or pass --fresh)")
+		}
+		dbPool, err := database.Connect(ctx, cfg.DatabaseURL)
+		if err != nil {
+			return holders.Distribution{}, "", externalError("connecting to database: %w", err)
+		}
+		defer dbPool.Close()
+		if err := database.RunMigrations(ctx, dbPool, migrations.FS); err != nil {
+			return holders.Distribution{}, "", fmt.Errorf("running migrations: %w", err)
+		}
+
+		s, err := holders.NewService(nil, holders.NewPgRepository(dbPool), "mtlf").Stored(ctx, asset, date)
+		switch {
+		case errors.Is(err, holders.ErrNoBalances):
+			return holders.Distribution{}, "", fmt.Errorf("the %s holder set of %s was recorded without balances; only --fresh can export it today", asset, date.Format("2006-01-02"))
+		case err != nil:
+			return holders.Distribution{}, "", fmt.Errorf("loading %s holders: %w", asset, err)
+		}
+		set = *s
+	}
+
+	exclude := c.StringSlice("exclude")
+	if perShare, ok := amounts["per-share"]; ok {
+		d, err := holders.DistributePerShare(set, perShare, exclude)
+		return d, source, err
+	}
+	d, err := holders.Distribute(set, amounts["pool"], exclude)
+	return d, source, err
+}
+
+// runHoldersExport writes the holders of --asset on --date with their share
+// of the distributed balances and, with --pool or --per-share, their
+// dividend, for a payout run.
+func runHoldersExport(c *cli.Context) error {
+	d, source, err := loadHolderDistribution(c, config.Load())
+	if err != nil {
+		return err
+	}
+	out := c.String("out")
+	if err := writeHoldersCSV(c, out, d); err != nil {
+		return err
+	}
+
+	date := d.Date.Format("2006-01-02")
+	slog.Info("holders exported", "asset", d.Asset, "date", date, "holders", len(d.Payouts), "source", source)
+	setResult(c, result{
+		{"asset", d.Asset},
+		{"date", date},
+		{"source", source},
+		{"holders", len(d.Payouts)},
+		{"excluded", len(d.Excluded)},
+		{"total", d.Total.String()},
+		{"pool", d.Pool.String()},
+		{"paid", d.Paid.String()},
+		{"file", out},
+	})
+	return nil
+}
+
+// writeHoldersCSV writes the distribution to path, or to stdout for "-".
+func writeHoldersCSV(c *cli.Context, path string, d holders.Distribution) error {
+	if path == "-" {
+		return d.WriteCSV(c.App.Writer)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := d.WriteCSV(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// runHoldersPayout builds the unsigned EURMTL payment transactions of a
+// dividend run from the distribution of holders export. The source
+// account's sequence and balance and the recipients' trustlines are read
+// from Horizon for the validation report; a plan with errors is not written.
+func runHoldersPayout(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if c.String("pool") == "" && c.String("per-share") == "" {
+		return configError("--per-share or --pool is required")
+	}
+	d, holderSource, err := loadHolderDistribution(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	client := newHorizonClient(cfg)
+	asset := domain.EURMTLAsset()
+	from := c.String("source")
+	account, err := client.FetchAccount(ctx, from)
+	if err != nil {
+		return externalError("loading source account: %w", err)
+	}
+	sequence, err := strconv.ParseInt(account.Sequence, 10, 64)
+	if err != nil {
+		return externalError("parsing sequence %q of %s: %w", account.Sequence, from, err)
+	}
+	available := decimal.Zero
+	for _, b := range account.Balances {
+		if b.AssetCode == asset.Code && b.AssetIssuer == asset.Issuer {
+			if available, err = decimal.NewFromString(b.Balance); err != nil {
+				return externalError("parsing %s balance of %s: %w", asset.Code, from, err)
+			}
+		}
+	}
+	holdersOfAsset, err := client.FetchAssetHolderBalancesByBalance(ctx, asset, decimal.Zero)
+	if err != nil {
+		return externalError("walking %s trustlines: %w", asset.Code, err)
+	}
+	trustlines := make(map[string]bool, len(holdersOfAsset))
+	for id := range holdersOfAsset {
+		trustlines[id] = true
+	}
+
+	plan, err := payout.Build(d, payout.Options{
+		Source:     from,
+		Sequence:   sequence,
+		Asset:      asset,
+		BaseFee:    uint32(c.Uint("base-fee")),
+		OpsPerTx:   c.Int("ops-per-tx"),
+		Memo:       c.String("memo"),
+		Trustlines: trustlines,
+		Available:  &available,
+	})
+	if err != nil {
+		return configError("%w", err)
+	}
+	if path := c.String("report"); path != "" {
+		if err := writePayoutReport(c, path, plan); err != nil {
+			return err
+		}
+	}
+	for _, is := range plan.Issues {
+		level := slog.LevelInfo
+		if is.Severity == payout.SeverityError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "payout issue", "severity", is.Severity, "kind", is.Kind, "account", is.Account, "message", is.Message)
+	}
+	slog.Info("payout planned", "date", plan.Date, "holders", plan.Holders, "payments", plan.Payments,
+		"amount", plan.Amount, "batches", len(plan.Batches), "source", holderSource)
+	res := result{
+		{"date", plan.Date},
+		{"payments", plan.Payments},
+		{"amount", plan.Amount.String()},
+		{"batches", len(plan.Batches)},
+		{"fees", plan.Fees.String()},
+		{"issues", len(plan.Issues)},
+	}
+	if n := plan.Errors(); n > 0 {
+		return configError("payout plan failed validation with %d errors, nothing written", n)
+	}
+	if c.Bool("dry-run") {
+		setResult(c, res)
+		return nil
+	}
+
+	out := c.String("out")
+	if out == "" {
+		return configError("--out is required unless --dry-run")
+	}
+	var lines strings.Builder
+	for _, b := range plan.Batches {
+		lines.WriteString(b.XDR + "\n")
+	}
+	if err := os.WriteFile(out, []byte(lines.String()), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
+	}
+	setResult(c, append(res, field{"file", out}))
+	return nil
+}
+
+// writePayoutReport writes the payout plan with its issues as JSON to path,
+// or to the app's writer when path is "-".
+func writePayoutReport(c *cli.Context, path string, plan payout.Plan) error {
+	raw, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling payout report: %w", err)
+	}
+	raw = append(raw, '\n')
+	if path == "-" {
+		_, err := c.App.Writer.Write(raw)
+		return err
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
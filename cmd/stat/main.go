@@ -14,38 +14,60 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sort"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 	"github.com/urfave/cli/v2"
-	"github.com/xuri/excelize/v2"
 
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/accountmeta"
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/annotation"
 	"github.com/mtlprog/stat/internal/api"
+	"github.com/mtlprog/stat/internal/apikey"
+	"github.com/mtlprog/stat/internal/association"
 	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/conversion"
 	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/decjson"
+	"github.com/mtlprog/stat/internal/definition"
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/explorer"
 	"github.com/mtlprog/stat/internal/export"
 	"github.com/mtlprog/stat/internal/external"
-	"github.com/mtlprog/stat/internal/fund"
+	"github.com/mtlprog/stat/internal/fixture"
 	"github.com/mtlprog/stat/internal/grist"
+	"github.com/mtlprog/stat/internal/holders"
 	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/hubble"
 	"github.com/mtlprog/stat/internal/indicator"
-	"github.com/mtlprog/stat/internal/metrics"
+	"github.com/mtlprog/stat/internal/intraday"
+	"github.com/mtlprog/stat/internal/issuance"
+	"github.com/mtlprog/stat/internal/job"
+	"github.com/mtlprog/stat/internal/legacy"
 	"github.com/mtlprog/stat/internal/notify"
-	"github.com/mtlprog/stat/internal/portfolio"
-	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/outbox"
+	"github.com/mtlprog/stat/internal/payout"
+	"github.com/mtlprog/stat/internal/period"
+	"github.com/mtlprog/stat/internal/progress"
+	"github.com/mtlprog/stat/internal/reconcile"
+	"github.com/mtlprog/stat/internal/slo"
+	"github.com/mtlprog/stat/internal/snapdate"
 	"github.com/mtlprog/stat/internal/snapshot"
-	"github.com/mtlprog/stat/internal/stellarexpert"
-	"github.com/mtlprog/stat/internal/valuation"
+	"github.com/mtlprog/stat/internal/subfond"
+	"github.com/mtlprog/stat/internal/supply"
+	"github.com/mtlprog/stat/internal/whale"
 	"github.com/mtlprog/stat/migrations"
 )
 
@@ -56,8 +78,23 @@ func main() {
 	defer stop()
 
 	app := &cli.App{
-		Name:  "stat",
-		Usage: "Montelibero Fund statistics",
+		Name:                 "stat",
+		Usage:                "Montelibero Fund statistics",
+		EnableBashCompletion: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Result format: table, json or yaml",
+				Value:   outputTable,
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if err := validateOutput(c); err != nil {
+				return err
+			}
+			return configureTransport(config.Load())
+		},
 		Commands: []*cli.Command{
 			{
 				Name:   "serve",
@@ -65,15 +102,97 @@ func main() {
 				Action: runServe,
 			},
 			{
-				Name:   "quote",
-				Usage:  "Fetch and store external price quotes",
+				Name:  "quote",
+				Usage: "Fetch and store external price quotes",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "fresh-for",
+						Usage: "Skip symbols whose stored quote is newer than this (default QUOTE_FRESH_FOR)",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Fetch every symbol, however fresh its stored quote",
+					},
+				},
 				Action: runQuote,
+				Subcommands: []*cli.Command{
+					{
+						Name:  "backfill",
+						Usage: "Store daily quotes for past dates from the CoinGecko range API",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "from",
+								Usage:    "First date to backfill (YYYY-MM-DD)",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "to",
+								Usage: "Last date to backfill (YYYY-MM-DD, default today)",
+							},
+						},
+						Action: runQuoteBackfill,
+					},
+					{
+						Name:      "set",
+						Usage:     "Store a manual quote, e.g. a price per m² for property valuations",
+						ArgsUsage: "SYMBOL PRICE_IN_EUR",
+						Action:    runQuoteSet,
+					},
+				},
+			},
+			{
+				Name:  "prices",
+				Usage: "Inspect token prices",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "audit",
+						Usage: "Price every held token through each source (path, orderbook, AMM, _COST, external quotes) and flag sources that disagree",
+						Flags: []cli.Flag{
+							&cli.Float64Flag{
+								Name:  "threshold",
+								Value: 0.1,
+								Usage: "Relative gap between a token's highest and lowest price above which its sources disagree",
+							},
+							&cli.StringSliceFlag{
+								Name:  "critical",
+								Value: cli.NewStringSlice("MTL", "MTLRECT", "XLM"),
+								Usage: "Asset code whose divergence fails the command (repeatable)",
+							},
+						},
+						Action: runPricesAudit,
+					},
+				},
 			},
 			{
-				Name:   "report",
-				Usage:  "Generate fund snapshot and export to Sheets",
+				Name:  "report",
+				Usage: "Generate fund snapshot and export to Sheets",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "date",
+						Usage: "Generate a past snapshot date (YYYY-MM-DD) as of the end of that day instead of today; with --sheets-diff, the stored snapshot to diff (default: latest)",
+					},
+					&cli.BoolFlag{
+						Name:  "sheets-diff",
+						Usage: "Recalculate the indicators of a stored snapshot and print how the IND_ALL, IND_MAIN and MONITORING rows differ from the spreadsheet, writing nothing",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Generate the snapshot and print its indicators without storing or exporting anything; works with a sqlite:// DATABASE_URL",
+					},
+				},
 				Action: runReport,
 			},
+			{
+				Name:  "association-report",
+				Usage: "Generate the Montelibero Association treasury snapshot (I28/I29 source)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "date",
+						Usage: "Generate a past snapshot date (YYYY-MM-DD) as of the end of that day instead of today",
+					},
+				},
+				Action: runAssociationReport,
+			},
 			{
 				Name:  "import",
 				Usage: "Import historical snapshots from the old stat API",
@@ -95,34 +214,477 @@ func main() {
 						Usage:    "Path to the Excel file (e.g. MTL_report_1.xlsx)",
 						Required: true,
 					},
+					&cli.StringFlag{
+						Name:  "sheet",
+						Usage: "Sheet holding the MONITORING data",
+						Value: export.DefaultExcelSheet,
+					},
+					&cli.IntFlag{
+						Name:  "header-row",
+						Usage: "1-based row with the column names (0 detects it)",
+					},
+					&cli.StringFlag{
+						Name:  "report",
+						Usage: "Write the JSON validation report to this file (- for stdout)",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Validate the file and report without writing to Google Sheets",
+					},
 				},
 				Action: runImportExcel,
 			},
+			{
+				Name:  "snapshot",
+				Usage: "Copy individual snapshots between databases (e.g. production to a local one)",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Write one stored snapshot to a JSON transfer file",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "date",
+								Usage:    "Snapshot date (YYYY-MM-DD)",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "out",
+								Usage:    "File to write",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "entity",
+								Value: "mtlf",
+								Usage: "Entity slug to export from",
+							},
+						},
+						Action: runSnapshotExport,
+					},
+					{
+						Name:  "import",
+						Usage: "Store a snapshot from a transfer file written by snapshot export",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "in",
+								Usage:    "Transfer file to read",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "entity",
+								Usage: "Entity slug to import into (default: the file's); created if missing",
+							},
+							&cli.BoolFlag{
+								Name:  "overwrite",
+								Usage: "Replace the snapshot if one already exists for the date",
+							},
+						},
+						Action: runSnapshotImport,
+					},
+				},
+			},
+			{
+				Name:  "holders",
+				Usage: "Export the MTL and MTLAP holder sets recorded by the report pipeline and prepare dividend payouts",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Write the holders on a record date with their share and dividend to a CSV file",
+						Flags: append(holderDistributionFlags(),
+							&cli.StringFlag{
+								Name:     "out",
+								Usage:    "CSV file to write (\"-\" for stdout)",
+								Required: true,
+							},
+						),
+						Action: runHoldersExport,
+					},
+					{
+						Name:  "payout",
+						Usage: "Build unsigned EURMTL payment transactions (≤100 payments each) paying the dividend of a record date",
+						Flags: append(holderDistributionFlags(),
+							&cli.StringFlag{
+								Name:  "source",
+								Value: domain.MTLDividendDistributor,
+								Usage: "Paying account",
+							},
+							&cli.StringFlag{
+								Name:  "memo",
+								Value: payout.DefaultMemo,
+								Usage: "Memo template: {date} (dd/mm/yyyy), {batch}, {batches}; at most 28 bytes",
+							},
+							&cli.UintFlag{
+								Name:  "base-fee",
+								Value: payout.DefaultBaseFee,
+								Usage: "Fee per payment in stroops",
+							},
+							&cli.IntFlag{
+								Name:  "ops-per-tx",
+								Value: payout.MaxOpsPerTx,
+								Usage: "Payments per transaction",
+							},
+							&cli.StringFlag{
+								Name:  "out",
+								Usage: "File to write, one base64 transaction envelope per line",
+							},
+							&cli.StringFlag{
+								Name:  "report",
+								Usage: "Write the plan and validation report as JSON to this file (\"-\" for stdout)",
+							},
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Validate and report without writing the transactions",
+							},
+						),
+						Action: runHoldersPayout,
+					},
+				},
+			},
+			{
+				Name:  "account-config",
+				Usage: "Declare and check the expected configuration of fund accounts (flags, home domain, inflation destination, sponsored reserves)",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "pin",
+						Usage: "Store the current live configuration as the expected state",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "account",
+								Usage: "Only pin these fund accounts (address or name; default: all)",
+							},
+						},
+						Action: runAccountConfigPin,
+					},
+					{
+						Name:   "check",
+						Usage:  "Compare the live configuration with the expected state (exits 4 on drift)",
+						Action: runAccountConfigCheck,
+					},
+				},
+			},
+			{
+				Name:  "fixture",
+				Usage: "Write a stored snapshot and its calculated indicators as an indicator test fixture",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "date",
+						Usage:    "Snapshot date (YYYY-MM-DD)",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "redact",
+						Usage: "Replace non-fund addresses, drop price path details and shrink token lists",
+					},
+					&cli.IntFlag{
+						Name:  "max-tokens",
+						Value: 10,
+						Usage: "Tokens kept per account with --redact (0 keeps all)",
+					},
+					&cli.StringFlag{
+						Name:  "dir",
+						Value: "internal/indicator/testdata/fixtures",
+						Usage: "Directory to write <date>.json to",
+					},
+				},
+				Action: runFixture,
+			},
 			{
 				Name:   "backfill-indicators",
-				Usage:  "Recompute and persist deterministic indicators for all stored snapshots",
+				Usage:  "Recompute and persist deterministic indicators for all stored snapshots (plus historical holder counts and supply with HUBBLE_PROJECT)",
 				Action: runBackfillIndicators,
 			},
+			{
+				Name:  "backfill-snapshots",
+				Usage: "Regenerate past snapshots from Horizon ledger history (balances and prices as of each day)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "from",
+						Usage:    "First date to regenerate (YYYY-MM-DD)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Last date to regenerate (YYYY-MM-DD, default yesterday)",
+					},
+					&cli.BoolFlag{
+						Name:  "overwrite",
+						Usage: "Replace snapshots that already exist instead of skipping them",
+					},
+				},
+				Action: runBackfillSnapshots,
+			},
+			{
+				Name:   "backfill-holdings",
+				Usage:  "Fill the fund_snapshots.holdings token index for snapshots stored before it existed",
+				Action: runBackfillHoldings,
+			},
+			{
+				Name:   "backfill-balances",
+				Usage:  "Fill the account_balances history table for snapshots stored before it existed",
+				Action: runBackfillBalances,
+			},
 			{
 				Name:   "backfill-divs",
 				Usage:  "Recompute I11 (sum) and I18 (distinct recipients) from latest dividend event ≤ each snapshot date",
 				Action: runBackfillDivs,
 			},
+			{
+				Name:   "backfill-index",
+				Usage:  "Recompute the Montelibero Index (I66) for every date with stored component indicators, using the current index definition",
+				Action: runBackfillIndex,
+			},
 			{
 				Name:   "import-indicators-from-sheets",
 				Usage:  "Import historical indicator values from the MONITORING Google Sheets tab into fund_indicators",
 				Action: runImportIndicatorsFromSheets,
 			},
+			{
+				Name:  "reconcile",
+				Usage: "Compare the MONITORING Google Sheets tab with indicators recomputed from stored snapshots and list dates that need re-import",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "First date to compare (YYYY-MM-DD)",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Last date to compare (YYYY-MM-DD)",
+					},
+					&cli.Float64Flag{
+						Name:  "tolerance",
+						Value: 0.001,
+						Usage: "Relative difference allowed per cell; values within half a unit of the indicator's precision always match",
+					},
+					&cli.StringFlag{
+						Name:  "report",
+						Usage: "Write the per-date, per-column diff as CSV to this file (- for stdout)",
+					},
+				},
+				Action: runReconcile,
+			},
+			{
+				Name:  "compact",
+				Usage: "Rewrite stored snapshots as weekly full snapshots plus deltas (--dedupe), or back to full rows (--expand)",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dedupe",
+						Usage: "Store each snapshot as a delta against the keyframe of its window",
+					},
+					&cli.BoolFlag{
+						Name:  "expand",
+						Usage: "Rewrite every delta as a full snapshot (required before rolling back migration 004)",
+					},
+					&cli.IntFlag{
+						Name:  "keyframe-days",
+						Usage: "Days between full snapshots",
+						Value: 7,
+					},
+				},
+				Action: runCompact,
+			},
+			{
+				Name:  "publish",
+				Usage: "Publish snapshot JSON, indicator CSVs and index.json to PUBLISH_TARGET (latest snapshot by default)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "First date to publish (YYYY-MM-DD); dates without a snapshot are skipped",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Last date to publish (YYYY-MM-DD, default today)",
+					},
+				},
+				Action: runPublish,
+			},
+			{
+				Name:  "period-report",
+				Usage: "Generate and store the month-end or quarter-end report for a period",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "period",
+						Usage:    "Period to report: YYYY-MM or YYYY-QN",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "notify",
+						Usage: "Also send the report summary through the notification providers",
+					},
+				},
+				Action: runPeriodReport,
+			},
 			{
 				Name:   "notify",
 				Usage:  "Check today's report and send a notification with key indicators and alerts",
 				Action: runNotify,
 			},
+			{
+				Name:   "whale-alerts",
+				Usage:  "Scan fund account payments since the last run and alert on large transfers",
+				Action: runWhaleAlerts,
+			},
+			{
+				Name:   "intraday-sample",
+				Usage:  "Sample the intraday indicators (I4, I10) once, e.g. from cron",
+				Action: runIntradaySample,
+			},
+			{
+				Name:  "annotate",
+				Usage: "Attach notes to snapshot dates, shown with indicator comparisons and in the MONITORING sheet",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "add",
+						Usage:     "Annotate a snapshot date",
+						ArgsUsage: "TEXT",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "date",
+								Usage:    "Snapshot date (YYYY-MM-DD)",
+								Required: true,
+							},
+							&cli.StringSliceFlag{
+								Name:  "tag",
+								Usage: "Tag (lowercase letters, digits and dashes; repeatable)",
+							},
+						},
+						Action: runAnnotateAdd,
+					},
+					{
+						Name:  "list",
+						Usage: "List annotations, oldest first",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "from",
+								Usage: "First date (YYYY-MM-DD; default: unbounded)",
+							},
+							&cli.StringFlag{
+								Name:  "to",
+								Usage: "Last date (YYYY-MM-DD; default: unbounded)",
+							},
+						},
+						Action: runAnnotateList,
+					},
+					{
+						Name:      "delete",
+						Usage:     "Delete an annotation",
+						ArgsUsage: "ID",
+						Action:    runAnnotateDelete,
+					},
+				},
+			},
+			{
+				Name:  "watchlist",
+				Usage: "Manage partner accounts outside the fund whose balances are captured in snapshots",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "add",
+						Usage:     "Watch an account, or update its label and tracked assets",
+						ArgsUsage: "ADDRESS",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "label",
+								Usage:    "Display name",
+								Required: true,
+							},
+							&cli.StringSliceFlag{
+								Name:  "asset",
+								Usage: "Tracked asset, CODE:ISSUER or XLM (repeatable; default: every non-zero balance)",
+							},
+						},
+						Action: runWatchlistAdd,
+					},
+					{
+						Name:   "list",
+						Usage:  "List watched accounts",
+						Action: runWatchlistList,
+					},
+					{
+						Name:      "delete",
+						Usage:     "Stop watching an account",
+						ArgsUsage: "ADDRESS",
+						Action:    runWatchlistDelete,
+					},
+				},
+			},
+			{
+				Name:  "indicator-definitions",
+				Usage: "Record indicator formula changes, flagged on comparisons that span them",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "add",
+						Usage:     "Record a new definition of an indicator, as its next version",
+						ArgsUsage: "SUMMARY",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:     "id",
+								Usage:    "Indicator ID",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "effective",
+								Usage:    "First date computed with the new definition (YYYY-MM-DD)",
+								Required: true,
+							},
+						},
+						Action: runDefinitionsAdd,
+					},
+					{
+						Name:  "list",
+						Usage: "List definition changes by indicator and version",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "id",
+								Usage: "Only this indicator (default: all)",
+							},
+						},
+						Action: runDefinitionsList,
+					},
+					{
+						Name:      "delete",
+						Usage:     "Delete a definition change",
+						ArgsUsage: "ID",
+						Action:    runDefinitionsDelete,
+					},
+				},
+			},
+			{
+				Name:  "export-outbox",
+				Usage: "Inspect and retry the Google Sheets exports still owed for stored snapshots",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "List the snapshots not exported yet, oldest first",
+						Action: runExportOutboxList,
+					},
+					{
+						Name:   "retry",
+						Usage:  "Export every pending snapshot now, ignoring the retry backoff",
+						Action: runExportOutboxRetry,
+					},
+				},
+			},
+			{
+				Name:   "doctor",
+				Usage:  "Check the database, Horizon, fund accounts, CoinGecko and Google Sheets",
+				Action: runDoctor,
+			},
 		},
 	}
 
-	if err := app.RunContext(ctx, os.Args); err != nil {
-		log.Fatal(err)
+	instrument(app.Commands)
+	app.Commands = append(app.Commands, &cli.Command{
+		Name:      "completion",
+		Usage:     "Print the shell completion script (source <(stat completion bash))",
+		ArgsUsage: "bash|zsh|fish",
+		Action:    runCompletion,
+	})
+
+	err := app.RunContext(ctx, os.Args)
+	if perr := printResult(os.Stdout, os.Stderr, app.Metadata, err); perr != nil {
+		slog.Error("printing result", "error", perr)
+	}
+	if err != nil {
+		stop()
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -131,45 +693,64 @@ func runQuote(c *cli.Context) error {
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
 	}
 
-	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	store, err := openStore(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return err
 	}
-	defer pool.Close()
+	defer store.Close()
 
-	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
-		return fmt.Errorf("running migrations: %w", err)
+	maxAge := cfg.QuoteFreshFor
+	if c.IsSet("fresh-for") {
+		maxAge = c.Duration("fresh-for")
+	}
+	if c.Bool("force") {
+		maxAge = 0
 	}
 
-	coingecko := external.NewCoinGeckoClient(cfg.CoinGeckoURL, cfg.CoinGeckoDelay, cfg.CoinGeckoRetryMax)
-	quoteRepo := external.NewPgQuoteRepository(pool)
-	externalSvc := external.NewService(coingecko, quoteRepo)
+	externalSvc := external.NewService(newCoinGeckoClient(cfg, store.Pool), store.Quotes)
 
-	if err := externalSvc.FetchAndStoreQuotes(ctx); err != nil {
-		return fmt.Errorf("fetching quotes: %w", err)
+	res, err := externalSvc.FetchAndStoreQuotes(ctx, maxAge)
+	if err != nil {
+		return externalError("fetching quotes: %w", err)
 	}
 
-	slog.Info("quotes fetched successfully")
-	return nil
+	for symbol, msg := range res.Failed {
+		slog.Error("quote fetch failed", "symbol", symbol, "error", msg)
+	}
+	slog.Info("quotes fetched", "fetched", len(res.Fetched), "skipped", len(res.Skipped), "failed", len(res.Failed))
+	setResult(c, result{{"fetched", res.Fetched}, {"skipped", res.Skipped}, {"failed", res.Failed}})
+	attempted := len(res.Fetched) + len(res.Failed)
+	if len(res.Failed) > 0 && len(res.Fetched) == 0 {
+		return externalError("fetching quotes: all %d symbols failed", attempted)
+	}
+	return partialIf(len(res.Failed), attempted, "symbols")
 }
 
-func runNotify(c *cli.Context) error {
+func runQuoteBackfill(c *cli.Context) error {
 	ctx := c.Context
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
 	}
-	if cfg.GristAPIKey == "" {
-		return fmt.Errorf("GRIST_KEY is required")
+
+	from, err := time.Parse("2006-01-02", c.String("from"))
+	if err != nil {
+		return configError("invalid --from date: %w", err)
+	}
+	to := time.Now().UTC()
+	if v := c.String("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return configError("invalid --to date: %w", err)
+		}
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -177,53 +758,44 @@ func runNotify(c *cli.Context) error {
 		return fmt.Errorf("running migrations: %w", err)
 	}
 
-	indicatorRepo := indicator.NewPgRepository(pool)
-	gristClient := grist.NewClient(cfg.GristAPIURL, cfg.GristDocID, cfg.GristAPIKey)
-	gristProvider := notify.NewGristProvider(gristClient, cfg.GristTableID, cfg.GristChatID, cfg.GristTopicID)
+	coingecko := newCoinGeckoClient(cfg, pool)
+	externalSvc := external.NewService(coingecko, external.NewPgQuoteRepository(pool))
 
-	notifyCfg := notify.Config{
-		Mentions:  notify.ParseMentions(cfg.NotifyMentions),
-		ReportURL: "https://stat.mtlf.me",
+	stored, err := externalSvc.BackfillQuotes(ctx, from, to)
+	if err != nil {
+		return externalError("backfilling quotes: %w", err)
 	}
-	svc := notify.NewService(indicatorRepo, []notify.Provider{gristProvider}, notifyCfg)
-
-	return svc.Run(ctx)
-}
 
-// configureLogger installs a slog handler whose level honours LOG_LEVEL
-// (debug|info|warn|error). Default is INFO. Anything unknown also defaults
-// to INFO so a typo doesn't silently raise the level.
-func configureLogger() {
-	level := slog.LevelInfo
-	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
-	case "debug":
-		level = slog.LevelDebug
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	}
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+	slog.Info("quote history backfilled", "from", from.Format("2006-01-02"), "to", to.Format("2006-01-02"), "quotes", stored)
+	setResult(c, result{{"from", from.Format("2006-01-02")}, {"to", to.Format("2006-01-02")}, {"quotes", stored}})
+	return nil
 }
 
-// reportTimeout caps the daily report run. Anything longer is a regression we
-// want surfaced as a non-zero exit so Railway alerts the maintainer instead of
-// silently overlapping with the next day's cron.
-const reportTimeout = 30 * time.Minute
-
-func runReport(c *cli.Context) error {
-	ctx, cancel := context.WithTimeout(c.Context, reportTimeout)
-	defer cancel()
-
+// runQuoteSet stores a quote CoinGecko doesn't provide, such as the EUR price
+// per m² a registered property is valued by, with today's quote history row.
+// CoinGecko symbols are refused: the next `stat quote` would overwrite them.
+func runQuoteSet(c *cli.Context) error {
+	ctx := c.Context
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
+	}
+	if c.NArg() != 2 {
+		return configError("usage: stat quote set SYMBOL PRICE_IN_EUR")
+	}
+	symbol := c.Args().Get(0)
+	if _, ok := external.SymbolMapping()[symbol]; ok {
+		return configError("%s is fetched from CoinGecko by `stat quote`", symbol)
+	}
+	price, err := decimal.NewFromString(c.Args().Get(1))
+	if err != nil || !price.IsPositive() {
+		return configError("invalid price %q: want a positive number", c.Args().Get(1))
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -231,116 +803,112 @@ func runReport(c *cli.Context) error {
 		return fmt.Errorf("running migrations: %w", err)
 	}
 
-	horizonClient := horizon.NewClient(cfg.HorizonURL, cfg.HorizonRetryMax, cfg.HorizonRetryBaseDelay)
-	portfolioSvc := portfolio.NewService(horizonClient)
-	priceSvc := price.NewService(horizonClient)
-	valuationSvc := valuation.NewService(horizonClient)
-
-	coingecko := external.NewCoinGeckoClient(cfg.CoinGeckoURL, cfg.CoinGeckoDelay, cfg.CoinGeckoRetryMax)
-	quoteRepo := external.NewPgQuoteRepository(pool)
-	externalSvc := external.NewService(coingecko, quoteRepo)
+	repo := external.NewPgQuoteRepository(pool)
+	if err := repo.SaveQuote(ctx, symbol, price); err != nil {
+		return err
+	}
+	today := time.Now().UTC()
+	if err := repo.SaveQuoteHistory(ctx, symbol, today, price); err != nil {
+		return err
+	}
 
-	fundSvc := fund.NewService(portfolioSvc, priceSvc, valuationSvc, externalSvc)
+	slog.Info("quote stored", "symbol", symbol, "priceInEur", price)
+	setResult(c, result{{"symbol", symbol}, {"priceInEur", price.String()}, {"date", today.Format("2006-01-02")}})
+	return nil
+}
 
-	snapshotRepo := snapshot.NewPgRepository(pool)
-	indicatorRepo := indicator.NewPgRepository(pool)
-	var fundAddrs []string
-	for _, a := range domain.AccountRegistry() {
-		fundAddrs = append(fundAddrs, a.Address)
-	}
-	expertClient := stellarexpert.NewClient(cfg.StellarExpertURL)
-	metricsSvc := metrics.NewService(horizonClient, priceSvc, expertClient, indicatorRepo, fundAddrs)
-	snapshotSvc := snapshot.NewService(fundSvc, snapshotRepo, metricsSvc)
+// runBackfillSnapshots regenerates one snapshot per day in [from, to] as of
+// the end of that day. Existing snapshots are kept unless --overwrite is set.
+// Indicators are not recomputed here; run backfill-indicators afterwards.
+func runBackfillSnapshots(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
 
-	if _, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
-		return fmt.Errorf("ensuring entity: %w", err)
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
 	}
 
-	now := time.Now().UTC()
-	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-
-	stage := startStage("snapshot_generate")
-	data, err := snapshotSvc.Generate(ctx, "mtlf", date)
+	from, err := time.Parse("2006-01-02", c.String("from"))
 	if err != nil {
-		return fmt.Errorf("generating snapshot: %w", err)
+		return configError("invalid --from date: %w", err)
 	}
-	stage.done("date", date.Format("2006-01-02"))
-
-	hist := &indicator.HistoricalData{Repo: snapshotRepo, IndicatorRepo: indicatorRepo, Slug: "mtlf"}
-	indicatorSvc := indicator.NewService(hist)
-
-	stage = startStage("indicator_calculate")
-	indicators, err := indicatorSvc.CalculateAll(ctx, data)
+	clock, err := snapshotClock(cfg)
 	if err != nil {
-		return fmt.Errorf("calculating indicators: %w", err)
+		return err
+	}
+	to := clock.Today().AddDate(0, 0, -1)
+	if v := c.String("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return configError("invalid --to date: %w", err)
+		}
+	}
+	if to.Before(from) {
+		return configError("--to %s is before --from %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
 	}
-	stage.done("count", len(indicators))
 
-	entityID, err := snapshotRepo.GetEntityID(ctx, "mtlf")
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("getting entity id for indicator persistence: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
+	defer pool.Close()
 
-	stage = startStage("indicator_persist")
-	if err := indicatorRepo.Save(ctx, entityID, date, indicators); err != nil {
-		return fmt.Errorf("persisting indicators: %w", err)
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
 	}
-	stage.done("count", len(indicators), "date", date.Format("2006-01-02"))
 
-	if cfg.GoogleSheetsSpreadsheetID != "" && cfg.GoogleCredentialsJSON != "" {
-		sheetsWriter, err := export.NewSheetsWriter(ctx, cfg.GoogleSheetsSpreadsheetID, cfg.GoogleCredentialsJSON)
-		if err != nil {
-			return fmt.Errorf("initializing Google Sheets writer: %w", err)
-		}
-		exportSvc := export.NewService(indicatorRepo, sheetsWriter)
+	pipeline, err := newReportPipeline(cfg, pool)
+	if err != nil {
+		return err
+	}
+	if _, err := pipeline.snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+		return fmt.Errorf("ensuring entity: %w", err)
+	}
+	pipeline.horizon.CheckHealth(ctx)
+	defer pipeline.logHorizonStats()
 
-		stage = startStage("sheets_export_indall")
-		rows, err := exportSvc.Export(ctx, indicators)
-		if err != nil {
-			return fmt.Errorf("exporting to Google Sheets: %w", err)
+	var generated, skipped int
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if !c.Bool("overwrite") {
+			_, err := pipeline.snapshotRepo.GetByDate(ctx, "mtlf", date)
+			if err == nil {
+				skipped++
+				continue
+			}
+			if !errors.Is(err, snapshot.ErrNotFound) {
+				return fmt.Errorf("checking snapshot for %s: %w", date.Format("2006-01-02"), err)
+			}
 		}
-		stage.done()
-
-		stage = startStage("sheets_append_monitoring")
-		if err := sheetsWriter.AppendMonitoring(ctx, rows); err != nil {
-			return fmt.Errorf("appending MONITORING row: %w", err)
+		if _, err := pipeline.backfillSnapshot(ctx, date); err != nil {
+			if errors.Is(err, horizon.ErrBeforeHistory) {
+				slog.Error("date outside Horizon history, skipping", "date", date.Format("2006-01-02"), "error", err)
+				skipped++
+				continue
+			}
+			return err
 		}
-		stage.done()
+		generated++
 	}
 
+	slog.Info("snapshot backfill complete", "generated", generated, "skipped", skipped,
+		"hint", "run backfill-indicators to recompute indicators for the regenerated dates")
+	setResult(c, result{{"generated", generated}, {"skipped", skipped}})
 	return nil
 }
 
-// stageTimer captures the duration of a discrete report stage and emits an
-// info-level summary on done(). Used to spot which step blew past its budget.
-type stageTimer struct {
-	name  string
-	start time.Time
-}
-
-func startStage(name string) stageTimer {
-	slog.Info("stage started", "name", name)
-	return stageTimer{name: name, start: time.Now()}
-}
-
-func (s stageTimer) done(extra ...any) {
-	args := []any{"name", s.name, "duration_ms", time.Since(s.start).Milliseconds()}
-	args = append(args, extra...)
-	slog.Info("stage completed", args...)
-}
-
-func runImport(c *cli.Context) error {
+func runPublish(c *cli.Context) error {
 	ctx := c.Context
 	cfg := config.Load()
-	apiURL := c.String("api-url")
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
+	}
+	if cfg.PublishTarget == "" {
+		return configError("PUBLISH_TARGET is required")
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -349,18 +917,554 @@ func runImport(c *cli.Context) error {
 	}
 
 	snapshotRepo := snapshot.NewPgRepository(pool)
-	indicatorRepo := indicator.NewPgRepository(pool)
+	publisher, err := newPublisher(cfg, snapshotRepo, indicator.NewPgRepository(pool))
+	if err != nil {
+		return err
+	}
+
+	if c.String("from") == "" {
+		latest, err := snapshotRepo.GetLatest(ctx, "mtlf")
+		if err != nil {
+			return fmt.Errorf("loading latest snapshot: %w", err)
+		}
+		if err := publisher.Publish(ctx, "mtlf", latest.SnapshotDate); err != nil {
+			return externalError("publishing %s: %w", latest.SnapshotDate.Format("2006-01-02"), err)
+		}
+		setResult(c, result{{"published", 1}, {"date", latest.SnapshotDate.Format("2006-01-02")}})
+		return nil
+	}
+
+	from, err := time.Parse("2006-01-02", c.String("from"))
+	if err != nil {
+		return configError("invalid --from date: %w", err)
+	}
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return err
+	}
+	to := clock.Today()
+	if v := c.String("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return configError("invalid --to date: %w", err)
+		}
+	}
+
+	var published, skipped int
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if err := publisher.PublishDay(ctx, "mtlf", date); err != nil {
+			if errors.Is(err, snapshot.ErrNotFound) {
+				skipped++
+				continue
+			}
+			return externalError("publishing %s: %w", date.Format("2006-01-02"), err)
+		}
+		published++
+	}
+	if err := publisher.WriteIndex(ctx, "mtlf"); err != nil {
+		return externalError("%w", err)
+	}
+	slog.Info("dataset publish complete", "published", published, "skipped", skipped)
+	setResult(c, result{{"published", published}, {"skipped", skipped}})
+	return nil
+}
+
+func runNotify(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	if cfg.GristAPIKey == "" {
+		return configError("GRIST_KEY is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	notifier, err := newNotifier(cfg, indicator.NewPgRepository(pool))
+	if err != nil {
+		return err
+	}
+	return notifier.Run(ctx)
+}
+
+func runWhaleAlerts(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	if cfg.WhaleAlertMinEURMTL <= 0 {
+		return configError("WHALE_ALERT_MIN_EURMTL must be positive")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	// Without Grist the alerts are only logged and stored.
+	var notifier whale.Notifier
+	if cfg.GristAPIKey != "" {
+		n, err := newNotifier(cfg, indicator.NewPgRepository(pool))
+		if err != nil {
+			return err
+		}
+		notifier = n
+	} else {
+		slog.Info("GRIST_KEY not set, whale alerts will not be sent")
+	}
+
+	svc := whale.NewService(newHorizonClient(cfg), snapshot.NewPgRepository(pool), whale.NewPgRepository(pool), notifier,
+		domain.AccountRegistry(), decimal.NewFromFloat(cfg.WhaleAlertMinEURMTL), "mtlf")
+	res, err := svc.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("scanning for whale transfers: %w", err)
+	}
+	notified := lo.CountBy(res.Alerts, func(a whale.Alert) bool { return a.Notified })
+	setResult(c, result{{"alerts", len(res.Alerts)}, {"notified", notified}, {"failed", len(res.Failed)}})
+	return partialIf(len(res.Failed), len(domain.AccountRegistry()), "account scans")
+}
+
+func runIntradaySample(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	samples, err := newIntradaySampler(cfg, pool).Sample(ctx)
+	if err != nil {
+		slog.Error("intraday sampling incomplete", "error", err)
+	}
+	if len(samples) == 0 && err != nil {
+		return externalError("sampling intraday indicators: %w", err)
+	}
+	setResult(c, result{{"sampled", len(samples)}})
+	return partialIf(len(intraday.Indicators)-len(samples), len(intraday.Indicators), "indicators")
+}
+
+// reportURL is the public site linked from notifications.
+const reportURL = "https://stat.mtlf.me"
+
+// newNotifier builds the notification service delivering through Grist.
+func newNotifier(cfg config.Config, indicatorRepo indicator.Repository) (*notify.Service, error) {
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+	gristClient := grist.NewClient(cfg.GristAPIURL, cfg.GristDocID, cfg.GristAPIKey, grist.WithTransport(roundTripper()))
+	gristProvider := notify.NewGristProvider(gristClient, cfg.GristTableID, cfg.GristChatID, cfg.GristTopicID)
+
+	notifyCfg := notify.Config{
+		Mentions:  notify.ParseMentions(cfg.NotifyMentions),
+		ReportURL: reportURL,
+		Clock:     clock,
+	}
+	return notify.NewService(indicatorRepo, []notify.Provider{gristProvider}, notifyCfg), nil
+}
+
+// newPeriodService builds the period report service, with the per-subfond
+// ROI from the stored subfond flows.
+func newPeriodService(pool *pgxpool.Pool, indicatorRepo indicator.Repository) *period.Service {
+	return period.NewService(indicatorRepo, period.NewPgRepository(pool), period.WithSubfondFlows(subfond.NewPgRepository(pool)))
+}
+
+// generatePeriodReport builds and stores the report for p and, with
+// sendNotification, posts its summary through the notifier.
+func generatePeriodReport(ctx context.Context, cfg config.Config, pool *pgxpool.Pool, indicatorRepo indicator.Repository, p period.Period, sendNotification bool) (*period.Report, error) {
+	r, err := newPeriodService(pool, indicatorRepo).Generate(ctx, "mtlf", p)
+	if err != nil {
+		return nil, fmt.Errorf("generating %s report: %w", p, err)
+	}
+	slog.Info("period report stored", "period", r.Period, "indicators", len(r.Indicators), "dividends", r.Dividends.Total.String())
+	if !sendNotification {
+		return r, nil
+	}
+	msg := period.HTML(r, reportURL+"/api/v1/reports/"+r.Period+"?format=markdown")
+	notifier, err := newNotifier(cfg, indicatorRepo)
+	if err != nil {
+		return r, err
+	}
+	if err := notifier.SendMessage(ctx, p.End, msg); err != nil {
+		return r, externalError("sending %s report: %w", p, err)
+	}
+	return r, nil
+}
+
+func runPeriodReport(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	p, err := period.Parse(c.String("period"))
+	if err != nil {
+		return err
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	r, err := generatePeriodReport(ctx, cfg, pool, indicator.NewPgRepository(pool), p, c.Bool("notify"))
+	if r != nil {
+		setResult(c, result{{"period", r.Period}, {"indicators", len(r.Indicators)}, {"dividends", r.Dividends.Total}})
+	}
+	return err
+}
+
+// configureLogger installs a slog handler whose level honours LOG_LEVEL
+// (debug|info|warn|error). Default is INFO. Anything unknown also defaults
+// to INFO so a typo doesn't silently raise the level.
+func configureLogger() {
+	level := slog.LevelInfo
+	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
+	case "debug":
+		level = slog.LevelDebug
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
+
+// runAssociationReport stores the association snapshot. It runs on its own
+// schedule; `stat report` picks up the latest one for I28/I29.
+func runAssociationReport(c *cli.Context) error {
+	ctx, cancel := context.WithTimeout(c.Context, reportTimeout)
+	defer cancel()
+
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	pipeline, err := newReportPipeline(cfg, pool)
+	if err != nil {
+		return err
+	}
+	if _, err := pipeline.snapshotRepo.EnsureEntity(ctx, association.Slug, association.Name, association.Description); err != nil {
+		return fmt.Errorf("ensuring entity: %w", err)
+	}
+
+	date := pipeline.clock.Today()
+	if v := c.String("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return configError("invalid --date: %w", err)
+		}
+		if d.After(date) {
+			return configError("--date %s is after today (%s)", v, date.Format("2006-01-02"))
+		}
+		date = d
+	}
+
+	data, err := pipeline.runAssociation(ctx, date)
+	if err != nil {
+		return err
+	}
+	endowment, _ := association.Endowment(data)
+	setResult(c, result{{"entity", association.Slug}, {"date", date.Format("2006-01-02")},
+		{"totalEURMTL", data.AggregatedTotals.TotalEURMTL.String()}, {"endowmentEURMTL", endowment.String()}})
+	return nil
+}
+
+// reportTimeout caps the daily report run. Anything longer is a regression we
+// want surfaced as a non-zero exit so Railway alerts the maintainer instead of
+// silently overlapping with the next day's cron.
+const reportTimeout = 30 * time.Minute
+
+func runReport(c *cli.Context) error {
+	ctx, cancel := context.WithTimeout(c.Context, reportTimeout)
+	defer cancel()
+
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	if c.Bool("dry-run") {
+		if c.Bool("sheets-diff") {
+			return configError("--dry-run and --sheets-diff are exclusive")
+		}
+		return runReportDryRun(ctx, c, cfg)
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	pipeline, err := newReportPipeline(cfg, pool)
+	if err != nil {
+		return err
+	}
+	indicatorRepo := pipeline.indicatorRepo
+
+	if _, err := pipeline.snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+		return fmt.Errorf("ensuring entity: %w", err)
+	}
+	if c.Bool("sheets-diff") {
+		return runSheetsDiff(ctx, c, cfg, pool, pipeline)
+	}
+
+	date, err := reportDate(c, pipeline.clock)
+	if err != nil {
+		return err
+	}
+
+	// The SLO covers the run and its export, the part of the day's report
+	// readers wait on.
+	var res indicator.PartialResult
+	err = pipeline.tracked(ctx, date, func(ctx context.Context) error {
+		var err error
+		res, err = pipeline.run(ctx, date)
+		if err != nil {
+			if progress.IsCancelled(err) {
+				// Each write is guarded, so the worst case is a saved snapshot with no
+				// indicators for the date — backfill-indicators repairs that.
+				slog.Error("report cancelled before completion", "date", date.Format("2006-01-02"), "error", err)
+			}
+			return err
+		}
+
+		// The pipeline queued this date's export. Drain the outbox oldest first;
+		// a past date's MONITORING row is inserted before the later dates, and
+		// a failed export stays queued for the serve worker or
+		// `stat export-outbox retry`.
+		if pipeline.exports != nil {
+			defer slo.Since(ctx, slo.StageExport, time.Now())
+			exporter, err := newSheetsExporter(ctx, cfg, pool, indicatorRepo, pipeline.snapshots, pipeline.clock)
+			if err != nil {
+				return err
+			}
+			exporter.runDate, exporter.run = date, &res
+			attempts, err := newExportOutbox(cfg, pipeline.exports, exporter).Drain(ctx, true)
+			if err != nil {
+				return err
+			}
+			if n := len(attempts); n > 0 && attempts[n-1].Err != nil {
+				return externalError("exporting %s to Google Sheets (queued for retry): %w", attempts[n-1].Date.Format("2006-01-02"), attempts[n-1].Err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	publisher, err := newPublisher(cfg, pipeline.snapshotRepo, indicatorRepo)
+	if err != nil {
+		return err
+	}
+	if publisher != nil {
+		stage := startStage("publish_dataset")
+		if err := publisher.Publish(ctx, "mtlf", date); err != nil {
+			return externalError("publishing dataset: %w", err)
+		}
+		stage.done()
+	}
+
+	kinds, err := period.ParseKinds(cfg.ReportPeriods)
+	if err != nil {
+		return configError("parsing REPORT_PERIODS: %w", err)
+	}
+	var reports []string
+	for _, p := range period.EndingOn(date, kinds) {
+		stage := startStage("period_report_" + string(p.Kind))
+		if _, err := generatePeriodReport(ctx, cfg, pool, indicatorRepo, p, cfg.ReportNotify); err != nil {
+			return err
+		}
+		reports = append(reports, p.String())
+		stage.done()
+	}
+
+	unavailable := res.UnavailableIDs()
+	setResult(c, result{
+		{"date", date.Format("2006-01-02")},
+		{"indicators", len(res.Indicators)},
+		{"unavailable", unavailable},
+		{"periodReports", reports},
+	})
+	if len(res.Failures) > 0 {
+		return partialError("%d indicator calculators failed (indicators %v unavailable)", len(res.Failures), unavailable)
+	}
+	return nil
+}
+
+// sheetsLocale builds the Sheets export locale from the SHEETS_* settings.
+func sheetsLocale(cfg config.Config) (export.Locale, error) {
+	loc, err := export.NewLocale(cfg.SheetsLocale, cfg.SheetsDateFormat, cfg.SheetsCurrencyFormat)
+	if err != nil {
+		return export.Locale{}, configError("invalid SHEETS_* formatting settings: %w", err)
+	}
+	return loc, nil
+}
+
+// comparisonMode parses COMPARISON_MODE.
+func comparisonMode(cfg config.Config) (period.Comparison, error) {
+	c, err := period.ParseComparison(cfg.ComparisonMode)
+	if err != nil {
+		return "", configError("invalid COMPARISON_MODE: %w", err)
+	}
+	return c, nil
+}
+
+// snapshotClock builds the snapshot date clock from SNAPSHOT_TIMEZONE and
+// SNAPSHOT_CUTOFF.
+func snapshotClock(cfg config.Config) (snapdate.Clock, error) {
+	clock, err := snapdate.New(cfg.SnapshotTimezone, cfg.SnapshotCutoff)
+	if err != nil {
+		return snapdate.Clock{}, configError("invalid SNAPSHOT_* date settings: %w", err)
+	}
+	return clock, nil
+}
+
+func runCompact(c *cli.Context) error {
+	ctx := c.Context
+	dedupe, expand := c.Bool("dedupe"), c.Bool("expand")
+	if dedupe == expand {
+		return configError("pass exactly one of --dedupe or --expand")
+	}
+
+	cfg := config.Load()
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+
+	if dedupe {
+		stage := startStage("snapshot_compact")
+		res, err := snapshotRepo.Compact(ctx, "mtlf", c.Int("keyframe-days"))
+		if err != nil {
+			return fmt.Errorf("compacting snapshots: %w", err)
+		}
+		stage.done("full", res.Full, "delta", res.Delta, "bytes_before", res.BytesBefore, "bytes_after", res.BytesAfter)
+		setResult(c, result{{"full", res.Full}, {"delta", res.Delta}, {"bytesBefore", res.BytesBefore}, {"bytesAfter", res.BytesAfter}})
+		return nil
+	}
+
+	stage := startStage("snapshot_expand")
+	res, err := snapshotRepo.Expand(ctx, "mtlf")
+	if err != nil {
+		return fmt.Errorf("expanding snapshots: %w", err)
+	}
+	stage.done("expanded", res.Full, "bytes_before", res.BytesBefore, "bytes_after", res.BytesAfter)
+	setResult(c, result{{"expanded", res.Full}, {"bytesBefore", res.BytesBefore}, {"bytesAfter", res.BytesAfter}})
+	return nil
+}
+
+// stageTimer captures the duration of a discrete report stage and emits an
+// info-level summary on done(). Used to spot which step blew past its budget.
+type stageTimer struct {
+	name  string
+	start time.Time
+}
+
+func startStage(name string) stageTimer {
+	slog.Info("stage started", "name", name)
+	return stageTimer{name: name, start: time.Now()}
+}
+
+func (s stageTimer) done(extra ...any) {
+	args := []any{"name", s.name, "duration_ms", time.Since(s.start).Milliseconds()}
+	args = append(args, extra...)
+	slog.Info("stage completed", args...)
+}
+
+func runImport(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+	apiURL := c.String("api-url")
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+	indicatorRepo := indicator.NewPgRepository(pool)
 	entityID, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics")
 	if err != nil {
 		return fmt.Errorf("ensuring entity: %w", err)
 	}
 
-	httpClient := &http.Client{Timeout: 30 * time.Second}
+	httpClient := &http.Client{Timeout: 30 * time.Second, Transport: roundTripper()}
 
 	// Fetch snapshot date list from old API.
 	dates, err := fetchOldSnapshots(ctx, httpClient, apiURL)
 	if err != nil {
-		return fmt.Errorf("fetching snapshot list: %w", err)
+		return externalError("fetching snapshot list: %w", err)
 	}
 	slog.Info("fetched snapshot list", "count", len(dates))
 
@@ -411,103 +1515,416 @@ func runImport(c *cli.Context) error {
 		slog.Info("imported snapshot", "date", date.Format("2006-01-02"))
 	}
 
-	slog.Info("import complete", "imported", imported, "skipped", skipped, "errors", len(dates)-imported-skipped)
+	failed := len(dates) - imported - skipped
+	slog.Info("import complete", "imported", imported, "skipped", skipped, "errors", failed)
+	setResult(c, result{{"imported", imported}, {"skipped", skipped}, {"failed", failed}})
 
 	// Export to Google Sheets if configured.
-	if cfg.GoogleSheetsSpreadsheetID == "" || cfg.GoogleCredentialsJSON == "" {
+	if !sheetsConfigured(cfg, "mtlf") {
 		slog.Info("Google Sheets not configured, skipping export")
+		return partialIf(failed, len(dates), "snapshots")
+	}
+
+	indexCfg, err := indicatorRepo.GetIndexConfig(ctx, "mtlf")
+	if err != nil {
+		return err
+	}
+	hist := &indicator.HistoricalData{Repo: snapshotRepo, IndicatorRepo: indicatorRepo, Slug: "mtlf", Index: &indexCfg}
+
+	loc, err := sheetsLocale(cfg)
+	if err != nil {
+		return err
+	}
+	sheetsWriter, err := newSheetsWriter(ctx, cfg, "mtlf", export.WithLocale(loc),
+		export.WithMonitoringMirror(export.NewPgMonitoringMirror(pool)))
+	if err != nil {
+		return externalError("initializing Google Sheets writer: %w", err)
+	}
+
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return err
+	}
+	indicatorSvc := indicator.NewService(hist, indicatorOpts...)
+
+	// IDs that produce correct values from snapshot data alone. Layer0 (I51-I53,
+	// I56, I58-I61) reads only account balances/prices stored in the snapshot.
+	// Layer1 I3 (Assets Value) and I4 (Operating Balance) depend on Layer0 outputs.
+	// Other Layer1+ indicators (I5-I7, I10, etc.) require live_metrics — for legacy
+	// snapshots without that block they resolve to zero and we omit them.
+	snapshotOnlyIDs := map[int]bool{
+		3: true, 4: true,
+		51: true, 52: true, 53: true, 56: true, 58: true, 59: true, 60: true, 61: true,
+	}
+
+	if _, err := sheetsWriter.CheckAccess(ctx); err != nil {
+		return externalError("checking Google Sheets access: %w", err)
+	}
+
+	// Delete existing MONITORING sheet so the bulk import starts clean.
+	if err := sheetsWriter.DeleteMonitoringSheet(ctx); err != nil {
+		return externalError("deleting MONITORING sheet: %w", err)
+	}
+
+	params := export.NewPgParameters(pool)
+	regulatoryPrice, err := params.RegulatoryPrice(ctx, "mtlf")
+	if err != nil {
+		return err
+	}
+	notes := export.MonitoringNotes{RegulatoryPrice: &regulatoryPrice}
+
+	// Append MONITORING rows for all dates (oldest first).
+	sortedDates := make([]time.Time, len(dates))
+	copy(sortedDates, dates)
+	sort.Slice(sortedDates, func(i, j int) bool { return sortedDates[i].Before(sortedDates[j]) })
+
+	for _, d := range sortedDates {
+		date := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+
+		snap, err := snapshotRepo.GetByDate(ctx, "mtlf", date)
+		if err != nil {
+			slog.Debug("monitoring: snapshot not found", "date", date.Format("2006-01-02"), "error", err)
+			continue
+		}
+
+		var fundData domain.FundStructureData
+		if err := json.Unmarshal(snap.Data, &fundData); err != nil {
+			slog.Error("monitoring: failed to unmarshal snapshot", "date", date.Format("2006-01-02"), "error", err)
+			continue
+		}
+
+		// Snapshots with live_metrics get the full indicator set; legacy snapshots
+		// without it get only the snapshot-computable subset (Layer0 + I3/I4).
+		hasLiveMetrics := fundData.LiveMetrics != nil
+		indicators, err := indicatorSvc.CalculateAll(ctx, fundData)
+		if err != nil {
+			slog.Error("monitoring: failed to calculate indicators", "date", date.Format("2006-01-02"), "error", err)
+			continue
+		}
+
+		var rows []export.IndicatorRow
+		if hasLiveMetrics {
+			rows = lo.Map(indicators, func(ind indicator.Indicator, _ int) export.IndicatorRow {
+				return export.IndicatorRow{Indicator: ind}
+			})
+		} else {
+			rows = lo.FilterMap(indicators, func(ind indicator.Indicator, _ int) (export.IndicatorRow, bool) {
+				if !snapshotOnlyIDs[ind.ID] {
+					return export.IndicatorRow{}, false
+				}
+				return export.IndicatorRow{Indicator: ind}, true
+			})
+		}
+
+		if err := sheetsWriter.AppendMonitoringRowOnly(ctx, rows, date, notes); err != nil {
+			slog.Error("monitoring: failed to append row", "date", date.Format("2006-01-02"), "error", err)
+			continue
+		}
+
+		slog.Info("appended MONITORING row", "date", date.Format("2006-01-02"), "full", hasLiveMetrics)
+
+		// Respect Google Sheets API rate limits (60 reads and 60 writes/min).
+		time.Sleep(sheetsWriter.AppendPause())
+	}
+
+	// Apply MONITORING formatting once after all rows are written.
+	if err := sheetsWriter.ApplyMonitoringFormatting(ctx); err != nil {
+		slog.Error("failed to apply MONITORING formatting", "error", err)
+	}
+
+	// Update IND_ALL / IND_MAIN with current data.
+	compare, err := comparisonMode(cfg)
+	if err != nil {
+		return err
+	}
+	exportSvc := export.NewService(indicatorRepo, sheetsWriter, export.WithComparison(compare),
+		export.WithMainIndicators(params))
+
+	latestSnap, err := snapshotRepo.GetLatest(ctx, "mtlf")
+	if err != nil {
+		return fmt.Errorf("getting latest snapshot for export: %w", err)
+	}
+
+	var latestData domain.FundStructureData
+	if err := json.Unmarshal(latestSnap.Data, &latestData); err != nil {
+		return fmt.Errorf("unmarshaling latest snapshot: %w", err)
+	}
+
+	latestIndicators, err := indicatorSvc.CalculateAll(ctx, latestData)
+	if err != nil {
+		return fmt.Errorf("calculating latest indicators for export: %w", err)
+	}
+
+	if _, err := exportSvc.Export(ctx, latestIndicators); err != nil {
+		return externalError("exporting to Google Sheets: %w", err)
+	}
+	slog.Info("Google Sheets IND_ALL/IND_MAIN export completed")
+
+	return partialIf(failed, len(dates), "snapshots")
+}
+
+// maxResponseBody limits HTTP response reads to 50 MB.
+const maxResponseBody = 50 << 20
+
+func fetchOldSnapshots(ctx context.Context, client *http.Client, apiURL string) ([]time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/api/snapshots", nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching snapshots: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d from /api/snapshots: %s", resp.StatusCode, snippet)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	var entries []legacy.SnapshotEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("parsing snapshots: %w", err)
+	}
+
+	return lo.Map(entries, func(e legacy.SnapshotEntry, _ int) time.Time {
+		return e.Date
+	}), nil
+}
+
+func fetchAndTransform(ctx context.Context, client *http.Client, apiURL string, date time.Time) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s/api/fund-structure?date=%s", apiURL, date.UTC().Format(time.RFC3339))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching fund structure: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d for date %s: %s", resp.StatusCode, date.Format("2006-01-02"), snippet)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	var old legacy.FundStructure
+	if err := json.Unmarshal(body, &old); err != nil {
+		return nil, fmt.Errorf("parsing fund structure: %w", err)
+	}
+
+	result, err := json.Marshal(legacy.FromLegacy(old))
+	if err != nil {
+		return nil, fmt.Errorf("marshaling transformed data: %w", err)
+	}
+
+	return result, nil
+}
+
+func runImportExcel(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+	filePath := c.String("file")
+
+	loc, err := sheetsLocale(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Read and validate the Excel MONITORING tab before touching the spreadsheet.
+	f, err := os.Open(filePath)
+	if err != nil {
+		return configError("opening Excel file: %w", err)
+	}
+	imported, err := export.ReadMonitoringExcel(f, export.ExcelImportOptions{
+		Sheet:     c.String("sheet"),
+		HeaderRow: c.Int("header-row"),
+	}, loc)
+	f.Close()
+	if err != nil {
+		return configError("reading Excel file: %w", err)
+	}
+	report := imported.Report
+	if path := c.String("report"); path != "" {
+		if err := writeImportReport(c, path, report); err != nil {
+			return err
+		}
+	}
+	for _, is := range report.Issues {
+		level := slog.LevelInfo
+		if is.Severity == export.SeverityError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "Excel import issue", "severity", is.Severity, "kind", is.Kind, "row", is.Row, "column", is.Column, "message", is.Message)
+	}
+	slog.Info("read Excel MONITORING data", "sheet", report.Sheet, "headerRow", report.HeaderRow,
+		"columns", len(report.Columns), "rows", report.Rows, "lastDate", report.LastDate)
+	if n := report.Errors(); n > 0 {
+		return configError("Excel file failed validation with %d errors, nothing written", n)
+	}
+	if c.Bool("dry-run") {
+		setResult(c, result{{"rows", report.Rows}, {"columns", len(report.Columns)}, {"firstDate", report.FirstDate}, {"lastDate", report.LastDate}})
+		return nil
+	}
+	excelRows, lastExcelDate := imported.Rows, imported.LastDate
+
+	if !sheetsConfigured(cfg, "mtlf") {
+		return configError("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required")
+	}
+
+	sheetsWriter, err := newSheetsWriter(ctx, cfg, "mtlf", export.WithLocale(loc))
+	if err != nil {
+		return externalError("initializing Google Sheets writer: %w", err)
+	}
+
+	if _, err := sheetsWriter.CheckAccess(ctx); err != nil {
+		return externalError("checking Google Sheets access: %w", err)
+	}
+
+	// Delete existing MONITORING sheet for clean rebuild.
+	if err := sheetsWriter.DeleteMonitoringSheet(ctx); err != nil {
+		return externalError("deleting MONITORING sheet: %w", err)
+	}
+
+	// Bulk-write all Excel rows (headers + data) at once.
+	if err := sheetsWriter.WriteMonitoringBulk(ctx, excelRows); err != nil {
+		return externalError("writing Excel data to MONITORING: %w", err)
+	}
+	slog.Info("wrote Excel MONITORING data to Google Sheets")
+
+	// Append DB snapshots for dates after the last Excel date.
+	if cfg.DatabaseURL == "" {
+		slog.Info("DATABASE_URL not set, skipping DB snapshot append")
+		if err := sheetsWriter.ApplyMonitoringFormatting(ctx); err != nil {
+			return externalError("applying MONITORING formatting: %w", err)
+		}
+		recordExportRun(ctx, cfg, sheetsWriter, "command", "import-excel")
 		return nil
 	}
 
-	hist := &indicator.HistoricalData{Repo: snapshotRepo, IndicatorRepo: indicatorRepo, Slug: "mtlf"}
-
-	sheetsWriter, err := export.NewSheetsWriter(ctx, cfg.GoogleSheetsSpreadsheetID, cfg.GoogleCredentialsJSON)
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("initializing Google Sheets writer: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
+	defer pool.Close()
 
-	indicatorSvc := indicator.NewService(hist)
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
 
-	// IDs that produce correct values from snapshot data alone. Layer0 (I51-I53,
-	// I56, I58-I61) reads only account balances/prices stored in the snapshot.
-	// Layer1 I3 (Assets Value) and I4 (Operating Balance) depend on Layer0 outputs.
-	// Other Layer1+ indicators (I5-I7, I10, etc.) require live_metrics — for legacy
-	// snapshots without that block they resolve to zero and we omit them.
-	snapshotOnlyIDs := map[int]bool{
-		3: true, 4: true,
-		51: true, 52: true, 53: true, 56: true, 58: true, 59: true, 60: true, 61: true,
+	sheetsWriter.SetMonitoringMirror(export.NewPgMonitoringMirror(pool))
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+	indicatorRepo := indicator.NewPgRepository(pool)
+	indexCfg, err := indicatorRepo.GetIndexConfig(ctx, "mtlf")
+	if err != nil {
+		return err
+	}
+	hist := &indicator.HistoricalData{Repo: snapshotRepo, IndicatorRepo: indicatorRepo, Slug: "mtlf", Index: &indexCfg}
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return err
 	}
+	fullIndicatorSvc := indicator.NewService(hist, indicatorOpts...)
 
-	// Delete existing MONITORING sheet so the bulk import starts clean.
-	if err := sheetsWriter.DeleteMonitoringSheet(ctx); err != nil {
-		return fmt.Errorf("deleting MONITORING sheet: %w", err)
+	params := export.NewPgParameters(pool)
+	regulatoryPrice, err := params.RegulatoryPrice(ctx, "mtlf")
+	if err != nil {
+		return err
 	}
+	notes := export.MonitoringNotes{RegulatoryPrice: &regulatoryPrice}
 
-	// Append MONITORING rows for all dates (oldest first).
-	sortedDates := make([]time.Time, len(dates))
-	copy(sortedDates, dates)
-	sort.Slice(sortedDates, func(i, j int) bool { return sortedDates[i].Before(sortedDates[j]) })
+	// Iterate day by day from lastExcelDate+1 to today.
+	const maxConsecutiveErrors = 5
 
-	for _, d := range sortedDates {
-		date := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return err
+	}
+	today := clock.Today()
+	var appended, failed, consecutiveErrors int
 
-		snap, err := snapshotRepo.GetByDate(ctx, "mtlf", date)
+	for d := lastExcelDate.AddDate(0, 0, 1); !d.After(today); d = d.AddDate(0, 0, 1) {
+		snap, err := snapshotRepo.GetByDate(ctx, "mtlf", d)
 		if err != nil {
-			slog.Debug("monitoring: snapshot not found", "date", date.Format("2006-01-02"), "error", err)
+			if errors.Is(err, snapshot.ErrNotFound) {
+				slog.Debug("no snapshot for date", "date", d.Format("2006-01-02"))
+				continue
+			}
+			failed++
+			consecutiveErrors++
+			slog.Error("database error fetching snapshot", "date", d.Format("2006-01-02"), "error", err)
+			if consecutiveErrors >= maxConsecutiveErrors {
+				return fmt.Errorf("aborting after %d consecutive errors, last: %w", consecutiveErrors, err)
+			}
 			continue
 		}
+		consecutiveErrors = 0
 
 		var fundData domain.FundStructureData
 		if err := json.Unmarshal(snap.Data, &fundData); err != nil {
-			slog.Error("monitoring: failed to unmarshal snapshot", "date", date.Format("2006-01-02"), "error", err)
+			failed++
+			slog.Error("failed to unmarshal snapshot", "date", d.Format("2006-01-02"), "error", err)
 			continue
 		}
 
-		// Snapshots with live_metrics get the full indicator set; legacy snapshots
-		// without it get only the snapshot-computable subset (Layer0 + I3/I4).
-		hasLiveMetrics := fundData.LiveMetrics != nil
-		indicators, err := indicatorSvc.CalculateAll(ctx, fundData)
+		indicators, err := fullIndicatorSvc.CalculateAll(ctx, fundData)
 		if err != nil {
-			slog.Error("monitoring: failed to calculate indicators", "date", date.Format("2006-01-02"), "error", err)
+			failed++
+			slog.Error("failed to calculate indicators", "date", d.Format("2006-01-02"), "error", err)
 			continue
 		}
 
-		var rows []export.IndicatorRow
-		if hasLiveMetrics {
-			rows = lo.Map(indicators, func(ind indicator.Indicator, _ int) export.IndicatorRow {
-				return export.IndicatorRow{Indicator: ind}
-			})
-		} else {
-			rows = lo.FilterMap(indicators, func(ind indicator.Indicator, _ int) (export.IndicatorRow, bool) {
-				if !snapshotOnlyIDs[ind.ID] {
-					return export.IndicatorRow{}, false
-				}
-				return export.IndicatorRow{Indicator: ind}, true
-			})
-		}
+		rows := lo.Map(indicators, func(ind indicator.Indicator, _ int) export.IndicatorRow {
+			return export.IndicatorRow{Indicator: ind}
+		})
 
-		if err := sheetsWriter.AppendMonitoringRowOnly(ctx, rows, date); err != nil {
-			slog.Error("monitoring: failed to append row", "date", date.Format("2006-01-02"), "error", err)
+		if err := sheetsWriter.AppendMonitoringRowOnly(ctx, rows, d, notes); err != nil {
+			failed++
+			slog.Error("failed to append MONITORING row", "date", d.Format("2006-01-02"), "error", err)
 			continue
 		}
 
-		slog.Info("appended MONITORING row", "date", date.Format("2006-01-02"), "full", hasLiveMetrics)
-
-		// Respect Google Sheets API rate limits (60 read requests/min).
-		time.Sleep(3 * time.Second)
+		appended++
+		slog.Info("appended MONITORING row from DB", "date", d.Format("2006-01-02"))
+		time.Sleep(sheetsWriter.AppendPause())
 	}
 
-	// Apply MONITORING formatting once after all rows are written.
+	slog.Info("DB snapshot append complete", "appended", appended, "failed", failed)
+	setResult(c, result{{"appended", appended}, {"failed", failed}})
+	outcome := partialIf(failed, appended+failed, "MONITORING rows")
+
+	// Apply MONITORING formatting.
 	if err := sheetsWriter.ApplyMonitoringFormatting(ctx); err != nil {
-		slog.Error("failed to apply MONITORING formatting", "error", err)
+		return externalError("applying MONITORING formatting: %w", err)
 	}
 
-	// Update IND_ALL / IND_MAIN with current data.
-	exportSvc := export.NewService(indicatorRepo, sheetsWriter)
+	// Refresh IND_ALL / IND_MAIN with latest snapshot.
+	if _, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+		return fmt.Errorf("ensuring entity: %w", err)
+	}
 
 	latestSnap, err := snapshotRepo.GetLatest(ctx, "mtlf")
 	if err != nil {
-		return fmt.Errorf("getting latest snapshot for export: %w", err)
+		if errors.Is(err, snapshot.ErrNotFound) {
+			slog.Info("no snapshots in database, skipping IND_ALL/IND_MAIN refresh")
+			recordExportRun(ctx, cfg, sheetsWriter, "command", "import-excel")
+			return outcome
+		}
+		return fmt.Errorf("getting latest snapshot for IND_ALL/IND_MAIN refresh: %w", err)
 	}
 
 	var latestData domain.FundStructureData
@@ -515,184 +1932,322 @@ func runImport(c *cli.Context) error {
 		return fmt.Errorf("unmarshaling latest snapshot: %w", err)
 	}
 
-	latestIndicators, err := indicatorSvc.CalculateAll(ctx, latestData)
+	latestIndicators, err := fullIndicatorSvc.CalculateAll(ctx, latestData)
 	if err != nil {
 		return fmt.Errorf("calculating latest indicators for export: %w", err)
 	}
 
-	if _, err := exportSvc.Export(ctx, latestIndicators); err != nil {
-		return fmt.Errorf("exporting to Google Sheets: %w", err)
+	compare, err := comparisonMode(cfg)
+	if err != nil {
+		return err
+	}
+	exportSvc := export.NewService(indicatorRepo, sheetsWriter, export.WithComparison(compare),
+		export.WithMainIndicators(params))
+	monHist := buildMonitoringHistory(excelRows, loc)
+	if _, err := exportSvc.ExportWithHistory(ctx, latestIndicators, monHist); err != nil {
+		return externalError("exporting to Google Sheets: %w", err)
 	}
 	slog.Info("Google Sheets IND_ALL/IND_MAIN export completed")
+	recordExportRun(ctx, cfg, sheetsWriter, "command", "import-excel")
 
-	return nil
+	return outcome
 }
 
-// oldSnapshotEntry matches the old API's /api/snapshots response.
-type oldSnapshotEntry struct {
-	Date      time.Time `json:"date"`
-	CreatedAt time.Time `json:"createdAt"`
+// writeImportReport writes the Excel import validation report as JSON to
+// path, or to the app's writer when path is "-".
+func writeImportReport(c *cli.Context, path string, report export.ImportReport) error {
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling import report: %w", err)
+	}
+	raw = append(raw, '\n')
+	if path == "-" {
+		_, err := c.App.Writer.Write(raw)
+		return err
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
 }
 
-// oldFundStructure matches the old API's /api/fund-structure response.
-type oldFundStructure struct {
-	Accounts      []domain.FundAccountPortfolio `json:"accounts"`
-	OtherAccounts []domain.FundAccountPortfolio `json:"otherAccounts"`
+// buildMonitoringHistory converts Excel MONITORING rows into an export.MonitoringHistory
+// for use by ExportWithHistory to fill historical change gaps.
+func buildMonitoringHistory(excelRows [][]any, loc export.Locale) export.MonitoringHistory {
+	colIDs := export.MonitoringColumnIndicatorIDs()
+	hist := make(export.MonitoringHistory, len(excelRows))
+	var skippedNoDate, skippedParseFail, skippedNoVals int
+
+	for i, row := range excelRows {
+		if i < 2 || len(row) == 0 {
+			continue // skip header rows and empty rows
+		}
+		dateStr, ok := row[0].(string)
+		if !ok {
+			skippedNoDate++
+			continue
+		}
+		date, err := loc.ParseDate(dateStr)
+		if err != nil {
+			skippedParseFail++
+			continue
+		}
+		vals := make(map[int]decimal.Decimal)
+		for j := 0; j < len(colIDs) && j+1 < len(row); j++ {
+			if colIDs[j] == 0 {
+				continue
+			}
+			if f, ok := row[j+1].(float64); ok {
+				vals[colIDs[j]] = decimal.NewFromFloat(f)
+			}
+		}
+		if len(vals) > 0 {
+			hist[date] = vals
+		} else {
+			skippedNoVals++
+		}
+	}
+
+	if skipped := skippedNoDate + skippedParseFail + skippedNoVals; skipped > 0 {
+		slog.Info("buildMonitoringHistory: skipped rows",
+			"parsed", len(hist),
+			"skippedNoDate", skippedNoDate,
+			"skippedParseFail", skippedParseFail,
+			"skippedNoVals", skippedNoVals,
+		)
+	}
+
+	return hist
 }
 
-// maxResponseBody limits HTTP response reads to 50 MB.
-const maxResponseBody = 50 << 20
+// runImportIndicatorsFromSheets reads the MONITORING tab from the configured Google Sheet
+// and upserts each (date, indicator_id, value) row into fund_indicators. Used to seed
+// historical indicator values that pre-date snapshot persistence — values for indicator
+// IDs that aren't in the MONITORING column mapping (e.g. I49) are silently absent.
+func runImportIndicatorsFromSheets(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
 
-func fetchOldSnapshots(ctx context.Context, client *http.Client, apiURL string) ([]time.Time, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/api/snapshots", nil)
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	if !sheetsConfigured(cfg, "mtlf") {
+		return configError("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
+	defer pool.Close()
 
-	resp, err := client.Do(req)
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+	indicatorRepo := indicator.NewPgRepository(pool)
+
+	entityID, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics")
 	if err != nil {
-		return nil, fmt.Errorf("fetching snapshots: %w", err)
+		return fmt.Errorf("ensuring entity: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return nil, fmt.Errorf("unexpected status %d from /api/snapshots: %s", resp.StatusCode, snippet)
+	loc, err := sheetsLocale(cfg)
+	if err != nil {
+		return err
+	}
+	sheetsWriter, err := newSheetsWriter(ctx, cfg, "mtlf", export.WithLocale(loc))
+	if err != nil {
+		return externalError("initializing Google Sheets client: %w", err)
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	rows, err := sheetsWriter.ReadMonitoring(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return externalError("reading MONITORING sheet: %w", err)
+	}
+	if len(rows) < 3 {
+		return fmt.Errorf("MONITORING sheet has fewer than 3 rows (got %d)", len(rows))
+	}
+
+	colIDs := export.MonitoringColumnIndicatorIDs()
+
+	const maxConsecutiveErrors = 5
+	var processed, failed, consecutive int
+	var skippedEmpty, skippedBadDate, skippedNoIndicators, suppressedCells int
+
+	for i, row := range rows {
+		if i < 2 {
+			continue // header rows
+		}
+		if len(row) == 0 {
+			skippedEmpty++
+			continue
+		}
+
+		dateStr, ok := row[0].(string)
+		if !ok || dateStr == "" {
+			skippedEmpty++
+			continue
+		}
+		date, err := parseSheetDate(dateStr, loc)
+		if err != nil {
+			slog.Debug("skipping row with unparseable date", "rowIndex", i+1, "value", dateStr, "error", err)
+			skippedBadDate++
+			continue
+		}
+
+		var inds []indicator.Indicator
+		for j := 0; j < len(colIDs) && j+1 < len(row); j++ {
+			id := colIDs[j]
+			if id == 0 {
+				continue
+			}
+			val, outcome := parseSheetNumber(row[j+1])
+			if outcome == sheetCellParseFailed {
+				suppressedCells++
+				continue
+			}
+			if outcome != sheetCellOK {
+				continue
+			}
+			inds = append(inds, indicator.Indicator{ID: id, Value: val})
+		}
+
+		if len(inds) == 0 {
+			skippedNoIndicators++
+			continue
+		}
+
+		if err := indicatorRepo.Save(ctx, entityID, date, inds, indicator.SourceSheet); err != nil {
+			failed++
+			consecutive++
+			slog.Error("failed to save indicators", "date", date.Format("2006-01-02"), "error", err)
+			if consecutive >= maxConsecutiveErrors {
+				return fmt.Errorf("aborting after %d consecutive save errors, last: %w", consecutive, err)
+			}
+			continue
+		}
+
+		consecutive = 0
+		processed++
+		if processed%50 == 0 {
+			slog.Info("import progress", "processed", processed)
+		}
 	}
 
-	var entries []oldSnapshotEntry
-	if err := json.Unmarshal(body, &entries); err != nil {
-		return nil, fmt.Errorf("parsing snapshots: %w", err)
+	if suppressedCells > 0 {
+		slog.Info("suppressed cells with unparseable values during import",
+			"count", suppressedCells,
+			"explanation", "non-numeric, non-error strings or unhandled types — values dropped, not zero-filled",
+		)
 	}
 
-	return lo.Map(entries, func(e oldSnapshotEntry, _ int) time.Time {
-		return e.Date
-	}), nil
+	slog.Info("MONITORING indicator import complete",
+		"processed", processed,
+		"skippedEmpty", skippedEmpty,
+		"skippedBadDate", skippedBadDate,
+		"skippedNoIndicators", skippedNoIndicators,
+		"suppressedCells", suppressedCells,
+		"total_rows", len(rows)-2,
+	)
+	setResult(c, result{
+		{"processed", processed},
+		{"failed", failed},
+		{"skippedEmpty", skippedEmpty},
+		{"skippedBadDate", skippedBadDate},
+		{"skippedNoIndicators", skippedNoIndicators},
+		{"suppressedCells", suppressedCells},
+	})
+	return partialIf(failed, processed+failed, "dates")
 }
 
-func fetchAndTransform(ctx context.Context, client *http.Client, apiURL string, date time.Time) (json.RawMessage, error) {
-	url := fmt.Sprintf("%s/api/fund-structure?date=%s", apiURL, date.UTC().Format(time.RFC3339))
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// runSnapshotExport writes one stored snapshot, with its entity and seal
+// hash, to a transfer file for runSnapshotImport.
+func runSnapshotExport(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	date, err := time.Parse("2006-01-02", c.String("date"))
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return configError("invalid --date: %w", err)
 	}
 
-	resp, err := client.Do(req)
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("fetching fund structure: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
-	defer resp.Body.Close()
+	defer pool.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return nil, fmt.Errorf("unexpected status %d for date %s: %s", resp.StatusCode, date.Format("2006-01-02"), snippet)
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	snapshotRepo := snapshot.NewPgRepository(pool)
+	slug := c.String("entity")
+	entity, err := snapshotRepo.GetEntity(ctx, slug)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	var old oldFundStructure
-	if err := json.Unmarshal(body, &old); err != nil {
-		return nil, fmt.Errorf("parsing fund structure: %w", err)
+		return fmt.Errorf("loading entity %s: %w", slug, err)
 	}
-
-	// Normalize account names to match current system.
-	for i := range old.Accounts {
-		if old.Accounts[i].Name == "CITY" {
-			old.Accounts[i].Name = "MCITY"
-		}
+	s, err := snapshotRepo.GetByDate(ctx, slug, date)
+	if err != nil {
+		return fmt.Errorf("loading snapshot %s: %w", date.Format("2006-01-02"), err)
 	}
-
-	// Split old "accounts" into main (issuer/subfond/operational) and mutual.
-	mainAccounts := lo.Filter(old.Accounts, func(a domain.FundAccountPortfolio, _ int) bool {
-		return a.Type != domain.AccountTypeMutual
-	})
-	mutualFunds := lo.Filter(old.Accounts, func(a domain.FundAccountPortfolio, _ int) bool {
-		return a.Type == domain.AccountTypeMutual
-	})
-
-	// Compute aggregated totals from main accounts only.
-	totalEURMTL := lo.Reduce(mainAccounts, func(acc decimal.Decimal, a domain.FundAccountPortfolio, _ int) decimal.Decimal {
-		return acc.Add(a.TotalEURMTL)
-	}, decimal.Zero)
-	totalXLM := lo.Reduce(mainAccounts, func(acc decimal.Decimal, a domain.FundAccountPortfolio, _ int) decimal.Decimal {
-		return acc.Add(a.TotalXLM)
-	}, decimal.Zero)
-	tokenCount := lo.Reduce(mainAccounts, func(acc int, a domain.FundAccountPortfolio, _ int) int {
-		return acc + len(a.Tokens)
-	}, 0)
-
-	newData := domain.FundStructureData{
-		Accounts:      mainAccounts,
-		MutualFunds:   mutualFunds,
-		OtherAccounts: old.OtherAccounts,
-		AggregatedTotals: domain.AggregatedTotals{
-			TotalEURMTL:  totalEURMTL,
-			TotalXLM:     totalXLM,
-			AccountCount: len(mainAccounts),
-			TokenCount:   tokenCount,
-		},
+	transfer, err := snapshot.NewTransfer(entity, s)
+	if err != nil {
+		return fmt.Errorf("packaging snapshot: %w", err)
 	}
 
-	result, err := json.Marshal(newData)
+	data, err := json.MarshalIndent(transfer, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("marshaling transformed data: %w", err)
+		return fmt.Errorf("encoding transfer file: %w", err)
+	}
+	out := c.String("out")
+	if err := os.WriteFile(out, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
 	}
 
-	return result, nil
+	slog.Info("snapshot exported", "entity", slug, "date", transfer.Date, "file", out)
+	setResult(c, result{{"entity", slug}, {"date", transfer.Date}, {"hash", transfer.Hash}, {"file", out}})
+	return nil
 }
 
-func runImportExcel(c *cli.Context) error {
+// runSnapshotImport stores the snapshot from a transfer file. The entity is
+// resolved by slug in the target database (--entity remaps it) and created
+// from the file's name and description if missing.
+func runSnapshotImport(c *cli.Context) error {
 	ctx := c.Context
 	cfg := config.Load()
-	filePath := c.String("file")
-
-	// Read the Excel MONITORING tab.
-	excelRows, lastExcelDate, err := readExcelMonitoring(filePath)
-	if err != nil {
-		return fmt.Errorf("reading Excel file: %w", err)
-	}
-	slog.Info("read Excel MONITORING data", "rows", len(excelRows)-2, "lastDate", lastExcelDate.Format("2006-01-02"))
 
-	if cfg.GoogleSheetsSpreadsheetID == "" || cfg.GoogleCredentialsJSON == "" {
-		return fmt.Errorf("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required")
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
 	}
 
-	sheetsWriter, err := export.NewSheetsWriter(ctx, cfg.GoogleSheetsSpreadsheetID, cfg.GoogleCredentialsJSON)
+	in := c.String("in")
+	f, err := os.Open(in)
 	if err != nil {
-		return fmt.Errorf("initializing Google Sheets writer: %w", err)
+		return configError("opening %s: %w", in, err)
 	}
-
-	// Delete existing MONITORING sheet for clean rebuild.
-	if err := sheetsWriter.DeleteMonitoringSheet(ctx); err != nil {
-		return fmt.Errorf("deleting MONITORING sheet: %w", err)
+	transfer, err := snapshot.ReadTransfer(f)
+	f.Close()
+	if err != nil {
+		return configError("reading %s: %w", in, err)
 	}
-
-	// Bulk-write all Excel rows (headers + data) at once.
-	if err := sheetsWriter.WriteMonitoringBulk(ctx, excelRows); err != nil {
-		return fmt.Errorf("writing Excel data to MONITORING: %w", err)
+	date, err := transfer.SnapshotDate()
+	if err != nil {
+		return configError("reading %s: %w", in, err)
 	}
-	slog.Info("wrote Excel MONITORING data to Google Sheets")
-
-	// Append DB snapshots for dates after the last Excel date.
-	if cfg.DatabaseURL == "" {
-		slog.Info("DATABASE_URL not set, skipping DB snapshot append")
-		if err := sheetsWriter.ApplyMonitoringFormatting(ctx); err != nil {
-			return fmt.Errorf("applying MONITORING formatting: %w", err)
-		}
-		return nil
+	entity := transfer.Entity
+	if slug := c.String("entity"); slug != "" {
+		entity.Slug = slug
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -701,277 +2256,246 @@ func runImportExcel(c *cli.Context) error {
 	}
 
 	snapshotRepo := snapshot.NewPgRepository(pool)
-	indicatorRepo := indicator.NewPgRepository(pool)
-	hist := &indicator.HistoricalData{Repo: snapshotRepo, IndicatorRepo: indicatorRepo, Slug: "mtlf"}
-	fullIndicatorSvc := indicator.NewService(hist)
-
-	// Iterate day by day from lastExcelDate+1 to today.
-	const maxConsecutiveErrors = 5
-
-	now := time.Now().UTC()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	var appended, consecutiveErrors int
-
-	for d := lastExcelDate.AddDate(0, 0, 1); !d.After(today); d = d.AddDate(0, 0, 1) {
-		snap, err := snapshotRepo.GetByDate(ctx, "mtlf", d)
-		if err != nil {
-			if errors.Is(err, snapshot.ErrNotFound) {
-				slog.Debug("no snapshot for date", "date", d.Format("2006-01-02"))
-				continue
-			}
-			consecutiveErrors++
-			slog.Error("database error fetching snapshot", "date", d.Format("2006-01-02"), "error", err)
-			if consecutiveErrors >= maxConsecutiveErrors {
-				return fmt.Errorf("aborting after %d consecutive errors, last: %w", consecutiveErrors, err)
-			}
-			continue
-		}
-		consecutiveErrors = 0
-
-		var fundData domain.FundStructureData
-		if err := json.Unmarshal(snap.Data, &fundData); err != nil {
-			slog.Error("failed to unmarshal snapshot", "date", d.Format("2006-01-02"), "error", err)
-			continue
-		}
+	entityID, err := snapshotRepo.GetEntityID(ctx, entity.Slug)
+	if errors.Is(err, snapshot.ErrNotFound) {
+		slog.Info("creating entity", "slug", entity.Slug, "name", entity.Name)
+		entityID, err = snapshotRepo.EnsureEntity(ctx, entity.Slug, entity.Name, entity.Description)
+	}
+	if err != nil {
+		return fmt.Errorf("resolving entity %s: %w", entity.Slug, err)
+	}
 
-		indicators, err := fullIndicatorSvc.CalculateAll(ctx, fundData)
-		if err != nil {
-			slog.Error("failed to calculate indicators", "date", d.Format("2006-01-02"), "error", err)
-			continue
+	if !c.Bool("overwrite") {
+		_, err := snapshotRepo.GetByDate(ctx, entity.Slug, date)
+		if err == nil {
+			return fmt.Errorf("snapshot %s already exists for %s (use --overwrite to replace it)", transfer.Date, entity.Slug)
 		}
-
-		rows := lo.Map(indicators, func(ind indicator.Indicator, _ int) export.IndicatorRow {
-			return export.IndicatorRow{Indicator: ind}
-		})
-
-		if err := sheetsWriter.AppendMonitoringRowOnly(ctx, rows, d); err != nil {
-			slog.Error("failed to append MONITORING row", "date", d.Format("2006-01-02"), "error", err)
-			continue
+		if !errors.Is(err, snapshot.ErrNotFound) {
+			return fmt.Errorf("checking existing snapshot: %w", err)
 		}
-
-		appended++
-		slog.Info("appended MONITORING row from DB", "date", d.Format("2006-01-02"))
-		time.Sleep(3 * time.Second)
 	}
 
-	slog.Info("DB snapshot append complete", "appended", appended)
-
-	// Apply MONITORING formatting.
-	if err := sheetsWriter.ApplyMonitoringFormatting(ctx); err != nil {
-		return fmt.Errorf("applying MONITORING formatting: %w", err)
+	if err := snapshotRepo.Save(ctx, entityID, date, transfer.Data); err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
 	}
 
-	// Refresh IND_ALL / IND_MAIN with latest snapshot.
-	if _, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
-		return fmt.Errorf("ensuring entity: %w", err)
-	}
+	slog.Info("snapshot imported", "from", transfer.Entity.Slug, "into", entity.Slug, "date", transfer.Date)
+	setResult(c, result{{"entity", entity.Slug}, {"date", transfer.Date}, {"hash", transfer.Hash}})
+	return nil
+}
 
-	latestSnap, err := snapshotRepo.GetLatest(ctx, "mtlf")
-	if err != nil {
-		if errors.Is(err, snapshot.ErrNotFound) {
-			slog.Info("no snapshots in database, skipping IND_ALL/IND_MAIN refresh")
-			return nil
-		}
-		return fmt.Errorf("getting latest snapshot for IND_ALL/IND_MAIN refresh: %w", err)
-	}
+// accountGuard connects to the database and returns the account guard over
+// every registry account, with its expectation repository.
+func accountGuard(c *cli.Context) (*accountguard.Service, *accountguard.PgRepository, func(), error) {
+	ctx := c.Context
+	cfg := config.Load()
 
-	var latestData domain.FundStructureData
-	if err := json.Unmarshal(latestSnap.Data, &latestData); err != nil {
-		return fmt.Errorf("unmarshaling latest snapshot: %w", err)
+	if cfg.DatabaseURL == "" {
+		return nil, nil, nil, configError("DATABASE_URL is required")
 	}
 
-	latestIndicators, err := fullIndicatorSvc.CalculateAll(ctx, latestData)
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("calculating latest indicators for export: %w", err)
+		return nil, nil, nil, externalError("connecting to database: %w", err)
 	}
-
-	exportSvc := export.NewService(indicatorRepo, sheetsWriter)
-	monHist := buildMonitoringHistory(excelRows)
-	if _, err := exportSvc.ExportWithHistory(ctx, latestIndicators, monHist); err != nil {
-		return fmt.Errorf("exporting to Google Sheets: %w", err)
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		pool.Close()
+		return nil, nil, nil, fmt.Errorf("running migrations: %w", err)
+	}
+	if _, err := snapshot.NewPgRepository(pool).EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+		pool.Close()
+		return nil, nil, nil, fmt.Errorf("ensuring entity: %w", err)
 	}
-	slog.Info("Google Sheets IND_ALL/IND_MAIN export completed")
 
-	return nil
+	repo := accountguard.NewPgRepository(pool)
+	return accountguard.NewService(newHorizonClient(cfg), repo, "mtlf", domain.AccountRegistry()), repo, pool.Close, nil
 }
 
-// readExcelMonitoring reads the MONITORING sheet from an Excel file and returns
-// all rows (2 header rows + data rows) as [][]any, plus the last data date.
-func readExcelMonitoring(filePath string) ([][]any, time.Time, error) {
-	f, err := excelize.OpenFile(filePath)
+// runAccountConfigPin stores the live configuration of fund accounts as the
+// expected state that snapshot generation checks against.
+func runAccountConfigPin(c *cli.Context) error {
+	ctx := c.Context
+	guard, repo, closeDB, err := accountGuard(c)
 	if err != nil {
-		return nil, time.Time{}, fmt.Errorf("opening file: %w", err)
+		return err
 	}
-	defer f.Close()
+	defer closeDB()
 
-	xlRows, err := f.GetRows("MONITORING")
+	only := c.StringSlice("account")
+	configs, err := guard.Collect(ctx)
 	if err != nil {
-		return nil, time.Time{}, fmt.Errorf("reading MONITORING sheet: %w", err)
+		return err
 	}
 
-	if len(xlRows) < 3 {
-		return nil, time.Time{}, fmt.Errorf("MONITORING sheet has fewer than 3 rows")
-	}
-
-	const totalCols = 41 // A through AO
-
-	var allRows [][]any
-	var lastDate time.Time
-	var suppressedErrors int
-
-	for rowIdx, xlRow := range xlRows {
-		row := make([]any, totalCols)
-		for colIdx := range totalCols {
-			if colIdx >= len(xlRow) || xlRow[colIdx] == "" {
-				row[colIdx] = nil
-				continue
-			}
-			cellVal := xlRow[colIdx]
-
-			if rowIdx >= 2 && colIdx == 0 {
-				// Date column: parse and format as dd.mm.yyyy for Google Sheets.
-				t, err := parseExcelDate(cellVal)
-				if err != nil {
-					slog.Debug("skipping row with unparseable date", "row", rowIdx+1, "value", cellVal, "error", err)
-					row = nil
-					break
-				}
-				row[0] = t.Format("02.01.2006")
-				lastDate = t
-			} else if rowIdx >= 2 && colIdx > 0 {
-				// Data cells: convert to float where possible, suppress Excel errors.
-				val := parseExcelNumber(cellVal)
-				if val == nil {
-					suppressedErrors++
-				}
-				row[colIdx] = val
-			} else {
-				// Header rows: convert to float where possible, keep as string otherwise.
-				row[colIdx] = parseExcelNumber(cellVal)
-			}
+	pinned, failed := 0, 0
+	for _, cfg := range configs {
+		if len(only) > 0 && !lo.Contains(only, cfg.Account) && !lo.Contains(only, cfg.Name) {
+			continue
+		}
+		if cfg.Error != "" {
+			slog.Error("account not pinned", "account", cfg.Name, "error", cfg.Error)
+			failed++
+			continue
 		}
-		if row != nil {
-			allRows = append(allRows, row)
+		if err := repo.Save(ctx, "mtlf", accountguard.Pin(cfg)); err != nil {
+			return err
 		}
+		slog.Info("account config pinned", "account", cfg.Name, "homeDomain", cfg.HomeDomain, "flags", cfg.Flags,
+			"numSponsoring", cfg.NumSponsoring, "numSponsored", cfg.NumSponsored)
+		pinned++
+	}
+	if pinned+failed == 0 {
+		return configError("no fund account matches --account %v", only)
 	}
+	setResult(c, result{{"pinned", pinned}, {"failed", failed}})
+	return partialIf(failed, pinned+failed, "accounts")
+}
 
-	if suppressedErrors > 0 {
-		slog.Info("suppressed Excel error values during import",
-			"count", suppressedErrors,
-			"explanation", "cells with # prefixes (e.g. #REF!, #DIV/0!) replaced with nil",
-		)
+// runAccountConfigCheck compares the live configuration of fund accounts
+// with the expected state and reports every drift.
+func runAccountConfigCheck(c *cli.Context) error {
+	ctx := c.Context
+	guard, _, closeDB, err := accountGuard(c)
+	if err != nil {
+		return err
 	}
+	defer closeDB()
 
-	if lastDate.IsZero() {
-		return nil, time.Time{}, fmt.Errorf("no valid dates found in MONITORING sheet")
+	configs, err := guard.Collect(ctx)
+	if err != nil {
+		return err
+	}
+	drift, err := guard.Check(ctx, configs)
+	if err != nil {
+		return err
+	}
+	for _, d := range drift {
+		slog.Error(d)
 	}
+	unavailable := lo.CountBy(configs, func(cfg domain.AccountConfig) bool { return cfg.Error != "" })
 
-	return allRows, lastDate, nil
+	setResult(c, result{{"accounts", len(configs)}, {"unavailable", unavailable}, {"drift", len(drift)}})
+	if len(drift) > 0 {
+		return partialError("%d configuration drifts", len(drift))
+	}
+	return partialIf(unavailable, len(configs), "accounts")
 }
 
-// parseExcelNumber tries to convert a string to a float64, stripping commas
-// from formatted numbers. Excel error values (#REF!, #DIV/0!, #N/A, etc.)
-// are replaced with nil to avoid Google Sheets interpreting them as errors.
-// Returns the original string if not a number and not an error.
-func parseExcelNumber(s string) any {
-	if strings.HasPrefix(s, "#") {
-		return nil
+// runFixture cuts an indicator regression fixture from a stored snapshot.
+// Expected values are calculated from the written (possibly redacted) data,
+// so the fixture is self-consistent; TestFixtures in internal/indicator
+// replays it.
+func runFixture(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
 	}
-	cleaned := strings.ReplaceAll(s, ",", "")
-	d, err := decimal.NewFromString(cleaned)
+	date, err := time.Parse("2006-01-02", c.String("date"))
 	if err != nil {
-		return s
+		return configError("invalid --date: %w", err)
 	}
-	f, _ := d.Float64()
-	return f
-}
 
-// parseExcelDate parses date strings in formats used by excelize output.
-func parseExcelDate(s string) (time.Time, error) {
-	for _, layout := range []string{
-		"02.01.2006", // dd.mm.yyyy — known MONITORING format
-		"2006-01-02", // ISO
-		"2006-01-02T15:04:05Z",
-		"01-02-06",   // MM-DD-YY
-		"1/2/06",     // US short
-		"1/2/2006",   // US long
-		"01-02-2006", // MM-DD-YYYY
-	} {
-		if t, err := time.Parse(layout, s); err == nil {
-			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
-		}
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
 	}
-	return time.Time{}, fmt.Errorf("cannot parse date: %q", s)
-}
+	defer pool.Close()
 
-// buildMonitoringHistory converts Excel MONITORING rows into an export.MonitoringHistory
-// for use by ExportWithHistory to fill historical change gaps.
-func buildMonitoringHistory(excelRows [][]any) export.MonitoringHistory {
-	colIDs := export.MonitoringColumnIndicatorIDs()
-	hist := make(export.MonitoringHistory, len(excelRows))
-	var skippedNoDate, skippedParseFail, skippedNoVals int
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
 
-	for i, row := range excelRows {
-		if i < 2 || len(row) == 0 {
-			continue // skip header rows and empty rows
-		}
-		dateStr, ok := row[0].(string)
-		if !ok {
-			skippedNoDate++
-			continue
-		}
-		t, err := time.Parse("02.01.2006", dateStr)
-		if err != nil {
-			skippedParseFail++
-			continue
-		}
-		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
-		vals := make(map[int]decimal.Decimal)
-		for j := 0; j < len(colIDs) && j+1 < len(row); j++ {
-			if colIDs[j] == 0 {
-				continue
-			}
-			if f, ok := row[j+1].(float64); ok {
-				vals[colIDs[j]] = decimal.NewFromFloat(f)
-			}
+	snapshotRepo := snapshot.NewPgRepository(pool)
+	s, err := snapshotRepo.GetByDate(ctx, "mtlf", date)
+	if err != nil {
+		return fmt.Errorf("loading snapshot %s: %w", date.Format("2006-01-02"), err)
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		return fmt.Errorf("decoding snapshot %s: %w", date.Format("2006-01-02"), err)
+	}
+	source := s.Hash
+	if source == "" {
+		if source, err = snapshot.Hash(s.Data); err != nil {
+			return fmt.Errorf("hashing snapshot: %w", err)
 		}
-		if len(vals) > 0 {
-			hist[date] = vals
-		} else {
-			skippedNoVals++
+	}
+
+	if c.Bool("redact") {
+		if data, err = fixture.Redact(data, fixture.DefaultRules(data, c.Int("max-tokens"))); err != nil {
+			return fmt.Errorf("redacting snapshot: %w", err)
 		}
 	}
+	inds, err := indicator.NewService(nil).CalculateAll(ctx, data)
+	if err != nil {
+		return fmt.Errorf("calculating indicators: %w", err)
+	}
 
-	if skipped := skippedNoDate + skippedParseFail + skippedNoVals; skipped > 0 {
-		slog.Info("buildMonitoringHistory: skipped rows",
-			"parsed", len(hist),
-			"skippedNoDate", skippedNoDate,
-			"skippedParseFail", skippedParseFail,
-			"skippedNoVals", skippedNoVals,
-		)
+	f := fixture.Fixture{
+		Date:     date.Format("2006-01-02"),
+		Source:   source,
+		Redacted: c.Bool("redact"),
+		Data:     data,
+		Expected: fixture.Expected(inds),
+	}
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding fixture: %w", err)
+	}
+	dir := c.String("dir")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	out := filepath.Join(dir, f.Date+".json")
+	if err := os.WriteFile(out, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
 	}
 
-	return hist
+	tokens := lo.SumBy(data.Accounts, func(a domain.FundAccountPortfolio) int { return len(a.Tokens) })
+	slog.Info("fixture written", "date", f.Date, "file", out, "redacted", f.Redacted)
+	setResult(c, result{{"date", f.Date}, {"file", out}, {"redacted", f.Redacted}, {"tokens", tokens}, {"indicators", len(inds)}})
+	return nil
 }
 
-// runImportIndicatorsFromSheets reads the MONITORING tab from the configured Google Sheet
-// and upserts each (date, indicator_id, value) row into fund_indicators. Used to seed
-// historical indicator values that pre-date snapshot persistence — values for indicator
-// IDs that aren't in the MONITORING column mapping (e.g. I49) are silently absent.
-func runImportIndicatorsFromSheets(c *cli.Context) error {
+// runReconcile reads the MONITORING sheet, recomputes the same rows from
+// stored snapshots and reports the cells that differ. Deterministic
+// indicators are recomputed like backfill-indicators does; live ones can't
+// be, so their stored fund_indicators value is compared instead.
+func runReconcile(c *cli.Context) error {
 	ctx := c.Context
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
 	}
-	if cfg.GoogleSheetsSpreadsheetID == "" || cfg.GoogleCredentialsJSON == "" {
-		return fmt.Errorf("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required")
+	if !sheetsConfigured(cfg, "mtlf") {
+		return configError("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required")
+	}
+	if c.Float64("tolerance") < 0 {
+		return configError("--tolerance must not be negative")
+	}
+	var from, to time.Time
+	if v := c.String("from"); v != "" {
+		var err error
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return configError("invalid --from date: %w", err)
+		}
+	}
+	if v := c.String("to"); v != "" {
+		var err error
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return configError("invalid --to date: %w", err)
+		}
+	}
+	inRange := func(d time.Time) bool {
+		return (from.IsZero() || !d.Before(from)) && (to.IsZero() || !d.After(to))
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -982,110 +2506,148 @@ func runImportIndicatorsFromSheets(c *cli.Context) error {
 	snapshotRepo := snapshot.NewPgRepository(pool)
 	indicatorRepo := indicator.NewPgRepository(pool)
 
-	entityID, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics")
+	loc, err := sheetsLocale(cfg)
 	if err != nil {
-		return fmt.Errorf("ensuring entity: %w", err)
+		return err
 	}
-
-	sheetsWriter, err := export.NewSheetsWriter(ctx, cfg.GoogleSheetsSpreadsheetID, cfg.GoogleCredentialsJSON)
+	sheetsWriter, err := newSheetsWriter(ctx, cfg, "mtlf", export.WithLocale(loc))
 	if err != nil {
-		return fmt.Errorf("initializing Google Sheets client: %w", err)
+		return externalError("initializing Google Sheets client: %w", err)
 	}
-
 	rows, err := sheetsWriter.ReadMonitoring(ctx)
 	if err != nil {
-		return fmt.Errorf("reading MONITORING sheet: %w", err)
-	}
-	if len(rows) < 3 {
-		return fmt.Errorf("MONITORING sheet has fewer than 3 rows (got %d)", len(rows))
+		return externalError("reading MONITORING sheet: %w", err)
 	}
 
-	colIDs := export.MonitoringColumnIndicatorIDs()
-
-	const maxConsecutiveErrors = 5
-	var processed, consecutive int
-	var skippedEmpty, skippedBadDate, skippedNoIndicators, suppressedCells int
+	var cols []reconcile.Column
+	headers := export.MonitoringColumnHeaders()
+	for i, id := range export.MonitoringColumnIndicatorIDs() {
+		if id != 0 {
+			cols = append(cols, reconcile.Column{Index: i, Header: headers[i], IndicatorID: id})
+		}
+	}
 
+	var sheetRows []reconcile.SheetRow
 	for i, row := range rows {
-		if i < 2 {
+		if i < 2 || len(row) == 0 {
 			continue // header rows
 		}
-		if len(row) == 0 {
-			skippedEmpty++
-			continue
-		}
-
 		dateStr, ok := row[0].(string)
 		if !ok || dateStr == "" {
-			skippedEmpty++
 			continue
 		}
-		date, err := parseSheetDate(dateStr)
+		date, err := parseSheetDate(dateStr, loc)
 		if err != nil {
-			slog.Debug("skipping row with unparseable date", "rowIndex", i+1, "value", dateStr, "error", err)
-			skippedBadDate++
+			slog.Error("reconcile: skipping row with unparseable date", "row", i+1, "value", dateStr)
 			continue
 		}
-
-		var inds []indicator.Indicator
-		for j := 0; j < len(colIDs) && j+1 < len(row); j++ {
-			id := colIDs[j]
-			if id == 0 {
-				continue
-			}
-			val, outcome := parseSheetNumber(row[j+1])
-			if outcome == sheetCellParseFailed {
-				suppressedCells++
-				continue
-			}
-			if outcome != sheetCellOK {
-				continue
+		if !inRange(date) {
+			continue
+		}
+		cells := make(map[int]decimal.Decimal)
+		for j := 1; j < len(row); j++ {
+			if val, outcome := parseSheetNumber(row[j]); outcome == sheetCellOK {
+				cells[j-1] = val
 			}
-			inds = append(inds, indicator.Indicator{ID: id, Value: val})
 		}
+		sheetRows = append(sheetRows, reconcile.SheetRow{Line: i + 1, Date: date, Cells: cells})
+	}
 
-		if len(inds) == 0 {
-			skippedNoIndicators++
+	metas, err := snapshotRepo.ListMeta(ctx, "mtlf")
+	if err != nil {
+		return fmt.Errorf("listing snapshot metadata: %w", err)
+	}
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return err
+	}
+	indicatorSvc := indicator.NewService(nil, indicatorOpts...)
+
+	var expected []reconcile.Expected
+	for _, m := range metas {
+		date := time.Date(m.SnapshotDate.Year(), m.SnapshotDate.Month(), m.SnapshotDate.Day(), 0, 0, 0, 0, time.UTC)
+		if !inRange(date) {
 			continue
 		}
-
-		if err := indicatorRepo.Save(ctx, entityID, date, inds); err != nil {
-			consecutive++
-			slog.Error("failed to save indicators", "date", date.Format("2006-01-02"), "error", err)
-			if consecutive >= maxConsecutiveErrors {
-				return fmt.Errorf("aborting after %d consecutive save errors, last: %w", consecutive, err)
+		snap, err := snapshotRepo.GetByDate(ctx, "mtlf", date)
+		if err != nil {
+			return fmt.Errorf("loading snapshot %s: %w", date.Format("2006-01-02"), err)
+		}
+		var fundData domain.FundStructureData
+		if err := json.Unmarshal(snap.Data, &fundData); err != nil {
+			return fmt.Errorf("parsing snapshot %s: %w", date.Format("2006-01-02"), err)
+		}
+		all, err := indicatorSvc.CalculateAll(ctx, fundData)
+		if err != nil {
+			return fmt.Errorf("calculating indicators for %s: %w", date.Format("2006-01-02"), err)
+		}
+		values := make(map[int]decimal.Decimal)
+		for _, ind := range all {
+			if indicator.DeterministicIDs[ind.ID] {
+				values[ind.ID] = ind.Value
 			}
-			continue
 		}
-
-		consecutive = 0
-		processed++
-		if processed%50 == 0 {
-			slog.Info("import progress", "processed", processed)
+		stored, err := indicatorRepo.GetByDate(ctx, "mtlf", date)
+		if err != nil && !errors.Is(err, indicator.ErrNotFound) {
+			return fmt.Errorf("loading stored indicators for %s: %w", date.Format("2006-01-02"), err)
+		}
+		for _, ind := range stored {
+			if !indicator.DeterministicIDs[ind.ID] {
+				values[ind.ID] = ind.Value
+			}
 		}
+		expected = append(expected, reconcile.Expected{Date: date, Values: values})
 	}
 
-	if suppressedCells > 0 {
-		slog.Info("suppressed cells with unparseable values during import",
-			"count", suppressedCells,
-			"explanation", "non-numeric, non-error strings or unhandled types — values dropped, not zero-filled",
-		)
+	report := reconcile.Compare(cols, sheetRows, expected, decimal.NewFromFloat(c.Float64("tolerance")))
+	if path := c.String("report"); path != "" {
+		if err := writeReconcileCSV(c, path, report); err != nil {
+			return err
+		}
 	}
 
-	slog.Info("MONITORING indicator import complete",
-		"processed", processed,
-		"skippedEmpty", skippedEmpty,
-		"skippedBadDate", skippedBadDate,
-		"skippedNoIndicators", skippedNoIndicators,
-		"suppressedCells", suppressedCells,
-		"total_rows", len(rows)-2,
-	)
+	reimport := report.NeedsReimport()
+	dates := lo.Map(reimport, func(d time.Time, _ int) string { return d.Format("2006-01-02") })
+	slog.Info("reconcile complete", "sheetRows", len(sheetRows), "snapshots", len(expected), "needReimport", len(reimport))
+	setResult(c, result{
+		{"sheetRows", len(sheetRows)},
+		{"snapshots", len(expected)},
+		{"match", report.Count(reconcile.StatusMatch)},
+		{"mismatch", report.Count(reconcile.StatusMismatch)},
+		{"duplicate", report.Count(reconcile.StatusDuplicate)},
+		{"missingInSheet", report.Count(reconcile.StatusMissingInSheet)},
+		{"missingInDB", report.Count(reconcile.StatusMissingInDB)},
+		{"reimport", dates},
+	})
+	if len(reimport) > 0 {
+		return partialError("%d dates need re-import", len(reimport))
+	}
 	return nil
 }
 
-// parseSheetDate parses dd.mm.yyyy and d.m.yyyy (both seen in MONITORING column A),
-// plus ISO and US fallbacks. Returns midnight UTC.
-func parseSheetDate(s string) (time.Time, error) {
+// writeReconcileCSV writes the diff report to path, or to stdout for "-".
+func writeReconcileCSV(c *cli.Context, path string, report reconcile.Report) error {
+	if path == "-" {
+		return report.WriteCSV(c.App.Writer)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating report file: %w", err)
+	}
+	if err := report.WriteCSV(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// parseSheetDate parses the configured sheet date format first, then dd.mm.yyyy
+// and d.m.yyyy (both seen in MONITORING column A), plus ISO and US fallbacks.
+// Returns midnight UTC.
+func parseSheetDate(s string, loc export.Locale) (time.Time, error) {
+	if t, err := loc.ParseDate(s); err == nil {
+		return t, nil
+	}
 	for _, layout := range []string{"02.01.2006", "2.1.2006", "2006-01-02", "1/2/2006", "01-02-2006"} {
 		if t, err := time.Parse(layout, s); err == nil {
 			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
@@ -1135,17 +2697,20 @@ func parseSheetNumber(v any) (decimal.Decimal, sheetCellOutcome) {
 // and writes them to fund_indicators. Indicators excluded from indicator.DeterministicIDs
 // (live tokenomics, dividend chain, MTLRECT live price) are skipped — past values for
 // those are unrecoverable and remain absent until the next daily `stat report` run.
+// With HUBBLE_PROJECT set, the holder counts (I23, I24, I27, I40, I62) and
+// MTL/MTLRECT circulation (I6, I7) a snapshot lacks are read from Hubble as of
+// the end of its day and stored with source "ledger".
 func runBackfillIndicators(c *cli.Context) error {
 	ctx := c.Context
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -1172,10 +2737,32 @@ func runBackfillIndicators(c *cli.Context) error {
 	// Indicators read live values from snapshot.LiveMetrics. Non-deterministic
 	// indicators that lack stored values resolve to zero and are filtered via
 	// DeterministicIDs below.
-	indicatorSvc := indicator.NewService(nil)
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return err
+	}
+	indicatorSvc := indicator.NewService(nil, indicatorOpts...)
+
+	// With Hubble the holder counts and supplies old snapshots lack are read
+	// from the ledger state of their day, and those indicators are stored too.
+	var history *hubble.Service
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.HubbleProject != "" {
+		if cfg.HubbleCredentialsJSON == "" {
+			return configError("HUBBLE_PROJECT requires HUBBLE_CREDENTIALS_JSON or GOOGLE_CREDENTIALS_JSON")
+		}
+		bq, err := hubble.NewClient(ctx, cfg.HubbleProject, cfg.HubbleCredentialsJSON, hubble.WithTransport(roundTripper()))
+		if err != nil {
+			return configError("HUBBLE_CREDENTIALS_JSON: %w", err)
+		}
+		history = hubble.NewService(bq, cfg.HubbleDataset)
+	}
 
 	const maxConsecutiveErrors = 5
-	var processed, failed, consecutive int
+	var processed, failed, consecutive, fromLedger int
 
 	for i, m := range metas {
 		date := time.Date(m.SnapshotDate.Year(), m.SnapshotDate.Month(), m.SnapshotDate.Day(), 0, 0, 0, 0, time.UTC)
@@ -1206,6 +2793,24 @@ func runBackfillIndicators(c *cli.Context) error {
 			continue
 		}
 
+		var historical map[int]bool
+		if history != nil {
+			if fundData.LiveMetrics == nil {
+				fundData.LiveMetrics = &domain.FundLiveMetrics{}
+			}
+			ids, err := history.Fill(ctx, clock.Start(date.AddDate(0, 0, 1)).Add(-time.Second), fundData.LiveMetrics)
+			if err != nil {
+				failed++
+				consecutive++
+				slog.Error("backfill: read holders from Hubble", "date", date.Format("2006-01-02"), "error", err)
+				if consecutive >= maxConsecutiveErrors {
+					return fmt.Errorf("aborting after %d consecutive Hubble errors, last: %w", consecutive, err)
+				}
+				continue
+			}
+			historical = lo.SliceToMap(ids, func(id int) (int, bool) { return id, true })
+		}
+
 		all, err := indicatorSvc.CalculateAll(ctx, fundData)
 		if err != nil {
 			failed++
@@ -1220,8 +2825,11 @@ func runBackfillIndicators(c *cli.Context) error {
 		deterministic := lo.Filter(all, func(ind indicator.Indicator, _ int) bool {
 			return indicator.DeterministicIDs[ind.ID]
 		})
+		fromHistory := lo.Filter(all, func(ind indicator.Indicator, _ int) bool {
+			return historical[ind.ID] && !indicator.DeterministicIDs[ind.ID]
+		})
 
-		if err := indicatorRepo.Save(ctx, entityID, date, deterministic); err != nil {
+		if err := indicatorRepo.Save(ctx, entityID, date, deterministic, indicator.SourceRecomputed); err != nil {
 			failed++
 			consecutive++
 			slog.Error("backfill: persist indicators", "date", date.Format("2006-01-02"), "error", err)
@@ -1231,6 +2839,19 @@ func runBackfillIndicators(c *cli.Context) error {
 			continue
 		}
 
+		if len(fromHistory) > 0 {
+			if err := indicatorRepo.Save(ctx, entityID, date, fromHistory, indicator.SourceLedger); err != nil {
+				failed++
+				consecutive++
+				slog.Error("backfill: persist ledger indicators", "date", date.Format("2006-01-02"), "error", err)
+				if consecutive >= maxConsecutiveErrors {
+					return fmt.Errorf("aborting after %d consecutive save errors, last: %w", consecutive, err)
+				}
+				continue
+			}
+			fromLedger++
+		}
+
 		consecutive = 0
 		processed++
 		if (i+1)%50 == 0 {
@@ -1238,7 +2859,72 @@ func runBackfillIndicators(c *cli.Context) error {
 		}
 	}
 
-	slog.Info("backfill complete", "processed", processed, "failed", failed, "total", len(metas))
+	slog.Info("backfill complete", "processed", processed, "failed", failed, "total", len(metas), "from_ledger", fromLedger)
+	setResult(c, result{{"processed", processed}, {"failed", failed}, {"total", len(metas)}, {"from_ledger", fromLedger}})
+	return partialIf(failed, len(metas), "snapshots")
+}
+
+// runBackfillHoldings fills the holdings column of snapshots stored before
+// it existed. Idempotent.
+func runBackfillHoldings(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+
+	stage := startStage("holdings_backfill")
+	n, err := snapshotRepo.BackfillHoldings(ctx, "mtlf")
+	if err != nil {
+		return fmt.Errorf("backfilling holdings: %w", err)
+	}
+	stage.done("updated", n)
+	setResult(c, result{{"updated", n}})
+	return nil
+}
+
+// runBackfillBalances fills account_balances from stored snapshots that
+// have no rows there yet. Idempotent.
+func runBackfillBalances(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+
+	stage := startStage("balances_backfill")
+	n, err := snapshotRepo.BackfillBalances(ctx, "mtlf")
+	if err != nil {
+		return fmt.Errorf("backfilling balances: %w", err)
+	}
+	stage.done("filled", n)
+	setResult(c, result{{"filled", n}})
 	return nil
 }
 
@@ -1255,12 +2941,12 @@ func runBackfillDivs(c *cli.Context) error {
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -1294,7 +2980,7 @@ func runBackfillDivs(c *cli.Context) error {
 	// it has an event ≤ the snapshot date.
 	walkSince := oldestDate.AddDate(-1, 0, 0)
 
-	horizonClient := horizon.NewClient(cfg.HorizonURL, cfg.HorizonRetryMax, cfg.HorizonRetryBaseDelay)
+	horizonClient := newHorizonClient(cfg)
 
 	var fundAddrs []string
 	for _, a := range domain.AccountRegistry() {
@@ -1303,7 +2989,7 @@ func runBackfillDivs(c *cli.Context) error {
 
 	activity, err := horizonClient.FetchDividendActivity(ctx, domain.MTLDividendDistributor, fundAddrs, walkSince)
 	if err != nil {
-		return fmt.Errorf("walking dividend activity from %s: %w", domain.MTLDividendDistributor, err)
+		return externalError("walking dividend activity from %s: %w", domain.MTLDividendDistributor, err)
 	}
 	slog.Info("backfill-divs: dividend activity fetched",
 		"last_divs_updates", len(activity.LastDivsUpdates),
@@ -1345,7 +3031,7 @@ func runBackfillDivs(c *cli.Context) error {
 			continue
 		}
 
-		if err := indicatorRepo.Save(ctx, entityID, date, inds); err != nil {
+		if err := indicatorRepo.Save(ctx, entityID, date, inds, indicator.SourceLedger); err != nil {
 			failed++
 			consecutive++
 			slog.Error("backfill-divs: persist", "date", date.Format("2006-01-02"), "error", err)
@@ -1363,7 +3049,96 @@ func runBackfillDivs(c *cli.Context) error {
 	}
 
 	slog.Info("backfill-divs complete", "processed", processed, "failed", failed, "total", len(metas))
-	return nil
+	setResult(c, result{{"processed", processed}, {"failed", failed}, {"total", len(metas)}})
+	return partialIf(failed, len(metas), "snapshots")
+}
+
+// runBackfillIndex recomputes I66 on every date that has a stored value of
+// an index component, carrying each component's last value forward across
+// dates where it is missing, as GetNearestBefore does for the live
+// calculation's base values. Run it after changing the index definition.
+func runBackfillIndex(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	snapshotRepo := snapshot.NewPgRepository(pool)
+	indicatorRepo := indicator.NewPgRepository(pool)
+
+	entityID, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics")
+	if err != nil {
+		return fmt.Errorf("ensuring entity: %w", err)
+	}
+
+	indexCfg, err := indicatorRepo.GetIndexConfig(ctx, "mtlf")
+	if err != nil {
+		return err
+	}
+	baseInds, err := indicatorRepo.GetNearestBefore(ctx, "mtlf", indexCfg.BaseDate)
+	if err != nil {
+		return fmt.Errorf("loading index base values: %w", err)
+	}
+	base := make(map[int]decimal.Decimal, len(baseInds))
+	for id, ind := range baseInds {
+		base[id] = ind.Value
+	}
+
+	points, err := indicatorRepo.GetHistory(ctx, "mtlf", indicator.IndexComponentIDs, time.Time{})
+	if err != nil {
+		return fmt.Errorf("loading index component history: %w", err)
+	}
+	if len(points) == 0 {
+		slog.Info("backfill-index: no component history to process")
+		return nil
+	}
+
+	const maxConsecutiveErrors = 5
+	var processed, skipped, failed, consecutive, dates int
+	current := make(map[int]decimal.Decimal, len(indicator.IndexComponentIDs))
+	for i, p := range points {
+		current[p.IndicatorID] = p.Value
+		if i+1 < len(points) && points[i+1].SnapshotDate.Equal(p.SnapshotDate) {
+			continue // points are ordered by date: finish the day first
+		}
+		dates++
+
+		value, ok := indicator.ComputeIndex(indexCfg, base, current)
+		if !ok {
+			skipped++
+			continue
+		}
+		date := p.SnapshotDate
+		inds := []indicator.Indicator{indicator.NewIndicator(indicator.IndexID, value, "", "")}
+		if err := indicatorRepo.Save(ctx, entityID, date, inds, indicator.SourceRecomputed); err != nil {
+			failed++
+			consecutive++
+			slog.Error("backfill-index: persist", "date", date.Format("2006-01-02"), "error", err)
+			if consecutive >= maxConsecutiveErrors {
+				return fmt.Errorf("aborting after %d consecutive save errors, last: %w", consecutive, err)
+			}
+			continue
+		}
+		consecutive = 0
+		processed++
+	}
+
+	slog.Info("backfill-index complete", "processed", processed, "skipped", skipped, "failed", failed,
+		"base_date", indexCfg.BaseDate.Format("2006-01-02"))
+	setResult(c, result{{"processed", processed}, {"skipped", skipped}, {"failed", failed}, {"total", dates}})
+	return partialIf(failed, dates, "dates")
 }
 
 func runServe(c *cli.Context) error {
@@ -1371,12 +3146,12 @@ func runServe(c *cli.Context) error {
 	cfg := config.Load()
 
 	if cfg.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+		return configError("DATABASE_URL is required")
 	}
 
 	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return externalError("connecting to database: %w", err)
 	}
 	defer pool.Close()
 
@@ -1387,23 +3162,198 @@ func runServe(c *cli.Context) error {
 	snapshotRepo := snapshot.NewPgRepository(pool)
 	indicatorRepo := indicator.NewPgRepository(pool)
 
-	// The serve path is read-only: no fund generation, no Horizon. Pass nil for the
-	// FundStructureService — Service.Generate is never invoked here.
+	// The serve path is read-only by default: no fund generation, and Horizon only
+	// for the operations explorer. Pass nil for the FundStructureService —
+	// Service.Generate is never invoked here.
 	snapshotSvc := snapshot.NewService(nil, snapshotRepo)
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return err
+	}
 
-	if _, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+	entityID, err := snapshotRepo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics")
+	if err != nil {
 		return fmt.Errorf("ensuring entity: %w", err)
 	}
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return err
+	}
+	compare, err := comparisonMode(cfg)
+	if err != nil {
+		return err
+	}
+	decimalFormat, err := decjson.Parse(cfg.JSONDecimalFormat)
+	if err != nil {
+		return configError("parsing JSON_DECIMAL_FORMAT: %w", err)
+	}
+	decjson.Apply(decimalFormat)
+
+	monitoringLoc, err := sheetsLocale(cfg)
+	if err != nil {
+		return err
+	}
+
+	annotationRepo := annotation.NewPgRepository(pool)
+	periods := newPeriodService(pool, indicatorRepo)
+	calcs := indicator.NewService(nil, indicatorOpts...)
+	opts := []api.Option{
+		api.WithClock(clock),
+		api.WithSiteURL(reportURL),
+		api.WithComparison(compare),
+		api.WithLimits(api.Limits{
+			RPS:           cfg.APIRateLimitRPS,
+			Burst:         cfg.APIRateLimitBurst,
+			MaxBodyBytes:  cfg.APIMaxBodyBytes,
+			MaxConcurrent: cfg.APIMaxConcurrent,
+			TrustProxy:    cfg.APITrustProxy,
+		}),
+		api.WithCORS(api.CORS{
+			Origins: cfg.APICORSOrigins,
+			Methods: cfg.APICORSMethods,
+		}),
+		api.WithCalculators(calcs),
+		api.WithCorrelations(analytics.NewService(snapshotSvc, indicatorRepo, cfg.CorrelationAssets)),
+		api.WithReports(period.NewPgRepository(pool)),
+		api.WithReturns(periods),
+		api.WithSubfonds(periods),
+		api.WithBalances(snapshotRepo),
+		api.WithSnapshotDetails(snapshotRepo),
+		api.WithForecast(analytics.NewForecastService(indicatorRepo)),
+		api.WithIssuance(issuance.NewPgRepository(pool)),
+		api.WithConversions(conversion.NewPgRepository(pool)),
+		api.WithSupplyHistory(supply.NewPgRepository(pool)),
+		api.WithHistoryAggregates(indicatorRepo),
+		api.WithAccountMetadata(accountmeta.NewPgRepository(pool)),
+		api.WithAnnotations(annotationRepo),
+		api.WithDefinitions(definition.NewPgRepository(pool)),
+		api.WithIndicatorMethods(calcs),
+		api.WithMonitoring(export.NewMonitoringService(indicatorRepo,
+			monitoringNotes{pool: pool, annotations: cfg.ExportAnnotations}), monitoringLoc),
+		api.WithHolders(holders.NewService(nil, holders.NewPgRepository(pool), "mtlf")),
+		api.WithWhaleAlerts(whale.NewPgRepository(pool)),
+		api.WithIntraday(intraday.NewService(nil, nil, intraday.NewPgRepository(pool), "mtlf", cfg.IntradayRetention)),
+		api.WithExplorer(explorer.NewService(newHorizonClient(cfg), cfg.ExplorerCacheTTL)),
+	}
+	keyRepo := apikey.NewPgRepository(pool)
+	opts = append(opts, api.WithAPIKeys(api.APIKeys{Verifier: keyRepo, Required: cfg.APIKeysRequired}))
+	adminRepo := admin.NewPgRepository(pool)
+	adminAPI := api.Admin{Token: cfg.AdminToken, Pprof: cfg.PprofEnabled, Index: adminRepo, Keys: keyRepo,
+		Entities: adminRepo, Accounts: adminRepo, Properties: adminRepo, Pricing: adminRepo, Parameters: adminRepo,
+		Audit: adminRepo, Annotations: annotationRepo}
+	if sharedTransport != nil {
+		adminAPI.Upstream = sharedTransport
+	}
+	jobsDone := make(chan struct{})
+	if cfg.APIGenerateEnabled {
+		slog.Info("on-demand snapshot generation enabled", "endpoint", "POST /api/v1/snapshots/generate")
+		pipeline, err := newReportPipeline(cfg, pool)
+		if err != nil {
+			return err
+		}
+		if cfg.PriceCacheWarmup {
+			pipeline.warmCaches(ctx, cfg.PriceCacheWarmupMaxAge)
+		}
+		adminAPI.Pipeline = pipeline
+		jobSvc := job.NewService(job.NewPgRepository(pool), pipeline, entityID,
+			job.WithTimeout(cfg.JobTimeout), job.WithShutdownGrace(cfg.JobShutdownGrace))
+		opts = append(opts, api.WithJobs(jobSvc))
+		go func() {
+			defer close(jobsDone)
+			if err := jobSvc.Run(ctx); err != nil {
+				slog.Error("job runner stopped", "error", err)
+			}
+		}()
+	} else {
+		if cfg.PriceCacheWarmup {
+			slog.Info("PRICE_CACHE_WARMUP ignored: serve builds no price service without API_GENERATE_ENABLED")
+		}
+		close(jobsDone)
+	}
+	if adminAPI.Token != "" {
+		slog.Info("admin diagnostics enabled", "pprof", adminAPI.Pprof)
+		opts = append(opts, api.WithAdmin(adminAPI))
+	} else if adminAPI.Pprof {
+		slog.Info("PPROF_ENABLED ignored: ADMIN_TOKEN is not set")
+	}
 
-	srv := api.NewServer(cfg.HTTPPort, snapshotSvc, indicatorRepo)
+	opts = append(opts, api.WithCoinGeckoBudget(
+		external.NewBudget(external.NewPgRequestCounter(pool), external.CoinGeckoService, cfg.CoinGeckoDailyBudget)))
+	if cfg.SLODeadline > 0 {
+		opts = append(opts, api.WithSLO(slo.NewService(slo.NewPgRepository(pool), nil, "mtlf", cfg.SLODeadline, cfg.SLOAlertAfter)))
+	}
 
-	serverErr := make(chan error, 1)
-	go func() {
-		slog.Info("HTTP server listening", "port", cfg.HTTPPort)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverErr <- err
+	// With Sheets credentials serve also retries the exports still owed,
+	// including those of snapshots generated through the API.
+	exportsDone := make(chan struct{})
+	if sheetsConfigured(cfg, "mtlf") {
+		exportRepo := outbox.NewPgRepository(pool)
+		exporter, err := newSheetsExporter(ctx, cfg, pool, indicatorRepo, snapshotSvc, clock)
+		if err != nil {
+			return err
 		}
-	}()
+		opts = append(opts, api.WithExportOutbox(exportRepo))
+		slog.Info("sheets export outbox worker enabled", "interval", cfg.ExportOutboxInterval)
+		go func() {
+			defer close(exportsDone)
+			if err := newExportOutbox(cfg, exportRepo, exporter).Run(ctx); err != nil {
+				slog.Error("export outbox worker stopped", "error", err)
+			}
+		}()
+	} else {
+		close(exportsDone)
+	}
+
+	// With HOLDER_REFRESH_INTERVAL serve also keeps the holder counts fresh
+	// for the report pipeline, which then skips the Horizon holder walks.
+	holdersDone := make(chan struct{})
+	if cfg.HolderRefreshInterval > 0 {
+		refresher := newHolderRefresher(cfg, pool)
+		slog.Info("holder count refresher enabled", "interval", cfg.HolderRefreshInterval)
+		go func() {
+			defer close(holdersDone)
+			if err := refresher.RunHolderRefresh(ctx, cfg.HolderRefreshInterval); err != nil {
+				slog.Error("holder count refresher stopped", "error", err)
+			}
+		}()
+	} else {
+		close(holdersDone)
+	}
+
+	// With INTRADAY_INTERVAL serve also samples I4 and I10 between the daily
+	// snapshots for /api/v1/intraday.
+	intradayDone := make(chan struct{})
+	if cfg.IntradayInterval > 0 {
+		sampler := newIntradaySampler(cfg, pool)
+		slog.Info("intraday sampler enabled", "interval", cfg.IntradayInterval, "retention", cfg.IntradayRetention)
+		go func() {
+			defer close(intradayDone)
+			if err := sampler.Run(ctx, cfg.IntradayInterval); err != nil {
+				slog.Error("intraday sampler stopped", "error", err)
+			}
+		}()
+	} else {
+		close(intradayDone)
+	}
+
+	if cfg.AdminAddr != "" {
+		opts = append(opts, api.WithAdminAddr(cfg.AdminAddr))
+	}
+	srv, adminSrv := api.NewServers(cfg.HTTPPort, snapshotSvc, indicatorRepo, opts...)
+	servers := []*http.Server{srv}
+	if adminSrv != nil {
+		servers = append(servers, adminSrv)
+	}
+
+	serverErr := make(chan error, len(servers))
+	for _, s := range servers {
+		go func() {
+			slog.Info("HTTP server listening", "addr", s.Addr)
+			if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverErr <- fmt.Errorf("%s: %w", s.Addr, err)
+			}
+		}()
+	}
 
 	select {
 	case err := <-serverErr:
@@ -1415,10 +3365,19 @@ func runServe(c *cli.Context) error {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		slog.Error("HTTP server shutdown error", "error", err)
+	for _, s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			slog.Error("HTTP server shutdown error", "addr", s.Addr, "error", err)
+		}
 	}
 
+	// The in-flight job (if any) sees ctx cancelled and records itself as
+	// cancelled; wait so that write happens before the pool closes.
+	<-jobsDone
+	<-exportsDone
+	<-holdersDone
+	<-intradayDone
+
 	slog.Info("shutdown complete")
 	return nil
 }
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/samber/lo"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/annotation"
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/export"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/issuance"
+	"github.com/mtlprog/stat/internal/outbox"
+	"github.com/mtlprog/stat/internal/snapdate"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// sheetsTarget returns the spreadsheet and credentials of entity slug:
+// GOOGLE_SHEETS_SPREADSHEET_ID_<SLUG> / GOOGLE_CREDENTIALS_JSON_<SLUG> when
+// set, GOOGLE_SHEETS_SPREADSHEET_ID / GOOGLE_CREDENTIALS_JSON otherwise.
+func sheetsTarget(cfg config.Config, slug string) (spreadsheetID, credentialsJSON string) {
+	spreadsheetID, credentialsJSON = cfg.GoogleSheetsSpreadsheetID, cfg.GoogleCredentialsJSON
+	if v := cfg.GoogleSheetsByEntity[slug]; v != "" {
+		spreadsheetID = v
+	}
+	if v := cfg.GoogleCredentialsByEntity[slug]; v != "" {
+		credentialsJSON = v
+	}
+	return spreadsheetID, credentialsJSON
+}
+
+// sheetsConfigured reports whether the Google Sheets export of entity slug
+// is on.
+func sheetsConfigured(cfg config.Config, slug string) bool {
+	spreadsheetID, credentialsJSON := sheetsTarget(cfg, slug)
+	return spreadsheetID != "" && credentialsJSON != ""
+}
+
+// newSheetsWriter connects a Sheets writer to the spreadsheet of entity slug
+// through the shared transport.
+func newSheetsWriter(ctx context.Context, cfg config.Config, slug string, opts ...export.WriterOption) (*export.SheetsWriter, error) {
+	spreadsheetID, credentialsJSON := sheetsTarget(cfg, slug)
+	return export.NewSheetsWriter(ctx, spreadsheetID, credentialsJSON, append([]export.WriterOption{export.WithTransport(roundTripper())}, opts...)...)
+}
+
+// sheetsExporter writes a stored snapshot to Google Sheets for the export
+// outbox: the MONITORING row of any date, and IND_ALL/IND_MAIN plus the
+// optional CORR, PEERS, provenance and history sheets only while the date
+// is the latest, so a late retry never overwrites newer data.
+type sheetsExporter struct {
+	cfg        config.Config
+	pool       *pgxpool.Pool
+	indicators *indicator.PgRepository
+	snapshots  *snapshot.Service
+	writer     *export.SheetsWriter
+	svc        *export.Service
+	checked    bool // CheckAccess passed
+
+	// The report command sets the result it just computed, so IND_ALL also
+	// lists its calculator failures. Other dates are read back from
+	// fund_indicators.
+	runDate time.Time
+	run     *indicator.PartialResult
+}
+
+// newSheetsExporter connects the Sheets writer.
+func newSheetsExporter(ctx context.Context, cfg config.Config, pool *pgxpool.Pool, indicators *indicator.PgRepository,
+	snapshots *snapshot.Service, clock snapdate.Clock) (*sheetsExporter, error) {
+	loc, err := sheetsLocale(cfg)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := newSheetsWriter(ctx, cfg, "mtlf", export.WithLocale(loc),
+		export.WithMonitoringMirror(export.NewPgMonitoringMirror(pool)))
+	if err != nil {
+		return nil, externalError("initializing Google Sheets writer: %w", err)
+	}
+	compare, err := comparisonMode(cfg)
+	if err != nil {
+		return nil, err
+	}
+	svc := export.NewService(indicators, writer, export.WithClock(clock), export.WithComparison(compare),
+		export.WithMainIndicators(export.NewPgParameters(pool)))
+	return &sheetsExporter{
+		cfg:        cfg,
+		pool:       pool,
+		indicators: indicators,
+		snapshots:  snapshots,
+		writer:     writer,
+		svc:        svc,
+	}, nil
+}
+
+// monitoringNotes implements export.NotesSource from the issuance events,
+// the configured regulatory price and, when annotations is set
+// (EXPORT_ANNOTATIONS), the annotations of a date.
+type monitoringNotes struct {
+	pool        *pgxpool.Pool
+	annotations bool
+}
+
+func (n monitoringNotes) MonitoringNotes(ctx context.Context, slug string, date time.Time) (export.MonitoringNotes, error) {
+	events, err := issuance.NewPgRepository(n.pool).List(ctx, slug, date, date)
+	if err != nil {
+		return export.MonitoringNotes{}, fmt.Errorf("loading issuance events: %w", err)
+	}
+	price, err := export.NewPgParameters(n.pool).RegulatoryPrice(ctx, slug)
+	if err != nil {
+		return export.MonitoringNotes{}, err
+	}
+	notes := export.MonitoringNotes{Issuance: issuance.Note(events), RegulatoryPrice: &price}
+	if n.annotations {
+		list, err := annotation.NewPgRepository(n.pool).List(ctx, slug, date, date)
+		if err != nil {
+			return export.MonitoringNotes{}, fmt.Errorf("loading annotations: %w", err)
+		}
+		notes.Annotations = annotation.Note(list)
+	}
+	return notes, nil
+}
+
+// newExportOutbox builds the export outbox service with the EXPORT_* retry
+// settings.
+func newExportOutbox(cfg config.Config, repo outbox.Repository, exporter outbox.Exporter) *outbox.Service {
+	return outbox.NewService(repo, exporter, "mtlf",
+		outbox.WithBackoff(cfg.ExportRetryBaseDelay, cfg.ExportRetryMaxDelay),
+		outbox.WithInterval(cfg.ExportOutboxInterval))
+}
+
+// Export implements outbox.Exporter. A successful run ends with its summary.
+// The first export of the exporter checks the spreadsheet first, so a
+// sharing problem fails with what to fix instead of a 403 halfway through.
+func (e *sheetsExporter) Export(ctx context.Context, date time.Time) error {
+	if !e.checked {
+		if _, err := e.writer.CheckAccess(ctx, export.MonitoringSheet); err != nil {
+			return err
+		}
+		e.checked = true
+	}
+	e.writer.StartRun()
+	if err := e.export(ctx, date); err != nil {
+		return err
+	}
+	recordExportRun(ctx, e.cfg, e.writer, "date", date.Format("2006-01-02"))
+	return nil
+}
+
+// recordExportRun logs the summary of the writer's run and, with EXPORT_META,
+// appends it to the _META sheet. The data is already written, so a failure
+// here is only logged.
+func recordExportRun(ctx context.Context, cfg config.Config, writer *export.SheetsWriter, attrs ...any) {
+	sum := writer.Summary()
+	for _, s := range sum.Sheets {
+		slog.Info("export sheet written", "sheet", s.Sheet, "mode", s.Mode, "rows", s.Rows, "checksum", s.Checksum)
+	}
+	slog.Info("export run summary", append(attrs,
+		"sheets", len(sum.Sheets),
+		"rows_appended", sum.RowsAppended,
+		"api_calls", sum.APICalls,
+		"duration_ms", sum.Duration.Milliseconds(),
+	)...)
+	if !cfg.ExportMeta {
+		return
+	}
+	if err := writer.WriteMeta(ctx, sum); err != nil {
+		slog.Error("writing export run summary", "sheet", export.MetaSheet, "error", err)
+	}
+}
+
+func (e *sheetsExporter) export(ctx context.Context, date time.Time) error {
+	_, latestDate, err := e.indicators.GetLatest(ctx, "mtlf")
+	if err != nil {
+		return fmt.Errorf("loading latest indicators: %w", err)
+	}
+	latest := !date.Before(latestDate)
+
+	res := indicator.PartialResult{}
+	if e.run != nil && date.Equal(e.runDate) {
+		res = *e.run
+	} else if res.Indicators, err = e.indicators.GetByDate(ctx, "mtlf", date); err != nil {
+		return fmt.Errorf("loading indicators: %w", err)
+	}
+
+	var rows []export.IndicatorRow
+	if latest {
+		stage := startStage("sheets_export_indall")
+		if rows, err = e.svc.ExportPartialAt(ctx, date, res); err != nil {
+			return err
+		}
+		stage.done()
+	} else {
+		rows = e.svc.Rows(ctx, date, res.Indicators)
+	}
+
+	stage := startStage("sheets_append_monitoring")
+	notes, err := monitoringNotes{pool: e.pool, annotations: e.cfg.ExportAnnotations}.MonitoringNotes(ctx, "mtlf", date)
+	if err != nil {
+		return err
+	}
+	if err := e.writer.AppendMonitoringReport(ctx, export.NewMonitoringReport(date, rows, notes)); err != nil {
+		return err
+	}
+	stage.done("date", date.Format("2006-01-02"))
+
+	if !latest {
+		return nil
+	}
+
+	if e.cfg.ExportCorrelations {
+		stage = startStage("sheets_write_corr")
+		corrSvc := analytics.NewService(e.snapshots, e.indicators, e.cfg.CorrelationAssets)
+		var matrices []*analytics.Matrix
+		for _, days := range []int{30, 90, 365} {
+			m, err := corrSvc.Correlations(ctx, days, date)
+			if err != nil {
+				return fmt.Errorf("computing %dd correlations: %w", days, err)
+			}
+			matrices = append(matrices, m)
+		}
+		if err := e.writer.WriteCorrelations(ctx, matrices); err != nil {
+			return fmt.Errorf("writing CORR sheet: %w", err)
+		}
+		stage.done()
+	}
+
+	if e.cfg.ExportPeers {
+		stage = startStage("sheets_write_peers")
+		snap, err := e.snapshots.GetByDate(ctx, "mtlf", date)
+		if err != nil {
+			return fmt.Errorf("loading snapshot for PEERS sheet: %w", err)
+		}
+		var data domain.FundStructureData
+		if err := json.Unmarshal(snap.Data, &data); err != nil {
+			return fmt.Errorf("decoding snapshot for PEERS sheet: %w", err)
+		}
+		if err := e.writer.WritePeers(ctx, analytics.ComparePeers(date.Format("2006-01-02"), data)); err != nil {
+			return fmt.Errorf("writing PEERS sheet: %w", err)
+		}
+		stage.done()
+	}
+
+	if e.cfg.ExportGroups {
+		stage = startStage("sheets_write_groups")
+		snap, err := e.snapshots.GetByDate(ctx, "mtlf", date)
+		if err != nil {
+			return fmt.Errorf("loading snapshot for GROUPS sheet: %w", err)
+		}
+		var data domain.FundStructureData
+		if err := json.Unmarshal(snap.Data, &data); err != nil {
+			return fmt.Errorf("decoding snapshot for GROUPS sheet: %w", err)
+		}
+		if err := e.writer.WriteGroups(ctx, date.Format("2006-01-02"), data.Groups); err != nil {
+			return fmt.Errorf("writing GROUPS sheet: %w", err)
+		}
+		stage.done()
+	}
+
+	if e.cfg.ExportProvenance {
+		stage = startStage("sheets_write_provenance")
+		ids := lo.Uniq(lo.Without(export.MonitoringColumnIndicatorIDs(), 0))
+		points, err := e.indicators.GetProvenance(ctx, "mtlf", ids)
+		if err != nil {
+			return fmt.Errorf("loading indicator provenance: %w", err)
+		}
+		if err := e.writer.WriteProvenance(ctx, points); err != nil {
+			return fmt.Errorf("writing %s sheet: %w", export.ProvenanceSheet, err)
+		}
+		stage.done("values", len(points))
+	}
+
+	if e.cfg.ExportHistory {
+		stage = startStage("sheets_append_history")
+		last, err := e.writer.LastHistoryDate(ctx)
+		if err != nil {
+			return fmt.Errorf("reading %s sheet: %w", export.HistorySheet, err)
+		}
+		var from time.Time
+		if !last.IsZero() {
+			from = last.AddDate(0, 0, 1)
+		}
+		points, err := e.indicators.GetHistory(ctx, "mtlf", indicator.RegisteredIDs(), from)
+		if err != nil {
+			return fmt.Errorf("loading indicator history: %w", err)
+		}
+		if err := e.writer.AppendHistory(ctx, points); err != nil {
+			return fmt.Errorf("appending %s sheet: %w", export.HistorySheet, err)
+		}
+		stage.done("values", len(points))
+	}
+	return nil
+}
+
+// diff compares what export would write for date, given the recalculated
+// res, with the spreadsheet. Nothing is written.
+func (e *sheetsExporter) diff(ctx context.Context, date time.Time, res indicator.PartialResult) ([]export.SheetDiff, error) {
+	_, latestDate, err := e.indicators.GetLatest(ctx, "mtlf")
+	if err != nil {
+		return nil, fmt.Errorf("loading latest indicators: %w", err)
+	}
+	rows := e.svc.Rows(ctx, date, res.Indicators)
+	notes, err := monitoringNotes{pool: e.pool, annotations: e.cfg.ExportAnnotations}.MonitoringNotes(ctx, "mtlf", date)
+	if err != nil {
+		return nil, err
+	}
+	return e.writer.Diff(ctx, export.DiffPlan{
+		Rows:       rows,
+		Failures:   res.Failures,
+		Monitoring: export.NewMonitoringReport(date, rows, notes),
+		Latest:     !date.Before(latestDate),
+	})
+}
+
+// openExportOutbox connects to the database for the export-outbox commands.
+func openExportOutbox(c *cli.Context) (config.Config, *pgxpool.Pool, error) {
+	cfg := config.Load()
+	if cfg.DatabaseURL == "" {
+		return cfg, nil, configError("DATABASE_URL is required")
+	}
+	pool, err := database.Connect(c.Context, cfg.DatabaseURL)
+	if err != nil {
+		return cfg, nil, externalError("connecting to database: %w", err)
+	}
+	if err := database.RunMigrations(c.Context, pool, migrations.FS); err != nil {
+		pool.Close()
+		return cfg, nil, fmt.Errorf("running migrations: %w", err)
+	}
+	return cfg, pool, nil
+}
+
+// runExportOutboxList lists the snapshots whose Sheets export is still owed.
+func runExportOutboxList(c *cli.Context) error {
+	_, pool, err := openExportOutbox(c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	tasks, err := outbox.NewPgRepository(pool).Pending(c.Context, "mtlf")
+	if err != nil {
+		return err
+	}
+	res := result{{"pending", len(tasks)}}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		res = append(res, field{"items", tasks})
+	} else {
+		for _, t := range tasks {
+			line := fmt.Sprintf("%s  attempts %d  next %s", t.SnapshotDate.Format("2006-01-02"), t.Attempts,
+				t.NextAttemptAt.UTC().Format(time.RFC3339))
+			if t.LastError != "" {
+				line += "  " + t.LastError
+			}
+			fmt.Fprintln(c.App.Writer, line)
+		}
+		if len(tasks) > 0 {
+			fmt.Fprintln(c.App.Writer)
+		}
+	}
+	setResult(c, res)
+	return nil
+}
+
+// runExportOutboxRetry exports every pending snapshot now, ignoring the
+// backoff.
+func runExportOutboxRetry(c *cli.Context) error {
+	ctx := c.Context
+	cfg, pool, err := openExportOutbox(c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	if !sheetsConfigured(cfg, "mtlf") {
+		return configError("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required")
+	}
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return err
+	}
+
+	indicatorRepo := indicator.NewPgRepository(pool)
+	exporter, err := newSheetsExporter(ctx, cfg, pool, indicatorRepo, snapshot.NewService(nil, snapshot.NewPgRepository(pool)), clock)
+	if err != nil {
+		return err
+	}
+	attempts, err := newExportOutbox(cfg, outbox.NewPgRepository(pool), exporter).Drain(ctx, true)
+	if err != nil {
+		return err
+	}
+	exported := lo.FilterMap(attempts, func(a outbox.Attempt, _ int) (string, bool) {
+		return a.Date.Format("2006-01-02"), a.Err == nil
+	})
+	setResult(c, result{{"exported", exported}})
+	if n := len(attempts); n > 0 && attempts[n-1].Err != nil {
+		return externalError("exporting %s to Google Sheets: %w", attempts[n-1].Date.Format("2006-01-02"), attempts[n-1].Err)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Exit codes. Automation can tell a setup problem (fix it, don't retry) from
+// an upstream outage (retry later) and a run that finished with gaps.
+const (
+	exitFailure  = 1 // unclassified failure
+	exitConfig   = 2 // missing or invalid settings, flags or arguments
+	exitExternal = 3 // Horizon, CoinGecko, Google, Grist, a publish target or the database failed
+	exitPartial  = 4 // the command finished but some items failed
+)
+
+// exitError attaches an exit code to an error. It deliberately doesn't
+// implement cli.ExitCoder: urfave/cli would os.Exit before the result is printed.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func configError(format string, args ...any) error {
+	return &exitError{code: exitConfig, err: fmt.Errorf(format, args...)}
+}
+
+func externalError(format string, args ...any) error {
+	return &exitError{code: exitExternal, err: fmt.Errorf(format, args...)}
+}
+
+func partialError(format string, args ...any) error {
+	return &exitError{code: exitPartial, err: fmt.Errorf(format, args...)}
+}
+
+// partialIf returns a partial-success error when failed of total items
+// failed, nil otherwise.
+func partialIf(failed, total int, items string) error {
+	if failed == 0 {
+		return nil
+	}
+	return partialError("%d of %d %s failed", failed, total, items)
+}
+
+// exitCode maps err to the process exit code. Errors not classified at the
+// call site still count as external when they come from the network or a
+// failed database connection.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.As(err, &connErr) {
+		return exitExternal
+	}
+	// urfave/cli's missing-flag error type is unexported.
+	if strings.HasPrefix(err.Error(), "Required flag") {
+		return exitConfig
+	}
+	return exitFailure
+}
+
+// Output formats accepted by the global --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// Keys under cli.App.Metadata.
+const (
+	metaCommand = "command"
+	metaResult  = "result"
+	metaOutput  = "output"
+)
+
+// field is one key/value of a command result.
+type field struct {
+	Key   string
+	Value any
+}
+
+// result describes what a command did, in a stable key order.
+type result []field
+
+// MarshalJSON encodes r as an object with keys in order.
+func (r result) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.Key)
+		v, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", f.Key, err)
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// setResult records the result printed after the command returns.
+func setResult(c *cli.Context, r result) {
+	c.App.Metadata[metaResult] = r
+}
+
+// instrument wraps every command action so the name of the command that ran
+// (with its parents, e.g. "quote backfill") is known when its result is
+// printed.
+func instrument(cmds []*cli.Command, parents ...string) {
+	for _, cmd := range cmds {
+		name := strings.Join(append(parents, cmd.Name), " ")
+		if action := cmd.Action; action != nil {
+			cmd.Action = func(c *cli.Context) error {
+				c.App.Metadata[metaCommand] = name
+				return action(c)
+			}
+		}
+		instrument(cmd.Subcommands, append(parents, cmd.Name)...)
+	}
+}
+
+// validateOutput is the app Before hook: it checks --output and remembers it
+// for printResult.
+func validateOutput(c *cli.Context) error {
+	switch f := c.String("output"); f {
+	case outputTable, outputJSON, outputYAML:
+		c.App.Metadata[metaOutput] = f
+		return nil
+	default:
+		return configError("invalid --output %q (want table, json or yaml)", f)
+	}
+}
+
+// printResult writes the outcome of the command that ran: its result fields
+// prefixed with command and status, plus the error and exit code on failure.
+// Table output sends errors to errOut so stdout stays parseable.
+func printResult(out, errOut io.Writer, meta map[string]any, runErr error) error {
+	format, _ := meta[metaOutput].(string)
+	if format == "" {
+		format = outputTable
+	}
+	command, _ := meta[metaCommand].(string)
+	fields, _ := meta[metaResult].(result)
+	if command == "" && runErr == nil {
+		return nil // help, version or shell completion
+	}
+
+	status := "ok"
+	switch code := exitCode(runErr); {
+	case code == exitPartial:
+		status = "partial"
+	case code != 0:
+		status = "error"
+	}
+	var r result
+	if command != "" { // empty when flags were rejected before any action ran
+		r = append(r, field{"command", command})
+	}
+	r = append(r, field{"status", status})
+	if runErr != nil {
+		r = append(r, field{"exitCode", exitCode(runErr)}, field{"error", runErr.Error()})
+	}
+	r = append(r, fields...)
+
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case outputYAML:
+		ms := make(yaml.MapSlice, len(r))
+		for i, f := range r {
+			ms[i] = yaml.MapItem{Key: f.Key, Value: yamlValue(f.Value)}
+		}
+		data, err := yaml.Marshal(ms)
+		if err != nil {
+			return fmt.Errorf("encoding YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		if runErr != nil {
+			fmt.Fprintln(errOut, "error:", runErr)
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, f := range r {
+			if f.Key == "error" {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%v\n", f.Key, tableValue(f.Value))
+		}
+		return tw.Flush()
+	}
+}
+
+// yamlValue round-trips v through JSON so YAML uses the same field names and
+// number formatting (decimals as strings) as the JSON output.
+func yamlValue(v any) any {
+	if r, ok := v.(result); ok {
+		ms := make(yaml.MapSlice, len(r))
+		for i, f := range r {
+			ms[i] = yaml.MapItem{Key: f.Key, Value: yamlValue(f.Value)}
+		}
+		return ms
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Sprint(v)
+	}
+	return out
+}
+
+// tableValue renders slices as comma-separated lists.
+func tableValue(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil || len(data) == 0 || data[0] != '[' {
+		return v
+	}
+	var items []any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return v
+	}
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = fmt.Sprint(it)
+	}
+	return strings.Join(parts, ",")
+}
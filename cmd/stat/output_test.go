@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// wrapped wraps err without formatting it, for error types whose Error
+// needs fields a test can't set.
+type wrapped struct{ err error }
+
+func (w wrapped) Error() string { return "wrapped" }
+func (w wrapped) Unwrap() error { return w.err }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"config", configError("invalid --date %q", "x"), exitConfig},
+		{"external", externalError("fetching: %w", errors.New("boom")), exitExternal},
+		{"partial", partialError("2 of 5 dates failed"), exitPartial},
+		{"wrapped config", fmt.Errorf("report: %w", configError("bad")), exitConfig},
+		{"url error", fmt.Errorf("fetching quotes: %w", &url.Error{Op: "Get", URL: "https://api.coingecko.com", Err: errors.New("timeout")}), exitExternal},
+		{"connect error", fmt.Errorf("connecting: %w", wrapped{&pgconn.ConnectError{Config: &pgconn.Config{}}}), exitExternal},
+		{"missing flag", errors.New(`Required flag "date" not set`), exitConfig},
+		{"unclassified", errors.New("boom"), exitFailure},
+		{"classified wins over cause", configError("bad url: %w", &url.Error{Op: "Get", URL: "x", Err: errors.New("y")}), exitConfig},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPartialIf(t *testing.T) {
+	if err := partialIf(0, 5, "dates"); err != nil {
+		t.Errorf("no failures: err = %v, want nil", err)
+	}
+	err := partialIf(2, 5, "dates")
+	if exitCode(err) != exitPartial || err.Error() != "2 of 5 dates failed" {
+		t.Errorf("two failures: err = %v (exit %d), want partial", err, exitCode(err))
+	}
+}
+
+func TestResultMarshalJSONKeepsOrder(t *testing.T) {
+	r := result{{"zeta", 1}, {"alpha", "a"}, {"nested", result{{"y", true}, {"x", []int{1, 2}}}}}
+	data, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"zeta":1,"alpha":"a","nested":{"y":true,"x":[1,2]}}`; string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	fields := result{{"dates", 3}, {"failed", []string{"2026-10-01"}}, {"alpha", "last"}}
+	tests := []struct {
+		name    string
+		format  string
+		err     error
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "json",
+			format: outputJSON,
+			want: `{
+  "command": "report",
+  "status": "ok",
+  "dates": 3,
+  "failed": [
+    "2026-10-01"
+  ],
+  "alpha": "last"
+}
+`,
+		},
+		{
+			name:   "yaml",
+			format: outputYAML,
+			err:    partialError("1 of 3 dates failed"),
+			want: `command: report
+status: partial
+exitCode: 4
+error: 1 of 3 dates failed
+dates: 3
+failed:
+- "2026-10-01"
+alpha: last
+`,
+		},
+		{
+			name:    "table",
+			format:  outputTable,
+			err:     externalError("horizon down"),
+			want:    "command   report\nstatus    error\nexitCode  3\ndates     3\nfailed    2026-10-01\nalpha     last\n",
+			wantErr: "error: horizon down\n",
+		},
+	}
+	for _, tt := range tests {
+		var out, errOut bytes.Buffer
+		meta := map[string]any{metaCommand: "report", metaOutput: tt.format, metaResult: fields}
+		if err := printResult(&out, &errOut, meta, tt.err); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: stdout =\n%s\nwant\n%s", tt.name, out.String(), tt.want)
+		}
+		if errOut.String() != tt.wantErr {
+			t.Errorf("%s: stderr = %q, want %q", tt.name, errOut.String(), tt.wantErr)
+		}
+	}
+}
+
+func TestPrintResultWithoutCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := printResult(&out, &out, map[string]any{}, nil); err != nil || out.Len() != 0 {
+		t.Errorf("help run: printed %q (%v), want nothing", out.String(), err)
+	}
+	out.Reset()
+	if err := printResult(&out, &out, map[string]any{metaOutput: outputJSON}, configError("invalid --output")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `"command"`) || !strings.Contains(out.String(), `"exitCode": 2`) {
+		t.Errorf("rejected flags: %s, want no command and exit code 2", out.String())
+	}
+}
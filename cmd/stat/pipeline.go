@@ -0,0 +1,845 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/accountmeta"
+	"github.com/mtlprog/stat/internal/association"
+	"github.com/mtlprog/stat/internal/chain"
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/conversion"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/fund"
+	"github.com/mtlprog/stat/internal/group"
+	"github.com/mtlprog/stat/internal/holders"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/intraday"
+	"github.com/mtlprog/stat/internal/issuance"
+	"github.com/mtlprog/stat/internal/job"
+	"github.com/mtlprog/stat/internal/ledgerguard"
+	"github.com/mtlprog/stat/internal/liability"
+	"github.com/mtlprog/stat/internal/metrics"
+	"github.com/mtlprog/stat/internal/outbox"
+	"github.com/mtlprog/stat/internal/pacing"
+	"github.com/mtlprog/stat/internal/peer"
+	"github.com/mtlprog/stat/internal/portfolio"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/progress"
+	"github.com/mtlprog/stat/internal/property"
+	"github.com/mtlprog/stat/internal/publish"
+	"github.com/mtlprog/stat/internal/slo"
+	"github.com/mtlprog/stat/internal/snapdate"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/stellarexpert"
+	"github.com/mtlprog/stat/internal/stellarkey"
+	"github.com/mtlprog/stat/internal/subfond"
+	"github.com/mtlprog/stat/internal/supply"
+	"github.com/mtlprog/stat/internal/transport"
+	"github.com/mtlprog/stat/internal/valuation"
+	"github.com/mtlprog/stat/internal/watchlist"
+)
+
+// reportPipeline generates a snapshot and persists its indicators. Shared by
+// `stat report` and the opt-in POST /api/v1/snapshots/generate endpoint.
+type reportPipeline struct {
+	snapshotRepo  *snapshot.PgRepository
+	indicatorRepo *indicator.PgRepository
+	snapshots     *snapshot.Service
+	association   *snapshot.Service // the MTLA entity (stat association-report)
+	assocMaxAge   time.Duration
+	indicatorOpts []indicator.ServiceOption
+	recalcOpts    []indicator.ServiceOption // indicatorOpts without the circuit breaker, which persists its state
+	issuance      *issuance.Service
+	supplies      *supply.Service
+	accountMeta   *accountmeta.Service
+	holders       *holders.Service
+	conversions   *conversion.Service
+	subfonds      *subfond.Service
+	prices        *price.Service
+	policies      *price.PgPolicyRepository
+	quotes        *external.Service
+	quoteHistory  *external.PgQuoteRepository
+	horizon       *horizon.Client
+	ledger        *ledgerguard.Guard
+	clock         snapdate.Clock
+	exports       *outbox.PgRepository // nil without Google Sheets
+	slo           *slo.Service         // nil with SLO_DEADLINE=0
+}
+
+// sharedTransport carries the requests of every external client. It is built
+// by configureTransport before any command runs.
+var sharedTransport *transport.Transport
+
+// configureTransport builds sharedTransport from the HTTP_* settings.
+func configureTransport(cfg config.Config) error {
+	t, err := transport.New(transport.Config{
+		DialTimeout:           cfg.HTTPDialTimeout,
+		KeepAlive:             cfg.HTTPKeepAlive,
+		TLSHandshakeTimeout:   cfg.HTTPTLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.HTTPResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.HTTPIdleConnTimeout,
+		MaxIdleConnsPerHost:   cfg.HTTPMaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.HTTPMaxConnsPerHost,
+		ProxyURL:              cfg.HTTPProxyURL,
+	})
+	if err != nil {
+		return configError("HTTP_PROXY_URL: %w", err)
+	}
+	sharedTransport = t
+	return nil
+}
+
+// roundTripper returns sharedTransport for the clients' WithTransport
+// options, or nil (each client's own pool) when it was never built.
+func roundTripper() http.RoundTripper {
+	if sharedTransport == nil {
+		return nil
+	}
+	return sharedTransport
+}
+
+// newCoinGeckoClient builds the CoinGecko client with its requests counted
+// in the database against COINGECKO_DAILY_BUDGET. Without a pool (SQLite)
+// the requests go uncounted.
+func newCoinGeckoClient(cfg config.Config, pool *pgxpool.Pool) *external.CoinGeckoClient {
+	opts := []external.CoinGeckoOption{external.WithTransport(roundTripper())}
+	if pool != nil {
+		budget := external.NewBudget(external.NewPgRequestCounter(pool), external.CoinGeckoService, cfg.CoinGeckoDailyBudget)
+		opts = append(opts, external.WithBudget(budget))
+	}
+	return external.NewCoinGeckoClient(cfg.CoinGeckoURL, cfg.CoinGeckoDelay, cfg.CoinGeckoRetryMax, opts...)
+}
+
+// newHolderRefresher builds the metrics service that only walks the holder
+// counts into the holder_counts cache, for the serve refresher.
+func newHolderRefresher(cfg config.Config, pool *pgxpool.Pool) *metrics.Service {
+	return metrics.NewService(newHorizonClient(cfg), nil, nil, nil, nil,
+		metrics.WithPacer(pacing.New(cfg.HorizonRPS)),
+		metrics.WithHolderCache(metrics.NewPgHolderCache(pool), cfg.HolderCacheMaxAge))
+}
+
+// newIntradaySampler builds the intraday service sampling the key
+// indicators from Horizon into intraday_metrics.
+func newIntradaySampler(cfg config.Config, pool *pgxpool.Pool) *intraday.Service {
+	client := newHorizonClient(cfg)
+	prices := price.NewService(client, price.WithPacer(pacing.New(cfg.HorizonRPS)))
+	return intraday.NewService(prices, client, intraday.NewPgRepository(pool), "mtlf", cfg.IntradayRetention)
+}
+
+// logTransportStats logs the shared transport's per-host request counters.
+func logTransportStats() {
+	if sharedTransport == nil {
+		return
+	}
+	for _, s := range sharedTransport.Stats() {
+		slog.Info("upstream host stats", "host", s.Host, "requests", s.Requests, "failures", s.Failures)
+	}
+}
+
+// newHorizonClient builds the Horizon client with the configured fallbacks.
+func newHorizonClient(cfg config.Config) *horizon.Client {
+	return horizon.NewClient(cfg.HorizonURL, cfg.HorizonRetryMax, cfg.HorizonRetryBaseDelay,
+		horizon.WithTransport(roundTripper()),
+		horizon.WithFallbackURLs(cfg.HorizonFallbackURLs...),
+		horizon.WithFailoverCooldown(cfg.HorizonFailoverCooldown),
+		horizon.WithMaxLedgerLag(cfg.HorizonMaxLag))
+}
+
+// indicatorOptions applies the calculator settings; every indicator.NewService
+// call that calculates passes them, including the I47 configuration.
+func indicatorOptions(cfg config.Config) ([]indicator.ServiceOption, error) {
+	timeouts, err := indicator.ParseTimeouts(cfg.IndicatorTimeouts)
+	if err != nil {
+		return nil, configError("parsing INDICATOR_TIMEOUTS: %w", err)
+	}
+	varCfg, err := indicator.ParseVaRConfig(cfg.VaRMethod, cfg.VaRConfidence, cfg.VaRHorizon)
+	if err != nil {
+		return nil, configError("parsing VAR_METHOD, VAR_CONFIDENCE and VAR_HORIZON: %w", err)
+	}
+	return []indicator.ServiceOption{
+		indicator.WithDisabledCalculators(cfg.DisabledCalculators...),
+		indicator.WithConcurrency(cfg.IndicatorConcurrency),
+		indicator.WithCalculatorTimeouts(cfg.IndicatorTimeout, timeouts),
+		indicator.WithVaRConfig(varCfg),
+	}, nil
+}
+
+// newFundService builds the fund structure service of a report run and the
+// price service it values tokens with. properties values the fund's real
+// estate (nil leaves it out); prior snapshots are read from snapshots for
+// OTHER_ACCOUNTS_PRICING=weekly.
+func newFundService(cfg config.Config, horizonClient *horizon.Client, pacer *pacing.Pacer, quotes *external.Service,
+	properties valuation.PropertySource, snapshots snapshot.Repository) (*fund.Service, *price.Service, error) {
+	include, err := fund.ParseTokenRules(cfg.TokenInclude)
+	if err != nil {
+		return nil, nil, configError("parsing TOKEN_INCLUDE: %w", err)
+	}
+	exclude, err := fund.ParseTokenRules(cfg.TokenExclude)
+	if err != nil {
+		return nil, nil, configError("parsing TOKEN_EXCLUDE: %w", err)
+	}
+	bridges, err := price.ParseBridges(cfg.PriceBridgeAssets)
+	if err != nil {
+		return nil, nil, configError("parsing PRICE_BRIDGE_ASSETS: %w", err)
+	}
+	otherPricing, err := fund.ParseOtherPricing(cfg.OtherAccountsPricing)
+	if err != nil {
+		return nil, nil, configError("OTHER_ACCOUNTS_PRICING: %w", err)
+	}
+
+	priceSvc := price.NewService(horizonClient, price.WithPacer(pacer),
+		price.WithSanityBound(decimal.NewFromFloat(cfg.PriceMaxMove)), price.WithBridges(bridges),
+		price.WithBatchConcurrency(cfg.PriceConcurrency))
+	valuationOpts := []valuation.Option{valuation.WithPacer(pacer)}
+	if properties != nil {
+		valuationOpts = append(valuationOpts, valuation.WithProperties(properties))
+	}
+	fundSvc := fund.NewService(portfolio.NewService(horizonClient), priceSvc, valuation.NewService(horizonClient, valuationOpts...), quotes,
+		fund.WithPacer(pacer), fund.WithTokenFilter(fund.NewTokenFilter(include, exclude)),
+		fund.WithOtherPricing(otherPricing, priorOtherAccounts{repo: snapshots}))
+	return fundSvc, priceSvc, nil
+}
+
+func newReportPipeline(cfg config.Config, pool *pgxpool.Pool) (*reportPipeline, error) {
+	peers, err := peer.ParseAccounts(cfg.PeerAccounts)
+	if err != nil {
+		return nil, configError("parsing PEER_ACCOUNTS: %w", err)
+	}
+
+	groups, err := group.ParseGroups(cfg.AccountGroups, domain.AccountRegistry())
+	if err != nil {
+		return nil, configError("parsing ACCOUNT_GROUPS: %w", err)
+	}
+
+	obligations, err := liability.ParseObligations(cfg.LiabilityTokens)
+	if err != nil {
+		return nil, configError("parsing LIABILITY_TOKENS: %w", err)
+	}
+
+	assocAccounts, err := association.ParseAccounts(cfg.AssociationAccounts, domain.AccountTypeOperational)
+	if err != nil {
+		return nil, configError("parsing ASSOCIATION_ACCOUNTS: %w", err)
+	}
+	endowment, err := association.ParseAccounts(cfg.AssociationEndowment, domain.AccountTypeEndowment)
+	if err != nil {
+		return nil, configError("parsing ASSOCIATION_ENDOWMENT_ACCOUNTS: %w", err)
+	}
+
+	clock, err := snapshotClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	horizonClient := newHorizonClient(cfg)
+	ledger, err := ledgerguard.NewGuard(horizonClient, cfg.HorizonMaxLag, cfg.HorizonLagPolicy)
+	if err != nil {
+		return nil, configError("HORIZON_MAX_LAG / HORIZON_LAG_POLICY: %w", err)
+	}
+	// Services that only read accounts, orderbooks, pools and payments take
+	// the chain backend; the rest still need Horizon-specific endpoints.
+	var chainSource chain.Source = horizonClient
+	pacer := pacing.New(cfg.HorizonRPS)
+	properties := property.NewService(property.NewPgRepository(pool), "mtlf", cfg.PropertyAppraisalMaxAge)
+
+	coingecko := newCoinGeckoClient(cfg, pool)
+	quoteRepo := external.NewPgQuoteRepository(pool)
+	externalSvc := external.NewService(coingecko, quoteRepo)
+
+	repoOpts := []snapshot.Option{snapshot.WithDeltaStorage(cfg.SnapshotDeltaDays)}
+	if cfg.SnapshotDetachDetails {
+		repoOpts = append(repoOpts, snapshot.WithDetachedDetails())
+	}
+	if cfg.SnapshotSigningSeed != "" {
+		signer, err := stellarkey.ParseSeed(cfg.SnapshotSigningSeed)
+		if err != nil {
+			return nil, configError("parsing SNAPSHOT_SIGNING_SEED: %w", err)
+		}
+		slog.Info("snapshots will be signed", "signer", signer.Address())
+		repoOpts = append(repoOpts, snapshot.WithSigner(signer))
+	}
+	// A stored snapshot reserves its Sheets export in the same transaction,
+	// so the export stays owed if the run dies before queueing it.
+	var exports *outbox.PgRepository
+	if sheetsConfigured(cfg, "mtlf") {
+		exports = outbox.NewPgRepository(pool)
+		repoOpts = append(repoOpts, snapshot.WithSaveHook(exports.Reserve("mtlf")))
+	}
+	snapshotRepo := snapshot.NewPgRepository(pool, repoOpts...)
+
+	fundSvc, priceSvc, err := newFundService(cfg, horizonClient, pacer, externalSvc, properties, snapshotRepo)
+	if err != nil {
+		return nil, err
+	}
+	indicatorRepo := indicator.NewPgRepository(pool)
+	indicatorOpts, err := indicatorOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	recalcOpts := indicatorOpts
+	if cfg.IndicatorBreakerThreshold > 0 {
+		breaker := indicator.NewBreaker(indicatorRepo, "mtlf", cfg.IndicatorBreakerThreshold, cfg.IndicatorBreakerCooldown)
+		indicatorOpts = append(indicatorOpts, indicator.WithCircuitBreaker(breaker))
+	}
+	var fundAddrs []string
+	for _, a := range domain.AccountRegistry() {
+		fundAddrs = append(fundAddrs, a.Address)
+	}
+	expertClient := stellarexpert.NewClient(cfg.StellarExpertURL, stellarexpert.WithTransport(roundTripper()))
+	metricsSvc := metrics.NewService(horizonClient, priceSvc, expertClient, indicatorRepo, fundAddrs,
+		metrics.WithPacer(pacer), metrics.WithQuotes(externalSvc),
+		metrics.WithHolderCache(metrics.NewPgHolderCache(pool), cfg.HolderCacheMaxAge))
+	guard := accountguard.NewService(chainSource, accountguard.NewPgRepository(pool), "mtlf", domain.AccountRegistry(),
+		accountguard.WithMetadataKeys(cfg.AccountMetadataKeys...))
+
+	// Without Grist unexpected supply changes and SLO breaches are only
+	// logged and stored.
+	var supplyNotifier supply.Notifier
+	var sloNotifier slo.Notifier
+	if cfg.GristAPIKey != "" {
+		n, err := newNotifier(cfg, indicatorRepo)
+		if err != nil {
+			return nil, err
+		}
+		supplyNotifier, sloNotifier = n, n
+	}
+	supplies := supply.NewService(horizonClient, snapshotRepo, supply.NewPgRepository(pool), supplyNotifier,
+		domain.AccountRegistry(), cfg.SupplyExpectedChanges, "mtlf")
+	enrichers := []snapshot.MetricsEnricher{ledger, metricsSvc, guard, properties, supplies,
+		watchlist.NewService(chainSource, watchlist.NewPgRepository(pool), "mtlf")}
+	if len(peers) > 0 {
+		enrichers = append(enrichers, peer.NewService(fundSvc, horizonClient, peers))
+	}
+	if len(obligations) > 0 {
+		enrichers = append(enrichers, liability.NewService(horizonClient, obligations))
+	}
+	if len(groups) > 0 {
+		enrichers = append(enrichers, group.NewService(groups))
+	}
+
+	var sloSvc *slo.Service
+	if cfg.SLODeadline > 0 {
+		sloSvc = slo.NewService(slo.NewPgRepository(pool), sloNotifier, "mtlf", cfg.SLODeadline, cfg.SLOAlertAfter)
+	}
+
+	return &reportPipeline{
+		snapshotRepo:  snapshotRepo,
+		indicatorRepo: indicatorRepo,
+		snapshots:     snapshot.NewService(fundSvc, snapshotRepo, enrichers...),
+		association: snapshot.NewService(association.NewService(fundSvc, association.Registry(append(assocAccounts, endowment...)...)),
+			snapshotRepo),
+		assocMaxAge:   cfg.AssociationMaxAge,
+		indicatorOpts: indicatorOpts,
+		recalcOpts:    recalcOpts,
+		issuance:      issuance.NewService(horizonClient, snapshotRepo, issuance.NewPgRepository(pool), "mtlf"),
+		supplies:      supplies,
+		accountMeta:   accountmeta.NewService(snapshotRepo, accountmeta.NewPgRepository(pool), "mtlf"),
+		holders:       holders.NewService(horizonClient, holders.NewPgRepository(pool), "mtlf"),
+		conversions:   conversion.NewService(horizonClient, conversion.NewPgRepository(pool), "mtlf"),
+		subfonds:      subfond.NewService(chainSource, snapshotRepo, subfond.NewPgRepository(pool), "mtlf"),
+		prices:        priceSvc,
+		policies:      price.NewPgPolicyRepository(pool),
+		quotes:        externalSvc,
+		quoteHistory:  quoteRepo,
+		horizon:       horizonClient,
+		ledger:        ledger,
+		clock:         clock,
+		exports:       exports,
+		slo:           sloSvc,
+	}, nil
+}
+
+// warmCaches seeds the price and quote caches from the latest stored
+// snapshot. Prices count as fresh for maxAge after the snapshot was written,
+// quotes for maxAge after they were fetched; anything older is left for the
+// run to resolve. A missing or unreadable snapshot only costs the warm-up.
+func (p *reportPipeline) warmCaches(ctx context.Context, maxAge time.Duration) {
+	s, err := p.snapshots.GetLatest(ctx, "mtlf")
+	if errors.Is(err, snapshot.ErrNotFound) {
+		slog.Info("price cache warm-up skipped", "reason", err)
+		return
+	}
+	if err != nil {
+		slog.Error("price cache warm-up skipped", "error", err)
+		return
+	}
+	// Warmed prices carry their details into the next snapshot, so detached
+	// ones are put back first.
+	doc := s.Data
+	if details, err := p.snapshotRepo.GetDetails(ctx, "mtlf", s.SnapshotDate, ""); err != nil {
+		slog.Error("price cache warm-up without detached details", "date", s.SnapshotDate.Format("2006-01-02"), "error", err)
+	} else if doc, err = snapshot.AttachDetails(s.Data, details); err != nil {
+		slog.Error("price cache warm-up without detached details", "date", s.SnapshotDate.Format("2006-01-02"), "error", err)
+		doc = s.Data
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(doc, &data); err != nil {
+		slog.Error("price cache warm-up skipped: decoding snapshot", "date", s.SnapshotDate.Format("2006-01-02"), "error", err)
+		return
+	}
+	prices := p.prices.Warm(data, s.CreatedAt.Add(maxAge))
+	quotes := p.quotes.WarmQuotes(data.Quotes, maxAge)
+	slog.Info("price cache warmed from snapshot", "date", s.SnapshotDate.Format("2006-01-02"), "createdAt", s.CreatedAt, "prices", prices, "quotes", quotes)
+}
+
+// setPriceReference sets the sanity-bound reference prices to those of the
+// latest snapshot before date. Without one, prices go unchecked this run.
+func setPriceReference(ctx context.Context, snapshots snapshot.Repository, prices *price.Service, date time.Time) {
+	s, err := snapshots.GetNearestBefore(ctx, "mtlf", date.AddDate(0, 0, -1))
+	if err != nil {
+		slog.Info("price sanity bound has no reference", "reason", err)
+		return
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		slog.Error("price sanity bound has no reference: decoding snapshot", "date", s.SnapshotDate.Format("2006-01-02"), "error", err)
+		return
+	}
+	n := prices.SetReference(data)
+	slog.Debug("price sanity bound reference set", "date", s.SnapshotDate.Format("2006-01-02"), "pairs", n)
+}
+
+// setPricingPolicies applies the pricing policies managed through
+// /api/v1/admin/entities/mtlf/pricing to this run's spot prices.
+func (p *reportPipeline) setPricingPolicies(ctx context.Context) error {
+	list, err := p.policies.Policies(ctx, "mtlf")
+	if err != nil {
+		return err
+	}
+	if changed := p.prices.SetPolicies(list); len(changed) > 0 {
+		slog.Info("pricing policies applied", "policies", len(list), "changed", changed)
+	}
+	return nil
+}
+
+// CacheSizes reports the price and quote cache sizes for the diagnostics endpoint.
+func (p *reportPipeline) CacheSizes() map[string]int {
+	return map[string]int{
+		"prices": p.prices.CacheSize(),
+		"quotes": p.quotes.WarmQuoteCount(),
+	}
+}
+
+// HorizonInFlight reports the Horizon requests in progress.
+func (p *reportPipeline) HorizonInFlight() int {
+	return p.horizon.InFlight()
+}
+
+// run generates the snapshot for date, then calculates and persists indicators.
+// A failing calculator doesn't abort the run: whatever could be computed is
+// persisted and the failures are returned alongside. A cancelled ctx aborts
+// before anything partial is written.
+//
+// A date before today is generated as of the end of that day
+// (backfillSnapshot). The stages that only observe the present (issuance
+// and supply detection, account metadata, holder sets, the conversion and
+// subfond flow scans) are skipped, and
+// the indicators are stored with the ledger source.
+func (p *reportPipeline) run(ctx context.Context, date time.Time) (indicator.PartialResult, error) {
+	p.horizon.CheckHealth(ctx)
+	defer p.logHorizonStats()
+	defer logTransportStats()
+	if err := p.ledger.Check(ctx); err != nil {
+		return indicator.PartialResult{}, externalError("%w", err)
+	}
+
+	setPriceReference(ctx, p.snapshotRepo, p.prices, date)
+	if err := p.setPricingPolicies(ctx); err != nil {
+		return indicator.PartialResult{}, err
+	}
+
+	past := date.Before(p.clock.Today())
+	var data domain.FundStructureData
+	var stage stageTimer
+	var err error
+	if past {
+		if data, err = p.backfillSnapshot(ctx, date); err != nil {
+			return indicator.PartialResult{}, err
+		}
+	} else {
+		stage = startStage("snapshot_generate")
+		if data, err = p.snapshots.Generate(ctx, "mtlf", date); err != nil {
+			return indicator.PartialResult{}, externalError("generating snapshot: %w", err)
+		}
+		stage.done("date", date.Format("2006-01-02"))
+
+		// Supply changes only annotate the run: a failure is logged, not fatal.
+		stage = startStage("issuance_detect")
+		if events, err := p.issuance.Record(ctx, date, data); err != nil {
+			slog.Error("issuance event detection failed", "date", date.Format("2006-01-02"), "error", err)
+		} else {
+			stage.done("events", len(events))
+		}
+
+		// The supply audit only alerts: a failure is logged, not fatal.
+		stage = startStage("supply_audit")
+		if changes, err := p.supplies.Record(ctx, date, data); err != nil {
+			slog.Error("supply audit failed", "date", date.Format("2006-01-02"), "error", err)
+		} else {
+			stage.done("changes", len(changes))
+		}
+
+		// Metadata events only annotate the accounts: a failure is logged, not fatal.
+		stage = startStage("account_metadata")
+		if events, err := p.accountMeta.Record(ctx, date, data); err != nil {
+			slog.Error("account metadata sync failed", "date", date.Format("2006-01-02"), "error", err)
+		} else {
+			stage.done("events", len(events))
+		}
+	}
+
+	// Holder churn feeds I67–I72 only: a failure leaves them out of this run.
+	// A past date records no set (holders are only read live); its churn
+	// compares the sets already stored.
+	stage = startStage("holders_record")
+	var churn []holders.Churn
+	var sets []holders.Set
+	var setsErr error
+	if !past {
+		sets, setsErr = p.holders.Record(ctx, date)
+	}
+	if setsErr != nil {
+		slog.Error("holder set recording failed", "date", date.Format("2006-01-02"), "error", setsErr)
+	} else if churn, err = p.holders.Churn(ctx, date, holders.DefaultPeriodDays); err != nil {
+		slog.Error("holder churn failed", "date", date.Format("2006-01-02"), "error", err)
+	} else {
+		stage.done("sets", len(sets), "churn", len(churn))
+	}
+
+	// Conversions feed I73/I74 only: a failure leaves them out of this run.
+	// A past date skips the scan and totals the conversions already stored.
+	stage = startStage("conversions_record")
+	var conversions *conversion.Stats
+	var found []conversion.Conversion
+	var scanErr error
+	if !past {
+		found, scanErr = p.conversions.Record(ctx)
+	}
+	if scanErr != nil {
+		slog.Error("MTLRECT conversion scan failed", "date", date.Format("2006-01-02"), "error", scanErr)
+	} else if stats, err := p.conversions.Stats(ctx, date, conversion.DefaultPeriodDays); err != nil {
+		slog.Error("MTLRECT conversion totals failed", "date", date.Format("2006-01-02"), "error", err)
+	} else {
+		conversions = &stats
+		stage.done("found", len(found), "total", stats.Total.String())
+	}
+
+	// Subfond flows feed I83–I86 only: a failure leaves them out of this run.
+	// A past date skips the scan and reads the flows already stored.
+	stage = startStage("subfond_flows")
+	var subfondFlows *subfond.Window
+	var flows []subfond.Flow
+	var flowErr error
+	if !past {
+		flows, flowErr = p.subfonds.Record(ctx)
+	}
+	if flowErr != nil {
+		slog.Error("subfond flow scan failed", "date", date.Format("2006-01-02"), "error", flowErr)
+	} else if w, err := p.subfonds.Window(ctx, date.AddDate(0, 0, -subfond.DefaultPeriodDays), date); err != nil {
+		slog.Error("subfond flows failed", "date", date.Format("2006-01-02"), "error", err)
+	} else {
+		subfondFlows = &w
+		stage.done("found", len(flows), "window", len(w.Flows))
+	}
+
+	indicatorSvc, err := p.calculator(ctx, date, p.indicatorOpts, churn, conversions, subfondFlows)
+	if err != nil {
+		return indicator.PartialResult{}, err
+	}
+
+	progress.Report(ctx, progress.Event{Stage: progress.StageIndicators})
+	stage = startStage("indicator_calculate")
+	res, err := indicatorSvc.CalculatePartial(ctx, data)
+	if err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("calculating indicators: %w", err)
+	}
+	slo.Since(ctx, slo.StageMetrics, stage.start)
+	for _, f := range res.Failures {
+		slog.Error("indicator calculator failed", "calculator", f.Calculator, "ids", f.IDs, "skipped", f.Skipped, "stale", f.Stale, "error", f.Error)
+	}
+	if len(res.Indicators) == 0 {
+		return indicator.PartialResult{}, fmt.Errorf("calculating indicators: all %d calculators failed", len(res.Failures))
+	}
+	stage.done("count", len(res.Indicators), "unavailable", len(res.UnavailableIDs()), "stale", len(res.StaleIDs()))
+	// Values served by an open circuit breaker are stored as carried, not measured.
+	stale := make(map[int]bool)
+	for _, id := range res.StaleIDs() {
+		stale[id] = true
+	}
+	var measured, carried []indicator.Indicator
+	for _, ind := range res.Indicators {
+		if stale[ind.ID] {
+			carried = append(carried, ind)
+		} else {
+			measured = append(measured, ind)
+		}
+	}
+
+	entityID, err := p.snapshotRepo.GetEntityID(ctx, "mtlf")
+	if err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("getting entity id for indicator persistence: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("indicators not saved: %w", err)
+	}
+
+	source := indicator.SourceMeasured
+	if past {
+		source = indicator.SourceLedger
+	}
+	stage = startStage("indicator_persist")
+	if err := p.indicatorRepo.SaveBatches(ctx, entityID, date,
+		indicator.Batch{Indicators: measured, Source: source},
+		indicator.Batch{Indicators: carried, Source: indicator.SourceCarried}); err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("persisting indicators: %w", err)
+	}
+	slo.Since(ctx, slo.StagePersistence, stage.start)
+	stage.done("count", len(res.Indicators), "carried", len(carried), "date", date.Format("2006-01-02"))
+
+	// The export reserved with the snapshot is due from here on, whoever
+	// ends up writing it.
+	if p.exports != nil {
+		if err := p.exports.Enqueue(ctx, "mtlf", date); err != nil {
+			return indicator.PartialResult{}, err
+		}
+	}
+
+	progress.Report(ctx, progress.Event{Stage: progress.StageDone})
+	return res, nil
+}
+
+// calculator returns the indicator service of date with opts, reading
+// history from the repositories and taking the holder churn, MTLRECT
+// conversions and subfond flows gathered for the run.
+func (p *reportPipeline) calculator(ctx context.Context, date time.Time, opts []indicator.ServiceOption, churn []holders.Churn,
+	conversions *conversion.Stats, subfondFlows *subfond.Window) (*indicator.Service, error) {
+	indexCfg, err := p.indicatorRepo.GetIndexConfig(ctx, "mtlf")
+	if err != nil {
+		return nil, err
+	}
+	hist := &indicator.HistoricalData{Repo: p.snapshotRepo, IndicatorRepo: p.indicatorRepo, Slug: "mtlf", Index: &indexCfg,
+		Churn: churn, Conversions: conversions, SubfondFlows: subfondFlows, Quotes: p.quoteHistory, Association: p.associationData(ctx, date), Date: date}
+	return indicator.NewService(hist, opts...), nil
+}
+
+// recalculate calculates the indicators of the stored snapshot of date with
+// the current calculators without writing anything: churn, conversions and
+// subfond flows are read as stored, as for a past date, and nothing is
+// fetched from Horizon or persisted.
+func (p *reportPipeline) recalculate(ctx context.Context, date time.Time) (indicator.PartialResult, error) {
+	snap, err := p.snapshotRepo.GetByDate(ctx, "mtlf", date)
+	if err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("loading snapshot %s: %w", date.Format("2006-01-02"), err)
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(snap.Data, &data); err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("decoding snapshot %s: %w", date.Format("2006-01-02"), err)
+	}
+
+	// As in run, these inputs only feed a few indicators: a failure leaves
+	// them out.
+	churn, err := p.holders.Churn(ctx, date, holders.DefaultPeriodDays)
+	if err != nil {
+		slog.Error("holder churn failed", "date", date.Format("2006-01-02"), "error", err)
+	}
+	var conversions *conversion.Stats
+	if stats, err := p.conversions.Stats(ctx, date, conversion.DefaultPeriodDays); err != nil {
+		slog.Error("MTLRECT conversion totals failed", "date", date.Format("2006-01-02"), "error", err)
+	} else {
+		conversions = &stats
+	}
+	var subfondFlows *subfond.Window
+	if w, err := p.subfonds.Window(ctx, date.AddDate(0, 0, -subfond.DefaultPeriodDays), date); err != nil {
+		slog.Error("subfond flows failed", "date", date.Format("2006-01-02"), "error", err)
+	} else {
+		subfondFlows = &w
+	}
+
+	indicatorSvc, err := p.calculator(ctx, date, p.recalcOpts, churn, conversions, subfondFlows)
+	if err != nil {
+		return indicator.PartialResult{}, err
+	}
+	res, err := indicatorSvc.CalculatePartial(ctx, data)
+	if err != nil {
+		return indicator.PartialResult{}, fmt.Errorf("calculating indicators: %w", err)
+	}
+	return res, nil
+}
+
+// backfillSnapshot regenerates the snapshot for a past date from ledger
+// history (snapshot.Service.GenerateAsOf): balances as of the end of that
+// snapshot day (the next day's cut-off), prices from that day's trade
+// aggregation close, and external quotes from quote_history. Live metrics
+// enrichers and peers are skipped because they can't look back.
+// Returns horizon.ErrBeforeHistory when the date predates the Horizon window.
+func (p *reportPipeline) backfillSnapshot(ctx context.Context, date time.Time) (domain.FundStructureData, error) {
+	at := p.clock.Start(date.AddDate(0, 0, 1)).Add(-time.Second) // end of the snapshot day
+	ledger, err := p.horizon.FetchLedgerAt(ctx, at)
+	if err != nil {
+		return domain.FundStructureData{}, externalError("resolving ledger for %s: %w", date.Format("2006-01-02"), err)
+	}
+
+	stage := startStage("snapshot_backfill")
+	data, err := p.snapshots.GenerateAsOf(ctx, "mtlf", date, at)
+	if err != nil {
+		return domain.FundStructureData{}, externalError("generating snapshot as of %s: %w", date.Format("2006-01-02"), err)
+	}
+	stage.done("date", date.Format("2006-01-02"), "ledger", ledger.Sequence)
+	return data, nil
+}
+
+// runAssociation generates and stores the association snapshot of date, as
+// of the end of that day when date is before today.
+func (p *reportPipeline) runAssociation(ctx context.Context, date time.Time) (domain.FundStructureData, error) {
+	p.horizon.CheckHealth(ctx)
+	defer p.logHorizonStats()
+	if err := p.ledger.Check(ctx); err != nil {
+		return domain.FundStructureData{}, externalError("%w", err)
+	}
+
+	stage := startStage("association_snapshot")
+	var data domain.FundStructureData
+	var err error
+	if date.Before(p.clock.Today()) {
+		at := p.clock.Start(date.AddDate(0, 0, 1)).Add(-time.Second) // end of the snapshot day
+		data, err = p.association.GenerateAsOf(ctx, association.Slug, date, at)
+	} else {
+		data, err = p.association.Generate(ctx, association.Slug, date)
+	}
+	if err != nil {
+		return domain.FundStructureData{}, externalError("generating association snapshot: %w", err)
+	}
+	stage.done("date", date.Format("2006-01-02"), "accounts", len(data.Accounts), "totalEURMTL", data.AggregatedTotals.TotalEURMTL.String())
+	return data, nil
+}
+
+// associationData returns the latest association snapshot at or before
+// date for I28/I29, or nil when there is none within assocMaxAge. The
+// association runs on its own schedule, so a missing one only leaves the
+// two indicators out.
+func (p *reportPipeline) associationData(ctx context.Context, date time.Time) *domain.FundStructureData {
+	s, err := p.snapshotRepo.GetNearestBefore(ctx, association.Slug, date)
+	if err != nil {
+		if !errors.Is(err, snapshot.ErrNotFound) {
+			slog.Error("loading association snapshot failed", "date", date.Format("2006-01-02"), "error", err)
+		}
+		return nil
+	}
+	if age := date.Sub(s.SnapshotDate); age > p.assocMaxAge {
+		slog.Info("association snapshot too old, I28/I29 left out", "date", date.Format("2006-01-02"), "snapshot", s.SnapshotDate.Format("2006-01-02"))
+		return nil
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		slog.Error("decoding association snapshot failed", "snapshot", s.SnapshotDate.Format("2006-01-02"), "error", err)
+		return nil
+	}
+	return &data
+}
+
+// logHorizonStats logs the per-endpoint request counters of this process.
+func (p *reportPipeline) logHorizonStats() {
+	for _, s := range p.horizon.Stats() {
+		slog.Info("horizon endpoint stats", "url", s.URL, "active", s.Active, "healthy", s.Healthy,
+			"requests", s.Requests, "failures", s.Failures, "failovers", s.Failovers, "lastError", s.LastError)
+	}
+}
+
+// tracked calls fn with the run of date timed against SLO_DEADLINE and
+// records it. Only the daily run is tracked: a past date is a backfill. A
+// failure to record is logged; fn's error is returned as is.
+func (p *reportPipeline) tracked(ctx context.Context, date time.Time, fn func(context.Context) error) error {
+	if p.slo == nil || date.Before(p.clock.Today()) {
+		return fn(ctx)
+	}
+	run := slo.NewRun(time.Now())
+	err := fn(slo.WithRun(ctx, run))
+	// Recorded even when the run timed out, on a context that still can be used.
+	if _, recErr := p.slo.Finish(context.WithoutCancel(ctx), date, run, err); recErr != nil {
+		slog.Error("recording pipeline run failed", "date", date.Format("2006-01-02"), "error", recErr)
+	}
+	return err
+}
+
+// GenerateReport implements job.Generator.
+func (p *reportPipeline) GenerateReport(ctx context.Context, date time.Time) (job.Result, error) {
+	var res indicator.PartialResult
+	err := p.tracked(ctx, date, func(ctx context.Context) error {
+		var err error
+		res, err = p.run(ctx, date)
+		return err
+	})
+	if err != nil {
+		return job.Result{}, err
+	}
+	return job.Result{
+		SnapshotDate:   date.Format("2006-01-02"),
+		IndicatorCount: len(res.Indicators),
+		Errors:         res.Failures,
+	}, nil
+}
+
+// newPublisher builds the dataset publisher selected by PUBLISH_TARGET, or
+// nil when publishing is off.
+func newPublisher(cfg config.Config, snapshots snapshot.Repository, indicators indicator.Repository) (*publish.Service, error) {
+	var target publish.Target
+	switch cfg.PublishTarget {
+	case "":
+		return nil, nil
+	case "dir":
+		if cfg.PublishDir == "" {
+			return nil, configError("PUBLISH_DIR is required for PUBLISH_TARGET=dir")
+		}
+		target = publish.NewDirTarget(cfg.PublishDir)
+	case "s3":
+		if cfg.PublishS3Endpoint == "" || cfg.PublishS3Bucket == "" || cfg.PublishS3AccessKey == "" || cfg.PublishS3SecretKey == "" {
+			return nil, configError("PUBLISH_S3_ENDPOINT, PUBLISH_S3_BUCKET, PUBLISH_S3_ACCESS_KEY and PUBLISH_S3_SECRET_KEY are required for PUBLISH_TARGET=s3")
+		}
+		target = publish.NewS3Target(publish.S3Config{
+			Endpoint:  cfg.PublishS3Endpoint,
+			Bucket:    cfg.PublishS3Bucket,
+			Region:    cfg.PublishS3Region,
+			Prefix:    cfg.PublishS3Prefix,
+			AccessKey: cfg.PublishS3AccessKey,
+			SecretKey: cfg.PublishS3SecretKey,
+		}, publish.WithTransport(roundTripper()))
+	case "ipfs":
+		target = publish.NewIPFSTarget(cfg.PublishIPFSAPI, cfg.PublishIPFSPath, cfg.PublishIPNSKey, publish.WithTransport(roundTripper()))
+	default:
+		return nil, configError("unknown PUBLISH_TARGET %q (want dir, s3 or ipfs)", cfg.PublishTarget)
+	}
+	return publish.NewService(snapshots, indicators, target, cfg.PublishIndexDays), nil
+}
+
+// priorOtherAccounts implements fund.PriorOthers from the stored fund
+// snapshots, for OTHER_ACCOUNTS_PRICING=weekly.
+type priorOtherAccounts struct {
+	repo snapshot.Repository
+}
+
+func (p priorOtherAccounts) PriorOtherAccounts(ctx context.Context, at time.Time) ([]domain.FundAccountPortfolio, error) {
+	s, err := p.repo.GetNearestBefore(ctx, "mtlf", at)
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading prior snapshot: %w", err)
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		return nil, fmt.Errorf("decoding prior snapshot %s: %w", s.SnapshotDate.Format("2006-01-02"), err)
+	}
+	return data.OtherAccounts, nil
+}
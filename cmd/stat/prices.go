@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/pacing"
+	"github.com/mtlprog/stat/internal/portfolio"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/priceaudit"
+	"github.com/mtlprog/stat/internal/property"
+	"github.com/mtlprog/stat/internal/valuation"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// runPricesAudit prices every token held by the fund through each source and
+// fails when a critical asset's sources disagree by more than --threshold.
+func runPricesAudit(c *cli.Context) error {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return configError("DATABASE_URL is required")
+	}
+	if c.Float64("threshold") <= 0 {
+		return configError("--threshold must be positive")
+	}
+	bridges, err := price.ParseBridges(cfg.PriceBridgeAssets)
+	if err != nil {
+		return configError("parsing PRICE_BRIDGE_ASSETS: %w", err)
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return externalError("connecting to database: %w", err)
+	}
+	defer pool.Close()
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	horizonClient := newHorizonClient(cfg)
+	pacer := pacing.New(cfg.HorizonRPS)
+	properties := property.NewService(property.NewPgRepository(pool), "mtlf", cfg.PropertyAppraisalMaxAge)
+	audit := priceaudit.NewService(
+		portfolio.NewService(horizonClient),
+		price.NewService(horizonClient, price.WithPacer(pacer), price.WithBridges(bridges)),
+		valuation.NewService(horizonClient, valuation.WithPacer(pacer), valuation.WithProperties(properties)),
+		external.NewService(nil, external.NewPgQuoteRepository(pool)),
+	)
+
+	threshold := decimal.NewFromFloat(c.Float64("threshold"))
+	report, err := audit.Audit(ctx, domain.AggregatedAccounts(), c.StringSlice("critical"), threshold)
+	if err != nil {
+		return externalError("auditing prices: %w", err)
+	}
+
+	res := result{{"tokens", len(report.Rows)}, {"divergent", len(report.Divergent(false))}}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		res = append(res, field{"items", report.Rows})
+	} else if err := printPriceAudit(c, report); err != nil {
+		return err
+	}
+	setResult(c, res)
+
+	if critical := report.Divergent(true); len(critical) > 0 {
+		codes := make([]string, len(critical))
+		for i, row := range critical {
+			codes[i] = row.Asset.Code
+		}
+		return partialError("%d critical assets have divergent prices: %s", len(critical), strings.Join(codes, ", "))
+	}
+	return nil
+}
+
+// printPriceAudit writes one line per token with its price from each source,
+// marking divergent tokens and critical ones among them.
+func printPriceAudit(c *cli.Context, report priceaudit.Report) error {
+	tw := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "ASSET\tBALANCE")
+	for _, src := range priceaudit.Sources {
+		fmt.Fprint(tw, "\t", strings.ToUpper(src))
+	}
+	fmt.Fprintln(tw, "\tSPREAD\t")
+	for _, row := range report.Rows {
+		fmt.Fprintf(tw, "%s\t%s", row.Asset.Code, row.Balance.StringFixed(2))
+		for _, src := range priceaudit.Sources {
+			cell := "-"
+			if q, ok := row.Quote(src); ok && q.Price != nil {
+				cell = q.Price.Round(7).String()
+			}
+			fmt.Fprint(tw, "\t", cell)
+		}
+		spread, mark := "-", ""
+		if row.Spread != nil {
+			spread = row.Spread.Mul(decimal.NewFromInt(100)).StringFixed(1) + "%"
+		}
+		switch {
+		case row.Divergent && row.Critical:
+			mark = "DIVERGENT (critical)"
+		case row.Divergent:
+			mark = "DIVERGENT"
+		}
+		fmt.Fprintf(tw, "\t%s\t%s\n", spread, mark)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.App.Writer)
+	return nil
+}
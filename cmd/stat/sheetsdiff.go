@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/export"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// runSheetsDiff is `stat report --sheets-diff`: it recalculates the
+// indicators of a stored snapshot (--date, default the latest) with the
+// current calculators and prints how the IND_ALL, IND_MAIN and MONITORING
+// rows an export would write differ from the spreadsheet. Nothing is
+// written to the database or the spreadsheet.
+func runSheetsDiff(ctx context.Context, c *cli.Context, cfg config.Config, pool *pgxpool.Pool, pipeline *reportPipeline) error {
+	if !sheetsConfigured(cfg, "mtlf") {
+		return configError("GOOGLE_SHEETS_SPREADSHEET_ID and GOOGLE_CREDENTIALS_JSON are required for --sheets-diff")
+	}
+
+	var date time.Time
+	if v := c.String("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return configError("invalid --date: %w", err)
+		}
+		date = d
+	} else {
+		latest, err := pipeline.snapshotRepo.GetLatest(ctx, "mtlf")
+		if errors.Is(err, snapshot.ErrNotFound) {
+			return configError("no stored snapshot to diff; run stat report first")
+		}
+		if err != nil {
+			return fmt.Errorf("loading latest snapshot: %w", err)
+		}
+		d := latest.SnapshotDate
+		date = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	stage := startStage("indicator_recalculate")
+	res, err := pipeline.recalculate(ctx, date)
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return configError("no stored snapshot for %s", date.Format("2006-01-02"))
+	}
+	if err != nil {
+		return err
+	}
+	stage.done("count", len(res.Indicators), "unavailable", len(res.UnavailableIDs()))
+
+	exporter, err := newSheetsExporter(ctx, cfg, pool, pipeline.indicatorRepo, pipeline.snapshots, pipeline.clock)
+	if err != nil {
+		return err
+	}
+	diffs, err := exporter.diff(ctx, date, res)
+	if err != nil {
+		return externalError("reading Google Sheets: %w", err)
+	}
+
+	out := result{{"date", date.Format("2006-01-02")}}
+	changed := 0
+	for _, d := range diffs {
+		changed += len(d.Changed) + len(d.Added) + len(d.Removed)
+		out = append(out, field{d.Sheet, fmt.Sprintf("%d changed, %d added, %d removed", len(d.Changed), len(d.Added), len(d.Removed))})
+	}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		out = append(out, field{"items", diffs})
+	} else {
+		printSheetDiffs(c, diffs)
+	}
+	setResult(c, append(out, field{"differences", changed}))
+	return nil
+}
+
+// printSheetDiffs writes one block per sheet: ~ for a changed cell, + for a
+// row only the export has, - for a row only the sheet has.
+func printSheetDiffs(c *cli.Context, diffs []export.SheetDiff) {
+	w := c.App.Writer
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s: %d changed, %d added, %d removed\n", d.Sheet, len(d.Changed), len(d.Added), len(d.Removed))
+		if d.Note != "" {
+			fmt.Fprintf(w, "  (%s)\n", d.Note)
+		}
+		for _, ch := range d.Changed {
+			fmt.Fprintf(w, "  ~ %s %s: %s → %s\n", ch.Key, ch.Column, cellText(ch.Old), cellText(ch.New))
+		}
+		for _, key := range d.Added {
+			fmt.Fprintf(w, "  + %s\n", key)
+		}
+		for _, key := range d.Removed {
+			fmt.Fprintf(w, "  - %s\n", key)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// cellText renders a cell for the diff, marking empty ones.
+func cellText(v any) string {
+	if s := export.FormatCell(v); s != "" {
+		return s
+	}
+	return "(empty)"
+}
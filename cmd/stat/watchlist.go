@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mtlprog/stat/internal/config"
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/watchlist"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// openWatchlist connects to the database and returns the watchlist
+// repository.
+func openWatchlist(c *cli.Context) (*watchlist.PgRepository, func(), error) {
+	ctx := c.Context
+	cfg := config.Load()
+
+	if cfg.DatabaseURL == "" {
+		return nil, nil, configError("DATABASE_URL is required")
+	}
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, externalError("connecting to database: %w", err)
+	}
+	if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("running migrations: %w", err)
+	}
+	if _, err := snapshot.NewPgRepository(pool).EnsureEntity(ctx, "mtlf", "Montelibero Fund", "Montelibero Fund statistics"); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("ensuring entity: %w", err)
+	}
+	return watchlist.NewPgRepository(pool), pool.Close, nil
+}
+
+// runWatchlistAdd adds an account to the watchlist or replaces its label
+// and tracked assets.
+func runWatchlistAdd(c *cli.Context) error {
+	e := watchlist.Entry{Address: c.Args().First(), Label: c.String("label")}
+	for _, s := range c.StringSlice("asset") {
+		a, err := watchlist.ParseAsset(s)
+		if err != nil {
+			return configError("%w", err)
+		}
+		e.Assets = append(e.Assets, a)
+	}
+	if err := e.Validate(); err != nil {
+		return configError("%w", err)
+	}
+
+	repo, closeDB, err := openWatchlist(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	e, err = repo.Put(c.Context, "mtlf", e)
+	if err != nil {
+		return err
+	}
+	setResult(c, result{{"id", e.ID}, {"address", e.Address}, {"label", e.Label}, {"assets", assetNames(e)}})
+	return nil
+}
+
+// runWatchlistList prints the watched accounts.
+func runWatchlistList(c *cli.Context) error {
+	repo, closeDB, err := openWatchlist(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	list, err := repo.List(c.Context, "mtlf")
+	if err != nil {
+		return err
+	}
+	res := result{{"accounts", len(list)}}
+	if format, _ := c.App.Metadata[metaOutput].(string); format == outputJSON || format == outputYAML {
+		res = append(res, field{"items", list})
+	} else {
+		for _, e := range list {
+			line := fmt.Sprintf("%s  %s", e.Address, e.Label)
+			if len(e.Assets) > 0 {
+				line += "  [" + strings.Join(assetNames(e), ", ") + "]"
+			}
+			fmt.Fprintln(c.App.Writer, line)
+		}
+		if len(list) > 0 {
+			fmt.Fprintln(c.App.Writer)
+		}
+	}
+	setResult(c, res)
+	return nil
+}
+
+// runWatchlistDelete removes an account from the watchlist.
+func runWatchlistDelete(c *cli.Context) error {
+	address := c.Args().First()
+	if address == "" {
+		return configError("expected an account address")
+	}
+
+	repo, closeDB, err := openWatchlist(c)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := repo.Delete(c.Context, "mtlf", address); err != nil {
+		if errors.Is(err, watchlist.ErrNotFound) {
+			return configError("%w", err)
+		}
+		return err
+	}
+	setResult(c, result{{"deleted", address}})
+	return nil
+}
+
+// assetNames returns the tracked assets of e in their CODE:ISSUER form.
+func assetNames(e watchlist.Entry) []string {
+	out := make([]string, 0, len(e.Assets))
+	for _, a := range e.Assets {
+		out = append(out, a.Canonical())
+	}
+	return out
+}
@@ -15,21 +15,21 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/charts/balance-by-subfund": {
+        "/api/v1/accounts/metadata/events": {
             "get": {
-                "description": "Returns the EURMTL value of the 4 sub-fund accounts (MABIZ, MCITY, DEFI, BOSS) plus MAIN ISSUER and ADMIN for a given date.",
+                "description": "Changes of what the fund accounts publish about themselves on chain, between consecutive snapshots, oldest first. ` + "`" + `field` + "`" + ` is ` + "`" + `home_domain` + "`" + ` or a DATA entry key from ACCOUNT_METADATA_KEYS; ` + "`" + `kind` + "`" + ` is added, changed or removed, with the ` + "`" + `old` + "`" + ` and ` + "`" + `new` + "`" + ` values (DATA values decoded, base64 when not UTF-8). The current values are in ` + "`" + `accountConfigs[].metadata` + "`" + ` of each snapshot.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "charts"
+                    "accounts"
                 ],
-                "summary": "Fund balance split by sub-fund",
+                "summary": "Fund account metadata changes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Snapshot date (YYYY-MM-DD); defaults to latest",
-                        "name": "date",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
                         "in": "query"
                     }
                 ],
@@ -37,46 +37,50 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_api.BalanceBySubfundResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_accountmeta.Event"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/charts/indicator-history": {
+        "/api/v1/accounts/{address}/balances/{asset}/history": {
             "get": {
-                "description": "Returns historical points for one or more indicator IDs over the requested range.",
+                "description": "Returns one account's balance of one asset on each snapshot date in the range, oldest first, read from the account_balances table rather than full snapshots. Dates on which the account had no trustline for the asset are omitted; zero balances on existing trustlines are included.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "charts"
+                    "accounts"
                 ],
-                "summary": "Indicator time-series",
+                "summary": "Account balance history",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comma-separated indicator IDs (e.g. 1,3,17,24,27)",
-                        "name": "ids",
-                        "in": "query",
+                        "description": "Stellar account (G...)",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "XLM or CODE-ISSUER",
+                        "name": "asset",
+                        "in": "path",
                         "required": true
                     },
                     {
@@ -90,36 +94,76 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_api.IndicatorHistoryResponse"
+                            "$ref": "#/definitions/internal_api.BalanceHistoryResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/indicators": {
+        "/api/v1/accounts/{address}/operations": {
             "get": {
-                "description": "Returns indicators from the most recent stored snapshot. Optional ` + "`" + `compare` + "`" + ` adds period-over-period changes.",
+                "description": "Recent operations of a fund account from Horizon, joined with their transaction memos. Transfers carry ` + "`" + `direction` + "`" + ` (in/out) and ` + "`" + `counterparty` + "`" + `; offers carry ` + "`" + `selling` + "`" + `, ` + "`" + `buying` + "`" + ` and ` + "`" + `price` + "`" + `. ` + "`" + `asset` + "`" + ` matches the transferred, sold or bought asset. Pass ` + "`" + `next` + "`" + ` back as ` + "`" + `cursor` + "`" + ` for the following page; an empty ` + "`" + `next` + "`" + ` means the history is exhausted. A filtered page may hold fewer than ` + "`" + `limit` + "`" + ` records while ` + "`" + `next` + "`" + ` is still set. Horizon pages are cached briefly, so the newest operations can lag by up to a minute.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "indicators"
+                    "accounts"
                 ],
-                "summary": "Latest indicators",
+                "summary": "Fund account operations",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comma-separated periods: any of 30d,90d,180d,365d, or 'all'",
-                        "name": "compare",
+                        "description": "Fund account (G...)",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Paging token to continue after",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 1-200 (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default: desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "XLM or CODE-ISSUER",
+                        "name": "asset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "in or out (transfers only)",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payment, trade, trustline, liquidity, data, account or other",
+                        "name": "category",
                         "in": "query"
                     }
                 ],
@@ -127,103 +171,176 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/internal_api.IndicatorWithChanges"
-                            }
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_explorer.Page"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/indicators/{date}": {
-            "get": {
-                "description": "Returns the most recent value per indicator as of the given date (same semantics as GET /api/v1/indicators but bounded by date). Optional ` + "`" + `compare` + "`" + ` adds period-over-period changes anchored to that date.",
+        "/api/v1/admin/annotations": {
+            "post": {
+                "description": "Attaches a note to a snapshot date. The text is at most 500 characters; tags are lowercase letters, digits and dashes (at most 10). Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "indicators"
+                    "admin"
                 ],
-                "summary": "Indicators by date",
+                "summary": "Annotate a snapshot date",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Snapshot date (YYYY-MM-DD)",
-                        "name": "date",
-                        "in": "path",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Comma-separated periods: any of 30d,90d,180d,365d, or 'all'",
-                        "name": "compare",
-                        "in": "query"
+                        "description": "Annotation",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.AnnotationBody"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/internal_api.IndicatorWithChanges"
-                            }
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_annotation.Annotation"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/annotations/{id}": {
+            "delete": {
+                "description": "Removes one annotation. Only mounted when ADMIN_TOKEN is set.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete an annotation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Annotation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/snapshots": {
+        "/api/v1/admin/audit": {
             "get": {
-                "description": "Returns recent fund snapshots, newest first.",
+                "description": "Changes made through the admin API, newest first, each with the resource before and after (absent when it didn't exist or was deleted) and the caller's address. Only mounted when ADMIN_TOKEN is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "snapshots"
+                    "admin"
                 ],
-                "summary": "List snapshots",
+                "summary": "Admin audit log",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "entity",
+                            "account",
+                            "index",
+                            "property"
+                        ],
+                        "type": "string",
+                        "description": "Only this resource type",
+                        "name": "resource",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Maximum number of snapshots (default 30, max 365)",
+                        "description": "Maximum entries (default 100, max 1000)",
                         "name": "limit",
                         "in": "query"
                     }
@@ -234,57 +351,133 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Snapshot"
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.AuditEntry"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/snapshots/latest": {
+        "/api/v1/admin/diagnostics": {
             "get": {
-                "description": "Returns the most recent fund snapshot.",
+                "description": "Goroutine count, heap and GC statistics, cache sizes, in-flight Horizon requests and per-host counters of the shared upstream HTTP transport of the running server. Pipeline caches and Horizon requests are only reported when on-demand generation is enabled. Only mounted when ADMIN_TOKEN is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "snapshots"
+                    "admin"
+                ],
+                "summary": "Runtime diagnostics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
                 ],
-                "summary": "Latest snapshot",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Snapshot"
+                            "$ref": "#/definitions/internal_api.Diagnostics"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities": {
+            "get": {
+                "description": "All fund entities by slug, with their versions. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List fund entities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Entity"
                             }
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/snapshots/{date}": {
+        "/api/v1/admin/entities/{slug}": {
             "get": {
-                "description": "Returns the fund snapshot for an exact date.",
+                "description": "One fund entity. The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "snapshots"
+                    "admin"
                 ],
-                "summary": "Snapshot by date",
+                "summary": "Get a fund entity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Snapshot date (YYYY-MM-DD)",
-                        "name": "date",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     }
@@ -293,85 +486,5049 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Snapshot"
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Entity"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "github_com_mtlprog_stat_internal_snapshot.Snapshot": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+            },
+            "put": {
+                "description": "Creates the entity (201) or replaces its name and description (200). The slug is lowercase letters, digits and dashes. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or update a fund entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Entity",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.EntityBody"
+                        }
                     }
-                },
-                "entityId": {
-                    "type": "integer"
-                },
-                "id": {
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Entity"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Entity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities/{slug}/accounts": {
+            "get": {
+                "description": "The declared configuration of each checked fund account of the entity, by address. Null fields are not checked. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List account expectations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Account"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities/{slug}/accounts/{address}": {
+            "get": {
+                "description": "The declared configuration of one fund account. The ETag header carries the version for If-Match on PUT and DELETE. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an account expectation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stellar account address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Account"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Declares the configuration the account is checked against; omitted or null fields are not checked, and an empty homeDomain or inflationDestination means it must be unset. The body's account, when given, must match the path. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or replace an account expectation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stellar account address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Expected configuration",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_accountguard.Expected"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Account"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stops checking the account's configuration. With If-Match the delete only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete an account expectation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stellar account address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities/{slug}/pricing": {
+            "get": {
+                "description": "The assets of the entity whose spot price source is pinned, by asset. Assets without a policy are priced \"best\": path finding and the orderbook are both queried and the higher price wins. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List pricing policies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.PricingPolicy"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities/{slug}/pricing/{asset}": {
+            "get": {
+                "description": "The spot price source pinned for one asset. The ETag header carries the version for If-Match on PUT and DELETE. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a pricing policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "native or CODE:ISSUER",
+                        "name": "asset",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.PricingPolicy"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "From the next report run the asset's spot price comes only from source: \"path\" (Horizon path finding), \"orderbook\" (the orderbook and AMM pools) or \"best\" (the higher of both, the default). The body's asset, when given, must match the path. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set a pricing policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "native or CODE:ISSUER",
+                        "name": "asset",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pricing policy",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.PricingPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.PricingPolicy"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.PricingPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "From the next report run the asset is priced \"best\" again. With If-Match the delete only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a pricing policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "native or CODE:ISSUER",
+                        "name": "asset",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities/{slug}/properties": {
+            "get": {
+                "description": "The real-estate tokens of the entity valued by area and appraisal instead of _COST DATA entries, by token. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List registered properties",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Property"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/entities/{slug}/properties/{token}": {
+            "get": {
+                "description": "The registry entry of one real-estate token. The ETag header carries the version for If-Match on PUT and DELETE. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a registered property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "MCITY136",
+                        "description": "Asset code",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Property"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "From the next snapshot the token is valued at areaM2 × the external quote priceSymbol (EUR per m², set with ` + "`" + `stat quote set` + "`" + `), or at appraisalValue when priceSymbol is empty, instead of its _COST / _1COST DATA entries. A held token whose appraisedOn is missing or older than PROPERTY_APPRAISAL_MAX_AGE draws a snapshot warning. The body's token, when given, must match the path. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Register or replace a property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "MCITY136",
+                        "description": "Asset code",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Property data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.PropertyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Property"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_admin.Property"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "From the next snapshot the token is valued by its DATA entries again. With If-Match the delete only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a registered property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "mtlf",
+                        "description": "Entity slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "MCITY136",
+                        "description": "Asset code",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/index": {
+            "get": {
+                "description": "Base date and component weights of the Montelibero Index (I66). Components are I1, I3, I11 and I62. The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Montelibero Index definition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.IndexConfigBody"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the base date and component weights of the Montelibero Index (I66). Weights must be non-negative, not all zero, and only for I1, I3, I11 and I62; they need not sum to 1. Takes effect from the next calculation; stored I66 history is recomputed by ` + "`" + `stat backfill-index` + "`" + `. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace the Montelibero Index definition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Index definition",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.IndexConfigBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.IndexConfigBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/keys": {
+            "get": {
+                "description": "All issued partner API keys, newest first, revoked ones included, with their all-time request count. Tokens are never returned. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_apikey.Key"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Issues a partner API key that reads one entity's data through the listed route groups (snapshots, indicators, charts, analytics, reports, accounts, forecast, issuance, holders, alerts, valuations, status, jobs, watchlist, groups, subfonds, intraday, monitoring, compat). The token is returned only in this response; send it as X-API-Key. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Issue an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Key scope",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_apikey.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_apikey.Issued"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/keys/{id}": {
+            "delete": {
+                "description": "Revokes the key immediately. Its row and usage history are kept. Only mounted when ADMIN_TOKEN is set.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/keys/{id}/usage": {
+            "get": {
+                "description": "Requests of one key per day and route group, oldest first. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "API key usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "30d, 90d (default), 180d, 365d or all",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_apikey.Usage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/main-indicators": {
+            "get": {
+                "description": "The indicators listed on the IND_MAIN sheet. The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "IND_MAIN indicator set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.MainIndicatorsBody"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the indicators listed on the IND_MAIN sheet from the next export. IDs must be registered indicators, each at most once. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace the IND_MAIN indicator set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "IND_MAIN indicators",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.MainIndicatorsBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.MainIndicatorsBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/regulatory-price": {
+            "get": {
+                "description": "The Regulatory Price written to each new MONITORING row (4 until one is set). The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Regulatory price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.RegulatoryPriceBody"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Sets the Regulatory Price of the MONITORING rows exported from now on; rows already in the sheet keep theirs. The price must be positive. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the regulatory price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ADMIN_TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ETag from GET, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Regulatory price",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.RegulatoryPriceBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.RegulatoryPriceBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/alerts/whales": {
+            "get": {
+                "description": "Transfers into (` + "`" + `in` + "`" + `) or out of (` + "`" + `out` + "`" + `) a fund account worth at least the configured EURMTL threshold at the latest snapshot's prices, oldest first. Transfers between fund accounts and unpriced assets never alert. ` + "`" + `notified` + "`" + ` is false when the notification could not be sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Whale alerts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_whale.Alert"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/analytics/correlations": {
+            "get": {
+                "description": "Pairwise Pearson correlations of daily returns between major fund holdings, from stored snapshot prices (MTL from I10 history). Prices are in EURMTL, so EURMTL itself is flat and its correlations are null; a pair is also null with fewer than two overlapping returns.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Asset return correlations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated windows: any of 30d,90d,180d,365d, or 'all' (default 90d)",
+                        "name": "windows",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.Matrix"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/analytics/peers": {
+            "get": {
+                "description": "Compares the fund with the external treasuries configured in PEER_ACCOUNTS, as captured in a stored snapshot: assets value in EURMTL, token count and holder count, plus value and holder ratios against the fund. Fund holders are I62; a peer's holders are the authorized trustlines of its most widely held issued asset. Peers are empty for snapshots taken without PEER_ACCOUNTS.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Peer treasury comparison",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD, default latest)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.PeerComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/analytics/returns": {
+            "get": {
+                "description": "Splits the total return of one share held through a month (YYYY-MM) or quarter (YYYY-QN) into price change and dividends received, and the price change into capital gain (market capitalisation I5 × I10 over the opening shares) and dilution from shares issued during the period. Components are percentages of the opening share price (I10 at the previous period close); dividends are I15 summed over the month ends. Computed from stored indicators on request, also for the current, unfinished period.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Shareholder return decomposition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period: YYYY-MM or YYYY-QN",
+                        "name": "period",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ReturnsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/charts/balance-by-subfund": {
+            "get": {
+                "description": "Returns the EURMTL value of the 4 sub-fund accounts (MABIZ, MCITY, DEFI, BOSS) plus MAIN ISSUER and ADMIN for a given date.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "charts"
+                ],
+                "summary": "Fund balance split by sub-fund",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD); defaults to latest",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.BalanceBySubfundResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/charts/indicator-history": {
+            "get": {
+                "description": "Returns historical points for one or more indicator IDs over the requested range. By default the resolution follows the range: daily up to 180d, weekly up to two years, monthly beyond ('all'). Weekly and monthly points are dated by the last day of the period with a value, carry that value, and add periodStart, min, max and avg.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "charts"
+                ],
+                "summary": "Indicator time-series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated indicator IDs (e.g. 1,3,17,24,27)",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day, week, month, or auto (default)",
+                        "name": "resolution",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.IndicatorHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/errors": {
+            "get": {
+                "description": "Lists the codes of error responses with their HTTP status and titles per language. Errors are RFC 7807 application/problem+json objects: type (/api/v1/errors#CODE), title (in the Accept-Language language: en or ru), status, detail, code, and error (the detail again, for older clients). Branch on code, not on detail.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "errors"
+                ],
+                "summary": "Error codes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_api.ErrorCode"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/forecast/dividends": {
+            "get": {
+                "description": "Forecasts next month's dividends (I11) from the stored dividend ledger, one value per calendar month, and the annual dividend yield they imply at the latest share price (I10) and share count (I5). ` + "`" + `moving-average` + "`" + ` predicts the mean of the last ` + "`" + `window` + "`" + ` months; ` + "`" + `seasonal-naive` + "`" + ` predicts the same month a year earlier. Lower and upper bounds cover the requested confidence, assuming normal errors; the lower bound is never negative.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "forecast"
+                ],
+                "summary": "Dividend forecast",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "moving-average (default) or seasonal-naive",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moving-average months, 2-36 (default 6)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "0.8, 0.9 (default) or 0.95",
+                        "name": "confidence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.DividendForecast"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Same as the GET form, with the parameters in a JSON body.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "forecast"
+                ],
+                "summary": "Dividend forecast",
+                "parameters": [
+                    {
+                        "description": "Forecast parameters",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.ForecastRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.DividendForecast"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/{name}": {
+            "get": {
+                "description": "Returns an account group configured in ACCOUNT_GROUPS (e.g. a real estate cluster of MCITY and APART) as captured in a stored snapshot: the members' values, the group's EURMTL and XLM totals, its token count and its share of the fund total. Members may be mutual or other accounts, which the fund total leaves out, so shares of overlapping groups need not add up. Members missing from the snapshot count as zero and are listed under missing. Snapshots taken before the group was configured don't have it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Account group rollup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD, default latest)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.GroupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/holders/churn": {
+            "get": {
+                "description": "New and exited MTL and MTLAP holders over a period: the latest stored holder set compared with the latest set at-or-before ` + "`" + `period` + "`" + ` days earlier. MTL holders are accounts with any positive MTL + MTLRECT balance, MTLAP holders those with at least 1 MTLAP. An asset is left out until a set that far back is stored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holders"
+                ],
+                "summary": "Holder churn",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period: 30d (default), 90d, 180d or 365d",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_holders.Churn"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/indicators": {
+            "get": {
+                "description": "Returns indicators from the most recent stored snapshot. Optional ` + "`" + `compare` + "`" + ` adds period-over-period changes. API v2 (/api/v2/ or Accept: application/vnd.mtlstat.v2+json) returns an IndicatorSet instead, adding the snapshot date and the annotations on the dates compared across.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "Latest indicators",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated periods: any of 30d,90d,180d,365d, or 'all'",
+                        "name": "compare",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rolling",
+                            "calendar"
+                        ],
+                        "type": "string",
+                        "description": "What changes compare with: rolling (N days earlier) or calendar (the previous month-, quarter-, half-year- or year-end). Default: the server's COMPARISON_MODE",
+                        "name": "baseline",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_api.IndicatorWithChanges"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/indicators/calculators": {
+            "get": {
+                "description": "Lists registered indicator calculators in initialization order with the indicator IDs each produces, the IDs it depends on, and the calculators providing them. Calculators switched off via INDICATOR_DISABLED_CALCULATORS (or depending on one that is) have enabled=false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "Indicator calculators",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_indicator.CalculatorInfo"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/indicators/meta": {
+            "get": {
+                "description": "Every registered indicator with its name, unit, description, display precision, estimation method (I47 only), the definition version in effect today and the changelog of its formula (version, effectiveDate, summary). Version 1 is the original definition. Comparisons in GET /api/v1/indicators that span an effectiveDate list the change in definitionChanges.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "Indicator metadata and definition history",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_api.IndicatorMetaResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/indicators/{date}": {
+            "get": {
+                "description": "Returns the most recent value per indicator as of the given date (same semantics as GET /api/v1/indicators but bounded by date). Optional ` + "`" + `compare` + "`" + ` adds period-over-period changes anchored to that date. API v2 returns an IndicatorSet, as GET /api/v1/indicators does.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "Indicators by date",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated periods: any of 30d,90d,180d,365d, or 'all'",
+                        "name": "compare",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rolling",
+                            "calendar"
+                        ],
+                        "type": "string",
+                        "description": "What changes compare with: rolling (N days earlier) or calendar (the previous month-, quarter-, half-year- or year-end). Default: the server's COMPARISON_MODE",
+                        "name": "baseline",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_api.IndicatorWithChanges"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/intraday/{id}": {
+            "get": {
+                "description": "Samples of a key indicator taken every INTRADAY_INTERVAL (typically hourly) by a light sampler outside the daily snapshot pipeline, oldest first. Only I4 (operating balance) and I10 (share market price) are sampled. The samples are kept for INTRADAY_RETENTION and never appear in the daily indicator series.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "Intraday indicator samples",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Indicator ID (4 or 10)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window in hours, 1–2160 (default: 48)",
+                        "name": "hours",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.IntradayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/issuance/conversions": {
+            "get": {
+                "description": "MTLRECT returned to the issuer and matched with the MTL issued to the same account within 7 days after it (usually in the same transaction), oldest first by the MTL issuance. ` + "`" + `amount` + "`" + ` is the MTLRECT returned, ` + "`" + `received` + "`" + ` the MTL issued. The totals are indicators I73 (cumulative) and I74 (last 30 days).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "issuance"
+                ],
+                "summary": "MTLRECT to MTL conversions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_conversion.Conversion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/issuance/events": {
+            "get": {
+                "description": "MTL and MTLRECT total supply changes between consecutive snapshots, oldest first. Each event lists the issuer operations since the previous snapshot: payments from the issuer (issuance, ` + "`" + `account` + "`" + ` is the recipient), and payments back to it or clawbacks (buyback, ` + "`" + `account` + "`" + ` is the sender). ` + "`" + `unattributed` + "`" + ` is the part of the net change those operations don't explain. Snapshots taken before supply tracking have no events.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "issuance"
+                ],
+                "summary": "Share issuance and buyback events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_issuance.Event"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/issuance/supply/{asset}": {
+            "get": {
+                "description": "Total supply of one asset issued by a fund account on each snapshot date in the range, oldest first. ` + "`" + `delta` + "`" + ` is the change since the previous snapshot, absent when there was none to compare with. Changes of codes outside SUPPLY_EXPECTED_CHANGES are unexpected mints or burns: ` + "`" + `expected` + "`" + ` is false and ` + "`" + `notified` + "`" + ` tells whether the alert was delivered. Snapshots taken before supply tracking have no points.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "issuance"
+                ],
+                "summary": "Fund-issued asset supply history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CODE-ISSUER",
+                        "name": "asset",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.SupplyHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/jobs/{id}": {
+            "get": {
+                "description": "Returns status, latest progress event, and (once succeeded) the result of a background job.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Job status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/monitoring": {
+            "get": {
+                "description": "The row the daily export appends to the MONITORING sheet for a date, built from the stored indicators: every column in sheet order with its header, indicator ID and value. ` + "`" + `source` + "`" + ` tells where each cell comes from: indicator, missing (mapped indicator without a value that day), fixed, empty (placeholder slot) or note (issuance and annotation text). ` + "`" + `format=csv` + "`" + ` returns a header row and the data row with exact decimals; ` + "`" + `format=xlsx` + "`" + ` returns a workbook in the sheet's layout that ` + "`" + `stat import-excel` + "`" + ` reads back.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "MONITORING row",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD, default latest)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default), csv or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_export.MonitoringReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reports/{period}": {
+            "get": {
+                "description": "Returns the stored month-end (YYYY-MM) or quarter-end (YYYY-QN) report: closing indicator values with changes since the previous period close, the top movers by relative change, and dividend totals (I11 at each month end, I18 recipients at period end). Reports are generated by ` + "`" + `stat report` + "`" + ` on the last day of each configured period, or by ` + "`" + `stat period-report` + "`" + `. With format=markdown the rendered Markdown document is returned instead.",
+                "produces": [
+                    "application/json",
+                    "text/markdown"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Period report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period: YYYY-MM or YYYY-QN",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or markdown",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshots": {
+            "get": {
+                "description": "Returns fund snapshots, newest first by default. Pagination metadata is returned in headers: X-Total-Count (matches for from/to) and X-Next-Cursor (absent on the last page).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "List snapshots",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 30, max 365)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest snapshot date, inclusive (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest snapshot date, inclusive (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Next-Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return only id, date, createdAt and size",
+                        "name": "omit_data",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Snapshot"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Snapshots matching from/to"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshots/annotations": {
+            "get": {
+                "description": "Operator notes attached to snapshot dates (a tranche received, a valuation methodology change) explaining anomalous data points, oldest first. API v2 also returns them with GET /api/v1/indicators.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Snapshot annotations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_mtlprog_stat_internal_annotation.Annotation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshots/generate": {
+            "post": {
+                "description": "Queues the full report pipeline for today's snapshot date, or for a past ` + "`" + `date` + "`" + `, and returns immediately. A past date is generated as of the end of that day from ledger history and stored quotes, to patch a missing day; an existing snapshot of the date is replaced. Poll /api/v1/jobs/{id} for progress and result. If a generation for the date is already queued or running, that job is returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Generate a snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Past snapshot date (YYYY-MM-DD, default today)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshots/latest": {
+            "get": {
+                "description": "Returns the most recent fund snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Latest snapshot",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Snapshot"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshots/{date}": {
+            "get": {
+                "description": "Returns the fund snapshot for an exact date.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Snapshot by date",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Snapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshots/{date}/details": {
+            "get": {
+                "description": "Returns how each token of a snapshot was priced (domain.PriceDetails: path hops, orderbook levels, AMM pools), per account and base (EURMTL or XLM). With SNAPSHOT_DETACH_DETAILS the snapshot document only keeps a stub (` + "`" + `source` + "`" + `, ` + "`" + `detached: true` + "`" + `) and the details are read from their own table; older snapshots have them inline and are served the same way.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Price details of a snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "XLM or CODE-ISSUER; all tokens when empty",
+                        "name": "asset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.SnapshotDetailsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/status": {
+            "get": {
+                "description": "Returns the data-quality score of a stored snapshot: percentage of held tokens priced in EURMTL, external quotes older than a day, and live metrics that reused the prior day's value (by indicator ID in snapshot live_metrics.fallbacks) and how far Horizon's latest ledger lagged (degraded when beyond HORIZON_MAX_LAG), plus the pricing warnings. With the Google Sheets export configured, unexportedSnapshots lists the snapshots whose export is still owed, with their attempts and last error. coingeckoBudget reports today's CoinGecko requests (retries included, counted by every stat process) against COINGECKO_DAILY_BUDGET. slo reports the share of daily report runs over the last 30 days that succeeded within SLO_DEADLINE, the current streak of misses and the latest run's duration per stage (valuation, portfolio, pricing, metrics, persistence, export). Snapshots taken before scores were stored are assessed on the fly as of their creation time; metric fallbacks were not recorded for them and read 0.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Snapshot data quality",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD, default latest)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subfonds/{name}/roi": {
+            "get": {
+                "description": "Return of one subfond (DEFI, MCITY, MABIZ or BOSS) by the Modified Dietz method: the change of its total value (I51, I52, I53, I59) from the end of ` + "`" + `from` + "`" + ` to the end of ` + "`" + `to` + "`" + `, net of the transfers between it and the other fund accounts (issuer, subfonds, ADMIN), over the opening value plus each transfer weighted by the part of the period it was there for. Transfers with outside accounts are the subfond's own business and stay in the return. ` + "`" + `period` + "`" + ` is a window ending today (30d, 90d, 180d, 365d) or a month (YYYY-MM) or quarter (YYYY-QN), measured from the close of the previous one. ` + "`" + `roi` + "`" + ` is null when the average capital isn't positive. The same figures over 30 days are I83–I86.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subfonds"
+                ],
+                "summary": "Subfond ROI",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subfond: DEFI, MCITY, MABIZ or BOSS",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period: 30d (default), 90d, 180d, 365d, YYYY-MM or YYYY-QN",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_subfond.ROI"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/valuations/explain": {
+            "get": {
+                "description": "Lists the manual valuations (Stellar DATA entries) that priced tokens in a stored snapshot, with the external quote each was resolved with: symbol, EUR price and the time the quote was fetched. Values stay explainable after quotes are refreshed. Snapshots taken before valuations were recorded return empty lists.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "valuations"
+                ],
+                "summary": "Valuation audit trail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD, default latest)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this asset code",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.ValuationExplanation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/watchlist": {
+            "get": {
+                "description": "Returns the balances of the watched accounts (partners and counterparties outside the fund registry, managed with ` + "`" + `stat watchlist` + "`" + `) as captured in a stored snapshot. Each tracked asset is valued in EURMTL at the snapshot's prices; watched balances are not part of the fund totals. An account that could not be loaded carries an error instead of balances.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshots"
+                ],
+                "summary": "Watchlist balances",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Snapshot date (YYYY-MM-DD, default latest)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.WatchlistResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/feed.atom": {
+            "get": {
+                "description": "Atom feed of the last 30 daily reports, newest first, for feed readers. Each entry is one snapshot date with the key indicators (I3 assets, I8 book value, I10 market price, I6 MTL in circulation, I11 monthly dividends, I27 shareholders), their percent change since the previous report and the operator annotations of that date. An entry's updated time moves when an annotation is added to it.",
+                "produces": [
+                    "application/atom+xml"
+                ],
+                "tags": [
+                    "indicators"
+                ],
+                "summary": "Daily statistics feed",
+                "responses": {
+                    "200": {
+                        "description": "Atom feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_mtlprog_stat_internal_accountguard.Expected": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "authClawbackEnabled": {
+                    "type": "boolean"
+                },
+                "authImmutable": {
+                    "type": "boolean"
+                },
+                "authRequired": {
+                    "type": "boolean"
+                },
+                "authRevocable": {
+                    "type": "boolean"
+                },
+                "homeDomain": {
+                    "type": "string"
+                },
+                "inflationDestination": {
+                    "type": "string"
+                },
+                "numSponsored": {
+                    "type": "integer"
+                },
+                "numSponsoring": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_accountmeta.Event": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "snapshot that saw the new value",
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_accountmeta.Kind"
+                },
+                "name": {
+                    "description": "registry name of the account",
+                    "type": "string"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                },
+                "prevDate": {
+                    "description": "snapshot it was compared with",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_accountmeta.Kind": {
+            "type": "string",
+            "enum": [
+                "added",
+                "changed",
+                "removed"
+            ],
+            "x-enum-varnames": [
+                "KindAdded",
+                "KindChanged",
+                "KindRemoved"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_admin.Account": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "authClawbackEnabled": {
+                    "type": "boolean"
+                },
+                "authImmutable": {
+                    "type": "boolean"
+                },
+                "authRequired": {
+                    "type": "boolean"
+                },
+                "authRevocable": {
+                    "type": "boolean"
+                },
+                "homeDomain": {
+                    "type": "string"
+                },
+                "inflationDestination": {
+                    "type": "string"
+                },
+                "numSponsored": {
+                    "type": "integer"
+                },
+                "numSponsoring": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_admin.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, update or delete",
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "remote": {
+                    "type": "string"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_admin.Entity": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_admin.PricingPolicy": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "type": "string",
+                    "example": "MTL:GACKTN5DAZGWXRWB2WLM6OPBDHAMT6SJNGLJZPQMEZBUR4JUGBX2UK7V"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "orderbook"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_admin.Property": {
+            "type": "object",
+            "properties": {
+                "appraisalValue": {
+                    "description": "last appraisal of the whole property, in EUR",
+                    "type": "number"
+                },
+                "appraisedOn": {
+                    "type": "string",
+                    "example": "2026-03-01"
+                },
+                "areaM2": {
+                    "type": "number"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priceSymbol": {
+                    "description": "external quote in EUR per m²; empty values at AppraisalValue",
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.DividendForecast": {
+            "type": "object",
+            "properties": {
+                "annualYield": {
+                    "description": "AnnualYield is MonthlyDividends × 12 per share (I5) over the share\nprice (I10), in percent. Absent without stored I5 and I10.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.Estimate"
+                        }
+                    ]
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "history": {
+                    "description": "months the forecast was fitted on",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.MonthlyValue"
+                    }
+                },
+                "method": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.ForecastMethod"
+                },
+                "month": {
+                    "description": "forecast month, YYYY-MM",
+                    "type": "string"
+                },
+                "monthlyDividends": {
+                    "description": "MonthlyDividends forecasts I11 for Month, in EURMTL.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.Estimate"
+                        }
+                    ]
+                },
+                "window": {
+                    "description": "moving-average only",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.Estimate": {
+            "type": "object",
+            "properties": {
+                "lower": {
+                    "type": "number"
+                },
+                "upper": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.ForecastMethod": {
+            "type": "string",
+            "enum": [
+                "moving-average",
+                "seasonal-naive"
+            ],
+            "x-enum-varnames": [
+                "ForecastMovingAverage",
+                "ForecastSeasonalNaive"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_analytics.Matrix": {
+            "type": "object",
+            "properties": {
+                "assets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "correlations": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "from": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "numeraire": {
+                    "type": "string"
+                },
+                "observations": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "to": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "windowDays": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.MonthlyValue": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "YYYY-MM",
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.PeerComparison": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "fund": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.PeerRow"
+                },
+                "peers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.PeerRow"
+                    }
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.PeerRow": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "holderCount": {
+                    "type": "integer"
+                },
+                "holdersVsFund": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tokenCount": {
+                    "type": "integer"
+                },
+                "totalEURMTL": {
+                    "type": "number"
+                },
+                "valueVsFund": {
+                    "description": "TotalEURMTL / fund TotalEURMTL",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.ValuationExplanation": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "quotes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.QuoteUsage"
+                    }
+                },
+                "valuations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_analytics.ValuationRow"
+                    }
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_analytics.ValuationRow": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "asset": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.AssetInfo"
+                },
+                "balance": {
+                    "type": "string"
+                },
+                "priceInEURMTL": {
+                    "type": "string"
+                },
+                "quote": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.QuoteUsage"
+                },
+                "rawValue": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.ValuationValue"
+                },
+                "sourceAccount": {
+                    "type": "string"
+                },
+                "valuationType": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.ValuationType"
+                },
+                "valueInEURMTL": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_annotation.Annotation": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2026-10-01"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_apikey.Issued": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "entity": {
+                    "description": "fund_entities slug",
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the token, for identification",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "all-time total",
+                    "type": "integer"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_apikey.Key": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "entity": {
+                    "description": "fund_entities slug",
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the token, for identification",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "all-time total",
+                    "type": "integer"
+                },
+                "revokedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_apikey.Request": {
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_apikey.Usage": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_conversion.Conversion": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "MTLRECT returned",
+                    "type": "number"
+                },
+                "at": {
+                    "description": "when the MTL was issued, completing the conversion",
+                    "type": "string"
+                },
+                "issueTx": {
+                    "description": "equal to ReturnTx for a single-transaction conversion",
+                    "type": "string"
+                },
+                "received": {
+                    "description": "MTL issued",
+                    "type": "number"
+                },
+                "returnTx": {
+                    "type": "string"
+                },
+                "returnedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_definition.Change": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "effectiveDate": {
+                    "type": "string",
+                    "example": "2026-05-01"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "indicatorId": {
+                    "type": "integer"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.AccountGroup": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.AccountGroupMember"
+                    }
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "shareOfFund": {
+                    "description": "ShareOfFund is TotalEURMTL as a percentage of\nAggregatedTotals.TotalEURMTL; nil when the fund total is zero.",
+                    "type": "number"
+                },
+                "tokenCount": {
+                    "type": "integer"
+                },
+                "totalEURMTL": {
+                    "type": "number"
+                },
+                "totalXLM": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.AccountGroupMember": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "totalEURMTL": {
+                    "type": "number"
+                },
+                "totalXLM": {
+                    "type": "number"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.AccountType"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.AccountType": {
+            "type": "string",
+            "enum": [
+                "issuer",
+                "subfond",
+                "mutual",
+                "operational",
+                "other",
+                "peer",
+                "endowment"
+            ],
+            "x-enum-varnames": [
+                "AccountTypeIssuer",
+                "AccountTypeSubfond",
+                "AccountTypeMutual",
+                "AccountTypeOperational",
+                "AccountTypeOther",
+                "AccountTypePeer",
+                "AccountTypeEndowment"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_domain.AssetInfo": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.AssetType"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.AssetType": {
+            "type": "string",
+            "enum": [
+                "native",
+                "credit_alphanum4",
+                "credit_alphanum12"
+            ],
+            "x-enum-varnames": [
+                "AssetTypeNative",
+                "AssetTypeCreditAlphanum4",
+                "AssetTypeCreditAlphanum12"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_domain.DataQuality": {
+            "type": "object",
+            "properties": {
+                "degraded": {
+                    "description": "Ledger.Stale: generated from a lagging Horizon",
+                    "type": "boolean"
+                },
+                "horizonLagSeconds": {
+                    "description": "Ledger.LagSeconds; absent before lag was recorded",
+                    "type": "integer"
+                },
+                "metricFallbacks": {
+                    "description": "len(LiveMetrics.Fallbacks)",
+                    "type": "integer"
+                },
+                "pricedCount": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "staleQuotes": {
+                    "description": "external quotes older than quality.StaleQuoteAge",
+                    "type": "integer"
+                },
+                "tokenCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.QuoteUsage": {
+            "type": "object",
+            "properties": {
+                "fetchedAt": {
+                    "type": "string"
+                },
+                "priceInEur": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.ValuationType": {
+            "type": "string",
+            "enum": [
+                "nft",
+                "unit"
+            ],
+            "x-enum-comments": {
+                "ValuationTypeNFT": "_COST: total price for entire holding",
+                "ValuationTypeUnit": "_1COST: price per unit"
+            },
+            "x-enum-descriptions": [
+                "_COST: total price for entire holding",
+                "_1COST: price per unit"
+            ],
+            "x-enum-varnames": [
+                "ValuationTypeNFT",
+                "ValuationTypeUnit"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_domain.ValuationValue": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "description": "For compound external values (e.g., AU 1g)",
+                    "type": "number"
+                },
+                "symbol": {
+                    "description": "For external type: BTC, ETH, XLM, Sats, USD, AU",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.ValuationValueType"
+                },
+                "unit": {
+                    "description": "g, oz; m2 for property registry valuations",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "For eurmtl type",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.ValuationValueType": {
+            "type": "string",
+            "enum": [
+                "eurmtl",
+                "external"
+            ],
+            "x-enum-varnames": [
+                "ValuationValueEURMTL",
+                "ValuationValueExternal"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_domain.WatchedAccount": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.WatchedBalance"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "totalEURMTL": {
+                    "description": "sum of the priced balances",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_domain.WatchedBalance": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.AssetInfo"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "valueEURMTL": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_explorer.Category": {
+            "type": "string",
+            "enum": [
+                "payment",
+                "trade",
+                "trustline",
+                "liquidity",
+                "data",
+                "account",
+                "other"
+            ],
+            "x-enum-varnames": [
+                "CategoryPayment",
+                "CategoryTrade",
+                "CategoryTrustline",
+                "CategoryLiquidity",
+                "CategoryData",
+                "CategoryAccount",
+                "CategoryOther"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_explorer.Direction": {
+            "type": "string",
+            "enum": [
+                "in",
+                "out"
+            ],
+            "x-enum-varnames": [
+                "In",
+                "Out"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_explorer.Operation": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "asset": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "buying": {
+                    "type": "string"
+                },
+                "category": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_explorer.Category"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "dataName": {
+                    "type": "string"
+                },
+                "direction": {
+                    "description": "transfers only",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_explorer.Direction"
+                        }
+                    ]
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "paging token, usable as a cursor",
+                    "type": "string"
+                },
+                "memo": {
+                    "type": "string"
+                },
+                "memoType": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "selling": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_explorer.Page": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_explorer.Operation"
+                    }
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_export.CellSource": {
+            "type": "string",
+            "enum": [
+                "indicator",
+                "missing",
+                "fixed",
+                "empty",
+                "note"
+            ],
+            "x-enum-comments": {
+                "CellEmpty": "placeholder slot without a value",
+                "CellFixed": "a configured parameter: Regulatory Price",
+                "CellIndicator": "the stored value of IndicatorID",
+                "CellMissing": "IndicatorID has no value on this date",
+                "CellNote": "free text: issuance or annotations"
+            },
+            "x-enum-descriptions": [
+                "the stored value of IndicatorID",
+                "IndicatorID has no value on this date",
+                "a configured parameter: Regulatory Price",
+                "placeholder slot without a value",
+                "free text: issuance or annotations"
+            ],
+            "x-enum-varnames": [
+                "CellIndicator",
+                "CellMissing",
+                "CellFixed",
+                "CellEmpty",
+                "CellNote"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_export.MonitoringCell": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "description": "1-based position after the date column",
+                    "type": "integer"
+                },
+                "header": {
+                    "type": "string"
+                },
+                "indicatorId": {
+                    "type": "integer"
+                },
+                "source": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_export.CellSource"
+                },
+                "text": {
+                    "description": "note cells only",
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_export.MonitoringReport": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_export.MonitoringCell"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_external.BudgetStatus": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "negative once over the limit",
+                    "type": "integer"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_holders.Churn": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "type": "string"
+                },
+                "exited": {
+                    "description": "holders on From that aren't on To",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "description": "date of the earlier set",
+                    "type": "string"
+                },
+                "holders": {
+                    "type": "integer"
+                },
+                "net": {
+                    "description": "Holders − PrevHolders",
+                    "type": "integer"
+                },
+                "new": {
+                    "description": "holders on To that weren't on From",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prevHolders": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_indicator.CalculatorInfo": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "dependsOn": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "disabledBy": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_indicator.Resolution": {
+            "type": "string",
+            "enum": [
+                "day",
+                "week",
+                "month"
+            ],
+            "x-enum-comments": {
+                "ResolutionDay": "fund_indicators rows as stored",
+                "ResolutionMonth": "calendar months",
+                "ResolutionWeek": "ISO weeks, starting Monday"
+            },
+            "x-enum-descriptions": [
+                "fund_indicators rows as stored",
+                "ISO weeks, starting Monday",
+                "calendar months"
+            ],
+            "x-enum-varnames": [
+                "ResolutionDay",
+                "ResolutionWeek",
+                "ResolutionMonth"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_intraday.Sample": {
+            "type": "object",
+            "properties": {
+                "time": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_issuance.Event": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "|supply − prevSupply|",
+                    "type": "number"
+                },
+                "asset": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "snapshot that saw the new supply",
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_issuance.Kind"
+                },
+                "prevDate": {
+                    "description": "snapshot it was compared with",
+                    "type": "string"
+                },
+                "prevSupply": {
+                    "type": "number"
+                },
+                "supply": {
+                    "type": "number"
+                },
+                "transfers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_issuance.Transfer"
+                    }
+                },
+                "unattributed": {
+                    "description": "Unattributed is the part of the net change the transfers don't\nexplain, e.g. claimable balances or operations outside the window.",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_issuance.Kind": {
+            "type": "string",
+            "enum": [
+                "issuance",
+                "buyback"
+            ],
+            "x-enum-varnames": [
+                "KindIssuance",
+                "KindBuyback"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_issuance.Transfer": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "description": "recipient of an issuance, sender of a buyback",
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_issuance.Kind"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_job.Job": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "entityId": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_progress.Event"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "snapshotDate": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_outbox.Task": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "exportedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "snapshotDate": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_period.Change": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "number"
+                },
+                "changePercent": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_period.Dividends": {
+            "type": "object",
+            "properties": {
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.MonthDividend"
+                    }
+                },
+                "recipients": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_period.Kind": {
+            "type": "string",
+            "enum": [
+                "month",
+                "quarter"
+            ],
+            "x-enum-varnames": [
+                "Month",
+                "Quarter"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_period.MonthDividend": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "month": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_period.Report": {
+            "type": "object",
+            "properties": {
+                "dividends": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Dividends"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "generatedAt": {
+                    "type": "string"
+                },
+                "indicators": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Change"
+                    }
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Kind"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "returns": {
+                    "description": "absent without I5 and I10 at both period ends",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Returns"
+                        }
+                    ]
+                },
+                "start": {
+                    "type": "string"
+                },
+                "subfonds": {
+                    "description": "per-subfond ROI, absent without subfond flows",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_subfond.ROI"
+                    }
+                },
+                "topMovers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Change"
+                    }
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_period.Returns": {
+            "type": "object",
+            "properties": {
+                "capitalGain": {
+                    "type": "number"
+                },
+                "closePrice": {
+                    "type": "number"
+                },
+                "closeShares": {
+                    "type": "number"
+                },
+                "dilution": {
+                    "type": "number"
+                },
+                "dividends": {
+                    "type": "number"
+                },
+                "dividendsPerShare": {
+                    "description": "Σ I15 at each month end, in EURMTL",
+                    "type": "number"
+                },
+                "openPrice": {
+                    "type": "number"
+                },
+                "openShares": {
+                    "type": "number"
+                },
+                "priceChange": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_progress.Event": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "accountIndex": {
+                    "type": "integer"
+                },
+                "accountTotal": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "tokenIndex": {
+                    "type": "integer"
+                },
+                "tokenTotal": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_slo.Attainment": {
+            "type": "object",
+            "properties": {
+                "consecutiveMisses": {
+                    "type": "integer"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "lastRun": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_slo.Record"
+                },
+                "met": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "description": "100 with no runs",
+                    "type": "number"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "windowDays": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_slo.Record": {
+            "type": "object",
+            "properties": {
+                "deadlineMs": {
+                    "type": "integer"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "met": {
+                    "description": "succeeded within the deadline",
+                    "type": "boolean"
+                },
+                "snapshotDate": {
+                    "type": "string"
+                },
+                "stages": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "succeeded": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_snapshot.BalancePoint": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_snapshot.Detail": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "asset": {
+                    "description": "AssetKey of the token",
+                    "type": "string"
+                },
+                "base": {
+                    "description": "\"EURMTL\" or \"XLM\"",
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_snapshot.Snapshot": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "entityId": {
+                    "type": "integer"
+                },
+                "hash": {
+                    "description": "hex SHA-256 of Canonicalize(data)",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "signature": {
+                    "description": "base64 ed25519 signature of SealMessage",
+                    "type": "string"
+                },
+                "signer": {
+                    "description": "G... address of the signing key",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "stored bytes (delta size for delta rows); set by ListPage only",
+                    "type": "integer"
+                },
+                "snapshotDate": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_subfond.Direction": {
+            "type": "string",
+            "enum": [
+                "in",
+                "out"
+            ],
+            "x-enum-varnames": [
+                "In",
+                "Out"
+            ]
+        },
+        "github_com_mtlprog_stat_internal_subfond.Flow": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "asset": {
+                    "description": "snapshot.AssetKey: XLM or CODE-ISSUER",
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "direction": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_subfond.Direction"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "subfond": {
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                },
+                "valueEURMTL": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_subfond.ROI": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "flows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_subfond.Flow"
+                    }
+                },
+                "from": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "gain": {
+                    "type": "number"
+                },
+                "inflows": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "outflows": {
+                    "type": "number"
+                },
+                "roi": {
+                    "type": "number"
+                },
+                "subfond": {
+                    "type": "string"
+                },
+                "to": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_supply.Point": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "delta": {
+                    "type": "number"
+                },
+                "expected": {
+                    "type": "boolean"
+                },
+                "notified": {
+                    "type": "boolean"
+                },
+                "supply": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_transport.HostStats": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "description": "round trips that returned no response",
+                    "type": "integer"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "inFlight": {
+                    "description": "waiting for response headers",
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_whale.Alert": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "accountName": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "asset": {
+                    "description": "snapshot.AssetKey: XLM or CODE-ISSUER",
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "direction": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_whale.Direction"
+                },
+                "memo": {
+                    "type": "string"
+                },
+                "notified": {
+                    "type": "boolean"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                },
+                "valueEURMTL": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_mtlprog_stat_internal_whale.Direction": {
+            "type": "string",
+            "enum": [
+                "in",
+                "out"
+            ],
+            "x-enum-varnames": [
+                "In",
+                "Out"
+            ]
+        },
+        "internal_api.AnnotationBody": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2026-10-01"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "defi"
+                    ]
+                },
+                "text": {
+                    "type": "string",
+                    "example": "DEFI received EUR 200k tranche"
+                }
+            }
+        },
+        "internal_api.BalanceBySubfundResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "slices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api.SubfundSlice"
+                    }
+                }
+            }
+        },
+        "internal_api.BalanceHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "asset": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.BalancePoint"
+                    }
+                }
+            }
+        },
+        "internal_api.Diagnostics": {
+            "type": "object",
+            "properties": {
+                "caches": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "goroutines": {
                     "type": "integer"
                 },
-                "snapshotDate": {
+                "heap": {
+                    "$ref": "#/definitions/internal_api.HeapStats"
+                },
+                "horizonInFlight": {
+                    "description": "absent without a generate pipeline",
+                    "type": "integer"
+                },
+                "upstream": {
+                    "description": "requests per external host",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_transport.HostStats"
+                    }
+                },
+                "uptime": {
                     "type": "string"
                 }
             }
         },
-        "internal_api.BalanceBySubfundResponse": {
+        "internal_api.EntityBody": {
             "type": "object",
             "properties": {
-                "date": {
-                    "description": "YYYY-MM-DD",
+                "description": {
+                    "type": "string",
+                    "example": "Montelibero Fund statistics"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Montelibero Fund"
+                }
+            }
+        },
+        "internal_api.ErrorCode": {
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "string"
                 },
-                "slices": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/internal_api.SubfundSlice"
+                "status": {
+                    "description": "usual status; unsupported_version is 406 when asked for in Accept",
+                    "type": "integer"
+                },
+                "titles": {
+                    "description": "language → title",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
                 }
             }
         },
+        "internal_api.ForecastRequest": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "method": {
+                    "type": "string",
+                    "enum": [
+                        "moving-average",
+                        "seasonal-naive"
+                    ]
+                },
+                "window": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api.GroupResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "group": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.AccountGroup"
+                }
+            }
+        },
+        "internal_api.HeapStats": {
+            "type": "object",
+            "properties": {
+                "allocBytes": {
+                    "type": "integer"
+                },
+                "gcPauseTotal": {
+                    "type": "string"
+                },
+                "inuseBytes": {
+                    "type": "integer"
+                },
+                "lastGC": {
+                    "type": "string"
+                },
+                "numGC": {
+                    "type": "integer"
+                },
+                "objects": {
+                    "type": "integer"
+                },
+                "sysBytes": {
+                    "type": "integer"
+                }
+            }
+        },
         "internal_api.HistoryPoint": {
             "type": "object",
             "properties": {
+                "avg": {
+                    "type": "number"
+                },
                 "date": {
                     "description": "YYYY-MM-DD",
                     "type": "string"
                 },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "periodStart": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
                 "value": {
                     "type": "number"
                 }
             }
         },
+        "internal_api.IndexConfigBody": {
+            "type": "object",
+            "properties": {
+                "baseDate": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
         "internal_api.IndicatorHistoryResponse": {
             "type": "object",
             "properties": {
+                "resolution": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_indicator.Resolution"
+                },
                 "series": {
                     "type": "array",
                     "items": {
@@ -380,6 +5537,40 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_api.IndicatorMetaResponse": {
+            "type": "object",
+            "properties": {
+                "definitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_definition.Change"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "description": "estimation method of I47, e.g. \"historical, 95%, 1d\"",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "precision": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "definition in effect today",
+                    "type": "integer"
+                }
+            }
+        },
         "internal_api.IndicatorSeries": {
             "type": "object",
             "properties": {
@@ -409,6 +5600,12 @@ const docTemplate = `{
                         "$ref": "#/definitions/internal_api.PeriodChange"
                     }
                 },
+                "definitionChanges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_definition.Change"
+                    }
+                },
                 "description": {
                     "type": "string"
                 },
@@ -426,6 +5623,49 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_api.IntradayResponse": {
+            "type": "object",
+            "properties": {
+                "hours": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_intraday.Sample"
+                    }
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api.MainIndicatorsBody": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        3,
+                        8,
+                        10
+                    ]
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
         "internal_api.PeriodChange": {
             "type": "object",
             "properties": {
@@ -437,6 +5677,168 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_api.PricingPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "orderbook"
+                }
+            }
+        },
+        "internal_api.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "snapshot_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "snapshot not found for date"
+                },
+                "error": {
+                    "description": "Error repeats Detail for clients of the {\"error\": \"...\"} body that\npreceded problem responses.",
+                    "type": "string",
+                    "example": "snapshot not found for date"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Snapshot not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/api/v1/errors#snapshot_not_found"
+                }
+            }
+        },
+        "internal_api.PropertyRequest": {
+            "type": "object",
+            "properties": {
+                "appraisalValue": {
+                    "type": "number",
+                    "example": 160000
+                },
+                "appraisedOn": {
+                    "type": "string",
+                    "example": "2026-03-01"
+                },
+                "areaM2": {
+                    "type": "number",
+                    "example": 54.3
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Budva, Montenegro"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Budva, Rozino 12, apt. 4"
+                },
+                "priceSymbol": {
+                    "type": "string",
+                    "example": "M2_BUDVA"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api.RegulatoryPriceBody": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number",
+                    "example": 4
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api.ReturnsResponse": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "returns": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_period.Returns"
+                },
+                "start": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api.SnapshotDetailsResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_snapshot.Detail"
+                    }
+                }
+            }
+        },
+        "internal_api.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "coingeckoBudget": {
+                    "description": "CoinGeckoBudget is today's use of the CoinGecko request budget\n(COINGECKO_DAILY_BUDGET). Absent when the server doesn't track it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_external.BudgetStatus"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "quality": {
+                    "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.DataQuality"
+                },
+                "slo": {
+                    "description": "SLO is the share of daily report runs over the last 30 days that\nfinished within SLO_DEADLINE, with the latest run's stage timings.\nAbsent when the server doesn't track it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_mtlprog_stat_internal_slo.Attainment"
+                        }
+                    ]
+                },
+                "snapshotDate": {
+                    "type": "string"
+                },
+                "unexportedSnapshots": {
+                    "description": "UnexportedSnapshots lists the snapshots whose Google Sheets export is\nstill owed, oldest first. Absent when the server has no export outbox.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_outbox.Task"
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "internal_api.SubfundSlice": {
             "type": "object",
             "properties": {
@@ -453,6 +5855,34 @@ const docTemplate = `{
                     "type": "number"
                 }
             }
+        },
+        "internal_api.SupplyHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_supply.Point"
+                    }
+                }
+            }
+        },
+        "internal_api.WatchlistResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mtlprog_stat_internal_domain.WatchedAccount"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,232 @@
+// Package accountguard records the on-chain configuration of fund accounts
+// (flags, home domain, inflation destination, sponsored reserves) in each
+// snapshot and warns when it drifts from the declared state in
+// account_expectations: an early warning for a taken-over or misconfigured
+// account.
+package accountguard
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/samber/lo"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// Expected is the declared configuration of one account. Nil fields are not
+// checked; an empty HomeDomain or InflationDestination means "must be unset".
+type Expected struct {
+	Account              string  `json:"account"`
+	HomeDomain           *string `json:"homeDomain"`
+	InflationDestination *string `json:"inflationDestination"`
+	AuthRequired         *bool   `json:"authRequired"`
+	AuthRevocable        *bool   `json:"authRevocable"`
+	AuthImmutable        *bool   `json:"authImmutable"`
+	AuthClawbackEnabled  *bool   `json:"authClawbackEnabled"`
+	NumSponsoring        *int    `json:"numSponsoring"`
+	NumSponsored         *int    `json:"numSponsored"`
+}
+
+// Pin declares cfg as the expected state, every field checked.
+func Pin(cfg domain.AccountConfig) Expected {
+	return Expected{
+		Account:              cfg.Account,
+		HomeDomain:           &cfg.HomeDomain,
+		InflationDestination: &cfg.InflationDestination,
+		AuthRequired:         &cfg.Flags.AuthRequired,
+		AuthRevocable:        &cfg.Flags.AuthRevocable,
+		AuthImmutable:        &cfg.Flags.AuthImmutable,
+		AuthClawbackEnabled:  &cfg.Flags.AuthClawbackEnabled,
+		NumSponsoring:        &cfg.NumSponsoring,
+		NumSponsored:         &cfg.NumSponsored,
+	}
+}
+
+// Drift describes every difference between cfg and exp, one sentence each.
+// An account that could not be fetched has no drift.
+func Drift(cfg domain.AccountConfig, exp Expected) []string {
+	if cfg.Error != "" {
+		return nil
+	}
+	var out []string
+	check := func(field string, got, want any) {
+		if got != want {
+			out = append(out, fmt.Sprintf("account config drift: %s %s is %v, expected %v", cfg.Name, field, got, want))
+		}
+	}
+	if exp.HomeDomain != nil {
+		check("home domain", quote(cfg.HomeDomain), quote(*exp.HomeDomain))
+	}
+	if exp.InflationDestination != nil {
+		check("inflation destination", quote(cfg.InflationDestination), quote(*exp.InflationDestination))
+	}
+	if exp.AuthRequired != nil {
+		check("auth_required", cfg.Flags.AuthRequired, *exp.AuthRequired)
+	}
+	if exp.AuthRevocable != nil {
+		check("auth_revocable", cfg.Flags.AuthRevocable, *exp.AuthRevocable)
+	}
+	if exp.AuthImmutable != nil {
+		check("auth_immutable", cfg.Flags.AuthImmutable, *exp.AuthImmutable)
+	}
+	if exp.AuthClawbackEnabled != nil {
+		check("auth_clawback_enabled", cfg.Flags.AuthClawbackEnabled, *exp.AuthClawbackEnabled)
+	}
+	if exp.NumSponsoring != nil {
+		check("sponsored reserves (sponsoring)", cfg.NumSponsoring, *exp.NumSponsoring)
+	}
+	if exp.NumSponsored != nil {
+		check("sponsored reserves (sponsored)", cfg.NumSponsored, *exp.NumSponsored)
+	}
+	return out
+}
+
+func quote(s string) string {
+	if s == "" {
+		return "unset"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// AccountSource fetches account details (horizon.Client).
+type AccountSource interface {
+	FetchAccount(ctx context.Context, accountID string) (horizon.HorizonAccount, error)
+}
+
+// ExpectationStore loads declared account configurations (PgRepository).
+type ExpectationStore interface {
+	List(ctx context.Context, slug string) ([]Expected, error)
+}
+
+// Service fills FundStructureData.AccountConfigs and adds drift warnings.
+type Service struct {
+	accounts     AccountSource
+	store        ExpectationStore
+	slug         string
+	fund         []domain.FundAccount
+	metadataKeys []string
+}
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithMetadataKeys also records the DATA entries named by keys in
+// AccountConfig.Metadata. A key ending in "*" matches every entry with
+// that prefix.
+func WithMetadataKeys(keys ...string) Option {
+	return func(s *Service) {
+		s.metadataKeys = keys
+	}
+}
+
+// NewService creates a Service checking fund (usually domain.AccountRegistry())
+// against the expectations stored for the entity slug.
+func NewService(accounts AccountSource, store ExpectationStore, slug string, fund []domain.FundAccount, opts ...Option) *Service {
+	s := &Service{accounts: accounts, store: store, slug: slug, fund: fund}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// metadata picks the configured DATA entries of an account and decodes them.
+func (s *Service) metadata(data map[string]string) map[string]string {
+	var out map[string]string
+	for key, encoded := range data {
+		if !lo.ContainsBy(s.metadataKeys, func(k string) bool {
+			prefix, wildcard := strings.CutSuffix(k, "*")
+			return key == k || wildcard && strings.HasPrefix(key, prefix)
+		}) {
+			continue
+		}
+		value := encoded
+		if raw, err := base64.StdEncoding.DecodeString(encoded); err == nil && utf8.Valid(raw) {
+			value = string(raw)
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// Collect fetches the configuration of every fund account. A failed account
+// is recorded with an error; only a cancelled ctx is returned as an error.
+func (s *Service) Collect(ctx context.Context) ([]domain.AccountConfig, error) {
+	configs := make([]domain.AccountConfig, 0, len(s.fund))
+	for _, acc := range s.fund {
+		a, err := s.accounts.FetchAccount(ctx, acc.Address)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			slog.Error("account config unavailable", "account", acc.Name, "address", acc.Address, "error", err)
+			configs = append(configs, domain.AccountConfig{Account: acc.Address, Name: acc.Name, Error: err.Error()})
+			continue
+		}
+		configs = append(configs, domain.AccountConfig{
+			Account: acc.Address,
+			Name:    acc.Name,
+			Flags: domain.AccountFlags{
+				AuthRequired:        a.Flags.AuthRequired,
+				AuthRevocable:       a.Flags.AuthRevocable,
+				AuthImmutable:       a.Flags.AuthImmutable,
+				AuthClawbackEnabled: a.Flags.AuthClawbackEnabled,
+			},
+			HomeDomain:           a.HomeDomain,
+			InflationDestination: a.InflationDestination,
+			NumSponsoring:        a.NumSponsoring,
+			NumSponsored:         a.NumSponsored,
+			Metadata:             s.metadata(a.Data),
+		})
+	}
+	return configs, nil
+}
+
+// Check returns the drift warnings for configs against the stored
+// expectations. Accounts without a row are not checked.
+func (s *Service) Check(ctx context.Context, configs []domain.AccountConfig) ([]string, error) {
+	expected, err := s.store.List(ctx, s.slug)
+	if err != nil {
+		return nil, fmt.Errorf("loading account expectations: %w", err)
+	}
+	byAccount := make(map[string]Expected, len(expected))
+	for _, e := range expected {
+		byAccount[e.Account] = e
+	}
+	var warnings []string
+	for _, cfg := range configs {
+		if e, ok := byAccount[cfg.Account]; ok {
+			warnings = append(warnings, Drift(cfg, e)...)
+		}
+	}
+	return warnings, nil
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher: the configurations are
+// stored in the snapshot and every drift is added to its warnings.
+func (s *Service) EnrichMetrics(ctx context.Context, _ time.Time, data *domain.FundStructureData) error {
+	configs, err := s.Collect(ctx)
+	if err != nil {
+		return err
+	}
+	data.AccountConfigs = configs
+
+	warnings, err := s.Check(ctx, configs)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		slog.Error(w)
+	}
+	data.Warnings = append(data.Warnings, warnings...)
+	return nil
+}
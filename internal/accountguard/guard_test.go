@@ -0,0 +1,136 @@
+package accountguard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+const (
+	addrMain = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAMAIN"
+	addrDown = "GBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBDOWN"
+)
+
+type stubAccounts map[string]horizon.HorizonAccount
+
+func (s stubAccounts) FetchAccount(_ context.Context, id string) (horizon.HorizonAccount, error) {
+	a, ok := s[id]
+	if !ok {
+		return horizon.HorizonAccount{}, errors.New("404")
+	}
+	return a, nil
+}
+
+type stubStore []Expected
+
+func (s stubStore) List(context.Context, string) ([]Expected, error) { return s, nil }
+
+func TestDrift(t *testing.T) {
+	cfg := domain.AccountConfig{
+		Account:    addrMain,
+		Name:       "MAIN",
+		Flags:      domain.AccountFlags{AuthRevocable: true},
+		HomeDomain: "evil.example",
+	}
+	exp := Pin(domain.AccountConfig{Account: addrMain, HomeDomain: "montelibero.org"})
+
+	got := Drift(cfg, exp)
+	if len(got) != 2 {
+		t.Fatalf("drift = %v, want home domain and auth_revocable", got)
+	}
+	if !strings.Contains(got[0], `MAIN home domain is "evil.example", expected "montelibero.org"`) {
+		t.Errorf("home domain warning = %q", got[0])
+	}
+	if !strings.Contains(got[1], "auth_revocable is true, expected false") {
+		t.Errorf("flag warning = %q", got[1])
+	}
+
+	if d := Drift(cfg, Expected{Account: addrMain, AuthRevocable: lo.ToPtr(true)}); len(d) != 0 {
+		t.Errorf("unchecked fields reported: %v", d)
+	}
+	if d := Drift(domain.AccountConfig{Account: addrMain, Error: "timeout"}, exp); len(d) != 0 {
+		t.Errorf("unfetched account reported: %v", d)
+	}
+	if d := Drift(domain.AccountConfig{Name: "MAIN"}, Expected{HomeDomain: lo.ToPtr("montelibero.org")}); len(d) != 1 || !strings.Contains(d[0], "is unset") {
+		t.Errorf("removed home domain = %v", d)
+	}
+}
+
+func TestEnrichMetrics(t *testing.T) {
+	accounts := stubAccounts{addrMain: {
+		ID:            addrMain,
+		Flags:         horizon.HorizonAccountFlags{AuthRequired: true},
+		HomeDomain:    "montelibero.org",
+		NumSponsoring: 3,
+	}}
+	store := stubStore{{Account: addrMain, NumSponsoring: lo.ToPtr(2), AuthRequired: lo.ToPtr(true)}}
+	fund := []domain.FundAccount{{Name: "MAIN", Address: addrMain}, {Name: "DOWN", Address: addrDown}}
+	svc := NewService(accounts, store, "mtlf", fund)
+
+	data := domain.FundStructureData{Warnings: []string{"existing"}}
+	if err := svc.EnrichMetrics(context.Background(), time.Now(), &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(data.AccountConfigs) != 2 {
+		t.Fatalf("configs = %+v, want both accounts", data.AccountConfigs)
+	}
+	main := data.AccountConfigs[0]
+	if !main.Flags.AuthRequired || main.HomeDomain != "montelibero.org" || main.NumSponsoring != 3 || main.Error != "" {
+		t.Errorf("MAIN config = %+v", main)
+	}
+	if data.AccountConfigs[1].Error == "" {
+		t.Error("unfetched account has no error")
+	}
+	if len(data.Warnings) != 2 || !strings.Contains(data.Warnings[1], "MAIN sponsored reserves (sponsoring) is 3, expected 2") {
+		t.Errorf("warnings = %v", data.Warnings)
+	}
+}
+
+func TestEnrichMetricsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc := NewService(stubAccounts{}, stubStore{}, "mtlf", []domain.FundAccount{{Name: "DOWN", Address: addrDown}})
+	if err := svc.EnrichMetrics(ctx, time.Now(), &domain.FundStructureData{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestCollectMetadata(t *testing.T) {
+	accounts := stubAccounts{addrMain: {Data: map[string]string{
+		"name":        "TW9udGVsaWJlcm8gRnVuZA==", // "Montelibero Fund"
+		"url_site":    "aHR0cHM6Ly9tdGxmLm1l",     // "https://mtlf.me"
+		"binary":      "/w==",                     // 0xff, not UTF-8
+		"MTL_1COST":   "MQ==",
+		"description": "not base64!",
+	}}}
+	svc := NewService(accounts, stubStore{}, "mtlf", []domain.FundAccount{{Name: "MAIN", Address: addrMain}},
+		WithMetadataKeys("name", "description", "binary", "url_*"))
+
+	configs, err := svc.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"name": "Montelibero Fund", "url_site": "https://mtlf.me", "binary": "/w==", "description": "not base64!"}
+	got := configs[0].Metadata
+	if len(got) != len(want) {
+		t.Fatalf("metadata = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("metadata[%s] = %q, want %q", k, got[k], v)
+		}
+	}
+
+	configs, _ = NewService(accounts, stubStore{}, "mtlf", []domain.FundAccount{{Name: "MAIN", Address: addrMain}}).Collect(context.Background())
+	if configs[0].Metadata != nil {
+		t.Errorf("metadata without keys = %v, want none", configs[0].Metadata)
+	}
+}
@@ -0,0 +1,72 @@
+package accountguard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores expectations in account_expectations.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL account expectation repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// List returns the expectations of the entity, by account.
+func (r *PgRepository) List(ctx context.Context, slug string) ([]Expected, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ae.account, ae.home_domain, ae.inflation_destination,
+		        ae.auth_required, ae.auth_revocable, ae.auth_immutable, ae.auth_clawback_enabled,
+		        ae.num_sponsoring, ae.num_sponsored
+		 FROM account_expectations ae
+		 JOIN fund_entities fe ON fe.id = ae.entity_id
+		 WHERE fe.slug = $1
+		 ORDER BY ae.account`, slug)
+	if err != nil {
+		return nil, fmt.Errorf("listing account expectations: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Expected
+	for rows.Next() {
+		var e Expected
+		if err := rows.Scan(&e.Account, &e.HomeDomain, &e.InflationDestination,
+			&e.AuthRequired, &e.AuthRevocable, &e.AuthImmutable, &e.AuthClawbackEnabled,
+			&e.NumSponsoring, &e.NumSponsored); err != nil {
+			return nil, fmt.Errorf("scanning account expectation: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating account expectations: %w", err)
+	}
+	return out, nil
+}
+
+// Save creates or replaces the expectation for e.Account.
+func (r *PgRepository) Save(ctx context.Context, slug string, e Expected) error {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO account_expectations (entity_id, account, home_domain, inflation_destination,
+		     auth_required, auth_revocable, auth_immutable, auth_clawback_enabled, num_sponsoring, num_sponsored)
+		 SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10 FROM fund_entities WHERE slug = $1
+		 ON CONFLICT (entity_id, account) DO UPDATE
+		 SET home_domain = EXCLUDED.home_domain, inflation_destination = EXCLUDED.inflation_destination,
+		     auth_required = EXCLUDED.auth_required, auth_revocable = EXCLUDED.auth_revocable,
+		     auth_immutable = EXCLUDED.auth_immutable, auth_clawback_enabled = EXCLUDED.auth_clawback_enabled,
+		     num_sponsoring = EXCLUDED.num_sponsoring, num_sponsored = EXCLUDED.num_sponsored,
+		     updated_at = CURRENT_TIMESTAMP`,
+		slug, e.Account, e.HomeDomain, e.InflationDestination,
+		e.AuthRequired, e.AuthRevocable, e.AuthImmutable, e.AuthClawbackEnabled, e.NumSponsoring, e.NumSponsored)
+	if err != nil {
+		return fmt.Errorf("saving expectation for %s: %w", e.Account, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("saving expectation for %s: entity %q not found", e.Account, slug)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package accountmeta tracks what the fund accounts say about themselves on
+// chain: the home domain and the DATA entries named by ACCOUNT_METADATA_KEYS
+// that accountguard stores in each snapshot. Each run compares them with the
+// previous snapshot and records every difference as an event, so a renamed
+// or repurposed account shows up in the API instead of only in the
+// hardcoded registry.
+package accountmeta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Kind classifies an Event.
+type Kind string
+
+const (
+	KindAdded   Kind = "added"
+	KindChanged Kind = "changed"
+	KindRemoved Kind = "removed"
+)
+
+// HomeDomainField is the Event.Field of home domain changes; every other
+// field is a DATA entry key.
+const HomeDomainField = "home_domain"
+
+// Event is one metadata difference of a fund account between two snapshots.
+type Event struct {
+	Date     time.Time `json:"date"`     // snapshot that saw the new value
+	PrevDate time.Time `json:"prevDate"` // snapshot it was compared with
+	Account  string    `json:"account"`
+	Name     string    `json:"name"` // registry name of the account
+	Field    string    `json:"field"`
+	Kind     Kind      `json:"kind"`
+	Old      string    `json:"old,omitempty"`
+	New      string    `json:"new,omitempty"`
+}
+
+func fields(cfg domain.AccountConfig) map[string]string {
+	out := make(map[string]string, len(cfg.Metadata)+1)
+	for k, v := range cfg.Metadata {
+		out[k] = v
+	}
+	if cfg.HomeDomain != "" {
+		out[HomeDomainField] = cfg.HomeDomain
+	}
+	return out
+}
+
+// Compare returns the metadata events between prev and cur, sorted by
+// account name and field. Accounts fetched in only one of the snapshots,
+// or with an error in either, are skipped, and a prev without account
+// configurations (snapshots older than accountguard) yields nothing.
+func Compare(prevDate, date time.Time, prev, cur []domain.AccountConfig) []Event {
+	before := make(map[string]domain.AccountConfig, len(prev))
+	for _, c := range prev {
+		before[c.Account] = c
+	}
+
+	var out []Event
+	for _, c := range cur {
+		p, ok := before[c.Account]
+		if !ok || p.Error != "" || c.Error != "" {
+			continue
+		}
+		old, now := fields(p), fields(c)
+		add := func(field string, kind Kind) {
+			out = append(out, Event{Date: date, PrevDate: prevDate, Account: c.Account, Name: c.Name,
+				Field: field, Kind: kind, Old: old[field], New: now[field]})
+		}
+		for field, v := range now {
+			switch o, existed := old[field]; {
+			case !existed:
+				add(field, KindAdded)
+			case o != v:
+				add(field, KindChanged)
+			}
+		}
+		for field := range old {
+			if _, kept := now[field]; !kept {
+				add(field, KindRemoved)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Field < out[j].Field
+	})
+	return out
+}
+
+// SnapshotSource finds the snapshot to compare with (snapshot.PgRepository).
+type SnapshotSource interface {
+	GetNearestBefore(ctx context.Context, slug string, date time.Time) (*snapshot.Snapshot, error)
+}
+
+// Store persists the events of a date (PgRepository).
+type Store interface {
+	Save(ctx context.Context, slug string, date time.Time, events []Event) error
+}
+
+// Service records the metadata events of each snapshot.
+type Service struct {
+	snapshots SnapshotSource
+	store     Store
+	slug      string
+}
+
+// NewService creates a Service for the entity slug.
+func NewService(snapshots SnapshotSource, store Store, slug string) *Service {
+	return &Service{snapshots: snapshots, store: store, slug: slug}
+}
+
+// Record compares data, the snapshot just generated for date, with the
+// latest stored snapshot before date and replaces the stored events of
+// date. Without an earlier snapshot nothing is recorded.
+func (s *Service) Record(ctx context.Context, date time.Time, data domain.FundStructureData) ([]Event, error) {
+	var events []Event
+	prev, err := s.snapshots.GetNearestBefore(ctx, s.slug, date.AddDate(0, 0, -1))
+	switch {
+	case errors.Is(err, snapshot.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("loading previous snapshot: %w", err)
+	default:
+		var prevData domain.FundStructureData
+		if err := json.Unmarshal(prev.Data, &prevData); err != nil {
+			return nil, fmt.Errorf("decoding snapshot %s: %w", prev.SnapshotDate.Format("2006-01-02"), err)
+		}
+		events = Compare(prev.SnapshotDate, date, prevData.AccountConfigs, data.AccountConfigs)
+	}
+
+	for _, e := range events {
+		slog.Info("account metadata changed", "account", e.Name, "field", e.Field, "kind", e.Kind, "old", e.Old, "new", e.New)
+	}
+	if err := s.store.Save(ctx, s.slug, date, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,107 @@
+package accountmeta
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+const (
+	addrMain = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAMAIN"
+	addrDefi = "GBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBDEFI"
+)
+
+var (
+	day1 = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 = time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+)
+
+func TestCompare(t *testing.T) {
+	prev := []domain.AccountConfig{
+		{Account: addrMain, Name: "MAIN", HomeDomain: "montelibero.org",
+			Metadata: map[string]string{"name": "Montelibero Fund", "purpose": "issuer"}},
+		{Account: addrDefi, Name: "DEFI", Error: "timeout"},
+	}
+	cur := []domain.AccountConfig{
+		{Account: addrMain, Name: "MAIN", HomeDomain: "mtlf.me",
+			Metadata: map[string]string{"name": "Montelibero Fund", "description": "Fund issuer"}},
+		{Account: addrDefi, Name: "DEFI", HomeDomain: "defi.example"},
+	}
+	events := Compare(day1, day2, prev, cur)
+
+	want := []struct {
+		field    string
+		kind     Kind
+		old, new string
+	}{
+		{"description", KindAdded, "", "Fund issuer"},
+		{HomeDomainField, KindChanged, "montelibero.org", "mtlf.me"},
+		{"purpose", KindRemoved, "issuer", ""},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Name != "MAIN" || e.Field != w.field || e.Kind != w.kind || e.Old != w.old || e.New != w.new {
+			t.Errorf("event %d = %+v, want %+v", i, e, w)
+		}
+		if !e.Date.Equal(day2) || !e.PrevDate.Equal(day1) {
+			t.Errorf("event %d dates = %s/%s", i, e.PrevDate, e.Date)
+		}
+	}
+
+	if got := Compare(day1, day2, nil, cur); got != nil {
+		t.Errorf("events without previous configs = %+v, want none", got)
+	}
+}
+
+type stubSnapshots struct {
+	snap *snapshot.Snapshot
+}
+
+func (s stubSnapshots) GetNearestBefore(context.Context, string, time.Time) (*snapshot.Snapshot, error) {
+	if s.snap == nil {
+		return nil, snapshot.ErrNotFound
+	}
+	return s.snap, nil
+}
+
+type stubStore struct {
+	saved  bool
+	events []Event
+}
+
+func (s *stubStore) Save(_ context.Context, _ string, _ time.Time, events []Event) error {
+	s.saved, s.events = true, events
+	return nil
+}
+
+func TestServiceRecord(t *testing.T) {
+	prevData, _ := json.Marshal(domain.FundStructureData{AccountConfigs: []domain.AccountConfig{
+		{Account: addrMain, Name: "MAIN", HomeDomain: "montelibero.org"},
+	}})
+	cur := domain.FundStructureData{AccountConfigs: []domain.AccountConfig{
+		{Account: addrMain, Name: "MAIN", HomeDomain: "evil.example"},
+	}}
+
+	store := &stubStore{}
+	events, err := NewService(stubSnapshots{&snapshot.Snapshot{SnapshotDate: day1, Data: prevData}}, store, "mtlf").
+		Record(context.Background(), day2, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Kind != KindChanged || !store.saved || len(store.events) != 1 {
+		t.Errorf("events = %+v, store = %+v", events, store)
+	}
+
+	store = &stubStore{}
+	events, err = NewService(stubSnapshots{}, store, "mtlf").Record(context.Background(), day2, cur)
+	if err != nil || events != nil || !store.saved {
+		t.Errorf("without a previous snapshot: events = %+v, err = %v, saved = %v", events, err, store.saved)
+	}
+}
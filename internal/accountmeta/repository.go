@@ -0,0 +1,90 @@
+package accountmeta
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores events in account_metadata_events.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL account metadata event repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Save replaces the events of date.
+func (r *PgRepository) Save(ctx context.Context, slug string, date time.Time, events []Event) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning account metadata tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM account_metadata_events WHERE entity_id = $1 AND snapshot_date = $2`, entityID, date); err != nil {
+		return fmt.Errorf("clearing account metadata events for %s: %w", date.Format("2006-01-02"), err)
+	}
+	for _, e := range events {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO account_metadata_events (entity_id, snapshot_date, prev_date, account, account_name,
+			     field, kind, old_value, new_value)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			entityID, date, e.PrevDate, e.Account, e.Name, e.Field, string(e.Kind), e.Old, e.New); err != nil {
+			return fmt.Errorf("saving %s %s event for %s: %w", e.Name, e.Field, date.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing account metadata events: %w", err)
+	}
+	return nil
+}
+
+// List returns the events between from and to (inclusive; zero means
+// unbounded), oldest first.
+func (r *PgRepository) List(ctx context.Context, slug string, from, to time.Time) ([]Event, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT e.snapshot_date, e.prev_date, e.account, e.account_name, e.field, e.kind, e.old_value, e.new_value
+		 FROM account_metadata_events e
+		 JOIN fund_entities fe ON fe.id = e.entity_id
+		 WHERE fe.slug = $1
+		   AND ($2::date IS NULL OR e.snapshot_date >= $2)
+		   AND ($3::date IS NULL OR e.snapshot_date <= $3)
+		 ORDER BY e.snapshot_date, e.account_name, e.field`,
+		slug, nullDate(from), nullDate(to))
+	if err != nil {
+		return nil, fmt.Errorf("listing account metadata events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		var e Event
+		var kind string
+		if err := rows.Scan(&e.Date, &e.PrevDate, &e.Account, &e.Name, &e.Field, &kind, &e.Old, &e.New); err != nil {
+			return nil, fmt.Errorf("scanning account metadata event: %w", err)
+		}
+		e.Kind = Kind(kind)
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating account metadata events: %w", err)
+	}
+	return out, nil
+}
+
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
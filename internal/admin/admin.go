@@ -0,0 +1,226 @@
+// Package admin stores the operator-managed configuration behind
+// /api/v1/admin/...: fund entities, account expectations, the real-estate
+// property registry, the Montelibero Index definition, the regulatory
+// price, the per-asset pricing policies and the IND_MAIN indicator set.
+// Every resource carries a version for optimistic concurrency, and every
+// change is written to admin_audit in the same transaction.
+//
+// The account registry is compiled in (domain.AccountRegistry) and is not
+// managed here.
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/property"
+	"github.com/mtlprog/stat/internal/stellarkey"
+)
+
+var (
+	// ErrNotFound indicates the resource doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrVersionMismatch indicates the resource changed since the version the
+	// caller read (or doesn't exist at that version).
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrInvalid indicates a resource that fails validation.
+	ErrInvalid = errors.New("invalid")
+)
+
+// Audited resources.
+const (
+	ResourceEntity          = "entity"
+	ResourceAccount         = "account"
+	ResourceIndex           = "index"
+	ResourceProperty        = "property"
+	ResourceRegulatoryPrice = "regulatory-price"
+	ResourcePricing         = "pricing"
+	ResourceMainIndicators  = "main-indicators"
+)
+
+// Change describes who is writing and which version they expect.
+type Change struct {
+	IfMatch int    // version the caller read; 0 writes unconditionally
+	Remote  string // client address, for the audit log
+}
+
+// check returns ErrVersionMismatch when c expects a version other than
+// current (0 when the resource doesn't exist).
+func (c Change) check(current int) error {
+	if c.IfMatch != 0 && c.IfMatch != current {
+		if current == 0 {
+			return fmt.Errorf("%w: resource does not exist", ErrVersionMismatch)
+		}
+		return fmt.Errorf("%w: current version is %d", ErrVersionMismatch, current)
+	}
+	return nil
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Entity is a fund entity (fund_entities).
+type Entity struct {
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Version     int       `json:"version"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Validate checks the slug format and that the name is set.
+func (e Entity) Validate() error {
+	if !slugPattern.MatchString(e.Slug) {
+		return fmt.Errorf("%w: slug must be lowercase letters, digits and dashes (at most 63)", ErrInvalid)
+	}
+	if e.Name == "" || len(e.Name) > 255 {
+		return fmt.Errorf("%w: name must be 1 to 255 characters", ErrInvalid)
+	}
+	return nil
+}
+
+// Account is the declared configuration of one fund account
+// (account_expectations).
+type Account struct {
+	accountguard.Expected
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ValidateExpected checks the address and that sponsorship counts are not
+// negative.
+func ValidateExpected(e accountguard.Expected) error {
+	if !stellarkey.ValidAddress(e.Account) {
+		return fmt.Errorf("%w: %q is not a Stellar account address", ErrInvalid, e.Account)
+	}
+	if (e.NumSponsoring != nil && *e.NumSponsoring < 0) || (e.NumSponsored != nil && *e.NumSponsored < 0) {
+		return fmt.Errorf("%w: sponsorship counts must not be negative", ErrInvalid)
+	}
+	return nil
+}
+
+// Property is a registered real-estate token (properties).
+type Property struct {
+	property.Property
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var tokenPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,12}$`)
+
+// ValidateProperty checks the token code, that the area is positive and
+// that the property can be valued: by a price symbol or an appraisal.
+func ValidateProperty(p property.Property) error {
+	if !tokenPattern.MatchString(p.Token) {
+		return fmt.Errorf("%w: token must be a Stellar asset code (1 to 12 letters and digits)", ErrInvalid)
+	}
+	if !p.AreaM2.IsPositive() {
+		return fmt.Errorf("%w: areaM2 must be positive", ErrInvalid)
+	}
+	if p.AppraisalValue != nil && !p.AppraisalValue.IsPositive() {
+		return fmt.Errorf("%w: appraisalValue must be positive", ErrInvalid)
+	}
+	if p.PriceSymbol == "" && p.AppraisalValue == nil {
+		return fmt.Errorf("%w: set priceSymbol or appraisalValue", ErrInvalid)
+	}
+	if len(p.PriceSymbol) > 32 {
+		return fmt.Errorf("%w: priceSymbol must be at most 32 characters", ErrInvalid)
+	}
+	return nil
+}
+
+// Index is the stored Montelibero Index definition. Version 0 means none is
+// stored and indicator.DefaultIndexConfig applies.
+type Index struct {
+	Config    indicator.IndexConfig
+	Version   int
+	UpdatedAt *time.Time
+}
+
+// RegulatoryPrice is the stored Regulatory Price of the MONITORING sheet.
+// Version 0 means none is stored and export.DefaultRegulatoryPrice applies.
+type RegulatoryPrice struct {
+	Price     decimal.Decimal
+	Version   int
+	UpdatedAt *time.Time
+}
+
+// ValidateRegulatoryPrice checks that the price is positive.
+func ValidateRegulatoryPrice(p decimal.Decimal) error {
+	if !p.IsPositive() {
+		return fmt.Errorf("%w: price must be positive", ErrInvalid)
+	}
+	return nil
+}
+
+// PricingPolicy is the spot price source pinned for one asset
+// (pricing_policies).
+type PricingPolicy struct {
+	price.Policy
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ValidatePolicy checks that the asset is "native" or CODE:ISSUER and that
+// the source is one price.Service can pin.
+func ValidatePolicy(p price.Policy) error {
+	if p.Asset != "native" {
+		code, issuer, ok := strings.Cut(p.Asset, ":")
+		if !ok || !tokenPattern.MatchString(code) || !stellarkey.ValidAddress(issuer) {
+			return fmt.Errorf("%w: asset must be native or CODE:ISSUER", ErrInvalid)
+		}
+	}
+	switch p.Source {
+	case price.SourceBest, price.SourcePath, price.SourceOrderbook:
+		return nil
+	}
+	return fmt.Errorf("%w: source must be %s, %s or %s", ErrInvalid, price.SourceBest, price.SourcePath, price.SourceOrderbook)
+}
+
+// MainIndicators is the stored IND_MAIN indicator set. Version 0 means none
+// is stored and export.DefaultMainIndicatorIDs applies.
+type MainIndicators struct {
+	IDs       []int
+	Version   int
+	UpdatedAt *time.Time
+}
+
+// ValidateMainIndicators checks that the set is not empty and lists each
+// registered indicator at most once.
+func ValidateMainIndicators(ids []int) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: at least one indicator is required", ErrInvalid)
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := indicator.MetaOf(id); !ok {
+			return fmt.Errorf("%w: I%d is not a registered indicator", ErrInvalid, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: I%d listed twice", ErrInvalid, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// AuditEntry is one change made through the admin API.
+type AuditEntry struct {
+	ID       int64           `json:"id"`
+	At       time.Time       `json:"at"`
+	Resource string          `json:"resource"`
+	Key      string          `json:"key"`
+	Action   string          `json:"action"` // create, update or delete
+	Version  int             `json:"version"`
+	Before   json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After    json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Remote   string          `json:"remote,omitempty"`
+}
@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/property"
+)
+
+func TestChangeCheck(t *testing.T) {
+	tests := []struct {
+		ifMatch, current int
+		wantErr          bool
+	}{
+		{0, 0, false}, // unconditional create
+		{0, 4, false}, // unconditional update
+		{4, 4, false},
+		{3, 4, true},
+		{1, 0, true}, // expects a resource that doesn't exist
+	}
+	for _, tt := range tests {
+		err := Change{IfMatch: tt.ifMatch}.check(tt.current)
+		if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrVersionMismatch)) {
+			t.Errorf("IfMatch %d at version %d: err = %v, wantErr %v", tt.ifMatch, tt.current, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, e := range []Entity{{Slug: "mtlf", Name: "MTL Fund"}, {Slug: "fund-2", Name: "x"}} {
+		if err := e.Validate(); err != nil {
+			t.Errorf("%+v: %v", e, err)
+		}
+	}
+	for _, e := range []Entity{{Slug: "MTLF", Name: "x"}, {Slug: "-a", Name: "x"}, {Slug: "a b", Name: "x"}, {Slug: "mtlf"}} {
+		if err := e.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: err = %v, want ErrInvalid", e, err)
+		}
+	}
+
+	if err := ValidateExpected(accountguard.Expected{Account: domain.IssuerAddress, NumSponsoring: lo.ToPtr(0)}); err != nil {
+		t.Errorf("valid expectation: %v", err)
+	}
+	for _, e := range []accountguard.Expected{
+		{Account: "GABC"},
+		{Account: domain.IssuerAddress, NumSponsored: lo.ToPtr(-1)},
+	} {
+		if err := ValidateExpected(e); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: err = %v, want ErrInvalid", e, err)
+		}
+	}
+}
+
+func TestValidateProperty(t *testing.T) {
+	area := decimal.NewFromInt(54)
+	for _, p := range []property.Property{
+		{Token: "MCITY136", AreaM2: area, PriceSymbol: "M2_BUDVA"},
+		{Token: "MFAPART1", AreaM2: area, AppraisalValue: lo.ToPtr(decimal.NewFromInt(160000))},
+	} {
+		if err := ValidateProperty(p); err != nil {
+			t.Errorf("%+v: %v", p, err)
+		}
+	}
+	for _, p := range []property.Property{
+		{Token: "MCITY-1", AreaM2: area, PriceSymbol: "M2_BUDVA"},
+		{Token: "MCITY136", PriceSymbol: "M2_BUDVA"},
+		{Token: "MCITY136", AreaM2: area},
+		{Token: "MCITY136", AreaM2: area, AppraisalValue: lo.ToPtr(decimal.Zero)},
+	} {
+		if err := ValidateProperty(p); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: err = %v, want ErrInvalid", p, err)
+		}
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	if err := ValidateRegulatoryPrice(decimal.RequireFromString("4.5")); err != nil {
+		t.Errorf("4.5: %v", err)
+	}
+	if err := ValidateRegulatoryPrice(decimal.Zero); !errors.Is(err, ErrInvalid) {
+		t.Errorf("0: err = %v, want ErrInvalid", err)
+	}
+
+	for _, p := range []price.Policy{
+		{Asset: "native", Source: price.SourcePath},
+		{Asset: "MTL:" + domain.IssuerAddress, Source: price.SourceOrderbook},
+		{Asset: "MTL:" + domain.IssuerAddress, Source: price.SourceBest},
+	} {
+		if err := ValidatePolicy(p); err != nil {
+			t.Errorf("%+v: %v", p, err)
+		}
+	}
+	for _, p := range []price.Policy{
+		{Asset: "MTL", Source: price.SourcePath},
+		{Asset: "MTL:GABC", Source: price.SourcePath},
+		{Asset: "native", Source: price.SourceAMM},
+	} {
+		if err := ValidatePolicy(p); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: err = %v, want ErrInvalid", p, err)
+		}
+	}
+
+	if err := ValidateMainIndicators([]int{1, 8, 47}); err != nil {
+		t.Errorf("[1 8 47]: %v", err)
+	}
+	for _, ids := range [][]int{nil, {1, 1}, {9999}} {
+		if err := ValidateMainIndicators(ids); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%v: err = %v, want ErrInvalid", ids, err)
+		}
+	}
+}
@@ -0,0 +1,944 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/export"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/property"
+)
+
+// PgRepository reads and writes the admin-managed tables, auditing every
+// write in admin_audit.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL admin repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// querier is a pool or a transaction.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// entityID resolves slug, returning ErrNotFound for an unknown entity.
+func entityID(ctx context.Context, q querier, slug string) (int, error) {
+	var id int
+	err := q.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("entity %q: %w", slug, ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	return id, nil
+}
+
+// audit records a change in admin_audit. A nil before or after is stored as
+// NULL.
+func audit(ctx context.Context, tx pgx.Tx, resource, key, action string, version int, before, after any, remote string) error {
+	encode := func(v any) ([]byte, error) {
+		if v == nil {
+			return nil, nil
+		}
+		return json.Marshal(v)
+	}
+	b, err := encode(before)
+	if err != nil {
+		return fmt.Errorf("encoding audit state: %w", err)
+	}
+	a, err := encode(after)
+	if err != nil {
+		return fmt.Errorf("encoding audit state: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO admin_audit (resource, key, action, version, before, after, remote)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		resource, key, action, version, b, a, remote); err != nil {
+		return fmt.Errorf("recording audit entry: %w", err)
+	}
+	return nil
+}
+
+const entityColumns = `slug, name, COALESCE(description, ''), version, updated_at`
+
+func scanEntity(row pgx.Row) (Entity, error) {
+	var e Entity
+	err := row.Scan(&e.Slug, &e.Name, &e.Description, &e.Version, &e.UpdatedAt)
+	return e, err
+}
+
+// Entities returns all entities by slug.
+func (r *PgRepository) Entities(ctx context.Context) ([]Entity, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+entityColumns+` FROM fund_entities ORDER BY slug`)
+	if err != nil {
+		return nil, fmt.Errorf("listing entities: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Entity
+	for rows.Next() {
+		e, err := scanEntity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning entity: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating entities: %w", err)
+	}
+	return out, nil
+}
+
+// Entity returns the entity with the slug.
+func (r *PgRepository) Entity(ctx context.Context, slug string) (Entity, error) {
+	e, err := scanEntity(r.pool.QueryRow(ctx, `SELECT `+entityColumns+` FROM fund_entities WHERE slug = $1`, slug))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Entity{}, fmt.Errorf("entity %q: %w", slug, ErrNotFound)
+	}
+	if err != nil {
+		return Entity{}, fmt.Errorf("loading entity %q: %w", slug, err)
+	}
+	return e, nil
+}
+
+// SaveEntity creates the entity or updates its name and description.
+func (r *PgRepository) SaveEntity(ctx context.Context, e Entity, ch Change) (Entity, error) {
+	if err := e.Validate(); err != nil {
+		return Entity{}, err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Entity{}, fmt.Errorf("beginning entity tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var before *Entity
+	cur, err := scanEntity(tx.QueryRow(ctx, `SELECT `+entityColumns+` FROM fund_entities WHERE slug = $1 FOR UPDATE`, e.Slug))
+	switch {
+	case err == nil:
+		before = &cur
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Entity{}, fmt.Errorf("loading entity %q: %w", e.Slug, err)
+	}
+	if err := ch.check(cur.Version); err != nil {
+		return Entity{}, err
+	}
+
+	action := "create"
+	if before != nil {
+		action = "update"
+		e, err = scanEntity(tx.QueryRow(ctx,
+			`UPDATE fund_entities SET name = $2, description = $3, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			 WHERE slug = $1
+			 RETURNING `+entityColumns, e.Slug, e.Name, e.Description))
+	} else {
+		e, err = scanEntity(tx.QueryRow(ctx,
+			`INSERT INTO fund_entities (slug, name, description) VALUES ($1, $2, $3)
+			 RETURNING `+entityColumns, e.Slug, e.Name, e.Description))
+	}
+	if err != nil {
+		return Entity{}, fmt.Errorf("saving entity %q: %w", e.Slug, err)
+	}
+	var beforeState any
+	if before != nil {
+		beforeState = before
+	}
+	if err := audit(ctx, tx, ResourceEntity, e.Slug, action, e.Version, beforeState, e, ch.Remote); err != nil {
+		return Entity{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Entity{}, fmt.Errorf("committing entity %q: %w", e.Slug, err)
+	}
+	return e, nil
+}
+
+const accountColumns = `account, home_domain, inflation_destination,
+	auth_required, auth_revocable, auth_immutable, auth_clawback_enabled,
+	num_sponsoring, num_sponsored, version, updated_at`
+
+func scanAccount(row pgx.Row) (Account, error) {
+	var a Account
+	err := row.Scan(&a.Account, &a.HomeDomain, &a.InflationDestination,
+		&a.AuthRequired, &a.AuthRevocable, &a.AuthImmutable, &a.AuthClawbackEnabled,
+		&a.NumSponsoring, &a.NumSponsored, &a.Version, &a.UpdatedAt)
+	return a, err
+}
+
+// Accounts returns the account expectations of the entity, by account.
+func (r *PgRepository) Accounts(ctx context.Context, slug string) ([]Account, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+accountColumns+` FROM account_expectations WHERE entity_id = $1 ORDER BY account`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing account expectations: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Account
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning account expectation: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating account expectations: %w", err)
+	}
+	return out, nil
+}
+
+// Account returns the expectation for one account of the entity.
+func (r *PgRepository) Account(ctx context.Context, slug, address string) (Account, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return Account{}, err
+	}
+	a, err := scanAccount(r.pool.QueryRow(ctx,
+		`SELECT `+accountColumns+` FROM account_expectations WHERE entity_id = $1 AND account = $2`, id, address))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, fmt.Errorf("account %s: %w", address, ErrNotFound)
+	}
+	if err != nil {
+		return Account{}, fmt.Errorf("loading expectation for %s: %w", address, err)
+	}
+	return a, nil
+}
+
+// lockAccount returns the current expectation for address, nil when there
+// is none, locking the row for the transaction.
+func lockAccount(ctx context.Context, tx pgx.Tx, entityID int, address string) (*Account, error) {
+	a, err := scanAccount(tx.QueryRow(ctx,
+		`SELECT `+accountColumns+` FROM account_expectations WHERE entity_id = $1 AND account = $2 FOR UPDATE`,
+		entityID, address))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading expectation for %s: %w", address, err)
+	}
+	return &a, nil
+}
+
+// SaveAccount creates or replaces the expectation for e.Account.
+func (r *PgRepository) SaveAccount(ctx context.Context, slug string, e accountguard.Expected, ch Change) (Account, error) {
+	if err := ValidateExpected(e); err != nil {
+		return Account{}, err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Account{}, fmt.Errorf("beginning account tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return Account{}, err
+	}
+	before, err := lockAccount(ctx, tx, id, e.Account)
+	if err != nil {
+		return Account{}, err
+	}
+	current, action := 0, "create"
+	if before != nil {
+		current, action = before.Version, "update"
+	}
+	if err := ch.check(current); err != nil {
+		return Account{}, err
+	}
+
+	a, err := scanAccount(tx.QueryRow(ctx,
+		`INSERT INTO account_expectations (entity_id, account, home_domain, inflation_destination,
+		     auth_required, auth_revocable, auth_immutable, auth_clawback_enabled, num_sponsoring, num_sponsored)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 ON CONFLICT (entity_id, account) DO UPDATE
+		 SET home_domain = EXCLUDED.home_domain, inflation_destination = EXCLUDED.inflation_destination,
+		     auth_required = EXCLUDED.auth_required, auth_revocable = EXCLUDED.auth_revocable,
+		     auth_immutable = EXCLUDED.auth_immutable, auth_clawback_enabled = EXCLUDED.auth_clawback_enabled,
+		     num_sponsoring = EXCLUDED.num_sponsoring, num_sponsored = EXCLUDED.num_sponsored,
+		     version = account_expectations.version + 1, updated_at = CURRENT_TIMESTAMP
+		 RETURNING `+accountColumns,
+		id, e.Account, e.HomeDomain, e.InflationDestination,
+		e.AuthRequired, e.AuthRevocable, e.AuthImmutable, e.AuthClawbackEnabled, e.NumSponsoring, e.NumSponsored))
+	if err != nil {
+		return Account{}, fmt.Errorf("saving expectation for %s: %w", e.Account, err)
+	}
+	var beforeState any
+	if before != nil {
+		beforeState = before
+	}
+	if err := audit(ctx, tx, ResourceAccount, slug+"/"+e.Account, action, a.Version, beforeState, a, ch.Remote); err != nil {
+		return Account{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Account{}, fmt.Errorf("committing expectation for %s: %w", e.Account, err)
+	}
+	return a, nil
+}
+
+// DeleteAccount removes the expectation for address, so the account's
+// configuration is no longer checked.
+func (r *PgRepository) DeleteAccount(ctx context.Context, slug, address string, ch Change) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning account tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return err
+	}
+	before, err := lockAccount(ctx, tx, id, address)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return fmt.Errorf("account %s: %w", address, ErrNotFound)
+	}
+	if err := ch.check(before.Version); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM account_expectations WHERE entity_id = $1 AND account = $2`, id, address); err != nil {
+		return fmt.Errorf("deleting expectation for %s: %w", address, err)
+	}
+	if err := audit(ctx, tx, ResourceAccount, slug+"/"+address, "delete", before.Version, before, nil, ch.Remote); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing expectation for %s: %w", address, err)
+	}
+	return nil
+}
+
+const propertyColumns = `token, name, location, area_m2, price_symbol, appraisal_value,
+	appraised_on::timestamptz, version, updated_at`
+
+func scanProperty(row pgx.Row) (Property, error) {
+	var p Property
+	err := row.Scan(&p.Token, &p.Name, &p.Location, &p.AreaM2, &p.PriceSymbol, &p.AppraisalValue,
+		&p.AppraisedOn, &p.Version, &p.UpdatedAt)
+	return p, err
+}
+
+// Properties returns the registered properties of the entity, by token.
+func (r *PgRepository) Properties(ctx context.Context, slug string) ([]Property, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+propertyColumns+` FROM properties WHERE entity_id = $1 ORDER BY token`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing properties: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Property
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning property: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating properties: %w", err)
+	}
+	return out, nil
+}
+
+// Property returns the registry entry of one token of the entity.
+func (r *PgRepository) Property(ctx context.Context, slug, token string) (Property, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return Property{}, err
+	}
+	p, err := scanProperty(r.pool.QueryRow(ctx,
+		`SELECT `+propertyColumns+` FROM properties WHERE entity_id = $1 AND token = $2`, id, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Property{}, fmt.Errorf("property %s: %w", token, ErrNotFound)
+	}
+	if err != nil {
+		return Property{}, fmt.Errorf("loading property %s: %w", token, err)
+	}
+	return p, nil
+}
+
+// lockProperty returns the current entry for token, nil when there is none,
+// locking the row for the transaction.
+func lockProperty(ctx context.Context, tx pgx.Tx, entityID int, token string) (*Property, error) {
+	p, err := scanProperty(tx.QueryRow(ctx,
+		`SELECT `+propertyColumns+` FROM properties WHERE entity_id = $1 AND token = $2 FOR UPDATE`,
+		entityID, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading property %s: %w", token, err)
+	}
+	return &p, nil
+}
+
+// SaveProperty creates or replaces the registry entry for p.Token. The next
+// snapshot values the token by it.
+func (r *PgRepository) SaveProperty(ctx context.Context, slug string, p property.Property, ch Change) (Property, error) {
+	if err := ValidateProperty(p); err != nil {
+		return Property{}, err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Property{}, fmt.Errorf("beginning property tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return Property{}, err
+	}
+	before, err := lockProperty(ctx, tx, id, p.Token)
+	if err != nil {
+		return Property{}, err
+	}
+	current, action := 0, "create"
+	if before != nil {
+		current, action = before.Version, "update"
+	}
+	if err := ch.check(current); err != nil {
+		return Property{}, err
+	}
+
+	var appraisedOn *string
+	if p.AppraisedOn != nil {
+		d := p.AppraisedOn.Format("2006-01-02")
+		appraisedOn = &d
+	}
+	saved, err := scanProperty(tx.QueryRow(ctx,
+		`INSERT INTO properties (entity_id, token, name, location, area_m2, price_symbol, appraisal_value, appraised_on)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::date)
+		 ON CONFLICT (entity_id, token) DO UPDATE
+		 SET name = EXCLUDED.name, location = EXCLUDED.location, area_m2 = EXCLUDED.area_m2,
+		     price_symbol = EXCLUDED.price_symbol, appraisal_value = EXCLUDED.appraisal_value,
+		     appraised_on = EXCLUDED.appraised_on,
+		     version = properties.version + 1, updated_at = CURRENT_TIMESTAMP
+		 RETURNING `+propertyColumns,
+		id, p.Token, p.Name, p.Location, p.AreaM2, p.PriceSymbol, p.AppraisalValue, appraisedOn))
+	if err != nil {
+		return Property{}, fmt.Errorf("saving property %s: %w", p.Token, err)
+	}
+	var beforeState any
+	if before != nil {
+		beforeState = before
+	}
+	if err := audit(ctx, tx, ResourceProperty, slug+"/"+p.Token, action, saved.Version, beforeState, saved, ch.Remote); err != nil {
+		return Property{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Property{}, fmt.Errorf("committing property %s: %w", p.Token, err)
+	}
+	return saved, nil
+}
+
+// DeleteProperty removes the registry entry for token, so the token is
+// valued by its DATA entries again.
+func (r *PgRepository) DeleteProperty(ctx context.Context, slug, token string, ch Change) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning property tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return err
+	}
+	before, err := lockProperty(ctx, tx, id, token)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return fmt.Errorf("property %s: %w", token, ErrNotFound)
+	}
+	if err := ch.check(before.Version); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM properties WHERE entity_id = $1 AND token = $2`, id, token); err != nil {
+		return fmt.Errorf("deleting property %s: %w", token, err)
+	}
+	if err := audit(ctx, tx, ResourceProperty, slug+"/"+token, "delete", before.Version, before, nil, ch.Remote); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing property %s: %w", token, err)
+	}
+	return nil
+}
+
+// loadIndex returns the stored index definition of the entity, nil when
+// none is stored.
+func loadIndex(ctx context.Context, q querier, entityID int, lock bool) (*Index, error) {
+	sql := `SELECT base_date, weights, version, updated_at FROM index_config WHERE entity_id = $1`
+	if lock {
+		sql += ` FOR UPDATE`
+	}
+	var idx Index
+	var weights []byte
+	err := q.QueryRow(ctx, sql, entityID).Scan(&idx.Config.BaseDate, &weights, &idx.Version, &idx.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading index config: %w", err)
+	}
+	if err := json.Unmarshal(weights, &idx.Config.Weights); err != nil {
+		return nil, fmt.Errorf("decoding index weights: %w", err)
+	}
+	return &idx, nil
+}
+
+// Index returns the index definition of the entity: the stored one, or
+// indicator.DefaultIndexConfig at version 0.
+func (r *PgRepository) Index(ctx context.Context, slug string) (Index, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return Index{}, err
+	}
+	idx, err := loadIndex(ctx, r.pool, id, false)
+	if err != nil {
+		return Index{}, err
+	}
+	if idx == nil {
+		return Index{Config: indicator.DefaultIndexConfig}, nil
+	}
+	return *idx, nil
+}
+
+// SaveIndex validates and stores the index definition of the entity.
+// Stored I66 values are not recomputed; see `stat backfill-index`.
+func (r *PgRepository) SaveIndex(ctx context.Context, slug string, cfg indicator.IndexConfig, ch Change) (Index, error) {
+	if err := cfg.Validate(); err != nil {
+		return Index{}, err
+	}
+	weights, err := json.Marshal(cfg.Weights)
+	if err != nil {
+		return Index{}, fmt.Errorf("encoding index weights: %w", err)
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Index{}, fmt.Errorf("beginning index tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return Index{}, err
+	}
+	before, err := loadIndex(ctx, tx, id, true)
+	if err != nil {
+		return Index{}, err
+	}
+	current, action := 0, "create"
+	var beforeState any
+	if before != nil {
+		current, action, beforeState = before.Version, "update", before.Config
+	}
+	if err := ch.check(current); err != nil {
+		return Index{}, err
+	}
+
+	idx := Index{Config: cfg}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO index_config (entity_id, base_date, weights) VALUES ($1, $2, $3)
+		 ON CONFLICT (entity_id) DO UPDATE
+		 SET base_date = EXCLUDED.base_date, weights = EXCLUDED.weights,
+		     version = index_config.version + 1, updated_at = CURRENT_TIMESTAMP
+		 RETURNING version, updated_at`,
+		id, cfg.BaseDate, weights).Scan(&idx.Version, &idx.UpdatedAt); err != nil {
+		return Index{}, fmt.Errorf("saving index config: %w", err)
+	}
+	if err := audit(ctx, tx, ResourceIndex, slug, action, idx.Version, beforeState, cfg, ch.Remote); err != nil {
+		return Index{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Index{}, fmt.Errorf("committing index config: %w", err)
+	}
+	return idx, nil
+}
+
+// regulatoryPriceState and mainIndicatorsState are the audited forms of the
+// singleton parameters.
+type regulatoryPriceState struct {
+	Price decimal.Decimal `json:"price"`
+}
+
+type mainIndicatorsState struct {
+	IDs []int `json:"ids"`
+}
+
+// loadRegulatoryPrice returns the stored regulatory price of the entity,
+// nil when none is stored.
+func loadRegulatoryPrice(ctx context.Context, q querier, entityID int, lock bool) (*RegulatoryPrice, error) {
+	sql := `SELECT price, version, updated_at FROM regulatory_price WHERE entity_id = $1`
+	if lock {
+		sql += ` FOR UPDATE`
+	}
+	var rp RegulatoryPrice
+	err := q.QueryRow(ctx, sql, entityID).Scan(&rp.Price, &rp.Version, &rp.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading regulatory price: %w", err)
+	}
+	return &rp, nil
+}
+
+// RegulatoryPrice returns the regulatory price of the entity: the stored
+// one, or export.DefaultRegulatoryPrice at version 0.
+func (r *PgRepository) RegulatoryPrice(ctx context.Context, slug string) (RegulatoryPrice, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return RegulatoryPrice{}, err
+	}
+	rp, err := loadRegulatoryPrice(ctx, r.pool, id, false)
+	if err != nil {
+		return RegulatoryPrice{}, err
+	}
+	if rp == nil {
+		return RegulatoryPrice{Price: export.DefaultRegulatoryPrice}, nil
+	}
+	return *rp, nil
+}
+
+// SaveRegulatoryPrice stores the regulatory price of the entity. Rows
+// already in MONITORING keep the price they were written with.
+func (r *PgRepository) SaveRegulatoryPrice(ctx context.Context, slug string, p decimal.Decimal, ch Change) (RegulatoryPrice, error) {
+	if err := ValidateRegulatoryPrice(p); err != nil {
+		return RegulatoryPrice{}, err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return RegulatoryPrice{}, fmt.Errorf("beginning regulatory price tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return RegulatoryPrice{}, err
+	}
+	before, err := loadRegulatoryPrice(ctx, tx, id, true)
+	if err != nil {
+		return RegulatoryPrice{}, err
+	}
+	current, action := 0, "create"
+	var beforeState any
+	if before != nil {
+		current, action, beforeState = before.Version, "update", regulatoryPriceState{Price: before.Price}
+	}
+	if err := ch.check(current); err != nil {
+		return RegulatoryPrice{}, err
+	}
+
+	rp := RegulatoryPrice{Price: p}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO regulatory_price (entity_id, price) VALUES ($1, $2)
+		 ON CONFLICT (entity_id) DO UPDATE
+		 SET price = EXCLUDED.price,
+		     version = regulatory_price.version + 1, updated_at = CURRENT_TIMESTAMP
+		 RETURNING version, updated_at`,
+		id, p).Scan(&rp.Version, &rp.UpdatedAt); err != nil {
+		return RegulatoryPrice{}, fmt.Errorf("saving regulatory price: %w", err)
+	}
+	if err := audit(ctx, tx, ResourceRegulatoryPrice, slug, action, rp.Version, beforeState, regulatoryPriceState{Price: p}, ch.Remote); err != nil {
+		return RegulatoryPrice{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RegulatoryPrice{}, fmt.Errorf("committing regulatory price: %w", err)
+	}
+	return rp, nil
+}
+
+const policyColumns = `asset, source, version, updated_at`
+
+func scanPolicy(row pgx.Row) (PricingPolicy, error) {
+	var p PricingPolicy
+	err := row.Scan(&p.Asset, &p.Source, &p.Version, &p.UpdatedAt)
+	return p, err
+}
+
+// PricingPolicies returns the pricing policies of the entity, by asset.
+func (r *PgRepository) PricingPolicies(ctx context.Context, slug string) ([]PricingPolicy, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+policyColumns+` FROM pricing_policies WHERE entity_id = $1 ORDER BY asset`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing pricing policies: %w", err)
+	}
+	defer rows.Close()
+
+	var out []PricingPolicy
+	for rows.Next() {
+		p, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning pricing policy: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pricing policies: %w", err)
+	}
+	return out, nil
+}
+
+// PricingPolicy returns the pricing policy of one asset of the entity.
+func (r *PgRepository) PricingPolicy(ctx context.Context, slug, asset string) (PricingPolicy, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return PricingPolicy{}, err
+	}
+	p, err := scanPolicy(r.pool.QueryRow(ctx,
+		`SELECT `+policyColumns+` FROM pricing_policies WHERE entity_id = $1 AND asset = $2`, id, asset))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PricingPolicy{}, fmt.Errorf("pricing policy %s: %w", asset, ErrNotFound)
+	}
+	if err != nil {
+		return PricingPolicy{}, fmt.Errorf("loading pricing policy %s: %w", asset, err)
+	}
+	return p, nil
+}
+
+// lockPolicy returns the current policy of asset, nil when there is none,
+// locking the row for the transaction.
+func lockPolicy(ctx context.Context, tx pgx.Tx, entityID int, asset string) (*PricingPolicy, error) {
+	p, err := scanPolicy(tx.QueryRow(ctx,
+		`SELECT `+policyColumns+` FROM pricing_policies WHERE entity_id = $1 AND asset = $2 FOR UPDATE`,
+		entityID, asset))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading pricing policy %s: %w", asset, err)
+	}
+	return &p, nil
+}
+
+// SavePricingPolicy creates or replaces the pricing policy of p.Asset. The
+// next report run prices the asset by it.
+func (r *PgRepository) SavePricingPolicy(ctx context.Context, slug string, p price.Policy, ch Change) (PricingPolicy, error) {
+	if err := ValidatePolicy(p); err != nil {
+		return PricingPolicy{}, err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return PricingPolicy{}, fmt.Errorf("beginning pricing policy tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return PricingPolicy{}, err
+	}
+	before, err := lockPolicy(ctx, tx, id, p.Asset)
+	if err != nil {
+		return PricingPolicy{}, err
+	}
+	current, action := 0, "create"
+	if before != nil {
+		current, action = before.Version, "update"
+	}
+	if err := ch.check(current); err != nil {
+		return PricingPolicy{}, err
+	}
+
+	saved, err := scanPolicy(tx.QueryRow(ctx,
+		`INSERT INTO pricing_policies (entity_id, asset, source) VALUES ($1, $2, $3)
+		 ON CONFLICT (entity_id, asset) DO UPDATE
+		 SET source = EXCLUDED.source,
+		     version = pricing_policies.version + 1, updated_at = CURRENT_TIMESTAMP
+		 RETURNING `+policyColumns,
+		id, p.Asset, p.Source))
+	if err != nil {
+		return PricingPolicy{}, fmt.Errorf("saving pricing policy %s: %w", p.Asset, err)
+	}
+	var beforeState any
+	if before != nil {
+		beforeState = before
+	}
+	if err := audit(ctx, tx, ResourcePricing, slug+"/"+p.Asset, action, saved.Version, beforeState, saved, ch.Remote); err != nil {
+		return PricingPolicy{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PricingPolicy{}, fmt.Errorf("committing pricing policy %s: %w", p.Asset, err)
+	}
+	return saved, nil
+}
+
+// DeletePricingPolicy removes the pricing policy of asset, so it is priced
+// by price.SourceBest again.
+func (r *PgRepository) DeletePricingPolicy(ctx context.Context, slug, asset string, ch Change) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning pricing policy tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return err
+	}
+	before, err := lockPolicy(ctx, tx, id, asset)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return fmt.Errorf("pricing policy %s: %w", asset, ErrNotFound)
+	}
+	if err := ch.check(before.Version); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM pricing_policies WHERE entity_id = $1 AND asset = $2`, id, asset); err != nil {
+		return fmt.Errorf("deleting pricing policy %s: %w", asset, err)
+	}
+	if err := audit(ctx, tx, ResourcePricing, slug+"/"+asset, "delete", before.Version, before, nil, ch.Remote); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing pricing policy %s: %w", asset, err)
+	}
+	return nil
+}
+
+// loadMainIndicators returns the stored IND_MAIN set of the entity, nil
+// when none is stored.
+func loadMainIndicators(ctx context.Context, q querier, entityID int, lock bool) (*MainIndicators, error) {
+	sql := `SELECT ids, version, updated_at FROM main_indicators WHERE entity_id = $1`
+	if lock {
+		sql += ` FOR UPDATE`
+	}
+	var mi MainIndicators
+	err := q.QueryRow(ctx, sql, entityID).Scan(&mi.IDs, &mi.Version, &mi.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading IND_MAIN indicators: %w", err)
+	}
+	return &mi, nil
+}
+
+// MainIndicators returns the IND_MAIN set of the entity: the stored one, or
+// export.DefaultMainIndicatorIDs at version 0.
+func (r *PgRepository) MainIndicators(ctx context.Context, slug string) (MainIndicators, error) {
+	id, err := entityID(ctx, r.pool, slug)
+	if err != nil {
+		return MainIndicators{}, err
+	}
+	mi, err := loadMainIndicators(ctx, r.pool, id, false)
+	if err != nil {
+		return MainIndicators{}, err
+	}
+	if mi == nil {
+		return MainIndicators{IDs: export.DefaultMainIndicatorIDs}, nil
+	}
+	return *mi, nil
+}
+
+// SaveMainIndicators stores the IND_MAIN set of the entity. It applies from
+// the next export.
+func (r *PgRepository) SaveMainIndicators(ctx context.Context, slug string, ids []int, ch Change) (MainIndicators, error) {
+	if err := ValidateMainIndicators(ids); err != nil {
+		return MainIndicators{}, err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return MainIndicators{}, fmt.Errorf("beginning IND_MAIN tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := entityID(ctx, tx, slug)
+	if err != nil {
+		return MainIndicators{}, err
+	}
+	before, err := loadMainIndicators(ctx, tx, id, true)
+	if err != nil {
+		return MainIndicators{}, err
+	}
+	current, action := 0, "create"
+	var beforeState any
+	if before != nil {
+		current, action, beforeState = before.Version, "update", mainIndicatorsState{IDs: before.IDs}
+	}
+	if err := ch.check(current); err != nil {
+		return MainIndicators{}, err
+	}
+
+	mi := MainIndicators{IDs: ids}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO main_indicators (entity_id, ids) VALUES ($1, $2)
+		 ON CONFLICT (entity_id) DO UPDATE
+		 SET ids = EXCLUDED.ids,
+		     version = main_indicators.version + 1, updated_at = CURRENT_TIMESTAMP
+		 RETURNING version, updated_at`,
+		id, ids).Scan(&mi.Version, &mi.UpdatedAt); err != nil {
+		return MainIndicators{}, fmt.Errorf("saving IND_MAIN indicators: %w", err)
+	}
+	if err := audit(ctx, tx, ResourceMainIndicators, slug, action, mi.Version, beforeState, mainIndicatorsState{IDs: ids}, ch.Remote); err != nil {
+		return MainIndicators{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return MainIndicators{}, fmt.Errorf("committing IND_MAIN indicators: %w", err)
+	}
+	return mi, nil
+}
+
+// Audit returns up to limit audit entries, newest first, optionally only
+// for one resource type.
+func (r *PgRepository) Audit(ctx context.Context, resource string, limit int) ([]AuditEntry, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, at, resource, key, action, version, before, after, remote
+		 FROM admin_audit
+		 WHERE $1 = '' OR resource = $1
+		 ORDER BY id DESC
+		 LIMIT $2`, resource, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var out []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.At, &e.Resource, &e.Key, &e.Action, &e.Version, &before, &after, &e.Remote); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		e.Before, e.After = before, after
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating audit entries: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,238 @@
+// Package analytics derives cross-asset statistics from stored history. It
+// reads fund_snapshots and fund_indicators only — like the rest of the serve
+// path it never calls Horizon or price services.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Numeraire is the unit every stored price is quoted in. Its own price series
+// is constant, so its correlations are always undefined (null).
+const Numeraire = "EURMTL"
+
+// DefaultAssets are the holdings compared when no asset list is configured.
+var DefaultAssets = []string{"MTL", "XLM", "BTCMTL", "EURMTL"}
+
+// indicatorPriced maps asset codes the fund does not hold to the indicator
+// carrying their price history — snapshot token scans come back empty for
+// them (see findBTCPrice / I10 notes in CLAUDE.md).
+var indicatorPriced = map[string]int{
+	"MTL": 10,
+}
+
+// snapshotPageSize bounds each ListPage call while walking a window.
+const snapshotPageSize = 100
+
+// SnapshotSource lists stored snapshots.
+type SnapshotSource interface {
+	ListPage(ctx context.Context, slug string, q snapshot.ListQuery) (*snapshot.Page, error)
+}
+
+// IndicatorHistory reads stored indicator time series.
+type IndicatorHistory interface {
+	GetHistory(ctx context.Context, slug string, ids []int, from time.Time) ([]indicator.HistoryPoint, error)
+}
+
+// Matrix holds pairwise return correlations for one window. Correlations[i][j]
+// is nil when the pair has fewer than two overlapping returns or either
+// series is flat; Observations[i][j] is the number of overlapping returns.
+type Matrix struct {
+	WindowDays   int                  `json:"windowDays"`
+	From         string               `json:"from"` // YYYY-MM-DD
+	To           string               `json:"to"`   // YYYY-MM-DD
+	Numeraire    string               `json:"numeraire"`
+	Assets       []string             `json:"assets"`
+	Correlations [][]*decimal.Decimal `json:"correlations"`
+	Observations [][]int              `json:"observations"`
+}
+
+// Service computes correlation matrices for a fixed asset list.
+type Service struct {
+	snapshots  SnapshotSource
+	indicators IndicatorHistory
+	assets     []string
+	slug       string
+}
+
+// NewService creates an analytics Service. assets are token codes ("XLM" for
+// native); an empty list means DefaultAssets.
+func NewService(snapshots SnapshotSource, indicators IndicatorHistory, assets []string) *Service {
+	if len(assets) == 0 {
+		assets = DefaultAssets
+	}
+	return &Service{snapshots: snapshots, indicators: indicators, assets: assets, slug: "mtlf"}
+}
+
+// Correlations computes the correlation matrix of daily simple returns over
+// the windowDays days ending at now (UTC date).
+func (s *Service) Correlations(ctx context.Context, windowDays int, now time.Time) (*Matrix, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -windowDays)
+
+	prices, err := s.loadPrices(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	returns := make([]map[time.Time]decimal.Decimal, len(s.assets))
+	for i, a := range s.assets {
+		returns[i] = dailyReturns(prices[a])
+	}
+
+	n := len(s.assets)
+	m := &Matrix{
+		WindowDays:   windowDays,
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		Numeraire:    Numeraire,
+		Assets:       s.assets,
+		Correlations: make([][]*decimal.Decimal, n),
+		Observations: make([][]int, n),
+	}
+	for i := range n {
+		m.Correlations[i] = make([]*decimal.Decimal, n)
+		m.Observations[i] = make([]int, n)
+	}
+	for i := range n {
+		for j := i; j < n; j++ {
+			xs, ys := pairSeries(returns[i], returns[j])
+			m.Observations[i][j], m.Observations[j][i] = len(xs), len(xs)
+			if r, ok := indicator.Correlation(xs, ys); ok {
+				r = r.Round(4)
+				m.Correlations[i][j], m.Correlations[j][i] = &r, &r
+			}
+		}
+	}
+	return m, nil
+}
+
+// loadPrices returns asset code → snapshot date → price in EURMTL.
+func (s *Service) loadPrices(ctx context.Context, from, to time.Time) (map[string]map[time.Time]decimal.Decimal, error) {
+	prices := make(map[string]map[time.Time]decimal.Decimal, len(s.assets))
+	for _, a := range s.assets {
+		prices[a] = make(map[time.Time]decimal.Decimal)
+	}
+
+	q := snapshot.ListQuery{From: from, To: to, Limit: snapshotPageSize, Ascending: true}
+	for {
+		page, err := s.snapshots.ListPage(ctx, s.slug, q)
+		if err != nil {
+			return nil, fmt.Errorf("listing snapshots: %w", err)
+		}
+		for _, snap := range page.Snapshots {
+			var data domain.FundStructureData
+			if err := json.Unmarshal(snap.Data, &data); err != nil {
+				return nil, fmt.Errorf("decoding snapshot %s: %w", snap.SnapshotDate.Format("2006-01-02"), err)
+			}
+			date := snap.SnapshotDate.UTC()
+			for _, a := range s.assets {
+				if _, ok := indicatorPriced[a]; ok {
+					continue
+				}
+				if p, ok := snapshotPrice(data, a); ok {
+					prices[a][date] = p
+				}
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	var ids []int
+	byID := make(map[int]string)
+	for _, a := range s.assets {
+		if id, ok := indicatorPriced[a]; ok {
+			ids = append(ids, id)
+			byID[id] = a
+		}
+	}
+	if len(ids) > 0 {
+		points, err := s.indicators.GetHistory(ctx, s.slug, ids, from)
+		if err != nil {
+			return nil, fmt.Errorf("loading indicator price history: %w", err)
+		}
+		for _, p := range points {
+			if p.SnapshotDate.After(to) || !p.Value.IsPositive() {
+				continue
+			}
+			prices[byID[p.IndicatorID]][p.SnapshotDate.UTC()] = p.Value
+		}
+	}
+	return prices, nil
+}
+
+// snapshotPrice finds the EURMTL price of code across all snapshot accounts.
+func snapshotPrice(data domain.FundStructureData, code string) (decimal.Decimal, bool) {
+	if code == Numeraire {
+		return decimal.NewFromInt(1), true
+	}
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			if code == "XLM" {
+				if acc.XLMPriceInEURMTL == nil {
+					continue
+				}
+				if p, err := decimal.NewFromString(*acc.XLMPriceInEURMTL); err == nil && p.IsPositive() {
+					return p, true
+				}
+				continue
+			}
+			for _, tok := range acc.Tokens {
+				if tok.Asset.Code != code || tok.PriceInEURMTL == nil {
+					continue
+				}
+				if p, err := decimal.NewFromString(*tok.PriceInEURMTL); err == nil && p.IsPositive() {
+					return p, true
+				}
+			}
+		}
+	}
+	return decimal.Zero, false
+}
+
+// dailyReturns converts a price series into simple returns keyed by the later
+// date of each consecutive pair. Gaps (missing snapshots) are bridged: the
+// return spans the gap rather than being dropped.
+func dailyReturns(prices map[time.Time]decimal.Decimal) map[time.Time]decimal.Decimal {
+	dates := make([]time.Time, 0, len(prices))
+	for d := range prices {
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	out := make(map[time.Time]decimal.Decimal, len(dates))
+	for i := 1; i < len(dates); i++ {
+		prev, cur := prices[dates[i-1]], prices[dates[i]]
+		out[dates[i]] = cur.Sub(prev).DivRound(prev, 16)
+	}
+	return out
+}
+
+// pairSeries aligns two return series on their common dates.
+func pairSeries(a, b map[time.Time]decimal.Decimal) (xs, ys []decimal.Decimal) {
+	dates := make([]time.Time, 0, len(a))
+	for d := range a {
+		if _, ok := b[d]; ok {
+			dates = append(dates, d)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	for _, d := range dates {
+		xs = append(xs, a[d])
+		ys = append(ys, b[d])
+	}
+	return xs, ys
+}
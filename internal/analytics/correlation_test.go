@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+type stubSnapshots struct {
+	snaps   []snapshot.Snapshot
+	queries []snapshot.ListQuery
+}
+
+// ListPage serves one snapshot per page to exercise cursor walking.
+func (s *stubSnapshots) ListPage(_ context.Context, _ string, q snapshot.ListQuery) (*snapshot.Page, error) {
+	s.queries = append(s.queries, q)
+	i := len(s.queries) - 1
+	page := &snapshot.Page{Snapshots: s.snaps[i : i+1], Total: len(s.snaps)}
+	if i+1 < len(s.snaps) {
+		page.NextCursor = "next"
+	}
+	return page, nil
+}
+
+type stubHistory struct {
+	points []indicator.HistoryPoint
+}
+
+func (h *stubHistory) GetHistory(_ context.Context, _ string, _ []int, _ time.Time) ([]indicator.HistoryPoint, error) {
+	return h.points, nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func snapWith(t *testing.T, date time.Time, xlm, btc string) snapshot.Snapshot {
+	t.Helper()
+	data := domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{
+		XLMPriceInEURMTL: strPtr(xlm),
+		Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "BTCMTL", Issuer: "GISSUER"}, Balance: "1", PriceInEURMTL: strPtr(btc)},
+		},
+	}}}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot.Snapshot{SnapshotDate: date, Data: raw}
+}
+
+func TestCorrelations(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	// XLM and BTCMTL move in lockstep; MTL (from I10) moves against them.
+	snaps := &stubSnapshots{snaps: []snapshot.Snapshot{
+		snapWith(t, day(1), "0.10", "60000"),
+		snapWith(t, day(2), "0.11", "66000"),
+		snapWith(t, day(3), "0.10", "60000"),
+		snapWith(t, day(4), "0.12", "72000"),
+	}}
+	hist := &stubHistory{points: []indicator.HistoryPoint{
+		{SnapshotDate: day(1), IndicatorID: 10, Value: decimal.RequireFromString("2.0")},
+		{SnapshotDate: day(2), IndicatorID: 10, Value: decimal.RequireFromString("1.8")},
+		{SnapshotDate: day(3), IndicatorID: 10, Value: decimal.RequireFromString("2.0")},
+		{SnapshotDate: day(4), IndicatorID: 10, Value: decimal.RequireFromString("1.6")},
+	}}
+
+	svc := NewService(snaps, hist, nil)
+	m, err := svc.Correlations(context.Background(), 30, day(4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(snaps.queries) != 4 || snaps.queries[1].Cursor != "next" || !snaps.queries[0].Ascending {
+		t.Errorf("snapshot queries = %+v, want 4 ascending pages following the cursor", snaps.queries)
+	}
+	if m.From != "2026-02-02" || m.To != "2026-03-04" {
+		t.Errorf("window = %s..%s", m.From, m.To)
+	}
+
+	idx := map[string]int{}
+	for i, a := range m.Assets {
+		idx[a] = i
+	}
+	if r := m.Correlations[idx["XLM"]][idx["BTCMTL"]]; r == nil || !r.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("corr(XLM, BTCMTL) = %v, want 1", r)
+	}
+	if r := m.Correlations[idx["MTL"]][idx["XLM"]]; r == nil || !r.IsNegative() {
+		t.Errorf("corr(MTL, XLM) = %v, want negative", r)
+	}
+	if r := m.Correlations[idx["EURMTL"]][idx["XLM"]]; r != nil {
+		t.Errorf("corr(EURMTL, XLM) = %v, want nil (numeraire is flat)", r)
+	}
+	if got := m.Observations[idx["MTL"]][idx["BTCMTL"]]; got != 3 {
+		t.Errorf("observations = %d, want 3", got)
+	}
+}
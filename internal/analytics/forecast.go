@@ -0,0 +1,266 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// Dividend forecasting works on the stored dividend ledger: I11 (Monthly
+// Dividends) as written daily by the report pipeline and by backfill-divs.
+// Each calendar month is represented by its last stored I11 value.
+const (
+	monthlyDividendsID = 11
+	totalSharesID      = 5
+	sharePriceID       = 10
+)
+
+// ForecastMethod selects how next month's dividends are predicted.
+type ForecastMethod string
+
+const (
+	// ForecastMovingAverage predicts the mean of the last Window months.
+	ForecastMovingAverage ForecastMethod = "moving-average"
+	// ForecastSeasonalNaive predicts the value of the same month a year earlier.
+	ForecastSeasonalNaive ForecastMethod = "seasonal-naive"
+)
+
+// DefaultForecastWindow is the moving-average window in months.
+const DefaultForecastWindow = 6
+
+// DefaultForecastConfidence is the coverage of the forecast bounds.
+var DefaultForecastConfidence = decimal.RequireFromString("0.9")
+
+// forecastZ holds the two-sided standard normal quantile per supported
+// confidence level.
+var forecastZ = map[string]decimal.Decimal{
+	"0.8":  decimal.RequireFromString("1.2815515655446004"),
+	"0.9":  decimal.RequireFromString("1.6448536269514722"),
+	"0.95": decimal.RequireFromString("1.959963984540054"),
+}
+
+// ErrInvalidForecast is returned for an unknown method, window or confidence.
+var ErrInvalidForecast = errors.New("invalid forecast parameters")
+
+// ErrInsufficientHistory is returned when the ledger is too short for the
+// requested method.
+var ErrInsufficientHistory = errors.New("not enough dividend history")
+
+// ForecastOptions parameterises DividendForecast. Zero values mean defaults.
+type ForecastOptions struct {
+	Method     ForecastMethod
+	Window     int             // moving-average months
+	Confidence decimal.Decimal // 0.8, 0.9 or 0.95
+}
+
+func (o ForecastOptions) withDefaults() (ForecastOptions, error) {
+	if o.Method == "" {
+		o.Method = ForecastMovingAverage
+	}
+	if o.Method != ForecastMovingAverage && o.Method != ForecastSeasonalNaive {
+		return o, fmt.Errorf("%w: unknown method %q, valid: moving-average, seasonal-naive", ErrInvalidForecast, o.Method)
+	}
+	if o.Window == 0 {
+		o.Window = DefaultForecastWindow
+	}
+	if o.Window < 2 || o.Window > 36 {
+		return o, fmt.Errorf("%w: window must be 2-36 months, got %d", ErrInvalidForecast, o.Window)
+	}
+	if o.Confidence.IsZero() {
+		o.Confidence = DefaultForecastConfidence
+	}
+	if _, ok := forecastZ[o.Confidence.String()]; !ok {
+		return o, fmt.Errorf("%w: unsupported confidence %s, valid: 0.8, 0.9, 0.95", ErrInvalidForecast, o.Confidence)
+	}
+	return o, nil
+}
+
+// MonthlyValue is one month of the dividend ledger.
+type MonthlyValue struct {
+	Month string          `json:"month"` // YYYY-MM
+	Value decimal.Decimal `json:"value"`
+}
+
+// Estimate is a point forecast with its confidence bounds.
+type Estimate struct {
+	Value decimal.Decimal `json:"value"`
+	Lower decimal.Decimal `json:"lower"`
+	Upper decimal.Decimal `json:"upper"`
+}
+
+// DividendForecast predicts next month's dividends and the annual dividend
+// yield they imply at the current share price.
+type DividendForecast struct {
+	Method     ForecastMethod  `json:"method"`
+	Window     int             `json:"window,omitempty"` // moving-average only
+	Confidence decimal.Decimal `json:"confidence"`
+	Month      string          `json:"month"` // forecast month, YYYY-MM
+	// MonthlyDividends forecasts I11 for Month, in EURMTL.
+	MonthlyDividends Estimate `json:"monthlyDividends"`
+	// AnnualYield is MonthlyDividends × 12 per share (I5) over the share
+	// price (I10), in percent. Absent without stored I5 and I10.
+	AnnualYield *Estimate      `json:"annualYield,omitempty"`
+	History     []MonthlyValue `json:"history"` // months the forecast was fitted on
+}
+
+// ForecastService forecasts from stored indicator history.
+type ForecastService struct {
+	indicators IndicatorHistory
+	slug       string
+}
+
+// NewForecastService creates a ForecastService.
+func NewForecastService(indicators IndicatorHistory) *ForecastService {
+	return &ForecastService{indicators: indicators, slug: "mtlf"}
+}
+
+// DividendForecast forecasts the month after the latest month with stored
+// I11. Only history at or before now is used.
+func (s *ForecastService) DividendForecast(ctx context.Context, opts ForecastOptions, now time.Time) (*DividendForecast, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.indicators.GetHistory(ctx, s.slug, []int{monthlyDividendsID, totalSharesID, sharePriceID}, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("loading dividend history: %w", err)
+	}
+
+	var dividends []indicator.HistoryPoint
+	var shares, price decimal.Decimal
+	for _, p := range points {
+		if p.SnapshotDate.After(now) {
+			continue
+		}
+		switch p.IndicatorID {
+		case monthlyDividendsID:
+			dividends = append(dividends, p)
+		case totalSharesID:
+			shares = p.Value
+		case sharePriceID:
+			price = p.Value
+		}
+	}
+
+	f, err := ForecastDividends(MonthlySeries(dividends), opts)
+	if err != nil {
+		return nil, err
+	}
+	if shares.IsPositive() && price.IsPositive() {
+		y := annualYield(f.MonthlyDividends, shares, price)
+		f.AnnualYield = &y
+	}
+	return f, nil
+}
+
+// MonthlySeries reduces daily I11 points (ordered by date) to the last value
+// of each calendar month. Months without a stored value are absent.
+func MonthlySeries(points []indicator.HistoryPoint) []MonthlyValue {
+	var out []MonthlyValue
+	for _, p := range points {
+		month := p.SnapshotDate.UTC().Format("2006-01")
+		if n := len(out); n > 0 && out[n-1].Month == month {
+			out[n-1].Value = p.Value
+			continue
+		}
+		out = append(out, MonthlyValue{Month: month, Value: p.Value})
+	}
+	return out
+}
+
+// ForecastDividends forecasts the month after the last entry of history
+// (ordered, one entry per month). Bounds are the point ± z·σ, where σ is the
+// spread of the window for the moving average and of the year-over-year
+// differences for the seasonal naive method; the lower bound is floored at
+// zero.
+func ForecastDividends(history []MonthlyValue, opts ForecastOptions) (*DividendForecast, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: no stored I11", ErrInsufficientHistory)
+	}
+	last, err := time.Parse("2006-01", history[len(history)-1].Month)
+	if err != nil {
+		return nil, fmt.Errorf("parsing month %q: %w", history[len(history)-1].Month, err)
+	}
+	target := last.AddDate(0, 1, 0)
+
+	f := &DividendForecast{Method: opts.Method, Confidence: opts.Confidence, Month: target.Format("2006-01")}
+	z := forecastZ[opts.Confidence.String()]
+
+	var point, spread decimal.Decimal
+	switch opts.Method {
+	case ForecastMovingAverage:
+		if len(history) < opts.Window {
+			return nil, fmt.Errorf("%w: moving average over %d months needs %d months, have %d",
+				ErrInsufficientHistory, opts.Window, opts.Window, len(history))
+		}
+		window := history[len(history)-opts.Window:]
+		values := make([]decimal.Decimal, len(window))
+		for i, m := range window {
+			values[i] = m.Value
+		}
+		point = indicator.Mean(values)
+		// Prediction interval of a mean model: the next value deviates from
+		// the window mean by σ·√(1 + 1/n).
+		scale := decimal.NewFromFloat(math.Sqrt(1 + 1/float64(opts.Window)))
+		spread = indicator.StdDev(values).Mul(scale)
+		f.Window = opts.Window
+		f.History = window
+
+	case ForecastSeasonalNaive:
+		byMonth := make(map[string]decimal.Decimal, len(history))
+		for _, m := range history {
+			byMonth[m.Month] = m.Value
+		}
+		seasonal, ok := byMonth[target.AddDate(-1, 0, 0).Format("2006-01")]
+		if !ok {
+			return nil, fmt.Errorf("%w: seasonal naive needs I11 for %s", ErrInsufficientHistory, target.AddDate(-1, 0, 0).Format("2006-01"))
+		}
+		var diffs []decimal.Decimal
+		var used []MonthlyValue
+		for _, m := range history {
+			t, err := time.Parse("2006-01", m.Month)
+			if err != nil {
+				return nil, fmt.Errorf("parsing month %q: %w", m.Month, err)
+			}
+			if prev, ok := byMonth[t.AddDate(-1, 0, 0).Format("2006-01")]; ok {
+				diffs = append(diffs, m.Value.Sub(prev))
+				used = append(used, m)
+			}
+		}
+		if len(diffs) < 2 {
+			return nil, fmt.Errorf("%w: seasonal naive bounds need two year-over-year pairs, have %d", ErrInsufficientHistory, len(diffs))
+		}
+		point = seasonal
+		spread = indicator.StdDev(diffs)
+		f.History = used
+	}
+
+	margin := z.Mul(spread)
+	f.MonthlyDividends = Estimate{
+		Value: point.Round(2),
+		Lower: decimal.Max(point.Sub(margin), decimal.Zero).Round(2),
+		Upper: point.Add(margin).Round(2),
+	}
+	return f, nil
+}
+
+// annualYield converts a monthly dividends estimate into annual dividend
+// yield percent: × 12 / shares / price × 100.
+func annualYield(m Estimate, shares, price decimal.Decimal) Estimate {
+	factor := decimal.NewFromInt(1200).Div(shares.Mul(price))
+	return Estimate{
+		Value: m.Value.Mul(factor).Round(2),
+		Lower: m.Lower.Mul(factor).Round(2),
+		Upper: m.Upper.Mul(factor).Round(2),
+	}
+}
@@ -0,0 +1,121 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func monthly(start string, values ...string) []MonthlyValue {
+	t, _ := time.Parse("2006-01", start)
+	out := make([]MonthlyValue, len(values))
+	for i, v := range values {
+		out[i] = MonthlyValue{Month: t.AddDate(0, i, 0).Format("2006-01"), Value: decimal.RequireFromString(v)}
+	}
+	return out
+}
+
+func TestMonthlySeriesKeepsLastValueOfMonth(t *testing.T) {
+	day := func(s string) time.Time { d, _ := time.Parse("2006-01-02", s); return d }
+	got := MonthlySeries([]indicator.HistoryPoint{
+		{SnapshotDate: day("2026-01-05"), IndicatorID: 11, Value: decimal.NewFromInt(100)},
+		{SnapshotDate: day("2026-01-31"), IndicatorID: 11, Value: decimal.NewFromInt(120)},
+		{SnapshotDate: day("2026-03-01"), IndicatorID: 11, Value: decimal.NewFromInt(90)},
+	})
+	want := monthly("2026-01", "120")
+	want = append(want, MonthlyValue{Month: "2026-03", Value: decimal.NewFromInt(90)})
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("MonthlySeries = %v, want %v", got, want)
+	}
+}
+
+func TestForecastMovingAverage(t *testing.T) {
+	f, err := ForecastDividends(monthly("2026-01", "100", "200", "100", "200"), ForecastOptions{Window: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Month != "2026-05" || f.Window != 4 || len(f.History) != 4 {
+		t.Errorf("forecast = %+v", f)
+	}
+	// mean 150, σ = 57.735, × √1.25 × 1.6449 = 106.17
+	if got := f.MonthlyDividends; !got.Value.Equal(decimal.NewFromInt(150)) ||
+		!got.Lower.Equal(decimal.RequireFromString("43.83")) || !got.Upper.Equal(decimal.RequireFromString("256.17")) {
+		t.Errorf("estimate = %+v", got)
+	}
+}
+
+func TestForecastSeasonalNaive(t *testing.T) {
+	// Two years: the second is the first plus 10, then plus 30 in its last month.
+	history := monthly("2025-01", "100", "50", "100", "50", "100", "50", "100", "50", "100", "50", "100", "50",
+		"110", "60", "110", "60", "110", "60", "110", "60", "110", "60", "110", "80")
+	f, err := ForecastDividends(history, ForecastOptions{Method: ForecastSeasonalNaive, Confidence: decimal.RequireFromString("0.95")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Month != "2027-01" || f.Window != 0 || len(f.History) != 12 {
+		t.Errorf("forecast = %+v", f)
+	}
+	if !f.MonthlyDividends.Value.Equal(decimal.NewFromInt(110)) {
+		t.Errorf("point = %s, want January 2026 value 110", f.MonthlyDividends.Value)
+	}
+	if !f.MonthlyDividends.Lower.LessThan(f.MonthlyDividends.Value) || !f.MonthlyDividends.Upper.GreaterThan(f.MonthlyDividends.Value) {
+		t.Errorf("bounds = %+v, want around the point", f.MonthlyDividends)
+	}
+}
+
+func TestForecastLowerBoundNotNegative(t *testing.T) {
+	f, err := ForecastDividends(monthly("2026-01", "0", "300"), ForecastOptions{Window: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.MonthlyDividends.Lower.IsZero() {
+		t.Errorf("lower = %s, want 0", f.MonthlyDividends.Lower)
+	}
+}
+
+func TestForecastErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		history []MonthlyValue
+		opts    ForecastOptions
+		want    error
+	}{
+		"unknown method":    {monthly("2026-01", "1", "2"), ForecastOptions{Method: "arima"}, ErrInvalidForecast},
+		"window too small":  {monthly("2026-01", "1", "2"), ForecastOptions{Window: 1}, ErrInvalidForecast},
+		"bad confidence":    {monthly("2026-01", "1", "2"), ForecastOptions{Confidence: decimal.RequireFromString("0.5")}, ErrInvalidForecast},
+		"empty":             {nil, ForecastOptions{}, ErrInsufficientHistory},
+		"short for window":  {monthly("2026-01", "1", "2"), ForecastOptions{Window: 3}, ErrInsufficientHistory},
+		"no year-ago month": {monthly("2026-01", "1", "2", "3"), ForecastOptions{Method: ForecastSeasonalNaive}, ErrInsufficientHistory},
+	} {
+		if _, err := ForecastDividends(tc.history, tc.opts); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+func TestDividendForecastAnnualYield(t *testing.T) {
+	day := func(s string) time.Time { d, _ := time.Parse("2006-01-02", s); return d }
+	hist := &stubHistory{points: []indicator.HistoryPoint{
+		{SnapshotDate: day("2026-08-31"), IndicatorID: 5, Value: decimal.NewFromInt(1000)},
+		{SnapshotDate: day("2026-08-31"), IndicatorID: 10, Value: decimal.NewFromInt(6)},
+		{SnapshotDate: day("2026-08-31"), IndicatorID: 11, Value: decimal.NewFromInt(40)},
+		{SnapshotDate: day("2026-09-30"), IndicatorID: 11, Value: decimal.NewFromInt(60)},
+		{SnapshotDate: day("2026-10-20"), IndicatorID: 11, Value: decimal.NewFromInt(500)}, // after now
+	}}
+	f, err := NewForecastService(hist).DividendForecast(context.Background(), ForecastOptions{Window: 2}, day("2026-10-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Month != "2026-10" || !f.MonthlyDividends.Value.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("forecast = %+v", f)
+	}
+	// 50 × 12 / 1000 shares / 6 EURMTL × 100 = 10%
+	if f.AnnualYield == nil || !f.AnnualYield.Value.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("annual yield = %+v, want 10", f.AnnualYield)
+	}
+}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// FundName labels the fund's own row in a peer comparison.
+const FundName = "MTLF"
+
+// PeerRow is one treasury in a peer comparison. Ratio fields compare the row
+// against the fund and are nil on the fund's own row or when the fund figure
+// is zero.
+type PeerRow struct {
+	Name          string           `json:"name"`
+	Address       string           `json:"address,omitempty"`
+	TotalEURMTL   decimal.Decimal  `json:"totalEURMTL"`
+	TokenCount    int              `json:"tokenCount"`
+	HolderCount   int              `json:"holderCount"`
+	ValueVsFund   *decimal.Decimal `json:"valueVsFund,omitempty"` // TotalEURMTL / fund TotalEURMTL
+	HoldersVsFund *decimal.Decimal `json:"holdersVsFund,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+// PeerComparison puts the fund next to the peers captured in one snapshot.
+type PeerComparison struct {
+	Date  string    `json:"date"` // YYYY-MM-DD
+	Fund  PeerRow   `json:"fund"`
+	Peers []PeerRow `json:"peers"`
+}
+
+// ComparePeers builds the comparison from a stored snapshot. The fund row uses
+// the aggregated totals (main accounts only) and I62 shareholders from live
+// metrics. Peers that failed to load keep their error and get no ratios.
+func ComparePeers(date string, data domain.FundStructureData) PeerComparison {
+	fund := PeerRow{
+		Name:        FundName,
+		TotalEURMTL: data.AggregatedTotals.TotalEURMTL,
+		TokenCount:  data.AggregatedTotals.TokenCount,
+	}
+	if data.LiveMetrics != nil && data.LiveMetrics.MTLShareholdersAny != nil {
+		if n, err := decimal.NewFromString(*data.LiveMetrics.MTLShareholdersAny); err == nil {
+			fund.HolderCount = int(n.IntPart())
+		}
+	}
+
+	out := PeerComparison{Date: date, Fund: fund, Peers: make([]PeerRow, 0, len(data.Peers))}
+	for _, p := range data.Peers {
+		row := PeerRow{
+			Name:        p.Name,
+			Address:     p.Address,
+			TotalEURMTL: p.TotalEURMTL,
+			TokenCount:  p.TokenCount,
+			HolderCount: p.HolderCount,
+			Error:       p.Error,
+		}
+		if p.Error == "" {
+			row.ValueVsFund = ratio(p.TotalEURMTL, fund.TotalEURMTL)
+			row.HoldersVsFund = ratio(decimal.NewFromInt(int64(p.HolderCount)), decimal.NewFromInt(int64(fund.HolderCount)))
+		}
+		out.Peers = append(out.Peers, row)
+	}
+	return out
+}
+
+func ratio(a, b decimal.Decimal) *decimal.Decimal {
+	if b.IsZero() {
+		return nil
+	}
+	r := a.DivRound(b, 4)
+	return &r
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestComparePeers(t *testing.T) {
+	holders := "200"
+	data := domain.FundStructureData{
+		AggregatedTotals: domain.AggregatedTotals{TotalEURMTL: decimal.NewFromInt(1000), TokenCount: 25},
+		LiveMetrics:      &domain.FundLiveMetrics{MTLShareholdersAny: &holders},
+		Peers: []domain.PeerMetrics{
+			{Name: "A", Address: "GA", TotalEURMTL: decimal.NewFromInt(250), TokenCount: 4, HolderCount: 50},
+			{Name: "B", Address: "GB", Error: "timeout"},
+		},
+	}
+
+	c := ComparePeers("2026-10-01", data)
+
+	if c.Fund.Name != FundName || c.Fund.HolderCount != 200 || c.Fund.TokenCount != 25 {
+		t.Errorf("fund row = %+v", c.Fund)
+	}
+	if len(c.Peers) != 2 {
+		t.Fatalf("got %d peers, want 2", len(c.Peers))
+	}
+	a := c.Peers[0]
+	if a.ValueVsFund == nil || !a.ValueVsFund.Equal(decimal.RequireFromString("0.25")) {
+		t.Errorf("A value ratio = %v, want 0.25", a.ValueVsFund)
+	}
+	if a.HoldersVsFund == nil || !a.HoldersVsFund.Equal(decimal.RequireFromString("0.25")) {
+		t.Errorf("A holder ratio = %v, want 0.25", a.HoldersVsFund)
+	}
+	if b := c.Peers[1]; b.ValueVsFund != nil || b.Error != "timeout" {
+		t.Errorf("failed peer = %+v, want error and no ratios", b)
+	}
+}
+
+func TestComparePeersNoFundHolders(t *testing.T) {
+	data := domain.FundStructureData{
+		AggregatedTotals: domain.AggregatedTotals{TotalEURMTL: decimal.NewFromInt(1000)},
+		Peers:            []domain.PeerMetrics{{Name: "A", TotalEURMTL: decimal.NewFromInt(10), HolderCount: 5}},
+	}
+
+	c := ComparePeers("2026-10-01", data)
+
+	if c.Peers[0].HoldersVsFund != nil {
+		t.Error("holder ratio must be nil without fund holder count")
+	}
+	if c.Peers[0].ValueVsFund == nil {
+		t.Error("value ratio should still be set")
+	}
+}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// ValuationRow is one token priced by a manual valuation in a snapshot: the
+// DATA entry that applied, the external quote it was resolved with (if any),
+// and the resulting price and value.
+type ValuationRow struct {
+	Account       string                `json:"account"`
+	Asset         domain.AssetInfo      `json:"asset"`
+	Balance       string                `json:"balance"`
+	ValuationType domain.ValuationType  `json:"valuationType"`
+	RawValue      domain.ValuationValue `json:"rawValue"`
+	SourceAccount string                `json:"sourceAccount"`
+	PriceInEURMTL *string               `json:"priceInEURMTL"`
+	ValueInEURMTL *string               `json:"valueInEURMTL"`
+	Quote         *domain.QuoteUsage    `json:"quote,omitempty"`
+}
+
+// ValuationExplanation lists the manual valuations and external quotes a
+// snapshot was computed with.
+type ValuationExplanation struct {
+	Date       string              `json:"date"` // YYYY-MM-DD
+	Quotes     []domain.QuoteUsage `json:"quotes"`
+	Valuations []ValuationRow      `json:"valuations"`
+}
+
+// ExplainValuations extracts the valuation audit trail from a stored snapshot,
+// across main, mutual and other accounts. token filters by asset code when
+// non-empty. Snapshots taken before valuations were recorded yield empty lists.
+func ExplainValuations(date, token string, data domain.FundStructureData) ValuationExplanation {
+	out := ValuationExplanation{Date: date, Quotes: data.Quotes, Valuations: []ValuationRow{}}
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			for _, t := range acc.Tokens {
+				if t.Valuation == nil || (token != "" && t.Asset.Code != token) {
+					continue
+				}
+				out.Valuations = append(out.Valuations, ValuationRow{
+					Account:       acc.Name,
+					Asset:         t.Asset,
+					Balance:       t.Balance,
+					ValuationType: t.Valuation.ValuationType,
+					RawValue:      t.Valuation.RawValue,
+					SourceAccount: t.Valuation.SourceAccount,
+					PriceInEURMTL: t.PriceInEURMTL,
+					ValueInEURMTL: t.ValueInEURMTL,
+					Quote:         t.Valuation.Quote,
+				})
+			}
+		}
+	}
+	if token != "" {
+		quotes := make([]domain.QuoteUsage, 0)
+		seen := make(map[string]bool)
+		for _, v := range out.Valuations {
+			if v.Quote != nil && !seen[v.Quote.Symbol] {
+				seen[v.Quote.Symbol] = true
+				quotes = append(quotes, *v.Quote)
+			}
+		}
+		sort.Slice(quotes, func(i, j int) bool { return quotes[i].Symbol < quotes[j].Symbol })
+		out.Quotes = quotes
+	}
+	if out.Quotes == nil {
+		out.Quotes = []domain.QuoteUsage{}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestExplainValuations(t *testing.T) {
+	fetched := time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC)
+	btc := &domain.QuoteUsage{Symbol: "BTC", PriceInEUR: decimal.NewFromInt(60000), FetchedAt: fetched}
+	price := "60000"
+	data := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{Name: "MAIN", Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "WBTC"}, Balance: "0.5", PriceInEURMTL: &price, Valuation: &domain.ResolvedAssetValuation{
+				AssetValuation: domain.AssetValuation{TokenCode: "WBTC", ValuationType: domain.ValuationTypeUnit, RawValue: domain.ValuationValue{Type: domain.ValuationValueExternal, Symbol: "BTC"}},
+				ValueInEURMTL:  price,
+				Quote:          btc,
+			}},
+			{Asset: domain.AssetInfo{Code: "MTL"}, Balance: "10"},
+		}}},
+		MutualFunds: []domain.FundAccountPortfolio{{Name: "MFB", Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "FLAT"}, Balance: "1", Valuation: &domain.ResolvedAssetValuation{
+				AssetValuation: domain.AssetValuation{TokenCode: "FLAT", ValuationType: domain.ValuationTypeNFT, RawValue: domain.ValuationValue{Type: domain.ValuationValueEURMTL, Value: "5000"}},
+				ValueInEURMTL:  "5000",
+			}},
+		}}},
+		Quotes: []domain.QuoteUsage{*btc},
+	}
+
+	e := ExplainValuations("2026-10-01", "", data)
+	if len(e.Valuations) != 2 || len(e.Quotes) != 1 {
+		t.Fatalf("got %d valuations and %d quotes, want 2 and 1", len(e.Valuations), len(e.Quotes))
+	}
+	if v := e.Valuations[0]; v.Account != "MAIN" || v.Quote == nil || !v.Quote.FetchedAt.Equal(fetched) {
+		t.Errorf("WBTC row = %+v, want BTC quote fetched at %s", v, fetched)
+	}
+
+	e = ExplainValuations("2026-10-01", "FLAT", data)
+	if len(e.Valuations) != 1 || e.Valuations[0].Account != "MFB" || len(e.Quotes) != 0 {
+		t.Errorf("FLAT filter = %+v, want the mutual fund row and no quotes", e)
+	}
+}
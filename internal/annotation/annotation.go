@@ -0,0 +1,72 @@
+// Package annotation stores free-form notes attached to a snapshot date, such
+// as "DEFI received EUR 200k tranche" or "valuation methodology change". They
+// are returned alongside indicator comparisons and written to the MONITORING
+// Notes column, so anomalous data points are explained in-band.
+package annotation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits enforced by Validate.
+const (
+	MaxTextLength = 500
+	MaxTags       = 10
+)
+
+var (
+	// ErrNotFound indicates the annotation doesn't exist.
+	ErrNotFound = errors.New("annotation not found")
+	// ErrInvalid indicates an annotation that fails validation.
+	ErrInvalid = errors.New("invalid annotation")
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Annotation is a note on one snapshot date.
+type Annotation struct {
+	ID        int64     `json:"id"`
+	Date      time.Time `json:"date" swaggertype:"string" example:"2026-10-01"`
+	Text      string    `json:"text"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate checks that the date and text are set and the tags are short
+// lowercase slugs.
+func (a Annotation) Validate() error {
+	if a.Date.IsZero() {
+		return fmt.Errorf("%w: date is required", ErrInvalid)
+	}
+	if n := utf8.RuneCountInString(strings.TrimSpace(a.Text)); n == 0 || n > MaxTextLength {
+		return fmt.Errorf("%w: text must be 1 to %d characters", ErrInvalid, MaxTextLength)
+	}
+	if len(a.Tags) > MaxTags {
+		return fmt.Errorf("%w: at most %d tags", ErrInvalid, MaxTags)
+	}
+	for _, tag := range a.Tags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("%w: tag %q must be lowercase letters, digits and dashes (at most 32)", ErrInvalid, tag)
+		}
+	}
+	return nil
+}
+
+// Note renders annotations as one line for the MONITORING Notes column,
+// e.g. "DEFI received EUR 200k tranche [defi]; valuation methodology change".
+func Note(annotations []Annotation) string {
+	parts := make([]string, 0, len(annotations))
+	for _, a := range annotations {
+		part := a.Text
+		if len(a.Tags) > 0 {
+			part += " [" + strings.Join(a.Tags, ", ") + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
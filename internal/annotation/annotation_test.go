@@ -0,0 +1,51 @@
+package annotation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Annotation
+		ok   bool
+	}{
+		{"valid", Annotation{Date: day, Text: "DEFI received EUR 200k tranche", Tags: []string{"defi", "tranche-2"}}, true},
+		{"no tags", Annotation{Date: day, Text: "valuation methodology change"}, true},
+		{"no date", Annotation{Text: "note"}, false},
+		{"blank text", Annotation{Date: day, Text: "  "}, false},
+		{"long text", Annotation{Date: day, Text: strings.Repeat("x", MaxTextLength+1)}, false},
+		{"bad tag", Annotation{Date: day, Text: "note", Tags: []string{"DeFi"}}, false},
+		{"too many tags", Annotation{Date: day, Text: "note", Tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.a.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalid) {
+				t.Errorf("Validate() = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestNote(t *testing.T) {
+	got := Note([]Annotation{
+		{Text: "DEFI received EUR 200k tranche", Tags: []string{"defi", "tranche"}},
+		{Text: "valuation methodology change"},
+	})
+	want := "DEFI received EUR 200k tranche [defi, tranche]; valuation methodology change"
+	if got != want {
+		t.Errorf("Note() = %q, want %q", got, want)
+	}
+	if got := Note(nil); got != "" {
+		t.Errorf("Note(nil) = %q, want empty", got)
+	}
+}
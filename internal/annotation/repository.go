@@ -0,0 +1,91 @@
+package annotation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores annotations in snapshot_annotations.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL annotation repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Add validates and stores a, and returns it with its ID and creation time.
+func (r *PgRepository) Add(ctx context.Context, slug string, a Annotation) (Annotation, error) {
+	if err := a.Validate(); err != nil {
+		return Annotation{}, err
+	}
+	if a.Tags == nil {
+		a.Tags = []string{}
+	}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO snapshot_annotations (entity_id, snapshot_date, text, tags)
+		 SELECT id, $2, $3, $4 FROM fund_entities WHERE slug = $1
+		 RETURNING id, created_at`,
+		slug, a.Date, a.Text, a.Tags).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return Annotation{}, fmt.Errorf("saving annotation: %w", err)
+	}
+	return a, nil
+}
+
+// List returns the annotations on dates between from and to (inclusive;
+// zero means unbounded), oldest first.
+func (r *PgRepository) List(ctx context.Context, slug string, from, to time.Time) ([]Annotation, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT sa.id, sa.snapshot_date, sa.text, sa.tags, sa.created_at
+		 FROM snapshot_annotations sa
+		 JOIN fund_entities fe ON fe.id = sa.entity_id
+		 WHERE fe.slug = $1
+		   AND ($2::date IS NULL OR sa.snapshot_date >= $2)
+		   AND ($3::date IS NULL OR sa.snapshot_date <= $3)
+		 ORDER BY sa.snapshot_date, sa.id`,
+		slug, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("listing annotations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Annotation{}
+	for rows.Next() {
+		var a Annotation
+		if err := rows.Scan(&a.ID, &a.Date, &a.Text, &a.Tags, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning annotation: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating annotations: %w", err)
+	}
+	return out, nil
+}
+
+// Delete removes the annotation id, or returns ErrNotFound.
+func (r *PgRepository) Delete(ctx context.Context, slug string, id int64) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM snapshot_annotations sa
+		 USING fund_entities fe
+		 WHERE fe.id = sa.entity_id AND fe.slug = $1 AND sa.id = $2`, slug, id)
+	if err != nil {
+		return fmt.Errorf("deleting annotation %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/accountmeta"
+)
+
+// AccountMetadataSource reads stored account metadata events.
+type AccountMetadataSource interface {
+	List(ctx context.Context, slug string, from, to time.Time) ([]accountmeta.Event, error)
+}
+
+// AccountMetadataHandler serves the metadata events of fund accounts.
+type AccountMetadataHandler struct {
+	source AccountMetadataSource
+}
+
+// NewAccountMetadataHandler creates a new account metadata handler.
+func NewAccountMetadataHandler(source AccountMetadataSource) *AccountMetadataHandler {
+	return &AccountMetadataHandler{source: source}
+}
+
+// GetAccountMetadataEvents handles GET /api/v1/accounts/metadata/events.
+//
+// @Summary      Fund account metadata changes
+// @Description  Changes of what the fund accounts publish about themselves on chain, between consecutive snapshots, oldest first. `field` is `home_domain` or a DATA entry key from ACCOUNT_METADATA_KEYS; `kind` is added, changed or removed, with the `old` and `new` values (DATA values decoded, base64 when not UTF-8). The current values are in `accountConfigs[].metadata` of each snapshot.
+// @Tags         accounts
+// @Produce      json
+// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {array}   accountmeta.Event
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/accounts/metadata/events [get]
+func (h *AccountMetadataHandler) GetAccountMetadataEvents(w http.ResponseWriter, r *http.Request) {
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	events, err := h.source.List(r.Context(), fundSlug, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to list account metadata events", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if events == nil {
+		events = []accountmeta.Event{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
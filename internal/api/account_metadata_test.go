@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/accountmeta"
+)
+
+type stubAccountMeta struct {
+	events []accountmeta.Event
+	from   time.Time
+}
+
+func (s *stubAccountMeta) List(_ context.Context, _ string, from, _ time.Time) ([]accountmeta.Event, error) {
+	s.from = from
+	return s.events, nil
+}
+
+func TestGetAccountMetadataEvents(t *testing.T) {
+	src := &stubAccountMeta{events: []accountmeta.Event{{
+		Name: "MAIN", Field: accountmeta.HomeDomainField, Kind: accountmeta.KindChanged, Old: "montelibero.org", New: "mtlf.me",
+	}}}
+	srv := NewServer("0", nil, nil, WithAccountMetadata(src))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/metadata/events?range=30d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got []accountmeta.Event
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].New != "mtlf.me" {
+		t.Errorf("events = %+v", got)
+	}
+	if days := time.Since(src.from).Hours() / 24; days < 29 || days > 31 {
+		t.Errorf("from = %s, want about a month ago", src.from)
+	}
+
+	srv = NewServer("0", nil, nil, WithAccountMetadata(&stubAccountMeta{}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/metadata/events", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q, want 200 []", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/metadata/events?range=7w", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad range: status = %d, want 400", w.Code)
+	}
+}
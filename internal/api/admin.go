@@ -0,0 +1,259 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/transport"
+)
+
+// Admin configures the diagnostics, configuration and API key endpoints.
+// Nothing is mounted without a Token: holding it is the admin role.
+type Admin struct {
+	Token       string
+	Pprof       bool               // also mount /debug/pprof/
+	Pipeline    PipelineStats      // nil when serve runs no generate pipeline
+	Upstream    UpstreamStats      // shared HTTP transport; nil omits its counters
+	Index       IndexConfigStore   // mounts /api/v1/admin/index when set
+	Keys        KeyStore           // mounts /api/v1/admin/keys when set
+	Entities    EntityStore        // mounts /api/v1/admin/entities when set
+	Accounts    AccountStore       // mounts /api/v1/admin/entities/{slug}/accounts when set
+	Properties  PropertyStore      // mounts /api/v1/admin/entities/{slug}/properties when set
+	Pricing     PricingPolicyStore // mounts /api/v1/admin/entities/{slug}/pricing when set
+	Parameters  ParameterStore     // mounts /api/v1/admin/regulatory-price and /main-indicators when set
+	Audit       AuditLog           // mounts /api/v1/admin/audit when set
+	Annotations AnnotationStore    // mounts /api/v1/admin/annotations when set
+}
+
+// PipelineStats reports the generate pipeline's caches and upstream load.
+type PipelineStats interface {
+	CacheSizes() map[string]int
+	HorizonInFlight() int
+}
+
+// UpstreamStats reports the shared HTTP transport's per-host counters
+// (transport.Transport).
+type UpstreamStats interface {
+	Stats() []transport.HostStats
+}
+
+// Diagnostics is a point-in-time view of the server process.
+type Diagnostics struct {
+	Uptime          string                `json:"uptime"`
+	Goroutines      int                   `json:"goroutines"`
+	Heap            HeapStats             `json:"heap"`
+	Caches          map[string]int        `json:"caches"`
+	HorizonInFlight *int                  `json:"horizonInFlight,omitempty"` // absent without a generate pipeline
+	Upstream        []transport.HostStats `json:"upstream,omitempty"`        // requests per external host
+}
+
+// HeapStats is the subset of runtime.MemStats useful for spotting leaks and
+// GC pressure.
+type HeapStats struct {
+	AllocBytes   uint64     `json:"allocBytes"`
+	InuseBytes   uint64     `json:"inuseBytes"`
+	SysBytes     uint64     `json:"sysBytes"`
+	Objects      uint64     `json:"objects"`
+	NumGC        uint32     `json:"numGC"`
+	GCPauseTotal string     `json:"gcPauseTotal"`
+	LastGC       *time.Time `json:"lastGC,omitempty"`
+}
+
+// AdminHandler serves runtime diagnostics.
+type AdminHandler struct {
+	pipeline PipelineStats
+	upstream UpstreamStats
+	limiter  *ipRateLimiter
+	started  time.Time
+}
+
+// NewAdminHandler creates a new admin handler. pipeline may be nil.
+func NewAdminHandler(pipeline PipelineStats) *AdminHandler {
+	return &AdminHandler{pipeline: pipeline, started: time.Now()}
+}
+
+// GetDiagnostics handles GET /api/v1/admin/diagnostics.
+//
+// @Summary      Runtime diagnostics
+// @Description  Goroutine count, heap and GC statistics, cache sizes, in-flight Horizon requests and per-host counters of the shared upstream HTTP transport of the running server. Pipeline caches and Horizon requests are only reported when on-demand generation is enabled. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Success      200  {object}  Diagnostics
+// @Failure      401  {object}  Problem
+// @Router       /api/v1/admin/diagnostics [get]
+func (h *AdminHandler) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	d := Diagnostics{
+		Uptime:     time.Since(h.started).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		Heap: HeapStats{
+			AllocBytes:   m.HeapAlloc,
+			InuseBytes:   m.HeapInuse,
+			SysBytes:     m.Sys,
+			Objects:      m.HeapObjects,
+			NumGC:        m.NumGC,
+			GCPauseTotal: time.Duration(m.PauseTotalNs).String(),
+		},
+		Caches: map[string]int{},
+	}
+	if m.LastGC > 0 {
+		last := time.Unix(0, int64(m.LastGC)).UTC()
+		d.Heap.LastGC = &last
+	}
+	if h.limiter != nil {
+		d.Caches["rateLimitBuckets"] = h.limiter.size()
+	}
+	if h.pipeline != nil {
+		for name, n := range h.pipeline.CacheSizes() {
+			d.Caches[name] = n
+		}
+		inFlight := h.pipeline.HorizonInFlight()
+		d.HorizonInFlight = &inFlight
+	}
+	if h.upstream != nil {
+		d.Upstream = h.upstream.Stats()
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// adminAuth rejects requests without "Authorization: Bearer <token>".
+func adminAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "admin token required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// etag formats a resource version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// adminChange reads the If-Match version (0 without the header: the write is
+// unconditional) and the caller's address for the audit log.
+func adminChange(r *http.Request, trustProxy bool) (admin.Change, error) {
+	ch := admin.Change{Remote: clientIP(r, trustProxy)}
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return ch, nil
+	}
+	n, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(v, "W/"), `"`))
+	if err != nil || n < 1 {
+		return ch, fmt.Errorf("invalid If-Match %q, expected a version ETag like \"3\"", v)
+	}
+	ch.IfMatch = n
+	return ch, nil
+}
+
+// writeAdminError maps admin store errors to responses: 404 for a missing
+// resource, 412 for a stale If-Match, 400 for invalid input.
+func writeAdminError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, admin.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, admin.ErrVersionMismatch):
+		writeError(w, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, admin.ErrInvalid), errors.Is(err, indicator.ErrInvalidIndexConfig):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error("failed to "+action, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// mountAdmin registers the admin endpoints, all behind adminAuth and
+// outside the per-route concurrency cap, so they still answer while the
+// API is saturated.
+func mountAdmin(mux *http.ServeMux, a Admin, limiter *ipRateLimiter, trustProxy bool) {
+	h := NewAdminHandler(a.Pipeline)
+	h.upstream = a.Upstream
+	h.limiter = limiter
+	mux.Handle("GET /api/v1/admin/diagnostics", adminAuth(a.Token, http.HandlerFunc(h.GetDiagnostics)))
+	if a.Index != nil {
+		ih := NewIndexConfigHandler(a.Index)
+		ih.trustProxy = trustProxy
+		mux.Handle("GET /api/v1/admin/index", adminAuth(a.Token, http.HandlerFunc(ih.GetIndexConfig)))
+		mux.Handle("PUT /api/v1/admin/index", adminAuth(a.Token, http.HandlerFunc(ih.PutIndexConfig)))
+	}
+	if a.Entities != nil {
+		eh := NewEntityHandler(a.Entities)
+		eh.trustProxy = trustProxy
+		mux.Handle("GET /api/v1/admin/entities", adminAuth(a.Token, http.HandlerFunc(eh.ListEntities)))
+		mux.Handle("GET /api/v1/admin/entities/{slug}", adminAuth(a.Token, http.HandlerFunc(eh.GetEntity)))
+		mux.Handle("PUT /api/v1/admin/entities/{slug}", adminAuth(a.Token, http.HandlerFunc(eh.PutEntity)))
+	}
+	if a.Accounts != nil {
+		ah := NewAccountExpectationHandler(a.Accounts)
+		ah.trustProxy = trustProxy
+		mux.Handle("GET /api/v1/admin/entities/{slug}/accounts", adminAuth(a.Token, http.HandlerFunc(ah.ListAccounts)))
+		mux.Handle("GET /api/v1/admin/entities/{slug}/accounts/{address}", adminAuth(a.Token, http.HandlerFunc(ah.GetAccount)))
+		mux.Handle("PUT /api/v1/admin/entities/{slug}/accounts/{address}", adminAuth(a.Token, http.HandlerFunc(ah.PutAccount)))
+		mux.Handle("DELETE /api/v1/admin/entities/{slug}/accounts/{address}", adminAuth(a.Token, http.HandlerFunc(ah.DeleteAccount)))
+	}
+	if a.Properties != nil {
+		ph := NewPropertyHandler(a.Properties)
+		ph.trustProxy = trustProxy
+		mux.Handle("GET /api/v1/admin/entities/{slug}/properties", adminAuth(a.Token, http.HandlerFunc(ph.ListProperties)))
+		mux.Handle("GET /api/v1/admin/entities/{slug}/properties/{token}", adminAuth(a.Token, http.HandlerFunc(ph.GetProperty)))
+		mux.Handle("PUT /api/v1/admin/entities/{slug}/properties/{token}", adminAuth(a.Token, http.HandlerFunc(ph.PutProperty)))
+		mux.Handle("DELETE /api/v1/admin/entities/{slug}/properties/{token}", adminAuth(a.Token, http.HandlerFunc(ph.DeleteProperty)))
+	}
+	if a.Pricing != nil {
+		ph := NewPricingPolicyHandler(a.Pricing)
+		ph.trustProxy = trustProxy
+		mux.Handle("GET /api/v1/admin/entities/{slug}/pricing", adminAuth(a.Token, http.HandlerFunc(ph.ListPricingPolicies)))
+		mux.Handle("GET /api/v1/admin/entities/{slug}/pricing/{asset}", adminAuth(a.Token, http.HandlerFunc(ph.GetPricingPolicy)))
+		mux.Handle("PUT /api/v1/admin/entities/{slug}/pricing/{asset}", adminAuth(a.Token, http.HandlerFunc(ph.PutPricingPolicy)))
+		mux.Handle("DELETE /api/v1/admin/entities/{slug}/pricing/{asset}", adminAuth(a.Token, http.HandlerFunc(ph.DeletePricingPolicy)))
+	}
+	if a.Parameters != nil {
+		ph := NewParameterHandler(a.Parameters)
+		ph.trustProxy = trustProxy
+		mux.Handle("GET /api/v1/admin/regulatory-price", adminAuth(a.Token, http.HandlerFunc(ph.GetRegulatoryPrice)))
+		mux.Handle("PUT /api/v1/admin/regulatory-price", adminAuth(a.Token, http.HandlerFunc(ph.PutRegulatoryPrice)))
+		mux.Handle("GET /api/v1/admin/main-indicators", adminAuth(a.Token, http.HandlerFunc(ph.GetMainIndicators)))
+		mux.Handle("PUT /api/v1/admin/main-indicators", adminAuth(a.Token, http.HandlerFunc(ph.PutMainIndicators)))
+	}
+	if a.Audit != nil {
+		mux.Handle("GET /api/v1/admin/audit", adminAuth(a.Token, http.HandlerFunc(NewAuditHandler(a.Audit).ListAudit)))
+	}
+	if a.Annotations != nil {
+		nh := NewAdminAnnotationsHandler(a.Annotations)
+		mux.Handle("POST /api/v1/admin/annotations", adminAuth(a.Token, http.HandlerFunc(nh.AddAnnotation)))
+		mux.Handle("DELETE /api/v1/admin/annotations/{id}", adminAuth(a.Token, http.HandlerFunc(nh.DeleteAnnotation)))
+	}
+	if a.Keys != nil {
+		kh := NewKeyHandler(a.Keys)
+		mux.Handle("GET /api/v1/admin/keys", adminAuth(a.Token, http.HandlerFunc(kh.ListKeys)))
+		mux.Handle("POST /api/v1/admin/keys", adminAuth(a.Token, http.HandlerFunc(kh.IssueKey)))
+		mux.Handle("DELETE /api/v1/admin/keys/{id}", adminAuth(a.Token, http.HandlerFunc(kh.RevokeKey)))
+		mux.Handle("GET /api/v1/admin/keys/{id}/usage", adminAuth(a.Token, http.HandlerFunc(kh.GetKeyUsage)))
+	}
+	if !a.Pprof {
+		return
+	}
+	mux.Handle("GET /debug/pprof/", adminAuth(a.Token, http.HandlerFunc(pprof.Index)))
+	mux.Handle("GET /debug/pprof/cmdline", adminAuth(a.Token, http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("GET /debug/pprof/profile", adminAuth(a.Token, http.HandlerFunc(pprof.Profile)))
+	mux.Handle("GET /debug/pprof/symbol", adminAuth(a.Token, http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("POST /debug/pprof/symbol", adminAuth(a.Token, http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("GET /debug/pprof/trace", adminAuth(a.Token, http.HandlerFunc(pprof.Trace)))
+}
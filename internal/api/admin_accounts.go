@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/admin"
+)
+
+// AccountStore reads and writes the declared configuration of fund accounts
+// (admin.PgRepository).
+type AccountStore interface {
+	Accounts(ctx context.Context, slug string) ([]admin.Account, error)
+	Account(ctx context.Context, slug, address string) (admin.Account, error)
+	SaveAccount(ctx context.Context, slug string, e accountguard.Expected, ch admin.Change) (admin.Account, error)
+	DeleteAccount(ctx context.Context, slug, address string, ch admin.Change) error
+}
+
+// AccountExpectationHandler serves account expectations: the configuration
+// snapshot generation checks each fund account against.
+type AccountExpectationHandler struct {
+	store      AccountStore
+	trustProxy bool
+}
+
+// NewAccountExpectationHandler creates a new account expectation handler.
+func NewAccountExpectationHandler(store AccountStore) *AccountExpectationHandler {
+	return &AccountExpectationHandler{store: store}
+}
+
+// ListAccounts handles GET /api/v1/admin/entities/{slug}/accounts.
+//
+// @Summary      List account expectations
+// @Description  The declared configuration of each checked fund account of the entity, by address. Null fields are not checked. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Success      200  {array}   admin.Account
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/accounts [get]
+func (h *AccountExpectationHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.store.Accounts(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		writeAdminError(w, err, "list account expectations")
+		return
+	}
+	if accounts == nil {
+		accounts = []admin.Account{}
+	}
+	writeJSON(w, http.StatusOK, accounts)
+}
+
+// GetAccount handles GET /api/v1/admin/entities/{slug}/accounts/{address}.
+//
+// @Summary      Get an account expectation
+// @Description  The declared configuration of one fund account. The ETag header carries the version for If-Match on PUT and DELETE. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Param        address        path    string  true  "Stellar account address"
+// @Success      200  {object}  admin.Account
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/accounts/{address} [get]
+func (h *AccountExpectationHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	a, err := h.store.Account(r.Context(), r.PathValue("slug"), r.PathValue("address"))
+	if err != nil {
+		writeAdminError(w, err, "load account expectation")
+		return
+	}
+	w.Header().Set("ETag", etag(a.Version))
+	writeJSON(w, http.StatusOK, a)
+}
+
+// PutAccount handles PUT /api/v1/admin/entities/{slug}/accounts/{address}.
+//
+// @Summary      Create or replace an account expectation
+// @Description  Declares the configuration the account is checked against; omitted or null fields are not checked, and an empty homeDomain or inflationDestination means it must be unset. The body's account, when given, must match the path. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                 true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string                 false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string                 true   "Entity slug"  example(mtlf)
+// @Param        address        path    string                 true   "Stellar account address"
+// @Param        body           body    accountguard.Expected  true   "Expected configuration"
+// @Success      200  {object}  admin.Account
+// @Success      201  {object}  admin.Account
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/accounts/{address} [put]
+func (h *AccountExpectationHandler) PutAccount(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body accountguard.Expected
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	address := r.PathValue("address")
+	if body.Account != "" && body.Account != address {
+		writeError(w, http.StatusBadRequest, "account in body does not match the path")
+		return
+	}
+	body.Account = address
+
+	slug := r.PathValue("slug")
+	a, err := h.store.SaveAccount(r.Context(), slug, body, ch)
+	if err != nil {
+		writeAdminError(w, err, "save account expectation")
+		return
+	}
+	slog.Info("account expectation saved", "entity", slug, "account", address, "version", a.Version)
+	status := http.StatusOK
+	if a.Version == 1 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("ETag", etag(a.Version))
+	writeJSON(w, status, a)
+}
+
+// DeleteAccount handles DELETE /api/v1/admin/entities/{slug}/accounts/{address}.
+//
+// @Summary      Delete an account expectation
+// @Description  Stops checking the account's configuration. With If-Match the delete only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Param        Authorization  header  string  true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string  false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string  true   "Entity slug"  example(mtlf)
+// @Param        address        path    string  true   "Stellar account address"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/accounts/{address} [delete]
+func (h *AccountExpectationHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	slug, address := r.PathValue("slug"), r.PathValue("address")
+	if err := h.store.DeleteAccount(r.Context(), slug, address, ch); err != nil {
+		writeAdminError(w, err, "delete account expectation")
+		return
+	}
+	slog.Info("account expectation deleted", "entity", slug, "account", address)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/accountguard"
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+type stubAccountStore struct {
+	accounts map[string]admin.Account
+}
+
+func (s *stubAccountStore) Accounts(_ context.Context, slug string) ([]admin.Account, error) {
+	if slug != "mtlf" {
+		return nil, admin.ErrNotFound
+	}
+	var out []admin.Account
+	for _, a := range s.accounts {
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (s *stubAccountStore) Account(_ context.Context, _, address string) (admin.Account, error) {
+	a, ok := s.accounts[address]
+	if !ok {
+		return admin.Account{}, admin.ErrNotFound
+	}
+	return a, nil
+}
+
+func (s *stubAccountStore) SaveAccount(_ context.Context, _ string, e accountguard.Expected, ch admin.Change) (admin.Account, error) {
+	if err := admin.ValidateExpected(e); err != nil {
+		return admin.Account{}, err
+	}
+	cur := s.accounts[e.Account]
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.Account{}, admin.ErrVersionMismatch
+	}
+	a := admin.Account{Expected: e, Version: cur.Version + 1}
+	s.accounts[e.Account] = a
+	return a, nil
+}
+
+func (s *stubAccountStore) DeleteAccount(_ context.Context, _, address string, ch admin.Change) error {
+	cur, ok := s.accounts[address]
+	if !ok {
+		return admin.ErrNotFound
+	}
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.ErrVersionMismatch
+	}
+	delete(s.accounts, address)
+	return nil
+}
+
+func TestAccountExpectations(t *testing.T) {
+	store := &stubAccountStore{accounts: map[string]admin.Account{}}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Accounts: store}))
+	path := "/api/v1/admin/entities/mtlf/accounts/" + domain.IssuerAddress
+
+	w := sendAdmin(t, srv, http.MethodPut, path, `{"homeDomain":"montelibero.org","authRevocable":true}`)
+	if w.Code != http.StatusCreated || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("create: status = %d, ETag = %q: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	a := store.accounts[domain.IssuerAddress]
+	if a.HomeDomain == nil || *a.HomeDomain != "montelibero.org" || a.AuthRequired != nil {
+		t.Errorf("saved = %+v", a.Expected)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodGet, path, ""); w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Errorf("GET: status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/entities/none/accounts", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown entity: status = %d, want 404", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"account":"GOTHER"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("mismatched account: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/entities/mtlf/accounts/GNOTANADDRESS", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid address: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"numSponsored":-1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("negative count: status = %d, want 400", w.Code)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodDelete, path, "", "If-Match", `"2"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale DELETE: status = %d, want 412", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodDelete, path, "", "If-Match", `"1"`); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status = %d, want 204", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodDelete, path, ""); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE again: status = %d, want 404", w.Code)
+	}
+}
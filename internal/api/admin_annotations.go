@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/stat/internal/annotation"
+)
+
+// AnnotationStore adds and deletes snapshot date annotations
+// (annotation.PgRepository).
+type AnnotationStore interface {
+	Add(ctx context.Context, slug string, a annotation.Annotation) (annotation.Annotation, error)
+	Delete(ctx context.Context, slug string, id int64) error
+}
+
+// AnnotationBody is a new annotation.
+type AnnotationBody struct {
+	Date string   `json:"date" example:"2026-10-01"`
+	Text string   `json:"text" example:"DEFI received EUR 200k tranche"`
+	Tags []string `json:"tags,omitempty" example:"defi"`
+}
+
+// AdminAnnotationsHandler manages snapshot date annotations.
+type AdminAnnotationsHandler struct {
+	store AnnotationStore
+}
+
+// NewAdminAnnotationsHandler creates a new annotation admin handler.
+func NewAdminAnnotationsHandler(store AnnotationStore) *AdminAnnotationsHandler {
+	return &AdminAnnotationsHandler{store: store}
+}
+
+// AddAnnotation handles POST /api/v1/admin/annotations.
+//
+// @Summary      Annotate a snapshot date
+// @Description  Attaches a note to a snapshot date. The text is at most 500 characters; tags are lowercase letters, digits and dashes (at most 10). Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string          true  "Bearer ADMIN_TOKEN"
+// @Param        body           body    AnnotationBody  true  "Annotation"
+// @Success      201  {object}  annotation.Annotation
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/annotations [post]
+func (h *AdminAnnotationsHandler) AddAnnotation(w http.ResponseWriter, r *http.Request) {
+	var body AnnotationBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	date, err := time.Parse("2006-01-02", body.Date)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	a, err := h.store.Add(r.Context(), fundSlug, annotation.Annotation{Date: date, Text: body.Text, Tags: body.Tags})
+	if errors.Is(err, annotation.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("failed to add annotation", "date", body.Date, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.Info("annotation added", "id", a.ID, "date", body.Date)
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// DeleteAnnotation handles DELETE /api/v1/admin/annotations/{id}.
+//
+// @Summary      Delete an annotation
+// @Description  Removes one annotation. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        id             path    int     true  "Annotation ID"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/annotations/{id} [delete]
+func (h *AdminAnnotationsHandler) DeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid annotation id")
+		return
+	}
+	if err := h.store.Delete(r.Context(), fundSlug, id); err != nil {
+		if errors.Is(err, annotation.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "annotation not found")
+			return
+		}
+		slog.Error("failed to delete annotation", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.Info("annotation deleted", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
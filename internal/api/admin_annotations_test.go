@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/annotation"
+)
+
+type stubAnnotationStore struct {
+	added []annotation.Annotation
+}
+
+func (s *stubAnnotationStore) Add(_ context.Context, _ string, a annotation.Annotation) (annotation.Annotation, error) {
+	if err := a.Validate(); err != nil {
+		return annotation.Annotation{}, err
+	}
+	a.ID = int64(len(s.added) + 1)
+	s.added = append(s.added, a)
+	return a, nil
+}
+
+func (s *stubAnnotationStore) Delete(_ context.Context, _ string, id int64) error {
+	if id < 1 || id > int64(len(s.added)) {
+		return annotation.ErrNotFound
+	}
+	return nil
+}
+
+func TestAdminAnnotations(t *testing.T) {
+	store := &stubAnnotationStore{}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Annotations: store}))
+
+	w := sendAdmin(t, srv, http.MethodPost, "/api/v1/admin/annotations",
+		`{"date":"2026-10-01","text":"DEFI received EUR 200k tranche","tags":["defi"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: status = %d, want 201: %s", w.Code, w.Body)
+	}
+	var got annotation.Annotation
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 1 || got.Date.Format("2006-01-02") != "2026-10-01" || len(got.Tags) != 1 {
+		t.Errorf("added = %+v", got)
+	}
+
+	for name, body := range map[string]string{
+		"bad date":      `{"date":"01.10.2026","text":"x"}`,
+		"empty text":    `{"date":"2026-10-01","text":""}`,
+		"bad tag":       `{"date":"2026-10-01","text":"x","tags":["Not A Slug"]}`,
+		"unknown field": `{"date":"2026-10-01","text":"x","author":"me"}`,
+	} {
+		if w := sendAdmin(t, srv, http.MethodPost, "/api/v1/admin/annotations", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+
+	if w := sendAdmin(t, srv, http.MethodDelete, "/api/v1/admin/annotations/1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status = %d, want 204", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodDelete, "/api/v1/admin/annotations/9", ""); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE unknown: status = %d, want 404", w.Code)
+	}
+	if w := serveAdmin(t, srv, http.MethodDelete, "/api/v1/admin/annotations/1", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", w.Code)
+	}
+}
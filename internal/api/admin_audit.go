@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mtlprog/stat/internal/admin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditLog lists changes made through the admin API (admin.PgRepository).
+type AuditLog interface {
+	Audit(ctx context.Context, resource string, limit int) ([]admin.AuditEntry, error)
+}
+
+// AuditHandler serves the admin audit log.
+type AuditHandler struct {
+	log AuditLog
+}
+
+// NewAuditHandler creates a new audit log handler.
+func NewAuditHandler(log AuditLog) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// ListAudit handles GET /api/v1/admin/audit.
+//
+// @Summary      Admin audit log
+// @Description  Changes made through the admin API, newest first, each with the resource before and after (absent when it didn't exist or was deleted) and the caller's address. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer ADMIN_TOKEN"
+// @Param        resource       query   string  false  "Only this resource type"  Enums(entity, account, index, property)
+// @Param        limit          query   int     false  "Maximum entries (default 100, max 1000)"
+// @Success      200  {array}   admin.AuditEntry
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/audit [get]
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	if resource != "" && !slices.Contains([]string{admin.ResourceEntity, admin.ResourceAccount, admin.ResourceIndex, admin.ResourceProperty}, resource) {
+		writeError(w, http.StatusBadRequest, "invalid resource, expected entity, account, index or property")
+		return
+	}
+	limit := defaultAuditLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			writeError(w, http.StatusBadRequest, "invalid limit, expected 1 to 1000")
+			return
+		}
+		limit = n
+	}
+	entries, err := h.log.Audit(r.Context(), resource, limit)
+	if err != nil {
+		writeAdminError(w, err, "list audit entries")
+		return
+	}
+	if entries == nil {
+		entries = []admin.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/admin"
+)
+
+type stubAuditLog struct {
+	resource string
+	limit    int
+}
+
+func (s *stubAuditLog) Audit(_ context.Context, resource string, limit int) ([]admin.AuditEntry, error) {
+	s.resource, s.limit = resource, limit
+	return []admin.AuditEntry{{ID: 1, Resource: admin.ResourceIndex, Key: "mtlf", Action: "update", Version: 2,
+		After: json.RawMessage(`{"baseDate":"2025-01-01T00:00:00Z"}`)}}, nil
+}
+
+func TestListAudit(t *testing.T) {
+	log := &stubAuditLog{}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Audit: log}))
+
+	w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/audit", "")
+	if w.Code != http.StatusOK || log.limit != defaultAuditLimit || log.resource != "" {
+		t.Fatalf("status = %d, resource = %q, limit = %d", w.Code, log.resource, log.limit)
+	}
+	var got []map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0]["before"] != nil || got[0]["after"] == nil {
+		t.Errorf("entries = %v", got)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/audit?resource=account&limit=5", ""); w.Code != http.StatusOK ||
+		log.resource != "account" || log.limit != 5 {
+		t.Errorf("filtered: status = %d, resource = %q, limit = %d", w.Code, log.resource, log.limit)
+	}
+	for _, q := range []string{"resource=keys", "limit=0", "limit=5000", "limit=x"} {
+		if w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/audit?"+q, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/admin"
+)
+
+// EntityStore reads and writes fund entities (admin.PgRepository).
+type EntityStore interface {
+	Entities(ctx context.Context) ([]admin.Entity, error)
+	Entity(ctx context.Context, slug string) (admin.Entity, error)
+	SaveEntity(ctx context.Context, e admin.Entity, ch admin.Change) (admin.Entity, error)
+}
+
+// EntityBody is the writable part of a fund entity.
+type EntityBody struct {
+	Name        string `json:"name" example:"Montelibero Fund"`
+	Description string `json:"description" example:"Montelibero Fund statistics"`
+}
+
+// EntityHandler serves fund entities.
+type EntityHandler struct {
+	store      EntityStore
+	trustProxy bool
+}
+
+// NewEntityHandler creates a new entity handler.
+func NewEntityHandler(store EntityStore) *EntityHandler {
+	return &EntityHandler{store: store}
+}
+
+// ListEntities handles GET /api/v1/admin/entities.
+//
+// @Summary      List fund entities
+// @Description  All fund entities by slug, with their versions. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Success      200  {array}   admin.Entity
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities [get]
+func (h *EntityHandler) ListEntities(w http.ResponseWriter, r *http.Request) {
+	entities, err := h.store.Entities(r.Context())
+	if err != nil {
+		writeAdminError(w, err, "list entities")
+		return
+	}
+	if entities == nil {
+		entities = []admin.Entity{}
+	}
+	writeJSON(w, http.StatusOK, entities)
+}
+
+// GetEntity handles GET /api/v1/admin/entities/{slug}.
+//
+// @Summary      Get a fund entity
+// @Description  One fund entity. The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Success      200  {object}  admin.Entity
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug} [get]
+func (h *EntityHandler) GetEntity(w http.ResponseWriter, r *http.Request) {
+	e, err := h.store.Entity(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		writeAdminError(w, err, "load entity")
+		return
+	}
+	w.Header().Set("ETag", etag(e.Version))
+	writeJSON(w, http.StatusOK, e)
+}
+
+// PutEntity handles PUT /api/v1/admin/entities/{slug}.
+//
+// @Summary      Create or update a fund entity
+// @Description  Creates the entity (201) or replaces its name and description (200). The slug is lowercase letters, digits and dashes. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string      true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string      false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string      true   "Entity slug"  example(mtlf)
+// @Param        body           body    EntityBody  true   "Entity"
+// @Success      200  {object}  admin.Entity
+// @Success      201  {object}  admin.Entity
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug} [put]
+func (h *EntityHandler) PutEntity(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body EntityBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	e, err := h.store.SaveEntity(r.Context(), admin.Entity{
+		Slug: r.PathValue("slug"), Name: body.Name, Description: body.Description,
+	}, ch)
+	if err != nil {
+		writeAdminError(w, err, "save entity")
+		return
+	}
+	slog.Info("entity saved", "slug", e.Slug, "version", e.Version)
+	status := http.StatusOK
+	if e.Version == 1 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("ETag", etag(e.Version))
+	writeJSON(w, status, e)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/admin"
+)
+
+type stubEntityStore struct {
+	entities map[string]admin.Entity
+	last     admin.Change
+}
+
+func (s *stubEntityStore) Entities(context.Context) ([]admin.Entity, error) {
+	var out []admin.Entity
+	for _, e := range s.entities {
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (s *stubEntityStore) Entity(_ context.Context, slug string) (admin.Entity, error) {
+	e, ok := s.entities[slug]
+	if !ok {
+		return admin.Entity{}, admin.ErrNotFound
+	}
+	return e, nil
+}
+
+func (s *stubEntityStore) SaveEntity(_ context.Context, e admin.Entity, ch admin.Change) (admin.Entity, error) {
+	if err := e.Validate(); err != nil {
+		return admin.Entity{}, err
+	}
+	s.last = ch
+	cur := s.entities[e.Slug]
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.Entity{}, admin.ErrVersionMismatch
+	}
+	e.Version = cur.Version + 1
+	s.entities[e.Slug] = e
+	return e, nil
+}
+
+func sendAdmin(t *testing.T, srv *http.Server, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestEntities(t *testing.T) {
+	store := &stubEntityStore{entities: map[string]admin.Entity{
+		"mtlf": {Slug: "mtlf", Name: "Montelibero Fund", Version: 3},
+	}}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Entities: store}))
+
+	w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/entities/mtlf", "")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+		t.Fatalf("GET: status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/entities/none", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET unknown: status = %d, want 404", w.Code)
+	}
+
+	w = sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/entities/mtlf", `{"name":"MTL Fund","description":"d"}`,
+		"If-Match", `"3"`, "X-Forwarded-For", "10.0.0.1")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"4"` {
+		t.Fatalf("PUT: status = %d, ETag = %q: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	var got admin.Entity
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "MTL Fund" || got.Version != 4 || store.last.Remote == "" {
+		t.Errorf("PUT = %+v, change = %+v", got, store.last)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/entities/mtlf", `{"name":"x"}`, "If-Match", `"3"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale PUT: status = %d, want 412", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/entities/new-fund", `{"name":"New"}`); w.Code != http.StatusCreated {
+		t.Errorf("create: status = %d, want 201", w.Code)
+	}
+	for _, tc := range []struct{ slug, body string }{
+		{"Bad_Slug", `{"name":"x"}`},
+		{"mtlf", `{"name":""}`},
+		{"mtlf", `{"name":"x","slug":"other"}`},
+	} {
+		if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/entities/"+tc.slug, tc.body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: status = %d, want 400", tc.slug, tc.body, w.Code)
+		}
+	}
+}
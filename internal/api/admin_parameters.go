@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/admin"
+)
+
+// ParameterStore reads and replaces the export parameters: the regulatory
+// price and the IND_MAIN set (admin.PgRepository).
+type ParameterStore interface {
+	RegulatoryPrice(ctx context.Context, slug string) (admin.RegulatoryPrice, error)
+	SaveRegulatoryPrice(ctx context.Context, slug string, p decimal.Decimal, ch admin.Change) (admin.RegulatoryPrice, error)
+	MainIndicators(ctx context.Context, slug string) (admin.MainIndicators, error)
+	SaveMainIndicators(ctx context.Context, slug string, ids []int, ch admin.Change) (admin.MainIndicators, error)
+}
+
+// RegulatoryPriceBody is the Regulatory Price written to the MONITORING
+// sheet. Version is 0 while the default applies; it is ignored on PUT.
+type RegulatoryPriceBody struct {
+	Price   decimal.Decimal `json:"price" example:"4"`
+	Version int             `json:"version"`
+}
+
+// MainIndicatorsBody is the set of indicators listed on the IND_MAIN sheet.
+// Version is 0 while the default applies; it is ignored on PUT.
+type MainIndicatorsBody struct {
+	IDs     []int `json:"ids" example:"1,3,8,10"`
+	Version int   `json:"version"`
+}
+
+// ParameterHandler serves the export parameters.
+type ParameterHandler struct {
+	store      ParameterStore
+	trustProxy bool
+}
+
+// NewParameterHandler creates a new export parameter handler.
+func NewParameterHandler(store ParameterStore) *ParameterHandler {
+	return &ParameterHandler{store: store}
+}
+
+// GetRegulatoryPrice handles GET /api/v1/admin/regulatory-price.
+//
+// @Summary      Regulatory price
+// @Description  The Regulatory Price written to each new MONITORING row (4 until one is set). The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Success      200  {object}  RegulatoryPriceBody
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/regulatory-price [get]
+func (h *ParameterHandler) GetRegulatoryPrice(w http.ResponseWriter, r *http.Request) {
+	rp, err := h.store.RegulatoryPrice(r.Context(), fundSlug)
+	if err != nil {
+		writeAdminError(w, err, "load regulatory price")
+		return
+	}
+	w.Header().Set("ETag", etag(rp.Version))
+	writeJSON(w, http.StatusOK, RegulatoryPriceBody{Price: rp.Price, Version: rp.Version})
+}
+
+// PutRegulatoryPrice handles PUT /api/v1/admin/regulatory-price.
+//
+// @Summary      Set the regulatory price
+// @Description  Sets the Regulatory Price of the MONITORING rows exported from now on; rows already in the sheet keep theirs. The price must be positive. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string               true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string               false  "Version ETag from GET, e.g. \"3\""
+// @Param        body           body    RegulatoryPriceBody  true   "Regulatory price"
+// @Success      200  {object}  RegulatoryPriceBody
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/regulatory-price [put]
+func (h *ParameterHandler) PutRegulatoryPrice(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body RegulatoryPriceBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	rp, err := h.store.SaveRegulatoryPrice(r.Context(), fundSlug, body.Price, ch)
+	if err != nil {
+		writeAdminError(w, err, "save regulatory price")
+		return
+	}
+	slog.Info("regulatory price updated", "price", rp.Price.String(), "version", rp.Version)
+	w.Header().Set("ETag", etag(rp.Version))
+	writeJSON(w, http.StatusOK, RegulatoryPriceBody{Price: rp.Price, Version: rp.Version})
+}
+
+// GetMainIndicators handles GET /api/v1/admin/main-indicators.
+//
+// @Summary      IND_MAIN indicator set
+// @Description  The indicators listed on the IND_MAIN sheet. The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Success      200  {object}  MainIndicatorsBody
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/main-indicators [get]
+func (h *ParameterHandler) GetMainIndicators(w http.ResponseWriter, r *http.Request) {
+	mi, err := h.store.MainIndicators(r.Context(), fundSlug)
+	if err != nil {
+		writeAdminError(w, err, "load IND_MAIN indicators")
+		return
+	}
+	w.Header().Set("ETag", etag(mi.Version))
+	writeJSON(w, http.StatusOK, MainIndicatorsBody{IDs: mi.IDs, Version: mi.Version})
+}
+
+// PutMainIndicators handles PUT /api/v1/admin/main-indicators.
+//
+// @Summary      Replace the IND_MAIN indicator set
+// @Description  Replaces the indicators listed on the IND_MAIN sheet from the next export. IDs must be registered indicators, each at most once. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string              true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string              false  "Version ETag from GET, e.g. \"3\""
+// @Param        body           body    MainIndicatorsBody  true   "IND_MAIN indicators"
+// @Success      200  {object}  MainIndicatorsBody
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/main-indicators [put]
+func (h *ParameterHandler) PutMainIndicators(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body MainIndicatorsBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	mi, err := h.store.SaveMainIndicators(r.Context(), fundSlug, body.IDs, ch)
+	if err != nil {
+		writeAdminError(w, err, "save IND_MAIN indicators")
+		return
+	}
+	slog.Info("IND_MAIN indicators updated", "ids", mi.IDs, "version", mi.Version)
+	w.Header().Set("ETag", etag(mi.Version))
+	writeJSON(w, http.StatusOK, MainIndicatorsBody{IDs: mi.IDs, Version: mi.Version})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/export"
+)
+
+type stubParameterStore struct {
+	price admin.RegulatoryPrice
+	main  admin.MainIndicators
+}
+
+func (s *stubParameterStore) RegulatoryPrice(context.Context, string) (admin.RegulatoryPrice, error) {
+	return s.price, nil
+}
+
+func (s *stubParameterStore) SaveRegulatoryPrice(_ context.Context, _ string, p decimal.Decimal, ch admin.Change) (admin.RegulatoryPrice, error) {
+	if err := admin.ValidateRegulatoryPrice(p); err != nil {
+		return admin.RegulatoryPrice{}, err
+	}
+	if ch.IfMatch != 0 && ch.IfMatch != s.price.Version {
+		return admin.RegulatoryPrice{}, admin.ErrVersionMismatch
+	}
+	s.price = admin.RegulatoryPrice{Price: p, Version: s.price.Version + 1}
+	return s.price, nil
+}
+
+func (s *stubParameterStore) MainIndicators(context.Context, string) (admin.MainIndicators, error) {
+	return s.main, nil
+}
+
+func (s *stubParameterStore) SaveMainIndicators(_ context.Context, _ string, ids []int, ch admin.Change) (admin.MainIndicators, error) {
+	if err := admin.ValidateMainIndicators(ids); err != nil {
+		return admin.MainIndicators{}, err
+	}
+	if ch.IfMatch != 0 && ch.IfMatch != s.main.Version {
+		return admin.MainIndicators{}, admin.ErrVersionMismatch
+	}
+	s.main = admin.MainIndicators{IDs: ids, Version: s.main.Version + 1}
+	return s.main, nil
+}
+
+func TestRegulatoryPrice(t *testing.T) {
+	store := &stubParameterStore{price: admin.RegulatoryPrice{Price: export.DefaultRegulatoryPrice}}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Parameters: store}))
+
+	w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/regulatory-price", "")
+	var got RegulatoryPriceBody
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d: %s", w.Code, w.Body)
+	}
+	if got.Price.String() != "4" || got.Version != 0 || w.Header().Get("ETag") != `"0"` {
+		t.Errorf("default = %+v, ETag %q", got, w.Header().Get("ETag"))
+	}
+
+	if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/regulatory-price", `{"price":"0"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("zero price: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/regulatory-price", `{"price":"4.5"}`, "If-Match", `"2"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale PUT: status = %d, want 412", w.Code)
+	}
+	w = sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/regulatory-price", `{"price":"4.5"}`)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` || store.price.Price.String() != "4.5" {
+		t.Errorf("PUT: status = %d, ETag %q, stored %s", w.Code, w.Header().Get("ETag"), store.price.Price)
+	}
+}
+
+func TestMainIndicators(t *testing.T) {
+	store := &stubParameterStore{main: admin.MainIndicators{IDs: export.DefaultMainIndicatorIDs}}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Parameters: store}))
+
+	w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/main-indicators", "")
+	var got MainIndicatorsBody
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d: %s", w.Code, w.Body)
+	}
+	if len(got.IDs) != len(export.DefaultMainIndicatorIDs) || got.Version != 0 {
+		t.Errorf("default = %+v", got)
+	}
+
+	for _, body := range []string{`{"ids":[]}`, `{"ids":[1,1]}`, `{"ids":[9999]}`, `{"ids":[1],"extra":true}`} {
+		if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/main-indicators", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+	w = sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/main-indicators", `{"ids":[1,8,47]}`)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` || len(store.main.IDs) != 3 {
+		t.Errorf("PUT: status = %d, ETag %q, stored %v", w.Code, w.Header().Get("ETag"), store.main.IDs)
+	}
+}
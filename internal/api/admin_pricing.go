@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/price"
+)
+
+// PricingPolicyStore reads and writes the per-asset pricing policies
+// (admin.PgRepository).
+type PricingPolicyStore interface {
+	PricingPolicies(ctx context.Context, slug string) ([]admin.PricingPolicy, error)
+	PricingPolicy(ctx context.Context, slug, asset string) (admin.PricingPolicy, error)
+	SavePricingPolicy(ctx context.Context, slug string, p price.Policy, ch admin.Change) (admin.PricingPolicy, error)
+	DeletePricingPolicy(ctx context.Context, slug, asset string, ch admin.Change) error
+}
+
+// PricingPolicyRequest is the body of PUT /api/v1/admin/entities/{slug}/pricing/{asset}.
+type PricingPolicyRequest struct {
+	Asset  string `json:"asset,omitempty"`
+	Source string `json:"source" example:"orderbook"`
+}
+
+// PricingPolicyHandler serves the pricing policies: which market source a
+// token's spot price is taken from.
+type PricingPolicyHandler struct {
+	store      PricingPolicyStore
+	trustProxy bool
+}
+
+// NewPricingPolicyHandler creates a new pricing policy handler.
+func NewPricingPolicyHandler(store PricingPolicyStore) *PricingPolicyHandler {
+	return &PricingPolicyHandler{store: store}
+}
+
+// ListPricingPolicies handles GET /api/v1/admin/entities/{slug}/pricing.
+//
+// @Summary      List pricing policies
+// @Description  The assets of the entity whose spot price source is pinned, by asset. Assets without a policy are priced "best": path finding and the orderbook are both queried and the higher price wins. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Success      200  {array}   admin.PricingPolicy
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/pricing [get]
+func (h *PricingPolicyHandler) ListPricingPolicies(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.PricingPolicies(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		writeAdminError(w, err, "list pricing policies")
+		return
+	}
+	if list == nil {
+		list = []admin.PricingPolicy{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetPricingPolicy handles GET /api/v1/admin/entities/{slug}/pricing/{asset}.
+//
+// @Summary      Get a pricing policy
+// @Description  The spot price source pinned for one asset. The ETag header carries the version for If-Match on PUT and DELETE. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Param        asset          path    string  true  "native or CODE:ISSUER"
+// @Success      200  {object}  admin.PricingPolicy
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/pricing/{asset} [get]
+func (h *PricingPolicyHandler) GetPricingPolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := h.store.PricingPolicy(r.Context(), r.PathValue("slug"), r.PathValue("asset"))
+	if err != nil {
+		writeAdminError(w, err, "load pricing policy")
+		return
+	}
+	w.Header().Set("ETag", etag(p.Version))
+	writeJSON(w, http.StatusOK, p)
+}
+
+// PutPricingPolicy handles PUT /api/v1/admin/entities/{slug}/pricing/{asset}.
+//
+// @Summary      Set a pricing policy
+// @Description  From the next report run the asset's spot price comes only from source: "path" (Horizon path finding), "orderbook" (the orderbook and AMM pools) or "best" (the higher of both, the default). The body's asset, when given, must match the path. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string                false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string                true   "Entity slug"  example(mtlf)
+// @Param        asset          path    string                true   "native or CODE:ISSUER"
+// @Param        body           body    PricingPolicyRequest  true   "Pricing policy"
+// @Success      200  {object}  admin.PricingPolicy
+// @Success      201  {object}  admin.PricingPolicy
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/pricing/{asset} [put]
+func (h *PricingPolicyHandler) PutPricingPolicy(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body PricingPolicyRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	asset := r.PathValue("asset")
+	if body.Asset != "" && body.Asset != asset {
+		writeError(w, http.StatusBadRequest, "asset in body does not match the path")
+		return
+	}
+
+	slug := r.PathValue("slug")
+	saved, err := h.store.SavePricingPolicy(r.Context(), slug, price.Policy{Asset: asset, Source: body.Source}, ch)
+	if err != nil {
+		writeAdminError(w, err, "save pricing policy")
+		return
+	}
+	slog.Info("pricing policy saved", "entity", slug, "asset", asset, "source", saved.Source, "version", saved.Version)
+	status := http.StatusOK
+	if saved.Version == 1 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("ETag", etag(saved.Version))
+	writeJSON(w, status, saved)
+}
+
+// DeletePricingPolicy handles DELETE /api/v1/admin/entities/{slug}/pricing/{asset}.
+//
+// @Summary      Delete a pricing policy
+// @Description  From the next report run the asset is priced "best" again. With If-Match the delete only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Param        Authorization  header  string  true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string  false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string  true   "Entity slug"  example(mtlf)
+// @Param        asset          path    string  true   "native or CODE:ISSUER"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/pricing/{asset} [delete]
+func (h *PricingPolicyHandler) DeletePricingPolicy(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	slug, asset := r.PathValue("slug"), r.PathValue("asset")
+	if err := h.store.DeletePricingPolicy(r.Context(), slug, asset, ch); err != nil {
+		writeAdminError(w, err, "delete pricing policy")
+		return
+	}
+	slog.Info("pricing policy deleted", "entity", slug, "asset", asset)
+	w.WriteHeader(http.StatusNoContent)
+}
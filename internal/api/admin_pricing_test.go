@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/price"
+)
+
+type stubPricingStore struct {
+	policies map[string]admin.PricingPolicy
+}
+
+func (s *stubPricingStore) PricingPolicies(_ context.Context, slug string) ([]admin.PricingPolicy, error) {
+	if slug != "mtlf" {
+		return nil, admin.ErrNotFound
+	}
+	var out []admin.PricingPolicy
+	for _, p := range s.policies {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (s *stubPricingStore) PricingPolicy(_ context.Context, _, asset string) (admin.PricingPolicy, error) {
+	p, ok := s.policies[asset]
+	if !ok {
+		return admin.PricingPolicy{}, admin.ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *stubPricingStore) SavePricingPolicy(_ context.Context, _ string, p price.Policy, ch admin.Change) (admin.PricingPolicy, error) {
+	if err := admin.ValidatePolicy(p); err != nil {
+		return admin.PricingPolicy{}, err
+	}
+	cur := s.policies[p.Asset]
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.PricingPolicy{}, admin.ErrVersionMismatch
+	}
+	saved := admin.PricingPolicy{Policy: p, Version: cur.Version + 1}
+	s.policies[p.Asset] = saved
+	return saved, nil
+}
+
+func (s *stubPricingStore) DeletePricingPolicy(_ context.Context, _, asset string, ch admin.Change) error {
+	cur, ok := s.policies[asset]
+	if !ok {
+		return admin.ErrNotFound
+	}
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.ErrVersionMismatch
+	}
+	delete(s.policies, asset)
+	return nil
+}
+
+func TestPricingPolicies(t *testing.T) {
+	store := &stubPricingStore{policies: map[string]admin.PricingPolicy{}}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Pricing: store}))
+	asset := "MTL:" + domain.IssuerAddress
+	path := "/api/v1/admin/entities/mtlf/pricing/" + asset
+
+	w := sendAdmin(t, srv, http.MethodPut, path, `{"source":"orderbook"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("create: status = %d, ETag = %q: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	if p := store.policies[asset]; p.Source != price.SourceOrderbook {
+		t.Errorf("saved = %+v", p.Policy)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/entities/mtlf/pricing", ""); w.Code != http.StatusOK {
+		t.Errorf("list: status = %d", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodGet, path, ""); w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Errorf("get: status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"asset":"native","source":"path"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("mismatched asset: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"source":"amm"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown source: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, "/api/v1/admin/entities/mtlf/pricing/MTL", `{"source":"path"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("asset without issuer: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"source":"path"}`, "If-Match", `"1"`); w.Code != http.StatusOK {
+		t.Errorf("update: status = %d, want 200", w.Code)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodDelete, path, "", "If-Match", `"1"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale DELETE: status = %d, want 412", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodDelete, path, ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status = %d, want 204", w.Code)
+	}
+}
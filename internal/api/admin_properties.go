@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/property"
+)
+
+// PropertyStore reads and writes the real-estate property registry
+// (admin.PgRepository).
+type PropertyStore interface {
+	Properties(ctx context.Context, slug string) ([]admin.Property, error)
+	Property(ctx context.Context, slug, token string) (admin.Property, error)
+	SaveProperty(ctx context.Context, slug string, p property.Property, ch admin.Change) (admin.Property, error)
+	DeleteProperty(ctx context.Context, slug, token string, ch admin.Change) error
+}
+
+// PropertyRequest is the body of PUT /api/v1/admin/entities/{slug}/properties/{token}.
+type PropertyRequest struct {
+	Token          string           `json:"token,omitempty"`
+	Name           string           `json:"name" example:"Budva, Rozino 12, apt. 4"`
+	Location       string           `json:"location" example:"Budva, Montenegro"`
+	AreaM2         decimal.Decimal  `json:"areaM2" example:"54.3"`
+	PriceSymbol    string           `json:"priceSymbol" example:"M2_BUDVA"`
+	AppraisalValue *decimal.Decimal `json:"appraisalValue,omitempty" example:"160000"`
+	AppraisedOn    string           `json:"appraisedOn,omitempty" example:"2026-03-01"`
+}
+
+// PropertyHandler serves the property registry: the area and appraisal data
+// real-estate tokens are valued by.
+type PropertyHandler struct {
+	store      PropertyStore
+	trustProxy bool
+}
+
+// NewPropertyHandler creates a new property registry handler.
+func NewPropertyHandler(store PropertyStore) *PropertyHandler {
+	return &PropertyHandler{store: store}
+}
+
+// ListProperties handles GET /api/v1/admin/entities/{slug}/properties.
+//
+// @Summary      List registered properties
+// @Description  The real-estate tokens of the entity valued by area and appraisal instead of _COST DATA entries, by token. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Success      200  {array}   admin.Property
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/properties [get]
+func (h *PropertyHandler) ListProperties(w http.ResponseWriter, r *http.Request) {
+	props, err := h.store.Properties(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		writeAdminError(w, err, "list properties")
+		return
+	}
+	if props == nil {
+		props = []admin.Property{}
+	}
+	writeJSON(w, http.StatusOK, props)
+}
+
+// GetProperty handles GET /api/v1/admin/entities/{slug}/properties/{token}.
+//
+// @Summary      Get a registered property
+// @Description  The registry entry of one real-estate token. The ETag header carries the version for If-Match on PUT and DELETE. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        slug           path    string  true  "Entity slug"  example(mtlf)
+// @Param        token          path    string  true  "Asset code"  example(MCITY136)
+// @Success      200  {object}  admin.Property
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/properties/{token} [get]
+func (h *PropertyHandler) GetProperty(w http.ResponseWriter, r *http.Request) {
+	p, err := h.store.Property(r.Context(), r.PathValue("slug"), r.PathValue("token"))
+	if err != nil {
+		writeAdminError(w, err, "load property")
+		return
+	}
+	w.Header().Set("ETag", etag(p.Version))
+	writeJSON(w, http.StatusOK, p)
+}
+
+// PutProperty handles PUT /api/v1/admin/entities/{slug}/properties/{token}.
+//
+// @Summary      Register or replace a property
+// @Description  From the next snapshot the token is valued at areaM2 × the external quote priceSymbol (EUR per m², set with `stat quote set`), or at appraisalValue when priceSymbol is empty, instead of its _COST / _1COST DATA entries. A held token whose appraisedOn is missing or older than PROPERTY_APPRAISAL_MAX_AGE draws a snapshot warning. The body's token, when given, must match the path. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string           true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string           false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string           true   "Entity slug"  example(mtlf)
+// @Param        token          path    string           true   "Asset code"  example(MCITY136)
+// @Param        body           body    PropertyRequest  true   "Property data"
+// @Success      200  {object}  admin.Property
+// @Success      201  {object}  admin.Property
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/properties/{token} [put]
+func (h *PropertyHandler) PutProperty(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body PropertyRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	token := r.PathValue("token")
+	if body.Token != "" && body.Token != token {
+		writeError(w, http.StatusBadRequest, "token in body does not match the path")
+		return
+	}
+	p := property.Property{
+		Token:          token,
+		Name:           body.Name,
+		Location:       body.Location,
+		AreaM2:         body.AreaM2,
+		PriceSymbol:    body.PriceSymbol,
+		AppraisalValue: body.AppraisalValue,
+	}
+	if body.AppraisedOn != "" {
+		d, err := time.Parse("2006-01-02", body.AppraisedOn)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid appraisedOn, expected YYYY-MM-DD")
+			return
+		}
+		p.AppraisedOn = &d
+	}
+
+	slug := r.PathValue("slug")
+	saved, err := h.store.SaveProperty(r.Context(), slug, p, ch)
+	if err != nil {
+		writeAdminError(w, err, "save property")
+		return
+	}
+	slog.Info("property saved", "entity", slug, "token", token, "version", saved.Version)
+	status := http.StatusOK
+	if saved.Version == 1 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("ETag", etag(saved.Version))
+	writeJSON(w, status, saved)
+}
+
+// DeleteProperty handles DELETE /api/v1/admin/entities/{slug}/properties/{token}.
+//
+// @Summary      Delete a registered property
+// @Description  From the next snapshot the token is valued by its DATA entries again. With If-Match the delete only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Param        Authorization  header  string  true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string  false  "Version ETag from GET, e.g. \"3\""
+// @Param        slug           path    string  true   "Entity slug"  example(mtlf)
+// @Param        token          path    string  true   "Asset code"  example(MCITY136)
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/entities/{slug}/properties/{token} [delete]
+func (h *PropertyHandler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	slug, token := r.PathValue("slug"), r.PathValue("token")
+	if err := h.store.DeleteProperty(r.Context(), slug, token, ch); err != nil {
+		writeAdminError(w, err, "delete property")
+		return
+	}
+	slog.Info("property deleted", "entity", slug, "token", token)
+	w.WriteHeader(http.StatusNoContent)
+}
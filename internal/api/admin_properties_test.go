@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/property"
+)
+
+type stubPropertyStore struct {
+	props map[string]admin.Property
+}
+
+func (s *stubPropertyStore) Properties(_ context.Context, slug string) ([]admin.Property, error) {
+	if slug != "mtlf" {
+		return nil, admin.ErrNotFound
+	}
+	var out []admin.Property
+	for _, p := range s.props {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (s *stubPropertyStore) Property(_ context.Context, _, token string) (admin.Property, error) {
+	p, ok := s.props[token]
+	if !ok {
+		return admin.Property{}, admin.ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *stubPropertyStore) SaveProperty(_ context.Context, _ string, p property.Property, ch admin.Change) (admin.Property, error) {
+	if err := admin.ValidateProperty(p); err != nil {
+		return admin.Property{}, err
+	}
+	cur := s.props[p.Token]
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.Property{}, admin.ErrVersionMismatch
+	}
+	saved := admin.Property{Property: p, Version: cur.Version + 1}
+	s.props[p.Token] = saved
+	return saved, nil
+}
+
+func (s *stubPropertyStore) DeleteProperty(_ context.Context, _, token string, ch admin.Change) error {
+	cur, ok := s.props[token]
+	if !ok {
+		return admin.ErrNotFound
+	}
+	if ch.IfMatch != 0 && ch.IfMatch != cur.Version {
+		return admin.ErrVersionMismatch
+	}
+	delete(s.props, token)
+	return nil
+}
+
+func TestProperties(t *testing.T) {
+	store := &stubPropertyStore{props: map[string]admin.Property{}}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Properties: store}))
+	path := "/api/v1/admin/entities/mtlf/properties/MCITY136"
+
+	w := sendAdmin(t, srv, http.MethodPut, path, `{"name":"Rozino 12","areaM2":"54.3","priceSymbol":"M2_BUDVA","appraisedOn":"2026-03-01"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("create: status = %d, ETag = %q: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	p := store.props["MCITY136"]
+	if p.AreaM2.String() != "54.3" || p.AppraisedOn == nil || p.AppraisedOn.Format("2006-01-02") != "2026-03-01" {
+		t.Errorf("saved = %+v", p.Property)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodGet, "/api/v1/admin/entities/mtlf/properties", ""); w.Code != http.StatusOK {
+		t.Errorf("list: status = %d", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"token":"OTHER","areaM2":"1","priceSymbol":"M2"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("mismatched token: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"areaM2":"54.3","priceSymbol":"M2","appraisedOn":"March"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid date: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"areaM2":"54.3"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("no price symbol or appraisal: status = %d, want 400", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodPut, path, `{"areaM2":"60","appraisalValue":"170000"}`, "If-Match", `"1"`); w.Code != http.StatusOK {
+		t.Errorf("update: status = %d, want 200", w.Code)
+	}
+
+	if w := sendAdmin(t, srv, http.MethodDelete, path, "", "If-Match", `"1"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale DELETE: status = %d, want 412", w.Code)
+	}
+	if w := sendAdmin(t, srv, http.MethodDelete, path, ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status = %d, want 204", w.Code)
+	}
+}
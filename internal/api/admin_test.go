@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/transport"
+)
+
+type stubPipeline struct{}
+
+func (stubPipeline) CacheSizes() map[string]int { return map[string]int{"prices": 12, "quotes": 3} }
+func (stubPipeline) HorizonInFlight() int       { return 2 }
+
+func serveAdmin(t *testing.T, srv *http.Server, method, target, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestDiagnostics(t *testing.T) {
+	srv := NewServer("0", nil, nil,
+		WithLimits(Limits{RPS: 100, Burst: 100}),
+		WithAdmin(Admin{Token: "s3cret", Pipeline: stubPipeline{}, Upstream: stubUpstream{}}))
+
+	w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/diagnostics", "s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var d Diagnostics
+	if err := json.NewDecoder(w.Body).Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Goroutines < 1 || d.Heap.AllocBytes == 0 {
+		t.Errorf("runtime stats missing: %+v", d)
+	}
+	if d.Caches["prices"] != 12 || d.Caches["quotes"] != 3 || d.Caches["rateLimitBuckets"] != 1 {
+		t.Errorf("caches = %v", d.Caches)
+	}
+	if d.HorizonInFlight == nil || *d.HorizonInFlight != 2 {
+		t.Errorf("horizonInFlight = %v, want 2", d.HorizonInFlight)
+	}
+	if len(d.Upstream) != 1 || d.Upstream[0].Host != "horizon.stellar.org" || d.Upstream[0].Requests != 40 {
+		t.Errorf("upstream = %+v", d.Upstream)
+	}
+}
+
+type stubUpstream struct{}
+
+func (stubUpstream) Stats() []transport.HostStats {
+	return []transport.HostStats{{Host: "horizon.stellar.org", Requests: 40, Failures: 1}}
+}
+
+func TestAdminAuth(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Pprof: true}))
+
+	for _, token := range []string{"", "wrong"} {
+		w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/diagnostics", token)
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("token %q: status = %d, want 401 with challenge", token, w.Code)
+		}
+	}
+	if w := serveAdmin(t, srv, http.MethodGet, "/debug/pprof/", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("pprof without token: status = %d, want 401", w.Code)
+	}
+	if w := serveAdmin(t, srv, http.MethodGet, "/debug/pprof/goroutine?debug=1", "s3cret"); w.Code != http.StatusOK {
+		t.Errorf("pprof goroutine: status = %d, want 200", w.Code)
+	}
+
+	var d Diagnostics
+	w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/diagnostics", "s3cret")
+	if err := json.NewDecoder(w.Body).Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+	if d.HorizonInFlight != nil {
+		t.Errorf("horizonInFlight = %d without a pipeline, want absent", *d.HorizonInFlight)
+	}
+}
+
+func TestAdminNotMountedWithoutToken(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Pprof: true}))
+	for _, target := range []string{"/api/v1/admin/diagnostics", "/debug/pprof/"} {
+		if w := serveAdmin(t, srv, http.MethodGet, target, ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", target, w.Code)
+		}
+	}
+}
+
+func TestAdminAddrSplitsListeners(t *testing.T) {
+	public, admin := NewServers("8080", nil, nil,
+		WithJobs(&mockJobQueue{}),
+		WithAdmin(Admin{Token: "s3cret"}),
+		WithAdminAddr("127.0.0.1:8081"))
+	if admin == nil || admin.Addr != "127.0.0.1:8081" {
+		t.Fatalf("admin server = %v, want one at 127.0.0.1:8081", admin)
+	}
+
+	for _, r := range []struct{ method, target string }{
+		{http.MethodGet, "/api/v1/admin/diagnostics"},
+		{http.MethodPost, "/api/v1/snapshots/generate"},
+		{http.MethodGet, "/api/v1/jobs/1"},
+	} {
+		if w := serveAdmin(t, public, r.method, r.target, "s3cret"); w.Code != http.StatusNotFound && w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("public %s %s: status = %d, want it unrouted", r.method, r.target, w.Code)
+		}
+		if w := serveAdmin(t, admin, r.method, r.target, "s3cret"); w.Code == http.StatusNotFound || w.Code == http.StatusMethodNotAllowed {
+			t.Errorf("admin %s %s: status = %d, want it routed", r.method, r.target, w.Code)
+		}
+	}
+	if w := serveAdmin(t, public, http.MethodGet, "/skill.md", ""); w.Code != http.StatusOK {
+		t.Errorf("public /skill.md: status = %d, want 200", w.Code)
+	}
+
+	if _, admin := NewServers("8080", nil, nil, WithAdmin(Admin{Token: "s3cret"})); admin != nil {
+		t.Error("admin server without WithAdminAddr, want nil")
+	}
+}
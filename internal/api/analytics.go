@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+// CorrelationSource computes asset correlation matrices.
+type CorrelationSource interface {
+	Correlations(ctx context.Context, windowDays int, now time.Time) (*analytics.Matrix, error)
+}
+
+// AnalyticsHandler provides cross-asset analytics endpoints.
+type AnalyticsHandler struct {
+	correlations CorrelationSource
+	clock        snapdate.Clock
+}
+
+// NewAnalyticsHandler creates a new analytics handler. Windows end on clock's
+// current snapshot date.
+func NewAnalyticsHandler(correlations CorrelationSource, clock snapdate.Clock) *AnalyticsHandler {
+	return &AnalyticsHandler{correlations: correlations, clock: clock}
+}
+
+// GetCorrelations handles GET /api/v1/analytics/correlations.
+//
+// @Summary      Asset return correlations
+// @Description  Pairwise Pearson correlations of daily returns between major fund holdings, from stored snapshot prices (MTL from I10 history). Prices are in EURMTL, so EURMTL itself is flat and its correlations are null; a pair is also null with fewer than two overlapping returns.
+// @Tags         analytics
+// @Produce      json
+// @Param        windows  query  string  false  "Comma-separated windows: any of 30d,90d,180d,365d, or 'all' (default 90d)"
+// @Success      200  {array}   analytics.Matrix
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/analytics/correlations [get]
+func (h *AnalyticsHandler) GetCorrelations(w http.ResponseWriter, r *http.Request) {
+	windows, err := parsePeriodList(r.URL.Query().Get("windows"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	if len(windows) == 0 {
+		windows = []int{90}
+	}
+
+	now := h.clock.Today()
+	out := make([]*analytics.Matrix, 0, len(windows))
+	for _, days := range windows {
+		m, err := h.correlations.Correlations(r.Context(), days, now)
+		if err != nil {
+			slog.Error("failed to compute correlations", "window_days", days, "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		out = append(out, m)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
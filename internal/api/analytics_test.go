@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+type stubCorrelations struct {
+	windows []int
+	err     error
+}
+
+func (s *stubCorrelations) Correlations(_ context.Context, windowDays int, _ time.Time) (*analytics.Matrix, error) {
+	s.windows = append(s.windows, windowDays)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &analytics.Matrix{WindowDays: windowDays, Assets: []string{"XLM"}}, nil
+}
+
+func TestGetCorrelationsDefaultWindow(t *testing.T) {
+	src := &stubCorrelations{}
+	h := NewAnalyticsHandler(src, snapdate.UTC)
+
+	w := httptest.NewRecorder()
+	h.GetCorrelations(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/correlations", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got []analytics.Matrix
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].WindowDays != 90 {
+		t.Errorf("got %+v, want one 90d matrix", got)
+	}
+}
+
+func TestGetCorrelationsWindows(t *testing.T) {
+	src := &stubCorrelations{}
+	h := NewAnalyticsHandler(src, snapdate.UTC)
+
+	w := httptest.NewRecorder()
+	h.GetCorrelations(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/correlations?windows=30d,365d", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if len(src.windows) != 2 || src.windows[0] != 30 || src.windows[1] != 365 {
+		t.Errorf("windows = %v, want [30 365]", src.windows)
+	}
+}
+
+func TestGetCorrelationsErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewAnalyticsHandler(&stubCorrelations{}, snapdate.UTC).GetCorrelations(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/correlations?windows=7d", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid window: status = %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	NewAnalyticsHandler(&stubCorrelations{err: errors.New("db down")}, snapdate.UTC).GetCorrelations(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/correlations", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("source error: status = %d, want 500", w.Code)
+	}
+}
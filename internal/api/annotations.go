@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/annotation"
+)
+
+// AnnotationSource reads snapshot date annotations (annotation.PgRepository).
+type AnnotationSource interface {
+	List(ctx context.Context, slug string, from, to time.Time) ([]annotation.Annotation, error)
+}
+
+// AnnotationsHandler serves snapshot date annotations.
+type AnnotationsHandler struct {
+	source AnnotationSource
+}
+
+// NewAnnotationsHandler creates a new annotation handler.
+func NewAnnotationsHandler(source AnnotationSource) *AnnotationsHandler {
+	return &AnnotationsHandler{source: source}
+}
+
+// GetAnnotations handles GET /api/v1/snapshots/annotations.
+//
+// @Summary      Snapshot annotations
+// @Description  Operator notes attached to snapshot dates (a tranche received, a valuation methodology change) explaining anomalous data points, oldest first. API v2 also returns them with GET /api/v1/indicators.
+// @Tags         snapshots
+// @Produce      json
+// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {array}   annotation.Annotation
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/snapshots/annotations [get]
+func (h *AnnotationsHandler) GetAnnotations(w http.ResponseWriter, r *http.Request) {
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	annotations, err := h.source.List(r.Context(), fundSlug, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to list annotations", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if annotations == nil {
+		annotations = []annotation.Annotation{}
+	}
+	writeJSON(w, http.StatusOK, annotations)
+}
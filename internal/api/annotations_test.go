@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/annotation"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+type stubAnnotations struct {
+	annotations []annotation.Annotation
+	err         error
+	from, to    time.Time
+}
+
+func (s *stubAnnotations) List(_ context.Context, _ string, from, to time.Time) ([]annotation.Annotation, error) {
+	s.from, s.to = from, to
+	return s.annotations, s.err
+}
+
+func TestGetAnnotations(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	srv := NewServer("0", nil, nil, WithAnnotations(&stubAnnotations{annotations: []annotation.Annotation{
+		{ID: 1, Date: day, Text: "DEFI received EUR 200k tranche", Tags: []string{"defi"}},
+	}}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/snapshots/annotations?range=all", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got []annotation.Annotation
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Text != "DEFI received EUR 200k tranche" || !got[0].Date.Equal(day) {
+		t.Errorf("annotations = %+v", got)
+	}
+
+	srv = NewServer("0", nil, nil, WithAnnotations(&stubAnnotations{}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/snapshots/annotations", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q, want 200 []", w.Code, w.Body)
+	}
+
+	srv = NewServer("0", nil, nil, WithAnnotations(&stubAnnotations{err: errors.New("db down")}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/snapshots/annotations", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("db error: status = %d, want 500", w.Code)
+	}
+}
+
+func TestIndicatorsV2Annotations(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockIndicatorRepo{latest: []indicator.Indicator{sampleIndicator(3, "200")}, latestDate: date}
+	notes := &stubAnnotations{annotations: []annotation.Annotation{{ID: 7, Date: date.AddDate(0, 0, -10), Text: "valuation methodology change"}}}
+	srv := NewServer("0", nil, repo, WithAnnotations(notes))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/indicators?compare=30d,90d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var set IndicatorSet
+	if err := json.NewDecoder(w.Body).Decode(&set); err != nil {
+		t.Fatal(err)
+	}
+	if set.Date != "2026-10-01" || len(set.Indicators) != 1 || len(set.Annotations) != 1 || set.Annotations[0].ID != 7 {
+		t.Errorf("v2 set = %+v", set)
+	}
+	if !notes.from.Equal(date.AddDate(0, 0, -90)) || !notes.to.Equal(date) {
+		t.Errorf("annotation window = %s..%s, want the 90d comparison window", notes.from, notes.to)
+	}
+
+	// Without compare only the date itself is annotated.
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators", nil)
+	req.Header.Set("Accept", "application/vnd.mtlstat.v2+json")
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !notes.from.Equal(date) {
+		t.Errorf("no compare: status = %d, from = %s, want %s", w.Code, notes.from, date)
+	}
+
+	// v1 keeps the plain array.
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/indicators", nil))
+	var items []IndicatorWithChanges
+	if err := json.NewDecoder(w.Body).Decode(&items); err != nil || len(items) != 1 {
+		t.Errorf("v1: items = %+v, err = %v", items, err)
+	}
+
+	// Without an annotation source v2 still returns the set, unannotated.
+	w = httptest.NewRecorder()
+	NewServer("0", nil, repo).Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/indicators", nil))
+	if w.Code != http.StatusOK || !json.Valid(w.Body.Bytes()) || !strings.Contains(w.Body.String(), `"annotations":[]`) {
+		t.Errorf("no source: status = %d, body = %s", w.Code, w.Body)
+	}
+}
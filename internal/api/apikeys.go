@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/stat/internal/apikey"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+// apiKeyHeader carries a partner API key.
+const apiKeyHeader = "X-API-Key"
+
+// KeyVerifier resolves presented API keys and counts their requests
+// (apikey.PgRepository).
+type KeyVerifier interface {
+	Lookup(ctx context.Context, token string) (*apikey.Key, error)
+	RecordUsage(ctx context.Context, id int64, date time.Time, group string) error
+}
+
+// KeyStore issues, lists and revokes API keys (apikey.PgRepository).
+type KeyStore interface {
+	Issue(ctx context.Context, req apikey.Request) (*apikey.Issued, error)
+	List(ctx context.Context) ([]apikey.Key, error)
+	Revoke(ctx context.Context, id int64) error
+	Usage(ctx context.Context, id int64, from time.Time) ([]apikey.Usage, error)
+}
+
+// APIKeys configures API key enforcement. A request carrying X-API-Key is
+// checked against the key's entity and route groups: 401 for an unknown or
+// revoked key, 403 outside its scope. Requests without a key stay anonymous
+// unless Required is set, which answers them 401.
+type APIKeys struct {
+	Verifier KeyVerifier
+	Required bool
+}
+
+// apiKeyMiddleware enforces k on the keyed API (apikey.RouteGroup); admin
+// routes, docs and static files pass through. Usage is counted per key,
+// snapshot date and route group; a failed count is logged, not fatal.
+func apiKeyMiddleware(k APIKeys, clock snapdate.Clock, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group, keyed := apikey.RouteGroup(r.URL.Path)
+		if !keyed {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := r.Header.Get(apiKeyHeader)
+		if token == "" {
+			if k.Required {
+				writeProblem(w, http.StatusUnauthorized, CodeAPIKeyRequired, "API key required ("+apiKeyHeader+" header)")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := k.Verifier.Lookup(r.Context(), token)
+		if errors.Is(err, apikey.ErrNotFound) {
+			writeProblem(w, http.StatusUnauthorized, CodeInvalidAPIKey, "invalid or revoked API key")
+			return
+		}
+		if err != nil {
+			slog.Error("failed to look up API key", "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		// Every keyed route serves the fund entity until routes take one.
+		if !key.Allows(fundSlug, group) {
+			writeError(w, http.StatusForbidden, "API key not valid for "+group+" of "+fundSlug)
+			return
+		}
+		if err := k.Verifier.RecordUsage(r.Context(), key.ID, clock.Today(), group); err != nil {
+			slog.Error("failed to record API key usage", "key", key.ID, "group", group, "error", err)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// KeyHandler serves the API key administration endpoints.
+type KeyHandler struct {
+	store KeyStore
+}
+
+// NewKeyHandler creates a new API key administration handler.
+func NewKeyHandler(store KeyStore) *KeyHandler {
+	return &KeyHandler{store: store}
+}
+
+// ListKeys handles GET /api/v1/admin/keys.
+//
+// @Summary      List API keys
+// @Description  All issued partner API keys, newest first, revoked ones included, with their all-time request count. Tokens are never returned. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Success      200  {array}   apikey.Key
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/keys [get]
+func (h *KeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.store.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list API keys", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if keys == nil {
+		keys = []apikey.Key{}
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// IssueKey handles POST /api/v1/admin/keys.
+//
+// @Summary      Issue an API key
+// @Description  Issues a partner API key that reads one entity's data through the listed route groups (snapshots, indicators, charts, analytics, reports, accounts, forecast, issuance, holders, alerts, valuations, status, jobs, watchlist, groups, subfonds, intraday, monitoring, compat). The token is returned only in this response; send it as X-API-Key. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string          true  "Bearer ADMIN_TOKEN"
+// @Param        body           body    apikey.Request  true  "Key scope"
+// @Success      201  {object}  apikey.Issued
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/keys [post]
+func (h *KeyHandler) IssueKey(w http.ResponseWriter, r *http.Request) {
+	var req apikey.Request
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	issued, err := h.store.Issue(r.Context(), req)
+	if errors.Is(err, apikey.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("failed to issue API key", "name", req.Name, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.Info("API key issued", "id", issued.ID, "name", issued.Name, "entity", issued.Entity, "groups", issued.Groups)
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+// RevokeKey handles DELETE /api/v1/admin/keys/{id}.
+//
+// @Summary      Revoke an API key
+// @Description  Revokes the key immediately. Its row and usage history are kept. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Param        id             path    int     true  "Key ID"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/keys/{id} [delete]
+func (h *KeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid key id")
+		return
+	}
+	if err := h.store.Revoke(r.Context(), id); err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		slog.Error("failed to revoke API key", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.Info("API key revoked", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetKeyUsage handles GET /api/v1/admin/keys/{id}/usage.
+//
+// @Summary      API key usage
+// @Description  Requests of one key per day and route group, oldest first. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer ADMIN_TOKEN"
+// @Param        id             path    int     true   "Key ID"
+// @Param        range          query   string  false  "30d, 90d (default), 180d, 365d or all"
+// @Success      200  {array}   apikey.Usage
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/keys/{id}/usage [get]
+func (h *KeyHandler) GetKeyUsage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid key id")
+		return
+	}
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	usage, err := h.store.Usage(r.Context(), id, from)
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		slog.Error("failed to load API key usage", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/apikey"
+)
+
+// stubKeys implements KeyVerifier and KeyStore over a fixed token → key map.
+type stubKeys struct {
+	keys    map[string]apikey.Key
+	usage   []string // "id/group" per recorded request
+	issued  *apikey.Request
+	revoked []int64
+}
+
+func (s *stubKeys) Lookup(_ context.Context, token string) (*apikey.Key, error) {
+	k, ok := s.keys[token]
+	if !ok || k.RevokedAt != nil {
+		return nil, apikey.ErrNotFound
+	}
+	return &k, nil
+}
+
+func (s *stubKeys) RecordUsage(_ context.Context, id int64, _ time.Time, group string) error {
+	s.usage = append(s.usage, fmt.Sprintf("%d/%s", id, group))
+	return nil
+}
+
+func (s *stubKeys) Issue(_ context.Context, req apikey.Request) (*apikey.Issued, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	s.issued = &req
+	return &apikey.Issued{Key: apikey.Key{ID: 7, Name: req.Name, Entity: req.Entity, Groups: req.Groups}, Token: "stat_new"}, nil
+}
+
+func (s *stubKeys) List(context.Context) ([]apikey.Key, error) {
+	var out []apikey.Key
+	for _, k := range s.keys {
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func (s *stubKeys) Revoke(_ context.Context, id int64) error {
+	if id != 1 {
+		return apikey.ErrNotFound
+	}
+	s.revoked = append(s.revoked, id)
+	return nil
+}
+
+func (s *stubKeys) Usage(_ context.Context, id int64, _ time.Time) ([]apikey.Usage, error) {
+	if id != 1 {
+		return nil, apikey.ErrNotFound
+	}
+	return []apikey.Usage{{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Group: "reports", Requests: 3}}, nil
+}
+
+func newKeyStub() *stubKeys {
+	revoked := time.Now()
+	return &stubKeys{keys: map[string]apikey.Key{
+		"stat_fund":    {ID: 1, Entity: fundSlug, Groups: []string{"reports", "compat"}},
+		"stat_mtla":    {ID: 2, Entity: "mtla", Groups: []string{"reports"}},
+		"stat_revoked": {ID: 3, Entity: fundSlug, Groups: []string{"reports"}, RevokedAt: &revoked},
+	}}
+}
+
+func keyedGet(srv *http.Server, target, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if key != "" {
+		req.Header.Set(apiKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyEnforcement(t *testing.T) {
+	keys := newKeyStub()
+	srv := NewServer("0", nil, nil, WithReports(&stubReports{}), WithAPIKeys(APIKeys{Verifier: keys}))
+
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{"anonymous", "", http.StatusNotFound}, // reaches the handler: no stored report
+		{"in scope", "stat_fund", http.StatusNotFound},
+		{"unknown key", "stat_nope", http.StatusUnauthorized},
+		{"revoked key", "stat_revoked", http.StatusUnauthorized},
+		{"other entity", "stat_mtla", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if w := keyedGet(srv, "/api/v1/reports/2026-09", tt.key); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+	if w := keyedGet(srv, "/api/v1/status", "stat_fund"); w.Code != http.StatusForbidden {
+		t.Errorf("group out of scope: status = %d, want 403", w.Code)
+	}
+	if len(keys.usage) != 1 || keys.usage[0] != "1/reports" {
+		t.Errorf("usage = %v, want one reports request of key 1", keys.usage)
+	}
+}
+
+func TestAPIKeyRequired(t *testing.T) {
+	keys := newKeyStub()
+	srv := NewServer("0", nil, nil, WithReports(&stubReports{}), WithAPIKeys(APIKeys{Verifier: keys, Required: true}))
+
+	if w := keyedGet(srv, "/api/v1/reports/2026-09", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+	if w := keyedGet(srv, "/api/v1/reports/2026-09", "stat_fund"); w.Code != http.StatusNotFound {
+		t.Errorf("keyed: status = %d, want 404 from the handler", w.Code)
+	}
+	// Docs stay public.
+	if w := keyedGet(srv, "/skill.md", ""); w.Code != http.StatusOK {
+		t.Errorf("skill.md: status = %d, want 200", w.Code)
+	}
+}
+
+func TestAdminKeys(t *testing.T) {
+	keys := newKeyStub()
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Keys: keys}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/keys",
+		strings.NewReader(`{"name":"MTLA dashboard","entity":"mtlf","groups":["snapshots","indicators"]}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, want 201: %s", w.Code, w.Body)
+	}
+	var issued apikey.Issued
+	if err := json.NewDecoder(w.Body).Decode(&issued); err != nil {
+		t.Fatal(err)
+	}
+	if issued.Token != "stat_new" || keys.issued == nil || keys.issued.Name != "MTLA dashboard" {
+		t.Errorf("issued = %+v, request = %+v", issued, keys.issued)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/keys", strings.NewReader(`{"name":"x","entity":"mtlf","groups":["admin"]}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown group: status = %d, want 400", w.Code)
+	}
+
+	if w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/keys", "s3cret"); w.Code != http.StatusOK {
+		t.Errorf("GET status = %d, want 200", w.Code)
+	}
+	if w := serveAdmin(t, srv, http.MethodDelete, "/api/v1/admin/keys/1", "s3cret"); w.Code != http.StatusNoContent || len(keys.revoked) != 1 {
+		t.Errorf("DELETE status = %d, revoked = %v", w.Code, keys.revoked)
+	}
+	if w := serveAdmin(t, srv, http.MethodDelete, "/api/v1/admin/keys/9", "s3cret"); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE unknown: status = %d, want 404", w.Code)
+	}
+	w = serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/keys/1/usage?range=30d", "s3cret")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"requests":3`) {
+		t.Errorf("usage: status = %d, body = %s", w.Code, w.Body)
+	}
+	if w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/keys", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no admin token: status = %d, want 401", w.Code)
+	}
+}
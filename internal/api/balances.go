@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// BalanceHistorySource reads per-account balance history.
+type BalanceHistorySource interface {
+	GetAccountBalanceHistory(ctx context.Context, entitySlug, account, assetKey string, from, to time.Time) ([]snapshot.BalancePoint, error)
+}
+
+// BalanceHistoryResponse is one account's balance of one asset over time.
+type BalanceHistoryResponse struct {
+	Account string                  `json:"account"`
+	Asset   string                  `json:"asset"`
+	Points  []snapshot.BalancePoint `json:"points"`
+}
+
+// BalanceHandler serves account balance history.
+type BalanceHandler struct {
+	source BalanceHistorySource
+}
+
+// NewBalanceHandler creates a new balance history handler.
+func NewBalanceHandler(source BalanceHistorySource) *BalanceHandler {
+	return &BalanceHandler{source: source}
+}
+
+var (
+	accountPattern  = regexp.MustCompile(`^G[A-Z2-7]{55}$`)
+	assetKeyPattern = regexp.MustCompile(`^(XLM|[A-Za-z0-9]{1,12}-G[A-Z2-7]{55})$`)
+)
+
+// GetBalanceHistory handles GET /api/v1/accounts/{address}/balances/{asset}/history.
+//
+// @Summary      Account balance history
+// @Description  Returns one account's balance of one asset on each snapshot date in the range, oldest first, read from the account_balances table rather than full snapshots. Dates on which the account had no trustline for the asset are omitted; zero balances on existing trustlines are included.
+// @Tags         accounts
+// @Produce      json
+// @Param        address  path   string  true   "Stellar account (G...)"
+// @Param        asset    path   string  true   "XLM or CODE-ISSUER"
+// @Param        range    query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {object}  BalanceHistoryResponse
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/accounts/{address}/balances/{asset}/history [get]
+func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	account, asset := r.PathValue("address"), r.PathValue("asset")
+	if !accountPattern.MatchString(account) {
+		writeError(w, http.StatusBadRequest, "invalid account address")
+		return
+	}
+	if !assetKeyPattern.MatchString(asset) {
+		writeError(w, http.StatusBadRequest, "invalid asset, expected XLM or CODE-ISSUER")
+		return
+	}
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+
+	points, err := h.source.GetAccountBalanceHistory(r.Context(), fundSlug, account, asset, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to fetch balance history", "account", account, "asset", asset, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if points == nil {
+		points = []snapshot.BalancePoint{}
+	}
+	writeJSON(w, http.StatusOK, BalanceHistoryResponse{Account: account, Asset: asset, Points: points})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+type stubBalances struct {
+	account, asset string
+	from           time.Time
+	points         []snapshot.BalancePoint
+}
+
+func (s *stubBalances) GetAccountBalanceHistory(_ context.Context, _, account, assetKey string, from, _ time.Time) ([]snapshot.BalancePoint, error) {
+	s.account, s.asset, s.from = account, assetKey, from
+	return s.points, nil
+}
+
+var (
+	testAccount = "GA" + strings.Repeat("B", 54)
+	testAsset   = "EURMTL-GC" + strings.Repeat("D", 54)
+)
+
+func TestGetBalanceHistory(t *testing.T) {
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	stub := &stubBalances{points: []snapshot.BalancePoint{
+		{Date: day, Balance: decimal.NewFromInt(100)},
+		{Date: day.AddDate(0, 0, 1), Balance: decimal.Zero},
+	}}
+	srv := NewServer("0", nil, nil, WithBalances(stub))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+testAccount+"/balances/"+testAsset+"/history?range=30d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var got BalanceHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Account != testAccount || got.Asset != testAsset || len(got.Points) != 2 || !got.Points[0].Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("response = %+v", got)
+	}
+	if stub.account != testAccount || stub.asset != testAsset {
+		t.Errorf("queried %s %s", stub.account, stub.asset)
+	}
+	if want := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -30); !stub.from.Equal(want) {
+		t.Errorf("from = %s, want %s", stub.from, want)
+	}
+}
+
+func TestGetBalanceHistoryEmpty(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithBalances(&stubBalances{}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+testAccount+"/balances/XLM/history", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"points":[]`) {
+		t.Errorf("status = %d, body = %s, want 200 with empty points", w.Code, w.Body.String())
+	}
+}
+
+func TestGetBalanceHistoryBadRequest(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithBalances(&stubBalances{}))
+	for _, target := range []string{
+		"/api/v1/accounts/GSHORT/balances/XLM/history",
+		"/api/v1/accounts/" + testAccount + "/balances/EURMTL/history",
+		"/api/v1/accounts/" + testAccount + "/balances/XLM/history?range=7y",
+	} {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", target, w.Code)
+		}
+	}
+}
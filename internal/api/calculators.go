@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// CalculatorLister reports the indicator calculators known to the server.
+type CalculatorLister interface {
+	Calculators() []indicator.CalculatorInfo
+}
+
+// CalculatorHandler exposes the indicator calculator registry.
+type CalculatorHandler struct {
+	calcs CalculatorLister
+}
+
+// NewCalculatorHandler creates a new calculator handler.
+func NewCalculatorHandler(calcs CalculatorLister) *CalculatorHandler {
+	return &CalculatorHandler{calcs: calcs}
+}
+
+// ListCalculators handles GET /api/v1/indicators/calculators.
+//
+// @Summary      Indicator calculators
+// @Description  Lists registered indicator calculators in initialization order with the indicator IDs each produces, the IDs it depends on, and the calculators providing them. Calculators switched off via INDICATOR_DISABLED_CALCULATORS (or depending on one that is) have enabled=false.
+// @Tags         indicators
+// @Produce      json
+// @Success      200  {array}  indicator.CalculatorInfo
+// @Router       /api/v1/indicators/calculators [get]
+func (h *CalculatorHandler) ListCalculators(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.calcs.Calculators())
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func TestListCalculators(t *testing.T) {
+	svc := indicator.NewService(nil, indicator.WithDisabledCalculators("layer2"))
+	srv := NewServer("0", nil, nil, WithCalculators(svc))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators/calculators", nil)
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got []indicator.CalculatorInfo
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got) == 0 || got[0].Name != "layer0" {
+		t.Fatalf("calculators = %+v, want layer0 first", got)
+	}
+	byName := make(map[string]indicator.CalculatorInfo)
+	for _, c := range got {
+		byName[c.Name] = c
+	}
+	if c := byName["layer2"]; c.Enabled || c.DisabledBy != "config" {
+		t.Errorf("layer2 = %+v, want disabled by config", c)
+	}
+	if c := byName["tokenomics"]; c.Enabled || c.DisabledBy != "layer2" {
+		t.Errorf("tokenomics = %+v, want disabled by layer2", c)
+	}
+	if c := byName["layer1"]; !c.Enabled || len(c.DependsOn) != 1 || c.DependsOn[0] != "layer0" {
+		t.Errorf("layer1 = %+v, want enabled, depends on layer0", c)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,10 +33,16 @@ type BalanceBySubfundResponse struct {
 	Slices []SubfundSlice `json:"slices"`
 }
 
-// HistoryPoint is a single (date, value) sample in a time series.
+// HistoryPoint is a single (date, value) sample in a time series. A weekly
+// or monthly point is dated by the last day of its period with a value and
+// carries that day's value, plus the period's start, min, max and average.
 type HistoryPoint struct {
-	Date  string          `json:"date"` // YYYY-MM-DD
-	Value decimal.Decimal `json:"value"`
+	Date        string           `json:"date"` // YYYY-MM-DD
+	Value       decimal.Decimal  `json:"value"`
+	PeriodStart string           `json:"periodStart,omitempty"` // YYYY-MM-DD
+	Min         *decimal.Decimal `json:"min,omitempty"`
+	Max         *decimal.Decimal `json:"max,omitempty"`
+	Avg         *decimal.Decimal `json:"avg,omitempty"`
 }
 
 // IndicatorSeries is one indicator's time series.
@@ -48,13 +55,21 @@ type IndicatorSeries struct {
 
 // IndicatorHistoryResponse is the response for GET /api/v1/charts/indicator-history.
 type IndicatorHistoryResponse struct {
-	Series []IndicatorSeries `json:"series"`
+	Resolution indicator.Resolution `json:"resolution"`
+	Series     []IndicatorSeries    `json:"series"`
+}
+
+// HistoryAggregateSource reads the weekly and monthly indicator aggregates
+// (indicator.PgRepository).
+type HistoryAggregateSource interface {
+	GetAggregates(ctx context.Context, slug string, ids []int, res indicator.Resolution, from time.Time) ([]indicator.AggregatePoint, error)
 }
 
 // ChartsHandler provides chart-data endpoints.
 type ChartsHandler struct {
-	snapshots *snapshot.Service
-	repo      indicator.Repository
+	snapshots  *snapshot.Service
+	repo       indicator.Repository
+	aggregates HistoryAggregateSource // set by NewServer; nil serves daily points only
 }
 
 // NewChartsHandler creates a new charts handler.
@@ -70,8 +85,8 @@ func NewChartsHandler(snapshots *snapshot.Service, repo indicator.Repository) *C
 // @Produce      json
 // @Param        date  query  string  false  "Snapshot date (YYYY-MM-DD); defaults to latest"
 // @Success      200  {object}  BalanceBySubfundResponse
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
 // @Router       /api/v1/charts/balance-by-subfund [get]
 func (h *ChartsHandler) GetBalanceBySubfund(w http.ResponseWriter, r *http.Request) {
 	dateStr := r.URL.Query().Get("date")
@@ -81,7 +96,7 @@ func (h *ChartsHandler) GetBalanceBySubfund(w http.ResponseWriter, r *http.Reque
 	if dateStr != "" {
 		date, parseErr := time.Parse("2006-01-02", dateStr)
 		if parseErr != nil {
-			writeError(w, http.StatusBadRequest, "invalid date format, expected YYYY-MM-DD")
+			writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
 			return
 		}
 		snap, err = h.snapshots.GetByDate(r.Context(), fundSlug, date)
@@ -90,7 +105,7 @@ func (h *ChartsHandler) GetBalanceBySubfund(w http.ResponseWriter, r *http.Reque
 	}
 	if err != nil {
 		if errors.Is(err, snapshot.ErrNotFound) {
-			writeError(w, http.StatusNotFound, "snapshot not found")
+			writeProblem(w, http.StatusNotFound, CodeSnapshotNotFound, "snapshot not found")
 			return
 		}
 		slog.Error("failed to fetch snapshot for subfund pie", "date", dateStr, "error", err)
@@ -136,13 +151,14 @@ func (h *ChartsHandler) GetBalanceBySubfund(w http.ResponseWriter, r *http.Reque
 // GetIndicatorHistory handles GET /api/v1/charts/indicator-history.
 //
 // @Summary      Indicator time-series
-// @Description  Returns historical points for one or more indicator IDs over the requested range.
+// @Description  Returns historical points for one or more indicator IDs over the requested range. By default the resolution follows the range: daily up to 180d, weekly up to two years, monthly beyond ('all'). Weekly and monthly points are dated by the last day of the period with a value, carry that value, and add periodStart, min, max and avg.
 // @Tags         charts
 // @Produce      json
-// @Param        ids    query  string  true   "Comma-separated indicator IDs (e.g. 1,3,17,24,27)"
-// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Param        ids         query  string  true   "Comma-separated indicator IDs (e.g. 1,3,17,24,27)"
+// @Param        range       query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Param        resolution  query  string  false  "day, week, month, or auto (default)"
 // @Success      200  {object}  IndicatorHistoryResponse
-// @Failure      400  {object}  map[string]string
+// @Failure      400  {object}  Problem
 // @Router       /api/v1/charts/indicator-history [get]
 func (h *ChartsHandler) GetIndicatorHistory(w http.ResponseWriter, r *http.Request) {
 	idsStr := r.URL.Query().Get("ids")
@@ -158,7 +174,29 @@ func (h *ChartsHandler) GetIndicatorHistory(w http.ResponseWriter, r *http.Reque
 
 	from, err := parseHistoryRange(r.URL.Query().Get("range"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+
+	res := indicator.ResolutionFor(from, time.Now().UTC())
+	if s := r.URL.Query().Get("resolution"); s != "" && s != "auto" {
+		if res, err = indicator.ParseResolution(s); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if h.aggregates == nil {
+		res = indicator.ResolutionDay
+	}
+
+	if res != indicator.ResolutionDay {
+		aggs, err := h.aggregates.GetAggregates(r.Context(), fundSlug, ids, res, from)
+		if err != nil {
+			slog.Error("failed to fetch indicator aggregates", "resolution", res, "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		writeJSON(w, http.StatusOK, IndicatorHistoryResponse{Resolution: res, Series: groupAggregates(ids, aggs)})
 		return
 	}
 
@@ -169,7 +207,7 @@ func (h *ChartsHandler) GetIndicatorHistory(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	writeJSON(w, http.StatusOK, IndicatorHistoryResponse{Series: groupHistory(ids, points)})
+	writeJSON(w, http.StatusOK, IndicatorHistoryResponse{Resolution: res, Series: groupHistory(ids, points)})
 }
 
 // groupHistory groups history points by indicator ID, preserving the requested ID order
@@ -182,6 +220,26 @@ func groupHistory(ids []int, points []indicator.HistoryPoint) []IndicatorSeries
 			Value: p.Value,
 		})
 	}
+	return buildSeries(ids, pointsByID)
+}
+
+// groupAggregates is groupHistory for weekly or monthly aggregates.
+func groupAggregates(ids []int, aggs []indicator.AggregatePoint) []IndicatorSeries {
+	pointsByID := make(map[int][]HistoryPoint, len(ids))
+	for _, a := range aggs {
+		pointsByID[a.IndicatorID] = append(pointsByID[a.IndicatorID], HistoryPoint{
+			Date:        a.CloseDate.UTC().Format("2006-01-02"),
+			Value:       a.Close,
+			PeriodStart: a.PeriodStart.UTC().Format("2006-01-02"),
+			Min:         &a.Min,
+			Max:         &a.Max,
+			Avg:         &a.Avg,
+		})
+	}
+	return buildSeries(ids, pointsByID)
+}
+
+func buildSeries(ids []int, pointsByID map[int][]HistoryPoint) []IndicatorSeries {
 
 	series := make([]IndicatorSeries, len(ids))
 	for i, id := range ids {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -241,3 +242,71 @@ func TestParseHistoryRangeDefault(t *testing.T) {
 		t.Errorf("default from = %v, want %v", from, expected)
 	}
 }
+
+type stubAggregates struct {
+	res indicator.Resolution
+}
+
+func (s *stubAggregates) GetAggregates(_ context.Context, _ string, _ []int, res indicator.Resolution, _ time.Time) ([]indicator.AggregatePoint, error) {
+	s.res = res
+	return []indicator.AggregatePoint{{
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), CloseDate: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		IndicatorID: 1, Min: decimal.NewFromInt(90), Max: decimal.NewFromInt(120), Avg: decimal.NewFromInt(105), Close: decimal.NewFromInt(110), Samples: 5,
+	}}, nil
+}
+
+func TestGetIndicatorHistoryResolution(t *testing.T) {
+	aggs := &stubAggregates{}
+	handler := NewChartsHandler(snapshot.NewService(&mockFundService{}, &mockSnapshotRepo{}), &mockIndicatorRepo{})
+	handler.aggregates = aggs
+
+	for _, tc := range []struct {
+		query string
+		want  indicator.Resolution
+	}{
+		{"range=30d", indicator.ResolutionDay},
+		{"range=365d", indicator.ResolutionWeek},
+		{"range=all", indicator.ResolutionMonth},
+		{"range=all&resolution=week", indicator.ResolutionWeek},
+		{"range=365d&resolution=day", indicator.ResolutionDay},
+	} {
+		aggs.res = ""
+		w := httptest.NewRecorder()
+		handler.GetIndicatorHistory(w, httptest.NewRequest(http.MethodGet, "/api/v1/charts/indicator-history?ids=1&"+tc.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tc.query, w.Code)
+		}
+		var result IndicatorHistoryResponse
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if result.Resolution != tc.want {
+			t.Errorf("%s: resolution = %q, want %q", tc.query, result.Resolution, tc.want)
+		}
+		if tc.want == indicator.ResolutionDay {
+			continue
+		}
+		if aggs.res != tc.want {
+			t.Errorf("%s: aggregates read at %q", tc.query, aggs.res)
+		}
+		p := result.Series[0].Points[0]
+		if p.Date != "2024-01-05" || p.PeriodStart != "2024-01-01" || !p.Value.Equal(decimal.NewFromInt(110)) || p.Min == nil || !p.Avg.Equal(decimal.NewFromInt(105)) {
+			t.Errorf("%s: point = %+v", tc.query, p)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.GetIndicatorHistory(w, httptest.NewRequest(http.MethodGet, "/api/v1/charts/indicator-history?ids=1&resolution=hour", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad resolution: status = %d, want 400", w.Code)
+	}
+
+	// Without aggregates every range is served daily.
+	handler.aggregates = nil
+	w = httptest.NewRecorder()
+	handler.GetIndicatorHistory(w, httptest.NewRequest(http.MethodGet, "/api/v1/charts/indicator-history?ids=1&range=all", nil))
+	var result IndicatorHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil || result.Resolution != indicator.ResolutionDay {
+		t.Errorf("without aggregates: resolution = %q, err = %v", result.Resolution, err)
+	}
+}
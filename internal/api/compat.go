@@ -5,20 +5,19 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
-	"slices"
-	"time"
+
+	"github.com/samber/lo"
 
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/legacy"
 	"github.com/mtlprog/stat/internal/snapshot"
 )
 
-// compatFundStructure is the response format for GET /api/fund-structure (legacy).
-// It merges Accounts + MutualFunds into a single Accounts slice and omits
-// Warnings and LiveMetrics to match the old dreadnought API.
-type compatFundStructure struct {
-	Accounts         []domain.FundAccountPortfolio `json:"accounts"`
-	OtherAccounts    []domain.FundAccountPortfolio `json:"otherAccounts"`
-	AggregatedTotals domain.AggregatedTotals       `json:"aggregatedTotals"`
+// mountCompat registers the routes of the old stat API (dreadnought
+// frontend and community tools), answered in the old response shapes.
+func mountCompat(handle func(string, http.HandlerFunc), h *Handler) {
+	handle("GET /api/snapshots", h.ListSnapshotsCompat)
+	handle("GET /api/fund-structure", h.GetFundStructureCompat)
 }
 
 // ListSnapshotsCompat handles GET /api/snapshots (legacy).
@@ -29,10 +28,13 @@ func (h *Handler) ListSnapshotsCompat(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
-	writeJSON(w, http.StatusOK, metas)
+	writeJSON(w, http.StatusOK, lo.Map(metas, func(m snapshot.SnapshotMeta, _ int) legacy.SnapshotEntry {
+		return legacy.SnapshotEntry{Date: m.SnapshotDate, CreatedAt: m.CreatedAt}
+	}))
 }
 
-// GetFundStructureCompat handles GET /api/fund-structure (legacy).
+// GetFundStructureCompat handles GET /api/fund-structure (legacy). date may
+// be YYYY-MM-DD or an RFC 3339 timestamp, as the old API took.
 func (h *Handler) GetFundStructureCompat(w http.ResponseWriter, r *http.Request) {
 	dateStr := r.URL.Query().Get("date")
 
@@ -40,9 +42,9 @@ func (h *Handler) GetFundStructureCompat(w http.ResponseWriter, r *http.Request)
 	var err error
 
 	if dateStr != "" {
-		date, parseErr := time.Parse("2006-01-02", dateStr)
+		date, parseErr := legacy.ParseDateIn(dateStr, h.clock)
 		if parseErr != nil {
-			writeError(w, http.StatusBadRequest, "invalid date format, expected YYYY-MM-DD")
+			writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD or RFC 3339")
 			return
 		}
 		s, err = h.snapshots.GetByDate(r.Context(), "mtlf", date)
@@ -52,7 +54,7 @@ func (h *Handler) GetFundStructureCompat(w http.ResponseWriter, r *http.Request)
 
 	if err != nil {
 		if errors.Is(err, snapshot.ErrNotFound) {
-			writeError(w, http.StatusNotFound, "snapshot not found")
+			writeProblem(w, http.StatusNotFound, CodeSnapshotNotFound, "snapshot not found")
 			return
 		}
 		slog.Error("failed to get fund structure (compat)", "date", dateStr, "error", err)
@@ -67,10 +69,5 @@ func (h *Handler) GetFundStructureCompat(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	compat := compatFundStructure{
-		Accounts:         slices.Concat(data.Accounts, data.MutualFunds),
-		OtherAccounts:    data.OtherAccounts,
-		AggregatedTotals: data.AggregatedTotals,
-	}
-	writeJSON(w, http.StatusOK, compat)
+	writeJSON(w, http.StatusOK, legacy.ToLegacy(data))
 }
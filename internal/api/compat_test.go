@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/legacy"
 	"github.com/mtlprog/stat/internal/snapshot"
 	"github.com/shopspring/decimal"
 )
@@ -158,7 +159,7 @@ func TestGetFundStructureCompatByDate(t *testing.T) {
 		t.Fatalf("status = %d, want 200", w.Code)
 	}
 
-	var result compatFundStructure
+	var result legacy.FundStructure
 	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
 		t.Fatalf("failed to decode: %v", err)
 	}
@@ -194,3 +195,37 @@ func TestGetFundStructureCompatInvalidDate(t *testing.T) {
 		t.Errorf("status = %d, want 400", w.Code)
 	}
 }
+
+func TestGetFundStructureCompatOldShape(t *testing.T) {
+	fundData := testFundData()
+	fundData.Accounts = append(fundData.Accounts, domain.FundAccountPortfolio{ID: "GCITY", Name: "MCITY", Type: domain.AccountTypeSubfond})
+	data, _ := json.Marshal(fundData)
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	repo := &mockSnapshotRepo{
+		snapshots: []snapshot.Snapshot{
+			{ID: 1, EntityID: 1, SnapshotDate: date, Data: data},
+		},
+	}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+	// The old API was called with RFC 3339 timestamps (see `stat import`).
+	req := httptest.NewRequest(http.MethodGet, "/api/fund-structure?date=2024-01-15T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	handler.GetFundStructureCompat(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var result legacy.FundStructure
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	names := make([]string, len(result.Accounts))
+	for i, a := range result.Accounts {
+		names[i] = a.Name
+	}
+	if len(names) != 3 || names[1] != "CITY" || names[2] != "APART" {
+		t.Errorf("accounts = %v, want [ISSUER CITY APART]", names)
+	}
+}
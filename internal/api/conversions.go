@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/conversion"
+)
+
+// ConversionSource reads stored MTLRECT → MTL conversions.
+type ConversionSource interface {
+	List(ctx context.Context, slug string, from, to time.Time) ([]conversion.Conversion, error)
+}
+
+// ConversionsHandler serves MTLRECT conversions.
+type ConversionsHandler struct {
+	source ConversionSource
+}
+
+// NewConversionsHandler creates a new conversion handler.
+func NewConversionsHandler(source ConversionSource) *ConversionsHandler {
+	return &ConversionsHandler{source: source}
+}
+
+// GetConversions handles GET /api/v1/issuance/conversions.
+//
+// @Summary      MTLRECT to MTL conversions
+// @Description  MTLRECT returned to the issuer and matched with the MTL issued to the same account within 7 days after it (usually in the same transaction), oldest first by the MTL issuance. `amount` is the MTLRECT returned, `received` the MTL issued. The totals are indicators I73 (cumulative) and I74 (last 30 days).
+// @Tags         issuance
+// @Produce      json
+// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {array}   conversion.Conversion
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/issuance/conversions [get]
+func (h *ConversionsHandler) GetConversions(w http.ResponseWriter, r *http.Request) {
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	conversions, err := h.source.List(r.Context(), fundSlug, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to list conversions", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if conversions == nil {
+		conversions = []conversion.Conversion{}
+	}
+	writeJSON(w, http.StatusOK, conversions)
+}
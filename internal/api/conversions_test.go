@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/conversion"
+)
+
+type stubConversions struct {
+	conversions []conversion.Conversion
+	err         error
+}
+
+func (s *stubConversions) List(context.Context, string, time.Time, time.Time) ([]conversion.Conversion, error) {
+	return s.conversions, s.err
+}
+
+func TestGetConversions(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithConversions(&stubConversions{conversions: []conversion.Conversion{
+		{Account: "GALICE", Amount: decimal.NewFromInt(100), Received: decimal.NewFromInt(100), ReturnTx: "tx1", IssueTx: "tx1"},
+	}}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/conversions?range=all", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got []conversion.Conversion
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Account != "GALICE" || !got[0].Amount.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("conversions = %+v", got)
+	}
+
+	srv = NewServer("0", nil, nil, WithConversions(&stubConversions{}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/conversions", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q, want 200 []", w.Code, w.Body)
+	}
+
+	srv = NewServer("0", nil, nil, WithConversions(&stubConversions{err: errors.New("db down")}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/conversions", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("db error: status = %d, want 500", w.Code)
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/definition"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// DefinitionSource reads the indicator definition changelog
+// (definition.PgRepository).
+type DefinitionSource interface {
+	List(ctx context.Context, id int) ([]definition.Change, error)
+}
+
+// IndicatorMethods reports the estimation method of indicators with a
+// configurable formula (indicator.Service).
+type IndicatorMethods interface {
+	Method(id int) string
+}
+
+// IndicatorMetaResponse is the registry entry of one indicator with its
+// definition history.
+type IndicatorMetaResponse struct {
+	ID          int                 `json:"id"`
+	Name        string              `json:"name"`
+	Unit        string              `json:"unit"`
+	Description string              `json:"description"`
+	Precision   int32               `json:"precision"`
+	Method      string              `json:"method,omitempty"` // estimation method of I47, e.g. "historical, 95%, 1d"
+	Version     int                 `json:"version"`          // definition in effect today
+	Definitions []definition.Change `json:"definitions"`
+}
+
+// DefinitionsHandler serves indicator metadata with definition history.
+type DefinitionsHandler struct {
+	source  DefinitionSource
+	methods IndicatorMethods // nil: no methods reported
+	now     func() time.Time
+}
+
+// NewDefinitionsHandler creates a new indicator metadata handler; methods
+// may be nil.
+func NewDefinitionsHandler(source DefinitionSource, methods IndicatorMethods) *DefinitionsHandler {
+	return &DefinitionsHandler{source: source, methods: methods, now: time.Now}
+}
+
+// GetIndicatorMeta handles GET /api/v1/indicators/meta.
+//
+// @Summary      Indicator metadata and definition history
+// @Description  Every registered indicator with its name, unit, description, display precision, estimation method (I47 only), the definition version in effect today and the changelog of its formula (version, effectiveDate, summary). Version 1 is the original definition. Comparisons in GET /api/v1/indicators that span an effectiveDate list the change in definitionChanges.
+// @Tags         indicators
+// @Produce      json
+// @Success      200  {array}   IndicatorMetaResponse
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/indicators/meta [get]
+func (h *DefinitionsHandler) GetIndicatorMeta(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.source.List(r.Context(), 0)
+	if err != nil {
+		slog.Error("failed to list definition changes", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	byID := make(map[int][]definition.Change)
+	for _, c := range changes {
+		byID[c.IndicatorID] = append(byID[c.IndicatorID], c)
+	}
+
+	today := h.now().UTC()
+	ids := indicator.RegisteredIDs()
+	out := make([]IndicatorMetaResponse, 0, len(ids))
+	for _, id := range ids {
+		meta, _ := indicator.MetaOf(id)
+		defs := byID[id]
+		if defs == nil {
+			defs = []definition.Change{}
+		}
+		var method string
+		if h.methods != nil {
+			method = h.methods.Method(id)
+		}
+		out = append(out, IndicatorMetaResponse{
+			ID:          id,
+			Name:        meta.Name,
+			Unit:        meta.Unit,
+			Description: meta.Description,
+			Precision:   meta.Precision,
+			Method:      method,
+			Version:     definition.VersionOn(defs, id, today),
+			Definitions: defs,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
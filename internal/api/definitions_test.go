@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/definition"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+type stubDefinitions []definition.Change
+
+func (s stubDefinitions) List(_ context.Context, id int) ([]definition.Change, error) {
+	var out []definition.Change
+	for _, c := range s {
+		if id == 0 || c.IndicatorID == id {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+var i1Change = definition.Change{
+	ID: 1, IndicatorID: 1, Version: 2,
+	EffectiveDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	Summary:       "market cap counts MTLRECT at the MTL price",
+}
+
+func TestGetIndicatorMeta(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithDefinitions(stubDefinitions{i1Change}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/indicators/meta", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var metas []IndicatorMetaResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &metas); err != nil {
+		t.Fatal(err)
+	}
+	if len(metas) != len(indicator.RegisteredIDs()) {
+		t.Fatalf("got %d indicators, want %d", len(metas), len(indicator.RegisteredIDs()))
+	}
+	i1, i2 := metas[0], metas[1]
+	if i1.ID != 1 || i1.Version != 2 || len(i1.Definitions) != 1 || i1.Name != "Market Cap EUR" || i1.Precision != 2 {
+		t.Errorf("I1 = %+v", i1)
+	}
+	if i2.Version != 1 || i2.Definitions == nil || len(i2.Definitions) != 0 {
+		t.Errorf("I2 = %+v, want version 1 and an empty changelog", i2)
+	}
+}
+
+func TestGetIndicatorsByDateDefinitionChanges(t *testing.T) {
+	date := time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)
+	repo := &mockIndicatorRepo{
+		nearestByCutoff: map[time.Time]map[int]indicator.Indicator{
+			date:                    {1: sampleIndicator(1, "120"), 2: sampleIndicator(2, "1")},
+			date.AddDate(0, 0, -30): {1: sampleIndicator(1, "100"), 2: sampleIndicator(2, "1")},
+		},
+	}
+	handler := NewIndicatorHandler(repo)
+	handler.definitions = stubDefinitions{i1Change}
+
+	get := func(query string) []IndicatorWithChanges {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators/2024-04-15"+query, nil)
+		req.SetPathValue("date", "2024-04-15")
+		w := httptest.NewRecorder()
+		handler.GetIndicatorsByDate(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d", w.Code)
+		}
+		var result []IndicatorWithChanges
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := get("?compare=30d")
+	if len(result) != 2 || len(result[0].DefinitionChanges) != 1 || result[0].DefinitionChanges[0].Version != 2 {
+		t.Fatalf("I1 = %+v, want the v2 change", result[0])
+	}
+	if result[1].DefinitionChanges != nil {
+		t.Errorf("I2 = %+v, want no definition changes", result[1])
+	}
+	// Without compare nothing spans the change.
+	if result := get(""); result[0].DefinitionChanges != nil {
+		t.Errorf("I1 without compare = %+v", result[0])
+	}
+}
+
+type stubMethods map[int]string
+
+func (m stubMethods) Method(id int) string { return m[id] }
+
+func TestGetIndicatorMetaReportsMethod(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithDefinitions(stubDefinitions{}),
+		WithIndicatorMethods(stubMethods{47: "historical, 99%, 1d"}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/indicators/meta", nil))
+	var metas []IndicatorMetaResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &metas); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range metas {
+		want := ""
+		if m.ID == 47 {
+			want = "historical, 99%, 1d"
+		}
+		if m.Method != want {
+			t.Errorf("I%d method = %q, want %q", m.ID, m.Method, want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/annotation"
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+// feedIndicatorIDs are the indicators summarised in each feed entry: assets,
+// book value and market price of the share, MTL in circulation, monthly
+// dividends and shareholders.
+var feedIndicatorIDs = []int{3, 8, 10, 6, 11, 27}
+
+// feedEntries is how many daily entries the feed carries, newest first.
+// History is read a week further back so the oldest entry has a change too.
+const feedEntries = 30
+
+// FeedSource reads indicator history (indicator.PgRepository).
+type FeedSource interface {
+	GetHistory(ctx context.Context, slug string, ids []int, from time.Time) ([]indicator.HistoryPoint, error)
+}
+
+// FeedHandler serves the public Atom feed of daily statistics.
+type FeedHandler struct {
+	source  FeedSource
+	notes   AnnotationSource // nil: entries carry no annotations
+	clock   snapdate.Clock
+	siteURL string // entries link to siteURL/?date=; "" leaves them unlinked
+}
+
+// NewFeedHandler creates a feed handler. notes may be nil.
+func NewFeedHandler(source FeedSource, notes AnnotationSource, clock snapdate.Clock, siteURL string) *FeedHandler {
+	return &FeedHandler{source: source, notes: notes, clock: clock, siteURL: strings.TrimRight(siteURL, "/")}
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Links   []atomLink `xml:"link"`
+	Summary string     `xml:"summary"`
+	Content atomText   `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// feedDay is one snapshot date of the feed.
+type feedDay struct {
+	date    time.Time
+	values  map[int]decimal.Decimal
+	changes map[int]decimal.Decimal // percent vs the previous date; absent when unknown
+	notes   []annotation.Annotation
+}
+
+// GetFeed handles GET /feed.atom.
+//
+// @Summary      Daily statistics feed
+// @Description  Atom feed of the last 30 daily reports, newest first, for feed readers. Each entry is one snapshot date with the key indicators (I3 assets, I8 book value, I10 market price, I6 MTL in circulation, I11 monthly dividends, I27 shareholders), their percent change since the previous report and the operator annotations of that date. An entry's updated time moves when an annotation is added to it.
+// @Tags         indicators
+// @Produce      application/atom+xml
+// @Success      200  {string}  string  "Atom feed"
+// @Failure      500  {object}  Problem
+// @Router       /feed.atom [get]
+func (h *FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	from := h.clock.Today().AddDate(0, 0, -(feedEntries + 7))
+	points, err := h.source.GetHistory(r.Context(), fundSlug, feedIndicatorIDs, from)
+	if err != nil {
+		slog.Error("failed to load feed history", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	var notes []annotation.Annotation
+	if h.notes != nil {
+		if notes, err = h.notes.List(r.Context(), fundSlug, from, time.Time{}); err != nil {
+			slog.Error("failed to load feed annotations", "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+
+	body, err := xml.MarshalIndent(h.buildFeed(r, feedDays(points, notes)), "", "  ")
+	if err != nil {
+		slog.Error("failed to render feed", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
+
+// feedDays groups the history by date, newest first, with each value's
+// change since the previous date, and keeps the latest feedEntries dates.
+func feedDays(points []indicator.HistoryPoint, notes []annotation.Annotation) []feedDay {
+	byDate := make(map[time.Time]*feedDay)
+	for _, p := range points {
+		d := byDate[p.SnapshotDate]
+		if d == nil {
+			d = &feedDay{date: p.SnapshotDate, values: make(map[int]decimal.Decimal), changes: make(map[int]decimal.Decimal)}
+			byDate[p.SnapshotDate] = d
+		}
+		d.values[p.IndicatorID] = p.Value
+	}
+	for _, n := range notes {
+		if d := byDate[n.Date]; d != nil {
+			d.notes = append(d.notes, n)
+		}
+	}
+
+	days := make([]feedDay, 0, len(byDate))
+	for _, d := range byDate {
+		days = append(days, *d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].date.After(days[j].date) })
+	for i := 0; i+1 < len(days); i++ {
+		for id, v := range days[i].values {
+			if prev, ok := days[i+1].values[id]; ok && !prev.IsZero() {
+				days[i].changes[id] = v.Sub(prev).Div(prev.Abs()).Mul(decimal.NewFromInt(100)).Round(2)
+			}
+		}
+	}
+	if len(days) > feedEntries {
+		days = days[:feedEntries]
+	}
+	return days
+}
+
+func (h *FeedHandler) buildFeed(r *http.Request, days []feedDay) atomFeed {
+	feed := atomFeed{
+		ID:     h.feedID(),
+		Title:  "MTL Fund daily statistics",
+		Author: "MTL Fund",
+		Links:  []atomLink{{Rel: "self", Type: "application/atom+xml", Href: requestOrigin(r) + "/feed.atom"}},
+	}
+	if h.siteURL != "" {
+		feed.Links = append(feed.Links, atomLink{Rel: "alternate", Type: "text/html", Href: h.siteURL + "/"})
+	}
+	for _, d := range days {
+		e := h.buildEntry(d)
+		if e.Updated > feed.Updated {
+			feed.Updated = e.Updated
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	if feed.Updated == "" {
+		feed.Updated = h.clock.Today().UTC().Format(time.RFC3339)
+	}
+	return feed
+}
+
+func (h *FeedHandler) feedID() string {
+	if h.siteURL != "" {
+		return h.siteURL + "/feed.atom"
+	}
+	return "urn:stat:" + fundSlug
+}
+
+func (h *FeedHandler) buildEntry(d feedDay) atomEntry {
+	date := d.date.Format("2006-01-02")
+	updated := d.date
+	for _, n := range d.notes {
+		if n.CreatedAt.After(updated) {
+			updated = n.CreatedAt
+		}
+	}
+	e := atomEntry{
+		ID:      "urn:stat:" + fundSlug + ":" + date,
+		Title:   "MTL Fund statistics for " + date,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Content: atomText{Type: "html"},
+	}
+	if h.siteURL != "" {
+		e.Links = []atomLink{{Rel: "alternate", Type: "text/html", Href: h.siteURL + "/?date=" + date}}
+	}
+
+	var summary []string
+	var content strings.Builder
+	content.WriteString("<ul>")
+	for _, id := range feedIndicatorIDs {
+		v, ok := d.values[id]
+		if !ok {
+			continue
+		}
+		meta, _ := indicator.MetaOf(id)
+		line := fmt.Sprintf("%s: %s %s", meta.Name, v.StringFixed(indicator.PrecisionOf(id)), meta.Unit)
+		if c, ok := d.changes[id]; ok {
+			line += fmt.Sprintf(" (%s%s%%)", sign(c), c.StringFixed(2))
+		}
+		summary = append(summary, line)
+		fmt.Fprintf(&content, "<li>I%d %s</li>", id, html.EscapeString(line))
+	}
+	content.WriteString("</ul>")
+	for _, n := range d.notes {
+		fmt.Fprintf(&content, "<p>%s</p>", html.EscapeString(n.Text))
+	}
+	e.Summary = strings.Join(summary, "; ")
+	e.Content.Body = content.String()
+	return e
+}
+
+// sign returns "+" for a positive change; negative ones carry their own.
+func sign(d decimal.Decimal) string {
+	if d.IsPositive() {
+		return "+"
+	}
+	return ""
+}
+
+// requestOrigin is the scheme and host the request was made to, honouring
+// the scheme a proxy in front of the server forwarded.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
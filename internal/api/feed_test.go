@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/annotation"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func TestGetFeed(t *testing.T) {
+	day1 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	repo := &mockIndicatorRepo{historyPoints: []indicator.HistoryPoint{
+		{SnapshotDate: day1, IndicatorID: 3, Value: decimal.NewFromInt(1000)},
+		{SnapshotDate: day1, IndicatorID: 10, Value: decimal.RequireFromString("2.5")},
+		{SnapshotDate: day2, IndicatorID: 3, Value: decimal.NewFromInt(1100)},
+		{SnapshotDate: day2, IndicatorID: 10, Value: decimal.RequireFromString("2.25")},
+	}}
+	notes := &stubAnnotations{annotations: []annotation.Annotation{
+		{ID: 1, Date: day2, Text: "DEFI received <EUR 200k> tranche", CreatedAt: day2.Add(30 * time.Hour)},
+	}}
+	srv := NewServer("0", nil, repo, WithAnnotations(notes), WithSiteURL("https://stat.example/"))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed.atom", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var feed atomFeed
+	if err := xml.NewDecoder(w.Body).Decode(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Entries) != 2 || feed.ID != "https://stat.example/feed.atom" {
+		t.Fatalf("feed = %+v, want 2 entries", feed)
+	}
+
+	latest := feed.Entries[0]
+	if latest.ID != "urn:stat:mtlf:2026-10-02" || latest.Links[0].Href != "https://stat.example/?date=2026-10-02" {
+		t.Errorf("latest entry id %q, links %+v", latest.ID, latest.Links)
+	}
+	if latest.Updated != "2026-10-03T06:00:00Z" || feed.Updated != latest.Updated {
+		t.Errorf("updated = %q (feed %q), want the annotation's time", latest.Updated, feed.Updated)
+	}
+	for _, want := range []string{"Assets Value MTLF: 1100.00 EURMTL (+10.00%)", "Share Market Price: 2.2500000 EURMTL (-10.00%)"} {
+		if !strings.Contains(latest.Summary, want) || !strings.Contains(latest.Content.Body, want) {
+			t.Errorf("latest entry is missing %q: %q", want, latest.Summary)
+		}
+	}
+	if !strings.Contains(latest.Content.Body, "<p>DEFI received &lt;EUR 200k&gt; tranche</p>") {
+		t.Errorf("content = %q, want the escaped annotation", latest.Content.Body)
+	}
+	if oldest := feed.Entries[1]; strings.Contains(oldest.Summary, "%") || oldest.Updated != "2026-10-01T00:00:00Z" {
+		t.Errorf("oldest entry = %+v, want no changes", oldest)
+	}
+}
+
+func TestGetFeedHistoryError(t *testing.T) {
+	srv := NewServer("0", nil, &mockIndicatorRepo{historyErr: errors.New("db down")})
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed.atom", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
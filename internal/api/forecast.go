@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+// DividendForecaster forecasts dividends from the stored ledger.
+type DividendForecaster interface {
+	DividendForecast(ctx context.Context, opts analytics.ForecastOptions, now time.Time) (*analytics.DividendForecast, error)
+}
+
+// ForecastRequest selects the forecast method. Omitted fields take the
+// defaults: moving-average, 6 months, 0.9.
+type ForecastRequest struct {
+	Method     string          `json:"method,omitempty" enums:"moving-average,seasonal-naive"`
+	Window     int             `json:"window,omitempty"`
+	Confidence decimal.Decimal `json:"confidence,omitempty" swaggertype:"number"`
+}
+
+// ForecastHandler serves forecasts.
+type ForecastHandler struct {
+	dividends DividendForecaster
+}
+
+// NewForecastHandler creates a new forecast handler.
+func NewForecastHandler(dividends DividendForecaster) *ForecastHandler {
+	return &ForecastHandler{dividends: dividends}
+}
+
+// GetDividendForecast handles GET /api/v1/forecast/dividends.
+//
+// @Summary      Dividend forecast
+// @Description  Forecasts next month's dividends (I11) from the stored dividend ledger, one value per calendar month, and the annual dividend yield they imply at the latest share price (I10) and share count (I5). `moving-average` predicts the mean of the last `window` months; `seasonal-naive` predicts the same month a year earlier. Lower and upper bounds cover the requested confidence, assuming normal errors; the lower bound is never negative.
+// @Tags         forecast
+// @Produce      json
+// @Param        method      query  string  false  "moving-average (default) or seasonal-naive"
+// @Param        window      query  int     false  "Moving-average months, 2-36 (default 6)"
+// @Param        confidence  query  number  false  "0.8, 0.9 (default) or 0.95"
+// @Success      200  {object}  analytics.DividendForecast
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/forecast/dividends [get]
+func (h *ForecastHandler) GetDividendForecast(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := ForecastRequest{Method: q.Get("method")}
+	if s := q.Get("window"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid window, expected a number of months")
+			return
+		}
+		req.Window = n
+	}
+	if s := q.Get("confidence"); s != "" {
+		c, err := decimal.NewFromString(s)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid confidence")
+			return
+		}
+		req.Confidence = c
+	}
+	h.forecast(w, r, req)
+}
+
+// PostDividendForecast handles POST /api/v1/forecast/dividends.
+//
+// @Summary      Dividend forecast
+// @Description  Same as the GET form, with the parameters in a JSON body.
+// @Tags         forecast
+// @Accept       json
+// @Produce      json
+// @Param        body  body  ForecastRequest  false  "Forecast parameters"
+// @Success      200  {object}  analytics.DividendForecast
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/forecast/dividends [post]
+func (h *ForecastHandler) PostDividendForecast(w http.ResponseWriter, r *http.Request) {
+	var req ForecastRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	h.forecast(w, r, req)
+}
+
+func (h *ForecastHandler) forecast(w http.ResponseWriter, r *http.Request, req ForecastRequest) {
+	opts := analytics.ForecastOptions{
+		Method:     analytics.ForecastMethod(req.Method),
+		Window:     req.Window,
+		Confidence: req.Confidence,
+	}
+	f, err := h.dividends.DividendForecast(r.Context(), opts, time.Now().UTC())
+	switch {
+	case errors.Is(err, analytics.ErrInvalidForecast):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, analytics.ErrInsufficientHistory):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		slog.Error("failed to forecast dividends", "method", req.Method, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	default:
+		writeJSON(w, http.StatusOK, f)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+type stubForecaster struct {
+	opts analytics.ForecastOptions
+	err  error
+}
+
+func (s *stubForecaster) DividendForecast(_ context.Context, opts analytics.ForecastOptions, _ time.Time) (*analytics.DividendForecast, error) {
+	s.opts = opts
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &analytics.DividendForecast{Method: opts.Method, Month: "2026-11"}, nil
+}
+
+func TestDividendForecastParams(t *testing.T) {
+	src := &stubForecaster{}
+	srv := NewServer("0", nil, nil, WithForecast(src))
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/forecast/dividends?method=seasonal-naive&window=12&confidence=0.95", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/forecast/dividends",
+			strings.NewReader(`{"method":"seasonal-naive","window":12,"confidence":0.95}`)),
+	} {
+		src.opts = analytics.ForecastOptions{}
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200: %s", req.Method, w.Code, w.Body)
+		}
+		if src.opts.Method != analytics.ForecastSeasonalNaive || src.opts.Window != 12 ||
+			!src.opts.Confidence.Equal(decimal.RequireFromString("0.95")) {
+			t.Errorf("%s: opts = %+v", req.Method, src.opts)
+		}
+	}
+
+	// An empty POST body means defaults.
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/forecast/dividends", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("empty POST: status = %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func TestDividendForecastErrors(t *testing.T) {
+	for _, tc := range []struct {
+		target string
+		err    error
+		want   int
+	}{
+		{"/api/v1/forecast/dividends?window=six", nil, http.StatusBadRequest},
+		{"/api/v1/forecast/dividends?confidence=high", nil, http.StatusBadRequest},
+		{"/api/v1/forecast/dividends?method=arima", fmt.Errorf("%w: unknown method", analytics.ErrInvalidForecast), http.StatusBadRequest},
+		{"/api/v1/forecast/dividends", fmt.Errorf("%w: no stored I11", analytics.ErrInsufficientHistory), http.StatusNotFound},
+		{"/api/v1/forecast/dividends", fmt.Errorf("db down"), http.StatusInternalServerError},
+	} {
+		srv := NewServer("0", nil, nil, WithForecast(&stubForecaster{err: tc.err}))
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.target, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s (%v): status = %d, want %d", tc.target, tc.err, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// GroupResponse is one account group captured in a snapshot.
+type GroupResponse struct {
+	Date  string              `json:"date"`
+	Group domain.AccountGroup `json:"group"`
+}
+
+// GetGroup handles GET /api/v1/groups/{name}.
+//
+// @Summary      Account group rollup
+// @Description  Returns an account group configured in ACCOUNT_GROUPS (e.g. a real estate cluster of MCITY and APART) as captured in a stored snapshot: the members' values, the group's EURMTL and XLM totals, its token count and its share of the fund total. Members may be mutual or other accounts, which the fund total leaves out, so shares of overlapping groups need not add up. Members missing from the snapshot count as zero and are listed under missing. Snapshots taken before the group was configured don't have it.
+// @Tags         snapshots
+// @Produce      json
+// @Param        name  path   string  true   "Group name"
+// @Param        date  query  string  false  "Snapshot date (YYYY-MM-DD, default latest)"
+// @Success      200  {object}  GroupResponse
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/groups/{name} [get]
+func (h *Handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	s, data, ok := h.snapshotData(w, r, "account group")
+	if !ok {
+		return
+	}
+	name := strings.ToLower(r.PathValue("name"))
+	for _, g := range data.Groups {
+		if g.Name == name {
+			writeJSON(w, http.StatusOK, GroupResponse{Date: s.SnapshotDate.Format("2006-01-02"), Group: g})
+			return
+		}
+	}
+	writeProblem(w, http.StatusNotFound, CodeNotFound, "account group "+name+" not found in the snapshot")
+}
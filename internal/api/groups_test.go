@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func TestGetGroup(t *testing.T) {
+	data, _ := json.Marshal(domain.FundStructureData{
+		Groups: []domain.AccountGroup{{Name: "real-estate", TotalEURMTL: decimal.NewFromInt(4000), TokenCount: 6}},
+	})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 2, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Data: []byte(`{}`)},
+	}}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+	get := func(url, name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.SetPathValue("name", name)
+		w := httptest.NewRecorder()
+		handler.GetGroup(w, req)
+		return w
+	}
+
+	w := get("/api/v1/groups/Real-Estate", "Real-Estate")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got GroupResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Date != "2026-10-02" || got.Group.Name != "real-estate" || got.Group.TotalEURMTL.String() != "4000" || got.Group.TokenCount != 6 {
+		t.Errorf("group = %+v", got)
+	}
+
+	if w := get("/api/v1/groups/offices", "offices"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown group: status = %d, want 404", w.Code)
+	}
+	w = get("/api/v1/groups/real-estate?date=2026-10-01", "real-estate")
+	var p Problem
+	json.NewDecoder(w.Body).Decode(&p)
+	if w.Code != http.StatusNotFound || p.Code != CodeNotFound {
+		t.Errorf("snapshot before the group: status = %d, code %q; want 404 %s", w.Code, p.Code, CodeNotFound)
+	}
+}
+
+func TestGetGroupRequiresKey(t *testing.T) {
+	srv := NewServer("0", snapshot.NewService(&mockFundService{}, &mockSnapshotRepo{}), nil,
+		WithAPIKeys(APIKeys{Verifier: newKeyStub(), Required: true}))
+	if w := keyedGet(srv, "/api/v1/groups/real-estate", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+}
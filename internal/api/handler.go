@@ -8,12 +8,19 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/mtlprog/stat/internal/decjson"
+	"github.com/mtlprog/stat/internal/snapdate"
 	"github.com/mtlprog/stat/internal/snapshot"
 )
 
 // Handler provides HTTP endpoints for the statistics API.
 type Handler struct {
 	snapshots *snapshot.Service
+	clock     snapdate.Clock       // set by NewServer
+	exports   ExportBacklog        // set by NewServer; nil omits the backlog from /status
+	budget    RequestBudget        // set by NewServer; nil omits the CoinGecko budget from /status
+	details   SnapshotDetailSource // set by NewServer; nil serves inline details only
+	slo       SLOSource            // set by NewServer; nil omits the pipeline SLO from /status
 }
 
 // NewHandler creates a new API handler.
@@ -28,13 +35,13 @@ func NewHandler(snapshots *snapshot.Service) *Handler {
 // @Tags         snapshots
 // @Produce      json
 // @Success      200  {object}  snapshot.Snapshot
-// @Failure      404  {object}  map[string]string
+// @Failure      404  {object}  Problem
 // @Router       /api/v1/snapshots/latest [get]
 func (h *Handler) GetLatestSnapshot(w http.ResponseWriter, r *http.Request) {
 	s, err := h.snapshots.GetLatest(r.Context(), "mtlf")
 	if err != nil {
 		if errors.Is(err, snapshot.ErrNotFound) {
-			writeError(w, http.StatusNotFound, "no snapshots found")
+			writeProblem(w, http.StatusNotFound, CodeSnapshotNotFound, "no snapshots found")
 			return
 		}
 		slog.Error("failed to get latest snapshot", "error", err)
@@ -52,21 +59,21 @@ func (h *Handler) GetLatestSnapshot(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        date  path  string  true  "Snapshot date (YYYY-MM-DD)"
 // @Success      200  {object}  snapshot.Snapshot
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
 // @Router       /api/v1/snapshots/{date} [get]
 func (h *Handler) GetSnapshotByDate(w http.ResponseWriter, r *http.Request) {
 	dateStr := r.PathValue("date")
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid date format, expected YYYY-MM-DD")
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
 		return
 	}
 
 	s, err := h.snapshots.GetByDate(r.Context(), "mtlf", date)
 	if err != nil {
 		if errors.Is(err, snapshot.ErrNotFound) {
-			writeError(w, http.StatusNotFound, "snapshot not found for date")
+			writeProblem(w, http.StatusNotFound, CodeSnapshotNotFound, "snapshot not found for date")
 			return
 		}
 		slog.Error("failed to get snapshot by date", "date", dateStr, "error", err)
@@ -79,30 +86,90 @@ func (h *Handler) GetSnapshotByDate(w http.ResponseWriter, r *http.Request) {
 // ListSnapshots handles GET /api/v1/snapshots.
 //
 // @Summary      List snapshots
-// @Description  Returns recent fund snapshots, newest first.
+// @Description  Returns fund snapshots, newest first by default. Pagination metadata is returned in headers: X-Total-Count (matches for from/to) and X-Next-Cursor (absent on the last page).
 // @Tags         snapshots
 // @Produce      json
-// @Param        limit  query  int  false  "Maximum number of snapshots (default 30, max 365)"
-// @Success      200  {array}  snapshot.Snapshot
+// @Param        limit      query  int     false  "Page size (default 30, max 365)"
+// @Param        from       query  string  false  "Earliest snapshot date, inclusive (YYYY-MM-DD)"
+// @Param        to         query  string  false  "Latest snapshot date, inclusive (YYYY-MM-DD)"
+// @Param        cursor     query  string  false  "X-Next-Cursor from the previous page"
+// @Param        order      query  string  false  "asc or desc (default desc)"
+// @Param        omit_data  query  bool    false  "Return only id, date, createdAt and size"
+// @Success      200  {array}   snapshot.Snapshot
+// @Header       200  {int}     X-Total-Count  "Snapshots matching from/to"
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page"
+// @Failure      400  {object}  Problem
 // @Router       /api/v1/snapshots [get]
 func (h *Handler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
 	const maxLimit = 365
-	limit := 30
-	if l := r.URL.Query().Get("limit"); l != "" {
+	params := r.URL.Query()
+
+	q := snapshot.ListQuery{Limit: 30, Cursor: params.Get("cursor")}
+	if l := params.Get("limit"); l != "" {
 		if n, err := strconv.Atoi(l); err == nil && n > 0 {
-			limit = min(n, maxLimit)
+			q.Limit = min(n, maxLimit)
+		}
+	}
+	var err error
+	if q.From, err = parseOptionalDate(params.Get("from")); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid from date, expected YYYY-MM-DD")
+		return
+	}
+	if q.To, err = parseOptionalDate(params.Get("to")); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid to date, expected YYYY-MM-DD")
+		return
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		writeError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+	switch params.Get("order") {
+	case "", "desc":
+	case "asc":
+		q.Ascending = true
+	default:
+		writeError(w, http.StatusBadRequest, "invalid order, expected asc or desc")
+		return
+	}
+	if v := params.Get("omit_data"); v != "" {
+		omit, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid omit_data, expected true or false")
+			return
 		}
+		q.OmitData = omit
 	}
 
-	snapshots, err := h.snapshots.List(r.Context(), "mtlf", limit)
+	page, err := h.snapshots.ListPage(r.Context(), "mtlf", q)
 	if err != nil {
+		if errors.Is(err, snapshot.ErrInvalidCursor) {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidCursor, "invalid cursor")
+			return
+		}
 		slog.Error("failed to list snapshots", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	snapshots := page.Snapshots
+	if snapshots == nil {
+		snapshots = []snapshot.Snapshot{}
+	}
 	writeJSON(w, http.StatusOK, snapshots)
 }
 
+// parseOptionalDate parses YYYY-MM-DD, returning the zero time for "".
+func parseOptionalDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -111,6 +178,7 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(decjson.Header, string(decjson.Current()))
 	w.WriteHeader(status)
 	if _, err := w.Write(data); err != nil {
 		slog.Debug("failed to write HTTP response body", "error", err)
@@ -118,7 +186,3 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	}
 	_, _ = w.Write([]byte("\n"))
 }
-
-func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{"error": msg})
-}
@@ -16,6 +16,8 @@ type mockSnapshotRepo struct {
 	snapshots     []snapshot.Snapshot
 	entityID      int
 	lastListLimit int
+	lastQuery     snapshot.ListQuery
+	pageErr       error
 	metas         []snapshot.SnapshotMeta
 }
 
@@ -56,6 +58,30 @@ func (m *mockSnapshotRepo) List(_ context.Context, _ string, limit int) ([]snaps
 	return m.snapshots[:limit], nil
 }
 
+func (m *mockSnapshotRepo) ListPage(_ context.Context, _ string, q snapshot.ListQuery) (*snapshot.Page, error) {
+	m.lastListLimit = q.Limit
+	m.lastQuery = q
+	if m.pageErr != nil {
+		return nil, m.pageErr
+	}
+	var matched []snapshot.Snapshot
+	for _, s := range m.snapshots {
+		if (!q.From.IsZero() && s.SnapshotDate.Before(q.From)) || (!q.To.IsZero() && s.SnapshotDate.After(q.To)) {
+			continue
+		}
+		if q.OmitData {
+			s.Data = nil
+		}
+		matched = append(matched, s)
+	}
+	page := &snapshot.Page{Total: len(matched), Snapshots: matched}
+	if len(matched) > q.Limit {
+		page.Snapshots = matched[:q.Limit]
+		page.NextCursor = "next"
+	}
+	return page, nil
+}
+
 func (m *mockSnapshotRepo) GetEntityID(_ context.Context, _ string) (int, error) {
 	return m.entityID, nil
 }
@@ -230,3 +256,73 @@ func TestListSnapshots(t *testing.T) {
 		t.Errorf("snapshot count = %d, want 2", len(result))
 	}
 }
+
+func TestListSnapshotsFiltersAndPagination(t *testing.T) {
+	data, _ := json.Marshal(map[string]string{"k": "v"})
+	repo := &mockSnapshotRepo{
+		snapshots: []snapshot.Snapshot{
+			{ID: 3, SnapshotDate: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Data: data},
+			{ID: 2, SnapshotDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Data: data},
+			{ID: 1, SnapshotDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Data: data},
+		},
+	}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/snapshots?from=2024-01-02&limit=1&order=asc&omit_data=true&cursor=abc", nil)
+	w := httptest.NewRecorder()
+	handler.ListSnapshots(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if !repo.lastQuery.Ascending || !repo.lastQuery.OmitData || repo.lastQuery.Cursor != "abc" {
+		t.Errorf("query = %+v, want ascending, omit_data, cursor abc", repo.lastQuery)
+	}
+	if !repo.lastQuery.From.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) || !repo.lastQuery.To.IsZero() {
+		t.Errorf("query range = %v..%v, want 2024-01-02..unbounded", repo.lastQuery.From, repo.lastQuery.To)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", got)
+	}
+	if got := w.Header().Get("X-Next-Cursor"); got != "next" {
+		t.Errorf("X-Next-Cursor = %q, want next", got)
+	}
+
+	var result []map[string]any
+	json.NewDecoder(w.Body).Decode(&result)
+	if len(result) != 1 {
+		t.Fatalf("snapshot count = %d, want 1", len(result))
+	}
+	if _, ok := result[0]["data"]; ok {
+		t.Error("omit_data=true response still contains data")
+	}
+}
+
+func TestListSnapshotsBadParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   error
+	}{
+		{"bad from", "from=2024-13-01", nil},
+		{"bad to", "to=yesterday", nil},
+		{"from after to", "from=2024-02-01&to=2024-01-01", nil},
+		{"bad order", "order=sideways", nil},
+		{"bad omit_data", "omit_data=maybe", nil},
+		{"bad cursor", "cursor=%21", snapshot.ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSnapshotRepo{pageErr: tt.err}
+			handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/snapshots?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ListSnapshots(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/holders"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+// HolderChurnSource compares stored holder sets (holders.Service).
+type HolderChurnSource interface {
+	Churn(ctx context.Context, date time.Time, days int) ([]holders.Churn, error)
+}
+
+// HoldersHandler serves holder churn.
+type HoldersHandler struct {
+	source HolderChurnSource
+	clock  snapdate.Clock
+}
+
+// NewHoldersHandler creates a new holder churn handler.
+func NewHoldersHandler(source HolderChurnSource, clock snapdate.Clock) *HoldersHandler {
+	return &HoldersHandler{source: source, clock: clock}
+}
+
+// GetHolderChurn handles GET /api/v1/holders/churn.
+//
+// @Summary      Holder churn
+// @Description  New and exited MTL and MTLAP holders over a period: the latest stored holder set compared with the latest set at-or-before `period` days earlier. MTL holders are accounts with any positive MTL + MTLRECT balance, MTLAP holders those with at least 1 MTLAP. An asset is left out until a set that far back is stored.
+// @Tags         holders
+// @Produce      json
+// @Param        period  query  string  false  "Period: 30d (default), 90d, 180d or 365d"
+// @Success      200  {array}   holders.Churn
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/holders/churn [get]
+func (h *HoldersHandler) GetHolderChurn(w http.ResponseWriter, r *http.Request) {
+	days := holders.DefaultPeriodDays
+	if p := r.URL.Query().Get("period"); p != "" {
+		var ok bool
+		if days, ok = parsePeriodDays(p); !ok {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, fmt.Sprintf("invalid period %q, valid: 30d, 90d, 180d, 365d", p))
+			return
+		}
+	}
+	churn, err := h.source.Churn(r.Context(), h.clock.Today(), days)
+	if err != nil {
+		slog.Error("failed to compute holder churn", "days", days, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if churn == nil {
+		churn = []holders.Churn{}
+	}
+	writeJSON(w, http.StatusOK, churn)
+}
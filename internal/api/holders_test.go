@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/holders"
+)
+
+type stubChurn struct {
+	churn []holders.Churn
+	days  int
+	err   error
+}
+
+func (s *stubChurn) Churn(_ context.Context, _ time.Time, days int) ([]holders.Churn, error) {
+	s.days = days
+	return s.churn, s.err
+}
+
+func TestGetHolderChurn(t *testing.T) {
+	src := &stubChurn{churn: []holders.Churn{{Asset: holders.AssetMTL, New: []string{"GA"}, Exited: []string{}, Net: 1}}}
+	srv := NewServer("0", nil, nil, WithHolders(src))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/holders/churn", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got []holders.Churn
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].New[0] != "GA" || src.days != 30 {
+		t.Errorf("churn = %+v, days = %d", got, src.days)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/holders/churn?period=90d", nil))
+	if w.Code != http.StatusOK || src.days != 90 {
+		t.Errorf("period=90d: status = %d, days = %d", w.Code, src.days)
+	}
+}
+
+func TestGetHolderChurnEmptyAndErrors(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithHolders(&stubChurn{}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/holders/churn", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q, want 200 []", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/holders/churn?period=7d", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad period: status = %d, want 400", w.Code)
+	}
+
+	srv = NewServer("0", nil, nil, WithHolders(&stubChurn{err: errors.New("db down")}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/holders/churn", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("source error: status = %d, want 500", w.Code)
+	}
+}
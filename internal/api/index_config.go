@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// IndexConfigStore reads and replaces the Montelibero Index definition
+// (admin.PgRepository).
+type IndexConfigStore interface {
+	Index(ctx context.Context, slug string) (admin.Index, error)
+	SaveIndex(ctx context.Context, slug string, cfg indicator.IndexConfig, ch admin.Change) (admin.Index, error)
+}
+
+// IndexConfigBody is the Montelibero Index (I66) definition: the date on
+// which the index reads 100 and the weight of each component indicator.
+// Version is 0 while the default definition applies; it is ignored on PUT.
+type IndexConfigBody struct {
+	BaseDate string                  `json:"baseDate" example:"2024-01-01"`
+	Weights  map[int]decimal.Decimal `json:"weights" swaggertype:"object,number"`
+	Version  int                     `json:"version"`
+}
+
+// IndexConfigHandler serves the index definition.
+type IndexConfigHandler struct {
+	store      IndexConfigStore
+	trustProxy bool
+}
+
+// NewIndexConfigHandler creates a new index definition handler.
+func NewIndexConfigHandler(store IndexConfigStore) *IndexConfigHandler {
+	return &IndexConfigHandler{store: store}
+}
+
+// GetIndexConfig handles GET /api/v1/admin/index.
+//
+// @Summary      Montelibero Index definition
+// @Description  Base date and component weights of the Montelibero Index (I66). Components are I1, I3, I11 and I62. The ETag header carries the version for If-Match on PUT. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer ADMIN_TOKEN"
+// @Success      200  {object}  IndexConfigBody
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/index [get]
+func (h *IndexConfigHandler) GetIndexConfig(w http.ResponseWriter, r *http.Request) {
+	idx, err := h.store.Index(r.Context(), fundSlug)
+	if err != nil {
+		writeAdminError(w, err, "load index config")
+		return
+	}
+	writeIndex(w, idx)
+}
+
+func writeIndex(w http.ResponseWriter, idx admin.Index) {
+	w.Header().Set("ETag", etag(idx.Version))
+	writeJSON(w, http.StatusOK, IndexConfigBody{
+		BaseDate: idx.Config.BaseDate.Format("2006-01-02"),
+		Weights:  idx.Config.Weights,
+		Version:  idx.Version,
+	})
+}
+
+// PutIndexConfig handles PUT /api/v1/admin/index.
+//
+// @Summary      Replace the Montelibero Index definition
+// @Description  Replaces the base date and component weights of the Montelibero Index (I66). Weights must be non-negative, not all zero, and only for I1, I3, I11 and I62; they need not sum to 1. Takes effect from the next calculation; stored I66 history is recomputed by `stat backfill-index`. With If-Match the write only succeeds at that version (412 otherwise). Audited. Only mounted when ADMIN_TOKEN is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string           true   "Bearer ADMIN_TOKEN"
+// @Param        If-Match       header  string           false  "Version ETag from GET, e.g. \"3\""
+// @Param        body           body    IndexConfigBody  true   "Index definition"
+// @Success      200  {object}  IndexConfigBody
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      412  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/admin/index [put]
+func (h *IndexConfigHandler) PutIndexConfig(w http.ResponseWriter, r *http.Request) {
+	ch, err := adminChange(r, h.trustProxy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body IndexConfigBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON body")
+		return
+	}
+	base, err := time.Parse("2006-01-02", body.BaseDate)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid baseDate, expected YYYY-MM-DD")
+		return
+	}
+	cfg := indicator.IndexConfig{BaseDate: base, Weights: body.Weights}
+	if err := cfg.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	idx, err := h.store.SaveIndex(r.Context(), fundSlug, cfg, ch)
+	if err != nil {
+		writeAdminError(w, err, "save index config")
+		return
+	}
+	slog.Info("index config updated", "base_date", body.BaseDate, "weights", body.Weights, "version", idx.Version)
+	writeIndex(w, idx)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/admin"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+type stubIndexStore struct {
+	cfg     indicator.IndexConfig
+	version int
+	saved   bool
+}
+
+func (s *stubIndexStore) Index(context.Context, string) (admin.Index, error) {
+	return admin.Index{Config: s.cfg, Version: s.version}, nil
+}
+
+func (s *stubIndexStore) SaveIndex(_ context.Context, _ string, cfg indicator.IndexConfig, ch admin.Change) (admin.Index, error) {
+	if err := cfg.Validate(); err != nil {
+		return admin.Index{}, err
+	}
+	if ch.IfMatch != 0 && ch.IfMatch != s.version {
+		return admin.Index{}, admin.ErrVersionMismatch
+	}
+	s.cfg, s.saved = cfg, true
+	s.version++
+	return admin.Index{Config: cfg, Version: s.version}, nil
+}
+
+func putIndex(t *testing.T, srv *http.Server, body, token string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/index", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIndexConfig(t *testing.T) {
+	store := &stubIndexStore{cfg: indicator.DefaultIndexConfig}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Index: store}))
+
+	w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/index", "s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got IndexConfigBody
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.BaseDate != "2024-01-01" || !got.Weights[3].Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("GET = %+v, want default config", got)
+	}
+
+	w = putIndex(t, srv, `{"baseDate":"2025-01-01","weights":{"1":1,"62":"0.5"}}`, "s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if !store.cfg.BaseDate.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || len(store.cfg.Weights) != 2 ||
+		!store.cfg.Weights[62].Equal(decimal.RequireFromString("0.5")) {
+		t.Errorf("saved = %+v", store.cfg)
+	}
+}
+
+func TestIndexConfigRejectsInvalid(t *testing.T) {
+	store := &stubIndexStore{cfg: indicator.DefaultIndexConfig}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Index: store}))
+
+	for _, body := range []string{
+		`not json`,
+		`{"baseDate":"01.01.2025","weights":{"1":1}}`,
+		`{"baseDate":"2025-01-01","weights":{"2":1}}`,
+		`{"baseDate":"2025-01-01","weights":{"1":-1,"3":2}}`,
+		`{"baseDate":"2025-01-01","weights":{"1":0}}`,
+		`{"baseDate":"2025-01-01","weights":{"1":1},"extra":true}`,
+	} {
+		if w := putIndex(t, srv, body, "s3cret"); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+	if w := putIndex(t, srv, `{"baseDate":"2025-01-01","weights":{"1":1}}`, "wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", w.Code)
+	}
+	if store.saved {
+		t.Error("invalid config was saved")
+	}
+}
+
+func TestIndexConfigIfMatch(t *testing.T) {
+	store := &stubIndexStore{cfg: indicator.DefaultIndexConfig}
+	srv := NewServer("0", nil, nil, WithAdmin(Admin{Token: "s3cret", Index: store}))
+	body := `{"baseDate":"2025-01-01","weights":{"1":1}}`
+
+	if w := serveAdmin(t, srv, http.MethodGet, "/api/v1/admin/index", "s3cret"); w.Header().Get("ETag") != `"0"` {
+		t.Errorf("default config ETag = %q, want \"0\"", w.Header().Get("ETag"))
+	}
+	w := putIndex(t, srv, body, "s3cret")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("unconditional PUT: status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := putIndex(t, srv, body, "s3cret", "If-Match", `"1"`); w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Errorf("PUT at current version: status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := putIndex(t, srv, body, "s3cret", "If-Match", `"1"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT at stale version: status = %d, want 412", w.Code)
+	}
+	if w := putIndex(t, srv, body, "s3cret", "If-Match", "latest"); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed If-Match: status = %d, want 400", w.Code)
+	}
+}
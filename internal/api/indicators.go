@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -12,7 +14,10 @@ import (
 	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 
+	"github.com/mtlprog/stat/internal/annotation"
+	"github.com/mtlprog/stat/internal/definition"
 	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/period"
 )
 
 const fundSlug = "mtlf"
@@ -25,41 +30,56 @@ type PeriodChange struct {
 
 // IndicatorWithChanges extends Indicator with optional multi-period changes.
 // `changes` is omitted when ?compare is not requested or no historical data exists.
+// `definitionChanges` lists the formula changes taking effect within the
+// widest compared period, when there are any.
 type IndicatorWithChanges struct {
-	ID          int                     `json:"id"`
-	Name        string                  `json:"name"`
-	Value       decimal.Decimal         `json:"value"`
-	Unit        string                  `json:"unit"`
-	Description string                  `json:"description,omitempty"`
-	Changes     map[string]PeriodChange `json:"changes,omitempty"`
+	ID                int                     `json:"id"`
+	Name              string                  `json:"name"`
+	Value             decimal.Decimal         `json:"value"`
+	Unit              string                  `json:"unit"`
+	Description       string                  `json:"description,omitempty"`
+	Changes           map[string]PeriodChange `json:"changes,omitempty"`
+	DefinitionChanges []definition.Change     `json:"definitionChanges,omitempty"`
+}
+
+// IndicatorSet is the API v2 shape of the indicator endpoints: the indicators
+// of one date with the annotations on the dates they're compared across.
+type IndicatorSet struct {
+	Date        string                  `json:"date" example:"2026-10-01"`
+	Indicators  []IndicatorWithChanges  `json:"indicators"`
+	Annotations []annotation.Annotation `json:"annotations"`
 }
 
 // IndicatorHandler provides HTTP endpoints for indicators backed by fund_indicators.
 type IndicatorHandler struct {
-	repo indicator.Repository
+	repo        indicator.Repository
+	annotations AnnotationSource  // nil: v2 responses carry no annotations
+	definitions DefinitionSource  // nil: comparisons carry no definition changes
+	compare     period.Comparison // default baseline when ?baseline is absent
 }
 
 // NewIndicatorHandler creates a new indicator handler.
 func NewIndicatorHandler(repo indicator.Repository) *IndicatorHandler {
-	return &IndicatorHandler{repo: repo}
+	return &IndicatorHandler{repo: repo, compare: period.Rolling}
 }
 
 // GetIndicators handles GET /api/v1/indicators.
 //
 // @Summary      Latest indicators
-// @Description  Returns indicators from the most recent stored snapshot. Optional `compare` adds period-over-period changes.
+// @Description  Returns indicators from the most recent stored snapshot. Optional `compare` adds period-over-period changes. API v2 (/api/v2/ or Accept: application/vnd.mtlstat.v2+json) returns an IndicatorSet instead, adding the snapshot date and the annotations on the dates compared across.
 // @Tags         indicators
 // @Produce      json
 // @Param        compare  query  string  false  "Comma-separated periods: any of 30d,90d,180d,365d, or 'all'"
+// @Param        baseline query  string  false  "What changes compare with: rolling (N days earlier) or calendar (the previous month-, quarter-, half-year- or year-end). Default: the server's COMPARISON_MODE"  Enums(rolling, calendar)
 // @Success      200  {array}   IndicatorWithChanges
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
 // @Router       /api/v1/indicators [get]
 func (h *IndicatorHandler) GetIndicators(w http.ResponseWriter, r *http.Request) {
 	indicators, latestDate, err := h.repo.GetLatest(r.Context(), fundSlug)
 	if err != nil {
 		if errors.Is(err, indicator.ErrNotFound) {
-			writeError(w, http.StatusNotFound, "no indicators found")
+			writeProblem(w, http.StatusNotFound, CodeIndicatorsNotFound, "no indicators found")
 			return
 		}
 		slog.Error("failed to get latest indicators", "error", err)
@@ -68,19 +88,24 @@ func (h *IndicatorHandler) GetIndicators(w http.ResponseWriter, r *http.Request)
 	}
 
 	periods, err := parsePeriodList(r.URL.Query().Get("compare"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	mode, err := h.comparison(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if len(periods) == 0 {
-		writeJSON(w, http.StatusOK, toWithChanges(indicators, nil))
+		h.writeIndicators(w, r, latestDate, latestDate, toWithChanges(indicators, nil))
 		return
 	}
 
 	historical := make(map[int]map[int]indicator.Indicator, len(periods))
 	for _, days := range periods {
-		hist, err := h.repo.GetNearestBefore(r.Context(), fundSlug, latestDate.AddDate(0, 0, -days))
+		hist, err := h.repo.GetNearestBefore(r.Context(), fundSlug, mode.Baseline(latestDate, days))
 		if err != nil {
 			slog.Error("failed to fetch historical indicators", "days", days, "error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
@@ -91,26 +116,34 @@ func (h *IndicatorHandler) GetIndicators(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	writeJSON(w, http.StatusOK, toWithChanges(indicators, buildChanges(indicators, periods, historical)))
+	from := mode.Baseline(latestDate, slices.Max(periods))
+	items := toWithChanges(indicators, buildChanges(indicators, periods, historical))
+	if err := h.annotateDefinitions(r.Context(), items, from, latestDate); err != nil {
+		slog.Error("failed to list definition changes", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeIndicators(w, r, latestDate, from, items)
 }
 
 // GetIndicatorsByDate handles GET /api/v1/indicators/{date}.
 //
 // @Summary      Indicators by date
-// @Description  Returns the most recent value per indicator as of the given date (same semantics as GET /api/v1/indicators but bounded by date). Optional `compare` adds period-over-period changes anchored to that date.
+// @Description  Returns the most recent value per indicator as of the given date (same semantics as GET /api/v1/indicators but bounded by date). Optional `compare` adds period-over-period changes anchored to that date. API v2 returns an IndicatorSet, as GET /api/v1/indicators does.
 // @Tags         indicators
 // @Produce      json
 // @Param        date     path   string  true   "Snapshot date (YYYY-MM-DD)"
 // @Param        compare  query  string  false  "Comma-separated periods: any of 30d,90d,180d,365d, or 'all'"
+// @Param        baseline query  string  false  "What changes compare with: rolling (N days earlier) or calendar (the previous month-, quarter-, half-year- or year-end). Default: the server's COMPARISON_MODE"  Enums(rolling, calendar)
 // @Success      200  {array}   IndicatorWithChanges
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
 // @Router       /api/v1/indicators/{date} [get]
 func (h *IndicatorHandler) GetIndicatorsByDate(w http.ResponseWriter, r *http.Request) {
 	dateStr := r.PathValue("date")
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid date format, expected YYYY-MM-DD")
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
 		return
 	}
 
@@ -121,26 +154,31 @@ func (h *IndicatorHandler) GetIndicatorsByDate(w http.ResponseWriter, r *http.Re
 		return
 	}
 	if len(asOf) == 0 {
-		writeError(w, http.StatusNotFound, "indicators not found for date")
+		writeProblem(w, http.StatusNotFound, CodeIndicatorsNotFound, "indicators not found for date")
 		return
 	}
 
 	indicators := sortedIndicators(asOf)
 
 	periods, err := parsePeriodList(r.URL.Query().Get("compare"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	mode, err := h.comparison(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	if len(periods) == 0 {
-		writeJSON(w, http.StatusOK, toWithChanges(indicators, nil))
+		h.writeIndicators(w, r, date, date, toWithChanges(indicators, nil))
 		return
 	}
 
 	historical := make(map[int]map[int]indicator.Indicator, len(periods))
 	for _, days := range periods {
-		before, err := h.repo.GetNearestBefore(r.Context(), fundSlug, date.AddDate(0, 0, -days))
+		before, err := h.repo.GetNearestBefore(r.Context(), fundSlug, mode.Baseline(date, days))
 		if err != nil {
 			slog.Error("failed to fetch historical indicators", "date", dateStr, "days", days, "error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
@@ -151,7 +189,64 @@ func (h *IndicatorHandler) GetIndicatorsByDate(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	writeJSON(w, http.StatusOK, toWithChanges(indicators, buildChanges(indicators, periods, historical)))
+	from := mode.Baseline(date, slices.Max(periods))
+	items := toWithChanges(indicators, buildChanges(indicators, periods, historical))
+	if err := h.annotateDefinitions(r.Context(), items, from, date); err != nil {
+		slog.Error("failed to list definition changes", "date", dateStr, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeIndicators(w, r, date, from, items)
+}
+
+// annotateDefinitions sets on each item the definition changes of its
+// indicator taking effect after from (the earliest compared date) and by
+// date, whose values are then computed differently.
+func (h *IndicatorHandler) annotateDefinitions(ctx context.Context, items []IndicatorWithChanges, from, date time.Time) error {
+	if h.definitions == nil {
+		return nil
+	}
+	changes, err := h.definitions.List(ctx, 0)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].DefinitionChanges = definition.Spanning(changes, items[i].ID, from, date)
+	}
+	return nil
+}
+
+// comparison returns the baseline mode from ?baseline, or the handler's
+// default when it's absent.
+func (h *IndicatorHandler) comparison(r *http.Request) (period.Comparison, error) {
+	s := r.URL.Query().Get("baseline")
+	if s == "" {
+		return h.compare, nil
+	}
+	return period.ParseComparison(s)
+}
+
+// writeIndicators writes items as a plain array (API v1) or as an
+// IndicatorSet (v2) carrying the annotations from the earliest compared date
+// (from) through date.
+func (h *IndicatorHandler) writeIndicators(w http.ResponseWriter, r *http.Request, date, from time.Time, items []IndicatorWithChanges) {
+	if apiVersion(r) < 2 {
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+	set := IndicatorSet{Date: date.Format("2006-01-02"), Indicators: items, Annotations: []annotation.Annotation{}}
+	if h.annotations != nil {
+		list, err := h.annotations.List(r.Context(), fundSlug, from, date)
+		if err != nil {
+			slog.Error("failed to list annotations", "date", set.Date, "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if list != nil {
+			set.Annotations = list
+		}
+	}
+	writeJSON(w, http.StatusOK, set)
 }
 
 // toWithChanges wraps each Indicator with an optional Changes map (nil if no compare requested).
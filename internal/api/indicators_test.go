@@ -6,11 +6,13 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
 
+	"github.com/mtlprog/stat/internal/decjson"
 	"github.com/mtlprog/stat/internal/indicator"
 )
 
@@ -24,7 +26,7 @@ type mockIndicatorRepo struct {
 	nearestErr      error
 }
 
-func (m *mockIndicatorRepo) Save(_ context.Context, _ int, _ time.Time, _ []indicator.Indicator) error {
+func (m *mockIndicatorRepo) Save(_ context.Context, _ int, _ time.Time, _ []indicator.Indicator, _ indicator.Source) error {
 	return nil
 }
 
@@ -96,6 +98,27 @@ func TestGetIndicatorsSuccess(t *testing.T) {
 	}
 }
 
+func TestGetIndicatorsDecimalFormat(t *testing.T) {
+	defer decjson.Apply(decjson.Current())
+	repo := &mockIndicatorRepo{
+		latest:     []indicator.Indicator{sampleIndicator(3, "200.5")},
+		latestDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+	handler := NewIndicatorHandler(repo)
+
+	for format, want := range map[decjson.Format]string{decjson.String: `"value":"200.5"`, decjson.Number: `"value":200.5`} {
+		decjson.Apply(format)
+		w := httptest.NewRecorder()
+		handler.GetIndicators(w, httptest.NewRequest(http.MethodGet, "/api/v1/indicators", nil))
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: body %s lacks %s", format, w.Body, want)
+		}
+		if got := w.Header().Get(decjson.Header); got != string(format) {
+			t.Errorf("%s: %s = %q", format, decjson.Header, got)
+		}
+	}
+}
+
 func TestGetIndicatorsNoSnapshot(t *testing.T) {
 	repo := &mockIndicatorRepo{}
 	handler := NewIndicatorHandler(repo)
@@ -202,6 +225,50 @@ func TestGetIndicatorsByDateWithCompare(t *testing.T) {
 	}
 }
 
+func TestGetIndicatorsByDateCalendarBaseline(t *testing.T) {
+	date := time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)
+	repo := &mockIndicatorRepo{
+		nearestByCutoff: map[time.Time]map[int]indicator.Indicator{
+			date: {1: sampleIndicator(1, "120")},
+			time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC): {1: sampleIndicator(1, "80")},
+			time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC): {1: sampleIndicator(1, "100")},
+		},
+	}
+	handler := NewIndicatorHandler(repo)
+
+	for _, tc := range []struct {
+		query string
+		want  int64
+	}{
+		{"?compare=30d", 20},                   // rolling: 2024-03-16
+		{"?compare=30d&baseline=calendar", 40}, // month-end: 2024-03-31
+		{"?compare=30d&baseline=rolling", 20},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators/2024-04-15"+tc.query, nil)
+		req.SetPathValue("date", "2024-04-15")
+		w := httptest.NewRecorder()
+		handler.GetIndicatorsByDate(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tc.query, w.Code)
+		}
+		var result []IndicatorWithChanges
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got := result[0].Changes["30d"].Abs; !got.Equal(decimal.NewFromInt(tc.want)) {
+			t.Errorf("%s: Abs = %s, want %d", tc.query, got, tc.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators/2024-04-15?compare=30d&baseline=fiscal", nil)
+	req.SetPathValue("date", "2024-04-15")
+	w := httptest.NewRecorder()
+	handler.GetIndicatorsByDate(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("baseline=fiscal: status = %d, want 400", w.Code)
+	}
+}
+
 func TestGetIndicatorsByDateCompareRepoError(t *testing.T) {
 	date := time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)
 	repo := &mockIndicatorRepo{
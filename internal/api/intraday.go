@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/intraday"
+)
+
+// Limits of the intraday ?hours window.
+const (
+	defaultIntradayHours = 48
+	maxIntradayHours     = 24 * 90
+)
+
+// IntradaySource reads intraday samples (intraday.Service).
+type IntradaySource interface {
+	History(ctx context.Context, id, hours int) ([]intraday.Sample, error)
+}
+
+// IntradayResponse is the recent intraday samples of one indicator.
+type IntradayResponse struct {
+	ID     int               `json:"id"`
+	Name   string            `json:"name"`
+	Unit   string            `json:"unit"`
+	Hours  int               `json:"hours"`
+	Points []intraday.Sample `json:"points"`
+}
+
+// IntradayHandler serves intraday indicator samples.
+type IntradayHandler struct {
+	source IntradaySource
+}
+
+// NewIntradayHandler creates a new intraday handler.
+func NewIntradayHandler(source IntradaySource) *IntradayHandler {
+	return &IntradayHandler{source: source}
+}
+
+// GetIntraday handles GET /api/v1/intraday/{id}.
+//
+// @Summary      Intraday indicator samples
+// @Description  Samples of a key indicator taken every INTRADAY_INTERVAL (typically hourly) by a light sampler outside the daily snapshot pipeline, oldest first. Only I4 (operating balance) and I10 (share market price) are sampled. The samples are kept for INTRADAY_RETENTION and never appear in the daily indicator series.
+// @Tags         indicators
+// @Produce      json
+// @Param        id     path   int  true   "Indicator ID (4 or 10)"
+// @Param        hours  query  int  false  "Window in hours, 1–2160 (default: 48)"
+// @Success      200  {object}  IntradayResponse
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/intraday/{id} [get]
+func (h *IntradayHandler) GetIntraday(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid indicator id")
+		return
+	}
+	if !intraday.IsSampled(id) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("indicator %d is not sampled intraday", id))
+		return
+	}
+	hours := defaultIntradayHours
+	if s := r.URL.Query().Get("hours"); s != "" {
+		if hours, err = strconv.Atoi(s); err != nil || hours < 1 || hours > maxIntradayHours {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid hours %q, want 1–%d", s, maxIntradayHours))
+			return
+		}
+	}
+
+	points, err := h.source.History(r.Context(), id, hours)
+	if err != nil {
+		slog.Error("failed to fetch intraday samples", "indicator", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if points == nil {
+		points = []intraday.Sample{}
+	}
+	meta := indicator.NewIndicator(id, decimal.Zero, "", "")
+	writeJSON(w, http.StatusOK, IntradayResponse{ID: id, Name: meta.Name, Unit: meta.Unit, Hours: hours, Points: points})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/intraday"
+)
+
+type stubIntraday struct {
+	id, hours int
+}
+
+func (s *stubIntraday) History(_ context.Context, id, hours int) ([]intraday.Sample, error) {
+	s.id, s.hours = id, hours
+	at := time.Date(2026, 10, 2, 14, 0, 0, 0, time.UTC)
+	return []intraday.Sample{{IndicatorID: id, SampledAt: at, Value: decimal.RequireFromString("8.5")}}, nil
+}
+
+func TestGetIntraday(t *testing.T) {
+	stub := &stubIntraday{}
+	srv := NewServer("0", nil, nil, WithIntraday(stub))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/intraday/10", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var got IntradayResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 10 || got.Name != "Share Market Price" || got.Hours != 48 || len(got.Points) != 1 || got.Points[0].Value.String() != "8.5" {
+		t.Errorf("response = %+v", got)
+	}
+	if stub.id != 10 || stub.hours != 48 {
+		t.Errorf("queried I%d over %dh", stub.id, stub.hours)
+	}
+
+	for target, want := range map[string]int{
+		"/api/v1/intraday/4?hours=6":    http.StatusOK,
+		"/api/v1/intraday/1":            http.StatusNotFound,
+		"/api/v1/intraday/x":            http.StatusBadRequest,
+		"/api/v1/intraday/4?hours=0":    http.StatusBadRequest,
+		"/api/v1/intraday/4?hours=9999": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", target, w.Code, want)
+		}
+	}
+	if stub.hours != 6 {
+		t.Errorf("?hours=6 queried %dh", stub.hours)
+	}
+}
+
+func TestGetIntradayRequiresKey(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithIntraday(&stubIntraday{}),
+		WithAPIKeys(APIKeys{Verifier: newKeyStub(), Required: true}))
+	if w := keyedGet(srv, "/api/v1/intraday/10", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/issuance"
+)
+
+// IssuanceSource reads stored share issuance and buyback events.
+type IssuanceSource interface {
+	List(ctx context.Context, slug string, from, to time.Time) ([]issuance.Event, error)
+}
+
+// IssuanceHandler serves issuance events.
+type IssuanceHandler struct {
+	source IssuanceSource
+}
+
+// NewIssuanceHandler creates a new issuance event handler.
+func NewIssuanceHandler(source IssuanceSource) *IssuanceHandler {
+	return &IssuanceHandler{source: source}
+}
+
+// GetIssuanceEvents handles GET /api/v1/issuance/events.
+//
+// @Summary      Share issuance and buyback events
+// @Description  MTL and MTLRECT total supply changes between consecutive snapshots, oldest first. Each event lists the issuer operations since the previous snapshot: payments from the issuer (issuance, `account` is the recipient), and payments back to it or clawbacks (buyback, `account` is the sender). `unattributed` is the part of the net change those operations don't explain. Snapshots taken before supply tracking have no events.
+// @Tags         issuance
+// @Produce      json
+// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {array}   issuance.Event
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/issuance/events [get]
+func (h *IssuanceHandler) GetIssuanceEvents(w http.ResponseWriter, r *http.Request) {
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	events, err := h.source.List(r.Context(), fundSlug, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to list issuance events", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if events == nil {
+		events = []issuance.Event{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/issuance"
+)
+
+type stubIssuance struct {
+	events []issuance.Event
+	from   time.Time
+	err    error
+}
+
+func (s *stubIssuance) List(_ context.Context, _ string, from, _ time.Time) ([]issuance.Event, error) {
+	s.from = from
+	return s.events, s.err
+}
+
+func TestGetIssuanceEvents(t *testing.T) {
+	src := &stubIssuance{events: []issuance.Event{{
+		Asset: "MTL", Kind: issuance.KindIssuance, Amount: decimal.NewFromInt(100),
+		Transfers: []issuance.Transfer{{Account: "GALICE", Amount: decimal.NewFromInt(100), Kind: issuance.KindIssuance}},
+	}}}
+	srv := NewServer("0", nil, nil, WithIssuance(src))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/events?range=365d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got []issuance.Event
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Transfers[0].Account != "GALICE" {
+		t.Errorf("events = %+v", got)
+	}
+	if days := time.Since(src.from).Hours() / 24; days < 364 || days > 366 {
+		t.Errorf("from = %s, want about a year ago", src.from)
+	}
+}
+
+func TestGetIssuanceEventsEmptyAndErrors(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithIssuance(&stubIssuance{}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/events", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q, want 200 []", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/events?range=2w", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad range: status = %d, want 400", w.Code)
+	}
+
+	srv = NewServer("0", nil, nil, WithIssuance(&stubIssuance{err: errors.New("db down")}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/events", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("db error: status = %d, want 500", w.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/stat/internal/job"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+// JobQueue enqueues and looks up background jobs.
+type JobQueue interface {
+	Enqueue(ctx context.Context, date time.Time) (*job.Job, error)
+	Get(ctx context.Context, id int64) (*job.Job, error)
+}
+
+// JobHandler exposes on-demand snapshot generation as background jobs.
+// Only mounted when API_GENERATE_ENABLED is set; the default server is read-only.
+type JobHandler struct {
+	jobs  JobQueue
+	clock snapdate.Clock
+}
+
+// NewJobHandler creates a new job handler. clock decides today's snapshot date.
+func NewJobHandler(jobs JobQueue, clock snapdate.Clock) *JobHandler {
+	return &JobHandler{jobs: jobs, clock: clock}
+}
+
+// GenerateSnapshot handles POST /api/v1/snapshots/generate.
+//
+// @Summary      Generate a snapshot
+// @Description  Queues the full report pipeline for today's snapshot date, or for a past `date`, and returns immediately. A past date is generated as of the end of that day from ledger history and stored quotes, to patch a missing day; an existing snapshot of the date is replaced. Poll /api/v1/jobs/{id} for progress and result. If a generation for the date is already queued or running, that job is returned.
+// @Tags         jobs
+// @Produce      json
+// @Param        date  query  string  false  "Past snapshot date (YYYY-MM-DD, default today)"
+// @Success      202  {object}  job.Job
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/snapshots/generate [post]
+func (h *JobHandler) GenerateSnapshot(w http.ResponseWriter, r *http.Request) {
+	date := h.clock.Today()
+	if v := r.URL.Query().Get("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		if d.After(date) {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "date is in the future")
+			return
+		}
+		date = d
+	}
+
+	j, err := h.jobs.Enqueue(r.Context(), date)
+	if err != nil {
+		slog.Error("failed to enqueue snapshot generation", "date", date.Format("2006-01-02"), "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.Header().Set("Location", "/api/v1/jobs/"+strconv.FormatInt(j.ID, 10))
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// GetJob handles GET /api/v1/jobs/{id}.
+//
+// @Summary      Job status
+// @Description  Returns status, latest progress event, and (once succeeded) the result of a background job.
+// @Tags         jobs
+// @Produce      json
+// @Param        id  path  int  true  "Job ID"
+// @Success      200  {object}  job.Job
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Router       /api/v1/jobs/{id} [get]
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	j, err := h.jobs.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, job.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		slog.Error("failed to get job", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, j)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/job"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+type mockJobQueue struct {
+	job *job.Job
+	err error
+}
+
+func (m *mockJobQueue) Enqueue(_ context.Context, date time.Time) (*job.Job, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &job.Job{ID: 7, Kind: job.KindSnapshotGenerate, SnapshotDate: date, Status: job.StatusQueued}, nil
+}
+
+func (m *mockJobQueue) Get(_ context.Context, _ int64) (*job.Job, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.job, nil
+}
+
+func TestGenerateSnapshotAccepted(t *testing.T) {
+	h := NewJobHandler(&mockJobQueue{}, snapdate.UTC)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/generate", nil)
+	w := httptest.NewRecorder()
+	h.GenerateSnapshot(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/v1/jobs/7" {
+		t.Errorf("Location = %q, want /api/v1/jobs/7", loc)
+	}
+	var j job.Job
+	if err := json.NewDecoder(w.Body).Decode(&j); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if j.Status != job.StatusQueued {
+		t.Errorf("Status = %q, want %q", j.Status, job.StatusQueued)
+	}
+}
+
+func TestGenerateSnapshotPastDate(t *testing.T) {
+	h := NewJobHandler(&mockJobQueue{}, snapdate.UTC)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/generate?date=2026-03-01", nil)
+	w := httptest.NewRecorder()
+	h.GenerateSnapshot(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	var j job.Job
+	if err := json.NewDecoder(w.Body).Decode(&j); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !j.SnapshotDate.Equal(want) {
+		t.Errorf("SnapshotDate = %s, want %s", j.SnapshotDate, want)
+	}
+
+	for _, date := range []string{"01.03.2026", snapdate.UTC.Today().AddDate(0, 0, 1).Format("2006-01-02")} {
+		w := httptest.NewRecorder()
+		h.GenerateSnapshot(w, httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/generate?date="+date, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("date %s: status = %d, want %d", date, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGenerateSnapshotEnqueueError(t *testing.T) {
+	h := NewJobHandler(&mockJobQueue{err: errors.New("db down")}, snapdate.UTC)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/generate", nil)
+	w := httptest.NewRecorder()
+	h.GenerateSnapshot(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		q    *mockJobQueue
+		want int
+	}{
+		{"found", "7", &mockJobQueue{job: &job.Job{ID: 7, Status: job.StatusRunning}}, http.StatusOK},
+		{"not found", "8", &mockJobQueue{err: job.ErrNotFound}, http.StatusNotFound},
+		{"invalid id", "abc", &mockJobQueue{}, http.StatusBadRequest},
+		{"zero id", "0", &mockJobQueue{}, http.StatusBadRequest},
+		{"internal error", "7", &mockJobQueue{err: errors.New("db down")}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewJobHandler(tt.q, snapdate.UTC)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			h.GetJob(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIPRateLimiterRefill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newIPRateLimiter(1, 2)
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.allow("1.2.3.4"); !ok {
+			t.Fatalf("request %d rejected within burst", i+1)
+		}
+	}
+	ok, wait := l.allow("1.2.3.4")
+	if ok {
+		t.Fatal("request beyond burst allowed")
+	}
+	if wait != time.Second {
+		t.Errorf("wait = %v, want 1s", wait)
+	}
+	if ok, _ := l.allow("5.6.7.8"); !ok {
+		t.Error("other IP must have its own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.allow("1.2.3.4"); !ok {
+		t.Error("request rejected after refill")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := rateLimitMiddleware(newIPRateLimiter(1, 1), false, next)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 203.0.113.7")
+
+	if got := clientIP(req, false); got != "10.0.0.1" {
+		t.Errorf("untrusted clientIP = %q, want 10.0.0.1", got)
+	}
+	if got := clientIP(req, true); got != "203.0.113.7" {
+		t.Errorf("trusted clientIP = %q, want 203.0.113.7", got)
+	}
+}
+
+func TestMaxBodyMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := maxBodyMiddleware(8, next)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/generate", strings.NewReader("0123456789"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
+
+func TestConcurrencyCap(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := concurrencyCap(1, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("second request status = %d, want 503", w.Code)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first request status = %d, want 200", code)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mtlprog/stat/internal/decjson"
+)
+
+// CORS configures cross-origin access for browser dashboards.
+type CORS struct {
+	Origins []string // allowed origins; "*" allows any
+	Methods []string
+}
+
+// defaultCORS is what the API always served before CORS became configurable.
+var defaultCORS = CORS{Origins: []string{"*"}, Methods: []string{"GET", "OPTIONS"}}
+
+func corsMiddleware(cfg CORS, next http.Handler) http.Handler {
+	anyOrigin := slices.Contains(cfg.Origins, "*")
+	methods := strings.Join(cfg.Methods, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		switch {
+		case anyOrigin:
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && slices.Contains(cfg.Origins, origin):
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		}
+		w.Header().Set("Access-Control-Allow-Methods", methods)
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, X-API-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "API-Version, Location, Retry-After, X-Total-Count, X-Next-Cursor, "+decjson.Header)
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxAPIVersion is the newest response shape served. Bump it when a handler
+// starts branching on apiVersion(r) — /api/v{N} and the vendor media type for
+// that N become routable at the same time.
+const maxAPIVersion = 2
+
+// versionMediaType matches Accept values like application/vnd.mtlstat.v2+json.
+var versionMediaType = regexp.MustCompile(`application/vnd\.mtlstat\.v(\d+)\+json`)
+
+type versionKey struct{}
+
+// apiVersion returns the negotiated API version for r (1 when unset).
+func apiVersion(r *http.Request) int {
+	if v, ok := r.Context().Value(versionKey{}).(int); ok {
+		return v
+	}
+	return 1
+}
+
+// versionMiddleware negotiates the response version from an /api/vN/ prefix
+// (N > 1) or else the Accept header, so existing /api/v1/ URLs can opt in to a
+// newer shape without changing paths. Every /api/vN/ path is served by the
+// /api/v1/ routes; a version only has to override the handlers it changes.
+func versionMiddleware(maxVersion int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := 1
+
+		if rest, ok := strings.CutPrefix(r.URL.Path, "/api/v"); ok {
+			num, tail, _ := strings.Cut(rest, "/")
+			n, err := strconv.Atoi(num)
+			if err != nil || n < 1 || n > maxVersion {
+				writeProblem(w, http.StatusNotFound, CodeUnsupportedVersion, fmt.Sprintf("unsupported API version v%s", num))
+				return
+			}
+			version = n
+			if n != 1 {
+				r = r.Clone(r.Context())
+				r.URL.Path = "/api/v1/" + tail
+				r.URL.RawPath = ""
+			}
+		}
+		if version == 1 {
+			if m := versionMediaType.FindStringSubmatch(r.Header.Get("Accept")); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				if n < 1 || n > maxVersion {
+					writeProblem(w, http.StatusNotAcceptable, CodeUnsupportedVersion, fmt.Sprintf("unsupported API version v%d", n))
+					return
+				}
+				version = n
+			}
+		}
+
+		w.Header().Set("API-Version", strconv.Itoa(version))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionKey{}, version)))
+	})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	tests := []struct {
+		name   string
+		cfg    CORS
+		origin string
+		want   string
+	}{
+		{"any origin", defaultCORS, "https://dash.example", "*"},
+		{"allowed origin", CORS{Origins: []string{"https://dash.example"}, Methods: []string{"GET"}}, "https://dash.example", "https://dash.example"},
+		{"disallowed origin", CORS{Origins: []string{"https://dash.example"}, Methods: []string{"GET"}}, "https://evil.example", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/indicators", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			corsMiddleware(tt.cfg, next).ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { t.Error("preflight reached handler") })
+	cfg := CORS{Origins: []string{"*"}, Methods: []string{"GET", "POST", "OPTIONS"}}
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/snapshots/generate", nil)
+	w := httptest.NewRecorder()
+	corsMiddleware(cfg, next).ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, OPTIONS" {
+		t.Errorf("Allow-Methods = %q", got)
+	}
+}
+
+func TestVersionMiddleware(t *testing.T) {
+	var gotPath string
+	var gotVersion int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotVersion = r.URL.Path, apiVersion(r)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		wantStatus  int
+		wantPath    string
+		wantVersion int
+	}{
+		{"v1 prefix", "/api/v1/indicators", "", http.StatusOK, "/api/v1/indicators", 1},
+		{"v2 prefix routes to v1 handlers", "/api/v2/indicators", "", http.StatusOK, "/api/v1/indicators", 2},
+		{"unsupported prefix", "/api/v3/indicators", "", http.StatusNotFound, "", 0},
+		{"accept header", "/api/v1/indicators", "application/vnd.mtlstat.v2+json", http.StatusOK, "/api/v1/indicators", 2},
+		{"unsupported accept", "/api/v1/indicators", "application/vnd.mtlstat.v9+json", http.StatusNotAcceptable, "", 0},
+		{"legacy path", "/api/snapshots", "", http.StatusOK, "/api/snapshots", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotVersion = "", 0
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			versionMiddleware(2, next).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if gotPath != tt.wantPath || gotVersion != tt.wantVersion {
+				t.Errorf("handler saw path %q v%d, want %q v%d", gotPath, gotVersion, tt.wantPath, tt.wantVersion)
+			}
+			if tt.wantStatus == http.StatusOK && w.Header().Get("API-Version") != strconv.Itoa(tt.wantVersion) {
+				t.Errorf("API-Version = %q, want %d", w.Header().Get("API-Version"), tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/export"
+)
+
+// MonitoringSource builds the MONITORING row of a date (export.MonitoringService).
+type MonitoringSource interface {
+	Report(ctx context.Context, date time.Time) (export.MonitoringReport, error)
+}
+
+// MonitoringHandler serves the MONITORING row of a date.
+type MonitoringHandler struct {
+	source MonitoringSource
+	locale export.Locale // date format of the xlsx rendering
+}
+
+// NewMonitoringHandler creates a new MONITORING handler.
+func NewMonitoringHandler(source MonitoringSource, locale export.Locale) *MonitoringHandler {
+	return &MonitoringHandler{source: source, locale: locale}
+}
+
+// GetMonitoring handles GET /api/v1/monitoring.
+//
+// @Summary      MONITORING row
+// @Description  The row the daily export appends to the MONITORING sheet for a date, built from the stored indicators: every column in sheet order with its header, indicator ID and value. `source` tells where each cell comes from: indicator, missing (mapped indicator without a value that day), fixed, empty (placeholder slot) or note (issuance and annotation text). `format=csv` returns a header row and the data row with exact decimals; `format=xlsx` returns a workbook in the sheet's layout that `stat import-excel` reads back.
+// @Tags         indicators
+// @Produce      json
+// @Produce      text/csv
+// @Param        date    query  string  false  "Date (YYYY-MM-DD, default latest)"
+// @Param        format  query  string  false  "json (default), csv or xlsx"
+// @Success      200  {object}  export.MonitoringReport
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/monitoring [get]
+func (h *MonitoringHandler) GetMonitoring(w http.ResponseWriter, r *http.Request) {
+	date, err := parseOptionalDate(r.URL.Query().Get("date"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", "json", "csv", "xlsx":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid format, expected json, csv or xlsx")
+		return
+	}
+
+	report, err := h.source.Report(r.Context(), date)
+	if errors.Is(err, export.ErrNoIndicators) {
+		writeProblem(w, http.StatusNotFound, CodeIndicatorsNotFound, "no indicators for date")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to build monitoring row", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	var buf bytes.Buffer
+	var contentType string
+	switch format {
+	case "csv":
+		err = export.WriteMonitoringCSV(&buf, []export.MonitoringReport{report})
+		contentType = "text/csv; charset=utf-8"
+	case "xlsx":
+		err = export.WriteMonitoringXLSX(&buf, []export.MonitoringReport{report}, h.locale)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to render monitoring row", "format", format, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="monitoring-`+report.Date.Format("2006-01-02")+"."+format+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
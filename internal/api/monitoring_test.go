@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/export"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+type stubMonitoring struct {
+	date time.Time
+}
+
+func (s *stubMonitoring) Report(_ context.Context, date time.Time) (export.MonitoringReport, error) {
+	s.date = date
+	if !date.IsZero() && date.Year() < 2026 {
+		return export.MonitoringReport{}, export.ErrNoIndicators
+	}
+	rows := []export.IndicatorRow{{Indicator: indicator.Indicator{ID: 1, Value: decimal.NewFromInt(42)}}}
+	return export.NewMonitoringReport(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), rows, export.MonitoringNotes{}), nil
+}
+
+func TestGetMonitoring(t *testing.T) {
+	src := &stubMonitoring{}
+	srv := NewServer("0", nil, nil, WithMonitoring(src, export.DefaultLocale))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/monitoring?date=2026-10-01", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if src.date.Format("2006-01-02") != "2026-10-01" {
+		t.Errorf("date = %s, want 2026-10-01", src.date)
+	}
+	var got export.MonitoringReport
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if c := got.Columns[0]; c.IndicatorID != 1 || c.Source != export.CellIndicator || !c.Value.Equal(decimal.NewFromInt(42)) {
+		t.Errorf("first column = %+v", c)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/monitoring?format=csv", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("csv: status = %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !src.date.IsZero() {
+		t.Errorf("date = %s, want zero (latest)", src.date)
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "2026-10-01,42,") {
+		t.Errorf("csv body = %q", w.Body)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "monitoring-2026-10-01.csv") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+}
+
+func TestGetMonitoringErrors(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithMonitoring(&stubMonitoring{}, export.DefaultLocale))
+	for _, tc := range []struct {
+		url  string
+		want int
+	}{
+		{"/api/v1/monitoring?date=01.10.2026", http.StatusBadRequest},
+		{"/api/v1/monitoring?format=pdf", http.StatusBadRequest},
+		{"/api/v1/monitoring?date=2025-01-01", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.url, w.Code, tc.want)
+		}
+	}
+}
+
+func TestGetMonitoringRequiresKey(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithMonitoring(&stubMonitoring{}, export.DefaultLocale),
+		WithAPIKeys(APIKeys{Verifier: newKeyStub(), Required: true}))
+	if w := keyedGet(srv, "/api/v1/monitoring", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/explorer"
+)
+
+// OperationLister lists account operations from Horizon.
+type OperationLister interface {
+	List(ctx context.Context, q explorer.Query) (explorer.Page, error)
+}
+
+// OperationsHandler serves the operations of the fund accounts.
+type OperationsHandler struct {
+	lister OperationLister
+	fund   map[string]bool
+}
+
+// NewOperationsHandler creates a new operations handler serving the accounts
+// of domain.AccountRegistry().
+func NewOperationsHandler(lister OperationLister) *OperationsHandler {
+	fund := make(map[string]bool)
+	for _, a := range domain.AccountRegistry() {
+		fund[a.Address] = true
+	}
+	return &OperationsHandler{lister: lister, fund: fund}
+}
+
+const (
+	defaultOperationsLimit = 50
+	maxOperationsLimit     = 200
+)
+
+var cursorPattern = regexp.MustCompile(`^[0-9]{1,20}$`)
+
+// GetOperations handles GET /api/v1/accounts/{address}/operations.
+//
+// @Summary      Fund account operations
+// @Description  Recent operations of a fund account from Horizon, joined with their transaction memos. Transfers carry `direction` (in/out) and `counterparty`; offers carry `selling`, `buying` and `price`. `asset` matches the transferred, sold or bought asset. Pass `next` back as `cursor` for the following page; an empty `next` means the history is exhausted. A filtered page may hold fewer than `limit` records while `next` is still set. Horizon pages are cached briefly, so the newest operations can lag by up to a minute.
+// @Tags         accounts
+// @Produce      json
+// @Param        address    path   string  true   "Fund account (G...)"
+// @Param        cursor     query  string  false  "Paging token to continue after"
+// @Param        limit      query  int     false  "Records per page, 1-200 (default: 50)"
+// @Param        order      query  string  false  "asc or desc (default: desc)"
+// @Param        asset      query  string  false  "XLM or CODE-ISSUER"
+// @Param        direction  query  string  false  "in or out (transfers only)"
+// @Param        category   query  string  false  "payment, trade, trustline, liquidity, data, account or other"
+// @Success      200  {object}  explorer.Page
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      502  {object}  Problem
+// @Router       /api/v1/accounts/{address}/operations [get]
+func (h *OperationsHandler) GetOperations(w http.ResponseWriter, r *http.Request) {
+	account := r.PathValue("address")
+	if !accountPattern.MatchString(account) {
+		writeError(w, http.StatusBadRequest, "invalid account address")
+		return
+	}
+	if !h.fund[account] {
+		writeError(w, http.StatusNotFound, "not a fund account")
+		return
+	}
+
+	params := r.URL.Query()
+	q := explorer.Query{
+		Account:   account,
+		Cursor:    params.Get("cursor"),
+		Order:     params.Get("order"),
+		Limit:     defaultOperationsLimit,
+		Asset:     params.Get("asset"),
+		Direction: explorer.Direction(params.Get("direction")),
+		Category:  explorer.Category(params.Get("category")),
+	}
+	if q.Cursor != "" && !cursorPattern.MatchString(q.Cursor) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidCursor, "invalid cursor")
+		return
+	}
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxOperationsLimit {
+			writeError(w, http.StatusBadRequest, "invalid limit, expected 1-200")
+			return
+		}
+		q.Limit = n
+	}
+	switch q.Order {
+	case "":
+		q.Order = "desc"
+	case "asc", "desc":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid order, expected asc or desc")
+		return
+	}
+	if q.Asset != "" && !assetKeyPattern.MatchString(q.Asset) {
+		writeError(w, http.StatusBadRequest, "invalid asset, expected XLM or CODE-ISSUER")
+		return
+	}
+	if q.Direction != "" && q.Direction != explorer.In && q.Direction != explorer.Out {
+		writeError(w, http.StatusBadRequest, "invalid direction, expected in or out")
+		return
+	}
+	if q.Category != "" && !slices.Contains(explorer.Categories, q.Category) {
+		writeError(w, http.StatusBadRequest, "invalid category")
+		return
+	}
+
+	page, err := h.lister.List(r.Context(), q)
+	if err != nil {
+		slog.Error("failed to list account operations", "account", account, "error", err)
+		writeProblem(w, http.StatusBadGateway, CodeHorizonUnavailable, "horizon unavailable")
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
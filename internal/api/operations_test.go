@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/explorer"
+)
+
+type stubOperations struct {
+	query explorer.Query
+	err   error
+}
+
+func (s *stubOperations) List(_ context.Context, q explorer.Query) (explorer.Page, error) {
+	s.query = q
+	return explorer.Page{Records: []explorer.Operation{{ID: "42", Type: "payment", Category: explorer.CategoryPayment}}, Next: "42"}, s.err
+}
+
+func TestGetOperations(t *testing.T) {
+	fundAccount := domain.AccountRegistry()[0].Address
+	src := &stubOperations{}
+	srv := NewServer("0", nil, nil, WithExplorer(src))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"/api/v1/accounts/"+fundAccount+"/operations?limit=10&direction=in&category=payment&asset=XLM&cursor=123", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var page explorer.Page
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 1 || page.Next != "42" {
+		t.Errorf("page = %+v", page)
+	}
+	want := explorer.Query{Account: fundAccount, Cursor: "123", Order: "desc", Limit: 10, Asset: "XLM", Direction: explorer.In, Category: explorer.CategoryPayment}
+	if src.query != want {
+		t.Errorf("query = %+v, want %+v", src.query, want)
+	}
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/api/v1/accounts/" + fundAccount + "/operations?limit=500", http.StatusBadRequest},
+		{"/api/v1/accounts/" + fundAccount + "/operations?order=up", http.StatusBadRequest},
+		{"/api/v1/accounts/" + fundAccount + "/operations?direction=both", http.StatusBadRequest},
+		{"/api/v1/accounts/" + fundAccount + "/operations?category=swap", http.StatusBadRequest},
+		{"/api/v1/accounts/" + fundAccount + "/operations?cursor=abc", http.StatusBadRequest},
+		{"/api/v1/accounts/GBAD/operations", http.StatusBadRequest},
+		{"/api/v1/accounts/GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA/operations", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.code {
+			t.Errorf("%s: status = %d, want %d", tc.path, w.Code, tc.code)
+		}
+	}
+
+	src.err = errors.New("horizon down")
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+fundAccount+"/operations", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("upstream failure: status = %d, want 502", w.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// GetPeers handles GET /api/v1/analytics/peers.
+//
+// @Summary      Peer treasury comparison
+// @Description  Compares the fund with the external treasuries configured in PEER_ACCOUNTS, as captured in a stored snapshot: assets value in EURMTL, token count and holder count, plus value and holder ratios against the fund. Fund holders are I62; a peer's holders are the authorized trustlines of its most widely held issued asset. Peers are empty for snapshots taken without PEER_ACCOUNTS.
+// @Tags         analytics
+// @Produce      json
+// @Param        date  query  string  false  "Snapshot date (YYYY-MM-DD, default latest)"
+// @Success      200  {object}  analytics.PeerComparison
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/analytics/peers [get]
+func (h *Handler) GetPeers(w http.ResponseWriter, r *http.Request) {
+	s, data, ok := h.snapshotData(w, r, "peer comparison")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, analytics.ComparePeers(s.SnapshotDate.Format("2006-01-02"), data))
+}
+
+// snapshotData loads and decodes the snapshot selected by the optional date
+// query parameter (latest when absent). On failure it writes the error
+// response and returns ok=false; purpose is only used in log messages.
+func (h *Handler) snapshotData(w http.ResponseWriter, r *http.Request, purpose string) (*snapshot.Snapshot, domain.FundStructureData, bool) {
+	date, err := parseOptionalDate(r.URL.Query().Get("date"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
+		return nil, domain.FundStructureData{}, false
+	}
+
+	var s *snapshot.Snapshot
+	if date.IsZero() {
+		s, err = h.snapshots.GetLatest(r.Context(), "mtlf")
+	} else {
+		s, err = h.snapshots.GetByDate(r.Context(), "mtlf", date)
+	}
+	if err != nil {
+		if errors.Is(err, snapshot.ErrNotFound) {
+			writeProblem(w, http.StatusNotFound, CodeSnapshotNotFound, "snapshot not found")
+			return nil, domain.FundStructureData{}, false
+		}
+		slog.Error("failed to get snapshot for "+purpose, "date", date, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return nil, domain.FundStructureData{}, false
+	}
+
+	var data domain.FundStructureData
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		slog.Error("failed to decode snapshot for "+purpose, "date", s.SnapshotDate, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return nil, domain.FundStructureData{}, false
+	}
+	return s, data, true
+}
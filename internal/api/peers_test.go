@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func TestGetPeers(t *testing.T) {
+	data, _ := json.Marshal(domain.FundStructureData{
+		AggregatedTotals: domain.AggregatedTotals{TotalEURMTL: decimal.NewFromInt(100)},
+		Peers:            []domain.PeerMetrics{{Name: "A", Address: "GA", TotalEURMTL: decimal.NewFromInt(50)}},
+	})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 2, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Data: []byte(`{}`)},
+	}}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+	w := httptest.NewRecorder()
+	handler.GetPeers(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/peers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got analytics.PeerComparison
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Date != "2026-10-02" || len(got.Peers) != 1 || got.Peers[0].ValueVsFund == nil {
+		t.Errorf("latest comparison = %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetPeers(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/peers?date=2026-10-01", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("by date: status = %d, want 200", w.Code)
+	}
+	got = analytics.PeerComparison{}
+	json.NewDecoder(w.Body).Decode(&got)
+	if got.Date != "2026-10-01" || got.Peers == nil || len(got.Peers) != 0 {
+		t.Errorf("snapshot without peers = %+v, want empty peers list", got)
+	}
+}
+
+func TestGetPeersErrors(t *testing.T) {
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, &mockSnapshotRepo{}))
+
+	w := httptest.NewRecorder()
+	handler.GetPeers(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/peers?date=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid date: status = %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetPeers(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/peers", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("no snapshots: status = %d, want 404", w.Code)
+	}
+}
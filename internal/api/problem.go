@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Error codes of problem responses. Clients branch on these; the detail
+// text may change between releases.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidDate        = "invalid_date"
+	CodeInvalidPeriod      = "invalid_period"
+	CodeInvalidJSON        = "invalid_json"
+	CodeInvalidCursor      = "invalid_cursor"
+	CodeUnauthorized       = "unauthorized"
+	CodeAPIKeyRequired     = "api_key_required"
+	CodeInvalidAPIKey      = "invalid_api_key"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeSnapshotNotFound   = "snapshot_not_found"
+	CodeIndicatorsNotFound = "indicators_not_found"
+	CodeReportNotFound     = "report_not_found"
+	CodeUnsupportedVersion = "unsupported_version"
+	CodePreconditionFailed = "precondition_failed"
+	CodeBodyTooLarge       = "body_too_large"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeHorizonUnavailable = "horizon_unavailable"
+	CodeOverloaded         = "overloaded"
+)
+
+// problemContentType is the RFC 7807 media type of error responses.
+const problemContentType = "application/problem+json"
+
+// problemLanguages are the languages of problem titles, the default first.
+var problemLanguages = []string{"en", "ru"}
+
+// problemKind is the status and the localized titles of one error code.
+type problemKind struct {
+	status int
+	titles map[string]string // language → title
+}
+
+var problemKinds = map[string]problemKind{
+	CodeBadRequest:         {http.StatusBadRequest, map[string]string{"en": "Bad request", "ru": "Некорректный запрос"}},
+	CodeInvalidDate:        {http.StatusBadRequest, map[string]string{"en": "Invalid date", "ru": "Некорректная дата"}},
+	CodeInvalidPeriod:      {http.StatusBadRequest, map[string]string{"en": "Invalid period", "ru": "Некорректный период"}},
+	CodeInvalidJSON:        {http.StatusBadRequest, map[string]string{"en": "Invalid JSON body", "ru": "Некорректное тело JSON"}},
+	CodeInvalidCursor:      {http.StatusBadRequest, map[string]string{"en": "Invalid cursor", "ru": "Некорректный курсор"}},
+	CodeUnauthorized:       {http.StatusUnauthorized, map[string]string{"en": "Unauthorized", "ru": "Требуется авторизация"}},
+	CodeAPIKeyRequired:     {http.StatusUnauthorized, map[string]string{"en": "API key required", "ru": "Требуется API-ключ"}},
+	CodeInvalidAPIKey:      {http.StatusUnauthorized, map[string]string{"en": "Invalid API key", "ru": "Недействительный API-ключ"}},
+	CodeForbidden:          {http.StatusForbidden, map[string]string{"en": "Forbidden", "ru": "Доступ запрещён"}},
+	CodeNotFound:           {http.StatusNotFound, map[string]string{"en": "Not found", "ru": "Не найдено"}},
+	CodeSnapshotNotFound:   {http.StatusNotFound, map[string]string{"en": "Snapshot not found", "ru": "Снимок не найден"}},
+	CodeIndicatorsNotFound: {http.StatusNotFound, map[string]string{"en": "Indicators not found", "ru": "Индикаторы не найдены"}},
+	CodeReportNotFound:     {http.StatusNotFound, map[string]string{"en": "Report not found", "ru": "Отчёт не найден"}},
+	CodeUnsupportedVersion: {http.StatusNotFound, map[string]string{"en": "Unsupported API version", "ru": "Неподдерживаемая версия API"}},
+	CodePreconditionFailed: {http.StatusPreconditionFailed, map[string]string{"en": "Precondition failed", "ru": "Версия не совпадает"}},
+	CodeBodyTooLarge:       {http.StatusRequestEntityTooLarge, map[string]string{"en": "Request body too large", "ru": "Слишком большое тело запроса"}},
+	CodeRateLimited:        {http.StatusTooManyRequests, map[string]string{"en": "Rate limit exceeded", "ru": "Превышен лимит запросов"}},
+	CodeInternal:           {http.StatusInternalServerError, map[string]string{"en": "Internal error", "ru": "Внутренняя ошибка"}},
+	CodeHorizonUnavailable: {http.StatusBadGateway, map[string]string{"en": "Horizon unavailable", "ru": "Horizon недоступен"}},
+	CodeOverloaded:         {http.StatusServiceUnavailable, map[string]string{"en": "Too many concurrent requests", "ru": "Сервер перегружен"}},
+}
+
+// statusCodes is the code of an error written without one.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodeBodyTooLarge,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusBadGateway:            CodeHorizonUnavailable,
+	http.StatusServiceUnavailable:    CodeOverloaded,
+}
+
+// Problem is an RFC 7807 error response.
+type Problem struct {
+	Type   string `json:"type" example:"/api/v1/errors#snapshot_not_found"`
+	Title  string `json:"title" example:"Snapshot not found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail,omitempty" example:"snapshot not found for date"`
+	Code   string `json:"code" example:"snapshot_not_found"`
+	// Error repeats Detail for clients of the {"error": "..."} body that
+	// preceded problem responses.
+	Error string `json:"error" example:"snapshot not found for date"`
+}
+
+// ErrorCode documents one problem code.
+type ErrorCode struct {
+	Code   string            `json:"code"`
+	Status int               `json:"status"` // usual status; unsupported_version is 406 when asked for in Accept
+	Titles map[string]string `json:"titles"` // language → title
+}
+
+// writeError writes a problem response with the default code of status.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	code, ok := statusCodes[status]
+	switch {
+	case ok:
+	case status >= 500:
+		code = CodeInternal
+	default:
+		code = CodeBadRequest
+	}
+	writeProblem(w, status, code, msg)
+}
+
+// writeProblem writes a problem response with code, titled in the language
+// problemMiddleware negotiated.
+func writeProblem(w http.ResponseWriter, status int, code, msg string) {
+	lang := problemLanguages[0]
+	if lw := findLanguageWriter(w); lw != nil {
+		lang = lw.lang
+	}
+	title := http.StatusText(status)
+	if k, ok := problemKinds[code]; ok {
+		title = k.titles[lang]
+	}
+	data, err := json.Marshal(Problem{Type: "/api/v1/errors#" + code, Title: title, Status: status, Detail: msg, Code: code, Error: msg})
+	if err != nil {
+		slog.Error("failed to marshal problem response", "error", err)
+		http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("Content-Language", lang)
+	w.WriteHeader(status)
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		slog.Debug("failed to write HTTP response body", "error", err)
+	}
+}
+
+// languageWriter carries the negotiated problem language to writeProblem.
+type languageWriter struct {
+	http.ResponseWriter
+	lang string
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (lw *languageWriter) Unwrap() http.ResponseWriter { return lw.ResponseWriter }
+
+func findLanguageWriter(w http.ResponseWriter) *languageWriter {
+	for {
+		if lw, ok := w.(*languageWriter); ok {
+			return lw
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+}
+
+// problemMiddleware negotiates the language of problem titles from
+// Accept-Language. It goes outermost so the rate limit and the other
+// middleware answer in the same language.
+func problemMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&languageWriter{ResponseWriter: w, lang: negotiateLanguage(r.Header.Get("Accept-Language"))}, r)
+	})
+}
+
+// negotiateLanguage picks the problemLanguages entry with the highest q in
+// an Accept-Language header, matching on the primary subtag ("ru-RU" is
+// ru); the default when none matches.
+func negotiateLanguage(header string) string {
+	best, bestQ := problemLanguages[0], 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if q > bestQ && slices.Contains(problemLanguages, primary) {
+			best, bestQ = primary, q
+		}
+	}
+	return best
+}
+
+// ListErrorCodes handles GET /api/v1/errors.
+//
+// @Summary      Error codes
+// @Description  Lists the codes of error responses with their HTTP status and titles per language. Errors are RFC 7807 application/problem+json objects: type (/api/v1/errors#CODE), title (in the Accept-Language language: en or ru), status, detail, code, and error (the detail again, for older clients). Branch on code, not on detail.
+// @Tags         errors
+// @Produce      json
+// @Success      200  {array}  ErrorCode
+// @Router       /api/v1/errors [get]
+func ListErrorCodes(w http.ResponseWriter, _ *http.Request) {
+	codes := make([]ErrorCode, 0, len(problemKinds))
+	for code, k := range problemKinds {
+		codes = append(codes, ErrorCode{Code: code, Status: k.status, Titles: k.titles})
+	}
+	slices.SortFunc(codes, func(a, b ErrorCode) int { return strings.Compare(a.Code, b.Code) })
+	writeJSON(w, http.StatusOK, codes)
+}
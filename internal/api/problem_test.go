@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                          "en",
+		"ru":                        "ru",
+		"ru-RU,ru;q=0.9,en;q=0.8":   "ru",
+		"de-DE,en;q=0.5,ru;q=0.7":   "ru",
+		"fr, en;q=0.9":              "en",
+		"ru;q=bogus, en;q=0.1":      "en",
+		"de":                        "en",
+		"EN-us;q=0.4, RU-ru;q=0.41": "ru",
+	} {
+		if got := negotiateLanguage(header); got != want {
+			t.Errorf("negotiateLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestProblemResponse(t *testing.T) {
+	srv := NewServer("0", nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v9/snapshots/latest", nil)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != problemContentType || w.Header().Get("Content-Language") != "ru" {
+		t.Fatalf("status %d, content type %q, language %q", w.Code, w.Header().Get("Content-Type"), w.Header().Get("Content-Language"))
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:   "/api/v1/errors#unsupported_version",
+		Title:  "Неподдерживаемая версия API",
+		Status: http.StatusNotFound,
+		Detail: "unsupported API version v9",
+		Code:   CodeUnsupportedVersion,
+		Error:  "unsupported API version v9",
+	}
+	if p != want {
+		t.Errorf("problem = %+v, want %+v", p, want)
+	}
+
+	// Without the middleware the title is in English.
+	w = httptest.NewRecorder()
+	writeError(w, http.StatusBadGateway, "upstream down")
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Code != CodeHorizonUnavailable || p.Title != "Horizon unavailable" {
+		t.Errorf("default problem = %+v", p)
+	}
+}
+
+func TestListErrorCodes(t *testing.T) {
+	srv := NewServer("0", nil, nil)
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var codes []ErrorCode
+	if err := json.Unmarshal(w.Body.Bytes(), &codes); err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != len(problemKinds) || codes[0].Code != CodeAPIKeyRequired {
+		t.Fatalf("codes = %+v", codes)
+	}
+	for _, c := range codes {
+		for _, lang := range problemLanguages {
+			if c.Titles[lang] == "" {
+				t.Errorf("%s has no %s title", c.Code, lang)
+			}
+		}
+	}
+}
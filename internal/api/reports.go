@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/period"
+)
+
+// ReportStore loads stored period reports.
+type ReportStore interface {
+	Get(ctx context.Context, slug string, p period.Period) (*period.Stored, error)
+}
+
+// ReportHandler serves month-end and quarter-end reports.
+type ReportHandler struct {
+	reports ReportStore
+}
+
+// NewReportHandler creates a new period report handler.
+func NewReportHandler(reports ReportStore) *ReportHandler {
+	return &ReportHandler{reports: reports}
+}
+
+// GetReport handles GET /api/v1/reports/{period}.
+//
+// @Summary      Period report
+// @Description  Returns the stored month-end (YYYY-MM) or quarter-end (YYYY-QN) report: closing indicator values with changes since the previous period close, the top movers by relative change, and dividend totals (I11 at each month end, I18 recipients at period end). Reports are generated by `stat report` on the last day of each configured period, or by `stat period-report`. With format=markdown the rendered Markdown document is returned instead.
+// @Tags         reports
+// @Produce      json
+// @Produce      text/markdown
+// @Param        period  path   string  true   "Period: YYYY-MM or YYYY-QN"
+// @Param        format  query  string  false  "json (default) or markdown"
+// @Success      200  {object}  period.Report
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/reports/{period} [get]
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	p, err := period.Parse(r.PathValue("period"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		writeError(w, http.StatusBadRequest, "invalid format, expected json or markdown")
+		return
+	}
+
+	stored, err := h.reports.Get(r.Context(), "mtlf", p)
+	if err != nil {
+		if errors.Is(err, period.ErrNotFound) {
+			writeProblem(w, http.StatusNotFound, CodeReportNotFound, "report not found for period")
+			return
+		}
+		slog.Error("failed to get period report", "period", p.String(), "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(stored.Markdown))
+		return
+	}
+	writeJSON(w, http.StatusOK, stored.Report)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/period"
+)
+
+type stubReports struct {
+	stored map[string]*period.Stored
+}
+
+func (s *stubReports) Get(_ context.Context, _ string, p period.Period) (*period.Stored, error) {
+	r, ok := s.stored[p.String()]
+	if !ok {
+		return nil, period.ErrNotFound
+	}
+	return r, nil
+}
+
+func serveReport(t *testing.T, store ReportStore, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/reports/{period}", NewReportHandler(store).GetReport)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestGetReport(t *testing.T) {
+	store := &stubReports{stored: map[string]*period.Stored{
+		"2026-Q3": {Report: period.Report{Period: "2026-Q3", Kind: period.Quarter}, Markdown: "# MTL Fund report 2026-Q3\n"},
+	}}
+
+	w := serveReport(t, store, "/api/v1/reports/2026-q3")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got period.Report
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Period != "2026-Q3" || got.Kind != period.Quarter {
+		t.Errorf("report = %+v", got)
+	}
+
+	w = serveReport(t, store, "/api/v1/reports/2026-Q3?format=markdown")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") || !strings.HasPrefix(w.Body.String(), "# MTL Fund report") {
+		t.Errorf("markdown response: %s %q", ct, w.Body.String())
+	}
+}
+
+func TestGetReportErrors(t *testing.T) {
+	store := &stubReports{}
+	for target, want := range map[string]int{
+		"/api/v1/reports/2026-13":            http.StatusBadRequest,
+		"/api/v1/reports/2026-09?format=pdf": http.StatusBadRequest,
+		"/api/v1/reports/2026-09":            http.StatusNotFound,
+	} {
+		if w := serveReport(t, store, target); w.Code != want {
+			t.Errorf("%s: status = %d, want %d", target, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/period"
+)
+
+// ReturnsSource decomposes shareholder returns per period.
+type ReturnsSource interface {
+	Returns(ctx context.Context, slug string, p period.Period) (*period.Returns, error)
+}
+
+// ReturnsResponse is the return decomposition of one period.
+type ReturnsResponse struct {
+	Period  string         `json:"period"`
+	Start   string         `json:"start"` // YYYY-MM-DD
+	End     string         `json:"end"`   // YYYY-MM-DD
+	Returns period.Returns `json:"returns"`
+}
+
+// ReturnsHandler serves the shareholder return decomposition.
+type ReturnsHandler struct {
+	returns ReturnsSource
+}
+
+// NewReturnsHandler creates a new returns handler.
+func NewReturnsHandler(returns ReturnsSource) *ReturnsHandler {
+	return &ReturnsHandler{returns: returns}
+}
+
+// GetReturns handles GET /api/v1/analytics/returns.
+//
+// @Summary      Shareholder return decomposition
+// @Description  Splits the total return of one share held through a month (YYYY-MM) or quarter (YYYY-QN) into price change and dividends received, and the price change into capital gain (market capitalisation I5 × I10 over the opening shares) and dilution from shares issued during the period. Components are percentages of the opening share price (I10 at the previous period close); dividends are I15 summed over the month ends. Computed from stored indicators on request, also for the current, unfinished period.
+// @Tags         analytics
+// @Produce      json
+// @Param        period  query  string  true  "Period: YYYY-MM or YYYY-QN"
+// @Success      200  {object}  ReturnsResponse
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/analytics/returns [get]
+func (h *ReturnsHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("period")
+	if raw == "" {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, "period is required (YYYY-MM or YYYY-QN)")
+		return
+	}
+	p, err := period.Parse(raw)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+
+	ret, err := h.returns.Returns(r.Context(), "mtlf", p)
+	if err != nil {
+		if errors.Is(err, period.ErrNoData) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("failed to decompose returns", "period", p.String(), "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, ReturnsResponse{
+		Period:  p.String(),
+		Start:   p.Start.Format("2006-01-02"),
+		End:     p.End.Format("2006-01-02"),
+		Returns: *ret,
+	})
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/period"
+)
+
+type stubReturns struct {
+	ret *period.Returns
+	err error
+}
+
+func (s *stubReturns) Returns(_ context.Context, _ string, _ period.Period) (*period.Returns, error) {
+	return s.ret, s.err
+}
+
+func TestGetReturns(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithReturns(&stubReturns{ret: &period.Returns{Total: decimal.NewFromInt(15)}}))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/analytics/returns?period=2026-q3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got ReturnsResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Period != "2026-Q3" || got.Start != "2026-07-01" || got.End != "2026-09-30" || !got.Returns.Total.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("response = %+v", got)
+	}
+}
+
+func TestGetReturnsErrors(t *testing.T) {
+	noData := &stubReturns{err: fmt.Errorf("%w 2026-09", period.ErrNoData)}
+	for _, tt := range []struct {
+		target string
+		src    ReturnsSource
+		want   int
+	}{
+		{"/api/v1/analytics/returns", noData, http.StatusBadRequest},
+		{"/api/v1/analytics/returns?period=2026-13", noData, http.StatusBadRequest},
+		{"/api/v1/analytics/returns?period=2026-09", noData, http.StatusNotFound},
+		{"/api/v1/analytics/returns?period=2026-09", &stubReturns{err: fmt.Errorf("db down")}, http.StatusInternalServerError},
+	} {
+		w := httptest.NewRecorder()
+		NewServer("0", nil, nil, WithReturns(tt.src)).Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.target, w.Code, tt.want)
+		}
+	}
+}
@@ -7,62 +7,475 @@ import (
 	httpswagger "github.com/swaggo/http-swagger"
 
 	_ "github.com/mtlprog/stat/docs"
+	"github.com/mtlprog/stat/internal/export"
 	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/period"
+	"github.com/mtlprog/stat/internal/snapdate"
 	"github.com/mtlprog/stat/internal/snapshot"
 	"github.com/mtlprog/stat/internal/static"
 )
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// Option configures optional server features.
+type Option func(*serverOptions)
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
+type serverOptions struct {
+	jobs      JobQueue
+	limits    Limits
+	cors      CORS
+	calcs     CalculatorLister
+	corr      CorrelationSource
+	reports   ReportStore
+	returns   ReturnsSource
+	subfonds  SubfondROISource
+	balances  BalanceHistorySource
+	forecast  DividendForecaster
+	issuance  IssuanceSource
+	convs     ConversionSource
+	supplies  SupplyHistorySource
+	aggs      HistoryAggregateSource
+	acctMeta  AccountMetadataSource
+	exports   ExportBacklog
+	budget    RequestBudget
+	slo       SLOSource
+	details   SnapshotDetailSource
+	monitor   MonitoringSource
+	monLocale export.Locale
+	notes     AnnotationSource
+	defs      DefinitionSource
+	methods   IndicatorMethods
+	holders   HolderChurnSource
+	intraday  IntradaySource
+	whales    WhaleAlertSource
+	explorer  OperationLister
+	admin     Admin
+	keys      APIKeys
+	clock     snapdate.Clock
+	siteURL   string
+	compare   period.Comparison
+	adminAddr string
+}
 
-		next.ServeHTTP(w, r)
-	})
+// WithJobs mounts POST /api/v1/snapshots/generate and GET /api/v1/jobs/{id}.
+func WithJobs(jobs JobQueue) Option {
+	return func(o *serverOptions) {
+		o.jobs = jobs
+	}
+}
+
+// WithLimits enables rate, body-size and concurrency limits.
+func WithLimits(l Limits) Option {
+	return func(o *serverOptions) {
+		o.limits = l
+	}
+}
+
+// WithCORS overrides the default allow-any-origin, GET-only CORS policy.
+func WithCORS(c CORS) Option {
+	return func(o *serverOptions) {
+		o.cors = c
+	}
+}
+
+// WithCalculators mounts GET /api/v1/indicators/calculators.
+func WithCalculators(c CalculatorLister) Option {
+	return func(o *serverOptions) {
+		o.calcs = c
+	}
+}
+
+// WithCorrelations mounts GET /api/v1/analytics/correlations.
+func WithCorrelations(c CorrelationSource) Option {
+	return func(o *serverOptions) {
+		o.corr = c
+	}
+}
+
+// WithReports mounts GET /api/v1/reports/{period}.
+func WithReports(r ReportStore) Option {
+	return func(o *serverOptions) {
+		o.reports = r
+	}
+}
+
+// WithReturns mounts GET /api/v1/analytics/returns.
+func WithReturns(r ReturnsSource) Option {
+	return func(o *serverOptions) {
+		o.returns = r
+	}
+}
+
+// WithSubfonds mounts GET /api/v1/subfonds/{name}/roi.
+func WithSubfonds(s SubfondROISource) Option {
+	return func(o *serverOptions) {
+		o.subfonds = s
+	}
+}
+
+// WithBalances mounts GET /api/v1/accounts/{address}/balances/{asset}/history.
+func WithBalances(b BalanceHistorySource) Option {
+	return func(o *serverOptions) {
+		o.balances = b
+	}
+}
+
+// WithForecast mounts GET and POST /api/v1/forecast/dividends.
+func WithForecast(f DividendForecaster) Option {
+	return func(o *serverOptions) {
+		o.forecast = f
+	}
+}
+
+// WithHolders mounts GET /api/v1/holders/churn.
+func WithHolders(h HolderChurnSource) Option {
+	return func(o *serverOptions) {
+		o.holders = h
+	}
+}
+
+// WithWhaleAlerts mounts GET /api/v1/alerts/whales.
+func WithWhaleAlerts(w WhaleAlertSource) Option {
+	return func(o *serverOptions) {
+		o.whales = w
+	}
+}
+
+// WithExplorer mounts GET /api/v1/accounts/{address}/operations.
+func WithExplorer(e OperationLister) Option {
+	return func(o *serverOptions) {
+		o.explorer = e
+	}
+}
+
+// WithIssuance mounts GET /api/v1/issuance/events.
+func WithIssuance(i IssuanceSource) Option {
+	return func(o *serverOptions) {
+		o.issuance = i
+	}
+}
+
+// WithConversions mounts GET /api/v1/issuance/conversions.
+func WithConversions(c ConversionSource) Option {
+	return func(o *serverOptions) {
+		o.convs = c
+	}
+}
+
+// WithSupplyHistory mounts GET /api/v1/issuance/supply/{asset}.
+func WithSupplyHistory(s SupplyHistorySource) Option {
+	return func(o *serverOptions) {
+		o.supplies = s
+	}
+}
+
+// WithHistoryAggregates lets GET /api/v1/charts/indicator-history serve
+// weekly and monthly points for long ranges instead of every day.
+func WithHistoryAggregates(a HistoryAggregateSource) Option {
+	return func(o *serverOptions) {
+		o.aggs = a
+	}
+}
+
+// WithAccountMetadata mounts GET /api/v1/accounts/metadata/events.
+func WithAccountMetadata(s AccountMetadataSource) Option {
+	return func(o *serverOptions) {
+		o.acctMeta = s
+	}
+}
+
+// WithExportOutbox adds the snapshots whose Sheets export is still owed to
+// GET /api/v1/status.
+func WithExportOutbox(e ExportBacklog) Option {
+	return func(o *serverOptions) {
+		o.exports = e
+	}
+}
+
+// WithCoinGeckoBudget adds today's CoinGecko request budget to
+// GET /api/v1/status.
+func WithCoinGeckoBudget(b RequestBudget) Option {
+	return func(o *serverOptions) {
+		o.budget = b
+	}
+}
+
+// WithSLO adds the daily report pipeline's SLO attainment to
+// GET /api/v1/status.
+func WithSLO(s SLOSource) Option {
+	return func(o *serverOptions) {
+		o.slo = s
+	}
+}
+
+// WithSnapshotDetails makes GET /api/v1/snapshots/{date}/details read the
+// details that SNAPSHOT_DETACH_DETAILS moved out of snapshot documents.
+func WithSnapshotDetails(d SnapshotDetailSource) Option {
+	return func(o *serverOptions) {
+		o.details = d
+	}
+}
+
+// WithIntraday mounts GET /api/v1/intraday/{id}.
+func WithIntraday(s IntradaySource) Option {
+	return func(o *serverOptions) {
+		o.intraday = s
+	}
 }
 
-// NewServer creates an HTTP server with all routes configured.
+// WithMonitoring mounts GET /api/v1/monitoring. loc formats the dates of the
+// xlsx rendering, as in the MONITORING sheet.
+func WithMonitoring(m MonitoringSource, loc export.Locale) Option {
+	return func(o *serverOptions) {
+		o.monitor, o.monLocale = m, loc
+	}
+}
+
+// WithAnnotations mounts GET /api/v1/snapshots/annotations and adds
+// annotations to the API v2 indicator responses.
+func WithAnnotations(a AnnotationSource) Option {
+	return func(o *serverOptions) {
+		o.notes = a
+	}
+}
+
+// WithDefinitions mounts GET /api/v1/indicators/meta and lists the
+// definition changes within compared periods in the indicator responses.
+func WithDefinitions(d DefinitionSource) Option {
+	return func(o *serverOptions) {
+		o.defs = d
+	}
+}
+
+// WithIndicatorMethods reports the estimation method of configurable
+// indicators (I47) in GET /api/v1/indicators/meta.
+func WithIndicatorMethods(m IndicatorMethods) Option {
+	return func(o *serverOptions) {
+		o.methods = m
+	}
+}
+
+// WithComparison sets the default baseline of the indicator endpoints'
+// ?compare changes, which ?baseline overrides per request. Default:
+// period.Rolling.
+func WithComparison(c period.Comparison) Option {
+	return func(o *serverOptions) {
+		o.compare = c
+	}
+}
+
+// WithClock sets the snapshot date clock used for "today" and for
+// timestamps passed where a snapshot date is expected. Default: UTC midnight.
+func WithClock(c snapdate.Clock) Option {
+	return func(o *serverOptions) {
+		o.clock = c
+	}
+}
+
+// WithSiteURL sets the public site the Atom feed's entries link to, each
+// at siteURL/?date=YYYY-MM-DD.
+func WithSiteURL(url string) Option {
+	return func(o *serverOptions) {
+		o.siteURL = url
+	}
+}
+
+// WithAPIKeys enforces partner API keys on the public API.
+func WithAPIKeys(k APIKeys) Option {
+	return func(o *serverOptions) {
+		o.keys = k
+	}
+}
+
+// WithAdmin mounts GET /api/v1/admin/diagnostics, the configuration
+// endpoints of the stores set in a and, with Pprof, /debug/pprof/, all
+// requiring the admin token. A no-op without a token.
+func WithAdmin(a Admin) Option {
+	return func(o *serverOptions) {
+		o.admin = a
+	}
+}
+
+// WithAdminAddr moves the write and admin endpoints (snapshot generation and
+// jobs, /api/v1/admin/..., /debug/pprof/) off the public listener onto a
+// second one at addr (host:port), so the public API can sit behind a CDN
+// while they stay on the internal network. Only NewServers honors it.
+func WithAdminAddr(addr string) Option {
+	return func(o *serverOptions) {
+		o.adminAddr = addr
+	}
+}
+
+// NewServer creates an HTTP server with all routes configured on one
+// listener; WithAdminAddr is ignored.
+func NewServer(port string, snapshots *snapshot.Service, indicators indicator.Repository, opts ...Option) *http.Server {
+	srv, _ := NewServers(port, snapshots, indicators, append(opts, WithAdminAddr(""))...)
+	return srv
+}
+
+// NewServers creates the public HTTP server and, with WithAdminAddr, the
+// admin server holding the write and admin endpoints. admin is nil without
+// WithAdminAddr, in which case public serves everything like NewServer.
 //
 // @title           MTL Fund Statistics API
 // @version         1.0
 // @description     Read-only API exposing fund snapshots, computed indicators, and chart data.
 // @BasePath        /
-func NewServer(port string, snapshots *snapshot.Service, indicators indicator.Repository) *http.Server {
+func NewServers(port string, snapshots *snapshot.Service, indicators indicator.Repository, opts ...Option) (public, admin *http.Server) {
+	o := serverOptions{cors: defaultCORS}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	handler := NewHandler(snapshots)
+	handler.clock = o.clock
+	handler.exports = o.exports
+	handler.budget = o.budget
+	handler.slo = o.slo
+	handler.details = o.details
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /skill.md", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
 		w.Write(static.SkillMD)
 	})
-	mux.HandleFunc("GET /api/v1/snapshots/latest", handler.GetLatestSnapshot)
-	mux.HandleFunc("GET /api/v1/snapshots/{date}", handler.GetSnapshotByDate)
-	mux.HandleFunc("GET /api/v1/snapshots", handler.ListSnapshots)
+	// API routes get a per-route in-flight cap; static docs don't touch the DB.
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, concurrencyCap(o.limits.MaxConcurrent, h))
+	}
+	// Write and admin routes go to adminMux, which is mux itself unless they
+	// have a listener of their own.
+	adminMux := mux
+	if o.adminAddr != "" {
+		adminMux = http.NewServeMux()
+	}
+	handle("GET /api/v1/snapshots/latest", handler.GetLatestSnapshot)
+	handle("GET /api/v1/snapshots/{date}", handler.GetSnapshotByDate)
+	handle("GET /api/v1/snapshots/{date}/details", handler.GetSnapshotDetails)
+	handle("GET /api/v1/snapshots", handler.ListSnapshots)
+	handle("GET /api/v1/analytics/peers", handler.GetPeers)
+	handle("GET /api/v1/watchlist", handler.GetWatchlist)
+	handle("GET /api/v1/groups/{name}", handler.GetGroup)
+	handle("GET /api/v1/valuations/explain", handler.ExplainValuations)
+	handle("GET /api/v1/status", handler.GetStatus)
+	mux.HandleFunc("GET /api/v1/errors", ListErrorCodes)
+
+	if o.jobs != nil {
+		jobHandler := NewJobHandler(o.jobs, o.clock)
+		adminMux.HandleFunc("POST /api/v1/snapshots/generate", concurrencyCap(o.limits.MaxConcurrent, jobHandler.GenerateSnapshot))
+		adminMux.HandleFunc("GET /api/v1/jobs/{id}", concurrencyCap(o.limits.MaxConcurrent, jobHandler.GetJob))
+	}
 
-	// Legacy endpoints for dreadnought frontend compatibility.
-	mux.HandleFunc("GET /api/snapshots", handler.ListSnapshotsCompat)
-	mux.HandleFunc("GET /api/fund-structure", handler.GetFundStructureCompat)
+	mountCompat(handle, handler)
 
 	if indicators != nil {
 		indHandler := NewIndicatorHandler(indicators)
+		indHandler.annotations = o.notes
+		indHandler.definitions = o.defs
+		if o.compare != "" {
+			indHandler.compare = o.compare
+		}
 		chartsHandler := NewChartsHandler(snapshots, indicators)
-		mux.HandleFunc("GET /api/v1/indicators", indHandler.GetIndicators)
-		mux.HandleFunc("GET /api/v1/indicators/{date}", indHandler.GetIndicatorsByDate)
-		mux.HandleFunc("GET /api/v1/charts/balance-by-subfund", chartsHandler.GetBalanceBySubfund)
-		mux.HandleFunc("GET /api/v1/charts/indicator-history", chartsHandler.GetIndicatorHistory)
+		chartsHandler.aggregates = o.aggs
+		handle("GET /api/v1/indicators", indHandler.GetIndicators)
+		handle("GET /api/v1/indicators/{date}", indHandler.GetIndicatorsByDate)
+		handle("GET /api/v1/charts/balance-by-subfund", chartsHandler.GetBalanceBySubfund)
+		handle("GET /api/v1/charts/indicator-history", chartsHandler.GetIndicatorHistory)
+		handle("GET /feed.atom", NewFeedHandler(indicators, o.notes, o.clock, o.siteURL).GetFeed)
+	}
+	if o.corr != nil {
+		handle("GET /api/v1/analytics/correlations", NewAnalyticsHandler(o.corr, o.clock).GetCorrelations)
+	}
+	if o.reports != nil {
+		handle("GET /api/v1/reports/{period}", NewReportHandler(o.reports).GetReport)
+	}
+	if o.returns != nil {
+		handle("GET /api/v1/analytics/returns", NewReturnsHandler(o.returns).GetReturns)
+	}
+	if o.subfonds != nil {
+		handle("GET /api/v1/subfonds/{name}/roi", NewSubfondsHandler(o.subfonds, o.clock).GetSubfondROI)
+	}
+	if o.balances != nil {
+		handle("GET /api/v1/accounts/{address}/balances/{asset}/history", NewBalanceHandler(o.balances).GetBalanceHistory)
+	}
+	if o.explorer != nil {
+		handle("GET /api/v1/accounts/{address}/operations", NewOperationsHandler(o.explorer).GetOperations)
+	}
+	if o.forecast != nil {
+		fh := NewForecastHandler(o.forecast)
+		handle("GET /api/v1/forecast/dividends", fh.GetDividendForecast)
+		handle("POST /api/v1/forecast/dividends", fh.PostDividendForecast)
+	}
+	if o.issuance != nil {
+		handle("GET /api/v1/issuance/events", NewIssuanceHandler(o.issuance).GetIssuanceEvents)
+	}
+	if o.convs != nil {
+		handle("GET /api/v1/issuance/conversions", NewConversionsHandler(o.convs).GetConversions)
+	}
+	if o.supplies != nil {
+		handle("GET /api/v1/issuance/supply/{asset}", NewSupplyHandler(o.supplies).GetSupplyHistory)
+	}
+	if o.acctMeta != nil {
+		handle("GET /api/v1/accounts/metadata/events", NewAccountMetadataHandler(o.acctMeta).GetAccountMetadataEvents)
+	}
+	if o.monitor != nil {
+		handle("GET /api/v1/monitoring", NewMonitoringHandler(o.monitor, o.monLocale).GetMonitoring)
+	}
+	if o.holders != nil {
+		handle("GET /api/v1/holders/churn", NewHoldersHandler(o.holders, o.clock).GetHolderChurn)
+	}
+	if o.intraday != nil {
+		handle("GET /api/v1/intraday/{id}", NewIntradayHandler(o.intraday).GetIntraday)
+	}
+	if o.whales != nil {
+		handle("GET /api/v1/alerts/whales", NewWhaleHandler(o.whales).GetWhaleAlerts)
+	}
+	if o.notes != nil {
+		handle("GET /api/v1/snapshots/annotations", NewAnnotationsHandler(o.notes).GetAnnotations)
+	}
+	if o.defs != nil {
+		handle("GET /api/v1/indicators/meta", NewDefinitionsHandler(o.defs, o.methods).GetIndicatorMeta)
+	}
+	if o.calcs != nil {
+		handle("GET /api/v1/indicators/calculators", NewCalculatorHandler(o.calcs).ListCalculators)
+	}
+
+	var limiter *ipRateLimiter
+	if o.limits.RPS > 0 {
+		limiter = newIPRateLimiter(o.limits.RPS, o.limits.Burst)
+	}
+	if o.admin.Token != "" {
+		mountAdmin(adminMux, o.admin, limiter, o.limits.TrustProxy)
 	}
 
 	mux.Handle("GET /swagger/", httpswagger.Handler(httpswagger.URL("/swagger/doc.json")))
 
+	var h http.Handler = versionMiddleware(maxAPIVersion, mux)
+	if o.keys.Verifier != nil {
+		h = apiKeyMiddleware(o.keys, o.clock, h)
+	}
+	if o.limits.MaxBodyBytes > 0 {
+		h = maxBodyMiddleware(o.limits.MaxBodyBytes, h)
+	}
+	if limiter != nil {
+		h = rateLimitMiddleware(limiter, o.limits.TrustProxy, h)
+	}
+
+	public = newHTTPServer(":"+port, problemMiddleware(corsMiddleware(o.cors, h)))
+	if o.adminAddr == "" {
+		return public, nil
+	}
+
+	// The admin listener is internal: no CORS, API keys or rate limit.
+	var ah http.Handler = versionMiddleware(maxAPIVersion, adminMux)
+	if o.limits.MaxBodyBytes > 0 {
+		ah = maxBodyMiddleware(o.limits.MaxBodyBytes, ah)
+	}
+	return public, newHTTPServer(o.adminAddr, problemMiddleware(ah))
+}
+
+func newHTTPServer(addr string, h http.Handler) *http.Server {
 	return &http.Server{
-		Addr:         ":" + port,
-		Handler:      corsMiddleware(mux),
+		Addr:         addr,
+		Handler:      h,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 120 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// SnapshotDetailSource reads detached price details (snapshot.PgRepository).
+type SnapshotDetailSource interface {
+	GetDetails(ctx context.Context, entitySlug string, date time.Time, asset string) ([]snapshot.Detail, error)
+}
+
+// SnapshotDetailsResponse is the price discovery details of a snapshot.
+type SnapshotDetailsResponse struct {
+	Date    string            `json:"date"`
+	Details []snapshot.Detail `json:"details"`
+}
+
+// GetSnapshotDetails handles GET /api/v1/snapshots/{date}/details.
+//
+// @Summary      Price details of a snapshot
+// @Description  Returns how each token of a snapshot was priced (domain.PriceDetails: path hops, orderbook levels, AMM pools), per account and base (EURMTL or XLM). With SNAPSHOT_DETACH_DETAILS the snapshot document only keeps a stub (`source`, `detached: true`) and the details are read from their own table; older snapshots have them inline and are served the same way.
+// @Tags         snapshots
+// @Produce      json
+// @Param        date   path   string  true   "Snapshot date (YYYY-MM-DD)"
+// @Param        asset  query  string  false  "XLM or CODE-ISSUER; all tokens when empty"
+// @Success      200  {object}  SnapshotDetailsResponse
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Router       /api/v1/snapshots/{date}/details [get]
+func (h *Handler) GetSnapshotDetails(w http.ResponseWriter, r *http.Request) {
+	date, err := time.Parse("2006-01-02", r.PathValue("date"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidDate, "invalid date format, expected YYYY-MM-DD")
+		return
+	}
+	asset := r.URL.Query().Get("asset")
+	if asset != "" && !assetKeyPattern.MatchString(asset) {
+		writeError(w, http.StatusBadRequest, "invalid asset, expected XLM or CODE-ISSUER")
+		return
+	}
+
+	s, err := h.snapshots.GetByDate(r.Context(), "mtlf", date)
+	if err != nil {
+		if errors.Is(err, snapshot.ErrNotFound) {
+			writeProblem(w, http.StatusNotFound, CodeSnapshotNotFound, "snapshot not found for date")
+			return
+		}
+		slog.Error("failed to get snapshot for details", "date", date, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	// Inline details win: a date re-saved without detaching may still have
+	// rows from an earlier save.
+	details, err := snapshot.InlineDetails(s.Data)
+	if err != nil {
+		slog.Error("failed to decode snapshot details", "date", date, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	out := []snapshot.Detail{}
+	for _, d := range details {
+		if asset == "" || d.Asset == asset {
+			out = append(out, d)
+		}
+	}
+	if len(details) == 0 && h.details != nil {
+		if out, err = h.details.GetDetails(r.Context(), "mtlf", date, asset); err != nil {
+			slog.Error("failed to read detached snapshot details", "date", date, "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, SnapshotDetailsResponse{Date: date.Format("2006-01-02"), Details: out})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+type mockDetailSource struct {
+	details   []snapshot.Detail
+	lastAsset string
+}
+
+func (m *mockDetailSource) GetDetails(_ context.Context, _ string, _ time.Time, asset string) ([]snapshot.Detail, error) {
+	m.lastAsset = asset
+	return m.details, nil
+}
+
+const detailsDoc = `{"accounts":[{"id":"GMAIN","tokens":[{"asset":{"code":"MTL","issuer":"GACKTN5DAZGWXRWB2WLM6OPBDHAMT6SJNGLJZPQMEZBUR4JUGBX2UK7V","type":"credit_alphanum4"},"detailsEURMTL":{"source":"path","sourceAmount":"1.5"}}]}]}`
+
+func serveSnapshotDetails(t *testing.T, h *Handler, query string) (*httptest.ResponseRecorder, SnapshotDetailsResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/snapshots/2024-01-15/details"+query, nil)
+	req.SetPathValue("date", "2024-01-15")
+	w := httptest.NewRecorder()
+	h.GetSnapshotDetails(w, req)
+	var resp SnapshotDetailsResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestGetSnapshotDetailsInline(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{{SnapshotDate: date, Data: json.RawMessage(detailsDoc)}}}
+	h := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+	src := &mockDetailSource{}
+	h.details = src
+
+	w, resp := serveSnapshotDetails(t, h, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if resp.Date != "2024-01-15" || len(resp.Details) != 1 || resp.Details[0].Base != "EURMTL" || resp.Details[0].Account != "GMAIN" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	_, resp = serveSnapshotDetails(t, h, "?asset=XLM")
+	if len(resp.Details) != 0 {
+		t.Errorf("XLM details = %+v, want none", resp.Details)
+	}
+}
+
+func TestGetSnapshotDetailsDetached(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	lean, _, err := snapshot.DetachDetails(json.RawMessage(detailsDoc))
+	if err != nil {
+		t.Fatalf("detach: %v", err)
+	}
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{{SnapshotDate: date, Data: lean}}}
+	h := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+	src := &mockDetailSource{details: []snapshot.Detail{{Account: "GMAIN", Asset: "XLM", Base: "EURMTL", Details: json.RawMessage(`{"source":"path"}`)}}}
+	h.details = src
+
+	w, resp := serveSnapshotDetails(t, h, "?asset=XLM")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if src.lastAsset != "XLM" || len(resp.Details) != 1 {
+		t.Errorf("asset = %q, details = %+v", src.lastAsset, resp.Details)
+	}
+}
+
+func TestGetSnapshotDetailsErrors(t *testing.T) {
+	h := NewHandler(snapshot.NewService(&mockFundService{}, &mockSnapshotRepo{}))
+
+	if w, _ := serveSnapshotDetails(t, h, "?asset=bogus"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid asset status = %d, want 400", w.Code)
+	}
+	if w, _ := serveSnapshotDetails(t, h, ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing snapshot status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/outbox"
+	"github.com/mtlprog/stat/internal/quality"
+	"github.com/mtlprog/stat/internal/slo"
+)
+
+// StatusResponse reports how trustworthy a stored snapshot is.
+type StatusResponse struct {
+	SnapshotDate string              `json:"snapshotDate"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	Quality      *domain.DataQuality `json:"quality"`
+	Warnings     []string            `json:"warnings"`
+	// UnexportedSnapshots lists the snapshots whose Google Sheets export is
+	// still owed, oldest first. Absent when the server has no export outbox.
+	UnexportedSnapshots []outbox.Task `json:"unexportedSnapshots,omitempty"`
+	// CoinGeckoBudget is today's use of the CoinGecko request budget
+	// (COINGECKO_DAILY_BUDGET). Absent when the server doesn't track it.
+	CoinGeckoBudget *external.BudgetStatus `json:"coingeckoBudget,omitempty"`
+	// SLO is the share of daily report runs over the last 30 days that
+	// finished within SLO_DEADLINE, with the latest run's stage timings.
+	// Absent when the server doesn't track it.
+	SLO *slo.Attainment `json:"slo,omitempty"`
+}
+
+// ExportBacklog lists the pending Sheets exports (outbox.PgRepository).
+type ExportBacklog interface {
+	Pending(ctx context.Context, slug string) ([]outbox.Task, error)
+}
+
+// RequestBudget reports the use of a daily request budget (external.Budget).
+type RequestBudget interface {
+	Status(ctx context.Context) (external.BudgetStatus, error)
+}
+
+// SLOSource reports the pipeline's deadline attainment (slo.Service).
+type SLOSource interface {
+	Attainment(ctx context.Context) (slo.Attainment, error)
+}
+
+// GetStatus handles GET /api/v1/status.
+//
+// @Summary      Snapshot data quality
+// @Description  Returns the data-quality score of a stored snapshot: percentage of held tokens priced in EURMTL, external quotes older than a day, and live metrics that reused the prior day's value (by indicator ID in snapshot live_metrics.fallbacks) and how far Horizon's latest ledger lagged (degraded when beyond HORIZON_MAX_LAG), plus the pricing warnings. With the Google Sheets export configured, unexportedSnapshots lists the snapshots whose export is still owed, with their attempts and last error. coingeckoBudget reports today's CoinGecko requests (retries included, counted by every stat process) against COINGECKO_DAILY_BUDGET. slo reports the share of daily report runs over the last 30 days that succeeded within SLO_DEADLINE, the current streak of misses and the latest run's duration per stage (valuation, portfolio, pricing, metrics, persistence, export). Snapshots taken before scores were stored are assessed on the fly as of their creation time; metric fallbacks were not recorded for them and read 0.
+// @Tags         snapshots
+// @Produce      json
+// @Param        date  query  string  false  "Snapshot date (YYYY-MM-DD, default latest)"
+// @Success      200  {object}  StatusResponse
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/status [get]
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	s, data, ok := h.snapshotData(w, r, "status")
+	if !ok {
+		return
+	}
+	q := data.Quality
+	if q == nil {
+		assessed := quality.Assess(data, s.CreatedAt)
+		q = &assessed
+	}
+	warnings := data.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	resp := StatusResponse{
+		SnapshotDate: s.SnapshotDate.Format("2006-01-02"),
+		CreatedAt:    s.CreatedAt,
+		Quality:      q,
+		Warnings:     warnings,
+	}
+	if h.exports != nil {
+		// The backlog only annotates the status: a failed read leaves it out.
+		pending, err := h.exports.Pending(r.Context(), "mtlf")
+		if err != nil {
+			slog.Error("failed to list pending sheets exports", "error", err)
+		} else {
+			resp.UnexportedSnapshots = pending
+		}
+	}
+	if h.budget != nil {
+		if b, err := h.budget.Status(r.Context()); err != nil {
+			slog.Error("failed to read CoinGecko request budget", "error", err)
+		} else {
+			resp.CoinGeckoBudget = &b
+		}
+	}
+	if h.slo != nil {
+		if a, err := h.slo.Attainment(r.Context()); err != nil {
+			slog.Error("failed to read pipeline SLO attainment", "error", err)
+		} else {
+			resp.SLO = &a
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
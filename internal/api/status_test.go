@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/outbox"
+	"github.com/mtlprog/stat/internal/slo"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func TestGetStatus(t *testing.T) {
+	v := "1"
+	stored, _ := json.Marshal(domain.FundStructureData{
+		Warnings: []string{"failed to price DEAD on MAIN: no price"},
+		Quality:  &domain.DataQuality{Score: decimal.NewFromInt(90), TokenCount: 10, PricedCount: 9, MetricFallbacks: 2},
+	})
+	legacy, _ := json.Marshal(domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "MTL"}, ValueInEURMTL: &v},
+			{Asset: domain.AssetInfo{Code: "DEAD"}},
+		}}},
+	})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 2, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: stored},
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Data: legacy},
+	}}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+	for _, tc := range []struct {
+		url       string
+		wantDate  string
+		wantScore string
+		wantCount int
+	}{
+		{"/api/v1/status", "2026-10-02", "90", 10},
+		{"/api/v1/status?date=2026-10-01", "2026-10-01", "50", 2},
+	} {
+		w := httptest.NewRecorder()
+		handler.GetStatus(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tc.url, w.Code)
+		}
+		var got StatusResponse
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.SnapshotDate != tc.wantDate || got.Quality == nil || got.Quality.Score.String() != tc.wantScore || got.Quality.TokenCount != tc.wantCount {
+			t.Errorf("%s: got %+v (quality %+v), want %s with score %s over %d tokens", tc.url, got, got.Quality, tc.wantDate, tc.wantScore, tc.wantCount)
+		}
+		if got.Warnings == nil {
+			t.Errorf("%s: warnings = nil, want a JSON array", tc.url)
+		}
+	}
+}
+
+type stubExportBacklog []outbox.Task
+
+func (s stubExportBacklog) Pending(context.Context, string) ([]outbox.Task, error) { return s, nil }
+
+func TestGetStatusListsUnexportedSnapshots(t *testing.T) {
+	data, _ := json.Marshal(domain.FundStructureData{})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+	}}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+	handler.exports = stubExportBacklog{{ID: 7, SnapshotDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Attempts: 3, LastError: "quota exceeded"}}
+
+	w := httptest.NewRecorder()
+	handler.GetStatus(w, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	var got StatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.UnexportedSnapshots) != 1 || got.UnexportedSnapshots[0].Attempts != 3 || got.UnexportedSnapshots[0].LastError == "" {
+		t.Errorf("unexportedSnapshots = %+v, want the pending 2026-10-01 export", got.UnexportedSnapshots)
+	}
+}
+
+type stubBudget external.BudgetStatus
+
+func (s stubBudget) Status(context.Context) (external.BudgetStatus, error) {
+	return external.BudgetStatus(s), nil
+}
+
+func TestGetStatusReportsCoinGeckoBudget(t *testing.T) {
+	data, _ := json.Marshal(domain.FundStructureData{})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+	}}
+	srv := NewServer("0", snapshot.NewService(&mockFundService{}, repo), nil,
+		WithCoinGeckoBudget(stubBudget{Service: "coingecko", Date: "2026-10-02", Limit: 330, Used: 300, Remaining: 30}))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	var got StatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.CoinGeckoBudget == nil || got.CoinGeckoBudget.Remaining != 30 || got.CoinGeckoBudget.Limit != 330 {
+		t.Errorf("coingeckoBudget = %+v, want 30 of 330 remaining", got.CoinGeckoBudget)
+	}
+}
+
+type stubSLO slo.Attainment
+
+func (s stubSLO) Attainment(context.Context) (slo.Attainment, error) {
+	return slo.Attainment(s), nil
+}
+
+func TestGetStatusReportsSLO(t *testing.T) {
+	data, _ := json.Marshal(domain.FundStructureData{})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+	}}
+	srv := NewServer("0", snapshot.NewService(&mockFundService{}, repo), nil,
+		WithSLO(stubSLO{WindowDays: 30, Deadline: "30m0s", Runs: 4, Met: 3, Percent: decimal.NewFromInt(75),
+			LastRun: &slo.Record{DurationMs: 2400000, Stages: map[string]int64{slo.StagePricing: 1800000}}}))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	var got StatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.SLO == nil || got.SLO.Runs != 4 || !got.SLO.Percent.Equal(decimal.NewFromInt(75)) {
+		t.Fatalf("slo = %+v, want 3 of 4 runs met", got.SLO)
+	}
+	if got.SLO.LastRun == nil || got.SLO.LastRun.Stages[slo.StagePricing] != 1800000 {
+		t.Errorf("slo.lastRun = %+v, want pricing 1800000 ms", got.SLO.LastRun)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/stat/internal/period"
+	"github.com/mtlprog/stat/internal/snapdate"
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+// SubfondROISource computes per-subfond returns (period.Service).
+type SubfondROISource interface {
+	SubfondROI(ctx context.Context, slug string, sf subfond.Subfond, from, to time.Time) (*subfond.ROI, error)
+}
+
+// SubfondsHandler serves per-subfond returns.
+type SubfondsHandler struct {
+	source SubfondROISource
+	clock  snapdate.Clock
+}
+
+// NewSubfondsHandler creates a new subfond handler.
+func NewSubfondsHandler(source SubfondROISource, clock snapdate.Clock) *SubfondsHandler {
+	return &SubfondsHandler{source: source, clock: clock}
+}
+
+// GetSubfondROI handles GET /api/v1/subfonds/{name}/roi.
+//
+// @Summary      Subfond ROI
+// @Description  Return of one subfond (DEFI, MCITY, MABIZ or BOSS) by the Modified Dietz method: the change of its total value (I51, I52, I53, I59) from the end of `from` to the end of `to`, net of the transfers between it and the other fund accounts (issuer, subfonds, ADMIN), over the opening value plus each transfer weighted by the part of the period it was there for. Transfers with outside accounts are the subfond's own business and stay in the return. `period` is a window ending today (30d, 90d, 180d, 365d) or a month (YYYY-MM) or quarter (YYYY-QN), measured from the close of the previous one. `roi` is null when the average capital isn't positive. The same figures over 30 days are I83–I86.
+// @Tags         subfonds
+// @Produce      json
+// @Param        name    path   string  true   "Subfond: DEFI, MCITY, MABIZ or BOSS"
+// @Param        period  query  string  false  "Period: 30d (default), 90d, 180d, 365d, YYYY-MM or YYYY-QN"
+// @Success      200  {object}  subfond.ROI
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/subfonds/{name}/roi [get]
+func (h *SubfondsHandler) GetSubfondROI(w http.ResponseWriter, r *http.Request) {
+	sf, ok := subfond.Lookup(r.PathValue("name"))
+	if !ok {
+		names := make([]string, 0, 4)
+		for _, s := range subfond.All() {
+			names = append(names, s.Name)
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown subfond %q, valid: %s", r.PathValue("name"), strings.Join(names, ", ")))
+		return
+	}
+
+	to := h.clock.Today()
+	from := to.AddDate(0, 0, -subfond.DefaultPeriodDays)
+	if raw := r.URL.Query().Get("period"); raw != "" {
+		if days, ok := parsePeriodDays(raw); ok {
+			from = to.AddDate(0, 0, -days)
+		} else if p, err := period.Parse(raw); err == nil {
+			from, to = p.Start.AddDate(0, 0, -1), p.End
+		} else {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, fmt.Sprintf("invalid period %q, valid: 30d, 90d, 180d, 365d, YYYY-MM or YYYY-QN", raw))
+			return
+		}
+	}
+
+	roi, err := h.source.SubfondROI(r.Context(), "mtlf", sf, from, to)
+	if err != nil {
+		if errors.Is(err, period.ErrNoData) {
+			writeProblem(w, http.StatusNotFound, CodeIndicatorsNotFound, err.Error())
+			return
+		}
+		slog.Error("failed to compute subfond ROI", "subfond", sf.Name, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, roi)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/period"
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+type stubSubfondROI struct {
+	name     string
+	from, to time.Time
+	err      error
+}
+
+func (s *stubSubfondROI) SubfondROI(_ context.Context, _ string, sf subfond.Subfond, from, to time.Time) (*subfond.ROI, error) {
+	s.name, s.from, s.to = sf.Name, from, to
+	if s.err != nil {
+		return nil, s.err
+	}
+	r := subfond.Return(sf, decimal.NewFromInt(1000), decimal.NewFromInt(1100), nil, from, to)
+	return &r, nil
+}
+
+func TestGetSubfondROI(t *testing.T) {
+	stub := &stubSubfondROI{}
+	srv := NewServer("0", nil, nil, WithSubfonds(stub))
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	problem := func(w *httptest.ResponseRecorder) Problem {
+		t.Helper()
+		var p Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	w := get("/api/v1/subfonds/defi/roi?period=2026-06")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var roi subfond.ROI
+	if err := json.Unmarshal(w.Body.Bytes(), &roi); err != nil {
+		t.Fatal(err)
+	}
+	if stub.name != "DEFI" || roi.From != "2026-05-31" || roi.To != "2026-06-30" || roi.ROI == nil || roi.ROI.String() != "10" {
+		t.Errorf("ROI = %+v", roi)
+	}
+
+	if w := get("/api/v1/subfonds/MABIZ/roi"); w.Code != http.StatusOK || stub.to.Sub(stub.from) != 30*24*time.Hour {
+		t.Errorf("default period: status %d, %s..%s", w.Code, stub.from, stub.to)
+	}
+	if w := get("/api/v1/subfonds/MABIZ/roi?period=90d"); w.Code != http.StatusOK || stub.to.Sub(stub.from) != 90*24*time.Hour {
+		t.Errorf("90d: status %d, %s..%s", w.Code, stub.from, stub.to)
+	}
+
+	for path, want := range map[string]string{
+		"/api/v1/subfonds/ADMIN/roi":            CodeNotFound,
+		"/api/v1/subfonds/DEFI/roi?period=7d":   CodeInvalidPeriod,
+		"/api/v1/subfonds/DEFI/roi?period=2026": CodeInvalidPeriod,
+	} {
+		if p := problem(get(path)); p.Code != want {
+			t.Errorf("%s: code = %q, want %q", path, p.Code, want)
+		}
+	}
+
+	stub.err = fmt.Errorf("%w: no DEFI value", period.ErrNoData)
+	if p := problem(get("/api/v1/subfonds/DEFI/roi")); p.Status != http.StatusNotFound || p.Code != CodeIndicatorsNotFound {
+		t.Errorf("no data: %+v", p)
+	}
+}
+
+func TestGetSubfondROIRequiresKey(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithSubfonds(&stubSubfondROI{}),
+		WithAPIKeys(APIKeys{Verifier: newKeyStub(), Required: true}))
+	if w := keyedGet(srv, "/api/v1/subfonds/mabiz/roi", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+}
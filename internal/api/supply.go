@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/mtlprog/stat/internal/supply"
+)
+
+// SupplyHistorySource reads the stored supplies of fund-issued assets.
+type SupplyHistorySource interface {
+	History(ctx context.Context, slug, asset string, from, to time.Time) ([]supply.Point, error)
+}
+
+// SupplyHistoryResponse is one fund-issued asset's total supply over time.
+type SupplyHistoryResponse struct {
+	Asset  string         `json:"asset"`
+	Points []supply.Point `json:"points"`
+}
+
+// SupplyHandler serves the supply history of fund-issued assets.
+type SupplyHandler struct {
+	source SupplyHistorySource
+}
+
+// NewSupplyHandler creates a new supply history handler.
+func NewSupplyHandler(source SupplyHistorySource) *SupplyHandler {
+	return &SupplyHandler{source: source}
+}
+
+var issuedAssetPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,12}-G[A-Z2-7]{55}$`)
+
+// GetSupplyHistory handles GET /api/v1/issuance/supply/{asset}.
+//
+// @Summary      Fund-issued asset supply history
+// @Description  Total supply of one asset issued by a fund account on each snapshot date in the range, oldest first. `delta` is the change since the previous snapshot, absent when there was none to compare with. Changes of codes outside SUPPLY_EXPECTED_CHANGES are unexpected mints or burns: `expected` is false and `notified` tells whether the alert was delivered. Snapshots taken before supply tracking have no points.
+// @Tags         issuance
+// @Produce      json
+// @Param        asset  path   string  true   "CODE-ISSUER"
+// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {object}  SupplyHistoryResponse
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/issuance/supply/{asset} [get]
+func (h *SupplyHandler) GetSupplyHistory(w http.ResponseWriter, r *http.Request) {
+	asset := r.PathValue("asset")
+	if !issuedAssetPattern.MatchString(asset) {
+		writeError(w, http.StatusBadRequest, "invalid asset, expected CODE-ISSUER")
+		return
+	}
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+
+	points, err := h.source.History(r.Context(), fundSlug, asset, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to fetch supply history", "asset", asset, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if points == nil {
+		points = []supply.Point{}
+	}
+	writeJSON(w, http.StatusOK, SupplyHistoryResponse{Asset: asset, Points: points})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/supply"
+)
+
+type stubSupplies struct {
+	asset  string
+	points []supply.Point
+}
+
+func (s *stubSupplies) History(_ context.Context, _, asset string, _, _ time.Time) ([]supply.Point, error) {
+	s.asset = asset
+	return s.points, nil
+}
+
+func TestGetSupplyHistory(t *testing.T) {
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	delta := decimal.NewFromInt(50)
+	stub := &stubSupplies{points: []supply.Point{
+		{Date: day, Supply: decimal.NewFromInt(100)},
+		{Date: day.AddDate(0, 0, 1), Supply: decimal.NewFromInt(150), Delta: &delta, Notified: true},
+	}}
+	srv := NewServer("0", nil, nil, WithSupplyHistory(stub))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/issuance/supply/"+testAsset+"?range=30d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var got SupplyHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Asset != testAsset || len(got.Points) != 2 || got.Points[0].Delta != nil || !got.Points[1].Delta.Equal(delta) {
+		t.Errorf("response = %+v", got)
+	}
+	if stub.asset != testAsset {
+		t.Errorf("queried %s", stub.asset)
+	}
+}
+
+func TestGetSupplyHistoryBadRequest(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithSupplyHistory(&stubSupplies{}))
+	for _, target := range []string{
+		"/api/v1/issuance/supply/XLM",
+		"/api/v1/issuance/supply/EURMTL",
+		"/api/v1/issuance/supply/" + testAsset + "?range=7y",
+	} {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", target, w.Code)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+// ExplainValuations handles GET /api/v1/valuations/explain.
+//
+// @Summary      Valuation audit trail
+// @Description  Lists the manual valuations (Stellar DATA entries) that priced tokens in a stored snapshot, with the external quote each was resolved with: symbol, EUR price and the time the quote was fetched. Values stay explainable after quotes are refreshed. Snapshots taken before valuations were recorded return empty lists.
+// @Tags         valuations
+// @Produce      json
+// @Param        date   query  string  false  "Snapshot date (YYYY-MM-DD, default latest)"
+// @Param        token  query  string  false  "Only this asset code"
+// @Success      200  {object}  analytics.ValuationExplanation
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/valuations/explain [get]
+func (h *Handler) ExplainValuations(w http.ResponseWriter, r *http.Request) {
+	s, data, ok := h.snapshotData(w, r, "valuation explain")
+	if !ok {
+		return
+	}
+	token := r.URL.Query().Get("token")
+	writeJSON(w, http.StatusOK, analytics.ExplainValuations(s.SnapshotDate.Format("2006-01-02"), token, data))
+}
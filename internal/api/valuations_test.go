@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/analytics"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func TestExplainValuations(t *testing.T) {
+	fetched := time.Date(2026, 10, 2, 9, 30, 0, 0, time.UTC)
+	data, _ := json.Marshal(domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{Name: "MAIN", Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "WBTC"}, Balance: "1", Valuation: &domain.ResolvedAssetValuation{
+				AssetValuation: domain.AssetValuation{TokenCode: "WBTC", RawValue: domain.ValuationValue{Type: domain.ValuationValueExternal, Symbol: "BTC"}},
+				ValueInEURMTL:  "60000",
+				Quote:          &domain.QuoteUsage{Symbol: "BTC", PriceInEUR: decimal.NewFromInt(60000), FetchedAt: fetched},
+			}},
+		}}},
+		Quotes: []domain.QuoteUsage{{Symbol: "BTC", PriceInEUR: decimal.NewFromInt(60000), FetchedAt: fetched}},
+	})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+	}}
+	handler := NewHandler(snapshot.NewService(&mockFundService{}, repo))
+
+	w := httptest.NewRecorder()
+	handler.ExplainValuations(w, httptest.NewRequest(http.MethodGet, "/api/v1/valuations/explain?date=2026-10-02", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got analytics.ValuationExplanation
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Valuations) != 1 || got.Valuations[0].Quote == nil || !got.Valuations[0].Quote.FetchedAt.Equal(fetched) {
+		t.Errorf("explanation = %+v, want WBTC with the BTC quote fetched at %s", got, fetched)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ExplainValuations(w, httptest.NewRequest(http.MethodGet, "/api/v1/valuations/explain?date=2026-13-01", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid date: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// WatchlistResponse is the watched accounts captured in a snapshot.
+type WatchlistResponse struct {
+	Date     string                  `json:"date"`
+	Accounts []domain.WatchedAccount `json:"accounts"`
+}
+
+// GetWatchlist handles GET /api/v1/watchlist.
+//
+// @Summary      Watchlist balances
+// @Description  Returns the balances of the watched accounts (partners and counterparties outside the fund registry, managed with `stat watchlist`) as captured in a stored snapshot. Each tracked asset is valued in EURMTL at the snapshot's prices; watched balances are not part of the fund totals. An account that could not be loaded carries an error instead of balances.
+// @Tags         snapshots
+// @Produce      json
+// @Param        date  query  string  false  "Snapshot date (YYYY-MM-DD, default latest)"
+// @Success      200  {object}  WatchlistResponse
+// @Failure      400  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/watchlist [get]
+func (h *Handler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
+	s, data, ok := h.snapshotData(w, r, "watchlist")
+	if !ok {
+		return
+	}
+	accounts := data.Watchlist
+	if accounts == nil {
+		accounts = []domain.WatchedAccount{}
+	}
+	writeJSON(w, http.StatusOK, WatchlistResponse{Date: s.SnapshotDate.Format("2006-01-02"), Accounts: accounts})
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func TestGetWatchlist(t *testing.T) {
+	data, _ := json.Marshal(domain.FundStructureData{
+		Watchlist: []domain.WatchedAccount{{Label: "Partner", Address: "GA", TotalEURMTL: decimal.NewFromInt(40)}},
+	})
+	repo := &mockSnapshotRepo{snapshots: []snapshot.Snapshot{
+		{ID: 2, SnapshotDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Data: data},
+		{ID: 1, SnapshotDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Data: []byte(`{}`)},
+	}}
+	srv := NewServer("0", snapshot.NewService(&mockFundService{}, repo), nil)
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/watchlist", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got WatchlistResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Date != "2026-10-02" || len(got.Accounts) != 1 || got.Accounts[0].Label != "Partner" {
+		t.Errorf("latest watchlist = %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/watchlist?date=2026-10-01", nil))
+	got = WatchlistResponse{}
+	json.NewDecoder(w.Body).Decode(&got)
+	if w.Code != http.StatusOK || got.Accounts == nil || len(got.Accounts) != 0 {
+		t.Errorf("snapshot without watchlist: status %d, %+v; want empty accounts", w.Code, got)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/watchlist?date=2026-09-01", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing snapshot: status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetWatchlistRequiresKey(t *testing.T) {
+	srv := NewServer("0", snapshot.NewService(&mockFundService{}, &mockSnapshotRepo{}), nil,
+		WithAPIKeys(APIKeys{Verifier: newKeyStub(), Required: true}))
+	if w := keyedGet(srv, "/api/v1/watchlist", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/stat/internal/whale"
+)
+
+// WhaleAlertSource reads the stored whale alert log.
+type WhaleAlertSource interface {
+	List(ctx context.Context, slug string, from, to time.Time) ([]whale.Alert, error)
+}
+
+// WhaleHandler serves whale alerts.
+type WhaleHandler struct {
+	source WhaleAlertSource
+}
+
+// NewWhaleHandler creates a new whale alert handler.
+func NewWhaleHandler(source WhaleAlertSource) *WhaleHandler {
+	return &WhaleHandler{source: source}
+}
+
+// GetWhaleAlerts handles GET /api/v1/alerts/whales.
+//
+// @Summary      Whale alerts
+// @Description  Transfers into (`in`) or out of (`out`) a fund account worth at least the configured EURMTL threshold at the latest snapshot's prices, oldest first. Transfers between fund accounts and unpriced assets never alert. `notified` is false when the notification could not be sent.
+// @Tags         alerts
+// @Produce      json
+// @Param        range  query  string  false  "Range: 30d, 90d, 180d, 365d, or 'all' (default: 90d)"
+// @Success      200  {array}   whale.Alert
+// @Failure      400  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/alerts/whales [get]
+func (h *WhaleHandler) GetWhaleAlerts(w http.ResponseWriter, r *http.Request) {
+	from, err := parseHistoryRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidPeriod, err.Error())
+		return
+	}
+	alerts, err := h.source.List(r.Context(), fundSlug, from, time.Time{})
+	if err != nil {
+		slog.Error("failed to list whale alerts", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if alerts == nil {
+		alerts = []whale.Alert{}
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
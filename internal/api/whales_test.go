@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/whale"
+)
+
+type stubWhales struct {
+	alerts []whale.Alert
+	from   time.Time
+	err    error
+}
+
+func (s *stubWhales) List(_ context.Context, _ string, from, _ time.Time) ([]whale.Alert, error) {
+	s.from = from
+	return s.alerts, s.err
+}
+
+func TestGetWhaleAlerts(t *testing.T) {
+	src := &stubWhales{alerts: []whale.Alert{{
+		Account: "GDEFI", Direction: whale.In, Counterparty: "GWHALE", Asset: "XLM",
+		Amount: decimal.NewFromInt(50000), ValueEURMTL: decimal.NewFromInt(12500), Notified: true,
+	}}}
+	srv := NewServer("0", nil, nil, WithWhaleAlerts(src))
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts/whales?range=30d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var got []whale.Alert
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Counterparty != "GWHALE" || got[0].Direction != whale.In {
+		t.Errorf("alerts = %+v", got)
+	}
+	if days := time.Since(src.from).Hours() / 24; days < 29 || days > 31 {
+		t.Errorf("from = %s, want about 30 days ago", src.from)
+	}
+}
+
+func TestGetWhaleAlertsEmptyAndErrors(t *testing.T) {
+	srv := NewServer("0", nil, nil, WithWhaleAlerts(&stubWhales{}))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts/whales", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q, want 200 []", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts/whales?range=7d", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad range: status = %d, want 400", w.Code)
+	}
+
+	srv = NewServer("0", nil, nil, WithWhaleAlerts(&stubWhales{err: errors.New("db down")}))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts/whales", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("source error: status = %d, want 500", w.Code)
+	}
+}
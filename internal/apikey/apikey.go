@@ -0,0 +1,125 @@
+// Package apikey issues and checks partner API keys. A key reads the data of
+// one entity through a fixed set of route groups; everything else answers
+// 403. Tokens are random, shown once at issuance, and stored as SHA-256
+// hashes, so a leaked database doesn't leak working keys.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TokenPrefix starts every token, so keys are recognizable in logs and
+// secret scanners.
+const TokenPrefix = "stat_"
+
+// Groups are the route groups a key can be scoped to: the path segment after
+// /api/v1/, plus "compat" for the legacy /api/snapshots and /api/fund-structure.
+var Groups = []string{
+	"snapshots", "indicators", "charts", "analytics", "reports", "accounts",
+	"forecast", "issuance", "holders", "alerts", "valuations", "status", "jobs",
+	"watchlist", "groups", "subfonds", "intraday", "monitoring", "compat",
+}
+
+// ErrNotFound is returned for unknown and revoked keys.
+var ErrNotFound = errors.New("api key not found")
+
+// ErrInvalid is returned for an issuance request the store can't accept.
+var ErrInvalid = errors.New("invalid api key request")
+
+// Key is an issued key without its token.
+type Key struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Entity     string     `json:"entity"` // fund_entities slug
+	Groups     []string   `json:"groups"`
+	Prefix     string     `json:"prefix"` // first characters of the token, for identification
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Requests   int64      `json:"requests"` // all-time total
+}
+
+// Allows reports whether the key may read entity's data through group.
+func (k Key) Allows(entity, group string) bool {
+	return k.RevokedAt == nil && k.Entity == entity && slices.Contains(k.Groups, group)
+}
+
+// Request describes a key to issue.
+type Request struct {
+	Name   string   `json:"name"`
+	Entity string   `json:"entity"`
+	Groups []string `json:"groups"`
+}
+
+// Validate checks the name and that every group is one of Groups.
+func (r Request) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if len(r.Name) > 100 {
+		return fmt.Errorf("%w: name is longer than 100 characters", ErrInvalid)
+	}
+	if r.Entity == "" {
+		return fmt.Errorf("%w: entity is required", ErrInvalid)
+	}
+	if len(r.Groups) == 0 {
+		return fmt.Errorf("%w: at least one route group is required", ErrInvalid)
+	}
+	for _, g := range r.Groups {
+		if !slices.Contains(Groups, g) {
+			return fmt.Errorf("%w: unknown route group %q (valid: %s)", ErrInvalid, g, strings.Join(Groups, ", "))
+		}
+	}
+	return nil
+}
+
+// Issued is a newly issued key together with its token, which is never
+// available again.
+type Issued struct {
+	Key
+	Token string `json:"token"`
+}
+
+// Usage is the request count of one key in one route group on one day.
+type Usage struct {
+	Date     time.Time `json:"date"`
+	Group    string    `json:"group"`
+	Requests int64     `json:"requests"`
+}
+
+// NewToken returns a random token with its hash and display prefix.
+func NewToken() (token string, hash []byte, prefix string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, "", fmt.Errorf("generating api key: %w", err)
+	}
+	token = TokenPrefix + hex.EncodeToString(b)
+	return token, Hash(token), token[:len(TokenPrefix)+6], nil
+}
+
+// Hash returns the stored form of token.
+func Hash(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
+
+// RouteGroup maps a request path to its route group; ok is false for paths
+// outside the keyed API (admin, docs, static files, and the Atom feed, which
+// is public so feed readers can poll it).
+func RouteGroup(path string) (group string, ok bool) {
+	if rest, found := strings.CutPrefix(path, "/api/v1/"); found {
+		group, _, _ = strings.Cut(rest, "/")
+		return group, slices.Contains(Groups, group)
+	}
+	if path == "/api/snapshots" || path == "/api/fund-structure" {
+		return "compat", true
+	}
+	return "", false
+}
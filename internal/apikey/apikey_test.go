@@ -0,0 +1,96 @@
+package apikey
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestValidate(t *testing.T) {
+	valid := Request{Name: "MTLA dashboard", Entity: "mtla", Groups: []string{"snapshots", "indicators"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+	tests := map[string]Request{
+		"no name":       {Entity: "mtla", Groups: []string{"snapshots"}},
+		"blank name":    {Name: "  ", Entity: "mtla", Groups: []string{"snapshots"}},
+		"long name":     {Name: strings.Repeat("x", 101), Entity: "mtla", Groups: []string{"snapshots"}},
+		"no entity":     {Name: "x", Groups: []string{"snapshots"}},
+		"no groups":     {Name: "x", Entity: "mtla"},
+		"unknown group": {Name: "x", Entity: "mtla", Groups: []string{"admin"}},
+	}
+	for name, req := range tests {
+		if err := req.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestKeyAllows(t *testing.T) {
+	k := Key{Entity: "mtla", Groups: []string{"snapshots", "indicators"}}
+	if !k.Allows("mtla", "snapshots") {
+		t.Error("in-scope request refused")
+	}
+	if k.Allows("mtlf", "snapshots") {
+		t.Error("other entity allowed")
+	}
+	if k.Allows("mtla", "reports") {
+		t.Error("out-of-scope group allowed")
+	}
+	revoked := time.Now()
+	k.RevokedAt = &revoked
+	if k.Allows("mtla", "snapshots") {
+		t.Error("revoked key allowed")
+	}
+}
+
+func TestRouteGroup(t *testing.T) {
+	tests := []struct {
+		path  string
+		group string
+		ok    bool
+	}{
+		{"/api/v1/snapshots/2026-01-15", "snapshots", true},
+		{"/api/v1/indicators", "indicators", true},
+		{"/api/v1/accounts/GABC/balances/XLM/history", "accounts", true},
+		{"/api/v1/watchlist", "watchlist", true},
+		{"/api/v1/groups/real-estate", "groups", true},
+		{"/api/v1/subfonds/mabiz/roi", "subfonds", true},
+		{"/api/v1/intraday/10", "intraday", true},
+		{"/api/v1/monitoring", "monitoring", true},
+		{"/api/snapshots", "compat", true},
+		{"/api/fund-structure", "compat", true},
+		{"/api/v1/admin/diagnostics", "admin", false},
+		{"/swagger/index.html", "", false},
+		{"/skill.md", "", false},
+		{"/feed.atom", "", false},
+	}
+	for _, tt := range tests {
+		group, ok := RouteGroup(tt.path)
+		if group != tt.group || ok != tt.ok {
+			t.Errorf("RouteGroup(%q) = %q, %v, want %q, %v", tt.path, group, ok, tt.group, tt.ok)
+		}
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, prefix, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, TokenPrefix) || len(token) != len(TokenPrefix)+48 {
+		t.Errorf("token = %q", token)
+	}
+	if !strings.HasPrefix(token, prefix) || len(prefix) != len(TokenPrefix)+6 {
+		t.Errorf("prefix = %q for token %q", prefix, token)
+	}
+	if !bytes.Equal(hash, Hash(token)) {
+		t.Error("hash doesn't match Hash(token)")
+	}
+	other, _, _, _ := NewToken()
+	if other == token {
+		t.Error("two tokens are equal")
+	}
+}
@@ -0,0 +1,171 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores keys in api_keys and their usage in api_key_usage.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL API key repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const keyColumns = `k.id, k.name, fe.slug, k.route_groups, k.token_prefix, k.created_at, k.last_used_at, k.revoked_at,
+	COALESCE((SELECT SUM(u.requests) FROM api_key_usage u WHERE u.key_id = k.id), 0)`
+
+func scanKey(row pgx.Row) (Key, error) {
+	var k Key
+	err := row.Scan(&k.ID, &k.Name, &k.Entity, &k.Groups, &k.Prefix, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt, &k.Requests)
+	return k, err
+}
+
+// Issue validates req and stores a new key. The returned token is not stored.
+func (r *PgRepository) Issue(ctx context.Context, req Request) (*Issued, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	token, hash, prefix, err := NewToken()
+	if err != nil {
+		return nil, err
+	}
+	k, err := scanKey(r.pool.QueryRow(ctx,
+		`WITH k AS (
+		     INSERT INTO api_keys (name, entity_id, route_groups, token_hash, token_prefix)
+		     SELECT $1, id, $3, $4, $5 FROM fund_entities WHERE slug = $2
+		     RETURNING *
+		 )
+		 SELECT `+keyColumns+` FROM k JOIN fund_entities fe ON fe.id = k.entity_id`,
+		req.Name, req.Entity, req.Groups, hash, prefix))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: entity %q not found", ErrInvalid, req.Entity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("issuing api key: %w", err)
+	}
+	return &Issued{Key: k, Token: token}, nil
+}
+
+// List returns all keys, revoked ones included, newest first.
+func (r *PgRepository) List(ctx context.Context) ([]Key, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+keyColumns+`
+		 FROM api_keys k
+		 JOIN fund_entities fe ON fe.id = k.entity_id
+		 ORDER BY k.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Key
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning api key: %w", err)
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// Revoke marks the key revoked. Revoking twice keeps the first time.
+func (r *PgRepository) Revoke(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("revoking api key %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Lookup returns the active key with token, or ErrNotFound.
+func (r *PgRepository) Lookup(ctx context.Context, token string) (*Key, error) {
+	k, err := scanKey(r.pool.QueryRow(ctx,
+		`SELECT `+keyColumns+`
+		 FROM api_keys k
+		 JOIN fund_entities fe ON fe.id = k.entity_id
+		 WHERE k.token_hash = $1 AND k.revoked_at IS NULL`, Hash(token)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up api key: %w", err)
+	}
+	return &k, nil
+}
+
+// RecordUsage counts one request of the key in group on date.
+func (r *PgRepository) RecordUsage(ctx context.Context, id int64, date time.Time, group string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning api key usage tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO api_key_usage (key_id, usage_date, route_group, requests)
+		 VALUES ($1, $2, $3, 1)
+		 ON CONFLICT (key_id, usage_date, route_group) DO UPDATE
+		 SET requests = api_key_usage.requests + 1`, id, date, group); err != nil {
+		return fmt.Errorf("recording api key usage: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("updating api key last use: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing api key usage: %w", err)
+	}
+	return nil
+}
+
+// Usage returns the daily per-group request counts of the key since from
+// (zero = all), oldest first.
+func (r *PgRepository) Usage(ctx context.Context, id int64, from time.Time) ([]Usage, error) {
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM api_keys WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("checking api key %d: %w", id, err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT usage_date, route_group, requests
+		 FROM api_key_usage
+		 WHERE key_id = $1 AND ($2::date IS NULL OR usage_date >= $2)
+		 ORDER BY usage_date, route_group`, id, nullDate(from))
+	if err != nil {
+		return nil, fmt.Errorf("loading api key usage: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Usage{}
+	for rows.Next() {
+		var u Usage
+		if err := rows.Scan(&u.Date, &u.Group, &u.Requests); err != nil {
+			return nil, fmt.Errorf("scanning api key usage: %w", err)
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// nullDate maps the zero time to SQL NULL (no bound).
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,25 @@
+// Package asof carries a point in time on the context for historical snapshot
+// regeneration. Services that can answer "as of" a past time (portfolio,
+// price, external quotes) check it; everything else keeps reading live state.
+//
+// Like progress, the time travels on the context so the fund pipeline and its
+// service interfaces need no extra parameter.
+package asof
+
+import (
+	"context"
+	"time"
+)
+
+type ctxKey struct{}
+
+// With returns a context asking for state as of t.
+func With(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t.UTC())
+}
+
+// From returns the as-of time carried by ctx, if any.
+func From(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(ctxKey{}).(time.Time)
+	return t, ok
+}
@@ -0,0 +1,123 @@
+// Package association snapshots the treasury of the Montelibero Association
+// (MTLA) as a second built-in entity, so the fund's MONITORING row can carry
+// the association's capitalization (I28) and endowment fund (I29). Its
+// accounts are priced through the same fund.Service pipeline as the fund's,
+// at market prices only, and stored under their own slug on their own
+// schedule (`stat association-report`).
+package association
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Entity of the association snapshots in fund_entities.
+const (
+	Slug        = "mtla"
+	Name        = "Montelibero Association"
+	Description = "Montelibero Association treasury"
+)
+
+// registry holds the association accounts known without configuration.
+var registry = []domain.FundAccount{
+	{Name: "MTLA ISSUER", Type: domain.AccountTypeIssuer, Address: domain.MTLAPAddress, Description: "MTLAP issuer and association treasury"},
+}
+
+// Registry returns the built-in association accounts followed by extra,
+// usually from ParseAccounts. An extra account already built in is skipped.
+func Registry(extra ...domain.FundAccount) []domain.FundAccount {
+	out := make([]domain.FundAccount, len(registry), len(registry)+len(extra))
+	copy(out, registry)
+	for _, a := range extra {
+		if !contains(out, a.Address) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func contains(accounts []domain.FundAccount, address string) bool {
+	for _, a := range accounts {
+		if a.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAccounts parses ASSOCIATION_ACCOUNTS or ASSOCIATION_ENDOWMENT_ACCOUNTS
+// entries of the form NAME=ADDRESS into accounts of type typ.
+func ParseAccounts(entries []string, typ domain.AccountType) ([]domain.FundAccount, error) {
+	var out []domain.FundAccount
+	for _, e := range entries {
+		name, addr, ok := strings.Cut(e, "=")
+		name, addr = strings.TrimSpace(name), strings.TrimSpace(addr)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid association account %q: expected NAME=ADDRESS", e)
+		}
+		if len(addr) != 56 || addr[0] != 'G' {
+			return nil, fmt.Errorf("invalid association account %q: expected a G... Stellar address", e)
+		}
+		if contains(out, addr) {
+			return nil, fmt.Errorf("duplicate association account %s", addr)
+		}
+		out = append(out, domain.FundAccount{Name: name, Type: typ, Address: addr})
+	}
+	return out, nil
+}
+
+// PortfolioSource prices a list of accounts (fund.Service).
+type PortfolioSource interface {
+	Portfolios(ctx context.Context, accounts []domain.FundAccount, allValuations []domain.AssetValuation) ([]domain.FundAccountPortfolio, []string, error)
+}
+
+// Service builds the association snapshot data. It implements
+// snapshot.FundStructureService, so snapshot.Service stores it like the
+// fund's.
+type Service struct {
+	portfolios PortfolioSource
+	accounts   []domain.FundAccount
+}
+
+// NewService creates a Service for accounts, usually Registry.
+func NewService(portfolios PortfolioSource, accounts []domain.FundAccount) *Service {
+	return &Service{portfolios: portfolios, accounts: accounts}
+}
+
+// GetFundStructure prices every association account without the fund's
+// manual valuations. All of them go into Accounts and count towards
+// AggregatedTotals, the association's capitalization.
+func (s *Service) GetFundStructure(ctx context.Context) (domain.FundStructureData, error) {
+	portfolios, warnings, err := s.portfolios.Portfolios(ctx, s.accounts, nil)
+	if err != nil {
+		return domain.FundStructureData{}, err
+	}
+	totals := domain.AggregatedTotals{AccountCount: len(portfolios)}
+	for _, p := range portfolios {
+		totals.TotalEURMTL = totals.TotalEURMTL.Add(p.TotalEURMTL)
+		totals.TotalXLM = totals.TotalXLM.Add(p.TotalXLM)
+		totals.TokenCount += len(p.Tokens)
+	}
+	return domain.FundStructureData{
+		Accounts:         portfolios,
+		AggregatedTotals: totals,
+		Warnings:         warnings,
+	}, nil
+}
+
+// Endowment returns the EURMTL value of the endowment accounts in an
+// association snapshot, and false when it has none.
+func Endowment(data domain.FundStructureData) (decimal.Decimal, bool) {
+	total, found := decimal.Zero, false
+	for _, a := range data.Accounts {
+		if a.Type == domain.AccountTypeEndowment {
+			total, found = total.Add(a.TotalEURMTL), true
+		}
+	}
+	return total, found
+}
@@ -0,0 +1,63 @@
+package association
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+const endowmentAddr = "GBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBENDW"
+
+func TestParseAccounts(t *testing.T) {
+	got, err := ParseAccounts([]string{"ENDOWMENT = " + endowmentAddr}, domain.AccountTypeEndowment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "ENDOWMENT" || got[0].Address != endowmentAddr || got[0].Type != domain.AccountTypeEndowment {
+		t.Errorf("got %+v", got)
+	}
+	for _, bad := range [][]string{
+		{endowmentAddr},
+		{"X=GABC"},
+		{"A=" + endowmentAddr, "B=" + endowmentAddr},
+	} {
+		if _, err := ParseAccounts(bad, domain.AccountTypeEndowment); err == nil {
+			t.Errorf("ParseAccounts(%q) succeeded", bad)
+		}
+	}
+
+	reg := Registry(append(got, domain.FundAccount{Name: "DUP", Address: domain.MTLAPAddress})...)
+	if len(reg) != 2 || reg[0].Address != domain.MTLAPAddress || reg[1].Name != "ENDOWMENT" {
+		t.Errorf("Registry = %+v", reg)
+	}
+}
+
+type stubPortfolios struct {
+	valuations []domain.AssetValuation
+}
+
+func (s *stubPortfolios) Portfolios(_ context.Context, accounts []domain.FundAccount, vals []domain.AssetValuation) ([]domain.FundAccountPortfolio, []string, error) {
+	s.valuations = vals
+	out := make([]domain.FundAccountPortfolio, len(accounts))
+	for i, a := range accounts {
+		out[i] = domain.FundAccountPortfolio{Name: a.Name, Type: a.Type, TotalEURMTL: decimal.NewFromInt(int64(100 * (i + 1)))}
+	}
+	return out, []string{"no price for FOO"}, nil
+}
+
+func TestServiceGetFundStructure(t *testing.T) {
+	endowment, _ := ParseAccounts([]string{"ENDOWMENT=" + endowmentAddr}, domain.AccountTypeEndowment)
+	data, err := NewService(&stubPortfolios{}, Registry(endowment...)).GetFundStructure(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Accounts) != 2 || data.AggregatedTotals.TotalEURMTL.IntPart() != 300 || data.AggregatedTotals.AccountCount != 2 || len(data.Warnings) != 1 {
+		t.Errorf("data = %+v", data)
+	}
+	if v, ok := Endowment(data); !ok || v.IntPart() != 200 {
+		t.Errorf("Endowment = %s, %v; want 200", v, ok)
+	}
+}
@@ -0,0 +1,55 @@
+// Package chain is the seam between the services and the Stellar network.
+// Every service declares the narrow slice of chain access it needs as its
+// own interface; Source is the union of those slices for the data a
+// snapshot is built from — accounts, balances, orderbooks, pools and
+// payments — so an alternative backend (Stellar RPC, or Hubble on BigQuery
+// for heavy historical scans) can be plugged in by implementing it.
+//
+// horizon.Client is the default and, for now, the only network
+// implementation. Fake is an in-memory one for tests.
+package chain
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// Accounts reads account state: balances, trustlines and DATA entries.
+type Accounts interface {
+	FetchAccount(ctx context.Context, accountID string) (horizon.HorizonAccount, error)
+	FetchAccountBalance(ctx context.Context, accountID string, asset domain.AssetInfo) (decimal.Decimal, error)
+}
+
+// Orderbooks reads the DEX orderbook of a pair.
+type Orderbooks interface {
+	FetchOrderbook(ctx context.Context, selling, buying domain.AssetInfo, limit int) (horizon.HorizonOrderbook, error)
+}
+
+// Pools reads the liquidity pools holding both reserve assets.
+type Pools interface {
+	FetchLiquidityPools(ctx context.Context, reserveA, reserveB domain.AssetInfo) ([]horizon.HorizonLiquidityPool, error)
+}
+
+// Payments streams the value transfers touching an account. An empty cursor
+// returns no transfers and the cursor of the latest one; "0" starts from the
+// account's first.
+type Payments interface {
+	FetchAccountTransfers(ctx context.Context, account, cursor string) ([]horizon.AccountTransfer, string, error)
+}
+
+// Source is a complete chain backend.
+type Source interface {
+	Accounts
+	Orderbooks
+	Pools
+	Payments
+}
+
+var (
+	_ Source = (*horizon.Client)(nil)
+	_ Source = (*Fake)(nil)
+)
@@ -0,0 +1,160 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/fault"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// Fake is an in-memory Source. Fill it with the Put and Add methods; reads
+// of an unknown account fail the way Horizon's 404 does, while a pair
+// without an orderbook or pools reads as empty. It is safe for concurrent use.
+type Fake struct {
+	mu         sync.Mutex
+	accounts   map[string]horizon.HorizonAccount
+	orderbooks map[string]horizon.HorizonOrderbook
+	pools      []horizon.HorizonLiquidityPool
+	transfers  map[string][]horizon.AccountTransfer
+}
+
+// NewFake creates an empty Fake.
+func NewFake() *Fake {
+	return &Fake{
+		accounts:   make(map[string]horizon.HorizonAccount),
+		orderbooks: make(map[string]horizon.HorizonOrderbook),
+		transfers:  make(map[string][]horizon.AccountTransfer),
+	}
+}
+
+// PutAccount stores acc under its ID, replacing any previous state.
+func (f *Fake) PutAccount(acc horizon.HorizonAccount) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accounts[acc.ID] = acc
+	return f
+}
+
+// PutOrderbook stores the orderbook of selling against buying.
+func (f *Fake) PutOrderbook(selling, buying domain.AssetInfo, ob horizon.HorizonOrderbook) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.orderbooks[pairKey(selling, buying)] = ob
+	return f
+}
+
+// AddPool adds a liquidity pool.
+func (f *Fake) AddPool(pool horizon.HorizonLiquidityPool) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pools = append(f.pools, pool)
+	return f
+}
+
+// AddTransfers appends transfers to account's payments, oldest first. Their
+// IDs are the cursors FetchAccountTransfers pages by.
+func (f *Fake) AddTransfers(account string, transfers ...horizon.AccountTransfer) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.transfers[account] = append(f.transfers[account], transfers...)
+	return f
+}
+
+// FetchAccount returns the stored account.
+func (f *Fake) FetchAccount(_ context.Context, accountID string) (horizon.HorizonAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	acc, ok := f.accounts[accountID]
+	if !ok {
+		return horizon.HorizonAccount{}, fault.New("fake", fault.NotFound, fmt.Errorf("fetching account %s: not found", accountID))
+	}
+	return acc, nil
+}
+
+// FetchAccountBalance returns the stored balance of asset, zero when the
+// account doesn't hold it.
+func (f *Fake) FetchAccountBalance(ctx context.Context, accountID string, asset domain.AssetInfo) (decimal.Decimal, error) {
+	acc, err := f.FetchAccount(ctx, accountID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	for _, b := range acc.Balances {
+		if b.AssetCode == asset.Code && b.AssetIssuer == asset.Issuer {
+			amt, err := decimal.NewFromString(b.Balance)
+			if err != nil {
+				return decimal.Zero, fmt.Errorf("parsing balance for %s: %w", asset.Code, err)
+			}
+			return amt, nil
+		}
+	}
+	return decimal.Zero, nil
+}
+
+// FetchOrderbook returns the stored orderbook truncated to limit levels a side.
+func (f *Fake) FetchOrderbook(_ context.Context, selling, buying domain.AssetInfo, limit int) (horizon.HorizonOrderbook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ob := f.orderbooks[pairKey(selling, buying)]
+	return horizon.HorizonOrderbook{Bids: firstN(ob.Bids, limit), Asks: firstN(ob.Asks, limit)}, nil
+}
+
+// FetchLiquidityPools returns the pools with both assets among their reserves.
+func (f *Fake) FetchLiquidityPools(_ context.Context, reserveA, reserveB domain.AssetInfo) ([]horizon.HorizonLiquidityPool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []horizon.HorizonLiquidityPool
+	for _, p := range f.pools {
+		has := func(a domain.AssetInfo) bool {
+			return slices.ContainsFunc(p.Reserves, func(r horizon.HorizonLiquidityPoolReserve) bool { return r.Asset == a.Canonical() })
+		}
+		if has(reserveA) && has(reserveB) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// FetchAccountTransfers pages the stored transfers as Horizon does: an empty
+// cursor returns none and the latest ID ("0" without any), any other cursor
+// returns the transfers after the one with that ID.
+func (f *Fake) FetchAccountTransfers(_ context.Context, account, cursor string) ([]horizon.AccountTransfer, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all := f.transfers[account]
+	if cursor == "" {
+		if len(all) == 0 {
+			return nil, "0", nil
+		}
+		return nil, all[len(all)-1].ID, nil
+	}
+	start := 0
+	if cursor != "0" {
+		i := slices.IndexFunc(all, func(t horizon.AccountTransfer) bool { return t.ID == cursor })
+		if i < 0 {
+			return nil, "", fmt.Errorf("fetching payments of %s: unknown cursor %q", account, cursor)
+		}
+		start = i + 1
+	}
+	out := slices.Clone(all[start:])
+	if len(out) > 0 {
+		cursor = out[len(out)-1].ID
+	}
+	return out, cursor, nil
+}
+
+func pairKey(selling, buying domain.AssetInfo) string {
+	return selling.Canonical() + "/" + buying.Canonical()
+}
+
+func firstN[T any](s []T, n int) []T {
+	if n > 0 && len(s) > n {
+		return s[:n]
+	}
+	return s
+}
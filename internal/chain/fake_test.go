@@ -0,0 +1,83 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+const account = "GDNHQWZRZDZZBARNOH6VFFXMN6LBUNZTZHOKBUT7GREOWBTZI4FGS7IQ"
+
+func TestFakeAccounts(t *testing.T) {
+	ctx := context.Background()
+	mtl := domain.NewAssetInfo("MTL", domain.IssuerAddress)
+	f := NewFake().PutAccount(horizon.HorizonAccount{ID: account, Balances: []horizon.HorizonBalance{
+		{AssetType: "credit_alphanum4", AssetCode: "MTL", AssetIssuer: domain.IssuerAddress, Balance: "12.5"},
+	}})
+
+	if got, err := f.FetchAccountBalance(ctx, account, mtl); err != nil || !got.Equal(decimal.RequireFromString("12.5")) {
+		t.Errorf("MTL balance = %v, %v", got, err)
+	}
+	if got, err := f.FetchAccountBalance(ctx, account, domain.EURMTLAsset()); err != nil || !got.IsZero() {
+		t.Errorf("untrusted asset = %v, %v, want zero", got, err)
+	}
+	if _, err := f.FetchAccount(ctx, "GMISSING"); err == nil {
+		t.Error("unknown account: want an error")
+	}
+}
+
+func TestFakeMarket(t *testing.T) {
+	ctx := context.Background()
+	mtl, eurmtl := domain.NewAssetInfo("MTL", domain.IssuerAddress), domain.EURMTLAsset()
+	f := NewFake().
+		PutOrderbook(mtl, eurmtl, horizon.HorizonOrderbook{
+			Bids: []horizon.HorizonOrderbookEntry{{Price: "4", Amount: "1"}, {Price: "3.9", Amount: "2"}},
+		}).
+		AddPool(horizon.HorizonLiquidityPool{ID: "p1", Reserves: []horizon.HorizonLiquidityPoolReserve{
+			{Asset: mtl.Canonical(), Amount: "10"}, {Asset: eurmtl.Canonical(), Amount: "40"},
+		}})
+
+	if ob, _ := f.FetchOrderbook(ctx, mtl, eurmtl, 1); len(ob.Bids) != 1 || ob.Bids[0].Price != "4" {
+		t.Errorf("orderbook = %+v, want the best bid only", ob)
+	}
+	if ob, _ := f.FetchOrderbook(ctx, eurmtl, mtl, 10); len(ob.Bids)+len(ob.Asks) != 0 {
+		t.Errorf("reverse pair = %+v, want empty", ob)
+	}
+	if pools, _ := f.FetchLiquidityPools(ctx, eurmtl, mtl); len(pools) != 1 || pools[0].ID != "p1" {
+		t.Errorf("pools = %+v", pools)
+	}
+	if pools, _ := f.FetchLiquidityPools(ctx, mtl, domain.XLMAsset()); len(pools) != 0 {
+		t.Errorf("MTL/XLM pools = %+v, want none", pools)
+	}
+}
+
+func TestFakeTransfers(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	if got, cursor, _ := f.FetchAccountTransfers(ctx, account, ""); got != nil || cursor != "0" {
+		t.Errorf("no payments = %v, %q, want the start cursor", got, cursor)
+	}
+
+	f.AddTransfers(account, horizon.AccountTransfer{ID: "10"}, horizon.AccountTransfer{ID: "11"})
+	if got, cursor, _ := f.FetchAccountTransfers(ctx, account, ""); got != nil || cursor != "11" {
+		t.Errorf("latest = %v, %q, want no replay and cursor 11", got, cursor)
+	}
+	if got, cursor, _ := f.FetchAccountTransfers(ctx, account, "0"); len(got) != 2 || cursor != "11" {
+		t.Errorf("from the start = %v, %q", got, cursor)
+	}
+
+	f.AddTransfers(account, horizon.AccountTransfer{ID: "12"})
+	if got, cursor, _ := f.FetchAccountTransfers(ctx, account, "11"); len(got) != 1 || got[0].ID != "12" || cursor != "12" {
+		t.Errorf("after 11 = %v, %q", got, cursor)
+	}
+	if got, cursor, _ := f.FetchAccountTransfers(ctx, account, "12"); len(got) != 0 || cursor != "12" {
+		t.Errorf("caught up = %v, %q, want the cursor kept", got, cursor)
+	}
+	if _, _, err := f.FetchAccountTransfers(ctx, account, "99"); err == nil {
+		t.Error("unknown cursor: want an error")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,11 +16,35 @@ type Config struct {
 	StellarExpertURL          string
 	HorizonRetryMax           int
 	HorizonRetryBaseDelay     time.Duration
+	HorizonRPS                float64
+	HorizonFallbackURLs       []string
+	HorizonFailoverCooldown   time.Duration
+	HorizonMaxLag             time.Duration
+	HorizonLagPolicy          string
+	PriceCacheWarmup          bool
+	PriceCacheWarmupMaxAge    time.Duration
+	OtherAccountsPricing      string
 	CoinGeckoDelay            time.Duration
 	CoinGeckoRetryMax         int
+	CoinGeckoDailyBudget      int
+	HolderRefreshInterval     time.Duration
+	HolderCacheMaxAge         time.Duration
+	IntradayInterval          time.Duration
+	IntradayRetention         time.Duration
+	QuoteFreshFor             time.Duration
 	HTTPPort                  string
 	GoogleSheetsSpreadsheetID string
+	SheetsLocale              string
+	SheetsDateFormat          string
+	SheetsCurrencyFormat      string
 	GoogleCredentialsJSON     string
+	// Per entity slug overrides: GOOGLE_SHEETS_SPREADSHEET_ID_<SLUG> and
+	// GOOGLE_CREDENTIALS_JSON_<SLUG>, keyed by the lowercased suffix.
+	GoogleSheetsByEntity      map[string]string
+	GoogleCredentialsByEntity map[string]string
+	HubbleProject             string
+	HubbleDataset             string
+	HubbleCredentialsJSON     string
 	GristAPIURL               string
 	GristAPIKey               string
 	GristDocID                string
@@ -27,6 +52,87 @@ type Config struct {
 	GristChatID               int64
 	GristTopicID              int64
 	NotifyMentions            string
+	APIGenerateEnabled        bool
+	JobTimeout                time.Duration
+	JobShutdownGrace          time.Duration
+	APIRateLimitRPS           float64
+	APIRateLimitBurst         int
+	APIMaxBodyBytes           int64
+	APIMaxConcurrent          int
+	APITrustProxy             bool
+	APICORSOrigins            []string
+	APICORSMethods            []string
+	AdminToken                string
+	AdminAddr                 string
+	PprofEnabled              bool
+	APIKeysRequired           bool
+	SnapshotDeltaDays         int
+	SnapshotDetachDetails     bool
+	SnapshotSigningSeed       string
+	SnapshotTimezone          string
+	SnapshotCutoff            string
+	ComparisonMode            string
+	DisabledCalculators       []string
+	IndicatorConcurrency      int
+	IndicatorTimeout          time.Duration
+	IndicatorTimeouts         []string
+	IndicatorBreakerThreshold int
+	IndicatorBreakerCooldown  time.Duration
+	VaRMethod                 string
+	VaRConfidence             string
+	VaRHorizon                int
+	CorrelationAssets         []string
+	ExportCorrelations        bool
+	PeerAccounts              []string
+	ExportPeers               bool
+	AccountGroups             []string
+	ExportGroups              bool
+	LiabilityTokens           []string
+	PropertyAppraisalMaxAge   time.Duration
+	SupplyExpectedChanges     []string
+	AccountMetadataKeys       []string
+	AssociationAccounts       []string
+	AssociationEndowment      []string
+	AssociationMaxAge         time.Duration
+	ExportProvenance          bool
+	ExportAnnotations         bool
+	ExportHistory             bool
+	ExportMeta                bool
+	ExportOutboxInterval      time.Duration
+	ExportRetryBaseDelay      time.Duration
+	ExportRetryMaxDelay       time.Duration
+	TokenInclude              []string
+	TokenExclude              []string
+	PublishTarget             string
+	PublishIndexDays          int
+	PublishDir                string
+	PublishS3Endpoint         string
+	PublishS3Bucket           string
+	PublishS3Region           string
+	PublishS3Prefix           string
+	PublishS3AccessKey        string
+	PublishS3SecretKey        string
+	PublishIPFSAPI            string
+	PublishIPFSPath           string
+	PublishIPNSKey            string
+	ReportPeriods             []string
+	ReportNotify              bool
+	WhaleAlertMinEURMTL       float64
+	SLODeadline               time.Duration
+	SLOAlertAfter             int
+	ExplorerCacheTTL          time.Duration
+	JSONDecimalFormat         string
+	PriceMaxMove              float64
+	PriceBridgeAssets         []string
+	PriceConcurrency          int
+	HTTPDialTimeout           time.Duration
+	HTTPTLSHandshakeTimeout   time.Duration
+	HTTPResponseHeaderTimeout time.Duration
+	HTTPIdleConnTimeout       time.Duration
+	HTTPKeepAlive             time.Duration
+	HTTPMaxIdleConnsPerHost   int
+	HTTPMaxConnsPerHost       int
+	HTTPProxyURL              string
 }
 
 // Load reads configuration from environment variables with sensible defaults.
@@ -38,11 +144,33 @@ func Load() Config {
 		StellarExpertURL:          envOrDefault("STELLAR_EXPERT_URL", "https://api.stellar.expert"),
 		HorizonRetryMax:           envOrDefaultInt("HORIZON_RETRY_MAX", 5),
 		HorizonRetryBaseDelay:     envOrDefaultDuration("HORIZON_RETRY_BASE_DELAY", 2*time.Second),
+		HorizonRPS:                envOrDefaultFloat("HORIZON_RPS", 10),
+		HorizonFallbackURLs:       envOrDefaultList("HORIZON_FALLBACK_URLS", nil),
+		HorizonFailoverCooldown:   envOrDefaultDuration("HORIZON_FAILOVER_COOLDOWN", 5*time.Minute),
+		HorizonMaxLag:             envOrDefaultDuration("HORIZON_MAX_LAG", 2*time.Minute),
+		HorizonLagPolicy:          envOrDefault("HORIZON_LAG_POLICY", "degrade"),
+		PriceCacheWarmup:          envOrDefaultBool("PRICE_CACHE_WARMUP", false),
+		PriceCacheWarmupMaxAge:    envOrDefaultDuration("PRICE_CACHE_WARMUP_MAX_AGE", time.Hour),
+		OtherAccountsPricing:      envOrDefault("OTHER_ACCOUNTS_PRICING", "daily"),
 		CoinGeckoDelay:            envOrDefaultDuration("COINGECKO_DELAY", 6*time.Second),
 		CoinGeckoRetryMax:         envOrDefaultInt("COINGECKO_RETRY_MAX", 5),
+		CoinGeckoDailyBudget:      envOrDefaultInt("COINGECKO_DAILY_BUDGET", 330),
+		HolderRefreshInterval:     envOrDefaultDuration("HOLDER_REFRESH_INTERVAL", 0),
+		HolderCacheMaxAge:         envOrDefaultDuration("HOLDER_CACHE_MAX_AGE", 12*time.Hour),
+		IntradayInterval:          envOrDefaultDuration("INTRADAY_INTERVAL", 0),
+		IntradayRetention:         envOrDefaultDuration("INTRADAY_RETENTION", 30*24*time.Hour),
+		QuoteFreshFor:             envOrDefaultDuration("QUOTE_FRESH_FOR", 10*time.Minute),
 		HTTPPort:                  envOrDefault("HTTP_PORT", "8080"),
 		GoogleSheetsSpreadsheetID: os.Getenv("GOOGLE_SHEETS_SPREADSHEET_ID"),
+		SheetsLocale:              os.Getenv("SHEETS_LOCALE"),
+		SheetsDateFormat:          os.Getenv("SHEETS_DATE_FORMAT"),
+		SheetsCurrencyFormat:      os.Getenv("SHEETS_CURRENCY_FORMAT"),
 		GoogleCredentialsJSON:     os.Getenv("GOOGLE_CREDENTIALS_JSON"),
+		GoogleSheetsByEntity:      envBySuffix("GOOGLE_SHEETS_SPREADSHEET_ID_"),
+		GoogleCredentialsByEntity: envBySuffix("GOOGLE_CREDENTIALS_JSON_"),
+		HubbleProject:             os.Getenv("HUBBLE_PROJECT"),
+		HubbleDataset:             envOrDefault("HUBBLE_DATASET", "crypto-stellar.crypto_stellar"),
+		HubbleCredentialsJSON:     envOrDefault("HUBBLE_CREDENTIALS_JSON", os.Getenv("GOOGLE_CREDENTIALS_JSON")),
 		GristAPIURL:               envOrDefault("GRIST_API_URL", "https://montelibero.getgrist.com"),
 		GristAPIKey:               os.Getenv("GRIST_KEY"),
 		GristDocID:                envOrDefault("GRIST_DOC_ID", "oNYTdHkEstf9X7dkh7yH11"),
@@ -50,6 +178,87 @@ func Load() Config {
 		GristChatID:               envOrDefaultInt64("GRIST_CHAT_ID", -1002871416798),
 		GristTopicID:              envOrDefaultInt64("GRIST_TOPIC_ID", 0),
 		NotifyMentions:            envOrDefault("NOTIFY_MENTIONS", "@xdefrag"),
+		APIGenerateEnabled:        envOrDefaultBool("API_GENERATE_ENABLED", false),
+		JobTimeout:                envOrDefaultDuration("JOB_TIMEOUT", 30*time.Minute),
+		JobShutdownGrace:          envOrDefaultDuration("JOB_SHUTDOWN_GRACE", 20*time.Second),
+		APIRateLimitRPS:           envOrDefaultFloat("API_RATE_LIMIT_RPS", 10),
+		APIRateLimitBurst:         envOrDefaultInt("API_RATE_LIMIT_BURST", 20),
+		APIMaxBodyBytes:           envOrDefaultInt64("API_MAX_BODY_BYTES", 1<<20),
+		APIMaxConcurrent:          envOrDefaultInt("API_MAX_CONCURRENT", 16),
+		APITrustProxy:             envOrDefaultBool("API_TRUST_PROXY", false),
+		APICORSOrigins:            envOrDefaultList("API_CORS_ORIGINS", []string{"*"}),
+		APICORSMethods:            envOrDefaultList("API_CORS_METHODS", []string{"GET", "OPTIONS"}),
+		AdminToken:                os.Getenv("ADMIN_TOKEN"),
+		AdminAddr:                 os.Getenv("ADMIN_ADDR"),
+		PprofEnabled:              envOrDefaultBool("PPROF_ENABLED", false),
+		APIKeysRequired:           envOrDefaultBool("API_KEYS_REQUIRED", false),
+		SnapshotDeltaDays:         envOrDefaultInt("SNAPSHOT_DELTA_DAYS", 0),
+		SnapshotDetachDetails:     envOrDefaultBool("SNAPSHOT_DETACH_DETAILS", false),
+		SnapshotSigningSeed:       envOrDefault("SNAPSHOT_SIGNING_SEED", ""),
+		SnapshotTimezone:          envOrDefault("SNAPSHOT_TIMEZONE", "UTC"),
+		SnapshotCutoff:            envOrDefault("SNAPSHOT_CUTOFF", "00:00"),
+		ComparisonMode:            envOrDefault("COMPARISON_MODE", "rolling"),
+		DisabledCalculators:       envOrDefaultList("INDICATOR_DISABLED_CALCULATORS", nil),
+		IndicatorConcurrency:      envOrDefaultInt("INDICATOR_CONCURRENCY", 4),
+		IndicatorTimeout:          envOrDefaultDuration("INDICATOR_TIMEOUT", 5*time.Minute),
+		IndicatorTimeouts:         envOrDefaultList("INDICATOR_TIMEOUTS", nil),
+		IndicatorBreakerThreshold: envOrDefaultInt("INDICATOR_BREAKER_THRESHOLD", 3),
+		IndicatorBreakerCooldown:  envOrDefaultDuration("INDICATOR_BREAKER_COOLDOWN", 72*time.Hour),
+		VaRMethod:                 envOrDefault("VAR_METHOD", "historical"),
+		VaRConfidence:             envOrDefault("VAR_CONFIDENCE", "0.95"),
+		VaRHorizon:                envOrDefaultInt("VAR_HORIZON", 1),
+		CorrelationAssets:         envOrDefaultList("CORRELATION_ASSETS", nil),
+		ExportCorrelations:        envOrDefaultBool("EXPORT_CORRELATIONS", false),
+		PeerAccounts:              envOrDefaultList("PEER_ACCOUNTS", nil),
+		ExportPeers:               envOrDefaultBool("EXPORT_PEERS", false),
+		AccountGroups:             envOrDefaultList("ACCOUNT_GROUPS", nil),
+		ExportGroups:              envOrDefaultBool("EXPORT_GROUPS", false),
+		LiabilityTokens:           envOrDefaultList("LIABILITY_TOKENS", nil),
+		PropertyAppraisalMaxAge:   envOrDefaultDuration("PROPERTY_APPRAISAL_MAX_AGE", 365*24*time.Hour),
+		SupplyExpectedChanges:     envOrDefaultList("SUPPLY_EXPECTED_CHANGES", []string{"EURMTL", "MTL", "MTLRECT"}),
+		AccountMetadataKeys:       envOrDefaultList("ACCOUNT_METADATA_KEYS", nil),
+		AssociationAccounts:       envOrDefaultList("ASSOCIATION_ACCOUNTS", nil),
+		AssociationEndowment:      envOrDefaultList("ASSOCIATION_ENDOWMENT_ACCOUNTS", nil),
+		AssociationMaxAge:         envOrDefaultDuration("ASSOCIATION_SNAPSHOT_MAX_AGE", 192*time.Hour),
+		ExportProvenance:          envOrDefaultBool("EXPORT_PROVENANCE", false),
+		ExportAnnotations:         envOrDefaultBool("EXPORT_ANNOTATIONS", true),
+		ExportHistory:             envOrDefaultBool("EXPORT_HISTORY", false),
+		ExportMeta:                envOrDefaultBool("EXPORT_META", false),
+		ExportOutboxInterval:      envOrDefaultDuration("EXPORT_OUTBOX_INTERVAL", 5*time.Minute),
+		ExportRetryBaseDelay:      envOrDefaultDuration("EXPORT_RETRY_BASE_DELAY", time.Minute),
+		ExportRetryMaxDelay:       envOrDefaultDuration("EXPORT_RETRY_MAX_DELAY", 6*time.Hour),
+		TokenInclude:              envOrDefaultList("TOKEN_INCLUDE", nil),
+		TokenExclude:              envOrDefaultList("TOKEN_EXCLUDE", nil),
+		PublishTarget:             envOrDefault("PUBLISH_TARGET", ""),
+		PublishIndexDays:          envOrDefaultInt("PUBLISH_INDEX_DAYS", 365),
+		PublishDir:                envOrDefault("PUBLISH_DIR", ""),
+		PublishS3Endpoint:         envOrDefault("PUBLISH_S3_ENDPOINT", ""),
+		PublishS3Bucket:           envOrDefault("PUBLISH_S3_BUCKET", ""),
+		PublishS3Region:           envOrDefault("PUBLISH_S3_REGION", "us-east-1"),
+		PublishS3Prefix:           envOrDefault("PUBLISH_S3_PREFIX", ""),
+		PublishS3AccessKey:        os.Getenv("PUBLISH_S3_ACCESS_KEY"),
+		PublishS3SecretKey:        os.Getenv("PUBLISH_S3_SECRET_KEY"),
+		PublishIPFSAPI:            envOrDefault("PUBLISH_IPFS_API", "http://127.0.0.1:5001"),
+		PublishIPFSPath:           envOrDefault("PUBLISH_IPFS_PATH", "/stat"),
+		PublishIPNSKey:            envOrDefault("PUBLISH_IPNS_KEY", ""),
+		ReportPeriods:             envOrDefaultList("REPORT_PERIODS", []string{"month", "quarter"}),
+		ReportNotify:              envOrDefaultBool("REPORT_NOTIFY", false),
+		WhaleAlertMinEURMTL:       envOrDefaultFloat("WHALE_ALERT_MIN_EURMTL", 10000),
+		SLODeadline:               envOrDefaultDuration("SLO_DEADLINE", 30*time.Minute),
+		SLOAlertAfter:             envOrDefaultInt("SLO_ALERT_AFTER", 3),
+		ExplorerCacheTTL:          envOrDefaultDuration("EXPLORER_CACHE_TTL", time.Minute),
+		JSONDecimalFormat:         envOrDefault("JSON_DECIMAL_FORMAT", "string"),
+		PriceMaxMove:              envOrDefaultFloat("PRICE_MAX_MOVE", 10),
+		PriceBridgeAssets:         envOrDefaultList("PRICE_BRIDGE_ASSETS", nil),
+		PriceConcurrency:          envOrDefaultInt("PRICE_CONCURRENCY", 4),
+		HTTPDialTimeout:           envOrDefaultDuration("HTTP_DIAL_TIMEOUT", 10*time.Second),
+		HTTPTLSHandshakeTimeout:   envOrDefaultDuration("HTTP_TLS_HANDSHAKE_TIMEOUT", 10*time.Second),
+		HTTPResponseHeaderTimeout: envOrDefaultDuration("HTTP_RESPONSE_HEADER_TIMEOUT", 0),
+		HTTPIdleConnTimeout:       envOrDefaultDuration("HTTP_IDLE_CONN_TIMEOUT", 90*time.Second),
+		HTTPKeepAlive:             envOrDefaultDuration("HTTP_KEEP_ALIVE", 30*time.Second),
+		HTTPMaxIdleConnsPerHost:   envOrDefaultInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 10),
+		HTTPMaxConnsPerHost:       envOrDefaultInt("HTTP_MAX_CONNS_PER_HOST", 16),
+		HTTPProxyURL:              envOrDefault("HTTP_PROXY_URL", ""),
 	}
 }
 
@@ -60,6 +269,37 @@ func envOrDefault(key, defaultVal string) string {
 	return defaultVal
 }
 
+// envOrDefaultList splits a comma-separated env var, trimming blanks.
+func envOrDefaultList(key string, defaultVal []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal
+	}
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	if len(out) == 0 {
+		return defaultVal
+	}
+	return out
+}
+
+// envBySuffix collects the non-empty env vars named prefix + SUFFIX, keyed
+// by the lowercased suffix.
+func envBySuffix(prefix string) map[string]string {
+	out := map[string]string{}
+	for _, kv := range os.Environ() {
+		key, v, _ := strings.Cut(kv, "=")
+		if suffix, ok := strings.CutPrefix(key, prefix); ok && suffix != "" && v != "" {
+			out[strings.ToLower(suffix)] = v
+		}
+	}
+	return out
+}
+
 func envOrDefaultWarn(key, defaultVal string) string {
 	v := envOrDefault(key, defaultVal)
 	if v == "" {
@@ -92,6 +332,30 @@ func envOrDefaultInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func envOrDefaultFloat(key string, defaultVal float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			slog.Info("invalid float env var, using default", "key", key, "value", v, "default", defaultVal)
+			return defaultVal
+		}
+		return f
+	}
+	return defaultVal
+}
+
+func envOrDefaultBool(key string, defaultVal bool) bool {
+	if v := os.Getenv(key); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Info("invalid boolean env var, using default", "key", key, "value", v, "default", defaultVal)
+			return defaultVal
+		}
+		return b
+	}
+	return defaultVal
+}
+
 func envOrDefaultDuration(key string, defaultVal time.Duration) time.Duration {
 	if v := os.Getenv(key); v != "" {
 		d, err := time.ParseDuration(v)
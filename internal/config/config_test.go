@@ -77,3 +77,88 @@ func TestLoadInvalidEnvFallsBackToDefault(t *testing.T) {
 		t.Errorf("HorizonRetryBaseDelay = %v, want default 2s on invalid input", cfg.HorizonRetryBaseDelay)
 	}
 }
+
+func TestLoadAPIGenerateEnabled(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"true", true},
+		{"1", true},
+		{"false", false},
+		{"not-a-bool", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("API_GENERATE_ENABLED", tt.value)
+			if got := Load().APIGenerateEnabled; got != tt.want {
+				t.Errorf("APIGenerateEnabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAPILimits(t *testing.T) {
+	t.Setenv("API_RATE_LIMIT_RPS", "2.5")
+	t.Setenv("API_RATE_LIMIT_BURST", "")
+	t.Setenv("API_MAX_BODY_BYTES", "4096")
+	t.Setenv("API_MAX_CONCURRENT", "")
+
+	cfg := Load()
+
+	if cfg.APIRateLimitRPS != 2.5 {
+		t.Errorf("APIRateLimitRPS = %v, want 2.5", cfg.APIRateLimitRPS)
+	}
+	if cfg.APIRateLimitBurst != 20 {
+		t.Errorf("APIRateLimitBurst = %d, want default 20", cfg.APIRateLimitBurst)
+	}
+	if cfg.APIMaxBodyBytes != 4096 {
+		t.Errorf("APIMaxBodyBytes = %d, want 4096", cfg.APIMaxBodyBytes)
+	}
+	if cfg.APIMaxConcurrent != 16 {
+		t.Errorf("APIMaxConcurrent = %d, want default 16", cfg.APIMaxConcurrent)
+	}
+}
+
+func TestLoadCORSLists(t *testing.T) {
+	t.Setenv("API_CORS_ORIGINS", " https://a.example , ,https://b.example")
+	t.Setenv("API_CORS_METHODS", "")
+
+	cfg := Load()
+
+	if len(cfg.APICORSOrigins) != 2 || cfg.APICORSOrigins[0] != "https://a.example" || cfg.APICORSOrigins[1] != "https://b.example" {
+		t.Errorf("APICORSOrigins = %q, want two trimmed origins", cfg.APICORSOrigins)
+	}
+	if len(cfg.APICORSMethods) != 2 || cfg.APICORSMethods[0] != "GET" {
+		t.Errorf("APICORSMethods = %q, want default [GET OPTIONS]", cfg.APICORSMethods)
+	}
+}
+
+func TestLoadDisabledCalculators(t *testing.T) {
+	t.Setenv("INDICATOR_DISABLED_CALCULATORS", "dividend, bpp")
+
+	cfg := Load()
+
+	if len(cfg.DisabledCalculators) != 2 || cfg.DisabledCalculators[0] != "dividend" || cfg.DisabledCalculators[1] != "bpp" {
+		t.Errorf("DisabledCalculators = %q, want [dividend bpp]", cfg.DisabledCalculators)
+	}
+}
+
+func TestLoadSheetsByEntity(t *testing.T) {
+	t.Setenv("GOOGLE_SHEETS_SPREADSHEET_ID_MTLA", "sheet-a")
+	t.Setenv("GOOGLE_CREDENTIALS_JSON_MTLA", `{"client_email":"a@x"}`)
+	t.Setenv("GOOGLE_CREDENTIALS_JSON_EMPTY", "")
+
+	cfg := Load()
+
+	if cfg.GoogleSheetsByEntity["mtla"] != "sheet-a" {
+		t.Errorf("GoogleSheetsByEntity = %v, want mtla → sheet-a", cfg.GoogleSheetsByEntity)
+	}
+	if cfg.GoogleCredentialsByEntity["mtla"] != `{"client_email":"a@x"}` {
+		t.Errorf("GoogleCredentialsByEntity = %v", cfg.GoogleCredentialsByEntity)
+	}
+	if _, ok := cfg.GoogleCredentialsByEntity["empty"]; ok {
+		t.Error("empty override should be ignored")
+	}
+}
@@ -0,0 +1,145 @@
+// Package conversion tracks MTLRECT shares converted to MTL. A holder
+// converts by returning MTLRECT to the issuer, which then issues MTL to the
+// same account, usually in the same transaction. The supply indicators only
+// show MTLRECT shrinking and MTL growing; conversion ties the two together
+// and measures how fast MTLRECT is being converted.
+package conversion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// MatchWindow is how long after an MTLRECT return an MTL issuance to the
+// same account still counts as its conversion.
+const MatchWindow = 7 * 24 * time.Hour
+
+// DefaultPeriodDays is the window of the conversion velocity (I74).
+const DefaultPeriodDays = 30
+
+// Conversion is one MTLRECT return matched with the MTL issued for it.
+type Conversion struct {
+	Account    string          `json:"account"`
+	Amount     decimal.Decimal `json:"amount"`   // MTLRECT returned
+	Received   decimal.Decimal `json:"received"` // MTL issued
+	ReturnTx   string          `json:"returnTx"`
+	IssueTx    string          `json:"issueTx"` // equal to ReturnTx for a single-transaction conversion
+	ReturnedAt time.Time       `json:"returnedAt"`
+	At         time.Time       `json:"at"` // when the MTL was issued, completing the conversion
+}
+
+// Stats are the conversion totals as of a date.
+type Stats struct {
+	Total      decimal.Decimal // MTLRECT converted up to the date (I73)
+	Period     decimal.Decimal // MTLRECT converted in the PeriodDays up to the date (I74)
+	PeriodDays int
+}
+
+// Detect matches each MTLRECT return to the issuer (rect, oldest first)
+// with the first unused MTL issuance to the same account (mtl, oldest
+// first) no earlier than the return and within MatchWindow. Clawbacks are
+// not conversions; an issuance without a preceding return is plain issuance.
+func Detect(issuer string, rect, mtl []horizon.IssuerOperation) []Conversion {
+	used := make([]bool, len(mtl))
+	var out []Conversion
+	for _, r := range rect {
+		if r.Type == "clawback" || r.Mint(issuer) {
+			continue
+		}
+		for j, m := range mtl {
+			if used[j] || !m.Mint(issuer) || m.To != r.From || m.TS.Before(r.TS) {
+				continue
+			}
+			if m.TS.Sub(r.TS) > MatchWindow {
+				break
+			}
+			used[j] = true
+			out = append(out, Conversion{
+				Account: r.From, Amount: r.Amount, Received: m.Amount,
+				ReturnTx: r.TxHash, IssueTx: m.TxHash, ReturnedAt: r.TS, At: m.TS,
+			})
+			break
+		}
+	}
+	return out
+}
+
+// OperationSource walks the issuer's operations (horizon.Client).
+type OperationSource interface {
+	FetchIssuerOperations(ctx context.Context, asset domain.AssetInfo, since time.Time) ([]horizon.IssuerOperation, error)
+}
+
+// Store persists conversions and the scan position (PgRepository).
+type Store interface {
+	ScannedTo(ctx context.Context, slug string) (time.Time, error)
+	Save(ctx context.Context, slug string, scannedTo time.Time, conversions []Conversion) error
+	Totals(ctx context.Context, slug string, periodStart, end time.Time) (total, period decimal.Decimal, err error)
+}
+
+// Service records conversions from the issuer's operations.
+type Service struct {
+	ops   OperationSource
+	store Store
+	slug  string
+	now   func() time.Time
+}
+
+// NewService creates a Service for the entity slug.
+func NewService(ops OperationSource, store Store, slug string) *Service {
+	return &Service{ops: ops, store: store, slug: slug, now: time.Now}
+}
+
+// Record scans the issuer's MTLRECT and MTL operations since the last scan
+// and stores the conversions found. The scan reaches back MatchWindow
+// before the last scan so a return just before it still finds its
+// issuance; conversions already stored are kept as they were. Without a
+// previous scan the whole history is walked once. Returns the conversions
+// of this scan, stored before or not.
+func (s *Service) Record(ctx context.Context) ([]Conversion, error) {
+	scannedTo, err := s.store.ScannedTo(ctx, s.slug)
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if !scannedTo.IsZero() {
+		since = scannedTo.Add(-MatchWindow)
+	}
+	now := s.now()
+
+	rect, err := s.ops.FetchIssuerOperations(ctx, domain.NewAssetInfo("MTLRECT", domain.IssuerAddress), since)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MTLRECT issuer operations: %w", err)
+	}
+	mtl, err := s.ops.FetchIssuerOperations(ctx, domain.NewAssetInfo("MTL", domain.IssuerAddress), since)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MTL issuer operations: %w", err)
+	}
+	conversions := Detect(domain.IssuerAddress, rect, mtl)
+	for _, c := range conversions {
+		slog.Debug("MTLRECT conversion detected", "account", c.Account, "amount", c.Amount.String(),
+			"received", c.Received.String(), "tx", c.IssueTx)
+	}
+
+	if err := s.store.Save(ctx, s.slug, now, conversions); err != nil {
+		return nil, err
+	}
+	return conversions, nil
+}
+
+// Stats returns the MTLRECT converted up to the end of date, in total and
+// in the `days` days ending with it.
+func (s *Service) Stats(ctx context.Context, date time.Time, days int) (Stats, error) {
+	end := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	total, period, err := s.store.Totals(ctx, s.slug, end.AddDate(0, 0, -days), end)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Total: total, Period: period, PeriodDays: days}, nil
+}
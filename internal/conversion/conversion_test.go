@@ -0,0 +1,107 @@
+package conversion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+var t0 = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func TestDetect(t *testing.T) {
+	const issuer = "GISSUER"
+	rect := []horizon.IssuerOperation{
+		{Type: "payment", From: "GALICE", To: issuer, Amount: decimal.NewFromInt(100), TxHash: "tx1", TS: t0},
+		{Type: "payment", From: "GBOB", To: issuer, Amount: decimal.NewFromInt(50), TxHash: "tx2", TS: t0},
+		{Type: "clawback", From: "GCAROL", Amount: decimal.NewFromInt(10), TxHash: "tx3", TS: t0},
+		{Type: "payment", From: issuer, To: "GDAVE", Amount: decimal.NewFromInt(5), TxHash: "tx4", TS: t0}, // MTLRECT issuance
+		{Type: "payment", From: "GERIN", To: issuer, Amount: decimal.NewFromInt(20), TxHash: "tx5", TS: t0},
+	}
+	mtl := []horizon.IssuerOperation{
+		{Type: "payment", From: issuer, To: "GALICE", Amount: decimal.NewFromInt(100), TxHash: "tx1", TS: t0},                  // same transaction
+		{Type: "payment", From: issuer, To: "GBOB", Amount: decimal.NewFromInt(50), TxHash: "tx6", TS: t0.Add(48 * time.Hour)}, // issued later
+		{Type: "payment", From: issuer, To: "GCAROL", Amount: decimal.NewFromInt(10), TxHash: "tx7", TS: t0},
+		{Type: "payment", From: issuer, To: "GERIN", Amount: decimal.NewFromInt(20), TxHash: "tx8", TS: t0.Add(MatchWindow + time.Hour)}, // too late
+	}
+
+	got := Detect(issuer, rect, mtl)
+	if len(got) != 2 {
+		t.Fatalf("got %d conversions %+v, want 2", len(got), got)
+	}
+	if c := got[0]; c.Account != "GALICE" || c.ReturnTx != "tx1" || c.IssueTx != "tx1" || !c.Amount.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("single-transaction conversion = %+v", c)
+	}
+	if c := got[1]; c.Account != "GBOB" || c.IssueTx != "tx6" || !c.At.Equal(t0.Add(48*time.Hour)) || !c.ReturnedAt.Equal(t0) {
+		t.Errorf("two-transaction conversion = %+v", c)
+	}
+}
+
+type stubOps map[string][]horizon.IssuerOperation
+
+func (s stubOps) FetchIssuerOperations(_ context.Context, asset domain.AssetInfo, _ time.Time) ([]horizon.IssuerOperation, error) {
+	return s[asset.Code], nil
+}
+
+type memStore struct {
+	scannedTo   time.Time
+	conversions []Conversion
+}
+
+func (m *memStore) ScannedTo(context.Context, string) (time.Time, error) { return m.scannedTo, nil }
+
+func (m *memStore) Save(_ context.Context, _ string, scannedTo time.Time, conversions []Conversion) error {
+	m.scannedTo = scannedTo
+	m.conversions = append(m.conversions, conversions...)
+	return nil
+}
+
+func (m *memStore) Totals(_ context.Context, _ string, periodStart, end time.Time) (decimal.Decimal, decimal.Decimal, error) {
+	total, period := decimal.Zero, decimal.Zero
+	for _, c := range m.conversions {
+		if !c.At.Before(end) {
+			continue
+		}
+		total = total.Add(c.Amount)
+		if !c.At.Before(periodStart) {
+			period = period.Add(c.Amount)
+		}
+	}
+	return total, period, nil
+}
+
+func TestServiceRecordAndStats(t *testing.T) {
+	ops := stubOps{
+		"MTLRECT": {
+			{Type: "payment", From: "GOLD", To: domain.IssuerAddress, Amount: decimal.NewFromInt(40), TxHash: "old", TS: t0.AddDate(0, 0, -60)},
+			{Type: "payment", From: "GNEW", To: domain.IssuerAddress, Amount: decimal.NewFromInt(15), TxHash: "new", TS: t0},
+		},
+		"MTL": {
+			{Type: "payment", From: domain.IssuerAddress, To: "GOLD", Amount: decimal.NewFromInt(40), TxHash: "old", TS: t0.AddDate(0, 0, -60)},
+			{Type: "payment", From: domain.IssuerAddress, To: "GNEW", Amount: decimal.NewFromInt(15), TxHash: "new", TS: t0},
+		},
+	}
+	store := &memStore{}
+	svc := NewService(ops, store, "mtlf")
+	svc.now = func() time.Time { return t0.Add(time.Hour) }
+
+	got, err := svc.Record(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !store.scannedTo.Equal(t0.Add(time.Hour)) {
+		t.Errorf("conversions = %+v, scannedTo = %v", got, store.scannedTo)
+	}
+
+	stats, err := svc.Stats(context.Background(), t0, DefaultPeriodDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Total.Equal(decimal.NewFromInt(55)) || !stats.Period.Equal(decimal.NewFromInt(15)) || stats.PeriodDays != 30 {
+		t.Errorf("stats = %+v, want total 55, period 15", stats)
+	}
+}
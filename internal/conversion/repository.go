@@ -0,0 +1,132 @@
+package conversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// PgRepository stores conversions in mtlrect_conversions and the scan
+// position in conversion_scans.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL conversion repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// ScannedTo returns how far the issuer's operations have been scanned, or
+// the zero time before the first scan.
+func (r *PgRepository) ScannedTo(ctx context.Context, slug string) (time.Time, error) {
+	var t time.Time
+	err := r.pool.QueryRow(ctx,
+		`SELECT cs.scanned_to
+		 FROM conversion_scans cs
+		 JOIN fund_entities fe ON fe.id = cs.entity_id
+		 WHERE fe.slug = $1`, slug).Scan(&t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loading conversion scan position: %w", err)
+	}
+	return t, nil
+}
+
+// Save stores conversions and moves the scan position to scannedTo in one
+// transaction. A conversion whose return or issuance is already stored is
+// skipped.
+func (r *PgRepository) Save(ctx context.Context, slug string, scannedTo time.Time, conversions []Conversion) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning conversion tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	for _, c := range conversions {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO mtlrect_conversions (entity_id, account, amount, received, return_tx, issue_tx,
+			     returned_at, occurred_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			 ON CONFLICT DO NOTHING`,
+			entityID, c.Account, c.Amount, c.Received, c.ReturnTx, c.IssueTx, c.ReturnedAt, c.At); err != nil {
+			return fmt.Errorf("saving conversion %s: %w", c.IssueTx, err)
+		}
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO conversion_scans (entity_id, scanned_to) VALUES ($1, $2)
+		 ON CONFLICT (entity_id) DO UPDATE
+		 SET scanned_to = EXCLUDED.scanned_to, updated_at = CURRENT_TIMESTAMP`,
+		entityID, scannedTo); err != nil {
+		return fmt.Errorf("saving conversion scan position: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing conversions: %w", err)
+	}
+	return nil
+}
+
+// Totals returns the MTLRECT converted before end, in total and since
+// periodStart.
+func (r *PgRepository) Totals(ctx context.Context, slug string, periodStart, end time.Time) (total, period decimal.Decimal, err error) {
+	err = r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(mc.amount), 0),
+		        COALESCE(SUM(mc.amount) FILTER (WHERE mc.occurred_at >= $2), 0)
+		 FROM mtlrect_conversions mc
+		 JOIN fund_entities fe ON fe.id = mc.entity_id
+		 WHERE fe.slug = $1 AND mc.occurred_at < $3`,
+		slug, periodStart, end).Scan(&total, &period)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("summing conversions: %w", err)
+	}
+	return total, period, nil
+}
+
+// List returns the conversions completed between from and to (inclusive;
+// zero means unbounded), oldest first.
+func (r *PgRepository) List(ctx context.Context, slug string, from, to time.Time) ([]Conversion, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT mc.account, mc.amount, mc.received, mc.return_tx, mc.issue_tx, mc.returned_at, mc.occurred_at
+		 FROM mtlrect_conversions mc
+		 JOIN fund_entities fe ON fe.id = mc.entity_id
+		 WHERE fe.slug = $1
+		   AND ($2::timestamptz IS NULL OR mc.occurred_at >= $2)
+		   AND ($3::timestamptz IS NULL OR mc.occurred_at <= $3)
+		 ORDER BY mc.occurred_at, mc.issue_tx`,
+		slug, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("listing conversions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Conversion
+	for rows.Next() {
+		var c Conversion
+		if err := rows.Scan(&c.Account, &c.Amount, &c.Received, &c.ReturnTx, &c.IssueTx, &c.ReturnedAt, &c.At); err != nil {
+			return nil, fmt.Errorf("scanning conversion: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating conversions: %w", err)
+	}
+	return out, nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"sort"
@@ -10,8 +11,15 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrSQLite is returned by Connect for a sqlite:// URL: only the snapshot and
+// quote stores have a SQLite implementation (storage.Open).
+var ErrSQLite = errors.New("this command needs PostgreSQL; a sqlite:// DATABASE_URL only serves `stat quote` and `stat report --dry-run`")
+
 // Connect creates a PostgreSQL connection pool.
 func Connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	if strings.HasPrefix(databaseURL, "sqlite://") {
+		return nil, ErrSQLite
+	}
 	pool, err := pgxpool.New(ctx, databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to database: %w", err)
@@ -37,37 +45,15 @@ func RunMigrations(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS) error {
 		return fmt.Errorf("creating schema_migrations table: %w", err)
 	}
 
-	rows, err := pool.Query(ctx, `SELECT filename FROM schema_migrations`)
+	applied, err := appliedMigrations(ctx, pool)
 	if err != nil {
-		return fmt.Errorf("reading applied migrations: %w", err)
+		return err
 	}
-	applied := make(map[string]bool)
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			rows.Close()
-			return fmt.Errorf("scanning migration name: %w", err)
-		}
-		applied[name] = true
-	}
-	rows.Close()
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("iterating applied migrations: %w", err)
-	}
-
-	entries, err := fs.ReadDir(fsys, ".")
+	upFiles, err := upMigrations(fsys)
 	if err != nil {
-		return fmt.Errorf("reading migrations directory: %w", err)
+		return err
 	}
 
-	var upFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".up.sql") {
-			upFiles = append(upFiles, entry.Name())
-		}
-	}
-	sort.Strings(upFiles)
-
 	for _, file := range upFiles {
 		if applied[file] {
 			continue
@@ -87,3 +73,68 @@ func RunMigrations(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS) error {
 
 	return nil
 }
+
+// PendingMigrations returns the .up.sql files in fsys not yet applied, in
+// the order RunMigrations would apply them. It changes nothing: before the
+// first run (no schema_migrations table) every migration is pending.
+func PendingMigrations(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS) ([]string, error) {
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("checking schema_migrations table: %w", err)
+	}
+	applied := map[string]bool{}
+	if exists {
+		var err error
+		if applied, err = appliedMigrations(ctx, pool); err != nil {
+			return nil, err
+		}
+	}
+	upFiles, err := upMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, file := range upFiles {
+		if !applied[file] {
+			pending = append(pending, file)
+		}
+	}
+	return pending, nil
+}
+
+// appliedMigrations returns the filenames recorded in schema_migrations.
+func appliedMigrations(ctx context.Context, pool *pgxpool.Pool) (map[string]bool, error) {
+	rows, err := pool.Query(ctx, `SELECT filename FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("reading applied migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scanning migration name: %w", err)
+		}
+		applied[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// upMigrations returns the .up.sql files in fsys, sorted.
+func upMigrations(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations directory: %w", err)
+	}
+	var upFiles []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".up.sql") {
+			upFiles = append(upFiles, entry.Name())
+		}
+	}
+	sort.Strings(upFiles)
+	return upFiles, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// OpenSQLite opens the SQLite database file at path (":memory:" for a
+// private in-memory one) with foreign keys enforced. The pool holds a single
+// connection: SQLite serializes writers anyway, and an in-memory database
+// exists only on the connection that created it.
+func OpenSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", path+sep+"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("opening SQLite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxIdleTime(0)
+	db.SetConnMaxLifetime(0)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening SQLite database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// RunSQLiteMigrations applies the .up.sql files of fsys to db like
+// RunMigrations, tracking them in the same schema_migrations table.
+func RunSQLiteMigrations(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			filename   TEXT PRIMARY KEY,
+			applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+		)`); err != nil {
+		return fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT filename FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("reading applied migrations: %w", err)
+	}
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning migration name: %w", err)
+		}
+		applied[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating applied migrations: %w", err)
+	}
+
+	upFiles, err := upMigrations(fsys)
+	if err != nil {
+		return err
+	}
+	for _, file := range upFiles {
+		if applied[file] {
+			continue
+		}
+		stmt, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("reading migration %s: %w", file, err)
+		}
+		if _, err := db.ExecContext(ctx, string(stmt)); err != nil {
+			return fmt.Errorf("executing migration %s: %w", file, err)
+		}
+		if _, err := db.ExecContext(ctx,
+			`INSERT INTO schema_migrations (filename) VALUES (?)`, file); err != nil {
+			return fmt.Errorf("recording migration %s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package decjson holds the serialization policy for decimal amounts in JSON.
+//
+// Amounts are strings by default: decimal.Decimal marshals as "1234.56" and
+// the snapshot documents store balances and prices as strings, so a client
+// sees one type for every amount. The number format writes decimal.Decimal
+// unquoted instead, at the scale it carries (indicator values are rounded to
+// their registered precision). Snapshot documents are sealed as stored and
+// keep string amounts under either policy. Reading accepts both forms, so
+// JSON persisted under one policy loads under the other.
+package decjson
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format is how decimal.Decimal values are written to JSON.
+type Format string
+
+const (
+	String Format = "string"
+	Number Format = "number"
+)
+
+// Header is the response header through which the API announces the format.
+const Header = "X-Decimal-Format"
+
+// Parse validates a format name; "" is String.
+func Parse(s string) (Format, error) {
+	switch Format(s) {
+	case "", String:
+		return String, nil
+	case Number:
+		return Number, nil
+	}
+	return "", fmt.Errorf("invalid decimal JSON format %q, expected string or number", s)
+}
+
+// Apply sets the process-wide format. Call it once at startup, before any
+// JSON is written.
+func Apply(f Format) {
+	decimal.MarshalJSONWithoutQuotes = f == Number
+}
+
+// Current returns the format in effect.
+func Current() Format {
+	if decimal.MarshalJSONWithoutQuotes {
+		return Number
+	}
+	return String
+}
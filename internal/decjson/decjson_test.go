@@ -0,0 +1,44 @@
+package decjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]Format{"": String, "string": String, "number": Number} {
+		if got, err := Parse(in); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := Parse("float"); err == nil {
+		t.Error("Parse(float) succeeded")
+	}
+}
+
+func TestApply(t *testing.T) {
+	defer Apply(Current())
+
+	v := struct {
+		Value decimal.Decimal `json:"value"`
+	}{decimal.RequireFromString("1234.50")}
+
+	Apply(Number)
+	if out, _ := json.Marshal(v); string(out) != `{"value":1234.5}` || Current() != Number {
+		t.Errorf("number: %s", out)
+	}
+	Apply(String)
+	if out, _ := json.Marshal(v); string(out) != `{"value":"1234.5"}` || Current() != String {
+		t.Errorf("string: %s", out)
+	}
+
+	// Either form reads back.
+	for _, raw := range []string{`{"value":1234.5}`, `{"value":"1234.5"}`} {
+		v.Value = decimal.Zero
+		if err := json.Unmarshal([]byte(raw), &v); err != nil || !v.Value.Equal(decimal.RequireFromString("1234.5")) {
+			t.Errorf("unmarshal %s: %v, %v", raw, v.Value, err)
+		}
+	}
+}
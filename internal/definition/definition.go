@@ -0,0 +1,75 @@
+// Package definition keeps the changelog of indicator formulas: when the
+// methodology of an indicator changes (say I16), a Change records the new
+// definition version and the date it takes effect. Comparisons spanning that
+// date are flagged, since the values on either side aren't computed the same
+// way.
+package definition
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSummaryLength bounds Change.Summary.
+const MaxSummaryLength = 500
+
+var (
+	// ErrNotFound indicates the change doesn't exist.
+	ErrNotFound = errors.New("definition change not found")
+	// ErrInvalid indicates a change that fails validation.
+	ErrInvalid = errors.New("invalid definition change")
+)
+
+// Change is one new definition of an indicator. Version 1 is the original
+// definition and has no Change; the first recorded change is version 2.
+type Change struct {
+	ID            int64     `json:"id"`
+	IndicatorID   int       `json:"indicatorId"`
+	Version       int       `json:"version"`
+	EffectiveDate time.Time `json:"effectiveDate" swaggertype:"string" example:"2026-05-01"`
+	Summary       string    `json:"summary"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Validate checks that the indicator ID and the date are set and the summary
+// is not empty. Retired indicators (not in the registry) may still get
+// changes, since their history is stored.
+func (c Change) Validate() error {
+	if c.IndicatorID <= 0 {
+		return fmt.Errorf("%w: indicator ID must be positive", ErrInvalid)
+	}
+	if c.EffectiveDate.IsZero() {
+		return fmt.Errorf("%w: effective date is required", ErrInvalid)
+	}
+	if n := utf8.RuneCountInString(strings.TrimSpace(c.Summary)); n == 0 || n > MaxSummaryLength {
+		return fmt.Errorf("%w: summary must be 1 to %d characters", ErrInvalid, MaxSummaryLength)
+	}
+	return nil
+}
+
+// Spanning returns the changes of indicator id that take effect after from
+// and on or before to: a value on from and one on to straddle each of them.
+func Spanning(changes []Change, id int, from, to time.Time) []Change {
+	var out []Change
+	for _, c := range changes {
+		if c.IndicatorID == id && c.EffectiveDate.After(from) && !c.EffectiveDate.After(to) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// VersionOn returns the definition version of indicator id in effect on
+// date: 1 unless a change took effect by then.
+func VersionOn(changes []Change, id int, date time.Time) int {
+	version := 1
+	for _, c := range changes {
+		if c.IndicatorID == id && !c.EffectiveDate.After(date) && c.Version > version {
+			version = c.Version
+		}
+	}
+	return version
+}
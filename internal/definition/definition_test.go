@@ -0,0 +1,56 @@
+package definition
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestValidate(t *testing.T) {
+	ok := Change{IndicatorID: 16, EffectiveDate: day("2026-05-01"), Summary: "yield over the last 12 paid months"}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("valid change: %v", err)
+	}
+	for name, c := range map[string]Change{
+		"no indicator":  {IndicatorID: 0, EffectiveDate: ok.EffectiveDate, Summary: ok.Summary},
+		"no date":       {IndicatorID: 16, Summary: ok.Summary},
+		"blank summary": {IndicatorID: 16, EffectiveDate: ok.EffectiveDate, Summary: "  "},
+	} {
+		if err := c.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestSpanningAndVersionOn(t *testing.T) {
+	changes := []Change{
+		{IndicatorID: 16, Version: 2, EffectiveDate: day("2026-03-01")},
+		{IndicatorID: 16, Version: 3, EffectiveDate: day("2026-06-01")},
+		{IndicatorID: 17, Version: 2, EffectiveDate: day("2026-04-01")},
+	}
+
+	got := Spanning(changes, 16, day("2026-03-01"), day("2026-06-01"))
+	if len(got) != 1 || got[0].Version != 3 {
+		t.Errorf("Spanning(I16, 03-01..06-01) = %+v, want only v3 (v2 took effect on the base date)", got)
+	}
+	if got := Spanning(changes, 16, day("2026-01-01"), day("2026-12-31")); len(got) != 2 {
+		t.Errorf("Spanning(I16, year) = %+v, want both", got)
+	}
+	if got := Spanning(changes, 8, day("2026-01-01"), day("2026-12-31")); got != nil {
+		t.Errorf("Spanning(I8) = %+v, want none", got)
+	}
+
+	for date, want := range map[string]int{"2026-02-28": 1, "2026-03-01": 2, "2026-05-31": 2, "2026-06-01": 3} {
+		if v := VersionOn(changes, 16, day(date)); v != want {
+			t.Errorf("VersionOn(I16, %s) = %d, want %d", date, v, want)
+		}
+	}
+}
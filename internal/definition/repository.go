@@ -0,0 +1,77 @@
+package definition
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores definition changes in indicator_definitions_history.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL definition changelog repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Add validates and stores c as the next version of its indicator, and
+// returns it with its ID, version and creation time.
+func (r *PgRepository) Add(ctx context.Context, c Change) (Change, error) {
+	if err := c.Validate(); err != nil {
+		return Change{}, err
+	}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO indicator_definitions_history (indicator_id, version, effective_date, summary)
+		 SELECT $1, COALESCE(MAX(version), 1) + 1, $2, $3
+		 FROM indicator_definitions_history WHERE indicator_id = $1
+		 RETURNING id, version, created_at`,
+		c.IndicatorID, c.EffectiveDate, c.Summary).Scan(&c.ID, &c.Version, &c.CreatedAt)
+	if err != nil {
+		return Change{}, fmt.Errorf("saving definition change of I%d: %w", c.IndicatorID, err)
+	}
+	return c, nil
+}
+
+// List returns the changes of indicator id, or of every indicator when id
+// is 0, by indicator and version.
+func (r *PgRepository) List(ctx context.Context, id int) ([]Change, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, indicator_id, version, effective_date, summary, created_at
+		 FROM indicator_definitions_history
+		 WHERE $1 = 0 OR indicator_id = $1
+		 ORDER BY indicator_id, version`,
+		id)
+	if err != nil {
+		return nil, fmt.Errorf("listing definition changes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Change{}
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.ID, &c.IndicatorID, &c.Version, &c.EffectiveDate, &c.Summary, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning definition change: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating definition changes: %w", err)
+	}
+	return out, nil
+}
+
+// Delete removes the change with the given ID, or returns ErrNotFound.
+// Later versions of the indicator keep their numbers.
+func (r *PgRepository) Delete(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM indicator_definitions_history WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting definition change %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return nil
+}
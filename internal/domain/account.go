@@ -13,6 +13,13 @@ const (
 	AccountTypeMutual      AccountType = "mutual"
 	AccountTypeOperational AccountType = "operational"
 	AccountTypeOther       AccountType = "other"
+	// AccountTypePeer marks an external treasury tracked for comparison only.
+	// Peers are never in the registry and never count towards fund totals.
+	AccountTypePeer AccountType = "peer"
+	// AccountTypeEndowment marks an account of the Montelibero Association's
+	// endowment fund (internal/association). It never appears in the fund
+	// registry.
+	AccountTypeEndowment AccountType = "endowment"
 )
 
 // FundAccount represents a Stellar account managed by the fund.
@@ -57,6 +57,9 @@ const IssuerAddress = "GACKTN5DAZGWXRWB2WLM6OPBDHAMT6SJNGLJZPQMEZBUR4JUGBX2UK7V"
 // MTLAPAddress is the Stellar address of the Montelibero Association issuer.
 const MTLAPAddress = "GCNVDZIHGX473FEI7IXCUAEXUJ4BGCKEMHF36VYP5EMS7PX2QBLAMTLA"
 
+// USDMIssuer is the Stellar address of the USDM (USD-pegged) issuer.
+const USDMIssuer = "GDHDC4GBNPMENZAOBB4NCQ25TGZPDRK6ZGWUGSI22TVFATOLRPSUUSDM"
+
 // MTLDividendDistributor is the single Stellar address from which the fund
 // dispatches monthly MTL dividends as direct EURMTL payments. Each distribution
 // is one transaction whose memo starts with "mtl div " followed by a date —
@@ -64,7 +67,7 @@ const MTLAPAddress = "GCNVDZIHGX473FEI7IXCUAEXUJ4BGCKEMHF36VYP5EMS7PX2QBLAMTLA"
 // transaction at-or-before the snapshot date and stay sticky between events.
 const MTLDividendDistributor = "GDNHQWZRZDZZBARNOH6VFFXMN6LBUNZTZHOKBUT7GREOWBTZI4FGS7IQ"
 
-// eurmtlAsset, xlmAsset and mtlapAsset are unexported to prevent external mutation.
+// eurmtlAsset, xlmAsset, mtlapAsset and usdmAsset are unexported to prevent external mutation.
 var (
 	eurmtlAsset = AssetInfo{
 		Code:   "EURMTL",
@@ -80,6 +83,11 @@ var (
 		Issuer: MTLAPAddress,
 		Type:   AssetTypeCreditAlphanum12,
 	}
+	usdmAsset = AssetInfo{
+		Code:   "USDM",
+		Issuer: USDMIssuer,
+		Type:   AssetTypeCreditAlphanum4,
+	}
 )
 
 // EURMTLAsset returns the fund's base asset (EUR-pegged stablecoin).
@@ -90,3 +98,6 @@ func XLMAsset() AssetInfo { return xlmAsset }
 
 // MTLAPAsset returns the Montelibero Association participation token asset.
 func MTLAPAsset() AssetInfo { return mtlapAsset }
+
+// USDMAsset returns the USD-pegged stablecoin, a common bridge between markets.
+func USDMAsset() AssetInfo { return usdmAsset }
@@ -1,6 +1,10 @@
 package domain
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // FundAccountPortfolio represents a fully priced and valued account portfolio.
 type FundAccountPortfolio struct {
@@ -13,8 +17,20 @@ type FundAccountPortfolio struct {
 	XLMPriceInEURMTL *string                 `json:"xlmPriceInEURMTL"`
 	TotalEURMTL      decimal.Decimal         `json:"totalEURMTL"`
 	TotalXLM         decimal.Decimal         `json:"totalXLM"`
+	Ignored          []IgnoredToken          `json:"ignored,omitempty"` // excluded by the token filter, not valued
+	// Accounts of type other under OTHER_ACCOUNTS_PRICING: Pricing is
+	// PricingCarried or PricingBalance when this run didn't price the tokens,
+	// and PricedAt is when their prices were discovered (weekly mode only).
+	Pricing  string     `json:"pricing,omitempty"`
+	PricedAt *time.Time `json:"pricedAt,omitempty"`
 }
 
+// Pricing of a FundAccountPortfolio not priced by its own run.
+const (
+	PricingCarried = "carried" // token prices carried over from PricedAt, values at today's balances
+	PricingBalance = "balance" // balances only, no prices
+)
+
 // AggregatedTotals holds the fund-level totals (excluding mutual and other accounts).
 type AggregatedTotals struct {
 	TotalEURMTL  decimal.Decimal `json:"totalEURMTL"`
@@ -27,19 +43,48 @@ type AggregatedTotals struct {
 // Indicator calculators read these values exclusively — they do not call Horizon.
 // This makes snapshots fully reproducible and keeps the report runtime bounded.
 type FundLiveMetrics struct {
-	MTLMarketPrice        *string `json:"mtl_market_price,omitempty"`        // I10
-	MTLRECTMarketPrice    *string `json:"mtlrect_market_price,omitempty"`    // I49
-	MTLCirculation        *string `json:"mtl_circulation,omitempty"`         // I6
-	MTLRECTCirculation    *string `json:"mtlrect_circulation,omitempty"`     // I7
-	MonthlyDividends      *string `json:"monthly_dividends,omitempty"`       // I11
-	EURMTLDailyVolume     *string `json:"eurmtl_daily_volume,omitempty"`     // I25
-	EURMTLPaymentTotal    *string `json:"eurmtl_payment_total,omitempty"`    // I26 — old snapshots wrote `eurmtl_30d_volume`; that key is intentionally not read (indicator history is authoritative for I26)
-	EURMTLParticipants    *string `json:"eurmtl_participants,omitempty"`     // I24
-	MTLShareholders       *string `json:"mtl_shareholders,omitempty"`        // I27
-	MTLShareholdersAny    *string `json:"mtl_shareholders_any,omitempty"`    // I62
-	MTLShareholdersMedian *string `json:"mtl_shareholders_median,omitempty"` // I23
-	MTLAPHolders          *string `json:"mtlap_holders,omitempty"`           // I40
-	EURMTLShareholders    *string `json:"eurmtl_shareholders,omitempty"`     // I18
+	MTLMarketPrice        *string    `json:"mtl_market_price,omitempty"`        // I10
+	MTLRECTMarketPrice    *string    `json:"mtlrect_market_price,omitempty"`    // I49
+	MTLCirculation        *string    `json:"mtl_circulation,omitempty"`         // I6
+	MTLRECTCirculation    *string    `json:"mtlrect_circulation,omitempty"`     // I7
+	MonthlyDividends      *string    `json:"monthly_dividends,omitempty"`       // I11
+	EURMTLDailyVolume     *string    `json:"eurmtl_daily_volume,omitempty"`     // I25
+	EURMTLPaymentTotal    *string    `json:"eurmtl_payment_total,omitempty"`    // I26 — old snapshots wrote `eurmtl_30d_volume`; that key is intentionally not read (indicator history is authoritative for I26)
+	EURMTLParticipants    *string    `json:"eurmtl_participants,omitempty"`     // I24
+	MTLShareholders       *string    `json:"mtl_shareholders,omitempty"`        // I27
+	MTLShareholdersAny    *string    `json:"mtl_shareholders_any,omitempty"`    // I62
+	MTLShareholdersMedian *string    `json:"mtl_shareholders_median,omitempty"` // I23
+	MTLAPHolders          *string    `json:"mtlap_holders,omitempty"`           // I40
+	EURMTLShareholders    *string    `json:"eurmtl_shareholders,omitempty"`     // I18
+	MTLAvgDailyVolume     *string    `json:"mtl_avg_daily_volume,omitempty"`    // I63 input: 30-day average MTL/EURMTL DEX volume, in MTL
+	MTLBidDepthTop5       *string    `json:"mtl_bid_depth_top5,omitempty"`      // I64 input: top-5 MTL bids, in EURMTL
+	MTLSupply             *string    `json:"mtl_supply,omitempty"`              // total MTL issued, AMM reserves included; issuance events compare it across snapshots
+	MTLRECTSupply         *string    `json:"mtlrect_supply,omitempty"`          // total MTLRECT issued, as MTLSupply
+	BTCRate               *string    `json:"btc_rate,omitempty"`                // I61: stored BTC quote, in EUR
+	BTCRateAt             *time.Time `json:"btc_rate_at,omitempty"`             // when the BTC quote was fetched; empty when I61 reused the prior day's value
+	HolderCountsAt        *time.Time `json:"holder_counts_at,omitempty"`        // when the oldest cached holder count used was computed; empty when every count was walked live
+	Fallbacks             []int      `json:"fallbacks,omitempty"`               // indicator IDs whose input reused the prior day's value
+}
+
+// DataQuality summarises how complete the inputs of a snapshot were, so a
+// regression shows up as a number rather than a longer warnings list.
+// Score is the percentage of held tokens that got a EURMTL value.
+type DataQuality struct {
+	Score           decimal.Decimal `json:"score"`
+	TokenCount      int             `json:"tokenCount"`
+	PricedCount     int             `json:"pricedCount"`
+	StaleQuotes     int             `json:"staleQuotes"`                 // external quotes older than quality.StaleQuoteAge
+	MetricFallbacks int             `json:"metricFallbacks"`             // len(LiveMetrics.Fallbacks)
+	HorizonLag      *int64          `json:"horizonLagSeconds,omitempty"` // Ledger.LagSeconds; absent before lag was recorded
+	Degraded        bool            `json:"degraded,omitempty"`          // Ledger.Stale: generated from a lagging Horizon
+}
+
+// LedgerStatus is how current Horizon was when a snapshot was generated.
+type LedgerStatus struct {
+	Ledger     int64     `json:"ledger"`          // latest ingested ledger
+	ClosedAt   time.Time `json:"closedAt"`        // when that ledger closed
+	LagSeconds int64     `json:"lagSeconds"`      // generation time minus ClosedAt
+	Stale      bool      `json:"stale,omitempty"` // lag beyond HORIZON_MAX_LAG
 }
 
 // FundStructureData is the top-level output of the fund aggregation pipeline.
@@ -50,4 +95,127 @@ type FundStructureData struct {
 	AggregatedTotals AggregatedTotals       `json:"aggregatedTotals"`
 	Warnings         []string               `json:"warnings,omitempty"`
 	LiveMetrics      *FundLiveMetrics       `json:"live_metrics,omitempty"`
+	Peers            []PeerMetrics          `json:"peers,omitempty"`
+	Watchlist        []WatchedAccount       `json:"watchlist,omitempty"`      // partner accounts tracked outside the fund totals
+	Liabilities      []Liability            `json:"liabilities,omitempty"`    // outstanding fund-issued obligations (LIABILITY_TOKENS)
+	IssuedSupplies   []IssuerSupply         `json:"issuedSupplies,omitempty"` // supply of every asset the fund accounts issue
+	Quotes           []QuoteUsage           `json:"quotes,omitempty"`         // external quotes used by valuations, one per symbol
+	Quality          *DataQuality           `json:"quality,omitempty"`
+	AccountConfigs   []AccountConfig        `json:"accountConfigs,omitempty"` // fund account settings, checked against account_expectations
+	Ledger           *LedgerStatus          `json:"ledger,omitempty"`         // Horizon recency at generation time
+	Groups           []AccountGroup         `json:"groups,omitempty"`         // ACCOUNT_GROUPS rollups of the accounts above
+}
+
+// AccountGroup is a configured rollup of fund accounts (ACCOUNT_GROUPS),
+// such as a real estate cluster of MCITY and APART, totalled at snapshot
+// time. Members may be of any account type, so a group can count accounts
+// that AggregatedTotals leaves out. Missing lists the members the snapshot
+// had no portfolio for; they count as zero.
+type AccountGroup struct {
+	Name        string               `json:"name"`
+	Members     []AccountGroupMember `json:"members"`
+	TotalEURMTL decimal.Decimal      `json:"totalEURMTL"`
+	TotalXLM    decimal.Decimal      `json:"totalXLM"`
+	TokenCount  int                  `json:"tokenCount"`
+	// ShareOfFund is TotalEURMTL as a percentage of
+	// AggregatedTotals.TotalEURMTL; nil when the fund total is zero.
+	ShareOfFund *decimal.Decimal `json:"shareOfFund,omitempty"`
+	Missing     []string         `json:"missing,omitempty"`
+}
+
+// AccountGroupMember is one account's contribution to an AccountGroup.
+type AccountGroupMember struct {
+	Name        string          `json:"name"`
+	Address     string          `json:"address"`
+	Type        AccountType     `json:"type"`
+	TotalEURMTL decimal.Decimal `json:"totalEURMTL"`
+	TotalXLM    decimal.Decimal `json:"totalXLM"`
+}
+
+// PeerMetrics is the comparable summary of an external treasury account,
+// captured at snapshot time. Error is set (and the figures left zero) when the
+// peer could not be fetched for that snapshot.
+type PeerMetrics struct {
+	Name        string          `json:"name"`
+	Address     string          `json:"address"`
+	TotalEURMTL decimal.Decimal `json:"totalEURMTL"`
+	TokenCount  int             `json:"tokenCount"`
+	HolderCount int             `json:"holderCount"` // accounts holding the peer's most widely held issued asset
+	Error       string          `json:"error,omitempty"`
+}
+
+// WatchedAccount is a partner or affiliate account on the watchlist,
+// captured at snapshot time. It never counts towards fund totals. Error is
+// set (and Balances left empty) when the account couldn't be fetched.
+type WatchedAccount struct {
+	Label       string           `json:"label"`
+	Address     string           `json:"address"`
+	Balances    []WatchedBalance `json:"balances"`
+	TotalEURMTL decimal.Decimal  `json:"totalEURMTL"` // sum of the priced balances
+	Error       string           `json:"error,omitempty"`
+}
+
+// WatchedBalance is a watched account's balance of one asset. ValueEURMTL
+// is nil when the snapshot has no price for the asset.
+type WatchedBalance struct {
+	Asset       AssetInfo        `json:"asset"`
+	Balance     decimal.Decimal  `json:"balance"`
+	ValueEURMTL *decimal.Decimal `json:"valueEURMTL,omitempty"`
+}
+
+// Liability is a fund-issued obligation token (e.g. MFBond) captured at
+// snapshot time. Outstanding is the on-chain supply less what the fund's own
+// accounts hold, and Value prices it at face value. Error is set (and the
+// figures left zero) when the supply couldn't be fetched.
+type Liability struct {
+	Asset       AssetInfo       `json:"asset"`
+	FaceValue   decimal.Decimal `json:"faceValue"`          // EURMTL owed per token
+	Maturity    *time.Time      `json:"maturity,omitempty"` // redemption date, if registered
+	Supply      decimal.Decimal `json:"supply"`
+	FundHeld    decimal.Decimal `json:"fundHeld"`
+	Outstanding decimal.Decimal `json:"outstanding"`
+	Value       decimal.Decimal `json:"value"` // Outstanding × FaceValue, in EURMTL
+	Error       string          `json:"error,omitempty"`
+}
+
+// IssuerSupply is the total supply of every asset one fund account issues,
+// captured at snapshot time. Accounts that issue nothing are left out. Error
+// is set (and Assets left empty) when the issued assets couldn't be fetched.
+type IssuerSupply struct {
+	Name   string        `json:"name"`
+	Issuer string        `json:"issuer"`
+	Assets []AssetSupply `json:"assets,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// AssetSupply is the total supply of one asset: balances at every
+// authorization level plus claimable balances, liquidity pools and contracts.
+type AssetSupply struct {
+	Code   string          `json:"code"`
+	Supply decimal.Decimal `json:"supply"`
+}
+
+// AccountFlags are a Stellar account's authorization flags.
+type AccountFlags struct {
+	AuthRequired        bool `json:"authRequired"`
+	AuthRevocable       bool `json:"authRevocable"`
+	AuthImmutable       bool `json:"authImmutable"`
+	AuthClawbackEnabled bool `json:"authClawbackEnabled"`
+}
+
+// AccountConfig is a fund account's on-chain configuration at snapshot time.
+// Unexpected changes to it can mean a compromised or misconfigured account.
+// Error is set (and the rest left zero) when the account could not be fetched.
+type AccountConfig struct {
+	Account              string       `json:"account"`
+	Name                 string       `json:"name"`
+	Flags                AccountFlags `json:"flags"`
+	HomeDomain           string       `json:"homeDomain,omitempty"`
+	InflationDestination string       `json:"inflationDestination,omitempty"`
+	NumSponsoring        int          `json:"numSponsoring"` // reserves this account pays for others
+	NumSponsored         int          `json:"numSponsored"`  // reserves others pay for this account
+	// Metadata holds the DATA entries named by ACCOUNT_METADATA_KEYS,
+	// decoded; a value that isn't UTF-8 keeps its base64 form.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Error    string            `json:"error,omitempty"`
 }
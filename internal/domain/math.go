@@ -35,31 +35,82 @@ func SafeSum(a, b decimal.Decimal) decimal.Decimal {
 	return a.Add(b)
 }
 
+// Precision is the number of decimal places a kind of quantity is rounded
+// to before it is stored or shown.
+type Precision int32
+
+const (
+	// AmountPrecision is the Stellar protocol's: one stroop, 0.0000001.
+	AmountPrecision Precision = stellarPrecision
+	// PricePrecision matches Horizon's `price` fields and path amounts.
+	PricePrecision Precision = stellarPrecision
+	// RatioPrecision keeps dimensionless ratios (shares, weights, yields)
+	// small enough to stay meaningful after a stroop-sized rounding.
+	RatioPrecision Precision = 12
+	// PercentPrecision rounds percentages to a hundredth of a basis point.
+	PercentPrecision Precision = 4
+)
+
 // MultiplyWithPrecision multiplies two string values with Stellar precision (7 decimal places),
-// stripping trailing zeros. Returns "0" for invalid input.
+// stripping trailing zeros. Returns "0" for invalid input. It is MultiplyAmount.
 func MultiplyWithPrecision(a, b string) string {
-	da := SafeParse(a)
-	db := SafeParse(b)
-	result := da.Mul(db)
-	return formatStellar(result)
+	return MultiplyAmount(a, b)
 }
 
 // DivideWithPrecision divides two string values with Stellar precision (7 decimal places),
-// stripping trailing zeros. Returns "0" for division by zero or invalid input.
+// stripping trailing zeros. Returns "0" for division by zero or invalid input. It is
+// DividePrice; for ratios and percentages use DivideRatio and Percent.
 func DivideWithPrecision(a, b string) string {
-	da := SafeParse(a)
+	return DividePrice(a, b)
+}
+
+// MultiplyAmount multiplies a balance by a price or a count into an amount.
+func MultiplyAmount(a, b string) string {
+	return Multiply(a, b, AmountPrecision)
+}
+
+// DividePrice divides a value by an amount or by another price into a price.
+func DividePrice(a, b string) string {
+	return Divide(a, b, PricePrecision)
+}
+
+// DivideRatio divides two like quantities into a dimensionless ratio.
+func DivideRatio(a, b string) string {
+	return Divide(a, b, RatioPrecision)
+}
+
+// Percent returns part as a percentage of whole, "0" when whole is zero or
+// either is invalid.
+func Percent(part, whole string) string {
+	dw := SafeParse(whole)
+	if dw.IsZero() {
+		return "0"
+	}
+	return formatRounded(SafeParse(part).Mul(decimal.NewFromInt(100)).Div(dw), PercentPrecision)
+}
+
+// Multiply multiplies two string values and rounds the product to p decimal
+// places, stripping trailing zeros. Invalid input counts as zero.
+func Multiply(a, b string, p Precision) string {
+	return formatRounded(SafeParse(a).Mul(SafeParse(b)), p)
+}
+
+// Divide divides two string values and rounds the quotient to p decimal
+// places, stripping trailing zeros. Returns "0" for division by zero or
+// invalid input. The quotient is computed with shopspring's 16-digit
+// division precision, so p beyond 16 adds nothing.
+func Divide(a, b string, p Precision) string {
 	db := SafeParse(b)
 	if db.IsZero() {
 		return "0"
 	}
-	result := da.Div(db)
-	return formatStellar(result)
+	return formatRounded(SafeParse(a).Div(db), p)
 }
 
-// formatStellar rounds to 7 decimal places and strips trailing zeros.
-func formatStellar(d decimal.Decimal) string {
-	rounded := d.Round(stellarPrecision)
-	s := rounded.StringFixed(stellarPrecision)
+// formatRounded rounds to p decimal places and strips trailing zeros.
+func formatRounded(d decimal.Decimal, p Precision) string {
+	places := int32(p)
+	s := d.Round(places).StringFixed(places)
 	if !strings.Contains(s, ".") {
 		return s
 	}
@@ -141,3 +141,48 @@ func TestDivideWithPrecision(t *testing.T) {
 		})
 	}
 }
+
+func TestAmountHelpersKeepStellarPrecision(t *testing.T) {
+	// Amounts and prices round to 7 places exactly as the old helpers did.
+	pairs := [][2]string{
+		{"10", "5"}, {"1.23456789", "1"}, {"0.00000005", "1"}, {"-3.5", "2"},
+		{"10", "3"}, {"1", "7"}, {"-10", "3"}, {"10", "0"}, {"abc", "5"}, {"123456.7654321", "0.3333333"},
+	}
+	for _, p := range pairs {
+		if got, want := MultiplyAmount(p[0], p[1]), MultiplyWithPrecision(p[0], p[1]); got != want {
+			t.Errorf("MultiplyAmount(%q, %q) = %q, MultiplyWithPrecision = %q", p[0], p[1], got, want)
+		}
+		if got, want := DividePrice(p[0], p[1]), DivideWithPrecision(p[0], p[1]); got != want {
+			t.Errorf("DividePrice(%q, %q) = %q, DivideWithPrecision = %q", p[0], p[1], got, want)
+		}
+		if got, want := Multiply(p[0], p[1], AmountPrecision), MultiplyWithPrecision(p[0], p[1]); got != want {
+			t.Errorf("Multiply(%q, %q, AmountPrecision) = %q, want %q", p[0], p[1], got, want)
+		}
+	}
+	if got := MultiplyAmount("0.12345678", "1"); got != "0.1234568" {
+		t.Errorf("MultiplyAmount rounds to a stroop: got %q", got)
+	}
+}
+
+func TestRatioAndPercent(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"ratio keeps what 7 places would round away", DivideRatio("1", "30000000"), "0.000000033333"},
+		{"ratio of equals", DivideRatio("2.5", "2.5"), "1"},
+		{"ratio by zero", DivideRatio("1", "0"), "0"},
+		{"percent", Percent("1", "3"), "33.3333"},
+		{"small percent", Percent("1", "300000"), "0.0003"},
+		{"percent of zero", Percent("5", "0"), "0"},
+		{"negative percent", Percent("-1", "8"), "-12.5"},
+		{"explicit precision", Divide("2", "3", 2), "0.67"},
+		{"explicit precision multiply", Multiply("1.005", "1", 2), "1.01"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,15 @@ type TokenBalance struct {
 	Limit   string    `json:"limit,omitempty"`
 }
 
+// IgnoredToken is a balance left out of valuation by the token filter, kept
+// in the snapshot so the exclusion stays visible. Rule is the matching
+// exclude rule, or empty when the token is not on the include list.
+type IgnoredToken struct {
+	Asset   AssetInfo `json:"asset"`
+	Balance string    `json:"balance"`
+	Rule    string    `json:"rule,omitempty"`
+}
+
 // AccountPortfolio holds the raw balances for a Stellar account.
 type AccountPortfolio struct {
 	AccountID  string         `json:"accountId"`
@@ -26,4 +35,6 @@ type TokenPriceWithBalance struct {
 	DetailsXLM          *PriceDetails `json:"detailsXLM,omitempty"`
 	IsNFT               bool          `json:"isNFT,omitempty"`
 	NFTValuationAccount string        `json:"nftValuationAccount,omitempty"`
+	// Valuation is the manual valuation that priced the token, if any.
+	Valuation *ResolvedAssetValuation `json:"valuation,omitempty"`
 }
@@ -8,12 +8,27 @@ type PriceSource struct {
 	Bid *string `json:"bid"`
 }
 
-// OrderbookData combines traditional orderbook and AMM price data.
+// OrderbookData combines traditional orderbook and AMM price data. AMM holds
+// the best execution prices across AMMPools; AMMPoolID is the pool of the bid.
 type OrderbookData struct {
-	Orderbook  PriceSource `json:"orderbook"`
-	AMM        PriceSource `json:"amm"`
-	AMMPoolID  *string     `json:"poolId,omitempty"`
-	BestSource string      `json:"bestSource"` // "orderbook", "amm", or "none"
+	Orderbook  PriceSource    `json:"orderbook"`
+	AMM        PriceSource    `json:"amm"`
+	AMMPoolID  *string        `json:"poolId,omitempty"`
+	AMMPools   []AMMPoolQuote `json:"pools,omitempty"`
+	BestSource string         `json:"bestSource"` // "orderbook", "amm", or "none"
+}
+
+// AMMPoolQuote is one constant-product pool's contribution to an AMM price.
+// Bid is the execution price for selling the traded amount into the pool,
+// Ask for buying it out, both after the pool fee; Spot is the reserve ratio.
+type AMMPoolQuote struct {
+	ID            string  `json:"id"`
+	FeeBP         int     `json:"feeBp"`
+	ReserveSource string  `json:"reserveSource"`
+	ReserveDest   string  `json:"reserveDest"`
+	Spot          string  `json:"spot"`
+	Bid           *string `json:"bid"`
+	Ask           *string `json:"ask"` // nil when the pool can't supply the amount
 }
 
 // PathHop represents a single hop in a path finding route.
@@ -24,19 +39,25 @@ type PathHop struct {
 }
 
 // PriceDetails is a concrete struct representing price source metadata.
-// The Source field discriminates between "path", "orderbook", and "best".
+// The Source field discriminates between "path", "orderbook", "best",
+// "history" and "previous".
 type PriceDetails struct {
-	Source            string         `json:"source"`                      // "path", "orderbook", or "best"
+	Source            string         `json:"source"`                      // "path", "orderbook", "best", "history" or "previous"
 	PriceType         string         `json:"priceType,omitempty"`         // "bid" or "ask"
 	SourceAmount      *string        `json:"sourceAmount,omitempty"`      // path
 	DestinationAmount *string        `json:"destinationAmount,omitempty"` // path
 	Path              []PathHop      `json:"path,omitempty"`              // path
+	Bridge            string         `json:"bridge,omitempty"`            // path: bridge asset of a composite route
 	OrderbookData     *OrderbookData `json:"orderbookData,omitempty"`     // orderbook
 	PathPrice         *string        `json:"pathPrice,omitempty"`         // best
 	OrderbookPrice    *string        `json:"orderbookPrice,omitempty"`    // best
 	ChosenSource      string         `json:"chosenSource,omitempty"`      // best: "path" or "orderbook"
 	PathSubDetails    *PriceDetails  `json:"pathDetails,omitempty"`       // best
 	OBSubDetails      *PriceDetails  `json:"orderbookDetails,omitempty"`  // best
+	ClosedAt          *string        `json:"closedAt,omitempty"`          // history: trading day of the close used (YYYY-MM-DD)
+	RejectedPrice     *string        `json:"rejectedPrice,omitempty"`     // previous: the discovered price outside the sanity bound
+	RejectedDetails   *PriceDetails  `json:"rejectedDetails,omitempty"`   // previous: how the rejected price was discovered
+	Detached          bool           `json:"detached,omitempty"`          // stored snapshot stub: the rest is in snapshot_details
 }
 
 // TokenPairPrice represents the price relationship between two tokens.
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 // ValuationType distinguishes between total-price and per-unit valuations.
 type ValuationType string
 
@@ -22,7 +28,7 @@ type ValuationValue struct {
 	Value    string             `json:"value,omitempty"`    // For eurmtl type
 	Symbol   string             `json:"symbol,omitempty"`   // For external type: BTC, ETH, XLM, Sats, USD, AU
 	Quantity *float64           `json:"quantity,omitempty"` // For compound external values (e.g., AU 1g)
-	Unit     string             `json:"unit,omitempty"`     // g, oz
+	Unit     string             `json:"unit,omitempty"`     // g, oz; m2 for property registry valuations
 }
 
 // AssetValuation represents a manual valuation read from a Stellar DATA entry.
@@ -36,5 +42,14 @@ type AssetValuation struct {
 // ResolvedAssetValuation extends AssetValuation with a resolved EUR/EURMTL price.
 type ResolvedAssetValuation struct {
 	AssetValuation
-	ValueInEURMTL string `json:"valueInEURMTL"`
+	ValueInEURMTL string      `json:"valueInEURMTL"`
+	Quote         *QuoteUsage `json:"quote,omitempty"` // set for external values
+}
+
+// QuoteUsage records the external quote a valuation was resolved with, so a
+// stored snapshot stays auditable after the quote is refreshed.
+type QuoteUsage struct {
+	Symbol     string          `json:"symbol"`
+	PriceInEUR decimal.Decimal `json:"priceInEur"`
+	FetchedAt  time.Time       `json:"fetchedAt"`
 }
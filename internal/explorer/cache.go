@@ -0,0 +1,56 @@
+package explorer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+type cacheEntry struct {
+	ops       []horizon.AccountOperation
+	expiresAt time.Time
+}
+
+// pageCache holds raw Horizon pages keyed by "{account}|{order}|{cursor}".
+// Pages after a cursor never change, but the first page of a desc listing
+// does, which is what the TTL is for.
+type pageCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newPageCache(ttl time.Duration) *pageCache {
+	return &pageCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *pageCache) get(key string) ([]horizon.AccountOperation, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		return nil, false
+	}
+	return entry.ops, true
+}
+
+// set stores a page and evicts expired entries, so keys of abandoned cursors
+// don't accumulate.
+func (c *pageCache) set(key string, ops []horizon.AccountOperation) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{ops: ops, expiresAt: now.Add(c.ttl)}
+}
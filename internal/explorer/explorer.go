@@ -0,0 +1,208 @@
+// Package explorer serves the recent operations of the fund accounts from
+// Horizon with their transaction memos, classified and filtered for the
+// dashboard. Raw Horizon pages are cached briefly so that many clients
+// viewing the same account cost one upstream request.
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Category groups operation types.
+type Category string
+
+const (
+	CategoryPayment   Category = "payment"
+	CategoryTrade     Category = "trade"
+	CategoryTrustline Category = "trustline"
+	CategoryLiquidity Category = "liquidity"
+	CategoryData      Category = "data"
+	CategoryAccount   Category = "account"
+	CategoryOther     Category = "other"
+)
+
+// Categories lists the valid categories.
+var Categories = []Category{
+	CategoryPayment, CategoryTrade, CategoryTrustline, CategoryLiquidity, CategoryData, CategoryAccount, CategoryOther,
+}
+
+// CategoryOf returns the category of a Horizon operation type.
+func CategoryOf(opType string) Category {
+	switch opType {
+	case "payment", "path_payment_strict_send", "path_payment_strict_receive", "create_account", "account_merge",
+		"create_claimable_balance", "claim_claimable_balance", "clawback":
+		return CategoryPayment
+	case "manage_sell_offer", "manage_buy_offer", "create_passive_sell_offer":
+		return CategoryTrade
+	case "change_trust", "allow_trust", "set_trust_line_flags":
+		return CategoryTrustline
+	case "liquidity_pool_deposit", "liquidity_pool_withdraw":
+		return CategoryLiquidity
+	case "manage_data":
+		return CategoryData
+	case "set_options", "bump_sequence", "begin_sponsoring_future_reserves", "end_sponsoring_future_reserves", "revoke_sponsorship":
+		return CategoryAccount
+	}
+	return CategoryOther
+}
+
+// Direction is the side of the account in a transfer.
+type Direction string
+
+const (
+	In  Direction = "in"
+	Out Direction = "out"
+)
+
+// Operation is one operation of an account. Assets are snapshot.AssetKey
+// strings (XLM or CODE-ISSUER); amounts and prices are as Horizon reports them.
+type Operation struct {
+	ID           string    `json:"id"` // paging token, usable as a cursor
+	Type         string    `json:"type"`
+	Category     Category  `json:"category"`
+	Direction    Direction `json:"direction,omitempty"` // transfers only
+	Source       string    `json:"source"`
+	From         string    `json:"from,omitempty"`
+	To           string    `json:"to,omitempty"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Asset        string    `json:"asset,omitempty"`
+	Amount       string    `json:"amount,omitempty"`
+	Selling      string    `json:"selling,omitempty"`
+	Buying       string    `json:"buying,omitempty"`
+	Price        string    `json:"price,omitempty"`
+	DataName     string    `json:"dataName,omitempty"`
+	Memo         string    `json:"memo"`
+	MemoType     string    `json:"memoType,omitempty"`
+	TxHash       string    `json:"txHash"`
+	At           time.Time `json:"at"`
+}
+
+// FromHorizon converts an operation of account.
+func FromHorizon(account string, op horizon.AccountOperation) Operation {
+	o := Operation{
+		ID: op.ID, Type: op.Type, Category: CategoryOf(op.Type), Source: op.Source, From: op.From, To: op.To,
+		Asset: assetKey(op.Asset), Amount: op.Amount, Selling: assetKey(op.Selling), Buying: assetKey(op.Buying),
+		Price: op.Price, DataName: op.DataName, Memo: op.Memo, MemoType: op.MemoType, TxHash: op.TxHash, At: op.TS,
+	}
+	if o.From != "" && o.To != "" && o.From != o.To {
+		switch account {
+		case o.To:
+			o.Direction, o.Counterparty = In, o.From
+		case o.From:
+			o.Direction, o.Counterparty = Out, o.To
+		}
+	}
+	return o
+}
+
+func assetKey(a *domain.AssetInfo) string {
+	if a == nil {
+		return ""
+	}
+	return snapshot.AssetKey(*a)
+}
+
+// Query selects operations of one account. Empty filters match everything.
+type Query struct {
+	Account   string
+	Cursor    string // paging token to continue after; "" starts at the newest (desc) or oldest (asc)
+	Order     string // "asc" or "desc"
+	Limit     int
+	Asset     string // snapshot.AssetKey; matches the transferred, sold or bought asset
+	Direction Direction
+	Category  Category
+}
+
+// Matches reports whether op passes the filters of q.
+func (q Query) Matches(op Operation) bool {
+	if q.Category != "" && op.Category != q.Category {
+		return false
+	}
+	if q.Direction != "" && op.Direction != q.Direction {
+		return false
+	}
+	if q.Asset != "" && op.Asset != q.Asset && op.Selling != q.Asset && op.Buying != q.Asset {
+		return false
+	}
+	return true
+}
+
+// Page is one page of matching operations. Next is the cursor of the
+// following page, empty when the account's history is exhausted.
+type Page struct {
+	Records []Operation `json:"records"`
+	Next    string      `json:"next"`
+}
+
+// OperationSource fetches raw operation pages (horizon.Client).
+type OperationSource interface {
+	FetchAccountOperations(ctx context.Context, account, cursor, order string, limit int) ([]horizon.AccountOperation, error)
+}
+
+// Horizon pages are always fetched at full size so that every query on an
+// account shares the cached pages; a filtered query scans at most
+// maxScanPages of them before returning what it has found.
+const (
+	horizonPageSize = 200
+	maxScanPages    = 5
+)
+
+// Service lists account operations through a page cache.
+type Service struct {
+	source OperationSource
+	cache  *pageCache
+}
+
+// NewService creates a Service caching Horizon pages for ttl.
+func NewService(source OperationSource, ttl time.Duration) *Service {
+	return &Service{source: source, cache: newPageCache(ttl)}
+}
+
+// List returns up to q.Limit operations matching q after q.Cursor. When the
+// scan budget runs out before the page fills, the partial page is returned
+// with Next set to the last scanned operation, so the client continues from
+// there.
+func (s *Service) List(ctx context.Context, q Query) (Page, error) {
+	page := Page{Records: []Operation{}}
+	cursor := q.Cursor
+	for range maxScanPages {
+		ops, err := s.fetch(ctx, q.Account, cursor, q.Order)
+		if err != nil {
+			return Page{}, err
+		}
+		for _, raw := range ops {
+			cursor = raw.ID
+			if op := FromHorizon(q.Account, raw); q.Matches(op) {
+				page.Records = append(page.Records, op)
+				if len(page.Records) == q.Limit {
+					page.Next = cursor
+					return page, nil
+				}
+			}
+		}
+		if len(ops) < horizonPageSize {
+			return page, nil
+		}
+	}
+	page.Next = cursor
+	return page, nil
+}
+
+func (s *Service) fetch(ctx context.Context, account, cursor, order string) ([]horizon.AccountOperation, error) {
+	key := fmt.Sprintf("%s|%s|%s", account, order, cursor)
+	if ops, ok := s.cache.get(key); ok {
+		return ops, nil
+	}
+	ops, err := s.source.FetchAccountOperations(ctx, account, cursor, order, horizonPageSize)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.set(key, ops)
+	return ops, nil
+}
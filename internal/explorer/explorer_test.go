@@ -0,0 +1,104 @@
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+var mtl = domain.NewAssetInfo("MTL", domain.IssuerAddress)
+
+// stubSource serves a desc history of n operations with paging tokens n..1:
+// odd tokens are incoming MTL payments, even ones XLM sell offers.
+type stubSource struct {
+	n     int
+	calls int
+}
+
+func (s *stubSource) FetchAccountOperations(_ context.Context, _, cursor, order string, limit int) ([]horizon.AccountOperation, error) {
+	s.calls++
+	if order != "desc" {
+		return nil, fmt.Errorf("unexpected order %q", order)
+	}
+	start := s.n
+	if cursor != "" {
+		c, _ := strconv.Atoi(cursor)
+		start = c - 1
+	}
+	xlm := domain.XLMAsset()
+	var out []horizon.AccountOperation
+	for id := start; id >= 1 && len(out) < limit; id-- {
+		op := horizon.AccountOperation{ID: strconv.Itoa(id), Type: "manage_sell_offer", Source: "GFUND", Selling: &xlm, Buying: &mtl}
+		if id%2 == 1 {
+			op = horizon.AccountOperation{ID: strconv.Itoa(id), Type: "payment", Source: "GX", From: "GX", To: "GFUND", Asset: &mtl, Amount: "1"}
+		}
+		out = append(out, op)
+	}
+	return out, nil
+}
+
+func TestCategoryOf(t *testing.T) {
+	for opType, want := range map[string]Category{
+		"path_payment_strict_send": CategoryPayment,
+		"manage_buy_offer":         CategoryTrade,
+		"change_trust":             CategoryTrustline,
+		"liquidity_pool_deposit":   CategoryLiquidity,
+		"manage_data":              CategoryData,
+		"set_options":              CategoryAccount,
+		"invoke_host_function":     CategoryOther,
+	} {
+		if got := CategoryOf(opType); got != want {
+			t.Errorf("CategoryOf(%q) = %q, want %q", opType, got, want)
+		}
+	}
+}
+
+func TestFromHorizonDirection(t *testing.T) {
+	out := FromHorizon("GFUND", horizon.AccountOperation{Type: "payment", From: "GFUND", To: "GX", Asset: &mtl})
+	if out.Direction != Out || out.Counterparty != "GX" || out.Asset != "MTL-"+domain.IssuerAddress {
+		t.Errorf("outgoing = %+v", out)
+	}
+	if op := FromHorizon("GFUND", horizon.AccountOperation{Type: "manage_data", Source: "GFUND"}); op.Direction != "" {
+		t.Errorf("manage_data has direction %q", op.Direction)
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	src := &stubSource{n: 500}
+	svc := NewService(src, time.Minute)
+	ctx := context.Background()
+
+	page, err := svc.List(ctx, Query{Account: "GFUND", Order: "desc", Limit: 3, Direction: In})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 3 || page.Records[0].ID != "499" || page.Records[2].ID != "495" || page.Next != "495" {
+		t.Errorf("page = %+v", page)
+	}
+
+	// The next page continues after the returned cursor.
+	page, err = svc.List(ctx, Query{Account: "GFUND", Order: "desc", Limit: 2, Cursor: page.Next, Direction: In})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 2 || page.Records[0].ID != "493" {
+		t.Errorf("second page = %+v", page)
+	}
+	if src.calls != 2 { // first page, then the one after cursor 495
+		t.Errorf("horizon calls = %d, want 2", src.calls)
+	}
+
+	// Running out of history ends the listing.
+	page, err = svc.List(ctx, Query{Account: "GFUND", Order: "desc", Limit: 200, Cursor: "5", Category: CategoryTrade})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 2 || page.Next != "" || page.Records[0].Selling != "XLM" {
+		t.Errorf("last page = %+v", page)
+	}
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/mtlprog/stat/internal/fault"
+)
+
+// MonitoringSheet is the sheet the export appends to. The other sheets are
+// rewritten whole and created when missing; MONITORING holds the history,
+// so an export expects it to be there already.
+const MonitoringSheet = "MONITORING"
+
+// Reasons of an AccessError.
+const (
+	AccessNotShared     = "not_shared"     // the spreadsheet can't be opened
+	AccessReadOnly      = "read_only"      // it opens but can't be edited
+	AccessMissingSheets = "missing_sheets" // required sheets don't exist
+)
+
+// AccessError is a spreadsheet the credentials can't export to, with what
+// to do about it.
+type AccessError struct {
+	Reason        string
+	SpreadsheetID string
+	Title         string   // empty when the spreadsheet can't be opened
+	Account       string   // service account the spreadsheet must be shared with
+	Missing       []string // AccessMissingSheets
+	Err           error    // the API error, if any
+}
+
+func (e *AccessError) Error() string {
+	who := e.Account
+	if who == "" {
+		who = "the service account of the credentials"
+	}
+	switch e.Reason {
+	case AccessNotShared:
+		return fmt.Sprintf("spreadsheet %s not found or not shared with %s: check the spreadsheet ID and share it with %s as Editor (%v)",
+			e.SpreadsheetID, who, who, e.Err)
+	case AccessReadOnly:
+		return fmt.Sprintf("%s can open spreadsheet %q but not edit it: share it with %s as Editor (%v)", who, e.Title, who, e.Err)
+	default:
+		return fmt.Sprintf("spreadsheet %q has no sheet %s: add an empty sheet with that name, or rebuild it with stat import or stat import-excel",
+			e.Title, strings.Join(e.Missing, ", "))
+	}
+}
+
+func (e *AccessError) Unwrap() error { return e.Err }
+
+// CheckAccess verifies the credentials can open and edit the spreadsheet
+// and that every required sheet exists, and returns its title. Edit access
+// is proven by rewriting the title unchanged, which leaves the spreadsheet
+// as it was. Permission problems come back as an *AccessError naming the
+// service account to share the spreadsheet with; other failures as is.
+func (w *SheetsWriter) CheckAccess(ctx context.Context, required ...string) (string, error) {
+	ss, err := w.svc.Spreadsheets.Get(w.spreadsheetID).Fields("properties.title,sheets.properties.title").Context(ctx).Do()
+	if err != nil {
+		if isDenied(err) {
+			return "", &AccessError{Reason: AccessNotShared, SpreadsheetID: w.spreadsheetID, Account: w.account, Err: err}
+		}
+		return "", fmt.Errorf("opening spreadsheet: %w", err)
+	}
+	title := ss.Properties.Title
+	_, err = w.svc.Spreadsheets.BatchUpdate(w.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			UpdateSpreadsheetProperties: &sheets.UpdateSpreadsheetPropertiesRequest{
+				Properties: &sheets.SpreadsheetProperties{Title: title},
+				Fields:     "title",
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		if isDenied(err) {
+			return title, &AccessError{Reason: AccessReadOnly, SpreadsheetID: w.spreadsheetID, Title: title, Account: w.account, Err: err}
+		}
+		return title, fmt.Errorf("checking edit access: %w", err)
+	}
+
+	existing := make(map[string]bool, len(ss.Sheets))
+	for _, s := range ss.Sheets {
+		existing[s.Properties.Title] = true
+	}
+	var missing []string
+	for _, name := range required {
+		if !existing[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return title, &AccessError{Reason: AccessMissingSheets, SpreadsheetID: w.spreadsheetID, Title: title, Account: w.account, Missing: missing}
+	}
+	return title, nil
+}
+
+// Account returns the service account email of the writer's credentials,
+// empty when they don't name one.
+func (w *SheetsWriter) Account() string {
+	return w.account
+}
+
+// isDenied reports whether err is the API refusing the caller: Sheets
+// answers 404 rather than 403 for a spreadsheet that isn't shared at all.
+func isDenied(err error) bool {
+	return fault.Is(err, fault.Auth) || fault.Is(err, fault.NotFound)
+}
+
+// clientEmail extracts client_email from service account credentials JSON.
+func clientEmail(credentialsJSON []byte) string {
+	var creds struct {
+		ClientEmail string `json:"client_email"`
+	}
+	if json.Unmarshal(credentialsJSON, &creds) != nil {
+		return ""
+	}
+	return creds.ClientEmail
+}
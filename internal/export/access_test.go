@@ -0,0 +1,91 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// accessWriter returns a writer whose spreadsheet metadata and batch update
+// calls answer with getStatus / updateStatus.
+func accessWriter(t *testing.T, getStatus, updateStatus int) *SheetsWriter {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		status := getStatus
+		if r.Method == http.MethodPost {
+			status = updateStatus
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"message":"The caller does not have permission"}}`))
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"properties":{"title":"MTL Fund"},"sheets":[{"properties":{"title":"IND_ALL"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	svc, err := sheets.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	return &SheetsWriter{spreadsheetID: "sheet-1", account: "stat@project.iam.gserviceaccount.com", svc: svc}
+}
+
+func TestCheckAccess(t *testing.T) {
+	title, err := accessWriter(t, http.StatusOK, http.StatusOK).CheckAccess(context.Background(), "IND_ALL")
+	if err != nil || title != "MTL Fund" {
+		t.Errorf("CheckAccess = %q, %v; want MTL Fund", title, err)
+	}
+}
+
+func TestCheckAccessErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		get, update  int
+		required     []string
+		reason, want string
+	}{
+		{"not shared", http.StatusNotFound, http.StatusOK, nil, AccessNotShared, "share it with stat@project.iam.gserviceaccount.com as Editor"},
+		{"viewer", http.StatusOK, http.StatusForbidden, nil, AccessReadOnly, `can open spreadsheet "MTL Fund" but not edit it`},
+		{"missing sheet", http.StatusOK, http.StatusOK, []string{MonitoringSheet, "IND_ALL"}, AccessMissingSheets, "has no sheet MONITORING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := accessWriter(t, tt.get, tt.update).CheckAccess(context.Background(), tt.required...)
+			var accessErr *AccessError
+			if !errors.As(err, &accessErr) {
+				t.Fatalf("err = %v, want *AccessError", err)
+			}
+			if accessErr.Reason != tt.reason || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %q (%s), want %s containing %q", err, accessErr.Reason, tt.reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAccessOtherFailure(t *testing.T) {
+	_, err := accessWriter(t, http.StatusInternalServerError, http.StatusOK).CheckAccess(context.Background())
+	var accessErr *AccessError
+	if err == nil || errors.As(err, &accessErr) {
+		t.Errorf("err = %v, want a plain error", err)
+	}
+}
+
+func TestClientEmail(t *testing.T) {
+	if got := clientEmail([]byte(`{"type":"service_account","client_email":"a@b"}`)); got != "a@b" {
+		t.Errorf("clientEmail = %q, want a@b", got)
+	}
+	if got := clientEmail([]byte(`not json`)); got != "" {
+		t.Errorf("clientEmail of invalid JSON = %q, want empty", got)
+	}
+}
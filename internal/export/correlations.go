@@ -0,0 +1,59 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+// buildCorrRows lays out one block per matrix: a title row, a header row of
+// asset codes, one row per asset, and a blank separator. Undefined
+// correlations are left as empty cells.
+func buildCorrRows(matrices []*analytics.Matrix) [][]any {
+	var data [][]any
+	for _, m := range matrices {
+		data = append(data, []any{fmt.Sprintf("%dd returns, %s – %s, prices in %s", m.WindowDays, m.From, m.To, m.Numeraire)})
+
+		header := make([]any, 0, len(m.Assets)+1)
+		header = append(header, "")
+		for _, a := range m.Assets {
+			header = append(header, a)
+		}
+		data = append(data, header)
+
+		for i, a := range m.Assets {
+			row := make([]any, 0, len(m.Assets)+1)
+			row = append(row, a)
+			for _, c := range m.Correlations[i] {
+				row = append(row, ptrFloat(c))
+			}
+			data = append(data, row)
+		}
+		data = append(data, []any{})
+	}
+	return data
+}
+
+// WriteCorrelations rewrites the CORR sheet with the given matrices.
+func (w *SheetsWriter) WriteCorrelations(ctx context.Context, matrices []*analytics.Matrix) error {
+	if _, err := w.ensureSheets(ctx, "CORR"); err != nil {
+		return err
+	}
+
+	if _, err := w.svc.Spreadsheets.Values.Clear(w.spreadsheetID, "CORR", &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("clearing CORR sheet: %w", err)
+	}
+
+	rows := buildCorrRows(matrices)
+	_, err := w.svc.Spreadsheets.Values.Update(w.spreadsheetID, "CORR!A1", &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing CORR sheet: %w", err)
+	}
+	w.currentRun().record("CORR", WriteRewrite, rows)
+	return nil
+}
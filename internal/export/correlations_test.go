@@ -0,0 +1,31 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+func TestBuildCorrRows(t *testing.T) {
+	one := decimal.NewFromInt(1)
+	m := &analytics.Matrix{
+		WindowDays: 90, From: "2026-01-01", To: "2026-04-01", Numeraire: "EURMTL",
+		Assets:       []string{"XLM", "EURMTL"},
+		Correlations: [][]*decimal.Decimal{{&one, nil}, {nil, nil}},
+	}
+
+	rows := buildCorrRows([]*analytics.Matrix{m, m})
+
+	// Per block: title + header + 2 asset rows + blank.
+	if len(rows) != 10 {
+		t.Fatalf("got %d rows, want 10", len(rows))
+	}
+	if rows[1][1] != "XLM" || rows[1][2] != "EURMTL" {
+		t.Errorf("header = %v", rows[1])
+	}
+	if rows[2][0] != "XLM" || rows[2][1] != 1.0 || rows[2][2] != nil {
+		t.Errorf("XLM row = %v, want [XLM 1 <nil>]", rows[2])
+	}
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// CellChange is a cell an export would write with a different value than
+// the sheet holds.
+type CellChange struct {
+	Key    string `json:"key"`    // the row: indicator ID, name or date in column A
+	Column string `json:"column"` // header of the column
+	Old    any    `json:"old"`
+	New    any    `json:"new"`
+}
+
+// SheetDiff is the difference between what an export would write to one
+// sheet and what the sheet holds, by row key (column A).
+type SheetDiff struct {
+	Sheet   string       `json:"sheet"`
+	Changed []CellChange `json:"changed"`
+	Added   []string     `json:"added"`   // keys of rows only the export has
+	Removed []string     `json:"removed"` // keys of rows only the sheet has
+	Note    string       `json:"note,omitempty"`
+}
+
+// Empty reports whether the export would leave the sheet as it is.
+func (d SheetDiff) Empty() bool {
+	return len(d.Changed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DiffPlan is what an export of one date would write.
+type DiffPlan struct {
+	Rows       []IndicatorRow
+	Failures   []indicator.Failure
+	Monitoring MonitoringReport
+	Latest     bool // IND_ALL and IND_MAIN are only rewritten for the latest date
+}
+
+// Diff reads IND_ALL, IND_MAIN and MONITORING and compares them with the
+// rows plan would write, without writing anything. The IND_MAIN date stamp
+// is left out, since it changes on every run. MONITORING is compared with
+// the existing row of the plan's date; when there is none the row shows as
+// added.
+func (w *SheetsWriter) Diff(ctx context.Context, plan DiffPlan) ([]SheetDiff, error) {
+	var diffs []SheetDiff
+	if plan.Latest {
+		resp, err := w.svc.Spreadsheets.Values.BatchGet(w.spreadsheetID).
+			Ranges("IND_ALL!A:L", "IND_MAIN!A:G").
+			ValueRenderOption("UNFORMATTED_VALUE").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, fmt.Errorf("reading IND_ALL and IND_MAIN: %w", err)
+		}
+		var current [2][][]any
+		for i, vr := range resp.ValueRanges {
+			if i < len(current) {
+				current[i] = vr.Values
+			}
+		}
+		indAll := buildIndAll(plan.Rows, plan.Failures)
+		indMain := buildIndMain(plan.Rows, time.Now(), w.locale)
+		diffs = append(diffs,
+			diffRows("IND_ALL", indAll[0], current[0], indAll),
+			diffRows("IND_MAIN", indMain[1], current[1], indMain[1:]))
+	}
+
+	sheet, err := w.ReadMonitoring(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diffs = append(diffs, diffMonitoring(sheet, plan.Monitoring, w.locale))
+	return diffs, nil
+}
+
+// diffMonitoring compares the MONITORING row of report with the sheet's
+// row of the same date. The export never rewrites an existing row, so
+// differences there only show what the current code would have written.
+func diffMonitoring(sheet [][]any, report MonitoringReport, loc Locale) SheetDiff {
+	planned := report.SheetRow(loc)
+	date := time.Date(report.Date.Year(), report.Date.Month(), report.Date.Day(), 0, 0, 0, 0, time.UTC)
+	for i, row := range sheet {
+		if i < len(MonitoringHeaderRows()) || len(row) == 0 {
+			continue
+		}
+		if d, err := loc.ParseDate(fmt.Sprint(row[0])); err != nil || !d.Equal(date) {
+			continue
+		}
+		header := append([]any{"Date"}, toAny(MonitoringColumnHeaders())...)
+		d := diffRows("MONITORING", header, [][]any{row}, [][]any{planned})
+		d.Note = fmt.Sprintf("row %d already holds %s; the export skips the append", i+1, loc.FormatDate(date))
+		return d
+	}
+	return SheetDiff{Sheet: "MONITORING", Added: []string{loc.FormatDate(date)}, Note: "the export appends this row"}
+}
+
+// diffRows matches the rows of current and planned by their first cell and
+// compares them cell by cell; header names the columns. Rows without a key
+// (blank spacers) are skipped.
+func diffRows(sheet string, header []any, current, planned [][]any) SheetDiff {
+	d := SheetDiff{Sheet: sheet, Changed: []CellChange{}, Added: []string{}, Removed: []string{}}
+	existing := make(map[string][]any, len(current))
+	for _, row := range current {
+		if key := rowKey(row); key != "" {
+			if _, dup := existing[key]; !dup {
+				existing[key] = row
+			}
+		}
+	}
+	seen := make(map[string]bool, len(planned))
+	for _, row := range planned {
+		key := rowKey(row)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		old, ok := existing[key]
+		if !ok {
+			d.Added = append(d.Added, key)
+			continue
+		}
+		for i := 1; i < max(len(row), len(old)); i++ {
+			o, n := cellAt(old, i), cellAt(row, i)
+			if !sameCell(o, n) {
+				d.Changed = append(d.Changed, CellChange{Key: key, Column: columnName(header, i), Old: o, New: n})
+			}
+		}
+	}
+	for _, row := range current {
+		if key := rowKey(row); key != "" && !seen[key] {
+			seen[key] = true
+			d.Removed = append(d.Removed, key)
+		}
+	}
+	return d
+}
+
+func rowKey(row []any) string {
+	if len(row) == 0 {
+		return ""
+	}
+	return FormatCell(row[0])
+}
+
+func cellAt(row []any, i int) any {
+	if i < len(row) {
+		return row[i]
+	}
+	return nil
+}
+
+func columnName(header []any, i int) string {
+	if name := FormatCell(cellAt(header, i)); name != "" {
+		return name
+	}
+	return columnLetter(i)
+}
+
+// FormatCell renders a cell value as the sheet would show it unformatted:
+// numbers in the shortest exact form, empty cells as "".
+func FormatCell(v any) string {
+	if f, ok := cellNumber(v); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// sameCell compares a cell read back from the sheet with one the export
+// would write. Numbers match within float rounding; nil and "" are both
+// empty.
+func sameCell(a, b any) bool {
+	fa, okA := cellNumber(a)
+	fb, okB := cellNumber(b)
+	if okA && okB {
+		return fa == fb || math.Abs(fa-fb) <= 1e-9*math.Max(math.Abs(fa), math.Abs(fb))
+	}
+	return FormatCell(a) == FormatCell(b)
+}
+
+func cellNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toAny(s []string) []any {
+	out := make([]any, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func TestDiffRowsIndAll(t *testing.T) {
+	week := decimal.RequireFromString("0.05")
+	planned := buildIndAll([]IndicatorRow{
+		{Indicator: indicator.Indicator{ID: 1, Name: "Assets", Value: decimal.RequireFromString("100.5"), Unit: "EURMTL"}, IsMain: true, WeekChange: &week},
+		{Indicator: indicator.Indicator{ID: 2, Name: "Tokens", Value: decimal.NewFromInt(7)}},
+	}, nil)
+	// As read back with UNFORMATTED_VALUE: numbers as float64, trailing
+	// empty cells dropped.
+	current := [][]any{
+		{"N", "Name", "Code", "Value", "measure", "Week", "Month", "Quarter", "Year", "Descr", "Formula", "MAIN"},
+		{float64(1), "Assets", "", 100.5, "EURMTL", 0.04, "", "", "", "", "", float64(1)},
+		{float64(3), "Old", "", float64(1)},
+		{},
+	}
+
+	d := diffRows("IND_ALL", planned[0], current, planned)
+	if len(d.Changed) != 1 || d.Changed[0] != (CellChange{Key: "1", Column: "Week", Old: 0.04, New: 0.05}) {
+		t.Errorf("changed = %+v, want only I1 Week 0.04 → 0.05", d.Changed)
+	}
+	if len(d.Added) != 1 || d.Added[0] != "2" || len(d.Removed) != 1 || d.Removed[0] != "3" {
+		t.Errorf("added %v removed %v, want I2 added and I3 removed", d.Added, d.Removed)
+	}
+
+	if d := diffRows("IND_ALL", planned[0], planned, planned); !d.Empty() {
+		t.Errorf("identical sheet: diff = %+v", d)
+	}
+}
+
+func TestDiffMonitoring(t *testing.T) {
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	report := NewMonitoringReport(date, []IndicatorRow{{Indicator: indicator.Indicator{ID: MonitoringColumnIndicatorIDs()[0], Value: decimal.NewFromInt(5)}}}, MonitoringNotes{})
+	header := MonitoringHeaderRows()
+
+	d := diffMonitoring(header, report, DefaultLocale)
+	if len(d.Added) != 1 || d.Note == "" {
+		t.Errorf("no row for the date: diff = %+v, want the row added", d)
+	}
+
+	row := report.SheetRow(DefaultLocale)
+	row[1] = float64(4)
+	d = diffMonitoring(append(header, row), report, DefaultLocale)
+	if len(d.Changed) != 1 || d.Changed[0].Column != MonitoringColumnHeaders()[0] || !sameCell(d.Changed[0].New, 5) {
+		t.Errorf("existing row: diff = %+v, want the first column 4 → 5", d)
+	}
+}
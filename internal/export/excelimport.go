@@ -0,0 +1,376 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultExcelSheet is the sheet stat import-excel reads unless told otherwise.
+const DefaultExcelSheet = "MONITORING"
+
+// headerScanRows is how many leading rows are searched for the header row.
+const headerScanRows = 10
+
+// minHeaderMatches is how many MONITORING headers a row must contain to be
+// taken for the header row.
+const minHeaderMatches = 3
+
+// ExcelImportOptions configures ReadMonitoringExcel.
+type ExcelImportOptions struct {
+	Sheet     string // DefaultExcelSheet when empty
+	HeaderRow int    // 1-based row holding the column names; 0 detects it
+}
+
+// Import issue severities. An error blocks the import; a warning is only
+// reported.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Import issue kinds.
+const (
+	IssueDuplicateDate   = "duplicate-date"   // a date already seen in an earlier row
+	IssueOutOfOrder      = "out-of-order"     // a date before the previous row's
+	IssueBadDate         = "bad-date"         // a row whose date can't be parsed; skipped
+	IssueFuzzyHeader     = "fuzzy-header"     // a column matched by a near-miss header
+	IssueUnmatchedHeader = "unmatched-header" // a workbook column with no MONITORING column; dropped
+	IssueMissingColumn   = "missing-column"   // a MONITORING column absent from the workbook; left empty
+)
+
+// ImportIssue is one finding of the validation report.
+type ImportIssue struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Row      int    `json:"row,omitempty"` // 1-based workbook row; 0 for column findings
+	Column   string `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ColumnMatch maps one workbook column onto a MONITORING column.
+type ColumnMatch struct {
+	Source      string `json:"source"` // workbook column letter
+	Header      string `json:"header"` // header text in the workbook
+	Column      string `json:"column"` // MONITORING column letter
+	Canonical   string `json:"canonical"`
+	IndicatorID int    `json:"indicatorId,omitempty"`
+	Fuzzy       bool   `json:"fuzzy,omitempty"`
+}
+
+// ImportReport is the validation report of a workbook, produced before
+// anything is written.
+type ImportReport struct {
+	Sheet            string        `json:"sheet"`
+	HeaderRow        int           `json:"headerRow"` // 1-based
+	Columns          []ColumnMatch `json:"columns"`
+	Rows             int           `json:"rows"` // data rows kept
+	FirstDate        string        `json:"firstDate,omitempty"`
+	LastDate         string        `json:"lastDate,omitempty"`
+	SuppressedErrors int           `json:"suppressedErrors"` // Excel error cells (#REF!, ...) emptied
+	Issues           []ImportIssue `json:"issues"`
+}
+
+// Errors counts the issues that block the import.
+func (r ImportReport) Errors() int {
+	n := 0
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// MonitoringImport is a workbook read into the MONITORING layout.
+type MonitoringImport struct {
+	Rows     [][]any   // MonitoringHeaderRows followed by one row per date
+	LastDate time.Time // last data date
+	Report   ImportReport
+}
+
+// ReadMonitoringExcel reads a MONITORING export from an Excel workbook. The
+// header row is detected among the first rows unless opts.HeaderRow is set,
+// and every column is mapped by its header text onto monitoringColumns,
+// exactly or with a small edit distance, so workbooks with columns added,
+// dropped or reordered still land in the right place. Rows below the header
+// become data rows in the canonical layout, dated in loc. The dates must be
+// strictly increasing; duplicates and out-of-order rows are reported as
+// errors, which the caller should refuse to write.
+func ReadMonitoringExcel(r io.Reader, opts ExcelImportOptions, loc Locale) (MonitoringImport, error) {
+	sheet := opts.Sheet
+	if sheet == "" {
+		sheet = DefaultExcelSheet
+	}
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return MonitoringImport{}, fmt.Errorf("opening workbook: %w", err)
+	}
+	defer f.Close()
+
+	xlRows, err := f.GetRows(sheet)
+	if err != nil {
+		return MonitoringImport{}, fmt.Errorf("reading %s sheet: %w", sheet, err)
+	}
+
+	headerIdx := opts.HeaderRow - 1
+	if opts.HeaderRow <= 0 {
+		if headerIdx = detectHeaderRow(xlRows); headerIdx < 0 {
+			return MonitoringImport{}, fmt.Errorf("no header row with at least %d MONITORING column names in the first %d rows of %s", minHeaderMatches, headerScanRows, sheet)
+		}
+	}
+	if headerIdx >= len(xlRows) {
+		return MonitoringImport{}, fmt.Errorf("%s sheet has %d rows, header row %d is out of range", sheet, len(xlRows), opts.HeaderRow)
+	}
+
+	report := ImportReport{Sheet: sheet, HeaderRow: headerIdx + 1, Issues: []ImportIssue{}}
+	headers := xlRows[headerIdx]
+	dateCol, mapping := matchHeaders(headers)
+	report.Columns, report.Issues = describeMapping(headers, dateCol, mapping)
+
+	out := MonitoringImport{Rows: MonitoringHeaderRows()}
+	seen := make(map[time.Time]int)
+	var prev time.Time
+	for i := headerIdx + 1; i < len(xlRows); i++ {
+		xlRow, line := xlRows[i], i+1
+		if dateCol >= len(xlRow) || strings.TrimSpace(xlRow[dateCol]) == "" {
+			continue
+		}
+		date, err := parseExcelDate(strings.TrimSpace(xlRow[dateCol]))
+		if err != nil {
+			report.Issues = append(report.Issues, ImportIssue{Severity: SeverityWarning, Kind: IssueBadDate, Row: line,
+				Message: fmt.Sprintf("row skipped: %v", err)})
+			continue
+		}
+		if first, dup := seen[date]; dup {
+			report.Issues = append(report.Issues, ImportIssue{Severity: SeverityError, Kind: IssueDuplicateDate, Row: line,
+				Message: fmt.Sprintf("%s already in row %d", date.Format("2006-01-02"), first)})
+		} else if date.Before(prev) {
+			report.Issues = append(report.Issues, ImportIssue{Severity: SeverityError, Kind: IssueOutOfOrder, Row: line,
+				Message: fmt.Sprintf("%s comes after %s", date.Format("2006-01-02"), prev.Format("2006-01-02"))})
+		}
+		seen[date] = line
+		if date.After(prev) {
+			prev = date
+		}
+
+		row := make([]any, 1+len(monitoringColumns))
+		row[0] = loc.FormatDate(date)
+		for src, col := range mapping {
+			if src >= len(xlRow) || xlRow[src] == "" {
+				continue
+			}
+			val := parseExcelNumber(xlRow[src])
+			if val == nil {
+				report.SuppressedErrors++
+			}
+			row[col+1] = val
+		}
+		out.Rows = append(out.Rows, row)
+		report.Rows++
+		if report.FirstDate == "" {
+			report.FirstDate = date.Format("2006-01-02")
+		}
+		out.LastDate = prev
+	}
+
+	if report.Rows == 0 {
+		return MonitoringImport{}, fmt.Errorf("no valid dates found below header row %d of %s", report.HeaderRow, sheet)
+	}
+	report.LastDate = out.LastDate.Format("2006-01-02")
+	if report.SuppressedErrors > 0 {
+		slog.Info("suppressed Excel error values during import",
+			"count", report.SuppressedErrors,
+			"explanation", "cells with # prefixes (e.g. #REF!, #DIV/0!) replaced with nil",
+		)
+	}
+	out.Report = report
+	return out, nil
+}
+
+// detectHeaderRow returns the index of the leading row naming the most
+// MONITORING columns, or -1 when none names minHeaderMatches.
+func detectHeaderRow(rows [][]string) int {
+	best, bestCount := -1, minHeaderMatches-1
+	for i := 0; i < len(rows) && i < headerScanRows; i++ {
+		_, mapping := matchHeaders(rows[i])
+		if len(mapping) > bestCount {
+			best, bestCount = i, len(mapping)
+		}
+	}
+	return best
+}
+
+// matchHeaders maps workbook columns (by index) onto monitoringColumns
+// indexes. The date column is the one headed "Date" or "Дата", else the
+// first. Exact matches on the normalized text are taken first; the rest go
+// to the closest unclaimed column within maxHeaderDistance.
+func matchHeaders(headers []string) (dateCol int, mapping map[int]int) {
+	canonical := make([]string, len(monitoringColumns))
+	for i, c := range monitoringColumns {
+		canonical[i] = normalizeHeader(c.header)
+	}
+
+	dateCol = 0
+	for i, h := range headers {
+		if n := normalizeHeader(h); n == "date" || n == "дата" {
+			dateCol = i
+			break
+		}
+	}
+
+	mapping = make(map[int]int)
+	claimed := make(map[int]bool)
+	for i, h := range headers {
+		n := normalizeHeader(h)
+		if i == dateCol || n == "" {
+			continue
+		}
+		for j, c := range canonical {
+			if !claimed[j] && c == n {
+				mapping[i], claimed[j] = j, true
+				break
+			}
+		}
+	}
+	for i, h := range headers {
+		n := normalizeHeader(h)
+		if _, done := mapping[i]; done || i == dateCol || n == "" {
+			continue
+		}
+		best, bestDist := -1, maxHeaderDistance(n)+1
+		for j, c := range canonical {
+			if claimed[j] {
+				continue
+			}
+			if d := levenshtein(n, c); d < bestDist {
+				best, bestDist = j, d
+			}
+		}
+		if best >= 0 {
+			mapping[i], claimed[best] = best, true
+		}
+	}
+	return dateCol, mapping
+}
+
+// describeMapping lists the matched columns in workbook order and the
+// header findings.
+func describeMapping(headers []string, dateCol int, mapping map[int]int) ([]ColumnMatch, []ImportIssue) {
+	matches := []ColumnMatch{}
+	issues := []ImportIssue{}
+	mapped := make(map[int]bool, len(mapping))
+	for i, h := range headers {
+		col, ok := mapping[i]
+		if !ok {
+			if i != dateCol && strings.TrimSpace(h) != "" {
+				issues = append(issues, ImportIssue{Severity: SeverityWarning, Kind: IssueUnmatchedHeader, Column: columnLetter(i),
+					Message: fmt.Sprintf("%q matches no MONITORING column and is dropped", h)})
+			}
+			continue
+		}
+		mapped[col] = true
+		m := ColumnMatch{
+			Source:      columnLetter(i),
+			Header:      h,
+			Column:      columnLetter(col + 1),
+			Canonical:   monitoringColumns[col].header,
+			IndicatorID: monitoringColumns[col].indicatorID,
+			Fuzzy:       normalizeHeader(h) != normalizeHeader(monitoringColumns[col].header),
+		}
+		if m.Fuzzy {
+			issues = append(issues, ImportIssue{Severity: SeverityWarning, Kind: IssueFuzzyHeader, Column: m.Source,
+				Message: fmt.Sprintf("%q taken for %q", h, m.Canonical)})
+		}
+		matches = append(matches, m)
+	}
+	for j, c := range monitoringColumns {
+		if !mapped[j] {
+			issues = append(issues, ImportIssue{Severity: SeverityWarning, Kind: IssueMissingColumn, Column: columnLetter(j + 1),
+				Message: fmt.Sprintf("%q not in the workbook, left empty", c.header)})
+		}
+	}
+	return matches, issues
+}
+
+// normalizeHeader lowercases s and reduces it to words of letters and
+// digits separated by single spaces.
+func normalizeHeader(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// maxHeaderDistance is the edit distance a header may be from its
+// MONITORING name: a typo per eight characters, at most three.
+func maxHeaderDistance(s string) int {
+	return min(len([]rune(s))/8, 3)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// columnLetter returns the sheet letter of a 0-based column index.
+func columnLetter(i int) string {
+	name, _ := excelize.ColumnNumberToName(i + 1)
+	return name
+}
+
+// parseExcelNumber tries to convert a string to a float64, stripping commas
+// from formatted numbers. Excel error values (#REF!, #DIV/0!, #N/A, etc.)
+// are replaced with nil to avoid Google Sheets interpreting them as errors.
+// Returns the original string if not a number and not an error.
+func parseExcelNumber(s string) any {
+	if strings.HasPrefix(s, "#") {
+		return nil
+	}
+	cleaned := strings.ReplaceAll(s, ",", "")
+	d, err := decimal.NewFromString(cleaned)
+	if err != nil {
+		return s
+	}
+	f, _ := d.Float64()
+	return f
+}
+
+// parseExcelDate parses date strings in formats used by excelize output.
+func parseExcelDate(s string) (time.Time, error) {
+	for _, layout := range []string{
+		"02.01.2006", // dd.mm.yyyy — known MONITORING format
+		"2006-01-02", // ISO
+		"2006-01-02T15:04:05Z",
+		"01-02-06",   // MM-DD-YY
+		"1/2/06",     // US short
+		"1/2/2006",   // US long
+		"01-02-2006", // MM-DD-YYYY
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse date: %q", s)
+}
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// workbook builds an in-memory xlsx with rows written from A1 on sheet.
+func workbook(t *testing.T, sheet string, rows [][]any) *bytes.Buffer {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	if sheet != "Sheet1" {
+		if err := f.SetSheetName("Sheet1", sheet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func issueKinds(r ImportReport) map[string]int {
+	kinds := make(map[string]int)
+	for _, is := range r.Issues {
+		kinds[is.Kind]++
+	}
+	return kinds
+}
+
+func TestReadMonitoringExcelMapsByHeader(t *testing.T) {
+	// A title row above the headers, columns reordered, one typo and one
+	// column stat doesn't know.
+	buf := workbook(t, "Data", [][]any{
+		{"MTL report"},
+		{"Дата", "Shares", "Market Cap  EUR", "Totl Balance", "Comments"},
+		{"01.03.2026", "1,200", "4020758.5", "#REF!", "ok"},
+		{"02.03.2026", "1,210", "4030000", "2033307", ""},
+	})
+
+	got, err := ReadMonitoringExcel(buf, ExcelImportOptions{Sheet: "Data"}, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := got.Report
+	if r.HeaderRow != 2 || r.Rows != 2 || r.FirstDate != "2026-03-01" || r.LastDate != "2026-03-02" {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.Errors() != 0 || r.SuppressedErrors != 1 {
+		t.Fatalf("errors = %d, suppressed = %d", r.Errors(), r.SuppressedErrors)
+	}
+	if len(r.Columns) != 3 {
+		t.Fatalf("columns = %+v", r.Columns)
+	}
+	kinds := issueKinds(r)
+	if kinds[IssueFuzzyHeader] != 1 || kinds[IssueUnmatchedHeader] != 1 || kinds[IssueMissingColumn] != len(monitoringColumns)-3 {
+		t.Fatalf("issues = %v", kinds)
+	}
+
+	if len(got.Rows) != 4 {
+		t.Fatalf("rows = %d, want 2 headers + 2 data", len(got.Rows))
+	}
+	ids := MonitoringColumnIndicatorIDs()
+	row := got.Rows[3]
+	if row[0] != "02.03.2026" {
+		t.Errorf("date = %v", row[0])
+	}
+	for i, id := range ids {
+		switch id {
+		case 1:
+			if row[i+1] != 4030000.0 {
+				t.Errorf("Market Cap EUR = %v", row[i+1])
+			}
+		case 3:
+			if row[i+1] != 2033307.0 {
+				t.Errorf("Total Balance = %v", row[i+1])
+			}
+		case 5:
+			if row[i+1] != 1210.0 {
+				t.Errorf("Shares = %v", row[i+1])
+			}
+		}
+	}
+}
+
+func TestReadMonitoringExcelReportsOrderErrors(t *testing.T) {
+	buf := workbook(t, DefaultExcelSheet, [][]any{
+		{"", 1, 3, 5},
+		{"Date", "Market Cap EUR", "Total Balance", "Shares"},
+		{"01.03.2026", 1, 2, 3},
+		{"03.03.2026", 1, 2, 3},
+		{"02.03.2026", 1, 2, 3},
+		{"03.03.2026", 1, 2, 3},
+		{"not a date", 1, 2, 3},
+	})
+
+	got, err := ReadMonitoringExcel(buf, ExcelImportOptions{}, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := got.Report
+	kinds := issueKinds(r)
+	if kinds[IssueOutOfOrder] != 1 || kinds[IssueDuplicateDate] != 1 || kinds[IssueBadDate] != 1 {
+		t.Fatalf("issues = %+v", r.Issues)
+	}
+	if r.Errors() != 2 {
+		t.Errorf("errors = %d, want 2", r.Errors())
+	}
+	if r.LastDate != "2026-03-03" {
+		t.Errorf("last date = %s", r.LastDate)
+	}
+}
+
+func TestReadMonitoringExcelHeaderRow(t *testing.T) {
+	rows := [][]any{
+		{"Date", "Market Cap EUR"},
+		{"01.03.2026", 1},
+	}
+	if _, err := ReadMonitoringExcel(workbook(t, DefaultExcelSheet, rows), ExcelImportOptions{}, DefaultLocale); err == nil {
+		t.Error("expected detection to fail with a single known header")
+	}
+	got, err := ReadMonitoringExcel(workbook(t, DefaultExcelSheet, rows), ExcelImportOptions{HeaderRow: 1}, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Report.Rows != 1 || len(got.Report.Columns) != 1 {
+		t.Errorf("report = %+v", got.Report)
+	}
+	if _, err := ReadMonitoringExcel(workbook(t, "Other", rows), ExcelImportOptions{HeaderRow: 1}, DefaultLocale); err == nil {
+		t.Error("expected a missing sheet error")
+	}
+}
+
+func TestNormalizeHeader(t *testing.T) {
+	if got := normalizeHeader("  MTL  in circulation (EURMTL)"); got != "mtl in circulation eurmtl" {
+		t.Errorf("normalizeHeader = %q", got)
+	}
+	if d := levenshtein("totl balance", "total balance"); d != 1 {
+		t.Errorf("levenshtein = %d", d)
+	}
+}
@@ -10,16 +10,10 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/period"
+	"github.com/mtlprog/stat/internal/snapdate"
 )
 
-// mainIndicatorIDs is the set of indicator IDs that appear in the IND_MAIN sheet.
-// Based on the MTL_report_1.xlsx example file.
-var mainIndicatorIDs = map[int]bool{
-	1: true, 2: true, 3: true, 4: true, 5: true, 6: true, 7: true,
-	8: true, 10: true, 11: true, 15: true, 16: true, 17: true, 18: true,
-	22: true, 24: true, 27: true, 30: true, 40: true,
-}
-
 // IndicatorRow holds a computed indicator with historical period changes.
 type IndicatorRow struct {
 	indicator.Indicator
@@ -30,9 +24,10 @@ type IndicatorRow struct {
 	IsMain        bool
 }
 
-// SheetWriter writes indicator rows to a spreadsheet destination.
+// SheetWriter writes indicator rows, plus any calculator failures from a
+// partial run, to a spreadsheet destination.
 type SheetWriter interface {
-	Write(ctx context.Context, rows []IndicatorRow) error
+	Write(ctx context.Context, rows []IndicatorRow, failures []indicator.Failure) error
 }
 
 // IndicatorHistory exposes the slice of repository methods the export service
@@ -49,24 +44,71 @@ type Service struct {
 	history IndicatorHistory
 	writer  SheetWriter
 	slug    string
+	clock   snapdate.Clock
+	compare period.Comparison
+	main    MainIndicatorSource // nil: DefaultMainIndicatorIDs
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithClock measures the 7/30/90/365-day comparisons back from clock's
+// current snapshot date instead of the UTC date.
+func WithClock(c snapdate.Clock) ServiceOption {
+	return func(s *Service) {
+		s.clock = c
+	}
+}
+
+// WithComparison sets the baselines of the Week/Month/Quarter/Year columns:
+// period.Rolling (the default) compares with 7/30/90/365 days earlier,
+// period.Calendar with the previous week-, month-, quarter- and year-end.
+func WithComparison(c period.Comparison) ServiceOption {
+	return func(s *Service) {
+		s.compare = c
+	}
 }
 
 // NewService creates a new export Service.
-func NewService(history IndicatorHistory, writer SheetWriter) *Service {
-	return &Service{history: history, writer: writer, slug: "mtlf"}
+func NewService(history IndicatorHistory, writer SheetWriter, opts ...ServiceOption) *Service {
+	s := &Service{history: history, writer: writer, slug: "mtlf", compare: period.Rolling}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Export writes IND_ALL/IND_MAIN with historical comparisons read from the
 // indicator repository.
 func (s *Service) Export(ctx context.Context, current []indicator.Indicator) ([]IndicatorRow, error) {
-	return s.exportRows(ctx, current, nil)
+	return s.exportRows(ctx, s.clock.Today(), current, nil, nil)
+}
+
+// ExportPartial works like Export for a partial calculation: unavailable
+// indicators have no row, and the failures are written as an errors section.
+func (s *Service) ExportPartial(ctx context.Context, res indicator.PartialResult) ([]IndicatorRow, error) {
+	return s.exportRows(ctx, s.clock.Today(), res.Indicators, res.Failures, nil)
+}
+
+// ExportPartialAt works like ExportPartial for the indicators of date, with
+// the period changes measured back from date instead of today. Use it to
+// export a stored snapshot later than the day it was taken.
+func (s *Service) ExportPartialAt(ctx context.Context, date time.Time, res indicator.PartialResult) ([]IndicatorRow, error) {
+	return s.exportRows(ctx, date, res.Indicators, res.Failures, nil)
+}
+
+// Rows returns the rows of the indicators of date, with the period changes
+// measured back from date, without writing IND_ALL/IND_MAIN. It feeds the
+// MONITORING row of a snapshot that is no longer the latest.
+func (s *Service) Rows(ctx context.Context, date time.Time, current []indicator.Indicator) []IndicatorRow {
+	return s.buildRows(ctx, date, current, nil)
 }
 
 // ExportWithHistory works like Export but fills gaps in historical data from monHist
 // when DB indicators are unavailable. Use this for import-excel where the DB has few
 // indicator rows but the Excel MONITORING sheet has full history.
 func (s *Service) ExportWithHistory(ctx context.Context, current []indicator.Indicator, monHist MonitoringHistory) ([]IndicatorRow, error) {
-	return s.exportRows(ctx, current, monHist)
+	return s.exportRows(ctx, s.clock.Today(), current, nil, monHist)
 }
 
 // MonitoringHistory maps dates to indicator values extracted from MONITORING sheet rows.
@@ -90,20 +132,38 @@ func (mh MonitoringHistory) NearestBefore(target time.Time) map[int]indicator.In
 	vals := mh[best]
 	result := make(map[int]indicator.Indicator, len(vals))
 	for id, v := range vals {
-		result[id] = indicator.Indicator{ID: id, Value: v}
+		result[id] = indicator.Round(indicator.Indicator{ID: id, Value: v})
 	}
 	return result
 }
 
-// fetchHistorical retrieves persisted indicator sets at-or-before each
-// (today − days) target. Reads from fund_indicators only; no recomputation,
+// fetchHistorical retrieves persisted indicator sets at-or-before the
+// baseline of each period (now − days when rolling). Reads from fund_indicators only; no recomputation,
+// mainIndicators returns the IND_MAIN set. When it can't be read, the
+// default set is used so the export still goes out.
+func (s *Service) mainIndicators(ctx context.Context) map[int]bool {
+	ids := DefaultMainIndicatorIDs
+	if s.main != nil {
+		configured, err := s.main.MainIndicators(ctx, s.slug)
+		if err != nil {
+			slog.Error("IND_MAIN set unavailable, using the default", "error", err)
+		} else {
+			ids = configured
+		}
+	}
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
 // no Horizon traffic.
-func (s *Service) fetchHistorical(ctx context.Context, periods []int) map[int]map[int]indicator.Indicator {
+func (s *Service) fetchHistorical(ctx context.Context, now time.Time, periods []int) map[int]map[int]indicator.Indicator {
 	result := make(map[int]map[int]indicator.Indicator, len(periods))
-	now := time.Now().UTC()
 
 	for _, days := range periods {
-		pastDate := now.AddDate(0, 0, -days)
+		pastDate := s.compare.Baseline(now, days)
 		hist, err := s.history.GetNearestBefore(ctx, s.slug, pastDate)
 		if err != nil {
 			if errors.Is(err, indicator.ErrNotFound) {
@@ -121,26 +181,34 @@ func (s *Service) fetchHistorical(ctx context.Context, periods []int) map[int]ma
 	return result
 }
 
-func (s *Service) exportRows(ctx context.Context, current []indicator.Indicator, monHist MonitoringHistory) ([]IndicatorRow, error) {
-	historicalByPeriod := s.fetchHistorical(ctx, []int{7, 30, 90, 365})
+func (s *Service) exportRows(ctx context.Context, now time.Time, current []indicator.Indicator, failures []indicator.Failure, monHist MonitoringHistory) ([]IndicatorRow, error) {
+	rows := s.buildRows(ctx, now, current, monHist)
+	if err := s.writer.Write(ctx, rows, failures); err != nil {
+		return nil, fmt.Errorf("writing indicator rows: %w", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) buildRows(ctx context.Context, now time.Time, current []indicator.Indicator, monHist MonitoringHistory) []IndicatorRow {
+	historicalByPeriod := s.fetchHistorical(ctx, now, []int{7, 30, 90, 365})
 
 	// Fill gaps from monitoring history.
-	now := time.Now().UTC()
 	for _, days := range []int{7, 30, 90, 365} {
 		if historicalByPeriod[days] != nil {
 			continue
 		}
-		pastDate := now.AddDate(0, 0, -days)
+		pastDate := s.compare.Baseline(now, days)
 		if fallback := monHist.NearestBefore(pastDate); fallback != nil {
 			historicalByPeriod[days] = fallback
 		}
 	}
 
+	main := s.mainIndicators(ctx)
 	rows := make([]IndicatorRow, 0, len(current))
 	for _, ind := range current {
 		row := IndicatorRow{
 			Indicator: ind,
-			IsMain:    mainIndicatorIDs[ind.ID],
+			IsMain:    main[ind.ID],
 		}
 
 		row.WeekChange = computeChange(ind.ID, ind.Value, historicalByPeriod[7])
@@ -150,11 +218,7 @@ func (s *Service) exportRows(ctx context.Context, current []indicator.Indicator,
 
 		rows = append(rows, row)
 	}
-
-	if err := s.writer.Write(ctx, rows); err != nil {
-		return nil, fmt.Errorf("writing indicator rows: %w", err)
-	}
-	return rows, nil
+	return rows
 }
 
 // computeChange returns (current - historical) / historical, or nil if
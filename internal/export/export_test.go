@@ -9,6 +9,8 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/period"
+	"github.com/mtlprog/stat/internal/snapdate"
 )
 
 type stubHistory struct {
@@ -32,11 +34,13 @@ func (s *stubHistory) GetNearestBefore(_ context.Context, _ string, date time.Ti
 }
 
 type captureWriter struct {
-	rows []IndicatorRow
+	rows     []IndicatorRow
+	failures []indicator.Failure
 }
 
-func (w *captureWriter) Write(_ context.Context, rows []IndicatorRow) error {
+func (w *captureWriter) Write(_ context.Context, rows []IndicatorRow, failures []indicator.Failure) error {
 	w.rows = rows
+	w.failures = failures
 	return nil
 }
 
@@ -75,12 +79,39 @@ func TestExportComputesPercentChangesFromRepo(t *testing.T) {
 	}
 }
 
+// recordingHistory records the dates looked up.
+type recordingHistory struct {
+	dates []time.Time
+}
+
+func (h *recordingHistory) GetNearestBefore(_ context.Context, _ string, date time.Time) (map[int]indicator.Indicator, error) {
+	h.dates = append(h.dates, date)
+	return map[int]indicator.Indicator{1: {ID: 1, Value: decimal.NewFromInt(100)}}, nil
+}
+
+func TestRowsMeasureFromDateWithoutWriting(t *testing.T) {
+	hist := &recordingHistory{}
+	w := &captureWriter{}
+	date := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	rows := NewService(hist, w).Rows(context.Background(), date, []indicator.Indicator{{ID: 1, Value: decimal.NewFromInt(150)}})
+	if len(rows) != 1 || rows[0].WeekChange == nil || !rows[0].WeekChange.Equal(decimal.RequireFromString("0.5")) {
+		t.Fatalf("rows = %+v, want one row with WeekChange 0.5", rows)
+	}
+	if w.rows != nil {
+		t.Error("Rows wrote to the sheet")
+	}
+	if len(hist.dates) != 4 || !hist.dates[0].Equal(date.AddDate(0, 0, -7)) {
+		t.Errorf("looked up %v, want baselines measured back from %s", hist.dates, date.Format("2006-01-02"))
+	}
+}
+
 func TestExportFallsBackToMonitoringHistory(t *testing.T) {
 	hist := &stubHistory{} // no values from DB
 	w := &captureWriter{}
 	svc := NewService(hist, w)
 
-	yearAgoDate := time.Now().UTC().AddDate(0, 0, -365)
+	yearAgoDate := snapdate.UTC.Today().AddDate(0, 0, -365) // MONITORING dates are whole days
 	monHist := MonitoringHistory{
 		yearAgoDate: {1: decimal.NewFromInt(50)},
 	}
@@ -124,3 +155,101 @@ func TestComputeChangeZeroHistorical(t *testing.T) {
 		t.Errorf("computeChange = %v, want nil (zero historical)", got)
 	}
 }
+
+func TestExportPartialPassesFailuresToWriter(t *testing.T) {
+	w := &captureWriter{}
+	svc := NewService(&stubHistory{}, w)
+
+	res := indicator.PartialResult{
+		Indicators: []indicator.Indicator{{ID: 3, Value: decimal.NewFromInt(10)}},
+		Failures: []indicator.Failure{
+			{Calculator: "dividend", IDs: []int{11, 15}, Error: "db down"},
+			{Calculator: "tokenomics", IDs: []int{21}, Error: "depends on I11 which is unavailable", Skipped: true},
+		},
+	}
+	rows, err := svc.ExportPartial(context.Background(), res)
+	if err != nil {
+		t.Fatalf("ExportPartial failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].ID != 3 {
+		t.Errorf("rows = %+v, want only I3 (no zero rows for unavailable IDs)", rows)
+	}
+	if len(w.failures) != 2 {
+		t.Errorf("writer got %d failures, want 2", len(w.failures))
+	}
+
+	data := buildIndAll(w.rows, w.failures)
+	// header + 1 row + blank + "Errors" + errors header + 2 failures
+	if len(data) != 7 {
+		t.Fatalf("IND_ALL has %d rows, want 7", len(data))
+	}
+	if got := data[5]; got[1] != "I11, I15" || got[2] != "failed" {
+		t.Errorf("error row = %v, want I11, I15 failed", got)
+	}
+	if got := data[6]; got[2] != "unavailable" {
+		t.Errorf("error row = %v, want unavailable", got)
+	}
+}
+
+type datesHistory struct{ dates []time.Time }
+
+func (d *datesHistory) GetNearestBefore(_ context.Context, _ string, date time.Time) (map[int]indicator.Indicator, error) {
+	d.dates = append(d.dates, date)
+	return nil, nil
+}
+
+func TestExportCalendarComparison(t *testing.T) {
+	hist := &datesHistory{}
+	svc := NewService(hist, &captureWriter{}, WithComparison(period.Calendar))
+	if _, err := svc.Export(context.Background(), []indicator.Indicator{{ID: 1, Value: decimal.NewFromInt(1)}}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	today := snapdate.UTC.Today()
+	var want []time.Time
+	for _, days := range []int{7, 30, 90, 365} {
+		want = append(want, period.Calendar.Baseline(today, days))
+	}
+	if len(hist.dates) != len(want) {
+		t.Fatalf("looked up %v, want %v", hist.dates, want)
+	}
+	for i := range want {
+		if !hist.dates[i].Equal(want[i]) {
+			t.Errorf("lookup %d = %s, want %s", i, hist.dates[i].Format("2006-01-02"), want[i].Format("2006-01-02"))
+		}
+	}
+	if d := hist.dates[1]; d.AddDate(0, 0, 1).Day() != 1 {
+		t.Errorf("month baseline %s is not a month-end", d.Format("2006-01-02"))
+	}
+}
+
+type stubMainIndicators struct {
+	ids []int
+	err error
+}
+
+func (s stubMainIndicators) MainIndicators(context.Context, string) ([]int, error) {
+	return s.ids, s.err
+}
+
+func TestRowsMarkConfiguredMainIndicators(t *testing.T) {
+	current := []indicator.Indicator{{ID: 1, Value: decimal.NewFromInt(1)}, {ID: 47, Value: decimal.NewFromInt(2)}}
+	date := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	rows := NewService(&stubHistory{}, nil).Rows(context.Background(), date, current)
+	if !rows[0].IsMain || rows[1].IsMain {
+		t.Errorf("default set: IsMain = %v, %v; want I1 only", rows[0].IsMain, rows[1].IsMain)
+	}
+
+	svc := NewService(&stubHistory{}, nil, WithMainIndicators(stubMainIndicators{ids: []int{47}}))
+	rows = svc.Rows(context.Background(), date, current)
+	if rows[0].IsMain || !rows[1].IsMain {
+		t.Errorf("configured set: IsMain = %v, %v; want I47 only", rows[0].IsMain, rows[1].IsMain)
+	}
+
+	svc = NewService(&stubHistory{}, nil, WithMainIndicators(stubMainIndicators{err: errors.New("connection reset")}))
+	rows = svc.Rows(context.Background(), date, current)
+	if !rows[0].IsMain || rows[1].IsMain {
+		t.Errorf("unreadable set: IsMain = %v, %v; want the default", rows[0].IsMain, rows[1].IsMain)
+	}
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// buildGroupRows lays out the GROUPS sheet: a title row, a header naming
+// the groups, then one row per figure with a column per group, so the
+// groups read side by side. A missing share is left as an empty cell.
+func buildGroupRows(date string, groups []domain.AccountGroup) [][]any {
+	header := []any{"Group"}
+	members := []any{"Members"}
+	eurmtl := []any{"Total, EURMTL"}
+	xlm := []any{"Total, XLM"}
+	share := []any{"Share of fund, %"}
+	tokens := []any{"Tokens"}
+	missing := []any{"Missing"}
+	for _, g := range groups {
+		names := make([]string, len(g.Members))
+		for i, m := range g.Members {
+			names[i] = m.Name
+		}
+		header = append(header, g.Name)
+		members = append(members, strings.Join(names, " + "))
+		eurmtl = append(eurmtl, toFloat(g.TotalEURMTL))
+		xlm = append(xlm, toFloat(g.TotalXLM))
+		share = append(share, ptrFloat(g.ShareOfFund))
+		tokens = append(tokens, g.TokenCount)
+		missing = append(missing, strings.Join(g.Missing, ", "))
+	}
+	return [][]any{
+		{fmt.Sprintf("Account groups, snapshot %s", date)},
+		header, members, eurmtl, xlm, share, tokens, missing,
+	}
+}
+
+// WriteGroups rewrites the GROUPS sheet with the groups of the snapshot of
+// date.
+func (w *SheetsWriter) WriteGroups(ctx context.Context, date string, groups []domain.AccountGroup) error {
+	if _, err := w.ensureSheets(ctx, "GROUPS"); err != nil {
+		return err
+	}
+
+	if _, err := w.svc.Spreadsheets.Values.Clear(w.spreadsheetID, "GROUPS", &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("clearing GROUPS sheet: %w", err)
+	}
+
+	rows := buildGroupRows(date, groups)
+	_, err := w.svc.Spreadsheets.Values.Update(w.spreadsheetID, "GROUPS!A1", &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing GROUPS sheet: %w", err)
+	}
+	w.currentRun().record("GROUPS", WriteRewrite, rows)
+	return nil
+}
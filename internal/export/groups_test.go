@@ -0,0 +1,39 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestBuildGroupRows(t *testing.T) {
+	half := decimal.NewFromInt(50)
+	groups := []domain.AccountGroup{
+		{
+			Name:        "real-estate",
+			Members:     []domain.AccountGroupMember{{Name: "MCITY"}, {Name: "APART"}},
+			TotalEURMTL: decimal.NewFromInt(4000),
+			TotalXLM:    decimal.NewFromInt(40000),
+			TokenCount:  6,
+			ShareOfFund: &half,
+		},
+		{Name: "affiliates", Members: []domain.AccountGroupMember{{Name: "LABR"}}, Missing: []string{"LABR"}},
+	}
+
+	rows := buildGroupRows("2026-10-01", groups)
+
+	if len(rows) != 8 {
+		t.Fatalf("got %d rows, want 8 (title, header, 6 figures)", len(rows))
+	}
+	if rows[1][1] != "real-estate" || rows[1][2] != "affiliates" {
+		t.Errorf("header = %v, want a column per group", rows[1])
+	}
+	if rows[2][1] != "MCITY + APART" || rows[3][1] != 4000.0 || rows[5][1] != 50.0 || rows[6][1] != 6 {
+		t.Errorf("real-estate column = %v %v %v %v", rows[2][1], rows[3][1], rows[5][1], rows[6][1])
+	}
+	if rows[5][2] != nil || rows[7][2] != "LABR" {
+		t.Errorf("affiliates share = %v, missing = %v; want empty and LABR", rows[5][2], rows[7][2])
+	}
+}
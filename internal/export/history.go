@@ -0,0 +1,92 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// HistorySheet holds every stored indicator value, one row per date per
+// indicator, for analysts who need more than the IND_ALL change columns.
+const HistorySheet = "IND_HISTORY"
+
+// historyHeader is row 1 of the IND_HISTORY sheet.
+var historyHeader = []any{"Date", "N", "Name", "Value", "measure"}
+
+// buildHistoryRows lays out points (ordered by date, as
+// indicator.PgRepository.GetHistory returns them) as IND_HISTORY rows:
+// Date | N | Name | Value | measure.
+func buildHistoryRows(points []indicator.HistoryPoint, loc Locale) [][]any {
+	data := make([][]any, 0, len(points))
+	for _, p := range points {
+		ind := indicator.NewIndicator(p.IndicatorID, p.Value, "", "")
+		data = append(data, []any{loc.FormatDate(p.SnapshotDate), ind.ID, ind.Name, toFloat(ind.Value), ind.Unit})
+	}
+	return data
+}
+
+// lastHistoryDate returns the latest date in the IND_HISTORY date column
+// (header included), or the zero time when there is none. Cells that don't
+// parse as loc dates are ignored.
+func lastHistoryDate(column [][]any, loc Locale) time.Time {
+	var last time.Time
+	for _, row := range column {
+		if len(row) == 0 {
+			continue
+		}
+		d, err := loc.ParseDate(fmt.Sprint(row[0]))
+		if err != nil {
+			continue
+		}
+		if d.After(last) {
+			last = d
+		}
+	}
+	return last
+}
+
+// LastHistoryDate returns the latest date already in the IND_HISTORY sheet,
+// or the zero time when the sheet is missing or empty. Pass the day after it
+// as `from` when loading the points for AppendHistory.
+func (w *SheetsWriter) LastHistoryDate(ctx context.Context) (time.Time, error) {
+	if _, err := w.ensureSheets(ctx, HistorySheet); err != nil {
+		return time.Time{}, err
+	}
+	// Cells are read back formatted, which renders exactly what FormatDate wrote.
+	resp, err := w.svc.Spreadsheets.Values.Get(w.spreadsheetID, HistorySheet+"!A:A").Context(ctx).Do()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading %s dates: %w", HistorySheet, err)
+	}
+	return lastHistoryDate(resp.Values, w.locale), nil
+}
+
+// AppendHistory rewrites the IND_HISTORY header and appends points below the
+// existing rows. Dates already in the sheet are never rewritten, so the
+// caller passes only points after LastHistoryDate.
+func (w *SheetsWriter) AppendHistory(ctx context.Context, points []indicator.HistoryPoint) error {
+	if _, err := w.ensureSheets(ctx, HistorySheet); err != nil {
+		return err
+	}
+	_, err := w.svc.Spreadsheets.Values.Update(w.spreadsheetID, HistorySheet+"!A1", &sheets.ValueRange{
+		Values: [][]any{historyHeader},
+	}).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing %s header: %w", HistorySheet, err)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+	rows := buildHistoryRows(points, w.locale)
+	_, err = w.svc.Spreadsheets.Values.Append(w.spreadsheetID, HistorySheet+"!A:E", &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("appending %s rows: %w", HistorySheet, err)
+	}
+	w.currentRun().record(HistorySheet, WriteAppend, rows)
+	return nil
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func TestBuildHistoryRows(t *testing.T) {
+	d := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	rows := buildHistoryRows([]indicator.HistoryPoint{
+		{SnapshotDate: d, IndicatorID: 3, Value: decimal.RequireFromString("1234.567")},
+	}, DefaultLocale)
+
+	if len(rows) != 1 || len(rows[0]) != len(historyHeader) {
+		t.Fatalf("rows = %v", rows)
+	}
+	if rows[0][0] != "30.09.2026" || rows[0][1] != 3 || rows[0][2] != indicator.NewIndicator(3, decimal.Zero, "", "").Name {
+		t.Errorf("row = %v", rows[0])
+	}
+	if rows[0][3] != 1234.57 || rows[0][4] != indicator.UnitOf(3) {
+		t.Errorf("value cells = %v, %v; want the rounded value and unit", rows[0][3], rows[0][4])
+	}
+}
+
+func TestLastHistoryDate(t *testing.T) {
+	column := [][]any{{"Date"}, {"29.09.2026"}, {"30.09.2026"}, {}, {"30.09.2026"}}
+	if got, want := lastHistoryDate(column, DefaultLocale), time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("last date = %s, want %s", got, want)
+	}
+	if got := lastHistoryDate([][]any{{"Date"}}, DefaultLocale); !got.IsZero() {
+		t.Errorf("header only: last date = %s, want zero", got)
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// Locale controls how dates and numbers are rendered in IND_ALL, IND_MAIN and
+// MONITORING.
+//
+// Sheets number-format patterns are locale-neutral: "." and "," in a pattern
+// always mean decimal point and grouping, and the spreadsheet's locale decides
+// which characters are displayed. The decimal separator is therefore chosen by
+// SpreadsheetLocale (ru_RU shows decimal commas), not by the patterns. The
+// locale also decides how USER_ENTERED date text is parsed, which is why dates
+// are written with DateLayout and displayed with DatePattern. The two describe
+// the same format, so the MONITORING same-day check can compare them directly.
+type Locale struct {
+	SpreadsheetLocale string // Sheets locale ID, e.g. "ru_RU"; empty leaves the spreadsheet's setting alone
+	DatePattern       string // Sheets DATE pattern, e.g. "dd.mm.yyyy"
+	DateLayout        string // Go layout equivalent of DatePattern
+	CurrencyPattern   string // number pattern for EUR-denominated values, "{n}" stands for the precision pattern; empty means plain numbers
+}
+
+// DefaultLocale matches the production spreadsheet: Russian locale with
+// dd.mm.yyyy dates and plain number formats. It leaves the spreadsheet's
+// locale as it is.
+var DefaultLocale = Locale{DatePattern: "dd.mm.yyyy", DateLayout: "02.01.2006"}
+
+// localeDatePatterns is the default date pattern for each known spreadsheet
+// locale. Other locales fall back to ISO dates, which every locale parses.
+var localeDatePatterns = map[string]string{
+	"ru_RU": "dd.mm.yyyy",
+	"de_DE": "dd.mm.yyyy",
+	"en_GB": "dd/mm/yyyy",
+	"en_US": "mm/dd/yyyy",
+}
+
+// currencyUnits are the indicator units that CurrencyPattern applies to.
+var currencyUnits = map[string]bool{"EUR": true, "EURMTL": true}
+
+// NewLocale builds a Locale from configuration. datePattern overrides the
+// locale's default date pattern. currencyPattern must contain "{n}" when set.
+func NewLocale(spreadsheetLocale, datePattern, currencyPattern string) (Locale, error) {
+	l := DefaultLocale
+	l.SpreadsheetLocale = spreadsheetLocale
+	if spreadsheetLocale != "" {
+		l.DatePattern = "yyyy-mm-dd"
+		if p, ok := localeDatePatterns[spreadsheetLocale]; ok {
+			l.DatePattern = p
+		}
+	}
+	if datePattern != "" {
+		l.DatePattern = datePattern
+	}
+	layout, err := dateLayout(l.DatePattern)
+	if err != nil {
+		return Locale{}, err
+	}
+	l.DateLayout = layout
+
+	if currencyPattern != "" && !strings.Contains(currencyPattern, "{n}") {
+		return Locale{}, fmt.Errorf("currency pattern %q must contain {n}", currencyPattern)
+	}
+	l.CurrencyPattern = currencyPattern
+	return l, nil
+}
+
+// dateLayout converts a Sheets date pattern into a Go time layout. Only the
+// day/month/year tokens used for whole dates are supported.
+func dateLayout(pattern string) (string, error) {
+	tokens := []struct{ sheets, layout string }{
+		{"yyyy", "2006"}, {"yy", "06"},
+		{"dd", "02"}, {"d", "2"},
+		{"mm", "01"}, {"m", "1"},
+	}
+	var b strings.Builder
+	var sawDay, sawMonth, sawYear bool
+	for rest := pattern; rest != ""; {
+		matched := false
+		for _, t := range tokens {
+			if strings.HasPrefix(rest, t.sheets) {
+				b.WriteString(t.layout)
+				rest = rest[len(t.sheets):]
+				switch t.sheets[0] {
+				case 'd':
+					sawDay = true
+				case 'm':
+					sawMonth = true
+				case 'y':
+					sawYear = true
+				}
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		c := rest[0]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			return "", fmt.Errorf("unsupported date pattern %q: only d, dd, m, mm, yy, yyyy and separators are allowed", pattern)
+		}
+		b.WriteByte(c)
+		rest = rest[1:]
+	}
+	if !sawDay || !sawMonth || !sawYear {
+		return "", fmt.Errorf("date pattern %q needs a day, a month and a year", pattern)
+	}
+	return b.String(), nil
+}
+
+// FormatDate renders t as date text that the spreadsheet parses back into t.
+func (l Locale) FormatDate(t time.Time) string {
+	return t.UTC().Format(l.DateLayout)
+}
+
+// ParseDate parses date text written by FormatDate.
+func (l Locale) ParseDate(s string) (time.Time, error) {
+	t, err := time.Parse(l.DateLayout, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// timestampLayout is DateLayout followed by the time of day, for the
+// IND_MAIN run stamp.
+func (l Locale) timestampLayout() string {
+	return l.DateLayout + " 15:04:05"
+}
+
+// valuePattern returns the number-format pattern for an indicator's value:
+// the precision pattern, wrapped in CurrencyPattern for EUR-denominated units.
+func (l Locale) valuePattern(id int) string {
+	p := numberFormatPattern(indicator.PrecisionOf(id))
+	if currencyUnits[indicator.UnitOf(id)] {
+		return l.currencyPattern(p)
+	}
+	return p
+}
+
+// currencyPattern wraps the number pattern p in the locale's currency
+// pattern, if it has one.
+func (l Locale) currencyPattern(p string) string {
+	if l.CurrencyPattern == "" {
+		return p
+	}
+	return strings.ReplaceAll(l.CurrencyPattern, "{n}", p)
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func TestNewLocale(t *testing.T) {
+	tests := []struct {
+		name                       string
+		locale, date               string
+		wantPattern, wantLayout    string
+		wantSpreadsheetLocaleUnset bool
+	}{
+		{name: "default", wantPattern: "dd.mm.yyyy", wantLayout: "02.01.2006", wantSpreadsheetLocaleUnset: true},
+		{name: "ru", locale: "ru_RU", wantPattern: "dd.mm.yyyy", wantLayout: "02.01.2006"},
+		{name: "us", locale: "en_US", wantPattern: "mm/dd/yyyy", wantLayout: "01/02/2006"},
+		{name: "unknown locale falls back to ISO", locale: "pt_BR", wantPattern: "yyyy-mm-dd", wantLayout: "2006-01-02"},
+		{name: "override", locale: "ru_RU", date: "d.m.yy", wantPattern: "d.m.yy", wantLayout: "2.1.06"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLocale(tt.locale, tt.date, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if l.DatePattern != tt.wantPattern || l.DateLayout != tt.wantLayout {
+				t.Errorf("got pattern %q layout %q, want %q %q", l.DatePattern, l.DateLayout, tt.wantPattern, tt.wantLayout)
+			}
+			if (l.SpreadsheetLocale == "") != tt.wantSpreadsheetLocaleUnset {
+				t.Errorf("SpreadsheetLocale = %q", l.SpreadsheetLocale)
+			}
+		})
+	}
+}
+
+func TestNewLocaleErrors(t *testing.T) {
+	for _, date := range []string{"dd.mm", "dd.mm.yyyy hh:mm", "yyyy-mm-dd Q"} {
+		if _, err := NewLocale("", date, ""); err == nil {
+			t.Errorf("date pattern %q: expected error", date)
+		}
+	}
+	if _, err := NewLocale("", "", `#,##0.00 "€"`); err == nil {
+		t.Error("currency pattern without {n}: expected error")
+	}
+}
+
+func TestLocaleDateRoundTrip(t *testing.T) {
+	l, _ := NewLocale("en_GB", "", "")
+	d := time.Date(2026, 3, 7, 15, 30, 0, 0, time.UTC)
+
+	s := l.FormatDate(d)
+	if s != "07/03/2026" {
+		t.Fatalf("FormatDate = %q, want 07/03/2026", s)
+	}
+	got, err := l.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDate = %v", got)
+	}
+}
+
+func TestLocaleValuePatternCurrency(t *testing.T) {
+	l, _ := NewLocale("", "", `{n} "€"`)
+
+	// I3 is EURMTL-denominated, I6 is MTL.
+	if got, want := l.valuePattern(3), numberFormatPattern(indicator.PrecisionOf(3))+` "€"`; got != want {
+		t.Errorf("I3 pattern = %q, want %q", got, want)
+	}
+	if got, want := l.valuePattern(6), numberFormatPattern(indicator.PrecisionOf(6)); got != want {
+		t.Errorf("I6 pattern = %q, want %q", got, want)
+	}
+	if got := DefaultLocale.valuePattern(3); got != numberFormatPattern(indicator.PrecisionOf(3)) {
+		t.Errorf("default I3 pattern = %q, want plain number", got)
+	}
+}
+
+func TestBuildIndMainTimestampUsesLocale(t *testing.T) {
+	l, _ := NewLocale("", "yyyy-mm-dd", "")
+	at := time.Date(2026, 10, 17, 9, 5, 0, 0, time.UTC)
+	rows := []IndicatorRow{{Indicator: indicator.Indicator{ID: 1, Value: decimal.NewFromInt(1)}, IsMain: true}}
+
+	data := buildIndMain(rows, at, l)
+
+	if data[0][1] != "2026-10-17 09:05:00" {
+		t.Errorf("stamp = %v", data[0][1])
+	}
+	_, dataRow := buildMonitoringRows(rows, at, l, MonitoringNotes{})
+	if dataRow[0] != "2026-10-17" {
+		t.Errorf("MONITORING date = %v", dataRow[0])
+	}
+}
@@ -0,0 +1,178 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// MonitoringState is what the writer knows of the MONITORING sheet without
+// reading it: the sheet it describes, its last data row and the row of every
+// date written.
+type MonitoringState struct {
+	SheetID int64
+	LastRow int               // 1-based; 2 (the header rows) when there is no data
+	Rows    map[time.Time]int // 1-based row of each date, keyed by UTC midnight
+}
+
+// MonitoringMirror keeps MonitoringState per spreadsheet between runs
+// (PgMonitoringMirror), so appending a row doesn't cost a read of the sheet.
+type MonitoringMirror interface {
+	// Load returns the mirrored state; ok is false when there is none.
+	Load(ctx context.Context, spreadsheetID string) (state MonitoringState, ok bool, err error)
+	// Replace stores state in place of the mirrored one.
+	Replace(ctx context.Context, spreadsheetID string, state MonitoringState) error
+	// Record adds the row just appended for date, which becomes the last row.
+	Record(ctx context.Context, spreadsheetID string, sheetID int64, date time.Time, row int) error
+	// Reset forgets the mirrored state; the next append reads the sheet.
+	Reset(ctx context.Context, spreadsheetID string) error
+}
+
+// WithMonitoringMirror keeps the MONITORING dates and last row in m and
+// reads the sheet only when m has no state for it or turns out stale.
+func WithMonitoringMirror(m MonitoringMirror) WriterOption {
+	return func(w *SheetsWriter) {
+		w.mirror = m
+	}
+}
+
+// SetMonitoringMirror is WithMonitoringMirror for a writer created before
+// the database was connected.
+func (w *SheetsWriter) SetMonitoringMirror(m MonitoringMirror) {
+	w.mirror = m
+}
+
+// AppendPause is how long to wait between MONITORING appends in a bulk run
+// to stay within the Sheets quota of 60 reads and 60 writes a minute. With
+// a mirror an append costs one read and one write; without it, two reads.
+func (w *SheetsWriter) AppendPause() time.Duration {
+	if w.mirror != nil {
+		return time.Second
+	}
+	return 3 * time.Second
+}
+
+// monitoringState returns the mirrored state of the MONITORING sheet
+// sheetID, reading the sheet (and refreshing the mirror) when there is no
+// mirror, it has no state or the state describes another sheet, as after the
+// sheet was deleted and recreated.
+func (w *SheetsWriter) monitoringState(ctx context.Context, sheetID int64) (MonitoringState, error) {
+	if w.mirror != nil {
+		state, ok, err := w.mirror.Load(ctx, w.spreadsheetID)
+		switch {
+		case err != nil:
+			slog.Error("monitoring mirror unavailable, reading the sheet", "error", err)
+		case !ok:
+			slog.Info("monitoring mirror empty, reading the sheet")
+		case state.SheetID != sheetID:
+			slog.Info("monitoring mirror describes another sheet, reading the sheet", "mirrored", state.SheetID, "sheet", sheetID)
+		default:
+			return state, nil
+		}
+	}
+	return w.readMonitoringState(ctx, sheetID)
+}
+
+// readMonitoringState reads the dates of column A and, with a mirror,
+// stores the state read.
+func (w *SheetsWriter) readMonitoringState(ctx context.Context, sheetID int64) (MonitoringState, error) {
+	// Cells are read back formatted with DatePattern, which renders exactly
+	// what FormatDate wrote.
+	dates, err := w.svc.Spreadsheets.Values.Get(w.spreadsheetID, "MONITORING!A3:A").Context(ctx).Do()
+	if err != nil {
+		return MonitoringState{}, fmt.Errorf("reading MONITORING dates: %w", err)
+	}
+	state := newMonitoringState(sheetID, dates.Values, w.locale)
+	if w.mirror != nil {
+		if err := w.mirror.Replace(ctx, w.spreadsheetID, state); err != nil {
+			slog.Error("refreshing monitoring mirror", "error", err)
+		}
+	}
+	return state, nil
+}
+
+// newMonitoringState builds the state of the sheet from the cells of
+// A3:A. Cells that aren't dates in loc are not rows of any date; a date
+// written twice keeps its first row.
+func newMonitoringState(sheetID int64, cells [][]any, loc Locale) MonitoringState {
+	state := MonitoringState{SheetID: sheetID, LastRow: 2 + len(cells), Rows: make(map[time.Time]int, len(cells))}
+	for i, row := range cells {
+		if len(row) == 0 {
+			continue
+		}
+		d, err := loc.ParseDate(fmt.Sprint(row[0]))
+		if err != nil {
+			continue
+		}
+		if _, dup := state.Rows[d]; !dup {
+			state.Rows[d] = 3 + i
+		}
+	}
+	return state
+}
+
+// recordMonitoringRow brings the mirror up to date after date was appended
+// at updatedRange. An append that didn't land right after the mirrored last
+// row means the sheet was edited by hand: the mirror is then rebuilt from
+// the sheet.
+func (w *SheetsWriter) recordMonitoringRow(ctx context.Context, state MonitoringState, date time.Time, updatedRange string) {
+	if w.mirror == nil {
+		return
+	}
+	row, err := appendedRow(updatedRange)
+	if err != nil || row != state.LastRow+1 {
+		slog.Info("MONITORING changed outside the export, re-reading it", "expected_row", state.LastRow+1, "range", updatedRange)
+		fresh, err := w.readMonitoringState(ctx, state.SheetID)
+		if err != nil {
+			slog.Error("re-reading MONITORING for the mirror", "error", err)
+			w.resetMirror(ctx)
+			return
+		}
+		if first, ok := fresh.Rows[date]; ok && first != row {
+			slog.Error("MONITORING has the date twice; remove the duplicate row", "date", w.locale.FormatDate(date), "rows", []int{first, row})
+		}
+		return
+	}
+	if err := w.mirror.Record(ctx, w.spreadsheetID, state.SheetID, date, row); err != nil {
+		slog.Error("recording MONITORING row in the mirror", "error", err)
+		w.resetMirror(ctx)
+	}
+}
+
+// insertionRow returns the row of the earliest date in state after date,
+// where a row for date belongs to keep the sheet in date order; ok is false
+// when no later date is written and the row is appended.
+func insertionRow(state MonitoringState, date time.Time) (row int, ok bool) {
+	var next time.Time
+	for d, r := range state.Rows {
+		if d.After(date) && (!ok || d.Before(next)) {
+			next, row, ok = d, r, true
+		}
+	}
+	return row, ok
+}
+
+// resetMirror forgets the mirrored state after the sheet was rewritten
+// other than by an append.
+func (w *SheetsWriter) resetMirror(ctx context.Context) {
+	if w.mirror == nil {
+		return
+	}
+	if err := w.mirror.Reset(ctx, w.spreadsheetID); err != nil {
+		slog.Error("resetting monitoring mirror", "error", err)
+	}
+}
+
+var rangeRowRe = regexp.MustCompile(`![A-Z]+(\d+)`)
+
+// appendedRow returns the first row of an A1 range like "MONITORING!A57:BG57".
+func appendedRow(a1 string) (int, error) {
+	m := rangeRowRe.FindStringSubmatch(a1)
+	if m == nil {
+		return 0, fmt.Errorf("no row in range %q", a1)
+	}
+	return strconv.Atoi(m[1])
+}
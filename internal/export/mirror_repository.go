@@ -0,0 +1,123 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgMonitoringMirror stores the MONITORING state in monitoring_mirror and
+// monitoring_mirror_dates.
+type PgMonitoringMirror struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgMonitoringMirror creates a new PostgreSQL MONITORING mirror.
+func NewPgMonitoringMirror(pool *pgxpool.Pool) *PgMonitoringMirror {
+	return &PgMonitoringMirror{pool: pool}
+}
+
+// Load implements MonitoringMirror.
+func (m *PgMonitoringMirror) Load(ctx context.Context, spreadsheetID string) (MonitoringState, bool, error) {
+	state := MonitoringState{Rows: map[time.Time]int{}}
+	err := m.pool.QueryRow(ctx,
+		`SELECT sheet_id, last_row FROM monitoring_mirror WHERE spreadsheet_id = $1`,
+		spreadsheetID).Scan(&state.SheetID, &state.LastRow)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return MonitoringState{}, false, nil
+	}
+	if err != nil {
+		return MonitoringState{}, false, fmt.Errorf("loading monitoring mirror: %w", err)
+	}
+
+	rows, err := m.pool.Query(ctx,
+		`SELECT row_date, row_index FROM monitoring_mirror_dates WHERE spreadsheet_id = $1`,
+		spreadsheetID)
+	if err != nil {
+		return MonitoringState{}, false, fmt.Errorf("loading monitoring mirror dates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d time.Time
+		var row int
+		if err := rows.Scan(&d, &row); err != nil {
+			return MonitoringState{}, false, fmt.Errorf("scanning monitoring mirror date: %w", err)
+		}
+		state.Rows[time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)] = row
+	}
+	if err := rows.Err(); err != nil {
+		return MonitoringState{}, false, fmt.Errorf("iterating monitoring mirror dates: %w", err)
+	}
+	return state, true, nil
+}
+
+// Replace implements MonitoringMirror.
+func (m *PgMonitoringMirror) Replace(ctx context.Context, spreadsheetID string, state MonitoringState) error {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning monitoring mirror tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO monitoring_mirror (spreadsheet_id, sheet_id, last_row) VALUES ($1, $2, $3)
+		 ON CONFLICT (spreadsheet_id) DO UPDATE
+		   SET sheet_id = EXCLUDED.sheet_id, last_row = EXCLUDED.last_row, updated_at = CURRENT_TIMESTAMP`,
+		spreadsheetID, state.SheetID, state.LastRow); err != nil {
+		return fmt.Errorf("saving monitoring mirror: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM monitoring_mirror_dates WHERE spreadsheet_id = $1`, spreadsheetID); err != nil {
+		return fmt.Errorf("clearing monitoring mirror dates: %w", err)
+	}
+	batch := &pgx.Batch{}
+	for d, row := range state.Rows {
+		batch.Queue(`INSERT INTO monitoring_mirror_dates (spreadsheet_id, row_date, row_index) VALUES ($1, $2, $3)`,
+			spreadsheetID, d, row)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("saving monitoring mirror dates: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing monitoring mirror: %w", err)
+	}
+	return nil
+}
+
+// Record implements MonitoringMirror.
+func (m *PgMonitoringMirror) Record(ctx context.Context, spreadsheetID string, sheetID int64, date time.Time, row int) error {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning monitoring mirror tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO monitoring_mirror (spreadsheet_id, sheet_id, last_row) VALUES ($1, $2, $3)
+		 ON CONFLICT (spreadsheet_id) DO UPDATE
+		   SET sheet_id = EXCLUDED.sheet_id, last_row = EXCLUDED.last_row, updated_at = CURRENT_TIMESTAMP`,
+		spreadsheetID, sheetID, row); err != nil {
+		return fmt.Errorf("saving monitoring mirror: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO monitoring_mirror_dates (spreadsheet_id, row_date, row_index) VALUES ($1, $2, $3)
+		 ON CONFLICT (spreadsheet_id, row_date) DO UPDATE SET row_index = EXCLUDED.row_index`,
+		spreadsheetID, date, row); err != nil {
+		return fmt.Errorf("recording monitoring mirror date %s: %w", date.Format("2006-01-02"), err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing monitoring mirror: %w", err)
+	}
+	return nil
+}
+
+// Reset implements MonitoringMirror.
+func (m *PgMonitoringMirror) Reset(ctx context.Context, spreadsheetID string) error {
+	if _, err := m.pool.Exec(ctx, `DELETE FROM monitoring_mirror WHERE spreadsheet_id = $1`, spreadsheetID); err != nil {
+		return fmt.Errorf("resetting monitoring mirror: %w", err)
+	}
+	return nil
+}
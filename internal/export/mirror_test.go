@@ -0,0 +1,77 @@
+package export
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewMonitoringState(t *testing.T) {
+	cells := [][]any{
+		{"01.09.2026"},
+		{},
+		{"notes"},
+		{"02.09.2026"},
+		{"01.09.2026"}, // pasted twice by hand
+	}
+	state := newMonitoringState(7, cells, DefaultLocale)
+	if state.SheetID != 7 || state.LastRow != 7 {
+		t.Errorf("state = sheet %d, last row %d; want 7, 7", state.SheetID, state.LastRow)
+	}
+	want := map[time.Time]int{
+		time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC): 3,
+		time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC): 6,
+	}
+	if len(state.Rows) != len(want) {
+		t.Fatalf("rows = %v, want %v", state.Rows, want)
+	}
+	for d, row := range want {
+		if state.Rows[d] != row {
+			t.Errorf("row of %s = %d, want %d", d.Format("2006-01-02"), state.Rows[d], row)
+		}
+	}
+
+	if empty := newMonitoringState(1, nil, DefaultLocale); empty.LastRow != 2 || len(empty.Rows) != 0 {
+		t.Errorf("empty sheet state = %+v", empty)
+	}
+}
+
+func TestAppendedRow(t *testing.T) {
+	for a1, want := range map[string]int{
+		"MONITORING!A57:BG57": 57,
+		"'MONITORING'!A3:BG3": 3,
+	} {
+		if got, err := appendedRow(a1); err != nil || got != want {
+			t.Errorf("appendedRow(%q) = %d, %v; want %d", a1, got, err, want)
+		}
+	}
+	if _, err := appendedRow("MONITORING"); err == nil {
+		t.Error("a range without a row must fail")
+	}
+}
+
+func TestInsertionRow(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+	state := MonitoringState{LastRow: 6, Rows: map[time.Time]int{day(1): 3, day(4): 6, day(3): 5, day(2): 4}}
+
+	if row, ok := insertionRow(state, day(5)); ok {
+		t.Errorf("latest date: insert at %d, want an append", row)
+	}
+	state.Rows = map[time.Time]int{day(1): 3, day(4): 5, day(6): 6}
+	if row, ok := insertionRow(state, day(2)); !ok || row != 5 {
+		t.Errorf("past date: row %d, %v; want 5, true", row, ok)
+	}
+	if row, ok := insertionRow(MonitoringState{LastRow: 2}, day(2)); ok {
+		t.Errorf("empty sheet: insert at %d, want an append", row)
+	}
+}
+
+func TestAppendPause(t *testing.T) {
+	w := &SheetsWriter{}
+	if w.AppendPause() != 3*time.Second {
+		t.Errorf("pause without a mirror = %v", w.AppendPause())
+	}
+	w.SetMonitoringMirror(NewPgMonitoringMirror(nil))
+	if w.AppendPause() != time.Second {
+		t.Errorf("pause with a mirror = %v", w.AppendPause())
+	}
+}
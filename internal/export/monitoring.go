@@ -7,20 +7,51 @@ import (
 	"time"
 
 	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
 	sheets "google.golang.org/api/sheets/v4"
-
-	"github.com/mtlprog/stat/internal/indicator"
 )
 
 // monitoringCol describes one column in the MONITORING sheet.
-// indicatorID == 0 means no mapped indicator; use fixedValue instead.
+// indicatorID == 0 means no mapped indicator: the column shows the
+// configured regulatory price, the row's note for a note column, or
+// nothing.
 type monitoringCol struct {
-	header      string
-	indicatorID int
-	fixedValue  any
+	header          string
+	indicatorID     int
+	regulatoryPrice bool     // MonitoringNotes.RegulatoryPrice
+	note            noteKind // free-text column, not a number
+}
+
+// noteKind selects which of MonitoringNotes a note column shows.
+type noteKind int
+
+const (
+	noNote noteKind = iota
+	noteIssuance
+	noteAnnotations
+)
+
+// MonitoringNotes are the cells of a MONITORING row not taken from
+// indicators: the free-text notes and the regulatory price.
+type MonitoringNotes struct {
+	Issuance    string // issuance.Note of the day's supply changes
+	Annotations string // operator annotations of the snapshot date
+	// RegulatoryPrice is the configured regulatory price
+	// (/api/v1/admin/regulatory-price); nil writes DefaultRegulatoryPrice.
+	RegulatoryPrice *decimal.Decimal
 }
 
-// monitoringColumns defines the 53 data columns (B through BB) in order.
+func (n MonitoringNotes) of(kind noteKind) string {
+	switch kind {
+	case noteIssuance:
+		return n.Issuance
+	case noteAnnotations:
+		return n.Annotations
+	}
+	return ""
+}
+
+// monitoringColumns defines the 58 data columns (B through BG) in order.
 // Column A (Date) is prepended separately in buildMonitoringRows.
 //
 // Column order is load-bearing — row alignment in MONITORING (and in
@@ -38,18 +69,18 @@ var monitoringColumns = []monitoringCol{
 	{header: "MTL  in circulation", indicatorID: 6},
 	{header: "MTLRECT in circulation", indicatorID: 7},
 	{header: "Book Value", indicatorID: 8},
-	{header: "Regulatory Price", indicatorID: 0, fixedValue: 4.0},
+	{header: "Regulatory Price", regulatoryPrice: true},
 	{header: "Share Market Price", indicatorID: 10},
 	{header: "Dividends", indicatorID: 11},
 	{header: "Dividends in eurmtl", indicatorID: 11}, // same as above; only EURMTL dividends tracked currently
-	{header: "Dividends in btcmtl", indicatorID: 0, fixedValue: nil},
-	{header: "Dividends in usdm", indicatorID: 0, fixedValue: nil},
+	{header: "Dividends in btcmtl"},
+	{header: "Dividends in usdm"},
 	{header: "Dividends per share", indicatorID: 15},
-	{header: "Annual Dividend Yield 1", indicatorID: 0, fixedValue: nil}, // I16 deprecated
+	{header: "Annual Dividend Yield 1"}, // I16 deprecated
 	{header: "Annual Dividend Yield 2", indicatorID: 17},
 	{header: "Shareholders by eurmtl", indicatorID: 18},
-	{header: "Shareholders by satsmtl", indicatorID: 0, fixedValue: nil},
-	{header: "Shareholders by usdm", indicatorID: 0, fixedValue: nil},
+	{header: "Shareholders by satsmtl"},
+	{header: "Shareholders by usdm"},
 	{header: "Average Shareholding", indicatorID: 21},
 	{header: "Average Share Price", indicatorID: 22},
 	{header: "Median shareholding size", indicatorID: 23},
@@ -57,17 +88,17 @@ var monitoringColumns = []monitoringCol{
 	{header: "EURMTL overall payment total", indicatorID: 26},
 	{header: "EURMTL overall payment per day", indicatorID: 25},
 	{header: "More-one-share Shareholders ", indicatorID: 27},
-	{header: "Montelibero Association Capitalization", indicatorID: 0, fixedValue: nil},
-	{header: "Association Endowment Fund", indicatorID: 0, fixedValue: nil},
+	{header: "Montelibero Association Capitalization", indicatorID: 28},
+	{header: "Association Endowment Fund", indicatorID: 29},
 	{header: "Price-to-book ratio", indicatorID: 30},
-	{header: "EBITDA", indicatorID: 0, fixedValue: nil},
-	{header: "EBITDA margin", indicatorID: 0, fixedValue: nil},
-	{header: "EPS", indicatorID: 0, fixedValue: nil}, // I33 deprecated
+	{header: "EBITDA"},
+	{header: "EBITDA margin"},
+	{header: "EPS"}, // I33 deprecated
 	{header: "P/E", indicatorID: 34},
-	{header: "P/S", indicatorID: 0, fixedValue: nil},
-	{header: "P/S (by cap)", indicatorID: 0, fixedValue: nil},
-	{header: "Margin", indicatorID: 0, fixedValue: nil},
-	{header: "Payout Ratio", indicatorID: 0, fixedValue: nil},
+	{header: "P/S"},
+	{header: "P/S (by cap)"},
+	{header: "Margin"},
+	{header: "Payout Ratio"},
 	{header: "BPP", indicatorID: 39},
 	{header: "MTLAP", indicatorID: 40},
 	{header: "Shareholders", indicatorID: 62},
@@ -83,14 +114,25 @@ var monitoringColumns = []monitoringCol{
 	{header: "BOSS Total Value", indicatorID: 59},
 	{header: "ADMIN Total Value", indicatorID: 60},
 	{header: "BTC Rate", indicatorID: 61},
+	{header: "Data Quality Score", indicatorID: 65},
+	{header: "Issuance / Buyback", note: noteIssuance},
+	{header: "MTLRECT Converted", indicatorID: 73},
+	{header: "MTLRECT Conversion 30d", indicatorID: 74},
+	{header: "Notes", note: noteAnnotations},
 }
 
-// MonitoringColumnIndicatorIDs returns the indicator ID for each of the 53 MONITORING
-// data columns (B through BB). A value of 0 means no mapped indicator at that index.
+// MonitoringColumnIndicatorIDs returns the indicator ID for each of the 58 MONITORING
+// data columns (B through BG). A value of 0 means no mapped indicator at that index.
 func MonitoringColumnIndicatorIDs() []int {
 	return lo.Map(monitoringColumns, func(c monitoringCol, _ int) int { return c.indicatorID })
 }
 
+// MonitoringColumnHeaders returns the header name of each of the 58 MONITORING
+// data columns, in the order of MonitoringColumnIndicatorIDs.
+func MonitoringColumnHeaders() []string {
+	return lo.Map(monitoringColumns, func(c monitoringCol, _ int) string { return c.header })
+}
+
 // MonitoringHeaderRows returns the canonical two-row header (indicator IDs in
 // row 1, header names in row 2) for the MONITORING sheet. Used by tooling
 // that refreshes stale headers in place when monitoringColumns changes.
@@ -111,57 +153,15 @@ func MonitoringHeaderRows() [][]any {
 }
 
 // buildMonitoringRows builds header rows and a single data row for the MONITORING sheet.
-func buildMonitoringRows(rows []IndicatorRow, at time.Time) (headerRows [][]any, dataRow []any) {
-	byID := lo.KeyBy(rows, func(r IndicatorRow) int { return r.ID })
-
-	// Row 1: indicator ID per column (A is blank). For placeholder/fixed
-	// slots without a current indicator, fall back to the column position —
-	// historically those slots WERE the legacy indicator IDs at that position
-	// (e.g. col 9 was I9 "Regulatory Price" before deprecation), so the
-	// position number still reads as a stable reference.
-	colNums := make([]any, 1+len(monitoringColumns))
-	colNums[0] = ""
-	for i, col := range monitoringColumns {
-		if col.indicatorID != 0 {
-			colNums[i+1] = float64(col.indicatorID)
-		} else {
-			colNums[i+1] = float64(i + 1)
-		}
-	}
-
-	// Row 2: header names
-	headers := make([]any, 1+len(monitoringColumns))
-	headers[0] = "Date"
-	for i, col := range monitoringColumns {
-		headers[i+1] = col.header
-	}
-
-	headerRows = [][]any{colNums, headers}
-
-	// Data row
-	data := make([]any, 1+len(monitoringColumns))
-	data[0] = at.UTC().Format("02.01.2006")
-	for i, col := range monitoringColumns {
-		if col.indicatorID != 0 {
-			if ind, ok := byID[col.indicatorID]; ok {
-				data[i+1] = toFloat(ind.Value)
-			} else {
-				slog.Debug("monitoring: indicator missing, writing empty cell",
-					"indicatorID", col.indicatorID,
-					"column", col.header,
-				)
-				data[i+1] = nil
-			}
-		} else {
-			data[i+1] = col.fixedValue
-		}
-	}
-
-	return headerRows, data
+// The date cell is written as text in the locale's date format; notes fill
+// the note columns.
+func buildMonitoringRows(rows []IndicatorRow, at time.Time, loc Locale, notes MonitoringNotes) (headerRows [][]any, dataRow []any) {
+	return MonitoringHeaderRows(), NewMonitoringReport(at, rows, notes).SheetRow(loc)
 }
 
 // DeleteMonitoringSheet deletes the MONITORING sheet if it exists.
-// After deletion, appendMonitoringRow will recreate it via ensureSheets.
+// After deletion, appendMonitoringRow will recreate it via ensureSheets and
+// read its state afresh.
 func (w *SheetsWriter) DeleteMonitoringSheet(ctx context.Context) error {
 	spreadsheet, err := w.svc.Spreadsheets.Get(w.spreadsheetID).Context(ctx).Do()
 	if err != nil {
@@ -179,6 +179,7 @@ func (w *SheetsWriter) DeleteMonitoringSheet(ctx context.Context) error {
 				return fmt.Errorf("deleting MONITORING sheet: %w", err)
 			}
 			slog.Info("deleted existing MONITORING sheet")
+			w.resetMirror(ctx)
 			return nil
 		}
 	}
@@ -203,6 +204,8 @@ func (w *SheetsWriter) WriteMonitoringBulk(ctx context.Context, allRows [][]any)
 	if err != nil {
 		return fmt.Errorf("writing MONITORING bulk data: %w", err)
 	}
+	w.currentRun().record("MONITORING", WriteRewrite, allRows)
+	w.resetMirror(ctx)
 
 	return nil
 }
@@ -215,7 +218,19 @@ func (w *SheetsWriter) AppendMonitoring(ctx context.Context, rows []IndicatorRow
 
 // AppendMonitoringForDate appends a MONITORING row for the given date and applies formatting.
 func (w *SheetsWriter) AppendMonitoringForDate(ctx context.Context, rows []IndicatorRow, date time.Time) error {
-	if err := w.appendMonitoringRow(ctx, rows, date); err != nil {
+	return w.AppendMonitoringNoted(ctx, rows, date, MonitoringNotes{})
+}
+
+// AppendMonitoringNoted is AppendMonitoringForDate with notes written to the
+// "Issuance / Buyback" and "Notes" columns.
+func (w *SheetsWriter) AppendMonitoringNoted(ctx context.Context, rows []IndicatorRow, date time.Time, notes MonitoringNotes) error {
+	return w.AppendMonitoringReport(ctx, NewMonitoringReport(date, rows, notes))
+}
+
+// AppendMonitoringReport appends the MONITORING row of report and applies
+// formatting.
+func (w *SheetsWriter) AppendMonitoringReport(ctx context.Context, report MonitoringReport) error {
+	if err := w.appendMonitoringRow(ctx, report); err != nil {
 		return err
 	}
 	return w.ApplyMonitoringFormatting(ctx)
@@ -223,8 +238,8 @@ func (w *SheetsWriter) AppendMonitoringForDate(ctx context.Context, rows []Indic
 
 // AppendMonitoringRowOnly appends a MONITORING row without applying formatting.
 // Use this for bulk imports, then call ApplyMonitoringFormatting once at the end.
-func (w *SheetsWriter) AppendMonitoringRowOnly(ctx context.Context, rows []IndicatorRow, date time.Time) error {
-	return w.appendMonitoringRow(ctx, rows, date)
+func (w *SheetsWriter) AppendMonitoringRowOnly(ctx context.Context, rows []IndicatorRow, date time.Time, notes MonitoringNotes) error {
+	return w.appendMonitoringRow(ctx, NewMonitoringReport(date, rows, notes))
 }
 
 // ApplyMonitoringFormatting applies visual formatting to the MONITORING sheet.
@@ -236,51 +251,110 @@ func (w *SheetsWriter) ApplyMonitoringFormatting(ctx context.Context) error {
 	return w.applyMonitoringFormatting(ctx, meta["MONITORING"])
 }
 
-func (w *SheetsWriter) appendMonitoringRow(ctx context.Context, rows []IndicatorRow, date time.Time) error {
-	_, err := w.ensureSheets(ctx, "MONITORING")
+func (w *SheetsWriter) appendMonitoringRow(ctx context.Context, report MonitoringReport) error {
+	meta, err := w.ensureSheets(ctx, "MONITORING")
 	if err != nil {
 		return fmt.Errorf("ensuring MONITORING sheet: %w", err)
 	}
+	sheetID := meta["MONITORING"].id
 
-	headerRows, dataRow := buildMonitoringRows(rows, date)
-
-	// Always rewrite header rows 1-2 so the sheet stays in sync with
-	// monitoringColumns. The old "write only when empty" path left stale
-	// labels (e.g. "EURMTL overall payment per month" for what is now the
-	// cumulative slot) frozen forever after the slice changed.
-	_, err = w.svc.Spreadsheets.Values.Update(
-		w.spreadsheetID,
-		"MONITORING!A1",
-		&sheets.ValueRange{Values: headerRows},
-	).ValueInputOption("USER_ENTERED").Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("writing MONITORING headers: %w", err)
+	if err := w.writeMonitoringHeaders(ctx, sheetID); err != nil {
+		return err
 	}
 
 	// Check for duplicate date to prevent double-append on same-day reruns.
-	todayStr := date.Format("02.01.2006")
-	dates, err := w.svc.Spreadsheets.Values.Get(
-		w.spreadsheetID, "MONITORING!A3:A",
-	).Context(ctx).Do()
+	date := time.Date(report.Date.Year(), report.Date.Month(), report.Date.Day(), 0, 0, 0, 0, time.UTC)
+	state, err := w.monitoringState(ctx, sheetID)
 	if err != nil {
-		return fmt.Errorf("reading MONITORING dates: %w", err)
+		return err
 	}
-	for _, row := range dates.Values {
-		if len(row) > 0 && fmt.Sprint(row[0]) == todayStr {
-			slog.Info("monitoring: row for today already exists, skipping append", "date", todayStr)
-			return nil
-		}
+	if row, ok := state.Rows[date]; ok {
+		slog.Info("monitoring: row for today already exists, skipping append", "date", w.locale.FormatDate(date), "row", row)
+		return nil
 	}
 
-	_, err = w.svc.Spreadsheets.Values.Append(
+	dataRow := report.SheetRow(w.locale)
+	if row, ok := insertionRow(state, date); ok {
+		return w.insertMonitoringRow(ctx, sheetID, row, dataRow)
+	}
+	resp, err := w.svc.Spreadsheets.Values.Append(
 		w.spreadsheetID,
-		"MONITORING!A:BB",
+		"MONITORING!A:BG",
 		&sheets.ValueRange{Values: [][]any{dataRow}},
 	).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("appending MONITORING row: %w", err)
 	}
+	w.currentRun().record("MONITORING", WriteAppend, [][]any{dataRow})
+	if resp.Updates != nil {
+		w.recordMonitoringRow(ctx, state, date, resp.Updates.UpdatedRange)
+	} else {
+		w.resetMirror(ctx)
+	}
+
+	return nil
+}
+
+// insertMonitoringRow writes dataRow as a new row at the 1-based row,
+// shifting it and the rows below it down, so a date exported after later
+// ones still lands in date order. The mirror's rows shift with it, so it is
+// reset and the next append reads the sheet.
+func (w *SheetsWriter) insertMonitoringRow(ctx context.Context, sheetID int64, row int, dataRow []any) error {
+	_, err := w.svc.Spreadsheets.BatchUpdate(w.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			InsertDimension: &sheets.InsertDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    sheetID,
+					Dimension:  "ROWS",
+					StartIndex: int64(row - 1),
+					EndIndex:   int64(row),
+				},
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("inserting MONITORING row %d: %w", row, err)
+	}
+	w.resetMirror(ctx)
+
+	_, err = w.svc.Spreadsheets.Values.Update(
+		w.spreadsheetID,
+		fmt.Sprintf("MONITORING!A%d", row),
+		&sheets.ValueRange{Values: [][]any{dataRow}},
+	).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing MONITORING row %d: %w", row, err)
+	}
+	w.currentRun().record("MONITORING", WriteAppend, [][]any{dataRow})
+	return nil
+}
 
+// writeMonitoringHeaders rewrites header rows 1-2 of the MONITORING sheet
+// once per writer, so the sheet stays in sync with monitoringColumns. The
+// old "write only when empty" path left stale labels (e.g. "EURMTL overall
+// payment per month" for what is now the cumulative slot) frozen forever
+// after the slice changed.
+func (w *SheetsWriter) writeMonitoringHeaders(ctx context.Context, sheetID int64) error {
+	w.mu.Lock()
+	done := w.headerSheets[sheetID]
+	w.mu.Unlock()
+	if done {
+		return nil
+	}
+	_, err := w.svc.Spreadsheets.Values.Update(
+		w.spreadsheetID,
+		"MONITORING!A1",
+		&sheets.ValueRange{Values: MonitoringHeaderRows()},
+	).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing MONITORING headers: %w", err)
+	}
+	w.mu.Lock()
+	if w.headerSheets == nil {
+		w.headerSheets = map[int64]bool{}
+	}
+	w.headerSheets[sheetID] = true
+	w.mu.Unlock()
 	return nil
 }
 
@@ -288,18 +362,25 @@ func (w *SheetsWriter) appendMonitoringRow(ctx context.Context, rows []Indicator
 // monitoring column at index col (0-based, including the date column at 0).
 // The pattern is derived from the mapped indicator's precision so the display
 // stays in sync with the rounding policy in indicator.IndicatorMeta. Columns
-// without a mapped indicator (fixedValue or always-nil placeholders) fall
-// back to the integer pattern, which is harmless for the literal 4.0 in
-// "Regulatory Price" and ignored for nil cells.
-func monitoringValuePattern(col int) string {
+// without a mapped indicator fall back to the integer pattern, which is
+// ignored for their nil cells. EUR-denominated columns, Regulatory Price
+// among them, use loc's currency pattern, as in IND_ALL and IND_MAIN. Note
+// columns are text and get no pattern.
+func monitoringValuePattern(loc Locale, col int) string {
 	if col == 0 || col > len(monitoringColumns) {
 		return ""
 	}
 	c := monitoringColumns[col-1]
+	if c.note != noNote {
+		return ""
+	}
+	if c.regulatoryPrice {
+		return loc.currencyPattern(numberFormatPattern(2))
+	}
 	if c.indicatorID == 0 {
 		return "#,##0"
 	}
-	return numberFormatPattern(indicator.PrecisionOf(c.indicatorID))
+	return loc.valuePattern(c.indicatorID)
 }
 
 // applyMonitoringFormatting applies visual formatting to the MONITORING sheet,
@@ -382,10 +463,10 @@ func (w *SheetsWriter) applyMonitoringFormatting(ctx context.Context, mon sheetM
 		&sheets.CellFormat{HorizontalAlignment: "CENTER"},
 		"userEnteredFormat.horizontalAlignment"))
 
-	// Date column A: date format d.m.yyyy, light green background (matching original Excel)
+	// Date column A: locale date pattern, light green background (matching original Excel)
 	reqs = append(reqs, cellFormatReq(mon.id, 2, 10000, 0, 1,
 		&sheets.CellFormat{
-			NumberFormat:    &sheets.NumberFormat{Type: "DATE", Pattern: "d.m.yyyy"},
+			NumberFormat:    &sheets.NumberFormat{Type: "DATE", Pattern: w.locale.DatePattern},
 			BackgroundColor: lightGreen,
 		},
 		"userEnteredFormat(numberFormat,backgroundColor)"))
@@ -395,7 +476,7 @@ func (w *SheetsWriter) applyMonitoringFormatting(ctx context.Context, mon sheetM
 	// ratios/per-share amounts no longer leak shopspring's 16-digit division
 	// output into the rendered sheet.
 	for col := 1; col <= len(monitoringColumns); col++ {
-		pattern := monitoringValuePattern(col)
+		pattern := monitoringValuePattern(w.locale, col)
 		if pattern == "" {
 			continue
 		}
@@ -413,7 +494,7 @@ func (w *SheetsWriter) applyMonitoringFormatting(ctx context.Context, mon sheetM
 
 	// Column widths sized to fit content: wide for large monetary columns,
 	// narrow for empty placeholders. Key is the sheet column index (0 = Date,
-	// 1..53 = monitoringColumns positions). Unset indexes fall back to 35px.
+	// 1..58 = monitoringColumns positions). Unset indexes fall back to 35px.
 	monColWidths := map[int64]int64{
 		0:  65,
 		1:  85,
@@ -467,6 +548,11 @@ func (w *SheetsWriter) applyMonitoringFormatting(ctx context.Context, mon sheetM
 		51: 75,
 		52: 75,
 		53: 70,
+		54: 55,
+		55: 160,
+		56: 65,
+		57: 65,
+		58: 200,
 	}
 	for col := range totalCols {
 		px := int64(35)
@@ -23,9 +23,11 @@ func TestBuildMonitoringRows(t *testing.T) {
 		{Indicator: indicator.Indicator{ID: 43, Value: decimal.NewFromFloat(12.34)}},
 		{Indicator: indicator.Indicator{ID: 61, Value: decimal.NewFromInt(95000)}},
 		{Indicator: indicator.Indicator{ID: 62, Value: decimal.NewFromFloat(310.0)}},
+		{Indicator: indicator.Indicator{ID: 73, Value: decimal.NewFromInt(1200)}},
 	}
 
-	headerRows, dataRow := buildMonitoringRows(rows, at)
+	headerRows, dataRow := buildMonitoringRows(rows, at, DefaultLocale,
+		MonitoringNotes{Issuance: "MTL +100 issuance", Annotations: "DEFI received EUR 200k tranche"})
 
 	// Check header structure
 	if len(headerRows) != 2 {
@@ -35,15 +37,15 @@ func TestBuildMonitoringRows(t *testing.T) {
 	colNumRow := headerRows[0]
 	headerRow := headerRows[1]
 
-	// 54 columns: Date + 53 data columns
-	if len(colNumRow) != 54 {
-		t.Errorf("col num row: expected 54 columns, got %d", len(colNumRow))
+	// 59 columns: Date + 58 data columns
+	if len(colNumRow) != 59 {
+		t.Errorf("col num row: expected 59 columns, got %d", len(colNumRow))
 	}
-	if len(headerRow) != 54 {
-		t.Errorf("header row: expected 54 columns, got %d", len(headerRow))
+	if len(headerRow) != 59 {
+		t.Errorf("header row: expected 59 columns, got %d", len(headerRow))
 	}
-	if len(dataRow) != 54 {
-		t.Errorf("data row: expected 54 columns, got %d", len(dataRow))
+	if len(dataRow) != 59 {
+		t.Errorf("data row: expected 59 columns, got %d", len(dataRow))
 	}
 
 	// Row 1: column A is blank, mapped slots show indicator ID, placeholders
@@ -72,6 +74,9 @@ func TestBuildMonitoringRows(t *testing.T) {
 	if colNumRow[53] != 61.0 {
 		t.Errorf("col num row[53]: expected 61.0 (I61 BTC Rate), got %v", colNumRow[53])
 	}
+	if colNumRow[54] != 65.0 {
+		t.Errorf("col num row[54]: expected 65.0 (I65 Data Quality Score), got %v", colNumRow[54])
+	}
 
 	// Row 2: header names
 	if headerRow[0] != "Date" {
@@ -104,7 +109,7 @@ func TestBuildMonitoringRows(t *testing.T) {
 		t.Errorf("data row Regulatory Price: expected 4.0, got %v", dataRow[9])
 	}
 
-	// Dividends in btcmtl (index 13) — unmapped, fixedValue nil
+	// Dividends in btcmtl (index 13) — unmapped placeholder
 	if dataRow[13] != nil {
 		t.Errorf("data row Dividends in btcmtl: expected nil, got %v", dataRow[13])
 	}
@@ -145,17 +150,47 @@ func TestBuildMonitoringRows(t *testing.T) {
 		t.Errorf("data row I43: expected 12.34, got %v", dataRow[42])
 	}
 
-	// I61 BTC Rate (index 53) — last column
+	// I61 BTC Rate (index 53)
 	if headerRow[53] != "BTC Rate" {
 		t.Errorf("header row[53]: expected 'BTC Rate', got %v", headerRow[53])
 	}
 	if v, ok := dataRow[53].(float64); !ok || v != 95000.0 {
 		t.Errorf("data row I61: expected 95000.0, got %v", dataRow[53])
 	}
+
+	// Issuance annotation (index 55) carries the note as text
+	if headerRow[55] != "Issuance / Buyback" {
+		t.Errorf("header row[55]: expected 'Issuance / Buyback', got %v", headerRow[55])
+	}
+	if dataRow[55] != "MTL +100 issuance" {
+		t.Errorf("data row note: expected 'MTL +100 issuance', got %v", dataRow[55])
+	}
+	if p := monitoringValuePattern(DefaultLocale, 55); p != "" {
+		t.Errorf("note column pattern: expected none, got %q", p)
+	}
+
+	// I73/I74 conversions (indexes 56, 57) follow the annotation column
+	if colNumRow[56] != 73.0 || colNumRow[57] != 74.0 {
+		t.Errorf("conversion column IDs: got %v, %v", colNumRow[56], colNumRow[57])
+	}
+	if v, ok := dataRow[56].(float64); !ok || v != 1200.0 {
+		t.Errorf("data row I73: expected 1200.0, got %v", dataRow[56])
+	}
+	if dataRow[57] != nil {
+		t.Errorf("data row I74: expected nil, got %v", dataRow[57])
+	}
+
+	// Operator annotations (index 58) close the row
+	if headerRow[58] != "Notes" || dataRow[58] != "DEFI received EUR 200k tranche" {
+		t.Errorf("notes column: header %v, value %v", headerRow[58], dataRow[58])
+	}
+	if p := monitoringValuePattern(DefaultLocale, 58); p != "" {
+		t.Errorf("notes column pattern: expected none, got %q", p)
+	}
 }
 
 func TestMonitoringColumnCount(t *testing.T) {
-	if len(monitoringColumns) != 53 {
-		t.Errorf("expected 53 monitoring columns, got %d", len(monitoringColumns))
+	if len(monitoringColumns) != 58 {
+		t.Errorf("expected 58 monitoring columns, got %d", len(monitoringColumns))
 	}
 }
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultRegulatoryPrice is the MONITORING Regulatory Price of an entity
+// without one configured through /api/v1/admin/regulatory-price.
+var DefaultRegulatoryPrice = decimal.NewFromInt(4)
+
+// DefaultMainIndicatorIDs are the IND_MAIN indicators of an entity without
+// a set configured through /api/v1/admin/main-indicators. Based on the
+// MTL_report_1.xlsx example file.
+var DefaultMainIndicatorIDs = []int{1, 2, 3, 4, 5, 6, 7, 8, 10, 11, 15, 16, 17, 18, 22, 24, 27, 30, 40, 66}
+
+// MainIndicatorSource supplies the IND_MAIN indicator set of an entity
+// (PgParameters).
+type MainIndicatorSource interface {
+	MainIndicators(ctx context.Context, slug string) ([]int, error)
+}
+
+// WithMainIndicators reads the IND_MAIN set from source on every export
+// instead of using DefaultMainIndicatorIDs.
+func WithMainIndicators(source MainIndicatorSource) ServiceOption {
+	return func(s *Service) {
+		s.main = source
+	}
+}
+
+// PgParameters reads the admin-managed export parameters from the
+// regulatory_price and main_indicators tables.
+type PgParameters struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgParameters creates a new PostgreSQL export parameter reader.
+func NewPgParameters(pool *pgxpool.Pool) *PgParameters {
+	return &PgParameters{pool: pool}
+}
+
+// RegulatoryPrice returns the configured regulatory price of the entity, or
+// DefaultRegulatoryPrice when none is stored.
+func (p *PgParameters) RegulatoryPrice(ctx context.Context, slug string) (decimal.Decimal, error) {
+	var price decimal.Decimal
+	err := p.pool.QueryRow(ctx,
+		`SELECT rp.price
+		 FROM regulatory_price rp
+		 JOIN fund_entities fe ON fe.id = rp.entity_id
+		 WHERE fe.slug = $1`, slug).Scan(&price)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultRegulatoryPrice, nil
+	}
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("loading regulatory price: %w", err)
+	}
+	return price, nil
+}
+
+// MainIndicators implements MainIndicatorSource: the stored IND_MAIN set of
+// the entity, or DefaultMainIndicatorIDs when none is stored.
+func (p *PgParameters) MainIndicators(ctx context.Context, slug string) ([]int, error) {
+	var ids []int
+	err := p.pool.QueryRow(ctx,
+		`SELECT mi.ids
+		 FROM main_indicators mi
+		 JOIN fund_entities fe ON fe.id = mi.entity_id
+		 WHERE fe.slug = $1`, slug).Scan(&ids)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultMainIndicatorIDs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading IND_MAIN indicators: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+// buildPeerRows lays out the PEERS sheet: a title row, a header, the fund's
+// own row, then one row per peer. Missing ratios are left as empty cells;
+// failed peers show their error in the last column.
+func buildPeerRows(c analytics.PeerComparison) [][]any {
+	data := [][]any{
+		{fmt.Sprintf("Peer comparison, snapshot %s", c.Date)},
+		{"Name", "Address", "Assets, EURMTL", "Tokens", "Holders", "Value vs fund", "Holders vs fund", "Error"},
+	}
+	for _, r := range append([]analytics.PeerRow{c.Fund}, c.Peers...) {
+		data = append(data, []any{
+			r.Name, r.Address, toFloat(r.TotalEURMTL), r.TokenCount, r.HolderCount,
+			ptrFloat(r.ValueVsFund), ptrFloat(r.HoldersVsFund), r.Error,
+		})
+	}
+	return data
+}
+
+// WritePeers rewrites the PEERS sheet with the given comparison.
+func (w *SheetsWriter) WritePeers(ctx context.Context, c analytics.PeerComparison) error {
+	if _, err := w.ensureSheets(ctx, "PEERS"); err != nil {
+		return err
+	}
+
+	if _, err := w.svc.Spreadsheets.Values.Clear(w.spreadsheetID, "PEERS", &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("clearing PEERS sheet: %w", err)
+	}
+
+	rows := buildPeerRows(c)
+	_, err := w.svc.Spreadsheets.Values.Update(w.spreadsheetID, "PEERS!A1", &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing PEERS sheet: %w", err)
+	}
+	w.currentRun().record("PEERS", WriteRewrite, rows)
+	return nil
+}
@@ -0,0 +1,36 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/analytics"
+)
+
+func TestBuildPeerRows(t *testing.T) {
+	half := decimal.RequireFromString("0.5")
+	c := analytics.PeerComparison{
+		Date: "2026-10-01",
+		Fund: analytics.PeerRow{Name: "MTLF", TotalEURMTL: decimal.NewFromInt(1000), TokenCount: 20, HolderCount: 300},
+		Peers: []analytics.PeerRow{
+			{Name: "PEER", Address: "GPEER", TotalEURMTL: decimal.NewFromInt(500), ValueVsFund: &half},
+			{Name: "DOWN", Address: "GDOWN", Error: "fetching portfolio: timeout"},
+		},
+	}
+
+	rows := buildPeerRows(c)
+
+	if len(rows) != 5 {
+		t.Fatalf("got %d rows, want 5 (title, header, fund, 2 peers)", len(rows))
+	}
+	if rows[2][0] != "MTLF" || rows[2][2] != 1000.0 || rows[2][5] != nil {
+		t.Errorf("fund row = %v", rows[2])
+	}
+	if rows[3][5] != 0.5 || rows[3][6] != nil {
+		t.Errorf("peer row = %v, want value ratio 0.5 and no holder ratio", rows[3])
+	}
+	if rows[4][7] != "fetching portfolio: timeout" {
+		t.Errorf("failed peer row = %v", rows[4])
+	}
+}
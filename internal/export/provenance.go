@@ -0,0 +1,86 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// ProvenanceSheet is the hidden sheet that mirrors MONITORING with the
+// source of each stored value instead of the value.
+const ProvenanceSheet = "PROVENANCE"
+
+// buildProvenanceRows lays out the PROVENANCE sheet: the MONITORING header
+// rows, then one row per stored date with "<source> <computed date>" in each
+// column whose indicator has a stored value. Cells without a value are empty.
+func buildProvenanceRows(points []indicator.Provenance, loc Locale) [][]any {
+	colIDs := MonitoringColumnIndicatorIDs()
+	colsByID := make(map[int][]int, len(colIDs))
+	for i, id := range colIDs {
+		if id != 0 {
+			colsByID[id] = append(colsByID[id], i)
+		}
+	}
+
+	data := MonitoringHeaderRows()
+	var row []any
+	var date time.Time
+	for _, p := range points {
+		cols, ok := colsByID[p.IndicatorID]
+		if !ok {
+			continue
+		}
+		if row == nil || !p.SnapshotDate.Equal(date) {
+			date = p.SnapshotDate
+			row = make([]any, 1+len(colIDs))
+			row[0] = loc.FormatDate(date)
+			for i := 1; i < len(row); i++ {
+				row[i] = ""
+			}
+			data = append(data, row)
+		}
+		for _, c := range cols {
+			row[c+1] = fmt.Sprintf("%s %s", p.Source, p.ComputedAt.UTC().Format("2006-01-02"))
+		}
+	}
+	return data
+}
+
+// WriteProvenance rewrites the PROVENANCE sheet from points (ordered by
+// date, as indicator.PgRepository.GetProvenance returns them) and hides it,
+// so it is there for audits without cluttering the spreadsheet.
+func (w *SheetsWriter) WriteProvenance(ctx context.Context, points []indicator.Provenance) error {
+	metas, err := w.ensureSheets(ctx, ProvenanceSheet)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.svc.Spreadsheets.Values.Clear(w.spreadsheetID, ProvenanceSheet, &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("clearing %s sheet: %w", ProvenanceSheet, err)
+	}
+	rows := buildProvenanceRows(points, w.locale)
+	_, err = w.svc.Spreadsheets.Values.Update(w.spreadsheetID, ProvenanceSheet+"!A1", &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing %s sheet: %w", ProvenanceSheet, err)
+	}
+	w.currentRun().record(ProvenanceSheet, WriteRewrite, rows)
+
+	_, err = w.svc.Spreadsheets.BatchUpdate(w.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+				Properties: &sheets.SheetProperties{SheetId: metas[ProvenanceSheet].id, Hidden: true},
+				Fields:     "hidden",
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("hiding %s sheet: %w", ProvenanceSheet, err)
+	}
+	return nil
+}
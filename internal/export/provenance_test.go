@@ -0,0 +1,46 @@
+package export
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func TestBuildProvenanceRows(t *testing.T) {
+	d1 := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	d2 := d1.AddDate(0, 0, 1)
+	computed := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+	points := []indicator.Provenance{
+		{SnapshotDate: d1, IndicatorID: 3, Value: decimal.NewFromInt(1), Source: indicator.SourceSheet, ComputedAt: computed},
+		{SnapshotDate: d1, IndicatorID: 9999, Source: indicator.SourceMeasured, ComputedAt: computed}, // not a MONITORING column
+		{SnapshotDate: d2, IndicatorID: 3, Source: indicator.SourceMeasured, ComputedAt: d2},
+	}
+
+	rows := buildProvenanceRows(points, DefaultLocale)
+
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 2 header rows and 2 dates", len(rows))
+	}
+	col := slices.Index(MonitoringColumnIndicatorIDs(), 3) + 1
+	if col == 0 {
+		t.Fatal("I3 is not a MONITORING column")
+	}
+	if rows[2][0] != "30.09.2026" || rows[2][col] != "sheet 2026-10-05" {
+		t.Errorf("first date row: date %v, I3 cell %v", rows[2][0], rows[2][col])
+	}
+	if rows[3][0] != "01.10.2026" || rows[3][col] != "measured 2026-10-01" {
+		t.Errorf("second date row: date %v, I3 cell %v", rows[3][0], rows[3][col])
+	}
+	if len(rows[2]) != len(rows[1]) {
+		t.Errorf("data row has %d cells, header %d", len(rows[2]), len(rows[1]))
+	}
+	for i, cell := range rows[3][1:] {
+		if i+1 != col && cell != "" {
+			t.Errorf("column %d = %v, want empty", i+1, cell)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// CellSource tells where a MonitoringCell's content comes from.
+type CellSource string
+
+const (
+	CellIndicator CellSource = "indicator" // the stored value of IndicatorID
+	CellMissing   CellSource = "missing"   // IndicatorID has no value on this date
+	CellFixed     CellSource = "fixed"     // a configured parameter: Regulatory Price
+	CellEmpty     CellSource = "empty"     // placeholder slot without a value
+	CellNote      CellSource = "note"      // free text: issuance or annotations
+)
+
+// MonitoringCell is one data column (B onwards) of a MonitoringReport.
+type MonitoringCell struct {
+	Column      int              `json:"column"` // 1-based position after the date column
+	Header      string           `json:"header"`
+	IndicatorID int              `json:"indicatorId,omitempty"`
+	Value       *decimal.Decimal `json:"value,omitempty"`
+	Text        string           `json:"text,omitempty"` // note cells only
+	Source      CellSource       `json:"source"`
+}
+
+// MonitoringReport is the MONITORING row of one date as data: every column
+// in monitoringColumns order with its value and where it came from. The
+// Sheets, CSV and XLSX writers and GET /api/v1/monitoring all render it.
+type MonitoringReport struct {
+	Date    time.Time        `json:"date"`
+	Columns []MonitoringCell `json:"columns"`
+}
+
+// NewMonitoringReport maps the indicator rows of date and its notes onto
+// the MONITORING columns.
+func NewMonitoringReport(date time.Time, rows []IndicatorRow, notes MonitoringNotes) MonitoringReport {
+	byID := lo.KeyBy(rows, func(r IndicatorRow) int { return r.ID })
+	cells := make([]MonitoringCell, len(monitoringColumns))
+	for i, col := range monitoringColumns {
+		c := MonitoringCell{Column: i + 1, Header: col.header, IndicatorID: col.indicatorID}
+		switch {
+		case col.note != noNote:
+			c.Text, c.Source = notes.of(col.note), CellNote
+		case col.indicatorID != 0:
+			if ind, ok := byID[col.indicatorID]; ok {
+				v := ind.Value
+				c.Value, c.Source = &v, CellIndicator
+			} else {
+				slog.Debug("monitoring: indicator missing, writing empty cell",
+					"indicatorID", col.indicatorID,
+					"column", col.header,
+				)
+				c.Source = CellMissing
+			}
+		case col.regulatoryPrice:
+			v := DefaultRegulatoryPrice
+			if notes.RegulatoryPrice != nil {
+				v = *notes.RegulatoryPrice
+			}
+			c.Value, c.Source = &v, CellFixed
+		default:
+			c.Source = CellEmpty
+		}
+		cells[i] = c
+	}
+	return MonitoringReport{Date: date, Columns: cells}
+}
+
+// SheetRow renders the report as a MONITORING data row: the date as text in
+// loc's format, numbers as float64, empty cells as nil.
+func (r MonitoringReport) SheetRow(loc Locale) []any {
+	row := make([]any, 1+len(r.Columns))
+	row[0] = loc.FormatDate(r.Date)
+	for i, c := range r.Columns {
+		switch {
+		case c.Source == CellNote:
+			row[i+1] = c.Text
+		case c.Value != nil:
+			row[i+1] = toFloat(*c.Value)
+		}
+	}
+	return row
+}
+
+// WriteMonitoringCSV writes reports as CSV: a header row (Date and the
+// column headers), then one row per report with ISO dates and exact
+// decimals.
+func WriteMonitoringCSV(w io.Writer, reports []MonitoringReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"Date"}, MonitoringColumnHeaders()...)); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		rec := make([]string, 1+len(r.Columns))
+		rec[0] = r.Date.Format("2006-01-02")
+		for i, c := range r.Columns {
+			switch {
+			case c.Source == CellNote:
+				rec[i+1] = c.Text
+			case c.Value != nil:
+				rec[i+1] = c.Value.String()
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMonitoringXLSX writes reports as a workbook with a MONITORING sheet in
+// the Google Sheets layout (MonitoringHeaderRows, then one row per report,
+// dates in loc's format), so `stat import-excel` can read it back.
+func WriteMonitoringXLSX(w io.Writer, reports []MonitoringReport, loc Locale) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", "MONITORING"); err != nil {
+		return err
+	}
+	rows := MonitoringHeaderRows()
+	for _, r := range reports {
+		rows = append(rows, r.SheetRow(loc))
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow("MONITORING", cell, &row); err != nil {
+			return fmt.Errorf("writing MONITORING row %d: %w", i+1, err)
+		}
+	}
+	_, err := f.WriteTo(w)
+	return err
+}
+
+// ErrNoIndicators is returned by MonitoringService.Report for a date without
+// stored indicators.
+var ErrNoIndicators = errors.New("no indicators stored for date")
+
+// IndicatorStore reads stored indicator values (indicator.PgRepository).
+type IndicatorStore interface {
+	GetByDate(ctx context.Context, slug string, date time.Time) ([]indicator.Indicator, error)
+	GetLatest(ctx context.Context, slug string) ([]indicator.Indicator, time.Time, error)
+}
+
+// NotesSource supplies the note cells of a date.
+type NotesSource interface {
+	MonitoringNotes(ctx context.Context, slug string, date time.Time) (MonitoringNotes, error)
+}
+
+// MonitoringService builds MonitoringReports from stored indicators, the
+// same values the daily export appended.
+type MonitoringService struct {
+	indicators IndicatorStore
+	notes      NotesSource
+	slug       string
+}
+
+// NewMonitoringService creates a MonitoringService. notes may be nil, which
+// leaves the note cells empty.
+func NewMonitoringService(indicators IndicatorStore, notes NotesSource) *MonitoringService {
+	return &MonitoringService{indicators: indicators, notes: notes, slug: "mtlf"}
+}
+
+// Report returns the MONITORING row of date, or of the latest date with
+// stored indicators when date is zero.
+func (s *MonitoringService) Report(ctx context.Context, date time.Time) (MonitoringReport, error) {
+	if date.IsZero() {
+		_, latest, err := s.indicators.GetLatest(ctx, s.slug)
+		if err != nil {
+			return MonitoringReport{}, err
+		}
+		if latest.IsZero() {
+			return MonitoringReport{}, ErrNoIndicators
+		}
+		date = latest
+	}
+	inds, err := s.indicators.GetByDate(ctx, s.slug, date)
+	if errors.Is(err, indicator.ErrNotFound) {
+		return MonitoringReport{}, ErrNoIndicators
+	}
+	if err != nil {
+		return MonitoringReport{}, err
+	}
+
+	var notes MonitoringNotes
+	if s.notes != nil {
+		if notes, err = s.notes.MonitoringNotes(ctx, s.slug, date); err != nil {
+			return MonitoringReport{}, err
+		}
+	}
+	rows := lo.Map(inds, func(ind indicator.Indicator, _ int) IndicatorRow { return IndicatorRow{Indicator: ind} })
+	return NewMonitoringReport(date, rows, notes), nil
+}
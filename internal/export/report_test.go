@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+func cellByHeader(t *testing.T, r MonitoringReport, header string) MonitoringCell {
+	t.Helper()
+	for _, c := range r.Columns {
+		if c.Header == header {
+			return c
+		}
+	}
+	t.Fatalf("no column %q", header)
+	return MonitoringCell{}
+}
+
+func TestNewMonitoringReportSources(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	rows := []IndicatorRow{{Indicator: indicator.Indicator{ID: 1, Value: decimal.RequireFromString("4020758.507")}}}
+	r := NewMonitoringReport(date, rows, MonitoringNotes{Issuance: "MTL +100 issuance"})
+
+	if len(r.Columns) != len(monitoringColumns) {
+		t.Fatalf("got %d columns, want %d", len(r.Columns), len(monitoringColumns))
+	}
+	for header, want := range map[string]CellSource{
+		"Market Cap EUR":      CellIndicator,
+		"Market Cap BTC":      CellMissing,
+		"Regulatory Price":    CellFixed,
+		"Dividends in btcmtl": CellEmpty,
+		"Issuance / Buyback":  CellNote,
+		"Notes":               CellNote,
+	} {
+		if got := cellByHeader(t, r, header).Source; got != want {
+			t.Errorf("%s: source %q, want %q", header, got, want)
+		}
+	}
+	if c := cellByHeader(t, r, "Market Cap EUR"); c.IndicatorID != 1 || c.Value == nil || c.Value.String() != "4020758.507" {
+		t.Errorf("Market Cap EUR = %+v", c)
+	}
+	if c := cellByHeader(t, r, "Issuance / Buyback"); c.Text != "MTL +100 issuance" {
+		t.Errorf("issuance note = %q", c.Text)
+	}
+
+	// The sheet row is the same data as buildMonitoringRows always produced.
+	row := r.SheetRow(DefaultLocale)
+	if row[0] != "01.10.2026" || row[1] != 4020758.507 || row[2] != nil || row[9] != 4.0 {
+		t.Errorf("sheet row starts %v", row[:10])
+	}
+
+	price := decimal.RequireFromString("4.5")
+	r = NewMonitoringReport(date, rows, MonitoringNotes{RegulatoryPrice: &price})
+	if c := cellByHeader(t, r, "Regulatory Price"); c.Source != CellFixed || c.Value == nil || !c.Value.Equal(price) {
+		t.Errorf("configured Regulatory Price = %+v, want 4.5", c)
+	}
+}
+
+func TestWriteMonitoringCSV(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	r := NewMonitoringReport(date, []IndicatorRow{{Indicator: indicator.Indicator{ID: 1, Value: decimal.RequireFromString("0.1")}}}, MonitoringNotes{})
+
+	var buf bytes.Buffer
+	if err := WriteMonitoringCSV(&buf, []MonitoringReport{r}); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0][0] != "Date" || recs[0][1] != "Market Cap EUR" {
+		t.Fatalf("records = %v", recs)
+	}
+	if recs[1][0] != "2026-10-01" || recs[1][1] != "0.1" || recs[1][2] != "" || recs[1][9] != "4" {
+		t.Errorf("data row starts %v", recs[1][:10])
+	}
+}
+
+func TestWriteMonitoringXLSX(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	r := NewMonitoringReport(date, []IndicatorRow{{Indicator: indicator.Indicator{ID: 1, Value: decimal.NewFromInt(42)}}}, MonitoringNotes{})
+
+	var buf bytes.Buffer
+	if err := WriteMonitoringXLSX(&buf, []MonitoringReport{r}, DefaultLocale); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("MONITORING")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1][0] != "Date" || rows[2][0] != "01.10.2026" || rows[2][1] != "42" {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+type stubIndicatorStore struct {
+	byDate map[time.Time][]indicator.Indicator
+	latest time.Time
+}
+
+func (s stubIndicatorStore) GetByDate(_ context.Context, _ string, date time.Time) ([]indicator.Indicator, error) {
+	inds, ok := s.byDate[date]
+	if !ok {
+		return nil, indicator.ErrNotFound
+	}
+	return inds, nil
+}
+
+func (s stubIndicatorStore) GetLatest(context.Context, string) ([]indicator.Indicator, time.Time, error) {
+	return nil, s.latest, nil
+}
+
+type stubNotes MonitoringNotes
+
+func (n stubNotes) MonitoringNotes(context.Context, string, time.Time) (MonitoringNotes, error) {
+	return MonitoringNotes(n), nil
+}
+
+func TestMonitoringServiceReport(t *testing.T) {
+	older := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	store := stubIndicatorStore{latest: latest, byDate: map[time.Time][]indicator.Indicator{
+		older:  {{ID: 1, Value: decimal.NewFromInt(1)}},
+		latest: {{ID: 1, Value: decimal.NewFromInt(2)}},
+	}}
+	svc := NewMonitoringService(store, stubNotes{Annotations: "tranche"})
+
+	r, err := svc.Report(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Date.Equal(latest) || cellByHeader(t, r, "Market Cap EUR").Value.IntPart() != 2 || cellByHeader(t, r, "Notes").Text != "tranche" {
+		t.Errorf("latest report = %+v", r.Columns[:2])
+	}
+
+	if r, err = svc.Report(context.Background(), older); err != nil || cellByHeader(t, r, "Market Cap EUR").Value.IntPart() != 1 {
+		t.Errorf("older report: %v", err)
+	}
+
+	if _, err := svc.Report(context.Background(), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoIndicators) {
+		t.Errorf("missing date err = %v, want ErrNoIndicators", err)
+	}
+	if _, err := NewMonitoringService(stubIndicatorStore{}, nil).Report(context.Background(), time.Time{}); !errors.Is(err, ErrNoIndicators) {
+		t.Errorf("empty store err = %v, want ErrNoIndicators", err)
+	}
+}
@@ -3,10 +3,15 @@ package export
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 	sheets "google.golang.org/api/sheets/v4"
@@ -17,26 +22,66 @@ import (
 // SheetsWriter implements SheetWriter using the Google Sheets API.
 type SheetsWriter struct {
 	spreadsheetID string
+	account       string // client_email of the credentials, for AccessError
 	svc           *sheets.Service
+	locale        Locale
+	transport     http.RoundTripper // nil = the API client's own
+	mirror        MonitoringMirror  // nil = read MONITORING on every append
+
+	mu           sync.Mutex
+	run          *runLog        // writes and API calls since StartRun
+	headerSheets map[int64]bool // MONITORING sheets whose headers this writer rewrote
+}
+
+// WriterOption configures a SheetsWriter.
+type WriterOption func(*SheetsWriter)
+
+// WithLocale sets date and number formatting. The default is DefaultLocale.
+func WithLocale(l Locale) WriterOption {
+	return func(w *SheetsWriter) {
+		w.locale = l
+	}
+}
+
+// WithTransport sends the API and token requests through rt, normally the
+// shared transport.Transport.
+func WithTransport(rt http.RoundTripper) WriterOption {
+	return func(w *SheetsWriter) {
+		w.transport = rt
+	}
 }
 
 // NewSheetsWriter creates a SheetsWriter authenticated with a service account JSON.
-func NewSheetsWriter(ctx context.Context, spreadsheetID, credentialsJSON string) (*SheetsWriter, error) {
+func NewSheetsWriter(ctx context.Context, spreadsheetID, credentialsJSON string, opts ...WriterOption) (*SheetsWriter, error) {
+	w := &SheetsWriter{spreadsheetID: spreadsheetID, locale: DefaultLocale, run: newRunLog()}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	// With a transport, the token exchange goes through it as well.
+	authCtx := ctx
+	if w.transport != nil {
+		authCtx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: w.transport})
+	}
 	creds, err := google.CredentialsFromJSON(
-		ctx,
+		authCtx,
 		[]byte(credentialsJSON),
 		sheets.SpreadsheetsScope,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("parsing google credentials: %w", err)
 	}
+	w.account = clientEmail(creds.JSON)
 
-	svc, err := sheets.NewService(ctx, option.WithCredentials(creds))
+	// API calls are counted for the run summary; token requests are not.
+	client := oauth2.NewClient(authCtx, creds.TokenSource)
+	client.Transport = countingTransport{base: client.Transport, log: w.currentRun}
+	svc, err := sheets.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("creating sheets service: %w", err)
 	}
-
-	return &SheetsWriter{spreadsheetID: spreadsheetID, svc: svc}, nil
+	w.svc = svc
+	return w, nil
 }
 
 // sheetMeta holds the sheet ID and IDs of any existing banded ranges.
@@ -45,12 +90,12 @@ type sheetMeta struct {
 	bandingIDs []int64
 }
 
-// ReadMonitoring fetches the full MONITORING sheet (`A1:BA`) as raw cell values.
+// ReadMonitoring fetches the full MONITORING sheet (`A:BG`) as raw cell values.
 // Cells are returned as strings or numbers (per `valueRenderOption=UNFORMATTED_VALUE`).
 // Caller is responsible for skipping the two header rows.
 func (w *SheetsWriter) ReadMonitoring(ctx context.Context) ([][]any, error) {
 	resp, err := w.svc.Spreadsheets.Values.
-		Get(w.spreadsheetID, "MONITORING!A:BB").
+		Get(w.spreadsheetID, "MONITORING!A:BG").
 		ValueRenderOption("UNFORMATTED_VALUE").
 		DateTimeRenderOption("FORMATTED_STRING").
 		Context(ctx).
@@ -62,15 +107,16 @@ func (w *SheetsWriter) ReadMonitoring(ctx context.Context) ([][]any, error) {
 }
 
 // Write ensures required sheets exist, then clears, rewrites, and formats them.
-func (w *SheetsWriter) Write(ctx context.Context, rows []IndicatorRow) error {
+// Failures go into an errors section below the IND_ALL table.
+func (w *SheetsWriter) Write(ctx context.Context, rows []IndicatorRow, failures []indicator.Failure) error {
 	meta, err := w.ensureSheets(ctx, "IND_ALL", "IND_MAIN")
 	if err != nil {
 		return err
 	}
 
 	now := time.Now()
-	indAllValues := buildIndAll(rows)
-	indMainValues := buildIndMain(rows, now)
+	indAllValues := buildIndAll(rows, failures)
+	indMainValues := buildIndMain(rows, now, w.locale)
 
 	_, err = w.svc.Spreadsheets.Values.BatchClear(
 		w.spreadsheetID,
@@ -95,6 +141,8 @@ func (w *SheetsWriter) Write(ctx context.Context, rows []IndicatorRow) error {
 	if err != nil {
 		return fmt.Errorf("writing sheets: %w", err)
 	}
+	w.currentRun().record("IND_ALL", WriteRewrite, indAllValues)
+	w.currentRun().record("IND_MAIN", WriteRewrite, indMainValues)
 
 	if err := w.applyFormatting(ctx, meta["IND_ALL"], meta["IND_MAIN"], rows); err != nil {
 		return fmt.Errorf("applying formatting: %w", err)
@@ -105,8 +153,10 @@ func (w *SheetsWriter) Write(ctx context.Context, rows []IndicatorRow) error {
 
 // buildIndAll builds the IND_ALL sheet data.
 // Columns: N | Name | Code | Value | measure | Week | Month | Quarter | Year | Descr | Formula | MAIN
-func buildIndAll(rows []IndicatorRow) [][]any {
-	data := make([][]any, 0, len(rows)+1)
+// When failures is non-empty, a blank row and an "Errors" block follow the table:
+// Calculator | Indicators | Status | Error.
+func buildIndAll(rows []IndicatorRow, failures []indicator.Failure) [][]any {
+	data := make([][]any, 0, len(rows)+len(failures)+3)
 	data = append(data, []any{
 		"N", "Name", "Code", "Value", "measure",
 		"Week", "Month", "Quarter", "Year",
@@ -129,15 +179,30 @@ func buildIndAll(rows []IndicatorRow) [][]any {
 		})
 	}
 
+	if len(failures) > 0 {
+		data = append(data, []any{}, []any{"Errors"}, []any{"Calculator", "Indicators", "Status", "Error"})
+		for _, f := range failures {
+			status := "failed"
+			switch {
+			case f.Skipped:
+				status = "unavailable"
+			case f.Stale:
+				status = "stale"
+			}
+			ids := lo.Map(f.IDs, func(id, _ int) string { return "I" + strconv.Itoa(id) })
+			data = append(data, []any{f.Calculator, strings.Join(ids, ", "), status, f.Error})
+		}
+	}
+
 	return data
 }
 
 // buildIndMain builds the IND_MAIN sheet data (only MAIN indicators).
-// Row 1: date stamp. Row 2: headers. Row 3+: data.
+// Row 1: date stamp in the locale's date format. Row 2: headers. Row 3+: data.
 // Columns: Name | Value | measure | Week | Month | Quarter | Year
-func buildIndMain(rows []IndicatorRow, at time.Time) [][]any {
+func buildIndMain(rows []IndicatorRow, at time.Time, loc Locale) [][]any {
 	data := [][]any{
-		{"", at.UTC().Format("02.01.2006 15:04:05")},
+		{"", at.UTC().Format(loc.timestampLayout())},
 		{"Name", "Value", "measure", "Week", "Month", "Quarter", "Year"},
 	}
 
@@ -177,6 +242,16 @@ func (w *SheetsWriter) ensureSheets(ctx context.Context, names ...string) (map[s
 	}
 
 	var requests []*sheets.Request
+	if l := w.locale.SpreadsheetLocale; l != "" && spreadsheet.Properties != nil && spreadsheet.Properties.Locale != l {
+		// Set before any values are written: the locale decides how
+		// USER_ENTERED dates and numbers are parsed.
+		requests = append(requests, &sheets.Request{
+			UpdateSpreadsheetProperties: &sheets.UpdateSpreadsheetPropertiesRequest{
+				Properties: &sheets.SpreadsheetProperties{Locale: l},
+				Fields:     "locale",
+			},
+		})
+	}
 	for _, name := range names {
 		if m, ok := existing[name]; ok {
 			result[name] = m
@@ -258,7 +333,7 @@ func (w *SheetsWriter) applyFormatting(ctx context.Context, indAll, indMain shee
 	reqs = append(reqs, cellFormatReq(indAll.id, 1, allEnd, 3, 4,
 		&sheets.CellFormat{TextFormat: &sheets.TextFormat{Bold: true}},
 		"userEnteredFormat.textFormat"))
-	reqs = append(reqs, valueFormatReqs(indAll.id, 3, 1, allIDs)...)
+	reqs = append(reqs, valueFormatReqs(w.locale, indAll.id, 3, 1, allIDs)...)
 
 	// Code column C (col 2) and measure column E (col 4): centered
 	reqs = append(reqs, cellFormatReq(indAll.id, 1, allEnd, 2, 3,
@@ -338,7 +413,7 @@ func (w *SheetsWriter) applyFormatting(ctx context.Context, indAll, indMain shee
 			VerticalAlignment: "MIDDLE",
 		},
 		"userEnteredFormat(textFormat,verticalAlignment)"))
-	reqs = append(reqs, valueFormatReqs(indMain.id, 1, 2, mainIDs)...)
+	reqs = append(reqs, valueFormatReqs(w.locale, indMain.id, 1, 2, mainIDs)...)
 
 	// Data column C (col 2): centered, v=center
 	reqs = append(reqs, cellFormatReq(indMain.id, 2, mainEnd, 2, 3,
@@ -423,15 +498,15 @@ func numberFormatPattern(precision int32) string {
 
 // valueFormatReqs emits per-row number-format requests for a column over
 // rows[startRow .. startRow+len(ids)). Consecutive rows with the same
-// indicator precision are coalesced into one range request to keep the
-// BatchUpdate small.
-func valueFormatReqs(sheetID, valueCol, startRow int64, ids []int) []*sheets.Request {
+// pattern (precision, plus currency per loc) are coalesced into one range
+// request to keep the BatchUpdate small.
+func valueFormatReqs(loc Locale, sheetID, valueCol, startRow int64, ids []int) []*sheets.Request {
 	if len(ids) == 0 {
 		return nil
 	}
 	var reqs []*sheets.Request
 	runStart := 0
-	runPattern := numberFormatPattern(indicator.PrecisionOf(ids[0]))
+	runPattern := loc.valuePattern(ids[0])
 	flush := func(end int) {
 		reqs = append(reqs, cellFormatReq(sheetID,
 			startRow+int64(runStart), startRow+int64(end),
@@ -442,7 +517,7 @@ func valueFormatReqs(sheetID, valueCol, startRow int64, ids []int) []*sheets.Req
 			"userEnteredFormat.numberFormat"))
 	}
 	for i := 1; i < len(ids); i++ {
-		p := numberFormatPattern(indicator.PrecisionOf(ids[i]))
+		p := loc.valuePattern(ids[i])
 		if p != runPattern {
 			flush(i)
 			runStart = i
@@ -0,0 +1,219 @@
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// MetaSheet is the hidden sheet that keeps one row per sheet per export run,
+// so the spreadsheet itself records what was last written to it.
+const MetaSheet = "_META"
+
+// Sheet write modes.
+const (
+	WriteRewrite = "rewrite" // the sheet was cleared and rewritten
+	WriteAppend  = "append"  // rows were added to the existing ones
+)
+
+// SheetSummary is what one export run wrote to one sheet. Checksum covers
+// every value written to the sheet during the run, in order; recompute it
+// with ValuesChecksum over the same cells to check the sheet still holds them.
+type SheetSummary struct {
+	Sheet    string `json:"sheet"`
+	Mode     string `json:"mode"`
+	Rows     int    `json:"rows"`
+	Checksum string `json:"checksum"`
+}
+
+// RunSummary describes one export run of a SheetsWriter.
+type RunSummary struct {
+	StartedAt    time.Time      `json:"startedAt"`
+	Duration     time.Duration  `json:"duration"`
+	Sheets       []SheetSummary `json:"sheets"`
+	RowsAppended int            `json:"rowsAppended"`
+	APICalls     int64          `json:"apiCalls"`
+}
+
+// ValuesChecksum returns the checksum SheetSummary uses for rows: the first
+// 16 hex digits of a SHA-256 over the cells as checksumCell renders them.
+func ValuesChecksum(rows [][]any) string {
+	h := sha256.New()
+	hashRows(h, rows)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// hashRows feeds rows to h, tab-separated cells, newline-terminated rows.
+func hashRows(h hash.Hash, rows [][]any) {
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				h.Write([]byte{'\t'})
+			}
+			h.Write([]byte(checksumCell(cell)))
+		}
+		h.Write([]byte{'\n'})
+	}
+}
+
+// checksumCell renders a cell independently of how it was typed: numbers in
+// their shortest decimal form, nil as empty.
+func checksumCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(x)
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// runLog accumulates the writes of the current run.
+type runLog struct {
+	mu      sync.Mutex
+	started time.Time
+	order   []string
+	sheets  map[string]*sheetLog
+	calls   atomic.Int64
+}
+
+type sheetLog struct {
+	mode string
+	rows int
+	hash hash.Hash
+}
+
+func newRunLog() *runLog {
+	return &runLog{started: time.Now(), sheets: make(map[string]*sheetLog)}
+}
+
+// record adds rows written to sheet. A sheet rewritten and appended to in
+// the same run is reported as rewritten.
+func (l *runLog) record(sheet, mode string, rows [][]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.sheets[sheet]
+	if !ok {
+		s = &sheetLog{mode: mode, hash: sha256.New()}
+		l.sheets[sheet] = s
+		l.order = append(l.order, sheet)
+	}
+	if mode == WriteRewrite {
+		s.mode = WriteRewrite
+	}
+	s.rows += len(rows)
+	hashRows(s.hash, rows)
+}
+
+func (l *runLog) summary() RunSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sum := RunSummary{StartedAt: l.started, Duration: time.Since(l.started), Sheets: []SheetSummary{}, APICalls: l.calls.Load()}
+	for _, name := range l.order {
+		s := l.sheets[name]
+		sum.Sheets = append(sum.Sheets, SheetSummary{
+			Sheet:    name,
+			Mode:     s.mode,
+			Rows:     s.rows,
+			Checksum: hex.EncodeToString(s.hash.Sum(nil))[:16],
+		})
+		if s.mode == WriteAppend {
+			sum.RowsAppended += s.rows
+		}
+	}
+	return sum
+}
+
+// countingTransport counts the Sheets API round trips of a writer.
+type countingTransport struct {
+	base http.RoundTripper
+	log  func() *runLog
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.log().calls.Add(1)
+	return t.base.RoundTrip(req)
+}
+
+// StartRun begins a new export run: the writes and API calls counted by
+// Summary start from zero.
+func (w *SheetsWriter) StartRun() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.run = newRunLog()
+}
+
+// Summary reports the writes and API calls since StartRun, or since the
+// writer was created.
+func (w *SheetsWriter) Summary() RunSummary {
+	return w.currentRun().summary()
+}
+
+func (w *SheetsWriter) currentRun() *runLog {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.run
+}
+
+// metaHeader is row 1 of the _META sheet.
+var metaHeader = []any{"Run", "Sheet", "Mode", "Rows", "Checksum", "Duration ms", "API calls"}
+
+// buildMetaRows lays out one _META row per sheet of s.
+func buildMetaRows(s RunSummary) [][]any {
+	at := s.StartedAt.UTC().Format(time.RFC3339)
+	rows := make([][]any, 0, len(s.Sheets))
+	for _, sh := range s.Sheets {
+		rows = append(rows, []any{at, sh.Sheet, sh.Mode, sh.Rows, sh.Checksum, s.Duration.Milliseconds(), s.APICalls})
+	}
+	return rows
+}
+
+// WriteMeta appends the rows of s to the hidden _META sheet. Its own API
+// calls are not part of s.
+func (w *SheetsWriter) WriteMeta(ctx context.Context, s RunSummary) error {
+	metas, err := w.ensureSheets(ctx, MetaSheet)
+	if err != nil {
+		return err
+	}
+	_, err = w.svc.Spreadsheets.Values.Update(w.spreadsheetID, MetaSheet+"!A1", &sheets.ValueRange{
+		Values: [][]any{metaHeader},
+	}).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("writing %s header: %w", MetaSheet, err)
+	}
+	if rows := buildMetaRows(s); len(rows) > 0 {
+		_, err = w.svc.Spreadsheets.Values.Append(w.spreadsheetID, MetaSheet+"!A:G", &sheets.ValueRange{
+			Values: rows,
+		}).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("appending %s rows: %w", MetaSheet, err)
+		}
+	}
+
+	_, err = w.svc.Spreadsheets.BatchUpdate(w.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+				Properties: &sheets.SheetProperties{SheetId: metas[MetaSheet].id, Hidden: true},
+				Fields:     "hidden",
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("hiding %s sheet: %w", MetaSheet, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestValuesChecksum(t *testing.T) {
+	a := ValuesChecksum([][]any{{"01.03.2026", 1.5, nil, 2}})
+	if len(a) != 16 {
+		t.Fatalf("checksum %q, want 16 hex digits", a)
+	}
+	if b := ValuesChecksum([][]any{{"01.03.2026", "1.5", "", "2"}}); b != a {
+		t.Errorf("typed and text cells differ: %s vs %s", a, b)
+	}
+	if c := ValuesChecksum([][]any{{"01.03.2026", 1.5}, {nil, 2}}); c == a {
+		t.Error("row boundaries don't change the checksum")
+	}
+}
+
+func TestRunLogSummary(t *testing.T) {
+	l := newRunLog()
+	first := [][]any{{"02.03.2026", 10.0}}
+	second := [][]any{{"03.03.2026", 11.0}}
+	l.record("MONITORING", WriteAppend, first)
+	l.record("IND_ALL", WriteRewrite, [][]any{{"N", "Name"}, {1, "Market Cap EUR"}})
+	l.record("MONITORING", WriteAppend, second)
+	l.calls.Add(3)
+
+	s := l.summary()
+	if len(s.Sheets) != 2 || s.Sheets[0].Sheet != "MONITORING" || s.Sheets[1].Sheet != "IND_ALL" {
+		t.Fatalf("sheets = %+v", s.Sheets)
+	}
+	mon := s.Sheets[0]
+	if mon.Mode != WriteAppend || mon.Rows != 2 || mon.Checksum != ValuesChecksum(append(first, second...)) {
+		t.Errorf("MONITORING = %+v", mon)
+	}
+	if s.Sheets[1].Mode != WriteRewrite || s.Sheets[1].Rows != 2 {
+		t.Errorf("IND_ALL = %+v", s.Sheets[1])
+	}
+	if s.RowsAppended != 2 || s.APICalls != 3 {
+		t.Errorf("rowsAppended = %d, apiCalls = %d", s.RowsAppended, s.APICalls)
+	}
+
+	rows := buildMetaRows(s)
+	if len(rows) != 2 || rows[0][1] != "MONITORING" || rows[0][4] != mon.Checksum || rows[0][6] != int64(3) {
+		t.Errorf("meta rows = %v", rows)
+	}
+}
+
+type okTransport struct{}
+
+func (okTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestStartRunResetsCounts(t *testing.T) {
+	w := &SheetsWriter{run: newRunLog()}
+	client := &http.Client{Transport: countingTransport{base: okTransport{}, log: w.currentRun}}
+	get := func() {
+		resp, err := client.Get("http://sheets.test/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	get()
+	get()
+	w.currentRun().record("CORR", WriteRewrite, [][]any{{"x"}})
+	if s := w.Summary(); s.APICalls != 2 || len(s.Sheets) != 1 {
+		t.Fatalf("before StartRun: %+v", s)
+	}
+
+	w.StartRun()
+	get()
+	s := w.Summary()
+	if s.APICalls != 1 || len(s.Sheets) != 0 || time.Since(s.StartedAt) > time.Minute {
+		t.Errorf("after StartRun: %+v", s)
+	}
+}
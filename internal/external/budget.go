@@ -0,0 +1,122 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CoinGeckoService is the service name CoinGecko requests are counted under.
+const CoinGeckoService = "coingecko"
+
+// budgetWarnShare is the share of the daily budget past which every request
+// is logged (at Info until the budget is spent, then at Error).
+const budgetWarnShare = 0.8
+
+// RequestCounter stores the requests sent to a service per UTC day.
+type RequestCounter interface {
+	// AddRequests adds n requests to day and returns the day's new total.
+	AddRequests(ctx context.Context, service string, day time.Time, n int) (int, error)
+	RequestsOn(ctx context.Context, service string, day time.Time) (int, error)
+}
+
+// BudgetStatus is the state of a daily request budget.
+type BudgetStatus struct {
+	Service   string `json:"service"`
+	Date      string `json:"date"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Remaining int    `json:"remaining"` // negative once over the limit
+}
+
+// Budget tracks the requests sent to a service against a daily limit shared
+// by every process using the same counter. It only counts and warns: the
+// service's own rate limiting is what refuses requests.
+type Budget struct {
+	counter RequestCounter
+	service string
+	limit   int
+	now     func() time.Time
+}
+
+// NewBudget tracks service against limit requests per UTC day.
+func NewBudget(counter RequestCounter, service string, limit int) *Budget {
+	return &Budget{counter: counter, service: service, limit: limit, now: time.Now}
+}
+
+// spend counts one request. A counter failure is logged, never returned: the
+// budget must not stop the request it counts.
+func (b *Budget) spend(ctx context.Context) {
+	used, err := b.counter.AddRequests(ctx, b.service, utcDay(b.now()), 1)
+	if err != nil {
+		slog.Error("counting external request failed", "service", b.service, "error", err)
+		return
+	}
+	switch {
+	case b.limit <= 0:
+	case used >= b.limit:
+		slog.Error("external request budget exhausted", "service", b.service, "used", used, "limit", b.limit)
+	case float64(used) >= budgetWarnShare*float64(b.limit):
+		slog.Info("external request budget nearly spent", "service", b.service, "used", used, "limit", b.limit)
+	}
+}
+
+// Status reports today's use of the budget.
+func (b *Budget) Status(ctx context.Context) (BudgetStatus, error) {
+	day := utcDay(b.now())
+	used, err := b.counter.RequestsOn(ctx, b.service, day)
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	return BudgetStatus{
+		Service:   b.service,
+		Date:      day.Format("2006-01-02"),
+		Limit:     b.limit,
+		Used:      used,
+		Remaining: b.limit - used,
+	}, nil
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PgRequestCounter implements RequestCounter with PostgreSQL.
+type PgRequestCounter struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRequestCounter creates a new PostgreSQL request counter.
+func NewPgRequestCounter(pool *pgxpool.Pool) *PgRequestCounter {
+	return &PgRequestCounter{pool: pool}
+}
+
+func (r *PgRequestCounter) AddRequests(ctx context.Context, service string, day time.Time, n int) (int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO external_request_counts (service, request_date, requests)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (service, request_date) DO UPDATE SET requests = external_request_counts.requests + EXCLUDED.requests
+		 RETURNING requests`,
+		service, day.Format("2006-01-02"), n).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("counting %s requests: %w", service, err)
+	}
+	return total, nil
+}
+
+func (r *PgRequestCounter) RequestsOn(ctx context.Context, service string, day time.Time) (int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(requests), 0) FROM external_request_counts
+		 WHERE service = $1 AND request_date = $2`,
+		service, day.Format("2006-01-02")).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("reading %s request count: %w", service, err)
+	}
+	return total, nil
+}
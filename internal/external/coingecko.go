@@ -7,11 +7,16 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math/rand/v2"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/fault"
 )
 
 // symbolMapping maps internal symbols to CoinGecko IDs.
@@ -40,16 +45,35 @@ type CoinGeckoClient struct {
 	httpClient *http.Client
 	delay      time.Duration
 	maxRetries int
+	budget     *Budget // nil = requests not counted
+}
+
+// CoinGeckoOption configures NewCoinGeckoClient.
+type CoinGeckoOption func(*CoinGeckoClient)
+
+// WithTransport sends requests through rt, normally the shared
+// transport.Transport, instead of a connection pool of the client's own.
+func WithTransport(rt http.RoundTripper) CoinGeckoOption {
+	return func(c *CoinGeckoClient) { c.httpClient.Transport = rt }
+}
+
+// WithBudget counts every request, retries included, against b.
+func WithBudget(b *Budget) CoinGeckoOption {
+	return func(c *CoinGeckoClient) { c.budget = b }
 }
 
 // NewCoinGeckoClient creates a new CoinGecko API client.
-func NewCoinGeckoClient(baseURL string, delay time.Duration, maxRetries int) *CoinGeckoClient {
-	return &CoinGeckoClient{
+func NewCoinGeckoClient(baseURL string, delay time.Duration, maxRetries int, opts ...CoinGeckoOption) *CoinGeckoClient {
+	c := &CoinGeckoClient{
 		baseURL:    baseURL,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		delay:      delay,
 		maxRetries: maxRetries,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 var (
@@ -59,18 +83,103 @@ var (
 
 // FetchPrices fetches EUR prices for all configured symbols from CoinGecko.
 func (c *CoinGeckoClient) FetchPrices(ctx context.Context) (map[string]decimal.Decimal, error) {
-	// Collect unique CoinGecko IDs
-	uniqueIDs := make(map[string]bool)
-	for _, id := range symbolMapping {
-		uniqueIDs[id] = true
+	symbols := make([]string, 0, len(symbolMapping))
+	for symbol := range symbolMapping {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	result, failed := c.FetchSymbolPrices(ctx, symbols)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("CoinGecko returned no valid prices (expected %d symbols): %w", len(symbolMapping), failed[symbols[0]])
+	}
+	if len(failed) > 0 {
+		slog.Error("CoinGecko returned partial prices", "got", len(result), "expected", len(symbolMapping))
+	}
+	return result, nil
+}
+
+// FetchSymbolPrices fetches EUR prices for symbols in one batched request.
+// Each symbol that gets no price — unknown, missing from the response or
+// unparseable — is returned in failed with the reason, without affecting
+// the rest. When the batched request itself is rejected (fault.Permanent or
+// fault.NotFound), every coin is requested on its own, so one rejected ID
+// doesn't drop every price; a rate limit or an outage fails them all.
+func (c *CoinGeckoClient) FetchSymbolPrices(ctx context.Context, symbols []string) (prices map[string]decimal.Decimal, failed map[string]error) {
+	prices = make(map[string]decimal.Decimal, len(symbols))
+	failed = make(map[string]error)
+
+	var coinIDs []string
+	seen := make(map[string]bool)
+	for _, symbol := range symbols {
+		id, ok := symbolMapping[symbol]
+		if !ok {
+			failed[symbol] = fmt.Errorf("no CoinGecko ID for %s", symbol)
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			coinIDs = append(coinIDs, id)
+		}
 	}
+	sort.Strings(coinIDs)
 
-	ids := make([]string, 0, len(uniqueIDs))
-	for id := range uniqueIDs {
-		ids = append(ids, id)
+	coinPrices := make(map[string]decimal.Decimal, len(coinIDs))
+	coinErrs := make(map[string]error)
+	if len(coinIDs) > 0 {
+		batch, err := c.fetchCoinPrices(ctx, coinIDs)
+		rejected := fault.Is(err, fault.Permanent) || fault.Is(err, fault.NotFound)
+		switch {
+		case err == nil:
+			coinPrices = batch
+		case !rejected || ctx.Err() != nil || len(coinIDs) == 1:
+			for _, id := range coinIDs {
+				coinErrs[id] = err
+			}
+		default:
+			slog.Info("CoinGecko batch request failed, fetching coins one by one", "coins", len(coinIDs), "error", err)
+			for i, id := range coinIDs {
+				if i > 0 {
+					select {
+					case <-ctx.Done():
+					case <-time.After(c.delay):
+					}
+				}
+				one, err := c.fetchCoinPrices(ctx, []string{id})
+				if err != nil {
+					coinErrs[id] = err
+					continue
+				}
+				maps.Copy(coinPrices, one)
+			}
+		}
 	}
 
-	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=eur", c.baseURL, strings.Join(ids, ","))
+	for _, symbol := range symbols {
+		id, ok := symbolMapping[symbol]
+		if !ok {
+			continue
+		}
+		if price, ok := coinPrices[id]; ok {
+			prices[symbol] = symbolPrice(symbol, price)
+			continue
+		}
+		if err := coinErrs[id]; err != nil {
+			failed[symbol] = err
+		} else {
+			failed[symbol] = fmt.Errorf("CoinGecko returned no EUR price for %s", id)
+		}
+	}
+	return prices, failed
+}
+
+// fetchCoinPrices returns the EUR price of each coin in coinIDs that the
+// simple/price endpoint answers with a parseable price.
+func (c *CoinGeckoClient) fetchCoinPrices(ctx context.Context, coinIDs []string) (map[string]decimal.Decimal, error) {
+	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=eur", c.baseURL, strings.Join(coinIDs, ","))
 
 	body, err := c.fetchWithRetry(ctx, url)
 	if err != nil {
@@ -85,66 +194,159 @@ func (c *CoinGeckoClient) FetchPrices(ctx context.Context) (map[string]decimal.D
 		return nil, fmt.Errorf("parsing CoinGecko response: %w", err)
 	}
 
-	result := make(map[string]decimal.Decimal)
-	for symbol, coinID := range symbolMapping {
-		prices, ok := raw[coinID]
+	result := make(map[string]decimal.Decimal, len(coinIDs))
+	for _, id := range coinIDs {
+		prices, ok := raw[id]
 		if !ok {
-			slog.Debug("CoinGecko response missing symbol", "symbol", symbol, "coinID", coinID)
+			slog.Debug("CoinGecko response missing coin", "coinID", id)
 			continue
 		}
 		eurStr := prices["eur"].String()
 		eurPrice, err := decimal.NewFromString(eurStr)
 		if err != nil {
-			slog.Debug("CoinGecko price unparseable", "symbol", symbol, "value", eurStr, "error", err)
+			slog.Debug("CoinGecko price unparseable", "coinID", id, "value", eurStr, "error", err)
 			continue
 		}
-
-		switch symbol {
-		case "Sats":
-			// 1 Sat = 1/100_000_000 BTC
-			result[symbol] = eurPrice.Div(satsDiv)
-		case "AU":
-			// Gold price is per troy ounce, convert to per gram
-			result[symbol] = eurPrice.Div(auDiv)
-		default:
-			result[symbol] = eurPrice
-		}
+		result[id] = eurPrice
 	}
+	return result, nil
+}
 
-	if len(result) == 0 {
-		return nil, fmt.Errorf("CoinGecko returned no valid prices (expected %d symbols)", len(symbolMapping))
+// symbolPrice converts a CoinGecko coin price into the unit of symbol.
+func symbolPrice(symbol string, eurPrice decimal.Decimal) decimal.Decimal {
+	switch symbol {
+	case "Sats":
+		// 1 Sat = 1/100_000_000 BTC
+		return eurPrice.Div(satsDiv)
+	case "AU":
+		// Gold price is per troy ounce, convert to per gram
+		return eurPrice.Div(auDiv)
+	default:
+		return eurPrice
 	}
-	if len(result) < len(symbolMapping) {
-		slog.Error("CoinGecko returned partial prices", "got", len(result), "expected", len(symbolMapping))
+}
+
+// FetchPriceHistory fetches daily EUR prices for all configured symbols over
+// [from, to] (UTC dates, inclusive) from the market_chart/range endpoint.
+// CoinGecko returns hourly points for ranges up to 90 days and daily ones
+// beyond; either way the last point of each UTC day is kept as that day's
+// price. Symbols sharing a coin (BTC and Sats) cost one request, and requests
+// are spaced by the client delay to stay under the public rate limit.
+func (c *CoinGeckoClient) FetchPriceHistory(ctx context.Context, from, to time.Time) (map[string]map[time.Time]decimal.Decimal, error) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if !start.Before(end) {
+		return nil, fmt.Errorf("invalid range: from %s is after to %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
 	}
 
-	return result, nil
-}
+	coinIDs := make([]string, 0, len(symbolMapping))
+	seen := make(map[string]bool)
+	for _, id := range symbolMapping {
+		if !seen[id] {
+			seen[id] = true
+			coinIDs = append(coinIDs, id)
+		}
+	}
+	sort.Strings(coinIDs)
 
-func (c *CoinGeckoClient) fetchWithRetry(ctx context.Context, url string) ([]byte, error) {
-	var lastErr error
-	for attempt := range c.maxRetries + 1 {
-		if attempt > 0 {
-			baseDelay := c.delay
-			if baseDelay == 0 {
-				baseDelay = 10 * time.Second
-			}
-			delay := baseDelay * time.Duration(1<<uint(attempt-1))
+	byCoin := make(map[string]map[time.Time]decimal.Decimal, len(coinIDs))
+	for i, id := range coinIDs {
+		if i > 0 {
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
-			case <-time.After(delay):
+			case <-time.After(c.delay):
 			}
 		}
+		daily, err := c.fetchDailyRange(ctx, id, start, end)
+		if err != nil {
+			return nil, err
+		}
+		byCoin[id] = daily
+	}
+
+	result := make(map[string]map[time.Time]decimal.Decimal, len(symbolMapping))
+	for symbol, id := range symbolMapping {
+		prices := make(map[time.Time]decimal.Decimal, len(byCoin[id]))
+		for day, p := range byCoin[id] {
+			prices[day] = symbolPrice(symbol, p)
+		}
+		result[symbol] = prices
+	}
+	return result, nil
+}
+
+// fetchDailyRange returns one EUR price per UTC day for coinID in [start, end).
+func (c *CoinGeckoClient) fetchDailyRange(ctx context.Context, coinID string, start, end time.Time) (map[time.Time]decimal.Decimal, error) {
+	url := fmt.Sprintf("%s/coins/%s/market_chart/range?vs_currency=eur&from=%d&to=%d", c.baseURL, coinID, start.Unix(), end.Unix())
+	body, err := c.fetchWithRetry(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s price history: %w", coinID, err)
+	}
+
+	var raw struct {
+		Prices [][2]json.Number `json:"prices"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("parsing CoinGecko %s price history: %w", coinID, err)
+	}
+
+	daily := make(map[time.Time]decimal.Decimal)
+	latest := make(map[time.Time]int64)
+	for _, point := range raw.Prices {
+		ms, err := point[0].Int64()
+		if err != nil {
+			return nil, fmt.Errorf("parsing CoinGecko %s timestamp %q: %w", coinID, point[0], err)
+		}
+		price, err := decimal.NewFromString(point[1].String())
+		if err != nil {
+			return nil, fmt.Errorf("parsing CoinGecko %s price %q: %w", coinID, point[1], err)
+		}
+		ts := time.UnixMilli(ms).UTC()
+		if ts.Before(start) || !ts.Before(end) {
+			continue
+		}
+		day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+		if prev, ok := latest[day]; !ok || ms >= prev {
+			latest[day] = ms
+			daily[day] = price
+		}
+	}
+	return daily, nil
+}
 
+// Ping checks that the CoinGecko API answers.
+func (c *CoinGeckoClient) Ping(ctx context.Context) error {
+	if _, err := c.fetchWithRetry(ctx, c.baseURL+"/ping"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// fetchWithRetry GETs url through fault.Do. A 429 is retried after the
+// Retry-After the response names, up to fault.MaxRetryAfter, or the backoff
+// when it names none; a 500, 502, 503 or 504 after the backoff: the client
+// delay (10s when unset) doubled per attempt, with up to half of it randomly
+// shaved off so parallel clients spread out. Every attempt counts against
+// the budget.
+func (c *CoinGeckoClient) fetchWithRetry(ctx context.Context, url string) ([]byte, error) {
+	attempt := 0
+	policy := fault.Policy{Service: "coingecko", Retries: c.maxRetries, Backoff: c.backoff}
+	return fault.Do(ctx, policy, func(ctx context.Context) ([]byte, error) {
+		attempt++
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("creating CoinGecko request: %w", err)
 		}
 
+		if c.budget != nil {
+			c.budget.spend(ctx)
+		}
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("CoinGecko request failed: %w", err)
+			return nil, fault.New("coingecko", fault.KindOf(err), fmt.Errorf("CoinGecko request failed: %w", err))
 		}
 
 		const maxResponseSize = 1 << 20 // 1 MB
@@ -154,17 +356,28 @@ func (c *CoinGeckoClient) fetchWithRetry(ctx context.Context, url string) ([]byt
 			return nil, fmt.Errorf("reading CoinGecko response: %w", err)
 		}
 
-		if resp.StatusCode == http.StatusOK {
+		switch resp.StatusCode {
+		case http.StatusOK:
 			return body, nil
+		case http.StatusTooManyRequests:
+			return nil, fault.FromResponse("coingecko", resp.StatusCode, resp.Header,
+				fmt.Errorf("CoinGecko rate limited (attempt %d/%d)", attempt, c.maxRetries+1))
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return nil, fault.FromResponse("coingecko", resp.StatusCode, resp.Header,
+				fmt.Errorf("CoinGecko HTTP %d (attempt %d/%d): %s", resp.StatusCode, attempt, c.maxRetries+1, string(body)))
+		default:
+			return nil, fault.FromResponse("coingecko", resp.StatusCode, resp.Header,
+				fmt.Errorf("CoinGecko HTTP %d: %s", resp.StatusCode, string(body)))
 		}
+	})
+}
 
-		if resp.StatusCode == http.StatusTooManyRequests {
-			lastErr = fmt.Errorf("CoinGecko rate limited (attempt %d/%d)", attempt+1, c.maxRetries+1)
-			continue
-		}
-
-		return nil, fmt.Errorf("CoinGecko HTTP %d: %s", resp.StatusCode, string(body))
+// backoff is the jittered wait before retry attempt (1-based).
+func (c *CoinGeckoClient) backoff(attempt int) time.Duration {
+	base := c.delay
+	if base == 0 {
+		base = 10 * time.Second
 	}
-
-	return nil, lastErr
+	d := base * time.Duration(1<<uint(attempt-1))
+	return d - rand.N(d/2+1)
 }
@@ -2,6 +2,7 @@ package external
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -160,3 +161,142 @@ func TestFetchPricesContextCancelled(t *testing.T) {
 		t.Fatal("expected error on cancelled context")
 	}
 }
+
+func TestFetchPriceHistoryLastPointPerDay(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("vs_currency") != "eur" {
+			t.Errorf("vs_currency = %q, want eur", r.URL.Query().Get("vs_currency"))
+		}
+		price := "1"
+		if r.URL.Path == "/coins/bitcoin/market_chart/range" {
+			price = "50000"
+		}
+		ms := func(t time.Time) int64 { return t.UnixMilli() }
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"prices": [[%d, 1], [%d, %s], [%d, 7], [%d, 99]]}`,
+			ms(day1.Add(time.Hour)), ms(day1.Add(23*time.Hour)), price,
+			ms(day1.AddDate(0, 0, 1).Add(time.Hour)),
+			ms(day1.AddDate(0, 0, 2).Add(time.Hour))) // outside the range
+	}))
+	defer server.Close()
+
+	client := NewCoinGeckoClient(server.URL, 0, 1)
+	history, err := client.FetchPriceHistory(context.Background(), day1, day1.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// bitcoin, ethereum, stellar, tether, pax-gold: Sats shares the bitcoin request
+	if requests != 5 {
+		t.Errorf("requests = %d, want 5", requests)
+	}
+	if got := history["BTC"][day1]; !got.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("BTC on day 1 = %s, want last point 50000", got)
+	}
+	if got := history["Sats"][day1]; !got.Equal(decimal.RequireFromString("0.0005")) {
+		t.Errorf("Sats on day 1 = %s, want 0.0005", got)
+	}
+	if len(history["XLM"]) != 2 {
+		t.Errorf("XLM days = %d, want 2 (point after the range dropped)", len(history["XLM"]))
+	}
+}
+
+func TestFetchPriceHistoryInvalidRange(t *testing.T) {
+	client := NewCoinGeckoClient("http://unused", 0, 1)
+	from := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := client.FetchPriceHistory(context.Background(), from, from.AddDate(0, 0, -1)); err == nil {
+		t.Error("expected error when from is after to")
+	}
+}
+
+// memCounter is an in-memory RequestCounter.
+type memCounter map[string]int
+
+func (m memCounter) AddRequests(_ context.Context, service string, day time.Time, n int) (int, error) {
+	key := service + day.Format("2006-01-02")
+	m[key] += n
+	return m[key], nil
+}
+
+func (m memCounter) RequestsOn(_ context.Context, service string, day time.Time) (int, error) {
+	return m[service+day.Format("2006-01-02")], nil
+}
+
+func TestFetchWithRetryHonorsRetryAfter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// The backoff alone would retry after a few milliseconds.
+	client := NewCoinGeckoClient(server.URL, time.Millisecond, 2)
+	if _, err := client.fetchWithRetry(context.Background(), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 2 || times[1].Sub(times[0]) < time.Second {
+		t.Errorf("retried after %v, want the 1s Retry-After", times[len(times)-1].Sub(times[0]))
+	}
+}
+
+func TestFetchWithRetryTransientErrors(t *testing.T) {
+	counter := memCounter{}
+	statuses := []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[requests])
+		requests++
+	}))
+	defer server.Close()
+
+	budget := NewBudget(counter, CoinGeckoService, 10)
+	client := NewCoinGeckoClient(server.URL, time.Millisecond, 3, WithBudget(budget))
+	if _, err := client.fetchWithRetry(context.Background(), server.URL); err != nil {
+		t.Fatalf("unexpected error after 5xx retries: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+	status, err := budget.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Used != 3 || status.Remaining != 7 || status.Date != time.Now().UTC().Format("2006-01-02") {
+		t.Errorf("budget = %+v, want 3 of 10 used today", status)
+	}
+}
+
+func TestFetchWithRetryNoRetryOnClientError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewCoinGeckoClient(server.URL, time.Millisecond, 3)
+	if _, err := client.fetchWithRetry(context.Background(), server.URL); err == nil {
+		t.Fatal("expected an error for 404")
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	client := NewCoinGeckoClient("http://unused", 100*time.Millisecond, 3)
+	for range 20 {
+		if d := client.backoff(3); d < 200*time.Millisecond || d > 400*time.Millisecond {
+			t.Fatalf("backoff(3) = %v, want within [200ms, 400ms]", d)
+		}
+	}
+}
@@ -26,6 +26,11 @@ type QuoteRepository interface {
 	SaveQuote(ctx context.Context, symbol string, priceInEUR decimal.Decimal) error
 	GetQuote(ctx context.Context, symbol string) (Quote, error)
 	GetAllQuotes(ctx context.Context) ([]Quote, error)
+	// SaveQuoteHistory records the quote for one UTC day, replacing any
+	// existing row for that symbol and day.
+	SaveQuoteHistory(ctx context.Context, symbol string, date time.Time, priceInEUR decimal.Decimal) error
+	// GetQuoteOn returns the latest daily quote on or before date.
+	GetQuoteOn(ctx context.Context, symbol string, date time.Time) (Quote, error)
 }
 
 // PgQuoteRepository implements QuoteRepository with PostgreSQL.
@@ -82,3 +87,31 @@ func (r *PgQuoteRepository) GetAllQuotes(ctx context.Context) ([]Quote, error) {
 	}
 	return quotes, rows.Err()
 }
+
+func (r *PgQuoteRepository) SaveQuoteHistory(ctx context.Context, symbol string, date time.Time, priceInEUR decimal.Decimal) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO quote_history (symbol, quote_date, price_in_eur, fetched_at)
+		 VALUES ($1, $2, $3, NOW())
+		 ON CONFLICT (symbol, quote_date) DO UPDATE SET price_in_eur = $3, fetched_at = NOW()`,
+		symbol, date.UTC().Format("2006-01-02"), priceInEUR)
+	if err != nil {
+		return fmt.Errorf("saving %s quote for %s: %w", symbol, date.Format("2006-01-02"), err)
+	}
+	return nil
+}
+
+func (r *PgQuoteRepository) GetQuoteOn(ctx context.Context, symbol string, date time.Time) (Quote, error) {
+	var q Quote
+	err := r.pool.QueryRow(ctx,
+		`SELECT symbol, price_in_eur, quote_date::timestamptz FROM quote_history
+		 WHERE symbol = $1 AND quote_date <= $2
+		 ORDER BY quote_date DESC LIMIT 1`,
+		symbol, date.UTC().Format("2006-01-02")).Scan(&q.Symbol, &q.PriceInEUR, &q.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Quote{}, ErrQuoteNotFound
+		}
+		return Quote{}, fmt.Errorf("getting %s quote on %s: %w", symbol, date.Format("2006-01-02"), err)
+	}
+	return q, nil
+}
@@ -3,9 +3,15 @@ package external
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/shopspring/decimal"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
 )
 
@@ -13,6 +19,14 @@ import (
 type Service struct {
 	coingecko *CoinGeckoClient
 	repo      QuoteRepository
+
+	mu   sync.RWMutex
+	warm map[string]warmQuote // seeded by WarmQuotes
+}
+
+type warmQuote struct {
+	quote     Quote
+	expiresAt time.Time
 }
 
 // NewService creates a new ExternalPriceService.
@@ -23,20 +37,94 @@ func NewService(coingecko *CoinGeckoClient, repo QuoteRepository) *Service {
 	}
 }
 
-// FetchAndStoreQuotes fetches all external prices from CoinGecko and stores them in the database.
-func (s *Service) FetchAndStoreQuotes(ctx context.Context) error {
-	prices, err := s.coingecko.FetchPrices(ctx)
-	if err != nil {
-		return fmt.Errorf("fetching external prices: %w", err)
+// QuoteFetch summarizes one FetchAndStoreQuotes run. Every symbol is in
+// exactly one of the lists.
+type QuoteFetch struct {
+	Fetched []string          `json:"fetched"`
+	Skipped []string          `json:"skipped"` // stored quote updated within maxAge
+	Failed  map[string]string `json:"failed"`  // symbol → error
+}
+
+// FetchAndStoreQuotes fetches external prices from CoinGecko and stores them
+// with today's quote history. Symbols whose stored quote was updated within
+// maxAge are skipped, so re-running it shortly after a successful run makes
+// no request (0 fetches everything). A symbol that can't be fetched or stored
+// is recorded in Failed and doesn't stop the others; the error is only set
+// when the stored quotes can't be read or ctx is done.
+func (s *Service) FetchAndStoreQuotes(ctx context.Context, maxAge time.Duration) (QuoteFetch, error) {
+	res := QuoteFetch{Fetched: []string{}, Skipped: []string{}, Failed: map[string]string{}}
+
+	fresh := make(map[string]bool)
+	if maxAge > 0 {
+		stored, err := s.repo.GetAllQuotes(ctx)
+		if err != nil {
+			return res, fmt.Errorf("reading stored quotes: %w", err)
+		}
+		cutoff := time.Now().Add(-maxAge)
+		for _, q := range stored {
+			if q.UpdatedAt.After(cutoff) {
+				fresh[q.Symbol] = true
+			}
+		}
+	}
+
+	var symbols []string
+	for _, symbol := range slices.Sorted(maps.Keys(symbolMapping)) {
+		if fresh[symbol] {
+			res.Skipped = append(res.Skipped, symbol)
+			continue
+		}
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		return res, nil
 	}
 
-	for symbol, priceInEUR := range prices {
+	prices, failed := s.coingecko.FetchSymbolPrices(ctx, symbols)
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	today := time.Now().UTC()
+	for _, symbol := range symbols {
+		if err := failed[symbol]; err != nil {
+			res.Failed[symbol] = err.Error()
+			continue
+		}
+		priceInEUR := prices[symbol]
 		if err := s.repo.SaveQuote(ctx, symbol, priceInEUR); err != nil {
-			return fmt.Errorf("storing quote for %s: %w", symbol, err)
+			res.Failed[symbol] = fmt.Sprintf("storing quote: %v", err)
+			continue
 		}
+		if err := s.repo.SaveQuoteHistory(ctx, symbol, today, priceInEUR); err != nil {
+			res.Failed[symbol] = fmt.Sprintf("storing quote history: %v", err)
+			continue
+		}
+		res.Fetched = append(res.Fetched, symbol)
 	}
+	return res, nil
+}
 
-	return nil
+// BackfillQuotes fetches daily quotes for [from, to] from CoinGecko's range
+// API and stores them in quote history. Existing days are overwritten, so a
+// backfill can be re-run over the same range. It returns the number of daily
+// quotes stored.
+func (s *Service) BackfillQuotes(ctx context.Context, from, to time.Time) (int, error) {
+	history, err := s.coingecko.FetchPriceHistory(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("fetching external price history: %w", err)
+	}
+
+	stored := 0
+	for symbol, daily := range history {
+		for day, priceInEUR := range daily {
+			if err := s.repo.SaveQuoteHistory(ctx, symbol, day, priceInEUR); err != nil {
+				return stored, fmt.Errorf("storing quote history for %s: %w", symbol, err)
+			}
+			stored++
+		}
+		slog.Debug("quote history backfilled", "symbol", symbol, "days", len(daily))
+	}
+	return stored, nil
 }
 
 // ResolveValuation resolves an asset valuation to a EURMTL value using stored external quotes.
@@ -51,7 +139,7 @@ func (s *Service) ResolveValuation(ctx context.Context, val domain.AssetValuatio
 		return resolved, nil
 
 	case domain.ValuationValueExternal:
-		quote, err := s.repo.GetQuote(ctx, val.RawValue.Symbol)
+		quote, err := s.Quote(ctx, val.RawValue.Symbol)
 		if err != nil {
 			return domain.ResolvedAssetValuation{}, fmt.Errorf("getting quote for %s: %w", val.RawValue.Symbol, err)
 		}
@@ -65,9 +153,61 @@ func (s *Service) ResolveValuation(ctx context.Context, val domain.AssetValuatio
 		}
 
 		resolved.ValueInEURMTL = priceInEUR.String()
+		resolved.Quote = &domain.QuoteUsage{Symbol: quote.Symbol, PriceInEUR: quote.PriceInEUR, FetchedAt: quote.UpdatedAt}
 		return resolved, nil
 
 	default:
 		return domain.ResolvedAssetValuation{}, fmt.Errorf("unknown valuation value type: %s", val.RawValue.Type)
 	}
 }
+
+// WarmQuotes seeds an in-memory quote cache from the quotes recorded in a
+// stored snapshot. Each quote is served until fetchedAt+maxAge instead of
+// reading external_quotes; `stat quote` refreshes hourly, so a maxAge of an
+// hour keeps the cache at most one refresh behind. Returns the number of
+// quotes seeded.
+func (s *Service) WarmQuotes(quotes []domain.QuoteUsage, maxAge time.Duration) int {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.warm == nil {
+		s.warm = make(map[string]warmQuote, len(quotes))
+	}
+	seeded := 0
+	for _, q := range quotes {
+		expiresAt := q.FetchedAt.Add(maxAge)
+		if !now.Before(expiresAt) {
+			continue
+		}
+		s.warm[q.Symbol] = warmQuote{
+			quote:     Quote{Symbol: q.Symbol, PriceInEUR: q.PriceInEUR, UpdatedAt: q.FetchedAt},
+			expiresAt: expiresAt,
+		}
+		seeded++
+	}
+	return seeded
+}
+
+// WarmQuoteCount returns the number of quotes held by the warm cache,
+// including expired ones.
+func (s *Service) WarmQuoteCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.warm)
+}
+
+// Quote returns the warm-cached quote for symbol while it is fresh, and the
+// stored one otherwise. Under an as-of context (snapshot backfill) the daily
+// quote_history row on or before that day is used instead.
+func (s *Service) Quote(ctx context.Context, symbol string) (Quote, error) {
+	if at, ok := asof.From(ctx); ok {
+		return s.repo.GetQuoteOn(ctx, symbol, at)
+	}
+	s.mu.RLock()
+	w, ok := s.warm[symbol]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(w.expiresAt) {
+		return w.quote, nil
+	}
+	return s.repo.GetQuote(ctx, symbol)
+}
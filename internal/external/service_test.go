@@ -2,16 +2,22 @@ package external
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
 )
 
 type mockQuoteRepo struct {
-	quotes map[string]Quote
+	quotes  map[string]Quote
+	history map[string]map[string]decimal.Decimal // symbol -> YYYY-MM-DD -> price
 }
 
 func (m *mockQuoteRepo) SaveQuote(_ context.Context, symbol string, priceInEUR decimal.Decimal) error {
@@ -35,6 +41,25 @@ func (m *mockQuoteRepo) GetAllQuotes(_ context.Context) ([]Quote, error) {
 	return result, nil
 }
 
+func (m *mockQuoteRepo) SaveQuoteHistory(_ context.Context, symbol string, date time.Time, priceInEUR decimal.Decimal) error {
+	if m.history == nil {
+		m.history = make(map[string]map[string]decimal.Decimal)
+	}
+	if m.history[symbol] == nil {
+		m.history[symbol] = make(map[string]decimal.Decimal)
+	}
+	m.history[symbol][date.Format("2006-01-02")] = priceInEUR
+	return nil
+}
+
+func (m *mockQuoteRepo) GetQuoteOn(_ context.Context, symbol string, date time.Time) (Quote, error) {
+	p, ok := m.history[symbol][date.Format("2006-01-02")]
+	if !ok {
+		return Quote{}, ErrQuoteNotFound
+	}
+	return Quote{Symbol: symbol, PriceInEUR: p, UpdatedAt: date}, nil
+}
+
 func TestResolveValuationDirectEURMTL(t *testing.T) {
 	repo := &mockQuoteRepo{quotes: make(map[string]Quote)}
 	svc := NewService(nil, repo)
@@ -73,6 +98,32 @@ func TestResolveValuationBTC(t *testing.T) {
 	if resolved.ValueInEURMTL != "55000" {
 		t.Errorf("ValueInEURMTL = %q, want 55000", resolved.ValueInEURMTL)
 	}
+	if resolved.Quote == nil || resolved.Quote.Symbol != "BTC" || !resolved.Quote.FetchedAt.Equal(repo.quotes["BTC"].UpdatedAt) {
+		t.Errorf("Quote = %+v, want the stored BTC quote", resolved.Quote)
+	}
+}
+
+func TestResolveValuationAsOfUsesHistory(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockQuoteRepo{
+		quotes:  map[string]Quote{"BTC": {Symbol: "BTC", PriceInEUR: decimal.RequireFromString("55000"), UpdatedAt: time.Now()}},
+		history: map[string]map[string]decimal.Decimal{"BTC": {"2025-03-01": decimal.RequireFromString("80000")}},
+	}
+	svc := NewService(nil, repo)
+
+	val := domain.AssetValuation{
+		TokenCode:     "WBTC",
+		ValuationType: domain.ValuationTypeUnit,
+		RawValue:      domain.ValuationValue{Type: domain.ValuationValueExternal, Symbol: "BTC"},
+	}
+
+	resolved, err := svc.ResolveValuation(asof.With(context.Background(), day), val)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.ValueInEURMTL != "80000" || resolved.Quote == nil || !resolved.Quote.FetchedAt.Equal(day) {
+		t.Errorf("resolved = %+v, want the 2025-03-01 history quote", resolved)
+	}
 }
 
 func TestResolveValuationAUCompound(t *testing.T) {
@@ -113,3 +164,131 @@ func TestResolveValuationMissingQuote(t *testing.T) {
 		t.Error("expected error for missing quote")
 	}
 }
+
+func TestBackfillQuotes(t *testing.T) {
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"prices": [[%d, 2]]}`, day.UnixMilli())
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{quotes: make(map[string]Quote)}
+	svc := NewService(NewCoinGeckoClient(server.URL, 0, 1), repo)
+
+	stored, err := svc.BackfillQuotes(context.Background(), day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored != len(symbolMapping) {
+		t.Errorf("stored = %d, want one quote per symbol (%d)", stored, len(symbolMapping))
+	}
+	q, err := repo.GetQuoteOn(context.Background(), "ETH", day)
+	if err != nil || !q.PriceInEUR.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("ETH on %s = %v (err %v), want 2", day.Format("2006-01-02"), q.PriceInEUR, err)
+	}
+	if len(repo.quotes) != 0 {
+		t.Error("backfill must not touch the latest quotes")
+	}
+}
+
+func TestWarmQuotes(t *testing.T) {
+	repo := &mockQuoteRepo{quotes: map[string]Quote{
+		"BTC": {Symbol: "BTC", PriceInEUR: decimal.RequireFromString("50000"), UpdatedAt: time.Now()},
+		"ETH": {Symbol: "ETH", PriceInEUR: decimal.RequireFromString("2000"), UpdatedAt: time.Now()},
+	}}
+	svc := NewService(nil, repo)
+
+	seeded := svc.WarmQuotes([]domain.QuoteUsage{
+		{Symbol: "BTC", PriceInEUR: decimal.RequireFromString("48000"), FetchedAt: time.Now().Add(-10 * time.Minute)},
+		{Symbol: "ETH", PriceInEUR: decimal.RequireFromString("1900"), FetchedAt: time.Now().Add(-2 * time.Hour)},
+	}, time.Hour)
+	if seeded != 1 {
+		t.Errorf("seeded = %d, want 1 (ETH quote is past max age)", seeded)
+	}
+
+	resolve := func(symbol string) string {
+		val := domain.AssetValuation{RawValue: domain.ValuationValue{Type: domain.ValuationValueExternal, Symbol: symbol}}
+		r, err := svc.ResolveValuation(context.Background(), val)
+		if err != nil {
+			t.Fatalf("resolving %s: %v", symbol, err)
+		}
+		return r.ValueInEURMTL
+	}
+	if got := resolve("BTC"); got != "48000" {
+		t.Errorf("BTC = %s, want warmed 48000", got)
+	}
+	if got := resolve("ETH"); got != "2000" {
+		t.Errorf("ETH = %s, want stored 2000", got)
+	}
+}
+
+func TestFetchAndStoreQuotesSkipsFreshSymbols(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Query().Get("ids"))
+		w.Write([]byte(`{"bitcoin": {"eur": 55000}, "ethereum": {"eur": 2500}, "tether": {"eur": 0.92}}`))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{quotes: map[string]Quote{
+		"XLM": {Symbol: "XLM", PriceInEUR: decimal.RequireFromString("0.1"), UpdatedAt: time.Now().Add(-time.Minute)},
+		"AU":  {Symbol: "AU", PriceInEUR: decimal.NewFromInt(60), UpdatedAt: time.Now().Add(-time.Minute)},
+		"ETH": {Symbol: "ETH", PriceInEUR: decimal.NewFromInt(2000), UpdatedAt: time.Now().Add(-time.Hour)},
+	}}
+	svc := NewService(NewCoinGeckoClient(server.URL, 0, 0), repo)
+
+	res, err := svc.FetchAndStoreQuotes(context.Background(), 10*time.Minute)
+	if err != nil {
+		t.Fatalf("FetchAndStoreQuotes: %v", err)
+	}
+	if fmt.Sprint(res.Skipped) != "[AU XLM]" {
+		t.Errorf("skipped = %v, want [AU XLM]", res.Skipped)
+	}
+	if fmt.Sprint(res.Fetched) != "[BTC ETH Sats USD]" || len(res.Failed) != 0 {
+		t.Errorf("fetched = %v, failed = %v, want [BTC ETH Sats USD] and none", res.Fetched, res.Failed)
+	}
+	if len(requested) != 1 || requested[0] != "bitcoin,ethereum,tether" {
+		t.Errorf("requested ids = %v, want one request for bitcoin,ethereum,tether", requested)
+	}
+	if !repo.quotes["ETH"].PriceInEUR.Equal(decimal.NewFromInt(2500)) {
+		t.Errorf("ETH = %s, want the fetched 2500", repo.quotes["ETH"].PriceInEUR)
+	}
+
+	// Everything is fresh now: no request at all.
+	requested = nil
+	res, err = svc.FetchAndStoreQuotes(context.Background(), 10*time.Minute)
+	if err != nil {
+		t.Fatalf("second FetchAndStoreQuotes: %v", err)
+	}
+	if len(requested) != 0 || len(res.Skipped) != 6 {
+		t.Errorf("second run requested %v and skipped %v, want nothing requested and all skipped", requested, res.Skipped)
+	}
+}
+
+func TestFetchAndStoreQuotesIsolatesFailingCoin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query().Get("ids")
+		if ids == "pax-gold" || strings.Contains(ids, ",") {
+			// The batch and the gold request are rejected.
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid id"}`))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(`{%q: {"eur": 2}}`, ids)))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{quotes: map[string]Quote{}}
+	svc := NewService(NewCoinGeckoClient(server.URL, 0, 0), repo)
+
+	res, err := svc.FetchAndStoreQuotes(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("FetchAndStoreQuotes: %v", err)
+	}
+	if len(res.Fetched) != 5 || len(res.Failed) != 1 || res.Failed["AU"] == "" {
+		t.Errorf("fetched = %v, failed = %v, want 5 fetched and AU failed", res.Fetched, res.Failed)
+	}
+	if _, ok := repo.quotes["AU"]; ok {
+		t.Error("AU stored despite failing")
+	}
+}
@@ -0,0 +1,113 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// sqliteTime is the layout of the SQLite timestamp columns: RFC 3339 in UTC
+// with a fixed-width fraction, so they sort as text.
+const sqliteTime = "2006-01-02T15:04:05.000000000Z"
+
+// SQLiteQuoteRepository implements QuoteRepository with SQLite, for local
+// runs without PostgreSQL (DATABASE_URL=sqlite://...). Prices are stored as
+// decimal text, so they round-trip exactly.
+type SQLiteQuoteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteQuoteRepository creates a new SQLite quote repository. The schema
+// is migrations.SQLite.
+func NewSQLiteQuoteRepository(db *sql.DB) *SQLiteQuoteRepository {
+	return &SQLiteQuoteRepository{db: db}
+}
+
+func (r *SQLiteQuoteRepository) SaveQuote(ctx context.Context, symbol string, priceInEUR decimal.Decimal) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO external_quotes (symbol, price_in_eur, updated_at)
+		 VALUES (?, ?, ?)
+		 ON CONFLICT (symbol) DO UPDATE SET price_in_eur = excluded.price_in_eur, updated_at = excluded.updated_at`,
+		symbol, priceInEUR.String(), time.Now().UTC().Format(sqliteTime))
+	if err != nil {
+		return fmt.Errorf("saving quote for %s: %w", symbol, err)
+	}
+	return nil
+}
+
+// scanSQLiteQuote scans symbol, price and a timestamp laid out as layout.
+func scanSQLiteQuote(row interface{ Scan(...any) error }, layout string) (Quote, error) {
+	var q Quote
+	var at string
+	if err := row.Scan(&q.Symbol, &q.PriceInEUR, &at); err != nil {
+		return Quote{}, err
+	}
+	t, err := time.Parse(layout, at)
+	if err != nil {
+		return Quote{}, fmt.Errorf("parsing quote time %q: %w", at, err)
+	}
+	q.UpdatedAt = t
+	return q, nil
+}
+
+func (r *SQLiteQuoteRepository) GetQuote(ctx context.Context, symbol string) (Quote, error) {
+	q, err := scanSQLiteQuote(r.db.QueryRowContext(ctx,
+		`SELECT symbol, price_in_eur, updated_at FROM external_quotes WHERE symbol = ?`, symbol), time.RFC3339Nano)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Quote{}, ErrQuoteNotFound
+		}
+		return Quote{}, fmt.Errorf("getting quote for %s: %w", symbol, err)
+	}
+	return q, nil
+}
+
+func (r *SQLiteQuoteRepository) GetAllQuotes(ctx context.Context) ([]Quote, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT symbol, price_in_eur, updated_at FROM external_quotes ORDER BY symbol`)
+	if err != nil {
+		return nil, fmt.Errorf("getting all quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var quotes []Quote
+	for rows.Next() {
+		q, err := scanSQLiteQuote(rows, time.RFC3339Nano)
+		if err != nil {
+			return nil, fmt.Errorf("scanning quote: %w", err)
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
+
+func (r *SQLiteQuoteRepository) SaveQuoteHistory(ctx context.Context, symbol string, date time.Time, priceInEUR decimal.Decimal) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO quote_history (symbol, quote_date, price_in_eur, fetched_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT (symbol, quote_date) DO UPDATE SET price_in_eur = excluded.price_in_eur, fetched_at = excluded.fetched_at`,
+		symbol, date.UTC().Format("2006-01-02"), priceInEUR.String(), time.Now().UTC().Format(sqliteTime))
+	if err != nil {
+		return fmt.Errorf("saving %s quote for %s: %w", symbol, date.Format("2006-01-02"), err)
+	}
+	return nil
+}
+
+func (r *SQLiteQuoteRepository) GetQuoteOn(ctx context.Context, symbol string, date time.Time) (Quote, error) {
+	q, err := scanSQLiteQuote(r.db.QueryRowContext(ctx,
+		`SELECT symbol, price_in_eur, quote_date FROM quote_history
+		 WHERE symbol = ? AND quote_date <= ?
+		 ORDER BY quote_date DESC LIMIT 1`,
+		symbol, date.UTC().Format("2006-01-02")), "2006-01-02")
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Quote{}, ErrQuoteNotFound
+		}
+		return Quote{}, fmt.Errorf("getting %s quote on %s: %w", symbol, date.Format("2006-01-02"), err)
+	}
+	return q, nil
+}
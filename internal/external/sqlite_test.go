@@ -0,0 +1,68 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/migrations"
+)
+
+func TestSQLiteQuoteRepository(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.OpenSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema, err := fs.Sub(migrations.SQLite, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.RunSQLiteMigrations(ctx, db, schema); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewSQLiteQuoteRepository(db)
+
+	if _, err := repo.GetQuote(ctx, "BTC"); !errors.Is(err, ErrQuoteNotFound) {
+		t.Fatalf("GetQuote on empty store err = %v, want ErrQuoteNotFound", err)
+	}
+	price := decimal.RequireFromString("61234.123456789012")
+	if err := repo.SaveQuote(ctx, "BTC", decimal.NewFromInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveQuote(ctx, "BTC", price); err != nil {
+		t.Fatal(err)
+	}
+	q, err := repo.GetQuote(ctx, "BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.PriceInEUR.Equal(price) || time.Since(q.UpdatedAt) > time.Minute {
+		t.Errorf("quote = %s at %s, want %s just now", q.PriceInEUR, q.UpdatedAt, price)
+	}
+	all, err := repo.GetAllQuotes(ctx)
+	if err != nil || len(all) != 1 {
+		t.Errorf("GetAllQuotes = %v, %v; want the one quote", all, err)
+	}
+
+	day := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	if err := repo.SaveQuoteHistory(ctx, "BTC", day, price); err != nil {
+		t.Fatal(err)
+	}
+	on, err := repo.GetQuoteOn(ctx, "BTC", day.AddDate(0, 0, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !on.PriceInEUR.Equal(price) || !on.UpdatedAt.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("quote on = %s dated %s, want %s dated 2026-03-01", on.PriceInEUR, on.UpdatedAt, price)
+	}
+	if _, err := repo.GetQuoteOn(ctx, "BTC", day.AddDate(0, 0, -1)); !errors.Is(err, ErrQuoteNotFound) {
+		t.Errorf("GetQuoteOn before the history err = %v, want ErrQuoteNotFound", err)
+	}
+}
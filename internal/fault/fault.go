@@ -0,0 +1,194 @@
+// Package fault classifies the failures of the external clients (Horizon,
+// CoinGecko, Google Sheets and BigQuery, Grist, Stellar Expert) into the few
+// kinds that decide what a caller does next, and retries the kinds worth
+// retrying (Do).
+//
+// A client wraps a failed response in an *Error naming its Kind; errors of
+// the Google API libraries and of the network are classified as they come.
+// Callers ask KindOf, Is or Retryable instead of matching messages.
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Kind is a category of failure.
+type Kind string
+
+const (
+	RateLimited Kind = "rate_limited" // 429: retry once the server's wait is over
+	Transient   Kind = "transient"    // 5xx or a network failure: retry with backoff
+	Permanent   Kind = "permanent"    // the request itself is at fault: retrying repeats it
+	Auth        Kind = "auth"         // 401 or 403: credentials or sharing, fixed by an operator
+	NotFound    Kind = "not_found"    // 404 or 410: the resource doesn't exist
+)
+
+// Error is a classified failure of an external service.
+type Error struct {
+	Kind       Kind
+	Service    string        // "horizon", "coingecko", ...
+	Status     int           // HTTP status, 0 when there was no response
+	RetryAfter time.Duration // the wait a RateLimited response asked for, 0 when none
+	Err        error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New classifies err from service as kind.
+func New(service string, kind Kind, err error) *Error {
+	return &Error{Kind: kind, Service: service, Err: err}
+}
+
+// FromResponse classifies err, describing a response of service with
+// status, by that status. A Retry-After header is kept for RateLimited.
+func FromResponse(service string, status int, header http.Header, err error) *Error {
+	e := &Error{Kind: KindOfStatus(status), Service: service, Status: status, Err: err}
+	if e.Kind == RateLimited && header != nil {
+		e.RetryAfter = ParseRetryAfter(header.Get("Retry-After"), time.Now())
+	}
+	return e
+}
+
+// KindOfStatus classifies an HTTP error status.
+func KindOfStatus(status int) Kind {
+	switch status {
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Transient
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return Auth
+	case http.StatusNotFound, http.StatusGone:
+		return NotFound
+	default:
+		return Permanent
+	}
+}
+
+// KindOf returns the kind of err: the Kind of a wrapped *Error, the status
+// of a Google API error, Transient for a network failure. A cancelled or
+// expired context and anything else unclassified are Permanent; nil has no
+// kind.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	var fe *Error
+	if errors.As(err, &fe) {
+		return fe.Kind
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return KindOfStatus(apiErr.Code)
+	}
+	if errors.Is(err, context.Canceled) {
+		return Permanent
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return Transient
+	}
+	return Permanent
+}
+
+// Is reports whether err is of kind.
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
+
+// Retryable reports whether err may go away on its own: RateLimited or
+// Transient.
+func Retryable(err error) bool {
+	k := KindOf(err)
+	return k == RateLimited || k == Transient
+}
+
+// retryAfterOf returns the wait a RateLimited err asked for, 0 when none.
+func retryAfterOf(err error) time.Duration {
+	var fe *Error
+	if errors.As(err, &fe) && fe.Kind == RateLimited {
+		return fe.RetryAfter
+	}
+	return 0
+}
+
+// ParseRetryAfter parses a Retry-After value, either seconds or an HTTP
+// date, into a wait from now. It returns 0 when the value is absent or
+// unusable.
+func ParseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// MaxRetryAfter is the default for how long a Retry-After is waited out. A
+// longer one ends the retries, since a run would otherwise stall on a
+// single request.
+const MaxRetryAfter = 2 * time.Minute
+
+// Policy is how Do retries.
+type Policy struct {
+	Service string // for the logs
+	Retries int    // attempts after the first
+	// Backoff is the wait before retry n (1-based) when the failure names
+	// none.
+	Backoff func(n int) time.Duration
+	// MaxRetryAfter caps the Retry-After waited out; a longer one ends the
+	// retries. 0 is MaxRetryAfter.
+	MaxRetryAfter time.Duration
+}
+
+// Do calls fn until it succeeds, fails with an error that isn't Retryable,
+// or has used up p.Retries. A RateLimited failure waits its Retry-After,
+// anything else retried the backoff. The last error is returned as is, or
+// ctx's error when ctx ends while waiting.
+func Do[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	maxWait := p.MaxRetryAfter
+	if maxWait == 0 {
+		maxWait = MaxRetryAfter
+	}
+	for n := 0; ; n++ {
+		v, err := fn(ctx)
+		if err == nil || ctx.Err() != nil || !Retryable(err) || n >= p.Retries {
+			return v, err
+		}
+		wait := retryAfterOf(err)
+		if wait > maxWait {
+			var zero T
+			return zero, fmt.Errorf("%w: retry after %s", err, wait)
+		}
+		if wait == 0 && p.Backoff != nil {
+			wait = p.Backoff(n + 1)
+		}
+		slog.Debug("retrying request", "service", p.Service, "kind", KindOf(err), "retry", n+1, "wait", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
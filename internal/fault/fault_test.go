@@ -0,0 +1,152 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestKindOfStatus(t *testing.T) {
+	for status, want := range map[int]Kind{
+		http.StatusTooManyRequests:     RateLimited,
+		http.StatusInternalServerError: Transient,
+		http.StatusBadGateway:          Transient,
+		http.StatusServiceUnavailable:  Transient,
+		http.StatusGatewayTimeout:      Transient,
+		http.StatusUnauthorized:        Auth,
+		http.StatusForbidden:           Auth,
+		http.StatusNotFound:            NotFound,
+		http.StatusGone:                NotFound,
+		http.StatusBadRequest:          Permanent,
+		http.StatusNotImplemented:      Permanent,
+	} {
+		if got := KindOfStatus(status); got != want {
+			t.Errorf("KindOfStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"nil", nil, ""},
+		{"classified and wrapped", fmt.Errorf("fetching: %w", New("horizon", NotFound, errors.New("HTTP 404"))), NotFound},
+		{"google api", fmt.Errorf("writing: %w", &googleapi.Error{Code: http.StatusForbidden}), Auth},
+		{"network", &url.Error{Op: "Get", URL: "http://x", Err: errors.New("connection refused")}, Transient},
+		{"cancelled", fmt.Errorf("waiting: %w", context.Canceled), Permanent},
+		{"unclassified", errors.New("parsing JSON"), Permanent},
+	} {
+		if got := KindOf(tc.err); got != tc.want {
+			t.Errorf("%s: KindOf = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFromResponseKeepsRetryAfterOfRateLimit(t *testing.T) {
+	h := http.Header{"Retry-After": []string{"30"}}
+	if e := FromResponse("coingecko", http.StatusTooManyRequests, h, errors.New("slow down")); e.Kind != RateLimited || e.RetryAfter != 30*time.Second {
+		t.Errorf("429 = %+v, want rate limited for 30s", e)
+	}
+	if e := FromResponse("coingecko", http.StatusServiceUnavailable, h, errors.New("down")); e.RetryAfter != 0 {
+		t.Errorf("503 RetryAfter = %v, want 0", e.RetryAfter)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	} {
+		if got := ParseRetryAfter(tc.in, now); got != tc.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+// failing returns a fn failing with errs in turn, then succeeding, and
+// counts its calls.
+func failing(calls *int, errs ...error) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		*calls++
+		if *calls <= len(errs) {
+			return "", errs[*calls-1]
+		}
+		return "ok", nil
+	}
+}
+
+func TestDoRetriesRetryableKinds(t *testing.T) {
+	var waits []int
+	p := Policy{Retries: 3, Backoff: func(n int) time.Duration { waits = append(waits, n); return time.Millisecond }}
+	calls := 0
+	got, err := Do(context.Background(), p, failing(&calls,
+		New("x", Transient, errors.New("502")), New("x", RateLimited, errors.New("429"))))
+	if err != nil || got != "ok" {
+		t.Fatalf("Do = %q, %v; want ok", got, err)
+	}
+	if calls != 3 || len(waits) != 2 || waits[1] != 2 {
+		t.Errorf("calls = %d, backoffs = %v; want 3 calls after backoffs 1 and 2", calls, waits)
+	}
+}
+
+func TestDoStopsOnOtherKindsAndExhaustion(t *testing.T) {
+	p := Policy{Retries: 3, Backoff: func(int) time.Duration { return time.Millisecond }}
+	for _, kind := range []Kind{Permanent, Auth, NotFound} {
+		calls := 0
+		if _, err := Do(context.Background(), p, failing(&calls, New("x", kind, errors.New("no")))); !Is(err, kind) || calls != 1 {
+			t.Errorf("%s: calls = %d, err = %v; want one call returning it", kind, calls, err)
+		}
+	}
+
+	calls := 0
+	transient := New("x", Transient, errors.New("503"))
+	if _, err := Do(context.Background(), p, failing(&calls, transient, transient, transient, transient, transient)); err != transient || calls != 4 {
+		t.Errorf("calls = %d, err = %v; want 4 calls returning the last failure", calls, err)
+	}
+}
+
+func TestDoHonorsRetryAfter(t *testing.T) {
+	p := Policy{Retries: 1, Backoff: func(int) time.Duration { return time.Hour }}
+	calls := 0
+	start := time.Now()
+	limited := &Error{Kind: RateLimited, RetryAfter: 20 * time.Millisecond, Err: errors.New("429")}
+	if _, err := Do(context.Background(), p, failing(&calls, limited)); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond || d > time.Second {
+		t.Errorf("retried after %v, want the 20ms Retry-After over the backoff", d)
+	}
+
+	calls = 0
+	p.MaxRetryAfter = 10 * time.Millisecond
+	_, err := Do(context.Background(), p, failing(&calls, limited))
+	if !Is(err, RateLimited) || calls != 1 {
+		t.Errorf("calls = %d, err = %v; want a Retry-After over the cap to end the retries", calls, err)
+	}
+}
+
+func TestDoStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{Retries: 5, Backoff: func(int) time.Duration { cancel(); return time.Hour }}
+	calls := 0
+	if _, err := Do(ctx, p, failing(&calls, New("x", Transient, errors.New("503")))); !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("calls = %d, err = %v; want the cancellation after one call", calls, err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package fixture turns a stored snapshot into an indicator regression
+// fixture: the snapshot data, optionally redacted and shrunk, together with
+// the indicator values calculated from it.
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/samber/lo"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// Fixture is the file stored under internal/indicator/testdata/fixtures.
+// Expected maps indicator IDs to the values indicator.Service (without
+// history) calculates from Data.
+type Fixture struct {
+	Date     string                   `json:"date"`
+	Source   string                   `json:"source"` // seal hash of the snapshot the fixture was cut from
+	Redacted bool                     `json:"redacted"`
+	Data     domain.FundStructureData `json:"data"`
+	Expected map[string]string        `json:"expected"`
+}
+
+// Rules control what Redact keeps.
+type Rules struct {
+	// KeepAddresses are Stellar accounts left as is; every other address
+	// is replaced by a placeholder.
+	KeepAddresses map[string]bool
+	// MaxTokens caps each account's token list (0 keeps all). The most
+	// valuable tokens are kept, plus every token in KeepTokens.
+	MaxTokens int
+	// KeepTokens are asset codes calculators read from token lists.
+	KeepTokens []string
+}
+
+// DefaultRules keeps the fund's own accounts and the issuers of the tokens
+// it holds, which are public and needed to identify assets. Counterparties
+// in price paths, valuation sources, peers and anything in warnings are
+// replaced.
+func DefaultRules(data domain.FundStructureData, maxTokens int) Rules {
+	keep := make(map[string]bool)
+	for _, a := range domain.AccountRegistry() {
+		keep[a.Address] = true
+	}
+	for _, acc := range allAccounts(&data) {
+		for _, t := range acc.Tokens {
+			keep[t.Asset.Issuer] = true
+		}
+	}
+	return Rules{
+		KeepAddresses: keep,
+		MaxTokens:     maxTokens,
+		KeepTokens:    []string{"EURMTL", "MTL", "MTLRECT", "BTC", "WBTC"},
+	}
+}
+
+// stellarAddress matches account IDs (G...) and muxed accounts (M...).
+var stellarAddress = regexp.MustCompile(`\b[GM][A-Z2-7]{55}\b`)
+
+// Redact applies rules to data: price path details are dropped, token lists
+// shrunk, and addresses outside KeepAddresses replaced by stable
+// placeholders (the same address always maps to the same placeholder, so
+// distinct holders stay distinct). The placeholders contain digits Stellar
+// addresses can't, so they never pass as real keys.
+func Redact(data domain.FundStructureData, rules Rules) (domain.FundStructureData, error) {
+	data, err := clone(data)
+	if err != nil {
+		return data, err
+	}
+	for _, acc := range allAccounts(&data) {
+		for i := range acc.Tokens {
+			acc.Tokens[i].DetailsEURMTL = nil
+			acc.Tokens[i].DetailsXLM = nil
+		}
+		acc.Tokens = shrink(acc.Tokens, rules)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data, fmt.Errorf("encoding snapshot data: %w", err)
+	}
+	placeholders := make(map[string]string)
+	raw = stellarAddress.ReplaceAllFunc(raw, func(addr []byte) []byte {
+		a := string(addr)
+		if rules.KeepAddresses[a] {
+			return addr
+		}
+		p, ok := placeholders[a]
+		if !ok {
+			p = fmt.Sprintf("%cREDACTED%047d", a[0], len(placeholders)+1)
+			placeholders[a] = p
+		}
+		return []byte(p)
+	})
+
+	var out domain.FundStructureData
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return data, fmt.Errorf("decoding redacted data: %w", err)
+	}
+	return out, nil
+}
+
+// clone deep-copies data so Redact leaves the caller's slices alone.
+func clone(data domain.FundStructureData) (domain.FundStructureData, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data, fmt.Errorf("encoding snapshot data: %w", err)
+	}
+	var out domain.FundStructureData
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return data, fmt.Errorf("decoding snapshot data: %w", err)
+	}
+	return out, nil
+}
+
+// shrink keeps the MaxTokens most valuable tokens plus KeepTokens, in their
+// original order.
+func shrink(tokens []domain.TokenPriceWithBalance, rules Rules) []domain.TokenPriceWithBalance {
+	if rules.MaxTokens <= 0 || len(tokens) <= rules.MaxTokens {
+		return tokens
+	}
+	idx := lo.Range(len(tokens))
+	value := func(i int) float64 {
+		v, _ := domain.SafeParse(lo.FromPtr(tokens[i].ValueInEURMTL)).Float64()
+		return v
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return value(idx[a]) > value(idx[b]) })
+
+	keep := make(map[int]bool)
+	for _, i := range idx[:rules.MaxTokens] {
+		keep[i] = true
+	}
+	for i, t := range tokens {
+		if lo.Contains(rules.KeepTokens, t.Asset.Code) {
+			keep[i] = true
+		}
+	}
+	return lo.Filter(tokens, func(_ domain.TokenPriceWithBalance, i int) bool { return keep[i] })
+}
+
+// allAccounts returns pointers to every account group's portfolios.
+func allAccounts(data *domain.FundStructureData) []*domain.FundAccountPortfolio {
+	var out []*domain.FundAccountPortfolio
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for i := range group {
+			out = append(out, &group[i])
+		}
+	}
+	return out
+}
+
+// Expected converts calculated indicators to Fixture.Expected.
+func Expected(inds []indicator.Indicator) map[string]string {
+	out := make(map[string]string, len(inds))
+	for _, ind := range inds {
+		out[strconv.Itoa(ind.ID)] = ind.Value.String()
+	}
+	return out
+}
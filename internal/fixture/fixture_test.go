@@ -0,0 +1,75 @@
+package fixture
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+var (
+	counterparty = "GCOUNTERPARTY" + strings.Repeat("X", 43)
+	tokenIssuer  = "GISSUER" + strings.Repeat("X", 49)
+)
+
+func token(code, value string) domain.TokenPriceWithBalance {
+	return domain.TokenPriceWithBalance{
+		Asset:         domain.AssetInfo{Code: code, Issuer: tokenIssuer},
+		ValueInEURMTL: lo.ToPtr(value),
+		DetailsEURMTL: &domain.PriceDetails{},
+	}
+}
+
+func testData() domain.FundStructureData {
+	mabiz := domain.AccountRegistry()[1]
+	return domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{
+			ID:     mabiz.Address,
+			Name:   mabiz.Name,
+			Tokens: []domain.TokenPriceWithBalance{token("A", "5"), token("MTL", "1"), token("B", "50"), token("C", "30")},
+		}},
+		Warnings: []string{"no price path via " + counterparty, "again " + counterparty},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	data := testData()
+	got, err := Redact(data, DefaultRules(data, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acc := got.Accounts[0]
+	if acc.ID != data.Accounts[0].ID {
+		t.Errorf("fund account ID redacted: %s", acc.ID)
+	}
+	codes := lo.Map(acc.Tokens, func(tok domain.TokenPriceWithBalance, _ int) string { return tok.Asset.Code })
+	if strings.Join(codes, ",") != "MTL,B,C" {
+		t.Errorf("tokens = %v, want MTL (always kept) and the two most valuable, in order", codes)
+	}
+	for _, tok := range acc.Tokens {
+		if tok.DetailsEURMTL != nil {
+			t.Error("price details kept")
+		}
+		if tok.Asset.Issuer != tokenIssuer {
+			t.Errorf("token issuer redacted: %s", tok.Asset.Issuer)
+		}
+	}
+
+	raw, _ := json.Marshal(got)
+	if strings.Contains(string(raw), counterparty) {
+		t.Error("counterparty address survived redaction")
+	}
+	w0 := strings.Fields(got.Warnings[0])
+	w1 := strings.Fields(got.Warnings[1])
+	if p := w0[len(w0)-1]; len(p) != 56 || !strings.HasPrefix(p, "GREDACTED") || p != w1[len(w1)-1] {
+		t.Errorf("placeholders = %q / %q, want the same 56-character placeholder", p, w1[len(w1)-1])
+	}
+
+	if len(data.Accounts[0].Tokens) != 4 || data.Accounts[0].Tokens[0].DetailsEURMTL == nil {
+		t.Error("Redact modified its input")
+	}
+}
@@ -0,0 +1,93 @@
+package fund
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// TokenRule matches tokens by code and issuer. Either side is a path.Match
+// glob ("*", "?", "[...]"); an empty issuer matches any issuer.
+type TokenRule struct {
+	Code   string
+	Issuer string
+}
+
+// String returns the rule in the form it was configured: CODE or CODE:ISSUER.
+func (r TokenRule) String() string {
+	if r.Issuer == "" {
+		return r.Code
+	}
+	return r.Code + ":" + r.Issuer
+}
+
+func (r TokenRule) matches(a domain.AssetInfo) bool {
+	if ok, _ := path.Match(r.Code, a.Code); !ok {
+		return false
+	}
+	if r.Issuer == "" {
+		return true
+	}
+	ok, _ := path.Match(r.Issuer, a.Issuer)
+	return ok
+}
+
+// ParseTokenRules parses TOKEN_INCLUDE / TOKEN_EXCLUDE entries: "CODE",
+// "CODE:ISSUER" or "*:ISSUER", each side optionally a glob such as "*AIRDROP*".
+func ParseTokenRules(entries []string) ([]TokenRule, error) {
+	var rules []TokenRule
+	for _, e := range entries {
+		code, issuer, _ := strings.Cut(strings.TrimSpace(e), ":")
+		if code == "" {
+			return nil, fmt.Errorf("invalid token rule %q: empty code (use * for any code)", e)
+		}
+		r := TokenRule{Code: code, Issuer: issuer}
+		for _, p := range []string{r.Code, r.Issuer} {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid token rule %q: %w", e, err)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// TokenFilter decides which held tokens are valued. A token matching any
+// exclude rule is ignored; with include rules set, so is every token that
+// matches none of them. Exclude wins over include.
+type TokenFilter struct {
+	include []TokenRule
+	exclude []TokenRule
+}
+
+// NewTokenFilter builds a filter from parsed rules. A nil filter keeps every token.
+func NewTokenFilter(include, exclude []TokenRule) *TokenFilter {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	return &TokenFilter{include: include, exclude: exclude}
+}
+
+// Ignore reports whether a is left out of valuation, and the exclude rule
+// responsible (empty when a is merely missing from the include list).
+func (f *TokenFilter) Ignore(a domain.AssetInfo) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	for _, r := range f.exclude {
+		if r.matches(a) {
+			return r.String(), true
+		}
+	}
+	if len(f.include) == 0 {
+		return "", false
+	}
+	for _, r := range f.include {
+		if r.matches(a) {
+			return "", false
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,53 @@
+package fund
+
+import (
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestTokenFilter(t *testing.T) {
+	include, err := ParseTokenRules([]string{"EURMTL", "MTL*:GISSUER", "*:GTRUSTED"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exclude, err := ParseTokenRules([]string{"MTLSPAM"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTokenFilter(include, exclude)
+
+	for _, tc := range []struct {
+		asset      domain.AssetInfo
+		wantIgnore bool
+		wantRule   string
+	}{
+		{domain.AssetInfo{Code: "EURMTL", Issuer: "GANY"}, false, ""},
+		{domain.AssetInfo{Code: "MTLRECT", Issuer: "GISSUER"}, false, ""},
+		{domain.AssetInfo{Code: "MTLRECT", Issuer: "GFAKE"}, true, ""},
+		{domain.AssetInfo{Code: "WHATEVER", Issuer: "GTRUSTED"}, false, ""},
+		{domain.AssetInfo{Code: "MTLSPAM", Issuer: "GISSUER"}, true, "MTLSPAM"}, // exclude wins
+		{domain.AssetInfo{Code: "BTC", Issuer: "GOTHER"}, true, ""},
+	} {
+		rule, ignore := f.Ignore(tc.asset)
+		if ignore != tc.wantIgnore || rule != tc.wantRule {
+			t.Errorf("Ignore(%s) = %q, %v; want %q, %v", tc.asset.Canonical(), rule, ignore, tc.wantRule, tc.wantIgnore)
+		}
+	}
+
+	var none *TokenFilter
+	if _, ignore := none.Ignore(domain.AssetInfo{Code: "ANY"}); ignore {
+		t.Error("nil filter must keep every token")
+	}
+	if NewTokenFilter(nil, nil) != nil {
+		t.Error("filter without rules should be nil")
+	}
+}
+
+func TestParseTokenRulesInvalid(t *testing.T) {
+	for _, e := range []string{":GISSUER", "[MTL"} {
+		if _, err := ParseTokenRules([]string{e}); err == nil {
+			t.Errorf("ParseTokenRules(%q) = nil error, want invalid rule", e)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package fund
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mtlprog/stat/internal/asof"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/valuation"
+)
+
+// OtherPricing is how the accounts of type other are valued. They are listed
+// in snapshots but left out of the fund totals, so they need not be priced as
+// often as the rest.
+type OtherPricing string
+
+const (
+	OtherPricingDaily   OtherPricing = "daily"   // priced every run, like the other accounts
+	OtherPricingWeekly  OtherPricing = "weekly"  // priced once the carried prices are OtherRepriceAfter old
+	OtherPricingBalance OtherPricing = "balance" // never priced, balances only
+)
+
+// OtherRepriceAfter is how old the prices OtherPricingWeekly carries over may
+// get before an account is priced again.
+const OtherRepriceAfter = 7 * 24 * time.Hour
+
+// ParseOtherPricing validates an OTHER_ACCOUNTS_PRICING value; empty is
+// OtherPricingDaily.
+func ParseOtherPricing(s string) (OtherPricing, error) {
+	switch p := OtherPricing(s); p {
+	case "":
+		return OtherPricingDaily, nil
+	case OtherPricingDaily, OtherPricingWeekly, OtherPricingBalance:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown pricing %q (want %s, %s or %s)", s, OtherPricingDaily, OtherPricingWeekly, OtherPricingBalance)
+	}
+}
+
+// PriorOthers supplies the accounts of type other of the latest snapshot
+// stored at or before at, none when there is no such snapshot.
+type PriorOthers interface {
+	PriorOtherAccounts(ctx context.Context, at time.Time) ([]domain.FundAccountPortfolio, error)
+}
+
+// WithOtherPricing sets how Portfolios values the accounts of type other.
+// OtherPricingWeekly reads the prices to carry over from prior.
+func WithOtherPricing(mode OtherPricing, prior PriorOthers) Option {
+	return func(s *Service) {
+		s.otherMode = mode
+		s.otherPrior = prior
+	}
+}
+
+// otherPricingActive reports whether accounts of type other skip the daily
+// pricing.
+func (s *Service) otherPricingActive() bool {
+	return s.otherMode == OtherPricingWeekly || s.otherMode == OtherPricingBalance
+}
+
+// priorOthers loads the prior portfolios of the accounts of type other by
+// address, for OtherPricingWeekly. A failure only means pricing them again.
+func (s *Service) priorOthers(ctx context.Context, at time.Time) (map[string]domain.FundAccountPortfolio, []string) {
+	if s.otherMode != OtherPricingWeekly || s.otherPrior == nil {
+		return nil, nil
+	}
+	prior, err := s.otherPrior.PriorOtherAccounts(ctx, at)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("prior prices of other accounts unavailable, pricing them: %v", err)}
+	}
+	byAddress := make(map[string]domain.FundAccountPortfolio, len(prior))
+	for _, p := range prior {
+		byAddress[p.ID] = p
+	}
+	return byAddress, nil
+}
+
+// otherPortfolio values an account of type other under s.otherMode: balances
+// only, or the prices of prior while they are younger than
+// OtherRepriceAfter. Otherwise it is priced like any account and stamped
+// with at.
+func (s *Service) otherPortfolio(ctx context.Context, acc domain.FundAccount, prior map[string]domain.FundAccountPortfolio, at time.Time,
+	allValuations []domain.AssetValuation) (domain.FundAccountPortfolio, []string, error) {
+	if s.otherMode == OtherPricingBalance {
+		raw, held, ignored, err := s.holdings(ctx, acc)
+		if err != nil {
+			return domain.FundAccountPortfolio{}, nil, err
+		}
+		tokens := make([]domain.TokenPriceWithBalance, len(held))
+		for i, tb := range held {
+			tokens[i] = domain.TokenPriceWithBalance{Asset: tb.Asset, Balance: tb.Balance, IsNFT: valuation.IsNFT(tb.Balance)}
+		}
+		p := newPortfolio(acc, raw, tokens, ignored, nil)
+		p.Pricing = domain.PricingBalance
+		return p, nil, nil
+	}
+
+	last, ok := prior[acc.Address]
+	if !ok || last.PricedAt == nil || last.Pricing == domain.PricingBalance || at.Sub(*last.PricedAt) >= OtherRepriceAfter {
+		p, warnings, err := s.Portfolio(ctx, acc, allValuations)
+		if err != nil {
+			return domain.FundAccountPortfolio{}, nil, err
+		}
+		p.PricedAt = &at
+		return p, warnings, nil
+	}
+	return s.carriedPortfolio(ctx, acc, last, allValuations)
+}
+
+// carriedPortfolio values the current balances of acc at the token prices of
+// last. Tokens last didn't price are priced now. The XLM price is today's,
+// as every account looks it up anyway.
+func (s *Service) carriedPortfolio(ctx context.Context, acc domain.FundAccount, last domain.FundAccountPortfolio,
+	allValuations []domain.AssetValuation) (domain.FundAccountPortfolio, []string, error) {
+	raw, held, ignored, err := s.holdings(ctx, acc)
+	if err != nil {
+		return domain.FundAccountPortfolio{}, nil, err
+	}
+	lastTokens := make(map[domain.AssetInfo]domain.TokenPriceWithBalance, len(last.Tokens))
+	for _, t := range last.Tokens {
+		lastTokens[t.Asset] = t
+	}
+
+	tokens := make([]domain.TokenPriceWithBalance, len(held))
+	var unpriced []domain.TokenBalance
+	var unpricedAt []int
+	for i, tb := range held {
+		lt, ok := lastTokens[tb.Asset]
+		if !ok || lt.PriceInEURMTL == nil {
+			unpriced = append(unpriced, tb)
+			unpricedAt = append(unpricedAt, i)
+			continue
+		}
+		tokens[i] = carryToken(tb, lt)
+	}
+
+	var warnings []string
+	if len(unpriced) > 0 {
+		priced, w, err := s.priceHeld(ctx, acc, unpriced, mergeValuations(acc.Address, allValuations))
+		if err != nil {
+			return domain.FundAccountPortfolio{}, nil, err
+		}
+		for j, i := range unpricedAt {
+			tokens[i] = priced[j]
+		}
+		warnings = append(warnings, w...)
+	}
+
+	xlmPriceInEURMTL, xlmWarnings := s.xlmPrice(ctx, acc)
+	p := newPortfolio(acc, raw, tokens, ignored, xlmPriceInEURMTL)
+	p.Pricing = domain.PricingCarried
+	p.PricedAt = last.PricedAt
+	return p, append(warnings, xlmWarnings...), nil
+}
+
+// carryToken values tb at the prices of last. The price details stay
+// behind: they describe a past discovery, and price warm-up would otherwise
+// take the carried prices for fresh ones.
+func carryToken(tb domain.TokenBalance, last domain.TokenPriceWithBalance) domain.TokenPriceWithBalance {
+	isNFT := valuation.IsNFT(tb.Balance)
+	value := func(price *string) *string {
+		if price == nil {
+			return nil
+		}
+		// A manually valued NFT is worth its valuation, as in priceToken.
+		if isNFT && last.Valuation != nil {
+			v := *price
+			return &v
+		}
+		v := domain.MultiplyAmount(tb.Balance, *price)
+		return &v
+	}
+	return domain.TokenPriceWithBalance{
+		Asset:               tb.Asset,
+		Balance:             tb.Balance,
+		PriceInEURMTL:       last.PriceInEURMTL,
+		PriceInXLM:          last.PriceInXLM,
+		ValueInEURMTL:       value(last.PriceInEURMTL),
+		ValueInXLM:          value(last.PriceInXLM),
+		IsNFT:               isNFT,
+		NFTValuationAccount: last.NFTValuationAccount,
+		Valuation:           last.Valuation,
+	}
+}
+
+// runTime is the time a run prices as of: the as-of time of a backfill, now
+// otherwise.
+func runTime(ctx context.Context) time.Time {
+	if t, ok := asof.From(ctx); ok {
+		return t
+	}
+	return time.Now()
+}
@@ -0,0 +1,158 @@
+package fund
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/asof"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/price"
+)
+
+type countingPrice struct {
+	mockPrice
+	batched int // tokens priced through GetTokenPricesBatch
+}
+
+func (m *countingPrice) GetTokenPricesBatch(ctx context.Context, balances []price.AssetBalance) []price.BatchResult {
+	m.batched += len(balances)
+	return m.mockPrice.GetTokenPricesBatch(ctx, balances)
+}
+
+type fakePriorOthers struct {
+	accounts []domain.FundAccountPortfolio
+	err      error
+}
+
+func (f fakePriorOthers) PriorOtherAccounts(context.Context, time.Time) ([]domain.FundAccountPortfolio, error) {
+	return f.accounts, f.err
+}
+
+var (
+	otherAccount = domain.FundAccount{Name: "LABR", Type: domain.AccountTypeOther, Address: "GLABR"}
+	mtlAsset     = domain.AssetInfo{Code: "MTL", Issuer: domain.IssuerAddress, Type: domain.AssetTypeCreditAlphanum4}
+	labrAsset    = domain.AssetInfo{Code: "LABR", Issuer: domain.IssuerAddress, Type: domain.AssetTypeCreditAlphanum4}
+)
+
+func otherService(prices *countingPrice, mode OtherPricing, prior PriorOthers) *Service {
+	return NewService(&mockPortfolio{portfolios: map[string]domain.AccountPortfolio{
+		"GLABR": {AccountID: "GLABR", XLMBalance: "10", Tokens: []domain.TokenBalance{
+			{Asset: mtlAsset, Balance: "3"},
+			{Asset: labrAsset, Balance: "5"},
+		}},
+	}}, prices, &mockValuation{}, &mockExternal{}, WithOtherPricing(mode, prior))
+}
+
+func TestParseOtherPricing(t *testing.T) {
+	for in, want := range map[string]OtherPricing{"": OtherPricingDaily, "daily": OtherPricingDaily, "weekly": OtherPricingWeekly, "balance": OtherPricingBalance} {
+		if got, err := ParseOtherPricing(in); err != nil || got != want {
+			t.Errorf("ParseOtherPricing(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseOtherPricing("monthly"); err == nil {
+		t.Error("expected error for unknown pricing")
+	}
+}
+
+func TestOtherPricingBalance(t *testing.T) {
+	prices := &countingPrice{}
+	svc := otherService(prices, OtherPricingBalance, nil)
+
+	got, warnings, err := svc.Portfolios(context.Background(), []domain.FundAccount{otherAccount}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := got[0]
+	if prices.batched != 0 || len(warnings) != 0 {
+		t.Errorf("batched %d tokens with warnings %v, want none", prices.batched, warnings)
+	}
+	if p.Pricing != domain.PricingBalance || p.PricedAt != nil || !p.TotalEURMTL.IsZero() || p.XLMPriceInEURMTL != nil {
+		t.Errorf("portfolio = %+v, want balances only", p)
+	}
+	if len(p.Tokens) != 2 || p.Tokens[0].Balance != "3" || p.Tokens[0].PriceInEURMTL != nil {
+		t.Errorf("tokens = %+v", p.Tokens)
+	}
+}
+
+func TestOtherPricingWeeklyCarries(t *testing.T) {
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	pricedAt := at.Add(-3 * 24 * time.Hour)
+	priceEUR, priceXLM := "4", "20"
+	prior := fakePriorOthers{accounts: []domain.FundAccountPortfolio{{
+		ID:       "GLABR",
+		PricedAt: &pricedAt,
+		Tokens:   []domain.TokenPriceWithBalance{{Asset: mtlAsset, Balance: "1", PriceInEURMTL: &priceEUR, PriceInXLM: &priceXLM, DetailsEURMTL: &domain.PriceDetails{Source: "path"}}},
+	}}}
+	prices := &countingPrice{}
+	svc := otherService(prices, OtherPricingWeekly, prior)
+
+	got, _, err := svc.Portfolios(asof.With(context.Background(), at), []domain.FundAccount{otherAccount}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := got[0]
+	if p.Pricing != domain.PricingCarried || p.PricedAt == nil || !p.PricedAt.Equal(pricedAt) {
+		t.Errorf("pricing = %q at %v, want carried from %v", p.Pricing, p.PricedAt, pricedAt)
+	}
+	mtl := p.Tokens[0]
+	if *mtl.PriceInEURMTL != "4" || *mtl.ValueInEURMTL != "12" || *mtl.ValueInXLM != "60" || mtl.DetailsEURMTL != nil {
+		t.Errorf("MTL = %+v, want 3 at the carried price without details", mtl)
+	}
+	// LABR was not in the prior snapshot, so it alone is priced.
+	if prices.batched != 1 || p.Tokens[1].PriceInEURMTL == nil || *p.Tokens[1].PriceInEURMTL != "2.0" {
+		t.Errorf("batched %d, LABR = %+v; want LABR priced now", prices.batched, p.Tokens[1])
+	}
+	if p.XLMPriceInEURMTL == nil || p.TotalEURMTL.IsZero() {
+		t.Errorf("XLM price %v, total %s; want both", p.XLMPriceInEURMTL, p.TotalEURMTL)
+	}
+}
+
+func TestOtherPricingWeeklyReprices(t *testing.T) {
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := at.Add(-OtherRepriceAfter)
+	price := "4"
+	tests := []struct {
+		name  string
+		prior PriorOthers
+		warn  bool
+	}{
+		{"no prior", fakePriorOthers{}, false},
+		{"prices too old", fakePriorOthers{accounts: []domain.FundAccountPortfolio{{ID: "GLABR", PricedAt: &old,
+			Tokens: []domain.TokenPriceWithBalance{{Asset: mtlAsset, PriceInEURMTL: &price}}}}}, false},
+		{"unstamped prior", fakePriorOthers{accounts: []domain.FundAccountPortfolio{{ID: "GLABR"}}}, false},
+		{"prior unavailable", fakePriorOthers{err: errors.New("db down")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices := &countingPrice{}
+			svc := otherService(prices, OtherPricingWeekly, tt.prior)
+			got, warnings, err := svc.Portfolios(asof.With(context.Background(), at), []domain.FundAccount{otherAccount}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p := got[0]
+			if p.Pricing != "" || p.PricedAt == nil || !p.PricedAt.Equal(at) || prices.batched != 2 {
+				t.Errorf("pricing = %q at %v, batched %d; want priced now", p.Pricing, p.PricedAt, prices.batched)
+			}
+			if (len(warnings) > 0) != tt.warn {
+				t.Errorf("warnings = %v, want some: %v", warnings, tt.warn)
+			}
+		})
+	}
+}
+
+func TestOtherPricingLeavesMainAccounts(t *testing.T) {
+	prices := &countingPrice{}
+	svc := otherService(prices, OtherPricingBalance, nil)
+	main := domain.FundAccount{Name: "MAIN", Type: domain.AccountTypeSubfond, Address: "GLABR"}
+
+	got, _, err := svc.Portfolios(context.Background(), []domain.FundAccount{main}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[0].Pricing != "" || got[0].PricedAt != nil || prices.batched != 2 {
+		t.Errorf("main account = %q at %v, batched %d; want priced as always", got[0].Pricing, got[0].PricedAt, prices.batched)
+	}
+}
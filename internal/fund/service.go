@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/samber/lo"
 
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/pacing"
 	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/progress"
+	"github.com/mtlprog/stat/internal/slo"
 	"github.com/mtlprog/stat/internal/valuation"
 )
 
@@ -21,7 +25,7 @@ type PortfolioService interface {
 // PriceService defines the price discovery interface.
 type PriceService interface {
 	GetPrice(ctx context.Context, asset, baseAsset domain.AssetInfo, amount string) (domain.TokenPairPrice, error)
-	GetTokenPrices(ctx context.Context, asset domain.AssetInfo, balance string) (price.TokenPriceResult, error)
+	GetTokenPricesBatch(ctx context.Context, balances []price.AssetBalance) []price.BatchResult
 }
 
 // ValuationService defines the valuation scanning interface.
@@ -40,10 +44,30 @@ type Service struct {
 	price     PriceService
 	valuation ValuationService
 	external  ExternalPriceService
+	pacer     *pacing.Pacer
+	filter    *TokenFilter
+
+	otherMode  OtherPricing
+	otherPrior PriorOthers
+}
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithPacer paces the account fetches against Horizon. Token pricing is paced
+// by the price service itself.
+func WithPacer(p *pacing.Pacer) Option {
+	return func(s *Service) { s.pacer = p }
+}
+
+// WithTokenFilter leaves tokens rejected by f out of valuation. They are
+// listed in FundAccountPortfolio.Ignored instead of Tokens.
+func WithTokenFilter(f *TokenFilter) Option {
+	return func(s *Service) { s.filter = f }
 }
 
 // NewService creates a new fund structure Service. All dependencies are required.
-func NewService(portfolio PortfolioService, priceSvc PriceService, val ValuationService, ext ExternalPriceService) *Service {
+func NewService(portfolio PortfolioService, priceSvc PriceService, val ValuationService, ext ExternalPriceService, opts ...Option) *Service {
 	if portfolio == nil {
 		panic("fund.NewService: portfolio is nil")
 	}
@@ -56,43 +80,33 @@ func NewService(portfolio PortfolioService, priceSvc PriceService, val Valuation
 	if ext == nil {
 		panic("fund.NewService: external is nil")
 	}
-	return &Service{
+	s := &Service{
 		portfolio: portfolio,
 		price:     priceSvc,
 		valuation: val,
 		external:  ext,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GetFundStructure runs the full fund aggregation pipeline.
 func (s *Service) GetFundStructure(ctx context.Context) (domain.FundStructureData, error) {
 	t0 := time.Now()
+	progress.Report(ctx, progress.Event{Stage: progress.StageValuations})
 	slog.Debug("fund.GetFundStructure: fetching valuations")
 	allValuations, err := s.valuation.FetchAllValuations(ctx)
 	if err != nil {
 		return domain.FundStructureData{}, fmt.Errorf("fetching valuations: %w", err)
 	}
+	slo.Since(ctx, slo.StageValuation, t0)
 	slog.Debug("fund.valuations done", "count", len(allValuations), "duration_ms", time.Since(t0).Milliseconds())
 
-	var allPortfolios []domain.FundAccountPortfolio
-	var warnings []string
-	for _, acc := range domain.AccountRegistry() {
-		ta := time.Now()
-		slog.Debug("fund.processAccount: start", "account", acc.Name)
-		portfolio, accWarnings, err := s.processAccount(ctx, acc, allValuations)
-		if err != nil {
-			return domain.FundStructureData{}, fmt.Errorf("processing account %s: %w", acc.Name, err)
-		}
-		slog.Debug("fund.processAccount: done", "account", acc.Name, "tokens", len(portfolio.Tokens), "duration_ms", time.Since(ta).Milliseconds())
-		allPortfolios = append(allPortfolios, portfolio)
-		warnings = append(warnings, accWarnings...)
-
-		// 200ms delay between accounts
-		select {
-		case <-ctx.Done():
-			return domain.FundStructureData{}, ctx.Err()
-		case <-time.After(200 * time.Millisecond):
-		}
+	allPortfolios, warnings, err := s.Portfolios(ctx, domain.AccountRegistry(), allValuations)
+	if err != nil {
+		return domain.FundStructureData{}, err
 	}
 
 	mainAccounts, mutualAccounts, otherAccounts := partitionAccounts(allPortfolios)
@@ -103,25 +117,138 @@ func (s *Service) GetFundStructure(ctx context.Context) (domain.FundStructureDat
 		OtherAccounts:    otherAccounts,
 		AggregatedTotals: calculateFundTotals(mainAccounts),
 		Warnings:         warnings,
+		Quotes:           collectQuotes(allPortfolios),
 	}, nil
 }
 
-func (s *Service) processAccount(ctx context.Context, acc domain.FundAccount, allValuations []domain.AssetValuation) (domain.FundAccountPortfolio, []string, error) {
+// collectQuotes returns the distinct external quotes used by valuations across
+// all portfolios, sorted by symbol. Every token in a run resolves a symbol
+// against the same stored quote, so one entry per symbol is enough.
+func collectQuotes(portfolios []domain.FundAccountPortfolio) []domain.QuoteUsage {
+	bySymbol := make(map[string]domain.QuoteUsage)
+	for _, p := range portfolios {
+		for _, t := range p.Tokens {
+			if t.Valuation != nil && t.Valuation.Quote != nil {
+				bySymbol[t.Valuation.Quote.Symbol] = *t.Valuation.Quote
+			}
+		}
+	}
+	quotes := lo.Values(bySymbol)
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Symbol < quotes[j].Symbol })
+	return quotes
+}
+
+// Portfolios prices every account in accounts, in order. allValuations are the
+// manual valuation overrides to honour (see mergeValuations); pass nil to value
+// at market prices only, as for accounts outside the fund. Accounts of type
+// other follow WithOtherPricing. The first account that cannot be fetched
+// aborts the run.
+func (s *Service) Portfolios(ctx context.Context, accounts []domain.FundAccount, allValuations []domain.AssetValuation) ([]domain.FundAccountPortfolio, []string, error) {
+	var portfolios []domain.FundAccountPortfolio
+	var warnings []string
+	var prior map[string]domain.FundAccountPortfolio
+	at := runTime(ctx)
+	if s.otherPricingActive() && lo.ContainsBy(accounts, func(a domain.FundAccount) bool { return a.Type == domain.AccountTypeOther }) {
+		prior, warnings = s.priorOthers(ctx, at)
+	}
+	for i, acc := range accounts {
+		ta := time.Now()
+		progress.Report(ctx, progress.Event{
+			Stage:        progress.StageAccounts,
+			Account:      acc.Name,
+			AccountIndex: i + 1,
+			AccountTotal: len(accounts),
+		})
+		slog.Debug("fund.Portfolio: start", "account", acc.Name)
+		var portfolio domain.FundAccountPortfolio
+		var accWarnings []string
+		var err error
+		if acc.Type == domain.AccountTypeOther && s.otherPricingActive() {
+			portfolio, accWarnings, err = s.otherPortfolio(ctx, acc, prior, at, allValuations)
+		} else {
+			portfolio, accWarnings, err = s.Portfolio(ctx, acc, allValuations)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("processing account %s: %w", acc.Name, err)
+		}
+		slog.Debug("fund.Portfolio: done", "account", acc.Name, "tokens", len(portfolio.Tokens), "duration_ms", time.Since(ta).Milliseconds())
+		portfolios = append(portfolios, portfolio)
+		warnings = append(warnings, accWarnings...)
+	}
+	return portfolios, warnings, nil
+}
+
+// Portfolio fetches and prices a single account. Tokens that cannot be priced
+// are kept with their balance only and reported as warnings; tokens rejected
+// by the token filter are not priced at all and go to Ignored.
+func (s *Service) Portfolio(ctx context.Context, acc domain.FundAccount, allValuations []domain.AssetValuation) (domain.FundAccountPortfolio, []string, error) {
+	raw, held, ignored, err := s.holdings(ctx, acc)
+	if err != nil {
+		return domain.FundAccountPortfolio{}, nil, err
+	}
+	tokens, warnings, err := s.priceHeld(ctx, acc, held, mergeValuations(acc.Address, allValuations))
+	if err != nil {
+		return domain.FundAccountPortfolio{}, nil, err
+	}
+	xlmPriceInEURMTL, xlmWarnings := s.xlmPrice(ctx, acc)
+	return newPortfolio(acc, raw, tokens, ignored, xlmPriceInEURMTL), append(warnings, xlmWarnings...), nil
+}
+
+// holdings fetches the balances of acc and splits off the tokens the token
+// filter rejects.
+func (s *Service) holdings(ctx context.Context, acc domain.FundAccount) (domain.AccountPortfolio, []domain.TokenBalance, []domain.IgnoredToken, error) {
+	if err := s.pacer.Wait(ctx); err != nil {
+		return domain.AccountPortfolio{}, nil, nil, err
+	}
 	tFetch := time.Now()
 	rawPortfolio, err := s.portfolio.FetchPortfolio(ctx, acc.Address)
 	if err != nil {
-		return domain.FundAccountPortfolio{}, nil, err
+		return domain.AccountPortfolio{}, nil, nil, err
 	}
+	slo.Since(ctx, slo.StagePortfolio, tFetch)
 	slog.Debug("fund.fetchPortfolio done", "account", acc.Name, "tokens", len(rawPortfolio.Tokens), "duration_ms", time.Since(tFetch).Milliseconds())
 
-	accountValuations := mergeValuations(acc.Address, allValuations)
+	var ignored []domain.IgnoredToken
+	held := lo.Filter(rawPortfolio.Tokens, func(tb domain.TokenBalance, _ int) bool {
+		rule, skip := s.filter.Ignore(tb.Asset)
+		if skip {
+			ignored = append(ignored, domain.IgnoredToken{Asset: tb.Asset, Balance: tb.Balance, Rule: rule})
+		}
+		return !skip
+	})
+	if len(ignored) > 0 {
+		slog.Debug("fund.Portfolio: tokens ignored by filter", "account", acc.Name, "count", len(ignored))
+	}
+	return rawPortfolio, held, ignored, nil
+}
+
+// priceHeld prices the held tokens of acc, in order.
+func (s *Service) priceHeld(ctx context.Context, acc domain.FundAccount, held []domain.TokenBalance, accountValuations []domain.AssetValuation) ([]domain.TokenPriceWithBalance, []string, error) {
+	// Market prices for every held token in one pass: tokens share their
+	// EURMTL and XLM pairs, so each pair is looked up once.
+	tPrices := time.Now()
+	defer slo.Since(ctx, slo.StagePricing, tPrices)
+	prices := s.price.GetTokenPricesBatch(ctx, lo.Map(held, func(tb domain.TokenBalance, _ int) price.AssetBalance {
+		return price.AssetBalance{Asset: tb.Asset, Balance: tb.Balance}
+	}))
+	slog.Debug("fund.GetTokenPricesBatch done", "account", acc.Name, "tokens", len(held), "duration_ms", time.Since(tPrices).Milliseconds())
 
 	var tokens []domain.TokenPriceWithBalance
 	var warnings []string
-	for _, tb := range rawPortfolio.Tokens {
+	for i, tb := range held {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
 		tTok := time.Now()
-		token, err := s.priceToken(ctx, tb, acc.Address, accountValuations)
+		token, err := s.priceToken(ctx, tb, prices[i], acc.Address, accountValuations)
 		slog.Debug("fund.priceToken done", "account", acc.Name, "asset", tb.Asset.Code, "duration_ms", time.Since(tTok).Milliseconds(), "err", err)
+		progress.Report(ctx, progress.Event{
+			Stage:      progress.StageAccounts,
+			Account:    acc.Name,
+			Token:      tb.Asset.Code,
+			TokenIndex: i + 1,
+			TokenTotal: len(held),
+		})
 		if err != nil {
 			w := fmt.Sprintf("failed to price %s on %s: %v", tb.Asset.Code, acc.Name, err)
 			slog.Debug("failed to price token", "asset", tb.Asset.Code, "account", acc.Name, "error", err)
@@ -132,43 +259,55 @@ func (s *Service) processAccount(ctx context.Context, acc domain.FundAccount, al
 			})
 			continue
 		}
-		tokens = append(tokens, token)
-
-		// 100ms delay between tokens
-		select {
-		case <-ctx.Done():
-			return domain.FundAccountPortfolio{}, nil, ctx.Err()
-		case <-time.After(100 * time.Millisecond):
+		for _, d := range []*domain.PriceDetails{token.DetailsEURMTL, token.DetailsXLM} {
+			if w, ok := rejectedPriceWarning(tb.Asset.Code, acc.Name, d); ok {
+				warnings = append(warnings, w)
+			}
 		}
+		tokens = append(tokens, token)
 	}
+	return tokens, warnings, nil
+}
 
-	var xlmPriceInEURMTL *string
+// xlmPrice returns the XLM price in EURMTL for the totals of acc, nil with a
+// warning when there is none.
+func (s *Service) xlmPrice(ctx context.Context, acc domain.FundAccount) (*string, []string) {
+	defer slo.Since(ctx, slo.StagePricing, time.Now())
 	xlmResult, err := s.price.GetPrice(ctx, domain.XLMAsset(), domain.EURMTLAsset(), "1")
-	if err == nil {
-		xlmPriceInEURMTL = &xlmResult.Price
-	} else {
+	if err != nil {
 		w := fmt.Sprintf("XLM price unavailable for %s, EURMTL total excludes XLM", acc.Name)
 		slog.Warn(w, "error", err)
+		return nil, []string{w}
+	}
+	var warnings []string
+	if w, ok := rejectedPriceWarning("XLM", acc.Name, xlmResult.Details); ok {
 		warnings = append(warnings, w)
 	}
+	return &xlmResult.Price, warnings
+}
 
+// newPortfolio assembles the portfolio of acc with its totals.
+func newPortfolio(acc domain.FundAccount, raw domain.AccountPortfolio, tokens []domain.TokenPriceWithBalance, ignored []domain.IgnoredToken, xlmPriceInEURMTL *string) domain.FundAccountPortfolio {
 	return domain.FundAccountPortfolio{
 		ID:               acc.Address,
 		Name:             acc.Name,
 		Type:             acc.Type,
 		Description:      acc.Description,
 		Tokens:           tokens,
-		XLMBalance:       rawPortfolio.XLMBalance,
+		XLMBalance:       raw.XLMBalance,
 		XLMPriceInEURMTL: xlmPriceInEURMTL,
-		TotalEURMTL:      calculateAccountTotalEURMTL(tokens, rawPortfolio.XLMBalance, xlmPriceInEURMTL),
-		TotalXLM:         calculateAccountTotalXLM(tokens, rawPortfolio.XLMBalance),
-	}, warnings, nil
+		TotalEURMTL:      calculateAccountTotalEURMTL(tokens, raw.XLMBalance, xlmPriceInEURMTL),
+		TotalXLM:         calculateAccountTotalXLM(tokens, raw.XLMBalance),
+		Ignored:          ignored,
+	}
 }
 
-func (s *Service) priceToken(ctx context.Context, tb domain.TokenBalance, accountID string, accountValuations []domain.AssetValuation) (domain.TokenPriceWithBalance, error) {
+// priceToken values tb at its market prices, or at its manual valuation when
+// one applies and resolves.
+func (s *Service) priceToken(ctx context.Context, tb domain.TokenBalance, market price.BatchResult, accountID string, accountValuations []domain.AssetValuation) (domain.TokenPriceWithBalance, error) {
 	isNFT := valuation.IsNFT(tb.Balance)
 
-	prices, priceErr := s.price.GetTokenPrices(ctx, tb.Asset, tb.Balance)
+	prices, priceErr := market.TokenPriceResult, market.Err
 
 	result := domain.TokenPriceWithBalance{
 		Asset:         tb.Asset,
@@ -199,10 +338,11 @@ func (s *Service) priceToken(ctx context.Context, tb domain.TokenBalance, accoun
 			if isNFT {
 				result.ValueInEURMTL = &resolved.ValueInEURMTL
 			} else {
-				v := domain.MultiplyWithPrecision(tb.Balance, resolved.ValueInEURMTL)
+				v := domain.MultiplyAmount(tb.Balance, resolved.ValueInEURMTL)
 				result.ValueInEURMTL = &v
 			}
 			result.NFTValuationAccount = val.SourceAccount
+			result.Valuation = &resolved
 
 			// Derive XLM value from EURMTL valuation
 			// priceInXLM = valuationInEURMTL / xlmRate.Price
@@ -210,12 +350,12 @@ func (s *Service) priceToken(ctx context.Context, tb domain.TokenBalance, accoun
 			if xlmErr != nil {
 				slog.Debug("failed to derive XLM price for valuation override", "token", tb.Asset.Code, "error", xlmErr)
 			} else {
-				xlmPrice := domain.DivideWithPrecision(resolved.ValueInEURMTL, xlmRate.Price)
+				xlmPrice := domain.DividePrice(resolved.ValueInEURMTL, xlmRate.Price)
 				result.PriceInXLM = &xlmPrice
 				if isNFT {
 					result.ValueInXLM = &xlmPrice
 				} else {
-					xlmVal := domain.MultiplyWithPrecision(tb.Balance, xlmPrice)
+					xlmVal := domain.MultiplyAmount(tb.Balance, xlmPrice)
 					result.ValueInXLM = &xlmVal
 				}
 			}
@@ -252,3 +392,12 @@ func strToPtr(s string) *string {
 	}
 	return &s
 }
+
+// rejectedPriceWarning describes a price that failed the sanity bound and was
+// replaced by the previous snapshot's price.
+func rejectedPriceWarning(code, account string, d *domain.PriceDetails) (string, bool) {
+	if d == nil || d.Source != price.SourcePrevious || d.RejectedPrice == nil {
+		return "", false
+	}
+	return fmt.Sprintf("rejected price %s for %s on %s (outside sanity bound), kept previous snapshot price", *d.RejectedPrice, code, account), true
+}
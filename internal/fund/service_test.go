@@ -3,6 +3,7 @@ package fund
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -32,13 +33,17 @@ func (m *mockPrice) GetPrice(_ context.Context, _, _ domain.AssetInfo, _ string)
 	return domain.TokenPairPrice{Price: "0.5"}, nil
 }
 
-func (m *mockPrice) GetTokenPrices(_ context.Context, _ domain.AssetInfo, _ string) (price.TokenPriceResult, error) {
-	return price.TokenPriceResult{
-		PriceEURMTL: "2.0",
-		PriceXLM:    "10.0",
-		ValueEURMTL: "20.0",
-		ValueXLM:    "100.0",
-	}, nil
+func (m *mockPrice) GetTokenPricesBatch(_ context.Context, balances []price.AssetBalance) []price.BatchResult {
+	out := make([]price.BatchResult, len(balances))
+	for i := range out {
+		out[i].TokenPriceResult = price.TokenPriceResult{
+			PriceEURMTL: "2.0",
+			PriceXLM:    "10.0",
+			ValueEURMTL: "20.0",
+			ValueXLM:    "100.0",
+		}
+	}
+	return out
 }
 
 type mockValuation struct {
@@ -110,6 +115,11 @@ func TestGetFundStructurePartitioning(t *testing.T) {
 	}
 }
 
+// marketPrice prices tb alone, as Portfolio does for each held token.
+func marketPrice(svc *Service, tb domain.TokenBalance) price.BatchResult {
+	return svc.price.GetTokenPricesBatch(context.Background(), []price.AssetBalance{{Asset: tb.Asset, Balance: tb.Balance}})[0]
+}
+
 func TestPriceTokenNFTWithValuation(t *testing.T) {
 	svc := &Service{
 		price:    &mockPrice{},
@@ -125,7 +135,7 @@ func TestPriceTokenNFTWithValuation(t *testing.T) {
 		{TokenCode: "MYTOKEN", ValuationType: domain.ValuationTypeNFT, RawValue: domain.ValuationValue{Type: domain.ValuationValueEURMTL, Value: "500"}, SourceAccount: "GACCOUNT"},
 	}
 
-	result, err := svc.priceToken(context.Background(), tb, "GACCOUNT", accountValuations)
+	result, err := svc.priceToken(context.Background(), tb, marketPrice(svc, tb), "GACCOUNT", accountValuations)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -153,7 +163,7 @@ func TestPriceTokenRegularWithValuation(t *testing.T) {
 		{TokenCode: "MYTOKEN", ValuationType: domain.ValuationTypeUnit, RawValue: domain.ValuationValue{Type: domain.ValuationValueEURMTL, Value: "10"}, SourceAccount: "GACCOUNT"},
 	}
 
-	result, err := svc.priceToken(context.Background(), tb, "GACCOUNT", accountValuations)
+	result, err := svc.priceToken(context.Background(), tb, marketPrice(svc, tb), "GACCOUNT", accountValuations)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -165,6 +175,23 @@ func TestPriceTokenRegularWithValuation(t *testing.T) {
 	if result.ValueInEURMTL == nil || *result.ValueInEURMTL != "50" {
 		t.Errorf("ValueInEURMTL = %v, want 50", result.ValueInEURMTL)
 	}
+	if result.Valuation == nil || result.Valuation.ValueInEURMTL != "10" {
+		t.Errorf("Valuation = %+v, want the resolved valuation recorded", result.Valuation)
+	}
+}
+
+func TestCollectQuotes(t *testing.T) {
+	quoted := func(symbol string) domain.TokenPriceWithBalance {
+		return domain.TokenPriceWithBalance{Valuation: &domain.ResolvedAssetValuation{Quote: &domain.QuoteUsage{Symbol: symbol}}}
+	}
+	portfolios := []domain.FundAccountPortfolio{
+		{Tokens: []domain.TokenPriceWithBalance{quoted("XLM"), quoted("BTC"), {}}},
+		{Tokens: []domain.TokenPriceWithBalance{quoted("BTC"), {Valuation: &domain.ResolvedAssetValuation{}}}},
+	}
+	quotes := collectQuotes(portfolios)
+	if len(quotes) != 2 || quotes[0].Symbol != "BTC" || quotes[1].Symbol != "XLM" {
+		t.Errorf("collectQuotes = %+v, want BTC and XLM once each", quotes)
+	}
 }
 
 func TestPriceTokenValuationResolutionFallback(t *testing.T) {
@@ -182,12 +209,12 @@ func TestPriceTokenValuationResolutionFallback(t *testing.T) {
 		{TokenCode: "MYTOKEN", ValuationType: domain.ValuationTypeNFT, RawValue: domain.ValuationValue{Type: domain.ValuationValueEURMTL, Value: "500"}, SourceAccount: "GACCOUNT"},
 	}
 
-	result, err := svc.priceToken(context.Background(), tb, "GACCOUNT", accountValuations)
+	result, err := svc.priceToken(context.Background(), tb, marketPrice(svc, tb), "GACCOUNT", accountValuations)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// When resolution fails, should fall back to market price from GetTokenPrices
+	// When resolution fails, should fall back to market price from GetTokenPricesBatch
 	if result.PriceInEURMTL == nil || *result.PriceInEURMTL != "2.0" {
 		t.Errorf("PriceInEURMTL = %v, want 2.0 (market price fallback)", result.PriceInEURMTL)
 	}
@@ -276,3 +303,97 @@ func TestPartitionAccounts(t *testing.T) {
 		t.Errorf("other = %d, want 1", len(other))
 	}
 }
+
+func TestPortfolioWithoutValuations(t *testing.T) {
+	peer := domain.FundAccount{Name: "PEER", Type: domain.AccountTypePeer, Address: "GPEER"}
+	svc := NewService(
+		&mockPortfolio{portfolios: map[string]domain.AccountPortfolio{
+			"GPEER": {AccountID: "GPEER", Tokens: []domain.TokenBalance{{Asset: domain.AssetInfo{Code: "EURMTL"}, Balance: "10"}}, XLMBalance: "100"},
+		}},
+		&mockPrice{},
+		&mockValuation{},
+		&mockExternal{},
+	)
+
+	p, _, err := svc.Portfolio(context.Background(), peer, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 10 × 2.0 market price + 100 XLM × 0.5
+	if !p.TotalEURMTL.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("TotalEURMTL = %s, want 70", p.TotalEURMTL)
+	}
+	if p.Type != domain.AccountTypePeer || p.ID != "GPEER" {
+		t.Errorf("portfolio = %+v", p)
+	}
+}
+
+func TestPortfolioTokenFilter(t *testing.T) {
+	acc := domain.FundAccount{Name: "MAIN", Address: "GMAIN"}
+	exclude, err := ParseTokenRules([]string{"*AIRDROP*", "*:GSPAM"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService(
+		&mockPortfolio{portfolios: map[string]domain.AccountPortfolio{
+			"GMAIN": {AccountID: "GMAIN", XLMBalance: "0", Tokens: []domain.TokenBalance{
+				{Asset: domain.AssetInfo{Code: "EURMTL", Issuer: "GISSUER"}, Balance: "10"},
+				{Asset: domain.AssetInfo{Code: "FREEAIRDROP", Issuer: "GOTHER"}, Balance: "1000"},
+				{Asset: domain.AssetInfo{Code: "USDC", Issuer: "GSPAM"}, Balance: "5"},
+			}},
+		}},
+		&mockPrice{},
+		&mockValuation{},
+		&mockExternal{},
+		WithTokenFilter(NewTokenFilter(nil, exclude)),
+	)
+
+	p, _, err := svc.Portfolio(context.Background(), acc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Tokens) != 1 || p.Tokens[0].Asset.Code != "EURMTL" {
+		t.Errorf("Tokens = %+v, want only EURMTL", p.Tokens)
+	}
+	if !p.TotalEURMTL.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("TotalEURMTL = %s, want 20 (ignored tokens not valued)", p.TotalEURMTL)
+	}
+	if len(p.Ignored) != 2 || p.Ignored[0].Rule != "*AIRDROP*" || p.Ignored[1].Rule != "*:GSPAM" || p.Ignored[1].Balance != "5" {
+		t.Errorf("Ignored = %+v, want FREEAIRDROP and USDC with their rules", p.Ignored)
+	}
+}
+
+type rejectingPrice struct{ mockPrice }
+
+func (m *rejectingPrice) GetTokenPricesBatch(_ context.Context, balances []price.AssetBalance) []price.BatchResult {
+	rejected := "2000000"
+	out := make([]price.BatchResult, len(balances))
+	for i := range out {
+		out[i].TokenPriceResult = price.TokenPriceResult{
+			PriceEURMTL:   "2.0",
+			ValueEURMTL:   "20.0",
+			DetailsEURMTL: &domain.PriceDetails{Source: price.SourcePrevious, RejectedPrice: &rejected},
+		}
+	}
+	return out
+}
+
+func TestPortfolioWarnsOnRejectedPrice(t *testing.T) {
+	acc := domain.FundAccount{Name: "DEFI", Address: "GDEFI"}
+	svc := NewService(
+		&mockPortfolio{portfolios: map[string]domain.AccountPortfolio{
+			"GDEFI": {AccountID: "GDEFI", Tokens: []domain.TokenBalance{{Asset: domain.AssetInfo{Code: "TOKEN"}, Balance: "10"}}, XLMBalance: "0"},
+		}},
+		&rejectingPrice{},
+		&mockValuation{},
+		&mockExternal{},
+	)
+
+	_, warnings, err := svc.Portfolio(context.Background(), acc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "rejected price 2000000 for TOKEN on DEFI") {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
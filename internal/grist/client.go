@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/mtlprog/stat/internal/fault"
 )
 
 const maxResponseBytes = 1 << 20
@@ -20,14 +22,27 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// Option configures NewClient.
+type Option func(*Client)
+
+// WithTransport sends requests through rt, normally the shared
+// transport.Transport, instead of a connection pool of the client's own.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.httpClient.Transport = rt }
+}
+
 // NewClient creates a Client. baseURL is the Grist instance root (e.g. "https://montelibero.getgrist.com").
-func NewClient(baseURL, docID, apiKey string) *Client {
-	return &Client{
+func NewClient(baseURL, docID, apiKey string, opts ...Option) *Client {
+	c := &Client{
 		baseURL:    baseURL,
 		docID:      docID,
 		apiKey:     apiKey,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type addRecordsRequest struct {
@@ -62,7 +77,7 @@ func (c *Client) AddRecords(ctx context.Context, tableID string, records []map[s
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("calling grist add-records: %w", err)
+		return fault.New("grist", fault.KindOf(err), fmt.Errorf("calling grist add-records: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -71,9 +86,13 @@ func (c *Client) AddRecords(ctx context.Context, tableID string, records []map[s
 		return nil
 	}
 
+	// Not retried here: a retry after a lost response would add the records
+	// twice.
 	preview, err := io.ReadAll(io.LimitReader(resp.Body, 512))
 	if err != nil {
-		return fmt.Errorf("grist add-records returned %s (could not read body: %w)", resp.Status, err)
+		return fault.FromResponse("grist", resp.StatusCode, resp.Header,
+			fmt.Errorf("grist add-records returned %s (could not read body: %w)", resp.Status, err))
 	}
-	return fmt.Errorf("grist add-records returned %s: %s", resp.Status, string(preview))
+	return fault.FromResponse("grist", resp.StatusCode, resp.Header,
+		fmt.Errorf("grist add-records returned %s: %s", resp.Status, string(preview)))
 }
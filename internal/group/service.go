@@ -0,0 +1,133 @@
+// Package group rolls fund accounts up into the groups configured by
+// ACCOUNT_GROUPS, such as a real estate cluster of MCITY and APART, so
+// reports can show totals the account types don't: a group may mix
+// sub-funds, mutual and other accounts. The rollup is computed from the
+// snapshot's portfolios at snapshot time and stored with it.
+package group
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Group is one configured rollup.
+type Group struct {
+	Name    string
+	Members []domain.FundAccount
+}
+
+// validName keeps group names usable as a URL path segment and a sheet
+// column header.
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ParseGroups parses ACCOUNT_GROUPS entries of the form
+// NAME=MEMBER+MEMBER..., where NAME is a lowercase slug and each MEMBER is
+// the name (case-insensitive) or address of an account in registry. An
+// account may belong to several groups, but only once to each.
+func ParseGroups(entries []string, registry []domain.FundAccount) ([]Group, error) {
+	seen := make(map[string]bool, len(entries))
+	var out []Group
+	for _, e := range entries {
+		name, members, ok := strings.Cut(strings.TrimSpace(e), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || !validName.MatchString(name) {
+			return nil, fmt.Errorf("invalid account group %q: want NAME=MEMBER+MEMBER with a lowercase name of letters, digits and dashes", e)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate account group %s", name)
+		}
+		seen[name] = true
+
+		g := Group{Name: name}
+		inGroup := make(map[string]bool)
+		for _, m := range strings.Split(members, "+") {
+			acc, ok := lookup(strings.TrimSpace(m), registry)
+			if !ok {
+				return nil, fmt.Errorf("account group %s: unknown fund account %q", name, strings.TrimSpace(m))
+			}
+			if inGroup[acc.Address] {
+				return nil, fmt.Errorf("account group %s: %s listed twice", name, acc.Name)
+			}
+			inGroup[acc.Address] = true
+			g.Members = append(g.Members, acc)
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
+
+func lookup(member string, registry []domain.FundAccount) (domain.FundAccount, bool) {
+	for _, acc := range registry {
+		if member == acc.Address || strings.EqualFold(member, acc.Name) {
+			return acc, true
+		}
+	}
+	return domain.FundAccount{}, false
+}
+
+// Totals computes the rollup of each group from the portfolios in data. A
+// member with no portfolio in data is listed under Missing.
+func Totals(groups []Group, data *domain.FundStructureData) []domain.AccountGroup {
+	portfolios := make(map[string]domain.FundAccountPortfolio)
+	for _, list := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, p := range list {
+			portfolios[p.ID] = p
+		}
+	}
+	fundTotal := data.AggregatedTotals.TotalEURMTL
+
+	out := make([]domain.AccountGroup, 0, len(groups))
+	for _, g := range groups {
+		ag := domain.AccountGroup{Name: g.Name, Members: make([]domain.AccountGroupMember, 0, len(g.Members))}
+		for _, acc := range g.Members {
+			p, ok := portfolios[acc.Address]
+			if !ok {
+				ag.Missing = append(ag.Missing, acc.Name)
+			}
+			ag.Members = append(ag.Members, domain.AccountGroupMember{
+				Name:        acc.Name,
+				Address:     acc.Address,
+				Type:        acc.Type,
+				TotalEURMTL: p.TotalEURMTL,
+				TotalXLM:    p.TotalXLM,
+			})
+			ag.TotalEURMTL = ag.TotalEURMTL.Add(p.TotalEURMTL)
+			ag.TotalXLM = ag.TotalXLM.Add(p.TotalXLM)
+			ag.TokenCount += len(p.Tokens)
+		}
+		if !fundTotal.IsZero() {
+			share := decimal.RequireFromString(domain.Percent(ag.TotalEURMTL.String(), fundTotal.String()))
+			ag.ShareOfFund = &share
+		}
+		out = append(out, ag)
+	}
+	return out
+}
+
+// Service fills FundStructureData.Groups for a fixed list of groups.
+type Service struct {
+	groups []Group
+}
+
+// NewService creates a group Service. groups usually comes from
+// ParseGroups.
+func NewService(groups []Group) *Service {
+	return &Service{groups: groups}
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher. It only reads the
+// portfolios already in data, so it never fails.
+func (s *Service) EnrichMetrics(_ context.Context, _ time.Time, data *domain.FundStructureData) error {
+	if len(s.groups) == 0 {
+		return nil
+	}
+	data.Groups = Totals(s.groups, data)
+	return nil
+}
@@ -0,0 +1,116 @@
+package group
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func account(t *testing.T, name string) domain.FundAccount {
+	t.Helper()
+	for _, acc := range domain.AccountRegistry() {
+		if acc.Name == name {
+			return acc
+		}
+	}
+	t.Fatalf("no registry account %s", name)
+	return domain.FundAccount{}
+}
+
+func TestParseGroups(t *testing.T) {
+	mcity := account(t, "MCITY")
+	got, err := ParseGroups([]string{"Real-Estate=mcity+APART", "ops=" + mcity.Address + " + main issuer"}, domain.AccountRegistry())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "real-estate" || got[1].Name != "ops" {
+		t.Fatalf("groups = %+v", got)
+	}
+	if len(got[0].Members) != 2 || got[0].Members[0].Name != "MCITY" || got[0].Members[1].Type != domain.AccountTypeMutual {
+		t.Errorf("real-estate members = %+v", got[0].Members)
+	}
+	if got[1].Members[0].Name != "MCITY" || got[1].Members[1].Name != "MAIN ISSUER" {
+		t.Errorf("ops members = %+v", got[1].Members)
+	}
+}
+
+func TestParseGroupsRejectsBadEntries(t *testing.T) {
+	for entry, want := range map[string]string{
+		"MCITY+APART":            "want NAME=MEMBER",
+		"real estate=MCITY":      "lowercase name",
+		"cluster=MCITY+NOPE":     `unknown fund account "NOPE"`,
+		"cluster=MCITY+mcity":    "MCITY listed twice",
+		"cluster=MCITY+":         `unknown fund account ""`,
+		"cluster=MCITY,cluster=": "",
+	} {
+		entries := strings.Split(entry, ",")
+		_, err := ParseGroups(entries, domain.AccountRegistry())
+		if err == nil {
+			t.Errorf("%q: no error", entry)
+			continue
+		}
+		if want != "" && !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error %q, want it to mention %q", entry, err, want)
+		}
+	}
+}
+
+func portfolio(acc domain.FundAccount, eurmtl, xlm string, tokens int) domain.FundAccountPortfolio {
+	return domain.FundAccountPortfolio{
+		ID:          acc.Address,
+		Name:        acc.Name,
+		Type:        acc.Type,
+		Tokens:      make([]domain.TokenPriceWithBalance, tokens),
+		TotalEURMTL: decimal.RequireFromString(eurmtl),
+		TotalXLM:    decimal.RequireFromString(xlm),
+	}
+}
+
+func TestEnrichMetricsTotalsGroupsAcrossAccountTypes(t *testing.T) {
+	mcity, apart, labr := account(t, "MCITY"), account(t, "APART"), account(t, "LABR")
+	data := &domain.FundStructureData{
+		Accounts:         []domain.FundAccountPortfolio{portfolio(mcity, "3000", "30000", 4)},
+		MutualFunds:      []domain.FundAccountPortfolio{portfolio(apart, "1000", "10000", 2)},
+		AggregatedTotals: domain.AggregatedTotals{TotalEURMTL: decimal.NewFromInt(8000)},
+	}
+	svc := NewService([]Group{
+		{Name: "real-estate", Members: []domain.FundAccount{mcity, apart}},
+		{Name: "affiliates", Members: []domain.FundAccount{labr}},
+	})
+	if err := svc.EnrichMetrics(context.Background(), time.Now(), data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Groups) != 2 {
+		t.Fatalf("groups = %+v", data.Groups)
+	}
+
+	re := data.Groups[0]
+	if re.TotalEURMTL.String() != "4000" || re.TotalXLM.String() != "40000" || re.TokenCount != 6 {
+		t.Errorf("real-estate totals = %s EURMTL, %s XLM, %d tokens; want 4000, 40000, 6", re.TotalEURMTL, re.TotalXLM, re.TokenCount)
+	}
+	if re.ShareOfFund == nil || re.ShareOfFund.String() != "50" {
+		t.Errorf("real-estate share = %v, want 50", re.ShareOfFund)
+	}
+	if len(re.Members) != 2 || re.Members[1].TotalEURMTL.String() != "1000" || len(re.Missing) != 0 {
+		t.Errorf("real-estate members = %+v, missing %v", re.Members, re.Missing)
+	}
+
+	aff := data.Groups[1]
+	if !aff.TotalEURMTL.IsZero() || len(aff.Missing) != 1 || aff.Missing[0] != "LABR" {
+		t.Errorf("affiliates = %+v, want zero with LABR missing", aff)
+	}
+}
+
+func TestTotalsLeavesShareUnsetWithoutFundTotal(t *testing.T) {
+	mcity := account(t, "MCITY")
+	data := &domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{portfolio(mcity, "0", "0", 0)}}
+	got := Totals([]Group{{Name: "city", Members: []domain.FundAccount{mcity}}}, data)
+	if len(got) != 1 || got[0].ShareOfFund != nil {
+		t.Errorf("groups = %+v, want no share of a zero fund", got)
+	}
+}
@@ -0,0 +1,113 @@
+package holders
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// payoutPlaces is the precision of a Stellar amount; dividends are rounded
+// down to it so the payouts never add up to more than the pool.
+const payoutPlaces = 7
+
+// Payout is one holder's part of a distribution.
+type Payout struct {
+	Account  string          `json:"account"`
+	Balance  decimal.Decimal `json:"balance"`
+	SharePct decimal.Decimal `json:"sharePct"` // of the distributed balances, 6 places
+	Dividend decimal.Decimal `json:"dividend"` // zero without a pool
+}
+
+// Distribution splits a dividend pool over the holders of a set in
+// proportion to their balances.
+type Distribution struct {
+	Asset    string          `json:"asset"`
+	Date     time.Time       `json:"date"`
+	Total    decimal.Decimal `json:"total"` // sum of the distributed balances
+	Pool     decimal.Decimal `json:"pool"`
+	Paid     decimal.Decimal `json:"paid"` // sum of the dividends, ≤ Pool
+	Payouts  []Payout        `json:"payouts"`
+	Excluded []string        `json:"excluded"` // holders of the set left out
+}
+
+// Distribute splits pool over the holders of set, leaving out the accounts
+// in exclude (e.g. the fund's own). Payouts are ordered by balance,
+// largest first. The set must have balances.
+func Distribute(set Set, pool decimal.Decimal, exclude []string) (Distribution, error) {
+	d, err := newDistribution(set, exclude)
+	d.Pool = pool
+	if err != nil || d.Total.IsZero() {
+		return d, err
+	}
+	d.pay(func(p Payout) decimal.Decimal { return pool.Mul(p.Balance).Div(d.Total) })
+	return d, nil
+}
+
+// DistributePerShare pays perShare for every unit of balance held, leaving
+// out the accounts in exclude. The pool is what that comes to.
+func DistributePerShare(set Set, perShare decimal.Decimal, exclude []string) (Distribution, error) {
+	d, err := newDistribution(set, exclude)
+	if err != nil || d.Total.IsZero() {
+		return d, err
+	}
+	d.Pool = d.Total.Mul(perShare)
+	d.pay(func(p Payout) decimal.Decimal { return p.Balance.Mul(perShare) })
+	return d, nil
+}
+
+// newDistribution lists the holders of set outside exclude with their share
+// of the total, largest balance first, without dividends.
+func newDistribution(set Set, exclude []string) (Distribution, error) {
+	if set.Balances == nil {
+		return Distribution{}, ErrNoBalances
+	}
+	d := Distribution{Asset: set.Asset, Date: set.Date, Payouts: []Payout{}, Excluded: []string{}}
+	for i, account := range set.Accounts {
+		if slices.Contains(exclude, account) {
+			d.Excluded = append(d.Excluded, account)
+			continue
+		}
+		d.Payouts = append(d.Payouts, Payout{Account: account, Balance: set.Balances[i]})
+		d.Total = d.Total.Add(set.Balances[i])
+	}
+	if d.Total.IsZero() {
+		return d, nil
+	}
+
+	hundred := decimal.NewFromInt(100)
+	for i := range d.Payouts {
+		d.Payouts[i].SharePct = d.Payouts[i].Balance.Mul(hundred).DivRound(d.Total, 6)
+	}
+	slices.SortStableFunc(d.Payouts, func(a, b Payout) int {
+		return b.Balance.Cmp(a.Balance)
+	})
+	return d, nil
+}
+
+// pay sets each payout's dividend, rounded down to a Stellar amount.
+func (d *Distribution) pay(dividend func(Payout) decimal.Decimal) {
+	for i := range d.Payouts {
+		p := &d.Payouts[i]
+		p.Dividend = dividend(*p).Truncate(payoutPlaces)
+		d.Paid = d.Paid.Add(p.Dividend)
+	}
+}
+
+// WriteCSV writes one row per payout: account,balance,share_pct,dividend.
+func (d Distribution) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"account", "balance", "share_pct", "dividend"}); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+	for _, p := range d.Payouts {
+		if err := cw.Write([]string{p.Account, p.Balance.String(), p.SharePct.String(), p.Dividend.StringFixed(payoutPlaces)}); err != nil {
+			return fmt.Errorf("writing CSV row: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,57 @@
+package holders
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDistribute(t *testing.T) {
+	set := Set{
+		Date:     day1,
+		Asset:    AssetMTL,
+		Accounts: []string{"GA", "GB", "GC", "GFUND"},
+		Balances: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3), decimal.NewFromInt(1000)},
+	}
+	d, err := Distribute(set, decimal.NewFromInt(100), []string{"GFUND"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Payouts) != 3 || d.Payouts[0].Account != "GC" || d.Payouts[2].Account != "GA" {
+		t.Fatalf("payouts = %+v, want largest balance first without GFUND", d.Payouts)
+	}
+	if d.Total.String() != "6" || len(d.Excluded) != 1 || d.Excluded[0] != "GFUND" {
+		t.Errorf("total = %s, excluded = %v", d.Total, d.Excluded)
+	}
+	// 100 × 1/6 = 16.666…: rounded down, so the payouts stay within the pool.
+	if got := d.Payouts[2]; got.Dividend.String() != "16.6666666" || got.SharePct.String() != "16.666667" {
+		t.Errorf("GA = %+v", got)
+	}
+	if d.Paid.GreaterThan(d.Pool) || d.Paid.String() != "99.9999999" {
+		t.Errorf("paid = %s of %s", d.Paid, d.Pool)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[0] != "account,balance,share_pct,dividend" || lines[1] != "GC,3,50,50.0000000" {
+		t.Errorf("CSV = %q", lines)
+	}
+
+	d, err = DistributePerShare(set, decimal.RequireFromString("0.125"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Pool.String() != "125.75" || d.Paid.String() != "125.75" || d.Payouts[0].Account != "GFUND" || d.Payouts[0].Dividend.String() != "125" {
+		t.Errorf("per share: pool = %s, paid = %s, payouts = %+v", d.Pool, d.Paid, d.Payouts)
+	}
+
+	if _, err := Distribute(Set{Accounts: []string{"GA"}}, decimal.NewFromInt(1), nil); !errors.Is(err, ErrNoBalances) {
+		t.Errorf("set without balances: err = %v, want ErrNoBalances", err)
+	}
+}
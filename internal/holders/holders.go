@@ -0,0 +1,219 @@
+// Package holders persists the set of accounts holding MTL and MTLAP on each
+// snapshot date and derives churn from two sets: who became a holder, who
+// exited, and the net change. The holder counts (I40, I62) only show the
+// net; churn shows the turnover behind it.
+package holders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Tracked assets. AssetMTL is the MTL ∪ MTLRECT shareholder set.
+const (
+	AssetMTL   = "MTL"
+	AssetMTLAP = "MTLAP"
+)
+
+// Assets lists the tracked assets in output order.
+var Assets = []string{AssetMTL, AssetMTLAP}
+
+// DefaultPeriodDays is the churn window of the I67–I72 indicators.
+const DefaultPeriodDays = 30
+
+// ErrNotFound is returned when no holder set is stored at-or-before a date.
+var ErrNotFound = errors.New("holder set not found")
+
+// ErrNoBalances is returned for a set recorded before balances were kept.
+var ErrNoBalances = errors.New("holder set has no balances")
+
+// Set is the sorted list of accounts holding an asset on a snapshot date.
+// Balances[i] is what Accounts[i] held (MTL + MTLRECT for AssetMTL); it is
+// nil for sets recorded before balances were kept.
+type Set struct {
+	Date     time.Time
+	Asset    string
+	Accounts []string
+	Balances []decimal.Decimal
+}
+
+// Churn compares the holder sets of one asset on two snapshot dates.
+type Churn struct {
+	Asset       string    `json:"asset"`
+	From        time.Time `json:"from"` // date of the earlier set
+	To          time.Time `json:"to"`
+	PrevHolders int       `json:"prevHolders"`
+	Holders     int       `json:"holders"`
+	New         []string  `json:"new"`    // holders on To that weren't on From
+	Exited      []string  `json:"exited"` // holders on From that aren't on To
+	Net         int       `json:"net"`    // Holders − PrevHolders
+}
+
+// Compare returns the churn from prev to cur. Both account lists must be sorted.
+func Compare(prev, cur Set) Churn {
+	c := Churn{
+		Asset:       cur.Asset,
+		From:        prev.Date,
+		To:          cur.Date,
+		PrevHolders: len(prev.Accounts),
+		Holders:     len(cur.Accounts),
+		New:         []string{},
+		Exited:      []string{},
+		Net:         len(cur.Accounts) - len(prev.Accounts),
+	}
+	i, j := 0, 0
+	for i < len(prev.Accounts) || j < len(cur.Accounts) {
+		switch {
+		case j == len(cur.Accounts) || (i < len(prev.Accounts) && prev.Accounts[i] < cur.Accounts[j]):
+			c.Exited = append(c.Exited, prev.Accounts[i])
+			i++
+		case i == len(prev.Accounts) || cur.Accounts[j] < prev.Accounts[i]:
+			c.New = append(c.New, cur.Accounts[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return c
+}
+
+// HolderSource walks the accounts holding an asset (horizon.Client).
+type HolderSource interface {
+	FetchAssetHolderBalancesByBalance(ctx context.Context, asset domain.AssetInfo, minBalance decimal.Decimal) (map[string]decimal.Decimal, error)
+}
+
+// Store persists holder sets (PgRepository).
+type Store interface {
+	Save(ctx context.Context, slug string, date time.Time, sets []Set) error
+	GetNearestBefore(ctx context.Context, slug, asset string, date time.Time) (*Set, error)
+}
+
+// Service records the holder sets of each snapshot and compares them.
+type Service struct {
+	source HolderSource
+	store  Store
+	slug   string
+}
+
+// NewService creates a Service for the entity slug. A read-only service
+// (Churn only) may pass a nil source.
+func NewService(source HolderSource, store Store, slug string) *Service {
+	return &Service{source: source, store: store, slug: slug}
+}
+
+// Record walks the current MTL, MTLRECT and MTLAP holders and replaces the
+// stored sets of date. The thresholds match the counts: any positive
+// MTL + MTLRECT balance (I62) and MTLAP ≥ 1 (I40).
+func (s *Service) Record(ctx context.Context, date time.Time) ([]Set, error) {
+	var sets []Set
+	for _, asset := range Assets {
+		set, err := s.Scan(ctx, asset, date)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	if err := s.store.Save(ctx, s.slug, date, sets); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// Scan walks the current holders of asset without storing them; the set is
+// dated date.
+func (s *Service) Scan(ctx context.Context, asset string, date time.Time) (Set, error) {
+	var balances []map[string]decimal.Decimal
+	switch asset {
+	case AssetMTL:
+		minNonZero := decimal.New(1, -7)
+		for _, code := range []string{"MTL", "MTLRECT"} {
+			b, err := s.source.FetchAssetHolderBalancesByBalance(ctx, domain.NewAssetInfo(code, domain.IssuerAddress), minNonZero)
+			if err != nil {
+				return Set{}, fmt.Errorf("fetching %s holders: %w", code, err)
+			}
+			balances = append(balances, b)
+		}
+	case AssetMTLAP:
+		b, err := s.source.FetchAssetHolderBalancesByBalance(ctx, domain.MTLAPAsset(), decimal.NewFromInt(1))
+		if err != nil {
+			return Set{}, fmt.Errorf("fetching MTLAP holders: %w", err)
+		}
+		balances = append(balances, b)
+	default:
+		return Set{}, fmt.Errorf("unknown holder asset %q", asset)
+	}
+	set := Set{Date: date, Asset: asset}
+	set.Accounts, set.Balances = merge(balances...)
+	return set, nil
+}
+
+// Stored returns the recorded set of asset on exactly date. A set recorded
+// before balances were kept is returned with ErrNoBalances.
+func (s *Service) Stored(ctx context.Context, asset string, date time.Time) (*Set, error) {
+	set, err := s.store.GetNearestBefore(ctx, s.slug, asset, date)
+	if err != nil {
+		return nil, err
+	}
+	if !set.Date.Equal(date) {
+		return nil, fmt.Errorf("%w: latest %s set before %s is from %s", ErrNotFound, asset,
+			date.Format("2006-01-02"), set.Date.Format("2006-01-02"))
+	}
+	if set.Balances == nil {
+		return set, ErrNoBalances
+	}
+	return set, nil
+}
+
+// Churn compares, per asset, the latest set at-or-before date with the
+// latest set at-or-before `days` days earlier. Assets without both sets are
+// left out.
+func (s *Service) Churn(ctx context.Context, date time.Time, days int) ([]Churn, error) {
+	var out []Churn
+	for _, asset := range Assets {
+		cur, err := s.store.GetNearestBefore(ctx, s.slug, asset, date)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		prev, err := s.store.GetNearestBefore(ctx, s.slug, asset, cur.Date.AddDate(0, 0, -days))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Compare(*prev, *cur))
+	}
+	return out, nil
+}
+
+// merge returns the sorted union of the balance maps' keys and, in the same
+// order, each account's balances summed.
+func merge(balances ...map[string]decimal.Decimal) ([]string, []decimal.Decimal) {
+	sum := make(map[string]decimal.Decimal)
+	for _, m := range balances {
+		for id, b := range m {
+			sum[id] = sum[id].Add(b)
+		}
+	}
+	accounts := make([]string, 0, len(sum))
+	for id := range sum {
+		accounts = append(accounts, id)
+	}
+	slices.Sort(accounts)
+	out := make([]decimal.Decimal, len(accounts))
+	for i, id := range accounts {
+		out[i] = sum[id]
+	}
+	return accounts, out
+}
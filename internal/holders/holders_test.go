@@ -0,0 +1,109 @@
+package holders
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+var (
+	day1  = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	day31 = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestCompare(t *testing.T) {
+	prev := Set{Date: day1, Asset: AssetMTL, Accounts: []string{"GA", "GB", "GC"}}
+	cur := Set{Date: day31, Asset: AssetMTL, Accounts: []string{"GB", "GD", "GE"}}
+
+	c := Compare(prev, cur)
+	if !slices.Equal(c.New, []string{"GD", "GE"}) || !slices.Equal(c.Exited, []string{"GA", "GC"}) {
+		t.Errorf("new = %v, exited = %v", c.New, c.Exited)
+	}
+	if c.Net != 0 || c.Holders != 3 || c.PrevHolders != 3 || !c.From.Equal(day1) || !c.To.Equal(day31) {
+		t.Errorf("churn = %+v", c)
+	}
+
+	c = Compare(Set{}, cur)
+	if len(c.New) != 3 || len(c.Exited) != 0 || c.Net != 3 {
+		t.Errorf("from empty: %+v", c)
+	}
+}
+
+type stubSource map[string]map[string]decimal.Decimal
+
+func (s stubSource) FetchAssetHolderBalancesByBalance(_ context.Context, asset domain.AssetInfo, _ decimal.Decimal) (map[string]decimal.Decimal, error) {
+	return s[asset.Code], nil
+}
+
+type memStore map[string][]Set // asset → sets, oldest first
+
+func (m memStore) Save(_ context.Context, _ string, _ time.Time, sets []Set) error {
+	for _, s := range sets {
+		m[s.Asset] = append(m[s.Asset], s)
+	}
+	return nil
+}
+
+func (m memStore) GetNearestBefore(_ context.Context, _, asset string, date time.Time) (*Set, error) {
+	sets := m[asset]
+	for i := len(sets) - 1; i >= 0; i-- {
+		if !sets[i].Date.After(date) {
+			return &sets[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func TestServiceRecordAndChurn(t *testing.T) {
+	one := decimal.NewFromInt(1)
+	store := memStore{}
+	svc := NewService(stubSource{
+		"MTL":     {"GA": one, "GB": one},
+		"MTLRECT": {"GB": one, "GC": one},
+		"MTLAP":   {"GX": one},
+	}, store, "mtlf")
+	if _, err := svc.Record(context.Background(), day1); err != nil {
+		t.Fatal(err)
+	}
+	if got := store[AssetMTL][0].Accounts; !slices.Equal(got, []string{"GA", "GB", "GC"}) {
+		t.Errorf("MTL set = %v, want the MTL ∪ MTLRECT holders", got)
+	}
+	if got := store[AssetMTL][0].Balances; len(got) != 3 || got[1].String() != "2" {
+		t.Errorf("MTL balances = %v, want GB's MTL and MTLRECT summed", got)
+	}
+	if set, err := svc.Stored(context.Background(), AssetMTL, day1); err != nil || len(set.Accounts) != 3 {
+		t.Errorf("Stored(day1) = %+v, %v", set, err)
+	}
+	if _, err := svc.Stored(context.Background(), AssetMTL, day1.AddDate(0, 0, 1)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stored(day after) error = %v, want ErrNotFound", err)
+	}
+
+	churn, err := svc.Churn(context.Background(), day1, DefaultPeriodDays)
+	if err != nil || len(churn) != 0 {
+		t.Fatalf("churn without a baseline = %+v, %v; want none", churn, err)
+	}
+
+	svc.source = stubSource{"MTL": {"GA": one, "GD": one}, "MTLAP": {"GX": one, "GY": one}}
+	if _, err := svc.Record(context.Background(), day31); err != nil {
+		t.Fatal(err)
+	}
+	churn, err = svc.Churn(context.Background(), day31, DefaultPeriodDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(churn) != 2 {
+		t.Fatalf("got %d churn rows, want 2", len(churn))
+	}
+	if c := churn[0]; c.Asset != AssetMTL || !slices.Equal(c.New, []string{"GD"}) || !slices.Equal(c.Exited, []string{"GB", "GC"}) || c.Net != -1 {
+		t.Errorf("MTL churn = %+v", c)
+	}
+	if c := churn[1]; c.Asset != AssetMTLAP || !slices.Equal(c.New, []string{"GY"}) || len(c.Exited) != 0 || c.Net != 1 {
+		t.Errorf("MTLAP churn = %+v", c)
+	}
+}
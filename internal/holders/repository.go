@@ -0,0 +1,92 @@
+package holders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// PgRepository stores holder sets in holder_sets.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL holder set repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Save replaces the sets of date with sets.
+func (r *PgRepository) Save(ctx context.Context, slug string, date time.Time, sets []Set) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning holder set tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM holder_sets WHERE entity_id = $1 AND snapshot_date = $2`, entityID, date); err != nil {
+		return fmt.Errorf("clearing holder sets for %s: %w", date.Format("2006-01-02"), err)
+	}
+	for _, s := range sets {
+		var balances []string
+		if s.Balances != nil {
+			balances = make([]string, len(s.Balances))
+			for i, b := range s.Balances {
+				balances[i] = b.String()
+			}
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO holder_sets (entity_id, snapshot_date, asset, accounts, balances)
+			 VALUES ($1, $2, $3, $4, $5::text[]::numeric[])`,
+			entityID, date, s.Asset, s.Accounts, balances); err != nil {
+			return fmt.Errorf("saving %s holder set for %s: %w", s.Asset, date.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing holder sets: %w", err)
+	}
+	return nil
+}
+
+// GetNearestBefore returns the latest set of asset at-or-before date, or
+// ErrNotFound.
+func (r *PgRepository) GetNearestBefore(ctx context.Context, slug, asset string, date time.Time) (*Set, error) {
+	s := Set{Asset: asset}
+	var balances []string
+	err := r.pool.QueryRow(ctx,
+		`SELECT hs.snapshot_date, hs.accounts, hs.balances::text[]
+		 FROM holder_sets hs
+		 JOIN fund_entities fe ON fe.id = hs.entity_id
+		 WHERE fe.slug = $1 AND hs.asset = $2 AND hs.snapshot_date <= $3
+		 ORDER BY hs.snapshot_date DESC
+		 LIMIT 1`, slug, asset, date).Scan(&s.Date, &s.Accounts, &balances)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading %s holder set at %s: %w", asset, date.Format("2006-01-02"), err)
+	}
+	if balances != nil {
+		if len(balances) != len(s.Accounts) {
+			return nil, fmt.Errorf("%s holder set at %s: %d balances for %d accounts",
+				asset, s.Date.Format("2006-01-02"), len(balances), len(s.Accounts))
+		}
+		s.Balances = make([]decimal.Decimal, len(balances))
+		for i, b := range balances {
+			if s.Balances[i], err = decimal.NewFromString(b); err != nil {
+				return nil, fmt.Errorf("%s holder set at %s: balance of %s: %w", asset, s.Date.Format("2006-01-02"), s.Accounts[i], err)
+			}
+		}
+	}
+	return &s, nil
+}
@@ -153,3 +153,34 @@ func TestFetchAccountDataEntryBadBase64(t *testing.T) {
 		t.Fatal("expected base64 decode error, got nil")
 	}
 }
+
+func TestFetchAccountParsesConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "GABC123",
+			"balances": [],
+			"flags": {"auth_required": true, "auth_revocable": false, "auth_immutable": false, "auth_clawback_enabled": true},
+			"home_domain": "montelibero.org",
+			"inflation_destination": "GINFL",
+			"num_sponsoring": 2,
+			"num_sponsored": 1
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+	account, err := client.FetchAccount(context.Background(), "GABC123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !account.Flags.AuthRequired || account.Flags.AuthRevocable || !account.Flags.AuthClawbackEnabled {
+		t.Errorf("flags = %+v", account.Flags)
+	}
+	if account.HomeDomain != "montelibero.org" || account.InflationDestination != "GINFL" {
+		t.Errorf("home domain = %q, inflation destination = %q", account.HomeDomain, account.InflationDestination)
+	}
+	if account.NumSponsoring != 2 || account.NumSponsored != 1 {
+		t.Errorf("sponsoring = %d, sponsored = %d, want 2 and 1", account.NumSponsoring, account.NumSponsored)
+	}
+}
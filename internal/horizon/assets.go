@@ -237,3 +237,41 @@ func (c *Client) FetchAssetStats(ctx context.Context, asset domain.AssetInfo) (A
 		Contracts:         contracts,
 	}, nil
 }
+
+// TotalSupply returns the asset's total supply as FetchAssetStats counts it:
+// balances at every authorization level plus claimable balances, liquidity
+// pools and contracts.
+func (a HorizonAsset) TotalSupply() (decimal.Decimal, error) {
+	total := decimal.Zero
+	for _, s := range []string{a.Balances.Authorized, a.Balances.AuthorizedToMaintainLiabilities, a.Balances.Unauthorized,
+		a.ClaimableBalancesAmount, a.LiquidityPoolsAmount, a.ContractsAmount} {
+		if s == "" {
+			continue
+		}
+		v, err := decimal.NewFromString(s)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("parsing supply of %s: %w", a.AssetCode, err)
+		}
+		total = total.Add(v)
+	}
+	return total, nil
+}
+
+// issuedAssetsLimit is the page size for FetchIssuedAssets. One page is
+// enough: treasury issuers publish a handful of assets, not hundreds.
+const issuedAssetsLimit = 200
+
+// FetchIssuedAssets returns the assets issued by the given account, with
+// their per-authorization holder counts. An account that issues nothing
+// yields an empty slice.
+func (c *Client) FetchIssuedAssets(ctx context.Context, issuer string) ([]HorizonAsset, error) {
+	params := url.Values{}
+	params.Set("asset_issuer", issuer)
+	params.Set("limit", fmt.Sprint(issuedAssetsLimit))
+
+	var resp HorizonAssetsResponse
+	if err := c.getJSON(ctx, "/assets?"+params.Encode(), &resp); err != nil {
+		return nil, fmt.Errorf("fetching assets issued by %s: %w", issuer, err)
+	}
+	return resp.Embedded.Records, nil
+}
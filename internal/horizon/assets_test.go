@@ -472,3 +472,53 @@ func TestFetchAssetStatsEmpty(t *testing.T) {
 		t.Errorf("expected zero stats for missing asset, got %+v", stats)
 	}
 }
+
+func TestFetchIssuedAssets(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"_embedded": {"records": [
+			{"asset_code": "AAA", "asset_issuer": "GPEER", "accounts": {"authorized": 12}},
+			{"asset_code": "BBB", "asset_issuer": "GPEER", "accounts": {"authorized": 40}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+	assets, err := client.FetchIssuedAssets(context.Background(), "GPEER")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotQuery != "asset_issuer=GPEER&limit=200" {
+		t.Errorf("query = %q", gotQuery)
+	}
+	if len(assets) != 2 || assets[1].Accounts.Authorized != 40 {
+		t.Errorf("assets = %+v", assets)
+	}
+}
+
+func TestHorizonAssetTotalSupply(t *testing.T) {
+	a := HorizonAsset{
+		AssetCode: "AAA",
+		Balances: HorizonAssetBalances{
+			Authorized:                      "100.5",
+			AuthorizedToMaintainLiabilities: "2",
+			Unauthorized:                    "0.5",
+		},
+		ClaimableBalancesAmount: "10",
+		LiquidityPoolsAmount:    "7",
+	}
+	got, err := a.TotalSupply()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Equal(decimal.NewFromInt(120)) {
+		t.Errorf("TotalSupply = %s, want 120", got)
+	}
+
+	a.ContractsAmount = "x"
+	if _, err := a.TotalSupply(); err == nil {
+		t.Error("expected an error for an unparsable amount")
+	}
+}
@@ -5,35 +5,137 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/mtlprog/stat/internal/fault"
 )
 
-// Client is an HTTP client for the Stellar Horizon API with retry on 429.
+// Client is an HTTP client for the Stellar Horizon API with retry on
+// rate limits and transient failures.
+//
+// A Client can hold several Horizon endpoints: the primary plus fallbacks
+// (WithFallbackURLs). Requests stick to the active endpoint. When it fails
+// with a network error or a transient status, it is marked down for the
+// cooldown and the client fails over to the next usable endpoint, in list
+// order, without backing off. It stays there until that endpoint fails too;
+// there is no automatic return to the primary, so one run doesn't mix data
+// from several Horizons more than it has to.
 type Client struct {
-	baseURL    string
 	httpClient *http.Client
 	maxRetries int
 	baseDelay  time.Duration
+	cooldown   time.Duration
+	maxLag     time.Duration
+	inFlight   atomic.Int64
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	active    int
+}
+
+// endpoint is one Horizon base URL and its counters. Guarded by Client.mu.
+type endpoint struct {
+	url       string
+	requests  int
+	failures  int
+	failovers int // times the client switched away from this endpoint
+	downUntil time.Time
+	lastError string
+}
+
+// MaxLedgerLag is the default for how far behind the network an endpoint's
+// latest ingested ledger may be before CheckHealth treats it as stale.
+const MaxLedgerLag = 2 * time.Minute
+
+// EndpointStats is a point-in-time view of one endpoint's counters.
+type EndpointStats struct {
+	URL       string `json:"url"`
+	Active    bool   `json:"active"`
+	Healthy   bool   `json:"healthy"`
+	Requests  int    `json:"requests"`
+	Failures  int    `json:"failures"`
+	Failovers int    `json:"failovers"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Option configures NewClient.
+type Option func(*Client)
+
+// WithFallbackURLs adds endpoints to fail over to, tried in order after the
+// primary.
+func WithFallbackURLs(urls ...string) Option {
+	return func(c *Client) {
+		for _, u := range urls {
+			c.endpoints = append(c.endpoints, &endpoint{url: strings.TrimRight(u, "/")})
+		}
+	}
+}
+
+// WithFailoverCooldown sets how long a failed endpoint is skipped before it
+// becomes eligible again (default 5 minutes).
+func WithFailoverCooldown(d time.Duration) Option {
+	return func(c *Client) { c.cooldown = d }
+}
+
+// WithMaxLedgerLag sets how far behind an endpoint may be before CheckHealth
+// fails over from it (default MaxLedgerLag).
+func WithMaxLedgerLag(d time.Duration) Option {
+	return func(c *Client) { c.maxLag = d }
+}
+
+// WithTransport sends requests through rt, normally the shared
+// transport.Transport, instead of a connection pool of the client's own.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.httpClient.Transport = rt }
 }
 
 // NewClient creates a new Horizon API client.
-func NewClient(baseURL string, maxRetries int, baseDelay time.Duration) *Client {
-	return &Client{
-		baseURL:    baseURL,
+func NewClient(baseURL string, maxRetries int, baseDelay time.Duration, opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		maxRetries: maxRetries,
 		baseDelay:  baseDelay,
+		cooldown:   5 * time.Minute,
+		maxLag:     MaxLedgerLag,
+		endpoints:  []*endpoint{{url: baseURL}},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// get performs a GET request, retrying on transient failures (429 + 5xx) with
-// exponential backoff. Non-transient errors fail fast.
+// get performs a GET request, retrying rate limits, 5xx and network
+// failures (fault.Retryable) with exponential backoff. Other failures fail
+// fast. With fallback endpoints, a retryable failure moves the request to
+// the next endpoint instead of backing off.
 func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
-	url := c.baseURL + path
+	c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+
+	attempt := 0
+	switched := false // the last attempt failed over: retry at once
+	policy := fault.Policy{
+		Service: "horizon",
+		Retries: c.maxRetries,
+		Backoff: func(n int) time.Duration {
+			if switched {
+				return 0
+			}
+			return c.baseDelay * time.Duration(1<<uint(n-1))
+		},
+	}
+	return fault.Do(ctx, policy, func(ctx context.Context) ([]byte, error) {
+		defer func() { attempt++ }()
+		switched = false
+		ep := c.pick()
+		url := ep.url + path
 
-	var lastErr error
-	for attempt := range c.maxRetries + 1 {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("creating request: %w", err)
@@ -41,7 +143,10 @@ func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("executing request: %w", err)
+			if ctx.Err() == nil {
+				switched = c.failover(ep, err.Error())
+			}
+			return nil, fault.New("horizon", fault.KindOf(err), fmt.Errorf("executing request: %w", err))
 		}
 
 		const maxResponseSize = 10 << 20 // 10 MB
@@ -52,42 +157,188 @@ func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
 		}
 
 		if resp.StatusCode == http.StatusOK {
+			c.succeeded(ep)
 			return body, nil
 		}
 
-		if isTransient(resp.StatusCode) {
-			lastErr = fmt.Errorf("HTTP %d at %s (attempt %d/%d)", resp.StatusCode, url, attempt+1, c.maxRetries+1)
-			if attempt < c.maxRetries {
-				delay := c.baseDelay * time.Duration(1<<uint(attempt))
-				select {
-				case <-ctx.Done():
-					return nil, ctx.Err()
-				case <-time.After(delay):
-				}
-				continue
-			}
-			return nil, lastErr
+		if kind := fault.KindOfStatus(resp.StatusCode); kind == fault.RateLimited || kind == fault.Transient {
+			// Horizon's Retry-After would hold up a run that can fail over.
+			switched = c.failover(ep, fmt.Sprintf("HTTP %d", resp.StatusCode))
+			return nil, fault.New("horizon", kind,
+				fmt.Errorf("HTTP %d at %s (attempt %d/%d)", resp.StatusCode, url, attempt+1, c.maxRetries+1))
 		}
 
-		return nil, fmt.Errorf("HTTP %d from %s: %s", resp.StatusCode, url, string(body))
+		c.succeeded(ep) // the endpoint answered; the request itself is at fault
+		return nil, fault.FromResponse("horizon", resp.StatusCode, nil,
+			fmt.Errorf("HTTP %d from %s: %s", resp.StatusCode, url, string(body)))
+	})
+}
+
+// pick returns the active endpoint, moving off it first if it is down and a
+// usable one exists.
+func (c *Client) pick() *endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.endpoints[c.active].downUntil.After(time.Now()) {
+		c.switchLocked()
 	}
+	ep := c.endpoints[c.active]
+	ep.requests++
+	return ep
+}
 
-	return nil, lastErr
+// failover records a failure on ep and switches to another usable endpoint.
+// It reports whether the client now points elsewhere; with a single endpoint
+// it only records the failure.
+func (c *Client) failover(ep *endpoint, reason string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failedLocked(ep, reason)
+	if len(c.endpoints) == 1 {
+		return false
+	}
+	ep.downUntil = time.Now().Add(c.cooldown)
+	if c.endpoints[c.active] != ep {
+		return true // another request already moved on
+	}
+	return c.switchLocked()
 }
 
-// isTransient reports whether status indicates a temporary failure that's
-// worth retrying — 429 (rate-limit) and the standard 5xx gateway-style errors.
-func isTransient(status int) bool {
-	switch status {
-	case http.StatusTooManyRequests,
-		http.StatusBadGateway,
-		http.StatusServiceUnavailable,
-		http.StatusGatewayTimeout:
-		return true
+// switchLocked makes the next endpoint (in list order, wrapping) that is not
+// down the active one. Returns false when every other endpoint is down.
+func (c *Client) switchLocked() bool {
+	now := time.Now()
+	for i := 1; i < len(c.endpoints); i++ {
+		next := (c.active + i) % len(c.endpoints)
+		if !c.endpoints[next].downUntil.After(now) {
+			from := c.endpoints[c.active]
+			from.failovers++
+			slog.Info("horizon failover", "from", from.url, "to", c.endpoints[next].url, "reason", from.lastError)
+			c.active = next
+			return true
+		}
 	}
 	return false
 }
 
+func (c *Client) failedLocked(ep *endpoint, reason string) {
+	ep.failures++
+	ep.lastError = reason
+}
+
+func (c *Client) succeeded(ep *endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ep.downUntil = time.Time{}
+}
+
+// Stats returns the per-endpoint counters, primary first.
+func (c *Client) Stats() []EndpointStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	out := make([]EndpointStats, len(c.endpoints))
+	for i, ep := range c.endpoints {
+		out[i] = EndpointStats{
+			URL:       ep.url,
+			Active:    i == c.active,
+			Healthy:   !ep.downUntil.After(now),
+			Requests:  ep.requests,
+			Failures:  ep.failures,
+			Failovers: ep.failovers,
+			LastError: ep.lastError,
+		}
+	}
+	return out
+}
+
+// InFlight returns the number of requests currently in progress, including
+// ones waiting out a retry backoff.
+func (c *Client) InFlight() int {
+	return int(c.inFlight.Load())
+}
+
+// CheckHealth probes every endpoint's root resource. An endpoint is healthy
+// when it answers 200 and its latest ingested ledger closed within the last
+// two minutes; a lagging Horizon would serve stale balances. Unhealthy
+// endpoints are marked down for the cooldown, healthy ones are cleared, and
+// the client moves off the active endpoint if it is down. With a single
+// endpoint this is a no-op, since there is nothing to fail over to.
+func (c *Client) CheckHealth(ctx context.Context) []EndpointStats {
+	c.mu.Lock()
+	n := len(c.endpoints)
+	urls := make([]string, n)
+	for i, ep := range c.endpoints {
+		urls[i] = ep.url
+	}
+	c.mu.Unlock()
+	if n == 1 {
+		return c.Stats()
+	}
+
+	problems := make([]string, n)
+	for i, u := range urls {
+		problems[i] = c.probe(ctx, u)
+	}
+
+	c.mu.Lock()
+	until := time.Now().Add(c.cooldown)
+	for i, ep := range c.endpoints {
+		if problems[i] == "" {
+			ep.downUntil = time.Time{}
+			continue
+		}
+		slog.Info("horizon endpoint unhealthy", "url", ep.url, "reason", problems[i])
+		ep.lastError = problems[i]
+		ep.downUntil = until
+	}
+	if c.endpoints[c.active].downUntil.After(time.Now()) {
+		c.switchLocked()
+	}
+	c.mu.Unlock()
+	return c.Stats()
+}
+
+// Root is the part of Horizon's root resource that tells how current the
+// endpoint is.
+type Root struct {
+	LatestLedger         int64     `json:"history_latest_ledger"`
+	LatestLedgerClosedAt time.Time `json:"history_latest_ledger_closed_at"`
+}
+
+// FetchRoot returns the active endpoint's root resource.
+func (c *Client) FetchRoot(ctx context.Context) (Root, error) {
+	var root Root
+	if err := c.getJSON(ctx, "/", &root); err != nil {
+		return Root{}, fmt.Errorf("fetching root resource: %w", err)
+	}
+	return root, nil
+}
+
+// probe returns why the endpoint at baseURL is unhealthy, or "" if it isn't.
+func (c *Client) probe(ctx context.Context, baseURL string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/", nil)
+	if err != nil {
+		return err.Error()
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	var root Root
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&root); err != nil {
+		return fmt.Sprintf("parsing root resource: %v", err)
+	}
+	if lag := time.Since(root.LatestLedgerClosedAt); lag > c.maxLag {
+		return fmt.Sprintf("latest ledger closed %s ago", lag.Round(time.Second))
+	}
+	return ""
+}
+
 // getJSON performs a GET request and unmarshals the JSON response.
 func (c *Client) getJSON(ctx context.Context, path string, dest any) error {
 	body, err := c.get(ctx, path)
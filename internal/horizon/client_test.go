@@ -2,8 +2,10 @@ package horizon
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -169,3 +171,146 @@ func TestClientContextCancellation(t *testing.T) {
 		t.Fatal("expected error on cancelled context, got nil")
 	}
 }
+
+func TestClientFailoverIsSticky(t *testing.T) {
+	var primaryHits, fallbackHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackHits.Add(1)
+		w.Write([]byte(`{"ok":1}`))
+	}))
+	defer fallback.Close()
+
+	client := NewClient(primary.URL, 3, time.Second, WithFallbackURLs(fallback.URL))
+	start := time.Now()
+	for range 3 {
+		if _, err := client.get(context.Background(), "/test"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("failover took %s, want no backoff", elapsed)
+	}
+	if primaryHits.Load() != 1 || fallbackHits.Load() != 3 {
+		t.Errorf("hits primary=%d fallback=%d, want 1 and 3 (stick to fallback)", primaryHits.Load(), fallbackHits.Load())
+	}
+
+	stats := client.Stats()
+	if stats[0].Active || stats[0].Healthy || stats[0].Failures != 1 || stats[0].Failovers != 1 || stats[0].LastError != "HTTP 503" {
+		t.Errorf("primary stats = %+v", stats[0])
+	}
+	if !stats[1].Active || stats[1].Requests != 3 {
+		t.Errorf("fallback stats = %+v", stats[1])
+	}
+}
+
+func TestClientFailoverOnNetworkError(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":1}`))
+	}))
+	defer fallback.Close()
+
+	client := NewClient(deadURL, 1, time.Millisecond, WithFallbackURLs(fallback.URL))
+	if _, err := client.get(context.Background(), "/test"); err != nil {
+		t.Fatalf("expected failover to succeed, got %v", err)
+	}
+}
+
+func TestClientAllEndpointsDown(t *testing.T) {
+	var hits atomic.Int32
+	down := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	a, b := httptest.NewServer(down), httptest.NewServer(down)
+	defer a.Close()
+	defer b.Close()
+
+	client := NewClient(a.URL, 3, time.Millisecond, WithFallbackURLs(b.URL))
+	if _, err := client.get(context.Background(), "/test"); err == nil {
+		t.Fatal("expected error when every endpoint fails")
+	}
+	if got := hits.Load(); got != 4 {
+		t.Errorf("attempts = %d, want 4 (retry budget is shared across endpoints)", got)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	root := func(closedAt time.Time) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"history_latest_ledger_closed_at": %q}`, closedAt.UTC().Format(time.RFC3339))
+		}
+	}
+	lagging := httptest.NewServer(root(time.Now().Add(-time.Hour)))
+	defer lagging.Close()
+	current := httptest.NewServer(root(time.Now()))
+	defer current.Close()
+
+	client := NewClient(lagging.URL, 1, time.Millisecond, WithFallbackURLs(current.URL))
+	stats := client.CheckHealth(context.Background())
+	if stats[0].Healthy || !strings.Contains(stats[0].LastError, "latest ledger closed") {
+		t.Errorf("lagging primary = %+v, want unhealthy", stats[0])
+	}
+	if !stats[1].Active || !stats[1].Healthy {
+		t.Errorf("fallback = %+v, want active and healthy", stats[1])
+	}
+}
+
+func TestClientInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 0, time.Millisecond)
+	done := make(chan error)
+	for range 2 {
+		go func() {
+			_, err := client.get(context.Background(), "/slow")
+			done <- err
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.InFlight() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("InFlight = %d, want 2", client.InFlight())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := client.InFlight(); got != 0 {
+		t.Errorf("InFlight after completion = %d, want 0", got)
+	}
+}
+
+func TestFetchRoot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			t.Errorf("path = %q, want /", r.URL.Path)
+		}
+		w.Write([]byte(`{"history_latest_ledger":52000000,"history_latest_ledger_closed_at":"2026-10-01T12:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	root, err := NewClient(server.URL, 0, 0).FetchRoot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.LatestLedger != 52000000 || !root.LatestLedgerClosedAt.Equal(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("root = %+v", root)
+	}
+}
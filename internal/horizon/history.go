@@ -0,0 +1,266 @@
+package horizon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrBeforeHistory is returned when a time predates the oldest ledger the
+// Horizon instance still serves. Public Horizon keeps about a year.
+var ErrBeforeHistory = errors.New("time is before the Horizon history window")
+
+// HorizonLedger is the subset of a /ledgers record used for time lookups.
+type HorizonLedger struct {
+	Sequence int64     `json:"sequence"`
+	ClosedAt time.Time `json:"closed_at"`
+}
+
+type horizonLedgersResponse struct {
+	Embedded struct {
+		Records []HorizonLedger `json:"records"`
+	} `json:"_embedded"`
+}
+
+// FetchLedgerAt returns the last ledger that closed at or before t. Horizon
+// has no time filter on /ledgers, so this binary-searches sequence numbers
+// between the oldest and newest ledger it serves (about 25 requests).
+// Returns ErrBeforeHistory when t predates the oldest served ledger.
+func (c *Client) FetchLedgerAt(ctx context.Context, t time.Time) (HorizonLedger, error) {
+	oldest, err := c.edgeLedger(ctx, "asc")
+	if err != nil {
+		return HorizonLedger{}, err
+	}
+	if t.Before(oldest.ClosedAt) {
+		return HorizonLedger{}, fmt.Errorf("%w: %s is before ledger %d (%s)", ErrBeforeHistory,
+			t.UTC().Format(time.RFC3339), oldest.Sequence, oldest.ClosedAt.Format(time.RFC3339))
+	}
+	newest, err := c.edgeLedger(ctx, "desc")
+	if err != nil {
+		return HorizonLedger{}, err
+	}
+	if !t.Before(newest.ClosedAt) {
+		return newest, nil
+	}
+
+	// Invariant: lo.ClosedAt <= t < hi.ClosedAt.
+	lo, hi := oldest, newest
+	for hi.Sequence-lo.Sequence > 1 {
+		var mid HorizonLedger
+		seq := lo.Sequence + (hi.Sequence-lo.Sequence)/2
+		if err := c.getJSON(ctx, fmt.Sprintf("/ledgers/%d", seq), &mid); err != nil {
+			return HorizonLedger{}, fmt.Errorf("fetching ledger %d: %w", seq, err)
+		}
+		if mid.ClosedAt.After(t) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return lo, nil
+}
+
+func (c *Client) edgeLedger(ctx context.Context, order string) (HorizonLedger, error) {
+	var resp horizonLedgersResponse
+	if err := c.getJSON(ctx, "/ledgers?order="+order+"&limit=1", &resp); err != nil {
+		return HorizonLedger{}, fmt.Errorf("fetching %s ledger: %w", order, err)
+	}
+	if len(resp.Embedded.Records) == 0 {
+		return HorizonLedger{}, fmt.Errorf("fetching %s ledger: no ledgers returned", order)
+	}
+	return resp.Embedded.Records[0], nil
+}
+
+// horizonEffect is the subset of an /effects record needed to undo balance
+// changes. Assets in reserve lists use Horizon's "CODE:ISSUER" / "native" form.
+type horizonEffect struct {
+	Type            string    `json:"type"`
+	CreatedAt       time.Time `json:"created_at"`
+	Amount          string    `json:"amount"`
+	StartingBalance string    `json:"starting_balance"`
+	AssetType       string    `json:"asset_type"`
+	AssetCode       string    `json:"asset_code"`
+	AssetIssuer     string    `json:"asset_issuer"`
+	Limit           string    `json:"limit"`
+
+	SoldAmount        string `json:"sold_amount"`
+	SoldAssetType     string `json:"sold_asset_type"`
+	SoldAssetCode     string `json:"sold_asset_code"`
+	SoldAssetIssuer   string `json:"sold_asset_issuer"`
+	BoughtAmount      string `json:"bought_amount"`
+	BoughtAssetType   string `json:"bought_asset_type"`
+	BoughtAssetCode   string `json:"bought_asset_code"`
+	BoughtAssetIssuer string `json:"bought_asset_issuer"`
+
+	ReservesDeposited []horizonReserve `json:"reserves_deposited"`
+	ReservesReceived  []horizonReserve `json:"reserves_received"`
+}
+
+type horizonReserve struct {
+	Asset  string `json:"asset"`
+	Amount string `json:"amount"`
+}
+
+type horizonEffectsResponse struct {
+	Embedded struct {
+		Records []horizonEffect `json:"records"`
+	} `json:"_embedded"`
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+}
+
+// balanceKey identifies a balance line: "native" or "CODE:ISSUER".
+func balanceKey(assetType, code, issuer string) string {
+	if assetType == "native" {
+		return "native"
+	}
+	return code + ":" + issuer
+}
+
+// FetchAccountAsOf reconstructs an account's balances as of asOf by taking
+// the current state and undoing every effect recorded after asOf, newest
+// first: credits, debits, trades, account creation, liquidity pool deposits
+// and withdrawals, and trustline creation/removal. Limitations:
+//   - transaction fees are not effects, so the XLM balance is overstated by
+//     the fees paid since asOf;
+//   - data entries and LP share lines are returned as they are now;
+//   - effects are only available inside the Horizon history window.
+func (c *Client) FetchAccountAsOf(ctx context.Context, accountID string, asOf time.Time) (HorizonAccount, error) {
+	account, err := c.FetchAccount(ctx, accountID)
+	if err != nil {
+		return HorizonAccount{}, err
+	}
+
+	type line struct {
+		bal HorizonBalance
+		amt decimal.Decimal
+	}
+	lines := make(map[string]*line, len(account.Balances))
+	var poolShares []HorizonBalance
+	for _, b := range account.Balances {
+		if b.AssetType == "liquidity_pool_shares" {
+			poolShares = append(poolShares, b)
+			continue
+		}
+		amt, err := decimal.NewFromString(b.Balance)
+		if err != nil {
+			return HorizonAccount{}, fmt.Errorf("parsing %s balance on %s: %w", b.AssetCode, accountID, err)
+		}
+		lines[balanceKey(b.AssetType, b.AssetCode, b.AssetIssuer)] = &line{bal: b, amt: amt}
+	}
+	undo := func(key string, delta string, sign int64) error {
+		if delta == "" {
+			return nil
+		}
+		d, err := decimal.NewFromString(delta)
+		if err != nil {
+			return fmt.Errorf("parsing effect amount %q: %w", delta, err)
+		}
+		l, ok := lines[key]
+		if !ok {
+			// Trustline removed after asOf: recreate it before undoing.
+			l = &line{bal: balanceFromKey(key)}
+			lines[key] = l
+		}
+		l.amt = l.amt.Sub(d.Mul(decimal.NewFromInt(sign)))
+		return nil
+	}
+	created := make(map[string]bool)
+
+	path := fmt.Sprintf("/accounts/%s/effects?order=desc&limit=200", accountID)
+walk:
+	for path != "" {
+		var resp horizonEffectsResponse
+		if err := c.getJSON(ctx, path, &resp); err != nil {
+			return HorizonAccount{}, fmt.Errorf("fetching effects for %s: %w", accountID, err)
+		}
+		for _, e := range resp.Embedded.Records {
+			if !e.CreatedAt.After(asOf) {
+				break walk
+			}
+			var err error
+			switch e.Type {
+			case "account_credited":
+				err = undo(balanceKey(e.AssetType, e.AssetCode, e.AssetIssuer), e.Amount, 1)
+			case "account_debited":
+				err = undo(balanceKey(e.AssetType, e.AssetCode, e.AssetIssuer), e.Amount, -1)
+			case "account_created":
+				err = undo("native", e.StartingBalance, 1)
+			case "trade":
+				if err = undo(balanceKey(e.BoughtAssetType, e.BoughtAssetCode, e.BoughtAssetIssuer), e.BoughtAmount, 1); err == nil {
+					err = undo(balanceKey(e.SoldAssetType, e.SoldAssetCode, e.SoldAssetIssuer), e.SoldAmount, -1)
+				}
+			case "liquidity_pool_deposited":
+				for _, r := range e.ReservesDeposited {
+					if err = undo(r.Asset, r.Amount, -1); err != nil {
+						break
+					}
+				}
+			case "liquidity_pool_withdrew":
+				for _, r := range e.ReservesReceived {
+					if err = undo(r.Asset, r.Amount, 1); err != nil {
+						break
+					}
+				}
+			case "trustline_created":
+				created[balanceKey(e.AssetType, e.AssetCode, e.AssetIssuer)] = true
+			case "trustline_removed":
+				key := balanceKey(e.AssetType, e.AssetCode, e.AssetIssuer)
+				if _, ok := lines[key]; !ok {
+					b := balanceFromKey(key)
+					b.Limit = e.Limit
+					lines[key] = &line{bal: b}
+				}
+			}
+			if err != nil {
+				return HorizonAccount{}, fmt.Errorf("undoing %s effect on %s: %w", e.Type, accountID, err)
+			}
+		}
+		if len(resp.Embedded.Records) == 0 || resp.Links.Next.Href == "" {
+			break
+		}
+		u, err := url.Parse(resp.Links.Next.Href)
+		if err != nil {
+			return HorizonAccount{}, fmt.Errorf("parsing Horizon pagination link %q: %w", resp.Links.Next.Href, err)
+		}
+		path = u.Path + "?" + u.RawQuery
+	}
+
+	keys := make([]string, 0, len(lines))
+	for k := range lines {
+		if !created[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	balances := make([]HorizonBalance, 0, len(keys)+len(poolShares))
+	for _, k := range keys {
+		l := lines[k]
+		l.bal.Balance = l.amt.StringFixed(7)
+		balances = append(balances, l.bal)
+	}
+	account.Balances = append(balances, poolShares...)
+	return account, nil
+}
+
+// balanceFromKey builds an empty balance line for a balanceKey.
+func balanceFromKey(key string) HorizonBalance {
+	if key == "native" {
+		return HorizonBalance{AssetType: "native"}
+	}
+	code, issuer, _ := strings.Cut(key, ":")
+	assetType := "credit_alphanum4"
+	if len(code) > 4 {
+		assetType = "credit_alphanum12"
+	}
+	return HorizonBalance{AssetType: assetType, AssetCode: code, AssetIssuer: issuer}
+}
@@ -0,0 +1,117 @@
+package horizon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchLedgerAt(t *testing.T) {
+	genesis := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	const first, last = 1000, 1999
+	closedAt := func(seq int) time.Time { return genesis.Add(time.Duration(seq-first) * 5 * time.Second) }
+	ledger := func(seq int) string {
+		return fmt.Sprintf(`{"sequence": %d, "closed_at": %q}`, seq, closedAt(seq).Format(time.RFC3339))
+	}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/ledgers" && r.URL.Query().Get("order") == "asc":
+			fmt.Fprintf(w, `{"_embedded": {"records": [%s]}}`, ledger(first))
+		case r.URL.Path == "/ledgers":
+			fmt.Fprintf(w, `{"_embedded": {"records": [%s]}}`, ledger(last))
+		default:
+			seq, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/ledgers/"))
+			w.Write([]byte(ledger(seq)))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+
+	got, err := client.FetchLedgerAt(context.Background(), closedAt(1500).Add(3*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Sequence != 1500 {
+		t.Errorf("sequence = %d, want 1500 (last ledger closed at or before t)", got.Sequence)
+	}
+	if requests > 14 {
+		t.Errorf("requests = %d, want a binary search", requests)
+	}
+
+	if got, _ := client.FetchLedgerAt(context.Background(), closedAt(last).Add(time.Hour)); got.Sequence != last {
+		t.Errorf("future time: sequence = %d, want newest %d", got.Sequence, last)
+	}
+	if _, err := client.FetchLedgerAt(context.Background(), genesis.Add(-time.Second)); !errors.Is(err, ErrBeforeHistory) {
+		t.Errorf("err = %v, want ErrBeforeHistory", err)
+	}
+}
+
+func TestFetchAccountAsOf(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	after := asOf.Add(time.Hour).Format(time.RFC3339)
+	before := asOf.Add(-time.Hour).Format(time.RFC3339)
+	const mtl = `"asset_type": "credit_alphanum4", "asset_code": "MTL", "asset_issuer": "GISSUER"`
+	const eurmtl = `"asset_type": "credit_alphanum12", "asset_code": "EURMTL", "asset_issuer": "GISSUER"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/accounts/GACC" {
+			w.Write([]byte(`{"id": "GACC", "balances": [
+				{` + mtl + `, "balance": "150.0000000"},
+				{"asset_type": "credit_alphanum4", "asset_code": "NEW", "asset_issuer": "GISSUER", "balance": "5.0000000"},
+				{` + eurmtl + `, "balance": "20.0000000"},
+				{"asset_type": "native", "balance": "90.0000000"}
+			]}`))
+			return
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprintf(w, `{"_embedded": {"records": [
+				{"type": "account_credited", "created_at": %q, %s, "amount": "100"},
+				{"type": "trade", "created_at": %q,
+				 "sold_amount": "10", "sold_asset_type": "native",
+				 "bought_amount": "20", "bought_asset_type": "credit_alphanum12", "bought_asset_code": "EURMTL", "bought_asset_issuer": "GISSUER"},
+				{"type": "account_credited", "created_at": %q, "asset_type": "credit_alphanum4", "asset_code": "NEW", "asset_issuer": "GISSUER", "amount": "5"},
+				{"type": "trustline_created", "created_at": %q, "asset_type": "credit_alphanum4", "asset_code": "NEW", "asset_issuer": "GISSUER"}
+			]}, "_links": {"next": {"href": "%s/accounts/GACC/effects?cursor=2&order=desc&limit=200"}}}`,
+				after, mtl, after, after, after, "http://horizon.example")
+			return
+		}
+		fmt.Fprintf(w, `{"_embedded": {"records": [
+			{"type": "trustline_removed", "created_at": %q, %s},
+			{"type": "account_debited", "created_at": %q, %s, "amount": "999"}
+		]}, "_links": {"next": {"href": ""}}}`, after, strings.ReplaceAll(eurmtl, "GISSUER", "GOLD"), before, mtl)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+
+	acc, err := client.FetchAccountAsOf(context.Background(), "GACC", asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]string{}
+	for _, b := range acc.Balances {
+		got[balanceKey(b.AssetType, b.AssetCode, b.AssetIssuer)] = b.Balance
+	}
+	want := map[string]string{
+		"MTL:GISSUER":    "50.0000000",  // 150 - 100 credited after asOf
+		"native":         "100.0000000", // 90 + 10 sold after asOf
+		"EURMTL:GISSUER": "0.0000000",   // 20 bought after asOf
+		"EURMTL:GOLD":    "0.0000000",   // removed after asOf, existed before
+	}
+	b, _ := json.Marshal(got)
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q (all: %s)", k, got[k], v, b)
+		}
+	}
+	if _, ok := got["NEW:GISSUER"]; ok {
+		t.Error("trustline created after asOf must not appear")
+	}
+}
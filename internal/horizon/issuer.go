@@ -0,0 +1,100 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// IssuerOperation is one operation that moved an asset out of or back into
+// its issuer account, i.e. minted or burned it.
+type IssuerOperation struct {
+	Type   string // payment, path_payment_strict_send, path_payment_strict_receive or clawback
+	From   string
+	To     string // the issuer for payments back to it; empty for clawback
+	Amount decimal.Decimal
+	TxHash string
+	TS     time.Time
+}
+
+// Mint reports whether op created new supply (the issuer sent the asset).
+func (op IssuerOperation) Mint(issuer string) bool {
+	return op.Type != "clawback" && op.From == issuer
+}
+
+// issuerOperationTypes are the operation types that move an asset across
+// the issuer boundary. Claimable balances and contract transfers are not
+// walked; supply changes through them stay unattributed.
+var issuerOperationTypes = map[string]bool{
+	"payment":                     true,
+	"path_payment_strict_send":    true,
+	"path_payment_strict_receive": true,
+	"clawback":                    true,
+}
+
+// FetchIssuerOperations walks /accounts/{issuer}/operations descending and
+// returns the operations since `since` that sent asset from its issuer,
+// returned it to the issuer or clawed it back, oldest first. Path payments
+// count by their destination asset and amount.
+func (c *Client) FetchIssuerOperations(ctx context.Context, asset domain.AssetInfo, since time.Time) ([]IssuerOperation, error) {
+	if asset.IsNative() {
+		return nil, fmt.Errorf("native asset has no issuer")
+	}
+
+	var out []IssuerOperation
+	path := fmt.Sprintf("/accounts/%s/operations?order=desc&limit=200", asset.Issuer)
+	for path != "" {
+		var resp horizonOperationsResponse
+		if err := c.getJSON(ctx, path, &resp); err != nil {
+			return nil, fmt.Errorf("fetching operations for %s: %w", asset.Issuer, err)
+		}
+
+		done := false
+		for _, op := range resp.Embedded.Records {
+			t, err := time.Parse(time.RFC3339, op.CreatedAt)
+			if err != nil {
+				slog.Error("issuer walker: op timestamp not RFC3339, skipping", "raw", op.CreatedAt, "error", err)
+				continue
+			}
+			if t.Before(since) {
+				done = true
+				break
+			}
+			if !issuerOperationTypes[op.Type] || op.AssetCode != asset.Code || op.AssetIssuer != asset.Issuer {
+				continue
+			}
+			if op.Type != "clawback" && op.From != asset.Issuer && op.To != asset.Issuer {
+				continue
+			}
+			if op.From == op.To {
+				continue
+			}
+			amount, err := decimal.NewFromString(op.Amount)
+			if err != nil {
+				slog.Error("issuer walker: amount not numeric, skipping", "tx", op.TxHash, "raw", op.Amount, "error", err)
+				continue
+			}
+			out = append(out, IssuerOperation{Type: op.Type, From: op.From, To: op.To, Amount: amount, TxHash: op.TxHash, TS: t})
+		}
+
+		if done || len(resp.Embedded.Records) == 0 || resp.Links.Next.Href == "" {
+			break
+		}
+		u, err := url.Parse(resp.Links.Next.Href)
+		if err != nil {
+			return nil, fmt.Errorf("parsing Horizon pagination link %q: %w", resp.Links.Next.Href, err)
+		}
+		path = u.Path + "?" + u.RawQuery
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,52 @@
+package horizon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestFetchIssuerOperationsFiltersAndStops(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/GISSUER/operations" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"_embedded":{"records":[
+			{"type":"clawback","from":"GHOLDER2","asset_code":"MTL","asset_issuer":"GISSUER","amount":"5.0000000","created_at":"2026-10-03T12:00:00Z","transaction_hash":"tx5"},
+			{"type":"payment","from":"GHOLDER1","to":"GISSUER","asset_code":"MTL","asset_issuer":"GISSUER","amount":"20.0000000","created_at":"2026-10-03T10:00:00Z","transaction_hash":"tx4"},
+			{"type":"payment","from":"GISSUER","to":"GHOLDER1","asset_code":"EURMTL","asset_issuer":"GISSUER","amount":"99.0000000","created_at":"2026-10-02T12:00:00Z","transaction_hash":"tx3"},
+			{"type":"manage_data","name":"X","created_at":"2026-10-02T11:00:00Z"},
+			{"type":"payment","from":"GISSUER","to":"GHOLDER1","asset_code":"MTL","asset_issuer":"GISSUER","amount":"100.0000000","created_at":"2026-10-02T10:00:00Z","transaction_hash":"tx2"},
+			{"type":"payment","from":"GISSUER","to":"GHOLDER3","asset_code":"MTL","asset_issuer":"GISSUER","amount":"7.0000000","created_at":"2026-09-30T10:00:00Z","transaction_hash":"tx1"}
+		]},"_links":{"next":{"href":"/accounts/GISSUER/operations?cursor=next"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+	ops, err := client.FetchIssuerOperations(context.Background(), domain.NewAssetInfo("MTL", "GISSUER"),
+		time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		tx   string
+		mint bool
+	}{{"tx2", true}, {"tx4", false}, {"tx5", false}}
+	if len(ops) != len(want) {
+		t.Fatalf("got %d ops %+v, want %d", len(ops), ops, len(want))
+	}
+	for i, w := range want {
+		if ops[i].TxHash != w.tx || ops[i].Mint("GISSUER") != w.mint {
+			t.Errorf("op %d = %+v, want tx %s mint %v", i, ops[i], w.tx, w.mint)
+		}
+	}
+	if ops[0].To != "GHOLDER1" || ops[0].Amount.String() != "100" {
+		t.Errorf("issuance op = %+v", ops[0])
+	}
+}
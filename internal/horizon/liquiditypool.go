@@ -20,6 +20,10 @@ type HorizonLiquidityPoolsResponse struct {
 	} `json:"_embedded"`
 }
 
+// maxPairPools bounds the pools fetched for one pair. The protocol allows one
+// constant-product pool per pair and fee tier, so this is a safety cap.
+const maxPairPools = 10
+
 // FetchLiquidityPools retrieves liquidity pools containing both reserve assets.
 func (c *Client) FetchLiquidityPools(ctx context.Context, reserveA, reserveB domain.AssetInfo) ([]HorizonLiquidityPool, error) {
 	params := url.Values{}
@@ -33,7 +37,7 @@ func (c *Client) FetchLiquidityPools(ctx context.Context, reserveA, reserveB dom
 		}
 	}
 	params.Set("reserves", reserves[0]+","+reserves[1])
-	params.Set("limit", "1")
+	params.Set("limit", fmt.Sprint(maxPairPools))
 
 	var resp HorizonLiquidityPoolsResponse
 	if err := c.getJSON(ctx, "/liquidity_pools?"+params.Encode(), &resp); err != nil {
@@ -0,0 +1,97 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// AccountOperation is one operation of an account with its transaction memo.
+// Which fields are set depends on Type: transfers have From, To, Asset and
+// Amount; trustline changes have Asset; offers have Selling, Buying, Amount
+// and Price; manage_data has DataName.
+type AccountOperation struct {
+	ID       string // Horizon paging token
+	Type     string
+	Source   string
+	From     string
+	To       string
+	Asset    *domain.AssetInfo
+	Amount   string
+	Selling  *domain.AssetInfo
+	Buying   *domain.AssetInfo
+	Price    string
+	DataName string
+	Memo     string
+	MemoType string
+	TxHash   string
+	TS       time.Time
+}
+
+// FetchAccountOperations returns one page of /accounts/{id}/operations joined
+// with transactions, after cursor ("" = from the start in order) in order
+// "asc" or "desc".
+func (c *Client) FetchAccountOperations(ctx context.Context, account, cursor, order string, limit int) ([]AccountOperation, error) {
+	q := url.Values{"join": {"transactions"}, "order": {order}, "limit": {fmt.Sprint(limit)}}
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	var resp horizonOperationsResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/accounts/%s/operations?%s", account, q.Encode()), &resp); err != nil {
+		return nil, fmt.Errorf("fetching operations of %s: %w", account, err)
+	}
+	out := make([]AccountOperation, 0, len(resp.Embedded.Records))
+	for _, op := range resp.Embedded.Records {
+		ts, err := time.Parse(time.RFC3339, op.CreatedAt)
+		if err != nil {
+			slog.Error("operation walker: op timestamp not RFC3339, skipping", "raw", op.CreatedAt, "error", err)
+			continue
+		}
+		out = append(out, toAccountOperation(op, ts))
+	}
+	return out, nil
+}
+
+func toAccountOperation(op horizonOperation, ts time.Time) AccountOperation {
+	o := AccountOperation{
+		ID: op.PagingToken, Type: op.Type, Source: op.SourceAccount,
+		From: op.From, To: op.To, Amount: op.Amount, TxHash: op.TxHash, TS: ts,
+	}
+	if op.Transaction != nil {
+		o.Memo, o.MemoType = op.Transaction.Memo, op.Transaction.MemoType
+	}
+	switch op.Type {
+	case "create_account":
+		xlm := domain.XLMAsset()
+		o.From, o.To, o.Asset, o.Amount = op.Funder, op.Account, &xlm, op.StartingBalance
+	case "account_merge":
+		xlm := domain.XLMAsset()
+		o.From, o.To, o.Asset = op.Account, op.Into, &xlm
+	case "manage_sell_offer", "manage_buy_offer", "create_passive_sell_offer":
+		o.Selling = operationAsset(op.SellingAssetType, op.SellingAssetCode, op.SellingAssetIssuer)
+		o.Buying = operationAsset(op.BuyingAssetType, op.BuyingAssetCode, op.BuyingAssetIssuer)
+		o.Price = op.Price
+	case "manage_data":
+		o.DataName = op.Name
+	default:
+		o.Asset = operationAsset(op.AssetType, op.AssetCode, op.AssetIssuer)
+	}
+	return o
+}
+
+// operationAsset returns nil when the record carries no asset.
+func operationAsset(assetType, code, issuer string) *domain.AssetInfo {
+	switch {
+	case assetType == "native":
+		a := domain.XLMAsset()
+		return &a
+	case code != "":
+		a := domain.NewAssetInfo(code, issuer)
+		return &a
+	}
+	return nil
+}
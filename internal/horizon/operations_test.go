@@ -0,0 +1,47 @@
+package horizon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchAccountOperations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/accounts/GFUND/operations" || q.Get("join") != "transactions" || q.Get("order") != "desc" || q.Get("cursor") != "200" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"_embedded":{"records":[
+			{"paging_token":"199","type":"payment","source_account":"GWHALE","from":"GWHALE","to":"GFUND","asset_type":"credit_alphanum4","asset_code":"MTL","asset_issuer":"GISSUER","amount":"5.0000000","created_at":"2026-10-02T10:00:00Z","transaction_hash":"tx1","transaction":{"memo":"buy","memo_type":"text"}},
+			{"paging_token":"198","type":"manage_sell_offer","source_account":"GFUND","selling_asset_type":"native","buying_asset_type":"credit_alphanum4","buying_asset_code":"MTL","buying_asset_issuer":"GISSUER","amount":"100.0000000","price":"0.2500000","created_at":"2026-10-02T09:00:00Z"},
+			{"paging_token":"197","type":"create_account","funder":"GFUND","account":"GNEW","starting_balance":"10.0000000","created_at":"2026-10-02T08:00:00Z"},
+			{"paging_token":"196","type":"manage_data","name":"mtla_delegate","created_at":"2026-10-02T07:00:00Z"}
+		]}}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+
+	ops, err := client.FetchAccountOperations(context.Background(), "GFUND", "200", "desc", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 4 {
+		t.Fatalf("got %d operations, want 4", len(ops))
+	}
+	if op := ops[0]; op.Asset == nil || op.Asset.Code != "MTL" || op.Memo != "buy" || op.MemoType != "text" || op.Source != "GWHALE" {
+		t.Errorf("payment = %+v", op)
+	}
+	if op := ops[1]; op.Selling == nil || !op.Selling.IsNative() || op.Buying == nil || op.Buying.Code != "MTL" || op.Price != "0.2500000" {
+		t.Errorf("offer = %+v", op)
+	}
+	if op := ops[2]; op.From != "GFUND" || op.To != "GNEW" || op.Asset == nil || !op.Asset.IsNative() || op.Amount != "10.0000000" {
+		t.Errorf("create_account = %+v", op)
+	}
+	if op := ops[3]; op.DataName != "mtla_delegate" || op.Asset != nil {
+		t.Errorf("manage_data = %+v", op)
+	}
+}
@@ -21,14 +21,31 @@ type horizonTransaction struct {
 }
 
 type horizonOperation struct {
-	Type        string              `json:"type"`
-	To          string              `json:"to"`
-	From        string              `json:"from"`
-	AssetCode   string              `json:"asset_code"`
-	AssetIssuer string              `json:"asset_issuer"`
-	Amount      string              `json:"amount"`
-	CreatedAt   string              `json:"created_at"`
-	Transaction *horizonTransaction `json:"transaction"`
+	PagingToken   string              `json:"paging_token"`
+	Type          string              `json:"type"`
+	SourceAccount string              `json:"source_account"`
+	To            string              `json:"to"`
+	From          string              `json:"from"`
+	AssetType     string              `json:"asset_type"`
+	AssetCode     string              `json:"asset_code"`
+	AssetIssuer   string              `json:"asset_issuer"`
+	Amount        string              `json:"amount"`
+	CreatedAt     string              `json:"created_at"`
+	TxHash        string              `json:"transaction_hash"`
+	Transaction   *horizonTransaction `json:"transaction"`
+	// create_account fields
+	Funder          string `json:"funder"`
+	Account         string `json:"account"`
+	StartingBalance string `json:"starting_balance"`
+	Into            string `json:"into"` // account_merge destination
+	// offer fields (manage_*_offer, create_passive_sell_offer)
+	SellingAssetType   string `json:"selling_asset_type"`
+	SellingAssetCode   string `json:"selling_asset_code"`
+	SellingAssetIssuer string `json:"selling_asset_issuer"`
+	BuyingAssetType    string `json:"buying_asset_type"`
+	BuyingAssetCode    string `json:"buying_asset_code"`
+	BuyingAssetIssuer  string `json:"buying_asset_issuer"`
+	Price              string `json:"price"`
 	// manage_data fields (populated only when Type == "manage_data")
 	Name  string `json:"name"`
 	Value string `json:"value"` // base64-encoded for manage_data ops with non-nil value
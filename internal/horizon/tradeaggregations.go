@@ -0,0 +1,60 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// HorizonTradeAggregation is one bucket from /trade_aggregations. Volumes and
+// prices are decimal strings; empty buckets are omitted by Horizon, not
+// returned as zero.
+type HorizonTradeAggregation struct {
+	Timestamp     string `json:"timestamp"` // bucket start, Unix milliseconds
+	TradeCount    string `json:"trade_count"`
+	BaseVolume    string `json:"base_volume"`
+	CounterVolume string `json:"counter_volume"`
+	Close         string `json:"close"` // last trade price in the bucket, counter per base
+}
+
+type horizonTradeAggregationsResponse struct {
+	Embedded struct {
+		Records []HorizonTradeAggregation `json:"records"`
+	} `json:"_embedded"`
+}
+
+// FetchTradeAggregations returns volume buckets of the given resolution for
+// [start, end). resolution must be one Horizon accepts (1m, 5m, 15m, 1h, 1d,
+// 1w). At daily resolution a year fits in one page.
+func (c *Client) FetchTradeAggregations(ctx context.Context, base, counter domain.AssetInfo, resolution time.Duration, start, end time.Time) ([]HorizonTradeAggregation, error) {
+	params := url.Values{}
+	if base.IsNative() {
+		params.Set("base_asset_type", "native")
+	} else {
+		params.Set("base_asset_type", string(base.Type))
+		params.Set("base_asset_code", base.Code)
+		params.Set("base_asset_issuer", base.Issuer)
+	}
+	if counter.IsNative() {
+		params.Set("counter_asset_type", "native")
+	} else {
+		params.Set("counter_asset_type", string(counter.Type))
+		params.Set("counter_asset_code", counter.Code)
+		params.Set("counter_asset_issuer", counter.Issuer)
+	}
+	params.Set("resolution", strconv.FormatInt(resolution.Milliseconds(), 10))
+	params.Set("start_time", strconv.FormatInt(start.UnixMilli(), 10))
+	params.Set("end_time", strconv.FormatInt(end.UnixMilli(), 10))
+	params.Set("order", "asc")
+	params.Set("limit", "200")
+
+	var resp horizonTradeAggregationsResponse
+	if err := c.getJSON(ctx, "/trade_aggregations?"+params.Encode(), &resp); err != nil {
+		return nil, fmt.Errorf("fetching trade aggregations: %w", err)
+	}
+	return resp.Embedded.Records, nil
+}
@@ -88,3 +88,39 @@ func TestFetchTradesHTTPError(t *testing.T) {
 		t.Fatal("expected error on HTTP 500")
 	}
 }
+
+func TestFetchTradeAggregationsParams(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 30)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trade_aggregations" {
+			t.Errorf("path = %q, want /trade_aggregations", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("resolution"); got != "86400000" {
+			t.Errorf("resolution = %q, want 86400000", got)
+		}
+		if got := q.Get("start_time"); got != "1772323200000" {
+			t.Errorf("start_time = %q", got)
+		}
+		if got := q.Get("counter_asset_type"); got != "native" {
+			t.Errorf("counter_asset_type = %q, want native", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"_embedded": {"records": [
+			{"timestamp": "1772323200000", "trade_count": "3", "base_volume": "12.5000000", "counter_volume": "100.0000000"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+	mtl := domain.AssetInfo{Code: "MTL", Issuer: "GISSUER", Type: domain.AssetTypeCreditAlphanum4}
+
+	aggs, err := client.FetchTradeAggregations(context.Background(), mtl, domain.XLMAsset(), 24*time.Hour, start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(aggs) != 1 || aggs[0].BaseVolume != "12.5000000" {
+		t.Errorf("aggregations = %+v", aggs)
+	}
+}
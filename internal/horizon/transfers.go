@@ -0,0 +1,109 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// AccountTransfer is one value transfer touching an account: a payment, a
+// path payment (counted by its destination asset and amount) or the
+// creation of an account with a starting XLM balance.
+type AccountTransfer struct {
+	ID     string // Horizon paging token, the cursor to resume after
+	Type   string
+	From   string
+	To     string
+	Asset  domain.AssetInfo
+	Amount decimal.Decimal
+	Memo   string
+	TxHash string
+	TS     time.Time
+}
+
+// maxTransferPages bounds one FetchAccountTransfers call; the returned cursor
+// resumes where it stopped.
+const maxTransferPages = 20
+
+// FetchAccountTransfers walks /accounts/{id}/payments ascending from cursor
+// and returns the transfers after it, oldest first, with the cursor to resume
+// from next time. An empty cursor starts at the account's latest operation
+// and returns no transfers, so a new watcher doesn't replay history.
+func (c *Client) FetchAccountTransfers(ctx context.Context, account, cursor string) ([]AccountTransfer, string, error) {
+	if cursor == "" {
+		var resp horizonOperationsResponse
+		if err := c.getJSON(ctx, fmt.Sprintf("/accounts/%s/payments?order=desc&limit=1", account), &resp); err != nil {
+			return nil, "", fmt.Errorf("fetching latest payment of %s: %w", account, err)
+		}
+		if len(resp.Embedded.Records) == 0 {
+			return nil, "0", nil
+		}
+		return nil, resp.Embedded.Records[0].PagingToken, nil
+	}
+
+	var out []AccountTransfer
+	path := fmt.Sprintf("/accounts/%s/payments?join=transactions&order=asc&limit=200&cursor=%s", account, url.QueryEscape(cursor))
+	for range maxTransferPages {
+		var resp horizonOperationsResponse
+		if err := c.getJSON(ctx, path, &resp); err != nil {
+			return nil, "", fmt.Errorf("fetching payments of %s: %w", account, err)
+		}
+		for _, op := range resp.Embedded.Records {
+			cursor = op.PagingToken
+			t, ok := parseTransfer(op)
+			if !ok {
+				continue
+			}
+			out = append(out, t)
+		}
+		if len(resp.Embedded.Records) == 0 || resp.Links.Next.Href == "" {
+			break
+		}
+		u, err := url.Parse(resp.Links.Next.Href)
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing Horizon pagination link %q: %w", resp.Links.Next.Href, err)
+		}
+		path = u.Path + "?" + u.RawQuery
+	}
+	return out, cursor, nil
+}
+
+// parseTransfer converts a /payments record; ok is false for operations
+// without a transferred amount (account merges, contract invocations).
+func parseTransfer(op horizonOperation) (AccountTransfer, bool) {
+	t := AccountTransfer{ID: op.PagingToken, Type: op.Type, From: op.From, To: op.To, TxHash: op.TxHash}
+	raw := op.Amount
+	switch op.Type {
+	case "payment", "path_payment_strict_send", "path_payment_strict_receive":
+		if op.AssetType == "native" {
+			t.Asset = domain.XLMAsset()
+		} else {
+			t.Asset = domain.NewAssetInfo(op.AssetCode, op.AssetIssuer)
+		}
+	case "create_account":
+		t.From, t.To, t.Asset, raw = op.Funder, op.Account, domain.XLMAsset(), op.StartingBalance
+	default:
+		return AccountTransfer{}, false
+	}
+	amount, err := decimal.NewFromString(raw)
+	if err != nil {
+		slog.Error("transfer walker: amount not numeric, skipping", "tx", op.TxHash, "raw", raw, "error", err)
+		return AccountTransfer{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, op.CreatedAt)
+	if err != nil {
+		slog.Error("transfer walker: op timestamp not RFC3339, skipping", "raw", op.CreatedAt, "error", err)
+		return AccountTransfer{}, false
+	}
+	t.Amount, t.TS = amount, ts
+	if op.Transaction != nil {
+		t.Memo = op.Transaction.Memo
+	}
+	return t, true
+}
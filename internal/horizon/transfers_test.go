@@ -0,0 +1,59 @@
+package horizon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchAccountTransfers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/GFUND/payments" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("order") == "desc" {
+			w.Write([]byte(`{"_embedded":{"records":[{"paging_token":"500","type":"payment"}]}}`))
+			return
+		}
+		if r.URL.Query().Get("cursor") != "100" {
+			w.Write([]byte(`{"_embedded":{"records":[]}}`))
+			return
+		}
+		w.Write([]byte(`{"_embedded":{"records":[
+			{"paging_token":"101","type":"payment","from":"GWHALE","to":"GFUND","asset_type":"credit_alphanum4","asset_code":"MTL","asset_issuer":"GISSUER","amount":"5000.0000000","created_at":"2026-10-02T10:00:00Z","transaction_hash":"tx1","transaction":{"memo":"buy"}},
+			{"paging_token":"102","type":"account_merge","created_at":"2026-10-02T11:00:00Z"},
+			{"paging_token":"103","type":"create_account","funder":"GFUND","account":"GNEW","starting_balance":"10.0000000","created_at":"2026-10-02T12:00:00Z","transaction_hash":"tx3"},
+			{"paging_token":"104","type":"payment","from":"GFUND","to":"GX","asset_type":"native","amount":"1.0000000","created_at":"2026-10-02T13:00:00Z","transaction_hash":"tx4"}
+		]},"_links":{"next":{"href":"/accounts/GFUND/payments?cursor=104&order=asc"}}}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, 1, 10*time.Millisecond)
+
+	transfers, cursor, err := client.FetchAccountTransfers(context.Background(), "GFUND", "")
+	if err != nil || len(transfers) != 0 || cursor != "500" {
+		t.Errorf("first run = %v, %q, %v; want no transfers from cursor 500", transfers, cursor, err)
+	}
+
+	transfers, cursor, err = client.FetchAccountTransfers(context.Background(), "GFUND", "100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != "104" {
+		t.Errorf("cursor = %q, want 104", cursor)
+	}
+	if len(transfers) != 3 {
+		t.Fatalf("got %d transfers %+v, want 3", len(transfers), transfers)
+	}
+	if tr := transfers[0]; tr.Asset.Code != "MTL" || tr.From != "GWHALE" || tr.Memo != "buy" || tr.Amount.String() != "5000" {
+		t.Errorf("payment = %+v", tr)
+	}
+	if tr := transfers[1]; tr.From != "GFUND" || tr.To != "GNEW" || !tr.Asset.IsNative() || tr.Amount.String() != "10" {
+		t.Errorf("create_account = %+v", tr)
+	}
+	if !transfers[2].Asset.IsNative() {
+		t.Errorf("native payment = %+v", transfers[2])
+	}
+}
@@ -2,9 +2,23 @@ package horizon
 
 // HorizonAccount represents the JSON response from GET /accounts/{id}.
 type HorizonAccount struct {
-	ID       string            `json:"id"`
-	Balances []HorizonBalance  `json:"balances"`
-	Data     map[string]string `json:"data"`
+	ID                   string              `json:"id"`
+	Sequence             string              `json:"sequence"` // int64 as a string
+	Balances             []HorizonBalance    `json:"balances"`
+	Data                 map[string]string   `json:"data"`
+	Flags                HorizonAccountFlags `json:"flags"`
+	HomeDomain           string              `json:"home_domain,omitempty"`
+	InflationDestination string              `json:"inflation_destination,omitempty"`
+	NumSponsoring        int                 `json:"num_sponsoring"`
+	NumSponsored         int                 `json:"num_sponsored"`
+}
+
+// HorizonAccountFlags are the issuer authorization flags of an account.
+type HorizonAccountFlags struct {
+	AuthRequired        bool `json:"auth_required"`
+	AuthRevocable       bool `json:"auth_revocable"`
+	AuthImmutable       bool `json:"auth_immutable"`
+	AuthClawbackEnabled bool `json:"auth_clawback_enabled"`
 }
 
 // HorizonBalance represents a single balance entry in an account response.
@@ -59,6 +73,7 @@ type HorizonPathResponse struct {
 // HorizonLiquidityPool represents a liquidity pool from the Horizon API.
 type HorizonLiquidityPool struct {
 	ID       string                        `json:"id"`
+	FeeBP    int                           `json:"fee_bp"`
 	Reserves []HorizonLiquidityPoolReserve `json:"reserves"`
 }
 
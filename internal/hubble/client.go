@@ -0,0 +1,119 @@
+package hubble
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+// queryTimeoutMs is how long one BigQuery call waits for the job before
+// returning; Query keeps polling until the job completes or ctx ends.
+const queryTimeoutMs = 60_000
+
+// Client runs standard-SQL queries through the BigQuery REST API, billed to
+// its project.
+type Client struct {
+	svc       *bigquery.Service
+	project   string
+	transport http.RoundTripper // nil = the API client's own
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTransport sends the API and token requests through rt, normally the
+// shared transport.Transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// NewClient creates a Client for project from a service account's JSON key.
+func NewClient(ctx context.Context, project, credentialsJSON string, opts ...Option) (*Client, error) {
+	c := &Client{project: project}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	authCtx := ctx
+	if c.transport != nil {
+		authCtx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: c.transport})
+	}
+	creds, err := google.CredentialsFromJSON(authCtx, []byte(credentialsJSON), bigquery.BigqueryScope)
+	if err != nil {
+		return nil, fmt.Errorf("parsing google credentials: %w", err)
+	}
+	svc, err := bigquery.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(authCtx, creds.TokenSource)))
+	if err != nil {
+		return nil, fmt.Errorf("creating bigquery service: %w", err)
+	}
+	c.svc = svc
+	return c, nil
+}
+
+// Query runs sql with named params and returns every row, each cell as the
+// string BigQuery sends (NULL as ""). It polls until the job is done and
+// reads all result pages.
+func (c *Client) Query(ctx context.Context, sql string, params ...Param) ([][]string, error) {
+	legacy := false
+	req := &bigquery.QueryRequest{
+		Query:         sql,
+		UseLegacySql:  &legacy,
+		ParameterMode: "NAMED",
+		TimeoutMs:     queryTimeoutMs,
+	}
+	for _, p := range params {
+		req.QueryParameters = append(req.QueryParameters, &bigquery.QueryParameter{
+			Name:           p.Name,
+			ParameterType:  &bigquery.QueryParameterType{Type: p.Type},
+			ParameterValue: &bigquery.QueryParameterValue{Value: p.Value},
+		})
+	}
+	resp, err := c.svc.Jobs.Query(c.project, req).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("running query: %w", err)
+	}
+
+	rows := appendRows(nil, resp.Rows)
+	complete, token, job := resp.JobComplete, resp.PageToken, resp.JobReference
+	for !complete || token != "" {
+		if job == nil {
+			return nil, fmt.Errorf("query did not complete and returned no job reference")
+		}
+		page, err := c.svc.Jobs.GetQueryResults(c.project, job.JobId).
+			Location(job.Location).
+			PageToken(token).
+			TimeoutMs(queryTimeoutMs).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, fmt.Errorf("reading results of job %s: %w", job.JobId, err)
+		}
+		// Rows only come with a completed job; an incomplete one is polled again.
+		if page.JobComplete {
+			rows = appendRows(rows, page.Rows)
+			token = page.PageToken
+		}
+		complete = page.JobComplete
+	}
+	return rows, nil
+}
+
+func appendRows(dst [][]string, rows []*bigquery.TableRow) [][]string {
+	for _, r := range rows {
+		row := make([]string, len(r.F))
+		for i, cell := range r.F {
+			if cell.V != nil {
+				row[i] = fmt.Sprint(cell.V)
+			}
+		}
+		dst = append(dst, row)
+	}
+	return dst
+}
@@ -0,0 +1,249 @@
+// Package hubble reads past ledger state from Hubble, the Stellar
+// Development Foundation's public BigQuery dataset. Horizon only serves the
+// current holders of an asset, so the holder counts of an old snapshot can't
+// be rebuilt from it; Hubble keeps every version of every trustline,
+// liquidity pool and claimable balance with the time its ledger closed,
+// which gives the state as of any moment.
+//
+// Queries scan partitions of large tables and are billed to the configured
+// project. Only `stat backfill-indicators` uses them, and only with
+// HUBBLE_PROJECT set.
+package hubble
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Param is a named query parameter, referenced as @Name in the SQL.
+type Param struct {
+	Name  string
+	Type  string // BigQuery type: STRING, FLOAT64, TIMESTAMP
+	Value string
+}
+
+// Runner runs a standard-SQL query and returns its rows (Client).
+type Runner interface {
+	Query(ctx context.Context, sql string, params ...Param) ([][]string, error)
+}
+
+// holderBalancesSQL lists each account's latest trustline version at @at
+// and keeps the live ones holding at least @min.
+const holderBalancesSQL = `
+SELECT account_id, FORMAT('%.7f', balance)
+FROM (
+  SELECT account_id, balance, deleted,
+    ROW_NUMBER() OVER (PARTITION BY account_id ORDER BY ledger_sequence DESC, last_modified_ledger DESC) AS rn
+  FROM ` + "`{dataset}.trust_lines`" + `
+  WHERE asset_code = @code AND asset_issuer = @issuer AND closed_at <= @at
+)
+WHERE rn = 1 AND NOT deleted AND balance >= @min`
+
+// supplySQL sums the latest version at @at of every trustline, claimable
+// balance and pool reserve of the asset: the first column is what accounts
+// and claimable balances hold, the second what pools hold.
+const supplySQL = `
+WITH lines AS (
+  SELECT balance AS amount, deleted,
+    ROW_NUMBER() OVER (PARTITION BY account_id ORDER BY ledger_sequence DESC, last_modified_ledger DESC) AS rn
+  FROM ` + "`{dataset}.trust_lines`" + `
+  WHERE asset_code = @code AND asset_issuer = @issuer AND closed_at <= @at
+), claims AS (
+  SELECT asset_amount AS amount, deleted,
+    ROW_NUMBER() OVER (PARTITION BY balance_id ORDER BY ledger_sequence DESC, last_modified_ledger DESC) AS rn
+  FROM ` + "`{dataset}.claimable_balances`" + `
+  WHERE asset_code = @code AND asset_issuer = @issuer AND closed_at <= @at
+), pools AS (
+  SELECT IF(asset_a_code = @code AND asset_a_issuer = @issuer, asset_a_amount, asset_b_amount) AS amount, deleted,
+    ROW_NUMBER() OVER (PARTITION BY liquidity_pool_id ORDER BY ledger_sequence DESC, last_modified_ledger DESC) AS rn
+  FROM ` + "`{dataset}.liquidity_pools`" + `
+  WHERE ((asset_a_code = @code AND asset_a_issuer = @issuer) OR (asset_b_code = @code AND asset_b_issuer = @issuer))
+    AND closed_at <= @at
+)
+SELECT
+  FORMAT('%.7f', IFNULL((SELECT SUM(amount) FROM lines WHERE rn = 1 AND NOT deleted), 0)
+    + IFNULL((SELECT SUM(amount) FROM claims WHERE rn = 1 AND NOT deleted), 0)),
+  FORMAT('%.7f', IFNULL((SELECT SUM(amount) FROM pools WHERE rn = 1 AND NOT deleted), 0))`
+
+// Supply is an asset's issued amount at a moment.
+type Supply struct {
+	Total decimal.Decimal // held by accounts, claimable balances and pools
+	Pools decimal.Decimal // held by liquidity pools
+}
+
+// Circulation is the supply outside pools, as metrics derives it from
+// Horizon's /assets (never negative).
+func (s Supply) Circulation() decimal.Decimal {
+	return decimal.Max(s.Total.Sub(s.Pools), decimal.Zero)
+}
+
+// Service answers historical holder and supply questions from Hubble.
+type Service struct {
+	run     Runner
+	dataset string
+}
+
+// NewService creates a Service reading the tables of dataset
+// (project.dataset, normally crypto-stellar.crypto_stellar).
+func NewService(run Runner, dataset string) *Service {
+	return &Service{run: run, dataset: dataset}
+}
+
+// HolderBalances returns the accounts whose trustline to asset held at least
+// min at time at, with their balances.
+func (s *Service) HolderBalances(ctx context.Context, asset domain.AssetInfo, at time.Time, min decimal.Decimal) (map[string]decimal.Decimal, error) {
+	rows, err := s.run.Query(ctx, s.sql(holderBalancesSQL), append(assetParams(asset, at),
+		Param{Name: "min", Type: "FLOAT64", Value: min.String()})...)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s holders at %s: %w", asset.Code, at.Format(time.RFC3339), err)
+	}
+	out := make(map[string]decimal.Decimal, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("querying %s holders: short row %v", asset.Code, row)
+		}
+		bal, err := decimal.NewFromString(row[1])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s balance of %s: %w", asset.Code, row[0], err)
+		}
+		out[row[0]] = bal
+	}
+	return out, nil
+}
+
+// Supply returns asset's supply at time at.
+func (s *Service) Supply(ctx context.Context, asset domain.AssetInfo, at time.Time) (Supply, error) {
+	rows, err := s.run.Query(ctx, s.sql(supplySQL), assetParams(asset, at)...)
+	if err != nil {
+		return Supply{}, fmt.Errorf("querying %s supply at %s: %w", asset.Code, at.Format(time.RFC3339), err)
+	}
+	if len(rows) != 1 || len(rows[0]) < 2 {
+		return Supply{}, fmt.Errorf("querying %s supply: unexpected result %v", asset.Code, rows)
+	}
+	total, err := decimal.NewFromString(rows[0][0])
+	if err != nil {
+		return Supply{}, fmt.Errorf("parsing %s supply: %w", asset.Code, err)
+	}
+	pools, err := decimal.NewFromString(rows[0][1])
+	if err != nil {
+		return Supply{}, fmt.Errorf("parsing %s pool reserves: %w", asset.Code, err)
+	}
+	return Supply{Total: total.Add(pools), Pools: pools}, nil
+}
+
+// Fill sets the holder counts and MTL / MTLRECT supplies that m lacks to
+// their values at time at, computed as metrics.EnrichMetrics computes them
+// from Horizon, and returns the IDs of the indicators whose input it set:
+// I6 and I7 (circulation), I24 (EURMTL holders), I40 (MTLAP holders) and
+// I23, I27, I62 (the MTL ∪ MTLRECT shareholders, set together). Values
+// already in m are kept. A failed query stops the fill; what was set before
+// it stays set and is returned with the error.
+func (s *Service) Fill(ctx context.Context, at time.Time, m *domain.FundLiveMetrics) ([]int, error) {
+	var filled []int
+	mtl := domain.NewAssetInfo("MTL", domain.IssuerAddress)
+	mtlrect := domain.NewAssetInfo("MTLRECT", domain.IssuerAddress)
+	stroop := decimal.New(1, -7)
+	one := decimal.NewFromInt(1)
+
+	for _, c := range []struct {
+		id          int
+		asset       domain.AssetInfo
+		circulation **string
+		supply      **string
+	}{
+		{6, mtl, &m.MTLCirculation, &m.MTLSupply},
+		{7, mtlrect, &m.MTLRECTCirculation, &m.MTLRECTSupply},
+	} {
+		if *c.circulation != nil {
+			continue
+		}
+		supply, err := s.Supply(ctx, c.asset, at)
+		if err != nil {
+			return filled, err
+		}
+		*c.circulation = ptr(supply.Circulation().String())
+		if *c.supply == nil {
+			*c.supply = ptr(supply.Total.String())
+		}
+		filled = append(filled, c.id)
+	}
+
+	if m.EURMTLParticipants == nil {
+		holders, err := s.HolderBalances(ctx, domain.EURMTLAsset(), at, stroop)
+		if err != nil {
+			return filled, err
+		}
+		m.EURMTLParticipants = ptr(fmt.Sprint(len(holders)))
+		filled = append(filled, 24)
+	}
+
+	if m.MTLAPHolders == nil {
+		holders, err := s.HolderBalances(ctx, domain.MTLAPAsset(), at, one)
+		if err != nil {
+			return filled, err
+		}
+		// The Secretariat's distribution account is not a participant, as in
+		// the live count.
+		m.MTLAPHolders = ptr(fmt.Sprint(max(len(holders)-1, 0)))
+		filled = append(filled, 40)
+	}
+
+	if m.MTLShareholders == nil || m.MTLShareholdersAny == nil || m.MTLShareholdersMedian == nil {
+		merged, err := s.HolderBalances(ctx, mtl, at, stroop)
+		if err != nil {
+			return filled, err
+		}
+		rect, err := s.HolderBalances(ctx, mtlrect, at, stroop)
+		if err != nil {
+			return filled, err
+		}
+		for id, bal := range rect {
+			merged[id] = merged[id].Add(bal)
+		}
+		var atLeastOne []decimal.Decimal
+		for _, bal := range merged {
+			if bal.GreaterThanOrEqual(one) {
+				atLeastOne = append(atLeastOne, bal)
+			}
+		}
+		m.MTLShareholders = ptr(fmt.Sprint(len(atLeastOne)))
+		m.MTLShareholdersAny = ptr(fmt.Sprint(len(merged)))
+		m.MTLShareholdersMedian = ptr(median(atLeastOne).String())
+		filled = append(filled, 23, 27, 62)
+	}
+	return filled, nil
+}
+
+func (s *Service) sql(query string) string {
+	return strings.ReplaceAll(query, "{dataset}", s.dataset)
+}
+
+func assetParams(asset domain.AssetInfo, at time.Time) []Param {
+	return []Param{
+		{Name: "code", Type: "STRING", Value: asset.Code},
+		{Name: "issuer", Type: "STRING", Value: asset.Issuer},
+		{Name: "at", Type: "TIMESTAMP", Value: at.UTC().Format("2006-01-02 15:04:05.999999") + " UTC"},
+	}
+}
+
+func median(values []decimal.Decimal) decimal.Decimal {
+	n := len(values)
+	if n == 0 {
+		return decimal.Zero
+	}
+	sorted := append([]decimal.Decimal(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return sorted[n/2-1].Add(sorted[n/2]).Div(decimal.NewFromInt(2))
+}
+
+func ptr(s string) *string { return &s }
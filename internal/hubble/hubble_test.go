@@ -0,0 +1,101 @@
+package hubble
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// stubRunner answers the holder query with rows per asset code and the
+// supply query with one row per asset code.
+type stubRunner struct {
+	holders map[string][][]string
+	supply  map[string][]string
+	calls   []string
+	err     error
+}
+
+func (s *stubRunner) Query(_ context.Context, sql string, params ...Param) ([][]string, error) {
+	var code, at string
+	for _, p := range params {
+		switch p.Name {
+		case "code":
+			code = p.Value
+		case "at":
+			at = p.Value
+		}
+	}
+	if !strings.Contains(sql, "`crypto-stellar.crypto_stellar.trust_lines`") || at != "2024-03-31 23:59:59 UTC" {
+		return nil, errors.New("unexpected query")
+	}
+	s.calls = append(s.calls, code)
+	if s.err != nil {
+		return nil, s.err
+	}
+	if strings.Contains(sql, "liquidity_pools") {
+		return [][]string{s.supply[code]}, nil
+	}
+	return s.holders[code], nil
+}
+
+func TestFill(t *testing.T) {
+	at := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	run := &stubRunner{
+		holders: map[string][][]string{
+			"EURMTL":  {{"GA", "5.0000000"}, {"GB", "0.0000001"}},
+			"MTLAP":   {{"GA", "2.0000000"}, {"GSECRETARIAT", "500.0000000"}},
+			"MTL":     {{"GA", "0.5000000"}, {"GB", "10.0000000"}, {"GC", "0.2000000"}},
+			"MTLRECT": {{"GA", "0.7000000"}, {"GD", "4.0000000"}},
+		},
+		supply: map[string][]string{
+			"MTL":     {"900.0000000", "100.0000000"},
+			"MTLRECT": {"50.0000000", "0.0000000"},
+		},
+	}
+	svc := NewService(run, "crypto-stellar.crypto_stellar")
+
+	var m domain.FundLiveMetrics
+	filled, err := svc.Fill(context.Background(), at, &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 7 {
+		t.Errorf("filled = %v, want I6, I7, I24, I40, I23, I27, I62", filled)
+	}
+	for name, c := range map[string]struct {
+		got  *string
+		want string
+	}{
+		"MTL circulation":     {m.MTLCirculation, "900"},
+		"MTL supply":          {m.MTLSupply, "1000"},
+		"MTLRECT circulation": {m.MTLRECTCirculation, "50"},
+		"EURMTL holders":      {m.EURMTLParticipants, "2"},
+		"MTLAP holders":       {m.MTLAPHolders, "1"},
+		"shareholders ≥ 1":    {m.MTLShareholders, "3"}, // GA (0.5 + 0.7), GB, GD
+		"shareholders any":    {m.MTLShareholdersAny, "4"},
+		"median":              {m.MTLShareholdersMedian, "4"},
+	} {
+		if c.got == nil || *c.got != c.want {
+			t.Errorf("%s = %v, want %s", name, c.got, c.want)
+		}
+	}
+
+	// Values a snapshot already has are kept and cost no query.
+	run.calls = nil
+	kept := "42"
+	m = domain.FundLiveMetrics{MTLCirculation: &kept, MTLAPHolders: &kept}
+	filled, _ = svc.Fill(context.Background(), at, &m)
+	if *m.MTLCirculation != "42" || *m.MTLAPHolders != "42" || len(filled) != 5 || strings.Join(run.calls, ",") != "MTLRECT,EURMTL,MTL,MTLRECT" {
+		t.Errorf("filled %v with calls %v, want the stored values kept", filled, run.calls)
+	}
+
+	run.err = errors.New("quota exceeded")
+	m = domain.FundLiveMetrics{}
+	if _, err := svc.Fill(context.Background(), at, &m); !errors.Is(err, run.err) {
+		t.Errorf("err = %v, want the query error", err)
+	}
+}
@@ -0,0 +1,127 @@
+package indicator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Resolution is the sampling of an indicator history.
+type Resolution string
+
+const (
+	ResolutionDay   Resolution = "day"   // fund_indicators rows as stored
+	ResolutionWeek  Resolution = "week"  // ISO weeks, starting Monday
+	ResolutionMonth Resolution = "month" // calendar months
+)
+
+// ParseResolution parses a resolution name.
+func ParseResolution(s string) (Resolution, error) {
+	switch r := Resolution(s); r {
+	case ResolutionDay, ResolutionWeek, ResolutionMonth:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid resolution %q, valid: day, week, month", s)
+}
+
+// ResolutionFor picks the resolution of a history starting at from and
+// ending at to: daily points up to half a year, weekly up to two years and
+// monthly beyond, so a chart stays within a few hundred points.
+func ResolutionFor(from, to time.Time) Resolution {
+	switch days := to.Sub(from).Hours() / 24; {
+	case days <= 184:
+		return ResolutionDay
+	case days <= 731:
+		return ResolutionWeek
+	default:
+		return ResolutionMonth
+	}
+}
+
+// AggregatePoint summarises one indicator over one week or month.
+type AggregatePoint struct {
+	PeriodStart time.Time // Monday of the week or first day of the month
+	CloseDate   time.Time // last day of the period with a value
+	IndicatorID int
+	Min         decimal.Decimal
+	Max         decimal.Decimal
+	Avg         decimal.Decimal
+	Close       decimal.Decimal // value on CloseDate
+	Samples     int
+}
+
+// refreshAggregates recomputes the week and month of date in
+// indicator_aggregates from fund_indicators for ids. It runs in Save's
+// transaction, so only the periods touched by a write are rebuilt.
+func refreshAggregates(ctx context.Context, tx pgx.Tx, entityID int, date time.Time, ids []int) error {
+	for _, res := range []Resolution{ResolutionWeek, ResolutionMonth} {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO indicator_aggregates
+			     (entity_id, resolution, period_start, indicator_id, min_value, max_value, avg_value, close_value, close_date, samples)
+			 SELECT fi.entity_id, $2::text, date_trunc($2::text, $3::timestamp)::date, fi.indicator_id,
+			        MIN(fi.value), MAX(fi.value), AVG(fi.value),
+			        (ARRAY_AGG(fi.value ORDER BY fi.snapshot_date DESC))[1], MAX(fi.snapshot_date), COUNT(*)
+			 FROM fund_indicators fi
+			 WHERE fi.entity_id = $1
+			   AND fi.indicator_id = ANY($4::int[])
+			   AND fi.snapshot_date >= date_trunc($2::text, $3::timestamp)
+			   AND fi.snapshot_date < date_trunc($2::text, $3::timestamp) + ('1 ' || $2::text)::interval
+			 GROUP BY fi.entity_id, fi.indicator_id
+			 ON CONFLICT (entity_id, resolution, indicator_id, period_start)
+			 DO UPDATE SET min_value = EXCLUDED.min_value, max_value = EXCLUDED.max_value,
+			               avg_value = EXCLUDED.avg_value, close_value = EXCLUDED.close_value,
+			               close_date = EXCLUDED.close_date, samples = EXCLUDED.samples`,
+			entityID, string(res), date, ids)
+		if err != nil {
+			return fmt.Errorf("refreshing %s aggregates: %w", res, err)
+		}
+	}
+	return nil
+}
+
+// GetAggregates returns the week or month aggregates of the given indicator
+// IDs whose period ends at or after from, ordered by period, then
+// indicator ID. Averages are rounded by each indicator's policy like the
+// stored values.
+func (r *PgRepository) GetAggregates(ctx context.Context, slug string, ids []int, res Resolution, from time.Time) ([]AggregatePoint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT ia.period_start, ia.close_date, ia.indicator_id,
+		        ia.min_value, ia.max_value, ia.avg_value, ia.close_value, ia.samples
+		 FROM indicator_aggregates ia
+		 JOIN fund_entities fe ON fe.id = ia.entity_id
+		 WHERE fe.slug = $1
+		   AND ia.resolution = $2
+		   AND ia.indicator_id = ANY($3::int[])
+		   AND ia.close_date >= $4
+		 ORDER BY ia.period_start ASC, ia.indicator_id ASC`,
+		slug, string(res), ids, from)
+	if err != nil {
+		return nil, fmt.Errorf("querying indicator aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var points []AggregatePoint
+	for rows.Next() {
+		var p AggregatePoint
+		if err := rows.Scan(&p.PeriodStart, &p.CloseDate, &p.IndicatorID, &p.Min, &p.Max, &p.Avg, &p.Close, &p.Samples); err != nil {
+			return nil, fmt.Errorf("scanning aggregate row: %w", err)
+		}
+		policy, ok := RoundingOf(p.IndicatorID)
+		if !ok {
+			continue
+		}
+		p.Avg = policy.Apply(p.Avg)
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating aggregates: %w", err)
+	}
+	return points, nil
+}
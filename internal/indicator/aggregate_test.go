@@ -0,0 +1,30 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolutionFor(t *testing.T) {
+	to := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		days int
+		want Resolution
+	}{
+		{30, ResolutionDay},
+		{180, ResolutionDay},
+		{365, ResolutionWeek},
+		{730, ResolutionWeek},
+		{1000, ResolutionMonth},
+	} {
+		if got := ResolutionFor(to.AddDate(0, 0, -tc.days), to); got != tc.want {
+			t.Errorf("%d days: %q, want %q", tc.days, got, tc.want)
+		}
+	}
+	if got := ResolutionFor(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), to); got != ResolutionMonth {
+		t.Errorf("all: %q, want month", got)
+	}
+	if _, err := ParseResolution("hour"); err == nil {
+		t.Error("ParseResolution(hour) succeeded")
+	}
+}
@@ -0,0 +1,34 @@
+package indicator
+
+import (
+	"context"
+
+	"github.com/mtlprog/stat/internal/association"
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// AssociationCalculator emits I28, the Montelibero Association's
+// capitalization (the EURMTL total of its accounts), and I29, the part held
+// by its endowment fund accounts, from HistoricalData.Association. The report
+// pipeline fills it with the latest association snapshot that isn't too old;
+// without one nothing is emitted, and I29 is left out when the snapshot has
+// no endowment accounts.
+type AssociationCalculator struct{}
+
+func init() {
+	registerCalculator("association", 56, func() Calculator { return &AssociationCalculator{} })
+}
+
+func (c *AssociationCalculator) IDs() []int          { return []int{28, 29} }
+func (c *AssociationCalculator) Dependencies() []int { return nil }
+
+func (c *AssociationCalculator) Calculate(_ context.Context, _ domain.FundStructureData, _ map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil || hist.Association == nil {
+		return nil, nil
+	}
+	out := []Indicator{NewIndicator(28, hist.Association.AggregatedTotals.TotalEURMTL, "", "")}
+	if endowment, ok := association.Endowment(*hist.Association); ok {
+		out = append(out, NewIndicator(29, endowment, "", ""))
+	}
+	return out, nil
+}
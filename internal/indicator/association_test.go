@@ -0,0 +1,35 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestAssociationCalculator(t *testing.T) {
+	mtla := &domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{
+			{Name: "MTLA ISSUER", Type: domain.AccountTypeIssuer, TotalEURMTL: decimal.RequireFromString("1000.004")},
+			{Name: "ENDOWMENT", Type: domain.AccountTypeEndowment, TotalEURMTL: decimal.RequireFromString("250.5")},
+		},
+		AggregatedTotals: domain.AggregatedTotals{TotalEURMTL: decimal.RequireFromString("1250.504")},
+	}
+	got, err := (&AssociationCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, &HistoricalData{Association: mtla})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != 28 || got[0].Value.String() != "1250.5" || got[1].ID != 29 || got[1].Value.String() != "250.5" {
+		t.Errorf("got %+v, want I28 = 1250.5 and I29 = 250.5", got)
+	}
+
+	mtla.Accounts = mtla.Accounts[:1]
+	if got, _ := (&AssociationCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, &HistoricalData{Association: mtla}); len(got) != 1 || got[0].ID != 28 {
+		t.Errorf("without endowment accounts: got %+v, want I28 only", got)
+	}
+	if got, _ := (&AssociationCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, &HistoricalData{}); len(got) != 0 {
+		t.Errorf("without a snapshot: got %+v, want none", got)
+	}
+}
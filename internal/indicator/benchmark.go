@@ -0,0 +1,110 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+)
+
+// QuoteHistory looks up stored daily external quotes.
+type QuoteHistory interface {
+	// GetQuoteOn returns the latest daily quote on or before date, or
+	// external.ErrQuoteNotFound.
+	GetQuoteOn(ctx context.Context, symbol string, date time.Time) (external.Quote, error)
+}
+
+// benchmarkWindows are the comparison windows in days.
+var benchmarkWindows = []int{30, 90, 365}
+
+// benchmarkIDs maps each benchmark quote symbol to its indicator IDs, one
+// per benchmarkWindows entry.
+var benchmarkIDs = []struct {
+	symbol string
+	ids    [3]int
+}{
+	{"XLM", [3]int{75, 76, 77}},
+	{"BTC", [3]int{78, 79, 80}},
+}
+
+// benchmarkMaxQuoteGap is how far a stored quote may predate the day it
+// stands for. quote_history has one row per `stat quote` day; an older row
+// means the quotes weren't collected then and the comparison would span a
+// different window.
+const benchmarkMaxQuoteGap = 7 * 24 * time.Hour
+
+// BenchmarkCalculator emits I75–I80: the return of the share book value (I8)
+// over 30, 90 and 365 days minus the return of simply holding XLM (I75–I77)
+// or BTC (I78–I80) over the same window, in percentage points. Book values
+// come from stored I8, benchmark prices from the stored daily quotes. A
+// window without both is left out; without HistoricalData.Quotes and Date
+// (deterministic recomputes, fixtures) nothing is emitted.
+type BenchmarkCalculator struct{}
+
+func init() {
+	registerCalculator("benchmark", 59, func() Calculator { return &BenchmarkCalculator{} })
+}
+
+func (c *BenchmarkCalculator) IDs() []int          { return []int{75, 76, 77, 78, 79, 80} }
+func (c *BenchmarkCalculator) Dependencies() []int { return []int{8} }
+
+func (c *BenchmarkCalculator) Calculate(ctx context.Context, _ domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil || hist.Quotes == nil || hist.Date.IsZero() {
+		return nil, nil
+	}
+	bookNow := deps[8].Value
+	if !bookNow.IsPositive() {
+		return nil, nil
+	}
+
+	var out []Indicator
+	for i, days := range benchmarkWindows {
+		start := hist.Date.AddDate(0, 0, -days)
+		bookStart, err := lookupIndicatorAt(ctx, hist, 8, start)
+		if err != nil {
+			return nil, err
+		}
+		if !bookStart.IsPositive() {
+			continue
+		}
+		fund := bookNow.Div(bookStart)
+
+		for _, b := range benchmarkIDs {
+			held, ok, err := benchmarkGrowth(ctx, hist, b.symbol, start)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			excess := fund.Sub(held).Mul(decimal.NewFromInt(100))
+			out = append(out, NewIndicator(b.ids[i], excess, "", ""))
+		}
+	}
+	return out, nil
+}
+
+// benchmarkGrowth returns the ratio of symbol's stored quote on hist.Date to
+// the one at start. ok is false when either is missing or too old.
+func benchmarkGrowth(ctx context.Context, hist *HistoricalData, symbol string, start time.Time) (decimal.Decimal, bool, error) {
+	var prices [2]decimal.Decimal
+	for i, date := range []time.Time{start, hist.Date} {
+		q, err := hist.Quotes.GetQuoteOn(ctx, symbol, date)
+		if errors.Is(err, external.ErrQuoteNotFound) {
+			return decimal.Zero, false, nil
+		}
+		if err != nil {
+			return decimal.Zero, false, fmt.Errorf("benchmark quote (symbol=%s, date=%s): %w", symbol, date.Format("2006-01-02"), err)
+		}
+		if date.Sub(q.UpdatedAt) > benchmarkMaxQuoteGap || !q.PriceInEUR.IsPositive() {
+			return decimal.Zero, false, nil
+		}
+		prices[i] = q.PriceInEUR
+	}
+	return prices[1].Div(prices[0]), true, nil
+}
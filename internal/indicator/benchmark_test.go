@@ -0,0 +1,89 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/external"
+)
+
+// datedIndicatorRepo serves GetNearestBefore from per-date I8 values.
+type datedIndicatorRepo struct {
+	stubIndicatorRepoForDividend
+	book map[string]string // YYYY-MM-DD → I8
+}
+
+func (r *datedIndicatorRepo) GetNearestBefore(_ context.Context, _ string, date time.Time) (map[int]Indicator, error) {
+	best := ""
+	for d := range r.book {
+		if d <= date.Format("2006-01-02") && d > best {
+			best = d
+		}
+	}
+	if best == "" {
+		return nil, nil
+	}
+	return map[int]Indicator{8: {ID: 8, Value: decimal.RequireFromString(r.book[best])}}, nil
+}
+
+// datedQuotes serves GetQuoteOn from per-symbol, per-date prices.
+type datedQuotes map[string]map[string]string
+
+func (q datedQuotes) GetQuoteOn(_ context.Context, symbol string, date time.Time) (external.Quote, error) {
+	best := ""
+	for d := range q[symbol] {
+		if d <= date.Format("2006-01-02") && d > best {
+			best = d
+		}
+	}
+	if best == "" {
+		return external.Quote{}, external.ErrQuoteNotFound
+	}
+	at, _ := time.Parse("2006-01-02", best)
+	return external.Quote{Symbol: symbol, PriceInEUR: decimal.RequireFromString(q[symbol][best]), UpdatedAt: at}, nil
+}
+
+func TestBenchmarkCalculator(t *testing.T) {
+	hist := &HistoricalData{
+		Slug: "mtlf",
+		Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		IndicatorRepo: &datedIndicatorRepo{book: map[string]string{
+			"2026-07-03": "0.004", // 90 days back
+			"2026-09-01": "0.005", // 30 days back
+		}},
+		Quotes: datedQuotes{
+			"XLM": {"2026-07-03": "0.2", "2026-09-01": "0.25", "2026-10-01": "0.25"},
+			// BTC quotes stopped in August: no 30-day comparison against the
+			// current price, and the 90-day start is too old to stand for July.
+			"BTC": {"2026-06-01": "50000", "2026-08-01": "60000"},
+		},
+	}
+	deps := map[int]Indicator{8: {ID: 8, Value: decimal.RequireFromString("0.006")}}
+
+	got, err := (&BenchmarkCalculator{}).Calculate(context.Background(), testFundStructureData(), deps, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[int]decimal.Decimal)
+	for _, ind := range got {
+		byID[ind.ID] = ind.Value
+	}
+	// 30d: book +20%, XLM flat. 90d: book +50%, XLM +25%. No 365-day book
+	// value, no usable BTC quotes.
+	want := map[int]string{75: "20", 76: "25"}
+	if len(byID) != len(want) {
+		t.Fatalf("got %v, want %v", byID, want)
+	}
+	for id, v := range want {
+		if !byID[id].Equal(decimal.RequireFromString(v)) {
+			t.Errorf("I%d = %s, want %s", id, byID[id], v)
+		}
+	}
+
+	if got, _ := (&BenchmarkCalculator{}).Calculate(context.Background(), testFundStructureData(), deps, &HistoricalData{}); len(got) != 0 {
+		t.Errorf("without quotes: got %v, want none", got)
+	}
+}
@@ -19,6 +19,10 @@ const bppValue = 24000
 // snapshot date gets the same number.
 type BPPCalculator struct{}
 
+func init() {
+	registerCalculator("bpp", 50, func() Calculator { return &BPPCalculator{} })
+}
+
 func (c *BPPCalculator) IDs() []int          { return []int{39} }
 func (c *BPPCalculator) Dependencies() []int { return nil }
 
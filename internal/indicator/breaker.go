@@ -0,0 +1,288 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// StaleError is returned, together with the last stored values of the
+// calculator's indicators, by a calculator whose circuit breaker is open.
+// CalculatePartial keeps those values and records the calculator as a stale
+// Failure; CalculateAll treats it like any other error.
+type StaleError struct {
+	Failures  int    // consecutive failed runs that opened the breaker
+	LastError string // error of the last failed run
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("circuit open after %d consecutive failures (last: %s); serving last stored values", e.Failures, e.LastError)
+}
+
+// BreakerState is the circuit-breaker state of one calculator, kept between
+// runs. OpenedAt is set while the breaker is open.
+type BreakerState struct {
+	Failures  int
+	OpenedAt  *time.Time
+	LastError string
+}
+
+// BreakerStore persists circuit-breaker state per calculator name.
+type BreakerStore interface {
+	Breakers(ctx context.Context, slug string) (map[string]BreakerState, error)
+	SaveBreaker(ctx context.Context, slug, calculator string, state BreakerState) error
+}
+
+// Breaker stops calling a calculator after threshold consecutive failed runs.
+// For cooldown after it opens, the calculator serves the last stored values of
+// its indicators instead; the first run after that tries it again, closing the
+// breaker on success and reopening it on failure.
+type Breaker struct {
+	store     BreakerStore
+	slug      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu     sync.Mutex
+	states map[string]BreakerState
+	dirty  map[string]bool
+}
+
+// NewBreaker creates a Breaker keeping its state in store.
+func NewBreaker(store BreakerStore, slug string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		store:     store,
+		slug:      slug,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		states:    make(map[string]BreakerState),
+		dirty:     make(map[string]bool),
+	}
+}
+
+// load reads the stored state, replacing what the previous run left in memory.
+func (b *Breaker) load(ctx context.Context) error {
+	states, err := b.store.Breakers(ctx, b.slug)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.states = maps.Clone(states)
+	if b.states == nil {
+		b.states = make(map[string]BreakerState)
+	}
+	b.dirty = make(map[string]bool)
+	return nil
+}
+
+// save writes the state of every calculator that changed since load.
+func (b *Breaker) save(ctx context.Context) error {
+	b.mu.Lock()
+	changed := make(map[string]BreakerState, len(b.dirty))
+	for name := range b.dirty {
+		changed[name] = b.states[name]
+	}
+	b.dirty = make(map[string]bool)
+	b.mu.Unlock()
+
+	var errs []error
+	for name, st := range changed {
+		if err := b.store.SaveBreaker(ctx, b.slug, name, st); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// open returns the state of calculator name and whether its breaker is open
+// and still cooling down.
+func (b *Breaker) open(name string) (BreakerState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.states[name]
+	return st, st.OpenedAt != nil && b.now().Before(st.OpenedAt.Add(b.cooldown))
+}
+
+// record updates the state of calculator name after a run that returned err.
+func (b *Breaker) record(name string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.states[name]
+	if err == nil {
+		if st.Failures == 0 && st.OpenedAt == nil {
+			return
+		}
+		if st.OpenedAt != nil {
+			slog.Info("indicator circuit breaker closed", "calculator", name)
+		}
+		b.states[name] = BreakerState{}
+		b.dirty[name] = true
+		return
+	}
+	st.Failures++
+	st.LastError = err.Error()
+	if st.Failures >= b.threshold {
+		now := b.now()
+		st.OpenedAt = &now
+		slog.Error("indicator circuit breaker open", "calculator", name, "failures", st.Failures, "until", now.Add(b.cooldown), "error", err)
+	}
+	b.states[name] = st
+	b.dirty[name] = true
+}
+
+// guardedCalculator applies a calculator's timeout and circuit breaker.
+type guardedCalculator struct {
+	Calculator
+	name    string
+	timeout time.Duration // 0 means none
+	breaker *Breaker      // nil means none
+}
+
+func (g *guardedCalculator) Calculate(ctx context.Context, data domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if g.breaker != nil {
+		if st, open := g.breaker.open(g.name); open {
+			return g.stored(ctx, hist, st)
+		}
+	}
+
+	inds, err := g.calculate(ctx, data, deps, hist)
+	if g.breaker != nil && ctx.Err() == nil {
+		g.breaker.record(g.name, err)
+	}
+	return inds, err
+}
+
+// calculate runs the wrapped calculator, giving up after g.timeout even when
+// the calculator doesn't watch its context.
+func (g *guardedCalculator) calculate(ctx context.Context, data domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if g.timeout <= 0 {
+		return g.Calculator.Calculate(ctx, data, deps, hist)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	type result struct {
+		inds []Indicator
+		err  error
+	}
+	done := make(chan result, 1)
+	deps = maps.Clone(deps) // the registry fills deps again once this returns
+	go func() {
+		inds, err := g.Calculator.Calculate(ctx, data, deps, hist)
+		done <- result{inds, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s: %w", g.timeout, r.err)
+		}
+		return r.inds, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", g.timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// stored returns the last stored values of the calculator's indicators with
+// a StaleError.
+func (g *guardedCalculator) stored(ctx context.Context, hist *HistoricalData, st BreakerState) ([]Indicator, error) {
+	if hist == nil || hist.IndicatorRepo == nil {
+		return nil, fmt.Errorf("circuit open after %d consecutive failures (last: %s); no stored values to serve", st.Failures, st.LastError)
+	}
+	latest, _, err := hist.IndicatorRepo.GetLatest(ctx, hist.Slug)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("circuit open, reading stored values: %w", err)
+	}
+	byID := make(map[int]Indicator, len(latest))
+	for _, ind := range latest {
+		byID[ind.ID] = ind
+	}
+	out := make([]Indicator, 0, len(g.IDs()))
+	for _, id := range g.IDs() {
+		ind, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("circuit open after %d consecutive failures (last: %s); no stored value of I%d", st.Failures, st.LastError, id)
+		}
+		out = append(out, ind)
+	}
+	return out, &StaleError{Failures: st.Failures, LastError: st.LastError}
+}
+
+// ParseTimeouts parses INDICATOR_TIMEOUTS entries of the form
+// "calculator=duration", e.g. "dividend=30s".
+func ParseTimeouts(entries []string) (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration, len(entries))
+	for _, e := range entries {
+		name, value, ok := strings.Cut(e, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("timeout %q: want calculator=duration", e)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("timeout %q: invalid duration", e)
+		}
+		out[strings.TrimSpace(name)] = d
+	}
+	return out, nil
+}
+
+// Breakers returns the stored circuit-breaker state of every calculator of
+// the entity.
+func (r *PgRepository) Breakers(ctx context.Context, slug string) (map[string]BreakerState, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT cb.calculator, cb.failures, cb.opened_at, cb.last_error
+		 FROM calculator_breakers cb
+		 JOIN fund_entities fe ON fe.id = cb.entity_id
+		 WHERE fe.slug = $1`,
+		slug)
+	if err != nil {
+		return nil, fmt.Errorf("querying calculator breakers: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]BreakerState)
+	for rows.Next() {
+		var name string
+		var st BreakerState
+		if err := rows.Scan(&name, &st.Failures, &st.OpenedAt, &st.LastError); err != nil {
+			return nil, fmt.Errorf("scanning calculator breaker row: %w", err)
+		}
+		out[name] = st
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating calculator breakers: %w", err)
+	}
+	return out, nil
+}
+
+// SaveBreaker upserts the circuit-breaker state of one calculator.
+func (r *PgRepository) SaveBreaker(ctx context.Context, slug, calculator string, st BreakerState) error {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO calculator_breakers (entity_id, calculator, failures, opened_at, last_error, updated_at)
+		 SELECT fe.id, $2, $3, $4, $5, CURRENT_TIMESTAMP FROM fund_entities fe WHERE fe.slug = $1
+		 ON CONFLICT (entity_id, calculator) DO UPDATE
+		 SET failures = EXCLUDED.failures, opened_at = EXCLUDED.opened_at,
+		     last_error = EXCLUDED.last_error, updated_at = EXCLUDED.updated_at`,
+		slug, calculator, st.Failures, st.OpenedAt, st.LastError)
+	if err != nil {
+		return fmt.Errorf("saving breaker of %s: %w", calculator, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("saving breaker of %s: %w", calculator, pgx.ErrNoRows)
+	}
+	return nil
+}
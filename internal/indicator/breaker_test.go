@@ -0,0 +1,158 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+type memBreakerStore struct {
+	states map[string]BreakerState
+}
+
+func (s *memBreakerStore) Breakers(_ context.Context, _ string) (map[string]BreakerState, error) {
+	return s.states, nil
+}
+
+func (s *memBreakerStore) SaveBreaker(_ context.Context, _, name string, st BreakerState) error {
+	s.states[name] = st
+	return nil
+}
+
+type latestIndicatorRepo struct {
+	stubIndicatorRepoForDividend
+	latest []Indicator
+}
+
+func (r *latestIndicatorRepo) GetLatest(_ context.Context, _ string) ([]Indicator, time.Time, error) {
+	return r.latest, time.Time{}, nil
+}
+
+// runGuarded runs one CalculatePartial of the guarded calculators with the
+// breaker state loaded and saved around it, like Service.CalculatePartial.
+func runGuarded(t *testing.T, b *Breaker, hist *HistoricalData, calcs ...*guardedCalculator) PartialResult {
+	t.Helper()
+	registry := NewRegistry()
+	for _, c := range calcs {
+		registry.Register(c)
+	}
+	if err := b.load(context.Background()); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	res, err := registry.CalculatePartial(context.Background(), domain.FundStructureData{}, hist)
+	if err != nil {
+		t.Fatalf("CalculatePartial: %v", err)
+	}
+	if err := b.save(context.Background()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	return res
+}
+
+func TestBreakerServesStoredValuesWhileOpen(t *testing.T) {
+	store := &memBreakerStore{states: map[string]BreakerState{}}
+	b := NewBreaker(store, "mtlf", 2, time.Hour)
+	now := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	hist := &HistoricalData{Slug: "mtlf", IndicatorRepo: &latestIndicatorRepo{
+		latest: []Indicator{{ID: 9902, Value: decimal.NewFromInt(42)}},
+	}}
+
+	failing := &stubCalc{ids: []int{9902}, err: errors.New("horizon timeout")}
+	holders := &guardedCalculator{Calculator: failing, name: "holders", breaker: b}
+	dependent := &guardedCalculator{Calculator: &stubCalc{ids: []int{9903}, deps: []int{9902}}, name: "dependent", breaker: b}
+
+	for run := 1; run <= 2; run++ {
+		res := runGuarded(t, b, hist, holders, dependent)
+		if ids := res.UnavailableIDs(); len(ids) != 2 {
+			t.Fatalf("run %d: UnavailableIDs = %v, want [9902 9903]", run, ids)
+		}
+	}
+	if st := store.states["holders"]; st.Failures != 2 || st.OpenedAt == nil {
+		t.Fatalf("stored state = %+v, want open after 2 failures", st)
+	}
+
+	res := runGuarded(t, b, hist, holders, dependent)
+	if len(res.Indicators) != 2 || !res.Indicators[0].Value.Equal(decimal.NewFromInt(42)) {
+		t.Fatalf("indicators = %+v, want stored I9902 and computed I9903", res.Indicators)
+	}
+	if ids := res.StaleIDs(); len(ids) != 1 || ids[0] != 9902 {
+		t.Errorf("StaleIDs = %v, want [9902]", ids)
+	}
+	if len(res.UnavailableIDs()) != 0 {
+		t.Errorf("UnavailableIDs = %v, want none", res.UnavailableIDs())
+	}
+	if f := res.Failures[0]; !f.Stale || !strings.Contains(f.Error, "horizon timeout") {
+		t.Errorf("failure = %+v, want stale with the last error", f)
+	}
+
+	// After the cooldown the calculator runs again and a success closes the breaker.
+	now = now.Add(2 * time.Hour)
+	failing.err = nil
+	res = runGuarded(t, b, hist, holders, dependent)
+	if len(res.Failures) != 0 {
+		t.Errorf("failures = %+v, want none after recovery", res.Failures)
+	}
+	if st := store.states["holders"]; st.Failures != 0 || st.OpenedAt != nil {
+		t.Errorf("stored state = %+v, want closed", st)
+	}
+}
+
+func TestBreakerWithoutStoredValuesFails(t *testing.T) {
+	opened := time.Now()
+	store := &memBreakerStore{states: map[string]BreakerState{
+		"holders": {Failures: 3, OpenedAt: &opened, LastError: "boom"},
+	}}
+	b := NewBreaker(store, "mtlf", 3, time.Hour)
+	hist := &HistoricalData{Slug: "mtlf", IndicatorRepo: &latestIndicatorRepo{}}
+
+	res := runGuarded(t, b, hist, &guardedCalculator{Calculator: &stubCalc{ids: []int{9902}}, name: "holders", breaker: b})
+	if len(res.Failures) != 1 || res.Failures[0].Stale {
+		t.Fatalf("failures = %+v, want one plain failure", res.Failures)
+	}
+	if !strings.Contains(res.Failures[0].Error, "no stored value of I9902") {
+		t.Errorf("error = %q, want the missing stored value", res.Failures[0].Error)
+	}
+}
+
+// stuckCalc ignores its context and returns only when release is closed.
+type stuckCalc struct{ release chan struct{} }
+
+func (c *stuckCalc) IDs() []int          { return []int{9901} }
+func (c *stuckCalc) Dependencies() []int { return nil }
+func (c *stuckCalc) Calculate(_ context.Context, _ domain.FundStructureData, _ map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	<-c.release
+	return nil, nil
+}
+
+func TestGuardedCalculatorTimeout(t *testing.T) {
+	slow := &stuckCalc{release: make(chan struct{})}
+	defer close(slow.release)
+
+	g := &guardedCalculator{Calculator: slow, name: "slow", timeout: 10 * time.Millisecond}
+	_, err := g.Calculate(context.Background(), domain.FundStructureData{}, map[int]Indicator{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+}
+
+func TestParseTimeouts(t *testing.T) {
+	got, err := ParseTimeouts([]string{"dividend=30s", " index = 1m "})
+	if err != nil {
+		t.Fatalf("ParseTimeouts: %v", err)
+	}
+	if got["dividend"] != 30*time.Second || got["index"] != time.Minute {
+		t.Errorf("got %v", got)
+	}
+	for _, bad := range []string{"dividend", "=1s", "dividend=soon", "dividend=-1s"} {
+		if _, err := ParseTimeouts([]string{bad}); err == nil {
+			t.Errorf("ParseTimeouts(%q) succeeded, want an error", bad)
+		}
+	}
+}
@@ -2,7 +2,11 @@ package indicator
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 
@@ -213,6 +217,43 @@ func TestTokenomicsCalculatorMissingLiveMetrics(t *testing.T) {
 	}
 }
 
+func TestLayer0CalculatorBTCRate(t *testing.T) {
+	btc := domain.NewAssetInfo("BTC", "GISSUER")
+	portfolioPrice := "60000"
+	recorded := "61000"
+	data := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{
+			Name:   "DEFI",
+			Tokens: []domain.TokenPriceWithBalance{{Asset: btc, PriceInEURMTL: &portfolioPrice}},
+		}},
+	}
+
+	i61 := func(data domain.FundStructureData) decimal.Decimal {
+		t.Helper()
+		out, err := (&Layer0Calculator{}).Calculate(context.Background(), data, nil, nil)
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		for _, ind := range out {
+			if ind.ID == 61 {
+				return ind.Value
+			}
+		}
+		t.Fatal("I61 missing")
+		return decimal.Zero
+	}
+
+	// Snapshots without a recorded rate use the portfolio's BTC price.
+	if got := i61(data); !got.Equal(decimal.NewFromInt(60000)) {
+		t.Errorf("I61 = %s, want 60000 from the portfolio", got)
+	}
+	// The recorded rate wins, so recomputes don't depend on portfolio pricing.
+	data.LiveMetrics = &domain.FundLiveMetrics{BTCRate: &recorded}
+	if got := i61(data); !got.Equal(decimal.NewFromInt(61000)) {
+		t.Errorf("I61 = %s, want 61000 from LiveMetrics", got)
+	}
+}
+
 func TestNewIndicatorUsesRegistry(t *testing.T) {
 	ind := NewIndicator(1, decimal.NewFromInt(85000), "fallback", "fallback-unit")
 	if ind.Name != "Market Cap EUR" {
@@ -292,3 +333,151 @@ func TestRegistryDependencyCycleDetected(t *testing.T) {
 		t.Error("expected error for dependency cycle, got nil")
 	}
 }
+
+func TestLiquidityCalculator(t *testing.T) {
+	volume, depth := "250", "5000"
+	mtl := domain.NewAssetInfo("MTL", domain.IssuerAddress)
+	data := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{
+			{Tokens: []domain.TokenPriceWithBalance{{Asset: mtl, Balance: "6000"}}},
+			{Tokens: []domain.TokenPriceWithBalance{{Asset: mtl, Balance: "4000"}}},
+		},
+		LiveMetrics: &domain.FundLiveMetrics{MTLAvgDailyVolume: &volume, MTLBidDepthTop5: &depth},
+	}
+	deps := map[int]Indicator{3: {ID: 3, Value: decimal.NewFromInt(200000)}}
+
+	got, err := (&LiquidityCalculator{}).Calculate(context.Background(), data, deps, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byID := map[int]decimal.Decimal{}
+	for _, ind := range got {
+		byID[ind.ID] = ind.Value
+	}
+	if !byID[63].Equal(decimal.NewFromInt(40)) {
+		t.Errorf("I63 = %s, want 40 days for 10000 MTL held", byID[63])
+	}
+	if !byID[64].Equal(decimal.RequireFromString("2.5")) {
+		t.Errorf("I64 = %s, want 2.5%%", byID[64])
+	}
+
+	// Legacy snapshot without liquidity inputs: unknown, so left out.
+	data.LiveMetrics = nil
+	got, err = (&LiquidityCalculator{}).Calculate(context.Background(), data, deps, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("indicators without inputs = %v, want none", got)
+	}
+
+	// Nothing held: no days needed, whatever the volume.
+	got, _ = (&LiquidityCalculator{}).Calculate(context.Background(), domain.FundStructureData{}, deps, nil)
+	if len(got) != 1 || got[0].ID != 63 || !got[0].Value.IsZero() {
+		t.Errorf("indicators without MTL held = %v, want I63 = 0 only", got)
+	}
+}
+
+func TestQualityCalculator(t *testing.T) {
+	data := domain.FundStructureData{Quality: &domain.DataQuality{Score: decimal.RequireFromString("97.5")}}
+	got, err := (&QualityCalculator{}).Calculate(context.Background(), data, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 65 || !got[0].Value.Equal(decimal.RequireFromString("97.5")) {
+		t.Errorf("got %+v, want I65 = 97.5", got)
+	}
+}
+
+// barrierCalc blocks until `arrived` reaches want, so it only completes when
+// its siblings run at the same time.
+type barrierCalc struct {
+	ids     []int
+	deps    []int
+	arrived *sync.WaitGroup
+}
+
+func (c *barrierCalc) IDs() []int          { return c.ids }
+func (c *barrierCalc) Dependencies() []int { return c.deps }
+func (c *barrierCalc) Calculate(ctx context.Context, _ domain.FundStructureData, deps map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	c.arrived.Done()
+	done := make(chan struct{})
+	go func() { c.arrived.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("siblings never ran concurrently")
+	}
+	sum := decimal.Zero
+	for _, dep := range c.deps {
+		sum = sum.Add(deps[dep].Value)
+	}
+	return []Indicator{{ID: c.ids[0], Value: sum.Add(decimal.NewFromInt(1))}}, nil
+}
+
+func TestRegistryRunsIndependentCalculatorsInParallel(t *testing.T) {
+	var level0, level1 sync.WaitGroup
+	level0.Add(2)
+	level1.Add(1)
+	registry := NewRegistry()
+	registry.SetConcurrency(2)
+	registry.Register(&barrierCalc{ids: []int{9903}, deps: []int{9901, 9902}, arrived: &level1})
+	registry.Register(&barrierCalc{ids: []int{9901}, arrived: &level0})
+	registry.Register(&barrierCalc{ids: []int{9902}, arrived: &level0})
+
+	got, err := registry.CalculateAll(context.Background(), domain.FundStructureData{}, nil)
+	if err != nil {
+		t.Fatalf("CalculateAll: %v", err)
+	}
+	if len(got) != 3 || got[2].ID != 9903 || !got[2].Value.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("indicators = %+v, want I9903 = 1 + 1 + 1 after both of its inputs", got)
+	}
+}
+
+// blockingCalc returns only when ctx is cancelled.
+type blockingCalc struct{ ids []int }
+
+func (c *blockingCalc) IDs() []int          { return c.ids }
+func (c *blockingCalc) Dependencies() []int { return nil }
+func (c *blockingCalc) Calculate(ctx context.Context, _ domain.FundStructureData, _ map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRegistryCalculateAllFailureCancelsLevel(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetConcurrency(2)
+	registry.Register(&blockingCalc{ids: []int{9901}})
+	registry.Register(&stubCalc{ids: []int{9902}, err: errors.New("horizon timeout")})
+
+	_, err := registry.CalculateAll(context.Background(), domain.FundStructureData{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "[9902]: horizon timeout") {
+		t.Errorf("CalculateAll() = %v, want the failing calculator's error", err)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	a := &stubCalc{ids: []int{1}}
+	b := &stubCalc{ids: []int{2}, deps: []int{1}}
+	c := &stubCalc{ids: []int{3}}
+	d := &stubCalc{ids: []int{4}, deps: []int{2, 3}}
+	e := &stubCalc{ids: []int{5}, deps: []int{99}} // unregistered dependency
+
+	got := levels([]Calculator{a, b, c, d, e})
+	want := [][]Calculator{{a, c, e}, {b}, {d}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d levels, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("level %d has %d calculators, want %d", i, len(got[i]), len(want[i]))
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Errorf("level %d[%d] = %v, want %v", i, j, got[i][j].IDs(), want[i][j].IDs())
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package indicator
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/holders"
+)
+
+// churnIDs maps each tracked holder asset to its new, exited and net
+// indicator IDs.
+var churnIDs = map[string][3]int{
+	holders.AssetMTL:   {67, 68, 69},
+	holders.AssetMTLAP: {70, 71, 72},
+}
+
+// ChurnCalculator emits I67–I72, the 30-day holder churn of MTL and MTLAP
+// from HistoricalData.Churn. The report pipeline fills it from the stored
+// holder sets; without it (deterministic recomputes, fixtures, or no set
+// 30 days back) nothing is emitted.
+type ChurnCalculator struct{}
+
+func init() {
+	registerCalculator("churn", 57, func() Calculator { return &ChurnCalculator{} })
+}
+
+func (c *ChurnCalculator) IDs() []int          { return []int{67, 68, 69, 70, 71, 72} }
+func (c *ChurnCalculator) Dependencies() []int { return nil }
+
+func (c *ChurnCalculator) Calculate(_ context.Context, _ domain.FundStructureData, _ map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil {
+		return nil, nil
+	}
+	var out []Indicator
+	for _, ch := range hist.Churn {
+		ids, ok := churnIDs[ch.Asset]
+		if !ok {
+			continue
+		}
+		out = append(out,
+			NewIndicator(ids[0], decimal.NewFromInt(int64(len(ch.New))), "", ""),
+			NewIndicator(ids[1], decimal.NewFromInt(int64(len(ch.Exited))), "", ""),
+			NewIndicator(ids[2], decimal.NewFromInt(int64(ch.Net)), "", ""),
+		)
+	}
+	return out, nil
+}
@@ -0,0 +1,32 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/holders"
+)
+
+func TestChurnCalculator(t *testing.T) {
+	hist := &HistoricalData{Churn: []holders.Churn{
+		{Asset: holders.AssetMTL, New: []string{"GA", "GB"}, Exited: []string{"GC"}, Net: 1},
+		{Asset: holders.AssetMTLAP, New: []string{}, Exited: []string{"GX", "GY"}, Net: -2},
+	}}
+	got, err := (&ChurnCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]int64{67: 2, 68: 1, 69: 1, 70: 0, 71: 2, 72: -2}
+	if len(got) != len(want) {
+		t.Fatalf("got %d indicators, want %d", len(got), len(want))
+	}
+	for _, ind := range got {
+		if ind.Value.IntPart() != want[ind.ID] {
+			t.Errorf("I%d = %s, want %d", ind.ID, ind.Value, want[ind.ID])
+		}
+	}
+
+	if got, _ := (&ChurnCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, &HistoricalData{}); len(got) != 0 {
+		t.Errorf("without churn: got %v, want none", got)
+	}
+}
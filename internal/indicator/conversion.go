@@ -0,0 +1,30 @@
+package indicator
+
+import (
+	"context"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// ConversionCalculator emits I73 and I74, the MTLRECT converted to MTL in
+// total and over the last 30 days, from HistoricalData.Conversions. The
+// report pipeline fills it from the stored conversions; without it
+// (deterministic recomputes, fixtures, or a failed scan) nothing is emitted.
+type ConversionCalculator struct{}
+
+func init() {
+	registerCalculator("conversion", 58, func() Calculator { return &ConversionCalculator{} })
+}
+
+func (c *ConversionCalculator) IDs() []int          { return []int{73, 74} }
+func (c *ConversionCalculator) Dependencies() []int { return nil }
+
+func (c *ConversionCalculator) Calculate(_ context.Context, _ domain.FundStructureData, _ map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil || hist.Conversions == nil {
+		return nil, nil
+	}
+	return []Indicator{
+		NewIndicator(73, hist.Conversions.Total, "", ""),
+		NewIndicator(74, hist.Conversions.Period, "", ""),
+	}, nil
+}
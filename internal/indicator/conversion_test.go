@@ -0,0 +1,25 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/conversion"
+)
+
+func TestConversionCalculator(t *testing.T) {
+	hist := &HistoricalData{Conversions: &conversion.Stats{Total: decimal.NewFromInt(1200), Period: decimal.NewFromInt(150), PeriodDays: 30}}
+	got, err := (&ConversionCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != 73 || got[0].Value.IntPart() != 1200 || got[1].ID != 74 || got[1].Value.IntPart() != 150 {
+		t.Errorf("got %+v, want I73 = 1200, I74 = 150", got)
+	}
+
+	if got, _ := (&ConversionCalculator{}).Calculate(context.Background(), testFundStructureData(), nil, &HistoricalData{}); len(got) != 0 {
+		t.Errorf("without conversions: got %v, want none", got)
+	}
+}
@@ -8,6 +8,7 @@ package indicator
 // Layer 0 (per-account totals): I51, I52, I53, I56, I58, I59, I60, I61.
 // Layer 1 derived from Layer 0 only: I3 (sum of subfond totals), I4 (operating balance).
 // Manually-managed constant: I39 (BPP) — value is hard-coded in bpp.go.
+// Data-quality score: I65 — stored in the snapshot, or recomputed from its portfolios.
 //
 // Excluded — even though the calculator runs, the result is meaningless without
 // LiveMetrics, Horizon, or historical snapshots:
@@ -24,6 +25,7 @@ package indicator
 var DeterministicIDs = map[int]bool{
 	3: true, 4: true,
 	39: true,
+	65: true,
 	51: true, 52: true, 53: true,
 	56: true, 58: true, 59: true, 60: true, 61: true,
 }
@@ -23,6 +23,10 @@ import (
 // network IO at this layer.
 type DividendCalculator struct{}
 
+func init() {
+	registerCalculator("dividend", 30, func() Calculator { return &DividendCalculator{} })
+}
+
 func (c *DividendCalculator) IDs() []int          { return []int{11, 15, 17, 34, 43, 54, 55} }
 func (c *DividendCalculator) Dependencies() []int { return []int{5, 10} }
 
@@ -314,6 +314,9 @@ func (s *stubSnapshotRepo) GetNearestBefore(_ context.Context, _ string, target
 func (s *stubSnapshotRepo) List(_ context.Context, _ string, _ int) ([]snapshot.Snapshot, error) {
 	return nil, nil
 }
+func (s *stubSnapshotRepo) ListPage(_ context.Context, _ string, _ snapshot.ListQuery) (*snapshot.Page, error) {
+	return &snapshot.Page{}, nil
+}
 func (s *stubSnapshotRepo) ListMeta(_ context.Context, _ string) ([]snapshot.SnapshotMeta, error) {
 	return nil, nil
 }
@@ -329,7 +332,7 @@ type stubIndicatorRepoForDividend struct {
 	historyErr error
 }
 
-func (s *stubIndicatorRepoForDividend) Save(_ context.Context, _ int, _ time.Time, _ []Indicator) error {
+func (s *stubIndicatorRepoForDividend) Save(_ context.Context, _ int, _ time.Time, _ []Indicator, _ Source) error {
 	return nil
 }
 func (s *stubIndicatorRepoForDividend) GetByDate(_ context.Context, _ string, _ time.Time) ([]Indicator, error) {
@@ -0,0 +1,63 @@
+package indicator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// TestFixtures replays the fixtures written by `stat fixture`: every
+// indicator calculated from a fixture's data must still equal the value
+// recorded when the fixture was cut. An intended change to a calculator
+// means regenerating the affected fixtures.
+func TestFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skip("no fixtures in testdata/fixtures (cut one with stat fixture)")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var f struct {
+				Data     domain.FundStructureData `json:"data"`
+				Expected map[string]string        `json:"expected"`
+			}
+			if err := json.Unmarshal(raw, &f); err != nil {
+				t.Fatalf("decoding fixture: %v", err)
+			}
+
+			inds, err := NewService(nil).CalculateAll(context.Background(), f.Data)
+			if err != nil {
+				t.Fatalf("CalculateAll: %v", err)
+			}
+			got := make(map[string]decimal.Decimal, len(inds))
+			for _, ind := range inds {
+				got[strconv.Itoa(ind.ID)] = ind.Value
+			}
+			for id, want := range f.Expected {
+				value, ok := got[id]
+				if !ok {
+					t.Errorf("I%s: not calculated, want %s", id, want)
+					continue
+				}
+				if !value.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("I%s = %s, want %s", id, value, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package indicator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// IndexID is the Montelibero Index indicator.
+const IndexID = 66
+
+// IndexComponentIDs are the indicators the index can weight: market cap (I1),
+// assets value (I3), monthly dividends (I11) and shareholders (I62).
+var IndexComponentIDs = []int{1, 3, 11, 62}
+
+// IndexConfig defines the Montelibero Index: each component is normalized to
+// its value on BaseDate, and the index is 100 times the weighted mean of the
+// normalized components, so it reads 100 on the base date.
+type IndexConfig struct {
+	BaseDate time.Time               `json:"baseDate"`
+	Weights  map[int]decimal.Decimal `json:"weights"` // by component ID; zero or absent excludes the component
+}
+
+// DefaultIndexConfig is used until weights are stored via the admin API.
+var DefaultIndexConfig = IndexConfig{
+	BaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	Weights: map[int]decimal.Decimal{
+		1:  decimal.RequireFromString("0.3"),
+		3:  decimal.RequireFromString("0.4"),
+		11: decimal.RequireFromString("0.1"),
+		62: decimal.RequireFromString("0.2"),
+	},
+}
+
+// ErrInvalidIndexConfig indicates weights or a base date the index can't use.
+var ErrInvalidIndexConfig = errors.New("invalid index config")
+
+// Validate checks that the weights are non-negative, cover only
+// IndexComponentIDs and are not all zero.
+func (c IndexConfig) Validate() error {
+	if c.BaseDate.IsZero() {
+		return fmt.Errorf("%w: base date is required", ErrInvalidIndexConfig)
+	}
+	total := decimal.Zero
+	for id, w := range c.Weights {
+		if !slices.Contains(IndexComponentIDs, id) {
+			return fmt.Errorf("%w: I%d is not an index component (allowed: %v)", ErrInvalidIndexConfig, id, IndexComponentIDs)
+		}
+		if w.IsNegative() {
+			return fmt.Errorf("%w: weight of I%d is negative", ErrInvalidIndexConfig, id)
+		}
+		total = total.Add(w)
+	}
+	if total.IsZero() {
+		return fmt.Errorf("%w: all weights are zero", ErrInvalidIndexConfig)
+	}
+	return nil
+}
+
+// ComputeIndex returns the index for the component values current, given
+// their values base on the base date. Components without a usable base or
+// current value are left out and the remaining weights renormalized; ok is
+// false when none is left.
+func ComputeIndex(cfg IndexConfig, base, current map[int]decimal.Decimal) (decimal.Decimal, bool) {
+	sum, weights := decimal.Zero, decimal.Zero
+	for _, id := range IndexComponentIDs {
+		w := cfg.Weights[id]
+		b, hasBase := base[id]
+		cur, hasCur := current[id]
+		if !w.IsPositive() || !hasBase || !hasCur || b.IsZero() {
+			continue
+		}
+		sum = sum.Add(w.Mul(cur).Div(b))
+		weights = weights.Add(w)
+	}
+	if weights.IsZero() {
+		return decimal.Zero, false
+	}
+	return sum.Div(weights).Mul(decimal.NewFromInt(100)), true
+}
+
+// IndexCalculator emits I66, the Montelibero Index. It needs the component
+// values on the base date from the indicator repository, so it emits nothing
+// without history (deterministic recomputes, fixtures).
+type IndexCalculator struct{}
+
+func init() {
+	registerCalculator("index", 60, func() Calculator { return &IndexCalculator{} })
+}
+
+func (c *IndexCalculator) IDs() []int          { return []int{IndexID} }
+func (c *IndexCalculator) Dependencies() []int { return IndexComponentIDs }
+
+func (c *IndexCalculator) Calculate(ctx context.Context, _ domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil || hist.IndicatorRepo == nil {
+		return nil, nil
+	}
+	cfg := DefaultIndexConfig
+	if hist.Index != nil {
+		cfg = *hist.Index
+	}
+
+	base, err := hist.IndicatorRepo.GetNearestBefore(ctx, hist.Slug, cfg.BaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("loading index base values (%s): %w", cfg.BaseDate.Format("2006-01-02"), err)
+	}
+	baseValues := make(map[int]decimal.Decimal, len(base))
+	for id, ind := range base {
+		baseValues[id] = ind.Value
+	}
+	current := make(map[int]decimal.Decimal, len(deps))
+	for id, ind := range deps {
+		current[id] = ind.Value
+	}
+
+	value, ok := ComputeIndex(cfg, baseValues, current)
+	if !ok {
+		return nil, nil
+	}
+	return []Indicator{NewIndicator(IndexID, value, "", "")}, nil
+}
+
+// GetIndexConfig returns the stored index definition of the entity, or
+// DefaultIndexConfig when none is stored.
+func (r *PgRepository) GetIndexConfig(ctx context.Context, slug string) (IndexConfig, error) {
+	var cfg IndexConfig
+	var weights []byte
+	err := r.pool.QueryRow(ctx,
+		`SELECT ic.base_date, ic.weights
+		 FROM index_config ic
+		 JOIN fund_entities fe ON fe.id = ic.entity_id
+		 WHERE fe.slug = $1`, slug).Scan(&cfg.BaseDate, &weights)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultIndexConfig, nil
+	}
+	if err != nil {
+		return IndexConfig{}, fmt.Errorf("loading index config: %w", err)
+	}
+	if err := json.Unmarshal(weights, &cfg.Weights); err != nil {
+		return IndexConfig{}, fmt.Errorf("decoding index weights: %w", err)
+	}
+	return cfg, nil
+}
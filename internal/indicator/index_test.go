@@ -0,0 +1,60 @@
+package indicator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeIndex(t *testing.T) {
+	d := decimal.RequireFromString
+	cfg := IndexConfig{
+		BaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Weights:  map[int]decimal.Decimal{1: d("0.5"), 3: d("0.5")},
+	}
+
+	tests := []struct {
+		name          string
+		base, current map[int]decimal.Decimal
+		want          string
+		wantOK        bool
+	}{
+		{"base date reads 100", map[int]decimal.Decimal{1: d("200"), 3: d("50")}, map[int]decimal.Decimal{1: d("200"), 3: d("50")}, "100", true},
+		{"weighted mean", map[int]decimal.Decimal{1: d("100"), 3: d("100")}, map[int]decimal.Decimal{1: d("150"), 3: d("50")}, "100", true},
+		{"growth", map[int]decimal.Decimal{1: d("100"), 3: d("100")}, map[int]decimal.Decimal{1: d("120"), 3: d("110")}, "115", true},
+		{"missing base renormalizes", map[int]decimal.Decimal{1: d("100")}, map[int]decimal.Decimal{1: d("130"), 3: d("10")}, "130", true},
+		{"zero base skipped", map[int]decimal.Decimal{1: d("0"), 3: d("100")}, map[int]decimal.Decimal{1: d("5"), 3: d("90")}, "90", true},
+		{"unweighted component ignored", map[int]decimal.Decimal{62: d("10")}, map[int]decimal.Decimal{62: d("20")}, "0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ComputeIndex(cfg, tt.base, tt.current)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !got.Equal(d(tt.want)) {
+				t.Errorf("index = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexConfigValidate(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := decimal.RequireFromString
+	if err := DefaultIndexConfig.Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+	for name, cfg := range map[string]IndexConfig{
+		"no base date":  {Weights: map[int]decimal.Decimal{1: d("1")}},
+		"not component": {BaseDate: base, Weights: map[int]decimal.Decimal{2: d("1")}},
+		"negative":      {BaseDate: base, Weights: map[int]decimal.Decimal{1: d("-1"), 3: d("2")}},
+		"all zero":      {BaseDate: base, Weights: map[int]decimal.Decimal{1: d("0")}},
+	} {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidIndexConfig) {
+			t.Errorf("%s: err = %v, want ErrInvalidIndexConfig", name, err)
+		}
+	}
+}
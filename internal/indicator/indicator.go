@@ -2,21 +2,28 @@ package indicator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 
+	"github.com/mtlprog/stat/internal/conversion"
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/holders"
 	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/subfond"
 )
 
 // IndicatorMeta holds the canonical name, unit, description, and display
 // precision for an indicator. Precision is the number of decimal places to
-// which NewIndicator rounds the raw computed value — it's the single source
-// of truth for rounding across all sinks (DB, API JSON, IND_ALL, IND_MAIN,
-// MONITORING).
+// which NewIndicator rounds the raw computed value, by Rounding (default
+// RoundHalfUp, the spreadsheet convention) — it's the single source of truth
+// for rounding across all sinks (DB, API JSON, IND_ALL, IND_MAIN,
+// MONITORING). See RoundingOf.
 //
 // Precision policy by semantic class:
 //   - 0: integer counts (shareholders, share quantities, BTC EUR rate)
@@ -31,6 +38,7 @@ type IndicatorMeta struct {
 	Unit        string
 	Description string
 	Precision   int32
+	Rounding    RoundingMode // empty means RoundHalfUp
 }
 
 // indicatorRegistry maps indicator IDs to their canonical metadata.
@@ -57,11 +65,14 @@ var indicatorRegistry = map[int]IndicatorMeta{
 	25: {Name: "EURMTL Daily Volume", Unit: "EURMTL", Description: "Оборот токеномики за прошлые сутки", Precision: 2},
 	26: {Name: "EURMTL Payment Total", Unit: "EURMTL", Description: "Совокупный оборот токеномики (кумулятивно)", Precision: 2},
 	27: {Name: "More-one-share Shareholders", Unit: "accounts", Description: "Число Stellar-аккаунтов, на которых не менее 1 MTL или MTLRECT", Precision: 0},
+	28: {Name: "Montelibero Association Capitalization", Unit: "EURMTL", Description: "Стоимость активов казны Ассоциации Монтелиберо (MTLA) по последнему снимку ассоциации", Precision: 2},
+	29: {Name: "Association Endowment Fund", Unit: "EURMTL", Description: "Стоимость активов эндаумент-фонда Ассоциации Монтелиберо по последнему снимку ассоциации", Precision: 2},
 	30: {Name: "Price/Book Ratio", Unit: "ratio", Description: "Ценность акции от её балансовой стоимости", Precision: 2},
 	34: {Name: "Price/Earnings Ratio", Unit: "ratio", Description: "Относительная ценность акции по дивиденду", Precision: 2},
 	39: {Name: "Bitcoin Purchase Price", Unit: "EURMTL", Description: "Цена закупа биткоина (BPP) — пока что задаётся вручную", Precision: 2},
 	40: {Name: "Association Participants", Unit: "accounts", Description: "Число участников Ассоциации Монтелиберо, держателей MTLAP", Precision: 0},
 	43: {Name: "Total ROI", Unit: "%", Description: "Общая рентабельность инвестиций", Precision: 2},
+	47: {Name: "Value at Risk", Unit: "%", Description: "Потеря балансовой стоимости акции, которая не будет превышена с заданной вероятностью за заданный горизонт, в процентах", Precision: 2},
 	49: {Name: "MTLRECT Market Price", Unit: "EURMTL", Description: "Рыночная цена MTLRECT", Precision: 7},
 	51: {Name: "DEFI Total Value", Unit: "EURMTL", Description: "Стоимость активов субфонда DEFI", Precision: 2},
 	52: {Name: "MCITY Total Value", Unit: "EURMTL", Description: "Стоимость активов субфонда MCITY", Precision: 2},
@@ -74,6 +85,30 @@ var indicatorRegistry = map[int]IndicatorMeta{
 	60: {Name: "ADMIN Total Value", Unit: "EURMTL", Description: "Стоимость активов счёта ADMIN", Precision: 2},
 	61: {Name: "BTC Rate", Unit: "EUR", Description: "Курс BTC в EUR", Precision: 0},
 	62: {Name: "Shareholders", Unit: "accounts", Description: "Число Stellar-аккаунтов с ненулевым балансом MTL или MTLRECT", Precision: 0},
+	63: {Name: "MTL Days to Liquidate", Unit: "days", Description: "Сколько дней среднего оборота DEX нужно, чтобы продать MTL на счетах фонда", Precision: 2},
+	64: {Name: "Bid Depth Coverage", Unit: "%", Description: "Доля стоимости активов, покрытая глубиной топ-5 заявок на покупку MTL", Precision: 2},
+	65: {Name: "Data Quality Score", Unit: "%", Description: "Доля токенов фонда, для которых удалось определить стоимость в EURMTL", Precision: 2},
+	66: {Name: "Montelibero Index", Unit: "points", Description: "Взвешенный индекс капитализации, активов, дивидендов и числа акционеров; 100 на базовую дату", Precision: 2},
+	67: {Name: "New MTL Holders (30d)", Unit: "accounts", Description: "Аккаунты, ставшие держателями MTL или MTLRECT за последние 30 дней", Precision: 0},
+	68: {Name: "Exited MTL Holders (30d)", Unit: "accounts", Description: "Аккаунты, переставшие держать MTL и MTLRECT за последние 30 дней", Precision: 0},
+	69: {Name: "MTL Holders Net Change (30d)", Unit: "accounts", Description: "Изменение числа держателей MTL или MTLRECT за последние 30 дней", Precision: 0},
+	70: {Name: "New MTLAP Holders (30d)", Unit: "accounts", Description: "Аккаунты, ставшие держателями MTLAP за последние 30 дней", Precision: 0},
+	71: {Name: "Exited MTLAP Holders (30d)", Unit: "accounts", Description: "Аккаунты, переставшие держать MTLAP за последние 30 дней", Precision: 0},
+	72: {Name: "MTLAP Holders Net Change (30d)", Unit: "accounts", Description: "Изменение числа держателей MTLAP за последние 30 дней", Precision: 0},
+	73: {Name: "MTLRECT Converted", Unit: "MTLRECT", Description: "Количество MTLRECT, конвертированных в MTL (кумулятивно)", Precision: 0},
+	74: {Name: "MTLRECT Conversion Velocity (30d)", Unit: "MTLRECT", Description: "Количество MTLRECT, конвертированных в MTL за последние 30 дней", Precision: 0},
+	75: {Name: "Excess Return vs XLM (30d)", Unit: "pp", Description: "Рост балансовой стоимости акции за 30 дней минус рост курса XLM, в процентных пунктах", Precision: 2},
+	76: {Name: "Excess Return vs XLM (90d)", Unit: "pp", Description: "Рост балансовой стоимости акции за 90 дней минус рост курса XLM, в процентных пунктах", Precision: 2},
+	77: {Name: "Excess Return vs XLM (365d)", Unit: "pp", Description: "Рост балансовой стоимости акции за 365 дней минус рост курса XLM, в процентных пунктах", Precision: 2},
+	78: {Name: "Excess Return vs BTC (30d)", Unit: "pp", Description: "Рост балансовой стоимости акции за 30 дней минус рост курса BTC, в процентных пунктах", Precision: 2},
+	79: {Name: "Excess Return vs BTC (90d)", Unit: "pp", Description: "Рост балансовой стоимости акции за 90 дней минус рост курса BTC, в процентных пунктах", Precision: 2},
+	80: {Name: "Excess Return vs BTC (365d)", Unit: "pp", Description: "Рост балансовой стоимости акции за 365 дней минус рост курса BTC, в процентных пунктах", Precision: 2},
+	81: {Name: "Outstanding Liabilities", Unit: "EURMTL", Description: "Номинальная стоимость выпущенных фондом долговых токенов (MFBond) в обращении", Precision: 2},
+	82: {Name: "Net Assets", Unit: "EURMTL", Description: "Стоимость активов фонда за вычетом обязательств по долговым токенам", Precision: 2},
+	83: {Name: "DEFI ROI (30d)", Unit: "%", Description: "Доходность субфонда DEFI за 30 дней за вычетом переводов с другими счетами фонда", Precision: 2},
+	84: {Name: "MCITY ROI (30d)", Unit: "%", Description: "Доходность субфонда MCITY за 30 дней за вычетом переводов с другими счетами фонда", Precision: 2},
+	85: {Name: "MABIZ ROI (30d)", Unit: "%", Description: "Доходность субфонда MABIZ за 30 дней за вычетом переводов с другими счетами фонда", Precision: 2},
+	86: {Name: "BOSS ROI (30d)", Unit: "%", Description: "Доходность субфонда BOSS за 30 дней за вычетом переводов с другими счетами фонда", Precision: 2},
 }
 
 // PrecisionOf returns the display precision (decimal places) for an indicator
@@ -87,6 +122,18 @@ func PrecisionOf(id int) int32 {
 	return 0
 }
 
+// UnitOf returns the unit of an indicator ID, or "" for unregistered IDs.
+// The export package uses it to pick currency number formats.
+func UnitOf(id int) string {
+	return indicatorRegistry[id].Unit
+}
+
+// MetaOf returns the registry metadata of an indicator ID.
+func MetaOf(id int) (IndicatorMeta, bool) {
+	meta, ok := indicatorRegistry[id]
+	return meta, ok
+}
+
 // IsRegistered reports whether `id` is a known indicator. Used by the
 // repository to filter out deprecated IDs that still have rows in
 // `fund_indicators` but are no longer part of the registry — surfacing those
@@ -96,6 +143,16 @@ func IsRegistered(id int) bool {
 	return ok
 }
 
+// RegisteredIDs returns every registered indicator ID in ascending order.
+func RegisteredIDs() []int {
+	ids := make([]int, 0, len(indicatorRegistry))
+	for id := range indicatorRegistry {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
 // Indicator represents a calculated statistical indicator.
 type Indicator struct {
 	ID          int             `json:"id"`
@@ -106,16 +163,17 @@ type Indicator struct {
 }
 
 // NewIndicator creates an indicator using the canonical metadata from the
-// registry. The value is rounded to meta.Precision so every downstream sink
-// (DB / API / sheets) sees the same display-precision number — calculators
-// don't carry per-indicator rounding logic. Falls back to the provided name
-// and unit (with no rounding) if the ID is not registered.
+// registry. The value is rounded by the ID's RoundingPolicy so every
+// downstream sink (DB / API / sheets) sees the same display-precision number
+// — calculators don't carry per-indicator rounding logic. Falls back to the
+// provided name and unit (with no rounding) if the ID is not registered.
 func NewIndicator(id int, value decimal.Decimal, name, unit string) Indicator {
 	if meta, ok := indicatorRegistry[id]; ok {
+		p, _ := RoundingOf(id)
 		return Indicator{
 			ID:          id,
 			Name:        meta.Name,
-			Value:       value.Round(meta.Precision),
+			Value:       p.Apply(value),
 			Unit:        meta.Unit,
 			Description: meta.Description,
 		}
@@ -141,17 +199,34 @@ type HistoricalData struct {
 	IndicatorRepo Repository
 	Slug          string
 	Calculus      func(ctx context.Context, data domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error)
+	Index         *IndexConfig              // Montelibero Index definition; nil uses DefaultIndexConfig
+	Churn         []holders.Churn           // 30-day holder churn per asset (I67–I72); nil emits none
+	Conversions   *conversion.Stats         // MTLRECT → MTL conversion totals (I73, I74); nil emits none
+	Quotes        QuoteHistory              // stored daily quotes for the benchmarks (I75–I80); nil emits none
+	Association   *domain.FundStructureData // latest association snapshot (I28, I29); nil emits none
+	SubfondFlows  *subfond.Window           // subfond flows of the ROI window ending on Date (I83–I86); nil emits none
+	Date          time.Time                 // snapshot date the benchmark windows end on
 }
 
 // Registry manages the execution of calculators in dependency order.
+// Calculators whose dependencies are all computed run in parallel, at most
+// concurrency at a time.
 type Registry struct {
 	calculators   []Calculator
 	registeredIDs map[int]bool
+	concurrency   int
 }
 
-// NewRegistry creates a new indicator registry.
+// NewRegistry creates a new indicator registry that runs one calculator at a
+// time; see SetConcurrency.
 func NewRegistry() *Registry {
-	return &Registry{registeredIDs: make(map[int]bool)}
+	return &Registry{registeredIDs: make(map[int]bool), concurrency: 1}
+}
+
+// SetConcurrency sets how many independent calculators may run at once
+// (values below 1 mean 1).
+func (r *Registry) SetConcurrency(n int) {
+	r.concurrency = max(n, 1)
 }
 
 // Register adds a calculator to the registry.
@@ -166,7 +241,8 @@ func (r *Registry) Register(calc Calculator) {
 	r.calculators = append(r.calculators, calc)
 }
 
-// CalculateAll runs all registered calculators in dependency order.
+// CalculateAll runs all registered calculators in dependency order. The first
+// calculator error cancels the rest of its level and aborts the run.
 func (r *Registry) CalculateAll(ctx context.Context, data domain.FundStructureData, hist *HistoricalData) ([]Indicator, error) {
 	ordered, err := r.topologicalSort()
 	if err != nil {
@@ -176,22 +252,37 @@ func (r *Registry) CalculateAll(ctx context.Context, data domain.FundStructureDa
 	computed := make(map[int]Indicator)
 	var allIndicators []Indicator
 
-	for _, calc := range ordered {
+	for _, level := range levels(ordered) {
 		// Check dependencies are satisfied
-		for _, dep := range calc.Dependencies() {
-			if _, ok := computed[dep]; !ok {
-				return nil, fmt.Errorf("indicator %v depends on I%d which is not yet computed", calc.IDs(), dep)
+		for _, calc := range level {
+			for _, dep := range calc.Dependencies() {
+				if _, ok := computed[dep]; !ok {
+					return nil, fmt.Errorf("indicator %v depends on I%d which is not yet computed", calc.IDs(), dep)
+				}
 			}
 		}
 
-		indicators, err := calc.Calculate(ctx, data, computed, hist)
-		if err != nil {
-			return nil, fmt.Errorf("calculating indicators %v: %w", calc.IDs(), err)
+		levelCtx, cancel := context.WithCancel(ctx)
+		outcomes := r.runLevel(levelCtx, level, data, computed, hist, cancel)
+		cancel()
+		var failed error
+		for i, o := range outcomes {
+			if o.err == nil {
+				continue
+			}
+			// Siblings cancelled by the first failure only report the cancellation.
+			if failed == nil || errors.Is(failed, context.Canceled) && ctx.Err() == nil {
+				failed = fmt.Errorf("calculating indicators %v: %w", level[i].IDs(), o.err)
+			}
 		}
-
-		for _, ind := range indicators {
-			computed[ind.ID] = ind
-			allIndicators = append(allIndicators, ind)
+		if failed != nil {
+			return nil, failed
+		}
+		for _, o := range outcomes {
+			for _, ind := range o.indicators {
+				computed[ind.ID] = ind
+				allIndicators = append(allIndicators, ind)
+			}
 		}
 	}
 
@@ -202,6 +293,77 @@ func (r *Registry) CalculateAll(ctx context.Context, data domain.FundStructureDa
 	return allIndicators, nil
 }
 
+// outcome is what one calculator of a level produced.
+type outcome struct {
+	indicators []Indicator
+	err        error
+}
+
+// runLevel calculates every calculator of one level, at most r.concurrency at
+// a time, and returns their outcomes in level order. deps is only read while
+// the level runs. When onError is set it's called after the first failure.
+func (r *Registry) runLevel(ctx context.Context, level []Calculator, data domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData, onError func()) []outcome {
+	out := make([]outcome, len(level))
+	run := func(i int) {
+		if err := ctx.Err(); err != nil {
+			out[i].err = err
+			return
+		}
+		out[i].indicators, out[i].err = level[i].Calculate(ctx, data, deps, hist)
+		if out[i].err != nil && onError != nil {
+			onError()
+		}
+	}
+	if r.concurrency <= 1 || len(level) == 1 {
+		for i := range level {
+			run(i)
+		}
+		return out
+	}
+
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+	for i := range level {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				run(i)
+			case <-ctx.Done():
+				out[i].err = ctx.Err()
+			}
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// levels groups sorted calculators so that each depends only on calculators
+// in earlier levels. The calculators of one level are independent of each
+// other and keep their sorted order.
+func levels(ordered []Calculator) [][]Calculator {
+	levelOf := make(map[int]int) // indicator ID → level of its calculator
+	var out [][]Calculator
+	for _, calc := range ordered {
+		lvl := 0
+		for _, dep := range calc.Dependencies() {
+			if l, ok := levelOf[dep]; ok && l+1 > lvl {
+				lvl = l + 1
+			}
+		}
+		for _, id := range calc.IDs() {
+			levelOf[id] = lvl
+		}
+		if lvl == len(out) {
+			out = append(out, nil)
+		}
+		out[lvl] = append(out[lvl], calc)
+	}
+	return out
+}
+
 // topologicalSort orders calculators so dependencies come first.
 // Returns an error if a dependency cycle is detected.
 func (r *Registry) topologicalSort() ([]Calculator, error) {
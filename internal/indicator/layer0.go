@@ -13,6 +13,10 @@ import (
 // Layer0Calculator computes per-account total values (I51-I53, I56, I58-I60) and BTC rate (I61).
 type Layer0Calculator struct{}
 
+func init() {
+	registerCalculator("layer0", 0, func() Calculator { return &Layer0Calculator{} })
+}
+
 func (c *Layer0Calculator) IDs() []int          { return []int{51, 52, 53, 56, 58, 59, 60, 61} }
 func (c *Layer0Calculator) Dependencies() []int { return nil }
 
@@ -48,8 +52,15 @@ func (c *Layer0Calculator) Calculate(_ context.Context, data domain.FundStructur
 		}
 	}
 
-	// I61: BTC rate — from BTC/WBTC market price in portfolio tokens
+	// I61: BTC rate — the quote recorded in the snapshot's live metrics, so a
+	// recompute uses the rate the snapshot was taken with. Snapshots from
+	// before it was recorded fall back to the BTC/WBTC price in portfolio tokens.
 	btcPrice := findBTCPrice(allAccounts)
+	if data.LiveMetrics != nil && data.LiveMetrics.BTCRate != nil {
+		if rate := domain.SafeParse(*data.LiveMetrics.BTCRate); rate.IsPositive() {
+			btcPrice = rate
+		}
+	}
 	indicators = append(indicators, NewIndicator(61, btcPrice, "", ""))
 
 	return indicators, nil
@@ -15,6 +15,10 @@ import (
 // network calls and has no Horizon dependency.
 type Layer1Calculator struct{}
 
+func init() {
+	registerCalculator("layer1", 10, func() Calculator { return &Layer1Calculator{} })
+}
+
 func (c *Layer1Calculator) IDs() []int          { return []int{3, 4, 5, 6, 7, 10, 49} }
 func (c *Layer1Calculator) Dependencies() []int { return []int{51, 52, 53, 58, 59, 60} }
 
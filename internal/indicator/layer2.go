@@ -11,6 +11,10 @@ import (
 // Layer2Calculator computes ratio indicators (I1, I2, I8, I30).
 type Layer2Calculator struct{}
 
+func init() {
+	registerCalculator("layer2", 20, func() Calculator { return &Layer2Calculator{} })
+}
+
 func (c *Layer2Calculator) IDs() []int          { return []int{1, 2, 8, 30} }
 func (c *Layer2Calculator) Dependencies() []int { return []int{3, 5, 10, 61} }
 
@@ -0,0 +1,40 @@
+package indicator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// LiabilityCalculator emits I81, the face value of the fund's outstanding
+// obligation tokens recorded in the snapshot (data.Liabilities, filled by
+// internal/liability), and I82, net assets: I3 minus I81. A snapshot without
+// liabilities has none registered, so I81 is zero and I82 equals I3. An
+// obligation whose supply couldn't be fetched fails the calculator rather
+// than understating the debt.
+type LiabilityCalculator struct{}
+
+func init() {
+	registerCalculator("liability", 15, func() Calculator { return &LiabilityCalculator{} })
+}
+
+func (c *LiabilityCalculator) IDs() []int          { return []int{81, 82} }
+func (c *LiabilityCalculator) Dependencies() []int { return []int{3} }
+
+func (c *LiabilityCalculator) Calculate(_ context.Context, data domain.FundStructureData, deps map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	i81 := decimal.Zero
+	for _, l := range data.Liabilities {
+		if l.Error != "" {
+			return nil, fmt.Errorf("supply of %s unavailable: %s", l.Asset.Code, l.Error)
+		}
+		i81 = i81.Add(l.Value)
+	}
+
+	return []Indicator{
+		NewIndicator(81, i81, "", ""),
+		NewIndicator(82, deps[3].Value.Sub(i81), "", ""),
+	}, nil
+}
@@ -0,0 +1,34 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestLiabilityCalculator(t *testing.T) {
+	deps := map[int]Indicator{3: NewIndicator(3, decimal.NewFromInt(10000), "", "")}
+	data := testFundStructureData()
+	data.Liabilities = []domain.Liability{{Value: decimal.NewFromInt(1700)}, {Value: decimal.NewFromInt(300)}}
+
+	got, err := (&LiabilityCalculator{}).Calculate(context.Background(), data, deps, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Value.IntPart() != 2000 || got[1].Value.IntPart() != 8000 {
+		t.Errorf("got %+v, want I81 = 2000, I82 = 8000", got)
+	}
+
+	data.Liabilities = append(data.Liabilities, domain.Liability{Asset: domain.NewAssetInfo("MFB2", domain.IssuerAddress), Error: "horizon 503"})
+	if _, err := (&LiabilityCalculator{}).Calculate(context.Background(), data, deps, nil); err == nil {
+		t.Error("expected an error for an unavailable supply")
+	}
+
+	got, _ = (&LiabilityCalculator{}).Calculate(context.Background(), testFundStructureData(), deps, nil)
+	if !got[0].Value.IsZero() || got[1].Value.IntPart() != 10000 {
+		t.Errorf("without liabilities: got %+v, want I81 = 0, I82 = I3", got)
+	}
+}
@@ -0,0 +1,65 @@
+package indicator
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// LiquidityCalculator computes market-liquidity indicators for the fund's
+// share from LiveMetrics inputs fetched by metrics.EnrichMetrics:
+//
+//   - I63: days of average DEX volume needed to sell the fund's own MTL
+//     position (MTL held by the fund accounts / 30-day average daily
+//     MTL/EURMTL volume).
+//   - I64: top-5 MTL/EURMTL bid depth as a percentage of Assets Value (I3).
+//
+// An indicator whose input is unknown (older snapshots, fetch failure) or
+// whose denominator is zero is left out rather than zeroed, so a partial run
+// reports it unavailable. I63 is 0 when the fund holds no MTL.
+type LiquidityCalculator struct{}
+
+func init() {
+	registerCalculator("liquidity", 45, func() Calculator { return &LiquidityCalculator{} })
+}
+
+func (c *LiquidityCalculator) IDs() []int          { return []int{63, 64} }
+func (c *LiquidityCalculator) Dependencies() []int { return []int{3} }
+
+func (c *LiquidityCalculator) Calculate(_ context.Context, data domain.FundStructureData, deps map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	m := data.LiveMetrics
+	if m == nil {
+		m = &domain.FundLiveMetrics{}
+	}
+
+	var out []Indicator
+	if held := fundMTLHeld(data); held.IsZero() {
+		out = append(out, NewIndicator(63, decimal.Zero, "", ""))
+	} else if m.MTLAvgDailyVolume != nil {
+		if volume := domain.SafeParse(*m.MTLAvgDailyVolume); volume.IsPositive() {
+			out = append(out, NewIndicator(63, held.Div(volume), "", ""))
+		}
+	}
+
+	if i3 := deps[3].Value; m.MTLBidDepthTop5 != nil && i3.IsPositive() { // Assets Value
+		depth := domain.SafeParse(*m.MTLBidDepthTop5)
+		out = append(out, NewIndicator(64, depth.Div(i3).Mul(decimal.NewFromInt(100)), "", ""))
+	}
+	return out, nil
+}
+
+// fundMTLHeld sums the MTL balances of the fund accounts counted in the
+// fund totals (issuer, sub-funds, operational).
+func fundMTLHeld(data domain.FundStructureData) decimal.Decimal {
+	total := decimal.Zero
+	for _, acc := range data.Accounts {
+		for _, token := range acc.Tokens {
+			if token.Asset.Code == "MTL" && token.Asset.Issuer == domain.IssuerAddress {
+				total = total.Add(domain.SafeParse(token.Balance))
+			}
+		}
+	}
+	return total
+}
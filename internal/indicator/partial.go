@@ -0,0 +1,130 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/samber/lo"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Failure records a calculator that produced no indicators in a partial run,
+// or the IDs a successful calculator left out because their inputs were
+// unknown. Skipped is set when the calculator never ran because one of its
+// inputs was unavailable; Error then names the missing input. Stale is set when the
+// calculator's circuit breaker is open and its last stored values were served
+// in its place (see StaleError).
+type Failure struct {
+	Calculator string `json:"calculator,omitempty"`
+	IDs        []int  `json:"ids"`
+	Error      string `json:"error"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Stale      bool   `json:"stale,omitempty"`
+}
+
+// PartialResult is the outcome of CalculatePartial. Indicators owned by a
+// failed or skipped calculator are absent from Indicators — never zero-filled.
+// A stale calculator's indicators are present with their last stored values.
+type PartialResult struct {
+	Indicators []Indicator
+	Failures   []Failure
+}
+
+// UnavailableIDs returns the sorted IDs of every indicator that was not computed.
+func (p PartialResult) UnavailableIDs() []int {
+	var ids []int
+	for _, f := range p.Failures {
+		if !f.Stale {
+			ids = append(ids, f.IDs...)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// StaleIDs returns the sorted IDs of every indicator served from its last
+// stored value.
+func (p PartialResult) StaleIDs() []int {
+	var ids []int
+	for _, f := range p.Failures {
+		if f.Stale {
+			ids = append(ids, f.IDs...)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// CalculatePartial runs all registered calculators like CalculateAll, but a
+// calculator error no longer aborts the run or its level: the calculator is
+// recorded as a Failure and every calculator depending on its IDs is skipped.
+// IDs a calculator leaves out of its result are recorded as a Failure too.
+// A calculator returning a StaleError is recorded as a stale Failure, but its
+// indicators are kept and its dependents run. Only a dependency cycle or a
+// cancelled ctx returns an error.
+func (r *Registry) CalculatePartial(ctx context.Context, data domain.FundStructureData, hist *HistoricalData) (PartialResult, error) {
+	ordered, err := r.topologicalSort()
+	if err != nil {
+		return PartialResult{}, fmt.Errorf("sorting calculators: %w", err)
+	}
+
+	computed := make(map[int]Indicator)
+	var res PartialResult
+
+	for _, level := range levels(ordered) {
+		if err := ctx.Err(); err != nil {
+			return PartialResult{}, err
+		}
+
+		var runnable []Calculator
+		for _, calc := range level {
+			missing, hasMissing := lo.Find(calc.Dependencies(), func(dep int) bool {
+				_, ok := computed[dep]
+				return !ok
+			})
+			if hasMissing {
+				res.Failures = append(res.Failures, Failure{
+					IDs:     calc.IDs(),
+					Error:   fmt.Sprintf("depends on I%d which is unavailable", missing),
+					Skipped: true,
+				})
+				continue
+			}
+			runnable = append(runnable, calc)
+		}
+
+		outcomes := r.runLevel(ctx, runnable, data, computed, hist, nil)
+		if err := ctx.Err(); err != nil {
+			return PartialResult{}, err
+		}
+		for i, o := range outcomes {
+			var stale *StaleError
+			if o.err != nil {
+				if !errors.As(o.err, &stale) {
+					res.Failures = append(res.Failures, Failure{IDs: runnable[i].IDs(), Error: o.err.Error()})
+					continue
+				}
+				res.Failures = append(res.Failures, Failure{IDs: runnable[i].IDs(), Error: o.err.Error(), Stale: true})
+			}
+			for _, ind := range o.indicators {
+				computed[ind.ID] = ind
+				res.Indicators = append(res.Indicators, ind)
+			}
+			if omitted := lo.Reject(runnable[i].IDs(), func(id int, _ int) bool {
+				_, ok := computed[id]
+				return ok
+			}); o.err == nil && len(omitted) > 0 {
+				res.Failures = append(res.Failures, Failure{IDs: omitted, Error: "input unavailable"})
+			}
+		}
+	}
+
+	sort.Slice(res.Indicators, func(i, j int) bool {
+		return res.Indicators[i].ID < res.Indicators[j].ID
+	})
+
+	return res, nil
+}
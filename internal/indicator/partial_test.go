@@ -0,0 +1,112 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+type stubCalc struct {
+	ids  []int
+	deps []int
+	err  error
+	omit int // ID left out of the result
+}
+
+func (c *stubCalc) IDs() []int          { return c.ids }
+func (c *stubCalc) Dependencies() []int { return c.deps }
+func (c *stubCalc) Calculate(_ context.Context, _ domain.FundStructureData, _ map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	out := make([]Indicator, 0, len(c.ids))
+	for _, id := range c.ids {
+		if id == c.omit {
+			continue
+		}
+		out = append(out, Indicator{ID: id, Value: decimal.NewFromInt(int64(id))})
+	}
+	return out, nil
+}
+
+func TestCalculatePartialSkipsDependentsOfFailedCalculator(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&stubCalc{ids: []int{9901}})
+	registry.Register(&stubCalc{ids: []int{9902}, err: errors.New("horizon timeout")})
+	registry.Register(&stubCalc{ids: []int{9903}, deps: []int{9902}})
+	registry.Register(&stubCalc{ids: []int{9904}, deps: []int{9903}})
+	registry.Register(&stubCalc{ids: []int{9905}, deps: []int{9901}})
+
+	res, err := registry.CalculatePartial(context.Background(), domain.FundStructureData{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []int
+	for _, ind := range res.Indicators {
+		got = append(got, ind.ID)
+	}
+	if len(got) != 2 || got[0] != 9901 || got[1] != 9905 {
+		t.Errorf("computed = %v, want [9901 9905]", got)
+	}
+
+	if len(res.Failures) != 3 {
+		t.Fatalf("got %d failures, want 3: %+v", len(res.Failures), res.Failures)
+	}
+	if f := res.Failures[0]; f.Skipped || f.Error != "horizon timeout" {
+		t.Errorf("first failure = %+v, want the calculator error", f)
+	}
+	if f := res.Failures[1]; !f.Skipped || f.IDs[0] != 9903 {
+		t.Errorf("second failure = %+v, want 9903 skipped", f)
+	}
+	if ids := res.UnavailableIDs(); len(ids) != 3 || ids[0] != 9902 || ids[2] != 9904 {
+		t.Errorf("UnavailableIDs = %v, want [9902 9903 9904]", ids)
+	}
+}
+
+func TestCalculatePartialReportsOmittedIDs(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&stubCalc{ids: []int{9901, 9902}, omit: 9902})
+	registry.Register(&stubCalc{ids: []int{9903}, deps: []int{9902}})
+
+	res, err := registry.CalculatePartial(context.Background(), domain.FundStructureData{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Indicators) != 1 || res.Indicators[0].ID != 9901 {
+		t.Errorf("computed = %+v, want 9901 only", res.Indicators)
+	}
+	if ids := res.UnavailableIDs(); len(ids) != 2 || ids[0] != 9902 || ids[1] != 9903 {
+		t.Errorf("UnavailableIDs = %v, want the omitted 9902 and its dependent 9903", ids)
+	}
+}
+
+func TestCalculatePartialCancelled(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&stubCalc{ids: []int{9901}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := registry.CalculatePartial(ctx, domain.FundStructureData{}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestServiceCalculatePartialNamesFailures(t *testing.T) {
+	svc := NewService(nil)
+	svc.registry = NewRegistry()
+	svc.registry.Register(&stubCalc{ids: []int{51}, err: errors.New("boom")})
+
+	res, err := svc.CalculatePartial(context.Background(), domain.FundStructureData{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Failures) != 1 || res.Failures[0].Calculator != "layer0" {
+		t.Errorf("failures = %+v, want one named layer0", res.Failures)
+	}
+}
@@ -0,0 +1,62 @@
+package indicator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Source records how a stored indicator value was obtained.
+type Source string
+
+const (
+	SourceMeasured   Source = "measured"   // calculated from a snapshot when it was generated (stat report, on-demand generate)
+	SourceRecomputed Source = "recomputed" // recalculated later from a stored snapshot (stat backfill-indicators)
+	SourceLedger     Source = "ledger"     // rebuilt from on-chain history (stat backfill-divs, stat report --date)
+	SourceSheet      Source = "sheet"      // read back from the MONITORING sheet, including the Excel-era rows
+	SourceCarried    Source = "carried"    // last stored value served while the calculator's circuit breaker was open (stat report)
+	SourceUnknown    Source = "unknown"    // stored before provenance was tracked
+)
+
+// Provenance is one stored indicator value with where it came from.
+type Provenance struct {
+	SnapshotDate time.Time
+	IndicatorID  int
+	Value        decimal.Decimal
+	Source       Source
+	ComputedAt   time.Time
+}
+
+// GetProvenance returns every stored value of the given indicator IDs with
+// its source and computation time, ordered by date, then indicator ID.
+// Values stored before sources were recorded have SourceUnknown.
+func (r *PgRepository) GetProvenance(ctx context.Context, slug string, ids []int) ([]Provenance, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT fi.snapshot_date, fi.indicator_id, fi.value, COALESCE(fi.source, $3), fi.computed_at
+		 FROM fund_indicators fi
+		 JOIN fund_entities fe ON fe.id = fi.entity_id
+		 WHERE fe.slug = $1 AND fi.indicator_id = ANY($2::int[])
+		 ORDER BY fi.snapshot_date, fi.indicator_id`,
+		slug, ids, string(SourceUnknown))
+	if err != nil {
+		return nil, fmt.Errorf("querying indicator provenance: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Provenance
+	for rows.Next() {
+		var p Provenance
+		var source string
+		if err := rows.Scan(&p.SnapshotDate, &p.IndicatorID, &p.Value, &source, &p.ComputedAt); err != nil {
+			return nil, fmt.Errorf("scanning provenance row: %w", err)
+		}
+		p.Source = Source(source)
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating provenance: %w", err)
+	}
+	return out, nil
+}
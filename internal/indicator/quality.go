@@ -0,0 +1,25 @@
+package indicator
+
+import (
+	"context"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/quality"
+)
+
+// QualityCalculator emits I65, the snapshot's data-quality score: the
+// percentage of held tokens that got a EURMTL value (quality.Score). Snapshots
+// stored before the score existed are scored from their portfolios, so the
+// value is reproducible from the JSONB alone.
+type QualityCalculator struct{}
+
+func init() {
+	registerCalculator("quality", 55, func() Calculator { return &QualityCalculator{} })
+}
+
+func (c *QualityCalculator) IDs() []int          { return []int{65} }
+func (c *QualityCalculator) Dependencies() []int { return nil }
+
+func (c *QualityCalculator) Calculate(_ context.Context, data domain.FundStructureData, _ map[int]Indicator, _ *HistoricalData) ([]Indicator, error) {
+	return []Indicator{NewIndicator(65, quality.Score(data), "", "")}, nil
+}
@@ -0,0 +1,187 @@
+package indicator
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Built-in calculators register themselves from init() in their own file, so
+// adding a calculator never touches NewService. Order fixes the registration
+// sequence (and therefore the tie-break order within a dependency layer);
+// it does not replace the dependency-based topological sort.
+type registration struct {
+	name    string
+	order   int
+	newCalc func() Calculator
+}
+
+var builtinCalculators []registration
+
+// registerCalculator adds a built-in calculator. Panics on a duplicate name
+// (programming error, same as duplicate IDs in Registry.Register).
+func registerCalculator(name string, order int, newCalc func() Calculator) {
+	for _, r := range builtinCalculators {
+		if r.name == name {
+			panic(fmt.Sprintf("duplicate calculator name %q registered", name))
+		}
+	}
+	builtinCalculators = append(builtinCalculators, registration{name: name, order: order, newCalc: newCalc})
+}
+
+// registrations returns the built-in calculators sorted by order, then name.
+func registrations() []registration {
+	out := make([]registration, len(builtinCalculators))
+	copy(out, builtinCalculators)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].order != out[j].order {
+			return out[i].order < out[j].order
+		}
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+// CalculatorInfo describes a registered calculator and its dependency edges.
+// DisabledBy is "config" when the calculator was switched off directly, or the
+// name of the disabled calculator it transitively depends on.
+type CalculatorInfo struct {
+	Name         string   `json:"name"`
+	Order        int      `json:"order"`
+	IDs          []int    `json:"ids"`
+	Dependencies []int    `json:"dependencies"`
+	DependsOn    []string `json:"dependsOn"`
+	Enabled      bool     `json:"enabled"`
+	DisabledBy   string   `json:"disabledBy,omitempty"`
+}
+
+// ServiceOption configures NewService.
+type ServiceOption func(*serviceOptions)
+
+type serviceOptions struct {
+	disabled    map[string]bool
+	concurrency int
+	timeout     time.Duration
+	timeouts    map[string]time.Duration
+	breaker     *Breaker
+	varCfg      *VaRConfig
+}
+
+// WithDisabledCalculators switches off calculators by name. Calculators that
+// depend on a disabled one are switched off as well, since their inputs would
+// never be computed. Unknown names are logged and ignored.
+func WithDisabledCalculators(names ...string) ServiceOption {
+	return func(o *serviceOptions) {
+		for _, n := range names {
+			o.disabled[n] = true
+		}
+	}
+}
+
+// WithConcurrency lets up to n calculators whose inputs are ready run at the
+// same time (default 1, strictly sequential).
+func WithConcurrency(n int) ServiceOption {
+	return func(o *serviceOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithCalculatorTimeouts gives up on a calculator that runs longer than its
+// entry in perCalculator (by name), or def when it has none; 0 means no limit.
+// A calculator that timed out counts as failed.
+func WithCalculatorTimeouts(def time.Duration, perCalculator map[string]time.Duration) ServiceOption {
+	return func(o *serviceOptions) {
+		o.timeout = def
+		o.timeouts = perCalculator
+	}
+}
+
+// WithCircuitBreaker stops running calculators that keep failing and serves
+// their last stored values instead while b is open; see Breaker. Only
+// Service.CalculatePartial loads and saves the breaker state.
+func WithCircuitBreaker(b *Breaker) ServiceOption {
+	return func(o *serviceOptions) {
+		o.breaker = b
+	}
+}
+
+// WithVaRConfig estimates I47 with cfg instead of DefaultVaRConfig.
+func WithVaRConfig(cfg VaRConfig) ServiceOption {
+	return func(o *serviceOptions) {
+		o.varCfg = &cfg
+	}
+}
+
+// resolveCalculators instantiates regs and works out which ones stay enabled.
+func resolveCalculators(regs []registration, disabled map[string]bool) ([]Calculator, []CalculatorInfo) {
+	known := make(map[string]bool, len(regs))
+	calcs := make([]Calculator, len(regs))
+	providerOf := make(map[int]string)
+	for i, r := range regs {
+		known[r.name] = true
+		calcs[i] = r.newCalc()
+		for _, id := range calcs[i].IDs() {
+			providerOf[id] = r.name
+		}
+	}
+	for name := range disabled {
+		if !known[name] {
+			slog.Info("unknown calculator in disabled list, ignoring", "calculator", name)
+		}
+	}
+
+	infos := make([]CalculatorInfo, len(regs))
+	disabledBy := make(map[string]string)
+	for i, r := range regs {
+		infos[i] = CalculatorInfo{
+			Name:         r.name,
+			Order:        r.order,
+			IDs:          calcs[i].IDs(),
+			Dependencies: calcs[i].Dependencies(),
+			DependsOn:    []string{},
+		}
+		if infos[i].Dependencies == nil {
+			infos[i].Dependencies = []int{}
+		}
+		seen := make(map[string]bool)
+		for _, dep := range infos[i].Dependencies {
+			if p, ok := providerOf[dep]; ok && !seen[p] {
+				seen[p] = true
+				infos[i].DependsOn = append(infos[i].DependsOn, p)
+			}
+		}
+		if disabled[r.name] {
+			disabledBy[r.name] = "config"
+		}
+	}
+
+	// Propagate until stable: a calculator is off if any provider is off.
+	for changed := true; changed; {
+		changed = false
+		for _, info := range infos {
+			if _, off := disabledBy[info.Name]; off {
+				continue
+			}
+			for _, p := range info.DependsOn {
+				if _, off := disabledBy[p]; off {
+					disabledBy[info.Name] = p
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var enabled []Calculator
+	for i := range infos {
+		if by, off := disabledBy[infos[i].Name]; off {
+			infos[i].DisabledBy = by
+			slog.Info("indicator calculator disabled", "calculator", infos[i].Name, "ids", infos[i].IDs, "disabled_by", by)
+			continue
+		}
+		infos[i].Enabled = true
+		enabled = append(enabled, calcs[i])
+	}
+	return enabled, infos
+}
@@ -0,0 +1,55 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuiltinRegistrationOrder(t *testing.T) {
+	want := []string{"layer0", "layer1", "liability", "layer2", "dividend", "tokenomics", "liquidity", "bpp", "quality", "association", "churn", "conversion", "benchmark", "index", "subfond_roi", "var"}
+	regs := registrations()
+	if len(regs) != len(want) {
+		t.Fatalf("got %d registrations, want %d", len(regs), len(want))
+	}
+	for i, r := range regs {
+		if r.name != want[i] {
+			t.Errorf("registration %d = %q, want %q", i, r.name, want[i])
+		}
+	}
+}
+
+func TestNewServiceDisabledCalculatorCascades(t *testing.T) {
+	svc := NewService(nil, WithDisabledCalculators("layer1", "no-such-calculator"))
+
+	enabled := make(map[string]bool)
+	for _, c := range svc.Calculators() {
+		enabled[c.Name] = c.Enabled
+	}
+	// layer2 and dividend need layer1 outputs; tokenomics needs layer2's I1.
+	for name, want := range map[string]bool{
+		"layer0": true, "layer1": false, "layer2": false,
+		"dividend": false, "tokenomics": false, "liquidity": false, "bpp": true, "churn": true, "index": false,
+	} {
+		if enabled[name] != want {
+			t.Errorf("%s enabled = %v, want %v", name, enabled[name], want)
+		}
+	}
+
+	indicators, err := svc.CalculateAll(context.Background(), testFundStructureData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, ind := range indicators {
+		if ind.ID == 3 || ind.ID == 1 {
+			t.Errorf("I%d computed although its calculator is disabled", ind.ID)
+		}
+	}
+}
+
+func TestNewServiceAllEnabledByDefault(t *testing.T) {
+	for _, c := range NewService(nil).Calculators() {
+		if !c.Enabled || c.DisabledBy != "" {
+			t.Errorf("%s = %+v, want enabled", c.Name, c)
+		}
+	}
+}
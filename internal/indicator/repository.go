@@ -23,7 +23,7 @@ type HistoryPoint struct {
 
 // Repository persists and retrieves computed indicators.
 type Repository interface {
-	Save(ctx context.Context, entityID int, date time.Time, indicators []Indicator) error
+	Save(ctx context.Context, entityID int, date time.Time, indicators []Indicator, source Source) error
 	GetByDate(ctx context.Context, slug string, date time.Time) ([]Indicator, error)
 	GetLatest(ctx context.Context, slug string) ([]Indicator, time.Time, error)
 	GetHistory(ctx context.Context, slug string, ids []int, from time.Time) ([]HistoryPoint, error)
@@ -40,9 +40,31 @@ func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
 	return &PgRepository{pool: pool}
 }
 
-// Save bulk-upserts all indicators for one (entity, date) tuple atomically.
-// On any failure, the entire batch is rolled back so partial state never reaches the table.
-func (r *PgRepository) Save(ctx context.Context, entityID int, date time.Time, indicators []Indicator) error {
+// Save bulk-upserts all indicators for one (entity, date) tuple atomically,
+// recording source as their provenance, and refreshes the week and month
+// aggregates of date. On any failure, the entire batch is rolled back so
+// partial state never reaches the tables.
+func (r *PgRepository) Save(ctx context.Context, entityID int, date time.Time, indicators []Indicator, source Source) error {
+	return r.SaveBatches(ctx, entityID, date, Batch{Indicators: indicators, Source: source})
+}
+
+// Batch is a set of indicators saved with one provenance.
+type Batch struct {
+	Indicators []Indicator
+	Source     Source
+}
+
+// SaveBatches is Save for several batches of the same date in one
+// transaction, so the date never keeps only some of them.
+func (r *PgRepository) SaveBatches(ctx context.Context, entityID int, date time.Time, batches ...Batch) error {
+	var indicators []Indicator
+	var sources []Source
+	for _, b := range batches {
+		for _, ind := range b.Indicators {
+			indicators = append(indicators, ind)
+			sources = append(sources, b.Source)
+		}
+	}
 	if len(indicators) == 0 {
 		return nil
 	}
@@ -53,14 +75,17 @@ func (r *PgRepository) Save(ctx context.Context, entityID int, date time.Time, i
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	// Values that bypassed NewIndicator (sheet imports, carried values) are
+	// stored under the same rounding policy as calculated ones.
 	batch := &pgx.Batch{}
-	for _, ind := range indicators {
+	for i, ind := range indicators {
+		ind = Round(ind)
 		batch.Queue(
-			`INSERT INTO fund_indicators (entity_id, snapshot_date, indicator_id, value)
-			 VALUES ($1, $2, $3, $4)
+			`INSERT INTO fund_indicators (entity_id, snapshot_date, indicator_id, value, source)
+			 VALUES ($1, $2, $3, $4, $5)
 			 ON CONFLICT (entity_id, snapshot_date, indicator_id)
-			 DO UPDATE SET value = EXCLUDED.value, computed_at = NOW()`,
-			entityID, date, ind.ID, ind.Value,
+			 DO UPDATE SET value = EXCLUDED.value, source = EXCLUDED.source, computed_at = NOW()`,
+			entityID, date, ind.ID, ind.Value, string(sources[i]),
 		)
 	}
 	br := tx.SendBatch(ctx, batch)
@@ -74,6 +99,14 @@ func (r *PgRepository) Save(ctx context.Context, entityID int, date time.Time, i
 		return fmt.Errorf("closing batch: %w", err)
 	}
 
+	ids := make([]int, len(indicators))
+	for i, ind := range indicators {
+		ids[i] = ind.ID
+	}
+	if err := refreshAggregates(ctx, tx, entityID, date, ids); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("committing indicator save tx: %w", err)
 	}
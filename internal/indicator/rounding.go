@@ -0,0 +1,59 @@
+package indicator
+
+import "github.com/shopspring/decimal"
+
+// RoundingMode is how a value is rounded to its indicator's precision.
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero, like the Sheets ROUND
+	// function and number formats. It is the default.
+	RoundHalfUp RoundingMode = "half-up"
+	// RoundHalfEven rounds halves to the even neighbour (banker's rounding).
+	RoundHalfEven RoundingMode = "half-even"
+	// RoundDown drops the digits beyond the precision, like ROUNDDOWN.
+	RoundDown RoundingMode = "down"
+)
+
+// RoundingPolicy is the rounding applied to an indicator's values before
+// they leave the calculators, so the stored value, the API and every export
+// sink carry the same number and the spreadsheet's number format has
+// nothing left to round.
+type RoundingPolicy struct {
+	Places int32        `json:"places"`
+	Mode   RoundingMode `json:"mode"`
+}
+
+// Apply rounds v by the policy.
+func (p RoundingPolicy) Apply(v decimal.Decimal) decimal.Decimal {
+	switch p.Mode {
+	case RoundHalfEven:
+		return v.RoundBank(p.Places)
+	case RoundDown:
+		return v.RoundDown(p.Places)
+	default:
+		return v.Round(p.Places)
+	}
+}
+
+// RoundingOf returns the rounding policy of a registered indicator ID.
+func RoundingOf(id int) (RoundingPolicy, bool) {
+	meta, ok := indicatorRegistry[id]
+	if !ok {
+		return RoundingPolicy{}, false
+	}
+	mode := meta.Rounding
+	if mode == "" {
+		mode = RoundHalfUp
+	}
+	return RoundingPolicy{Places: meta.Precision, Mode: mode}, true
+}
+
+// Round returns ind with its value rounded by the policy of its ID. Values
+// of unregistered IDs are returned unchanged.
+func Round(ind Indicator) Indicator {
+	if p, ok := RoundingOf(ind.ID); ok {
+		ind.Value = p.Apply(ind.Value)
+	}
+	return ind
+}
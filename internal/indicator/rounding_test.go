@@ -0,0 +1,51 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRoundingPolicyApply(t *testing.T) {
+	tests := []struct {
+		mode RoundingMode
+		in   string
+		want string
+	}{
+		{RoundHalfUp, "2.345", "2.35"},
+		{RoundHalfUp, "-2.345", "-2.35"},
+		{"", "2.345", "2.35"},
+		{RoundHalfEven, "2.345", "2.34"},
+		{RoundHalfEven, "2.355", "2.36"},
+		{RoundDown, "2.349", "2.34"},
+		{RoundDown, "-2.349", "-2.34"},
+	}
+	for _, tc := range tests {
+		got := RoundingPolicy{Places: 2, Mode: tc.mode}.Apply(decimal.RequireFromString(tc.in))
+		if got.String() != tc.want {
+			t.Errorf("%q %s = %s, want %s", tc.mode, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRoundingOfMatchesNewIndicator(t *testing.T) {
+	for _, id := range RegisteredIDs() {
+		p, ok := RoundingOf(id)
+		if !ok || p.Places != PrecisionOf(id) || p.Mode == "" {
+			t.Errorf("RoundingOf(%d) = %+v, %v", id, p, ok)
+		}
+	}
+	if _, ok := RoundingOf(9999); ok {
+		t.Error("RoundingOf(9999) found a policy for an unregistered ID")
+	}
+
+	// A value read back from the sheet as a float lands on the value the
+	// calculator would have produced.
+	sheet := decimal.NewFromFloat(4020758.5069999998)
+	if got, want := Round(Indicator{ID: 1, Value: sheet}).Value, NewIndicator(1, decimal.RequireFromString("4020758.507"), "", "").Value; !got.Equal(want) {
+		t.Errorf("Round(sheet value) = %s, want %s", got, want)
+	}
+	if got := Round(Indicator{ID: 9999, Value: sheet}).Value; !got.Equal(sheet) {
+		t.Errorf("unregistered value rounded to %s", got)
+	}
+}
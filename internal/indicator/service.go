@@ -2,6 +2,9 @@ package indicator
 
 import (
 	"context"
+	"log/slog"
+
+	"github.com/samber/lo"
 
 	"github.com/mtlprog/stat/internal/domain"
 )
@@ -9,25 +12,100 @@ import (
 // Service manages indicator calculation. Calculators read live values from
 // snapshot.LiveMetrics — there are no Horizon dependencies at this layer.
 type Service struct {
-	registry *Registry
-	hist     *HistoricalData
+	registry    *Registry
+	hist        *HistoricalData
+	calculators []CalculatorInfo
+	breaker     *Breaker
+	methods     map[int]string // by indicator ID, see Method
+}
+
+// methodReporter is a calculator with a configurable formula; Method
+// describes the configuration in use.
+type methodReporter interface {
+	Method() string
 }
 
-// NewService creates a new indicator Service with all calculators registered.
-// hist is optional; calculators that need historical data (dividend chain) fall
-// back to zero when nil.
-func NewService(hist *HistoricalData) *Service {
+// NewService creates a new indicator Service with every self-registered
+// calculator that is not disabled via opts. hist is optional; calculators that
+// need historical data (dividend chain) fall back to zero when nil.
+func NewService(hist *HistoricalData, opts ...ServiceOption) *Service {
+	o := serviceOptions{disabled: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	calcs, infos := resolveCalculators(registrations(), o.disabled)
 	registry := NewRegistry()
-	registry.Register(&Layer0Calculator{})
-	registry.Register(&Layer1Calculator{})
-	registry.Register(&Layer2Calculator{})
-	registry.Register(&DividendCalculator{})
-	registry.Register(&TokenomicsCalculator{})
-	registry.Register(&BPPCalculator{})
-	return &Service{registry: registry, hist: hist}
+	registry.SetConcurrency(o.concurrency)
+	enabled := lo.Filter(infos, func(c CalculatorInfo, _ int) bool { return c.Enabled })
+	methods := make(map[int]string)
+	for i, calc := range calcs {
+		name := enabled[i].Name
+		if v, ok := calc.(*VaRCalculator); ok && o.varCfg != nil {
+			v.cfg = *o.varCfg
+		}
+		if m, ok := calc.(methodReporter); ok {
+			for _, id := range calc.IDs() {
+				methods[id] = m.Method()
+			}
+		}
+		timeout, ok := o.timeouts[name]
+		if !ok {
+			timeout = o.timeout
+		}
+		if timeout > 0 || o.breaker != nil {
+			calc = &guardedCalculator{Calculator: calc, name: name, timeout: timeout, breaker: o.breaker}
+		}
+		registry.Register(calc)
+	}
+	return &Service{registry: registry, hist: hist, calculators: infos, breaker: o.breaker, methods: methods}
+}
+
+// Method returns the estimation method of an indicator with a configurable
+// formula (I47, e.g. "historical, 95%, 1d"), "" for the others and for
+// indicators of disabled calculators.
+func (s *Service) Method(id int) string {
+	return s.methods[id]
 }
 
 // CalculateAll computes all indicators from a snapshot.
 func (s *Service) CalculateAll(ctx context.Context, data domain.FundStructureData) ([]Indicator, error) {
 	return s.registry.CalculateAll(ctx, data, s.hist)
 }
+
+// CalculatePartial computes every indicator it can from a snapshot; see
+// Registry.CalculatePartial. Failures carry the calculator name. With a
+// circuit breaker, its state is loaded before and saved after the run; a
+// breaker that can't be read or written is logged and doesn't fail the run.
+func (s *Service) CalculatePartial(ctx context.Context, data domain.FundStructureData) (PartialResult, error) {
+	if s.breaker != nil {
+		if err := s.breaker.load(ctx); err != nil {
+			slog.Error("loading indicator circuit breakers", "error", err)
+		}
+		defer func() {
+			if err := s.breaker.save(context.WithoutCancel(ctx)); err != nil {
+				slog.Error("saving indicator circuit breakers", "error", err)
+			}
+		}()
+	}
+	res, err := s.registry.CalculatePartial(ctx, data, s.hist)
+	if err != nil {
+		return PartialResult{}, err
+	}
+	for i, f := range res.Failures {
+		for _, c := range s.calculators {
+			if len(f.IDs) > 0 && lo.Contains(c.IDs, f.IDs[0]) {
+				res.Failures[i].Calculator = c.Name
+			}
+		}
+	}
+	return res, nil
+}
+
+// Calculators lists every registered calculator in initialization order,
+// including disabled ones.
+func (s *Service) Calculators() []CalculatorInfo {
+	out := make([]CalculatorInfo, len(s.calculators))
+	copy(out, s.calculators)
+	return out
+}
@@ -0,0 +1,135 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// statsPlaces is the number of decimal places StdDev and DownsideStdDev are
+// exact to. Indicators round further via NewIndicator, so this only has to
+// stay well above the largest IndicatorMeta.Precision.
+const statsPlaces = 16
+
+// sqrtMaxIterations bounds Newton's method. Starting from a float64 estimate
+// it converges in two or three steps; outside float64 range the order-of-
+// magnitude seed needs a handful more.
+const sqrtMaxIterations = 200
+
+// Stats computes dispersion statistics over decimal series. The zero value is
+// exact: variance is accumulated in decimal and the square root is taken by
+// Newton's method (float64 only seeds the first guess). Fast takes the square
+// root in float64 instead — about 15 significant digits, for callers that
+// crunch long series and don't need more.
+type Stats struct {
+	Fast bool
+}
+
+// StdDev returns the sample standard deviation (n−1 denominator) of xs, or
+// zero for fewer than two values.
+func (s Stats) StdDev(xs []decimal.Decimal) decimal.Decimal {
+	if len(xs) < 2 {
+		return decimal.Zero
+	}
+	mean := Mean(xs)
+	var sum decimal.Decimal
+	for _, x := range xs {
+		d := x.Sub(mean)
+		sum = sum.Add(d.Mul(d))
+	}
+	return s.sqrt(sum.DivRound(decimal.NewFromInt(int64(len(xs)-1)), statsPlaces+4))
+}
+
+// DownsideStdDev returns the downside deviation of xs below target: the root
+// mean square of min(x − target, 0) over all n values (the Sortino
+// convention — values above target count as zero, not as missing). Zero for
+// an empty series.
+func (s Stats) DownsideStdDev(xs []decimal.Decimal, target decimal.Decimal) decimal.Decimal {
+	if len(xs) == 0 {
+		return decimal.Zero
+	}
+	var sum decimal.Decimal
+	for _, x := range xs {
+		if d := x.Sub(target); d.IsNegative() {
+			sum = sum.Add(d.Mul(d))
+		}
+	}
+	return s.sqrt(sum.DivRound(decimal.NewFromInt(int64(len(xs))), statsPlaces+4))
+}
+
+func (s Stats) sqrt(d decimal.Decimal) decimal.Decimal {
+	if s.Fast {
+		return decimal.NewFromFloat(math.Sqrt(d.InexactFloat64())).Round(statsPlaces)
+	}
+	return sqrtDecimal(d, statsPlaces)
+}
+
+// StdDev is Stats{}.StdDev.
+func StdDev(xs []decimal.Decimal) decimal.Decimal {
+	return Stats{}.StdDev(xs)
+}
+
+// DownsideStdDev is Stats{}.DownsideStdDev.
+func DownsideStdDev(xs []decimal.Decimal, target decimal.Decimal) decimal.Decimal {
+	return Stats{}.DownsideStdDev(xs, target)
+}
+
+// Mean returns the arithmetic mean of xs, or zero for an empty series.
+func Mean(xs []decimal.Decimal) decimal.Decimal {
+	if len(xs) == 0 {
+		return decimal.Zero
+	}
+	return decimal.Sum(xs[0], xs[1:]...).DivRound(decimal.NewFromInt(int64(len(xs))), statsPlaces+4)
+}
+
+// sqrtDecimal returns √d rounded to places decimal places using Newton's
+// method, x ← (x + d/x) / 2, seeded from the float64 square root. Returns zero
+// for d ≤ 0: the callers only pass variances, where a negative value can only
+// be rounding noise around zero.
+func sqrtDecimal(d decimal.Decimal, places int32) decimal.Decimal {
+	if !d.IsPositive() {
+		return decimal.Zero
+	}
+
+	work := places + 4
+	// Outside float64 range, seed with 10^(magnitude/2) instead.
+	x := decimal.New(1, (d.Exponent()+int32(len(d.Coefficient().String())))/2)
+	if f := math.Sqrt(d.InexactFloat64()); f > 0 && !math.IsInf(f, 0) {
+		x = decimal.NewFromFloat(f)
+	}
+	two := decimal.NewFromInt(2)
+	eps := decimal.New(1, -work)
+
+	for range sqrtMaxIterations {
+		next := x.Add(d.DivRound(x, work)).DivRound(two, work)
+		if next.Sub(x).Abs().LessThanOrEqual(eps) {
+			x = next
+			break
+		}
+		x = next
+	}
+	return x.Round(places)
+}
+
+// Correlation returns the Pearson correlation of the paired series xs and ys,
+// rounded to statsPlaces. ok is false when fewer than two pairs exist or
+// either series is constant — the coefficient is undefined there, not zero.
+func Correlation(xs, ys []decimal.Decimal) (r decimal.Decimal, ok bool) {
+	n := min(len(xs), len(ys))
+	if n < 2 {
+		return decimal.Zero, false
+	}
+	mx, my := Mean(xs[:n]), Mean(ys[:n])
+	var sxy, sxx, syy decimal.Decimal
+	for i := range n {
+		dx, dy := xs[i].Sub(mx), ys[i].Sub(my)
+		sxy = sxy.Add(dx.Mul(dy))
+		sxx = sxx.Add(dx.Mul(dx))
+		syy = syy.Add(dy.Mul(dy))
+	}
+	denom := sqrtDecimal(sxx.Mul(syy), statsPlaces+4)
+	if denom.IsZero() {
+		return decimal.Zero, false
+	}
+	return sxy.DivRound(denom, statsPlaces), true
+}
@@ -0,0 +1,133 @@
+package indicator
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/shopspring/decimal"
+)
+
+// series is a quick.Generator producing return-like decimal series: up to 60
+// values with 8 decimal places in roughly ±50.
+type series []decimal.Decimal
+
+func (series) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(60)
+	s := make(series, n)
+	for i := range s {
+		s[i] = decimal.New(r.Int63n(10_000_000_000)-5_000_000_000, -8)
+	}
+	return reflect.ValueOf(s)
+}
+
+// closeTo reports whether a and b agree to 1e-12 relative (or absolute near zero),
+// comfortably inside float64's ~15 significant digits.
+func closeTo(a, b decimal.Decimal) bool {
+	tol := decimal.New(1, -12)
+	scale := decimal.Max(a.Abs(), b.Abs(), decimal.NewFromInt(1))
+	return a.Sub(b).Abs().LessThanOrEqual(tol.Mul(scale))
+}
+
+func TestStdDevExactMatchesFast(t *testing.T) {
+	prop := func(xs series) bool {
+		return closeTo(Stats{}.StdDev(xs), Stats{Fast: true}.StdDev(xs))
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDownsideStdDevExactMatchesFast(t *testing.T) {
+	prop := func(xs series, target int16) bool {
+		tgt := decimal.New(int64(target), -3)
+		return closeTo(Stats{}.DownsideStdDev(xs, tgt), Stats{Fast: true}.DownsideStdDev(xs, tgt))
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSqrtDecimalSquaresBack(t *testing.T) {
+	prop := func(mantissa uint64, exp uint8) bool {
+		d := decimal.New(int64(mantissa>>1), -int32(exp%40))
+		r := sqrtDecimal(d, statsPlaces)
+		// (r ± 1ulp)² must bracket d: r is √d correctly rounded to statsPlaces.
+		ulp := decimal.New(1, -statsPlaces)
+		lo, hi := r.Sub(ulp), r.Add(ulp)
+		if r.IsZero() {
+			lo = decimal.Zero
+		}
+		return lo.Mul(lo).LessThanOrEqual(d) && hi.Mul(hi).GreaterThanOrEqual(d)
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSqrtDecimalExactValues(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"0", "0"},
+		{"-4", "0"},
+		{"1", "1"},
+		{"144", "12"},
+		{"0.0001", "0.01"},
+		{"2", "1.4142135623730950"},
+		// Beyond float64 range the float seed is useless; Newton still converges.
+		{"1e400", "1e200"},
+	}
+	for _, c := range cases {
+		got := sqrtDecimal(decimal.RequireFromString(c.in), statsPlaces)
+		if !got.Equal(decimal.RequireFromString(c.want)) {
+			t.Errorf("sqrtDecimal(%s) = %s, want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestStdDevKnownSeries(t *testing.T) {
+	xs := []decimal.Decimal{
+		decimal.NewFromInt(2), decimal.NewFromInt(4), decimal.NewFromInt(4), decimal.NewFromInt(4),
+		decimal.NewFromInt(5), decimal.NewFromInt(5), decimal.NewFromInt(7), decimal.NewFromInt(9),
+	}
+	// Population σ is 2; sample σ = √(32/7).
+	if got, want := StdDev(xs), decimal.RequireFromString("2.1380899352993951"); !got.Equal(want) {
+		t.Errorf("StdDev = %s, want %s", got, want)
+	}
+	// Below 5: 2, 4, 4, 4 → (9+1+1+1)/8 = 1.5.
+	if got, want := DownsideStdDev(xs, decimal.NewFromInt(5)), decimal.RequireFromString("1.2247448713915890"); !got.Equal(want) {
+		t.Errorf("DownsideStdDev = %s, want %s", got, want)
+	}
+	if !StdDev(xs[:1]).IsZero() || !DownsideStdDev(nil, decimal.Zero).IsZero() {
+		t.Error("degenerate series should yield zero")
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	xs := []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3), decimal.NewFromInt(4)}
+	neg := []decimal.Decimal{decimal.NewFromInt(8), decimal.NewFromInt(6), decimal.NewFromInt(4), decimal.NewFromInt(2)}
+	flat := []decimal.Decimal{decimal.NewFromInt(5), decimal.NewFromInt(5), decimal.NewFromInt(5), decimal.NewFromInt(5)}
+
+	if r, ok := Correlation(xs, xs); !ok || !r.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("Correlation(xs, xs) = %s, %v; want 1", r, ok)
+	}
+	if r, ok := Correlation(xs, neg); !ok || !r.Equal(decimal.NewFromInt(-1)) {
+		t.Errorf("Correlation(xs, neg) = %s, %v; want -1", r, ok)
+	}
+	if _, ok := Correlation(xs, flat); ok {
+		t.Error("Correlation with a constant series should be undefined")
+	}
+	if _, ok := Correlation(xs[:1], neg[:1]); ok {
+		t.Error("Correlation of a single pair should be undefined")
+	}
+}
+
+func TestCorrelationBounded(t *testing.T) {
+	prop := func(xs, ys series) bool {
+		r, ok := Correlation(xs, ys)
+		return !ok || r.Abs().LessThanOrEqual(decimal.NewFromInt(1))
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,53 @@
+package indicator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+// subfondROIIDs maps subfond names to their 30-day ROI indicator.
+var subfondROIIDs = map[string]int{"DEFI": 83, "MCITY": 84, "MABIZ": 85, "BOSS": 86}
+
+// SubfondROICalculator emits I83–I86, the return of each subfond over the
+// window of HistoricalData.SubfondFlows by subfond.Return: the change of
+// its total value (I51, I52, I53, I59) from the stored value at the window
+// start, net of the capital moved between it and the other fund accounts.
+// A subfond without a stored value at the window start, or without
+// positive capital, is left out; without SubfondFlows (deterministic
+// recomputes, fixtures, or a failed scan) nothing is emitted.
+type SubfondROICalculator struct{}
+
+func init() {
+	registerCalculator("subfond_roi", 61, func() Calculator { return &SubfondROICalculator{} })
+}
+
+func (c *SubfondROICalculator) IDs() []int          { return []int{83, 84, 85, 86} }
+func (c *SubfondROICalculator) Dependencies() []int { return []int{51, 52, 53, 59} }
+
+func (c *SubfondROICalculator) Calculate(ctx context.Context, _ domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil || hist.SubfondFlows == nil || hist.IndicatorRepo == nil {
+		return nil, nil
+	}
+	w := hist.SubfondFlows
+	opening, err := hist.IndicatorRepo.GetNearestBefore(ctx, hist.Slug, w.From)
+	if err != nil {
+		return nil, fmt.Errorf("indicator repo lookup (slug=%s, target=%s): %w", hist.Slug, w.From.Format("2006-01-02"), err)
+	}
+
+	var out []Indicator
+	for _, sf := range subfond.All() {
+		open, ok := opening[sf.ValueID]
+		if !ok {
+			continue
+		}
+		r := subfond.Return(sf, open.Value, deps[sf.ValueID].Value, w.Flows, w.From, w.To)
+		if r.ROI == nil {
+			continue
+		}
+		out = append(out, NewIndicator(subfondROIIDs[sf.Name], *r.ROI, "", ""))
+	}
+	return out, nil
+}
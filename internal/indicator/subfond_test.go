@@ -0,0 +1,61 @@
+package indicator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+// openingRepo serves the same stored values for every GetNearestBefore.
+type openingRepo struct {
+	stubIndicatorRepoForDividend
+	values map[int]Indicator
+}
+
+func (r *openingRepo) GetNearestBefore(context.Context, string, time.Time) (map[int]Indicator, error) {
+	return r.values, nil
+}
+
+func TestSubfondROICalculator(t *testing.T) {
+	defi, _ := subfond.Lookup("DEFI")
+	date := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	hist := &HistoricalData{
+		Slug: "mtlf",
+		// No stored MCITY value at the window start; BOSS had no capital.
+		IndicatorRepo: &openingRepo{values: map[int]Indicator{
+			51: {ID: 51, Value: decimal.NewFromInt(1000)},
+			53: {ID: 53, Value: decimal.NewFromInt(2000)},
+			59: {ID: 59, Value: decimal.Zero},
+		}},
+		SubfondFlows: &subfond.Window{From: date.AddDate(0, 0, -30), To: date, Flows: []subfond.Flow{
+			{Account: defi.Address, Direction: subfond.In, ValueEURMTL: decimal.NewFromInt(500), At: date.AddDate(0, 0, -14)},
+		}},
+	}
+	deps := map[int]Indicator{
+		51: {ID: 51, Value: decimal.NewFromInt(1600)},
+		52: {ID: 52, Value: decimal.NewFromInt(700)},
+		53: {ID: 53, Value: decimal.NewFromInt(1900)},
+		59: {ID: 59, Value: decimal.NewFromInt(5)},
+	}
+
+	got, err := (&SubfondROICalculator{}).Calculate(context.Background(), testFundStructureData(), deps, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Subfonds come in registry order: MABIZ, then DEFI.
+	if len(got) != 2 || got[0].ID != 85 || got[1].ID != 83 {
+		t.Fatalf("got %+v, want I85 and I83", got)
+	}
+	// MABIZ lost 100 of 2000; DEFI gained 100 on 1000 + 500 × ½.
+	if !got[0].Value.Equal(decimal.NewFromInt(-5)) || !got[1].Value.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("I85 = %s, I83 = %s, want -5 and 8", got[0].Value, got[1].Value)
+	}
+
+	if got, _ := (&SubfondROICalculator{}).Calculate(context.Background(), testFundStructureData(), deps, &HistoricalData{}); len(got) != 0 {
+		t.Errorf("without flows: got %v, want none", got)
+	}
+}
@@ -15,6 +15,10 @@ import (
 // day on fetch failures.
 type TokenomicsCalculator struct{}
 
+func init() {
+	registerCalculator("tokenomics", 40, func() Calculator { return &TokenomicsCalculator{} })
+}
+
 func (c *TokenomicsCalculator) IDs() []int {
 	return []int{18, 21, 22, 23, 24, 25, 26, 27, 40, 62}
 }
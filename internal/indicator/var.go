@@ -0,0 +1,272 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Value at Risk engine behind I47 (VaRCalculator). The method, confidence
+// and horizon come from VAR_METHOD, VAR_CONFIDENCE and VAR_HORIZON through
+// WithVaRConfig, and Service.Method(47) reports the method in use.
+
+// VaRMethod selects how the loss distribution is estimated.
+type VaRMethod string
+
+const (
+	// VaRParametric assumes normally distributed returns: μ·h + z·σ·√h.
+	VaRParametric VaRMethod = "parametric"
+	// VaRHistorical takes the empirical quantile of overlapping h-day returns.
+	VaRHistorical VaRMethod = "historical"
+	// VaRMonteCarlo simulates h-day returns from a normal fitted to the series.
+	VaRMonteCarlo VaRMethod = "monte-carlo"
+)
+
+// ErrInsufficientData is returned when the return series is too short for
+// the requested horizon.
+var ErrInsufficientData = errors.New("not enough returns for VaR")
+
+// zScores holds the lower-tail standard normal quantile per supported
+// confidence level. Only 95% and 99% are offered: they're the levels the
+// fund reports, and a table beats shipping an inverse-CDF approximation.
+var zScores = map[string]decimal.Decimal{
+	"0.95": decimal.RequireFromString("-1.6448536269514722"),
+	"0.99": decimal.RequireFromString("-2.3263478740408408"),
+}
+
+// defaultSimulations is the Monte Carlo path count when VaRConfig leaves it zero.
+const defaultSimulations = 10_000
+
+// VaRConfig parameterises ValueAtRisk.
+type VaRConfig struct {
+	Method      VaRMethod
+	Confidence  decimal.Decimal // 0.95 or 0.99
+	HorizonDays int             // ≥ 1
+	Simulations int             // Monte Carlo paths; 0 means defaultSimulations
+	Seed        uint64          // Monte Carlo seed, so a given snapshot reproduces
+}
+
+// DefaultVaRConfig is the I47 estimate without WithVaRConfig: one-day 95%
+// historical simulation.
+var DefaultVaRConfig = VaRConfig{Method: VaRHistorical, Confidence: decimal.RequireFromString("0.95"), HorizonDays: 1}
+
+// String describes cfg as Service.Method reports it, e.g.
+// "historical, 95%, 1d".
+func (c VaRConfig) String() string {
+	return fmt.Sprintf("%s, %s%%, %dd", c.Method, c.Confidence.Shift(2).String(), c.HorizonDays)
+}
+
+// ParseVaRConfig validates the VAR_METHOD, VAR_CONFIDENCE and VAR_HORIZON
+// settings.
+func ParseVaRConfig(method, confidence string, horizonDays int) (VaRConfig, error) {
+	m, err := ParseVaRMethod(method)
+	if err != nil {
+		return VaRConfig{}, err
+	}
+	c, err := decimal.NewFromString(confidence)
+	if err != nil {
+		return VaRConfig{}, fmt.Errorf("invalid VaR confidence %q: %w", confidence, err)
+	}
+	if _, ok := zScores[c.String()]; !ok {
+		return VaRConfig{}, fmt.Errorf("unsupported VaR confidence %s, valid: 0.95, 0.99", c)
+	}
+	if horizonDays < 1 {
+		return VaRConfig{}, fmt.Errorf("VaR horizon must be at least 1 day, got %d", horizonDays)
+	}
+	return VaRConfig{Method: m, Confidence: c, HorizonDays: horizonDays}, nil
+}
+
+// ParseVaRMethod validates a method name from configuration.
+func ParseVaRMethod(s string) (VaRMethod, error) {
+	switch m := VaRMethod(s); m {
+	case VaRParametric, VaRHistorical, VaRMonteCarlo:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown VaR method %q, valid: parametric, historical, monte-carlo", s)
+}
+
+// VaRResult is a VaR estimate together with the parameters that produced it,
+// so the method can be recorded next to the value.
+type VaRResult struct {
+	Value       decimal.Decimal `json:"value"` // loss as a positive fraction of value
+	Method      VaRMethod       `json:"method"`
+	Confidence  decimal.Decimal `json:"confidence"`
+	HorizonDays int             `json:"horizonDays"`
+}
+
+// ValueAtRisk estimates the loss not exceeded with cfg.Confidence over
+// cfg.HorizonDays, from daily simple returns (0.01 = +1%). The result is a
+// positive loss fraction; a distribution whose tail is still a gain yields a
+// negative value rather than being clamped.
+func ValueAtRisk(returns []decimal.Decimal, cfg VaRConfig) (VaRResult, error) {
+	z, ok := zScores[cfg.Confidence.String()]
+	if !ok {
+		return VaRResult{}, fmt.Errorf("unsupported VaR confidence %s, valid: 0.95, 0.99", cfg.Confidence)
+	}
+	if cfg.HorizonDays < 1 {
+		return VaRResult{}, fmt.Errorf("VaR horizon must be at least 1 day, got %d", cfg.HorizonDays)
+	}
+	if len(returns) < 2 || len(returns) < cfg.HorizonDays {
+		return VaRResult{}, fmt.Errorf("%w: have %d, horizon %d", ErrInsufficientData, len(returns), cfg.HorizonDays)
+	}
+
+	var quantile decimal.Decimal
+	switch cfg.Method {
+	case VaRParametric:
+		quantile = parametricQuantile(returns, z, cfg.HorizonDays)
+	case VaRHistorical:
+		quantile = empiricalQuantile(horizonReturns(returns, cfg.HorizonDays), cfg.Confidence)
+	case VaRMonteCarlo:
+		quantile = monteCarloQuantile(returns, cfg)
+	default:
+		return VaRResult{}, fmt.Errorf("unknown VaR method %q", cfg.Method)
+	}
+
+	return VaRResult{
+		Value:       quantile.Neg().Round(statsPlaces),
+		Method:      cfg.Method,
+		Confidence:  cfg.Confidence,
+		HorizonDays: cfg.HorizonDays,
+	}, nil
+}
+
+func parametricQuantile(returns []decimal.Decimal, z decimal.Decimal, h int) decimal.Decimal {
+	hd := decimal.NewFromInt(int64(h))
+	return Mean(returns).Mul(hd).Add(z.Mul(StdDev(returns)).Mul(sqrtDecimal(hd, statsPlaces)))
+}
+
+// horizonReturns sums each window of h consecutive daily returns. Windows
+// overlap, so a year of data still gives ~250 samples at h = 10.
+func horizonReturns(returns []decimal.Decimal, h int) []decimal.Decimal {
+	if h == 1 {
+		return returns
+	}
+	out := make([]decimal.Decimal, 0, len(returns)-h+1)
+	var window decimal.Decimal
+	for i, r := range returns {
+		window = window.Add(r)
+		if i >= h {
+			window = window.Sub(returns[i-h])
+		}
+		if i >= h-1 {
+			out = append(out, window)
+		}
+	}
+	return out
+}
+
+// empiricalQuantile returns the nearest-rank lower-tail quantile at
+// 1 − confidence: the ⌈α·n⌉-th smallest value.
+func empiricalQuantile(xs []decimal.Decimal, confidence decimal.Decimal) decimal.Decimal {
+	sorted := make([]decimal.Decimal, len(xs))
+	copy(sorted, xs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	alpha := decimal.NewFromInt(1).Sub(confidence)
+	k := int(alpha.Mul(decimal.NewFromInt(int64(len(sorted)))).Ceil().IntPart())
+	k = max(k, 1)
+	return sorted[k-1]
+}
+
+// monteCarloQuantile draws cfg.Simulations h-day returns, each the sum of h
+// daily draws from N(μ, σ) fitted to returns, and takes their empirical
+// quantile. Sampling runs in float64 — the estimate's own sampling error is
+// orders of magnitude above float rounding — but μ and σ come from the exact
+// decimal statistics.
+func monteCarloQuantile(returns []decimal.Decimal, cfg VaRConfig) decimal.Decimal {
+	n := cfg.Simulations
+	if n <= 0 {
+		n = defaultSimulations
+	}
+	mu := Mean(returns).InexactFloat64()
+	sigma := StdDev(returns).InexactFloat64()
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x9e3779b97f4a7c15))
+
+	sims := make([]decimal.Decimal, n)
+	for i := range sims {
+		var total float64
+		for range cfg.HorizonDays {
+			total += mu + sigma*rng.NormFloat64()
+		}
+		sims[i] = decimal.NewFromFloat(total)
+	}
+	return empiricalQuantile(sims, cfg.Confidence)
+}
+
+// varLookbackDays is how far back the I8 history behind I47 reaches.
+const varLookbackDays = 365
+
+// VaRCalculator emits I47: the loss of the share book value (I8) not
+// exceeded with the configured confidence over the configured horizon, in
+// percent, estimated by ValueAtRisk from the daily returns between the stored
+// I8 values of the last year and today's. Returns span consecutive stored
+// dates, so a day without a report folds into the next return. Without
+// HistoricalData.IndicatorRepo and Date, or with too short a history, I47 is
+// left out.
+type VaRCalculator struct {
+	cfg VaRConfig // zero: DefaultVaRConfig
+}
+
+func init() {
+	registerCalculator("var", 62, func() Calculator { return &VaRCalculator{} })
+}
+
+func (c *VaRCalculator) IDs() []int          { return []int{47} }
+func (c *VaRCalculator) Dependencies() []int { return []int{8} }
+
+// config returns the configuration I47 is estimated with.
+func (c *VaRCalculator) config() VaRConfig {
+	if c.cfg.Method == "" {
+		return DefaultVaRConfig
+	}
+	return c.cfg
+}
+
+// Method describes the configuration I47 is estimated with.
+func (c *VaRCalculator) Method() string { return c.config().String() }
+
+func (c *VaRCalculator) Calculate(ctx context.Context, _ domain.FundStructureData, deps map[int]Indicator, hist *HistoricalData) ([]Indicator, error) {
+	if hist == nil || hist.IndicatorRepo == nil || hist.Date.IsZero() {
+		return nil, nil
+	}
+	points, err := hist.IndicatorRepo.GetHistory(ctx, hist.Slug, []int{8}, hist.Date.AddDate(0, 0, -varLookbackDays))
+	if err != nil {
+		return nil, fmt.Errorf("book value history (slug=%s): %w", hist.Slug, err)
+	}
+	var book []decimal.Decimal
+	for _, p := range points {
+		if p.IndicatorID == 8 && p.SnapshotDate.Before(hist.Date) {
+			book = append(book, p.Value)
+		}
+	}
+	book = append(book, deps[8].Value)
+
+	cfg := c.config()
+	cfg.Seed = uint64(hist.Date.Unix())
+	res, err := ValueAtRisk(bookReturns(book), cfg)
+	if errors.Is(err, ErrInsufficientData) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Indicator{NewIndicator(47, res.Value.Mul(decimal.NewFromInt(100)), "", "")}, nil
+}
+
+// bookReturns returns the simple return between each pair of consecutive
+// values, skipping pairs that start from a non-positive value.
+func bookReturns(values []decimal.Decimal) []decimal.Decimal {
+	var out []decimal.Decimal
+	for i := 1; i < len(values); i++ {
+		if values[i-1].IsPositive() {
+			out = append(out, values[i].Div(values[i-1]).Sub(decimal.NewFromInt(1)))
+		}
+	}
+	return out
+}
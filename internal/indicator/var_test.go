@@ -0,0 +1,185 @@
+package indicator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+// varSeries is 100 daily returns: -0.05, -0.04, …, -0.01 once each, then 95 × +0.01.
+func varSeries() []decimal.Decimal {
+	xs := []decimal.Decimal{dec("-0.05"), dec("-0.04"), dec("-0.03"), dec("-0.02"), dec("-0.01")}
+	for range 95 {
+		xs = append(xs, dec("0.01"))
+	}
+	return xs
+}
+
+func TestValueAtRiskHistorical(t *testing.T) {
+	res, err := ValueAtRisk(varSeries(), VaRConfig{Method: VaRHistorical, Confidence: dec("0.95"), HorizonDays: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// ⌈0.05·100⌉ = 5th smallest return is -0.01.
+	if !res.Value.Equal(dec("0.01")) {
+		t.Errorf("VaR95 = %s, want 0.01", res.Value)
+	}
+	if res.Method != VaRHistorical || res.HorizonDays != 1 {
+		t.Errorf("result metadata = %+v", res)
+	}
+
+	res, err = ValueAtRisk(varSeries(), VaRConfig{Method: VaRHistorical, Confidence: dec("0.99"), HorizonDays: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Value.Equal(dec("0.05")) {
+		t.Errorf("VaR99 = %s, want 0.05", res.Value)
+	}
+}
+
+func TestValueAtRiskHistoricalHorizon(t *testing.T) {
+	// Two-day windows: worst is -0.05 + -0.04 = -0.09.
+	res, err := ValueAtRisk(varSeries(), VaRConfig{Method: VaRHistorical, Confidence: dec("0.99"), HorizonDays: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Value.Equal(dec("0.09")) {
+		t.Errorf("2-day VaR99 = %s, want 0.09", res.Value)
+	}
+}
+
+func TestValueAtRiskParametric(t *testing.T) {
+	xs := []decimal.Decimal{dec("0.01"), dec("-0.01"), dec("0.01"), dec("-0.01")}
+	// μ = 0, σ = √(4·0.0001/3); VaR = 1.6448536269514722·σ.
+	res, err := ValueAtRisk(xs, VaRConfig{Method: VaRParametric, Confidence: dec("0.95"), HorizonDays: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := dec("1.6448536269514722").Mul(StdDev(xs)).Round(statsPlaces)
+	if !res.Value.Equal(want) {
+		t.Errorf("parametric VaR = %s, want %s", res.Value, want)
+	}
+}
+
+func TestValueAtRiskMonteCarloReproducibleAndNearParametric(t *testing.T) {
+	var xs []decimal.Decimal
+	for range 25 {
+		xs = append(xs, dec("0.01"), dec("-0.02"), dec("0.02"), dec("-0.01"))
+	}
+	cfg := VaRConfig{Method: VaRMonteCarlo, Confidence: dec("0.99"), HorizonDays: 10, Simulations: 20_000, Seed: 7}
+	a, err := ValueAtRisk(xs, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := ValueAtRisk(xs, cfg)
+	if !a.Value.Equal(b.Value) {
+		t.Errorf("same seed gave %s and %s", a.Value, b.Value)
+	}
+
+	cfg.Method = VaRParametric
+	p, _ := ValueAtRisk(xs, cfg)
+	// Both estimate the same normal quantile; 20k paths land within a few percent.
+	if diff := a.Value.Sub(p.Value).Abs(); diff.GreaterThan(p.Value.Abs().Mul(dec("0.05"))) {
+		t.Errorf("monte carlo %s too far from parametric %s", a.Value, p.Value)
+	}
+}
+
+func TestValueAtRiskInvalidConfig(t *testing.T) {
+	xs := varSeries()
+	cases := []VaRConfig{
+		{Method: VaRHistorical, Confidence: dec("0.9"), HorizonDays: 1},
+		{Method: VaRHistorical, Confidence: dec("0.95"), HorizonDays: 0},
+		{Method: "garch", Confidence: dec("0.95"), HorizonDays: 1},
+	}
+	for _, cfg := range cases {
+		if _, err := ValueAtRisk(xs, cfg); err == nil {
+			t.Errorf("ValueAtRisk(%+v) succeeded, want error", cfg)
+		}
+	}
+
+	_, err := ValueAtRisk(xs[:1], VaRConfig{Method: VaRHistorical, Confidence: dec("0.95"), HorizonDays: 1})
+	if !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("err = %v, want ErrInsufficientData", err)
+	}
+}
+
+func TestParseVaRMethod(t *testing.T) {
+	if m, err := ParseVaRMethod("monte-carlo"); err != nil || m != VaRMonteCarlo {
+		t.Errorf("ParseVaRMethod(monte-carlo) = %q, %v", m, err)
+	}
+	if _, err := ParseVaRMethod("normal"); err == nil {
+		t.Error("ParseVaRMethod(normal) succeeded, want error")
+	}
+}
+
+func TestParseVaRConfig(t *testing.T) {
+	cfg, err := ParseVaRConfig("parametric", "0.99", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.String() != "parametric, 99%, 10d" {
+		t.Errorf("config = %s", cfg)
+	}
+	for _, bad := range []struct {
+		method, confidence string
+		horizon            int
+	}{
+		{"normal", "0.95", 1},
+		{"historical", "0.9", 1},
+		{"historical", "high", 1},
+		{"historical", "0.95", 0},
+	} {
+		if _, err := ParseVaRConfig(bad.method, bad.confidence, bad.horizon); err == nil {
+			t.Errorf("ParseVaRConfig(%q, %q, %d) succeeded, want error", bad.method, bad.confidence, bad.horizon)
+		}
+	}
+}
+
+func TestVaRCalculator(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	// Book value falls 5%, 4%, …, 1% once each, then grows 1% for 95 days.
+	book := dec("1")
+	var history []HistoryPoint
+	for i, r := range varSeries() {
+		history = append(history, HistoryPoint{SnapshotDate: date.AddDate(0, 0, i-100), IndicatorID: 8, Value: book})
+		book = book.Mul(dec("1").Add(r))
+	}
+	hist := &HistoricalData{Slug: "mtlf", Date: date, IndicatorRepo: &stubIndicatorRepoForDividend{history: history}}
+	deps := map[int]Indicator{8: {ID: 8, Value: book}}
+
+	calc := &VaRCalculator{cfg: VaRConfig{Method: VaRHistorical, Confidence: dec("0.99"), HorizonDays: 1}}
+	got, err := calc.Calculate(context.Background(), testFundStructureData(), deps, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != 47 || got[0].Value.String() != "5" {
+		t.Errorf("got %+v, want I47 = 5", got)
+	}
+	if m := calc.Method(); m != "historical, 99%, 1d" {
+		t.Errorf("I47 method = %q", m)
+	}
+
+	// A single stored value gives one return: too short for any estimate.
+	hist.IndicatorRepo = &stubIndicatorRepoForDividend{history: history[:1]}
+	if got, err := (&VaRCalculator{}).Calculate(context.Background(), testFundStructureData(), deps, hist); err != nil || len(got) != 0 {
+		t.Errorf("short history: got %+v, %v; want nothing", got, err)
+	}
+}
+
+func TestServiceMethodReportsVaRConfig(t *testing.T) {
+	if m := NewService(nil).Method(47); m != "historical, 95%, 1d" {
+		t.Errorf("default I47 method = %q", m)
+	}
+	cfg := VaRConfig{Method: VaRParametric, Confidence: dec("0.99"), HorizonDays: 10}
+	if m := NewService(nil, WithVaRConfig(cfg)).Method(47); m != "parametric, 99%, 10d" {
+		t.Errorf("configured I47 method = %q", m)
+	}
+	if m := NewService(nil).Method(1); m != "" {
+		t.Errorf("I1 method = %q, want none", m)
+	}
+}
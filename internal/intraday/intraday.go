@@ -0,0 +1,182 @@
+// Package intraday samples a few key indicators every hour or so, outside
+// the daily report pipeline: the share market price (I10) from the last DEX
+// trades and the operating balance (I4) from the sub-fund balances. Samples
+// are stored apart from the daily snapshot series and serve charts of the
+// last hours; they are never mixed into fund_indicators.
+package intraday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// Sampled indicators.
+const (
+	IDOperatingBalance = 4
+	IDShareMarketPrice = 10
+)
+
+// Indicators lists the sampled indicators in output order.
+var Indicators = []int{IDOperatingBalance, IDShareMarketPrice}
+
+// tradesWindow is how many recent MTL/EURMTL trades I10 averages, as in the
+// daily snapshot.
+const tradesWindow = 100
+
+// Sample is one indicator value at one time.
+type Sample struct {
+	IndicatorID int             `json:"-"`
+	SampledAt   time.Time       `json:"time"`
+	Value       decimal.Decimal `json:"value"`
+}
+
+// PriceSource prices assets on the DEX (price.Service).
+type PriceSource interface {
+	GetAverageTradePrice(ctx context.Context, base, counter domain.AssetInfo, limit int) (decimal.Decimal, error)
+	GetPrice(ctx context.Context, asset, baseAsset domain.AssetInfo, amount string) (domain.TokenPairPrice, error)
+}
+
+// AccountSource reads account balances (horizon.Client).
+type AccountSource interface {
+	FetchAccount(ctx context.Context, accountID string) (horizon.HorizonAccount, error)
+}
+
+// Store persists samples (PgRepository).
+type Store interface {
+	Save(ctx context.Context, slug string, samples []Sample) error
+	Since(ctx context.Context, slug string, indicatorID int, since time.Time) ([]Sample, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Service takes and reads intraday samples for one entity.
+type Service struct {
+	prices    PriceSource
+	accounts  AccountSource
+	store     Store
+	slug      string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewService creates a Service for the entity slug keeping samples for
+// retention (0 keeps them forever). A read-only service (History only) may
+// pass nil sources.
+func NewService(prices PriceSource, accounts AccountSource, store Store, slug string, retention time.Duration) *Service {
+	return &Service{prices: prices, accounts: accounts, store: store, slug: slug, retention: retention, now: time.Now}
+}
+
+// IsSampled reports whether id is one of the sampled indicators.
+func IsSampled(id int) bool {
+	return slices.Contains(Indicators, id)
+}
+
+// Sample takes one sample of every indicator, stores it and prunes samples
+// past the retention. An indicator that can't be computed is left out; its
+// error is returned joined with the others once the rest are stored.
+func (s *Service) Sample(ctx context.Context) ([]Sample, error) {
+	at := s.now().UTC().Truncate(time.Second)
+	var samples []Sample
+	var errs []error
+	for _, id := range Indicators {
+		v, err := s.compute(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("I%d: %w", id, err))
+			continue
+		}
+		samples = append(samples, Sample{IndicatorID: id, SampledAt: at, Value: indicator.NewIndicator(id, v, "", "").Value})
+	}
+	if len(samples) > 0 {
+		if err := s.store.Save(ctx, s.slug, samples); err != nil {
+			return nil, err
+		}
+	}
+	if s.retention > 0 {
+		if n, err := s.store.Prune(ctx, at.Add(-s.retention)); err != nil {
+			errs = append(errs, err)
+		} else if n > 0 {
+			slog.Debug("pruned intraday samples", "rows", n)
+		}
+	}
+	return samples, errors.Join(errs...)
+}
+
+func (s *Service) compute(ctx context.Context, id int) (decimal.Decimal, error) {
+	switch id {
+	case IDShareMarketPrice:
+		return s.prices.GetAverageTradePrice(ctx, domain.NewAssetInfo("MTL", domain.IssuerAddress), domain.EURMTLAsset(), tradesWindow)
+	case IDOperatingBalance:
+		return s.operatingBalance(ctx)
+	}
+	return decimal.Zero, fmt.Errorf("no sampler for I%d", id)
+}
+
+// operatingBalance is I4 as the daily calculator defines it: the EURMTL of
+// every sub-fund plus its XLM at the XLM → EURMTL price.
+func (s *Service) operatingBalance(ctx context.Context) (decimal.Decimal, error) {
+	xlm, err := s.prices.GetPrice(ctx, domain.XLMAsset(), domain.EURMTLAsset(), "1")
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("pricing XLM: %w", err)
+	}
+	xlmPrice, err := decimal.NewFromString(xlm.Price)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("parsing XLM price %q: %w", xlm.Price, err)
+	}
+	eurmtl := domain.EURMTLAsset()
+	total := decimal.Zero
+	for _, acc := range domain.AccountRegistry() {
+		if acc.Type != domain.AccountTypeSubfond {
+			continue
+		}
+		a, err := s.accounts.FetchAccount(ctx, acc.Address)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		for _, b := range a.Balances {
+			switch {
+			case b.AssetType == "native":
+				total = total.Add(domain.SafeParse(b.Balance).Mul(xlmPrice))
+			case b.AssetCode == eurmtl.Code && b.AssetIssuer == eurmtl.Issuer:
+				total = total.Add(domain.SafeParse(b.Balance))
+			}
+		}
+	}
+	return total, nil
+}
+
+// Run samples now and then every interval until ctx is cancelled. Failures
+// are logged and retried on the next tick.
+func (s *Service) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		samples, err := s.Sample(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			slog.Error("intraday sampling failed", "sampled", len(samples), "error", err)
+		default:
+			slog.Info("intraday sample taken", "indicators", len(samples))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// History returns the samples of indicator id taken in the last `hours`
+// hours, oldest first.
+func (s *Service) History(ctx context.Context, id, hours int) ([]Sample, error) {
+	return s.store.Since(ctx, s.slug, id, s.now().UTC().Add(-time.Duration(hours)*time.Hour))
+}
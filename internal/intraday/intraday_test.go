@@ -0,0 +1,103 @@
+package intraday
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+type stubPrices struct {
+	tradesErr error
+}
+
+func (s stubPrices) GetAverageTradePrice(context.Context, domain.AssetInfo, domain.AssetInfo, int) (decimal.Decimal, error) {
+	return decimal.RequireFromString("8.123456789"), s.tradesErr
+}
+
+func (stubPrices) GetPrice(context.Context, domain.AssetInfo, domain.AssetInfo, string) (domain.TokenPairPrice, error) {
+	return domain.TokenPairPrice{Price: "0.25"}, nil
+}
+
+// stubAccounts gives every sub-fund 100 EURMTL and 40 XLM.
+type stubAccounts struct{}
+
+func (stubAccounts) FetchAccount(_ context.Context, id string) (horizon.HorizonAccount, error) {
+	eurmtl := domain.EURMTLAsset()
+	return horizon.HorizonAccount{ID: id, Balances: []horizon.HorizonBalance{
+		{AssetType: "native", Balance: "40"},
+		{AssetType: "credit_alphanum12", AssetCode: eurmtl.Code, AssetIssuer: eurmtl.Issuer, Balance: "100"},
+		{AssetType: "credit_alphanum4", AssetCode: "MTL", AssetIssuer: domain.IssuerAddress, Balance: "5"},
+	}}, nil
+}
+
+type memStore struct {
+	samples []Sample
+	pruned  time.Time
+}
+
+func (m *memStore) Save(_ context.Context, _ string, samples []Sample) error {
+	m.samples = append(m.samples, samples...)
+	return nil
+}
+
+func (m *memStore) Since(_ context.Context, _ string, id int, since time.Time) ([]Sample, error) {
+	var out []Sample
+	for _, s := range m.samples {
+		if s.IndicatorID == id && !s.SampledAt.Before(since) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) Prune(_ context.Context, before time.Time) (int64, error) {
+	m.pruned = before
+	return 0, nil
+}
+
+func TestSample(t *testing.T) {
+	now := time.Date(2026, 10, 2, 14, 0, 0, 0, time.UTC)
+	store := &memStore{}
+	svc := NewService(stubPrices{}, stubAccounts{}, store, "mtlf", 30*24*time.Hour)
+	svc.now = func() time.Time { return now }
+
+	samples, err := svc.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	subfunds := 0
+	for _, a := range domain.AccountRegistry() {
+		if a.Type == domain.AccountTypeSubfond {
+			subfunds++
+		}
+	}
+	// Each sub-fund: 100 EURMTL + 40 XLM × 0.25.
+	wantI4 := decimal.NewFromInt(int64(110 * subfunds))
+	if len(samples) != 2 || !samples[0].Value.Equal(wantI4) || samples[1].Value.String() != "8.1234568" {
+		t.Errorf("samples = %+v, want I4 = %s and I10 at 7 places", samples, wantI4)
+	}
+	if !store.pruned.Equal(now.AddDate(0, 0, -30)) {
+		t.Errorf("pruned before %v", store.pruned)
+	}
+
+	// A failed indicator is left out; the other is still stored.
+	svc.prices = stubPrices{tradesErr: errors.New("horizon down")}
+	svc.now = func() time.Time { return now.Add(time.Hour) }
+	if samples, err := svc.Sample(context.Background()); err == nil || len(samples) != 1 || samples[0].IndicatorID != IDOperatingBalance {
+		t.Errorf("partial sample = %+v, %v", samples, err)
+	}
+
+	hist, err := svc.History(context.Background(), IDOperatingBalance, 2)
+	if err != nil || len(hist) != 2 {
+		t.Errorf("History(I4, 2h) = %+v, %v; want both samples", hist, err)
+	}
+	if hist, _ := svc.History(context.Background(), IDShareMarketPrice, 0); len(hist) != 0 {
+		t.Errorf("History(I10, 0h) = %+v, want none", hist)
+	}
+}
@@ -0,0 +1,77 @@
+package intraday
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores samples in intraday_metrics.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL intraday sample repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Save stores samples; a sample at a time already stored replaces it.
+func (r *PgRepository) Save(ctx context.Context, slug string, samples []Sample) error {
+	var entityID int
+	if err := r.pool.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	batch := &pgx.Batch{}
+	for _, s := range samples {
+		batch.Queue(
+			`INSERT INTO intraday_metrics (entity_id, indicator_id, sampled_at, value)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (entity_id, indicator_id, sampled_at) DO UPDATE SET value = EXCLUDED.value`,
+			entityID, s.IndicatorID, s.SampledAt, s.Value)
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("saving intraday samples: %w", err)
+	}
+	return nil
+}
+
+// Since returns the samples of indicatorID taken at or after since, oldest
+// first.
+func (r *PgRepository) Since(ctx context.Context, slug string, indicatorID int, since time.Time) ([]Sample, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT im.sampled_at, im.value
+		 FROM intraday_metrics im
+		 JOIN fund_entities fe ON fe.id = im.entity_id
+		 WHERE fe.slug = $1 AND im.indicator_id = $2 AND im.sampled_at >= $3
+		 ORDER BY im.sampled_at`, slug, indicatorID, since)
+	if err != nil {
+		return nil, fmt.Errorf("loading intraday samples of I%d: %w", indicatorID, err)
+	}
+	defer rows.Close()
+
+	var out []Sample
+	for rows.Next() {
+		s := Sample{IndicatorID: indicatorID}
+		if err := rows.Scan(&s.SampledAt, &s.Value); err != nil {
+			return nil, fmt.Errorf("scanning intraday sample: %w", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating intraday samples: %w", err)
+	}
+	return out, nil
+}
+
+// Prune deletes the samples taken before before and returns how many.
+func (r *PgRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM intraday_metrics WHERE sampled_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("pruning intraday samples: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,196 @@
+// Package issuance detects changes in MTL and MTLRECT supply between
+// consecutive snapshots and attributes them to the issuer operations behind
+// them: who received newly issued shares, and who returned shares in a
+// buyback. Without it a supply change only shows up as a silent move in I5,
+// I6 and I7.
+package issuance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Kind is the direction of a supply change.
+type Kind string
+
+const (
+	// KindIssuance is new supply: the issuer sent shares out.
+	KindIssuance Kind = "issuance"
+	// KindBuyback is burned supply: shares returned to the issuer or clawed back.
+	KindBuyback Kind = "buyback"
+)
+
+// Transfer is one issuer operation behind an event.
+type Transfer struct {
+	Account   string          `json:"account"` // recipient of an issuance, sender of a buyback
+	Amount    decimal.Decimal `json:"amount"`
+	Kind      Kind            `json:"kind"`
+	Operation string          `json:"operation"`
+	TxHash    string          `json:"txHash"`
+	At        time.Time       `json:"at"`
+}
+
+// Event is a supply change of one asset between two snapshots.
+type Event struct {
+	Date       time.Time       `json:"date"`     // snapshot that saw the new supply
+	PrevDate   time.Time       `json:"prevDate"` // snapshot it was compared with
+	Asset      string          `json:"asset"`
+	Kind       Kind            `json:"kind"`
+	Amount     decimal.Decimal `json:"amount"` // |supply − prevSupply|
+	PrevSupply decimal.Decimal `json:"prevSupply"`
+	Supply     decimal.Decimal `json:"supply"`
+	Transfers  []Transfer      `json:"transfers"`
+	// Unattributed is the part of the net change the transfers don't
+	// explain, e.g. claimable balances or operations outside the window.
+	Unattributed decimal.Decimal `json:"unattributed"`
+}
+
+// supplies lists the tracked assets with their supply field in live metrics.
+var supplies = []struct {
+	code   string
+	supply func(*domain.FundLiveMetrics) *string
+}{
+	{"MTL", func(m *domain.FundLiveMetrics) *string { return m.MTLSupply }},
+	{"MTLRECT", func(m *domain.FundLiveMetrics) *string { return m.MTLRECTSupply }},
+}
+
+// Detect returns one event per asset whose total supply differs between
+// prev and cur. Assets without a supply in either snapshot (snapshots older
+// than supply tracking, or a failed fetch) are skipped.
+func Detect(prevDate, date time.Time, prev, cur *domain.FundLiveMetrics) []Event {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	var out []Event
+	for _, s := range supplies {
+		p, c := s.supply(prev), s.supply(cur)
+		if p == nil || c == nil {
+			continue
+		}
+		before, after := domain.SafeParse(*p), domain.SafeParse(*c)
+		if before.Equal(after) {
+			continue
+		}
+		kind := KindIssuance
+		if after.LessThan(before) {
+			kind = KindBuyback
+		}
+		out = append(out, Event{
+			Date:       date,
+			PrevDate:   prevDate,
+			Asset:      s.code,
+			Kind:       kind,
+			Amount:     after.Sub(before).Abs(),
+			PrevSupply: before,
+			Supply:     after,
+		})
+	}
+	return out
+}
+
+// Attribute fills e.Transfers from the issuer operations of its asset and
+// sets Unattributed to the net change they leave unexplained.
+func Attribute(e *Event, issuer string, ops []horizon.IssuerOperation) {
+	e.Transfers = make([]Transfer, 0, len(ops))
+	net := decimal.Zero
+	for _, op := range ops {
+		t := Transfer{Amount: op.Amount, Operation: op.Type, TxHash: op.TxHash, At: op.TS}
+		if op.Mint(issuer) {
+			t.Kind, t.Account = KindIssuance, op.To
+			net = net.Add(op.Amount)
+		} else {
+			t.Kind, t.Account = KindBuyback, op.From
+			net = net.Sub(op.Amount)
+		}
+		e.Transfers = append(e.Transfers, t)
+	}
+	e.Unattributed = e.Supply.Sub(e.PrevSupply).Sub(net)
+}
+
+// Note renders events as one line for the MONITORING annotation column,
+// e.g. "MTL +1000 issuance; MTLRECT −50 buyback".
+func Note(events []Event) string {
+	parts := make([]string, 0, len(events))
+	for _, e := range events {
+		sign := "+"
+		if e.Kind == KindBuyback {
+			sign = "−"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s%s %s", e.Asset, sign, e.Amount.String(), e.Kind))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// OperationSource walks the issuer's operations (horizon.Client).
+type OperationSource interface {
+	FetchIssuerOperations(ctx context.Context, asset domain.AssetInfo, since time.Time) ([]horizon.IssuerOperation, error)
+}
+
+// SnapshotSource finds the snapshot to compare with (snapshot.PgRepository).
+type SnapshotSource interface {
+	GetNearestBefore(ctx context.Context, slug string, date time.Time) (*snapshot.Snapshot, error)
+}
+
+// Store persists events (PgRepository).
+type Store interface {
+	Save(ctx context.Context, slug string, date time.Time, events []Event) error
+}
+
+// Service records the supply changes of each new snapshot.
+type Service struct {
+	ops       OperationSource
+	snapshots SnapshotSource
+	store     Store
+	slug      string
+}
+
+// NewService creates a Service for the entity slug.
+func NewService(ops OperationSource, snapshots SnapshotSource, store Store, slug string) *Service {
+	return &Service{ops: ops, snapshots: snapshots, store: store, slug: slug}
+}
+
+// Record compares data, the snapshot just generated for date, with the
+// latest stored snapshot before date, attributes each supply change to the
+// issuer operations since that snapshot was written, and replaces the
+// stored events of date. Without an earlier snapshot nothing is recorded.
+func (s *Service) Record(ctx context.Context, date time.Time, data domain.FundStructureData) ([]Event, error) {
+	prev, err := s.snapshots.GetNearestBefore(ctx, s.slug, date.AddDate(0, 0, -1))
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading previous snapshot: %w", err)
+	}
+	var prevData domain.FundStructureData
+	if err := json.Unmarshal(prev.Data, &prevData); err != nil {
+		return nil, fmt.Errorf("decoding snapshot %s: %w", prev.SnapshotDate.Format("2006-01-02"), err)
+	}
+
+	events := Detect(prev.SnapshotDate, date, prevData.LiveMetrics, data.LiveMetrics)
+	for i := range events {
+		asset := domain.NewAssetInfo(events[i].Asset, domain.IssuerAddress)
+		ops, err := s.ops.FetchIssuerOperations(ctx, asset, prev.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s issuer operations: %w", events[i].Asset, err)
+		}
+		Attribute(&events[i], domain.IssuerAddress, ops)
+		slog.Info("supply change detected", "asset", events[i].Asset, "kind", events[i].Kind,
+			"amount", events[i].Amount.String(), "transfers", len(events[i].Transfers), "unattributed", events[i].Unattributed.String())
+	}
+
+	if err := s.store.Save(ctx, s.slug, date, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,138 @@
+package issuance
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func strPtr(s string) *string { return &s }
+
+var (
+	day1 = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 = time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+)
+
+func TestDetect(t *testing.T) {
+	prev := &domain.FundLiveMetrics{MTLSupply: strPtr("1000"), MTLRECTSupply: strPtr("500")}
+	cur := &domain.FundLiveMetrics{MTLSupply: strPtr("1100"), MTLRECTSupply: strPtr("480")}
+
+	events := Detect(day1, day2, prev, cur)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if e := events[0]; e.Asset != "MTL" || e.Kind != KindIssuance || !e.Amount.Equal(decimal.NewFromInt(100)) || !e.PrevDate.Equal(day1) {
+		t.Errorf("MTL event = %+v", e)
+	}
+	if e := events[1]; e.Asset != "MTLRECT" || e.Kind != KindBuyback || !e.Amount.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("MTLRECT event = %+v", e)
+	}
+	if got := Note(events); got != "MTL +100 issuance; MTLRECT −20 buyback" {
+		t.Errorf("Note = %q", got)
+	}
+}
+
+func TestDetectSkipsUnchangedAndUntracked(t *testing.T) {
+	prev := &domain.FundLiveMetrics{MTLSupply: strPtr("1000")} // predates MTLRECT supply tracking
+	cur := &domain.FundLiveMetrics{MTLSupply: strPtr("1000.0000000"), MTLRECTSupply: strPtr("480")}
+	if events := Detect(day1, day2, prev, cur); len(events) != 0 {
+		t.Errorf("got %+v, want no events", events)
+	}
+	if events := Detect(day1, day2, nil, cur); len(events) != 0 {
+		t.Errorf("got %+v without previous metrics, want no events", events)
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	e := Event{Asset: "MTL", Kind: KindIssuance, PrevSupply: decimal.NewFromInt(1000), Supply: decimal.NewFromInt(1100), Amount: decimal.NewFromInt(100)}
+	Attribute(&e, "GISSUER", []horizon.IssuerOperation{
+		{Type: "payment", From: "GISSUER", To: "GALICE", Amount: decimal.NewFromInt(150), TxHash: "tx1"},
+		{Type: "payment", From: "GBOB", To: "GISSUER", Amount: decimal.NewFromInt(30), TxHash: "tx2"},
+		{Type: "clawback", From: "GCAROL", Amount: decimal.NewFromInt(10), TxHash: "tx3"},
+	})
+	want := []Transfer{
+		{Account: "GALICE", Kind: KindIssuance},
+		{Account: "GBOB", Kind: KindBuyback},
+		{Account: "GCAROL", Kind: KindBuyback},
+	}
+	for i, w := range want {
+		if got := e.Transfers[i]; got.Account != w.Account || got.Kind != w.Kind {
+			t.Errorf("transfer %d = %+v, want %s %s", i, got, w.Kind, w.Account)
+		}
+	}
+	// net transfers 150 − 30 − 10 = 110 against a change of 100
+	if !e.Unattributed.Equal(decimal.NewFromInt(-10)) {
+		t.Errorf("unattributed = %s, want -10", e.Unattributed)
+	}
+}
+
+type stubSnapshots struct {
+	snap *snapshot.Snapshot
+}
+
+func (s stubSnapshots) GetNearestBefore(context.Context, string, time.Time) (*snapshot.Snapshot, error) {
+	if s.snap == nil {
+		return nil, snapshot.ErrNotFound
+	}
+	return s.snap, nil
+}
+
+type stubOps struct {
+	since time.Time
+	ops   []horizon.IssuerOperation
+}
+
+func (s *stubOps) FetchIssuerOperations(_ context.Context, _ domain.AssetInfo, since time.Time) ([]horizon.IssuerOperation, error) {
+	s.since = since
+	return s.ops, nil
+}
+
+type stubStore struct {
+	saved  bool
+	events []Event
+}
+
+func (s *stubStore) Save(_ context.Context, _ string, _ time.Time, events []Event) error {
+	s.saved, s.events = true, events
+	return nil
+}
+
+func TestServiceRecord(t *testing.T) {
+	prevData, _ := json.Marshal(domain.FundStructureData{LiveMetrics: &domain.FundLiveMetrics{MTLSupply: strPtr("1000")}})
+	created := day1.Add(2 * time.Hour)
+	ops := &stubOps{ops: []horizon.IssuerOperation{
+		{Type: "payment", From: domain.IssuerAddress, To: "GALICE", Amount: decimal.NewFromInt(100)},
+	}}
+	store := &stubStore{}
+	svc := NewService(ops, stubSnapshots{&snapshot.Snapshot{SnapshotDate: day1, CreatedAt: created, Data: prevData}}, store, "mtlf")
+
+	events, err := svc.Record(context.Background(), day2,
+		domain.FundStructureData{LiveMetrics: &domain.FundLiveMetrics{MTLSupply: strPtr("1100")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || len(events[0].Transfers) != 1 || !events[0].Unattributed.IsZero() {
+		t.Fatalf("events = %+v", events)
+	}
+	if !ops.since.Equal(created) {
+		t.Errorf("operations walked since %s, want previous snapshot creation %s", ops.since, created)
+	}
+	if !store.saved || len(store.events) != 1 {
+		t.Errorf("store = %+v", store)
+	}
+}
+
+func TestServiceRecordWithoutPreviousSnapshot(t *testing.T) {
+	store := &stubStore{}
+	events, err := NewService(&stubOps{}, stubSnapshots{}, store, "mtlf").Record(context.Background(), day2, domain.FundStructureData{})
+	if err != nil || events != nil || store.saved {
+		t.Errorf("events = %v, err = %v, saved = %v; want nothing recorded", events, err, store.saved)
+	}
+}
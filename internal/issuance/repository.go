@@ -0,0 +1,102 @@
+package issuance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores events in issuance_events.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL issuance event repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Save replaces the events of date with events.
+func (r *PgRepository) Save(ctx context.Context, slug string, date time.Time, events []Event) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning issuance event tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM issuance_events WHERE entity_id = $1 AND event_date = $2`, entityID, date); err != nil {
+		return fmt.Errorf("clearing issuance events for %s: %w", date.Format("2006-01-02"), err)
+	}
+	for _, e := range events {
+		transfers, err := json.Marshal(e.Transfers)
+		if err != nil {
+			return fmt.Errorf("encoding %s transfers: %w", e.Asset, err)
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO issuance_events (entity_id, event_date, asset, prev_date, kind, amount,
+			     prev_supply, supply, unattributed, transfers)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			entityID, date, e.Asset, e.PrevDate, string(e.Kind), e.Amount,
+			e.PrevSupply, e.Supply, e.Unattributed, transfers); err != nil {
+			return fmt.Errorf("saving %s issuance event for %s: %w", e.Asset, date.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing issuance events: %w", err)
+	}
+	return nil
+}
+
+// List returns the events between from and to (inclusive; zero means
+// unbounded), oldest first.
+func (r *PgRepository) List(ctx context.Context, slug string, from, to time.Time) ([]Event, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ie.event_date, ie.prev_date, ie.asset, ie.kind, ie.amount,
+		        ie.prev_supply, ie.supply, ie.unattributed, ie.transfers
+		 FROM issuance_events ie
+		 JOIN fund_entities fe ON fe.id = ie.entity_id
+		 WHERE fe.slug = $1
+		   AND ($2::date IS NULL OR ie.event_date >= $2)
+		   AND ($3::date IS NULL OR ie.event_date <= $3)
+		 ORDER BY ie.event_date, ie.asset`,
+		slug, nullDate(from), nullDate(to))
+	if err != nil {
+		return nil, fmt.Errorf("listing issuance events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		var e Event
+		var kind string
+		var transfers []byte
+		if err := rows.Scan(&e.Date, &e.PrevDate, &e.Asset, &kind, &e.Amount,
+			&e.PrevSupply, &e.Supply, &e.Unattributed, &transfers); err != nil {
+			return nil, fmt.Errorf("scanning issuance event: %w", err)
+		}
+		e.Kind = Kind(kind)
+		if err := json.Unmarshal(transfers, &e.Transfers); err != nil {
+			return nil, fmt.Errorf("decoding transfers of %s %s: %w", e.Asset, e.Date.Format("2006-01-02"), err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating issuance events: %w", err)
+	}
+	return out, nil
+}
+
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,197 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mtlprog/stat/internal/progress"
+)
+
+// ErrNotFound indicates that the requested job was not found.
+var ErrNotFound = errors.New("job not found")
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// KindSnapshotGenerate is the only job kind today: the full report pipeline for a date.
+const KindSnapshotGenerate = "snapshot_generate"
+
+// Job is a persisted background job.
+type Job struct {
+	ID           int64           `json:"id"`
+	Kind         string          `json:"kind"`
+	EntityID     int             `json:"entityId"`
+	SnapshotDate time.Time       `json:"snapshotDate"`
+	Status       string          `json:"status"`
+	Progress     *progress.Event `json:"progress,omitempty"`
+	Result       json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error        string          `json:"error,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	StartedAt    *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// Repository defines persistent storage for jobs.
+type Repository interface {
+	// Create inserts a queued job. If an identical job is already queued or
+	// running, that job is returned with created=false.
+	Create(ctx context.Context, kind string, entityID int, date time.Time) (j *Job, created bool, err error)
+	Get(ctx context.Context, id int64) (*Job, error)
+	ListQueued(ctx context.Context) ([]Job, error)
+	MarkRunning(ctx context.Context, id int64) error
+	UpdateProgress(ctx context.Context, id int64, ev progress.Event) error
+	Finish(ctx context.Context, id int64, status string, result json.RawMessage, errMsg string) error
+	// FailRunning marks every running job as failed. Called on startup: a job
+	// still "running" then was interrupted by a crash or redeploy.
+	FailRunning(ctx context.Context, reason string) (int64, error)
+}
+
+// PgRepository implements Repository with PostgreSQL.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL job repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const jobColumns = `id, kind, entity_id, snapshot_date, status, progress, result, COALESCE(error, ''), created_at, started_at, finished_at`
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var j Job
+	var prog []byte
+	if err := row.Scan(&j.ID, &j.Kind, &j.EntityID, &j.SnapshotDate, &j.Status, &prog, &j.Result,
+		&j.Error, &j.CreatedAt, &j.StartedAt, &j.FinishedAt); err != nil {
+		return nil, err
+	}
+	if len(prog) > 0 {
+		var ev progress.Event
+		if err := json.Unmarshal(prog, &ev); err != nil {
+			return nil, fmt.Errorf("decoding job progress: %w", err)
+		}
+		j.Progress = &ev
+	}
+	return &j, nil
+}
+
+func (r *PgRepository) Create(ctx context.Context, kind string, entityID int, date time.Time) (*Job, bool, error) {
+	j, err := scanJob(r.pool.QueryRow(ctx,
+		`INSERT INTO jobs (kind, entity_id, snapshot_date, status)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+jobColumns,
+		kind, entityID, date, StatusQueued))
+	if err == nil {
+		return j, true, nil
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return nil, false, fmt.Errorf("creating job: %w", err)
+	}
+
+	// Unique violation on idx_jobs_active: reuse the in-flight job.
+	j, err = scanJob(r.pool.QueryRow(ctx,
+		`SELECT `+jobColumns+`
+		 FROM jobs
+		 WHERE kind = $1 AND entity_id = $2 AND snapshot_date = $3
+		   AND status IN ('queued', 'running')`,
+		kind, entityID, date))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The active job finished between the two statements.
+			return r.Create(ctx, kind, entityID, date)
+		}
+		return nil, false, fmt.Errorf("getting active job: %w", err)
+	}
+	return j, false, nil
+}
+
+func (r *PgRepository) Get(ctx context.Context, id int64) (*Job, error) {
+	j, err := scanJob(r.pool.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting job %d: %w", id, err)
+	}
+	return j, nil
+}
+
+func (r *PgRepository) ListQueued(ctx context.Context) ([]Job, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+jobColumns+` FROM jobs WHERE status = $1 ORDER BY created_at, id`, StatusQueued)
+	if err != nil {
+		return nil, fmt.Errorf("listing queued jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+		jobs = append(jobs, *j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *PgRepository) MarkRunning(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET status = $2, started_at = NOW() WHERE id = $1`, id, StatusRunning)
+	if err != nil {
+		return fmt.Errorf("marking job %d running: %w", id, err)
+	}
+	return nil
+}
+
+func (r *PgRepository) UpdateProgress(ctx context.Context, id int64, ev progress.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshaling progress: %w", err)
+	}
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET progress = $2::jsonb WHERE id = $1`, id, data); err != nil {
+		return fmt.Errorf("updating job %d progress: %w", id, err)
+	}
+	return nil
+}
+
+func (r *PgRepository) Finish(ctx context.Context, id int64, status string, result json.RawMessage, errMsg string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs
+		 SET status = $2, result = $3::jsonb, error = NULLIF($4, ''), finished_at = NOW()
+		 WHERE id = $1`,
+		id, status, result, errMsg)
+	if err != nil {
+		return fmt.Errorf("finishing job %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *PgRepository) FailRunning(ctx context.Context, reason string) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET status = $1, error = $2, finished_at = NOW() WHERE status = $3`,
+		StatusFailed, reason, StatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failing interrupted jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
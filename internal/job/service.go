@@ -0,0 +1,227 @@
+// Package job runs snapshot generation in the background for the API. Jobs are
+// persisted in the jobs table, which doubles as the queue, so a restart neither
+// loses queued work nor leaves a job stuck in "running".
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/progress"
+)
+
+// Result is stored on a succeeded job. Errors lists calculators that failed or
+// were skipped; their indicators are missing from the persisted set.
+type Result struct {
+	SnapshotDate   string              `json:"snapshotDate"`
+	IndicatorCount int                 `json:"indicatorCount"`
+	Errors         []indicator.Failure `json:"errors,omitempty"`
+}
+
+// Generator runs the report pipeline for a date.
+type Generator interface {
+	GenerateReport(ctx context.Context, date time.Time) (Result, error)
+}
+
+// progressInterval throttles per-token progress writes; stage and account
+// events are always persisted.
+const progressInterval = 2 * time.Second
+
+// writeTimeout bounds bookkeeping writes made after the job context is gone.
+const writeTimeout = 5 * time.Second
+
+// Service enqueues jobs and runs them one at a time.
+type Service struct {
+	repo     Repository
+	gen      Generator
+	entityID int
+	wake     chan struct{}
+	timeout  time.Duration // 0 means none
+	grace    time.Duration
+}
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithTimeout fails a job that runs longer than d.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Service) {
+		s.timeout = d
+	}
+}
+
+// WithShutdownGrace lets the in-flight job run for up to d after Run's
+// context is cancelled, so a shutdown shortly before the end of a run still
+// stores it. Without it the job is cancelled at once.
+func WithShutdownGrace(d time.Duration) Option {
+	return func(s *Service) {
+		s.grace = d
+	}
+}
+
+// NewService creates a job Service for the given entity.
+func NewService(repo Repository, gen Generator, entityID int, opts ...Option) *Service {
+	s := &Service{repo: repo, gen: gen, entityID: entityID, wake: make(chan struct{}, 1)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Enqueue queues snapshot generation for date. If one is already queued or
+// running for that date it is returned instead of creating a duplicate. The
+// write is detached from ctx, so a client that disconnects mid-request
+// doesn't lose a job it may already have been told about.
+func (s *Service) Enqueue(ctx context.Context, date time.Time) (*Job, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+	j, created, err := s.repo.Create(ctx, KindSnapshotGenerate, s.entityID, date)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		slog.Info("job queued", "id", j.ID, "kind", j.Kind, "date", date.Format("2006-01-02"))
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return j, nil
+}
+
+// Get returns a job by ID.
+func (s *Service) Get(ctx context.Context, id int64) (*Job, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Run processes queued jobs until ctx is cancelled. Jobs left running by a
+// previous process are marked failed first; queued ones are picked up.
+// Cancelling ctx cancels the in-flight job after the shutdown grace; a job
+// cancelled before it stores its snapshot saves nothing.
+func (s *Service) Run(ctx context.Context) error {
+	n, err := s.repo.FailRunning(ctx, "interrupted by server restart")
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("marked interrupted jobs as failed", "count", n)
+	}
+
+	for {
+		queued, err := s.repo.ListQueued(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Error("failed to list queued jobs", "error", err)
+		}
+		for _, j := range queued {
+			if ctx.Err() != nil {
+				return nil
+			}
+			s.process(ctx, j)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *Service) process(ctx context.Context, j Job) {
+	if err := s.repo.MarkRunning(ctx, j.ID); err != nil {
+		slog.Error("failed to mark job running", "id", j.ID, "error", err)
+		return
+	}
+	slog.Info("job started", "id", j.ID, "kind", j.Kind, "date", j.SnapshotDate.Format("2006-01-02"))
+
+	runCtx, cancel := s.runContext(ctx)
+	rep := &reporter{repo: s.repo, id: j.ID}
+	res, err := s.gen.GenerateReport(progress.WithReporter(runCtx, rep), j.SnapshotDate)
+	timedOut := errors.Is(runCtx.Err(), context.DeadlineExceeded)
+	cancel()
+
+	// The job context may already be cancelled; bookkeeping must still land.
+	wctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+
+	status := StatusSucceeded
+	var result json.RawMessage
+	var errMsg string
+	switch {
+	case err == nil:
+		result, err = json.Marshal(res)
+		if err != nil {
+			status, errMsg = StatusFailed, fmt.Sprintf("marshaling result: %v", err)
+		}
+	case timedOut:
+		status, errMsg = StatusFailed, fmt.Sprintf("timed out after %s: %v", s.timeout, err)
+	case progress.IsCancelled(err):
+		status, errMsg = StatusCancelled, err.Error()
+	default:
+		status, errMsg = StatusFailed, err.Error()
+	}
+
+	if err := s.repo.Finish(wctx, j.ID, status, result, errMsg); err != nil {
+		slog.Error("failed to record job result", "id", j.ID, "status", status, "error", err)
+		return
+	}
+	if errMsg != "" {
+		slog.Error("job finished", "id", j.ID, "status", status, "error", errMsg)
+		return
+	}
+	slog.Info("job finished", "id", j.ID, "status", status)
+}
+
+// runContext returns the context a job runs under: detached from ctx, bounded
+// by the job timeout, and cancelled once the shutdown grace has passed after
+// ctx is cancelled.
+func (s *Service) runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		if s.grace <= 0 {
+			cancel()
+			return
+		}
+		slog.Info("shutting down, letting the running job finish", "grace", s.grace)
+		time.AfterFunc(s.grace, cancel)
+	})
+	if s.timeout <= 0 {
+		return runCtx, func() { stop(); cancel() }
+	}
+	runCtx, cancelTimeout := context.WithTimeout(runCtx, s.timeout)
+	return runCtx, func() { stop(); cancelTimeout(); cancel() }
+}
+
+// reporter persists progress events onto the job row.
+type reporter struct {
+	repo Repository
+	id   int64
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (r *reporter) Report(ev progress.Event) {
+	r.mu.Lock()
+	if ev.Token != "" && time.Since(r.last) < progressInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.last = time.Now()
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := r.repo.UpdateProgress(ctx, r.id, ev); err != nil {
+		slog.Debug("failed to persist job progress", "id", r.id, "error", err)
+	}
+}
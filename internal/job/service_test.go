@@ -0,0 +1,291 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/progress"
+)
+
+type mockRepo struct {
+	mu     sync.Mutex
+	jobs   map[int64]*Job
+	nextID int64
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{jobs: make(map[int64]*Job)}
+}
+
+func (m *mockRepo) Create(ctx context.Context, kind string, entityID int, date time.Time) (*Job, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.Kind == kind && j.EntityID == entityID && j.SnapshotDate.Equal(date) &&
+			(j.Status == StatusQueued || j.Status == StatusRunning) {
+			cp := *j
+			return &cp, false, nil
+		}
+	}
+	m.nextID++
+	j := &Job{ID: m.nextID, Kind: kind, EntityID: entityID, SnapshotDate: date, Status: StatusQueued, CreatedAt: time.Now()}
+	m.jobs[j.ID] = j
+	cp := *j
+	return &cp, true, nil
+}
+
+func (m *mockRepo) Get(_ context.Context, id int64) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *j
+	return &cp, nil
+}
+
+func (m *mockRepo) ListQueued(_ context.Context) ([]Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Job
+	for id := int64(1); id <= m.nextID; id++ {
+		if j, ok := m.jobs[id]; ok && j.Status == StatusQueued {
+			out = append(out, *j)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) MarkRunning(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[id].Status = StatusRunning
+	return nil
+}
+
+func (m *mockRepo) UpdateProgress(_ context.Context, id int64, ev progress.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[id].Progress = &ev
+	return nil
+}
+
+func (m *mockRepo) Finish(_ context.Context, id int64, status string, result json.RawMessage, errMsg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.jobs[id]
+	j.Status, j.Result, j.Error = status, result, errMsg
+	return nil
+}
+
+func (m *mockRepo) FailRunning(_ context.Context, reason string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, j := range m.jobs {
+		if j.Status == StatusRunning {
+			j.Status, j.Error = StatusFailed, reason
+			n++
+		}
+	}
+	return n, nil
+}
+
+type mockGenerator struct {
+	err   error
+	calls int
+}
+
+func (g *mockGenerator) GenerateReport(ctx context.Context, date time.Time) (Result, error) {
+	g.calls++
+	progress.Report(ctx, progress.Event{Stage: progress.StageIndicators})
+	if g.err != nil {
+		return Result{}, g.err
+	}
+	return Result{SnapshotDate: date.Format("2006-01-02"), IndicatorCount: 42}, nil
+}
+
+var testDate = time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+func TestEnqueueDeduplicatesActiveJob(t *testing.T) {
+	svc := NewService(newMockRepo(), &mockGenerator{}, 1)
+
+	first, err := svc.Enqueue(context.Background(), testDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := svc.Enqueue(context.Background(), testDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.ID != second.ID {
+		t.Errorf("second Enqueue created job %d, want reuse of %d", second.ID, first.ID)
+	}
+}
+
+func TestProcessSuccess(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo, &mockGenerator{}, 1)
+	j, _ := svc.Enqueue(context.Background(), testDate)
+
+	svc.process(context.Background(), *j)
+
+	got, _ := repo.Get(context.Background(), j.ID)
+	if got.Status != StatusSucceeded {
+		t.Fatalf("Status = %q, want %q", got.Status, StatusSucceeded)
+	}
+	var res Result
+	if err := json.Unmarshal(got.Result, &res); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	if res.IndicatorCount != 42 || res.SnapshotDate != "2026-01-15" {
+		t.Errorf("Result = %+v, want 42 indicators for 2026-01-15", res)
+	}
+	if got.Progress == nil || got.Progress.Stage != progress.StageIndicators {
+		t.Errorf("Progress = %+v, want stage %q", got.Progress, progress.StageIndicators)
+	}
+}
+
+func TestProcessFailureAndCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"failure", errors.New("horizon down"), StatusFailed},
+		{"cancelled", context.Canceled, StatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			svc := NewService(repo, &mockGenerator{err: tt.err}, 1)
+			j, _ := svc.Enqueue(context.Background(), testDate)
+
+			svc.process(context.Background(), *j)
+
+			got, _ := repo.Get(context.Background(), j.ID)
+			if got.Status != tt.want {
+				t.Errorf("Status = %q, want %q", got.Status, tt.want)
+			}
+			if got.Error == "" {
+				t.Error("expected error message to be recorded")
+			}
+			if got.Result != nil {
+				t.Errorf("Result = %s, want nil", got.Result)
+			}
+		})
+	}
+}
+
+func TestRunRecoversAfterRestart(t *testing.T) {
+	repo := newMockRepo()
+	gen := &mockGenerator{}
+	svc := NewService(repo, gen, 1)
+
+	interrupted, _ := svc.Enqueue(context.Background(), testDate)
+	_ = repo.MarkRunning(context.Background(), interrupted.ID)
+	queued, _ := svc.Enqueue(context.Background(), testDate.AddDate(0, 0, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		got, _ := repo.Get(context.Background(), queued.ID)
+		if got.Status == StatusSucceeded {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("queued job not processed, status = %q", got.Status)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	got, _ := repo.Get(context.Background(), interrupted.ID)
+	if got.Status != StatusFailed {
+		t.Errorf("interrupted job Status = %q, want %q", got.Status, StatusFailed)
+	}
+	if gen.calls != 1 {
+		t.Errorf("generator calls = %d, want 1 (interrupted job must not be rerun)", gen.calls)
+	}
+}
+
+// blockingGenerator returns when its context ends or release is closed.
+type blockingGenerator struct{ release chan struct{} }
+
+func (g *blockingGenerator) GenerateReport(ctx context.Context, date time.Time) (Result, error) {
+	select {
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	case <-g.release:
+		return Result{SnapshotDate: date.Format("2006-01-02")}, nil
+	}
+}
+
+func TestProcessTimeout(t *testing.T) {
+	repo := newMockRepo()
+	gen := &blockingGenerator{release: make(chan struct{})}
+	svc := NewService(repo, gen, 1, WithTimeout(10*time.Millisecond))
+	j, _ := svc.Enqueue(context.Background(), testDate)
+
+	svc.process(context.Background(), *j)
+
+	got, _ := repo.Get(context.Background(), j.ID)
+	if got.Status != StatusFailed || !strings.Contains(got.Error, "timed out after 10ms") {
+		t.Errorf("job = %q %q, want failed with a timeout", got.Status, got.Error)
+	}
+}
+
+func TestProcessShutdownGrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		grace time.Duration
+		want  string
+	}{
+		{"finishes within the grace", time.Minute, StatusSucceeded},
+		{"cancelled without a grace", 0, StatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			gen := &blockingGenerator{release: make(chan struct{})}
+			svc := NewService(repo, gen, 1, WithShutdownGrace(tt.grace))
+			j, _ := svc.Enqueue(context.Background(), testDate)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			time.AfterFunc(20*time.Millisecond, func() { close(gen.release) })
+			svc.process(ctx, *j)
+
+			got, _ := repo.Get(context.Background(), j.ID)
+			if got.Status != tt.want {
+				t.Errorf("Status = %q, want %q", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnqueueSurvivesCancelledRequest(t *testing.T) {
+	svc := NewService(newMockRepo(), &mockGenerator{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := svc.Enqueue(ctx, testDate); err != nil {
+		t.Fatalf("Enqueue with a cancelled request context: %v", err)
+	}
+}
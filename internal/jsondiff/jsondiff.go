@@ -0,0 +1,198 @@
+// Package jsondiff computes and applies structural patches between two JSON
+// documents. Unlike RFC 7396 merge patches it diffs arrays element-wise, which
+// is what makes snapshot deltas small: nearly all of a snapshot lives in
+// accounts[].tokens[] arrays that change in a handful of positions per day.
+//
+// Patch format (every patch is a JSON object with exactly one shape):
+//
+//	{"$v": value}                        replace with value
+//	{"$o": {"key": patch}, "$d": [keys]} patch object members, delete $d keys
+//	{"$a": {"3": patch}, "$n": 12}       resize array to $n, patch listed indexes
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Diff returns a patch that turns base into target. equal is true (and patch
+// nil) when the documents are semantically identical.
+func Diff(base, target []byte) (patch json.RawMessage, equal bool, err error) {
+	b, err := decode(base)
+	if err != nil {
+		return nil, false, fmt.Errorf("decoding base: %w", err)
+	}
+	t, err := decode(target)
+	if err != nil {
+		return nil, false, fmt.Errorf("decoding target: %w", err)
+	}
+	p, changed := diff(b, t)
+	if !changed {
+		return nil, true, nil
+	}
+	out, err := json.Marshal(p)
+	if err != nil {
+		return nil, false, fmt.Errorf("encoding patch: %w", err)
+	}
+	return out, false, nil
+}
+
+// Apply applies patch to base and returns the resulting document.
+func Apply(base, patch []byte) (json.RawMessage, error) {
+	b, err := decode(base)
+	if err != nil {
+		return nil, fmt.Errorf("decoding base: %w", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("decoding patch: %w", err)
+	}
+	res, err := apply(b, p)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(res)
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep numeric text exact
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diff(base, target any) (map[string]any, bool) {
+	switch t := target.(type) {
+	case map[string]any:
+		if b, ok := base.(map[string]any); ok {
+			return diffObject(b, t)
+		}
+	case []any:
+		if b, ok := base.([]any); ok {
+			return diffArray(b, t)
+		}
+	}
+	if reflect.DeepEqual(base, target) {
+		return nil, false
+	}
+	return map[string]any{"$v": target}, true
+}
+
+func diffObject(base, target map[string]any) (map[string]any, bool) {
+	members := map[string]any{}
+	for k, tv := range target {
+		bv, ok := base[k]
+		if !ok {
+			members[k] = map[string]any{"$v": tv}
+			continue
+		}
+		if p, changed := diff(bv, tv); changed {
+			members[k] = p
+		}
+	}
+	var deleted []string
+	for k := range base {
+		if _, ok := target[k]; !ok {
+			deleted = append(deleted, k)
+		}
+	}
+	if len(members) == 0 && len(deleted) == 0 {
+		return nil, false
+	}
+	p := map[string]any{"$o": members}
+	if len(deleted) > 0 {
+		sort.Strings(deleted)
+		p["$d"] = deleted
+	}
+	return p, true
+}
+
+func diffArray(base, target []any) (map[string]any, bool) {
+	elems := map[string]any{}
+	for i, tv := range target {
+		if i >= len(base) {
+			elems[strconv.Itoa(i)] = map[string]any{"$v": tv}
+			continue
+		}
+		if p, changed := diff(base[i], tv); changed {
+			elems[strconv.Itoa(i)] = p
+		}
+	}
+	if len(elems) == 0 && len(base) == len(target) {
+		return nil, false
+	}
+	return map[string]any{"$a": elems, "$n": len(target)}, true
+}
+
+func apply(base, patch any) (any, error) {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("patch is %T, want object", patch)
+	}
+	if v, ok := p["$v"]; ok {
+		return v, nil
+	}
+	if members, ok := p["$o"].(map[string]any); ok {
+		b, ok := base.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("object patch applied to %T", base)
+		}
+		out := make(map[string]any, len(b)+len(members))
+		for k, v := range b {
+			out[k] = v
+		}
+		if del, ok := p["$d"].([]any); ok {
+			for _, k := range del {
+				ks, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("deleted key is %T, want string", k)
+				}
+				delete(out, ks)
+			}
+		}
+		for k, mp := range members {
+			v, err := apply(out[k], mp)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			out[k] = v
+		}
+		return out, nil
+	}
+	if elems, ok := p["$a"].(map[string]any); ok {
+		b, ok := base.([]any)
+		if !ok {
+			return nil, fmt.Errorf("array patch applied to %T", base)
+		}
+		num, ok := p["$n"].(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("array patch missing $n")
+		}
+		n, err := strconv.Atoi(num.String())
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid array length %q", num)
+		}
+		out := make([]any, n)
+		copy(out, b)
+		for k, ep := range elems {
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= n {
+				return nil, fmt.Errorf("invalid array index %q", k)
+			}
+			v, err := apply(out[i], ep)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unrecognized patch %v", p)
+}
@@ -0,0 +1,91 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func canonical(t *testing.T, data []byte) string {
+	t.Helper()
+	v, err := decode(data)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
+func TestDiffApplyRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		base, target string
+	}{
+		{"scalar change", `{"a":"1.0000000","b":2}`, `{"a":"1.5000000","b":2}`},
+		{"added and removed keys", `{"a":1,"b":2}`, `{"a":1,"c":3}`},
+		{"nested array element", `{"accounts":[{"tokens":[{"b":"1"},{"b":"2"}]}]}`, `{"accounts":[{"tokens":[{"b":"1"},{"b":"3"}]}]}`},
+		{"array grows", `{"w":["x"]}`, `{"w":["x","y","z"]}`},
+		{"array shrinks", `{"w":["x","y","z"]}`, `{"w":["x"]}`},
+		{"type change", `{"p":null}`, `{"p":{"q":1}}`},
+		{"top-level replace", `[1,2]`, `{"a":1}`},
+		{"big number kept exact", `{"n":1}`, `{"n":12345678901234567890.1234567}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, equal, err := Diff([]byte(tt.base), []byte(tt.target))
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			if equal {
+				t.Fatal("Diff reported equal for different documents")
+			}
+			got, err := Apply([]byte(tt.base), patch)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if canonical(t, got) != canonical(t, []byte(tt.target)) {
+				t.Errorf("Apply(base, Diff) = %s, want %s", got, tt.target)
+			}
+		})
+	}
+}
+
+func TestDiffEqual(t *testing.T) {
+	patch, equal, err := Diff([]byte(`{"a":[1,{"b":"x"}]}`), []byte(`{ "a": [1, {"b": "x"}] }`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !equal || patch != nil {
+		t.Errorf("Diff = %s, %v; want nil, true", patch, equal)
+	}
+}
+
+func TestDiffIsSmallForLocalChange(t *testing.T) {
+	var tokens []map[string]string
+	for range 500 {
+		tokens = append(tokens, map[string]string{"code": "TOKEN", "balance": "1000.0000000", "price": "0.1234567"})
+	}
+	base, _ := json.Marshal(map[string]any{"tokens": tokens})
+	tokens[250]["price"] = "0.2000000"
+	target, _ := json.Marshal(map[string]any{"tokens": tokens})
+
+	patch, _, err := Diff(base, target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(patch) > 100 {
+		t.Errorf("patch is %d bytes for a one-field change: %s", len(patch), patch)
+	}
+}
+
+func TestApplyRejectsMalformedPatch(t *testing.T) {
+	for _, patch := range []string{`[1]`, `{"$o":{"a":{"$v":1}}}`, `{"$a":{"5":{"$v":1}},"$n":2}`, `{"x":1}`} {
+		base := []byte(`[0,1]`)
+		if bytes.HasPrefix([]byte(patch), []byte(`{"$o"`)) {
+			base = []byte(`"scalar"`)
+		}
+		if _, err := Apply(base, []byte(patch)); err == nil {
+			t.Errorf("Apply(%s, %s) succeeded, want error", base, patch)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package ledgerguard protects snapshot generation from a lagging Horizon.
+// Balances, prices and live metrics all come from Horizon's latest ingested
+// ledger, so a Horizon that stopped ingesting would silently produce a
+// snapshot of the past. The guard checks the latest ledger's close time
+// before generation and either aborts the run or records the snapshot as
+// degraded.
+package ledgerguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// What to do when Horizon lags beyond the maximum.
+const (
+	PolicyDegrade = "degrade" // generate anyway, flag the snapshot and warn
+	PolicyAbort   = "abort"   // fail the run before generating
+)
+
+// ErrStale indicates Horizon's latest ledger closed longer ago than allowed.
+var ErrStale = errors.New("horizon is lagging")
+
+// RootSource reads Horizon's root resource (horizon.Client).
+type RootSource interface {
+	FetchRoot(ctx context.Context) (horizon.Root, error)
+}
+
+// Guard compares Horizon's latest ledger close time with the clock.
+type Guard struct {
+	source RootSource
+	maxLag time.Duration
+	policy string
+	now    func() time.Time
+}
+
+// NewGuard creates a guard allowing maxLag between the latest ledger close
+// and now. policy is PolicyDegrade or PolicyAbort.
+func NewGuard(source RootSource, maxLag time.Duration, policy string) (*Guard, error) {
+	if policy != PolicyDegrade && policy != PolicyAbort {
+		return nil, fmt.Errorf("unknown lag policy %q (want %s or %s)", policy, PolicyDegrade, PolicyAbort)
+	}
+	if maxLag <= 0 {
+		return nil, fmt.Errorf("maximum lag must be positive, got %s", maxLag)
+	}
+	return &Guard{source: source, maxLag: maxLag, policy: policy, now: time.Now}, nil
+}
+
+// Status fetches the latest ledger and how far it lags.
+func (g *Guard) Status(ctx context.Context) (domain.LedgerStatus, error) {
+	root, err := g.source.FetchRoot(ctx)
+	if err != nil {
+		return domain.LedgerStatus{}, err
+	}
+	lag := g.now().Sub(root.LatestLedgerClosedAt)
+	return domain.LedgerStatus{
+		Ledger:     root.LatestLedger,
+		ClosedAt:   root.LatestLedgerClosedAt,
+		LagSeconds: int64(lag / time.Second),
+		Stale:      lag > g.maxLag,
+	}, nil
+}
+
+// Check runs before generation. Under PolicyAbort it returns ErrStale when
+// Horizon lags, or the error when the lag can't be read; under PolicyDegrade
+// it never fails, since EnrichMetrics flags the snapshot instead.
+func (g *Guard) Check(ctx context.Context) error {
+	status, err := g.Status(ctx)
+	if err != nil {
+		if g.policy == PolicyAbort {
+			return fmt.Errorf("checking Horizon ledger lag: %w", err)
+		}
+		slog.Info("horizon ledger lag unknown", "error", err)
+		return nil
+	}
+	if status.Stale && g.policy == PolicyAbort {
+		return fmt.Errorf("%w: %s", ErrStale, g.describe(status))
+	}
+	return nil
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher: the ledger status is
+// stored in the snapshot, and a stale ledger adds a warning and marks the
+// snapshot's quality as degraded.
+func (g *Guard) EnrichMetrics(ctx context.Context, _ time.Time, data *domain.FundStructureData) error {
+	status, err := g.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("checking Horizon ledger lag: %w", err)
+	}
+	data.Ledger = &status
+	if status.Stale {
+		w := "horizon lagging: " + g.describe(status)
+		slog.Error(w)
+		data.Warnings = append(data.Warnings, w)
+	}
+	return nil
+}
+
+func (g *Guard) describe(s domain.LedgerStatus) string {
+	return fmt.Sprintf("ledger %d closed %s ago, more than %s", s.Ledger,
+		(time.Duration(s.LagSeconds) * time.Second).String(), g.maxLag)
+}
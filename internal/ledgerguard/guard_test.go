@@ -0,0 +1,93 @@
+package ledgerguard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+type stubRoot struct {
+	root horizon.Root
+	err  error
+}
+
+func (s stubRoot) FetchRoot(context.Context) (horizon.Root, error) { return s.root, s.err }
+
+var now = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func newGuard(t *testing.T, source RootSource, policy string) *Guard {
+	t.Helper()
+	g, err := NewGuard(source, 2*time.Minute, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.now = func() time.Time { return now }
+	return g
+}
+
+func TestCheck(t *testing.T) {
+	fresh := stubRoot{root: horizon.Root{LatestLedger: 100, LatestLedgerClosedAt: now.Add(-5 * time.Second)}}
+	stale := stubRoot{root: horizon.Root{LatestLedger: 90, LatestLedgerClosedAt: now.Add(-10 * time.Minute)}}
+	down := stubRoot{err: errors.New("connection refused")}
+
+	tests := []struct {
+		name    string
+		source  RootSource
+		policy  string
+		wantErr bool
+	}{
+		{"fresh abort", fresh, PolicyAbort, false},
+		{"stale abort", stale, PolicyAbort, true},
+		{"down abort", down, PolicyAbort, true},
+		{"stale degrade", stale, PolicyDegrade, false},
+		{"down degrade", down, PolicyDegrade, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newGuard(t, tt.source, tt.policy).Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := newGuard(t, stale, PolicyAbort).Check(context.Background()); !errors.Is(err, ErrStale) {
+		t.Errorf("stale abort: Check() = %v, want ErrStale", err)
+	}
+}
+
+func TestEnrichMetrics(t *testing.T) {
+	var data domain.FundStructureData
+	g := newGuard(t, stubRoot{root: horizon.Root{LatestLedger: 90, LatestLedgerClosedAt: now.Add(-10 * time.Minute)}}, PolicyDegrade)
+	if err := g.EnrichMetrics(context.Background(), now, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Ledger == nil || data.Ledger.Ledger != 90 || data.Ledger.LagSeconds != 600 || !data.Ledger.Stale {
+		t.Errorf("Ledger = %+v, want ledger 90 stale by 600s", data.Ledger)
+	}
+	if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0], "ledger 90 closed 10m0s ago") {
+		t.Errorf("Warnings = %q", data.Warnings)
+	}
+
+	data = domain.FundStructureData{}
+	g = newGuard(t, stubRoot{root: horizon.Root{LatestLedger: 100, LatestLedgerClosedAt: now.Add(-5 * time.Second)}}, PolicyDegrade)
+	if err := g.EnrichMetrics(context.Background(), now, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Ledger == nil || data.Ledger.Stale || len(data.Warnings) != 0 {
+		t.Errorf("fresh: Ledger = %+v, Warnings = %q", data.Ledger, data.Warnings)
+	}
+}
+
+func TestNewGuardRejectsBadConfig(t *testing.T) {
+	if _, err := NewGuard(stubRoot{}, time.Minute, "ignore"); err == nil {
+		t.Error("unknown policy accepted")
+	}
+	if _, err := NewGuard(stubRoot{}, 0, PolicyAbort); err == nil {
+		t.Error("zero lag accepted")
+	}
+}
@@ -0,0 +1,112 @@
+// Package legacy maps between snapshot data and the response shapes of the
+// old stat API (/api/snapshots, /api/fund-structure). `stat import` reads the
+// old API with FromLegacy; the compatibility routes serve ToLegacy, so tools
+// written against the old API keep working.
+package legacy
+
+import (
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+// SnapshotEntry is one element of the old /api/snapshots response.
+type SnapshotEntry struct {
+	Date      time.Time `json:"date"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FundStructure is the old /api/fund-structure response: main and mutual
+// accounts in one list, no warnings or live metrics.
+type FundStructure struct {
+	Accounts         []domain.FundAccountPortfolio `json:"accounts"`
+	OtherAccounts    []domain.FundAccountPortfolio `json:"otherAccounts"`
+	AggregatedTotals domain.AggregatedTotals       `json:"aggregatedTotals"`
+}
+
+// renamed maps old account names to the current ones.
+var renamed = map[string]string{"CITY": "MCITY"}
+
+// FromLegacy converts an old fund structure to snapshot data: accounts are
+// renamed, mutual funds split out, and the totals recomputed from the main
+// accounts (the old API's totals covered a different account set).
+func FromLegacy(old FundStructure) domain.FundStructureData {
+	accounts := slices.Clone(old.Accounts)
+	for i := range accounts {
+		if name, ok := renamed[accounts[i].Name]; ok {
+			accounts[i].Name = name
+		}
+	}
+
+	mainAccounts := lo.Filter(accounts, func(a domain.FundAccountPortfolio, _ int) bool {
+		return a.Type != domain.AccountTypeMutual
+	})
+	mutualFunds := lo.Filter(accounts, func(a domain.FundAccountPortfolio, _ int) bool {
+		return a.Type == domain.AccountTypeMutual
+	})
+
+	totalEURMTL := lo.Reduce(mainAccounts, func(acc decimal.Decimal, a domain.FundAccountPortfolio, _ int) decimal.Decimal {
+		return acc.Add(a.TotalEURMTL)
+	}, decimal.Zero)
+	totalXLM := lo.Reduce(mainAccounts, func(acc decimal.Decimal, a domain.FundAccountPortfolio, _ int) decimal.Decimal {
+		return acc.Add(a.TotalXLM)
+	}, decimal.Zero)
+	tokenCount := lo.Reduce(mainAccounts, func(acc int, a domain.FundAccountPortfolio, _ int) int {
+		return acc + len(a.Tokens)
+	}, 0)
+
+	return domain.FundStructureData{
+		Accounts:      mainAccounts,
+		MutualFunds:   mutualFunds,
+		OtherAccounts: old.OtherAccounts,
+		AggregatedTotals: domain.AggregatedTotals{
+			TotalEURMTL:  totalEURMTL,
+			TotalXLM:     totalXLM,
+			AccountCount: len(mainAccounts),
+			TokenCount:   tokenCount,
+		},
+	}
+}
+
+// ToLegacy reverses FromLegacy: main and mutual accounts are merged back
+// into one list under their old names. Stored totals are passed through.
+func ToLegacy(data domain.FundStructureData) FundStructure {
+	accounts := slices.Concat(data.Accounts, data.MutualFunds)
+	for i := range accounts {
+		for old, current := range renamed {
+			if accounts[i].Name == current {
+				accounts[i].Name = old
+			}
+		}
+	}
+	return FundStructure{
+		Accounts:         accounts,
+		OtherAccounts:    data.OtherAccounts,
+		AggregatedTotals: data.AggregatedTotals,
+	}
+}
+
+// ParseDate reads the date parameter of /api/fund-structure. The old API
+// took RFC 3339 timestamps; plain dates are accepted too. Returns midnight UTC.
+func ParseDate(s string) (time.Time, error) {
+	return ParseDateIn(s, snapdate.UTC)
+}
+
+// ParseDateIn is ParseDate with timestamps mapped to the snapshot date that
+// clock assigns them; plain dates are taken as they are.
+func ParseDateIn(s string, clock snapdate.Clock) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		ts, tsErr := time.Parse(time.RFC3339, s)
+		if tsErr != nil {
+			return time.Time{}, err
+		}
+		return clock.Date(ts), nil
+	}
+	return t, nil
+}
@@ -0,0 +1,95 @@
+package legacy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapdate"
+)
+
+func TestFromLegacy(t *testing.T) {
+	old := FundStructure{
+		Accounts: []domain.FundAccountPortfolio{
+			{Name: "ISSUER", Type: domain.AccountTypeIssuer, TotalEURMTL: decimal.NewFromInt(100), TotalXLM: decimal.NewFromInt(300), Tokens: make([]domain.TokenPriceWithBalance, 2)},
+			{Name: "CITY", Type: domain.AccountTypeSubfond, TotalEURMTL: decimal.NewFromInt(40), Tokens: make([]domain.TokenPriceWithBalance, 1)},
+			{Name: "APART", Type: domain.AccountTypeMutual, TotalEURMTL: decimal.NewFromInt(50)},
+		},
+	}
+	data := FromLegacy(old)
+
+	if len(data.Accounts) != 2 || data.Accounts[1].Name != "MCITY" {
+		t.Errorf("accounts = %+v, want ISSUER and MCITY", data.Accounts)
+	}
+	if old.Accounts[1].Name != "CITY" {
+		t.Error("FromLegacy renamed the caller's account")
+	}
+	if len(data.MutualFunds) != 1 || data.MutualFunds[0].Name != "APART" {
+		t.Errorf("mutual funds = %+v", data.MutualFunds)
+	}
+	tot := data.AggregatedTotals
+	if !tot.TotalEURMTL.Equal(decimal.NewFromInt(140)) || !tot.TotalXLM.Equal(decimal.NewFromInt(300)) || tot.AccountCount != 2 || tot.TokenCount != 3 {
+		t.Errorf("totals = %+v, want 140 EURMTL, 300 XLM, 2 accounts, 3 tokens", tot)
+	}
+}
+
+func TestToLegacyReversesFromLegacy(t *testing.T) {
+	old := FundStructure{
+		Accounts: []domain.FundAccountPortfolio{
+			{Name: "ISSUER", Type: domain.AccountTypeIssuer, TotalEURMTL: decimal.NewFromInt(100)},
+			{Name: "CITY", Type: domain.AccountTypeSubfond, TotalEURMTL: decimal.NewFromInt(40)},
+			{Name: "APART", Type: domain.AccountTypeMutual, TotalEURMTL: decimal.NewFromInt(50)},
+		},
+		OtherAccounts: []domain.FundAccountPortfolio{{Name: "LABR", Type: domain.AccountTypeOther}},
+	}
+	data := FromLegacy(old)
+	back := ToLegacy(data)
+
+	if len(back.Accounts) != len(old.Accounts) {
+		t.Fatalf("got %d accounts, want %d", len(back.Accounts), len(old.Accounts))
+	}
+	for i, a := range back.Accounts {
+		if a.Name != old.Accounts[i].Name || a.Type != old.Accounts[i].Type {
+			t.Errorf("account %d = %s/%s, want %s/%s", i, a.Name, a.Type, old.Accounts[i].Name, old.Accounts[i].Type)
+		}
+	}
+	if data.Accounts[1].Name != "MCITY" {
+		t.Error("ToLegacy renamed the snapshot's account")
+	}
+	if len(back.OtherAccounts) != 1 || !back.AggregatedTotals.TotalEURMTL.Equal(decimal.NewFromInt(140)) {
+		t.Errorf("other accounts / totals = %+v / %+v", back.OtherAccounts, back.AggregatedTotals)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{"2024-01-15", "2024-01-15T00:00:00Z", "2024-01-15T23:30:00+02:00"} {
+		got, err := ParseDate(in)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseDate("15.01.2024"); err == nil {
+		t.Error("ParseDate(15.01.2024) succeeded, want error")
+	}
+}
+
+func TestParseDateInReportingTimezone(t *testing.T) {
+	clock, err := snapdate.New("Europe/Belgrade", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 23:30 UTC is already the 16th in Belgrade; plain dates stay as given.
+	tests := map[string]time.Time{
+		"2024-01-15T23:30:00Z": time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+		"2024-01-15":           time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range tests {
+		got, err := ParseDateIn(in, clock)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseDateIn(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+}
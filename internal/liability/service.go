@@ -0,0 +1,126 @@
+// Package liability tracks the obligation tokens the fund issues (MFBond and
+// the like). Holders see them as assets; for the fund they are debts, so their
+// outstanding supply is captured at snapshot time and priced at face value.
+package liability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/stellarkey"
+)
+
+// Obligation is one registered obligation token.
+type Obligation struct {
+	Asset     domain.AssetInfo
+	FaceValue decimal.Decimal // EURMTL owed per token
+	Maturity  *time.Time
+}
+
+// SupplySource reports an asset's on-chain supply (horizon.Client).
+type SupplySource interface {
+	FetchAssetStats(ctx context.Context, asset domain.AssetInfo) (horizon.AssetStats, error)
+}
+
+// Service fills FundStructureData.Liabilities for a fixed list of obligations.
+type Service struct {
+	supply      SupplySource
+	obligations []Obligation
+}
+
+// NewService creates a liability Service. obligations usually comes from
+// ParseObligations.
+func NewService(supply SupplySource, obligations []Obligation) *Service {
+	return &Service{supply: supply, obligations: obligations}
+}
+
+// ParseObligations parses LIABILITY_TOKENS entries of the form
+// CODE:ISSUER=FACE_VALUE, optionally followed by @YYYY-MM-DD for the maturity.
+func ParseObligations(entries []string) ([]Obligation, error) {
+	seen := make(map[string]bool, len(entries))
+	var out []Obligation
+	for _, e := range entries {
+		asset, terms, ok := strings.Cut(strings.TrimSpace(e), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid liability token %q: want CODE:ISSUER=FACE_VALUE[@YYYY-MM-DD]", e)
+		}
+		code, issuer, ok := strings.Cut(strings.TrimSpace(asset), ":")
+		if !ok || code == "" || !stellarkey.ValidAddress(issuer) {
+			return nil, fmt.Errorf("invalid liability token %q: want CODE:ISSUER with a G... issuer", e)
+		}
+		face, maturity, hasMaturity := strings.Cut(strings.TrimSpace(terms), "@")
+		o := Obligation{Asset: domain.NewAssetInfo(code, issuer)}
+		v, err := decimal.NewFromString(strings.TrimSpace(face))
+		if err != nil || !v.IsPositive() {
+			return nil, fmt.Errorf("invalid liability token %q: face value must be a positive number", e)
+		}
+		o.FaceValue = v
+		if hasMaturity {
+			t, err := time.Parse("2006-01-02", strings.TrimSpace(maturity))
+			if err != nil {
+				return nil, fmt.Errorf("invalid liability token %q: maturity must be YYYY-MM-DD", e)
+			}
+			o.Maturity = &t
+		}
+		if seen[o.Asset.Canonical()] {
+			return nil, fmt.Errorf("duplicate liability token %s", o.Asset.Canonical())
+		}
+		seen[o.Asset.Canonical()] = true
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher. It runs after the fund
+// accounts are priced, so tokens the fund holds itself are netted out of the
+// supply. A failed fetch is logged and recorded on that obligation's entry,
+// and only a cancelled ctx is returned as an error.
+func (s *Service) EnrichMetrics(ctx context.Context, _ time.Time, data *domain.FundStructureData) error {
+	if len(s.obligations) == 0 {
+		return nil
+	}
+	out := make([]domain.Liability, 0, len(s.obligations))
+	for _, o := range s.obligations {
+		l := domain.Liability{Asset: o.Asset, FaceValue: o.FaceValue, Maturity: o.Maturity}
+		stats, err := s.supply.FetchAssetStats(ctx, o.Asset)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			slog.Error("liability supply unavailable", "asset", o.Asset.Canonical(), "error", err)
+			l.Error = err.Error()
+			out = append(out, l)
+			continue
+		}
+		l.Supply = stats.TotalSupply
+		l.FundHeld = fundHeld(data.Accounts, o.Asset)
+		l.Outstanding = decimal.Max(l.Supply.Sub(l.FundHeld), decimal.Zero)
+		l.Value = l.Outstanding.Mul(o.FaceValue)
+		out = append(out, l)
+	}
+	data.Liabilities = out
+	return nil
+}
+
+// fundHeld sums the balances of asset across the fund's accounts.
+func fundHeld(accounts []domain.FundAccountPortfolio, asset domain.AssetInfo) decimal.Decimal {
+	total := decimal.Zero
+	for _, acc := range accounts {
+		for _, tok := range acc.Tokens {
+			if tok.Asset.Canonical() != asset.Canonical() {
+				continue
+			}
+			if b, err := decimal.NewFromString(tok.Balance); err == nil {
+				total = total.Add(b)
+			}
+		}
+	}
+	return total
+}
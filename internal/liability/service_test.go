@@ -0,0 +1,76 @@
+package liability
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+type stubSupply map[string]decimal.Decimal
+
+func (s stubSupply) FetchAssetStats(_ context.Context, asset domain.AssetInfo) (horizon.AssetStats, error) {
+	v, ok := s[asset.Code]
+	if !ok {
+		return horizon.AssetStats{}, errors.New("horizon 503")
+	}
+	return horizon.AssetStats{TotalSupply: v}, nil
+}
+
+func TestParseObligations(t *testing.T) {
+	got, err := ParseObligations([]string{"MFBOND:" + domain.IssuerAddress + "=1@2027-06-30", " MFB2:" + domain.IssuerAddress + " = 0.5 "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d obligations, want 2", len(got))
+	}
+	if got[0].Asset.Code != "MFBOND" || !got[0].FaceValue.Equal(decimal.NewFromInt(1)) ||
+		got[0].Maturity == nil || got[0].Maturity.Format("2006-01-02") != "2027-06-30" {
+		t.Errorf("first = %+v", got[0])
+	}
+	if got[1].Maturity != nil || got[1].FaceValue.String() != "0.5" {
+		t.Errorf("second = %+v, want face 0.5 and no maturity", got[1])
+	}
+
+	for _, bad := range [][]string{
+		{"MFBOND:" + domain.IssuerAddress},
+		{"MFBOND:GSHORT=1"},
+		{"MFBOND:" + domain.IssuerAddress + "=0"},
+		{"MFBOND:" + domain.IssuerAddress + "=1@soon"},
+		{"MFBOND:" + domain.IssuerAddress + "=1", "MFBOND:" + domain.IssuerAddress + "=2"},
+	} {
+		if _, err := ParseObligations(bad); err == nil {
+			t.Errorf("ParseObligations(%v): expected error", bad)
+		}
+	}
+}
+
+func TestEnrichMetrics(t *testing.T) {
+	obligations, _ := ParseObligations([]string{"MFBOND:" + domain.IssuerAddress + "=2", "MFB2:" + domain.IssuerAddress + "=1"})
+	data := domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{
+		Tokens: []domain.TokenPriceWithBalance{{Asset: domain.NewAssetInfo("MFBOND", domain.IssuerAddress), Balance: "150"}},
+	}}}
+
+	svc := NewService(stubSupply{"MFBOND": decimal.NewFromInt(1000)}, obligations)
+	if err := svc.EnrichMetrics(context.Background(), time.Now(), &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(data.Liabilities) != 2 {
+		t.Fatalf("got %d liabilities, want 2", len(data.Liabilities))
+	}
+	l := data.Liabilities[0]
+	if !l.FundHeld.Equal(decimal.NewFromInt(150)) || !l.Outstanding.Equal(decimal.NewFromInt(850)) || !l.Value.Equal(decimal.NewFromInt(1700)) {
+		t.Errorf("MFBOND = %+v, want 850 outstanding worth 1700", l)
+	}
+	if e := data.Liabilities[1]; !strings.Contains(e.Error, "horizon 503") || !e.Value.IsZero() {
+		t.Errorf("MFB2 = %+v, want recorded error", e)
+	}
+}
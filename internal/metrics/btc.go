@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/stat/internal/external"
+)
+
+// btcSymbol is the external quote behind I61.
+const btcSymbol = "BTC"
+
+// QuoteSource provides stored external quotes. Under an as-of context it
+// returns the quote of that day, so a backfilled snapshot records the rate
+// it was valued with.
+type QuoteSource interface {
+	Quote(ctx context.Context, symbol string) (external.Quote, error)
+}
+
+// WithQuotes records the stored BTC quote in LiveMetrics so I61 (and I2,
+// derived from it) comes from the snapshot rather than from whatever the
+// portfolio happened to price BTC at.
+func WithQuotes(q QuoteSource) Option {
+	return func(s *Service) {
+		s.quotes = q
+	}
+}
+
+// fetchBTCRate returns the stored BTC quote and when it was fetched. The
+// quote table keeps the last successful `stat quote` run, so a failed run
+// leaves the previous rate in place; ok is false only when there is no
+// usable quote at all.
+func (s *Service) fetchBTCRate(ctx context.Context) (rate string, at time.Time, ok bool) {
+	stepCtx, cancel := withStepTimeout(ctx)
+	defer cancel()
+	q, err := s.quotes.Quote(stepCtx, btcSymbol)
+	if err != nil {
+		slog.Error("metrics: read BTC quote failed, reusing prior I61", "error", err)
+		return "", time.Time{}, false
+	}
+	if !q.PriceInEUR.IsPositive() {
+		slog.Error("metrics: BTC quote is not positive, reusing prior I61", "price", q.PriceInEUR.String())
+		return "", time.Time{}, false
+	}
+	return q.PriceInEUR.String(), q.UpdatedAt, true
+}
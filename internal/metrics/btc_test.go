@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+type stubQuotes struct {
+	quote external.Quote
+	err   error
+}
+
+func (s *stubQuotes) Quote(_ context.Context, _ string) (external.Quote, error) {
+	return s.quote, s.err
+}
+
+func TestEnrichMetricsRecordsBTCQuote(t *testing.T) {
+	fetchedAt := time.Date(2026, 4, 28, 23, 0, 0, 0, time.UTC)
+	quotes := &stubQuotes{quote: external.Quote{Symbol: "BTC", PriceInEUR: decimal.NewFromInt(61000), UpdatedAt: fetchedAt}}
+	svc := NewService(&stubHorizon{}, &stubPrice{}, &stubExpert{}, nil, nil, WithQuotes(quotes))
+	data := &domain.FundStructureData{}
+
+	if err := svc.EnrichMetrics(context.Background(), time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := data.LiveMetrics
+	if m.BTCRate == nil || *m.BTCRate != "61000" {
+		t.Errorf("BTCRate = %v, want 61000", m.BTCRate)
+	}
+	if m.BTCRateAt == nil || !m.BTCRateAt.Equal(fetchedAt) {
+		t.Errorf("BTCRateAt = %v, want %v", m.BTCRateAt, fetchedAt)
+	}
+}
+
+func TestEnrichMetricsBTCQuoteFallsBackToPrior(t *testing.T) {
+	repo := &stubIndicatorRepo{byTarget: map[string]map[int]indicator.Indicator{
+		"latest": indicatorMap(map[int]string{61: "59000"}),
+	}}
+	for name, quotes := range map[string]*stubQuotes{
+		"missing": {err: errors.New("no rows")},
+		"zero":    {quote: external.Quote{Symbol: "BTC", UpdatedAt: time.Now()}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := NewService(&stubHorizon{}, &stubPrice{}, &stubExpert{}, repo, nil, WithQuotes(quotes))
+			data := &domain.FundStructureData{}
+
+			if err := svc.EnrichMetrics(context.Background(), time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			m := data.LiveMetrics
+			if m.BTCRate == nil || *m.BTCRate != "59000" {
+				t.Errorf("BTCRate = %v, want the prior 59000", m.BTCRate)
+			}
+			if m.BTCRateAt != nil {
+				t.Errorf("BTCRateAt = %v, want nil for a reused rate", m.BTCRateAt)
+			}
+			if !slices.Contains(m.Fallbacks, 61) {
+				t.Errorf("Fallbacks = %v, want 61 recorded", m.Fallbacks)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Holder-count indicators, each the result of a paginated Horizon walk that
+// takes minutes: I24 (EURMTL holders), I40 (MTLAP holders), and I23, I27
+// and I62 from the one MTL ∪ MTLRECT walk.
+const (
+	idShareholdersMedian = 23
+	idEURMTLParticipants = 24
+	idShareholders       = 27
+	idMTLAPHolders       = 40
+	idShareholdersAny    = 62
+)
+
+// HolderCount is a holder-count indicator value as computed by a walk.
+type HolderCount struct {
+	IndicatorID int
+	Value       decimal.Decimal
+	ComputedAt  time.Time
+}
+
+// HolderCache stores the latest holder counts, one per indicator.
+type HolderCache interface {
+	LoadHolderCounts(ctx context.Context) (map[int]HolderCount, error)
+	SaveHolderCounts(ctx context.Context, counts []HolderCount) error
+}
+
+// WithHolderCache makes EnrichMetrics take the holder counts from c when
+// they were computed within maxAge, instead of walking Horizon; stale or
+// missing ones are walked as before. RefreshHolderCounts fills c.
+func WithHolderCache(c HolderCache, maxAge time.Duration) Option {
+	return func(s *Service) {
+		s.holders, s.holdersMaxAge = c, maxAge
+	}
+}
+
+// RefreshHolderCounts walks the holders of EURMTL, MTLAP, MTL and MTLRECT
+// and saves the counts to the holder cache. A walk that fails leaves its
+// cached counts as they were; the failures are returned joined.
+func (s *Service) RefreshHolderCounts(ctx context.Context) ([]HolderCount, error) {
+	if s.holders == nil {
+		return nil, errors.New("no holder cache configured")
+	}
+	var counts []HolderCount
+	var errs []error
+	add := func(id int, v decimal.Decimal) {
+		counts = append(counts, HolderCount{IndicatorID: id, Value: v, ComputedAt: time.Now().UTC()})
+	}
+
+	if n, err := s.countEURMTLHolders(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("EURMTL holders: %w", err))
+	} else {
+		add(idEURMTLParticipants, decimal.NewFromInt(int64(n)))
+	}
+	if n, err := s.countMTLAPHolders(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("MTLAP holders: %w", err))
+	} else {
+		add(idMTLAPHolders, decimal.NewFromInt(int64(n)))
+	}
+	mtl := domain.NewAssetInfo("MTL", domain.IssuerAddress)
+	mtlrect := domain.NewAssetInfo("MTLRECT", domain.IssuerAddress)
+	if _, stats, ok := s.fetchShareholderStats(ctx, mtl, mtlrect); !ok {
+		errs = append(errs, errors.New("MTL/MTLRECT shareholders walk failed"))
+	} else {
+		add(idShareholders, decimal.NewFromInt(int64(stats.countAtLeastOne)))
+		add(idShareholdersAny, decimal.NewFromInt(int64(stats.countAny)))
+		add(idShareholdersMedian, stats.median)
+	}
+
+	if len(counts) > 0 {
+		if err := s.holders.SaveHolderCounts(ctx, counts); err != nil {
+			return nil, fmt.Errorf("saving holder counts: %w", err)
+		}
+	}
+	return counts, errors.Join(errs...)
+}
+
+// RunHolderRefresh refreshes the holder counts now and then every interval
+// until ctx is cancelled. Failures are logged and retried on the next tick.
+func (s *Service) RunHolderRefresh(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		counts, err := s.RefreshHolderCounts(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			slog.Error("holder count refresh failed", "refreshed", len(counts), "error", err)
+		default:
+			slog.Info("holder counts refreshed", "counts", len(counts), "duration_ms", time.Since(start).Milliseconds())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// freshHolderCounts returns the cached counts computed within the max age.
+// A cache that can't be read is logged and treated as empty.
+func (s *Service) freshHolderCounts(ctx context.Context) map[int]HolderCount {
+	if s.holders == nil {
+		return nil
+	}
+	all, err := s.holders.LoadHolderCounts(ctx)
+	if err != nil {
+		slog.Error("metrics: loading cached holder counts failed, walking Horizon", "error", err)
+		return nil
+	}
+	fresh := make(map[int]HolderCount, len(all))
+	for id, c := range all {
+		if time.Since(c.ComputedAt) <= s.holdersMaxAge {
+			fresh[id] = c
+		}
+	}
+	return fresh
+}
+
+// countEURMTLHolders counts EURMTL trustlines with a non-zero balance (I24).
+// It walks the holders because /assets `accounts.authorized` includes empty
+// trustlines and would inflate the count by ~3x.
+func (s *Service) countEURMTLHolders(ctx context.Context) (int, error) {
+	stepCtx, cancel := withStepTimeout(ctx)
+	defer cancel()
+	return s.horizon.FetchAssetHolderCountByBalance(stepCtx, domain.EURMTLAsset(), decimal.New(1, -7))
+}
+
+// countMTLAPHolders counts MTLAP holders with a balance of at least 1 (I40).
+// /assets `accounts.authorized` for MTLAP returns ~1 because most holders
+// are AUTHORIZED_TO_MAINTAIN_LIABILITIES, not authorized — so we have to
+// walk and apply the balance filter. The Secretariat's distribution account
+// holds MTLAP stock but is not a participant, so it is subtracted.
+func (s *Service) countMTLAPHolders(ctx context.Context) (int, error) {
+	stepCtx, cancel := withStepTimeout(ctx)
+	defer cancel()
+	n, err := s.horizon.FetchAssetHolderCountByBalance(stepCtx, domain.MTLAPAsset(), decimal.NewFromInt(1))
+	if err != nil {
+		return 0, err
+	}
+	return n - 1, nil
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+type memHolderCache map[int]HolderCount
+
+func (m memHolderCache) LoadHolderCounts(context.Context) (map[int]HolderCount, error) {
+	return m, nil
+}
+
+func (m memHolderCache) SaveHolderCounts(_ context.Context, counts []HolderCount) error {
+	for _, c := range counts {
+		m[c.IndicatorID] = c
+	}
+	return nil
+}
+
+func TestRefreshHolderCounts(t *testing.T) {
+	h := &stubHorizon{
+		holderCounts: map[string]int{"EURMTL": 900, "MTLAP": 51},
+		holderBalances: map[string]map[string]decimal.Decimal{
+			"MTL":     {"A": decimal.NewFromInt(10), "B": decimal.RequireFromString("0.5")},
+			"MTLRECT": {"C": decimal.NewFromInt(2)},
+		},
+	}
+	cache := memHolderCache{}
+	svc := NewService(h, nil, nil, nil, nil, WithHolderCache(cache, time.Hour))
+
+	counts, err := svc.RefreshHolderCounts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 5 {
+		t.Fatalf("counts = %+v, want 5", counts)
+	}
+	for id, want := range map[int]string{24: "900", 40: "50", 27: "2", 62: "3", 23: "6"} {
+		if got := cache[id].Value.String(); got != want {
+			t.Errorf("I%d = %s, want %s", id, got, want)
+		}
+	}
+
+	// A failed walk leaves its cached counts alone.
+	h.holderCountErr = map[string]error{"MTLAP": errors.New("horizon timeout")}
+	h.holderCounts["EURMTL"] = 905
+	if _, err := svc.RefreshHolderCounts(context.Background()); err == nil {
+		t.Error("expected the MTLAP failure to be returned")
+	}
+	if cache[24].Value.String() != "905" || cache[40].Value.String() != "50" {
+		t.Errorf("after partial refresh I24 = %s, I40 = %s", cache[24].Value, cache[40].Value)
+	}
+}
+
+func TestEnrichMetricsPrefersFreshHolderCounts(t *testing.T) {
+	// The stub walks would fail: every value must come from the cache or,
+	// when stale, fall back.
+	h := &stubHorizon{
+		holderCountErr: map[string]error{"EURMTL": errors.New("walked"), "MTLAP": errors.New("walked")},
+		holderErr:      map[string]error{"MTL": errors.New("walked")},
+	}
+	fresh := time.Now().Add(-time.Hour).UTC()
+	cache := memHolderCache{
+		24: {IndicatorID: 24, Value: decimal.NewFromInt(900), ComputedAt: fresh},
+		27: {IndicatorID: 27, Value: decimal.NewFromInt(400), ComputedAt: fresh.Add(10 * time.Minute)},
+		62: {IndicatorID: 62, Value: decimal.NewFromInt(700), ComputedAt: fresh},
+		23: {IndicatorID: 23, Value: decimal.NewFromInt(25), ComputedAt: fresh},
+		40: {IndicatorID: 40, Value: decimal.NewFromInt(50), ComputedAt: time.Now().Add(-48 * time.Hour)},
+	}
+	svc := NewService(h, &stubPrice{}, &stubExpert{}, nil, nil, WithHolderCache(cache, 12*time.Hour))
+
+	var data domain.FundStructureData
+	if err := svc.EnrichMetrics(context.Background(), time.Now().UTC(), &data); err != nil {
+		t.Fatal(err)
+	}
+	m := data.LiveMetrics
+	if *m.EURMTLParticipants != "900" || *m.MTLShareholders != "400" || *m.MTLShareholdersAny != "700" || *m.MTLShareholdersMedian != "25" {
+		t.Errorf("cached counts not used: %+v", m)
+	}
+	if m.MTLAPHolders != nil {
+		t.Errorf("stale I40 = %s, want the failed walk's fallback (none without history)", *m.MTLAPHolders)
+	}
+	if m.HolderCountsAt == nil || !m.HolderCountsAt.Equal(fresh) {
+		t.Errorf("HolderCountsAt = %v, want the oldest count used, %v", m.HolderCountsAt, fresh)
+	}
+	for _, id := range m.Fallbacks {
+		if id == 24 || id == 27 {
+			t.Errorf("I%d listed as a fallback", id)
+		}
+	}
+}
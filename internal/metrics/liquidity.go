@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// liquidityVolumeWindow is the trailing window averaged for MTL's daily DEX
+// volume (I63). Days without trades count as zero — Horizon omits empty
+// buckets, so the sum is divided by the window length, not the bucket count.
+const liquidityVolumeWindow = 30
+
+// bidDepthLevels is how many price levels of MTL/EURMTL bids count towards
+// bid depth (I64).
+const bidDepthLevels = 5
+
+// fetchMTLAvgDailyVolume averages MTL base volume against EURMTL over the
+// liquidityVolumeWindow days before date. ok=false on fetch or parse failure.
+func (s *Service) fetchMTLAvgDailyVolume(ctx context.Context, date time.Time, mtl, eurmtl domain.AssetInfo) (decimal.Decimal, bool) {
+	stepCtx, cancel := withStepTimeout(ctx)
+	defer cancel()
+	start := date.AddDate(0, 0, -liquidityVolumeWindow)
+	aggs, err := s.horizon.FetchTradeAggregations(stepCtx, mtl, eurmtl, 24*time.Hour, start, date)
+	if err != nil {
+		slog.Error("metrics: fetch MTL trade aggregations failed", "error", err)
+		return decimal.Zero, false
+	}
+	var total decimal.Decimal
+	for _, a := range aggs {
+		v, err := decimal.NewFromString(a.BaseVolume)
+		if err != nil {
+			slog.Error("metrics: unparseable trade aggregation volume", "timestamp", a.Timestamp, "value", a.BaseVolume, "error", err)
+			return decimal.Zero, false
+		}
+		total = total.Add(v)
+	}
+	return total.Div(decimal.NewFromInt(liquidityVolumeWindow)), true
+}
+
+// fetchMTLBidDepth sums the EURMTL amount of the top bidDepthLevels bids for
+// MTL. Horizon quotes bid amounts in the buying asset, i.e. already in EURMTL.
+func (s *Service) fetchMTLBidDepth(ctx context.Context, mtl, eurmtl domain.AssetInfo) (decimal.Decimal, bool) {
+	stepCtx, cancel := withStepTimeout(ctx)
+	defer cancel()
+	ob, err := s.horizon.FetchOrderbook(stepCtx, mtl, eurmtl, bidDepthLevels)
+	if err != nil {
+		slog.Error("metrics: fetch MTL/EURMTL orderbook failed", "error", err)
+		return decimal.Zero, false
+	}
+	var depth decimal.Decimal
+	for i, bid := range ob.Bids {
+		if i == bidDepthLevels {
+			break
+		}
+		amt, err := decimal.NewFromString(bid.Amount)
+		if err != nil {
+			slog.Error("metrics: unparseable orderbook bid", "price", bid.Price, "amount", bid.Amount, "error", err)
+			return decimal.Zero, false
+		}
+		depth = depth.Add(amt)
+	}
+	return depth, true
+}
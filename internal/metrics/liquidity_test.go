@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+func TestEnrichMetricsLiquidityInputs(t *testing.T) {
+	h := &stubHorizon{
+		tradeAggs: []horizon.HorizonTradeAggregation{
+			{BaseVolume: "100.0000000"},
+			{BaseVolume: "50.0000000"},
+		},
+		orderbook: horizon.HorizonOrderbook{Bids: []horizon.HorizonOrderbookEntry{
+			{Price: "8", Amount: "10"}, {Price: "7.9", Amount: "20"}, {Price: "7.8", Amount: "30"},
+			{Price: "7.7", Amount: "40"}, {Price: "7.6", Amount: "50"}, {Price: "7.5", Amount: "1000"},
+		}},
+	}
+	svc := NewService(h, &stubPrice{}, &stubExpert{}, nil, nil)
+	data := &domain.FundStructureData{}
+
+	if err := svc.EnrichMetrics(context.Background(), time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := data.LiveMetrics
+	// 150 MTL over a 30-day window, empty days included.
+	if m.MTLAvgDailyVolume == nil || !decimal.RequireFromString(*m.MTLAvgDailyVolume).Equal(decimal.NewFromInt(5)) {
+		t.Errorf("MTLAvgDailyVolume = %v, want 5", m.MTLAvgDailyVolume)
+	}
+	// Only the top five levels count.
+	if m.MTLBidDepthTop5 == nil || *m.MTLBidDepthTop5 != "150" {
+		t.Errorf("MTLBidDepthTop5 = %v, want 150", m.MTLBidDepthTop5)
+	}
+}
+
+func TestEnrichMetricsLiquidityFetchFailureLeavesEmpty(t *testing.T) {
+	h := &stubHorizon{tradeAggsErr: errors.New("timeout"), orderbookErr: errors.New("timeout")}
+	svc := NewService(h, &stubPrice{}, &stubExpert{}, nil, nil)
+	data := &domain.FundStructureData{}
+
+	if err := svc.EnrichMetrics(context.Background(), time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.LiveMetrics.MTLAvgDailyVolume != nil || data.LiveMetrics.MTLBidDepthTop5 != nil {
+		t.Errorf("liquidity inputs = %v / %v, want nil", data.LiveMetrics.MTLAvgDailyVolume, data.LiveMetrics.MTLBidDepthTop5)
+	}
+}
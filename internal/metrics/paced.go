@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/pacing"
+)
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithPacer paces every Horizon call made by the service. A paginated fetch
+// (the holder walks) counts as one call.
+func WithPacer(p *pacing.Pacer) Option {
+	return func(s *Service) {
+		if p != nil {
+			s.horizon = pacedHorizon{next: s.horizon, pacer: p}
+		}
+	}
+}
+
+// pacedHorizon waits for a pacer slot before each call.
+type pacedHorizon struct {
+	next  Horizon
+	pacer *pacing.Pacer
+}
+
+func (h pacedHorizon) FetchAssetStats(ctx context.Context, asset domain.AssetInfo) (horizon.AssetStats, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return horizon.AssetStats{}, err
+	}
+	return h.next.FetchAssetStats(ctx, asset)
+}
+
+func (h pacedHorizon) FetchAssetHolderCountByBalance(ctx context.Context, asset domain.AssetInfo, minBalance decimal.Decimal) (int, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return 0, err
+	}
+	return h.next.FetchAssetHolderCountByBalance(ctx, asset, minBalance)
+}
+
+func (h pacedHorizon) FetchAssetHolderBalancesByBalance(ctx context.Context, asset domain.AssetInfo, minBalance decimal.Decimal) (map[string]decimal.Decimal, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchAssetHolderBalancesByBalance(ctx, asset, minBalance)
+}
+
+func (h pacedHorizon) FetchDividendActivity(ctx context.Context, distributor string, fundAddresses []string, since time.Time) (horizon.DividendActivity, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return horizon.DividendActivity{}, err
+	}
+	return h.next.FetchDividendActivity(ctx, distributor, fundAddresses, since)
+}
+
+func (h pacedHorizon) FetchAccountDataEntry(ctx context.Context, accountID, key string) (string, bool, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return "", false, err
+	}
+	return h.next.FetchAccountDataEntry(ctx, accountID, key)
+}
+
+func (h pacedHorizon) FetchTradeAggregations(ctx context.Context, base, counter domain.AssetInfo, resolution time.Duration, start, end time.Time) ([]horizon.HorizonTradeAggregation, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchTradeAggregations(ctx, base, counter, resolution, start, end)
+}
+
+func (h pacedHorizon) FetchOrderbook(ctx context.Context, selling, buying domain.AssetInfo, limit int) (horizon.HorizonOrderbook, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return horizon.HorizonOrderbook{}, err
+	}
+	return h.next.FetchOrderbook(ctx, selling, buying, limit)
+}
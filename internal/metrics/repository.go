@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgHolderCache implements HolderCache with PostgreSQL.
+type PgHolderCache struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgHolderCache creates a new PostgreSQL holder-count cache.
+func NewPgHolderCache(pool *pgxpool.Pool) *PgHolderCache {
+	return &PgHolderCache{pool: pool}
+}
+
+func (r *PgHolderCache) LoadHolderCounts(ctx context.Context) (map[int]HolderCount, error) {
+	rows, err := r.pool.Query(ctx, `SELECT indicator_id, value, computed_at FROM holder_counts`)
+	if err != nil {
+		return nil, fmt.Errorf("loading holder counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]HolderCount)
+	for rows.Next() {
+		var c HolderCount
+		if err := rows.Scan(&c.IndicatorID, &c.Value, &c.ComputedAt); err != nil {
+			return nil, fmt.Errorf("scanning holder count: %w", err)
+		}
+		counts[c.IndicatorID] = c
+	}
+	return counts, rows.Err()
+}
+
+func (r *PgHolderCache) SaveHolderCounts(ctx context.Context, counts []HolderCount) error {
+	batch := &pgx.Batch{}
+	for _, c := range counts {
+		batch.Queue(
+			`INSERT INTO holder_counts (indicator_id, value, computed_at)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT (indicator_id) DO UPDATE SET value = EXCLUDED.value, computed_at = EXCLUDED.computed_at`,
+			c.IndicatorID, c.Value, c.ComputedAt)
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("saving holder counts: %w", err)
+	}
+	return nil
+}
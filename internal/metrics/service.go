@@ -33,6 +33,8 @@ type Horizon interface {
 	FetchAssetHolderBalancesByBalance(ctx context.Context, asset domain.AssetInfo, minBalance decimal.Decimal) (map[string]decimal.Decimal, error)
 	FetchDividendActivity(ctx context.Context, distributor string, fundAddresses []string, since time.Time) (horizon.DividendActivity, error)
 	FetchAccountDataEntry(ctx context.Context, accountID, key string) (string, bool, error)
+	FetchTradeAggregations(ctx context.Context, base, counter domain.AssetInfo, resolution time.Duration, start, end time.Time) ([]horizon.HorizonTradeAggregation, error)
+	FetchOrderbook(ctx context.Context, selling, buying domain.AssetInfo, limit int) (horizon.HorizonOrderbook, error)
 }
 
 // dividendLookbackWindow caps how far back the live path scans for the most
@@ -67,32 +69,47 @@ type Service struct {
 	expert    PaymentStatsSource
 	indicator indicator.Repository
 	fundAddrs []string
+	quotes    QuoteSource // nil leaves I61 to the portfolio's BTC price
+
+	holders       HolderCache // nil walks Horizon for every holder count
+	holdersMaxAge time.Duration
 }
 
 // NewService creates a new metrics Service. indicatorRepo is required for the
 // sticky-fallback path (reusing the prior day's value when a fetch fails);
 // passing nil disables it.
-func NewService(h Horizon, p PriceSource, expert PaymentStatsSource, indicatorRepo indicator.Repository, fundAddrs []string) *Service {
-	return &Service{
+func NewService(h Horizon, p PriceSource, expert PaymentStatsSource, indicatorRepo indicator.Repository, fundAddrs []string, opts ...Option) *Service {
+	s := &Service{
 		horizon:   h,
 		price:     p,
 		expert:    expert,
 		indicator: indicatorRepo,
 		fundAddrs: fundAddrs,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // EnrichMetrics computes all live indicators (I6, I7, I10, I11, I18, I23-I27,
-// I40, I49, I62) for the snapshot dated `date` and stores them in
-// data.LiveMetrics. On any fetch failure it logs an error and falls back to
-// the prior day's persisted value, never zero.
+// I40, I49, I61, I62) plus the I63/I64 liquidity inputs for the snapshot dated
+// `date` and stores them in data.LiveMetrics. On any fetch failure it logs an
+// error and falls back to the prior day's persisted value, never zero — except
+// the liquidity inputs, which have no persisted counterpart. Fallen-back IDs
+// are listed in LiveMetrics.Fallbacks.
 func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domain.FundStructureData) error {
 	prev := s.priorMetrics(ctx, date)
 	m := &domain.FundLiveMetrics{}
+	// fallback reuses the prior day's value for indicator id and records it in
+	// m.Fallbacks, which feeds the snapshot's data-quality score.
+	fallback := func(id int) *string {
+		m.Fallbacks = append(m.Fallbacks, id)
+		return pickPrior(prev, id)
+	}
 
 	mtlAsset := domain.NewAssetInfo("MTL", domain.IssuerAddress)
 	mtlrectAsset := domain.NewAssetInfo("MTLRECT", domain.IssuerAddress)
-	mtlapAsset := domain.MTLAPAsset()
 	eurmtlAsset := domain.EURMTLAsset()
 
 	stage := func(name string) func() {
@@ -104,67 +121,80 @@ func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domai
 	}
 
 	done := stage("MTL_circulation")
-	if circ, ok := s.fetchCirculation(ctx, mtlAsset); ok {
+	if circ, supply, ok := s.fetchCirculation(ctx, mtlAsset); ok {
 		m.MTLCirculation = ptr(circ.String())
+		m.MTLSupply = ptr(supply.String())
 	} else {
-		m.MTLCirculation = pickPrior(prev, 6)
+		m.MTLCirculation = fallback(6)
 	}
 	done()
 
 	done = stage("MTLRECT_circulation")
-	if circ, ok := s.fetchCirculation(ctx, mtlrectAsset); ok {
+	if circ, supply, ok := s.fetchCirculation(ctx, mtlrectAsset); ok {
 		m.MTLRECTCirculation = ptr(circ.String())
+		m.MTLRECTSupply = ptr(supply.String())
 	} else {
-		m.MTLRECTCirculation = pickPrior(prev, 7)
+		m.MTLRECTCirculation = fallback(7)
 	}
 	done()
 
-	// I24: count of EURMTL trustlines with non-zero balance. Uses a paginated
-	// walk because /assets `accounts.authorized` includes empty trustlines and
-	// would inflate the count by ~3x.
-	done = stage("EURMTL_holders")
-	{
-		stepCtx, cancel := withStepTimeout(ctx)
-		minNonZero := decimal.New(1, -7)
-		if count, err := s.horizon.FetchAssetHolderCountByBalance(stepCtx, eurmtlAsset, minNonZero); err != nil {
-			slog.Error("metrics: fetch EURMTL holders failed, reusing prior I24", "error", err)
-			m.EURMTLParticipants = pickPrior(prev, 24)
-		} else {
-			m.EURMTLParticipants = ptr(decimal.NewFromInt(int64(count)).String())
+	// I24, I40 and the shareholder walk (I23, I27, I62) come from the holder
+	// cache when it has them fresh, so a snapshot doesn't wait minutes on
+	// walks the serve refresher already made.
+	cached := s.freshHolderCounts(ctx)
+	useCached := func(ids ...int) bool {
+		for _, id := range ids {
+			if _, ok := cached[id]; !ok {
+				return false
+			}
 		}
-		cancel()
+		for _, id := range ids {
+			if at := cached[id].ComputedAt; m.HolderCountsAt == nil || at.Before(*m.HolderCountsAt) {
+				m.HolderCountsAt = &at
+			}
+		}
+		return true
+	}
+	cachedValue := func(id int) *string { return ptr(cached[id].Value.String()) }
+
+	done = stage("EURMTL_holders")
+	if useCached(idEURMTLParticipants) {
+		m.EURMTLParticipants = cachedValue(idEURMTLParticipants)
+	} else if count, err := s.countEURMTLHolders(ctx); err != nil {
+		slog.Error("metrics: fetch EURMTL holders failed, reusing prior I24", "error", err)
+		m.EURMTLParticipants = fallback(24)
+	} else {
+		m.EURMTLParticipants = ptr(decimal.NewFromInt(int64(count)).String())
 	}
 	done()
 
-	// I40: count of MTLAP holders with balance ≥1. /assets `accounts.authorized`
-	// for MTLAP returns ~1 because most holders are AUTHORIZED_TO_MAINTAIN_LIABILITIES,
-	// not authorized — so we have to walk and apply the balance filter.
-	// Subtract 1 to exclude the Secretariat's distribution account (holds MTLAP
-	// stock but is not a participant).
 	done = stage("MTLAP_holders")
-	{
-		stepCtx, cancel := withStepTimeout(ctx)
-		minOne := decimal.NewFromInt(1)
-		if count, err := s.horizon.FetchAssetHolderCountByBalance(stepCtx, mtlapAsset, minOne); err != nil {
-			slog.Error("metrics: fetch MTLAP holders failed, reusing prior I40", "error", err)
-			m.MTLAPHolders = pickPrior(prev, 40)
-		} else {
-			m.MTLAPHolders = ptr(decimal.NewFromInt(int64(count - 1)).String())
-		}
-		cancel()
+	if useCached(idMTLAPHolders) {
+		m.MTLAPHolders = cachedValue(idMTLAPHolders)
+	} else if count, err := s.countMTLAPHolders(ctx); err != nil {
+		slog.Error("metrics: fetch MTLAP holders failed, reusing prior I40", "error", err)
+		m.MTLAPHolders = fallback(40)
+	} else {
+		m.MTLAPHolders = ptr(decimal.NewFromInt(int64(count)).String())
 	}
 	done()
 
 	done = stage("MTL_MTLRECT_shareholders_walk")
-	_, stats, shareholdersOK := s.fetchShareholderStats(ctx, mtlAsset, mtlrectAsset)
-	if shareholdersOK {
+	var stats shareholderStats
+	shareholdersOK := true
+	if useCached(idShareholders, idShareholdersAny, idShareholdersMedian) {
+		m.MTLShareholders = cachedValue(idShareholders)
+		m.MTLShareholdersAny = cachedValue(idShareholdersAny)
+		m.MTLShareholdersMedian = cachedValue(idShareholdersMedian)
+		stats.countAtLeastOne = int(cached[idShareholders].Value.IntPart())
+	} else if _, stats, shareholdersOK = s.fetchShareholderStats(ctx, mtlAsset, mtlrectAsset); shareholdersOK {
 		m.MTLShareholders = ptr(decimal.NewFromInt(int64(stats.countAtLeastOne)).String())
 		m.MTLShareholdersAny = ptr(decimal.NewFromInt(int64(stats.countAny)).String())
 		m.MTLShareholdersMedian = ptr(stats.median.String())
 	} else {
-		m.MTLShareholders = pickPrior(prev, 27)
-		m.MTLShareholdersAny = pickPrior(prev, 62)
-		m.MTLShareholdersMedian = pickPrior(prev, 23)
+		m.MTLShareholders = fallback(27)
+		m.MTLShareholdersAny = fallback(62)
+		m.MTLShareholdersMedian = fallback(23)
 	}
 	done()
 
@@ -186,8 +216,8 @@ func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domai
 				s.auditI18VsI27(i18Count, stats.countAtLeastOne, shareholdersOK)
 			}
 		} else {
-			m.MonthlyDividends = pickPrior(prev, 11)
-			m.EURMTLShareholders = pickPrior(prev, 18)
+			m.MonthlyDividends = fallback(11)
+			m.EURMTLShareholders = fallback(18)
 		}
 	}
 	done()
@@ -213,12 +243,12 @@ func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domai
 		case errors.Is(err, stellarexpert.ErrNoDailyEntry):
 			slog.Info("metrics: stellar.expert has no entry for prior day yet, reusing persisted I25/I26",
 				"prior_day", priorDay.Format("2006-01-02"))
-			m.EURMTLDailyVolume = pickPrior(prev, 25)
-			m.EURMTLPaymentTotal = pickPrior(prev, 26)
+			m.EURMTLDailyVolume = fallback(25)
+			m.EURMTLPaymentTotal = fallback(26)
 		default:
 			slog.Error("metrics: fetch stellar.expert stats failed, reusing prior I25/I26", "error", err)
-			m.EURMTLDailyVolume = pickPrior(prev, 25)
-			m.EURMTLPaymentTotal = pickPrior(prev, 26)
+			m.EURMTLDailyVolume = fallback(25)
+			m.EURMTLPaymentTotal = fallback(26)
 		}
 	}
 	done()
@@ -228,7 +258,7 @@ func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domai
 		stepCtx, cancel := withStepTimeout(ctx)
 		if avg, err := s.price.GetAverageTradePrice(stepCtx, mtlAsset, eurmtlAsset, tradesAvgWindow); err != nil {
 			slog.Error("metrics: fetch MTL trades-average failed, reusing prior I10", "error", err)
-			m.MTLMarketPrice = pickPrior(prev, 10)
+			m.MTLMarketPrice = fallback(10)
 		} else {
 			m.MTLMarketPrice = ptr(avg.String())
 		}
@@ -241,7 +271,7 @@ func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domai
 		stepCtx, cancel := withStepTimeout(ctx)
 		if avg, err := s.price.GetAverageTradePrice(stepCtx, mtlrectAsset, eurmtlAsset, tradesAvgWindow); err != nil {
 			slog.Error("metrics: fetch MTLRECT trades-average failed, reusing prior I49", "error", err)
-			m.MTLRECTMarketPrice = pickPrior(prev, 49)
+			m.MTLRECTMarketPrice = fallback(49)
 		} else {
 			m.MTLRECTMarketPrice = ptr(avg.String())
 		}
@@ -249,6 +279,32 @@ func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domai
 	}
 	done()
 
+	// I63 / I64 inputs. No sticky fallback: the prior day's indicator is a
+	// derived ratio, not the raw volume/depth, so a failed fetch leaves the
+	// field empty and the indicators are unavailable for the day.
+	done = stage("MTL_liquidity")
+	if vol, ok := s.fetchMTLAvgDailyVolume(ctx, date, mtlAsset, eurmtlAsset); ok {
+		m.MTLAvgDailyVolume = ptr(vol.String())
+	}
+	if depth, ok := s.fetchMTLBidDepth(ctx, mtlAsset, eurmtlAsset); ok {
+		m.MTLBidDepthTop5 = ptr(depth.String())
+	}
+	done()
+
+	// I61: the stored BTC quote and its fetch time, so I2 = I1 / I61 is
+	// recomputed from the same rate later. Without a stored quote the prior
+	// day's I61 is reused; its timestamp is unknown and left empty.
+	if s.quotes != nil {
+		done = stage("BTC_rate")
+		if rate, at, ok := s.fetchBTCRate(ctx); ok {
+			m.BTCRate = ptr(rate)
+			m.BTCRateAt = &at
+		} else {
+			m.BTCRate = fallback(61)
+		}
+		done()
+	}
+
 	data.LiveMetrics = m
 	return nil
 }
@@ -281,20 +337,21 @@ func withStepTimeout(ctx context.Context) (context.Context, context.CancelFunc)
 }
 
 // fetchCirculation derives circulating supply from a single /assets call:
-// total supply minus AMM-pool reserves. Returns ok=false on fetch failure.
-func (s *Service) fetchCirculation(ctx context.Context, asset domain.AssetInfo) (decimal.Decimal, bool) {
+// total supply minus AMM-pool reserves. The total supply is returned too; it
+// is what issuance and buybacks change. Returns ok=false on fetch failure.
+func (s *Service) fetchCirculation(ctx context.Context, asset domain.AssetInfo) (circulation, supply decimal.Decimal, ok bool) {
 	stepCtx, cancel := withStepTimeout(ctx)
 	defer cancel()
 	stats, err := s.horizon.FetchAssetStats(stepCtx, asset)
 	if err != nil {
 		slog.Error("metrics: fetch asset stats failed", "asset", asset.Code, "error", err)
-		return decimal.Zero, false
+		return decimal.Zero, decimal.Zero, false
 	}
 	c := stats.TotalSupply.Sub(stats.LiquidityPools)
 	if c.IsNegative() {
 		c = decimal.Zero
 	}
-	return c, true
+	return c, stats.TotalSupply, true
 }
 
 // shareholderStats bundles the two holder counts and the median per-holder
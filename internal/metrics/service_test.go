@@ -29,6 +29,10 @@ type stubHorizon struct {
 	accountDataValue   string
 	accountDataPresent bool
 	accountDataErr     error
+	tradeAggs          []horizon.HorizonTradeAggregation
+	tradeAggsErr       error
+	orderbook          horizon.HorizonOrderbook
+	orderbookErr       error
 }
 
 type stubExpert struct {
@@ -81,6 +85,14 @@ func (s *stubHorizon) FetchAccountDataEntry(_ context.Context, _, _ string) (str
 	return s.accountDataValue, s.accountDataPresent, nil
 }
 
+func (s *stubHorizon) FetchTradeAggregations(_ context.Context, _, _ domain.AssetInfo, _ time.Duration, _, _ time.Time) ([]horizon.HorizonTradeAggregation, error) {
+	return s.tradeAggs, s.tradeAggsErr
+}
+
+func (s *stubHorizon) FetchOrderbook(_ context.Context, _, _ domain.AssetInfo, _ int) (horizon.HorizonOrderbook, error) {
+	return s.orderbook, s.orderbookErr
+}
+
 type stubPrice struct {
 	avgByAsset map[string]decimal.Decimal
 	avgErr     map[string]error
@@ -97,7 +109,7 @@ type stubIndicatorRepo struct {
 	byTarget map[string]map[int]indicator.Indicator
 }
 
-func (s *stubIndicatorRepo) Save(_ context.Context, _ int, _ time.Time, _ []indicator.Indicator) error {
+func (s *stubIndicatorRepo) Save(_ context.Context, _ int, _ time.Time, _ []indicator.Indicator, _ indicator.Source) error {
 	return nil
 }
 func (s *stubIndicatorRepo) GetByDate(_ context.Context, _ string, _ time.Time) ([]indicator.Indicator, error) {
@@ -204,6 +216,8 @@ func TestEnrichMetricsHappyPath(t *testing.T) {
 	}{
 		{"I6 MTL circulation", m.MTLCirculation, "850"},         // 1000 - 150
 		{"I7 MTLRECT circulation", m.MTLRECTCirculation, "450"}, // 500 - 50
+		{"MTL supply", m.MTLSupply, "1000"},
+		{"MTLRECT supply", m.MTLRECTSupply, "500"},
 		{"I24 EURMTL participants", m.EURMTLParticipants, "200"},
 		{"I27 shareholders ≥1", m.MTLShareholders, "4"},     // A,B,C,D — E (0.5) excluded
 		{"I62 shareholders any", m.MTLShareholdersAny, "5"}, // A,B,C,D,E all counted
@@ -292,6 +306,9 @@ func TestEnrichMetricsStickyFallback(t *testing.T) {
 			t.Errorf("%s = %s, want %s (sticky-fallback)", id, *c.got, c.want)
 		}
 	}
+	if len(m.Fallbacks) != len(checks) {
+		t.Errorf("Fallbacks = %v, want all %d fallen-back IDs recorded", m.Fallbacks, len(checks))
+	}
 }
 
 func TestEnrichMetricsNoRepoLeavesNil(t *testing.T) {
@@ -43,6 +43,9 @@ func (p *GristProvider) Send(ctx context.Context, report Report) error {
 }
 
 func formatHTML(r Report) string {
+	if r.Message != "" {
+		return r.Message
+	}
 	date := r.Date.Format("2006-01-02")
 	mentions := strings.Join(r.Mentions, " ")
 
@@ -16,6 +16,9 @@ type Report struct {
 	Alerts        []Alert
 	Mentions      []string
 	ReportURL     string
+	// Message, when set, is sent as-is instead of the daily summary (used
+	// for period reports, which render their own HTML).
+	Message string
 }
 
 // Alert describes an indicator that changed sharply vs the previous observation.
@@ -12,6 +12,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/snapdate"
 )
 
 // alertThreshold is the minimum absolute percent change to trigger an alert.
@@ -24,6 +25,7 @@ var keyIndicatorIDs = []int{3, 6, 7, 10}
 type Config struct {
 	Mentions  []string
 	ReportURL string
+	Clock     snapdate.Clock // decides which snapshot date is "today"
 }
 
 // Service assembles and dispatches daily fund notifications.
@@ -50,8 +52,7 @@ func ParseMentions(raw string) []string {
 // Run checks today's report, builds a Report, and sends it via all providers.
 // Returns a non-zero error if the report is missing or any provider fails.
 func (s *Service) Run(ctx context.Context) error {
-	now := time.Now().UTC()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	today := s.cfg.Clock.Today()
 	yesterday := today.AddDate(0, 0, -1)
 
 	todayIndicators, err := s.indicatorRepo.GetByDate(ctx, "mtlf", today)
@@ -118,6 +119,11 @@ func (s *Service) buildReport(date time.Time, today []indicator.Indicator, yeste
 	}
 }
 
+// SendMessage sends a preformatted HTML message via all providers.
+func (s *Service) SendMessage(ctx context.Context, date time.Time, msg string) error {
+	return s.sendAll(ctx, Report{Date: date, Message: msg})
+}
+
 func (s *Service) sendAll(ctx context.Context, report Report) error {
 	var errs []error
 	for _, p := range s.providers {
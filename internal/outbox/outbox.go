@@ -0,0 +1,166 @@
+// Package outbox keeps the Google Sheets export of each stored snapshot owed
+// until it succeeds. Saving a snapshot reserves its task in the same
+// transaction (Reserve), and the report pipeline makes it due once the
+// date's indicators are saved; the report command and the serve worker export the
+// pending tasks oldest first and retry a failed one with exponential backoff,
+// so a Sheets outage delays the MONITORING row instead of dropping it.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/stat/internal/fault"
+)
+
+// Task is the export owed for one snapshot date.
+type Task struct {
+	ID            int64      `json:"id"`
+	SnapshotDate  time.Time  `json:"snapshotDate"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ExportedAt    *time.Time `json:"exportedAt,omitempty"`
+}
+
+// Repository persists export tasks (PgRepository).
+type Repository interface {
+	// Enqueue queues the export of date, resetting an existing task so a
+	// rerun of the date is exported again.
+	Enqueue(ctx context.Context, slug string, date time.Time) error
+	// Pending returns the tasks not exported yet, oldest date first.
+	Pending(ctx context.Context, slug string) ([]Task, error)
+	MarkExported(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, errMsg string, next time.Time) error
+}
+
+// Exporter writes the stored snapshot of date to the spreadsheet.
+type Exporter interface {
+	Export(ctx context.Context, date time.Time) error
+}
+
+// Attempt is the outcome of exporting one task.
+type Attempt struct {
+	Date time.Time
+	Err  error
+}
+
+// Default retry schedule: 1m, 2m, 4m, … capped at 6h.
+const (
+	DefaultBaseDelay = time.Minute
+	DefaultMaxDelay  = 6 * time.Hour
+	DefaultInterval  = 5 * time.Minute
+)
+
+// Service exports the pending tasks of one entity.
+type Service struct {
+	repo      Repository
+	exporter  Exporter
+	slug      string
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithBackoff waits base after the first failure of a task, doubling per
+// further failure up to max.
+func WithBackoff(base, max time.Duration) Option {
+	return func(s *Service) {
+		s.baseDelay, s.maxDelay = base, max
+	}
+}
+
+// WithInterval sets how often Run looks for due tasks.
+func WithInterval(d time.Duration) Option {
+	return func(s *Service) {
+		s.interval = d
+	}
+}
+
+// NewService creates a Service exporting the tasks of the entity slug.
+func NewService(repo Repository, exporter Exporter, slug string, opts ...Option) *Service {
+	s := &Service{repo: repo, exporter: exporter, slug: slug,
+		baseDelay: DefaultBaseDelay, maxDelay: DefaultMaxDelay, interval: DefaultInterval, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Backoff returns the delay before the next attempt of a task that has
+// failed attempts times.
+func (s *Service) Backoff(attempts int) time.Duration {
+	d := s.baseDelay
+	for i := 1; i < attempts && d < s.maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, s.maxDelay)
+}
+
+// retryDelay is the wait after the attempts-th failure, err. Access refused
+// or a spreadsheet gone needs an operator, so it waits the longest delay
+// straight away; anything else backs off.
+func (s *Service) retryDelay(attempts int, err error) time.Duration {
+	if fault.Is(err, fault.Auth) || fault.Is(err, fault.NotFound) {
+		return s.maxDelay
+	}
+	return s.Backoff(attempts)
+}
+
+// Drain exports the pending tasks oldest first. It stops at the first
+// failure and, unless force, at the first task still backing off, so an
+// outage costs one failed attempt per drain rather than one per task. The
+// returned attempts cover the tasks tried; the error is a storage failure.
+func (s *Service) Drain(ctx context.Context, force bool) ([]Attempt, error) {
+	tasks, err := s.repo.Pending(ctx, s.slug)
+	if err != nil {
+		return nil, err
+	}
+	var out []Attempt
+	for _, t := range tasks {
+		if ctx.Err() != nil || (!force && s.now().Before(t.NextAttemptAt)) {
+			break
+		}
+		err := s.exporter.Export(ctx, t.SnapshotDate)
+		out = append(out, Attempt{Date: t.SnapshotDate, Err: err})
+		if err != nil && ctx.Err() != nil {
+			break // interrupted, not a failed attempt
+		}
+		if err != nil {
+			next := s.now().Add(s.retryDelay(t.Attempts+1, err))
+			slog.Error("sheets export failed", "date", t.SnapshotDate.Format("2006-01-02"),
+				"attempt", t.Attempts+1, "nextAttempt", next.Format(time.RFC3339), "error", err)
+			if err := s.repo.MarkFailed(context.WithoutCancel(ctx), t.ID, err.Error(), next); err != nil {
+				return out, err
+			}
+			break
+		}
+		if err := s.repo.MarkExported(context.WithoutCancel(ctx), t.ID); err != nil {
+			return out, err
+		}
+		slog.Info("sheets export done", "date", t.SnapshotDate.Format("2006-01-02"), "attempts", t.Attempts+1)
+	}
+	return out, nil
+}
+
+// Run drains the due tasks every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Drain(ctx, false); err != nil && ctx.Err() == nil {
+			slog.Error("export outbox drain failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
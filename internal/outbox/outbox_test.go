@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/fault"
+)
+
+var now = time.Date(2026, 10, 3, 6, 0, 0, 0, time.UTC)
+
+func day(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+
+type memRepo struct {
+	tasks []Task
+}
+
+func (m *memRepo) Enqueue(_ context.Context, _ string, date time.Time) error {
+	m.tasks = append(m.tasks, Task{ID: int64(len(m.tasks) + 1), SnapshotDate: date, NextAttemptAt: now})
+	return nil
+}
+
+func (m *memRepo) Pending(context.Context, string) ([]Task, error) {
+	var out []Task
+	for _, t := range m.tasks {
+		if t.ExportedAt == nil {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) task(id int64) *Task { return &m.tasks[id-1] }
+
+func (m *memRepo) MarkExported(_ context.Context, id int64) error {
+	t := m.task(id)
+	t.Attempts++
+	t.ExportedAt = &now
+	return nil
+}
+
+func (m *memRepo) MarkFailed(_ context.Context, id int64, errMsg string, next time.Time) error {
+	t := m.task(id)
+	t.Attempts++
+	t.LastError, t.NextAttemptAt = errMsg, next
+	return nil
+}
+
+type stubExporter struct {
+	fail     map[time.Time]bool
+	err      error // returned for the failing dates; nil is a plain error
+	exported []time.Time
+}
+
+func (e *stubExporter) Export(_ context.Context, date time.Time) error {
+	if e.fail[date] {
+		if e.err != nil {
+			return e.err
+		}
+		return errors.New("sheets unavailable")
+	}
+	e.exported = append(e.exported, date)
+	return nil
+}
+
+func newTestService(repo Repository, exp Exporter) *Service {
+	s := NewService(repo, exp, "mtlf", WithBackoff(time.Minute, 10*time.Minute))
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestDrainStopsAtFirstFailure(t *testing.T) {
+	repo := &memRepo{}
+	for _, d := range []int{1, 2, 3} {
+		_ = repo.Enqueue(context.Background(), "mtlf", day(d))
+	}
+	exp := &stubExporter{fail: map[time.Time]bool{day(2): true}}
+	svc := newTestService(repo, exp)
+
+	attempts, err := svc.Drain(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[0].Err != nil || attempts[1].Err == nil {
+		t.Fatalf("attempts = %+v, want day 1 exported and day 2 failed", attempts)
+	}
+	if len(exp.exported) != 1 || repo.task(3).Attempts != 0 {
+		t.Errorf("exported %v, day 3 attempts %d; want day 3 left untouched", exp.exported, repo.task(3).Attempts)
+	}
+	failed := repo.task(2)
+	if failed.Attempts != 1 || failed.LastError == "" || !failed.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("failed task = %+v", failed)
+	}
+
+	// Still backing off: nothing is tried without force.
+	if attempts, _ := svc.Drain(context.Background(), false); len(attempts) != 0 {
+		t.Errorf("attempts during backoff = %+v, want none", attempts)
+	}
+
+	delete(exp.fail, day(2))
+	attempts, err = svc.Drain(context.Background(), true)
+	if err != nil || len(attempts) != 2 {
+		t.Fatalf("forced drain = %+v, %v; want days 2 and 3", attempts, err)
+	}
+	if pending, _ := repo.Pending(context.Background(), "mtlf"); len(pending) != 0 {
+		t.Errorf("pending = %+v, want none", pending)
+	}
+}
+
+func TestDrainWaitsLongestWhenAccessIsRefused(t *testing.T) {
+	repo := &memRepo{}
+	_ = repo.Enqueue(context.Background(), "mtlf", day(1))
+	exp := &stubExporter{fail: map[time.Time]bool{day(1): true},
+		err: fault.New("sheets", fault.Auth, errors.New("googleapi: Error 403: The caller does not have permission"))}
+
+	if _, err := newTestService(repo, exp).Drain(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	if next := repo.task(1).NextAttemptAt; !next.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("next attempt = %s, want the 10m max delay after a refusal", next)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	svc := NewService(nil, nil, "mtlf", WithBackoff(time.Minute, 10*time.Minute))
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 5: 10 * time.Minute, 40: 10 * time.Minute} {
+		if got := svc.Backoff(attempts); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores export tasks in export_outbox.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL export outbox repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Enqueue implements Repository. It also makes a task reserved by Reserve
+// due now.
+func (r *PgRepository) Enqueue(ctx context.Context, slug string, date time.Time) error {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO export_outbox (entity_id, snapshot_date)
+		 SELECT id, $2 FROM fund_entities WHERE slug = $1
+		 ON CONFLICT (entity_id, snapshot_date) DO UPDATE
+		   SET attempts = 0, last_error = '', next_attempt_at = CURRENT_TIMESTAMP,
+		       created_at = CURRENT_TIMESTAMP, exported_at = NULL`,
+		slug, date)
+	if err != nil {
+		return fmt.Errorf("queueing sheets export for %s: %w", date.Format("2006-01-02"), err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("queueing sheets export: entity %q not found", slug)
+	}
+	return nil
+}
+
+// ReserveHold is how long a task reserved with a snapshot stays held back
+// for its indicators. Enqueue makes it due once they are saved; should the
+// run die in between, the task comes due when the hold ends, and is retried
+// until the date's indicators exist.
+const ReserveHold = time.Hour
+
+// Reserve returns a snapshot.SaveHook queuing the export of each snapshot
+// of slug in the transaction that saves it, held back by ReserveHold, so a
+// stored snapshot always has its export owed. Snapshots of other entities
+// are left alone.
+func (r *PgRepository) Reserve(slug string) func(ctx context.Context, tx pgx.Tx, entityID int, date time.Time) error {
+	return func(ctx context.Context, tx pgx.Tx, entityID int, date time.Time) error {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO export_outbox (entity_id, snapshot_date, next_attempt_at)
+			 SELECT id, $2, CURRENT_TIMESTAMP + make_interval(secs => $4) FROM fund_entities WHERE id = $1 AND slug = $3
+			 ON CONFLICT (entity_id, snapshot_date) DO UPDATE
+			   SET attempts = 0, last_error = '', next_attempt_at = EXCLUDED.next_attempt_at,
+			       created_at = CURRENT_TIMESTAMP, exported_at = NULL`,
+			entityID, date, slug, ReserveHold.Seconds()); err != nil {
+			return fmt.Errorf("reserving sheets export for %s: %w", date.Format("2006-01-02"), err)
+		}
+		return nil
+	}
+}
+
+// Pending implements Repository.
+func (r *PgRepository) Pending(ctx context.Context, slug string) ([]Task, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT o.id, o.snapshot_date, o.attempts, o.last_error, o.next_attempt_at, o.created_at, o.exported_at
+		 FROM export_outbox o
+		 JOIN fund_entities fe ON fe.id = o.entity_id
+		 WHERE fe.slug = $1 AND o.exported_at IS NULL
+		 ORDER BY o.snapshot_date`,
+		slug)
+	if err != nil {
+		return nil, fmt.Errorf("listing pending sheets exports: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Task
+	for rows.Next() {
+		var t Task
+		if err := rows.Scan(&t.ID, &t.SnapshotDate, &t.Attempts, &t.LastError, &t.NextAttemptAt, &t.CreatedAt, &t.ExportedAt); err != nil {
+			return nil, fmt.Errorf("scanning sheets export: %w", err)
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating sheets exports: %w", err)
+	}
+	return out, nil
+}
+
+// MarkExported implements Repository.
+func (r *PgRepository) MarkExported(ctx context.Context, id int64) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE export_outbox SET attempts = attempts + 1, last_error = '', exported_at = CURRENT_TIMESTAMP
+		 WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("marking sheets export %d done: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed implements Repository.
+func (r *PgRepository) MarkFailed(ctx context.Context, id int64, errMsg string, next time.Time) error {
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE export_outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		 WHERE id = $1`, id, errMsg, next); err != nil {
+		return fmt.Errorf("recording failed sheets export %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Package pacing spaces requests to Horizon at a configured rate. One Pacer
+// is shared by every service of a snapshot run, so the total request rate is
+// bounded no matter which service is busy.
+package pacing
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Pacer hands out request slots at a fixed rate, with a burst allowance that
+// is earned back while idle. Unlike fixed sleeps between requests it only
+// waits for whatever is left of the interval: when Horizon is slower than the
+// budget, requests go out without any delay. A nil *Pacer never waits.
+type Pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	next     time.Time // earliest start of the next request
+}
+
+// New returns a Pacer allowing rps requests per second, of which up to one
+// second's worth may go out back to back after an idle period. rps <= 0
+// disables pacing and returns nil.
+func New(rps float64) *Pacer {
+	if rps <= 0 {
+		return nil
+	}
+	return &Pacer{
+		interval: time.Duration(float64(time.Second) / rps),
+		burst:    max(1, int(math.Ceil(rps))),
+	}
+}
+
+// Wait blocks until the caller may send its next request, or ctx is done.
+// It returns ctx.Err() when ctx is cancelled, even if no wait was needed, so
+// callers can use it as their cancellation check between requests.
+func (p *Pacer) Wait(ctx context.Context) error {
+	if p == nil {
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	// Idle time earns credit for at most burst-1 immediate extra requests.
+	if earliest := now.Add(-time.Duration(p.burst-1) * p.interval); p.next.Before(earliest) {
+		p.next = earliest
+	}
+	slot := p.next
+	p.next = slot.Add(p.interval)
+	p.mu.Unlock()
+
+	d := slot.Sub(now)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
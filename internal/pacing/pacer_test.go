@@ -0,0 +1,59 @@
+package pacing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewDisabled(t *testing.T) {
+	if New(0) != nil || New(-1) != nil {
+		t.Error("rps <= 0 must disable pacing")
+	}
+	var p *Pacer
+	if err := p.Wait(context.Background()); err != nil {
+		t.Errorf("nil pacer Wait = %v, want nil", err)
+	}
+}
+
+func TestWaitBurstThenRate(t *testing.T) {
+	p := New(20) // 50ms interval, burst of 20
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 20 {
+		if err := p.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("burst of 20 took %s, want no waiting", elapsed)
+	}
+
+	start = time.Now()
+	for range 3 {
+		if err := p.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 120*time.Millisecond {
+		t.Errorf("3 requests past the burst took %s, want about 150ms", elapsed)
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	p := New(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := p.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := p.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait after cancel = %v, want context.Canceled", err)
+	}
+	var nilPacer *Pacer
+	if err := nilPacer.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("nil pacer Wait after cancel = %v, want context.Canceled", err)
+	}
+}
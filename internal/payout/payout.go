@@ -0,0 +1,227 @@
+// Package payout turns a dividend distribution (holders.Distribution) into
+// unsigned Stellar transactions paying every holder from the distributor
+// account, at most 100 payments each, with a validation report. Signing and
+// submitting happen elsewhere; only the transaction XDR needed for that is
+// encoded here.
+package payout
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/holders"
+	"github.com/mtlprog/stat/internal/stellarkey"
+)
+
+// MaxOpsPerTx is the Stellar limit on operations in one transaction.
+const MaxOpsPerTx = 100
+
+// DefaultBaseFee is the fee per operation, in stroops, unless told otherwise.
+const DefaultBaseFee = 100
+
+// DefaultMemo is the memo template of a dividend transaction. The "mtl div "
+// prefix is what I11 and I18 recognise, and every batch of one run must
+// share the memo to be counted as one distribution.
+const DefaultMemo = "mtl div {date}"
+
+// maxMemoBytes is the length limit of a Stellar text memo.
+const maxMemoBytes = 28
+
+// Issue severities. An error blocks writing the batches; a warning is only
+// reported.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue kinds.
+const (
+	IssueBadAddress   = "bad-address"  // the recipient isn't a G... address; left out
+	IssueNoTrustline  = "no-trustline" // the recipient can't hold the asset; left out
+	IssueDust         = "dust"         // the dividend rounds to zero; left out
+	IssueSelfPayment  = "self-payment" // the recipient is the source; left out
+	IssueInsufficient = "insufficient" // the source holds less than the payouts
+	IssueNoPayments   = "no-payments"  // nothing left to pay
+)
+
+// Issue is one finding of the validation report.
+type Issue struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Account  string `json:"account,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Options configures Build.
+type Options struct {
+	Source     string           // paying account, G...
+	Sequence   int64            // the source's current sequence; the batch with Index n uses Sequence+n
+	Asset      domain.AssetInfo // paid asset (EURMTL)
+	BaseFee    uint32           // stroops per operation; DefaultBaseFee when zero
+	OpsPerTx   int              // MaxOpsPerTx when zero
+	Memo       string           // template with {date}, {batch}, {batches}; DefaultMemo when empty
+	Passphrase string           // network of the hashes; PublicNetworkPassphrase when empty
+
+	// Trustlines lists the accounts able to receive Asset; nil skips the
+	// check. Available is the source's balance of Asset; nil skips it.
+	Trustlines map[string]bool
+	Available  *decimal.Decimal
+}
+
+// Batch is one unsigned transaction.
+type Batch struct {
+	Index    int             `json:"index"` // 1-based
+	Sequence int64           `json:"sequence"`
+	Memo     string          `json:"memo"`
+	Payments int             `json:"payments"`
+	Amount   decimal.Decimal `json:"amount"`
+	Fee      uint32          `json:"fee"` // total, in stroops
+	Hash     string          `json:"hash"`
+	XDR      string          `json:"xdr"` // base64 TransactionEnvelope without signatures
+}
+
+// Plan is the set of transactions paying a distribution, with the report.
+type Plan struct {
+	Source   string          `json:"source"`
+	Asset    string          `json:"asset"`
+	Date     string          `json:"date"` // record date of the holder set
+	Holders  int             `json:"holders"`
+	Payments int             `json:"payments"`
+	Amount   decimal.Decimal `json:"amount"` // sum of the payments
+	Fees     decimal.Decimal `json:"fees"`   // XLM
+	Batches  []Batch         `json:"batches"`
+	Issues   []Issue         `json:"issues"`
+}
+
+// Errors counts the issues that block writing the batches.
+func (p Plan) Errors() int {
+	n := 0
+	for _, is := range p.Issues {
+		if is.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Build lays the payouts of d out in transactions from opts.Source. Payouts
+// that can't be paid are left out and reported; the remaining ones keep the
+// order of d. An error is returned only for unusable options.
+func Build(d holders.Distribution, opts Options) (Plan, error) {
+	source, err := stellarkey.PublicKey(opts.Source)
+	if err != nil {
+		return Plan{}, fmt.Errorf("source account: %w", err)
+	}
+	if opts.OpsPerTx == 0 {
+		opts.OpsPerTx = MaxOpsPerTx
+	}
+	if opts.OpsPerTx < 1 || opts.OpsPerTx > MaxOpsPerTx {
+		return Plan{}, fmt.Errorf("operations per transaction must be 1–%d, got %d", MaxOpsPerTx, opts.OpsPerTx)
+	}
+	if opts.BaseFee == 0 {
+		opts.BaseFee = DefaultBaseFee
+	}
+	if opts.Memo == "" {
+		opts.Memo = DefaultMemo
+	}
+	if opts.Passphrase == "" {
+		opts.Passphrase = PublicNetworkPassphrase
+	}
+
+	plan := Plan{
+		Source:  opts.Source,
+		Asset:   opts.Asset.Code,
+		Date:    d.Date.Format("2006-01-02"),
+		Holders: len(d.Payouts),
+		Batches: []Batch{},
+		Issues:  []Issue{},
+	}
+	var payable []payment
+	var amounts []decimal.Decimal
+	for _, p := range d.Payouts {
+		skip := func(severity, kind, msg string) {
+			plan.Issues = append(plan.Issues, Issue{Severity: severity, Kind: kind, Account: p.Account, Message: msg})
+		}
+		dest, err := stellarkey.PublicKey(p.Account)
+		switch {
+		case err != nil:
+			skip(SeverityError, IssueBadAddress, err.Error())
+			continue
+		case p.Account == opts.Source:
+			skip(SeverityWarning, IssueSelfPayment, "the source holds "+p.Balance.String()+"; not paid to itself")
+			continue
+		case !p.Dividend.IsPositive():
+			skip(SeverityWarning, IssueDust, "dividend on "+p.Balance.String()+" rounds to zero")
+			continue
+		case opts.Trustlines != nil && !opts.Trustlines[p.Account]:
+			skip(SeverityError, IssueNoTrustline, fmt.Sprintf("no %s trustline; %s not paid", opts.Asset.Code, p.Dividend))
+			continue
+		}
+		payable = append(payable, payment{destination: dest, stroops: p.Dividend.Shift(7).IntPart()})
+		amounts = append(amounts, p.Dividend)
+		plan.Amount = plan.Amount.Add(p.Dividend)
+	}
+	plan.Payments = len(payable)
+	if len(payable) == 0 {
+		plan.Issues = append(plan.Issues, Issue{Severity: SeverityError, Kind: IssueNoPayments, Message: "no payouts left to pay"})
+		return plan, nil
+	}
+	if opts.Available != nil && opts.Available.LessThan(plan.Amount) {
+		plan.Issues = append(plan.Issues, Issue{
+			Severity: SeverityError,
+			Kind:     IssueInsufficient,
+			Account:  opts.Source,
+			Message:  fmt.Sprintf("holds %s %s, the payouts need %s", opts.Available, opts.Asset.Code, plan.Amount),
+		})
+	}
+
+	batches := (len(payable) + opts.OpsPerTx - 1) / opts.OpsPerTx
+	var stroops int64
+	for i := range batches {
+		lo, hi := i*opts.OpsPerTx, min((i+1)*opts.OpsPerTx, len(payable))
+		memo := renderMemo(opts.Memo, d.Date, i+1, batches)
+		if len(memo) > maxMemoBytes {
+			return Plan{}, fmt.Errorf("memo %q is %d bytes, the limit is %d", memo, len(memo), maxMemoBytes)
+		}
+		tx := transaction{
+			source:   source,
+			fee:      opts.BaseFee * uint32(hi-lo),
+			sequence: opts.Sequence + int64(i) + 1,
+			memo:     memo,
+			asset:    opts.Asset,
+			payments: payable[lo:hi],
+		}
+		raw, err := tx.encode()
+		if err != nil {
+			return Plan{}, err
+		}
+		b := Batch{Index: i + 1, Sequence: tx.sequence, Memo: memo, Payments: hi - lo, Fee: tx.fee}
+		for _, a := range amounts[lo:hi] {
+			b.Amount = b.Amount.Add(a)
+		}
+		h := hash(raw, opts.Passphrase)
+		b.Hash = hex.EncodeToString(h[:])
+		b.XDR = base64.StdEncoding.EncodeToString(envelope(raw))
+		plan.Batches = append(plan.Batches, b)
+		stroops += int64(tx.fee)
+	}
+	plan.Fees = decimal.New(stroops, -7)
+	return plan, nil
+}
+
+// renderMemo fills the memo template: {date} is the record date as
+// dd/mm/yyyy, {batch} and {batches} number the transaction.
+func renderMemo(tmpl string, date time.Time, batch, batches int) string {
+	return strings.NewReplacer(
+		"{date}", date.Format("02/01/2006"),
+		"{batch}", strconv.Itoa(batch),
+		"{batches}", strconv.Itoa(batches),
+	).Replace(tmpl)
+}
@@ -0,0 +1,173 @@
+package payout
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/holders"
+	"github.com/mtlprog/stat/internal/stellarkey"
+)
+
+var recordDate = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func distribution(n int) holders.Distribution {
+	d := holders.Distribution{Asset: holders.AssetMTL, Date: recordDate}
+	for i := range n {
+		d.Payouts = append(d.Payouts, holders.Payout{
+			Account:  domain.MTLAPAddress,
+			Balance:  decimal.NewFromInt(int64(10 + i)),
+			Dividend: decimal.New(int64(1+i), -2), // 0.01, 0.02, ...
+		})
+	}
+	return d
+}
+
+func TestBuildBatches(t *testing.T) {
+	plan, err := Build(distribution(250), Options{
+		Source:   domain.MTLDividendDistributor,
+		Sequence: 1000,
+		Asset:    domain.EURMTLAsset(),
+		Memo:     "mtl div {date} {batch}/{batches}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Batches) != 3 || plan.Payments != 250 || plan.Errors() != 0 {
+		t.Fatalf("plan = %d batches, %d payments, issues %+v", len(plan.Batches), plan.Payments, plan.Issues)
+	}
+	last := plan.Batches[2]
+	if last.Payments != 50 || last.Sequence != 1003 || last.Fee != 5000 || last.Memo != "mtl div 01/09/2026 3/3" {
+		t.Errorf("last batch = %+v", last)
+	}
+	// 0.01 + … + 2.50
+	if plan.Amount.String() != "313.75" || plan.Fees.String() != "0.0025" {
+		t.Errorf("amount = %s, fees = %s", plan.Amount, plan.Fees)
+	}
+	if len(plan.Batches[0].Hash) != 64 || plan.Batches[0].Hash == plan.Batches[1].Hash {
+		t.Errorf("hashes = %s, %s", plan.Batches[0].Hash, plan.Batches[1].Hash)
+	}
+}
+
+func TestBuildReportsUnpayable(t *testing.T) {
+	d := distribution(1)
+	d.Payouts = append(d.Payouts,
+		holders.Payout{Account: "GNOTANADDRESS", Dividend: decimal.NewFromInt(1)},
+		holders.Payout{Account: domain.IssuerAddress, Dividend: decimal.Zero},
+		holders.Payout{Account: domain.USDMIssuer, Dividend: decimal.NewFromInt(1)},
+		holders.Payout{Account: domain.MTLDividendDistributor, Dividend: decimal.NewFromInt(1)},
+	)
+	available := decimal.RequireFromString("0.005")
+	plan, err := Build(d, Options{
+		Source:     domain.MTLDividendDistributor,
+		Asset:      domain.EURMTLAsset(),
+		Trustlines: map[string]bool{domain.MTLAPAddress: true, domain.IssuerAddress: true},
+		Available:  &available,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]string{}
+	for _, is := range plan.Issues {
+		kinds[is.Kind] = is.Severity
+	}
+	want := map[string]string{
+		IssueBadAddress:   SeverityError,
+		IssueDust:         SeverityWarning,
+		IssueNoTrustline:  SeverityError,
+		IssueSelfPayment:  SeverityWarning,
+		IssueInsufficient: SeverityError,
+	}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("issues = %+v, want kinds %v", plan.Issues, want)
+	}
+	if plan.Payments != 1 || plan.Errors() != 3 {
+		t.Errorf("payments = %d, errors = %d", plan.Payments, plan.Errors())
+	}
+
+	if _, err := Build(d, Options{Source: domain.MTLDividendDistributor, Asset: domain.EURMTLAsset(), Memo: "mtl dividend for {date} batch {batch}"}); err == nil {
+		t.Error("a memo over 28 bytes must be rejected")
+	}
+}
+
+// TestEnvelopeLayout decodes a one-payment envelope field by field.
+func TestEnvelopeLayout(t *testing.T) {
+	plan, err := Build(distribution(1), Options{
+		Source:   domain.MTLDividendDistributor,
+		Sequence: 41,
+		Asset:    domain.EURMTLAsset(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(plan.Batches[0].XDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 200 {
+		t.Fatalf("envelope is %d bytes, want 200", len(raw))
+	}
+	r := bytes.NewReader(raw)
+	u32 := func() uint32 {
+		var v uint32
+		_ = binary.Read(r, binary.BigEndian, &v)
+		return v
+	}
+	u64 := func() uint64 {
+		var v uint64
+		_ = binary.Read(r, binary.BigEndian, &v)
+		return v
+	}
+	key := func() []byte {
+		b := make([]byte, 32)
+		_, _ = r.Read(b)
+		return b
+	}
+	fixed := func(n int) []byte {
+		b := make([]byte, n)
+		_, _ = r.Read(b)
+		return b
+	}
+	mustKey := func(address string) []byte {
+		k, err := stellarkey.PublicKey(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	check := func(field string, got, want any) {
+		t.Helper()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s = %v, want %v", field, got, want)
+		}
+	}
+	check("envelope type", u32(), envelopeTypeTx)
+	check("source key type", u32(), keyTypeEd25519)
+	check("source", key(), mustKey(domain.MTLDividendDistributor))
+	check("fee", u32(), DefaultBaseFee)
+	check("sequence", u64(), 42)
+	check("preconditions", u32(), preconditionNone)
+	check("memo type", u32(), memoText)
+	check("memo length", u32(), 18)
+	check("memo", string(fixed(20)[:18]), "mtl div 01/09/2026")
+	check("operations", u32(), 1)
+	check("op source", u32(), 0)
+	check("op type", u32(), operationPayment)
+	check("destination key type", u32(), keyTypeEd25519)
+	check("destination", key(), mustKey(domain.MTLAPAddress))
+	check("asset type", u32(), assetAlphanum12)
+	check("asset code", string(bytes.TrimRight(fixed(12), "\x00")), "EURMTL")
+	check("issuer key type", u32(), publicKeyEd25519)
+	check("issuer", key(), mustKey(domain.IssuerAddress))
+	check("amount", u64(), 100000) // 0.01 EURMTL in stroops
+	check("tx ext", u32(), transactionExtNone)
+	check("signatures", u32(), 0)
+	check("trailing bytes", r.Len(), 0)
+}
@@ -0,0 +1,140 @@
+package payout
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/stellarkey"
+)
+
+// PublicNetworkPassphrase identifies the Stellar public network in
+// transaction hashes.
+const PublicNetworkPassphrase = "Public Global Stellar Network ; September 2015"
+
+// XDR discriminants (Stellar-transaction.x, Stellar-ledger-entries.x).
+const (
+	envelopeTypeTx     = 2
+	keyTypeEd25519     = 0
+	preconditionNone   = 0
+	memoText           = 1
+	operationPayment   = 1
+	assetAlphanum4     = 1
+	assetAlphanum12    = 2
+	publicKeyEd25519   = 0
+	transactionExtNone = 0
+)
+
+// xdrWriter appends XDR-encoded values (RFC 4506): big-endian, padded to
+// 4 bytes.
+type xdrWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *xdrWriter) uint32(v uint32) {
+	w.buf.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (w *xdrWriter) int32(v int32) { w.uint32(uint32(v)) }
+
+func (w *xdrWriter) int64(v int64) {
+	w.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+}
+
+// fixed writes fixed-length opaque data.
+func (w *xdrWriter) fixed(b []byte) {
+	w.buf.Write(b)
+	w.pad(len(b))
+}
+
+// string writes variable-length opaque data with its length.
+func (w *xdrWriter) string(s string) {
+	w.uint32(uint32(len(s)))
+	w.buf.WriteString(s)
+	w.pad(len(s))
+}
+
+func (w *xdrWriter) pad(n int) {
+	if r := n % 4; r != 0 {
+		w.buf.Write(make([]byte, 4-r))
+	}
+}
+
+// payment is one payment operation of a transaction.
+type payment struct {
+	destination []byte // ed25519 key
+	stroops     int64
+}
+
+// transaction is the part of a Stellar transaction a payout batch varies.
+type transaction struct {
+	source   []byte // ed25519 key
+	fee      uint32 // total, in stroops
+	sequence int64
+	memo     string
+	asset    domain.AssetInfo
+	payments []payment
+}
+
+// encode returns the XDR of the Transaction (not the envelope).
+func (t transaction) encode() ([]byte, error) {
+	issuer, err := stellarkey.PublicKey(t.asset.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("asset issuer: %w", err)
+	}
+	var code []byte
+	var assetType int32
+	switch n := len(t.asset.Code); {
+	case n >= 1 && n <= 4:
+		code, assetType = make([]byte, 4), assetAlphanum4
+	case n >= 5 && n <= 12:
+		code, assetType = make([]byte, 12), assetAlphanum12
+	default:
+		return nil, fmt.Errorf("invalid asset code %q", t.asset.Code)
+	}
+	copy(code, t.asset.Code)
+
+	var w xdrWriter
+	w.int32(keyTypeEd25519) // MuxedAccount sourceAccount
+	w.fixed(t.source)
+	w.uint32(t.fee)
+	w.int64(t.sequence)
+	w.int32(preconditionNone)
+	w.int32(memoText)
+	w.string(t.memo)
+	w.uint32(uint32(len(t.payments)))
+	for _, p := range t.payments {
+		w.uint32(0) // no operation source account
+		w.int32(operationPayment)
+		w.int32(keyTypeEd25519) // MuxedAccount destination
+		w.fixed(p.destination)
+		w.int32(assetType)
+		w.fixed(code)
+		w.int32(publicKeyEd25519)
+		w.fixed(issuer)
+		w.int64(p.stroops)
+	}
+	w.int32(transactionExtNone)
+	return w.buf.Bytes(), nil
+}
+
+// envelope wraps the transaction XDR in an unsigned TransactionEnvelope.
+func envelope(tx []byte) []byte {
+	var w xdrWriter
+	w.int32(envelopeTypeTx)
+	w.buf.Write(tx)
+	w.uint32(0) // no signatures
+	return w.buf.Bytes()
+}
+
+// hash returns the transaction hash signers sign on the given network.
+func hash(tx []byte, passphrase string) [32]byte {
+	network := sha256.Sum256([]byte(passphrase))
+	var w xdrWriter
+	w.buf.Write(network[:])
+	w.int32(envelopeTypeTx)
+	w.buf.Write(tx)
+	return sha256.Sum256(w.buf.Bytes())
+}
@@ -0,0 +1,123 @@
+// Package peer captures comparable metrics for external Stellar treasuries at
+// snapshot time, so the fund can be benchmarked against them. Peers are priced
+// through the same fund.Service pipeline as the fund's own accounts, minus the
+// fund's manual valuations.
+package peer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// peerTimeout caps the time spent on one peer. A slow or missing peer is
+// recorded with an error rather than holding up the snapshot.
+const peerTimeout = 2 * time.Minute
+
+// PortfolioSource prices a single account (fund.Service).
+type PortfolioSource interface {
+	Portfolio(ctx context.Context, acc domain.FundAccount, allValuations []domain.AssetValuation) (domain.FundAccountPortfolio, []string, error)
+}
+
+// IssuedAssetSource lists the assets an account issues (horizon.Client).
+type IssuedAssetSource interface {
+	FetchIssuedAssets(ctx context.Context, issuer string) ([]horizon.HorizonAsset, error)
+}
+
+// Service fills FundStructureData.Peers for a fixed list of peer accounts.
+type Service struct {
+	portfolios PortfolioSource
+	assets     IssuedAssetSource
+	peers      []domain.FundAccount
+}
+
+// NewService creates a peer Service. peers usually comes from ParseAccounts.
+func NewService(portfolios PortfolioSource, assets IssuedAssetSource, peers []domain.FundAccount) *Service {
+	return &Service{portfolios: portfolios, assets: assets, peers: peers}
+}
+
+// ParseAccounts parses PEER_ACCOUNTS entries of the form NAME=ADDRESS. A bare
+// ADDRESS is named after its last four characters, the way Stellar vanity
+// addresses are usually referred to.
+func ParseAccounts(entries []string) ([]domain.FundAccount, error) {
+	seen := make(map[string]bool, len(entries))
+	var out []domain.FundAccount
+	for _, e := range entries {
+		name, addr, ok := strings.Cut(e, "=")
+		if !ok {
+			addr = name
+			name = ""
+		}
+		name, addr = strings.TrimSpace(name), strings.TrimSpace(addr)
+		if len(addr) != 56 || addr[0] != 'G' {
+			return nil, fmt.Errorf("invalid peer account %q: expected a G... Stellar address", e)
+		}
+		if name == "" {
+			name = addr[len(addr)-4:]
+		}
+		if seen[addr] {
+			return nil, fmt.Errorf("duplicate peer account %s", addr)
+		}
+		seen[addr] = true
+		out = append(out, domain.FundAccount{Name: name, Type: domain.AccountTypePeer, Address: addr})
+	}
+	return out, nil
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher. Each peer is fetched
+// independently: a failure is logged and recorded on that peer's entry, and
+// only a cancelled ctx is returned as an error.
+func (s *Service) EnrichMetrics(ctx context.Context, _ time.Time, data *domain.FundStructureData) error {
+	if len(s.peers) == 0 {
+		return nil
+	}
+	peers := make([]domain.PeerMetrics, 0, len(s.peers))
+	for _, acc := range s.peers {
+		m, err := s.measure(ctx, acc)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			slog.Error("peer metrics unavailable", "peer", acc.Name, "address", acc.Address, "error", err)
+			m = domain.PeerMetrics{Name: acc.Name, Address: acc.Address, Error: err.Error()}
+		}
+		peers = append(peers, m)
+	}
+	data.Peers = peers
+	return nil
+}
+
+func (s *Service) measure(ctx context.Context, acc domain.FundAccount) (domain.PeerMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, peerTimeout)
+	defer cancel()
+
+	portfolio, warnings, err := s.portfolios.Portfolio(ctx, acc, nil)
+	if err != nil {
+		return domain.PeerMetrics{}, fmt.Errorf("fetching portfolio: %w", err)
+	}
+	for _, w := range warnings {
+		slog.Debug("peer portfolio warning", "peer", acc.Name, "warning", w)
+	}
+
+	issued, err := s.assets.FetchIssuedAssets(ctx, acc.Address)
+	if err != nil {
+		return domain.PeerMetrics{}, fmt.Errorf("fetching issued assets: %w", err)
+	}
+	var holders int
+	for _, a := range issued {
+		holders = max(holders, a.Accounts.Authorized)
+	}
+
+	return domain.PeerMetrics{
+		Name:        acc.Name,
+		Address:     acc.Address,
+		TotalEURMTL: portfolio.TotalEURMTL,
+		TokenCount:  len(portfolio.Tokens),
+		HolderCount: holders,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package peer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+const (
+	addrA = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAPEER"
+	addrB = "GBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBDOWN"
+)
+
+type stubPortfolios struct {
+	byAddr     map[string]domain.FundAccountPortfolio
+	errs       map[string]error
+	valuations [][]domain.AssetValuation
+}
+
+func (s *stubPortfolios) Portfolio(_ context.Context, acc domain.FundAccount, vals []domain.AssetValuation) (domain.FundAccountPortfolio, []string, error) {
+	s.valuations = append(s.valuations, vals)
+	if err := s.errs[acc.Address]; err != nil {
+		return domain.FundAccountPortfolio{}, nil, err
+	}
+	return s.byAddr[acc.Address], nil, nil
+}
+
+type stubIssued map[string][]horizon.HorizonAsset
+
+func (s stubIssued) FetchIssuedAssets(_ context.Context, issuer string) ([]horizon.HorizonAsset, error) {
+	return s[issuer], nil
+}
+
+func TestParseAccounts(t *testing.T) {
+	got, err := ParseAccounts([]string{"Peer Fund=" + addrA, addrB})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d accounts, want 2", len(got))
+	}
+	if got[0].Name != "Peer Fund" || got[0].Address != addrA || got[0].Type != domain.AccountTypePeer {
+		t.Errorf("named entry = %+v", got[0])
+	}
+	if got[1].Name != "DOWN" {
+		t.Errorf("bare address name = %q, want last four characters", got[1].Name)
+	}
+}
+
+func TestParseAccountsErrors(t *testing.T) {
+	for _, entries := range [][]string{
+		{"X=GSHORT"},
+		{"X=S" + addrA[1:]},
+		{addrA, "Again=" + addrA},
+	} {
+		if _, err := ParseAccounts(entries); err == nil {
+			t.Errorf("ParseAccounts(%v): expected error", entries)
+		}
+	}
+}
+
+func TestEnrichMetrics(t *testing.T) {
+	portfolios := &stubPortfolios{
+		byAddr: map[string]domain.FundAccountPortfolio{
+			addrA: {TotalEURMTL: decimal.NewFromInt(1500), Tokens: make([]domain.TokenPriceWithBalance, 3)},
+		},
+		errs: map[string]error{addrB: errors.New("horizon 503")},
+	}
+	issued := stubIssued{addrA: {
+		{AssetCode: "AAA", Accounts: horizon.HorizonAssetAccounts{Authorized: 12}},
+		{AssetCode: "BBB", Accounts: horizon.HorizonAssetAccounts{Authorized: 40}},
+	}}
+	peers, _ := ParseAccounts([]string{"A=" + addrA, "B=" + addrB})
+	svc := NewService(portfolios, issued, peers)
+
+	var data domain.FundStructureData
+	if err := svc.EnrichMetrics(context.Background(), time.Now(), &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(data.Peers) != 2 {
+		t.Fatalf("got %d peers, want 2", len(data.Peers))
+	}
+	a := data.Peers[0]
+	if !a.TotalEURMTL.Equal(decimal.NewFromInt(1500)) || a.TokenCount != 3 || a.HolderCount != 40 || a.Error != "" {
+		t.Errorf("peer A = %+v, want 1500 EURMTL, 3 tokens, 40 holders", a)
+	}
+	b := data.Peers[1]
+	if b.Name != "B" || !strings.Contains(b.Error, "horizon 503") {
+		t.Errorf("peer B = %+v, want recorded error", b)
+	}
+	for _, v := range portfolios.valuations {
+		if v != nil {
+			t.Error("peers must be priced without the fund's manual valuations")
+		}
+	}
+}
+
+func TestEnrichMetricsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	portfolios := &stubPortfolios{errs: map[string]error{addrA: context.Canceled}}
+	peers, _ := ParseAccounts([]string{addrA})
+
+	var data domain.FundStructureData
+	err := NewService(portfolios, stubIssued{}, peers).EnrichMetrics(ctx, time.Now(), &data)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if data.Peers != nil {
+		t.Error("cancelled run must not write partial peers")
+	}
+}
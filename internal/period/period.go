@@ -0,0 +1,145 @@
+// Package period builds month-end and quarter-end fund reports from the
+// stored indicator history, and picks the baselines period-over-period
+// changes compare with.
+package period
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPeriod indicates a period string that is neither YYYY-MM nor YYYY-QN.
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// Kind is a reporting boundary.
+type Kind string
+
+const (
+	Month   Kind = "month"
+	Quarter Kind = "quarter"
+)
+
+// Period is a calendar month or quarter. Start and End are the first and
+// last day (inclusive), midnight UTC like snapshot dates.
+type Period struct {
+	Kind  Kind
+	Start time.Time
+	End   time.Time
+}
+
+// Of returns the period of kind containing date.
+func Of(kind Kind, date time.Time) Period {
+	month := date.Month()
+	months := 1
+	if kind == Quarter {
+		month = (month-1)/3*3 + 1
+		months = 3
+	}
+	start := time.Date(date.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	return Period{Kind: kind, Start: start, End: start.AddDate(0, months, -1)}
+}
+
+// Parse reads "2026-09" as a month and "2026-Q3" as a quarter.
+func Parse(s string) (Period, error) {
+	year, rest, ok := strings.Cut(s, "-")
+	y, err := strconv.Atoi(year)
+	if !ok || err != nil || len(year) != 4 {
+		return Period{}, fmt.Errorf("%w %q: want YYYY-MM or YYYY-QN", ErrInvalidPeriod, s)
+	}
+	if q, isQuarter := strings.CutPrefix(strings.ToUpper(rest), "Q"); isQuarter {
+		n, err := strconv.Atoi(q)
+		if err != nil || n < 1 || n > 4 {
+			return Period{}, fmt.Errorf("%w %q: quarter must be Q1..Q4", ErrInvalidPeriod, s)
+		}
+		return Of(Quarter, time.Date(y, time.Month(n*3), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+	m, err := strconv.Atoi(rest)
+	if err != nil || len(rest) != 2 || m < 1 || m > 12 {
+		return Period{}, fmt.Errorf("%w %q: month must be 01..12", ErrInvalidPeriod, s)
+	}
+	return Of(Month, time.Date(y, time.Month(m), 1, 0, 0, 0, 0, time.UTC)), nil
+}
+
+// String returns the period in the form Parse accepts.
+func (p Period) String() string {
+	if p.Kind == Quarter {
+		return fmt.Sprintf("%d-Q%d", p.Start.Year(), (int(p.Start.Month())-1)/3+1)
+	}
+	return p.Start.Format("2006-01")
+}
+
+// EndingOn returns the periods among kinds whose last day is date.
+func EndingOn(date time.Time, kinds []Kind) []Period {
+	var out []Period
+	for _, k := range kinds {
+		if p := Of(k, date); p.End.Equal(date) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ParseKinds parses REPORT_PERIODS entries ("month", "quarter"); "none"
+// contributes nothing, so REPORT_PERIODS=none switches reports off.
+func ParseKinds(entries []string) ([]Kind, error) {
+	var kinds []Kind
+	for _, e := range entries {
+		switch k := Kind(strings.ToLower(strings.TrimSpace(e))); k {
+		case Month, Quarter:
+			kinds = append(kinds, k)
+		case "none":
+		default:
+			return nil, fmt.Errorf("unknown report period %q (want month or quarter)", e)
+		}
+	}
+	return kinds, nil
+}
+
+// Comparison selects the baseline a change over N days is measured from.
+type Comparison string
+
+const (
+	// Rolling compares with the date N days earlier.
+	Rolling Comparison = "rolling"
+	// Calendar compares with the last day of the previous calendar week
+	// (N ≤ 7), month (≤ 31), quarter (≤ 92), half-year (≤ 183) or year.
+	Calendar Comparison = "calendar"
+)
+
+// ParseComparison reads COMPARISON_MODE and the API baseline parameter;
+// "" is Rolling.
+func ParseComparison(s string) (Comparison, error) {
+	switch c := Comparison(strings.ToLower(strings.TrimSpace(s))); c {
+	case "", Rolling:
+		return Rolling, nil
+	case Calendar:
+		return Calendar, nil
+	default:
+		return "", fmt.Errorf("unknown comparison %q (want rolling or calendar)", s)
+	}
+}
+
+// Baseline returns the date a change over days ending on date compares with.
+func (c Comparison) Baseline(date time.Time, days int) time.Time {
+	if c != Calendar {
+		return date.AddDate(0, 0, -days)
+	}
+	var start time.Time
+	switch {
+	case days <= 7:
+		// Weeks run Monday to Sunday.
+		start = date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case days <= 31:
+		start = Of(Month, date).Start
+	case days <= 92:
+		start = Of(Quarter, date).Start
+	case days <= 183:
+		start = time.Date(date.Year(), (date.Month()-1)/6*6+1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		start = time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return start.AddDate(0, 0, -1)
+}
@@ -0,0 +1,116 @@
+package period
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in         string
+		kind       Kind
+		start, end string
+	}{
+		{"2026-09", Month, "2026-09-01", "2026-09-30"},
+		{"2024-02", Month, "2024-02-01", "2024-02-29"},
+		{"2026-Q3", Quarter, "2026-07-01", "2026-09-30"},
+		{"2026-q4", Quarter, "2026-10-01", "2026-12-31"},
+	}
+	for _, tc := range tests {
+		p, err := Parse(tc.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.in, err)
+		}
+		if p.Kind != tc.kind || !p.Start.Equal(day(tc.start)) || !p.End.Equal(day(tc.end)) {
+			t.Errorf("Parse(%q) = %s %s..%s, want %s %s..%s", tc.in, p.Kind, p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"), tc.kind, tc.start, tc.end)
+		}
+	}
+
+	for _, bad := range []string{"", "2026", "2026-13", "2026-9", "2026-Q5", "26-09", "2026-Qx"} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("Parse(%q) err = %v, want ErrInvalidPeriod", bad, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := Of(Quarter, day("2026-11-15")).String(); got != "2026-Q4" {
+		t.Errorf("quarter String = %q, want 2026-Q4", got)
+	}
+	if got := Of(Month, day("2026-01-31")).String(); got != "2026-01" {
+		t.Errorf("month String = %q, want 2026-01", got)
+	}
+}
+
+func TestEndingOn(t *testing.T) {
+	both := []Kind{Month, Quarter}
+	if got := EndingOn(day("2026-09-30"), both); len(got) != 2 || got[0].String() != "2026-09" || got[1].String() != "2026-Q3" {
+		t.Errorf("EndingOn(Sep 30) = %v, want month and quarter", got)
+	}
+	if got := EndingOn(day("2026-08-31"), both); len(got) != 1 || got[0].Kind != Month {
+		t.Errorf("EndingOn(Aug 31) = %v, want month only", got)
+	}
+	if got := EndingOn(day("2026-09-29"), both); len(got) != 0 {
+		t.Errorf("EndingOn(Sep 29) = %v, want none", got)
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := ParseKinds([]string{"Month", " quarter"})
+	if err != nil || len(kinds) != 2 || kinds[0] != Month || kinds[1] != Quarter {
+		t.Errorf("ParseKinds = %v, %v", kinds, err)
+	}
+	if kinds, err := ParseKinds([]string{"none"}); err != nil || len(kinds) != 0 {
+		t.Errorf("ParseKinds(none) = %v, %v, want no kinds", kinds, err)
+	}
+	if _, err := ParseKinds([]string{"week"}); err == nil {
+		t.Error("ParseKinds(week) succeeded, want error")
+	}
+}
+
+func TestComparisonBaseline(t *testing.T) {
+	tests := []struct {
+		c    Comparison
+		date string
+		days int
+		want string
+	}{
+		{Rolling, "2026-10-15", 30, "2026-09-15"},
+		{Rolling, "2026-10-15", 365, "2025-10-15"},
+		{Calendar, "2026-10-15", 7, "2026-10-11"}, // Thursday → previous Sunday
+		{Calendar, "2026-10-12", 7, "2026-10-11"}, // Monday
+		{Calendar, "2026-10-11", 7, "2026-10-04"}, // Sunday ends its own week
+		{Calendar, "2026-10-15", 30, "2026-09-30"},
+		{Calendar, "2026-09-30", 30, "2026-08-31"},
+		{Calendar, "2026-10-15", 90, "2026-09-30"},
+		{Calendar, "2026-09-30", 90, "2026-06-30"},
+		{Calendar, "2026-10-15", 180, "2026-06-30"},
+		{Calendar, "2026-03-01", 180, "2025-12-31"},
+		{Calendar, "2026-10-15", 365, "2025-12-31"},
+	}
+	for _, tc := range tests {
+		if got := tc.c.Baseline(day(tc.date), tc.days); !got.Equal(day(tc.want)) {
+			t.Errorf("%s.Baseline(%s, %d) = %s, want %s", tc.c, tc.date, tc.days, got.Format("2006-01-02"), tc.want)
+		}
+	}
+}
+
+func TestParseComparison(t *testing.T) {
+	for in, want := range map[string]Comparison{"": Rolling, "rolling": Rolling, "Calendar": Calendar} {
+		if got, err := ParseComparison(in); err != nil || got != want {
+			t.Errorf("ParseComparison(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseComparison("fiscal"); err == nil {
+		t.Error("ParseComparison(fiscal) succeeded, want an error")
+	}
+}
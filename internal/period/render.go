@@ -0,0 +1,109 @@
+package period
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Markdown renders r as a Markdown document.
+func Markdown(r *Report) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# MTL Fund report %s\n\n", r.Period)
+	fmt.Fprintf(&sb, "Period: %s – %s\n", r.Start, r.End)
+
+	if len(r.TopMovers) > 0 {
+		sb.WriteString("\n## Top movers\n\n| ID | Indicator | Open | Close | Change |\n|---|---|---:|---:|---:|\n")
+		for _, c := range r.TopMovers {
+			fmt.Fprintf(&sb, "| I%d | %s | %s | %s | %s%% |\n", c.ID, c.Name, c.Open, c.Value, signed(*c.ChangePercent))
+		}
+	}
+
+	sb.WriteString("\n## Dividends\n\n")
+	for _, m := range r.Dividends.Months {
+		fmt.Fprintf(&sb, "- %s: %s EURMTL\n", m.Month, m.Amount)
+	}
+	fmt.Fprintf(&sb, "- **Total: %s EURMTL**, %s recipients at period end\n", r.Dividends.Total, r.Dividends.Recipients)
+
+	if ret := r.Returns; ret != nil {
+		fmt.Fprintf(&sb, "\n## Shareholder return\n\nOne share held through the period: %s → %s EURMTL, %s EURMTL dividends; shares %s → %s.\n\n", ret.OpenPrice, ret.ClosePrice, ret.DividendsPerShare, ret.OpenShares, ret.CloseShares)
+		sb.WriteString("| Component | Return |\n|---|---:|\n")
+		fmt.Fprintf(&sb, "| Capital gain | %s%% |\n", signed(ret.CapitalGain))
+		fmt.Fprintf(&sb, "| Dilution from issuance | %s%% |\n", signed(ret.Dilution))
+		fmt.Fprintf(&sb, "| Price change | %s%% |\n", signed(ret.PriceChange))
+		fmt.Fprintf(&sb, "| Dividends | %s%% |\n", signed(ret.Dividends))
+		fmt.Fprintf(&sb, "| **Total** | **%s%%** |\n", signed(ret.Total))
+	}
+
+	if len(r.Subfonds) > 0 {
+		sb.WriteString("\n## Subfond ROI\n\nChange of each subfond's value net of transfers with the other fund accounts, over the average capital.\n\n")
+		sb.WriteString("| Subfond | Open | Close | Transfers in | Transfers out | Gain | ROI |\n|---|---:|---:|---:|---:|---:|---:|\n")
+		for _, sf := range r.Subfonds {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s | %s |\n", sf.Subfond, sf.Open, sf.Close, sf.Inflows, sf.Outflows, signed(sf.Gain), roiPercent(sf.ROI))
+		}
+	}
+
+	sb.WriteString("\n## Indicators\n\n| ID | Indicator | Value | Unit | Change | Change % |\n|---|---|---:|---|---:|---:|\n")
+	for _, c := range r.Indicators {
+		diff, pct := "", ""
+		if c.Change != nil {
+			diff = signed(*c.Change)
+		}
+		if c.ChangePercent != nil {
+			pct = signed(*c.ChangePercent) + "%"
+		}
+		fmt.Fprintf(&sb, "| I%d | %s | %s | %s | %s | %s |\n", c.ID, c.Name, c.Value, c.Unit, diff, pct)
+	}
+	return sb.String()
+}
+
+// HTML renders the headline part of r as Telegram HTML for notifications;
+// the full table stays in the stored report.
+func HTML(r *Report, reportURL string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<b>📅 Отчёт MTL Fund за %s</b>\n%s – %s\n", r.Period, r.Start, r.End)
+
+	if len(r.TopMovers) > 0 {
+		sb.WriteString("\n<b>Главные изменения:</b>\n")
+		for _, c := range r.TopMovers {
+			fmt.Fprintf(&sb, "I%d %s: %s → %s %s (%s%%)\n", c.ID, c.Name, c.Open, c.Value, c.Unit, signed(*c.ChangePercent))
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n<b>Дивиденды:</b> %s EURMTL, получателей: %s\n", r.Dividends.Total, r.Dividends.Recipients)
+	if ret := r.Returns; ret != nil {
+		fmt.Fprintf(&sb, "<b>Доходность акции:</b> %s%% (цена %s%%, из них размывание %s%%; дивиденды %s%%)\n",
+			signed(ret.Total), signed(ret.PriceChange), signed(ret.Dilution), signed(ret.Dividends))
+	}
+	if len(r.Subfonds) > 0 {
+		sb.WriteString("<b>Доходность субфондов:</b>")
+		for i, sf := range r.Subfonds {
+			sep := ", "
+			if i == 0 {
+				sep = " "
+			}
+			fmt.Fprintf(&sb, "%s%s %s", sep, sf.Subfond, roiPercent(sf.ROI))
+		}
+		sb.WriteString("\n")
+	}
+	if reportURL != "" {
+		fmt.Fprintf(&sb, "\n<a href=\"%s\">Полный отчёт</a>", reportURL)
+	}
+	return sb.String()
+}
+
+// roiPercent renders a subfond ROI, or "n/a" without capital.
+func roiPercent(roi *decimal.Decimal) string {
+	if roi == nil {
+		return "n/a"
+	}
+	return signed(*roi) + "%"
+}
+
+func signed(d decimal.Decimal) string {
+	if d.IsPositive() {
+		return "+" + d.String()
+	}
+	return d.String()
+}
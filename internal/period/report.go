@@ -0,0 +1,174 @@
+package period
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+// ErrNoData indicates that no indicators were stored on or before the period end.
+var ErrNoData = errors.New("no indicators for period")
+
+// TopMovers is how many indicators the report lists by largest relative change.
+const TopMovers = 5
+
+// Indicator IDs the dividend section reads.
+const (
+	monthlyDividendsID = 11
+	recipientsID       = 18
+)
+
+// Report is a period summary. Closing values are the latest indicators on or
+// before the period end; opening values are the close of the previous
+// period, so changes cover the whole period.
+type Report struct {
+	Period      string        `json:"period"`
+	Kind        Kind          `json:"kind"`
+	Start       string        `json:"start"`
+	End         string        `json:"end"`
+	Indicators  []Change      `json:"indicators"`
+	TopMovers   []Change      `json:"topMovers"`
+	Dividends   Dividends     `json:"dividends"`
+	Returns     *Returns      `json:"returns,omitempty"`  // absent without I5 and I10 at both period ends
+	Subfonds    []subfond.ROI `json:"subfonds,omitempty"` // per-subfond ROI, absent without subfond flows
+	GeneratedAt time.Time     `json:"generatedAt"`
+}
+
+// Change is one indicator's close with its opening value and change, when an
+// opening value exists.
+type Change struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	Unit          string           `json:"unit"`
+	Value         decimal.Decimal  `json:"value"`
+	Open          *decimal.Decimal `json:"open,omitempty"`
+	Change        *decimal.Decimal `json:"change,omitempty"`
+	ChangePercent *decimal.Decimal `json:"changePercent,omitempty"`
+}
+
+// Dividends totals I11 (the latest monthly distribution) at each month end
+// of the period; Recipients is I18 at the period end.
+type Dividends struct {
+	Total      decimal.Decimal `json:"total"`
+	Months     []MonthDividend `json:"months"`
+	Recipients decimal.Decimal `json:"recipients"`
+}
+
+// MonthDividend is the distribution recorded for one month of the period.
+type MonthDividend struct {
+	Month  string          `json:"month"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Service builds and stores period reports.
+type Service struct {
+	indicators indicator.Repository
+	repo       Repository
+	flows      FlowSource // nil leaves the subfond ROI out
+}
+
+// NewService creates a period report Service.
+func NewService(indicators indicator.Repository, repo Repository, opts ...ServiceOption) *Service {
+	s := &Service{indicators: indicators, repo: repo}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Generate builds the report for p and stores it, replacing any earlier one.
+func (s *Service) Generate(ctx context.Context, slug string, p Period) (*Report, error) {
+	r, err := s.Build(ctx, slug, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, slug, p, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Build assembles the report for p from stored indicators.
+func (s *Service) Build(ctx context.Context, slug string, p Period) (*Report, error) {
+	closing, err := s.indicators.GetNearestBefore(ctx, slug, p.End)
+	if err != nil {
+		return nil, fmt.Errorf("loading closing indicators: %w", err)
+	}
+	if len(closing) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoData, p)
+	}
+	opening, err := s.indicators.GetNearestBefore(ctx, slug, p.Start.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("loading opening indicators: %w", err)
+	}
+
+	r := &Report{
+		Period:      p.String(),
+		Kind:        p.Kind,
+		Start:       p.Start.Format("2006-01-02"),
+		End:         p.End.Format("2006-01-02"),
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, ind := range closing {
+		r.Indicators = append(r.Indicators, change(ind, opening))
+	}
+	slices.SortFunc(r.Indicators, func(a, b Change) int { return a.ID - b.ID })
+	r.TopMovers = topMovers(r.Indicators, TopMovers)
+
+	var perShare decimal.Decimal
+	for m := p.Start; m.Before(p.End); m = m.AddDate(0, 1, 0) {
+		monthEnd := m.AddDate(0, 1, -1)
+		vals := closing
+		if !monthEnd.Equal(p.End) {
+			if vals, err = s.indicators.GetNearestBefore(ctx, slug, monthEnd); err != nil {
+				return nil, fmt.Errorf("loading indicators for %s: %w", m.Format("2006-01"), err)
+			}
+		}
+		amount := vals[monthlyDividendsID].Value
+		r.Dividends.Months = append(r.Dividends.Months, MonthDividend{Month: m.Format("2006-01"), Amount: amount})
+		r.Dividends.Total = r.Dividends.Total.Add(amount)
+		perShare = perShare.Add(dividendsPerShare(vals))
+	}
+	r.Dividends.Recipients = closing[recipientsID].Value
+	r.Returns = decompose(opening, closing, perShare)
+	if r.Subfonds, err = s.subfondReturns(ctx, slug, p, opening, closing); err != nil {
+		return nil, fmt.Errorf("subfond returns: %w", err)
+	}
+	return r, nil
+}
+
+func change(ind indicator.Indicator, opening map[int]indicator.Indicator) Change {
+	c := Change{ID: ind.ID, Name: ind.Name, Unit: ind.Unit, Value: ind.Value}
+	prev, ok := opening[ind.ID]
+	if !ok {
+		return c
+	}
+	open, diff := prev.Value, ind.Value.Sub(prev.Value)
+	c.Open, c.Change = &open, &diff
+	if !open.IsZero() {
+		pct := diff.Div(open.Abs()).Mul(decimal.NewFromInt(100)).Round(2)
+		c.ChangePercent = &pct
+	}
+	return c
+}
+
+// topMovers returns the n changes with the largest absolute percent change.
+func topMovers(changes []Change, n int) []Change {
+	var movers []Change
+	for _, c := range changes {
+		if c.ChangePercent != nil && !c.ChangePercent.IsZero() {
+			movers = append(movers, c)
+		}
+	}
+	slices.SortStableFunc(movers, func(a, b Change) int {
+		return b.ChangePercent.Abs().Cmp(a.ChangePercent.Abs())
+	})
+	return movers[:min(n, len(movers))]
+}
@@ -0,0 +1,189 @@
+package period
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+// fakeIndicators serves GetNearestBefore from per-date values; the embedded
+// interface panics on any other call.
+type fakeIndicators struct {
+	indicator.Repository
+	byDate map[string]map[int]string
+}
+
+func (f *fakeIndicators) GetNearestBefore(_ context.Context, _ string, date time.Time) (map[int]indicator.Indicator, error) {
+	var best string
+	for d := range f.byDate {
+		if d <= date.Format("2006-01-02") && d > best {
+			best = d
+		}
+	}
+	if best == "" {
+		return nil, nil
+	}
+	out := make(map[int]indicator.Indicator)
+	for id, v := range f.byDate[best] {
+		out[id] = indicator.NewIndicator(id, decimal.RequireFromString(v), "", "")
+	}
+	return out, nil
+}
+
+type memRepo struct {
+	saved map[string]*Report
+}
+
+func (m *memRepo) Save(_ context.Context, _ string, p Period, r *Report) error {
+	m.saved[p.String()] = r
+	return nil
+}
+
+func (m *memRepo) Get(_ context.Context, _ string, p Period) (*Stored, error) {
+	r, ok := m.saved[p.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &Stored{Report: *r, Markdown: Markdown(r)}, nil
+}
+
+func TestGenerateQuarter(t *testing.T) {
+	inds := &fakeIndicators{byDate: map[string]map[int]string{
+		"2026-06-30": {3: "1000", 10: "2", 11: "0"},
+		"2026-07-31": {3: "1100", 10: "2", 11: "50"},
+		"2026-08-31": {3: "1200", 10: "2.5", 11: "60"},
+		"2026-09-29": {3: "1200", 10: "3", 11: "70", 18: "120"},
+	}}
+	repo := &memRepo{saved: map[string]*Report{}}
+	q, _ := Parse("2026-Q3")
+
+	r, err := NewService(inds, repo).Generate(context.Background(), "mtlf", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.saved["2026-Q3"] != r {
+		t.Error("report was not stored")
+	}
+
+	if !r.Dividends.Total.Equal(decimal.NewFromInt(180)) || len(r.Dividends.Months) != 3 || !r.Dividends.Recipients.Equal(decimal.NewFromInt(120)) {
+		t.Errorf("dividends = %+v, want 50+60+70 = 180 over 3 months, 120 recipients", r.Dividends)
+	}
+
+	if len(r.TopMovers) != 2 || r.TopMovers[0].ID != 10 || r.TopMovers[1].ID != 3 {
+		t.Errorf("top movers = %+v, want I10 +50%%, I3 +20%% (I11 opened at 0, I18 had no open)", r.TopMovers)
+	}
+
+	var i18 Change
+	for _, c := range r.Indicators {
+		if c.ID == 18 {
+			i18 = c
+		}
+	}
+	if i18.Open != nil || i18.ChangePercent != nil {
+		t.Errorf("I18 had no opening value, got %+v", i18)
+	}
+
+	md := Markdown(r)
+	for _, want := range []string{"# MTL Fund report 2026-Q3", "| I10 | Share Market Price | 2 | 3 | +50% |", "**Total: 180 EURMTL**"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestBuildNoData(t *testing.T) {
+	svc := NewService(&fakeIndicators{byDate: map[string]map[int]string{"2026-10-01": {3: "1"}}}, &memRepo{})
+	m, _ := Parse("2026-09")
+	if _, err := svc.Build(context.Background(), "mtlf", m); !errors.Is(err, ErrNoData) {
+		t.Errorf("err = %v, want ErrNoData", err)
+	}
+}
+
+func TestReturnsDecomposition(t *testing.T) {
+	inds := &fakeIndicators{byDate: map[string]map[int]string{
+		"2026-08-31": {5: "1000", 10: "2", 11: "0"},
+		"2026-09-30": {5: "1100", 10: "2.2", 11: "110", 15: "0.1"},
+	}}
+	m, _ := Parse("2026-09")
+
+	ret, err := NewService(inds, &memRepo{}).Returns(context.Background(), "mtlf", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Market cap 2000 → 2420 is +21% per opening share; 100 new shares take
+	// 11% off it, leaving the +10% price change; 0.1 dividends add 5%.
+	for name, c := range map[string]struct{ got, want decimal.Decimal }{
+		"capitalGain": {ret.CapitalGain, decimal.NewFromInt(21)},
+		"dilution":    {ret.Dilution, decimal.NewFromInt(-11)},
+		"priceChange": {ret.PriceChange, decimal.NewFromInt(10)},
+		"dividends":   {ret.Dividends, decimal.NewFromInt(5)},
+		"total":       {ret.Total, decimal.NewFromInt(15)},
+	} {
+		if !c.got.Equal(c.want) {
+			t.Errorf("%s = %s, want %s", name, c.got, c.want)
+		}
+	}
+
+	r, _ := NewService(inds, &memRepo{}).Build(context.Background(), "mtlf", m)
+	if md := Markdown(r); !strings.Contains(md, "| **Total** | **+15%** |") {
+		t.Errorf("markdown lacks the return section:\n%s", md)
+	}
+}
+
+func TestReturnsWithoutSharePrice(t *testing.T) {
+	inds := &fakeIndicators{byDate: map[string]map[int]string{
+		"2026-09-30": {5: "1100", 10: "2.2"},
+	}}
+	m, _ := Parse("2026-09")
+	if _, err := NewService(inds, &memRepo{}).Returns(context.Background(), "mtlf", m); !errors.Is(err, ErrNoData) {
+		t.Errorf("err = %v, want ErrNoData without an opening price", err)
+	}
+}
+
+// fakeFlows lists the same subfond flows for any range.
+type fakeFlows []subfond.Flow
+
+func (f fakeFlows) List(context.Context, string, time.Time, time.Time) ([]subfond.Flow, error) {
+	return f, nil
+}
+
+func TestSubfondROI(t *testing.T) {
+	defi, _ := subfond.Lookup("DEFI")
+	inds := &fakeIndicators{byDate: map[string]map[int]string{
+		"2026-05-31": {51: "1000", 52: "400"},
+		"2026-06-30": {51: "1600", 52: "400", 53: "900"},
+	}}
+	flows := fakeFlows{{Subfond: "DEFI", Account: defi.Address, Direction: subfond.In,
+		ValueEURMTL: decimal.NewFromInt(500), At: time.Date(2026, 6, 16, 0, 0, 0, 0, time.UTC)}}
+	svc := NewService(inds, &memRepo{}, WithSubfondFlows(flows))
+	m, _ := Parse("2026-06")
+
+	r, err := svc.Build(context.Background(), "mtlf", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MCITY and DEFI have values at both ends; MABIZ only at the close.
+	if len(r.Subfonds) != 2 || r.Subfonds[0].Subfond != "MCITY" || r.Subfonds[1].Subfond != "DEFI" {
+		t.Fatalf("subfonds = %+v, want MCITY and DEFI", r.Subfonds)
+	}
+	if got := r.Subfonds[1]; got.ROI == nil || !got.ROI.Equal(decimal.NewFromInt(8)) || got.From != "2026-05-31" {
+		t.Errorf("DEFI = %+v, want 8%% from 2026-05-31", got)
+	}
+	if md := Markdown(r); !strings.Contains(md, "| DEFI | 1000 | 1600 | 500 | 0 | +100 | +8% |") {
+		t.Errorf("markdown lacks the subfond section:\n%s", md)
+	}
+
+	if _, err := svc.SubfondROI(context.Background(), "mtlf", defi, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), m.End); !errors.Is(err, ErrNoData) {
+		t.Errorf("err = %v, want ErrNoData before the first value", err)
+	}
+	if r, _ := NewService(inds, &memRepo{}).Build(context.Background(), "mtlf", m); r.Subfonds != nil {
+		t.Errorf("without flows: %+v", r.Subfonds)
+	}
+}
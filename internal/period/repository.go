@@ -0,0 +1,76 @@
+package period
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNotFound indicates that no report is stored for the requested period.
+var ErrNotFound = errors.New("period report not found")
+
+// Stored is a saved report with its rendered Markdown.
+type Stored struct {
+	Report   Report
+	Markdown string
+}
+
+// Repository persists period reports.
+type Repository interface {
+	Save(ctx context.Context, slug string, p Period, r *Report) error
+	Get(ctx context.Context, slug string, p Period) (*Stored, error)
+}
+
+// PgRepository implements Repository with PostgreSQL.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL period report repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+func (r *PgRepository) Save(ctx context.Context, slug string, p Period, report *Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("encoding period report: %w", err)
+	}
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO period_reports (entity_id, period, kind, start_date, end_date, data, markdown)
+		 SELECT id, $2, $3, $4, $5, $6, $7 FROM fund_entities WHERE slug = $1
+		 ON CONFLICT (entity_id, period) DO UPDATE
+		 SET data = EXCLUDED.data, markdown = EXCLUDED.markdown, created_at = CURRENT_TIMESTAMP`,
+		slug, p.String(), string(p.Kind), p.Start, p.End, data, Markdown(report))
+	if err != nil {
+		return fmt.Errorf("saving period report %s: %w", p, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("saving period report %s: entity %q not found", p, slug)
+	}
+	return nil
+}
+
+func (r *PgRepository) Get(ctx context.Context, slug string, p Period) (*Stored, error) {
+	var s Stored
+	var data []byte
+	err := r.pool.QueryRow(ctx,
+		`SELECT pr.data, pr.markdown
+		 FROM period_reports pr
+		 JOIN fund_entities fe ON fe.id = pr.entity_id
+		 WHERE fe.slug = $1 AND pr.period = $2`, slug, p.String()).Scan(&data, &s.Markdown)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting period report %s: %w", p, err)
+	}
+	if err := json.Unmarshal(data, &s.Report); err != nil {
+		return nil, fmt.Errorf("decoding period report %s: %w", p, err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,95 @@
+package period
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// Indicator IDs the return decomposition reads.
+const (
+	sharesID            = 5
+	sharePriceID        = 10
+	dividendsPerShareID = 15
+)
+
+// Returns decomposes the total return of one share held through the period,
+// in percent of the opening share price (I10):
+//
+//	Total       = PriceChange + Dividends
+//	PriceChange = CapitalGain + Dilution
+//
+// CapitalGain is the change of market capitalisation (I5 × I10) over the
+// opening shares — what the price would have done had no shares been
+// issued — and Dilution is what the shares issued during the period (I5)
+// took off it.
+type Returns struct {
+	OpenPrice         decimal.Decimal `json:"openPrice"`
+	ClosePrice        decimal.Decimal `json:"closePrice"`
+	OpenShares        decimal.Decimal `json:"openShares"`
+	CloseShares       decimal.Decimal `json:"closeShares"`
+	DividendsPerShare decimal.Decimal `json:"dividendsPerShare"` // Σ I15 at each month end, in EURMTL
+	CapitalGain       decimal.Decimal `json:"capitalGain"`
+	Dilution          decimal.Decimal `json:"dilution"`
+	PriceChange       decimal.Decimal `json:"priceChange"`
+	Dividends         decimal.Decimal `json:"dividends"`
+	Total             decimal.Decimal `json:"total"`
+}
+
+// Returns builds the return decomposition of p from stored indicators.
+func (s *Service) Returns(ctx context.Context, slug string, p Period) (*Returns, error) {
+	r, err := s.Build(ctx, slug, p)
+	if err != nil {
+		return nil, err
+	}
+	if r.Returns == nil {
+		return nil, fmt.Errorf("%w %s: no share price (I10) and share count (I5) at both period ends", ErrNoData, p)
+	}
+	return r.Returns, nil
+}
+
+// dividendsPerShare is I15 at a month end, or I11 / I5 for indicator sets
+// stored without it.
+func dividendsPerShare(vals map[int]indicator.Indicator) decimal.Decimal {
+	if ind, ok := vals[dividendsPerShareID]; ok {
+		return ind.Value
+	}
+	shares := vals[sharesID].Value
+	if shares.IsZero() {
+		return decimal.Zero
+	}
+	return vals[monthlyDividendsID].Value.Div(shares)
+}
+
+// decompose returns nil unless the share price and count are known, and
+// positive, at both ends.
+func decompose(opening, closing map[int]indicator.Indicator, perShare decimal.Decimal) *Returns {
+	p0, s0 := opening[sharePriceID].Value, opening[sharesID].Value
+	p1, s1 := closing[sharePriceID].Value, closing[sharesID].Value
+	if !p0.IsPositive() || !s0.IsPositive() || !p1.IsPositive() || !s1.IsPositive() {
+		return nil
+	}
+
+	hundred := decimal.NewFromInt(100)
+	pct := func(d decimal.Decimal) decimal.Decimal {
+		return d.Div(p0).Mul(hundred).Round(2)
+	}
+	// Undiluted close price: the closing market cap spread over the opening shares.
+	undiluted := p1.Mul(s1).Div(s0)
+	r := &Returns{
+		OpenPrice:         p0,
+		ClosePrice:        p1,
+		OpenShares:        s0,
+		CloseShares:       s1,
+		DividendsPerShare: perShare.Round(7),
+		CapitalGain:       pct(undiluted.Sub(p0)),
+		Dilution:          pct(p1.Sub(undiluted)),
+		PriceChange:       pct(p1.Sub(p0)),
+		Dividends:         pct(perShare),
+	}
+	r.Total = r.PriceChange.Add(r.Dividends)
+	return r
+}
@@ -0,0 +1,76 @@
+package period
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/subfond"
+)
+
+// FlowSource lists stored subfond flows (subfond.PgRepository).
+type FlowSource interface {
+	List(ctx context.Context, slug string, from, to time.Time) ([]subfond.Flow, error)
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithSubfondFlows adds the per-subfond ROI to reports, netting out the
+// flows listed by f.
+func WithSubfondFlows(f FlowSource) ServiceOption {
+	return func(s *Service) { s.flows = f }
+}
+
+// SubfondROI returns the ROI of sf from the end of from to the end of to,
+// from its stored total values and flows. Either end without a stored value
+// is ErrNoData.
+func (s *Service) SubfondROI(ctx context.Context, slug string, sf subfond.Subfond, from, to time.Time) (*subfond.ROI, error) {
+	if s.flows == nil {
+		return nil, errors.New("subfond flows not configured")
+	}
+	opening, err := s.indicators.GetNearestBefore(ctx, slug, from)
+	if err != nil {
+		return nil, fmt.Errorf("loading opening indicators: %w", err)
+	}
+	closing, err := s.indicators.GetNearestBefore(ctx, slug, to)
+	if err != nil {
+		return nil, fmt.Errorf("loading closing indicators: %w", err)
+	}
+	open, okOpen := opening[sf.ValueID]
+	closeVal, okClose := closing[sf.ValueID]
+	if !okOpen || !okClose {
+		return nil, fmt.Errorf("%w: no %s value (I%d) on %s and %s", ErrNoData, sf.Name, sf.ValueID,
+			from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	flows, err := s.flows.List(ctx, slug, from, to)
+	if err != nil {
+		return nil, err
+	}
+	r := subfond.Return(sf, open.Value, closeVal.Value, flows, from, to)
+	return &r, nil
+}
+
+// subfondReturns returns the ROI over p of every subfond with a value at
+// both period ends, or nil without a FlowSource.
+func (s *Service) subfondReturns(ctx context.Context, slug string, p Period, opening, closing map[int]indicator.Indicator) ([]subfond.ROI, error) {
+	if s.flows == nil {
+		return nil, nil
+	}
+	from := p.Start.AddDate(0, 0, -1)
+	flows, err := s.flows.List(ctx, slug, from, p.End)
+	if err != nil {
+		return nil, err
+	}
+	var out []subfond.ROI
+	for _, sf := range subfond.All() {
+		open, okOpen := opening[sf.ValueID]
+		closeVal, okClose := closing[sf.ValueID]
+		if okOpen && okClose {
+			out = append(out, subfond.Return(sf, open.Value, closeVal.Value, flows, from, p.End))
+		}
+	}
+	return out, nil
+}
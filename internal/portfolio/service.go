@@ -3,9 +3,11 @@ package portfolio
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/samber/lo"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
 	"github.com/mtlprog/stat/internal/horizon"
 )
@@ -13,6 +15,7 @@ import (
 // HorizonClient defines the subset of Horizon API used by PortfolioService.
 type HorizonClient interface {
 	FetchAccount(ctx context.Context, accountID string) (horizon.HorizonAccount, error)
+	FetchAccountAsOf(ctx context.Context, accountID string, asOf time.Time) (horizon.HorizonAccount, error)
 }
 
 // Service fetches and converts raw Stellar account balances into domain portfolios.
@@ -26,9 +29,16 @@ func NewService(horizon HorizonClient) *Service {
 }
 
 // FetchPortfolio retrieves balances for a Stellar account and converts them into an AccountPortfolio.
-// LP shares are excluded; XLM is extracted separately.
+// LP shares are excluded; XLM is extracted separately. When ctx carries an
+// as-of time (asof.With), balances are reconstructed for that time.
 func (s *Service) FetchPortfolio(ctx context.Context, accountID string) (domain.AccountPortfolio, error) {
-	account, err := s.horizon.FetchAccount(ctx, accountID)
+	var account horizon.HorizonAccount
+	var err error
+	if t, ok := asof.From(ctx); ok {
+		account, err = s.horizon.FetchAccountAsOf(ctx, accountID, t)
+	} else {
+		account, err = s.horizon.FetchAccount(ctx, accountID)
+	}
 	if err != nil {
 		return domain.AccountPortfolio{}, fmt.Errorf("fetching portfolio for %s: %w", accountID, err)
 	}
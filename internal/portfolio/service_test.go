@@ -3,19 +3,27 @@ package portfolio
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/horizon"
 )
 
 type mockHorizonClient struct {
 	account horizon.HorizonAccount
 	err     error
+	asOf    time.Time // set by FetchAccountAsOf
 }
 
 func (m *mockHorizonClient) FetchAccount(_ context.Context, _ string) (horizon.HorizonAccount, error) {
 	return m.account, m.err
 }
 
+func (m *mockHorizonClient) FetchAccountAsOf(_ context.Context, _ string, asOf time.Time) (horizon.HorizonAccount, error) {
+	m.asOf = asOf
+	return m.account, m.err
+}
+
 func TestFetchPortfolioMixedBalances(t *testing.T) {
 	mock := &mockHorizonClient{
 		account: horizon.HorizonAccount{
@@ -143,3 +151,15 @@ func TestFetchPortfolioAssetTypeDetermination(t *testing.T) {
 		t.Errorf("tokens[1] type = %q, want credit_alphanum12", portfolio.Tokens[1].Asset.Type)
 	}
 }
+
+func TestFetchPortfolioAsOf(t *testing.T) {
+	mock := &mockHorizonClient{account: horizon.HorizonAccount{ID: "GABC123"}}
+	at := time.Date(2026, 3, 1, 23, 59, 59, 0, time.UTC)
+
+	if _, err := NewService(mock).FetchPortfolio(asof.With(context.Background(), at), "GABC123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.asOf.Equal(at) {
+		t.Errorf("FetchAccountAsOf called with %v, want %v", mock.asOf, at)
+	}
+}
@@ -0,0 +1,90 @@
+package price
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// defaultBatchConcurrency is the number of pair lookups GetTokenPricesBatch
+// runs at once unless WithBatchConcurrency says otherwise. The pacer, when
+// set, still bounds the request rate; concurrency only overlaps the waits.
+const defaultBatchConcurrency = 4
+
+// WithBatchConcurrency sets how many pair lookups GetTokenPricesBatch runs at
+// once. Values below 1 mean one at a time.
+func WithBatchConcurrency(n int) Option {
+	return func(s *Service) {
+		s.workers = max(n, 1)
+	}
+}
+
+// AssetBalance is one holding to price with GetTokenPricesBatch.
+type AssetBalance struct {
+	Asset   domain.AssetInfo
+	Balance string
+}
+
+// BatchResult is the outcome of one AssetBalance. Err is set, as by
+// GetTokenPrices, when neither the EURMTL nor the XLM price is known.
+type BatchResult struct {
+	TokenPriceResult
+	Err error
+}
+
+// pairPrice is the outcome of one spot-price lookup.
+type pairPrice struct {
+	price domain.TokenPairPrice
+	err   error
+}
+
+// GetTokenPricesBatch prices every balance as GetTokenPrices would and
+// returns the results in the order of balances. Each distinct asset's EURMTL
+// and XLM spot pairs are looked up once, however many balances hold it, with
+// up to WithBatchConcurrency lookups in flight; the cross rate is resolved
+// once and reused. A cancelled ctx leaves the remaining lookups failed with
+// its error.
+func (s *Service) GetTokenPricesBatch(ctx context.Context, balances []AssetBalance) []BatchResult {
+	type pair struct {
+		asset, base domain.AssetInfo
+	}
+	bases := []domain.AssetInfo{domain.EURMTLAsset(), domain.XLMAsset()}
+	var pairs []pair
+	index := make(map[string]int) // cacheKey → position in pairs
+	for _, b := range balances {
+		for _, base := range bases {
+			key := cacheKey(b.Asset, base, "1")
+			if _, ok := index[key]; !ok {
+				index[key] = len(pairs)
+				pairs = append(pairs, pair{b.Asset, base})
+			}
+		}
+	}
+
+	found := make([]pairPrice, len(pairs))
+	sem := make(chan struct{}, s.workers)
+	var wg sync.WaitGroup
+	for i, p := range pairs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				found[i].price, found[i].err = s.GetPrice(ctx, p.asset, p.base, "1")
+			case <-ctx.Done():
+				found[i].err = ctx.Err()
+			}
+		}()
+	}
+	wg.Wait()
+
+	out := make([]BatchResult, len(balances))
+	for i, b := range balances {
+		eurmtl := found[index[cacheKey(b.Asset, bases[0], "1")]]
+		xlm := found[index[cacheKey(b.Asset, bases[1], "1")]]
+		out[i].TokenPriceResult, out[i].Err = s.combine(ctx, b.Asset, b.Balance, eurmtl.price, eurmtl.err, xlm.price, xlm.err)
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// countingHorizon counts path lookups by source asset and tracks how many run
+// at once.
+type countingHorizon struct {
+	mockHorizon
+	mu       sync.Mutex
+	calls    map[string]int
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (m *countingHorizon) FetchStrictSendPaths(ctx context.Context, source domain.AssetInfo, amount string, dest domain.AssetInfo) ([]horizon.HorizonPathRecord, error) {
+	n := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		p := m.peak.Load()
+		if n <= p || m.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	m.mu.Lock()
+	m.calls[source.Code]++
+	m.mu.Unlock()
+	return m.mockHorizon.FetchStrictSendPaths(ctx, source, amount, dest)
+}
+
+func newCountingHorizon() *countingHorizon {
+	return &countingHorizon{
+		mockHorizon: mockHorizon{
+			strictSendPaths: []horizon.HorizonPathRecord{{SourceAmount: "1", DestinationAmount: "2.0"}},
+			orderbookErr:    errors.New("no orderbook"),
+			poolsErr:        errors.New("no pools"),
+		},
+		calls: make(map[string]int),
+	}
+}
+
+func TestGetTokenPricesBatchDeduplicatesPairs(t *testing.T) {
+	single := newCountingHorizon()
+	want, err := NewService(single).GetTokenPrices(context.Background(), testAsset(), "100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	batched := newCountingHorizon()
+	other := domain.AssetInfo{Code: "MCITY1", Issuer: "GISSUER", Type: domain.AssetTypeCreditAlphanum12}
+	results := NewService(batched, WithBatchConcurrency(2)).GetTokenPricesBatch(context.Background(), []AssetBalance{
+		{Asset: testAsset(), Balance: "100"},
+		{Asset: other, Balance: "3"},
+		{Asset: testAsset(), Balance: "10"},
+	})
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("result %d: unexpected error: %v", i, r.Err)
+		}
+	}
+	if results[0].PriceEURMTL != want.PriceEURMTL || results[0].ValueEURMTL != want.ValueEURMTL {
+		t.Errorf("result 0 = %+v, want %+v", results[0].TokenPriceResult, want)
+	}
+	if results[2].PriceEURMTL != want.PriceEURMTL || results[2].ValueEURMTL == results[0].ValueEURMTL {
+		t.Errorf("result 2 = %+v, want the same price valued at its own balance", results[2].TokenPriceResult)
+	}
+	if got := batched.calls["MTL"]; got != single.calls["MTL"] {
+		t.Errorf("MTL looked up %d times for two balances, want %d as for one", got, single.calls["MTL"])
+	}
+	if peak := batched.peak.Load(); peak > 2 {
+		t.Errorf("%d lookups in flight, want at most 2", peak)
+	}
+}
+
+func TestGetTokenPricesBatchFailure(t *testing.T) {
+	mock := &mockHorizon{
+		strictSendErr:    errors.New("no path"),
+		strictReceiveErr: errors.New("no path"),
+		orderbookErr:     errors.New("no orderbook"),
+		poolsErr:         errors.New("no pools"),
+	}
+	results := NewService(mock).GetTokenPricesBatch(context.Background(), []AssetBalance{{Asset: testAsset(), Balance: "100"}})
+	if len(results) != 1 || results[0].Err == nil {
+		t.Errorf("results = %+v, want one failed result", results)
+	}
+}
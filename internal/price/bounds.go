@@ -0,0 +1,118 @@
+package price
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// SourcePrevious marks a price taken from the reference snapshot because the
+// discovered one was outside the sanity bound.
+const SourcePrevious = "previous"
+
+// WithSanityBound rejects a discovered spot price more than multiple times
+// above or below the reference price of the same pair (see SetReference) and
+// uses the reference price instead. A multiple ≤ 1 disables the check.
+func WithSanityBound(multiple decimal.Decimal) Option {
+	return func(s *Service) {
+		if multiple.GreaterThan(decimal.NewFromInt(1)) {
+			s.bound = multiple
+		}
+	}
+}
+
+// reference holds the last-known spot prices, keyed like the cache.
+type reference struct {
+	mu     sync.RWMutex
+	prices map[string]string
+}
+
+func (r *reference) get(key string) (decimal.Decimal, bool) {
+	r.mu.RLock()
+	raw, ok := r.prices[key]
+	r.mu.RUnlock()
+	if !ok {
+		return decimal.Zero, false
+	}
+	p, err := decimal.NewFromString(raw)
+	if err != nil || !p.IsPositive() {
+		return decimal.Zero, false
+	}
+	return p, true
+}
+
+// SetReference replaces the reference prices with the spot prices recorded in
+// a stored snapshot, normally the one before the snapshot being generated.
+// As in Warm, tokens priced by a manual valuation or through a cross rate are
+// skipped, and prices sized for a holding count at their one-unit price.
+// Returns the number of pairs recorded.
+func (s *Service) SetReference(data domain.FundStructureData) int {
+	eurmtl, xlm := domain.EURMTLAsset(), domain.XLMAsset()
+	prices := make(map[string]string)
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			if acc.XLMPriceInEURMTL != nil {
+				prices[cacheKey(xlm, eurmtl, "1")] = *acc.XLMPriceInEURMTL
+				if p, err := decimal.NewFromString(*acc.XLMPriceInEURMTL); err == nil && p.IsPositive() {
+					prices[cacheKey(eurmtl, xlm, "1")] = decimal.NewFromInt(1).DivRound(p, stellarPrecision).String()
+				}
+			}
+			for _, t := range acc.Tokens {
+				if t.Valuation != nil || t.NFTValuationAccount != "" {
+					continue
+				}
+				if t.PriceInEURMTL != nil && t.DetailsEURMTL != nil {
+					prices[cacheKey(t.Asset, eurmtl, "1")], _ = unitPrice(*t.PriceInEURMTL, t.DetailsEURMTL)
+				}
+				if t.PriceInXLM != nil && t.DetailsXLM != nil {
+					prices[cacheKey(t.Asset, xlm, "1")], _ = unitPrice(*t.PriceInXLM, t.DetailsXLM)
+				}
+			}
+		}
+	}
+	s.reference.mu.Lock()
+	s.reference.prices = prices
+	s.reference.mu.Unlock()
+	return len(prices)
+}
+
+// checkBound returns result, or the reference price in its place when result
+// is outside the sanity bound. The rejected price and its details are kept in
+// the replacement's details.
+func (s *Service) checkBound(key string, result domain.TokenPairPrice) domain.TokenPairPrice {
+	if s.bound.IsZero() {
+		return result
+	}
+	prev, ok := s.reference.get(key)
+	if !ok {
+		return result
+	}
+	p, err := decimal.NewFromString(result.Price)
+	if err != nil {
+		return result
+	}
+	ratio := p.Div(prev)
+	if ratio.LessThanOrEqual(s.bound) && ratio.Mul(s.bound).GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return result
+	}
+
+	slog.Error("price outside sanity bound, using previous snapshot price",
+		"pair", key, "price", result.Price, "previous", prev.String(), "bound", s.bound.String())
+	rejected := result.Price
+	return domain.TokenPairPrice{
+		TokenA:            result.TokenA,
+		TokenB:            result.TokenB,
+		Price:             prev.String(),
+		DestinationAmount: prev.String(),
+		Timestamp:         time.Now(),
+		Details: &domain.PriceDetails{
+			Source:          SourcePrevious,
+			RejectedPrice:   &rejected,
+			RejectedDetails: result.Details,
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+func pathQuote(price string) *mockHorizon {
+	return &mockHorizon{
+		strictSendPaths: []horizon.HorizonPathRecord{{SourceAmount: "1", DestinationAmount: price}},
+		orderbookErr:    errors.New("no orderbook"),
+		poolsErr:        errors.New("no pools"),
+	}
+}
+
+func referenceData(eurmtlPrice string) domain.FundStructureData {
+	return domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{
+		Tokens: []domain.TokenPriceWithBalance{{
+			Asset: testAsset(), PriceInEURMTL: &eurmtlPrice, DetailsEURMTL: &domain.PriceDetails{Source: "path"},
+		}},
+	}}}
+}
+
+func TestSanityBoundRejectsOutlier(t *testing.T) {
+	svc := NewService(pathQuote("2500000"), WithSanityBound(decimal.NewFromInt(10)))
+	if n := svc.SetReference(referenceData("2.5")); n != 1 {
+		t.Fatalf("SetReference recorded %d pairs, want 1", n)
+	}
+
+	p, err := svc.GetPrice(context.Background(), testAsset(), domain.EURMTLAsset(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Price != "2.5" || p.Details == nil || p.Details.Source != SourcePrevious {
+		t.Fatalf("price = %+v, want the previous 2.5", p)
+	}
+	if p.Details.RejectedPrice == nil || *p.Details.RejectedPrice != "2500000" || p.Details.RejectedDetails == nil {
+		t.Errorf("rejection not recorded: %+v", p.Details)
+	}
+}
+
+func TestSanityBoundAcceptsMovesWithinBound(t *testing.T) {
+	for _, quote := range []string{"24", "0.26"} { // ×9.6 and ÷9.6 from 2.5
+		svc := NewService(pathQuote(quote), WithSanityBound(decimal.NewFromInt(10)))
+		svc.SetReference(referenceData("2.5"))
+		p, err := svc.GetPrice(context.Background(), testAsset(), domain.EURMTLAsset(), "1")
+		if err != nil || p.Details.Source == SourcePrevious {
+			t.Errorf("quote %s: price = %+v, %v; want accepted", quote, p, err)
+		}
+	}
+}
+
+func TestSanityBoundOffOrWithoutReference(t *testing.T) {
+	ctx := context.Background()
+
+	// No bound configured: the reference is ignored.
+	svc := NewService(pathQuote("2500000"))
+	svc.SetReference(referenceData("2.5"))
+	if p, _ := svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "1"); p.Details.Source == SourcePrevious {
+		t.Errorf("unbounded service rejected %+v", p)
+	}
+
+	// No reference for the pair: nothing to compare with.
+	svc = NewService(pathQuote("2500000"), WithSanityBound(decimal.NewFromInt(10)))
+	if p, _ := svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "1"); p.Details.Source == SourcePrevious {
+		t.Errorf("price without reference rejected %+v", p)
+	}
+}
@@ -0,0 +1,150 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// WithBridges enables multi-hop routing: besides Horizon's own path, every
+// path price is also quoted as two legs through each bridge asset
+// (source → bridge → dest) and the best rate wins. Each bridge costs up to
+// four extra Horizon calls per pair, so keep the list short.
+func WithBridges(bridges []domain.AssetInfo) Option {
+	return func(s *Service) {
+		s.bridges = bridges
+	}
+}
+
+// knownBridges are the assets ParseBridges accepts by code alone.
+var knownBridges = map[string]domain.AssetInfo{
+	"XLM":    domain.XLMAsset(),
+	"EURMTL": domain.EURMTLAsset(),
+	"USDM":   domain.USDMAsset(),
+}
+
+// ParseBridges parses PRICE_BRIDGE_ASSETS entries: "CODE:ISSUER", or XLM,
+// EURMTL or USDM by code alone.
+func ParseBridges(entries []string) ([]domain.AssetInfo, error) {
+	var out []domain.AssetInfo
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if a, ok := knownBridges[strings.ToUpper(e)]; ok {
+			out = append(out, a)
+			continue
+		}
+		code, issuer, ok := strings.Cut(e, ":")
+		if !ok || code == "" || issuer == "" {
+			return nil, fmt.Errorf("invalid bridge asset %q: want CODE:ISSUER, XLM, EURMTL or USDM", e)
+		}
+		out = append(out, domain.NewAssetInfo(code, issuer))
+	}
+	return out, nil
+}
+
+// getBridgePrice quotes amount of source in dest through each configured
+// bridge: the first leg sells amount for the bridge, the second sells what
+// the first received for dest. The route delivering the most dest wins.
+// Returns ErrNoPrice when no bridge has both legs.
+func (s *Service) getBridgePrice(ctx context.Context, source, dest domain.AssetInfo, amount string) (domain.TokenPairPrice, error) {
+	srcAmount, err := decimal.NewFromString(amount)
+	if err != nil || !srcAmount.IsPositive() {
+		return domain.TokenPairPrice{}, ErrNoPrice
+	}
+
+	var best domain.TokenPairPrice
+	var bestOut decimal.Decimal
+	for _, bridge := range s.bridges {
+		if bridge.Canonical() == source.Canonical() || bridge.Canonical() == dest.Canonical() {
+			continue
+		}
+		first, err := s.getDirectPathPrice(ctx, source, bridge, amount)
+		if err != nil {
+			if ctx.Err() != nil {
+				return domain.TokenPairPrice{}, ctx.Err()
+			}
+			slog.Debug("bridge leg failed", "source", source.Code, "bridge", bridge.Code, "error", err)
+			continue
+		}
+		mid, err := decimal.NewFromString(first.DestinationAmount)
+		if err != nil || !mid.IsPositive() {
+			continue
+		}
+		second, err := s.getDirectPathPrice(ctx, bridge, dest, mid.String())
+		if err != nil {
+			if ctx.Err() != nil {
+				return domain.TokenPairPrice{}, ctx.Err()
+			}
+			slog.Debug("bridge leg failed", "bridge", bridge.Code, "dest", dest.Code, "error", err)
+			continue
+		}
+		out, err := decimal.NewFromString(second.DestinationAmount)
+		if err != nil || !out.IsPositive() || out.LessThanOrEqual(bestOut) {
+			continue
+		}
+		bestOut = out
+		best = composeBridge(source, dest, bridge, amount, first, second)
+	}
+	if bestOut.IsZero() {
+		return domain.TokenPairPrice{}, ErrNoPrice
+	}
+	return best, nil
+}
+
+// composeBridge joins two path legs into one path price. Its Path lists the
+// intermediate assets of both legs with the bridge between them, in the same
+// form as a single Horizon path.
+func composeBridge(source, dest, bridge domain.AssetInfo, amount string, first, second domain.TokenPairPrice) domain.TokenPairPrice {
+	srcAmount, _ := decimal.NewFromString(amount)
+	out, _ := decimal.NewFromString(second.DestinationAmount)
+
+	chain := []string{hopCode(source)}
+	chain = append(chain, intermediates(first)...)
+	chain = append(chain, hopCode(bridge))
+	chain = append(chain, intermediates(second)...)
+	hops := make([]domain.PathHop, 0, len(chain)-1)
+	for i := 1; i < len(chain); i++ {
+		hops = append(hops, domain.PathHop{From: chain[i-1], To: chain[i]})
+	}
+
+	srcStr, destStr := amount, second.DestinationAmount
+	return domain.TokenPairPrice{
+		TokenA:            source.Canonical(),
+		TokenB:            dest.Canonical(),
+		Price:             out.Div(srcAmount).String(),
+		DestinationAmount: second.DestinationAmount,
+		Timestamp:         time.Now(),
+		Details: &domain.PriceDetails{
+			Source:            "path",
+			SourceAmount:      &srcStr,
+			DestinationAmount: &destStr,
+			Path:              hops,
+			Bridge:            bridge.Canonical(),
+		},
+	}
+}
+
+// intermediates returns the assets a path leg passes through.
+func intermediates(leg domain.TokenPairPrice) []string {
+	if leg.Details == nil {
+		return nil
+	}
+	out := make([]string, len(leg.Details.Path))
+	for i, h := range leg.Details.Path {
+		out[i] = h.To
+	}
+	return out
+}
+
+func hopCode(a domain.AssetInfo) string {
+	if a.IsNative() {
+		return "XLM"
+	}
+	return a.Code
+}
@@ -0,0 +1,120 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+// pairMockHorizon answers strict-send path finding per "SOURCE/DEST" code,
+// scaling the recorded rate by the source amount.
+type pairMockHorizon struct {
+	rates map[string]string
+	paths map[string][]horizon.HorizonPathAsset
+}
+
+func (m *pairMockHorizon) FetchStrictSendPaths(_ context.Context, source domain.AssetInfo, amount string, dest domain.AssetInfo) ([]horizon.HorizonPathRecord, error) {
+	key := source.Code + "/" + dest.Code
+	rate, ok := m.rates[key]
+	if !ok {
+		return nil, errors.New("no path " + key)
+	}
+	out := parseDecimalOrZero(&amount).Mul(parseDecimalOrZero(&rate))
+	return []horizon.HorizonPathRecord{{
+		SourceAssetCode:   source.Code,
+		SourceAmount:      amount,
+		DestinationAmount: out.String(),
+		Path:              m.paths[key],
+	}}, nil
+}
+
+func (m *pairMockHorizon) FetchStrictReceivePaths(context.Context, domain.AssetInfo, domain.AssetInfo, string) ([]horizon.HorizonPathRecord, error) {
+	return nil, errors.New("no receive paths")
+}
+
+func (m *pairMockHorizon) FetchOrderbook(context.Context, domain.AssetInfo, domain.AssetInfo, int) (horizon.HorizonOrderbook, error) {
+	return horizon.HorizonOrderbook{}, errors.New("no orderbook")
+}
+
+func (m *pairMockHorizon) FetchLiquidityPools(context.Context, domain.AssetInfo, domain.AssetInfo) ([]horizon.HorizonLiquidityPool, error) {
+	return nil, errors.New("no pools")
+}
+
+func (m *pairMockHorizon) FetchTrades(context.Context, domain.AssetInfo, domain.AssetInfo, int) ([]horizon.HorizonTrade, error) {
+	return nil, nil
+}
+
+func (m *pairMockHorizon) FetchTradeAggregations(context.Context, domain.AssetInfo, domain.AssetInfo, time.Duration, time.Time, time.Time) ([]horizon.HorizonTradeAggregation, error) {
+	return nil, nil
+}
+
+func TestParseBridges(t *testing.T) {
+	got, err := ParseBridges([]string{"XLM", " usdm ", "EURMTL", "GOLD:GISSUER"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.AssetInfo{domain.XLMAsset(), domain.USDMAsset(), domain.EURMTLAsset(), domain.NewAssetInfo("GOLD", "GISSUER")}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("bridge %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if _, err := ParseBridges([]string{"GOLD"}); err == nil {
+		t.Error("expected an error for an unknown code without issuer")
+	}
+}
+
+func TestGetPriceBridgeRouting(t *testing.T) {
+	mock := &pairMockHorizon{
+		rates: map[string]string{
+			"MTL/XLM":     "10",
+			"XLM/EURMTL":  "0.25", // via XLM: 2.5
+			"MTL/USDM":    "3",
+			"USDM/EURMTL": "0.9", // via USDM: 2.7
+		},
+		paths: map[string][]horizon.HorizonPathAsset{
+			"USDM/EURMTL": {{AssetCode: "EURC"}},
+		},
+	}
+	bridges := []domain.AssetInfo{domain.XLMAsset(), domain.USDMAsset(), domain.EURMTLAsset()}
+
+	// No direct path: the best bridge wins; EURMTL is the destination, not a bridge.
+	svc := NewService(mock, WithBridges(bridges))
+	got, err := svc.GetPrice(t.Context(), testAsset(), domain.EURMTLAsset(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Price != "2.7" || got.Details.Bridge != domain.USDMAsset().Canonical() {
+		t.Errorf("price = %s via %q, want 2.7 via USDM", got.Price, got.Details.Bridge)
+	}
+	wantHops := []domain.PathHop{{From: "MTL", To: "USDM"}, {From: "USDM", To: "EURC"}}
+	if len(got.Details.Path) != len(wantHops) {
+		t.Fatalf("hops = %+v, want %+v", got.Details.Path, wantHops)
+	}
+	for i, h := range wantHops {
+		if got.Details.Path[i] != h {
+			t.Errorf("hop %d = %+v, want %+v", i, got.Details.Path[i], h)
+		}
+	}
+
+	// A better direct path beats the bridges.
+	mock.rates["MTL/EURMTL"] = "3"
+	svc = NewService(mock, WithBridges(bridges))
+	got, err = svc.GetPrice(t.Context(), testAsset(), domain.EURMTLAsset(), "1")
+	if err != nil || got.Price != "3" || got.Details.Bridge != "" {
+		t.Errorf("direct price = %+v, err = %v, want 3 without bridge", got, err)
+	}
+
+	// Without bridges only the direct path is tried.
+	delete(mock.rates, "MTL/EURMTL")
+	if _, err := NewService(mock).GetPrice(t.Context(), testAsset(), domain.EURMTLAsset(), "1"); err == nil {
+		t.Error("expected an error without bridges and a direct path")
+	}
+}
@@ -2,6 +2,7 @@ package price
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -48,11 +49,34 @@ func (c *priceCache) get(key string) (domain.TokenPairPrice, bool) {
 }
 
 func (c *priceCache) set(key string, price domain.TokenPairPrice) {
+	c.setUntil(key, price, time.Now().Add(cacheTTL))
+}
+
+func (c *priceCache) setUntil(key string, price domain.TokenPairPrice, expiresAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.entries[key] = cacheEntry{
 		price:     price,
-		expiresAt: time.Now().Add(cacheTTL),
+		expiresAt: expiresAt,
+	}
+}
+
+// len returns the number of entries, including expired ones not yet evicted.
+func (c *priceCache) len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// dropAsset removes the cached prices of asset in any base asset.
+func (c *priceCache) dropAsset(canonical string) {
+	prefix := canonical + "=>"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
 	}
 }
@@ -2,6 +2,7 @@ package price
 
 import (
 	"context"
+	"time"
 
 	"github.com/mtlprog/stat/internal/domain"
 	"github.com/mtlprog/stat/internal/horizon"
@@ -14,4 +15,5 @@ type HorizonClient interface {
 	FetchStrictReceivePaths(ctx context.Context, source domain.AssetInfo, dest domain.AssetInfo, amount string) ([]horizon.HorizonPathRecord, error)
 	FetchLiquidityPools(ctx context.Context, reserveA, reserveB domain.AssetInfo) ([]horizon.HorizonLiquidityPool, error)
 	FetchTrades(ctx context.Context, base, counter domain.AssetInfo, limit int) ([]horizon.HorizonTrade, error)
+	FetchTradeAggregations(ctx context.Context, base, counter domain.AssetInfo, resolution time.Duration, start, end time.Time) ([]horizon.HorizonTradeAggregation, error)
 }
@@ -12,13 +12,18 @@ import (
 	"github.com/mtlprog/stat/internal/horizon"
 )
 
-// getOrderbookPrice attempts price discovery via direct orderbook + AMM.
-func (s *Service) getOrderbookPrice(ctx context.Context, source, dest domain.AssetInfo) (domain.TokenPairPrice, error) {
-	obData, err := s.fetchOrderbookData(ctx, source, dest)
+// getOrderbookPrice attempts price discovery via direct orderbook + AMM for
+// trading amount units of source.
+func (s *Service) getOrderbookPrice(ctx context.Context, source, dest domain.AssetInfo, amount decimal.Decimal) (domain.TokenPairPrice, error) {
+	obData, err := s.fetchOrderbookData(ctx, source, dest, amount)
 	if err != nil {
 		return domain.TokenPairPrice{}, err
 	}
+	return orderbookPrice(source.Canonical(), dest.Canonical(), obData)
+}
 
+// orderbookPrice is the price of obData's best source, bid preferred over ask.
+func orderbookPrice(tokenA, tokenB string, obData domain.OrderbookData) (domain.TokenPairPrice, error) {
 	// Select best price: bid preferred over ask
 	var priceStr string
 	var priceType string
@@ -47,8 +52,8 @@ func (s *Service) getOrderbookPrice(ctx context.Context, source, dest domain.Ass
 	}
 
 	return domain.TokenPairPrice{
-		TokenA:            source.Canonical(),
-		TokenB:            dest.Canonical(),
+		TokenA:            tokenA,
+		TokenB:            tokenB,
 		Price:             priceStr,
 		DestinationAmount: priceStr,
 		Timestamp:         time.Now(),
@@ -61,7 +66,9 @@ func (s *Service) getOrderbookPrice(ctx context.Context, source, dest domain.Ass
 }
 
 // fetchOrderbookData retrieves orderbook + AMM data and selects the best source.
-func (s *Service) fetchOrderbookData(ctx context.Context, source, dest domain.AssetInfo) (domain.OrderbookData, error) {
+// The AMM side quotes every pool of the pair for trading amount units of
+// source and keeps the best bid and the best ask across them.
+func (s *Service) fetchOrderbookData(ctx context.Context, source, dest domain.AssetInfo, amount decimal.Decimal) (domain.OrderbookData, error) {
 	data := domain.OrderbookData{BestSource: "none"}
 
 	// Fetch traditional orderbook
@@ -77,26 +84,26 @@ func (s *Service) fetchOrderbookData(ctx context.Context, source, dest domain.As
 		slog.Debug("orderbook fetch failed", "source", source.Code, "dest", dest.Code, "error", err)
 	}
 
-	// Fetch AMM liquidity pool
+	// Fetch AMM liquidity pools
 	pools, poolErr := s.horizon.FetchLiquidityPools(ctx, source, dest)
 	if poolErr != nil {
 		slog.Debug("liquidity pool fetch failed", "source", source.Code, "dest", dest.Code, "error", poolErr)
 	}
-	if poolErr == nil && len(pools) > 0 {
-		pool := pools[0]
-		spot := calculateAMMSpot(pool, source)
-		if spot != nil {
-			data.AMM.Ask = spot
-			data.AMM.Bid = spot
-			data.AMMPoolID = &pool.ID
-		}
+	if poolErr == nil {
+		aggregateAMM(&data, pools, source, amount)
 	}
 
 	if err != nil && poolErr != nil {
 		return data, fmt.Errorf("both orderbook and pool fetch failed: ob: %w, pool: %v", err, poolErr)
 	}
+	selectBestSource(&data)
+	return data, nil
+}
 
-	// Select best source: higher bid wins (for fund valuation, selling perspective)
+// selectBestSource sets data.BestSource: the higher bid wins (for fund
+// valuation, selling perspective), then any orderbook price, then the AMM ask.
+func selectBestSource(data *domain.OrderbookData) {
+	data.BestSource = "none"
 	obHasPrice := data.Orderbook.Ask != nil || data.Orderbook.Bid != nil
 	ammHasPrice := data.AMM.Ask != nil
 	obHasBid := data.Orderbook.Bid != nil
@@ -120,23 +127,180 @@ func (s *Service) fetchOrderbookData(ctx context.Context, source, dest domain.As
 	case ammHasPrice:
 		data.BestSource = "amm"
 	}
+}
 
-	return data, nil
+// defaultPoolFeeBP is the fee of Stellar's constant-product pools, used when
+// Horizon doesn't report fee_bp.
+const defaultPoolFeeBP = 30
+
+// aggregateAMM quotes every pool and stores the per-pool quotes in data along
+// with the highest bid and the lowest ask. AMMPoolID is the pool of the best
+// bid, or of the best ask when no pool can be sold into.
+func aggregateAMM(data *domain.OrderbookData, pools []horizon.HorizonLiquidityPool, source domain.AssetInfo, amount decimal.Decimal) {
+	for _, pool := range pools {
+		if q, ok := quotePool(pool, source, amount); ok {
+			data.AMMPools = append(data.AMMPools, q)
+		}
+	}
+	bestAMM(data)
+}
+
+// bestAMM sets data.AMM and AMMPoolID from the quotes in data.AMMPools.
+func bestAMM(data *domain.OrderbookData) {
+	data.AMM, data.AMMPoolID = domain.PriceSource{}, nil
+	var bestBid, bestAsk decimal.Decimal
+	var bidPool, askPool string
+	for _, q := range data.AMMPools {
+		if q.Bid != nil {
+			if bid := parseDecimalOrZero(q.Bid); data.AMM.Bid == nil || bid.GreaterThan(bestBid) {
+				bestBid, bidPool, data.AMM.Bid = bid, q.ID, q.Bid
+			}
+		}
+		if q.Ask != nil {
+			if ask := parseDecimalOrZero(q.Ask); data.AMM.Ask == nil || ask.LessThan(bestAsk) {
+				bestAsk, askPool, data.AMM.Ask = ask, q.ID, q.Ask
+			}
+		}
+	}
+	switch {
+	case bidPool != "":
+		data.AMMPoolID = &bidPool
+	case askPool != "":
+		data.AMMPoolID = &askPool
+	}
+}
+
+// quotePool prices a trade of amount units of source against one pool with
+// the constant-product formula, fee included. With g = 1 − fee and reserves
+// Rs (source) and Rd (dest):
+//
+//	bid = g·Rd / (Rs + g·amount)   dest received per source sold
+//	ask = Rd / (g·(Rs − amount))   dest paid per source bought
+//
+// Ask is nil when the pool holds no more than amount of source. ok is false
+// for pools without two usable reserves.
+func quotePool(pool horizon.HorizonLiquidityPool, source domain.AssetInfo, amount decimal.Decimal) (domain.AMMPoolQuote, bool) {
+	rs, rd, ok := poolReserves(pool, source)
+	if !ok {
+		return domain.AMMPoolQuote{}, false
+	}
+	fee := pool.FeeBP
+	if fee <= 0 {
+		fee = defaultPoolFeeBP
+	}
+	return quoteReserves(pool.ID, fee, rs, rd, amount), true
+}
+
+// quoteReserves is quotePool for known reserves and fee.
+func quoteReserves(id string, fee int, rs, rd, amount decimal.Decimal) domain.AMMPoolQuote {
+	if !amount.IsPositive() {
+		amount = decimal.NewFromInt(1)
+	}
+	g := decimal.NewFromInt(1).Sub(decimal.New(int64(fee), -4))
+
+	q := domain.AMMPoolQuote{
+		ID:            id,
+		FeeBP:         fee,
+		ReserveSource: rs.String(),
+		ReserveDest:   rd.String(),
+		Spot:          rd.Div(rs).String(),
+	}
+	bid := g.Mul(rd).DivRound(rs.Add(g.Mul(amount)), stellarPrecision).String()
+	q.Bid = &bid
+	if rs.GreaterThan(amount) {
+		ask := rd.DivRound(g.Mul(rs.Sub(amount)), stellarPrecision).String()
+		q.Ask = &ask
+	}
+	return q
+}
+
+// atTradeSize re-prices p for selling amount units of its token. The pools
+// recorded in p's orderbook details are quoted again at amount from their
+// reserves, without asking Horizon, and the orderbook and path comparisons
+// are redone, so a holding too large for the pools falls back to the next
+// best source. Prices without recorded pools are returned as they are.
+func atTradeSize(p domain.TokenPairPrice, amount decimal.Decimal) domain.TokenPairPrice {
+	d := p.Details
+	if d == nil {
+		return p
+	}
+	switch d.Source {
+	case "orderbook":
+		if d.OrderbookData == nil || len(d.OrderbookData.AMMPools) == 0 {
+			return p
+		}
+		data := *d.OrderbookData
+		data.AMMPools = make([]domain.AMMPoolQuote, 0, len(d.OrderbookData.AMMPools))
+		for _, q := range d.OrderbookData.AMMPools {
+			rs, rsErr := decimal.NewFromString(q.ReserveSource)
+			rd, rdErr := decimal.NewFromString(q.ReserveDest)
+			if rsErr != nil || rdErr != nil || !rs.IsPositive() || !rd.IsPositive() {
+				return p
+			}
+			data.AMMPools = append(data.AMMPools, quoteReserves(q.ID, q.FeeBP, rs, rd, amount))
+		}
+		bestAMM(&data)
+		selectBestSource(&data)
+		sized, err := orderbookPrice(p.TokenA, p.TokenB, data)
+		if err != nil {
+			return p
+		}
+		sized.Timestamp = p.Timestamp
+		return sized
+	case "best":
+		if d.OBSubDetails == nil || d.PathPrice == nil || d.OrderbookPrice == nil {
+			return p
+		}
+		ob := atTradeSize(domain.TokenPairPrice{
+			TokenA: p.TokenA, TokenB: p.TokenB, Price: *d.OrderbookPrice, DestinationAmount: *d.OrderbookPrice, Details: d.OBSubDetails,
+		}, amount)
+		pathDest := *d.PathPrice
+		if d.ChosenSource == "path" {
+			pathDest = p.DestinationAmount
+		}
+		path := domain.TokenPairPrice{TokenA: p.TokenA, TokenB: p.TokenB, Price: *d.PathPrice, DestinationAmount: pathDest, Details: d.PathSubDetails}
+		sized, err := chooseBest(path, ob)
+		if err != nil {
+			return p
+		}
+		sized.Timestamp = p.Timestamp
+		return sized
+	}
+	return p
+}
+
+// unitPrice returns the one-unit price behind a holding's recorded price,
+// which atTradeSize may have sized for the holding's balance.
+func unitPrice(price string, details *domain.PriceDetails) (string, *domain.PriceDetails) {
+	p := atTradeSize(domain.TokenPairPrice{Price: price, DestinationAmount: price, Details: details}, decimal.NewFromInt(1))
+	return p.Price, p.Details
 }
 
 func calculateAMMSpot(pool horizon.HorizonLiquidityPool, source domain.AssetInfo) *string {
-	if len(pool.Reserves) != 2 {
+	reserveA, reserveB, ok := poolReserves(pool, source)
+	if !ok {
 		return nil
 	}
 
-	var reserveA, reserveB decimal.Decimal
+	// spotPrice = reserveB / reserveA (constant product AMM formula)
+	spot := reserveB.Div(reserveA).String()
+	return &spot
+}
+
+// poolReserves returns the source and dest reserves of a two-asset pool; ok
+// is false when either is missing, zero or unparseable.
+func poolReserves(pool horizon.HorizonLiquidityPool, source domain.AssetInfo) (reserveA, reserveB decimal.Decimal, ok bool) {
+	if len(pool.Reserves) != 2 {
+		return decimal.Zero, decimal.Zero, false
+	}
+
 	sourceCanonical := source.Canonical()
 
 	// Find which reserve matches the source asset
 	for _, r := range pool.Reserves {
 		amount, err := decimal.NewFromString(r.Amount)
 		if err != nil || amount.IsZero() {
-			return nil
+			return decimal.Zero, decimal.Zero, false
 		}
 		// Pool reserve asset format: "CODE:ISSUER" or "native"
 		if r.Asset == sourceCanonical {
@@ -147,12 +311,9 @@ func calculateAMMSpot(pool horizon.HorizonLiquidityPool, source domain.AssetInfo
 	}
 
 	if reserveA.IsZero() || reserveB.IsZero() {
-		return nil
+		return decimal.Zero, decimal.Zero, false
 	}
-
-	// spotPrice = reserveB / reserveA (constant product AMM formula)
-	spot := reserveB.Div(reserveA).String()
-	return &spot
+	return reserveA, reserveB, true
 }
 
 func parseDecimalOrZero(s *string) decimal.Decimal {
@@ -1,7 +1,11 @@
 package price
 
 import (
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	"github.com/mtlprog/stat/internal/domain"
 	"github.com/mtlprog/stat/internal/horizon"
@@ -130,7 +134,7 @@ func TestFetchOrderbookDataBestSourceSelection(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			svc := NewService(tt.mock)
 			source := domain.AssetInfo{Code: "MTL", Issuer: "GISSUER", Type: domain.AssetTypeCreditAlphanum4}
-			data, err := svc.fetchOrderbookData(t.Context(), source, domain.EURMTLAsset())
+			data, err := svc.fetchOrderbookData(t.Context(), source, domain.EURMTLAsset(), decimal.NewFromInt(1))
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -140,3 +144,147 @@ func TestFetchOrderbookDataBestSourceSelection(t *testing.T) {
 		})
 	}
 }
+
+func TestQuotePoolExecutionPrice(t *testing.T) {
+	pool := horizon.HorizonLiquidityPool{
+		ID: "pool",
+		Reserves: []horizon.HorizonLiquidityPoolReserve{
+			{Asset: "MTL:GISSUER", Amount: "1000"},
+			{Asset: domain.EURMTLAsset().Canonical(), Amount: "600"},
+		},
+	}
+	source := domain.AssetInfo{Code: "MTL", Issuer: "GISSUER", Type: domain.AssetTypeCreditAlphanum4}
+
+	q, ok := quotePool(pool, source, decimal.NewFromInt(1))
+	if !ok {
+		t.Fatal("expected a quote")
+	}
+	// Default 30bps fee: bid = 0.997·600/(1000+0.997), ask = 600/(0.997·999).
+	if q.FeeBP != 30 || q.Spot != "0.6" || *q.Bid != "0.5976042" || *q.Ask != "0.6024078" {
+		t.Errorf("quote = %+v (bid %s, ask %s)", q, *q.Bid, *q.Ask)
+	}
+
+	// A larger trade moves the price along the curve.
+	q, _ = quotePool(pool, source, decimal.NewFromInt(100))
+	if *q.Bid != "0.5439665" {
+		t.Errorf("bid for 100 = %s, want 0.5439665", *q.Bid)
+	}
+
+	// The pool can't sell more than it holds.
+	q, _ = quotePool(pool, source, decimal.NewFromInt(1000))
+	if q.Ask != nil {
+		t.Errorf("ask for the whole reserve = %s, want nil", *q.Ask)
+	}
+}
+
+func TestFetchOrderbookDataAggregatesPools(t *testing.T) {
+	mock := &mockHorizon{
+		orderbookErr: errors.New("no orderbook"),
+		pools: []horizon.HorizonLiquidityPool{
+			{
+				ID: "deep",
+				Reserves: []horizon.HorizonLiquidityPoolReserve{
+					{Asset: "MTL:GISSUER", Amount: "1000"},
+					{Asset: domain.EURMTLAsset().Canonical(), Amount: "600"},
+				},
+			},
+			{
+				ID: "rich",
+				Reserves: []horizon.HorizonLiquidityPoolReserve{
+					{Asset: domain.EURMTLAsset().Canonical(), Amount: "320"},
+					{Asset: "MTL:GISSUER", Amount: "500"},
+				},
+			},
+			{ID: "empty"},
+		},
+	}
+	svc := NewService(mock)
+	source := domain.AssetInfo{Code: "MTL", Issuer: "GISSUER", Type: domain.AssetTypeCreditAlphanum4}
+	data, err := svc.fetchOrderbookData(t.Context(), source, domain.EURMTLAsset(), decimal.NewFromInt(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.AMMPools) != 2 {
+		t.Fatalf("got %d pool quotes, want 2", len(data.AMMPools))
+	}
+	if *data.AMM.Bid != "0.6368102" || data.AMMPoolID == nil || *data.AMMPoolID != "rich" {
+		t.Errorf("best bid = %s from %v, want 0.6368102 from rich", *data.AMM.Bid, data.AMMPoolID)
+	}
+	if *data.AMM.Ask != "0.6024078" {
+		t.Errorf("best ask = %s, want 0.6024078 from deep", *data.AMM.Ask)
+	}
+	if data.BestSource != "amm" {
+		t.Errorf("BestSource = %q, want amm", data.BestSource)
+	}
+}
+
+func TestGetTokenPricesBatchSizesAMMPrice(t *testing.T) {
+	mock := &mockHorizon{
+		strictSendPaths: []horizon.HorizonPathRecord{{SourceAmount: "1", DestinationAmount: "0.55"}},
+		orderbookErr:    errors.New("no orderbook"),
+		pools: []horizon.HorizonLiquidityPool{{
+			ID: "deep",
+			Reserves: []horizon.HorizonLiquidityPoolReserve{
+				{Asset: "MTL:GISSUER", Amount: "1000"},
+				{Asset: domain.EURMTLAsset().Canonical(), Amount: "600"},
+			},
+		}},
+	}
+	results := NewService(mock).GetTokenPricesBatch(t.Context(), []AssetBalance{
+		{Asset: testAsset(), Balance: "1"},
+		{Asset: testAsset(), Balance: "10"},
+		{Asset: testAsset(), Balance: "100"},
+	})
+
+	tests := []struct {
+		balance string
+		price   string
+		chosen  string
+	}{
+		{"1", "0.5976042", "orderbook"},  // the pool's one-unit bid beats the path
+		{"10", "0.5922948", "orderbook"}, // selling 10 moves down the curve
+		{"100", "0.55", "path"},          // the pool's bid for 100 falls below the path
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Err != nil {
+			t.Fatalf("balance %s: unexpected error: %v", tt.balance, r.Err)
+		}
+		if r.PriceEURMTL != tt.price || r.DetailsEURMTL.ChosenSource != tt.chosen {
+			t.Errorf("balance %s: price %s from %s, want %s from %s", tt.balance, r.PriceEURMTL, r.DetailsEURMTL.ChosenSource, tt.price, tt.chosen)
+		}
+	}
+	if *results[1].DetailsEURMTL.OBSubDetails.OrderbookData.AMMPools[0].Bid != "0.5922948" {
+		t.Error("sized details don't carry the pool quote for the balance")
+	}
+}
+
+func TestWarmSeedsUnitPriceOfSizedHolding(t *testing.T) {
+	mock := &mockHorizon{
+		orderbookErr: errors.New("no orderbook"),
+		pools: []horizon.HorizonLiquidityPool{{
+			ID: "deep",
+			Reserves: []horizon.HorizonLiquidityPoolReserve{
+				{Asset: "MTL:GISSUER", Amount: "1000"},
+				{Asset: domain.EURMTLAsset().Canonical(), Amount: "600"},
+			},
+		}},
+		strictSendErr:    errors.New("no path"),
+		strictReceiveErr: errors.New("no path"),
+	}
+	svc := NewService(mock)
+	sized := svc.GetTokenPricesBatch(t.Context(), []AssetBalance{{Asset: testAsset(), Balance: "100"}})[0]
+	if sized.Err != nil || sized.PriceEURMTL != "0.5439665" {
+		t.Fatalf("sized = %+v, want the pool's bid for 100", sized)
+	}
+
+	warmed := NewService(&mockHorizon{})
+	data := domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{{
+		Asset: testAsset(), Balance: "100", PriceInEURMTL: &sized.PriceEURMTL, DetailsEURMTL: sized.DetailsEURMTL,
+	}}}}}
+	warmed.Warm(data, time.Now().Add(time.Hour))
+	got, err := warmed.GetPrice(t.Context(), testAsset(), domain.EURMTLAsset(), "1")
+	if err != nil || got.Price != "0.5976042" {
+		t.Errorf("warmed spot = %q (%v), want the one-unit bid 0.5976042", got.Price, err)
+	}
+}
@@ -0,0 +1,71 @@
+package price
+
+import (
+	"context"
+	"time"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/pacing"
+)
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithPacer paces every Horizon call made by the service. Cache hits cost
+// nothing.
+func WithPacer(p *pacing.Pacer) Option {
+	return func(s *Service) {
+		if p != nil {
+			s.horizon = pacedHorizon{next: s.horizon, pacer: p}
+		}
+	}
+}
+
+// pacedHorizon waits for a pacer slot before each call.
+type pacedHorizon struct {
+	next  HorizonClient
+	pacer *pacing.Pacer
+}
+
+func (h pacedHorizon) FetchOrderbook(ctx context.Context, selling, buying domain.AssetInfo, limit int) (horizon.HorizonOrderbook, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return horizon.HorizonOrderbook{}, err
+	}
+	return h.next.FetchOrderbook(ctx, selling, buying, limit)
+}
+
+func (h pacedHorizon) FetchStrictSendPaths(ctx context.Context, source domain.AssetInfo, amount string, dest domain.AssetInfo) ([]horizon.HorizonPathRecord, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchStrictSendPaths(ctx, source, amount, dest)
+}
+
+func (h pacedHorizon) FetchStrictReceivePaths(ctx context.Context, source domain.AssetInfo, dest domain.AssetInfo, amount string) ([]horizon.HorizonPathRecord, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchStrictReceivePaths(ctx, source, dest, amount)
+}
+
+func (h pacedHorizon) FetchLiquidityPools(ctx context.Context, reserveA, reserveB domain.AssetInfo) ([]horizon.HorizonLiquidityPool, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchLiquidityPools(ctx, reserveA, reserveB)
+}
+
+func (h pacedHorizon) FetchTradeAggregations(ctx context.Context, base, counter domain.AssetInfo, resolution time.Duration, start, end time.Time) ([]horizon.HorizonTradeAggregation, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchTradeAggregations(ctx, base, counter, resolution, start, end)
+}
+
+func (h pacedHorizon) FetchTrades(ctx context.Context, base, counter domain.AssetInfo, limit int) ([]horizon.HorizonTrade, error) {
+	if err := h.pacer.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return h.next.FetchTrades(ctx, base, counter, limit)
+}
@@ -11,9 +11,30 @@ import (
 	"github.com/mtlprog/stat/internal/horizon"
 )
 
-// getPathPrice attempts price discovery via path finding.
-// Primary: strictSend. Fallback: strictReceive.
+// getPathPrice attempts price discovery via path finding. With bridges
+// configured (WithBridges), the best bridge route competes with Horizon's
+// own path and the higher price wins.
 func (s *Service) getPathPrice(ctx context.Context, source, dest domain.AssetInfo, amount string) (domain.TokenPairPrice, error) {
+	direct, err := s.getDirectPathPrice(ctx, source, dest, amount)
+	if len(s.bridges) == 0 || ctx.Err() != nil {
+		return direct, err
+	}
+	bridged, bridgeErr := s.getBridgePrice(ctx, source, dest, amount)
+	switch {
+	case bridgeErr != nil:
+		return direct, err
+	case err != nil:
+		return bridged, nil
+	}
+	if parseDecimalOrZero(&bridged.Price).GreaterThan(parseDecimalOrZero(&direct.Price)) {
+		return bridged, nil
+	}
+	return direct, nil
+}
+
+// getDirectPathPrice prices a pair with Horizon's path finding.
+// Primary: strictSend. Fallback: strictReceive.
+func (s *Service) getDirectPathPrice(ctx context.Context, source, dest domain.AssetInfo, amount string) (domain.TokenPairPrice, error) {
 	// Try strictSend first
 	paths, err := s.horizon.FetchStrictSendPaths(ctx, source, amount, dest)
 	if err != nil {
@@ -0,0 +1,111 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// SourceBest is the default spot price policy: path finding and the
+// orderbook are both queried and the higher price wins.
+const SourceBest = "best"
+
+// Policy pins the spot price source of one asset (pricing_policies). Asset
+// is the canonical form, "native" or CODE:ISSUER; Source is SourceBest,
+// SourcePath or SourceOrderbook.
+type Policy struct {
+	Asset  string `json:"asset" example:"MTL:GACKTN5DAZGWXRWB2WLM6OPBDHAMT6SJNGLJZPQMEZBUR4JUGBX2UK7V"`
+	Source string `json:"source" example:"orderbook"`
+}
+
+// policies holds the spot source of each asset with a policy other than
+// SourceBest.
+type policies struct {
+	mu     sync.RWMutex
+	source map[string]string
+}
+
+func (p *policies) get(asset domain.AssetInfo) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if src, ok := p.source[asset.Canonical()]; ok {
+		return src
+	}
+	return SourceBest
+}
+
+// SetPolicies replaces the spot price policies. Assets without one are
+// priced by SourceBest. Cached prices of an asset whose policy changed are
+// dropped, so the next lookup uses the new source. Returns the assets whose
+// policy changed.
+func (s *Service) SetPolicies(list []Policy) []string {
+	next := make(map[string]string, len(list))
+	for _, p := range list {
+		if p.Source != SourceBest {
+			next[p.Asset] = p.Source
+		}
+	}
+
+	s.policies.mu.Lock()
+	prev := s.policies.source
+	s.policies.source = next
+	s.policies.mu.Unlock()
+
+	var changed []string
+	for asset, src := range next {
+		if prev[asset] != src {
+			changed = append(changed, asset)
+		}
+	}
+	for asset := range prev {
+		if _, ok := next[asset]; !ok {
+			changed = append(changed, asset)
+		}
+	}
+	for _, asset := range changed {
+		s.cache.dropAsset(asset)
+	}
+	return changed
+}
+
+// PgPolicyRepository reads the pricing policies from the pricing_policies
+// table.
+type PgPolicyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgPolicyRepository creates a new PostgreSQL pricing policy repository.
+func NewPgPolicyRepository(pool *pgxpool.Pool) *PgPolicyRepository {
+	return &PgPolicyRepository{pool: pool}
+}
+
+// Policies returns the pricing policies of the entity, by asset.
+func (r *PgPolicyRepository) Policies(ctx context.Context, slug string) ([]Policy, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT pp.asset, pp.source
+		 FROM pricing_policies pp
+		 JOIN fund_entities fe ON fe.id = pp.entity_id
+		 WHERE fe.slug = $1
+		 ORDER BY pp.asset`, slug)
+	if err != nil {
+		return nil, fmt.Errorf("listing pricing policies: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Policy
+	for rows.Next() {
+		var p Policy
+		if err := rows.Scan(&p.Asset, &p.Source); err != nil {
+			return nil, fmt.Errorf("scanning pricing policy: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pricing policies: %w", err)
+	}
+	return out, nil
+}
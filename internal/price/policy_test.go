@@ -0,0 +1,44 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+func TestSetPoliciesPinsSpotSource(t *testing.T) {
+	mock := &mockHorizon{
+		strictSendPaths: []horizon.HorizonPathRecord{{SourceAmount: "1", DestinationAmount: "0.5"}},
+		orderbook:       horizon.HorizonOrderbook{Bids: []horizon.HorizonOrderbookEntry{{Price: "0.6", Amount: "100"}}},
+		poolsErr:        errors.New("no pools"),
+	}
+	svc := NewService(mock)
+	ctx := context.Background()
+
+	changed := svc.SetPolicies([]Policy{{Asset: testAsset().Canonical(), Source: SourcePath}})
+	if len(changed) != 1 {
+		t.Fatalf("changed = %v, want the pinned asset", changed)
+	}
+	got, err := svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Price != "0.5" {
+		t.Errorf("pinned to path: Price = %q, want 0.5 although the orderbook pays 0.6", got.Price)
+	}
+
+	if changed := svc.SetPolicies([]Policy{{Asset: testAsset().Canonical(), Source: SourcePath}}); len(changed) != 0 {
+		t.Errorf("unchanged policies reported %v", changed)
+	}
+	svc.SetPolicies(nil)
+	got, err = svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Price != "0.6" || got.Details == nil || got.Details.Source != SourceBest {
+		t.Errorf("after dropping the policy: Price = %q, want the cached path price replaced by the best 0.6", got.Price)
+	}
+}
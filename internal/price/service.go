@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/shopspring/decimal"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
 )
 
@@ -27,23 +29,42 @@ type TokenPriceResult struct {
 
 // Service implements token price discovery.
 type Service struct {
-	horizon HorizonClient
-	cache   *priceCache
+	horizon   HorizonClient
+	cache     *priceCache
+	bound     decimal.Decimal // zero = no sanity bound
+	reference reference
+	bridges   []domain.AssetInfo // empty = Horizon's path only
+	workers   int                // pair lookups in flight per GetTokenPricesBatch
+	policies  policies           // spot source per asset; SourceBest otherwise
 }
 
 // NewService creates a new PriceService.
-func NewService(horizon HorizonClient) *Service {
-	return &Service{
+func NewService(horizon HorizonClient, opts ...Option) *Service {
+	s := &Service{
 		horizon: horizon,
 		cache:   newPriceCache(),
+		workers: defaultBatchConcurrency,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GetPrice determines the price of `asset` in terms of `baseAsset`.
-// For amount="1" (spot price), both path finding and orderbook are queried; the higher price wins.
+// For amount="1" (spot price), both path finding and orderbook are queried and the higher price
+// wins, unless the asset's policy (SetPolicies) pins one source.
 // For amount!="1" (full balance), only path finding is used.
+// A spot price outside the sanity bound (WithSanityBound) is replaced by the
+// reference price.
+// When ctx carries an as-of time (asof.With), the historical daily close is
+// used instead (see getHistoricalPrice).
 func (s *Service) GetPrice(ctx context.Context, asset, baseAsset domain.AssetInfo, amount string) (domain.TokenPairPrice, error) {
 	key := cacheKey(asset, baseAsset, amount)
+	at, historical := asof.From(ctx)
+	if historical {
+		key += "@" + at.Format(time.RFC3339)
+	}
 	if cached, ok := s.cache.get(key); ok {
 		return cached, nil
 	}
@@ -51,8 +72,13 @@ func (s *Service) GetPrice(ctx context.Context, asset, baseAsset domain.AssetInf
 	var result domain.TokenPairPrice
 	var err error
 
-	if amount == "1" {
+	if historical {
+		result, err = s.getHistoricalPrice(ctx, asset, baseAsset, amount, at)
+	} else if amount == "1" {
 		result, err = s.getSpotPrice(ctx, asset, baseAsset)
+		if err == nil {
+			result = s.checkBound(key, result)
+		}
 	} else {
 		result, err = s.getPathPrice(ctx, asset, baseAsset, amount)
 	}
@@ -121,8 +147,16 @@ func (s *Service) GetAverageTradePrice(ctx context.Context, base, counter domain
 // expressed at most to this precision; rounding is half-away-from-zero.
 const stellarPrecision = 7
 
-// getSpotPrice queries both path finding and orderbook, returning the higher price.
+// getSpotPrice queries both path finding and orderbook, returning the higher
+// price, or only the source the asset's policy names.
 func (s *Service) getSpotPrice(ctx context.Context, asset, baseAsset domain.AssetInfo) (domain.TokenPairPrice, error) {
+	switch s.policies.get(asset) {
+	case SourcePath:
+		return s.getPathPrice(ctx, asset, baseAsset, "1")
+	case SourceOrderbook:
+		return s.getOrderbookPrice(ctx, asset, baseAsset, decimal.NewFromInt(1))
+	}
+
 	type priceResult struct {
 		price domain.TokenPairPrice
 		err   error
@@ -137,7 +171,7 @@ func (s *Service) getSpotPrice(ctx context.Context, asset, baseAsset domain.Asse
 	}()
 
 	go func() {
-		p, err := s.getOrderbookPrice(ctx, asset, baseAsset)
+		p, err := s.getOrderbookPrice(ctx, asset, baseAsset, decimal.NewFromInt(1))
 		obCh <- priceResult{p, err}
 	}()
 
@@ -157,53 +191,57 @@ func (s *Service) getSpotPrice(ctx context.Context, asset, baseAsset domain.Asse
 	if !pathOK && obOK {
 		return obResult.price, nil
 	}
+	return chooseBest(pathResult.price, obResult.price)
+}
 
-	// Both succeeded: choose the higher price
-	pathPrice, pathParseErr := decimal.NewFromString(pathResult.price.Price)
-	obPrice, obParseErr := decimal.NewFromString(obResult.price.Price)
+// chooseBest returns the higher of a path and an orderbook price, with both
+// recorded in its "best" details.
+func chooseBest(path, ob domain.TokenPairPrice) (domain.TokenPairPrice, error) {
+	pathPrice, pathParseErr := decimal.NewFromString(path.Price)
+	obPrice, obParseErr := decimal.NewFromString(ob.Price)
 
 	// If one price is unparseable, prefer the other
 	if pathParseErr != nil && obParseErr != nil {
 		return domain.TokenPairPrice{}, fmt.Errorf("both price sources returned unparseable prices")
 	}
 	if pathParseErr != nil {
-		return obResult.price, nil
+		return ob, nil
 	}
 	if obParseErr != nil {
-		return pathResult.price, nil
+		return path, nil
 	}
 
-	pathPriceStr := pathResult.price.Price
-	obPriceStr := obResult.price.Price
+	pathPriceStr := path.Price
+	obPriceStr := ob.Price
 
 	if pathPrice.GreaterThanOrEqual(obPrice) {
 		return domain.TokenPairPrice{
-			TokenA:            pathResult.price.TokenA,
-			TokenB:            pathResult.price.TokenB,
-			Price:             pathResult.price.Price,
-			DestinationAmount: pathResult.price.DestinationAmount,
+			TokenA:            path.TokenA,
+			TokenB:            path.TokenB,
+			Price:             path.Price,
+			DestinationAmount: path.DestinationAmount,
 			Timestamp:         time.Now(),
 			Details: &domain.PriceDetails{
 				Source:         "best",
 				PathPrice:      &pathPriceStr,
 				OrderbookPrice: &obPriceStr,
 				ChosenSource:   "path",
-				PathSubDetails: pathResult.price.Details,
-				OBSubDetails:   obResult.price.Details,
+				PathSubDetails: path.Details,
+				OBSubDetails:   ob.Details,
 			},
 		}, nil
 	}
 
 	var priceType string
-	if obResult.price.Details != nil {
-		priceType = obResult.price.Details.PriceType
+	if ob.Details != nil {
+		priceType = ob.Details.PriceType
 	}
 
 	return domain.TokenPairPrice{
-		TokenA:            obResult.price.TokenA,
-		TokenB:            obResult.price.TokenB,
-		Price:             obResult.price.Price,
-		DestinationAmount: obResult.price.DestinationAmount,
+		TokenA:            ob.TokenA,
+		TokenB:            ob.TokenB,
+		Price:             ob.Price,
+		DestinationAmount: ob.DestinationAmount,
 		Timestamp:         time.Now(),
 		Details: &domain.PriceDetails{
 			Source:         "best",
@@ -211,23 +249,99 @@ func (s *Service) getSpotPrice(ctx context.Context, asset, baseAsset domain.Asse
 			PathPrice:      &pathPriceStr,
 			OrderbookPrice: &obPriceStr,
 			ChosenSource:   "orderbook",
-			PathSubDetails: pathResult.price.Details,
-			OBSubDetails:   obResult.price.Details,
+			PathSubDetails: path.Details,
+			OBSubDetails:   ob.Details,
 		},
 	}, nil
 }
 
+// historyWindow is how far back getHistoricalPrice looks for the last trading
+// day of a pair. 30 daily buckets fit in one trade_aggregations page.
+const historyWindow = 30 * 24 * time.Hour
+
+// getHistoricalPrice prices asset in baseAsset from the close of the last UTC
+// day with trades on or before at's day, within historyWindow. Path finding
+// and the orderbook only show the present, so this is the only source for
+// regenerated snapshots. The pair is looked up in both directions; the
+// inverse close is inverted. amount only scales DestinationAmount.
+func (s *Service) getHistoricalPrice(ctx context.Context, asset, baseAsset domain.AssetInfo, amount string, at time.Time) (domain.TokenPairPrice, error) {
+	end := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour)
+	start := end.Add(-historyWindow)
+
+	price, day, err := s.lastClose(ctx, asset, baseAsset, start, end)
+	if errors.Is(err, ErrNoPrice) {
+		var inverse decimal.Decimal
+		inverse, day, err = s.lastClose(ctx, baseAsset, asset, start, end)
+		if err == nil {
+			price = decimal.NewFromInt(1).DivRound(inverse, stellarPrecision)
+		}
+	}
+	if err != nil {
+		return domain.TokenPairPrice{}, err
+	}
+
+	destAmount := price
+	if amt, err := decimal.NewFromString(amount); err == nil {
+		destAmount = price.Mul(amt).Round(stellarPrecision)
+	}
+	return domain.TokenPairPrice{
+		TokenA:            asset.Canonical(),
+		TokenB:            baseAsset.Canonical(),
+		Price:             price.String(),
+		DestinationAmount: destAmount.String(),
+		Timestamp:         at,
+		Details:           &domain.PriceDetails{Source: "history", ClosedAt: &day},
+	}, nil
+}
+
+// lastClose returns the close of the latest daily bucket for base/counter in
+// [start, end) and that bucket's date. A non-positive close counts as absent.
+func (s *Service) lastClose(ctx context.Context, base, counter domain.AssetInfo, start, end time.Time) (decimal.Decimal, string, error) {
+	buckets, err := s.horizon.FetchTradeAggregations(ctx, base, counter, 24*time.Hour, start, end)
+	if err != nil {
+		return decimal.Zero, "", fmt.Errorf("fetching %s/%s history: %w", base.Code, counter.Code, err)
+	}
+	for i := len(buckets) - 1; i >= 0; i-- {
+		close, err := decimal.NewFromString(buckets[i].Close)
+		if err != nil || !close.IsPositive() {
+			continue
+		}
+		day := buckets[i].Timestamp
+		if ms, err := strconv.ParseInt(buckets[i].Timestamp, 10, 64); err == nil {
+			day = time.UnixMilli(ms).UTC().Format("2006-01-02")
+		}
+		return close, day, nil
+	}
+	return decimal.Zero, "", ErrNoPrice
+}
+
 // GetTokenPrices returns EURMTL and XLM prices/values for a token, including cross-rate derivation.
 func (s *Service) GetTokenPrices(ctx context.Context, asset domain.AssetInfo, balance string) (TokenPriceResult, error) {
-	var result TokenPriceResult
-
 	eurmtlResult, eurmtlErr := s.GetPrice(ctx, asset, domain.EURMTLAsset(), "1")
+	xlmResult, xlmErr := s.GetPrice(ctx, asset, domain.XLMAsset(), "1")
+	return s.combine(ctx, asset, balance, eurmtlResult, eurmtlErr, xlmResult, xlmErr)
+}
+
+// combine builds the TokenPriceResult of balance from the token's EURMTL and
+// XLM spot prices. A balance above one unit sells at its execution price
+// where an AMM pool sets the price (atTradeSize). When only one side is known
+// the other is derived through the EURMTL/XLM cross rate, which GetPrice
+// caches for every token after the first.
+func (s *Service) combine(ctx context.Context, asset domain.AssetInfo, balance string,
+	eurmtlResult domain.TokenPairPrice, eurmtlErr error, xlmResult domain.TokenPairPrice, xlmErr error) (TokenPriceResult, error) {
+	if amount, err := decimal.NewFromString(balance); err == nil && amount.GreaterThan(decimal.NewFromInt(1)) {
+		if eurmtlErr == nil {
+			eurmtlResult = atTradeSize(eurmtlResult, amount)
+		}
+		if xlmErr == nil {
+			xlmResult = atTradeSize(xlmResult, amount)
+		}
+	}
+	var result TokenPriceResult
 	if eurmtlErr == nil {
 		result.PriceEURMTL = eurmtlResult.Price
 		result.DetailsEURMTL = eurmtlResult.Details
 	}
-
-	xlmResult, xlmErr := s.GetPrice(ctx, asset, domain.XLMAsset(), "1")
 	if xlmErr == nil {
 		result.PriceXLM = xlmResult.Price
 		result.DetailsXLM = xlmResult.Details
@@ -265,10 +379,10 @@ func (s *Service) GetTokenPrices(ctx context.Context, asset domain.AssetInfo, ba
 	}
 
 	if result.PriceEURMTL != "" {
-		result.ValueEURMTL = domain.MultiplyWithPrecision(result.PriceEURMTL, balance)
+		result.ValueEURMTL = domain.MultiplyAmount(result.PriceEURMTL, balance)
 	}
 	if result.PriceXLM != "" {
-		result.ValueXLM = domain.MultiplyWithPrecision(result.PriceXLM, balance)
+		result.ValueXLM = domain.MultiplyAmount(result.PriceXLM, balance)
 	}
 
 	if eurmtlErr != nil && xlmErr != nil {
@@ -277,3 +391,55 @@ func (s *Service) GetTokenPrices(ctx context.Context, asset domain.AssetInfo, ba
 
 	return result, nil
 }
+
+// CacheSize returns the number of cached pair prices.
+func (s *Service) CacheSize() int {
+	return s.cache.len()
+}
+
+// Warm seeds the cache with the spot prices recorded in a stored snapshot, so
+// the first run after a restart doesn't re-resolve every pair. Entries expire
+// at until instead of after cacheTTL. Tokens priced by a manual valuation or
+// through a cross rate are skipped: their recorded price is not what GetPrice
+// would return. Prices sized for a holding are seeded as their one-unit
+// price (unitPrice). Returns the number of entries seeded.
+func (s *Service) Warm(data domain.FundStructureData, until time.Time) int {
+	if !time.Now().Before(until) {
+		return 0
+	}
+	eurmtl, xlm := domain.EURMTLAsset(), domain.XLMAsset()
+	seed := func(asset, base domain.AssetInfo, price string, details *domain.PriceDetails) {
+		price, details = unitPrice(price, details)
+		s.cache.setUntil(cacheKey(asset, base, "1"), domain.TokenPairPrice{
+			TokenA:    asset.Canonical(),
+			TokenB:    base.Canonical(),
+			Price:     price,
+			Timestamp: time.Now(),
+			Details:   details,
+		}, until)
+	}
+
+	seeded := make(map[string]bool)
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			if acc.XLMPriceInEURMTL != nil && !seeded[cacheKey(xlm, eurmtl, "1")] {
+				seed(xlm, eurmtl, *acc.XLMPriceInEURMTL, nil)
+				seeded[cacheKey(xlm, eurmtl, "1")] = true
+			}
+			for _, t := range acc.Tokens {
+				if t.Valuation != nil || t.NFTValuationAccount != "" {
+					continue
+				}
+				if t.PriceInEURMTL != nil && t.DetailsEURMTL != nil {
+					seed(t.Asset, eurmtl, *t.PriceInEURMTL, t.DetailsEURMTL)
+					seeded[cacheKey(t.Asset, eurmtl, "1")] = true
+				}
+				if t.PriceInXLM != nil && t.DetailsXLM != nil {
+					seed(t.Asset, xlm, *t.PriceInXLM, t.DetailsXLM)
+					seeded[cacheKey(t.Asset, xlm, "1")] = true
+				}
+			}
+		}
+	}
+	return len(seeded)
+}
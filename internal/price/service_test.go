@@ -3,10 +3,14 @@ package price
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
 	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/pacing"
 )
 
 type mockHorizon struct {
@@ -20,6 +24,7 @@ type mockHorizon struct {
 	poolsErr           error
 	trades             []horizon.HorizonTrade
 	tradesErr          error
+	aggregations       map[string][]horizon.HorizonTradeAggregation // by "BASE/COUNTER" code
 }
 
 func (m *mockHorizon) FetchOrderbook(_ context.Context, _, _ domain.AssetInfo, _ int) (horizon.HorizonOrderbook, error) {
@@ -42,6 +47,10 @@ func (m *mockHorizon) FetchTrades(_ context.Context, _, _ domain.AssetInfo, _ in
 	return m.trades, m.tradesErr
 }
 
+func (m *mockHorizon) FetchTradeAggregations(_ context.Context, base, counter domain.AssetInfo, _ time.Duration, _, _ time.Time) ([]horizon.HorizonTradeAggregation, error) {
+	return m.aggregations[base.Code+"/"+counter.Code], nil
+}
+
 func TestGetPricePathOnly(t *testing.T) {
 	mock := &mockHorizon{
 		strictSendPaths: []horizon.HorizonPathRecord{
@@ -378,6 +387,10 @@ func (m *assetAwareMockHorizon) FetchTrades(_ context.Context, _, _ domain.Asset
 	return nil, nil
 }
 
+func (m *assetAwareMockHorizon) FetchTradeAggregations(_ context.Context, _, _ domain.AssetInfo, _ time.Duration, _, _ time.Time) ([]horizon.HorizonTradeAggregation, error) {
+	return nil, nil
+}
+
 func TestGetTokenPricesCrossRateFromXLM(t *testing.T) {
 	// XLM path succeeds; EURMTL path fails.
 	// Cross-rate EURMTL→XLM also succeeds via same mock.
@@ -408,3 +421,95 @@ func testAsset() domain.AssetInfo {
 		Type:   domain.AssetTypeCreditAlphanum4,
 	}
 }
+
+func TestWithPacerCancelledStopsHorizonCalls(t *testing.T) {
+	mock := &mockHorizon{
+		strictSendPaths: []horizon.HorizonPathRecord{{SourceAmount: "100", DestinationAmount: "50"}},
+	}
+	svc := NewService(mock, WithPacer(pacing.New(1)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "100"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled from the pacer", err)
+	}
+
+	if _, ok := NewService(mock, WithPacer(nil)).horizon.(*mockHorizon); !ok {
+		t.Error("WithPacer(nil) must leave the Horizon client unwrapped")
+	}
+}
+
+func TestWarmSeedsMarketSpotPrices(t *testing.T) {
+	market, valued, crossXLM, xlmRate := "2.5", "100", "30", "0.3"
+	details := &domain.PriceDetails{Source: "path"}
+	data := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{
+			XLMPriceInEURMTL: &xlmRate,
+			Tokens: []domain.TokenPriceWithBalance{
+				{Asset: testAsset(), PriceInEURMTL: &market, DetailsEURMTL: details, PriceInXLM: &crossXLM}, // XLM derived via cross rate
+				{Asset: domain.NewAssetInfo("HOUSE", domain.IssuerAddress), PriceInEURMTL: &valued, DetailsEURMTL: details, NFTValuationAccount: "GVAL"},
+			},
+		}},
+	}
+	// Every Horizon call fails, so anything GetPrice returns came from the cache.
+	mock := &mockHorizon{
+		strictSendErr: errors.New("down"), strictReceiveErr: errors.New("down"),
+		orderbookErr: errors.New("down"), poolsErr: errors.New("down"),
+	}
+	svc := NewService(mock)
+
+	if n := svc.Warm(data, time.Now().Add(-time.Minute)); n != 0 {
+		t.Errorf("expired warm-up seeded %d entries, want 0", n)
+	}
+	if n := svc.Warm(data, time.Now().Add(time.Hour)); n != 2 {
+		t.Errorf("Warm seeded %d entries, want 2 (token EURMTL price, XLM rate)", n)
+	}
+
+	ctx := context.Background()
+	if p, err := svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "1"); err != nil || p.Price != market {
+		t.Errorf("warmed price = %+v, %v; want %s", p, err, market)
+	}
+	if p, err := svc.GetPrice(ctx, domain.XLMAsset(), domain.EURMTLAsset(), "1"); err != nil || p.Price != xlmRate {
+		t.Errorf("warmed XLM rate = %+v, %v; want %s", p, err, xlmRate)
+	}
+	if _, err := svc.GetPrice(ctx, testAsset(), domain.XLMAsset(), "1"); err == nil {
+		t.Error("cross-rate XLM price must not be seeded")
+	}
+	if _, err := svc.GetPrice(ctx, domain.NewAssetInfo("HOUSE", domain.IssuerAddress), domain.EURMTLAsset(), "1"); err == nil {
+		t.Error("manually valued token must not be seeded")
+	}
+}
+
+func TestGetPriceAsOfUsesDailyClose(t *testing.T) {
+	day := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	ms := func(t time.Time) string { return strconv.FormatInt(t.UnixMilli(), 10) }
+	mock := &mockHorizon{
+		strictSendErr: errors.New("live path must not be used"),
+		aggregations: map[string][]horizon.HorizonTradeAggregation{
+			testAsset().Code + "/EURMTL": {
+				{Timestamp: ms(day.AddDate(0, 0, -2)), Close: "1.2"},
+				{Timestamp: ms(day.AddDate(0, 0, -1)), Close: "1.5"},
+			},
+			"EURMTL/XLM": {{Timestamp: ms(day), Close: "4"}}, // only traded the other way round
+		},
+	}
+	svc := NewService(mock)
+	ctx := asof.With(context.Background(), day.Add(23*time.Hour))
+
+	p, err := svc.GetPrice(ctx, testAsset(), domain.EURMTLAsset(), "10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Price != "1.5" || p.DestinationAmount != "15" || p.Details == nil || p.Details.Source != "history" || *p.Details.ClosedAt != "2025-06-09" {
+		t.Errorf("historical price = %+v, want last close 1.5 from 2025-06-09", p)
+	}
+
+	inv, err := svc.GetPrice(ctx, domain.XLMAsset(), domain.EURMTLAsset(), "1")
+	if err != nil || inv.Price != "0.25" {
+		t.Errorf("inverted price = %+v, %v; want 0.25", inv, err)
+	}
+
+	if _, err := svc.GetPrice(context.Background(), testAsset(), domain.EURMTLAsset(), "10"); err == nil {
+		t.Error("live lookup must not reuse the historical cache entry")
+	}
+}
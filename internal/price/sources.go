@@ -0,0 +1,65 @@
+package price
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Market price sources reported by Quotes.
+const (
+	SourcePath      = "path"
+	SourceOrderbook = "orderbook"
+	SourceAMM       = "amm"
+)
+
+// SourceQuote is the price of one unit of a pair from a single source, or
+// the reason that source has none.
+type SourceQuote struct {
+	Source string
+	Price  decimal.Decimal
+	Err    error
+}
+
+// Quotes prices one unit of asset in baseAsset from each market source on
+// its own: path finding (bridges included), the best orderbook bid and the
+// best AMM pool bid, each falling back to its ask when nobody bids. Unlike
+// GetPrice it neither caches nor applies the sanity bound, so sources that
+// disagree stay visible.
+func (s *Service) Quotes(ctx context.Context, asset, baseAsset domain.AssetInfo) []SourceQuote {
+	quotes := make([]SourceQuote, 0, 3)
+
+	q := SourceQuote{Source: SourcePath}
+	if p, err := s.getPathPrice(ctx, asset, baseAsset, "1"); err != nil {
+		q.Err = err
+	} else {
+		q.Price, q.Err = decimal.NewFromString(p.Price)
+	}
+	quotes = append(quotes, q)
+
+	data, err := s.fetchOrderbookData(ctx, asset, baseAsset, decimal.NewFromInt(1))
+	for _, side := range []struct {
+		source string
+		prices domain.PriceSource
+	}{{SourceOrderbook, data.Orderbook}, {SourceAMM, data.AMM}} {
+		q := SourceQuote{Source: side.source, Err: err}
+		if err == nil {
+			q.Price, q.Err = bestOf(side.prices)
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes
+}
+
+// bestOf returns the bid of p, or its ask when there is no bid.
+func bestOf(p domain.PriceSource) (decimal.Decimal, error) {
+	switch {
+	case p.Bid != nil:
+		return decimal.NewFromString(*p.Bid)
+	case p.Ask != nil:
+		return decimal.NewFromString(*p.Ask)
+	}
+	return decimal.Zero, ErrNoPrice
+}
@@ -0,0 +1,48 @@
+package price
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+)
+
+func TestQuotes(t *testing.T) {
+	mock := &mockHorizon{
+		strictSendPaths: []horizon.HorizonPathRecord{{SourceAmount: "1", DestinationAmount: "0.9"}},
+		orderbook:       horizon.HorizonOrderbook{Asks: []horizon.HorizonOrderbookEntry{{Price: "0.8", Amount: "10"}}},
+		pools: []horizon.HorizonLiquidityPool{{
+			ID: "pool",
+			Reserves: []horizon.HorizonLiquidityPoolReserve{
+				{Asset: "MTL:GISSUER", Amount: "1000"},
+				{Asset: domain.EURMTLAsset().Canonical(), Amount: "600"},
+			},
+		}},
+	}
+	svc := NewService(mock)
+	source := domain.AssetInfo{Code: "MTL", Issuer: "GISSUER", Type: domain.AssetTypeCreditAlphanum4}
+
+	quotes := svc.Quotes(t.Context(), source, domain.EURMTLAsset())
+	want := map[string]string{SourcePath: "0.9", SourceOrderbook: "0.8", SourceAMM: "0.5976042"}
+	if len(quotes) != 3 {
+		t.Fatalf("quotes = %+v, want path, orderbook and amm", quotes)
+	}
+	for _, q := range quotes {
+		if q.Err != nil || q.Price.String() != want[q.Source] {
+			t.Errorf("%s = %s (%v), want %s", q.Source, q.Price, q.Err, want[q.Source])
+		}
+	}
+
+	mock.pools = nil
+	mock.strictSendErr = errors.New("down")
+	mock.strictReceiveErr = errors.New("down")
+	for _, q := range svc.Quotes(t.Context(), source, domain.EURMTLAsset()) {
+		if (q.Source == SourceOrderbook) != (q.Err == nil) {
+			t.Errorf("without path and pools: %s err = %v", q.Source, q.Err)
+		}
+	}
+	if q := svc.Quotes(t.Context(), source, domain.EURMTLAsset())[2]; !errors.Is(q.Err, ErrNoPrice) {
+		t.Errorf("amm without pools: err = %v, want ErrNoPrice", q.Err)
+	}
+}
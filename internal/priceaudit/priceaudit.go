@@ -0,0 +1,267 @@
+// Package priceaudit prices every token the fund holds through each price
+// source separately and flags the tokens whose sources disagree. A snapshot
+// keeps only the price that won; the audit shows the ones that lost, so a
+// thin orderbook or a stale DATA entry is caught before month-end figures
+// are published.
+package priceaudit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/price"
+	"github.com/mtlprog/stat/internal/valuation"
+)
+
+// Manual price sources, next to the market sources of price.Quotes.
+const (
+	SourceCost     = "cost"     // a _COST / _1COST DATA entry in EURMTL
+	SourceExternal = "external" // a DATA entry or the XLM price from an external quote
+)
+
+// Sources lists every source in report order.
+var Sources = []string{price.SourcePath, price.SourceOrderbook, price.SourceAMM, SourceCost, SourceExternal}
+
+// Quote is the EURMTL price of one unit of a token from one source.
+type Quote struct {
+	Source string           `json:"source"`
+	Price  *decimal.Decimal `json:"price,omitempty"`
+	Detail string           `json:"detail,omitempty"` // the DATA entry or quote symbol behind a manual price
+	Error  string           `json:"error,omitempty"`
+}
+
+// Row is the audit of one token across the fund's accounts.
+type Row struct {
+	Asset     domain.AssetInfo `json:"asset"`
+	Balance   decimal.Decimal  `json:"balance"`
+	Critical  bool             `json:"critical"`
+	Quotes    []Quote          `json:"quotes"`
+	Spread    *decimal.Decimal `json:"spread"` // nil with fewer than two prices
+	Divergent bool             `json:"divergent"`
+}
+
+// Quote returns the quote of source, if the row has one.
+func (r Row) Quote(source string) (Quote, bool) {
+	for _, q := range r.Quotes {
+		if q.Source == source {
+			return q, true
+		}
+	}
+	return Quote{}, false
+}
+
+// Report is the audit of every held token.
+type Report struct {
+	Threshold decimal.Decimal `json:"threshold"`
+	Rows      []Row           `json:"rows"`
+}
+
+// Divergent returns the rows whose sources disagree by more than the
+// threshold; with critical set, only those of critical assets.
+func (r Report) Divergent(critical bool) []Row {
+	var out []Row
+	for _, row := range r.Rows {
+		if row.Divergent && (row.Critical || !critical) {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// Spread returns the gap between the highest and the lowest positive price
+// of quotes relative to the lowest, or nil with fewer than two such prices.
+func Spread(quotes []Quote) *decimal.Decimal {
+	var lo, hi decimal.Decimal
+	n := 0
+	for _, q := range quotes {
+		if q.Price == nil || !q.Price.IsPositive() {
+			continue
+		}
+		if n == 0 || q.Price.LessThan(lo) {
+			lo = *q.Price
+		}
+		if n == 0 || q.Price.GreaterThan(hi) {
+			hi = *q.Price
+		}
+		n++
+	}
+	if n < 2 {
+		return nil
+	}
+	spread := hi.Sub(lo).Div(lo).Round(4)
+	return &spread
+}
+
+// PortfolioSource fetches account balances (portfolio.Service).
+type PortfolioSource interface {
+	FetchPortfolio(ctx context.Context, accountID string) (domain.AccountPortfolio, error)
+}
+
+// MarketSource prices a pair per market source (price.Service).
+type MarketSource interface {
+	Quotes(ctx context.Context, asset, baseAsset domain.AssetInfo) []price.SourceQuote
+}
+
+// ValuationSource reads the DATA entry valuations (valuation.Service).
+type ValuationSource interface {
+	FetchAllValuations(ctx context.Context) ([]domain.AssetValuation, error)
+}
+
+// ExternalSource resolves valuations and reads external quotes (external.Service).
+type ExternalSource interface {
+	ResolveValuation(ctx context.Context, val domain.AssetValuation) (domain.ResolvedAssetValuation, error)
+	Quote(ctx context.Context, symbol string) (external.Quote, error)
+}
+
+// Service audits the prices of the fund's holdings.
+type Service struct {
+	portfolios PortfolioSource
+	market     MarketSource
+	valuations ValuationSource
+	external   ExternalSource
+}
+
+// NewService creates a Service.
+func NewService(portfolios PortfolioSource, market MarketSource, valuations ValuationSource, ext ExternalSource) *Service {
+	return &Service{portfolios: portfolios, market: market, valuations: valuations, external: ext}
+}
+
+// holding is a token summed over accounts, with the account holding most of
+// it, whose DATA entries take priority as they do when the fund is priced.
+type holding struct {
+	asset        domain.AssetInfo
+	balance      decimal.Decimal
+	owner        string
+	ownerBalance decimal.Decimal
+}
+
+// Audit prices every token held by accounts, XLM included and EURMTL
+// excluded, in EURMTL through each source. Tokens whose code is in critical
+// (any case) are marked critical. A token diverges when its Spread exceeds
+// threshold. Failing to read a balance or the DATA entries fails the audit;
+// a source that can't price a token is reported on its quote.
+func (s *Service) Audit(ctx context.Context, accounts []domain.FundAccount, critical []string, threshold decimal.Decimal) (Report, error) {
+	vals, err := s.valuations.FetchAllValuations(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("fetching valuations: %w", err)
+	}
+	holdings, err := s.holdings(ctx, accounts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Threshold: threshold, Rows: []Row{}}
+	for _, h := range holdings {
+		if err := ctx.Err(); err != nil {
+			return Report{}, err
+		}
+		row := Row{Asset: h.asset, Balance: h.balance}
+		for _, code := range critical {
+			row.Critical = row.Critical || strings.EqualFold(code, h.asset.Code)
+		}
+		for _, q := range s.market.Quotes(ctx, h.asset, domain.EURMTLAsset()) {
+			quote := Quote{Source: q.Source}
+			if q.Err != nil {
+				quote.Error = q.Err.Error()
+			} else {
+				quote.Price = &q.Price
+			}
+			row.Quotes = append(row.Quotes, quote)
+		}
+		if q, ok := s.manual(ctx, h, vals); ok {
+			row.Quotes = append(row.Quotes, q)
+		}
+		row.Spread = Spread(row.Quotes)
+		row.Divergent = row.Spread != nil && row.Spread.GreaterThan(threshold)
+		report.Rows = append(report.Rows, row)
+	}
+	return report, nil
+}
+
+// holdings sums the positive balances of accounts by asset, XLM first and
+// the tokens in the order they are first seen.
+func (s *Service) holdings(ctx context.Context, accounts []domain.FundAccount) ([]*holding, error) {
+	xlm := &holding{asset: domain.XLMAsset()}
+	out := []*holding{xlm}
+	byKey := map[string]*holding{xlm.asset.Canonical(): xlm}
+	for _, acc := range accounts {
+		p, err := s.portfolios.FetchPortfolio(ctx, acc.Address)
+		if err != nil {
+			return nil, fmt.Errorf("fetching balances of %s: %w", acc.Name, err)
+		}
+		balances := append([]domain.TokenBalance{{Asset: xlm.asset, Balance: p.XLMBalance}}, p.Tokens...)
+		for _, tb := range balances {
+			amount, err := decimal.NewFromString(tb.Balance)
+			if err != nil || !amount.IsPositive() || tb.Asset == domain.EURMTLAsset() {
+				continue
+			}
+			h, ok := byKey[tb.Asset.Canonical()]
+			if !ok {
+				h = &holding{asset: tb.Asset}
+				byKey[tb.Asset.Canonical()] = h
+				out = append(out, h)
+			}
+			h.balance = h.balance.Add(amount)
+			if amount.GreaterThan(h.ownerBalance) {
+				h.owner, h.ownerBalance = acc.Address, amount
+			}
+		}
+	}
+	if xlm.balance.IsZero() {
+		out = out[1:]
+	}
+	return out, nil
+}
+
+// manual prices h from the DATA entry the fund would value it with, per
+// unit: a _COST entry values the owner's whole holding. XLM, which has no
+// DATA entries, is priced from its external quote instead.
+func (s *Service) manual(ctx context.Context, h *holding, vals []domain.AssetValuation) (Quote, bool) {
+	if h.asset.IsNative() {
+		q := Quote{Source: SourceExternal, Detail: "XLM quote"}
+		quote, err := s.external.Quote(ctx, "XLM")
+		if err != nil {
+			q.Error = err.Error()
+		} else {
+			q.Price = &quote.PriceInEUR
+		}
+		return q, true
+	}
+
+	val := valuation.LookupValuation(h.asset.Code, h.ownerBalance.String(), h.owner, vals)
+	if val == nil {
+		return Quote{}, false
+	}
+	q := Quote{Source: SourceCost, Detail: h.asset.Code + "_1COST"}
+	if val.ValuationType == domain.ValuationTypeNFT {
+		q.Detail = h.asset.Code + "_COST"
+	}
+	if val.RawValue.Type == domain.ValuationValueExternal {
+		q.Source = SourceExternal
+		q.Detail += " = " + val.RawValue.Symbol
+	}
+	if acc, ok := domain.AccountByAddress(val.SourceAccount); ok {
+		q.Detail += " on " + acc.Name
+	}
+
+	resolved, err := s.external.ResolveValuation(ctx, *val)
+	if err != nil {
+		q.Error = err.Error()
+		return q, true
+	}
+	unit, err := decimal.NewFromString(resolved.ValueInEURMTL)
+	if err != nil {
+		q.Error = fmt.Sprintf("unparseable value %q", resolved.ValueInEURMTL)
+		return q, true
+	}
+	if val.ValuationType == domain.ValuationTypeNFT {
+		unit = unit.Div(h.ownerBalance)
+	}
+	q.Price = &unit
+	return q, true
+}
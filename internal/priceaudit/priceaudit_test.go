@@ -0,0 +1,140 @@
+package priceaudit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/price"
+)
+
+type stubPortfolios map[string]domain.AccountPortfolio
+
+func (s stubPortfolios) FetchPortfolio(_ context.Context, id string) (domain.AccountPortfolio, error) {
+	p, ok := s[id]
+	if !ok {
+		return domain.AccountPortfolio{}, errors.New("not found")
+	}
+	return p, nil
+}
+
+// stubMarket quotes each asset code at fixed per-source prices; a missing
+// source has no price.
+type stubMarket map[string]map[string]string
+
+func (s stubMarket) Quotes(_ context.Context, asset, _ domain.AssetInfo) []price.SourceQuote {
+	var out []price.SourceQuote
+	for _, src := range []string{price.SourcePath, price.SourceOrderbook, price.SourceAMM} {
+		q := price.SourceQuote{Source: src, Err: price.ErrNoPrice}
+		if p, ok := s[asset.Code][src]; ok {
+			q.Price, q.Err = decimal.RequireFromString(p), nil
+		}
+		out = append(out, q)
+	}
+	return out
+}
+
+type stubValuations []domain.AssetValuation
+
+func (s stubValuations) FetchAllValuations(context.Context) ([]domain.AssetValuation, error) {
+	return s, nil
+}
+
+type stubExternal map[string]string // symbol -> EUR price
+
+func (s stubExternal) ResolveValuation(ctx context.Context, val domain.AssetValuation) (domain.ResolvedAssetValuation, error) {
+	r := domain.ResolvedAssetValuation{AssetValuation: val, ValueInEURMTL: val.RawValue.Value}
+	if val.RawValue.Type == domain.ValuationValueExternal {
+		q, err := s.Quote(ctx, val.RawValue.Symbol)
+		if err != nil {
+			return domain.ResolvedAssetValuation{}, err
+		}
+		r.ValueInEURMTL = q.PriceInEUR.String()
+	}
+	return r, nil
+}
+
+func (s stubExternal) Quote(_ context.Context, symbol string) (external.Quote, error) {
+	p, ok := s[symbol]
+	if !ok {
+		return external.Quote{}, errors.New("no quote")
+	}
+	return external.Quote{Symbol: symbol, PriceInEUR: decimal.RequireFromString(p)}, nil
+}
+
+func TestAudit(t *testing.T) {
+	accounts := domain.AggregatedAccounts()[:2]
+	mtl := domain.NewAssetInfo("MTL", domain.IssuerAddress)
+	btc := domain.NewAssetInfo("MTLBTC", domain.IssuerAddress)
+	house := domain.NewAssetInfo("HOUSE", domain.IssuerAddress)
+	portfolios := stubPortfolios{
+		accounts[0].Address: {XLMBalance: "100", Tokens: []domain.TokenBalance{
+			{Asset: mtl, Balance: "10"}, {Asset: domain.EURMTLAsset(), Balance: "500"}, {Asset: btc, Balance: "0"},
+		}},
+		accounts[1].Address: {XLMBalance: "50", Tokens: []domain.TokenBalance{
+			{Asset: mtl, Balance: "5"}, {Asset: house, Balance: "2"},
+		}},
+	}
+	market := stubMarket{
+		"XLM": {price.SourcePath: "0.30", price.SourceOrderbook: "0.31"},
+		"MTL": {price.SourcePath: "4", price.SourceOrderbook: "4.1", price.SourceAMM: "2"},
+	}
+	vals := stubValuations{{
+		TokenCode: "HOUSE", ValuationType: domain.ValuationTypeNFT, SourceAccount: accounts[1].Address,
+		RawValue: domain.ValuationValue{Type: domain.ValuationValueEURMTL, Value: "1000"},
+	}}
+	svc := NewService(portfolios, market, vals, stubExternal{"XLM": "0.29"})
+
+	report, err := svc.Audit(context.Background(), accounts, []string{"mtl"}, decimal.RequireFromString("0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Rows) != 3 {
+		t.Fatalf("rows = %+v, want XLM, MTL and HOUSE (no EURMTL, no zero balances)", report.Rows)
+	}
+	xlm, mtlRow, houseRow := report.Rows[0], report.Rows[1], report.Rows[2]
+
+	if !xlm.Balance.Equal(decimal.NewFromInt(150)) || xlm.Divergent || xlm.Spread == nil || xlm.Spread.String() != "0.069" {
+		t.Errorf("XLM = %+v (spread %v), want 150 held and 0.31/0.29 within the threshold", xlm, xlm.Spread)
+	}
+	if q, ok := xlm.Quote(SourceExternal); !ok || q.Price == nil || q.Price.String() != "0.29" {
+		t.Errorf("XLM external quote = %+v", q)
+	}
+
+	if !mtlRow.Critical || !mtlRow.Divergent || mtlRow.Spread.String() != "1.05" || !mtlRow.Balance.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("MTL = %+v (spread %v), want a critical divergence of 1.05", mtlRow, mtlRow.Spread)
+	}
+	if q, _ := mtlRow.Quote(price.SourceAMM); q.Price == nil {
+		t.Errorf("MTL amm quote = %+v, want priced", q)
+	}
+
+	q, ok := houseRow.Quote(SourceCost)
+	if !ok || q.Price == nil || q.Price.String() != "500" || q.Detail != "HOUSE_COST on "+accounts[1].Name {
+		t.Errorf("HOUSE cost quote = %+v, want 1000 over the 2 units held", q)
+	}
+	if houseRow.Spread != nil || houseRow.Divergent {
+		t.Errorf("HOUSE with one price = %+v, want no spread", houseRow)
+	}
+
+	if got := report.Divergent(true); len(got) != 1 || got[0].Asset != mtl {
+		t.Errorf("critical divergent = %+v, want MTL", got)
+	}
+
+	if _, err := NewService(stubPortfolios{}, market, vals, stubExternal{}).Audit(context.Background(), accounts, nil, decimal.Zero); err == nil {
+		t.Error("unreadable account: want an error")
+	}
+}
+
+func TestSpread(t *testing.T) {
+	p := func(s string) *decimal.Decimal { d := decimal.RequireFromString(s); return &d }
+	if s := Spread([]Quote{{Price: p("2")}, {Price: p("3")}, {Error: "none"}, {Price: p("0")}}); s == nil || s.String() != "0.5" {
+		t.Errorf("Spread = %v, want 0.5", s)
+	}
+	if s := Spread([]Quote{{Price: p("2")}, {Error: "none"}}); s != nil {
+		t.Errorf("Spread of one price = %v, want nil", s)
+	}
+}
@@ -0,0 +1,72 @@
+// Package progress carries snapshot-generation progress events from deep inside
+// the pipeline (fund accounts, token pricing, metrics enrichment) up to whoever
+// started the run — the `stat report` logger or an API generation job.
+//
+// The reporter travels on the context so none of the service interfaces need a
+// new parameter; code that runs without a reporter still logs via slog.
+package progress
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Pipeline stages, in execution order.
+const (
+	StageValuations = "valuations"
+	StageAccounts   = "accounts"
+	StageMetrics    = "metrics"
+	StagePersist    = "persist"
+	StageIndicators = "indicators"
+	StageDone       = "done"
+)
+
+// Event is a single progress update. Account/Token counters are 1-based and
+// zero when not applicable to the stage.
+type Event struct {
+	Stage        string `json:"stage"`
+	Account      string `json:"account,omitempty"`
+	AccountIndex int    `json:"accountIndex,omitempty"`
+	AccountTotal int    `json:"accountTotal,omitempty"`
+	Token        string `json:"token,omitempty"`
+	TokenIndex   int    `json:"tokenIndex,omitempty"`
+	TokenTotal   int    `json:"tokenTotal,omitempty"`
+}
+
+// Reporter receives progress events.
+type Reporter interface {
+	Report(Event)
+}
+
+type ctxKey struct{}
+
+// WithReporter returns a context that delivers progress events to r.
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, ctxKey{}, r)
+}
+
+// Report logs ev and forwards it to the context's Reporter, if any. Stage and
+// account transitions log at Info; per-token events are Debug because a full
+// run prices a few hundred tokens.
+func Report(ctx context.Context, ev Event) {
+	args := []any{"stage", ev.Stage}
+	if ev.Account != "" {
+		args = append(args, "account", ev.Account, "account_n", ev.AccountIndex, "account_total", ev.AccountTotal)
+	}
+	if ev.Token != "" {
+		args = append(args, "token", ev.Token, "token_n", ev.TokenIndex, "token_total", ev.TokenTotal)
+		slog.Debug("snapshot progress", args...)
+	} else {
+		slog.Info("snapshot progress", args...)
+	}
+
+	if r, ok := ctx.Value(ctxKey{}).(Reporter); ok {
+		r.Report(ev)
+	}
+}
+
+// IsCancelled reports whether err stems from context cancellation or deadline.
+func IsCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,52 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Report(ev Event) {
+	r.events = append(r.events, ev)
+}
+
+func TestReportForwardsToReporter(t *testing.T) {
+	rec := &recorder{}
+	ctx := WithReporter(context.Background(), rec)
+
+	Report(ctx, Event{Stage: StageAccounts, Account: "MAIN ISSUER", AccountIndex: 1, AccountTotal: 10})
+
+	if len(rec.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(rec.events))
+	}
+	if ev := rec.events[0]; ev.Account != "MAIN ISSUER" || ev.AccountIndex != 1 {
+		t.Errorf("event = %+v, want MAIN ISSUER 1/10", ev)
+	}
+}
+
+func TestReportWithoutReporter(t *testing.T) {
+	// Must not panic when no reporter is attached.
+	Report(context.Background(), Event{Stage: StageMetrics})
+}
+
+func TestIsCancelled(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("horizon down"), false},
+		{fmt.Errorf("snapshot not saved: %w", context.Canceled), true},
+		{context.DeadlineExceeded, true},
+	}
+	for _, tt := range tests {
+		if got := IsCancelled(tt.err); got != tt.want {
+			t.Errorf("IsCancelled(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
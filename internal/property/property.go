@@ -0,0 +1,128 @@
+// Package property is the registry of real-estate tokens (MCITY, MFApart):
+// each token's property, its area and its last appraisal. A registered token
+// is valued at its area times a price per square metre taken from the
+// external quote system, or at its appraisal when no price symbol is set,
+// instead of a flat _COST DATA entry.
+package property
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Property is the registry entry of one real-estate token.
+type Property struct {
+	Token          string           `json:"token"`
+	Name           string           `json:"name"`
+	Location       string           `json:"location"`
+	AreaM2         decimal.Decimal  `json:"areaM2"`
+	PriceSymbol    string           `json:"priceSymbol"`              // external quote in EUR per m²; empty values at AppraisalValue
+	AppraisalValue *decimal.Decimal `json:"appraisalValue,omitempty"` // last appraisal of the whole property, in EUR
+	AppraisedOn    *time.Time       `json:"appraisedOn,omitempty" swaggertype:"string" example:"2026-03-01"`
+}
+
+// Valuation returns the manual valuation of the token: area × PriceSymbol,
+// resolved by external.Service.ResolveValuation like "AU 1g", or the
+// appraisal value. It prices the whole holding, as a _COST entry does.
+func (p Property) Valuation() domain.AssetValuation {
+	v := domain.AssetValuation{TokenCode: p.Token, ValuationType: domain.ValuationTypeNFT}
+	if p.PriceSymbol != "" {
+		area := p.AreaM2.InexactFloat64()
+		v.RawValue = domain.ValuationValue{Type: domain.ValuationValueExternal, Symbol: p.PriceSymbol, Quantity: &area, Unit: "m2"}
+		return v
+	}
+	v.RawValue = domain.ValuationValue{Type: domain.ValuationValueEURMTL}
+	if p.AppraisalValue != nil {
+		v.RawValue.Value = p.AppraisalValue.String()
+	}
+	return v
+}
+
+// Store lists the registered properties of an entity (PgRepository).
+type Store interface {
+	List(ctx context.Context, slug string) ([]Property, error)
+}
+
+// Service serves the registry to valuation and checks appraisal age at
+// snapshot time.
+type Service struct {
+	store  Store
+	slug   string
+	maxAge time.Duration
+}
+
+// NewService creates a property Service. An appraisal older than maxAge on
+// the snapshot date draws a warning; 0 turns the check off.
+func NewService(store Store, slug string, maxAge time.Duration) *Service {
+	return &Service{store: store, slug: slug, maxAge: maxAge}
+}
+
+// Valuations returns the valuation of every registered property
+// (valuation.WithProperties).
+func (s *Service) Valuations(ctx context.Context) ([]domain.AssetValuation, error) {
+	props, err := s.store.List(ctx, s.slug)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.AssetValuation, 0, len(props))
+	for _, p := range props {
+		out = append(out, p.Valuation())
+	}
+	return out, nil
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher. It appends a warning for
+// each registered token held by the fund whose appraisal is missing or older
+// than maxAge on date.
+func (s *Service) EnrichMetrics(ctx context.Context, date time.Time, data *domain.FundStructureData) error {
+	if s.maxAge <= 0 {
+		return nil
+	}
+	props, err := s.store.List(ctx, s.slug)
+	if err != nil {
+		return fmt.Errorf("listing properties: %w", err)
+	}
+	held := heldTokens(data)
+	for _, p := range props {
+		if !held[p.Token] {
+			continue
+		}
+		if w, ok := staleAppraisal(p, date, s.maxAge); ok {
+			slog.Error(w)
+			data.Warnings = append(data.Warnings, w)
+		}
+	}
+	return nil
+}
+
+// staleAppraisal describes a missing appraisal or one older than maxAge.
+func staleAppraisal(p Property, date time.Time, maxAge time.Duration) (string, bool) {
+	if p.AppraisedOn == nil {
+		return fmt.Sprintf("stale appraisal for %s: no appraisal date recorded", p.Token), true
+	}
+	age := date.Sub(*p.AppraisedOn)
+	if age <= maxAge {
+		return "", false
+	}
+	return fmt.Sprintf("stale appraisal for %s: appraised %s, %d days before the snapshot",
+		p.Token, p.AppraisedOn.Format("2006-01-02"), int(age.Hours()/24)), true
+}
+
+// heldTokens returns the codes of the tokens in every fund portfolio.
+func heldTokens(data *domain.FundStructureData) map[string]bool {
+	held := make(map[string]bool)
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			for _, t := range acc.Tokens {
+				held[t.Asset.Code] = true
+			}
+		}
+	}
+	return held
+}
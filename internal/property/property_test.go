@@ -0,0 +1,63 @@
+package property
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+type stubStore []Property
+
+func (s stubStore) List(context.Context, string) ([]Property, error) { return s, nil }
+
+func TestValuation(t *testing.T) {
+	byArea := Property{Token: "MCITY136", AreaM2: decimal.RequireFromString("54.3"), PriceSymbol: "M2_BUDVA",
+		AppraisalValue: lo.ToPtr(decimal.NewFromInt(160000))}
+	v := byArea.Valuation()
+	if v.ValuationType != domain.ValuationTypeNFT || v.RawValue.Type != domain.ValuationValueExternal ||
+		v.RawValue.Symbol != "M2_BUDVA" || v.RawValue.Quantity == nil || *v.RawValue.Quantity != 54.3 {
+		t.Errorf("area valuation = %+v", v)
+	}
+
+	appraised := Property{Token: "MFAPART1", AreaM2: decimal.NewFromInt(80), AppraisalValue: lo.ToPtr(decimal.NewFromInt(160000))}
+	if v := appraised.Valuation(); v.RawValue.Type != domain.ValuationValueEURMTL || v.RawValue.Value != "160000" {
+		t.Errorf("appraisal valuation = %+v", v)
+	}
+}
+
+func TestEnrichMetricsStaleAppraisals(t *testing.T) {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	store := stubStore{
+		{Token: "FRESH", AppraisedOn: lo.ToPtr(date.AddDate(0, -6, 0))},
+		{Token: "OLD", AppraisedOn: lo.ToPtr(date.AddDate(-2, 0, 0))},
+		{Token: "NEVER"},
+		{Token: "SOLD", AppraisedOn: lo.ToPtr(date.AddDate(-5, 0, 0))},
+	}
+	data := domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{
+		{Asset: domain.AssetInfo{Code: "FRESH"}}, {Asset: domain.AssetInfo{Code: "OLD"}}, {Asset: domain.AssetInfo{Code: "NEVER"}},
+	}}}}
+
+	if err := NewService(store, "mtlf", 365*24*time.Hour).EnrichMetrics(context.Background(), date, &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Warnings) != 2 {
+		t.Fatalf("warnings = %v, want OLD and NEVER", data.Warnings)
+	}
+	if !strings.Contains(data.Warnings[0], "OLD: appraised 2024-10-01, 730 days") {
+		t.Errorf("warning = %q", data.Warnings[0])
+	}
+	if !strings.Contains(data.Warnings[1], "NEVER: no appraisal date") {
+		t.Errorf("warning = %q", data.Warnings[1])
+	}
+
+	data.Warnings = nil
+	if err := NewService(store, "mtlf", 0).EnrichMetrics(context.Background(), date, &data); err != nil || data.Warnings != nil {
+		t.Errorf("max age 0: warnings = %v, err = %v, want the check off", data.Warnings, err)
+	}
+}
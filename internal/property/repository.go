@@ -0,0 +1,45 @@
+package property
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository reads the registry from the properties table.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL property repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// List returns the registered properties of the entity, by token.
+func (r *PgRepository) List(ctx context.Context, slug string) ([]Property, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT p.token, p.name, p.location, p.area_m2, p.price_symbol, p.appraisal_value, p.appraised_on::timestamptz
+		 FROM properties p
+		 JOIN fund_entities fe ON fe.id = p.entity_id
+		 WHERE fe.slug = $1
+		 ORDER BY p.token`, slug)
+	if err != nil {
+		return nil, fmt.Errorf("listing properties: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Property
+	for rows.Next() {
+		var p Property
+		if err := rows.Scan(&p.Token, &p.Name, &p.Location, &p.AreaM2, &p.PriceSymbol, &p.AppraisalValue, &p.AppraisedOn); err != nil {
+			return nil, fmt.Errorf("scanning property: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating properties: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,178 @@
+// Package publish mirrors daily snapshots and indicators to public storage
+// (a local directory, an S3-compatible bucket or an IPFS node) so the fund
+// statistics can be read and verified without the API or Google Sheets.
+//
+// Layout under the target root:
+//
+//	index.json                  rolling list of the latest published days
+//	snapshots/YYYY-MM-DD.json   canonical snapshot JSON (sha256 == seal hash)
+//	indicators/YYYY-MM-DD.csv   id,name,value,unit
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// DefaultIndexDays is how many days index.json lists when not configured.
+const DefaultIndexDays = 365
+
+// Target stores published files. name is a slash-separated path relative to
+// the target root, e.g. "snapshots/2026-10-17.json".
+type Target interface {
+	Put(ctx context.Context, name string, body []byte, contentType string) error
+}
+
+// Rooter is implemented by content-addressed targets that can report the
+// address of the whole published tree after a run (the IPFS root CID).
+type Rooter interface {
+	Root(ctx context.Context) (string, error)
+}
+
+// Index is the rolling index.json written after every publish.
+type Index struct {
+	Entity    string       `json:"entity"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Latest    string       `json:"latest,omitempty"`
+	Days      []IndexEntry `json:"days"` // newest first
+}
+
+// IndexEntry describes one published day. Hash is the SHA-256 of the
+// snapshot file; Signature/Signer are present when snapshots are signed.
+type IndexEntry struct {
+	Date       string `json:"date"`
+	Snapshot   string `json:"snapshot"`
+	Indicators string `json:"indicators"`
+	snapshot.Seal
+}
+
+// Service publishes stored snapshots and indicators to a Target.
+type Service struct {
+	snapshots  snapshot.Repository
+	indicators indicator.Repository
+	target     Target
+	indexDays  int
+}
+
+// NewService creates a publish Service. indexDays <= 0 uses DefaultIndexDays.
+func NewService(snapshots snapshot.Repository, indicators indicator.Repository, target Target, indexDays int) *Service {
+	if indexDays <= 0 {
+		indexDays = DefaultIndexDays
+	}
+	return &Service{snapshots: snapshots, indicators: indicators, target: target, indexDays: indexDays}
+}
+
+// Publish writes the files for date and refreshes index.json.
+func (s *Service) Publish(ctx context.Context, slug string, date time.Time) error {
+	if err := s.PublishDay(ctx, slug, date); err != nil {
+		return err
+	}
+	return s.WriteIndex(ctx, slug)
+}
+
+// PublishDay writes the snapshot and indicator files for date without
+// touching index.json, so a range can be published with one index write.
+func (s *Service) PublishDay(ctx context.Context, slug string, date time.Time) error {
+	snap, err := s.snapshots.GetByDate(ctx, slug, date)
+	if err != nil {
+		return fmt.Errorf("loading snapshot: %w", err)
+	}
+	body, err := snapshot.Canonicalize(snap.Data)
+	if err != nil {
+		return err
+	}
+	day := date.Format("2006-01-02")
+	if err := s.target.Put(ctx, snapshotPath(day), body, "application/json"); err != nil {
+		return fmt.Errorf("publishing snapshot %s: %w", day, err)
+	}
+
+	inds, err := s.indicators.GetByDate(ctx, slug, date)
+	if err != nil {
+		return fmt.Errorf("loading indicators: %w", err)
+	}
+	csvBody, err := indicatorsCSV(inds)
+	if err != nil {
+		return err
+	}
+	if err := s.target.Put(ctx, indicatorsPath(day), csvBody, "text/csv; charset=utf-8"); err != nil {
+		return fmt.Errorf("publishing indicators %s: %w", day, err)
+	}
+	return nil
+}
+
+// WriteIndex rewrites index.json from the latest stored snapshots and, for
+// content-addressed targets, logs the new root address.
+func (s *Service) WriteIndex(ctx context.Context, slug string) error {
+	page, err := s.snapshots.ListPage(ctx, slug, snapshot.ListQuery{Limit: s.indexDays, OmitData: true})
+	if err != nil {
+		return fmt.Errorf("listing snapshots for index: %w", err)
+	}
+	idx := Index{Entity: slug, UpdatedAt: time.Now().UTC(), Days: make([]IndexEntry, 0, len(page.Snapshots))}
+	for _, snap := range page.Snapshots {
+		day := snap.SnapshotDate.Format("2006-01-02")
+		idx.Days = append(idx.Days, IndexEntry{
+			Date:       day,
+			Snapshot:   snapshotPath(day),
+			Indicators: indicatorsPath(day),
+			Seal:       snap.Seal,
+		})
+	}
+	if len(idx.Days) > 0 {
+		idx.Latest = idx.Days[0].Date
+	}
+
+	body, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	if err := s.target.Put(ctx, "index.json", body, "application/json"); err != nil {
+		return fmt.Errorf("publishing index: %w", err)
+	}
+
+	if r, ok := s.target.(Rooter); ok {
+		root, err := r.Root(ctx)
+		if err != nil {
+			return fmt.Errorf("resolving published root: %w", err)
+		}
+		slog.Info("published dataset", "root", root, "days", len(idx.Days))
+	}
+	return nil
+}
+
+func snapshotPath(day string) string   { return "snapshots/" + day + ".json" }
+func indicatorsPath(day string) string { return "indicators/" + day + ".csv" }
+
+func indicatorsCSV(inds []indicator.Indicator) ([]byte, error) {
+	inds = slices.Clone(inds)
+	slices.SortFunc(inds, func(a, b indicator.Indicator) int { return a.ID - b.ID })
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"id", "name", "value", "unit"})
+	for _, ind := range inds {
+		_ = w.Write([]string{strconv.Itoa(ind.ID), ind.Name, ind.Value.String(), ind.Unit})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("encoding indicators CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// errStatus formats a non-2xx response from a storage backend.
+func errStatus(op, status string, preview []byte) error {
+	if len(preview) == 0 {
+		return fmt.Errorf("%s returned %s", op, status)
+	}
+	return fmt.Errorf("%s returned %s: %s", op, status, preview)
+}
@@ -0,0 +1,202 @@
+package publish
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Only the methods the publisher calls are implemented; the embedded
+// interfaces panic if anything else is reached.
+type fakeSnapshots struct {
+	snapshot.Repository
+	byDate map[string]snapshot.Snapshot
+}
+
+func (f *fakeSnapshots) GetByDate(_ context.Context, _ string, date time.Time) (*snapshot.Snapshot, error) {
+	s, ok := f.byDate[date.Format("2006-01-02")]
+	if !ok {
+		return nil, snapshot.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (f *fakeSnapshots) ListPage(_ context.Context, _ string, q snapshot.ListQuery) (*snapshot.Page, error) {
+	var page snapshot.Page
+	for _, day := range []string{"2026-10-17", "2026-10-16"} {
+		if s, ok := f.byDate[day]; ok && len(page.Snapshots) < q.Limit {
+			s.Data = nil
+			page.Snapshots = append(page.Snapshots, s)
+		}
+	}
+	return &page, nil
+}
+
+type fakeIndicators struct {
+	indicator.Repository
+}
+
+func (fakeIndicators) GetByDate(context.Context, string, time.Time) ([]indicator.Indicator, error) {
+	return []indicator.Indicator{
+		{ID: 2, Name: "Market Cap, EUR", Value: decimal.RequireFromString("1.5"), Unit: "EURMTL"},
+		{ID: 1, Name: "Total, EUR", Value: decimal.NewFromInt(10), Unit: "EURMTL"},
+	}, nil
+}
+
+func TestPublish(t *testing.T) {
+	data := []byte(`{"b": 1, "a": "x<y"}`)
+	hash, err := snapshot.Hash(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps := &fakeSnapshots{byDate: map[string]snapshot.Snapshot{
+		"2026-10-16": {SnapshotDate: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Data: data, Seal: snapshot.Seal{Hash: "old"}},
+		"2026-10-17": {SnapshotDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), Data: data, Seal: snapshot.Seal{Hash: hash}},
+	}}
+	dir := t.TempDir()
+	svc := NewService(snaps, fakeIndicators{}, NewDirTarget(dir), 0)
+
+	if err := svc.Publish(context.Background(), "mtlf", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := os.ReadFile(filepath.Join(dir, "snapshots", "2026-10-17.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) != hash {
+		t.Errorf("published snapshot %s does not hash to the seal", body)
+	}
+
+	csvBody, err := os.ReadFile(filepath.Join(dir, "indicators", "2026-10-17.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,value,unit\n1,\"Total, EUR\",10,EURMTL\n2,\"Market Cap, EUR\",1.5,EURMTL\n"
+	if string(csvBody) != want {
+		t.Errorf("indicators CSV = %q, want %q", csvBody, want)
+	}
+
+	var idx Index
+	raw, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		t.Fatal(err)
+	}
+	if idx.Latest != "2026-10-17" || len(idx.Days) != 2 || idx.Days[0].Hash != hash || idx.Days[1].Snapshot != "snapshots/2026-10-16.json" {
+		t.Errorf("index = %+v", idx)
+	}
+}
+
+func TestIndexDaysLimit(t *testing.T) {
+	snaps := &fakeSnapshots{byDate: map[string]snapshot.Snapshot{
+		"2026-10-16": {SnapshotDate: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		"2026-10-17": {SnapshotDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+	}}
+	dir := t.TempDir()
+	if err := NewService(snaps, fakeIndicators{}, NewDirTarget(dir), 1).WriteIndex(context.Background(), "mtlf"); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(dir, "index.json"))
+	var idx Index
+	_ = json.Unmarshal(raw, &idx)
+	if len(idx.Days) != 1 || idx.Latest != "2026-10-17" {
+		t.Errorf("index = %+v, want only 2026-10-17", idx)
+	}
+}
+
+func TestS3TargetPut(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	target := NewS3Target(S3Config{Endpoint: srv.URL + "/", Bucket: "fund", Prefix: "stat", AccessKey: "AKID", SecretKey: "secret"})
+	target.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	if err := target.Put(context.Background(), "index.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != http.MethodPut || got.URL.Path != "/fund/stat/index.json" || string(gotBody) != "{}" {
+		t.Errorf("request = %s %s %q", got.Method, got.URL.Path, gotBody)
+	}
+	auth := got.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261017/us-east-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Header.Get("X-Amz-Content-Sha256") != sha256Hex([]byte("{}")) {
+		t.Errorf("X-Amz-Content-Sha256 = %q", got.Header.Get("X-Amz-Content-Sha256"))
+	}
+}
+
+func TestS3TargetError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Code>AccessDenied</Code>", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := NewS3Target(S3Config{Endpoint: srv.URL, Bucket: "fund"}).Put(context.Background(), "index.json", nil, "application/json")
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("err = %v, want AccessDenied", err)
+	}
+}
+
+func TestIPFSTarget(t *testing.T) {
+	var writes []string
+	var published string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/files/write":
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("files/write without file: %v", err)
+				return
+			}
+			body, _ := io.ReadAll(f)
+			writes = append(writes, r.URL.Query().Get("arg")+"="+string(body))
+		case "/api/v0/files/stat":
+			_, _ = w.Write([]byte(`{"Hash":"bafyroot"}`))
+		case "/api/v0/name/publish":
+			published = r.URL.Query().Get("arg")
+			_, _ = w.Write([]byte(`{"Name":"k51name"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	target := NewIPFSTarget(srv.URL, "stat/", "fund")
+	if err := target.Put(context.Background(), "snapshots/2026-10-17.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatal(err)
+	}
+	if len(writes) != 1 || writes[0] != "/stat/snapshots/2026-10-17.json={}" {
+		t.Errorf("writes = %v", writes)
+	}
+
+	root, err := target.Root(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "bafyroot (ipns k51name)" || published != "/ipfs/bafyroot" {
+		t.Errorf("root = %q, published %q", root, published)
+	}
+}
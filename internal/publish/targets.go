@@ -0,0 +1,298 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const maxResponseBytes = 1 << 20
+
+// DirTarget writes files under a local directory, for static hosting from
+// any web server or a synced folder (GitHub Pages, rsync, ...).
+type DirTarget struct {
+	root string
+}
+
+// NewDirTarget creates a DirTarget rooted at dir.
+func NewDirTarget(dir string) *DirTarget {
+	return &DirTarget{root: dir}
+}
+
+// Put writes body to root/name via a temp file and rename, so a web server
+// never serves a half-written index.json.
+func (t *DirTarget) Put(_ context.Context, name string, body []byte, _ string) error {
+	dst := filepath.Join(t.root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".publish-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// TargetOption configures the HTTP targets (NewS3Target, NewIPFSTarget).
+type TargetOption func(*http.Client)
+
+// WithTransport sends the target's requests through rt, normally the shared
+// transport.Transport, instead of a connection pool of its own.
+func WithTransport(rt http.RoundTripper) TargetOption {
+	return func(c *http.Client) { c.Transport = rt }
+}
+
+// newHTTPClient builds a target's client with its own overall timeout.
+func newHTTPClient(timeout time.Duration, opts []TargetOption) *http.Client {
+	c := &http.Client{Timeout: timeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// S3Config configures an S3Target.
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.eu-central-1.amazonaws.com or an R2/MinIO URL
+	Bucket    string
+	Region    string // "auto" for R2; empty means us-east-1
+	Prefix    string // key prefix inside the bucket, e.g. "stat/"
+	AccessKey string
+	SecretKey string
+}
+
+// S3Target uploads files to an S3-compatible bucket with path-style
+// addressing and AWS Signature V4. Public read access is left to the bucket
+// policy; the target only needs PutObject.
+type S3Target struct {
+	cfg        S3Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3Target creates an S3Target.
+func NewS3Target(cfg S3Config, opts ...TargetOption) *S3Target {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3Target{cfg: cfg, httpClient: newHTTPClient(60*time.Second, opts), now: time.Now}
+}
+
+// Put uploads body as bucket/prefix+name.
+func (t *S3Target) Put(ctx context.Context, name string, body []byte, contentType string) error {
+	u, err := url.Parse(t.cfg.Endpoint + "/" + t.cfg.Bucket + "/" + path.Join(t.cfg.Prefix, name))
+	if err != nil {
+		return fmt.Errorf("building S3 URL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building S3 request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	t.sign(req, body)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling S3 PutObject: %w", err)
+	}
+	return drain(resp, "S3 PutObject")
+}
+
+// sign adds AWS Signature V4 headers for the "s3" service.
+func (t *S3Target) sign(req *http.Request, body []byte) {
+	now := t.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	var canonHeaders strings.Builder
+	for _, h := range signed {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.URL.Host
+		}
+		canonHeaders.WriteString(h + ":" + strings.TrimSpace(v) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + t.cfg.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+t.cfg.SecretKey), day)
+	key = hmacSHA256(key, t.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(msg))
+	return m.Sum(nil)
+}
+
+// IPFSTarget writes files into the MFS (mutable file system) of an IPFS
+// node through its Kubo RPC API, then reports the CID of the MFS directory
+// as the published root. With an IPNS key configured, Root also publishes
+// that CID under the key so readers get a stable address.
+type IPFSTarget struct {
+	apiURL     string
+	dir        string
+	ipnsKey    string
+	httpClient *http.Client
+}
+
+// NewIPFSTarget creates an IPFSTarget. apiURL is the node's RPC root (e.g.
+// http://127.0.0.1:5001); dir is the MFS directory holding the dataset.
+func NewIPFSTarget(apiURL, dir, ipnsKey string, opts ...TargetOption) *IPFSTarget {
+	return &IPFSTarget{
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		dir:        "/" + strings.Trim(dir, "/"),
+		ipnsKey:    ipnsKey,
+		httpClient: newHTTPClient(2*time.Minute, opts),
+	}
+}
+
+// Put writes body to dir/name, replacing any previous content.
+func (t *IPFSTarget) Put(ctx context.Context, name string, body []byte, _ string) error {
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("file", path.Base(name))
+	if err != nil {
+		return fmt.Errorf("building IPFS upload: %w", err)
+	}
+	if _, err := fw.Write(body); err != nil {
+		return fmt.Errorf("building IPFS upload: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("building IPFS upload: %w", err)
+	}
+
+	q := url.Values{
+		"arg":      {path.Join(t.dir, name)},
+		"create":   {"true"},
+		"parents":  {"true"},
+		"truncate": {"true"},
+	}
+	resp, err := t.call(ctx, "files/write", q, &form, mw.FormDataContentType())
+	if err != nil {
+		return err
+	}
+	return drain(resp, "IPFS files/write")
+}
+
+// Root returns the CID of the dataset directory, published to IPNS when a
+// key is configured.
+func (t *IPFSTarget) Root(ctx context.Context) (string, error) {
+	resp, err := t.call(ctx, "files/stat", url.Values{"arg": {t.dir}}, nil, "")
+	if err != nil {
+		return "", err
+	}
+	var stat struct {
+		Hash string `json:"Hash"`
+	}
+	if err := decode(resp, "IPFS files/stat", &stat); err != nil {
+		return "", err
+	}
+	if t.ipnsKey == "" {
+		return stat.Hash, nil
+	}
+
+	resp, err = t.call(ctx, "name/publish", url.Values{"arg": {"/ipfs/" + stat.Hash}, "key": {t.ipnsKey}}, nil, "")
+	if err != nil {
+		return "", err
+	}
+	var pub struct {
+		Name string `json:"Name"`
+	}
+	if err := decode(resp, "IPFS name/publish", &pub); err != nil {
+		return "", err
+	}
+	return stat.Hash + " (ipns " + pub.Name + ")", nil
+}
+
+// call POSTs to the Kubo RPC API; every RPC endpoint is POST-only.
+func (t *IPFSTarget) call(ctx context.Context, endpoint string, q url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.apiURL+"/api/v0/"+endpoint+"?"+q.Encode(), body)
+	if err != nil {
+		return nil, fmt.Errorf("building IPFS request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling IPFS %s: %w", endpoint, err)
+	}
+	return resp, nil
+}
+
+// drain consumes and closes resp, turning a non-2xx status into an error.
+func drain(resp *http.Response, op string) error {
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return nil
+	}
+	preview, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return errStatus(op, resp.Status, preview)
+}
+
+// decode reads a 2xx JSON response into v.
+func decode(resp *http.Response, op string, v any) error {
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		preview, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errStatus(op, resp.Status, preview)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s response: %w", op, err)
+	}
+	return nil
+}
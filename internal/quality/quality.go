@@ -0,0 +1,78 @@
+// Package quality scores how complete the inputs of a fund snapshot were.
+package quality
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// StaleQuoteAge is how old an external quote may be at snapshot time before
+// it counts as stale. `stat quote` runs hourly, so a day-old quote means the
+// cron has been failing for a while.
+const StaleQuoteAge = 24 * time.Hour
+
+// Assess scores data as generated at `at`: the share of held tokens across
+// all account groups that got a EURMTL value, the external quotes older than
+// StaleQuoteAge, the live metrics that reused the prior day's value, and how
+// far Horizon lagged.
+func Assess(data domain.FundStructureData, at time.Time) domain.DataQuality {
+	total, priced := tokenCoverage(data)
+	q := domain.DataQuality{
+		Score:       score(total, priced),
+		TokenCount:  total,
+		PricedCount: priced,
+	}
+	for _, quote := range data.Quotes {
+		if at.Sub(quote.FetchedAt) > StaleQuoteAge {
+			q.StaleQuotes++
+		}
+	}
+	if data.LiveMetrics != nil {
+		q.MetricFallbacks = len(data.LiveMetrics.Fallbacks)
+	}
+	if data.Ledger != nil {
+		lag := data.Ledger.LagSeconds
+		q.HorizonLag = &lag
+		q.Degraded = data.Ledger.Stale
+	}
+	return q
+}
+
+// Score returns the stored score of data, or the token coverage recomputed
+// from its portfolios for snapshots taken before scores were stored.
+func Score(data domain.FundStructureData) decimal.Decimal {
+	if data.Quality != nil {
+		return data.Quality.Score
+	}
+	return score(tokenCoverage(data))
+}
+
+func tokenCoverage(data domain.FundStructureData) (total, priced int) {
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			// Accounts kept at balances only (OTHER_ACCOUNTS_PRICING=balance)
+			// are unpriced by choice.
+			if acc.Pricing == domain.PricingBalance {
+				continue
+			}
+			for _, t := range acc.Tokens {
+				total++
+				if t.ValueInEURMTL != nil {
+					priced++
+				}
+			}
+		}
+	}
+	return total, priced
+}
+
+// score is priced/total as a percentage with 2 decimals; 100 when nothing is held.
+func score(total, priced int) decimal.Decimal {
+	if total == 0 {
+		return decimal.NewFromInt(100)
+	}
+	return decimal.NewFromInt(int64(priced)).Mul(decimal.NewFromInt(100)).DivRound(decimal.NewFromInt(int64(total)), 2)
+}
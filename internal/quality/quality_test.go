@@ -0,0 +1,74 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestAssess(t *testing.T) {
+	at := time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)
+	v := "10"
+	data := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "MTL"}, ValueInEURMTL: &v},
+			{Asset: domain.AssetInfo{Code: "DEAD"}},
+		}}},
+		MutualFunds: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "EURMTL"}, ValueInEURMTL: &v},
+		}}},
+		Quotes: []domain.QuoteUsage{
+			{Symbol: "BTC", FetchedAt: at.Add(-time.Hour)},
+			{Symbol: "AU", FetchedAt: at.Add(-3 * 24 * time.Hour)},
+		},
+		LiveMetrics: &domain.FundLiveMetrics{Fallbacks: []int{6, 7}},
+	}
+
+	q := Assess(data, at)
+	if !q.Score.Equal(decimal.RequireFromString("66.67")) || q.TokenCount != 3 || q.PricedCount != 2 {
+		t.Errorf("coverage = %s (%d/%d), want 66.67 (2/3)", q.Score, q.PricedCount, q.TokenCount)
+	}
+	if q.StaleQuotes != 1 {
+		t.Errorf("StaleQuotes = %d, want 1 (AU is 3 days old)", q.StaleQuotes)
+	}
+	if q.MetricFallbacks != 2 {
+		t.Errorf("MetricFallbacks = %d, want 2", q.MetricFallbacks)
+	}
+	if q.HorizonLag != nil || q.Degraded {
+		t.Errorf("HorizonLag = %v, Degraded = %v, want unset without a ledger status", q.HorizonLag, q.Degraded)
+	}
+	data.Ledger = &domain.LedgerStatus{LagSeconds: 600, Stale: true}
+	if q := Assess(data, at); q.HorizonLag == nil || *q.HorizonLag != 600 || !q.Degraded {
+		t.Errorf("with stale ledger: HorizonLag = %v, Degraded = %v, want 600 and degraded", q.HorizonLag, q.Degraded)
+	}
+
+	if got := Score(data); !got.Equal(q.Score) {
+		t.Errorf("Score without stored quality = %s, want recomputed %s", got, q.Score)
+	}
+	data.Quality = &domain.DataQuality{Score: decimal.NewFromInt(50)}
+	if got := Score(data); !got.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("Score = %s, want stored 50", got)
+	}
+}
+
+func TestAssessEmpty(t *testing.T) {
+	if q := Assess(domain.FundStructureData{}, time.Now()); !q.Score.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Score = %s, want 100 for a snapshot holding nothing", q.Score)
+	}
+}
+
+func TestAssessSkipsBalanceOnlyAccounts(t *testing.T) {
+	v := "10"
+	data := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{{ValueInEURMTL: &v}}}},
+		OtherAccounts: []domain.FundAccountPortfolio{{Pricing: domain.PricingBalance, Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "LABR"}},
+		}}},
+	}
+	if q := Assess(data, time.Now()); !q.Score.Equal(decimal.NewFromInt(100)) || q.TokenCount != 1 {
+		t.Errorf("coverage = %s over %d tokens, want 100 over 1", q.Score, q.TokenCount)
+	}
+}
@@ -0,0 +1,206 @@
+// Package reconcile compares the MONITORING sheet with the indicator rows
+// recomputed from stored snapshots and reports the dates that need re-import.
+package reconcile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/indicator"
+)
+
+// Status classifies one date of the report.
+type Status string
+
+const (
+	StatusMatch          Status = "match"            // every compared cell within tolerance
+	StatusMismatch       Status = "mismatch"         // at least one cell differs or is missing in the sheet
+	StatusDuplicate      Status = "duplicate"        // the date appears in more than one sheet row
+	StatusMissingInSheet Status = "missing_in_sheet" // a snapshot exists but the sheet has no row
+	StatusMissingInDB    Status = "missing_in_db"    // a sheet row without a stored snapshot
+)
+
+// Column is one MONITORING data column mapped to an indicator. Index is the
+// position among the data columns (0 = column B).
+type Column struct {
+	Index       int
+	Header      string
+	IndicatorID int
+}
+
+// SheetRow is one parsed MONITORING data row. Cells is keyed by
+// Column.Index; empty and unparseable cells are absent.
+type SheetRow struct {
+	Line  int // 1-based sheet row number
+	Date  time.Time
+	Cells map[int]decimal.Decimal
+}
+
+// Expected holds the indicator values a MONITORING row for Date should
+// contain, keyed by indicator ID.
+type Expected struct {
+	Date   time.Time
+	Values map[int]decimal.Decimal
+}
+
+// Diff is one cell outside tolerance. Sheet is nil when the cell is empty.
+type Diff struct {
+	Column      string           `json:"column"`
+	IndicatorID int              `json:"indicatorId"`
+	Sheet       *decimal.Decimal `json:"sheet"`
+	Expected    decimal.Decimal  `json:"expected"`
+}
+
+// Row is the outcome for one date.
+type Row struct {
+	Date    time.Time `json:"date"`
+	Line    int       `json:"line,omitempty"` // sheet row; 0 when missing in the sheet
+	Status  Status    `json:"status"`
+	Checked int       `json:"checked"` // cells compared
+	Diffs   []Diff    `json:"diffs,omitempty"`
+}
+
+// Report is the full reconciliation, oldest date first.
+type Report struct {
+	Tolerance decimal.Decimal `json:"tolerance"`
+	Rows      []Row           `json:"rows"`
+}
+
+// Compare matches sheet rows to expected rows by date and compares every
+// mapped column whose indicator has an expected value. A cell is within
+// tolerance when it differs from the expected value by at most tolerance
+// relative to it, or by half a unit of the indicator's precision, whichever
+// is larger, so values the sheet shows rounded still match.
+func Compare(cols []Column, sheet []SheetRow, expected []Expected, tolerance decimal.Decimal) Report {
+	byDate := make(map[time.Time]Expected, len(expected))
+	for _, e := range expected {
+		byDate[e.Date] = e
+	}
+
+	seen := make(map[time.Time]bool, len(sheet))
+	var rows []Row
+	for _, s := range sheet {
+		e, ok := byDate[s.Date]
+		if !ok {
+			rows = append(rows, Row{Date: s.Date, Line: s.Line, Status: StatusMissingInDB})
+			continue
+		}
+		row := compareRow(cols, s, e, tolerance)
+		if seen[s.Date] {
+			row.Status = StatusDuplicate
+		}
+		seen[s.Date] = true
+		rows = append(rows, row)
+	}
+	for _, e := range expected {
+		if !seen[e.Date] {
+			rows = append(rows, Row{Date: e.Date, Status: StatusMissingInSheet})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Date.Before(rows[j].Date) })
+	return Report{Tolerance: tolerance, Rows: rows}
+}
+
+func compareRow(cols []Column, s SheetRow, e Expected, tolerance decimal.Decimal) Row {
+	row := Row{Date: s.Date, Line: s.Line, Status: StatusMatch}
+	for _, col := range cols {
+		want, ok := e.Values[col.IndicatorID]
+		if !ok {
+			continue // not recomputable for this date
+		}
+		row.Checked++
+		got, ok := s.Cells[col.Index]
+		if ok && within(got, want, tolerance, indicator.PrecisionOf(col.IndicatorID)) {
+			continue
+		}
+		d := Diff{Column: col.Header, IndicatorID: col.IndicatorID, Expected: want}
+		if ok {
+			d.Sheet = &got
+		}
+		row.Diffs = append(row.Diffs, d)
+	}
+	if len(row.Diffs) > 0 {
+		row.Status = StatusMismatch
+	}
+	return row
+}
+
+func within(got, want, tolerance decimal.Decimal, precision int32) bool {
+	allowed := decimal.New(5, -precision-1) // half a unit in the last displayed place
+	if rel := want.Abs().Mul(tolerance); rel.GreaterThan(allowed) {
+		allowed = rel
+	}
+	return got.Sub(want).Abs().LessThanOrEqual(allowed)
+}
+
+// NeedsReimport returns the dates whose sheet rows are wrong, duplicated or
+// missing, oldest first. Sheet rows without a snapshot can't be re-imported
+// from the database and are not included.
+func (r Report) NeedsReimport() []time.Time {
+	var out []time.Time
+	for _, row := range r.Rows {
+		switch row.Status {
+		case StatusMismatch, StatusDuplicate, StatusMissingInSheet:
+			if len(out) == 0 || !out[len(out)-1].Equal(row.Date) {
+				out = append(out, row.Date)
+			}
+		}
+	}
+	return out
+}
+
+// Count returns the number of rows with status s.
+func (r Report) Count(s Status) int {
+	n := 0
+	for _, row := range r.Rows {
+		if row.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteCSV writes one line per differing cell, plus one line for every
+// row that is not a match but has no cell diffs (missing or duplicate rows):
+// date,line,status,column,indicator,sheet,expected,delta.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "line", "status", "column", "indicator", "sheet", "expected", "delta"}); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+	for _, row := range r.Rows {
+		if row.Status == StatusMatch {
+			continue
+		}
+		date := row.Date.Format("2006-01-02")
+		line := ""
+		if row.Line > 0 {
+			line = fmt.Sprint(row.Line)
+		}
+		if len(row.Diffs) == 0 {
+			if err := cw.Write([]string{date, line, string(row.Status), "", "", "", "", ""}); err != nil {
+				return fmt.Errorf("writing CSV row: %w", err)
+			}
+			continue
+		}
+		for _, d := range row.Diffs {
+			sheet, delta := "", ""
+			if d.Sheet != nil {
+				sheet = d.Sheet.String()
+				delta = d.Sheet.Sub(d.Expected).String()
+			}
+			rec := []string{date, line, string(row.Status), d.Column, fmt.Sprintf("I%d", d.IndicatorID), sheet, d.Expected.String(), delta}
+			if err := cw.Write(rec); err != nil {
+				return fmt.Errorf("writing CSV row: %w", err)
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,100 @@
+package reconcile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+var cols = []Column{
+	{Index: 2, Header: "Total Balance", IndicatorID: 3},
+	{Index: 4, Header: "Shares", IndicatorID: 5},
+	{Index: 14, Header: "Dividends per share", IndicatorID: 15},
+}
+
+func TestCompare(t *testing.T) {
+	sheet := []SheetRow{
+		{Line: 3, Date: day("2026-01-01"), Cells: map[int]decimal.Decimal{2: d("1000.004"), 4: d("500"), 14: d("0.0041")}},
+		{Line: 4, Date: day("2026-01-02"), Cells: map[int]decimal.Decimal{2: d("1100"), 4: d("500")}},
+		{Line: 5, Date: day("2026-01-02"), Cells: map[int]decimal.Decimal{2: d("1200"), 4: d("500")}},
+		{Line: 6, Date: day("2025-12-31"), Cells: map[int]decimal.Decimal{2: d("900")}},
+	}
+	expected := []Expected{
+		// I15 has precision 4: the sheet's 0.0041 against 0.00412 is within half a unit.
+		{Date: day("2026-01-01"), Values: map[int]decimal.Decimal{3: d("1000"), 5: d("500"), 15: d("0.00412")}},
+		// I15 absent: not recomputable, so not compared.
+		{Date: day("2026-01-02"), Values: map[int]decimal.Decimal{3: d("1200"), 5: d("501")}},
+		{Date: day("2026-01-03"), Values: map[int]decimal.Decimal{3: d("1300")}},
+	}
+
+	r := Compare(cols, sheet, expected, d("0.0001"))
+	got := make([]string, len(r.Rows))
+	for i, row := range r.Rows {
+		got[i] = row.Date.Format("01-02") + " " + string(row.Status)
+	}
+	want := []string{"12-31 missing_in_db", "01-01 match", "01-02 mismatch", "01-02 duplicate", "01-03 missing_in_sheet"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+
+	first := r.Rows[2]
+	if first.Checked != 2 || len(first.Diffs) != 2 {
+		t.Fatalf("2026-01-02 line 4: checked %d, diffs %+v", first.Checked, first.Diffs)
+	}
+	if first.Diffs[0].Column != "Total Balance" || !first.Diffs[0].Sheet.Equal(d("1100")) || !first.Diffs[0].Expected.Equal(d("1200")) {
+		t.Errorf("diff = %+v", first.Diffs[0])
+	}
+
+	reimport := r.NeedsReimport()
+	if len(reimport) != 2 || !reimport[0].Equal(day("2026-01-02")) || !reimport[1].Equal(day("2026-01-03")) {
+		t.Errorf("NeedsReimport = %v, want 2026-01-02 and 2026-01-03", reimport)
+	}
+	if n := r.Count(StatusMismatch); n != 1 {
+		t.Errorf("Count(mismatch) = %d, want 1", n)
+	}
+}
+
+func TestCompareRelativeTolerance(t *testing.T) {
+	sheet := []SheetRow{{Line: 3, Date: day("2026-01-01"), Cells: map[int]decimal.Decimal{2: d("1000.9")}}}
+	expected := []Expected{{Date: day("2026-01-01"), Values: map[int]decimal.Decimal{3: d("1000")}}}
+
+	if r := Compare(cols, sheet, expected, d("0.001")); r.Rows[0].Status != StatusMatch {
+		t.Errorf("0.09%% off with 0.1%% tolerance: %s", r.Rows[0].Status)
+	}
+	if r := Compare(cols, sheet, expected, d("0.0001")); r.Rows[0].Status != StatusMismatch {
+		t.Errorf("0.09%% off with 0.01%% tolerance: %s", r.Rows[0].Status)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	sheet := []SheetRow{{Line: 3, Date: day("2026-01-01"), Cells: map[int]decimal.Decimal{2: d("990")}}}
+	expected := []Expected{
+		{Date: day("2026-01-01"), Values: map[int]decimal.Decimal{3: d("1000"), 5: d("500")}},
+		{Date: day("2026-01-02"), Values: map[int]decimal.Decimal{3: d("1000")}},
+	}
+	var buf bytes.Buffer
+	if err := Compare(cols, sheet, expected, decimal.Zero).WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `date,line,status,column,indicator,sheet,expected,delta
+2026-01-01,3,mismatch,Total Balance,I3,990,1000,-10
+2026-01-01,3,mismatch,Shares,I5,,500,
+2026-01-02,,missing_in_sheet,,,,,
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
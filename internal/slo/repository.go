@@ -0,0 +1,89 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores run records in pipeline_runs.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL pipeline run repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// SaveRun implements Store.
+func (r *PgRepository) SaveRun(ctx context.Context, slug string, rec Record) error {
+	stages, err := json.Marshal(rec.Stages)
+	if err != nil {
+		return fmt.Errorf("encoding run stages: %w", err)
+	}
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO pipeline_runs (entity_id, snapshot_date, started_at, duration_ms, deadline_ms, succeeded, met, stages)
+		 SELECT id, $2, $3, $4, $5, $6, $7, $8 FROM fund_entities WHERE slug = $1`,
+		slug, rec.SnapshotDate, rec.StartedAt, rec.DurationMs, rec.DeadlineMs, rec.Succeeded, rec.Met, stages)
+	if err != nil {
+		return fmt.Errorf("saving pipeline run for %s: %w", rec.SnapshotDate.Format("2006-01-02"), err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("saving pipeline run: entity %q not found", slug)
+	}
+	return nil
+}
+
+// Runs implements Store.
+func (r *PgRepository) Runs(ctx context.Context, slug string, since time.Time) ([]Record, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT p.snapshot_date, p.started_at, p.duration_ms, p.deadline_ms, p.succeeded, p.met, p.stages
+		 FROM pipeline_runs p
+		 JOIN fund_entities fe ON fe.id = p.entity_id
+		 WHERE fe.slug = $1 AND p.started_at >= $2
+		 ORDER BY p.started_at DESC`,
+		slug, since)
+	if err != nil {
+		return nil, fmt.Errorf("listing pipeline runs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Record
+	for rows.Next() {
+		var rec Record
+		var stages []byte
+		if err := rows.Scan(&rec.SnapshotDate, &rec.StartedAt, &rec.DurationMs, &rec.DeadlineMs, &rec.Succeeded, &rec.Met, &stages); err != nil {
+			return nil, fmt.Errorf("scanning pipeline run: %w", err)
+		}
+		if err := json.Unmarshal(stages, &rec.Stages); err != nil {
+			return nil, fmt.Errorf("decoding run stages: %w", err)
+		}
+		out = append(out, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pipeline runs: %w", err)
+	}
+	return out, nil
+}
+
+// ConsecutiveMisses implements Store.
+func (r *PgRepository) ConsecutiveMisses(ctx context.Context, slug string) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*)
+		 FROM pipeline_runs p
+		 JOIN fund_entities fe ON fe.id = p.entity_id
+		 WHERE fe.slug = $1 AND NOT p.met
+		   AND p.started_at > COALESCE(
+		     (SELECT MAX(m.started_at) FROM pipeline_runs m WHERE m.entity_id = fe.id AND m.met),
+		     '-infinity'::timestamptz)`,
+		slug).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("counting missed pipeline runs: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,228 @@
+// Package slo tracks the latency budget of the daily report pipeline: how
+// long each stage of a run took, whether the whole run finished within the
+// deadline (SLO_DEADLINE), and how often it did over the last 30 days.
+//
+// The run being timed travels on the context, like progress reporting, so
+// the services only call Since around their work; without a run on the
+// context that is a no-op.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Pipeline stages, in execution order.
+const (
+	StageValuation   = "valuation"   // manual valuations scan
+	StagePortfolio   = "portfolio"   // account balances
+	StagePricing     = "pricing"     // token and XLM prices
+	StageMetrics     = "metrics"     // snapshot enrichers and indicator calculation
+	StagePersistence = "persistence" // snapshot and indicator writes
+	StageExport      = "export"      // Google Sheets export
+)
+
+// Stages lists the stages in execution order.
+var Stages = []string{StageValuation, StagePortfolio, StagePricing, StageMetrics, StagePersistence, StageExport}
+
+// WindowDays is the span of the rolling attainment.
+const WindowDays = 30
+
+// Run accumulates the stage durations of one pipeline run. It is safe for
+// concurrent use: token pricing runs in parallel.
+type Run struct {
+	started time.Time
+	mu      sync.Mutex
+	stages  map[string]time.Duration
+}
+
+// NewRun starts timing a run at started.
+func NewRun(started time.Time) *Run {
+	return &Run{started: started, stages: make(map[string]time.Duration)}
+}
+
+// Add adds d to the time spent in stage.
+func (r *Run) Add(stage string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[stage] += d
+}
+
+// StagesMs returns the time spent per stage in milliseconds, stages never
+// entered left out. Work done in parallel, such as the accounts priced side
+// by side, adds up, so stages can sum past the run's duration.
+func (r *Run) StagesMs() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]int64, len(r.stages))
+	for stage, d := range r.stages {
+		out[stage] = d.Milliseconds()
+	}
+	return out
+}
+
+type ctxKey struct{}
+
+// WithRun returns a context whose stages are timed into r.
+func WithRun(ctx context.Context, r *Run) context.Context {
+	return context.WithValue(ctx, ctxKey{}, r)
+}
+
+// Since adds the time since start to stage of the run on ctx, if any. Use it
+// as `defer slo.Since(ctx, slo.StagePricing, time.Now())`.
+func Since(ctx context.Context, stage string, start time.Time) {
+	if r, ok := ctx.Value(ctxKey{}).(*Run); ok {
+		r.Add(stage, time.Since(start))
+	}
+}
+
+// Record is one finished run.
+type Record struct {
+	SnapshotDate time.Time        `json:"snapshotDate"`
+	StartedAt    time.Time        `json:"startedAt"`
+	DurationMs   int64            `json:"durationMs"`
+	DeadlineMs   int64            `json:"deadlineMs"`
+	Succeeded    bool             `json:"succeeded"`
+	Met          bool             `json:"met"` // succeeded within the deadline
+	Stages       map[string]int64 `json:"stages"`
+}
+
+// Attainment is the share of runs that met the deadline over WindowDays.
+type Attainment struct {
+	WindowDays        int             `json:"windowDays"`
+	Deadline          string          `json:"deadline"`
+	Runs              int             `json:"runs"`
+	Met               int             `json:"met"`
+	Percent           decimal.Decimal `json:"percent"` // 100 with no runs
+	ConsecutiveMisses int             `json:"consecutiveMisses"`
+	LastRun           *Record         `json:"lastRun,omitempty"`
+}
+
+// Store persists run records (PgRepository).
+type Store interface {
+	SaveRun(ctx context.Context, slug string, rec Record) error
+	// Runs returns the runs started at or after since, newest first.
+	Runs(ctx context.Context, slug string, since time.Time) ([]Record, error)
+	// ConsecutiveMisses counts the latest runs that missed, up to the
+	// newest one that met the deadline.
+	ConsecutiveMisses(ctx context.Context, slug string) (int, error)
+}
+
+// Notifier delivers an HTML message (notify.Service).
+type Notifier interface {
+	SendMessage(ctx context.Context, date time.Time, msg string) error
+}
+
+// Service records runs against the deadline and alerts on repeated misses.
+type Service struct {
+	store      Store
+	notifier   Notifier
+	slug       string
+	deadline   time.Duration
+	alertAfter int
+	now        func() time.Time
+}
+
+// NewService creates a Service for the runs of entity slug. A run meets the
+// SLO when it succeeds within deadline; alertAfter consecutive misses send
+// one alert (0 disables it). A nil notifier only logs the alert.
+func NewService(store Store, notifier Notifier, slug string, deadline time.Duration, alertAfter int) *Service {
+	return &Service{store: store, notifier: notifier, slug: slug, deadline: deadline, alertAfter: alertAfter, now: time.Now}
+}
+
+// Finish records run for date, ended now, as failed when runErr is set,
+// and alerts when it makes exactly alertAfter misses in a row, so a streak
+// alerts once.
+func (s *Service) Finish(ctx context.Context, date time.Time, run *Run, runErr error) (Record, error) {
+	duration := s.now().Sub(run.started)
+	rec := Record{
+		SnapshotDate: date,
+		StartedAt:    run.started,
+		DurationMs:   duration.Milliseconds(),
+		DeadlineMs:   s.deadline.Milliseconds(),
+		Succeeded:    runErr == nil,
+		Met:          runErr == nil && duration <= s.deadline,
+		Stages:       run.StagesMs(),
+	}
+	if err := s.store.SaveRun(ctx, s.slug, rec); err != nil {
+		return rec, err
+	}
+	if rec.Met {
+		slog.Info("pipeline run met its deadline", "date", date.Format("2006-01-02"), "duration", duration.Round(time.Second), "deadline", s.deadline)
+		return rec, nil
+	}
+	slog.Error("pipeline run missed its deadline", "date", date.Format("2006-01-02"), "duration", duration.Round(time.Second),
+		"deadline", s.deadline, "succeeded", rec.Succeeded, "stages_ms", rec.Stages)
+
+	if s.alertAfter <= 0 {
+		return rec, nil
+	}
+	misses, err := s.store.ConsecutiveMisses(ctx, s.slug)
+	if err != nil {
+		return rec, err
+	}
+	if misses != s.alertAfter {
+		return rec, nil
+	}
+	msg := Message(rec, misses)
+	if s.notifier == nil {
+		slog.Error("pipeline SLO breached", "misses", misses)
+		return rec, nil
+	}
+	if err := s.notifier.SendMessage(ctx, date, msg); err != nil {
+		return rec, fmt.Errorf("sending SLO alert: %w", err)
+	}
+	return rec, nil
+}
+
+// Attainment returns the attainment over the WindowDays before now.
+func (s *Service) Attainment(ctx context.Context) (Attainment, error) {
+	runs, err := s.store.Runs(ctx, s.slug, s.now().AddDate(0, 0, -WindowDays))
+	if err != nil {
+		return Attainment{}, err
+	}
+	misses, err := s.store.ConsecutiveMisses(ctx, s.slug)
+	if err != nil {
+		return Attainment{}, err
+	}
+	a := Attainment{
+		WindowDays:        WindowDays,
+		Deadline:          s.deadline.String(),
+		Runs:              len(runs),
+		Percent:           decimal.NewFromInt(100),
+		ConsecutiveMisses: misses,
+	}
+	for _, r := range runs {
+		if r.Met {
+			a.Met++
+		}
+	}
+	if a.Runs > 0 {
+		a.Percent = decimal.NewFromInt(int64(a.Met)).Mul(decimal.NewFromInt(100)).DivRound(decimal.NewFromInt(int64(a.Runs)), 2)
+		last := runs[0]
+		a.LastRun = &last
+	}
+	return a, nil
+}
+
+// Message formats the alert for misses runs in a row, the last being rec.
+func Message(rec Record, misses int) string {
+	outcome := "finished"
+	if !rec.Succeeded {
+		outcome = "failed"
+	}
+	msg := fmt.Sprintf("<b>Report pipeline SLO breached</b>\n%d runs in a row missed the %s deadline. The %s run %s after %s.",
+		misses, time.Duration(rec.DeadlineMs)*time.Millisecond, rec.SnapshotDate.Format("2006-01-02"), outcome,
+		(time.Duration(rec.DurationMs) * time.Millisecond).Round(time.Second))
+	for _, stage := range Stages {
+		if ms, ok := rec.Stages[stage]; ok {
+			msg += fmt.Sprintf("\n%s: %s", stage, (time.Duration(ms) * time.Millisecond).Round(time.Second))
+		}
+	}
+	return msg
+}
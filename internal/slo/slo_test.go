@@ -0,0 +1,157 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memStore keeps runs newest first.
+type memStore struct {
+	runs []Record
+}
+
+func (m *memStore) SaveRun(_ context.Context, _ string, rec Record) error {
+	m.runs = append([]Record{rec}, m.runs...)
+	return nil
+}
+
+func (m *memStore) Runs(_ context.Context, _ string, since time.Time) ([]Record, error) {
+	var out []Record
+	for _, r := range m.runs {
+		if !r.StartedAt.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) ConsecutiveMisses(context.Context, string) (int, error) {
+	n := 0
+	for _, r := range m.runs {
+		if r.Met {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+type recordingNotifier struct {
+	msgs []string
+}
+
+func (n *recordingNotifier) SendMessage(_ context.Context, _ time.Time, msg string) error {
+	n.msgs = append(n.msgs, msg)
+	return nil
+}
+
+var day = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+// finish records a run that started at start and took d.
+func finish(t *testing.T, s *Service, start time.Time, d time.Duration, runErr error) Record {
+	t.Helper()
+	s.now = func() time.Time { return start.Add(d) }
+	rec, err := s.Finish(context.Background(), day, NewRun(start), runErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestSinceTimesStagesOfTheRunOnContext(t *testing.T) {
+	run := NewRun(time.Now())
+	ctx := WithRun(context.Background(), run)
+	Since(ctx, StagePricing, time.Now().Add(-2*time.Second))
+	Since(ctx, StagePricing, time.Now().Add(-time.Second))
+	Since(context.Background(), StageExport, time.Now().Add(-time.Hour)) // no run: ignored
+
+	got := run.StagesMs()
+	if ms := got[StagePricing]; ms < 3000 || ms > 3500 {
+		t.Errorf("pricing = %d ms, want about 3000", ms)
+	}
+	if _, ok := got[StageExport]; ok || len(got) != 1 {
+		t.Errorf("stages = %v, want pricing only", got)
+	}
+}
+
+func TestFinishMeetsDeadlineOnlyWhenSucceededInTime(t *testing.T) {
+	s := NewService(&memStore{}, nil, "mtlf", 30*time.Minute, 3)
+	start := day.Add(time.Hour)
+
+	if rec := finish(t, s, start, 20*time.Minute, nil); !rec.Met || !rec.Succeeded || rec.DurationMs != 1200000 {
+		t.Errorf("20m run = %+v, want met", rec)
+	}
+	if rec := finish(t, s, start, 30*time.Minute, nil); !rec.Met {
+		t.Error("run taking exactly the deadline missed it")
+	}
+	if rec := finish(t, s, start, 31*time.Minute, nil); rec.Met {
+		t.Error("31m run met a 30m deadline")
+	}
+	if rec := finish(t, s, start, time.Minute, errors.New("horizon down")); rec.Met || rec.Succeeded {
+		t.Errorf("failed run = %+v, want missed", rec)
+	}
+}
+
+func TestFinishAlertsOnceWhenMissesReachAlertAfter(t *testing.T) {
+	n := &recordingNotifier{}
+	s := NewService(&memStore{}, n, "mtlf", 30*time.Minute, 2)
+	start := day.Add(time.Hour)
+
+	finish(t, s, start, 40*time.Minute, nil)
+	if len(n.msgs) != 0 {
+		t.Fatalf("alerted after one miss: %v", n.msgs)
+	}
+	finish(t, s, start, time.Minute, errors.New("timeout"))
+	if len(n.msgs) != 1 {
+		t.Fatalf("alerts after two misses = %d, want 1", len(n.msgs))
+	}
+	if !strings.Contains(n.msgs[0], "2 runs in a row missed the 30m0s deadline") || !strings.Contains(n.msgs[0], "failed after 1m0s") {
+		t.Errorf("alert = %q", n.msgs[0])
+	}
+	finish(t, s, start, 40*time.Minute, nil)
+	if len(n.msgs) != 1 {
+		t.Errorf("third miss alerted again: %d alerts", len(n.msgs))
+	}
+
+	// A met run ends the streak; the next one alerts anew.
+	finish(t, s, start, time.Minute, nil)
+	finish(t, s, start, 40*time.Minute, nil)
+	finish(t, s, start, 40*time.Minute, nil)
+	if len(n.msgs) != 2 {
+		t.Errorf("alerts after a new streak = %d, want 2", len(n.msgs))
+	}
+}
+
+func TestAttainmentOverWindow(t *testing.T) {
+	store := &memStore{}
+	s := NewService(store, nil, "mtlf", 30*time.Minute, 0)
+	now := day.Add(12 * time.Hour)
+
+	empty, err := s.Attainment(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Runs != 0 || empty.Percent.String() != "100" || empty.LastRun != nil {
+		t.Errorf("no runs = %+v, want 100%% and no last run", empty)
+	}
+
+	finish(t, s, now.AddDate(0, 0, -40), 40*time.Minute, nil) // outside the window
+	finish(t, s, now.AddDate(0, 0, -3), time.Minute, nil)
+	finish(t, s, now.AddDate(0, 0, -2), time.Minute, nil)
+	finish(t, s, now.AddDate(0, 0, -1), 40*time.Minute, nil)
+	s.now = func() time.Time { return now }
+
+	a, err := s.Attainment(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Runs != 3 || a.Met != 2 || a.Percent.String() != "66.67" {
+		t.Errorf("attainment = %d of %d (%s%%), want 2 of 3 (66.67%%)", a.Met, a.Runs, a.Percent)
+	}
+	if a.ConsecutiveMisses != 1 || a.LastRun == nil || a.LastRun.Met || a.Deadline != "30m0s" || a.WindowDays != WindowDays {
+		t.Errorf("attainment = %+v, want one miss in a row, the last run", a)
+	}
+}
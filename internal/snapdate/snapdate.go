@@ -0,0 +1,88 @@
+// Package snapdate maps instants to snapshot dates.
+//
+// A snapshot date is a calendar day of the reporting timezone, stored as
+// midnight UTC like every DATE column and every YYYY-MM-DD in the API. The
+// day starts at the cut-off time on the local wall clock, so a run at 00:30
+// CET with a 01:00 cut-off still belongs to the previous day, and DST
+// transitions move neither the boundary nor the date.
+package snapdate
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock turns instants into snapshot dates. The zero value is UTC with a
+// midnight cut-off, the behaviour before the reporting timezone existed.
+type Clock struct {
+	loc    *time.Location
+	cutoff time.Duration // since local midnight
+}
+
+// UTC is the default clock.
+var UTC = Clock{}
+
+// New returns a clock for the IANA timezone tz ("" = UTC) whose day starts
+// at cutoff, given as HH:MM ("" = 00:00).
+func New(tz, cutoff string) (Clock, error) {
+	var c Clock
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return Clock{}, fmt.Errorf("invalid snapshot timezone %q: %w", tz, err)
+		}
+		c.loc = loc
+	}
+	if cutoff != "" {
+		t, err := time.Parse("15:04", cutoff)
+		if err != nil {
+			return Clock{}, fmt.Errorf("invalid snapshot cut-off %q, expected HH:MM", cutoff)
+		}
+		c.cutoff = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return c, nil
+}
+
+// Location returns the reporting timezone.
+func (c Clock) Location() *time.Location {
+	if c.loc == nil {
+		return time.UTC
+	}
+	return c.loc
+}
+
+// Date returns the snapshot date that instant t belongs to.
+func (c Clock) Date(t time.Time) time.Time {
+	local := t.In(c.Location())
+	day := Day(local)
+	wall := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second + time.Duration(local.Nanosecond())
+	if wall < c.cutoff {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// Today returns the snapshot date of the current instant.
+func (c Clock) Today() time.Time {
+	return c.Date(time.Now())
+}
+
+// Start returns the instant the snapshot day date begins: its cut-off on the
+// local wall clock.
+func (c Clock) Start(date time.Time) time.Time {
+	h, m := int(c.cutoff.Hours()), int(c.cutoff.Minutes())%60
+	return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, c.Location())
+}
+
+// String describes the clock for logs, e.g. "Europe/Belgrade 01:00".
+func (c Clock) String() string {
+	return fmt.Sprintf("%s %02d:%02d", c.Location(), int(c.cutoff.Hours()), int(c.cutoff.Minutes())%60)
+}
+
+// Day returns the calendar day of t, in t's own location, as midnight UTC.
+// Dates read from query parameters and the database are already in this
+// form; Day normalizes anything else before it is compared or stored.
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
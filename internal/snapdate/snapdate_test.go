@@ -0,0 +1,112 @@
+package snapdate
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestClockDateDefaultIsUTCMidnight(t *testing.T) {
+	got := UTC.Date(time.Date(2026, 3, 29, 23, 59, 0, 0, time.UTC))
+	if !got.Equal(date(2026, 3, 29)) {
+		t.Errorf("Date = %v, want 2026-03-29", got)
+	}
+	// An instant in another zone maps through UTC.
+	cet := time.FixedZone("CET", 3600)
+	got = UTC.Date(time.Date(2026, 3, 30, 0, 30, 0, 0, cet))
+	if !got.Equal(date(2026, 3, 29)) {
+		t.Errorf("Date(00:30 CET) = %v, want 2026-03-29", got)
+	}
+}
+
+func TestClockDateLocalCutoff(t *testing.T) {
+	c, err := New("Europe/Belgrade", "01:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		at   time.Time
+		want time.Time
+	}{
+		// 23:30 UTC is 00:30 CET on the 16th: before the cut-off, so the 15th.
+		{time.Date(2026, 1, 15, 23, 30, 0, 0, time.UTC), date(2026, 1, 15)},
+		// 00:30 UTC is 01:30 CET: the 16th.
+		{time.Date(2026, 1, 16, 0, 30, 0, 0, time.UTC), date(2026, 1, 16)},
+		// Summer (CEST, UTC+2): 23:30 UTC is 01:30 local, already the next day.
+		{time.Date(2026, 7, 15, 23, 30, 0, 0, time.UTC), date(2026, 7, 16)},
+	}
+	for _, tt := range tests {
+		if got := c.Date(tt.at); !got.Equal(tt.want) {
+			t.Errorf("Date(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestClockDateAcrossDST(t *testing.T) {
+	c, err := New("Europe/Belgrade", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Hourly instants through the spring-forward and fall-back days: the
+	// local day keeps its 23 or 25 hours under one date.
+	tests := []struct {
+		day   time.Time
+		hours int
+	}{
+		{date(2026, 3, 29), 23},
+		{date(2026, 10, 25), 25},
+	}
+	for _, tt := range tests {
+		from := tt.day.AddDate(0, 0, -1)
+		n := 0
+		for h := 0; h < 72; h++ {
+			if c.Date(from.Add(time.Duration(h) * time.Hour)).Equal(tt.day) {
+				n++
+			}
+		}
+		if n != tt.hours {
+			t.Errorf("%s: %d hourly instants, want %d", tt.day.Format("2006-01-02"), n, tt.hours)
+		}
+	}
+}
+
+func TestClockStart(t *testing.T) {
+	c, err := New("Europe/Belgrade", "06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := c.Start(date(2026, 3, 29)) // DST starts at 02:00 that morning
+	if want := time.Date(2026, 3, 29, 4, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("Start = %v, want %v", start.UTC(), want)
+	}
+	if got := c.Date(start); !got.Equal(date(2026, 3, 29)) {
+		t.Errorf("Date(Start) = %v, want the same day", got)
+	}
+	if got := c.Date(start.Add(-time.Second)); !got.Equal(date(2026, 3, 28)) {
+		t.Errorf("Date(Start−1s) = %v, want the previous day", got)
+	}
+}
+
+func TestNewRejectsInvalid(t *testing.T) {
+	if _, err := New("Mars/Olympus", ""); err == nil {
+		t.Error("unknown timezone accepted")
+	}
+	for _, cutoff := range []string{"25:00", "6", "06:00:00"} {
+		if _, err := New("", cutoff); err == nil {
+			t.Errorf("cut-off %q accepted", cutoff)
+		}
+	}
+}
+
+func TestClockString(t *testing.T) {
+	c, _ := New("Europe/Belgrade", "01:30")
+	if got := c.String(); got != "Europe/Belgrade 01:30" {
+		t.Errorf("String = %q", got)
+	}
+	if got := UTC.String(); got != "UTC 00:00" {
+		t.Errorf("UTC.String = %q", got)
+	}
+}
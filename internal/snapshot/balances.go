@@ -0,0 +1,168 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// BalancePoint is one account's balance of one asset on a snapshot date.
+type BalancePoint struct {
+	Date    time.Time       `json:"date"`
+	Balance decimal.Decimal `json:"balance"`
+}
+
+// accountBalances holds one account_balances row per account and asset key
+// (see AssetKey).
+type accountBalances struct {
+	accounts, assets, balances []string
+}
+
+// extractBalances lists every account's balances in a full FundStructureData
+// document, zero balances included. Unparseable balances are skipped.
+func extractBalances(data json.RawMessage) (accountBalances, error) {
+	var fs domain.FundStructureData
+	if err := json.Unmarshal(data, &fs); err != nil {
+		return accountBalances{}, fmt.Errorf("decoding snapshot for balances: %w", err)
+	}
+
+	var b accountBalances
+	seen := map[[2]string]bool{}
+	add := func(account, asset, balance string) {
+		key := [2]string{account, asset}
+		if _, err := decimal.NewFromString(balance); err != nil || seen[key] {
+			return
+		}
+		seen[key] = true
+		b.accounts = append(b.accounts, account)
+		b.assets = append(b.assets, asset)
+		b.balances = append(b.balances, balance)
+	}
+
+	for _, group := range [][]domain.FundAccountPortfolio{fs.Accounts, fs.MutualFunds, fs.OtherAccounts} {
+		for _, acc := range group {
+			add(acc.ID, "XLM", acc.XLMBalance)
+			for _, t := range acc.Tokens {
+				add(acc.ID, AssetKey(t.Asset), t.Balance)
+			}
+		}
+	}
+	return b, nil
+}
+
+// saveBalances replaces the account_balances rows of one snapshot date.
+func saveBalances(ctx context.Context, tx pgx.Tx, entityID int, date time.Time, b accountBalances) error {
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM account_balances WHERE entity_id = $1 AND balance_date = $2`, entityID, date); err != nil {
+		return fmt.Errorf("clearing balances for %s: %w", date.Format("2006-01-02"), err)
+	}
+	if len(b.accounts) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO account_balances (entity_id, account, asset, balance_date, balance)
+		 SELECT $1, t.account, t.asset, $2, t.balance::numeric
+		 FROM unnest($3::text[], $4::text[], $5::text[]) AS t(account, asset, balance)`,
+		entityID, date, b.accounts, b.assets, b.balances); err != nil {
+		return fmt.Errorf("saving balances for %s: %w", date.Format("2006-01-02"), err)
+	}
+	return nil
+}
+
+// GetAccountBalanceHistory returns account's balance of assetKey on every
+// snapshot date between from and to (inclusive; zero means unbounded) on
+// which it had a trustline, ascending.
+func (r *PgRepository) GetAccountBalanceHistory(ctx context.Context, entitySlug, account, assetKey string, from, to time.Time) ([]BalancePoint, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ab.balance_date, ab.balance
+		 FROM account_balances ab
+		 JOIN fund_entities fe ON fe.id = ab.entity_id
+		 WHERE fe.slug = $1 AND ab.account = $2 AND ab.asset = $3
+		   AND ($4::date IS NULL OR ab.balance_date >= $4)
+		   AND ($5::date IS NULL OR ab.balance_date <= $5)
+		 ORDER BY ab.balance_date`, entitySlug, account, assetKey, nullDate(from), nullDate(to))
+	if err != nil {
+		return nil, fmt.Errorf("getting %s balance history of %s: %w", assetKey, account, err)
+	}
+	defer rows.Close()
+
+	var points []BalancePoint
+	for rows.Next() {
+		var p BalancePoint
+		if err := rows.Scan(&p.Date, &p.Balance); err != nil {
+			return nil, fmt.Errorf("scanning balance point: %w", err)
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating balance history: %w", err)
+	}
+	return points, nil
+}
+
+// BackfillBalances fills account_balances for snapshots stored before the
+// table existed. Delta rows are reconstructed first. Returns the number of
+// snapshot dates filled.
+func (r *PgRepository) BackfillBalances(ctx context.Context, entitySlug string) (int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+snapshotColumns+`
+		 FROM fund_snapshots fs
+		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id
+		 WHERE fe.slug = $1
+		   AND NOT EXISTS (SELECT 1 FROM account_balances ab
+		                   WHERE ab.entity_id = fs.entity_id AND ab.balance_date = fs.snapshot_date)
+		 ORDER BY fs.snapshot_date`, entitySlug)
+	if err != nil {
+		return 0, fmt.Errorf("listing snapshots without balances: %w", err)
+	}
+	type pending struct {
+		entityID int
+		date     time.Time
+		balances accountBalances
+	}
+	var todo []pending
+	for rows.Next() {
+		var s Snapshot
+		var baseData json.RawMessage
+		if err := rows.Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt, &baseData, &s.Hash, &s.Signature, &s.Signer); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning snapshot: %w", err)
+		}
+		if err := s.reconstruct(baseData); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		b, err := extractBalances(s.Data)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("snapshot %s: %w", s.SnapshotDate.Format("2006-01-02"), err)
+		}
+		todo = append(todo, pending{entityID: s.EntityID, date: s.SnapshotDate, balances: b})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating snapshots: %w", err)
+	}
+
+	for _, p := range todo {
+		tx, err := r.pool.Begin(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("beginning balances tx: %w", err)
+		}
+		if err := saveBalances(ctx, tx, p.entityID, p.date, p.balances); err != nil {
+			_ = tx.Rollback(ctx)
+			return 0, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, fmt.Errorf("committing balances for %s: %w", p.date.Format("2006-01-02"), err)
+		}
+	}
+	return len(todo), nil
+}
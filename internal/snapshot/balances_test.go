@@ -0,0 +1,57 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestExtractBalances(t *testing.T) {
+	mtl := domain.AssetInfo{Code: "MTL", Issuer: domain.IssuerAddress, Type: domain.AssetTypeCreditAlphanum4}
+	fs := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{
+			ID:         "GMAIN",
+			XLMBalance: "150.5000000",
+			Tokens: []domain.TokenPriceWithBalance{
+				{Asset: mtl, Balance: "1000.0000000"},
+				{Asset: domain.EURMTLAsset(), Balance: "0.0000000"},
+				{Asset: domain.AssetInfo{Code: "BAD", Issuer: "GX"}, Balance: "n/a"},
+			},
+		}},
+		OtherAccounts: []domain.FundAccountPortfolio{{
+			ID:     "GOTHER",
+			Tokens: []domain.TokenPriceWithBalance{{Asset: mtl, Balance: "5.0000000"}},
+		}},
+	}
+	data, _ := json.Marshal(fs)
+
+	b, err := extractBalances(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[[2]string]string{}
+	for i := range b.accounts {
+		got[[2]string{b.accounts[i], b.assets[i]}] = b.balances[i]
+	}
+	want := map[[2]string]string{
+		{"GMAIN", "XLM"}:                          "150.5000000",
+		{"GMAIN", AssetKey(mtl)}:                  "1000.0000000",
+		{"GMAIN", AssetKey(domain.EURMTLAsset())}: "0.0000000", // zero trustlines are kept
+		{"GOTHER", AssetKey(mtl)}:                 "5.0000000",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d balances, want %d: %v", len(got), len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s %s = %q, want %q", k[0], k[1], got[k], v)
+		}
+	}
+}
+
+func TestExtractBalancesInvalidJSON(t *testing.T) {
+	if _, err := extractBalances(json.RawMessage(`{`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,387 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mtlprog/stat/internal/jsondiff"
+)
+
+// Delta storage: a row with base_id set holds a jsondiff patch against that
+// full snapshot instead of the full document. Bases are always full rows, so
+// reconstruction is one patch application, never a chain. Every read path
+// selects b.data (the base) alongside fs.data and calls reconstruct.
+
+// snapshotColumns is the select list for reads; callers must LEFT JOIN
+// fund_snapshots b ON b.id = fs.base_id.
+const snapshotColumns = `fs.id, fs.entity_id, fs.snapshot_date, fs.data, fs.created_at, b.data, ` + sealColumns
+
+// sealColumns reads the seal of fs; rows stored before migration 007 have none.
+const sealColumns = `COALESCE(fs.hash, ''), COALESCE(fs.signature, ''), COALESCE(fs.signer, '')`
+
+// reconstruct replaces a delta payload with the full document. baseData is
+// nil for full rows.
+func (s *Snapshot) reconstruct(baseData json.RawMessage) error {
+	if baseData == nil || s.Data == nil {
+		return nil
+	}
+	full, err := jsondiff.Apply(baseData, s.Data)
+	if err != nil {
+		return fmt.Errorf("reconstructing snapshot %s from delta: %w", s.SnapshotDate.Format("2006-01-02"), err)
+	}
+	s.Data = full
+	return nil
+}
+
+// PgRepository implements Repository with PostgreSQL.
+type PgRepository struct {
+	pool         *pgxpool.Pool
+	keyframeDays int    // 0 disables delta writes
+	signer       Signer // nil stores the hash only
+
+	detachDetails bool // move price details to snapshot_details
+	onSave        SaveHook
+}
+
+// Option configures a PgRepository.
+type Option func(*PgRepository)
+
+// WithDeltaStorage makes Save store a delta against the most recent full
+// snapshot written within keyframeDays, and a new full snapshot otherwise.
+// Reads reconstruct deltas regardless of this option.
+func WithDeltaStorage(keyframeDays int) Option {
+	return func(r *PgRepository) {
+		r.keyframeDays = keyframeDays
+	}
+}
+
+// SaveHook runs in the transaction of each snapshot Save, so whatever it
+// writes commits or rolls back with the snapshot.
+type SaveHook func(ctx context.Context, tx pgx.Tx, entityID int, date time.Time) error
+
+// WithSaveHook makes Save run hook before committing; an error from hook
+// fails the save.
+func WithSaveHook(hook SaveHook) Option {
+	return func(r *PgRepository) {
+		r.onSave = hook
+	}
+}
+
+// WithSigner makes Save sign each snapshot's hash (see Seal).
+func WithSigner(signer Signer) Option {
+	return func(r *PgRepository) {
+		r.signer = signer
+	}
+}
+
+// NewPgRepository creates a new PostgreSQL snapshot repository.
+func NewPgRepository(pool *pgxpool.Pool, opts ...Option) *PgRepository {
+	r := &PgRepository{pool: pool}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *PgRepository) Save(ctx context.Context, entityID int, date time.Time, data json.RawMessage) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning snapshot save tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// holdings and balances are lookup indexes; a document they can't parse
+	// is still saved.
+	holdings, err := extractHoldings(data)
+	if err != nil {
+		slog.Error("snapshot saved without holdings index", "date", date.Format("2006-01-02"), "error", err)
+		holdings = nil
+	}
+	balances, err := extractBalances(data)
+	if err != nil {
+		slog.Error("snapshot saved without account balances", "date", date.Format("2006-01-02"), "error", err)
+	}
+
+	var details []Detail
+	if r.detachDetails {
+		if data, details, err = DetachDetails(data); err != nil {
+			return fmt.Errorf("detaching price details: %w", err)
+		}
+	}
+
+	// The seal covers the full document as stored (lean when details are
+	// detached), so it survives delta storage and compaction unchanged.
+	seal, err := NewSeal(date, data, r.signer)
+	if err != nil {
+		return fmt.Errorf("sealing snapshot: %w", err)
+	}
+
+	// Overwriting a row that other deltas point at would silently corrupt them.
+	if _, err := materializeDependents(ctx, tx, entityID, date); err != nil {
+		return err
+	}
+
+	stored, baseID := data, (*int)(nil)
+	if r.keyframeDays > 0 {
+		stored, baseID, err = deltaAgainstKeyframe(ctx, tx, entityID, date, data, r.keyframeDays)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO fund_snapshots (entity_id, snapshot_date, data, base_id, holdings, hash, signature, signer)
+		 VALUES ($1, $2, $3::jsonb, $4, $5::jsonb, $6, NULLIF($7, ''), NULLIF($8, ''))
+		 ON CONFLICT (entity_id, snapshot_date)
+		 DO UPDATE SET data = $3::jsonb, base_id = $4, holdings = $5::jsonb,
+		   hash = $6, signature = NULLIF($7, ''), signer = NULLIF($8, '')`,
+		entityID, date, stored, baseID, holdings, seal.Hash, seal.Signature, seal.Signer); err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+	if err := saveBalances(ctx, tx, entityID, date, balances); err != nil {
+		return err
+	}
+	// Saving a document that is already lean (compaction, import) keeps the
+	// details detached earlier.
+	if len(details) > 0 {
+		if err := saveDetails(ctx, tx, entityID, date, details); err != nil {
+			return err
+		}
+	}
+
+	if r.onSave != nil {
+		if err := r.onSave(ctx, tx, entityID, date); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing snapshot save tx: %w", err)
+	}
+	return nil
+}
+
+// deltaAgainstKeyframe returns the patch and base ID to store for data, or
+// data itself with a nil base when there is no recent keyframe or the patch
+// would not be smaller.
+func deltaAgainstKeyframe(ctx context.Context, tx pgx.Tx, entityID int, date time.Time, data json.RawMessage, keyframeDays int) (json.RawMessage, *int, error) {
+	var baseID int
+	var baseData json.RawMessage
+	err := tx.QueryRow(ctx,
+		`SELECT id, data FROM fund_snapshots
+		 WHERE entity_id = $1 AND base_id IS NULL
+		   AND snapshot_date < $2 AND snapshot_date > $2::date - $3::int
+		 ORDER BY snapshot_date DESC
+		 LIMIT 1`, entityID, date, keyframeDays).Scan(&baseID, &baseData)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding keyframe for %s: %w", date.Format("2006-01-02"), err)
+	}
+
+	patch, equal, err := jsondiff.Diff(baseData, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("diffing snapshot against keyframe: %w", err)
+	}
+	if equal {
+		patch = json.RawMessage(`{"$o":{}}`)
+	}
+	if len(patch) >= len(data) {
+		return data, nil, nil
+	}
+	return patch, &baseID, nil
+}
+
+// materializeDependents rewrites every delta based on the snapshot at date
+// (if any) as a full row. Returns how many rows were rewritten.
+func materializeDependents(ctx context.Context, tx pgx.Tx, entityID int, date time.Time) (int, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT fs.id, fs.snapshot_date, fs.data, b.data
+		 FROM fund_snapshots fs
+		 JOIN fund_snapshots b ON b.id = fs.base_id
+		 WHERE b.entity_id = $1 AND b.snapshot_date = $2`, entityID, date)
+	if err != nil {
+		return 0, fmt.Errorf("listing dependent deltas: %w", err)
+	}
+	full, err := collectReconstructed(rows)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range full {
+		if _, err := tx.Exec(ctx,
+			`UPDATE fund_snapshots SET data = $2::jsonb, base_id = NULL WHERE id = $1`, s.ID, s.Data); err != nil {
+			return 0, fmt.Errorf("materializing snapshot %d: %w", s.ID, err)
+		}
+	}
+	return len(full), nil
+}
+
+// collectReconstructed scans (id, snapshot_date, data, base data) rows.
+func collectReconstructed(rows pgx.Rows) ([]Snapshot, error) {
+	defer rows.Close()
+	var out []Snapshot
+	for rows.Next() {
+		var s Snapshot
+		var baseData json.RawMessage
+		if err := rows.Scan(&s.ID, &s.SnapshotDate, &s.Data, &baseData); err != nil {
+			return nil, fmt.Errorf("scanning delta row: %w", err)
+		}
+		if err := s.reconstruct(baseData); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating delta rows: %w", err)
+	}
+	return out, nil
+}
+
+// CompactResult summarizes a Compact or Expand run.
+type CompactResult struct {
+	Full        int
+	Delta       int
+	BytesBefore int64
+	BytesAfter  int64
+}
+
+// Expand rewrites every delta row of the entity as a full snapshot.
+func (r *PgRepository) Expand(ctx context.Context, entitySlug string) (CompactResult, error) {
+	entityID, err := r.GetEntityID(ctx, entitySlug)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	var res CompactResult
+	if res.BytesBefore, err = r.storedBytes(ctx, entityID); err != nil {
+		return res, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return res, fmt.Errorf("beginning expand tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx,
+		`SELECT fs.id, fs.snapshot_date, fs.data, b.data
+		 FROM fund_snapshots fs
+		 JOIN fund_snapshots b ON b.id = fs.base_id
+		 WHERE fs.entity_id = $1`, entityID)
+	if err != nil {
+		return res, fmt.Errorf("listing delta rows: %w", err)
+	}
+	full, err := collectReconstructed(rows)
+	if err != nil {
+		return res, err
+	}
+	for _, s := range full {
+		if _, err := tx.Exec(ctx,
+			`UPDATE fund_snapshots SET data = $2::jsonb, base_id = NULL WHERE id = $1`, s.ID, s.Data); err != nil {
+			return res, fmt.Errorf("expanding snapshot %d: %w", s.ID, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return res, fmt.Errorf("committing expand tx: %w", err)
+	}
+
+	res.Full = len(full)
+	res.BytesAfter, err = r.storedBytes(ctx, entityID)
+	return res, err
+}
+
+// Compact re-plans delta storage for every snapshot of the entity: the first
+// snapshot of each keyframeDays window is stored in full, the rest as deltas
+// against it (unless a delta would be larger). Idempotent; it expands all
+// deltas first so no row is rewritten while another still depends on it.
+func (r *PgRepository) Compact(ctx context.Context, entitySlug string, keyframeDays int) (CompactResult, error) {
+	if keyframeDays < 1 {
+		return CompactResult{}, fmt.Errorf("keyframe interval must be at least 1 day, got %d", keyframeDays)
+	}
+	expanded, err := r.Expand(ctx, entitySlug)
+	if err != nil {
+		return CompactResult{}, fmt.Errorf("expanding before compaction: %w", err)
+	}
+	entityID, err := r.GetEntityID(ctx, entitySlug)
+	if err != nil {
+		return CompactResult{}, err
+	}
+
+	res := CompactResult{BytesBefore: expanded.BytesBefore}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, snapshot_date FROM fund_snapshots WHERE entity_id = $1 ORDER BY snapshot_date`, entityID)
+	if err != nil {
+		return res, fmt.Errorf("listing snapshots: %w", err)
+	}
+	type ref struct {
+		id   int
+		date time.Time
+	}
+	var refs []ref
+	for rows.Next() {
+		var x ref
+		if err := rows.Scan(&x.id, &x.date); err != nil {
+			rows.Close()
+			return res, fmt.Errorf("scanning snapshot ref: %w", err)
+		}
+		refs = append(refs, x)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, fmt.Errorf("iterating snapshot refs: %w", err)
+	}
+
+	// One row at a time keeps memory at two snapshots regardless of history size.
+	var base *Snapshot
+	for _, x := range refs {
+		var data json.RawMessage
+		if err := r.pool.QueryRow(ctx, `SELECT data FROM fund_snapshots WHERE id = $1`, x.id).Scan(&data); err != nil {
+			return res, fmt.Errorf("loading snapshot %d: %w", x.id, err)
+		}
+
+		if base == nil || x.date.Sub(base.SnapshotDate) >= time.Duration(keyframeDays)*24*time.Hour {
+			base = &Snapshot{ID: x.id, SnapshotDate: x.date, Data: data}
+			res.Full++
+			continue
+		}
+
+		patch, equal, err := jsondiff.Diff(base.Data, data)
+		if err != nil {
+			return res, fmt.Errorf("diffing snapshot %s: %w", x.date.Format("2006-01-02"), err)
+		}
+		if equal {
+			patch = json.RawMessage(`{"$o":{}}`)
+		}
+		if len(patch) >= len(data) {
+			res.Full++
+			continue
+		}
+		if _, err := r.pool.Exec(ctx,
+			`UPDATE fund_snapshots SET data = $2::jsonb, base_id = $3 WHERE id = $1`, x.id, patch, base.ID); err != nil {
+			return res, fmt.Errorf("storing delta for %s: %w", x.date.Format("2006-01-02"), err)
+		}
+		res.Delta++
+		slog.Debug("snapshot compacted", "date", x.date.Format("2006-01-02"), "full_bytes", len(data), "delta_bytes", len(patch))
+	}
+
+	res.BytesAfter, err = r.storedBytes(ctx, entityID)
+	return res, err
+}
+
+func (r *PgRepository) storedBytes(ctx context.Context, entityID int) (int64, error) {
+	var n int64
+	if err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(octet_length(data::text)), 0) FROM fund_snapshots WHERE entity_id = $1`,
+		entityID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("measuring snapshot storage: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,49 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/jsondiff"
+)
+
+func TestReconstructFullRowUnchanged(t *testing.T) {
+	s := Snapshot{Data: json.RawMessage(`{"a":1}`)}
+	if err := s.reconstruct(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(s.Data) != `{"a":1}` {
+		t.Errorf("Data = %s, want unchanged", s.Data)
+	}
+}
+
+func TestReconstructDelta(t *testing.T) {
+	base := json.RawMessage(`{"accounts":[{"name":"MAIN","total":"100"}],"warnings":[]}`)
+	target := json.RawMessage(`{"accounts":[{"name":"MAIN","total":"105"}],"warnings":["x"]}`)
+	patch, _, err := jsondiff.Diff(base, target)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	s := Snapshot{SnapshotDate: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Data: patch}
+	if err := s.reconstruct(base); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got, want any
+	json.Unmarshal(s.Data, &got)
+	json.Unmarshal(target, &want)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("reconstructed = %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestReconstructCorruptDelta(t *testing.T) {
+	s := Snapshot{SnapshotDate: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Data: json.RawMessage(`{"bogus":1}`)}
+	if err := s.reconstruct(json.RawMessage(`{"a":1}`)); err == nil {
+		t.Error("expected error for corrupt delta")
+	}
+}
@@ -0,0 +1,231 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// Detail is the price discovery details of one token on one account, priced
+// in one base: what DetachDetails moves out of a snapshot document.
+type Detail struct {
+	Account string          `json:"account"`
+	Asset   string          `json:"asset"` // AssetKey of the token
+	Base    string          `json:"base"`  // "EURMTL" or "XLM"
+	Details json.RawMessage `json:"details"`
+}
+
+// detailFields maps the token fields holding price details to their base.
+var detailFields = []struct{ field, base string }{
+	{"detailsEURMTL", "EURMTL"},
+	{"detailsXLM", "XLM"},
+}
+
+// portfolioGroups are the document sections listing accounts with tokens.
+var portfolioGroups = []string{"accounts", "mutualFunds", "otherAccounts"}
+
+// WithDetachedDetails makes Save move every token's price details out of the
+// document into snapshot_details, leaving a stub (see DetachDetails). The
+// seal covers the stored, lean document.
+func WithDetachedDetails() Option {
+	return func(r *PgRepository) {
+		r.detachDetails = true
+	}
+}
+
+// DetachDetails returns data with every token's detailsEURMTL / detailsXLM
+// replaced by {"source": ..., "detached": true} and the removed details.
+// The stub keeps the document's "priced by the market" marker that price
+// warm-up relies on. Details that are already stubs are left alone.
+// Numbers and the rest of the document are kept verbatim.
+func DetachDetails(data json.RawMessage) (json.RawMessage, []Detail, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var out []Detail
+	err = walkTokens(doc, func(account, asset string, token map[string]any) error {
+		for _, f := range detailFields {
+			d, ok := token[f.field].(map[string]any)
+			if !ok || d["detached"] == true {
+				continue
+			}
+			raw, err := json.Marshal(d)
+			if err != nil {
+				return fmt.Errorf("encoding %s of %s on %s: %w", f.field, asset, account, err)
+			}
+			out = append(out, Detail{Account: account, Asset: asset, Base: f.base, Details: raw})
+			token[f.field] = map[string]any{"source": d["source"], "detached": true}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(out) == 0 {
+		return data, nil, nil
+	}
+	lean, err := encodeDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lean, out, nil
+}
+
+// AttachDetails returns data with the detached stubs replaced by the
+// matching details, the inverse of DetachDetails. Stubs without a detail and
+// details without a stub are left as they are.
+func AttachDetails(data json.RawMessage, details []Detail) (json.RawMessage, error) {
+	if len(details) == 0 {
+		return data, nil
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[[3]string]json.RawMessage, len(details))
+	for _, d := range details {
+		byKey[[3]string{d.Account, d.Asset, d.Base}] = d.Details
+	}
+	err = walkTokens(doc, func(account, asset string, token map[string]any) error {
+		for _, f := range detailFields {
+			stub, ok := token[f.field].(map[string]any)
+			if !ok || stub["detached"] != true {
+				continue
+			}
+			raw, ok := byKey[[3]string{account, asset, f.base}]
+			if !ok {
+				continue
+			}
+			full, err := decodeDocument(raw)
+			if err != nil {
+				return err
+			}
+			token[f.field] = full
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(doc)
+}
+
+// InlineDetails lists the details still embedded in data, as DetachDetails
+// would move them, for snapshots stored before or without detaching.
+func InlineDetails(data json.RawMessage) ([]Detail, error) {
+	_, details, err := DetachDetails(data)
+	return details, err
+}
+
+func decodeDocument(data json.RawMessage) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding snapshot for details: %w", err)
+	}
+	return doc, nil
+}
+
+func encodeDocument(doc map[string]any) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encoding lean snapshot: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// walkTokens calls fn for every token object of every account in doc.
+func walkTokens(doc map[string]any, fn func(account, asset string, token map[string]any) error) error {
+	for _, group := range portfolioGroups {
+		accounts, _ := doc[group].([]any)
+		for _, a := range accounts {
+			acc, ok := a.(map[string]any)
+			if !ok {
+				continue
+			}
+			id, _ := acc["id"].(string)
+			tokens, _ := acc["tokens"].([]any)
+			for _, t := range tokens {
+				token, ok := t.(map[string]any)
+				if !ok {
+					continue
+				}
+				raw, err := json.Marshal(token["asset"])
+				if err != nil {
+					return fmt.Errorf("encoding token asset on %s: %w", id, err)
+				}
+				var asset domain.AssetInfo
+				if err := json.Unmarshal(raw, &asset); err != nil {
+					return fmt.Errorf("decoding token asset on %s: %w", id, err)
+				}
+				if err := fn(id, AssetKey(asset), token); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func saveDetails(ctx context.Context, tx pgx.Tx, entityID int, date time.Time, details []Detail) error {
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM snapshot_details WHERE entity_id = $1 AND snapshot_date = $2`, entityID, date); err != nil {
+		return fmt.Errorf("clearing details for %s: %w", date.Format("2006-01-02"), err)
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	accounts := make([]string, len(details))
+	assets := make([]string, len(details))
+	bases := make([]string, len(details))
+	docs := make([]string, len(details))
+	for i, d := range details {
+		accounts[i], assets[i], bases[i], docs[i] = d.Account, d.Asset, d.Base, string(d.Details)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO snapshot_details (entity_id, snapshot_date, account, asset, base, details)
+		 SELECT $1, $2, t.account, t.asset, t.base, t.details::jsonb
+		 FROM unnest($3::text[], $4::text[], $5::text[], $6::text[]) AS t(account, asset, base, details)`,
+		entityID, date, accounts, assets, bases, docs); err != nil {
+		return fmt.Errorf("saving details for %s: %w", date.Format("2006-01-02"), err)
+	}
+	return nil
+}
+
+// GetDetails returns the detached price details of the snapshot of date,
+// of one asset (AssetKey) or of all when asset is empty, ordered by asset,
+// account and base. Snapshots saved with their details inline have none;
+// rows left from an earlier detached save of a date since re-saved inline
+// are still returned, so read the document's stubs first.
+func (r *PgRepository) GetDetails(ctx context.Context, entitySlug string, date time.Time, asset string) ([]Detail, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT sd.account, sd.asset, sd.base, sd.details
+		 FROM snapshot_details sd
+		 JOIN fund_entities fe ON fe.id = sd.entity_id
+		 WHERE fe.slug = $1 AND sd.snapshot_date = $2 AND ($3 = '' OR sd.asset = $3)
+		 ORDER BY sd.asset, sd.account, sd.base`, entitySlug, date, asset)
+	if err != nil {
+		return nil, fmt.Errorf("querying snapshot details: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Detail{}
+	for rows.Next() {
+		var d Detail
+		if err := rows.Scan(&d.Account, &d.Asset, &d.Base, &d.Details); err != nil {
+			return nil, fmt.Errorf("scanning snapshot detail: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,139 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func detailedDocument(t *testing.T) json.RawMessage {
+	t.Helper()
+	mtl := domain.AssetInfo{Code: "MTL", Issuer: domain.IssuerAddress, Type: domain.AssetTypeCreditAlphanum4}
+	amount := "12.3400000"
+	fs := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{
+			ID:         "GMAIN",
+			XLMBalance: "150.5000000",
+			Tokens: []domain.TokenPriceWithBalance{
+				{
+					Asset:         mtl,
+					Balance:       "1000.0000000",
+					DetailsEURMTL: &domain.PriceDetails{Source: "path", SourceAmount: &amount},
+					DetailsXLM:    &domain.PriceDetails{Source: "orderbook", PriceType: "bid"},
+				},
+				{Asset: domain.EURMTLAsset(), Balance: "5.0000000"},
+			},
+		}},
+	}
+	data, err := json.Marshal(fs)
+	if err != nil {
+		t.Fatalf("encoding document: %v", err)
+	}
+	return data
+}
+
+func TestDetachDetails(t *testing.T) {
+	data := detailedDocument(t)
+
+	lean, details, err := DetachDetails(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("details = %d, want 2", len(details))
+	}
+	mtlKey := "MTL-" + domain.IssuerAddress
+	if d := details[0]; d.Account != "GMAIN" || d.Asset != mtlKey || d.Base != "EURMTL" || !strings.Contains(string(d.Details), `"sourceAmount":"12.3400000"`) {
+		t.Errorf("details[0] = %+v", d)
+	}
+	if details[1].Base != "XLM" {
+		t.Errorf("details[1].Base = %q, want XLM", details[1].Base)
+	}
+	if len(lean) >= len(data) {
+		t.Errorf("lean document is %d bytes, original %d", len(lean), len(data))
+	}
+
+	var fs domain.FundStructureData
+	if err := json.Unmarshal(lean, &fs); err != nil {
+		t.Fatalf("decoding lean document: %v", err)
+	}
+	token := fs.Accounts[0].Tokens[0]
+	if token.DetailsEURMTL == nil || token.DetailsEURMTL.Source != "path" || !token.DetailsEURMTL.Detached || token.DetailsEURMTL.SourceAmount != nil {
+		t.Errorf("DetailsEURMTL = %+v, want a path stub", token.DetailsEURMTL)
+	}
+	if token.Balance != "1000.0000000" || fs.Accounts[0].XLMBalance != "150.5000000" {
+		t.Errorf("balances changed: %s, %s", token.Balance, fs.Accounts[0].XLMBalance)
+	}
+
+	holdings, err := extractHoldings(lean)
+	if err != nil {
+		t.Fatalf("extractHoldings on lean document: %v", err)
+	}
+	if !strings.Contains(string(holdings), `"GMAIN":"1000.0000000"`) {
+		t.Errorf("holdings = %s", holdings)
+	}
+
+	again, more, err := DetachDetails(lean)
+	if err != nil {
+		t.Fatalf("second detach: %v", err)
+	}
+	if len(more) != 0 || string(again) != string(lean) {
+		t.Errorf("second detach = %d details, changed %v; want none, unchanged", len(more), string(again) != string(lean))
+	}
+}
+
+func TestDetachDetailsNothingToDetach(t *testing.T) {
+	data := json.RawMessage(`{"accounts":[{"id":"GMAIN","tokens":[]}],"total":1.50}`)
+	lean, details, err := DetachDetails(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if details != nil || string(lean) != string(data) {
+		t.Errorf("got %s with %d details, want the document unchanged", lean, len(details))
+	}
+}
+
+func TestAttachDetailsRoundTrip(t *testing.T) {
+	data := detailedDocument(t)
+	lean, details, err := DetachDetails(data)
+	if err != nil {
+		t.Fatalf("detach: %v", err)
+	}
+
+	full, err := AttachDetails(lean, details)
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	var got, want domain.FundStructureData
+	json.Unmarshal(full, &got)
+	json.Unmarshal(data, &want)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("attached = %s, want %s", gotJSON, wantJSON)
+	}
+
+	partial, err := AttachDetails(lean, details[1:])
+	if err != nil {
+		t.Fatalf("partial attach: %v", err)
+	}
+	json.Unmarshal(partial, &got)
+	if d := got.Accounts[0].Tokens[0].DetailsEURMTL; d == nil || !d.Detached {
+		t.Errorf("DetailsEURMTL = %+v, want the stub kept", d)
+	}
+}
+
+func TestInlineDetails(t *testing.T) {
+	details, err := InlineDetails(detailedDocument(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(details) != 2 {
+		t.Errorf("details = %d, want 2", len(details))
+	}
+	if _, err := InlineDetails(json.RawMessage(`not json`)); err == nil {
+		t.Error("expected error for invalid document")
+	}
+}
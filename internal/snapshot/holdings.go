@@ -0,0 +1,185 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// AssetKey is the holdings key for an asset: "XLM" for native, else CODE-ISSUER.
+func AssetKey(a domain.AssetInfo) string {
+	if a.IsNative() {
+		return "XLM"
+	}
+	return a.Code + "-" + a.Issuer
+}
+
+// extractHoldings builds the holdings column from a full FundStructureData
+// document: asset key → account address → balance, non-zero balances only.
+func extractHoldings(data json.RawMessage) (json.RawMessage, error) {
+	var fs domain.FundStructureData
+	if err := json.Unmarshal(data, &fs); err != nil {
+		return nil, fmt.Errorf("decoding snapshot for holdings: %w", err)
+	}
+
+	holdings := map[string]map[string]string{}
+	add := func(asset, account, balance string) {
+		b, err := decimal.NewFromString(balance)
+		if err != nil || b.IsZero() {
+			return
+		}
+		if holdings[asset] == nil {
+			holdings[asset] = map[string]string{}
+		}
+		holdings[asset][account] = balance
+	}
+
+	for _, group := range [][]domain.FundAccountPortfolio{fs.Accounts, fs.MutualFunds, fs.OtherAccounts} {
+		for _, acc := range group {
+			add("XLM", acc.ID, acc.XLMBalance)
+			for _, t := range acc.Tokens {
+				add(AssetKey(t.Asset), acc.ID, t.Balance)
+			}
+		}
+	}
+	return json.Marshal(holdings)
+}
+
+// TokenBalancePoint is the fund-wide balance of one asset on a snapshot date.
+type TokenBalancePoint struct {
+	Date     time.Time                  `json:"date"`
+	Balance  decimal.Decimal            `json:"balance"`
+	Accounts map[string]decimal.Decimal `json:"accounts"`
+}
+
+// FindSnapshotsHoldingToken returns the dates (ascending) of snapshots in
+// which any fund account held a non-zero balance of assetKey (see AssetKey).
+// The first element answers "when did we first hold X".
+func (r *PgRepository) FindSnapshotsHoldingToken(ctx context.Context, entitySlug, assetKey string) ([]SnapshotMeta, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT fs.snapshot_date, fs.created_at
+		 FROM fund_snapshots fs
+		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 WHERE fe.slug = $1 AND fs.holdings ? $2
+		 ORDER BY fs.snapshot_date`, entitySlug, assetKey)
+	if err != nil {
+		return nil, fmt.Errorf("finding snapshots holding %s: %w", assetKey, err)
+	}
+	defer rows.Close()
+
+	var metas []SnapshotMeta
+	for rows.Next() {
+		var m SnapshotMeta
+		if err := rows.Scan(&m.SnapshotDate, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning snapshot meta: %w", err)
+		}
+		metas = append(metas, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating snapshot meta: %w", err)
+	}
+	return metas, nil
+}
+
+// GetTokenBalanceSeries returns the per-date balance of assetKey across fund
+// accounts between from and to (inclusive; zero means unbounded), ascending.
+// Dates where the asset was not held are omitted.
+func (r *PgRepository) GetTokenBalanceSeries(ctx context.Context, entitySlug, assetKey string, from, to time.Time) ([]TokenBalancePoint, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT fs.snapshot_date, fs.holdings -> $2
+		 FROM fund_snapshots fs
+		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 WHERE fe.slug = $1 AND fs.holdings ? $2
+		   AND ($3::date IS NULL OR fs.snapshot_date >= $3)
+		   AND ($4::date IS NULL OR fs.snapshot_date <= $4)
+		 ORDER BY fs.snapshot_date`, entitySlug, assetKey, nullDate(from), nullDate(to))
+	if err != nil {
+		return nil, fmt.Errorf("getting balance series for %s: %w", assetKey, err)
+	}
+	defer rows.Close()
+
+	var points []TokenBalancePoint
+	for rows.Next() {
+		var p TokenBalancePoint
+		var byAccount map[string]string
+		if err := rows.Scan(&p.Date, &byAccount); err != nil {
+			return nil, fmt.Errorf("scanning balance point: %w", err)
+		}
+		p.Accounts = make(map[string]decimal.Decimal, len(byAccount))
+		for acc, bal := range byAccount {
+			d, err := decimal.NewFromString(bal)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s balance on %s: %w", acc, p.Date.Format("2006-01-02"), err)
+			}
+			p.Accounts[acc] = d
+			p.Balance = p.Balance.Add(d)
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating balance series: %w", err)
+	}
+	return points, nil
+}
+
+// BackfillHoldings fills the holdings column for rows written before it
+// existed. Delta rows are reconstructed first. Returns the number of rows updated.
+func (r *PgRepository) BackfillHoldings(ctx context.Context, entitySlug string) (int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+snapshotColumns+`
+		 FROM fund_snapshots fs
+		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id
+		 WHERE fe.slug = $1 AND fs.holdings IS NULL
+		 ORDER BY fs.snapshot_date`, entitySlug)
+	if err != nil {
+		return 0, fmt.Errorf("listing snapshots without holdings: %w", err)
+	}
+	type pending struct {
+		id       int
+		holdings json.RawMessage
+	}
+	var todo []pending
+	for rows.Next() {
+		var s Snapshot
+		var baseData json.RawMessage
+		if err := rows.Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt, &baseData, &s.Hash, &s.Signature, &s.Signer); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning snapshot: %w", err)
+		}
+		if err := s.reconstruct(baseData); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		h, err := extractHoldings(s.Data)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("snapshot %s: %w", s.SnapshotDate.Format("2006-01-02"), err)
+		}
+		todo = append(todo, pending{id: s.ID, holdings: h})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating snapshots: %w", err)
+	}
+
+	for _, p := range todo {
+		if _, err := r.pool.Exec(ctx,
+			`UPDATE fund_snapshots SET holdings = $2::jsonb WHERE id = $1`, p.id, p.holdings); err != nil {
+			return 0, fmt.Errorf("updating holdings for snapshot %d: %w", p.id, err)
+		}
+	}
+	return len(todo), nil
+}
+
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestExtractHoldings(t *testing.T) {
+	mtl := domain.AssetInfo{Code: "MTL", Issuer: domain.IssuerAddress, Type: domain.AssetTypeCreditAlphanum4}
+	fs := domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{
+			ID:         "GMAIN",
+			XLMBalance: "150.5000000",
+			Tokens: []domain.TokenPriceWithBalance{
+				{Asset: mtl, Balance: "1000.0000000"},
+				{Asset: domain.EURMTLAsset(), Balance: "0.0000000"},
+			},
+		}},
+		MutualFunds: []domain.FundAccountPortfolio{{
+			ID:         "GMUTUAL",
+			XLMBalance: "0",
+			Tokens:     []domain.TokenPriceWithBalance{{Asset: mtl, Balance: "5.0000000"}},
+		}},
+	}
+	data, _ := json.Marshal(fs)
+
+	raw, err := extractHoldings(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got map[string]map[string]string
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decoding holdings: %v", err)
+	}
+
+	mtlKey := AssetKey(mtl)
+	if got[mtlKey]["GMAIN"] != "1000.0000000" || got[mtlKey]["GMUTUAL"] != "5.0000000" {
+		t.Errorf("MTL holdings = %v, want both accounts", got[mtlKey])
+	}
+	if got["XLM"]["GMAIN"] != "150.5000000" {
+		t.Errorf("XLM holdings = %v, want GMAIN 150.5", got["XLM"])
+	}
+	if _, ok := got["XLM"]["GMUTUAL"]; ok {
+		t.Error("zero XLM balance must be omitted")
+	}
+	if _, ok := got[AssetKey(domain.EURMTLAsset())]; ok {
+		t.Error("zero-balance asset must be omitted")
+	}
+}
+
+func TestAssetKey(t *testing.T) {
+	if got := AssetKey(domain.XLMAsset()); got != "XLM" {
+		t.Errorf("AssetKey(XLM) = %q, want XLM", got)
+	}
+	if got := AssetKey(domain.EURMTLAsset()); got != "EURMTL-"+domain.EURMTLAsset().Issuer {
+		t.Errorf("AssetKey(EURMTL) = %q", got)
+	}
+}
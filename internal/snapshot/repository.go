@@ -2,25 +2,31 @@ package snapshot
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // ErrNotFound indicates that the requested snapshot was not found.
 var ErrNotFound = errors.New("snapshot not found")
 
+// ErrInvalidCursor indicates a malformed pagination cursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // Snapshot represents a stored fund snapshot.
 type Snapshot struct {
 	ID           int             `json:"id"`
 	EntityID     int             `json:"entityId"`
 	SnapshotDate time.Time       `json:"snapshotDate"`
-	Data         json.RawMessage `json:"data"`
+	Data         json.RawMessage `json:"data,omitempty"`
 	CreatedAt    time.Time       `json:"createdAt"`
+	Size         int             `json:"size,omitempty"` // stored bytes (delta size for delta rows); set by ListPage only
+	Seal
 }
 
 // SnapshotMeta holds snapshot metadata without the data payload.
@@ -29,6 +35,41 @@ type SnapshotMeta struct {
 	CreatedAt    time.Time `json:"createdAt"`
 }
 
+// ListQuery filters and pages a snapshot listing.
+type ListQuery struct {
+	From      time.Time // inclusive; zero means unbounded
+	To        time.Time // inclusive; zero means unbounded
+	Limit     int
+	Cursor    string // NextCursor from a previous page
+	Ascending bool
+	OmitData  bool // skip the JSONB payload, return metadata and size only
+}
+
+// Page is one page of a snapshot listing.
+type Page struct {
+	Snapshots  []Snapshot
+	Total      int    // rows matching From/To, regardless of cursor
+	NextCursor string // empty on the last page
+}
+
+// Snapshot dates are unique per entity, so the last date seen is a stable
+// keyset cursor. It is base64-wrapped to keep clients from depending on it.
+func encodeCursor(date time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(date.Format("2006-01-02")))
+}
+
+func decodeCursor(cursor string) (time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	date, err := time.Parse("2006-01-02", string(raw))
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return date, nil
+}
+
 // Repository defines persistent storage for snapshots.
 type Repository interface {
 	Save(ctx context.Context, entityID int, date time.Time, data json.RawMessage) error
@@ -36,43 +77,26 @@ type Repository interface {
 	GetByDate(ctx context.Context, entitySlug string, date time.Time) (*Snapshot, error)
 	GetNearestBefore(ctx context.Context, entitySlug string, date time.Time) (*Snapshot, error)
 	List(ctx context.Context, entitySlug string, limit int) ([]Snapshot, error)
+	ListPage(ctx context.Context, entitySlug string, q ListQuery) (*Page, error)
 	ListMeta(ctx context.Context, entitySlug string) ([]SnapshotMeta, error)
 	GetEntityID(ctx context.Context, slug string) (int, error)
 	EnsureEntity(ctx context.Context, slug, name, description string) (int, error)
 }
 
-// PgRepository implements Repository with PostgreSQL.
-type PgRepository struct {
-	pool *pgxpool.Pool
-}
-
-// NewPgRepository creates a new PostgreSQL snapshot repository.
-func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
-	return &PgRepository{pool: pool}
-}
-
-func (r *PgRepository) Save(ctx context.Context, entityID int, date time.Time, data json.RawMessage) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO fund_snapshots (entity_id, snapshot_date, data)
-		 VALUES ($1, $2, $3::jsonb)
-		 ON CONFLICT (entity_id, snapshot_date)
-		 DO UPDATE SET data = $3::jsonb`,
-		entityID, date, data)
-	if err != nil {
-		return fmt.Errorf("saving snapshot: %w", err)
-	}
-	return nil
-}
-
 func (r *PgRepository) GetLatest(ctx context.Context, entitySlug string) (*Snapshot, error) {
 	var s Snapshot
+	var baseData json.RawMessage
 	err := r.pool.QueryRow(ctx,
-		`SELECT fs.id, fs.entity_id, fs.snapshot_date, fs.data, fs.created_at
+		`SELECT `+snapshotColumns+`
 		 FROM fund_snapshots fs
 		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id
 		 WHERE fe.slug = $1
 		 ORDER BY fs.snapshot_date DESC
-		 LIMIT 1`, entitySlug).Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt)
+		 LIMIT 1`, entitySlug).Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt, &baseData, &s.Hash, &s.Signature, &s.Signer)
+	if err == nil {
+		err = s.reconstruct(baseData)
+	}
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -84,11 +108,16 @@ func (r *PgRepository) GetLatest(ctx context.Context, entitySlug string) (*Snaps
 
 func (r *PgRepository) GetByDate(ctx context.Context, entitySlug string, date time.Time) (*Snapshot, error) {
 	var s Snapshot
+	var baseData json.RawMessage
 	err := r.pool.QueryRow(ctx,
-		`SELECT fs.id, fs.entity_id, fs.snapshot_date, fs.data, fs.created_at
+		`SELECT `+snapshotColumns+`
 		 FROM fund_snapshots fs
 		 JOIN fund_entities fe ON fe.id = fs.entity_id
-		 WHERE fe.slug = $1 AND fs.snapshot_date = $2`, entitySlug, date).Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt)
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id
+		 WHERE fe.slug = $1 AND fs.snapshot_date = $2`, entitySlug, date).Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt, &baseData, &s.Hash, &s.Signature, &s.Signer)
+	if err == nil {
+		err = s.reconstruct(baseData)
+	}
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -101,13 +130,18 @@ func (r *PgRepository) GetByDate(ctx context.Context, entitySlug string, date ti
 // GetNearestBefore returns the most recent snapshot at or before the given date.
 func (r *PgRepository) GetNearestBefore(ctx context.Context, entitySlug string, date time.Time) (*Snapshot, error) {
 	var s Snapshot
+	var baseData json.RawMessage
 	err := r.pool.QueryRow(ctx,
-		`SELECT fs.id, fs.entity_id, fs.snapshot_date, fs.data, fs.created_at
+		`SELECT `+snapshotColumns+`
 		 FROM fund_snapshots fs
 		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id
 		 WHERE fe.slug = $1 AND fs.snapshot_date <= $2
 		 ORDER BY fs.snapshot_date DESC
-		 LIMIT 1`, entitySlug, date).Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt)
+		 LIMIT 1`, entitySlug, date).Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt, &baseData, &s.Hash, &s.Signature, &s.Signer)
+	if err == nil {
+		err = s.reconstruct(baseData)
+	}
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -123,9 +157,10 @@ func (r *PgRepository) List(ctx context.Context, entitySlug string, limit int) (
 	}
 
 	rows, err := r.pool.Query(ctx,
-		`SELECT fs.id, fs.entity_id, fs.snapshot_date, fs.data, fs.created_at
+		`SELECT `+snapshotColumns+`
 		 FROM fund_snapshots fs
 		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id
 		 WHERE fe.slug = $1
 		 ORDER BY fs.snapshot_date DESC
 		 LIMIT $2`, entitySlug, limit)
@@ -137,9 +172,13 @@ func (r *PgRepository) List(ctx context.Context, entitySlug string, limit int) (
 	var snapshots []Snapshot
 	for rows.Next() {
 		var s Snapshot
-		if err := rows.Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt); err != nil {
+		var baseData json.RawMessage
+		if err := rows.Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &s.CreatedAt, &baseData, &s.Hash, &s.Signature, &s.Signer); err != nil {
 			return nil, fmt.Errorf("scanning snapshot: %w", err)
 		}
+		if err := s.reconstruct(baseData); err != nil {
+			return nil, err
+		}
 		snapshots = append(snapshots, s)
 	}
 	if err := rows.Err(); err != nil {
@@ -148,6 +187,82 @@ func (r *PgRepository) List(ctx context.Context, entitySlug string, limit int) (
 	return snapshots, nil
 }
 
+// ListPage returns one page of snapshots matching q, plus the total match count.
+func (r *PgRepository) ListPage(ctx context.Context, entitySlug string, q ListQuery) (*Page, error) {
+	if q.Limit <= 0 {
+		q.Limit = 30
+	}
+
+	where := []string{"fe.slug = $1"}
+	args := []any{entitySlug}
+	if !q.From.IsZero() {
+		args = append(args, q.From)
+		where = append(where, fmt.Sprintf("fs.snapshot_date >= $%d", len(args)))
+	}
+	if !q.To.IsZero() {
+		args = append(args, q.To)
+		where = append(where, fmt.Sprintf("fs.snapshot_date <= $%d", len(args)))
+	}
+
+	const from = ` FROM fund_snapshots fs JOIN fund_entities fe ON fe.id = fs.entity_id
+		 LEFT JOIN fund_snapshots b ON b.id = fs.base_id WHERE `
+
+	var page Page
+	if err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*)`+from+strings.Join(where, " AND "), args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("counting snapshots: %w", err)
+	}
+
+	order, cmp := "DESC", "<"
+	if q.Ascending {
+		order, cmp = "ASC", ">"
+	}
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, after)
+		where = append(where, fmt.Sprintf("fs.snapshot_date %s $%d", cmp, len(args)))
+	}
+
+	dataCols := "fs.data, b.data"
+	if q.OmitData {
+		dataCols = "NULL::jsonb, NULL::jsonb"
+	}
+	args = append(args, q.Limit+1)
+	rows, err := r.pool.Query(ctx,
+		`SELECT fs.id, fs.entity_id, fs.snapshot_date, `+dataCols+`, fs.created_at, octet_length(fs.data::text), `+sealColumns+
+			from+strings.Join(where, " AND ")+
+			fmt.Sprintf(" ORDER BY fs.snapshot_date %s LIMIT $%d", order, len(args)),
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshot page: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Snapshot
+		var baseData json.RawMessage
+		if err := rows.Scan(&s.ID, &s.EntityID, &s.SnapshotDate, &s.Data, &baseData, &s.CreatedAt, &s.Size, &s.Hash, &s.Signature, &s.Signer); err != nil {
+			return nil, fmt.Errorf("scanning snapshot: %w", err)
+		}
+		if err := s.reconstruct(baseData); err != nil {
+			return nil, err
+		}
+		page.Snapshots = append(page.Snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating snapshots: %w", err)
+	}
+
+	if len(page.Snapshots) > q.Limit {
+		page.Snapshots = page.Snapshots[:q.Limit]
+		page.NextCursor = encodeCursor(page.Snapshots[q.Limit-1].SnapshotDate)
+	}
+	return &page, nil
+}
+
 func (r *PgRepository) ListMeta(ctx context.Context, entitySlug string) ([]SnapshotMeta, error) {
 	rows, err := r.pool.Query(ctx,
 		`SELECT fs.snapshot_date, fs.created_at
@@ -187,12 +302,29 @@ func (r *PgRepository) GetEntityID(ctx context.Context, slug string) (int, error
 	return id, nil
 }
 
+// GetEntity returns the entity with slug.
+func (r *PgRepository) GetEntity(ctx context.Context, slug string) (Entity, error) {
+	e := Entity{Slug: slug}
+	err := r.pool.QueryRow(ctx,
+		`SELECT name, COALESCE(description, '') FROM fund_entities WHERE slug = $1`, slug).Scan(&e.Name, &e.Description)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Entity{}, ErrNotFound
+		}
+		return Entity{}, fmt.Errorf("getting entity %s: %w", slug, err)
+	}
+	return e, nil
+}
+
+// EnsureEntity creates the entity if it doesn't exist and returns its ID. An
+// existing entity keeps its name and description, which operators manage
+// through the admin API.
 func (r *PgRepository) EnsureEntity(ctx context.Context, slug, name, description string) (int, error) {
 	var id int
 	err := r.pool.QueryRow(ctx,
 		`INSERT INTO fund_entities (slug, name, description)
 		 VALUES ($1, $2, $3)
-		 ON CONFLICT (slug) DO UPDATE SET name = $2, description = $3, updated_at = NOW()
+		 ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
 		 RETURNING id`,
 		slug, name, description).Scan(&id)
 	if err != nil {
@@ -0,0 +1,27 @@
+package snapshot
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	date := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	got, err := decodeCursor(encodeCursor(date))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Equal(date) {
+		t.Errorf("decoded = %v, want %v", got, date)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, c := range []string{"!!!", "bm90LWEtZGF0ZQ"} { // bad base64, "not-a-date"
+		if _, err := decodeCursor(c); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) err = %v, want ErrInvalidCursor", c, err)
+		}
+	}
+}
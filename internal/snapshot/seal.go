@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Signer signs snapshot seals. stellarkey.Keypair implements it.
+type Signer interface {
+	Address() string
+	Sign(msg []byte) []byte
+}
+
+// Seal is the integrity record stored with a snapshot: the SHA-256 of its
+// canonical JSON and, when a signer is configured, an ed25519 signature of
+// SealMessage by the signer's Stellar key.
+type Seal struct {
+	Hash      string `json:"hash,omitempty"`      // hex SHA-256 of Canonicalize(data)
+	Signature string `json:"signature,omitempty"` // base64 ed25519 signature of SealMessage
+	Signer    string `json:"signer,omitempty"`    // G... address of the signing key
+}
+
+// Canonicalize re-encodes a JSON document deterministically: object keys
+// sorted by their UTF-8 bytes, no insignificant whitespace, strings without
+// HTML escaping, numbers exactly as written. The stored JSONB reorders keys
+// and reformats whitespace, so hashes are always taken over this form.
+func Canonicalize(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decoding snapshot JSON: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil { // maps encode with sorted keys
+		return nil, fmt.Errorf("encoding canonical JSON: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Hash returns the hex SHA-256 of the canonical form of data.
+func Hash(data []byte) (string, error) {
+	canonical, err := Canonicalize(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SealMessage is the byte string a seal signature covers. It binds the hash
+// to the snapshot date so a signature can't be replayed for another day.
+func SealMessage(date time.Time, hash string) []byte {
+	return []byte("stat-snapshot:" + date.Format("2006-01-02") + ":" + hash)
+}
+
+// NewSeal hashes data and signs it with signer, if not nil.
+func NewSeal(date time.Time, data []byte, signer Signer) (Seal, error) {
+	hash, err := Hash(data)
+	if err != nil {
+		return Seal{}, err
+	}
+	seal := Seal{Hash: hash}
+	if signer != nil {
+		seal.Signature = base64.StdEncoding.EncodeToString(signer.Sign(SealMessage(date, hash)))
+		seal.Signer = signer.Address()
+	}
+	return seal, nil
+}
@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/stellarkey"
+)
+
+func TestCanonicalize(t *testing.T) {
+	got, err := Canonicalize([]byte(`{ "b": [1.50, {"y": 1, "x": "<&>"}],
+		"a": "é" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":"é","b":[1.50,{"x":"<&>","y":1}]}`
+	if string(got) != want {
+		t.Errorf("Canonicalize = %s, want %s", got, want)
+	}
+}
+
+func TestHashIgnoresFormatting(t *testing.T) {
+	a, err := Hash([]byte(`{"accounts":[],"aggregatedTotals":{"totalEURMTL":"10"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JSONB output: keys reordered, spaces after separators.
+	b, err := Hash([]byte(`{"aggregatedTotals": {"totalEURMTL": "10"}, "accounts": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b || len(a) != 64 {
+		t.Errorf("hashes = %s / %s, want equal hex SHA-256", a, b)
+	}
+	c, _ := Hash([]byte(`{"accounts":[],"aggregatedTotals":{"totalEURMTL":"11"}}`))
+	if c == a {
+		t.Error("changed value must change the hash")
+	}
+}
+
+func TestNewSealSigned(t *testing.T) {
+	date := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	kp, err := stellarkey.ParseSeed("SAVCUKRKFIVCUKRKFIVCUKRKFIVCUKRKFIVCUKRKFIVCUKRKFIVCVLG5") // 32 × 0x2a
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"accounts":[]}`)
+
+	seal, err := NewSeal(date, data, kp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(seal.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seal.Signer != kp.Address() {
+		t.Errorf("Signer = %s, want %s", seal.Signer, kp.Address())
+	}
+	if err := stellarkey.Verify(seal.Signer, SealMessage(date, seal.Hash), sig); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+	if err := stellarkey.Verify(seal.Signer, SealMessage(date.AddDate(0, 0, 1), seal.Hash), sig); err == nil {
+		t.Error("signature must not verify for another date")
+	}
+
+	unsigned, err := NewSeal(date, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Hash != seal.Hash || unsigned.Signature != "" || unsigned.Signer != "" {
+		t.Errorf("unsigned seal = %+v, want hash only", unsigned)
+	}
+	if !bytes.Equal(SealMessage(date, "ab"), []byte("stat-snapshot:2026-10-02:ab")) {
+		t.Errorf("SealMessage = %s", SealMessage(date, "ab"))
+	}
+}
@@ -7,7 +7,11 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/progress"
+	"github.com/mtlprog/stat/internal/quality"
+	"github.com/mtlprog/stat/internal/slo"
 )
 
 // FundStructureService defines the fund structure generation interface.
@@ -24,37 +28,56 @@ type MetricsEnricher interface {
 
 // Service manages snapshot generation and retrieval.
 type Service struct {
-	fund     FundStructureService
-	repo     Repository
-	enricher MetricsEnricher
+	fund      FundStructureService
+	repo      Repository
+	enrichers []MetricsEnricher
 }
 
-// NewService creates a new SnapshotService. An optional MetricsEnricher can be provided
-// to store live metrics (I10, I6/I7, I11) in each snapshot for historical comparison.
+// NewService creates a new SnapshotService. Optional MetricsEnrichers store live
+// metrics (I10, I6/I7, I11, peer comparisons) in each snapshot for historical
+// comparison; they run in the order given.
 func NewService(fund FundStructureService, repo Repository, enrichers ...MetricsEnricher) *Service {
-	var enricher MetricsEnricher
-	if len(enrichers) > 0 {
-		enricher = enrichers[0]
-	}
-	return &Service{fund: fund, repo: repo, enricher: enricher}
+	return &Service{fund: fund, repo: repo, enrichers: enrichers}
 }
 
 // Generate creates a new snapshot for the given entity slug and date.
+//
+// Progress is reported through the progress package. If ctx is cancelled at any
+// point before the save, nothing is persisted: enrichment swallows its own errors
+// and would otherwise let a half-enriched snapshot through, so the context is
+// checked again right before writing. The stored data carries a quality.Assess
+// score taken at generation time (or at the as-of time of a backfill).
 func (s *Service) Generate(ctx context.Context, slug string, date time.Time) (domain.FundStructureData, error) {
+	return s.generate(ctx, slug, date, s.enrichers)
+}
+
+// GenerateAsOf creates the snapshot of a past date with the fund structure
+// resolved as of at (asof.With): historical balances, that day's trade
+// aggregation close and stored quote history. The enrichers are skipped
+// because they only measure the present.
+func (s *Service) GenerateAsOf(ctx context.Context, slug string, date, at time.Time) (domain.FundStructureData, error) {
+	return s.generate(asof.With(ctx, at), slug, date, nil)
+}
+
+// Preview builds the snapshot of date like Generate, or like GenerateAsOf
+// when ctx carries an as-of time (asof.With), without saving it.
+func (s *Service) Preview(ctx context.Context, date time.Time) (domain.FundStructureData, error) {
+	enrichers := s.enrichers
+	if _, ok := asof.From(ctx); ok {
+		enrichers = nil
+	}
+	return s.build(ctx, date, enrichers)
+}
+
+func (s *Service) generate(ctx context.Context, slug string, date time.Time, enrichers []MetricsEnricher) (domain.FundStructureData, error) {
 	entityID, err := s.repo.GetEntityID(ctx, slug)
 	if err != nil {
 		return domain.FundStructureData{}, fmt.Errorf("getting entity: %w", err)
 	}
 
-	fundData, err := s.fund.GetFundStructure(ctx)
+	fundData, err := s.build(ctx, date, enrichers)
 	if err != nil {
-		return domain.FundStructureData{}, fmt.Errorf("generating fund structure: %w", err)
-	}
-
-	if s.enricher != nil {
-		if err := s.enricher.EnrichMetrics(ctx, date, &fundData); err != nil {
-			slog.Error("failed to enrich snapshot with live metrics", "error", err)
-		}
+		return domain.FundStructureData{}, err
 	}
 
 	data, err := json.Marshal(fundData)
@@ -62,10 +85,46 @@ func (s *Service) Generate(ctx context.Context, slug string, date time.Time) (do
 		return domain.FundStructureData{}, fmt.Errorf("marshaling fund data: %w", err)
 	}
 
+	progress.Report(ctx, progress.Event{Stage: progress.StagePersist})
+	saveStart := time.Now()
 	if err := s.repo.Save(ctx, entityID, date, data); err != nil {
 		return domain.FundStructureData{}, fmt.Errorf("saving snapshot: %w", err)
 	}
+	slo.Since(ctx, slo.StagePersistence, saveStart)
+
+	return fundData, nil
+}
+
+// build generates the fund structure of date, enriched and scored.
+func (s *Service) build(ctx context.Context, date time.Time, enrichers []MetricsEnricher) (domain.FundStructureData, error) {
+	fundData, err := s.fund.GetFundStructure(ctx)
+	if err != nil {
+		return domain.FundStructureData{}, fmt.Errorf("generating fund structure: %w", err)
+	}
 
+	if len(enrichers) > 0 {
+		progress.Report(ctx, progress.Event{Stage: progress.StageMetrics})
+	}
+	enrichStart := time.Now()
+	for _, e := range enrichers {
+		if err := e.EnrichMetrics(ctx, date, &fundData); err != nil {
+			slog.Error("failed to enrich snapshot with live metrics", "error", err)
+		}
+	}
+	slo.Since(ctx, slo.StageMetrics, enrichStart)
+
+	if err := ctx.Err(); err != nil {
+		return domain.FundStructureData{}, fmt.Errorf("snapshot not saved: %w", err)
+	}
+
+	at := time.Now()
+	if t, ok := asof.From(ctx); ok {
+		at = t
+	}
+	q := quality.Assess(fundData, at)
+	fundData.Quality = &q
+	slog.Info("snapshot data quality", "date", date.Format("2006-01-02"), "score", q.Score.String(),
+		"priced", q.PricedCount, "tokens", q.TokenCount, "staleQuotes", q.StaleQuotes, "metricFallbacks", q.MetricFallbacks)
 	return fundData, nil
 }
 
@@ -89,6 +148,11 @@ func (s *Service) List(ctx context.Context, slug string, limit int) ([]Snapshot,
 	return s.repo.List(ctx, slug, limit)
 }
 
+// ListPage retrieves a filtered, cursor-paginated page of snapshots.
+func (s *Service) ListPage(ctx context.Context, slug string, q ListQuery) (*Page, error) {
+	return s.repo.ListPage(ctx, slug, q)
+}
+
 // ListMeta retrieves all snapshot metadata (date + createdAt) without the data payload.
 func (s *Service) ListMeta(ctx context.Context, slug string) ([]SnapshotMeta, error) {
 	return s.repo.ListMeta(ctx, slug)
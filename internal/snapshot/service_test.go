@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mtlprog/stat/internal/asof"
 	"github.com/mtlprog/stat/internal/domain"
 )
 
@@ -64,6 +65,13 @@ func (m *mockRepo) List(_ context.Context, _ string, _ int) ([]Snapshot, error)
 	return m.list, m.listErr
 }
 
+func (m *mockRepo) ListPage(_ context.Context, _ string, _ ListQuery) (*Page, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return &Page{Snapshots: m.list, Total: len(m.list)}, nil
+}
+
 func (m *mockRepo) GetEntityID(_ context.Context, _ string) (int, error) {
 	return m.entityID, m.entityErr
 }
@@ -100,6 +108,28 @@ func TestGenerateSuccess(t *testing.T) {
 	}
 }
 
+func TestGenerateStoresQuality(t *testing.T) {
+	v := "1"
+	fund := &mockFundService{data: domain.FundStructureData{
+		Accounts: []domain.FundAccountPortfolio{{Tokens: []domain.TokenPriceWithBalance{
+			{Asset: domain.AssetInfo{Code: "MTL"}, ValueInEURMTL: &v},
+			{Asset: domain.AssetInfo{Code: "DEAD"}},
+		}}},
+	}}
+	repo := &mockRepo{entityID: 1}
+
+	if _, err := NewService(fund, repo).Generate(context.Background(), "mtlf", time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var saved domain.FundStructureData
+	if err := json.Unmarshal(repo.savedData, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Quality == nil || saved.Quality.Score.String() != "50" || saved.Quality.TokenCount != 2 {
+		t.Errorf("saved quality = %+v, want score 50 over 2 tokens", saved.Quality)
+	}
+}
+
 func TestGenerateFundServiceError(t *testing.T) {
 	repo := &mockRepo{entityID: 1}
 	fund := &mockFundService{err: errors.New("fund service error")}
@@ -132,3 +162,102 @@ func TestGenerateEntityNotFound(t *testing.T) {
 		t.Fatal("expected error for unknown entity")
 	}
 }
+
+// cancellingEnricher cancels the run mid-enrichment, as a SIGINT would.
+type cancellingEnricher struct {
+	cancel context.CancelFunc
+}
+
+func (e *cancellingEnricher) EnrichMetrics(_ context.Context, _ time.Time, _ *domain.FundStructureData) error {
+	e.cancel()
+	return nil
+}
+
+func TestGenerateCancelledDoesNotSave(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &mockRepo{entityID: 1}
+	fund := &mockFundService{data: domain.FundStructureData{}}
+	svc := NewService(fund, repo, &cancellingEnricher{cancel: cancel})
+
+	_, err := svc.Generate(ctx, "mtlf", time.Now())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if repo.savedData != nil {
+		t.Error("cancelled run must not save a snapshot")
+	}
+}
+
+// peerEnricher stands in for a second enricher that adds to the data.
+type peerEnricher struct{ err error }
+
+func (e peerEnricher) EnrichMetrics(_ context.Context, _ time.Time, data *domain.FundStructureData) error {
+	if e.err != nil {
+		return e.err
+	}
+	data.Peers = append(data.Peers, domain.PeerMetrics{Name: "PEER"})
+	return nil
+}
+
+func TestGenerateRunsAllEnrichers(t *testing.T) {
+	repo := &mockRepo{entityID: 1}
+	fund := &mockFundService{data: domain.FundStructureData{}}
+	svc := NewService(fund, repo, peerEnricher{err: errors.New("horizon down")}, peerEnricher{})
+
+	result, err := svc.Generate(context.Background(), "mtlf", time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Peers) != 1 {
+		t.Errorf("got %d peers, want 1: a failing enricher must not stop the next", len(result.Peers))
+	}
+}
+
+type asOfFundService struct {
+	at time.Time
+}
+
+func (m *asOfFundService) GetFundStructure(ctx context.Context) (domain.FundStructureData, error) {
+	m.at, _ = asof.From(ctx)
+	return domain.FundStructureData{}, nil
+}
+
+func TestGenerateAsOfSkipsEnrichers(t *testing.T) {
+	repo := &mockRepo{entityID: 1}
+	fund := &asOfFundService{}
+	svc := NewService(fund, repo, peerEnricher{})
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := date.AddDate(0, 0, 1).Add(-time.Second)
+
+	result, err := svc.GenerateAsOf(context.Background(), "mtlf", date, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fund.at.Equal(at) {
+		t.Errorf("fund structure resolved as of %s, want %s", fund.at, at)
+	}
+	if len(result.Peers) != 0 {
+		t.Errorf("got %d peers, want none: enrichers only measure the present", len(result.Peers))
+	}
+	if !repo.savedDate.Equal(date) {
+		t.Errorf("saved date = %s, want %s", repo.savedDate, date)
+	}
+}
+
+func TestPreviewDoesNotSave(t *testing.T) {
+	repo := &mockRepo{entityErr: ErrNotFound}
+	svc := NewService(&mockFundService{}, repo, peerEnricher{})
+
+	result, err := svc.Preview(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Peers) != 1 || result.Quality == nil {
+		t.Errorf("preview = %d peers, quality %v; want enriched and scored", len(result.Peers), result.Quality)
+	}
+	if repo.savedData != nil {
+		t.Error("preview must not save a snapshot")
+	}
+}
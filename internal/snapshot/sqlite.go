@@ -0,0 +1,244 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sqliteTime is the layout of the SQLite timestamp columns: RFC 3339 in UTC
+// with a fixed-width fraction, so they sort as text.
+const sqliteTime = "2006-01-02T15:04:05.000000000Z"
+
+// SQLiteRepository implements Repository with SQLite, for local runs
+// without PostgreSQL (DATABASE_URL=sqlite://...). Snapshots are stored
+// whole and sealed with their hash; delta storage, signing, detached
+// details, the save hook and the holdings and balance indexes are
+// PostgreSQL only.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite snapshot repository. The schema
+// is migrations.SQLite.
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+const sqliteSnapshotColumns = `fs.id, fs.entity_id, fs.snapshot_date, fs.data, fs.created_at,
+	COALESCE(fs.hash, ''), COALESCE(fs.signature, ''), COALESCE(fs.signer, '')`
+
+func (r *SQLiteRepository) Save(ctx context.Context, entityID int, date time.Time, data json.RawMessage) error {
+	seal, err := NewSeal(date, data, nil)
+	if err != nil {
+		return fmt.Errorf("sealing snapshot: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO fund_snapshots (entity_id, snapshot_date, data, hash, created_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (entity_id, snapshot_date)
+		 DO UPDATE SET data = excluded.data, hash = excluded.hash, signature = NULL, signer = NULL`,
+		entityID, date.Format("2006-01-02"), string(data), seal.Hash, time.Now().UTC().Format(sqliteTime)); err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+	return nil
+}
+
+// scanSQLiteSnapshot scans a row of sqliteSnapshotColumns, plus size when
+// it isn't nil.
+func scanSQLiteSnapshot(row interface{ Scan(...any) error }, size *int) (Snapshot, error) {
+	var s Snapshot
+	var date, createdAt string
+	dest := []any{&s.ID, &s.EntityID, &date, (*[]byte)(&s.Data), &createdAt, &s.Hash, &s.Signature, &s.Signer}
+	if size != nil {
+		dest = append(dest, size)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return Snapshot{}, err
+	}
+	var err error
+	if s.SnapshotDate, err = time.Parse("2006-01-02", date); err != nil {
+		return Snapshot{}, fmt.Errorf("parsing snapshot date %q: %w", date, err)
+	}
+	if s.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return Snapshot{}, fmt.Errorf("parsing snapshot created_at %q: %w", createdAt, err)
+	}
+	return s, nil
+}
+
+// getOne returns the first snapshot of the query, ErrNotFound without one.
+func (r *SQLiteRepository) getOne(ctx context.Context, what, where string, args ...any) (*Snapshot, error) {
+	s, err := scanSQLiteSnapshot(r.db.QueryRowContext(ctx,
+		`SELECT `+sqliteSnapshotColumns+`
+		 FROM fund_snapshots fs
+		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 WHERE `+where+`
+		 ORDER BY fs.snapshot_date DESC
+		 LIMIT 1`, args...), nil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting %s: %w", what, err)
+	}
+	return &s, nil
+}
+
+func (r *SQLiteRepository) GetLatest(ctx context.Context, entitySlug string) (*Snapshot, error) {
+	return r.getOne(ctx, "latest snapshot", "fe.slug = ?", entitySlug)
+}
+
+func (r *SQLiteRepository) GetByDate(ctx context.Context, entitySlug string, date time.Time) (*Snapshot, error) {
+	return r.getOne(ctx, "snapshot by date", "fe.slug = ? AND fs.snapshot_date = ?", entitySlug, date.Format("2006-01-02"))
+}
+
+// GetNearestBefore returns the most recent snapshot at or before the given date.
+func (r *SQLiteRepository) GetNearestBefore(ctx context.Context, entitySlug string, date time.Time) (*Snapshot, error) {
+	return r.getOne(ctx, "nearest snapshot before "+date.Format("2006-01-02"),
+		"fe.slug = ? AND fs.snapshot_date <= ?", entitySlug, date.Format("2006-01-02"))
+}
+
+func (r *SQLiteRepository) List(ctx context.Context, entitySlug string, limit int) ([]Snapshot, error) {
+	page, err := r.ListPage(ctx, entitySlug, ListQuery{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return page.Snapshots, nil
+}
+
+// ListPage returns one page of snapshots matching q, plus the total match count.
+func (r *SQLiteRepository) ListPage(ctx context.Context, entitySlug string, q ListQuery) (*Page, error) {
+	if q.Limit <= 0 {
+		q.Limit = 30
+	}
+
+	where := []string{"fe.slug = ?"}
+	args := []any{entitySlug}
+	if !q.From.IsZero() {
+		where = append(where, "fs.snapshot_date >= ?")
+		args = append(args, q.From.Format("2006-01-02"))
+	}
+	if !q.To.IsZero() {
+		where = append(where, "fs.snapshot_date <= ?")
+		args = append(args, q.To.Format("2006-01-02"))
+	}
+
+	const from = ` FROM fund_snapshots fs JOIN fund_entities fe ON fe.id = fs.entity_id WHERE `
+
+	var page Page
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*)`+from+strings.Join(where, " AND "), args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("counting snapshots: %w", err)
+	}
+
+	order, cmp := "DESC", "<"
+	if q.Ascending {
+		order, cmp = "ASC", ">"
+	}
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, "fs.snapshot_date "+cmp+" ?")
+		args = append(args, after.Format("2006-01-02"))
+	}
+
+	cols := sqliteSnapshotColumns
+	if q.OmitData {
+		cols = strings.Replace(cols, "fs.data", "NULL", 1)
+	}
+	args = append(args, q.Limit+1)
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+cols+`, length(CAST(fs.data AS BLOB))`+from+strings.Join(where, " AND ")+
+			" ORDER BY fs.snapshot_date "+order+" LIMIT ?", args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshot page: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var size int
+		s, err := scanSQLiteSnapshot(rows, &size)
+		if err != nil {
+			return nil, fmt.Errorf("scanning snapshot: %w", err)
+		}
+		s.Size = size
+		page.Snapshots = append(page.Snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating snapshots: %w", err)
+	}
+
+	if len(page.Snapshots) > q.Limit {
+		page.Snapshots = page.Snapshots[:q.Limit]
+		page.NextCursor = encodeCursor(page.Snapshots[q.Limit-1].SnapshotDate)
+	}
+	return &page, nil
+}
+
+func (r *SQLiteRepository) ListMeta(ctx context.Context, entitySlug string) ([]SnapshotMeta, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT fs.snapshot_date, fs.created_at
+		 FROM fund_snapshots fs
+		 JOIN fund_entities fe ON fe.id = fs.entity_id
+		 WHERE fe.slug = ?
+		 ORDER BY fs.snapshot_date DESC`, entitySlug)
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshot meta: %w", err)
+	}
+	defer rows.Close()
+
+	var metas []SnapshotMeta
+	for rows.Next() {
+		var date, createdAt string
+		if err := rows.Scan(&date, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning snapshot meta: %w", err)
+		}
+		var m SnapshotMeta
+		if m.SnapshotDate, err = time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("parsing snapshot date %q: %w", date, err)
+		}
+		if m.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parsing snapshot created_at %q: %w", createdAt, err)
+		}
+		metas = append(metas, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating snapshot meta: %w", err)
+	}
+	return metas, nil
+}
+
+func (r *SQLiteRepository) GetEntityID(ctx context.Context, slug string) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id FROM fund_entities WHERE slug = ?`, slug).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("getting entity ID for %s: %w", slug, err)
+	}
+	return id, nil
+}
+
+// EnsureEntity creates the entity if it doesn't exist and returns its ID. An
+// existing entity keeps its name and description.
+func (r *SQLiteRepository) EnsureEntity(ctx context.Context, slug, name, description string) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO fund_entities (slug, name, description)
+		 VALUES (?, ?, ?)
+		 ON CONFLICT (slug) DO UPDATE SET slug = excluded.slug
+		 RETURNING id`,
+		slug, name, description).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ensuring entity %s: %w", slug, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/migrations"
+)
+
+func newSQLiteRepo(t *testing.T) *SQLiteRepository {
+	t.Helper()
+	ctx := context.Background()
+	db, err := database.OpenSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	schema, err := fs.Sub(migrations.SQLite, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.RunSQLiteMigrations(ctx, db, schema); err != nil {
+		t.Fatal(err)
+	}
+	return NewSQLiteRepository(db)
+}
+
+func TestSQLiteRepositoryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+
+	if _, err := repo.GetLatest(ctx, "mtlf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetLatest on empty store err = %v, want ErrNotFound", err)
+	}
+	id, err := repo.EnsureEntity(ctx, "mtlf", "Montelibero Fund", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := repo.EnsureEntity(ctx, "mtlf", "renamed", ""); err != nil || again != id {
+		t.Fatalf("EnsureEntity again = %d, %v; want %d", again, err, id)
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	for d := 1; d <= 3; d++ {
+		if err := repo.Save(ctx, id, day(d), json.RawMessage(fmt.Sprintf(`{"day":%d}`, d))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Save(ctx, id, day(2), json.RawMessage(`{"day":22}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := repo.GetLatest(ctx, "mtlf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !latest.SnapshotDate.Equal(day(3)) || string(latest.Data) != `{"day":3}` || latest.Hash == "" {
+		t.Errorf("latest = %s %s hash %q, want 2026-03-03 {\"day\":3} with a hash", latest.SnapshotDate, latest.Data, latest.Hash)
+	}
+	got, err := repo.GetNearestBefore(ctx, "mtlf", day(2).Add(12*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Data) != `{"day":22}` {
+		t.Errorf("nearest before 2026-03-02 = %s, want the overwritten {\"day\":22}", got.Data)
+	}
+	if _, err := repo.GetByDate(ctx, "mtlf", day(4)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByDate of a missing date err = %v, want ErrNotFound", err)
+	}
+
+	page, err := repo.ListPage(ctx, "mtlf", ListQuery{Limit: 2, Ascending: true, OmitData: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Snapshots) != 2 || page.NextCursor == "" || page.Snapshots[0].Data != nil || page.Snapshots[0].Size == 0 {
+		t.Fatalf("first page = %+v, want 2 of 3 without data, with sizes and a cursor", page)
+	}
+	next, err := repo.ListPage(ctx, "mtlf", ListQuery{Limit: 2, Ascending: true, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.Snapshots) != 1 || !next.Snapshots[0].SnapshotDate.Equal(day(3)) || next.NextCursor != "" {
+		t.Errorf("second page = %+v, want the last snapshot only", next)
+	}
+
+	metas, err := repo.ListMeta(ctx, "mtlf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metas) != 3 || !metas[0].SnapshotDate.Equal(day(3)) {
+		t.Errorf("ListMeta = %+v, want 3 dates newest first", metas)
+	}
+}
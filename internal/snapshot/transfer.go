@@ -0,0 +1,89 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// TransferFormat is the version of the file written by `stat snapshot export`.
+const TransferFormat = 1
+
+// ErrInvalidTransfer indicates a transfer file that can't be imported as is.
+var ErrInvalidTransfer = errors.New("invalid snapshot transfer file")
+
+// Entity is a row of fund_entities without its database ID.
+type Entity struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Transfer is one snapshot packaged for copying between databases. The
+// entity is referenced by slug because IDs differ between databases; Hash is
+// the snapshot seal hash, checked again on import.
+type Transfer struct {
+	Format    int             `json:"format"`
+	Entity    Entity          `json:"entity"`
+	Date      string          `json:"date"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Hash      string          `json:"hash"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewTransfer packages s, which belongs to entity. Snapshots stored before
+// seals existed are hashed here.
+func NewTransfer(entity Entity, s *Snapshot) (*Transfer, error) {
+	hash := s.Hash
+	if hash == "" {
+		var err error
+		if hash, err = Hash(s.Data); err != nil {
+			return nil, err
+		}
+	}
+	return &Transfer{
+		Format:    TransferFormat,
+		Entity:    entity,
+		Date:      s.SnapshotDate.Format("2006-01-02"),
+		CreatedAt: s.CreatedAt,
+		Hash:      hash,
+		Data:      s.Data,
+	}, nil
+}
+
+// ReadTransfer decodes a transfer file and checks its format, date and that
+// the data still matches the hash.
+func ReadTransfer(r io.Reader) (*Transfer, error) {
+	var t Transfer
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransfer, err)
+	}
+	if t.Format != TransferFormat {
+		return nil, fmt.Errorf("%w: format %d, want %d", ErrInvalidTransfer, t.Format, TransferFormat)
+	}
+	if _, err := t.SnapshotDate(); err != nil {
+		return nil, err
+	}
+	if t.Entity.Slug == "" || len(t.Data) == 0 {
+		return nil, fmt.Errorf("%w: missing entity or data", ErrInvalidTransfer)
+	}
+	hash, err := Hash(t.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTransfer, err)
+	}
+	if hash != t.Hash {
+		return nil, fmt.Errorf("%w: data hash %s does not match %s", ErrInvalidTransfer, hash, t.Hash)
+	}
+	return &t, nil
+}
+
+// SnapshotDate returns Date as midnight UTC.
+func (t *Transfer) SnapshotDate() (time.Time, error) {
+	date, err := time.Parse("2006-01-02", t.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q", ErrInvalidTransfer, t.Date)
+	}
+	return date, nil
+}
@@ -0,0 +1,61 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransferRoundTrip(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &Snapshot{SnapshotDate: date, CreatedAt: date.Add(time.Hour), Data: json.RawMessage(`{"b":1,"a":[2]}`)}
+	entity := Entity{Slug: "mtlf", Name: "Montelibero Fund"}
+
+	tr, err := NewTransfer(entity, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := Hash(s.Data); tr.Hash != want {
+		t.Errorf("hash = %s, want %s (computed for unsealed snapshots)", tr.Hash, want)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(tr); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadTransfer(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotDate, _ := got.SnapshotDate()
+	if got.Entity != entity || !gotDate.Equal(date) || got.Hash != tr.Hash {
+		t.Errorf("read back %+v", got)
+	}
+}
+
+func TestReadTransferRejects(t *testing.T) {
+	hash, _ := Hash([]byte(`{"a":1}`))
+	valid := Transfer{Format: TransferFormat, Entity: Entity{Slug: "mtlf"}, Date: "2026-03-01", Hash: hash, Data: json.RawMessage(`{"a":1}`)}
+
+	tests := map[string]func(*Transfer){
+		"format":   func(t *Transfer) { t.Format = 99 },
+		"date":     func(t *Transfer) { t.Date = "01.03.2026" },
+		"entity":   func(t *Transfer) { t.Entity.Slug = "" },
+		"tampered": func(t *Transfer) { t.Data = json.RawMessage(`{"a":2}`) },
+	}
+	for name, mutate := range tests {
+		tr := valid
+		mutate(&tr)
+		data, _ := json.Marshal(tr)
+		if _, err := ReadTransfer(bytes.NewReader(data)); !errors.Is(err, ErrInvalidTransfer) {
+			t.Errorf("%s: err = %v, want ErrInvalidTransfer", name, err)
+		}
+	}
+
+	data, _ := json.Marshal(valid)
+	if _, err := ReadTransfer(bytes.NewReader(data)); err != nil {
+		t.Errorf("valid transfer rejected: %v", err)
+	}
+}
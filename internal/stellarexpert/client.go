@@ -16,6 +16,8 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/fault"
 )
 
 // EURMTLAssetID is the asset identifier stellar.expert uses for the EURMTL
@@ -60,13 +62,26 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// Option configures NewClient.
+type Option func(*Client)
+
+// WithTransport sends requests through rt, normally the shared
+// transport.Transport, instead of a connection pool of the client's own.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.httpClient.Transport = rt }
+}
+
 // NewClient creates a Client. baseURL should be the API root, e.g.
 // "https://api.stellar.expert".
-func NewClient(baseURL string) *Client {
-	return &Client{
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
 		baseURL:    baseURL,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type historyPoint struct {
@@ -95,7 +110,7 @@ func (c *Client) FetchEURMTLPaymentStats(ctx context.Context, date time.Time) (S
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return Stats{}, fmt.Errorf("calling stats-history: %w", err)
+		return Stats{}, fault.New("stellarexpert", fault.KindOf(err), fmt.Errorf("calling stats-history: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -109,7 +124,8 @@ func (c *Client) FetchEURMTLPaymentStats(ctx context.Context, date time.Time) (S
 		if len(preview) > 1024 {
 			preview = preview[:1024]
 		}
-		return Stats{}, fmt.Errorf("stats-history returned %s: %s", resp.Status, string(preview))
+		return Stats{}, fault.FromResponse("stellarexpert", resp.StatusCode, resp.Header,
+			fmt.Errorf("stats-history returned %s: %s", resp.Status, string(preview)))
 	}
 
 	var pts []historyPoint
@@ -0,0 +1,117 @@
+// Package stellarkey decodes Stellar strkey-encoded keys (S... seeds and
+// G... account addresses) and signs and verifies with them. Only what the
+// snapshot seal and the dividend payout transactions need is implemented; it
+// is not a general SDK replacement.
+package stellarkey
+
+import (
+	"crypto/ed25519"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Strkey version bytes (https://stellar.org/protocol/sep-23).
+const (
+	versionAccountID byte = 6 << 3  // G...
+	versionSeed      byte = 18 << 3 // S...
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidSignature is returned by Verify when the signature doesn't match.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Keypair is an ed25519 key loaded from a Stellar secret seed.
+type Keypair struct {
+	priv    ed25519.PrivateKey
+	address string
+}
+
+// ParseSeed decodes an S... secret seed.
+func ParseSeed(seed string) (*Keypair, error) {
+	raw, err := decode(versionSeed, seed)
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret seed: %w", err)
+	}
+	priv := ed25519.NewKeyFromSeed(raw)
+	return &Keypair{
+		priv:    priv,
+		address: encode(versionAccountID, priv.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+// Address returns the G... account address of the key.
+func (k *Keypair) Address() string { return k.address }
+
+// Sign returns the ed25519 signature of msg.
+func (k *Keypair) Sign(msg []byte) []byte { return ed25519.Sign(k.priv, msg) }
+
+// Verify checks sig over msg against a G... account address.
+func Verify(address string, msg, sig []byte) error {
+	pub, err := decode(versionAccountID, address)
+	if err != nil {
+		return fmt.Errorf("decoding address: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), msg, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ValidAddress reports whether s is a well-formed G... account address.
+func ValidAddress(s string) bool {
+	_, err := decode(versionAccountID, s)
+	return err == nil
+}
+
+// PublicKey returns the 32-byte ed25519 key of a G... account address.
+func PublicKey(address string) ([]byte, error) {
+	pub, err := decode(versionAccountID, address)
+	if err != nil {
+		return nil, fmt.Errorf("decoding address: %w", err)
+	}
+	return pub, nil
+}
+
+// decode returns the 32-byte payload of a strkey with the given version,
+// checking the CRC16 checksum.
+func decode(version byte, s string) ([]byte, error) {
+	raw, err := encoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32: %w", err)
+	}
+	if len(raw) != 1+32+2 {
+		return nil, fmt.Errorf("invalid length %d", len(raw))
+	}
+	if raw[0] != version {
+		return nil, fmt.Errorf("unexpected version byte %d, want %d", raw[0], version)
+	}
+	body, sum := raw[:33], binary.LittleEndian.Uint16(raw[33:])
+	if crc16(body) != sum {
+		return nil, errors.New("checksum mismatch")
+	}
+	return body[1:], nil
+}
+
+func encode(version byte, payload []byte) string {
+	body := append([]byte{version}, payload...)
+	return encoding.EncodeToString(binary.LittleEndian.AppendUint16(body, crc16(body)))
+}
+
+// crc16 is CRC-16/XMODEM (poly 0x1021, init 0), as used by strkey.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,50 @@
+package stellarkey
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+func TestCRC16(t *testing.T) {
+	if got := crc16([]byte("123456789")); got != 0x31C3 {
+		t.Errorf("crc16 = %#x, want 0x31c3 (XMODEM check value)", got)
+	}
+}
+
+func TestDecodeKnownAddress(t *testing.T) {
+	if _, err := decode(versionAccountID, domain.IssuerAddress); err != nil {
+		t.Errorf("decoding the issuer address: %v", err)
+	}
+	if _, err := decode(versionSeed, domain.IssuerAddress); err == nil {
+		t.Error("a G... address must not decode as a seed")
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	seed := encode(versionSeed, bytes.Repeat([]byte{7}, 32))
+	kp, err := ParseSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kp.Address()[0] != 'G' || len(kp.Address()) != 56 {
+		t.Fatalf("Address = %q, want a G... strkey", kp.Address())
+	}
+
+	msg := []byte("stat-snapshot:2026-10-02:abc")
+	sig := kp.Sign(msg)
+	if err := Verify(kp.Address(), msg, sig); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := Verify(kp.Address(), []byte("tampered"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify tampered = %v, want ErrInvalidSignature", err)
+	}
+
+	corrupt := []byte(seed)
+	corrupt[10] ^= 1
+	if _, err := ParseSeed(string(corrupt)); err == nil {
+		t.Error("corrupted seed must fail the checksum")
+	}
+}
@@ -0,0 +1,88 @@
+// Package storage opens the database named by DATABASE_URL, picking the
+// backend from its scheme: SQLite for sqlite://PATH (sqlite://:memory: for a
+// throwaway one), PostgreSQL for anything else (postgres:// URLs and pgx
+// keyword/value strings alike). Only the snapshot and quote repositories
+// have a SQLite implementation, which is enough for `stat quote` and
+// `stat report --dry-run`; everything else needs Pool.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mtlprog/stat/internal/database"
+	"github.com/mtlprog/stat/internal/external"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/migrations"
+)
+
+// Store is an open database with its migrations applied.
+type Store struct {
+	Snapshots snapshot.Repository
+	Quotes    external.QuoteRepository
+	Pool      *pgxpool.Pool // nil on SQLite
+
+	db *sql.DB // nil on PostgreSQL
+}
+
+// IsSQLite reports whether databaseURL selects the SQLite backend.
+func IsSQLite(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, "sqlite://")
+}
+
+// Open connects to databaseURL and applies the backend's migrations.
+func Open(ctx context.Context, databaseURL string) (*Store, error) {
+	if !IsSQLite(databaseURL) {
+		pool, err := database.Connect(ctx, databaseURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := database.RunMigrations(ctx, pool, migrations.FS); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("running migrations: %w", err)
+		}
+		return &Store{
+			Snapshots: snapshot.NewPgRepository(pool),
+			Quotes:    external.NewPgQuoteRepository(pool),
+			Pool:      pool,
+		}, nil
+	}
+
+	path := strings.TrimPrefix(databaseURL, "sqlite://")
+	if path == "" {
+		return nil, fmt.Errorf("DATABASE_URL %q names no SQLite file", databaseURL)
+	}
+	db, err := database.OpenSQLite(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := fs.Sub(migrations.SQLite, "sqlite")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("reading SQLite migrations: %w", err)
+	}
+	if err := database.RunSQLiteMigrations(ctx, db, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("running migrations: %w", err)
+	}
+	return &Store{
+		Snapshots: snapshot.NewSQLiteRepository(db),
+		Quotes:    external.NewSQLiteQuoteRepository(db),
+		db:        db,
+	}, nil
+}
+
+// Close closes the connection pool or database.
+func (s *Store) Close() {
+	if s.Pool != nil {
+		s.Pool.Close()
+	}
+	if s.db != nil {
+		s.db.Close()
+	}
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenSQLite(t *testing.T) {
+	ctx := context.Background()
+	url := "sqlite://" + filepath.Join(t.TempDir(), "stat.db")
+
+	// Reopening finds the schema already applied.
+	for range 2 {
+		s, err := Open(ctx, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Pool != nil {
+			t.Error("Pool is set on SQLite")
+		}
+		if _, err := s.Snapshots.EnsureEntity(ctx, "mtlf", "Montelibero Fund", ""); err != nil {
+			t.Error(err)
+		}
+		if _, err := s.Quotes.GetAllQuotes(ctx); err != nil {
+			t.Error(err)
+		}
+		s.Close()
+	}
+}
+
+func TestOpenSQLiteWithoutPath(t *testing.T) {
+	if _, err := Open(context.Background(), "sqlite://"); err == nil {
+		t.Error("expected an error for a SQLite URL without a file")
+	}
+}
@@ -0,0 +1,114 @@
+package subfond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores flows in subfond_flows and scan cursors in
+// subfond_flow_cursors.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL subfond flow repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Cursor returns the stored cursor of account, or "" before its first scan.
+func (r *PgRepository) Cursor(ctx context.Context, slug, account string) (string, error) {
+	var cursor string
+	err := r.pool.QueryRow(ctx,
+		`SELECT c.cursor
+		 FROM subfond_flow_cursors c
+		 JOIN fund_entities fe ON fe.id = c.entity_id
+		 WHERE fe.slug = $1 AND c.account = $2`, slug, account).Scan(&cursor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("loading subfond flow cursor of %s: %w", account, err)
+	}
+	return cursor, nil
+}
+
+// Save stores flows and advances the cursor of account in one transaction.
+// A flow already stored for the same operation is kept as it was.
+func (r *PgRepository) Save(ctx context.Context, slug, account, cursor string, flows []Flow) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning subfond flow tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	for _, f := range flows {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO subfond_flows (entity_id, operation_id, account, subfond, direction, counterparty,
+			     asset, amount, value_eurmtl, tx_hash, occurred_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			 ON CONFLICT (entity_id, operation_id, account) DO NOTHING`,
+			entityID, f.OperationID, f.Account, f.Subfond, string(f.Direction), f.Counterparty, f.Asset,
+			f.Amount, f.ValueEURMTL, f.TxHash, f.At); err != nil {
+			return fmt.Errorf("saving subfond flow %s: %w", f.OperationID, err)
+		}
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO subfond_flow_cursors (entity_id, account, cursor) VALUES ($1, $2, $3)
+		 ON CONFLICT (entity_id, account) DO UPDATE
+		 SET cursor = EXCLUDED.cursor, updated_at = CURRENT_TIMESTAMP`,
+		entityID, account, cursor); err != nil {
+		return fmt.Errorf("saving subfond flow cursor of %s: %w", account, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing subfond flows: %w", err)
+	}
+	return nil
+}
+
+// List returns the flows dated (UTC) after from through to, oldest first.
+func (r *PgRepository) List(ctx context.Context, slug string, from, to time.Time) ([]Flow, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT sf.operation_id, sf.subfond, sf.account, sf.direction, sf.counterparty, sf.asset, sf.amount,
+		        sf.value_eurmtl, sf.tx_hash, sf.occurred_at
+		 FROM subfond_flows sf
+		 JOIN fund_entities fe ON fe.id = sf.entity_id
+		 WHERE fe.slug = $1 AND sf.occurred_at >= $2 AND sf.occurred_at < $3
+		 ORDER BY sf.occurred_at, sf.operation_id`,
+		slug, day(from).AddDate(0, 0, 1), day(to).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("listing subfond flows: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Flow{}
+	for rows.Next() {
+		var f Flow
+		var direction string
+		if err := rows.Scan(&f.OperationID, &f.Subfond, &f.Account, &direction, &f.Counterparty, &f.Asset, &f.Amount,
+			&f.ValueEURMTL, &f.TxHash, &f.At); err != nil {
+			return nil, fmt.Errorf("scanning subfond flow: %w", err)
+		}
+		f.Direction = Direction(direction)
+		out = append(out, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating subfond flows: %w", err)
+	}
+	return out, nil
+}
+
+// day truncates t to its UTC date.
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
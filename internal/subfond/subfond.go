@@ -0,0 +1,306 @@
+// Package subfond measures the return of each subfond net of the capital
+// moved between it and the rest of the fund. A subfond's total value (I51,
+// I52, I53, I59) grows both with its investments and with the EURMTL the
+// issuer or another subfond sends it; the flows recorded here from the
+// payments stream separate the two, so subfonds can be compared by what
+// they earned on the capital they were given.
+package subfond
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/whale"
+)
+
+// DefaultPeriodDays is the window of the per-subfond ROI indicators (I83–I86).
+const DefaultPeriodDays = 30
+
+// Subfond is a subfond account with the indicator holding its total value.
+type Subfond struct {
+	Name    string
+	Address string
+	ValueID int
+}
+
+// valueIDs maps subfond names to their total value indicators.
+var valueIDs = map[string]int{"DEFI": 51, "MCITY": 52, "MABIZ": 53, "BOSS": 59}
+
+// All returns the subfonds of the account registry in registry order.
+func All() []Subfond {
+	var out []Subfond
+	for _, a := range domain.AccountRegistry() {
+		if id, ok := valueIDs[a.Name]; ok && a.Type == domain.AccountTypeSubfond {
+			out = append(out, Subfond{Name: a.Name, Address: a.Address, ValueID: id})
+		}
+	}
+	return out
+}
+
+// Lookup returns the subfond called name, in any case.
+func Lookup(name string) (Subfond, bool) {
+	for _, sf := range All() {
+		if strings.EqualFold(sf.Name, name) {
+			return sf, true
+		}
+	}
+	return Subfond{}, false
+}
+
+// Direction is the side of the subfond in a flow.
+type Direction string
+
+const (
+	In  Direction = "in"
+	Out Direction = "out"
+)
+
+// Flow is one transfer between a subfond and another fund account: capital
+// allocated to the subfond (In) or taken out of it (Out), valued in EURMTL
+// at the prices of the latest snapshot on or before the transfer.
+type Flow struct {
+	OperationID  string          `json:"operationId"`
+	Subfond      string          `json:"subfond"`
+	Account      string          `json:"account"`
+	Direction    Direction       `json:"direction"`
+	Counterparty string          `json:"counterparty"`
+	Asset        string          `json:"asset"` // snapshot.AssetKey: XLM or CODE-ISSUER
+	Amount       decimal.Decimal `json:"amount"`
+	ValueEURMTL  decimal.Decimal `json:"valueEURMTL"`
+	TxHash       string          `json:"txHash"`
+	At           time.Time       `json:"at"`
+}
+
+// Signed returns the flow's value, negative for an outflow.
+func (f Flow) Signed() decimal.Decimal {
+	if f.Direction == Out {
+		return f.ValueEURMTL.Neg()
+	}
+	return f.ValueEURMTL
+}
+
+// Detect returns the flows among the transfers of sf: those with another
+// fund account. Transfers with outside accounts are the subfond's business
+// (investments, income, expenses) and show up in its value instead.
+// pricesAt returns the EURMTL prices for a transfer time, nil when none are
+// known; assets without a price are skipped.
+func Detect(sf Subfond, transfers []horizon.AccountTransfer, fund map[string]bool, pricesAt func(time.Time) (whale.Prices, error)) ([]Flow, error) {
+	var out []Flow
+	for _, t := range transfers {
+		f := Flow{
+			OperationID: t.ID, Subfond: sf.Name, Account: sf.Address,
+			Asset: snapshot.AssetKey(t.Asset), Amount: t.Amount, TxHash: t.TxHash, At: t.TS,
+		}
+		switch sf.Address {
+		case t.To:
+			f.Direction, f.Counterparty = In, t.From
+		case t.From:
+			f.Direction, f.Counterparty = Out, t.To
+		default:
+			continue
+		}
+		if f.Counterparty == sf.Address || !fund[f.Counterparty] {
+			continue
+		}
+		prices, err := pricesAt(t.TS)
+		if err != nil {
+			return nil, err
+		}
+		price, ok := prices[f.Asset]
+		if !ok {
+			continue
+		}
+		f.ValueEURMTL = t.Amount.Mul(price).Round(2)
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// ROI is the return of a subfond from the end of From to the end of To by
+// the Modified Dietz method: Gain is the change of value net of flows, and
+// ROI divides it by the opening value plus each flow weighted by the part
+// of the period it was there for, in percent. ROI is nil when that average
+// capital isn't positive.
+type ROI struct {
+	Subfond  string           `json:"subfond"`
+	From     string           `json:"from"` // YYYY-MM-DD
+	To       string           `json:"to"`   // YYYY-MM-DD
+	Open     decimal.Decimal  `json:"open"`
+	Close    decimal.Decimal  `json:"close"`
+	Inflows  decimal.Decimal  `json:"inflows"`
+	Outflows decimal.Decimal  `json:"outflows"`
+	Gain     decimal.Decimal  `json:"gain"`
+	ROI      *decimal.Decimal `json:"roi"`
+	Flows    []Flow           `json:"flows"`
+}
+
+// Return computes the ROI of sf from open, its value at the end of from, to
+// close, its value at the end of to, given the flows in between. Flows of
+// other subfonds are ignored.
+func Return(sf Subfond, open, closing decimal.Decimal, flows []Flow, from, to time.Time) ROI {
+	r := ROI{
+		Subfond: sf.Name, From: from.Format("2006-01-02"), To: to.Format("2006-01-02"),
+		Open: open, Close: closing, Flows: []Flow{},
+	}
+	start, end := from.AddDate(0, 0, 1), to.AddDate(0, 0, 1)
+	length := decimal.NewFromInt(int64(end.Sub(start)))
+	capital := open
+	for _, f := range flows {
+		if f.Account != sf.Address {
+			continue
+		}
+		r.Flows = append(r.Flows, f)
+		if f.Direction == In {
+			r.Inflows = r.Inflows.Add(f.ValueEURMTL)
+		} else {
+			r.Outflows = r.Outflows.Add(f.ValueEURMTL)
+		}
+		weight := decimal.NewFromInt(1)
+		if length.IsPositive() {
+			remaining := min(max(end.Sub(f.At), 0), end.Sub(start))
+			weight = decimal.NewFromInt(int64(remaining)).Div(length)
+		}
+		capital = capital.Add(f.Signed().Mul(weight))
+	}
+	r.Gain = closing.Sub(open).Sub(r.Inflows).Add(r.Outflows)
+	if capital.IsPositive() {
+		roi := r.Gain.Div(capital).Mul(decimal.NewFromInt(100)).Round(2)
+		r.ROI = &roi
+	}
+	return r
+}
+
+// Window is the flows of every subfond dated after From through To.
+type Window struct {
+	From  time.Time
+	To    time.Time
+	Flows []Flow
+}
+
+// TransferSource walks an account's payments (horizon.Client).
+type TransferSource interface {
+	FetchAccountTransfers(ctx context.Context, account, cursor string) ([]horizon.AccountTransfer, string, error)
+}
+
+// SnapshotSource provides the snapshots flows are valued with (snapshot.PgRepository).
+type SnapshotSource interface {
+	GetNearestBefore(ctx context.Context, slug string, date time.Time) (*snapshot.Snapshot, error)
+}
+
+// Store persists cursors and flows (PgRepository).
+type Store interface {
+	Cursor(ctx context.Context, slug, account string) (string, error)
+	Save(ctx context.Context, slug, account, cursor string, flows []Flow) error
+	List(ctx context.Context, slug string, from, to time.Time) ([]Flow, error)
+}
+
+// Service records subfond flows from the payments stream.
+type Service struct {
+	transfers TransferSource
+	snapshots SnapshotSource
+	store     Store
+	slug      string
+}
+
+// NewService creates a Service for the entity slug.
+func NewService(transfers TransferSource, snapshots SnapshotSource, store Store, slug string) *Service {
+	return &Service{transfers: transfers, snapshots: snapshots, store: store, slug: slug}
+}
+
+// Record scans the payments of every subfond from its stored cursor and
+// stores the flows found with the new cursor. The first scan of an account
+// starts at its first payment; a long history takes several runs, since one
+// scan reads a bounded number of pages. A subfond that fails to scan keeps
+// its cursor and the others proceed; the failures are returned joined.
+func (s *Service) Record(ctx context.Context) ([]Flow, error) {
+	fund := make(map[string]bool)
+	for _, a := range domain.AggregatedAccounts() {
+		fund[a.Address] = true
+	}
+	prices := make(map[string]whale.Prices)
+	pricesAt := func(t time.Time) (whale.Prices, error) {
+		day := t.UTC().Format("2006-01-02")
+		if p, ok := prices[day]; ok {
+			return p, nil
+		}
+		p, err := s.pricesOn(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		prices[day] = p
+		return p, nil
+	}
+
+	var out []Flow
+	var errs []error
+	for _, sf := range All() {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+		cursor, err := s.store.Cursor(ctx, s.slug, sf.Address)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if cursor == "" {
+			cursor = "0"
+		}
+		transfers, next, err := s.transfers.FetchAccountTransfers(ctx, sf.Address, cursor)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scanning %s: %w", sf.Name, err))
+			continue
+		}
+		flows, err := Detect(sf, transfers, fund, pricesAt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("valuing flows of %s: %w", sf.Name, err))
+			continue
+		}
+		for _, f := range flows {
+			slog.Debug("subfond flow detected", "subfond", sf.Name, "direction", f.Direction, "counterparty", f.Counterparty,
+				"asset", f.Asset, "valueEURMTL", f.ValueEURMTL.String(), "tx", f.TxHash)
+		}
+		if err := s.store.Save(ctx, s.slug, sf.Address, next, flows); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, flows...)
+	}
+	return out, errors.Join(errs...)
+}
+
+// Window returns the stored flows dated after from through to.
+func (s *Service) Window(ctx context.Context, from, to time.Time) (Window, error) {
+	flows, err := s.store.List(ctx, s.slug, from, to)
+	if err != nil {
+		return Window{}, err
+	}
+	return Window{From: from, To: to, Flows: flows}, nil
+}
+
+// pricesOn reads the EURMTL prices of the latest snapshot on or before t,
+// or nil when there is none: flows before the first snapshot precede every
+// value they could adjust.
+func (s *Service) pricesOn(ctx context.Context, t time.Time) (whale.Prices, error) {
+	snap, err := s.snapshots.GetNearestBefore(ctx, s.slug, t)
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading snapshot for %s: %w", t.Format("2006-01-02"), err)
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(snap.Data, &data); err != nil {
+		return nil, fmt.Errorf("decoding snapshot %s: %w", snap.SnapshotDate.Format("2006-01-02"), err)
+	}
+	return whale.PricesFrom(data), nil
+}
@@ -0,0 +1,100 @@
+package subfond
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/whale"
+)
+
+func mustLookup(t *testing.T, name string) Subfond {
+	t.Helper()
+	sf, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("subfond %s not found", name)
+	}
+	return sf
+}
+
+func TestAll(t *testing.T) {
+	got := All()
+	if len(got) != 4 {
+		t.Fatalf("All() = %+v, want the 4 registry subfonds", got)
+	}
+	if sf := mustLookup(t, "defi"); sf.ValueID != 51 || sf.Name != "DEFI" {
+		t.Errorf("Lookup(defi) = %+v", sf)
+	}
+	if _, ok := Lookup("ADMIN"); ok {
+		t.Error("ADMIN is not a subfond")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	defi, mabiz := mustLookup(t, "DEFI"), mustLookup(t, "MABIZ")
+	eurmtl, spam := domain.EURMTLAsset(), domain.NewAssetInfo("SPAM", "GSPAM")
+	at := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	fund := map[string]bool{defi.Address: true, mabiz.Address: true, domain.IssuerAddress: true}
+	transfers := []horizon.AccountTransfer{
+		{ID: "1", From: domain.IssuerAddress, To: defi.Address, Asset: eurmtl, Amount: decimal.NewFromInt(500), TS: at},
+		{ID: "2", From: defi.Address, To: mabiz.Address, Asset: domain.XLMAsset(), Amount: decimal.NewFromInt(100), TS: at},
+		{ID: "3", From: defi.Address, To: "GOUTSIDE", Asset: eurmtl, Amount: decimal.NewFromInt(70), TS: at},
+		{ID: "4", From: mabiz.Address, To: defi.Address, Asset: spam, Amount: decimal.NewFromInt(9), TS: at},
+	}
+	prices := whale.Prices{snapshot.AssetKey(eurmtl): decimal.NewFromInt(1), "XLM": decimal.RequireFromString("0.25")}
+
+	got, err := Detect(defi, transfers, fund, func(time.Time) (whale.Prices, error) { return prices, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want the issuer allocation and the MABIZ transfer", got)
+	}
+	if got[0].Direction != In || !got[0].ValueEURMTL.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("allocation = %+v", got[0])
+	}
+	if got[1].Direction != Out || got[1].Counterparty != mabiz.Address || !got[1].Signed().Equal(decimal.NewFromInt(-25)) {
+		t.Errorf("transfer to MABIZ = %+v", got[1])
+	}
+
+	// Without a snapshot nothing can be valued; a failed lookup fails the scan.
+	if got, _ := Detect(defi, transfers, fund, func(time.Time) (whale.Prices, error) { return nil, nil }); len(got) != 0 {
+		t.Errorf("without prices: %+v", got)
+	}
+	boom := errors.New("boom")
+	if _, err := Detect(defi, transfers, fund, func(time.Time) (whale.Prices, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want boom", err)
+	}
+}
+
+func TestReturn(t *testing.T) {
+	defi, mabiz := mustLookup(t, "DEFI"), mustLookup(t, "MABIZ")
+	from := time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	flows := []Flow{
+		// Half-way through June: weighs half its value in the average capital.
+		{Account: defi.Address, Direction: In, ValueEURMTL: decimal.NewFromInt(500), At: time.Date(2026, 6, 16, 0, 0, 0, 0, time.UTC)},
+		{Account: mabiz.Address, Direction: Out, ValueEURMTL: decimal.NewFromInt(300), At: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	r := Return(defi, decimal.NewFromInt(1000), decimal.NewFromInt(1600), flows, from, to)
+	if len(r.Flows) != 1 || !r.Inflows.Equal(decimal.NewFromInt(500)) || !r.Outflows.IsZero() {
+		t.Errorf("flows = %+v, in %s, out %s", r.Flows, r.Inflows, r.Outflows)
+	}
+	// Gain 1600 − 1000 − 500 = 100 on 1000 + 500 × ½.
+	if !r.Gain.Equal(decimal.NewFromInt(100)) || r.ROI == nil || !r.ROI.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("gain %s, ROI %v, want 100 and 8%%", r.Gain, r.ROI)
+	}
+	if r.From != "2026-05-31" || r.To != "2026-06-30" {
+		t.Errorf("range = %s..%s", r.From, r.To)
+	}
+
+	if r := Return(defi, decimal.Zero, decimal.NewFromInt(10), nil, from, to); r.ROI != nil || r.Flows == nil {
+		t.Errorf("no capital: %+v, want nil ROI and empty flows", r)
+	}
+}
@@ -0,0 +1,125 @@
+package supply
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// PgRepository stores supplies in asset_supplies.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL asset supply repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Save replaces the rows of date with one row per asset in supplies, carrying
+// the matching change if any. Assets of an issuer whose fetch failed get no
+// row. An asset gone from Horizon since the previous snapshot only appears
+// in changes and is stored at its new supply, zero.
+func (r *PgRepository) Save(ctx context.Context, slug string, date time.Time, supplies []domain.IssuerSupply, changes []Change) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning asset supply tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM asset_supplies WHERE entity_id = $1 AND snapshot_date = $2`, entityID, date); err != nil {
+		return fmt.Errorf("clearing asset supplies for %s: %w", date.Format("2006-01-02"), err)
+	}
+
+	byAsset := make(map[string]Change, len(changes))
+	for _, c := range changes {
+		byAsset[c.Asset] = c
+	}
+	insert := func(asset, issuer string, supply decimal.Decimal) error {
+		var prevDate *time.Time
+		var delta *decimal.Decimal
+		c, changed := byAsset[asset]
+		if changed {
+			prevDate, delta = &c.PrevDate, &c.Delta
+			delete(byAsset, asset)
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO asset_supplies (entity_id, snapshot_date, asset, issuer_name, supply,
+			     prev_date, delta, expected, notified)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			entityID, date, asset, issuer, supply, prevDate, delta, c.Expected, c.Notified); err != nil {
+			return fmt.Errorf("saving %s supply for %s: %w", asset, date.Format("2006-01-02"), err)
+		}
+		return nil
+	}
+	for _, is := range supplies {
+		if is.Error != "" {
+			continue
+		}
+		for _, a := range is.Assets {
+			if err := insert(snapshot.AssetKey(domain.NewAssetInfo(a.Code, is.Issuer)), is.Name, a.Supply); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range changes {
+		if _, left := byAsset[c.Asset]; left {
+			if err := insert(c.Asset, c.Issuer, c.Supply); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing asset supplies: %w", err)
+	}
+	return nil
+}
+
+// History returns the supply of asset (CODE-ISSUER) on each stored date
+// between from and to (inclusive; zero means unbounded), oldest first.
+func (r *PgRepository) History(ctx context.Context, slug, asset string, from, to time.Time) ([]Point, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT s.snapshot_date, s.supply, s.delta, s.expected, s.notified
+		 FROM asset_supplies s
+		 JOIN fund_entities fe ON fe.id = s.entity_id
+		 WHERE fe.slug = $1 AND s.asset = $2
+		   AND ($3::date IS NULL OR s.snapshot_date >= $3)
+		   AND ($4::date IS NULL OR s.snapshot_date <= $4)
+		 ORDER BY s.snapshot_date`,
+		slug, asset, nullDate(from), nullDate(to))
+	if err != nil {
+		return nil, fmt.Errorf("listing %s supply history: %w", asset, err)
+	}
+	defer rows.Close()
+
+	var out []Point
+	for rows.Next() {
+		var p Point
+		if err := rows.Scan(&p.Date, &p.Supply, &p.Delta, &p.Expected, &p.Notified); err != nil {
+			return nil, fmt.Errorf("scanning asset supply: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating asset supplies: %w", err)
+	}
+	return out, nil
+}
+
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
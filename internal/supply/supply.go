@@ -0,0 +1,259 @@
+// Package supply audits the total supply of every asset the fund accounts
+// issue, not just MTL and MTLRECT. Each snapshot records the supplies; each
+// run compares them with the previous snapshot and alerts on a mint or burn
+// of an asset whose supply isn't expected to move, so a compromised issuer
+// key can't silently inflate a sub-token.
+package supply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Change is a supply change of one fund-issued asset between two snapshots.
+type Change struct {
+	Date       time.Time       `json:"date"`     // snapshot that saw the new supply
+	PrevDate   time.Time       `json:"prevDate"` // snapshot it was compared with
+	Asset      string          `json:"asset"`    // snapshot.AssetKey: CODE-ISSUER
+	Issuer     string          `json:"issuer"`   // name of the issuing fund account
+	PrevSupply decimal.Decimal `json:"prevSupply"`
+	Supply     decimal.Decimal `json:"supply"`
+	Delta      decimal.Decimal `json:"delta"`    // Supply − PrevSupply
+	Expected   bool            `json:"expected"` // code listed in SUPPLY_EXPECTED_CHANGES, no alert
+	Notified   bool            `json:"notified"`
+}
+
+// Point is an asset's supply on one snapshot date. Delta is the change since
+// the previous snapshot, nil when there was none to compare with.
+type Point struct {
+	Date     time.Time        `json:"date"`
+	Supply   decimal.Decimal  `json:"supply"`
+	Delta    *decimal.Decimal `json:"delta,omitempty"`
+	Expected bool             `json:"expected"`
+	Notified bool             `json:"notified"`
+}
+
+// issued indexes snapshot supplies by asset key.
+type issued struct {
+	supply map[string]decimal.Decimal
+	issuer map[string]domain.IssuerSupply // by asset key
+	failed map[string]bool                // issuer addresses whose fetch failed
+}
+
+func index(supplies []domain.IssuerSupply) issued {
+	ix := issued{supply: make(map[string]decimal.Decimal), issuer: make(map[string]domain.IssuerSupply), failed: make(map[string]bool)}
+	for _, is := range supplies {
+		if is.Error != "" {
+			ix.failed[is.Issuer] = true
+			continue
+		}
+		for _, a := range is.Assets {
+			key := snapshot.AssetKey(domain.NewAssetInfo(a.Code, is.Issuer))
+			ix.supply[key] = a.Supply
+			ix.issuer[key] = is
+		}
+	}
+	return ix
+}
+
+// Compare returns one change per asset whose supply differs between prev and
+// cur, sorted by asset. An asset missing from one side counts as zero supply,
+// so a newly issued asset is a mint. Assets of an issuer whose fetch failed
+// in either snapshot are skipped, and a prev without supplies (snapshots
+// older than supply tracking) yields nothing. expected holds the asset codes
+// whose changes are routine.
+func Compare(prevDate, date time.Time, prev, cur []domain.IssuerSupply, expected map[string]bool) []Change {
+	if len(prev) == 0 {
+		return nil
+	}
+	before, after := index(prev), index(cur)
+	keys := make(map[string]bool, len(after.supply))
+	for k := range before.supply {
+		keys[k] = true
+	}
+	for k := range after.supply {
+		keys[k] = true
+	}
+
+	var out []Change
+	for key := range keys {
+		is, ok := after.issuer[key]
+		if !ok {
+			is = before.issuer[key]
+		}
+		if before.failed[is.Issuer] || after.failed[is.Issuer] {
+			continue
+		}
+		p, c := before.supply[key], after.supply[key]
+		if p.Equal(c) {
+			continue
+		}
+		code, _, _ := strings.Cut(key, "-")
+		out = append(out, Change{
+			Date:       date,
+			PrevDate:   prevDate,
+			Asset:      key,
+			Issuer:     is.Name,
+			PrevSupply: p,
+			Supply:     c,
+			Delta:      c.Sub(p),
+			Expected:   expected[code],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Asset < out[j].Asset })
+	return out
+}
+
+// Message renders an unexpected change as the HTML notification text.
+func Message(c Change) string {
+	code, _, _ := strings.Cut(c.Asset, "-")
+	verb, sign := "Выпуск", "+"
+	if c.Delta.IsNegative() {
+		verb, sign = "Сжигание", "−"
+	}
+	return fmt.Sprintf("<b>⚠️ Неожиданное изменение эмиссии: %s</b>\n%s %s%s (было %s, стало %s)\nЭмитент: %s\n<a href=\"https://stellar.expert/explorer/public/asset/%s\">Актив</a>",
+		html.EscapeString(code), verb, sign, c.Delta.Abs().String(), c.PrevSupply.String(), c.Supply.String(),
+		html.EscapeString(c.Issuer), c.Asset)
+}
+
+// AssetSource lists the assets an account issues (horizon.Client).
+type AssetSource interface {
+	FetchIssuedAssets(ctx context.Context, issuer string) ([]horizon.HorizonAsset, error)
+}
+
+// SnapshotSource finds the snapshot to compare with (snapshot.PgRepository).
+type SnapshotSource interface {
+	GetNearestBefore(ctx context.Context, slug string, date time.Time) (*snapshot.Snapshot, error)
+}
+
+// Store persists the supplies and changes of a date (PgRepository).
+type Store interface {
+	Save(ctx context.Context, slug string, date time.Time, supplies []domain.IssuerSupply, changes []Change) error
+}
+
+// Notifier delivers an HTML message (notify.Service).
+type Notifier interface {
+	SendMessage(ctx context.Context, date time.Time, msg string) error
+}
+
+// Service captures issued supplies at snapshot time and audits them after.
+type Service struct {
+	assets    AssetSource
+	snapshots SnapshotSource
+	store     Store
+	notifier  Notifier
+	accounts  []domain.FundAccount
+	expected  map[string]bool
+	slug      string
+}
+
+// NewService creates a Service auditing the assets issued by accounts of the
+// entity slug. Changes of the expected asset codes are recorded without an
+// alert. A nil notifier only logs the alerts.
+func NewService(assets AssetSource, snapshots SnapshotSource, store Store, notifier Notifier,
+	accounts []domain.FundAccount, expected []string, slug string) *Service {
+	exp := make(map[string]bool, len(expected))
+	for _, code := range expected {
+		exp[code] = true
+	}
+	return &Service{assets: assets, snapshots: snapshots, store: store, notifier: notifier,
+		accounts: accounts, expected: exp, slug: slug}
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher. It fills
+// FundStructureData.IssuedSupplies with one entry per account that issues
+// anything. A failed fetch is logged and recorded on that account's entry,
+// and only a cancelled ctx is returned as an error.
+func (s *Service) EnrichMetrics(ctx context.Context, _ time.Time, data *domain.FundStructureData) error {
+	var out []domain.IssuerSupply
+	for _, acc := range s.accounts {
+		is := domain.IssuerSupply{Name: acc.Name, Issuer: acc.Address}
+		assets, err := s.assets.FetchIssuedAssets(ctx, acc.Address)
+		if err == nil {
+			is.Assets, err = assetSupplies(assets)
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			slog.Error("issued supply unavailable", "account", acc.Name, "error", err)
+			out = append(out, domain.IssuerSupply{Name: acc.Name, Issuer: acc.Address, Error: err.Error()})
+			continue
+		}
+		if len(is.Assets) > 0 {
+			out = append(out, is)
+		}
+	}
+	data.IssuedSupplies = out
+	return nil
+}
+
+func assetSupplies(assets []horizon.HorizonAsset) ([]domain.AssetSupply, error) {
+	out := make([]domain.AssetSupply, 0, len(assets))
+	for _, a := range assets {
+		total, err := a.TotalSupply()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, domain.AssetSupply{Code: a.AssetCode, Supply: total})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, nil
+}
+
+// Record compares data, the snapshot just generated for date, with the
+// latest stored snapshot before date, alerts on each unexpected change and
+// replaces the stored supplies of date. Without an earlier snapshot the
+// supplies are stored with no changes. A failed send is logged and the
+// change is stored as not notified.
+func (s *Service) Record(ctx context.Context, date time.Time, data domain.FundStructureData) ([]Change, error) {
+	var changes []Change
+	prev, err := s.snapshots.GetNearestBefore(ctx, s.slug, date.AddDate(0, 0, -1))
+	switch {
+	case errors.Is(err, snapshot.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("loading previous snapshot: %w", err)
+	default:
+		var prevData domain.FundStructureData
+		if err := json.Unmarshal(prev.Data, &prevData); err != nil {
+			return nil, fmt.Errorf("decoding snapshot %s: %w", prev.SnapshotDate.Format("2006-01-02"), err)
+		}
+		changes = Compare(prev.SnapshotDate, date, prevData.IssuedSupplies, data.IssuedSupplies, s.expected)
+	}
+
+	for i := range changes {
+		c := &changes[i]
+		if c.Expected {
+			slog.Info("supply change", "asset", c.Asset, "delta", c.Delta.String())
+			continue
+		}
+		slog.Error("unexpected supply change", "asset", c.Asset, "issuer", c.Issuer,
+			"prevSupply", c.PrevSupply.String(), "supply", c.Supply.String())
+		if s.notifier == nil {
+			continue
+		}
+		if err := s.notifier.SendMessage(ctx, date, Message(*c)); err != nil {
+			slog.Error("supply alert notification failed", "asset", c.Asset, "error", err)
+			continue
+		}
+		c.Notified = true
+	}
+
+	if err := s.store.Save(ctx, s.slug, date, data.IssuedSupplies, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,192 @@
+package supply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+const (
+	issuerA = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	issuerB = "GBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB"
+)
+
+var (
+	day1 = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 = time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+)
+
+func supplies(issuer, name string, kv ...any) domain.IssuerSupply {
+	is := domain.IssuerSupply{Name: name, Issuer: issuer}
+	for i := 0; i < len(kv); i += 2 {
+		is.Assets = append(is.Assets, domain.AssetSupply{Code: kv[i].(string), Supply: decimal.NewFromInt(int64(kv[i+1].(int)))})
+	}
+	return is
+}
+
+func TestCompare(t *testing.T) {
+	prev := []domain.IssuerSupply{
+		supplies(issuerA, "Issuer", "EURMTL", 1000, "MCITY1", 50, "OLD", 5),
+		{Name: "Other", Issuer: issuerB, Error: "timeout"},
+	}
+	cur := []domain.IssuerSupply{
+		supplies(issuerA, "Issuer", "EURMTL", 1200, "MCITY1", 60, "NEW", 7),
+		supplies(issuerB, "Other", "BBB", 10),
+	}
+	changes := Compare(day1, day2, prev, cur, map[string]bool{"EURMTL": true})
+
+	want := []struct {
+		asset    string
+		delta    int64
+		expected bool
+	}{
+		{"EURMTL-" + issuerA, 200, true},
+		{"MCITY1-" + issuerA, 10, false},
+		{"NEW-" + issuerA, 7, false},
+		{"OLD-" + issuerA, -5, false},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Asset != w.asset || !c.Delta.Equal(decimal.NewFromInt(w.delta)) || c.Expected != w.expected {
+			t.Errorf("change %d = %+v, want %s %+d expected=%v", i, c, w.asset, w.delta, w.expected)
+		}
+		if c.Issuer != "Issuer" || !c.PrevDate.Equal(day1) || !c.Date.Equal(day2) {
+			t.Errorf("change %d = %+v", i, c)
+		}
+	}
+}
+
+func TestCompareWithoutTrackedPrevious(t *testing.T) {
+	cur := []domain.IssuerSupply{supplies(issuerA, "Issuer", "EURMTL", 1000)}
+	if changes := Compare(day1, day2, nil, cur, nil); len(changes) != 0 {
+		t.Errorf("got %+v, want no changes before supply tracking", changes)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	msg := Message(Change{Asset: "MCITY1-" + issuerA, Issuer: "Issuer",
+		PrevSupply: decimal.NewFromInt(60), Supply: decimal.NewFromInt(50), Delta: decimal.NewFromInt(-10)})
+	for _, want := range []string{"MCITY1", "Сжигание −10", "было 60, стало 50", "asset/MCITY1-" + issuerA} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q lacks %q", msg, want)
+		}
+	}
+}
+
+type stubAssets map[string][]horizon.HorizonAsset
+
+func (s stubAssets) FetchIssuedAssets(_ context.Context, issuer string) ([]horizon.HorizonAsset, error) {
+	assets, ok := s[issuer]
+	if !ok {
+		return nil, errors.New("horizon down")
+	}
+	return assets, nil
+}
+
+func TestEnrichMetrics(t *testing.T) {
+	assets := stubAssets{
+		issuerA: {
+			{AssetCode: "MCITY1", Balances: horizon.HorizonAssetBalances{Authorized: "40"}, LiquidityPoolsAmount: "10"},
+			{AssetCode: "EURMTL", Balances: horizon.HorizonAssetBalances{Authorized: "1000"}},
+		},
+		issuerB: nil,
+	}
+	accounts := []domain.FundAccount{{Name: "Issuer", Address: issuerA}, {Name: "Empty", Address: issuerB}, {Name: "Down", Address: "GDOWN"}}
+	svc := NewService(assets, stubSnapshots{}, &stubStore{}, nil, accounts, nil, "mtlf")
+
+	var data domain.FundStructureData
+	if err := svc.EnrichMetrics(context.Background(), day2, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.IssuedSupplies) != 2 {
+		t.Fatalf("issued supplies = %+v, want the issuer and the failed account", data.IssuedSupplies)
+	}
+	is := data.IssuedSupplies[0]
+	if is.Name != "Issuer" || len(is.Assets) != 2 || is.Assets[0].Code != "EURMTL" || !is.Assets[1].Supply.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("issuer supply = %+v", is)
+	}
+	if failed := data.IssuedSupplies[1]; failed.Name != "Down" || failed.Error == "" {
+		t.Errorf("failed entry = %+v", failed)
+	}
+}
+
+type stubSnapshots struct {
+	snap *snapshot.Snapshot
+}
+
+func (s stubSnapshots) GetNearestBefore(context.Context, string, time.Time) (*snapshot.Snapshot, error) {
+	if s.snap == nil {
+		return nil, snapshot.ErrNotFound
+	}
+	return s.snap, nil
+}
+
+type stubStore struct {
+	saved    bool
+	supplies []domain.IssuerSupply
+	changes  []Change
+}
+
+func (s *stubStore) Save(_ context.Context, _ string, _ time.Time, supplies []domain.IssuerSupply, changes []Change) error {
+	s.saved, s.supplies, s.changes = true, supplies, changes
+	return nil
+}
+
+type stubNotifier struct {
+	messages []string
+}
+
+func (n *stubNotifier) SendMessage(_ context.Context, _ time.Time, msg string) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func TestServiceRecord(t *testing.T) {
+	prevData, _ := json.Marshal(domain.FundStructureData{IssuedSupplies: []domain.IssuerSupply{
+		supplies(issuerA, "Issuer", "EURMTL", 1000, "MCITY1", 50),
+	}})
+	store, notifier := &stubStore{}, &stubNotifier{}
+	svc := NewService(stubAssets{}, stubSnapshots{&snapshot.Snapshot{SnapshotDate: day1, Data: prevData}}, store, notifier,
+		nil, []string{"EURMTL"}, "mtlf")
+
+	cur := domain.FundStructureData{IssuedSupplies: []domain.IssuerSupply{
+		supplies(issuerA, "Issuer", "EURMTL", 1100, "MCITY1", 80),
+	}}
+	changes, err := svc.Record(context.Background(), day2, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Notified || !changes[1].Notified {
+		t.Fatalf("changes = %+v, want EURMTL expected and MCITY1 notified", changes)
+	}
+	if len(notifier.messages) != 1 || !strings.Contains(notifier.messages[0], "MCITY1") {
+		t.Errorf("messages = %q, want one MCITY1 alert", notifier.messages)
+	}
+	if !store.saved || len(store.supplies) != 1 || len(store.changes) != 2 {
+		t.Errorf("store = %+v", store)
+	}
+}
+
+func TestServiceRecordWithoutPreviousSnapshot(t *testing.T) {
+	store := &stubStore{}
+	cur := domain.FundStructureData{IssuedSupplies: []domain.IssuerSupply{supplies(issuerA, "Issuer", "EURMTL", 1000)}}
+	changes, err := NewService(stubAssets{}, stubSnapshots{}, store, nil, nil, nil, "mtlf").Record(context.Background(), day2, cur)
+	if err != nil || changes != nil {
+		t.Fatalf("changes = %v, err = %v; want none", changes, err)
+	}
+	if !store.saved || len(store.supplies) != 1 {
+		t.Errorf("store = %+v, want the supplies saved without changes", store)
+	}
+}
@@ -0,0 +1,118 @@
+// Package transport is the HTTP transport shared by every external client:
+// Horizon, CoinGecko, stellar.expert, Grist, the publish targets and the
+// importer. One connection pool with keep-alives replaces a pool per client,
+// connections per host are capped, a proxy can be configured, and requests
+// are counted per host for diagnostics. Each client keeps its own overall
+// request timeout.
+package transport
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Config tunes the shared transport.
+type Config struct {
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration // TCP keep-alive probe interval
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // 0 = only the client's timeout applies
+	IdleConnTimeout       time.Duration
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int    // 0 = unlimited
+	ProxyURL              string // empty = HTTP_PROXY / HTTPS_PROXY / NO_PROXY
+}
+
+// HostStats are the request counters of one upstream host.
+type HostStats struct {
+	Host     string `json:"host"`
+	Requests int64  `json:"requests"`
+	Failures int64  `json:"failures"` // round trips that returned no response
+	InFlight int64  `json:"inFlight"` // waiting for response headers
+}
+
+// Transport is an http.RoundTripper over one shared connection pool that
+// counts requests per host. It is safe for concurrent use.
+type Transport struct {
+	base *http.Transport
+
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+// New builds a Transport from cfg. An unparsable ProxyURL is an error.
+func New(cfg Config) (*Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		u, err := url.Parse(cfg.ProxyURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.ProxyURL)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: cfg.KeepAlive}
+	return &Transport{
+		base: &http.Transport{
+			Proxy:                 proxy,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+			IdleConnTimeout:       cfg.IdleConnTimeout,
+			MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       cfg.MaxConnsPerHost,
+			ExpectContinueTimeout: time.Second,
+		},
+		hosts: make(map[string]*HostStats),
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	t.update(host, func(s *HostStats) {
+		s.Requests++
+		s.InFlight++
+	})
+	resp, err := t.base.RoundTrip(req)
+	t.update(host, func(s *HostStats) {
+		s.InFlight--
+		if err != nil {
+			s.Failures++
+		}
+	})
+	return resp, err
+}
+
+func (t *Transport) update(host string, f func(*HostStats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.hosts[host]
+	if !ok {
+		s = &HostStats{Host: host}
+		t.hosts[host] = s
+	}
+	f(s)
+}
+
+// Stats returns the counters of every host contacted so far, by host.
+func (t *Transport) Stats() []HostStats {
+	t.mu.Lock()
+	out := make([]HostStats, 0, len(t.hosts))
+	for _, s := range t.hosts {
+		out = append(out, *s)
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+// CloseIdleConnections closes the pooled connections not in use.
+func (t *Transport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+}
@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTransportCountsPerHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tr, err := New(Config{DialTimeout: time.Second, MaxConnsPerHost: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+	for range 3 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// Nothing listens on port 1: the round trip fails without a response.
+	if _, err := client.Get("http://127.0.0.1:1/"); err == nil {
+		t.Fatal("expected a connection error")
+	}
+
+	host := server.Listener.Addr().String()
+	stats := tr.Stats()
+	if len(stats) != 2 || stats[0].Host != "127.0.0.1:1" || stats[1].Host != host {
+		t.Fatalf("stats = %+v, want the dead host then %s", stats, host)
+	}
+	if s := stats[1]; s.Requests != 3 || s.Failures != 0 || s.InFlight != 0 {
+		t.Errorf("server stats = %+v, want 3 requests", s)
+	}
+	if s := stats[0]; s.Requests != 1 || s.Failures != 1 {
+		t.Errorf("dead host stats = %+v, want 1 failed request", s)
+	}
+}
+
+func TestNewProxy(t *testing.T) {
+	tr, err := New(Config{ProxyURL: "http://proxy.internal:3128"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &http.Request{URL: &url.URL{Scheme: "https", Host: "horizon.stellar.org"}}
+	got, err := tr.base.Proxy(req)
+	if err != nil || got == nil || got.Host != "proxy.internal:3128" {
+		t.Errorf("proxy = %v, %v; want proxy.internal:3128", got, err)
+	}
+
+	for _, bad := range []string{"proxy.internal", "://"} {
+		if _, err := New(Config{ProxyURL: bad}); err == nil {
+			t.Errorf("New(%q): expected an error", bad)
+		}
+	}
+}
@@ -10,16 +10,60 @@ import (
 	"github.com/samber/lo"
 
 	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/pacing"
 )
 
 // Service provides asset valuation from Stellar DATA entries.
 type Service struct {
-	fetcher AccountFetcher
+	fetcher    AccountFetcher
+	properties PropertySource
+}
+
+// PropertySource lists the valuations of registered real-estate tokens
+// (property.Service).
+type PropertySource interface {
+	Valuations(ctx context.Context) ([]domain.AssetValuation, error)
+}
+
+// Option configures NewService.
+type Option func(*Service)
+
+// WithPacer paces the account fetches against Horizon.
+func WithPacer(p *pacing.Pacer) Option {
+	return func(s *Service) {
+		if p != nil {
+			s.fetcher = pacedFetcher{next: s.fetcher, pacer: p}
+		}
+	}
+}
+
+// WithProperties values the tokens registered in src by their property data
+// instead of their DATA entries.
+func WithProperties(src PropertySource) Option {
+	return func(s *Service) { s.properties = src }
+}
+
+// pacedFetcher waits for a pacer slot before each account fetch.
+type pacedFetcher struct {
+	next  AccountFetcher
+	pacer *pacing.Pacer
+}
+
+func (f pacedFetcher) FetchAccount(ctx context.Context, accountID string) (horizon.HorizonAccount, error) {
+	if err := f.pacer.Wait(ctx); err != nil {
+		return horizon.HorizonAccount{}, err
+	}
+	return f.next.FetchAccount(ctx, accountID)
 }
 
 // NewService creates a new ValuationService.
-func NewService(fetcher AccountFetcher) *Service {
-	return &Service{fetcher: fetcher}
+func NewService(fetcher AccountFetcher, opts ...Option) *Service {
+	s := &Service{fetcher: fetcher}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // FetchAllValuations scans all fund accounts for DATA entry valuations with concurrency=3.
@@ -61,7 +105,27 @@ func (s *Service) FetchAllValuations(ctx context.Context) ([]domain.AssetValuati
 		}
 	}
 
-	return deduplicateValuations(allValuations), nil
+	return s.withProperties(ctx, deduplicateValuations(allValuations)), nil
+}
+
+// withProperties replaces the DATA entry valuations of every registered
+// property token with its registry valuation. When the registry can't be
+// read the DATA entries are kept.
+func (s *Service) withProperties(ctx context.Context, vals []domain.AssetValuation) []domain.AssetValuation {
+	if s.properties == nil {
+		return vals
+	}
+	props, err := s.properties.Valuations(ctx)
+	if err != nil {
+		slog.Error("property registry unavailable, valuing real estate by DATA entries", "error", err)
+		return vals
+	}
+	registered := make(map[string]bool, len(props))
+	for _, p := range props {
+		registered[p.TokenCode] = true
+	}
+	vals = lo.Reject(vals, func(v domain.AssetValuation, _ int) bool { return registered[v.TokenCode] })
+	return append(vals, props...)
 }
 
 // deduplicateValuations removes duplicates by tokenCode:valuationType.
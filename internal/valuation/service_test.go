@@ -143,3 +143,26 @@ func TestFetchAllValuationsPartialFailure(t *testing.T) {
 		t.Error("expected at least one valuation from the successful account")
 	}
 }
+
+type stubProperties []domain.AssetValuation
+
+func (s stubProperties) Valuations(context.Context) ([]domain.AssetValuation, error) { return s, nil }
+
+func TestFetchAllValuationsWithProperties(t *testing.T) {
+	firstAccount := domain.AccountRegistry()[0].Address
+	area := 54.3
+	props := stubProperties{{TokenCode: "TOKEN", ValuationType: domain.ValuationTypeNFT,
+		RawValue: domain.ValuationValue{Type: domain.ValuationValueExternal, Symbol: "M2_BUDVA", Quantity: &area, Unit: "m2"}}}
+	svc := NewService(&partialFailFetcher{successID: firstAccount}, WithProperties(props))
+
+	valuations, err := svc.FetchAllValuations(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(valuations) != 1 || valuations[0].RawValue.Symbol != "M2_BUDVA" {
+		t.Fatalf("valuations = %+v, want only the property valuation", valuations)
+	}
+	if v := LookupValuation("TOKEN", "0.0000001", firstAccount, valuations); v == nil || v.RawValue.Unit != "m2" {
+		t.Errorf("LookupValuation = %+v, want the property valuation", v)
+	}
+}
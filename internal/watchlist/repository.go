@@ -0,0 +1,97 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mtlprog/stat/internal/domain"
+)
+
+// PgRepository stores the watchlist in the watchlist table.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL watchlist repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Put validates and stores e, replacing the label and assets of an address
+// already on the watchlist, and returns it with its ID and creation time.
+func (r *PgRepository) Put(ctx context.Context, slug string, e Entry) (Entry, error) {
+	if err := e.Validate(); err != nil {
+		return Entry{}, err
+	}
+	assets := make([]string, 0, len(e.Assets))
+	for _, a := range e.Assets {
+		assets = append(assets, a.Canonical())
+	}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO watchlist (entity_id, address, label, assets)
+		 SELECT id, $2, $3, $4 FROM fund_entities WHERE slug = $1
+		 ON CONFLICT (entity_id, address) DO UPDATE
+		 SET label = EXCLUDED.label, assets = EXCLUDED.assets
+		 RETURNING id, created_at`,
+		slug, e.Address, e.Label, assets).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return Entry{}, fmt.Errorf("saving watchlist entry %s: %w", e.Address, err)
+	}
+	if e.Assets == nil {
+		e.Assets = []domain.AssetInfo{}
+	}
+	return e, nil
+}
+
+// List returns the watchlist in the order entries were added.
+func (r *PgRepository) List(ctx context.Context, slug string) ([]Entry, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT w.id, w.address, w.label, w.assets, w.created_at
+		 FROM watchlist w
+		 JOIN fund_entities fe ON fe.id = w.entity_id
+		 WHERE fe.slug = $1
+		 ORDER BY w.id`, slug)
+	if err != nil {
+		return nil, fmt.Errorf("listing watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Entry{}
+	for rows.Next() {
+		var e Entry
+		var assets []string
+		if err := rows.Scan(&e.ID, &e.Address, &e.Label, &assets, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning watchlist entry: %w", err)
+		}
+		e.Assets = make([]domain.AssetInfo, 0, len(assets))
+		for _, s := range assets {
+			a, err := ParseAsset(s)
+			if err != nil {
+				return nil, fmt.Errorf("watchlist entry %s: %w", e.Address, err)
+			}
+			e.Assets = append(e.Assets, a)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating watchlist: %w", err)
+	}
+	return out, nil
+}
+
+// Delete removes address from the watchlist, or returns ErrNotFound.
+// Snapshots already taken keep its balances.
+func (r *PgRepository) Delete(ctx context.Context, slug, address string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM watchlist w USING fund_entities fe
+		 WHERE fe.id = w.entity_id AND fe.slug = $1 AND w.address = $2`, slug, address)
+	if err != nil {
+		return fmt.Errorf("deleting watchlist entry %s: %w", address, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, address)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Package watchlist tracks the key token balances of partner and affiliate
+// accounts that are not part of the fund registry. Their balances are
+// captured at snapshot time into FundStructureData.Watchlist, valued with
+// the snapshot's prices, and never count towards fund totals.
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+	"github.com/mtlprog/stat/internal/stellarkey"
+	"github.com/mtlprog/stat/internal/whale"
+)
+
+// MaxLabelLength bounds Entry.Label.
+const MaxLabelLength = 100
+
+var (
+	// ErrNotFound indicates the address isn't on the watchlist.
+	ErrNotFound = errors.New("watchlist entry not found")
+	// ErrInvalid indicates an entry that fails validation.
+	ErrInvalid = errors.New("invalid watchlist entry")
+)
+
+// Entry is one watched account. Without Assets every non-zero balance of
+// the account is captured.
+type Entry struct {
+	ID        int64              `json:"id"`
+	Address   string             `json:"address"`
+	Label     string             `json:"label"`
+	Assets    []domain.AssetInfo `json:"assets"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// Validate checks the address, the label and that no asset is listed twice.
+// Fund accounts are rejected: their balances are already in the snapshot.
+func (e Entry) Validate() error {
+	if !stellarkey.ValidAddress(e.Address) {
+		return fmt.Errorf("%w: %q is not a G... Stellar address", ErrInvalid, e.Address)
+	}
+	for _, a := range domain.AccountRegistry() {
+		if a.Address == e.Address {
+			return fmt.Errorf("%w: %s is the fund account %s", ErrInvalid, e.Address, a.Name)
+		}
+	}
+	if n := utf8.RuneCountInString(strings.TrimSpace(e.Label)); n == 0 || n > MaxLabelLength {
+		return fmt.Errorf("%w: label must be 1 to %d characters", ErrInvalid, MaxLabelLength)
+	}
+	seen := make(map[string]bool, len(e.Assets))
+	for _, a := range e.Assets {
+		if seen[a.Canonical()] {
+			return fmt.Errorf("%w: asset %s listed twice", ErrInvalid, a.Canonical())
+		}
+		seen[a.Canonical()] = true
+	}
+	return nil
+}
+
+// ParseAsset reads XLM (or native) and CODE:ISSUER.
+func ParseAsset(s string) (domain.AssetInfo, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "XLM") || s == "native" {
+		return domain.XLMAsset(), nil
+	}
+	code, issuer, ok := strings.Cut(s, ":")
+	if !ok || code == "" || len(code) > 12 || !stellarkey.ValidAddress(issuer) {
+		return domain.AssetInfo{}, fmt.Errorf("%w: asset %q, want XLM or CODE:ISSUER", ErrInvalid, s)
+	}
+	return domain.NewAssetInfo(code, issuer), nil
+}
+
+// Balances returns the balances of the tracked assets in acc, valued with
+// prices; a tracked asset without a trustline has a zero balance. Without
+// tracked assets every non-zero balance is returned, liquidity pool shares
+// excepted. The total sums the priced balances.
+func Balances(acc horizon.HorizonAccount, tracked []domain.AssetInfo, prices whale.Prices) ([]domain.WatchedBalance, decimal.Decimal) {
+	held := make(map[string]decimal.Decimal, len(acc.Balances))
+	var order []domain.AssetInfo
+	for _, b := range acc.Balances {
+		var asset domain.AssetInfo
+		switch b.AssetType {
+		case "native":
+			asset = domain.XLMAsset()
+		case "liquidity_pool_shares":
+			continue
+		default:
+			asset = domain.NewAssetInfo(b.AssetCode, b.AssetIssuer)
+		}
+		held[asset.Canonical()] = domain.SafeParse(b.Balance)
+		order = append(order, asset)
+	}
+	if len(tracked) == 0 {
+		for _, a := range order {
+			if held[a.Canonical()].IsPositive() {
+				tracked = append(tracked, a)
+			}
+		}
+	}
+
+	out := make([]domain.WatchedBalance, 0, len(tracked))
+	total := decimal.Zero
+	for _, a := range tracked {
+		b := domain.WatchedBalance{Asset: a, Balance: held[a.Canonical()]}
+		if price, ok := prices[snapshot.AssetKey(a)]; ok {
+			v := b.Balance.Mul(price).Round(2)
+			b.ValueEURMTL = &v
+			total = total.Add(v)
+		}
+		out = append(out, b)
+	}
+	return out, total
+}
+
+// AccountSource fetches an account (horizon.Client).
+type AccountSource interface {
+	FetchAccount(ctx context.Context, accountID string) (horizon.HorizonAccount, error)
+}
+
+// EntrySource lists the watchlist (PgRepository).
+type EntrySource interface {
+	List(ctx context.Context, slug string) ([]Entry, error)
+}
+
+// Service fills FundStructureData.Watchlist from the stored watchlist.
+type Service struct {
+	accounts AccountSource
+	entries  EntrySource
+	slug     string
+}
+
+// NewService creates a watchlist Service for the entity slug.
+func NewService(accounts AccountSource, entries EntrySource, slug string) *Service {
+	return &Service{accounts: accounts, entries: entries, slug: slug}
+}
+
+// EnrichMetrics implements snapshot.MetricsEnricher. It runs after the fund
+// accounts are priced, so balances are valued at the snapshot's prices. An
+// account that can't be fetched is recorded with its error; only a failed
+// watchlist read or a cancelled ctx is returned.
+func (s *Service) EnrichMetrics(ctx context.Context, _ time.Time, data *domain.FundStructureData) error {
+	entries, err := s.entries.List(ctx, s.slug)
+	if err != nil {
+		return fmt.Errorf("loading watchlist: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	prices := whale.PricesFrom(*data)
+	watched := make([]domain.WatchedAccount, 0, len(entries))
+	for _, e := range entries {
+		w := domain.WatchedAccount{Label: e.Label, Address: e.Address, Balances: []domain.WatchedBalance{}}
+		acc, err := s.accounts.FetchAccount(ctx, e.Address)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			slog.Error("watched account unavailable", "label", e.Label, "address", e.Address, "error", err)
+			w.Error = err.Error()
+		} else {
+			w.Balances, w.TotalEURMTL = Balances(acc, e.Assets, prices)
+		}
+		watched = append(watched, w)
+	}
+	data.Watchlist = watched
+	return nil
+}
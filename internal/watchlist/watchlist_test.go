@@ -0,0 +1,100 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/chain"
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/whale"
+)
+
+const partner = "GDNHQWZRZDZZBARNOH6VFFXMN6LBUNZTZHOKBUT7GREOWBTZI4FGS7IQ"
+
+var mtl = domain.NewAssetInfo("MTL", domain.IssuerAddress)
+
+func TestValidateAndParseAsset(t *testing.T) {
+	ok := Entry{Address: partner, Label: "Partner", Assets: []domain.AssetInfo{mtl, domain.XLMAsset()}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("valid entry: %v", err)
+	}
+	for name, e := range map[string]Entry{
+		"bad address":  {Address: "GBAD", Label: "x"},
+		"fund account": {Address: domain.IssuerAddress, Label: "x"},
+		"no label":     {Address: partner, Label: " "},
+		"duplicate":    {Address: partner, Label: "x", Assets: []domain.AssetInfo{mtl, mtl}},
+	} {
+		if err := e.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+
+	if a, err := ParseAsset("MTL:" + domain.IssuerAddress); err != nil || a != mtl {
+		t.Errorf("ParseAsset(MTL) = %+v, %v", a, err)
+	}
+	if a, err := ParseAsset("native"); err != nil || !a.IsNative() {
+		t.Errorf("ParseAsset(native) = %+v, %v", a, err)
+	}
+	if _, err := ParseAsset("MTL"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("ParseAsset without issuer: err = %v", err)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	acc := horizon.HorizonAccount{Balances: []horizon.HorizonBalance{
+		{AssetType: "credit_alphanum4", AssetCode: "MTL", AssetIssuer: domain.IssuerAddress, Balance: "10.0000000"},
+		{AssetType: "credit_alphanum4", AssetCode: "ZERO", AssetIssuer: domain.IssuerAddress, Balance: "0.0000000"},
+		{AssetType: "liquidity_pool_shares", LiquidityPoolID: "abc", Balance: "5.0000000"},
+		{AssetType: "native", Balance: "100.0000000"},
+	}}
+	prices := whale.PricesFrom(domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{
+		Tokens: []domain.TokenPriceWithBalance{{Asset: mtl, PriceInEURMTL: ptr("4")}},
+	}}})
+
+	all, total := Balances(acc, nil, prices)
+	if len(all) != 2 || all[0].Asset != mtl || !all[1].Asset.IsNative() {
+		t.Fatalf("untracked = %+v, want MTL and XLM", all)
+	}
+	if all[0].ValueEURMTL == nil || !all[0].ValueEURMTL.Equal(decimal.NewFromInt(40)) || all[1].ValueEURMTL != nil || !total.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("values = %+v, total %s; want MTL at 40 and unpriced XLM", all, total)
+	}
+
+	eurmtl := domain.EURMTLAsset()
+	tracked, total := Balances(acc, []domain.AssetInfo{eurmtl, mtl}, prices)
+	if len(tracked) != 2 || !tracked[0].Balance.IsZero() || tracked[0].ValueEURMTL == nil || !total.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("tracked = %+v, total %s; want EURMTL at zero then MTL", tracked, total)
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+type stubEntries []Entry
+
+func (s stubEntries) List(context.Context, string) ([]Entry, error) { return s, nil }
+
+func TestEnrichMetrics(t *testing.T) {
+	other := "GACKTN5DAZGWXRWB2WLM6OPBDHAMT6SJNGLJZPQMEZBUR4JUGBX2UK7V"
+	svc := NewService(chain.NewFake().PutAccount(horizon.HorizonAccount{ID: partner, Balances: []horizon.HorizonBalance{{AssetType: "native", Balance: "3"}}}),
+		stubEntries{{Address: partner, Label: "Partner"}, {Address: other, Label: "Gone"}}, "mtlf")
+
+	var data domain.FundStructureData
+	if err := svc.EnrichMetrics(context.Background(), time.Now(), &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Watchlist) != 2 || len(data.Watchlist[0].Balances) != 1 || data.Watchlist[1].Error == "" || data.Watchlist[1].Balances == nil {
+		t.Errorf("watchlist = %+v, want the partner's XLM and an error for the missing account", data.Watchlist)
+	}
+	if !data.AggregatedTotals.TotalEURMTL.IsZero() {
+		t.Error("watched balances leaked into fund totals")
+	}
+
+	data = domain.FundStructureData{}
+	if err := NewService(chain.NewFake(), stubEntries{}, "mtlf").EnrichMetrics(context.Background(), time.Now(), &data); err != nil || data.Watchlist != nil {
+		t.Errorf("empty watchlist: %+v, %v", data.Watchlist, err)
+	}
+}
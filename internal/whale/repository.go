@@ -0,0 +1,117 @@
+package whale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRepository stores alerts in whale_alerts and scan cursors in whale_cursors.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PostgreSQL whale alert repository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// Cursor returns the stored cursor of account, or "" before its first scan.
+func (r *PgRepository) Cursor(ctx context.Context, slug, account string) (string, error) {
+	var cursor string
+	err := r.pool.QueryRow(ctx,
+		`SELECT wc.cursor
+		 FROM whale_cursors wc
+		 JOIN fund_entities fe ON fe.id = wc.entity_id
+		 WHERE fe.slug = $1 AND wc.account = $2`, slug, account).Scan(&cursor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("loading whale cursor of %s: %w", account, err)
+	}
+	return cursor, nil
+}
+
+// Save stores alerts and advances the cursor of account in one transaction.
+// An alert already stored for the same operation is kept as it was.
+func (r *PgRepository) Save(ctx context.Context, slug, account, cursor string, alerts []Alert) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning whale alert tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var entityID int
+	if err := tx.QueryRow(ctx, `SELECT id FROM fund_entities WHERE slug = $1`, slug).Scan(&entityID); err != nil {
+		return fmt.Errorf("resolving entity %q: %w", slug, err)
+	}
+	for _, a := range alerts {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO whale_alerts (entity_id, operation_id, account, account_name, direction, counterparty,
+			     asset, amount, value_eurmtl, memo, tx_hash, occurred_at, notified)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			 ON CONFLICT (entity_id, operation_id, account) DO NOTHING`,
+			entityID, a.OperationID, a.Account, a.AccountName, string(a.Direction), a.Counterparty, a.Asset,
+			a.Amount, a.ValueEURMTL, a.Memo, a.TxHash, a.At, a.Notified); err != nil {
+			return fmt.Errorf("saving whale alert %s: %w", a.OperationID, err)
+		}
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO whale_cursors (entity_id, account, cursor) VALUES ($1, $2, $3)
+		 ON CONFLICT (entity_id, account) DO UPDATE
+		 SET cursor = EXCLUDED.cursor, updated_at = CURRENT_TIMESTAMP`,
+		entityID, account, cursor); err != nil {
+		return fmt.Errorf("saving whale cursor of %s: %w", account, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing whale alerts: %w", err)
+	}
+	return nil
+}
+
+// List returns the alerts that occurred between from and to (inclusive;
+// zero means unbounded), oldest first.
+func (r *PgRepository) List(ctx context.Context, slug string, from, to time.Time) ([]Alert, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT wa.operation_id, wa.account, wa.account_name, wa.direction, wa.counterparty, wa.asset, wa.amount,
+		        wa.value_eurmtl, wa.memo, wa.tx_hash, wa.occurred_at, wa.notified
+		 FROM whale_alerts wa
+		 JOIN fund_entities fe ON fe.id = wa.entity_id
+		 WHERE fe.slug = $1
+		   AND ($2::timestamptz IS NULL OR wa.occurred_at >= $2)
+		   AND ($3::timestamptz IS NULL OR wa.occurred_at <= $3)
+		 ORDER BY wa.occurred_at, wa.operation_id`,
+		slug, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("listing whale alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Alert
+	for rows.Next() {
+		var a Alert
+		var direction string
+		if err := rows.Scan(&a.OperationID, &a.Account, &a.AccountName, &direction, &a.Counterparty, &a.Asset, &a.Amount,
+			&a.ValueEURMTL, &a.Memo, &a.TxHash, &a.At, &a.Notified); err != nil {
+			return nil, fmt.Errorf("scanning whale alert: %w", err)
+		}
+		a.Direction = Direction(direction)
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating whale alerts: %w", err)
+	}
+	return out, nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
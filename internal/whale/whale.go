@@ -0,0 +1,244 @@
+// Package whale watches the payments of the fund accounts and raises an
+// alert for every transfer worth at least a threshold in EURMTL, incoming or
+// outgoing. Alerts are sent through the notification providers and kept in
+// a log, so a large movement is noticed the day it happens rather than in
+// the next snapshot's balances.
+package whale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+// Direction is the side of the fund account in a transfer.
+type Direction string
+
+const (
+	In  Direction = "in"
+	Out Direction = "out"
+)
+
+// Alert is one large transfer into or out of a fund account.
+type Alert struct {
+	OperationID  string          `json:"operationId"`
+	Account      string          `json:"account"`
+	AccountName  string          `json:"accountName"`
+	Direction    Direction       `json:"direction"`
+	Counterparty string          `json:"counterparty"`
+	Asset        string          `json:"asset"` // snapshot.AssetKey: XLM or CODE-ISSUER
+	Amount       decimal.Decimal `json:"amount"`
+	ValueEURMTL  decimal.Decimal `json:"valueEURMTL"`
+	Memo         string          `json:"memo"`
+	TxHash       string          `json:"txHash"`
+	At           time.Time       `json:"at"`
+	Notified     bool            `json:"notified"`
+}
+
+// Prices maps snapshot.AssetKey to the EURMTL price of one unit.
+type Prices map[string]decimal.Decimal
+
+// PricesFrom collects the EURMTL prices of every asset valued in data.
+// EURMTL itself is always 1.
+func PricesFrom(data domain.FundStructureData) Prices {
+	p := Prices{snapshot.AssetKey(domain.EURMTLAsset()): decimal.NewFromInt(1)}
+	for _, group := range [][]domain.FundAccountPortfolio{data.Accounts, data.MutualFunds, data.OtherAccounts} {
+		for _, acc := range group {
+			if acc.XLMPriceInEURMTL != nil {
+				if price := domain.SafeParse(*acc.XLMPriceInEURMTL); price.IsPositive() {
+					p["XLM"] = price
+				}
+			}
+			for _, t := range acc.Tokens {
+				if t.PriceInEURMTL == nil {
+					continue
+				}
+				if price := domain.SafeParse(*t.PriceInEURMTL); price.IsPositive() {
+					p[snapshot.AssetKey(t.Asset)] = price
+				}
+			}
+		}
+	}
+	return p
+}
+
+// Detect returns the alerts among the transfers of account: those worth at
+// least threshold EURMTL. Transfers with another fund account are internal
+// rebalancing and never alert; assets without a price are skipped, which
+// also keeps spam tokens out.
+func Detect(account domain.FundAccount, transfers []horizon.AccountTransfer, prices Prices, threshold decimal.Decimal, fund map[string]bool) []Alert {
+	var out []Alert
+	for _, t := range transfers {
+		a := Alert{
+			OperationID: t.ID, Account: account.Address, AccountName: account.Name,
+			Asset: snapshot.AssetKey(t.Asset), Amount: t.Amount, Memo: t.Memo, TxHash: t.TxHash, At: t.TS,
+		}
+		switch account.Address {
+		case t.To:
+			a.Direction, a.Counterparty = In, t.From
+		case t.From:
+			a.Direction, a.Counterparty = Out, t.To
+		default:
+			continue
+		}
+		if fund[a.Counterparty] {
+			continue
+		}
+		price, ok := prices[a.Asset]
+		if !ok {
+			continue
+		}
+		a.ValueEURMTL = t.Amount.Mul(price).Round(2)
+		if a.ValueEURMTL.LessThan(threshold) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// Message renders an alert as the HTML notification text.
+func Message(a Alert) string {
+	arrow, verb := "⬇️", "от"
+	if a.Direction == Out {
+		arrow, verb = "⬆️", "на"
+	}
+	code, _, _ := strings.Cut(a.Asset, "-")
+	msg := fmt.Sprintf("<b>%s Крупный перевод: %s</b>\n%s %s (≈ %s EURMTL) %s <code>%s</code>\n<a href=\"https://stellar.expert/explorer/public/tx/%s\">Транзакция</a>",
+		arrow, html.EscapeString(a.AccountName), a.Amount.String(), html.EscapeString(code), a.ValueEURMTL.StringFixed(2),
+		verb, a.Counterparty, a.TxHash)
+	if a.Memo != "" {
+		msg += "\nMemo: " + html.EscapeString(a.Memo)
+	}
+	return msg
+}
+
+// TransferSource walks an account's payments (horizon.Client).
+type TransferSource interface {
+	FetchAccountTransfers(ctx context.Context, account, cursor string) ([]horizon.AccountTransfer, string, error)
+}
+
+// SnapshotSource provides the latest snapshot for prices (snapshot.PgRepository).
+type SnapshotSource interface {
+	GetLatest(ctx context.Context, slug string) (*snapshot.Snapshot, error)
+}
+
+// Store persists cursors and alerts (PgRepository).
+type Store interface {
+	Cursor(ctx context.Context, slug, account string) (string, error)
+	Save(ctx context.Context, slug, account, cursor string, alerts []Alert) error
+}
+
+// Notifier delivers an HTML message (notify.Service).
+type Notifier interface {
+	SendMessage(ctx context.Context, date time.Time, msg string) error
+}
+
+// Service scans the fund accounts for large transfers.
+type Service struct {
+	transfers TransferSource
+	snapshots SnapshotSource
+	store     Store
+	notifier  Notifier
+	accounts  []domain.FundAccount
+	threshold decimal.Decimal
+	slug      string
+}
+
+// NewService creates a Service watching accounts of the entity slug. A nil
+// notifier only logs the alerts.
+func NewService(transfers TransferSource, snapshots SnapshotSource, store Store, notifier Notifier,
+	accounts []domain.FundAccount, threshold decimal.Decimal, slug string) *Service {
+	return &Service{transfers: transfers, snapshots: snapshots, store: store, notifier: notifier,
+		accounts: accounts, threshold: threshold, slug: slug}
+}
+
+// Result is the outcome of one Run.
+type Result struct {
+	Alerts []Alert
+	Failed []string // names of the accounts whose scan failed
+}
+
+// Run scans every account from its stored cursor, sends one notification per
+// alert and stores the alerts together with the new cursor. A failed send is
+// logged and the alert is stored as not notified. An account that fails to
+// scan keeps its cursor and is retried on the next run; the others proceed.
+// The error is only for failures that stop the whole run.
+func (s *Service) Run(ctx context.Context) (Result, error) {
+	prices, err := s.prices(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	fund := make(map[string]bool, len(s.accounts))
+	for _, a := range s.accounts {
+		fund[a.Address] = true
+	}
+
+	var res Result
+	for _, acc := range s.accounts {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		alerts, err := s.scan(ctx, acc, prices, fund)
+		if err != nil {
+			slog.Error("whale scan failed", "account", acc.Name, "error", err)
+			res.Failed = append(res.Failed, acc.Name)
+			continue
+		}
+		res.Alerts = append(res.Alerts, alerts...)
+	}
+	return res, nil
+}
+
+func (s *Service) scan(ctx context.Context, acc domain.FundAccount, prices Prices, fund map[string]bool) ([]Alert, error) {
+	cursor, err := s.store.Cursor(ctx, s.slug, acc.Address)
+	if err != nil {
+		return nil, err
+	}
+	transfers, next, err := s.transfers.FetchAccountTransfers(ctx, acc.Address, cursor)
+	if err != nil {
+		return nil, err
+	}
+	alerts := Detect(acc, transfers, prices, s.threshold, fund)
+	for i := range alerts {
+		a := &alerts[i]
+		slog.Info("whale transfer detected", "account", acc.Name, "direction", a.Direction, "counterparty", a.Counterparty,
+			"asset", a.Asset, "amount", a.Amount.String(), "valueEURMTL", a.ValueEURMTL.String(), "tx", a.TxHash)
+		if s.notifier == nil {
+			continue
+		}
+		if err := s.notifier.SendMessage(ctx, a.At, Message(*a)); err != nil {
+			slog.Error("whale alert notification failed", "tx", a.TxHash, "error", err)
+			continue
+		}
+		a.Notified = true
+	}
+	if err := s.store.Save(ctx, s.slug, acc.Address, next, alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// prices reads the EURMTL prices from the latest snapshot. Without one no
+// transfer can be valued, which is an error rather than a silent no-op.
+func (s *Service) prices(ctx context.Context) (Prices, error) {
+	snap, err := s.snapshots.GetLatest(ctx, s.slug)
+	if err != nil {
+		return nil, fmt.Errorf("loading latest snapshot for prices: %w", err)
+	}
+	var data domain.FundStructureData
+	if err := json.Unmarshal(snap.Data, &data); err != nil {
+		return nil, fmt.Errorf("decoding snapshot %s: %w", snap.SnapshotDate.Format("2006-01-02"), err)
+	}
+	return PricesFrom(data), nil
+}
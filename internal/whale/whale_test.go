@@ -0,0 +1,148 @@
+package whale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mtlprog/stat/internal/domain"
+	"github.com/mtlprog/stat/internal/horizon"
+	"github.com/mtlprog/stat/internal/snapshot"
+)
+
+func strPtr(s string) *string { return &s }
+
+var (
+	fundAcc = domain.FundAccount{Name: "DEFI", Address: "GDEFI"}
+	mtl     = domain.NewAssetInfo("MTL", domain.IssuerAddress)
+	spam    = domain.NewAssetInfo("EURMTL", "GSPAM")
+	at      = time.Date(2026, 10, 2, 10, 0, 0, 0, time.UTC)
+)
+
+func testData() domain.FundStructureData {
+	return domain.FundStructureData{Accounts: []domain.FundAccountPortfolio{{
+		XLMPriceInEURMTL: strPtr("0.25"),
+		Tokens:           []domain.TokenPriceWithBalance{{Asset: mtl, PriceInEURMTL: strPtr("4")}},
+	}}}
+}
+
+func TestPricesFrom(t *testing.T) {
+	p := PricesFrom(testData())
+	if !p["XLM"].Equal(decimal.RequireFromString("0.25")) || !p[snapshot.AssetKey(mtl)].Equal(decimal.NewFromInt(4)) {
+		t.Errorf("prices = %v", p)
+	}
+	if !p[snapshot.AssetKey(domain.EURMTLAsset())].Equal(decimal.NewFromInt(1)) {
+		t.Error("EURMTL isn't priced at 1")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	transfers := []horizon.AccountTransfer{
+		{ID: "1", From: "GWHALE", To: "GDEFI", Asset: mtl, Amount: decimal.NewFromInt(3000), Memo: "buy", TS: at}, // 12000 EURMTL in
+		{ID: "2", From: "GDEFI", To: "GOUT", Asset: domain.XLMAsset(), Amount: decimal.NewFromInt(50000), TS: at}, // 12500 EURMTL out
+		{ID: "3", From: "GDEFI", To: "GSMALL", Asset: mtl, Amount: decimal.NewFromInt(10), TS: at},                // below threshold
+		{ID: "4", From: "GDEFI", To: "GMCITY", Asset: mtl, Amount: decimal.NewFromInt(9000), TS: at},              // internal
+		{ID: "5", From: "GSPAMMER", To: "GDEFI", Asset: spam, Amount: decimal.NewFromInt(1e9), TS: at},            // unpriced
+		{ID: "6", From: "GA", To: "GB", Asset: mtl, Amount: decimal.NewFromInt(9000), TS: at},                     // not this account
+	}
+	alerts := Detect(fundAcc, transfers, PricesFrom(testData()), decimal.NewFromInt(10000), map[string]bool{"GDEFI": true, "GMCITY": true})
+	if len(alerts) != 2 {
+		t.Fatalf("got %d alerts %+v, want 2", len(alerts), alerts)
+	}
+	if a := alerts[0]; a.Direction != In || a.Counterparty != "GWHALE" || !a.ValueEURMTL.Equal(decimal.NewFromInt(12000)) || a.AccountName != "DEFI" {
+		t.Errorf("incoming alert = %+v", a)
+	}
+	if a := alerts[1]; a.Direction != Out || a.Counterparty != "GOUT" || a.Asset != "XLM" {
+		t.Errorf("outgoing alert = %+v", a)
+	}
+	if msg := Message(alerts[0]); !strings.Contains(msg, "3000 MTL") || !strings.Contains(msg, "GWHALE") || !strings.Contains(msg, "Memo: buy") {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+type stubTransfers struct {
+	transfers []horizon.AccountTransfer
+	err       error
+}
+
+func (s *stubTransfers) FetchAccountTransfers(_ context.Context, _, cursor string) ([]horizon.AccountTransfer, string, error) {
+	return s.transfers, cursor + "+", s.err
+}
+
+type stubSnapshots struct{ data []byte }
+
+func (s stubSnapshots) GetLatest(context.Context, string) (*snapshot.Snapshot, error) {
+	return &snapshot.Snapshot{Data: s.data}, nil
+}
+
+type memStore struct {
+	cursors map[string]string
+	alerts  []Alert
+}
+
+func (m *memStore) Cursor(_ context.Context, _, account string) (string, error) {
+	return m.cursors[account], nil
+}
+
+func (m *memStore) Save(_ context.Context, _, account, cursor string, alerts []Alert) error {
+	m.cursors[account] = cursor
+	m.alerts = append(m.alerts, alerts...)
+	return nil
+}
+
+type stubNotifier struct {
+	sent []string
+	err  error
+}
+
+func (n *stubNotifier) SendMessage(_ context.Context, _ time.Time, msg string) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func TestServiceRun(t *testing.T) {
+	data, _ := json.Marshal(testData())
+	store := &memStore{cursors: map[string]string{"GDEFI": "10"}}
+	notifier := &stubNotifier{}
+	src := &stubTransfers{transfers: []horizon.AccountTransfer{
+		{ID: "11", From: "GWHALE", To: "GDEFI", Asset: mtl, Amount: decimal.NewFromInt(3000), TS: at},
+	}}
+	svc := NewService(src, stubSnapshots{data}, store, notifier, []domain.FundAccount{fundAcc}, decimal.NewFromInt(10000), "mtlf")
+
+	res, err := svc.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Alerts) != 1 || !res.Alerts[0].Notified || len(notifier.sent) != 1 {
+		t.Errorf("alerts = %+v, sent = %d", res.Alerts, len(notifier.sent))
+	}
+	if store.cursors["GDEFI"] != "10+" || len(store.alerts) != 1 {
+		t.Errorf("cursor = %q, stored = %d", store.cursors["GDEFI"], len(store.alerts))
+	}
+
+	// A failed send still stores the alert and advances the cursor.
+	notifier.err = errors.New("grist down")
+	if res, err := svc.Run(context.Background()); err != nil || res.Alerts[0].Notified {
+		t.Errorf("failed send: result = %+v, err = %v", res, err)
+	}
+	if store.cursors["GDEFI"] != "10++" {
+		t.Errorf("cursor = %q, want advanced", store.cursors["GDEFI"])
+	}
+
+	// A failed scan keeps the cursor.
+	src.err = errors.New("horizon down")
+	if res, err := svc.Run(context.Background()); err != nil || len(res.Failed) != 1 || res.Failed[0] != "DEFI" {
+		t.Errorf("scan failure: result = %+v, err = %v", res, err)
+	}
+	if store.cursors["GDEFI"] != "10++" {
+		t.Errorf("cursor = %q, want unchanged", store.cursors["GDEFI"])
+	}
+}
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id            BIGSERIAL PRIMARY KEY,
+    kind          VARCHAR(64) NOT NULL,
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE        NOT NULL,
+    status        VARCHAR(16) NOT NULL,
+    progress      JSONB,
+    result        JSONB,
+    error         TEXT,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at    TIMESTAMP WITH TIME ZONE,
+    finished_at   TIMESTAMP WITH TIME ZONE
+);
+
+-- At most one queued/running job per kind+entity+date; a second POST reuses it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_active
+    ON jobs(kind, entity_id, snapshot_date)
+    WHERE status IN ('queued', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status
+    ON jobs(status, created_at);
@@ -0,0 +1,10 @@
+-- Deltas can only be reconstructed in Go: run `stat compact --expand` first.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM fund_snapshots WHERE base_id IS NOT NULL) THEN
+        RAISE EXCEPTION 'fund_snapshots has delta rows; run stat compact --expand before rolling back';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_fund_snapshots_base;
+ALTER TABLE fund_snapshots DROP COLUMN IF EXISTS base_id;
@@ -0,0 +1,7 @@
+-- Delta rows store a jsondiff patch in data against the full snapshot base_id.
+ALTER TABLE fund_snapshots
+    ADD COLUMN IF NOT EXISTS base_id INTEGER REFERENCES fund_snapshots(id);
+
+CREATE INDEX IF NOT EXISTS idx_fund_snapshots_base
+    ON fund_snapshots(base_id)
+    WHERE base_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_fund_snapshots_holdings;
+ALTER TABLE fund_snapshots DROP COLUMN IF EXISTS holdings;
@@ -0,0 +1,9 @@
+-- holdings: {"CODE-ISSUER" | "XLM": {"<account address>": "<balance>"}} for
+-- non-zero balances. Always a full document, even on delta rows, so token
+-- lookups can stay in SQL. Populated by Save; `stat backfill-holdings` fills
+-- rows written before this migration.
+ALTER TABLE fund_snapshots
+    ADD COLUMN IF NOT EXISTS holdings JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_fund_snapshots_holdings
+    ON fund_snapshots USING GIN (holdings);
@@ -0,0 +1,11 @@
+-- Daily EUR quote per external symbol. external_quotes keeps only the latest
+-- value; this keeps one row per UTC day so past snapshots can be revalued with
+-- the quote of their own date. `stat quote` records today's row, `stat quote
+-- backfill` fills past dates from CoinGecko's market_chart/range.
+CREATE TABLE IF NOT EXISTS quote_history (
+    symbol VARCHAR(10) NOT NULL,
+    quote_date DATE NOT NULL,
+    price_in_eur NUMERIC NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (symbol, quote_date)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE fund_snapshots
+    DROP COLUMN IF EXISTS signer,
+    DROP COLUMN IF EXISTS signature,
+    DROP COLUMN IF EXISTS hash;
@@ -0,0 +1,8 @@
+-- Integrity seal written by Save: hex SHA-256 of the canonical JSON of the
+-- full document (see snapshot.Canonicalize), and optionally a base64 ed25519
+-- signature of "stat-snapshot:<date>:<hash>" by the Stellar key in signer.
+-- Rows stored before this migration have no seal.
+ALTER TABLE fund_snapshots
+    ADD COLUMN IF NOT EXISTS hash TEXT,
+    ADD COLUMN IF NOT EXISTS signature TEXT,
+    ADD COLUMN IF NOT EXISTS signer TEXT;
@@ -0,0 +1,13 @@
+-- Month-end / quarter-end summaries built from fund_indicators. One row per
+-- entity and period ("2026-09", "2026-Q3"); regenerating a period replaces it.
+CREATE TABLE IF NOT EXISTS period_reports (
+    entity_id  INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    period     VARCHAR(10) NOT NULL,
+    kind       VARCHAR(10) NOT NULL,
+    start_date DATE        NOT NULL,
+    end_date   DATE        NOT NULL,
+    data       JSONB       NOT NULL,
+    markdown   TEXT        NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, period)
+);
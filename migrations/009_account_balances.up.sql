@@ -0,0 +1,16 @@
+-- Per-account, per-asset balance on each snapshot date, so one account's
+-- history is an index range scan instead of decoding every snapshot. Every
+-- trustline is recorded, zero balances included; a missing row means the
+-- account had no trustline (or was not in the snapshot) that day. Written by
+-- Save alongside holdings; `stat backfill-balances` fills older snapshots.
+CREATE TABLE IF NOT EXISTS account_balances (
+    entity_id    INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    account      VARCHAR(56) NOT NULL,
+    asset        TEXT        NOT NULL,
+    balance_date DATE        NOT NULL,
+    balance      NUMERIC     NOT NULL,
+    PRIMARY KEY (entity_id, account, asset, balance_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_balances_date
+    ON account_balances (entity_id, balance_date);
@@ -0,0 +1,18 @@
+-- Declared configuration of fund accounts. Snapshot generation compares each
+-- account's live settings (FundStructureData.accountConfigs) with its row and
+-- adds a warning per difference. NULL columns are not checked. Rows are
+-- written by `stat account-config pin`.
+CREATE TABLE IF NOT EXISTS account_expectations (
+    entity_id             INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    account               VARCHAR(56) NOT NULL,
+    home_domain           TEXT,
+    inflation_destination TEXT,
+    auth_required         BOOLEAN,
+    auth_revocable        BOOLEAN,
+    auth_immutable        BOOLEAN,
+    auth_clawback_enabled BOOLEAN,
+    num_sponsoring        INTEGER,
+    num_sponsored         INTEGER,
+    updated_at            TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, account)
+);
@@ -0,0 +1 @@
+ALTER TABLE fund_indicators DROP COLUMN IF EXISTS source;
@@ -0,0 +1,6 @@
+-- Provenance of each stored indicator value (see indicator.Source):
+-- measured, recomputed, ledger or sheet. Together with value and computed_at
+-- it tells which MONITORING cells were measured at snapshot time and which
+-- were filled in later. Rows written before this migration have no source.
+ALTER TABLE fund_indicators
+    ADD COLUMN IF NOT EXISTS source VARCHAR(16);
@@ -0,0 +1,10 @@
+-- Montelibero Index (I66) definition per entity: the base date on which the
+-- index reads 100 and the component weights as {"<indicator id>": weight}.
+-- Managed via PUT /api/v1/admin/index; without a row indicator.DefaultIndexConfig
+-- applies.
+CREATE TABLE IF NOT EXISTS index_config (
+    entity_id  INTEGER PRIMARY KEY REFERENCES fund_entities(id) ON DELETE CASCADE,
+    base_date  DATE    NOT NULL,
+    weights    JSONB   NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
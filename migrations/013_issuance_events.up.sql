@@ -0,0 +1,18 @@
+-- MTL / MTLRECT supply changes between consecutive snapshots, one row per
+-- asset per snapshot date, with the issuer operations behind them as JSON
+-- (issuance.Transfer). Written by the report pipeline after each snapshot;
+-- a rerun for the same date replaces that date's rows.
+CREATE TABLE IF NOT EXISTS issuance_events (
+    entity_id    INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    event_date   DATE        NOT NULL,
+    asset        VARCHAR(12) NOT NULL,
+    prev_date    DATE        NOT NULL,
+    kind         VARCHAR(10) NOT NULL,
+    amount       NUMERIC     NOT NULL,
+    prev_supply  NUMERIC     NOT NULL,
+    supply       NUMERIC     NOT NULL,
+    unattributed NUMERIC     NOT NULL,
+    transfers    JSONB       NOT NULL DEFAULT '[]',
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, event_date, asset)
+);
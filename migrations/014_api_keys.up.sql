@@ -0,0 +1,24 @@
+-- Partner API keys. Each key reads one entity's data through the route groups
+-- it lists (apikey.Groups). Only the SHA-256 of the token is stored; the
+-- token itself is shown once when the key is issued. Revoked keys keep their
+-- row and usage history.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(100) NOT NULL,
+    entity_id    INTEGER      NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    route_groups TEXT[]       NOT NULL,
+    token_hash   BYTEA        NOT NULL UNIQUE,
+    token_prefix VARCHAR(16)  NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at   TIMESTAMP WITH TIME ZONE
+);
+
+-- Requests per key, snapshot date and route group.
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id      BIGINT      NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    usage_date  DATE        NOT NULL,
+    route_group VARCHAR(20) NOT NULL,
+    requests    BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, usage_date, route_group)
+);
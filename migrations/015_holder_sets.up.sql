@@ -0,0 +1,12 @@
+-- Accounts holding MTL (MTL or MTLRECT, any positive balance) and MTLAP
+-- (balance ≥ 1) on each snapshot date. Written by the report pipeline after
+-- each snapshot; a rerun for the same date replaces that date's rows. Churn
+-- (new and exited holders) is the difference of two sets.
+CREATE TABLE IF NOT EXISTS holder_sets (
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE        NOT NULL,
+    asset         VARCHAR(12) NOT NULL,
+    accounts      TEXT[]      NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, snapshot_date, asset)
+);
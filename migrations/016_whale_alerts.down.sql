@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS whale_cursors;
+DROP TABLE IF EXISTS whale_alerts;
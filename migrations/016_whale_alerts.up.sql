@@ -0,0 +1,32 @@
+-- Large transfers into or out of fund accounts (whale.Alert), one row per
+-- Horizon operation and fund account, with whether the notification went
+-- out. whale_cursors holds the last Horizon paging token scanned per fund
+-- account, so each `stat whale-alerts` run resumes where the previous one
+-- stopped.
+CREATE TABLE IF NOT EXISTS whale_alerts (
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    operation_id  VARCHAR(32) NOT NULL,
+    account       VARCHAR(56) NOT NULL,
+    account_name  TEXT        NOT NULL,
+    direction     VARCHAR(3)  NOT NULL,
+    counterparty  VARCHAR(56) NOT NULL,
+    asset         VARCHAR(69) NOT NULL,
+    amount        NUMERIC     NOT NULL,
+    value_eurmtl  NUMERIC     NOT NULL,
+    memo          TEXT        NOT NULL DEFAULT '',
+    tx_hash       VARCHAR(64) NOT NULL,
+    occurred_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    notified      BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, operation_id, account)
+);
+
+CREATE INDEX IF NOT EXISTS idx_whale_alerts_occurred ON whale_alerts (entity_id, occurred_at);
+
+CREATE TABLE IF NOT EXISTS whale_cursors (
+    entity_id  INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    account    VARCHAR(56) NOT NULL,
+    cursor     VARCHAR(32) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, account)
+);
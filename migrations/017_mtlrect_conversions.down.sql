@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS conversion_scans;
+DROP TABLE IF EXISTS mtlrect_conversions;
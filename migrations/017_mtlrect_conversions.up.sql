@@ -0,0 +1,27 @@
+-- MTLRECT shares converted to MTL (conversion.Conversion): an MTLRECT
+-- return to the issuer paired with the MTL issuance to the same account that
+-- followed it. A return and an issuance are each used at most once, so a
+-- rescan of an overlapping window adds nothing. conversion_scans holds how
+-- far the issuer's operations have been scanned; without a row the next run
+-- walks the whole history.
+CREATE TABLE IF NOT EXISTS mtlrect_conversions (
+    entity_id   INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    account     VARCHAR(56) NOT NULL,
+    amount      NUMERIC     NOT NULL,
+    received    NUMERIC     NOT NULL,
+    return_tx   VARCHAR(64) NOT NULL,
+    issue_tx    VARCHAR(64) NOT NULL,
+    returned_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, return_tx, account),
+    UNIQUE (entity_id, issue_tx, account)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mtlrect_conversions_occurred ON mtlrect_conversions (entity_id, occurred_at);
+
+CREATE TABLE IF NOT EXISTS conversion_scans (
+    entity_id  INTEGER NOT NULL PRIMARY KEY REFERENCES fund_entities(id) ON DELETE CASCADE,
+    scanned_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS admin_audit;
+ALTER TABLE account_expectations DROP COLUMN IF EXISTS version;
+ALTER TABLE index_config         DROP COLUMN IF EXISTS version;
+ALTER TABLE fund_entities        DROP COLUMN IF EXISTS version;
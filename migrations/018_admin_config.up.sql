@@ -0,0 +1,21 @@
+-- Resource versions for the admin API's optimistic concurrency: a write with
+-- If-Match must name the current version, and every write increments it.
+ALTER TABLE fund_entities        ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE index_config         ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE account_expectations ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- Every change made through /api/v1/admin/..., with the resource before and
+-- after (NULL when it didn't exist or was deleted).
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id         BIGSERIAL    PRIMARY KEY,
+    at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resource   VARCHAR(40)  NOT NULL,
+    key        VARCHAR(255) NOT NULL,
+    action     VARCHAR(10)  NOT NULL,
+    version    INTEGER      NOT NULL,
+    before     JSONB,
+    after      JSONB,
+    remote     VARCHAR(64)  NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_resource ON admin_audit(resource, key, at DESC);
@@ -0,0 +1,13 @@
+-- Free-form notes attached to a snapshot date (annotation.Annotation), e.g. a
+-- tranche received or a valuation methodology change, so anomalous data
+-- points are explained next to the numbers.
+CREATE TABLE IF NOT EXISTS snapshot_annotations (
+    id            BIGSERIAL PRIMARY KEY,
+    entity_id     INTEGER NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE    NOT NULL,
+    text          TEXT    NOT NULL,
+    tags          TEXT[]  NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_annotations_date ON snapshot_annotations (entity_id, snapshot_date);
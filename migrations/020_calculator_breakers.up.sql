@@ -0,0 +1,12 @@
+-- Circuit-breaker state of each indicator calculator (indicator.Breaker),
+-- kept between runs so stat report stops calling a calculator that keeps
+-- failing and serves its last stored values for a while instead.
+CREATE TABLE IF NOT EXISTS calculator_breakers (
+    entity_id  INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    calculator VARCHAR(64) NOT NULL,
+    failures   INTEGER     NOT NULL DEFAULT 0,
+    opened_at  TIMESTAMP WITH TIME ZONE,
+    last_error TEXT        NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, calculator)
+);
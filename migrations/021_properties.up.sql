@@ -0,0 +1,18 @@
+-- Real-estate tokens (MCITY, MFApart) registered with their property data.
+-- A row values its token as area_m2 × the external quote price_symbol (EUR per
+-- square metre), or as appraisal_value when no symbol is set, taking the place
+-- of the token's _COST / _1COST DATA entries. Rows are written through
+-- /api/v1/admin/entities/{slug}/properties.
+CREATE TABLE IF NOT EXISTS properties (
+    entity_id       INTEGER       NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    token           VARCHAR(12)   NOT NULL,
+    name            TEXT          NOT NULL DEFAULT '',
+    location        TEXT          NOT NULL DEFAULT '',
+    area_m2         NUMERIC(12,2) NOT NULL,
+    price_symbol    VARCHAR(32)   NOT NULL DEFAULT '',
+    appraisal_value NUMERIC(20,2),
+    appraised_on    DATE,
+    version         INTEGER       NOT NULL DEFAULT 1,
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, token)
+);
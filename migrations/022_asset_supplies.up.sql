@@ -0,0 +1,19 @@
+-- Total supply of every asset the fund accounts issue, one row per asset per
+-- snapshot date. delta is the change since the snapshot of prev_date (both
+-- NULL when there was none to compare with); expected marks changes of codes
+-- listed in SUPPLY_EXPECTED_CHANGES, which don't alert. Written by the report
+-- pipeline after each snapshot; a rerun for the same date replaces that
+-- date's rows.
+CREATE TABLE IF NOT EXISTS asset_supplies (
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE        NOT NULL,
+    asset         VARCHAR(69) NOT NULL,
+    issuer_name   TEXT        NOT NULL DEFAULT '',
+    supply        NUMERIC     NOT NULL,
+    prev_date     DATE,
+    delta         NUMERIC,
+    expected      BOOLEAN     NOT NULL DEFAULT FALSE,
+    notified      BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, asset, snapshot_date)
+);
@@ -0,0 +1,19 @@
+-- Sheets exports still owed for stored snapshots, one row per snapshot date.
+-- The report pipeline inserts (or, on a rerun, resets) the row once the
+-- date's indicators are saved; the export worker sets exported_at on success
+-- and otherwise counts the attempt, keeps the error and pushes
+-- next_attempt_at back with exponential backoff.
+CREATE TABLE IF NOT EXISTS export_outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    entity_id       INTEGER NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date   DATE    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT    NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    exported_at     TIMESTAMP WITH TIME ZONE,
+    UNIQUE (entity_id, snapshot_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_outbox_pending
+    ON export_outbox (entity_id, snapshot_date) WHERE exported_at IS NULL;
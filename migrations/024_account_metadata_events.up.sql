@@ -0,0 +1,20 @@
+-- Changes of the fund accounts' on-chain metadata (home domain and the DATA
+-- entries named by ACCOUNT_METADATA_KEYS) between consecutive snapshots,
+-- one row per account and field. field is 'home_domain' or the DATA key;
+-- kind is added, changed or removed. Written by the report pipeline after
+-- each snapshot; a rerun for the same date replaces that date's rows.
+CREATE TABLE IF NOT EXISTS account_metadata_events (
+    id            BIGSERIAL   PRIMARY KEY,
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE        NOT NULL,
+    prev_date     DATE        NOT NULL,
+    account       VARCHAR(56) NOT NULL,
+    account_name  TEXT        NOT NULL DEFAULT '',
+    field         TEXT        NOT NULL,
+    kind          VARCHAR(8)  NOT NULL,
+    old_value     TEXT        NOT NULL DEFAULT '',
+    new_value     TEXT        NOT NULL DEFAULT '',
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_metadata_events_date ON account_metadata_events (entity_id, snapshot_date);
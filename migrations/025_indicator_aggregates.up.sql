@@ -0,0 +1,29 @@
+-- Weekly and monthly roll-ups of fund_indicators for long-range charts, one
+-- row per indicator per period. period_start is the Monday of an ISO week or
+-- the first day of a month; close is the value on close_date, the last day
+-- of the period with a value. indicator.PgRepository.Save recomputes the
+-- periods of the date it writes, in the same transaction, so the rows never
+-- disagree with fund_indicators.
+CREATE TABLE IF NOT EXISTS indicator_aggregates (
+    entity_id    INTEGER    NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    resolution   VARCHAR(8) NOT NULL,
+    period_start DATE       NOT NULL,
+    indicator_id INTEGER    NOT NULL,
+    min_value    NUMERIC    NOT NULL,
+    max_value    NUMERIC    NOT NULL,
+    avg_value    NUMERIC    NOT NULL,
+    close_value  NUMERIC    NOT NULL,
+    close_date   DATE       NOT NULL,
+    samples      INTEGER    NOT NULL,
+    PRIMARY KEY (entity_id, resolution, indicator_id, period_start)
+);
+
+INSERT INTO indicator_aggregates
+    (entity_id, resolution, period_start, indicator_id, min_value, max_value, avg_value, close_value, close_date, samples)
+SELECT fi.entity_id, r.resolution, date_trunc(r.resolution, fi.snapshot_date::timestamp)::date, fi.indicator_id,
+       MIN(fi.value), MAX(fi.value), AVG(fi.value),
+       (ARRAY_AGG(fi.value ORDER BY fi.snapshot_date DESC))[1], MAX(fi.snapshot_date), COUNT(*)
+FROM fund_indicators fi
+CROSS JOIN (VALUES ('week'), ('month')) AS r(resolution)
+GROUP BY fi.entity_id, r.resolution, date_trunc(r.resolution, fi.snapshot_date::timestamp), fi.indicator_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS external_request_counts;
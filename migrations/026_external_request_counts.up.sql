@@ -0,0 +1,9 @@
+-- Requests sent to a rate-limited external API per UTC day, counted by the
+-- client before each attempt (retries included), so every process shares one
+-- daily budget. service is the API name, e.g. coingecko.
+CREATE TABLE IF NOT EXISTS external_request_counts (
+    service      TEXT    NOT NULL,
+    request_date DATE    NOT NULL,
+    requests     INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (service, request_date)
+);
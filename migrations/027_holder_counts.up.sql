@@ -0,0 +1,8 @@
+-- Latest holder-count indicator values (I23, I24, I27, I40, I62), refreshed
+-- in the background by `stat serve` so snapshots can skip the Horizon holder
+-- walks. One row per indicator; computed_at decides whether it is fresh.
+CREATE TABLE IF NOT EXISTS holder_counts (
+    indicator_id INTEGER PRIMARY KEY,
+    value        NUMERIC NOT NULL,
+    computed_at  TIMESTAMP WITH TIME ZONE NOT NULL
+);
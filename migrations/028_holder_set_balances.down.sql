@@ -0,0 +1 @@
+ALTER TABLE holder_sets DROP COLUMN IF EXISTS balances;
@@ -0,0 +1,4 @@
+-- Balance of each holder in a holder set, parallel to accounts (MTL + MTLRECT
+-- for the MTL set), so a dividend run can be computed from the set of its
+-- record date. NULL for sets recorded before balances were kept.
+ALTER TABLE holder_sets ADD COLUMN IF NOT EXISTS balances NUMERIC[];
@@ -0,0 +1,14 @@
+-- Hourly samples of a few key indicators (I4, I10) taken by a light
+-- sampler, not the report pipeline, so they stay apart from the daily
+-- snapshot series in fund_indicators. Rows older than INTRADAY_RETENTION
+-- are pruned by the sampler.
+CREATE TABLE IF NOT EXISTS intraday_metrics (
+    entity_id    INTEGER                  NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    indicator_id INTEGER                  NOT NULL,
+    sampled_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    value        NUMERIC                  NOT NULL,
+    PRIMARY KEY (entity_id, indicator_id, sampled_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_intraday_metrics_sampled_at
+    ON intraday_metrics (sampled_at);
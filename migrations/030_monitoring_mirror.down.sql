@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS monitoring_mirror_dates;
+DROP TABLE IF EXISTS monitoring_mirror;
@@ -0,0 +1,17 @@
+-- Local mirror of the Google Sheets MONITORING state, kept up to date by
+-- every write so appends don't have to read the sheet back. One row per
+-- spreadsheet: the MONITORING sheet ID it describes and its last data row.
+CREATE TABLE IF NOT EXISTS monitoring_mirror (
+    spreadsheet_id TEXT PRIMARY KEY,
+    sheet_id       BIGINT NOT NULL,
+    last_row       INTEGER NOT NULL,
+    updated_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The dates written to MONITORING with their (1-based) sheet rows.
+CREATE TABLE IF NOT EXISTS monitoring_mirror_dates (
+    spreadsheet_id TEXT    NOT NULL REFERENCES monitoring_mirror(spreadsheet_id) ON DELETE CASCADE,
+    row_date       DATE    NOT NULL,
+    row_index      INTEGER NOT NULL,
+    PRIMARY KEY (spreadsheet_id, row_date)
+);
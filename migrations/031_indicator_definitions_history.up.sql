@@ -0,0 +1,13 @@
+-- Changelog of indicator formulas (definition.Change): each row is a new
+-- definition version of one indicator and the date it takes effect, so values
+-- compared across that date can be flagged as computed differently. Version 1
+-- is the original definition and has no row.
+CREATE TABLE IF NOT EXISTS indicator_definitions_history (
+    id             BIGSERIAL PRIMARY KEY,
+    indicator_id   INTEGER NOT NULL,
+    version        INTEGER NOT NULL,
+    effective_date DATE    NOT NULL,
+    summary        TEXT    NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (indicator_id, version)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subfond_flow_cursors;
+DROP TABLE IF EXISTS subfond_flows;
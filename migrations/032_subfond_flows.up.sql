@@ -0,0 +1,30 @@
+-- Transfers between a subfond and another fund account (subfond.Flow), one
+-- row per Horizon operation and subfond: the capital allocated to or taken
+-- out of the subfond, valued in EURMTL. The per-subfond ROI nets them out of
+-- the change of the subfond's total value. subfond_flow_cursors holds the
+-- last Horizon paging token scanned per subfond account.
+CREATE TABLE IF NOT EXISTS subfond_flows (
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    operation_id  VARCHAR(32) NOT NULL,
+    account       VARCHAR(56) NOT NULL,
+    subfond       TEXT        NOT NULL,
+    direction     VARCHAR(3)  NOT NULL,
+    counterparty  VARCHAR(56) NOT NULL,
+    asset         VARCHAR(69) NOT NULL,
+    amount        NUMERIC     NOT NULL,
+    value_eurmtl  NUMERIC     NOT NULL,
+    tx_hash       VARCHAR(64) NOT NULL,
+    occurred_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, operation_id, account)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subfond_flows_occurred ON subfond_flows (entity_id, occurred_at);
+
+CREATE TABLE IF NOT EXISTS subfond_flow_cursors (
+    entity_id  INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    account    VARCHAR(56) NOT NULL,
+    cursor     VARCHAR(32) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, account)
+);
@@ -0,0 +1,14 @@
+-- Partner and affiliate accounts tracked outside the fund registry
+-- (watchlist.Entry). Their balances of the listed assets, or of every asset
+-- when the list is empty, are captured into the snapshot's watchlist
+-- section; they never count towards fund totals. Assets are stored as
+-- "native" or CODE:ISSUER.
+CREATE TABLE IF NOT EXISTS watchlist (
+    id         BIGSERIAL PRIMARY KEY,
+    entity_id  INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    address    VARCHAR(56) NOT NULL,
+    label      TEXT        NOT NULL,
+    assets     TEXT[]      NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_id, address)
+);
@@ -0,0 +1,16 @@
+-- Price discovery details (domain.PriceDetails: orderbook levels, AMM pools,
+-- path hops) of each valued token, moved out of fund_snapshots.data when
+-- SNAPSHOT_DETACH_DETAILS is on. The snapshot keeps a stub with the source
+-- and "detached": true; GET /api/v1/snapshots/{date}/details reads them back.
+-- asset is the holdings key (XLM or CODE-ISSUER), base EURMTL or XLM.
+CREATE TABLE IF NOT EXISTS snapshot_details (
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE        NOT NULL,
+    account       VARCHAR(56) NOT NULL,
+    asset         TEXT        NOT NULL,
+    base          TEXT        NOT NULL,
+    details       JSONB       NOT NULL,
+    PRIMARY KEY (entity_id, snapshot_date, account, asset, base)
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_details_asset ON snapshot_details (entity_id, asset, snapshot_date);
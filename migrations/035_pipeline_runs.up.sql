@@ -0,0 +1,18 @@
+-- One row per tracked daily report run (internal/slo): how long it took end
+-- to end and per stage, and whether it met SLO_DEADLINE. GET /api/v1/status
+-- reports the 30-day attainment from it. stages maps a stage name
+-- (valuation, portfolio, pricing, metrics, persistence, export) to
+-- milliseconds.
+CREATE TABLE IF NOT EXISTS pipeline_runs (
+    id            BIGSERIAL   PRIMARY KEY,
+    entity_id     INTEGER     NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date DATE        NOT NULL,
+    started_at    TIMESTAMPTZ NOT NULL,
+    duration_ms   BIGINT      NOT NULL,
+    deadline_ms   BIGINT      NOT NULL,
+    succeeded     BOOLEAN     NOT NULL,
+    met           BOOLEAN     NOT NULL,
+    stages        JSONB       NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_pipeline_runs_started ON pipeline_runs (entity_id, started_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS main_indicators;
+DROP TABLE IF EXISTS pricing_policies;
+DROP TABLE IF EXISTS regulatory_price;
@@ -0,0 +1,29 @@
+-- Parameters that were compiled in, now managed through /api/v1/admin/...
+-- An entity without a row uses the compiled-in default.
+
+-- The Regulatory Price column of the MONITORING sheet.
+CREATE TABLE IF NOT EXISTS regulatory_price (
+    entity_id  INTEGER       PRIMARY KEY REFERENCES fund_entities(id) ON DELETE CASCADE,
+    price      NUMERIC(20,7) NOT NULL,
+    version    INTEGER       NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The spot price source of an asset: best (the higher of path finding and
+-- the orderbook), path or orderbook. asset is "native" or CODE:ISSUER.
+CREATE TABLE IF NOT EXISTS pricing_policies (
+    entity_id  INTEGER      NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    asset      VARCHAR(70)  NOT NULL,
+    source     VARCHAR(16)  NOT NULL,
+    version    INTEGER      NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_id, asset)
+);
+
+-- The indicators of the IND_MAIN sheet.
+CREATE TABLE IF NOT EXISTS main_indicators (
+    entity_id  INTEGER   PRIMARY KEY REFERENCES fund_entities(id) ON DELETE CASCADE,
+    ids        INTEGER[] NOT NULL,
+    version    INTEGER   NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
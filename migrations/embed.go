@@ -4,3 +4,9 @@ import "embed"
 
 //go:embed *.sql
 var FS embed.FS
+
+// SQLite holds the SQLite schema of the snapshot and quote tables, under
+// sqlite/ (fs.Sub it before RunSQLiteMigrations).
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS quote_history;
+DROP TABLE IF EXISTS external_quotes;
+DROP TABLE IF EXISTS fund_snapshots;
+DROP TABLE IF EXISTS fund_entities;
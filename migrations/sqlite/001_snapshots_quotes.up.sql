@@ -0,0 +1,38 @@
+-- SQLite schema for local runs (DATABASE_URL=sqlite://...). It covers only
+-- the tables of the snapshot and quote repositories, mirroring their
+-- PostgreSQL columns: JSON documents and NUMERIC prices are stored as TEXT,
+-- dates as YYYY-MM-DD and timestamps as RFC 3339 in UTC.
+CREATE TABLE IF NOT EXISTS fund_entities (
+    id          INTEGER PRIMARY KEY,
+    slug        TEXT    NOT NULL UNIQUE,
+    name        TEXT    NOT NULL,
+    description TEXT,
+    created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS fund_snapshots (
+    id            INTEGER PRIMARY KEY,
+    entity_id     INTEGER NOT NULL REFERENCES fund_entities(id) ON DELETE CASCADE,
+    snapshot_date TEXT    NOT NULL,
+    data          TEXT    NOT NULL,
+    hash          TEXT,
+    signature     TEXT,
+    signer        TEXT,
+    created_at    TEXT    NOT NULL,
+
+    UNIQUE (entity_id, snapshot_date)
+);
+
+CREATE TABLE IF NOT EXISTS external_quotes (
+    symbol       TEXT PRIMARY KEY,
+    price_in_eur TEXT NOT NULL,
+    updated_at   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quote_history (
+    symbol       TEXT NOT NULL,
+    quote_date   TEXT NOT NULL,
+    price_in_eur TEXT NOT NULL,
+    fetched_at   TEXT NOT NULL,
+    PRIMARY KEY (symbol, quote_date)
+);